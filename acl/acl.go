@@ -38,6 +38,16 @@ func (c capabilitySet) Clear() {
 	}
 }
 
+// list returns the capabilities of the set in sorted order.
+func (c capabilitySet) list() []string {
+	caps := make([]string, 0, len(c))
+	for cap := range c {
+		caps = append(caps, cap)
+	}
+	sort.Strings(caps)
+	return caps
+}
+
 // ACL object is used to convert a set of policies into a structure that
 // can be efficiently evaluated to determine if an action is allowed.
 type ACL struct {
@@ -51,6 +61,14 @@ type ACL struct {
 	// We use an iradix for the purposes of ordered iteration.
 	wildcardNamespaces *iradix.Tree
 
+	// variables maps a namespace or namespace glob to the capabilitySet of
+	// each variables path glob in the namespace
+	variables map[string]map[string]capabilitySet
+
+	// jobs maps a namespace or namespace glob to the capabilitySet of each
+	// job ID glob in the namespace
+	jobs map[string]map[string]capabilitySet
+
 	agent    string
 	node     string
 	operator string
@@ -80,13 +98,19 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 	}
 
 	// Create the ACL object
-	acl := &ACL{}
+	acl := &ACL{
+		variables: make(map[string]map[string]capabilitySet),
+		jobs:      make(map[string]map[string]capabilitySet),
+	}
 	nsTxn := iradix.New().Txn()
 	wnsTxn := iradix.New().Txn()
 
 	for _, policy := range policies {
 	NAMESPACES:
 		for _, ns := range policy.Namespaces {
+			acl.addVariablesPaths(ns)
+			acl.addJobs(ns)
+
 			// Should the namespace be matched using a glob?
 			globDefinition := strings.Contains(ns.Name, "*")
 
@@ -264,6 +288,229 @@ func (a *ACL) findAllMatchingWildcards(ns string) []matchingGlob {
 	return matches
 }
 
+// addVariablesPaths adds the variables capabilities granted by the namespace
+// policy, including those implied by its short hand policy.
+func (a *ACL) addVariablesPaths(ns *NamespacePolicy) {
+	add := func(pathSpec string, caps []string) {
+		paths, ok := a.variables[ns.Name]
+		if !ok {
+			paths = make(map[string]capabilitySet)
+			a.variables[ns.Name] = paths
+		}
+
+		capabilities, ok := paths[pathSpec]
+		if !ok {
+			capabilities = make(capabilitySet)
+			paths[pathSpec] = capabilities
+		}
+
+		// Deny always takes precedence
+		if capabilities.Check(VariablesCapabilityDeny) {
+			return
+		}
+		for _, cap := range caps {
+			if cap == VariablesCapabilityDeny {
+				capabilities.Clear()
+				capabilities.Set(VariablesCapabilityDeny)
+				return
+			}
+			capabilities.Set(cap)
+		}
+	}
+
+	if ns.Policy != "" {
+		add("*", expandVariablesPolicy(ns.Policy))
+	}
+	if ns.Variables != nil {
+		for _, path := range ns.Variables.Paths {
+			add(path.PathSpec, path.Capabilities)
+		}
+	}
+}
+
+// addJobs adds the capabilities granted by the job rules of the namespace
+// policy.
+func (a *ACL) addJobs(ns *NamespacePolicy) {
+	for _, job := range ns.Jobs {
+		jobs, ok := a.jobs[ns.Name]
+		if !ok {
+			jobs = make(map[string]capabilitySet)
+			a.jobs[ns.Name] = jobs
+		}
+
+		capabilities, ok := jobs[job.JobSpec]
+		if !ok {
+			capabilities = make(capabilitySet)
+			jobs[job.JobSpec] = capabilities
+		}
+
+		// Deny always takes precedence
+		if capabilities.Check(NamespaceCapabilityDeny) {
+			continue
+		}
+		for _, cap := range job.Capabilities {
+			if cap == NamespaceCapabilityDeny {
+				capabilities.Clear()
+				capabilities.Set(NamespaceCapabilityDeny)
+				break
+			}
+			capabilities.Set(cap)
+		}
+	}
+}
+
+// matchingJobRules returns the job rules of the namespace, using the closest
+// matching namespace glob when there is no exact match.
+func (a *ACL) matchingJobRules(ns string) (map[string]capabilitySet, bool) {
+	if jobs, ok := a.jobs[ns]; ok {
+		return jobs, true
+	}
+
+	nsGlobs := make([]string, 0, len(a.jobs))
+	for nsGlob := range a.jobs {
+		nsGlobs = append(nsGlobs, nsGlob)
+	}
+	nsGlob, ok := closestMatchingGlob(nsGlobs, ns)
+	if !ok {
+		return nil, false
+	}
+	return a.jobs[nsGlob], true
+}
+
+// AllowJobOp is shorthand for AllowJobOperation
+func (a *ACL) AllowJobOp(ns, jobID, op string) bool {
+	return a.AllowJobOperation(ns, jobID, op)
+}
+
+// AllowJobOperation checks if a given operation is allowed on the job in the
+// namespace. The operation is allowed if it is granted on the namespace or by
+// the closest matching job rule, unless either denies it.
+func (a *ACL) AllowJobOperation(ns, jobID, op string) bool {
+	// Hot path management tokens
+	if a.management {
+		return true
+	}
+
+	nsCapabilities, nsOk := a.matchingCapabilitySet(ns)
+	if nsOk && nsCapabilities.Check(NamespaceCapabilityDeny) {
+		return false
+	}
+
+	if jobs, ok := a.matchingJobRules(ns); ok {
+		capabilities, ok := jobs[jobID]
+		if !ok {
+			jobGlobs := make([]string, 0, len(jobs))
+			for jobGlob := range jobs {
+				jobGlobs = append(jobGlobs, jobGlob)
+			}
+			if jobGlob, found := closestMatchingGlob(jobGlobs, jobID); found {
+				capabilities, ok = jobs[jobGlob], true
+			}
+		}
+		if ok {
+			if capabilities.Check(NamespaceCapabilityDeny) {
+				return false
+			}
+			if capabilities.Check(op) {
+				return true
+			}
+		}
+	}
+
+	return nsOk && nsCapabilities.Check(op)
+}
+
+// AllowNamespaceJobsOperation checks if a given operation is allowed on the
+// namespace or on at least one of its jobs. It is used to reject requests
+// before the job they act on is known.
+func (a *ACL) AllowNamespaceJobsOperation(ns, op string) bool {
+	if a.AllowNamespaceOperation(ns, op) {
+		return true
+	}
+
+	if capabilities, ok := a.matchingCapabilitySet(ns); ok && capabilities.Check(NamespaceCapabilityDeny) {
+		return false
+	}
+	jobs, ok := a.matchingJobRules(ns)
+	if !ok {
+		return false
+	}
+	for _, capabilities := range jobs {
+		if capabilities.Check(op) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowVariableOperation checks if a given operation is allowed on the
+// variable at the path in the namespace. The closest matching namespace and
+// path globs are used when there is no exact match.
+func (a *ACL) AllowVariableOperation(ns, path, op string) bool {
+	// Hot path management tokens
+	if a.management {
+		return true
+	}
+
+	// Denying the namespace denies its variables
+	if capabilities, ok := a.matchingCapabilitySet(ns); ok && capabilities.Check(NamespaceCapabilityDeny) {
+		return false
+	}
+
+	paths, ok := a.variables[ns]
+	if !ok {
+		nsGlobs := make([]string, 0, len(a.variables))
+		for nsGlob := range a.variables {
+			nsGlobs = append(nsGlobs, nsGlob)
+		}
+		nsGlob, ok := closestMatchingGlob(nsGlobs, ns)
+		if !ok {
+			return false
+		}
+		paths = a.variables[nsGlob]
+	}
+
+	capabilities, ok := paths[path]
+	if !ok {
+		pathGlobs := make([]string, 0, len(paths))
+		for pathGlob := range paths {
+			pathGlobs = append(pathGlobs, pathGlob)
+		}
+		pathGlob, ok := closestMatchingGlob(pathGlobs, path)
+		if !ok {
+			return false
+		}
+		capabilities = paths[pathGlob]
+	}
+
+	return capabilities.Check(op)
+}
+
+// closestMatchingGlob returns the glob that matches the value with the
+// smallest character difference, in the same way namespace globs are
+// matched.
+func closestMatchingGlob(globs []string, value string) (string, bool) {
+	sort.Strings(globs)
+
+	var matches []matchingGlob
+	for _, g := range globs {
+		if glob.Glob(g, value) {
+			matches = append(matches, matchingGlob{
+				ns:         g,
+				difference: len(value) - len(g) + strings.Count(g, glob.GLOB),
+			})
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].difference < matches[j].difference
+	})
+	return matches[0].ns, true
+}
+
 // AllowAgentRead checks if read operations are allowed for an agent
 func (a *ACL) AllowAgentRead() bool {
 	switch {
@@ -372,3 +619,82 @@ func (a *ACL) AllowQuotaWrite() bool {
 func (a *ACL) IsManagement() bool {
 	return a.management
 }
+
+// Capabilities is the set of capabilities an ACL grants, merged from all of
+// its policies.
+type Capabilities struct {
+	// Management is true for management ACLs, which are allowed to do
+	// anything. The other fields are empty for them.
+	Management bool
+
+	// Namespaces maps a namespace or namespace glob to the capabilities
+	// granted on it.
+	Namespaces map[string]*NamespaceCapabilities
+
+	// Agent, Node, Operator and Quota are the policies granted on the
+	// respective resources, or empty if none is granted.
+	Agent    string
+	Node     string
+	Operator string
+	Quota    string
+}
+
+// NamespaceCapabilities is the set of capabilities granted on a namespace.
+type NamespaceCapabilities struct {
+	// Capabilities are granted on all jobs of the namespace.
+	Capabilities []string
+
+	// Jobs maps job ID globs to the capabilities granted on the matching
+	// jobs in addition to Capabilities.
+	Jobs map[string][]string
+
+	// Variables maps variable path globs to the capabilities granted on the
+	// matching variables.
+	Variables map[string][]string
+}
+
+// Capabilities returns the capabilities the ACL grants.
+func (a *ACL) Capabilities() *Capabilities {
+	if a.management {
+		return &Capabilities{Management: true}
+	}
+
+	c := &Capabilities{
+		Namespaces: make(map[string]*NamespaceCapabilities),
+		Agent:      a.agent,
+		Node:       a.node,
+		Operator:   a.operator,
+		Quota:      a.quota,
+	}
+	namespace := func(ns string) *NamespaceCapabilities {
+		n, ok := c.Namespaces[ns]
+		if !ok {
+			n = &NamespaceCapabilities{Capabilities: []string{}}
+			c.Namespaces[ns] = n
+		}
+		return n
+	}
+
+	walk := func(k []byte, v interface{}) bool {
+		namespace(string(k)).Capabilities = v.(capabilitySet).list()
+		return false
+	}
+	a.namespaces.Root().Walk(walk)
+	a.wildcardNamespaces.Root().Walk(walk)
+
+	for ns, jobs := range a.jobs {
+		n := namespace(ns)
+		n.Jobs = make(map[string][]string, len(jobs))
+		for job, capabilities := range jobs {
+			n.Jobs[job] = capabilities.list()
+		}
+	}
+	for ns, paths := range a.variables {
+		n := namespace(ns)
+		n.Variables = make(map[string][]string, len(paths))
+		for path, capabilities := range paths {
+			n.Variables[path] = capabilities.list()
+		}
+	}
+	return c
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCapabilitySet(t *testing.T) {
@@ -410,3 +411,287 @@ func TestACL_matchingCapabilitySet_difference(t *testing.T) {
 	}
 
 }
+
+func TestAllowVariableOperation(t *testing.T) {
+	tests := []struct {
+		Policy string
+		NS     string
+		Path   string
+		Op     string
+		Allow  bool
+	}{
+		{
+			Policy: `namespace "default" { policy = "read" }`,
+			NS:     "default",
+			Path:   "project/db",
+			Op:     VariablesCapabilityRead,
+			Allow:  true,
+		},
+		{
+			Policy: `namespace "default" { policy = "read" }`,
+			NS:     "default",
+			Path:   "project/db",
+			Op:     VariablesCapabilityWrite,
+			Allow:  false,
+		},
+		{
+			Policy: `namespace "default" { policy = "write" }`,
+			NS:     "default",
+			Path:   "project/db",
+			Op:     VariablesCapabilityDestroy,
+			Allow:  true,
+		},
+		{
+			Policy: `namespace "default" { policy = "write" }`,
+			NS:     "other",
+			Path:   "project/db",
+			Op:     VariablesCapabilityRead,
+			Allow:  false,
+		},
+		{
+			Policy: `namespace "default" {
+				variables {
+					path "project/*" { capabilities = ["read", "list"] }
+				}
+			}`,
+			NS:    "default",
+			Path:  "project/db",
+			Op:    VariablesCapabilityRead,
+			Allow: true,
+		},
+		{
+			Policy: `namespace "default" {
+				variables {
+					path "project/*" { capabilities = ["read", "list"] }
+				}
+			}`,
+			NS:    "default",
+			Path:  "other/db",
+			Op:    VariablesCapabilityRead,
+			Allow: false,
+		},
+		{
+			// The closest matching path glob is used
+			Policy: `namespace "default" {
+				policy = "write"
+				variables {
+					path "project/secret/*" { capabilities = ["deny"] }
+				}
+			}`,
+			NS:    "default",
+			Path:  "project/secret/db",
+			Op:    VariablesCapabilityRead,
+			Allow: false,
+		},
+		{
+			Policy: `namespace "prod-*" {
+				variables {
+					path "*" { capabilities = ["write"] }
+				}
+			}`,
+			NS:    "prod-api",
+			Path:  "db",
+			Op:    VariablesCapabilityWrite,
+			Allow: true,
+		},
+		{
+			// Denying the namespace denies its variables
+			Policy: `namespace "default" {
+				capabilities = ["deny"]
+				variables {
+					path "*" { capabilities = ["read"] }
+				}
+			}`,
+			NS:    "default",
+			Path:  "db",
+			Op:    VariablesCapabilityRead,
+			Allow: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Policy, func(t *testing.T) {
+			require := require.New(t)
+
+			policy, err := Parse(tc.Policy)
+			require.NoError(err)
+
+			acl, err := NewACL(false, []*Policy{policy})
+			require.NoError(err)
+			require.Equal(tc.Allow, acl.AllowVariableOperation(tc.NS, tc.Path, tc.Op))
+		})
+	}
+
+	// Management tokens may do anything
+	require.True(t, ManagementACL.AllowVariableOperation("default", "db", VariablesCapabilityDestroy))
+}
+
+func TestAllowJobOperation(t *testing.T) {
+	tests := []struct {
+		Policy string
+		NS     string
+		JobID  string
+		Op     string
+		Allow  bool
+	}{
+		{
+			Policy: `namespace "default" { policy = "write" }`,
+			NS:     "default",
+			JobID:  "web",
+			Op:     NamespaceCapabilitySubmitJob,
+			Allow:  true,
+		},
+		{
+			Policy: `namespace "default" {
+				policy = "read"
+				job "team-a/*" { capabilities = ["submit-job"] }
+			}`,
+			NS:    "default",
+			JobID: "team-a/web",
+			Op:    NamespaceCapabilitySubmitJob,
+			Allow: true,
+		},
+		{
+			Policy: `namespace "default" {
+				policy = "read"
+				job "team-a/*" { capabilities = ["submit-job"] }
+			}`,
+			NS:    "default",
+			JobID: "team-b/web",
+			Op:    NamespaceCapabilitySubmitJob,
+			Allow: false,
+		},
+		{
+			// Namespace capabilities apply to jobs with rules
+			Policy: `namespace "default" {
+				policy = "read"
+				job "team-a/*" { capabilities = ["submit-job"] }
+			}`,
+			NS:    "default",
+			JobID: "team-a/web",
+			Op:    NamespaceCapabilityReadJob,
+			Allow: true,
+		},
+		{
+			// The closest matching job glob is used
+			Policy: `namespace "default" {
+				policy = "write"
+				job "team-a/*" { capabilities = ["submit-job"] }
+				job "team-a/secret-*" { capabilities = ["deny"] }
+			}`,
+			NS:    "default",
+			JobID: "team-a/secret-db",
+			Op:    NamespaceCapabilityReadJob,
+			Allow: false,
+		},
+		{
+			Policy: `namespace "prod-*" {
+				job "*" { policy = "read" }
+			}`,
+			NS:    "prod-api",
+			JobID: "web",
+			Op:    NamespaceCapabilityReadJob,
+			Allow: true,
+		},
+		{
+			// Denying the namespace denies its jobs
+			Policy: `namespace "default" {
+				capabilities = ["deny"]
+				job "*" { policy = "write" }
+			}`,
+			NS:    "default",
+			JobID: "web",
+			Op:    NamespaceCapabilityReadJob,
+			Allow: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Policy, func(t *testing.T) {
+			require := require.New(t)
+
+			policy, err := Parse(tc.Policy)
+			require.NoError(err)
+
+			acl, err := NewACL(false, []*Policy{policy})
+			require.NoError(err)
+			require.Equal(tc.Allow, acl.AllowJobOperation(tc.NS, tc.JobID, tc.Op))
+		})
+	}
+
+	// Management tokens may do anything
+	require.True(t, ManagementACL.AllowJobOperation("default", "web", NamespaceCapabilitySubmitJob))
+}
+
+func TestAllowNamespaceJobsOperation(t *testing.T) {
+	require := require.New(t)
+
+	policy, err := Parse(`namespace "default" {
+		policy = "read"
+		job "team-a/*" { capabilities = ["submit-job"] }
+	}
+	namespace "other" {
+		capabilities = ["deny"]
+		job "*" { policy = "write" }
+	}`)
+	require.NoError(err)
+
+	acl, err := NewACL(false, []*Policy{policy})
+	require.NoError(err)
+	require.True(acl.AllowNamespaceJobsOperation("default", NamespaceCapabilityReadJob))
+	require.True(acl.AllowNamespaceJobsOperation("default", NamespaceCapabilitySubmitJob))
+	require.False(acl.AllowNamespaceJobsOperation("default", NamespaceCapabilityDispatchJob))
+	require.False(acl.AllowNamespaceJobsOperation("other", NamespaceCapabilitySubmitJob))
+	require.False(acl.AllowNamespaceJobsOperation("unknown", NamespaceCapabilityReadJob))
+}
+
+func TestACL_Capabilities(t *testing.T) {
+	require := require.New(t)
+
+	policy, err := Parse(`namespace "default" {
+		policy = "read"
+		job "team-a/*" { capabilities = ["submit-job"] }
+	}
+	namespace "prod-*" {
+		capabilities = ["list-jobs"]
+		variables {
+			path "app/*" { capabilities = ["read"] }
+		}
+	}
+	node {
+		policy = "write"
+	}`)
+	require.NoError(err)
+
+	acl, err := NewACL(false, []*Policy{policy})
+	require.NoError(err)
+
+	expected := &Capabilities{
+		Namespaces: map[string]*NamespaceCapabilities{
+			"default": {
+				Capabilities: []string{
+					NamespaceCapabilityCSIListVolume,
+					NamespaceCapabilityCSIReadVolume,
+					NamespaceCapabilityListJobs,
+					NamespaceCapabilityReadJob,
+				},
+				Jobs: map[string][]string{
+					"team-a/*": {NamespaceCapabilitySubmitJob},
+				},
+				Variables: map[string][]string{
+					"*": {VariablesCapabilityList, VariablesCapabilityRead},
+				},
+			},
+			"prod-*": {
+				Capabilities: []string{NamespaceCapabilityListJobs},
+				Variables: map[string][]string{
+					"app/*": {VariablesCapabilityRead},
+				},
+			},
+		},
+		Node: PolicyWrite,
+	}
+	require.Equal(expected, acl.Capabilities())
+
+	require.Equal(&Capabilities{Management: true}, ManagementACL.Capabilities())
+}
@@ -29,6 +29,21 @@ const (
 	NamespaceCapabilityReadLogs         = "read-logs"
 	NamespaceCapabilityReadFS           = "read-fs"
 	NamespaceCapabilitySentinelOverride = "sentinel-override"
+	NamespaceCapabilityCSIListVolume    = "csi-list-volume"
+	NamespaceCapabilityCSIReadVolume    = "csi-read-volume"
+	NamespaceCapabilityCSIWriteVolume   = "csi-write-volume"
+	NamespaceCapabilityCSIMountVolume   = "csi-mount-volume"
+)
+
+const (
+	// The following are the capabilities that can be granted on variable
+	// paths within a namespace. As with namespace capabilities, deny takes
+	// precedence over all other capabilities.
+	VariablesCapabilityDeny    = "deny"
+	VariablesCapabilityList    = "list"
+	VariablesCapabilityRead    = "read"
+	VariablesCapabilityWrite   = "write"
+	VariablesCapabilityDestroy = "destroy"
 )
 
 var (
@@ -60,6 +75,29 @@ type NamespacePolicy struct {
 	Name         string `hcl:",key"`
 	Policy       string
 	Capabilities []string
+	Variables    *VariablesPolicy `hcl:"variables"`
+	Jobs         []*JobPolicy     `hcl:"job,expand"`
+}
+
+// JobPolicy grants capabilities on the jobs whose ID matches the glob JobSpec,
+// in addition to those granted on the whole namespace. Denying a job pattern
+// denies all operations on the matching jobs.
+type JobPolicy struct {
+	JobSpec      string `hcl:",key"`
+	Policy       string
+	Capabilities []string
+}
+
+// VariablesPolicy is the policy for the variables in a namespace
+type VariablesPolicy struct {
+	Paths []*VariablesPathPolicy `hcl:"path,expand"`
+}
+
+// VariablesPathPolicy grants capabilities on the variables whose path
+// matches the glob PathSpec
+type VariablesPathPolicy struct {
+	PathSpec     string `hcl:",key"`
+	Capabilities []string
 }
 
 type AgentPolicy struct {
@@ -93,7 +131,8 @@ func isNamespaceCapabilityValid(cap string) bool {
 	switch cap {
 	case NamespaceCapabilityDeny, NamespaceCapabilityListJobs, NamespaceCapabilityReadJob,
 		NamespaceCapabilitySubmitJob, NamespaceCapabilityDispatchJob, NamespaceCapabilityReadLogs,
-		NamespaceCapabilityReadFS:
+		NamespaceCapabilityReadFS, NamespaceCapabilityCSIListVolume, NamespaceCapabilityCSIReadVolume,
+		NamespaceCapabilityCSIWriteVolume, NamespaceCapabilityCSIMountVolume:
 		return true
 	// Separate the enterprise-only capabilities
 	case NamespaceCapabilitySentinelOverride:
@@ -103,6 +142,41 @@ func isNamespaceCapabilityValid(cap string) bool {
 	}
 }
 
+// isVariablesCapabilityValid ensures the given capability is valid for a
+// variables path policy
+func isVariablesCapabilityValid(cap string) bool {
+	switch cap {
+	case VariablesCapabilityDeny, VariablesCapabilityList, VariablesCapabilityRead,
+		VariablesCapabilityWrite, VariablesCapabilityDestroy:
+		return true
+	default:
+		return false
+	}
+}
+
+// expandVariablesPolicy provides the equivalent set of variables
+// capabilities for a namespace policy
+func expandVariablesPolicy(policy string) []string {
+	switch policy {
+	case PolicyDeny:
+		return []string{VariablesCapabilityDeny}
+	case PolicyRead:
+		return []string{
+			VariablesCapabilityList,
+			VariablesCapabilityRead,
+		}
+	case PolicyWrite:
+		return []string{
+			VariablesCapabilityList,
+			VariablesCapabilityRead,
+			VariablesCapabilityWrite,
+			VariablesCapabilityDestroy,
+		}
+	default:
+		return nil
+	}
+}
+
 // expandNamespacePolicy provides the equivalent set of capabilities for
 // a namespace policy
 func expandNamespacePolicy(policy string) []string {
@@ -113,6 +187,8 @@ func expandNamespacePolicy(policy string) []string {
 		return []string{
 			NamespaceCapabilityListJobs,
 			NamespaceCapabilityReadJob,
+			NamespaceCapabilityCSIListVolume,
+			NamespaceCapabilityCSIReadVolume,
 		}
 	case PolicyWrite:
 		return []string{
@@ -122,6 +198,10 @@ func expandNamespacePolicy(policy string) []string {
 			NamespaceCapabilityDispatchJob,
 			NamespaceCapabilityReadLogs,
 			NamespaceCapabilityReadFS,
+			NamespaceCapabilityCSIListVolume,
+			NamespaceCapabilityCSIReadVolume,
+			NamespaceCapabilityCSIWriteVolume,
+			NamespaceCapabilityCSIMountVolume,
 		}
 	default:
 		return nil
@@ -164,6 +244,37 @@ func Parse(rules string) (*Policy, error) {
 			}
 		}
 
+		if ns.Variables != nil {
+			for _, path := range ns.Variables.Paths {
+				if path.PathSpec == "" {
+					return nil, fmt.Errorf("Invalid missing variables path in namespace %#v", ns)
+				}
+				for _, cap := range path.Capabilities {
+					if !isVariablesCapabilityValid(cap) {
+						return nil, fmt.Errorf("Invalid variables capability '%s': %#v", cap, path)
+					}
+				}
+			}
+		}
+
+		for _, job := range ns.Jobs {
+			if job.JobSpec == "" {
+				return nil, fmt.Errorf("Invalid missing job ID in namespace %#v", ns)
+			}
+			if job.Policy != "" && !isPolicyValid(job.Policy) {
+				return nil, fmt.Errorf("Invalid job policy: %#v", job)
+			}
+			for _, cap := range job.Capabilities {
+				if !isNamespaceCapabilityValid(cap) {
+					return nil, fmt.Errorf("Invalid job capability '%s': %#v", cap, job)
+				}
+			}
+			if job.Policy != "" {
+				extraCap := expandNamespacePolicy(job.Policy)
+				job.Capabilities = append(job.Capabilities, extraCap...)
+			}
+		}
+
 		// Expand the short hand policy to the capabilities and
 		// add to any existing capabilities
 		if ns.Policy != "" {
@@ -30,6 +30,8 @@ func TestParse(t *testing.T) {
 						Capabilities: []string{
 							NamespaceCapabilityListJobs,
 							NamespaceCapabilityReadJob,
+							NamespaceCapabilityCSIListVolume,
+							NamespaceCapabilityCSIReadVolume,
 						},
 					},
 				},
@@ -68,6 +70,8 @@ func TestParse(t *testing.T) {
 						Capabilities: []string{
 							NamespaceCapabilityListJobs,
 							NamespaceCapabilityReadJob,
+							NamespaceCapabilityCSIListVolume,
+							NamespaceCapabilityCSIReadVolume,
 						},
 					},
 					{
@@ -80,6 +84,10 @@ func TestParse(t *testing.T) {
 							NamespaceCapabilityDispatchJob,
 							NamespaceCapabilityReadLogs,
 							NamespaceCapabilityReadFS,
+							NamespaceCapabilityCSIListVolume,
+							NamespaceCapabilityCSIReadVolume,
+							NamespaceCapabilityCSIWriteVolume,
+							NamespaceCapabilityCSIMountVolume,
 						},
 					},
 					{
@@ -197,6 +205,123 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			`
+			namespace "default" {
+				variables {
+					path "project/*" {
+						capabilities = ["read", "list"]
+					}
+				}
+			}
+			`,
+			"",
+			&Policy{
+				Namespaces: []*NamespacePolicy{
+					{
+						Name: "default",
+						Variables: &VariablesPolicy{
+							Paths: []*VariablesPathPolicy{
+								{
+									PathSpec: "project/*",
+									Capabilities: []string{
+										VariablesCapabilityRead,
+										VariablesCapabilityList,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`
+			namespace "default" {
+				variables {
+					path "project/*" {
+						capabilities = ["submit-job"]
+					}
+				}
+			}
+			`,
+			"Invalid variables capability",
+			nil,
+		},
+		{
+			`
+			namespace "default" {
+				policy = "read"
+				job "team-a/*" {
+					policy = "write"
+				}
+				job "team-a/batch-*" {
+					capabilities = ["dispatch-job"]
+				}
+			}
+			`,
+			"",
+			&Policy{
+				Namespaces: []*NamespacePolicy{
+					{
+						Name:   "default",
+						Policy: PolicyRead,
+						Capabilities: []string{
+							NamespaceCapabilityListJobs,
+							NamespaceCapabilityReadJob,
+							NamespaceCapabilityCSIListVolume,
+							NamespaceCapabilityCSIReadVolume,
+						},
+						Jobs: []*JobPolicy{
+							{
+								JobSpec: "team-a/*",
+								Policy:  PolicyWrite,
+								Capabilities: []string{
+									NamespaceCapabilityListJobs,
+									NamespaceCapabilityReadJob,
+									NamespaceCapabilitySubmitJob,
+									NamespaceCapabilityDispatchJob,
+									NamespaceCapabilityReadLogs,
+									NamespaceCapabilityReadFS,
+									NamespaceCapabilityCSIListVolume,
+									NamespaceCapabilityCSIReadVolume,
+									NamespaceCapabilityCSIWriteVolume,
+									NamespaceCapabilityCSIMountVolume,
+								},
+							},
+							{
+								JobSpec: "team-a/batch-*",
+								Capabilities: []string{
+									NamespaceCapabilityDispatchJob,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`
+			namespace "default" {
+				job "team-a/*" {
+					capabilities = ["read"]
+				}
+			}
+			`,
+			"Invalid job capability",
+			nil,
+		},
+		{
+			`
+			namespace "default" {
+				job "team-a/*" {
+					policy = "admin"
+				}
+			}
+			`,
+			"Invalid job policy",
+			nil,
+		},
 	}
 
 	for idx, tc := range tcases {
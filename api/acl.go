@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 	"time"
 )
 
@@ -62,6 +63,196 @@ func (a *ACLPolicies) Info(policyName string, q *QueryOptions) (*ACLPolicy, *Que
 	return &resp, wm, nil
 }
 
+// ACLRoles is used to query the ACL Role endpoints.
+type ACLRoles struct {
+	client *Client
+}
+
+// ACLRoles returns a new handle on the ACL roles.
+func (c *Client) ACLRoles() *ACLRoles {
+	return &ACLRoles{client: c}
+}
+
+// List is used to dump all of the roles.
+func (a *ACLRoles) List(q *QueryOptions) ([]*ACLRoleListStub, *QueryMeta, error) {
+	var resp []*ACLRoleListStub
+	qm, err := a.client.query("/v1/acl/roles", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Upsert is used to create or update a role
+func (a *ACLRoles) Upsert(role *ACLRole, q *WriteOptions) (*WriteMeta, error) {
+	if role == nil || role.Name == "" {
+		return nil, fmt.Errorf("missing role name")
+	}
+	wm, err := a.client.write("/v1/acl/role/"+role.Name, role, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// Delete is used to delete a role
+func (a *ACLRoles) Delete(roleName string, q *WriteOptions) (*WriteMeta, error) {
+	if roleName == "" {
+		return nil, fmt.Errorf("missing role name")
+	}
+	wm, err := a.client.delete("/v1/acl/role/"+roleName, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// Info is used to query a specific role
+func (a *ACLRoles) Info(roleName string, q *QueryOptions) (*ACLRole, *QueryMeta, error) {
+	if roleName == "" {
+		return nil, nil, fmt.Errorf("missing role name")
+	}
+	var resp ACLRole
+	wm, err := a.client.query("/v1/acl/role/"+roleName, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// ACLAuthMethods is used to query the ACL auth method endpoints.
+type ACLAuthMethods struct {
+	client *Client
+}
+
+// ACLAuthMethods returns a new handle on the ACL auth methods.
+func (c *Client) ACLAuthMethods() *ACLAuthMethods {
+	return &ACLAuthMethods{client: c}
+}
+
+// List is used to dump all of the auth methods.
+func (a *ACLAuthMethods) List(q *QueryOptions) ([]*ACLAuthMethodListStub, *QueryMeta, error) {
+	var resp []*ACLAuthMethodListStub
+	qm, err := a.client.query("/v1/acl/auth-methods", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Upsert is used to create or update an auth method
+func (a *ACLAuthMethods) Upsert(method *ACLAuthMethod, q *WriteOptions) (*WriteMeta, error) {
+	if method == nil || method.Name == "" {
+		return nil, fmt.Errorf("missing auth method name")
+	}
+	wm, err := a.client.write("/v1/acl/auth-method/"+method.Name, method, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// Delete is used to delete an auth method and its binding rules
+func (a *ACLAuthMethods) Delete(methodName string, q *WriteOptions) (*WriteMeta, error) {
+	if methodName == "" {
+		return nil, fmt.Errorf("missing auth method name")
+	}
+	wm, err := a.client.delete("/v1/acl/auth-method/"+methodName, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// Info is used to query a specific auth method
+func (a *ACLAuthMethods) Info(methodName string, q *QueryOptions) (*ACLAuthMethod, *QueryMeta, error) {
+	if methodName == "" {
+		return nil, nil, fmt.Errorf("missing auth method name")
+	}
+	var resp ACLAuthMethod
+	wm, err := a.client.query("/v1/acl/auth-method/"+methodName, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// ACLBindingRules is used to query the ACL binding rule endpoints.
+type ACLBindingRules struct {
+	client *Client
+}
+
+// ACLBindingRules returns a new handle on the ACL binding rules.
+func (c *Client) ACLBindingRules() *ACLBindingRules {
+	return &ACLBindingRules{client: c}
+}
+
+// List is used to dump the binding rules, optionally limited to those of a
+// single auth method.
+func (a *ACLBindingRules) List(authMethod string, q *QueryOptions) ([]*ACLBindingRuleListStub, *QueryMeta, error) {
+	var resp []*ACLBindingRuleListStub
+	path := "/v1/acl/binding-rules"
+	if authMethod != "" {
+		path += "?auth_method=" + url.QueryEscape(authMethod)
+	}
+	qm, err := a.client.query(path, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Create is used to create a binding rule
+func (a *ACLBindingRules) Create(rule *ACLBindingRule, q *WriteOptions) (*ACLBindingRule, *WriteMeta, error) {
+	if rule.ID != "" {
+		return nil, nil, fmt.Errorf("cannot specify ID")
+	}
+	var resp ACLBindingRule
+	wm, err := a.client.write("/v1/acl/binding-rule", rule, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// Update is used to update an existing binding rule
+func (a *ACLBindingRules) Update(rule *ACLBindingRule, q *WriteOptions) (*ACLBindingRule, *WriteMeta, error) {
+	if rule.ID == "" {
+		return nil, nil, fmt.Errorf("missing binding rule ID")
+	}
+	var resp ACLBindingRule
+	wm, err := a.client.write("/v1/acl/binding-rule/"+rule.ID, rule, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// Delete is used to delete a binding rule
+func (a *ACLBindingRules) Delete(ruleID string, q *WriteOptions) (*WriteMeta, error) {
+	if ruleID == "" {
+		return nil, fmt.Errorf("missing binding rule ID")
+	}
+	wm, err := a.client.delete("/v1/acl/binding-rule/"+ruleID, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// Info is used to query a specific binding rule
+func (a *ACLBindingRules) Info(ruleID string, q *QueryOptions) (*ACLBindingRule, *QueryMeta, error) {
+	if ruleID == "" {
+		return nil, nil, fmt.Errorf("missing binding rule ID")
+	}
+	var resp ACLBindingRule
+	wm, err := a.client.query("/v1/acl/binding-rule/"+ruleID, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
 // ACLTokens is used to query the ACL token endpoints.
 type ACLTokens struct {
 	client *Client
@@ -154,6 +345,30 @@ func (a *ACLTokens) Self(q *QueryOptions) (*ACLToken, *QueryMeta, error) {
 	return &resp, wm, nil
 }
 
+// SelfCapabilities is used to query the capabilities granted to our own token
+func (a *ACLTokens) SelfCapabilities(q *QueryOptions) (*ACLTokenCapabilities, *QueryMeta, error) {
+	var resp ACLTokenCapabilities
+	wm, err := a.client.query("/v1/acl/token/self/capabilities", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// Login is used to exchange a JWT issued by the identity provider of an auth
+// method for a token
+func (a *ACLTokens) Login(req *ACLLoginRequest, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if req == nil || req.AuthMethod == "" {
+		return nil, nil, fmt.Errorf("missing auth method name")
+	}
+	var resp ACLToken
+	wm, err := a.client.write("/v1/acl/login", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
 // ACLPolicyListStub is used to for listing ACL policies
 type ACLPolicyListStub struct {
 	Name        string
@@ -171,26 +386,157 @@ type ACLPolicy struct {
 	ModifyIndex uint64
 }
 
-// ACLToken represents a client token which is used to Authenticate
-type ACLToken struct {
-	AccessorID  string
-	SecretID    string
+// ACLRoleListStub is used to for listing ACL roles
+type ACLRoleListStub struct {
 	Name        string
-	Type        string
+	Description string
 	Policies    []string
-	Global      bool
-	CreateTime  time.Time
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
-type ACLTokenListStub struct {
-	AccessorID  string
+// ACLRole is used to represent an ACL role, a named set of policies
+type ACLRole struct {
 	Name        string
-	Type        string
+	Description string
 	Policies    []string
-	Global      bool
-	CreateTime  time.Time
 	CreateIndex uint64
 	ModifyIndex uint64
 }
+
+// ACLToken represents a client token which is used to Authenticate
+type ACLToken struct {
+	AccessorID string
+	SecretID   string
+	Name       string
+	Type       string
+	Policies   []string
+	Roles      []string
+	Global     bool
+	CreateTime time.Time
+
+	// ExpirationTime is the time the token expires, or nil if it does not.
+	ExpirationTime *time.Time
+
+	// ExpirationTTL sets the expiration time when creating a token.
+	ExpirationTTL time.Duration
+
+	// AuthMethod is the auth method the token was created by logging in
+	// with, if any.
+	AuthMethod string
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+type ACLTokenListStub struct {
+	AccessorID     string
+	Name           string
+	Type           string
+	Policies       []string
+	Roles          []string
+	Global         bool
+	CreateTime     time.Time
+	ExpirationTime *time.Time
+	AuthMethod     string
+	CreateIndex    uint64
+	ModifyIndex    uint64
+}
+
+// ACLTokenCapabilities is the set of capabilities granted to a token, merged
+// from all of its policies
+type ACLTokenCapabilities struct {
+	// Management is true for management tokens, which are allowed to do
+	// anything
+	Management bool
+
+	// Namespaces maps a namespace or namespace glob to its capabilities
+	Namespaces map[string]*ACLNamespaceCapabilities
+
+	Agent    string
+	Node     string
+	Operator string
+	Quota    string
+}
+
+// ACLNamespaceCapabilities is the set of capabilities granted on a namespace
+type ACLNamespaceCapabilities struct {
+	// Capabilities are granted on all jobs of the namespace
+	Capabilities []string
+
+	// Jobs maps job ID globs to the capabilities granted on the matching
+	// jobs in addition to Capabilities
+	Jobs map[string][]string
+
+	// Variables maps variable path globs to the capabilities granted on the
+	// matching variables
+	Variables map[string][]string
+}
+
+// ACLAuthMethodListStub is used to for listing ACL auth methods
+type ACLAuthMethodListStub struct {
+	Name          string
+	Type          string
+	TokenLocality string
+	MaxTokenTTL   time.Duration
+	CreateIndex   uint64
+	ModifyIndex   uint64
+}
+
+// ACLAuthMethod is used to exchange JWTs issued by a trusted identity
+// provider for ACL tokens
+type ACLAuthMethod struct {
+	Name          string
+	Type          string
+	TokenLocality string
+	MaxTokenTTL   time.Duration
+	Config        *ACLAuthMethodConfig
+	CreateIndex   uint64
+	ModifyIndex   uint64
+}
+
+// ACLAuthMethodConfig describes how the JWTs presented to an auth method are
+// validated and which of their claims are available to binding rules
+type ACLAuthMethodConfig struct {
+	JWTValidationPubKeys []string
+	JWKSURL              string
+	JWKSCACert           string
+	OIDCDiscoveryURL     string
+	OIDCDiscoveryCACert  string
+	BoundAudiences       []string
+	BoundIssuer          []string
+	SigningAlgs          []string
+	ClaimMappings        map[string]string
+	ListClaimMappings    map[string]string
+	ClockSkewLeeway      time.Duration
+}
+
+// ACLBindingRuleListStub is used to for listing ACL binding rules
+type ACLBindingRuleListStub struct {
+	ID          string
+	Description string
+	AuthMethod  string
+	BindType    string
+	BindName    string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLBindingRule grants a policy or role to the tokens created by an auth
+// method when the claims of the login token match its selector
+type ACLBindingRule struct {
+	ID          string
+	Description string
+	AuthMethod  string
+	Selector    string
+	BindType    string
+	BindName    string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLLoginRequest is used to exchange a JWT for an ACL token
+type ACLLoginRequest struct {
+	AuthMethod string
+	LoginToken string
+}
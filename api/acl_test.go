@@ -2,8 +2,10 @@ package api
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestACLPolicies_ListUpsert(t *testing.T) {
@@ -212,6 +214,52 @@ func TestACLTokens_Self(t *testing.T) {
 	}
 }
 
+func TestACLTokens_SelfCapabilities(t *testing.T) {
+	t.Parallel()
+	c, s, _ := makeACLClient(t, nil, nil)
+	defer s.Stop()
+
+	// The capabilities of management tokens are not listed
+	out, qm, err := c.ACLTokens().SelfCapabilities(nil)
+	require.NoError(t, err)
+	assertQueryMeta(t, qm)
+	require.True(t, out.Management)
+
+	// Create a policy and a token using it
+	policy := &ACLPolicy{
+		Name: "test",
+		Rules: `
+namespace "default" {
+	policy = "read"
+	job "team-a/*" {
+		capabilities = ["submit-job"]
+	}
+}
+node {
+	policy = "read"
+}`,
+	}
+	_, err = c.ACLPolicies().Upsert(policy, nil)
+	require.NoError(t, err)
+
+	token, _, err := c.ACLTokens().Create(&ACLToken{
+		Name:     "test",
+		Type:     "client",
+		Policies: []string{"test"},
+	}, nil)
+	require.NoError(t, err)
+
+	c.SetSecretID(token.SecretID)
+	out, _, err = c.ACLTokens().SelfCapabilities(nil)
+	require.NoError(t, err)
+	require.False(t, out.Management)
+	require.Equal(t, "read", out.Node)
+	require.Contains(t, out.Namespaces, "default")
+	require.Contains(t, out.Namespaces["default"].Capabilities, "read-job")
+	require.Equal(t, []string{"submit-job"}, out.Namespaces["default"].Jobs["team-a/*"])
+	require.Equal(t, []string{"list", "read"}, out.Namespaces["default"].Variables["*"])
+}
+
 func TestACLTokens_Delete(t *testing.T) {
 	t.Parallel()
 	c, s, _ := makeACLClient(t, nil, nil)
@@ -235,3 +283,110 @@ func TestACLTokens_Delete(t *testing.T) {
 	assert.Nil(t, err)
 	assertWriteMeta(t, wm)
 }
+
+func TestACLRoles_CRUD(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s, _ := makeACLClient(t, nil, nil)
+	defer s.Stop()
+
+	// Register a policy for the role to reference
+	policy := &ACLPolicy{
+		Name:  "test",
+		Rules: `namespace "default" { policy = "read" }`,
+	}
+	_, err := c.ACLPolicies().Upsert(policy, nil)
+	require.NoError(err)
+
+	ar := c.ACLRoles()
+	role := &ACLRole{
+		Name:        "ops",
+		Description: "operators",
+		Policies:    []string{policy.Name},
+	}
+	wm, err := ar.Upsert(role, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	result, qm, err := ar.List(nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Len(result, 1)
+
+	out, qm, err := ar.Info(role.Name, nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Equal(role.Policies, out.Policies)
+
+	wm, err = ar.Delete(role.Name, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	result, _, err = ar.List(nil)
+	require.NoError(err)
+	require.Empty(result)
+}
+
+func TestACLAuthMethods_BindingRules_CRUD(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s, _ := makeACLClient(t, nil, nil)
+	defer s.Stop()
+
+	am := c.ACLAuthMethods()
+	method := &ACLAuthMethod{
+		Name:          "ci",
+		Type:          "JWT",
+		TokenLocality: "local",
+		MaxTokenTTL:   time.Hour,
+		Config: &ACLAuthMethodConfig{
+			JWKSURL:        "https://example.com/.well-known/jwks.json",
+			BoundAudiences: []string{"nomad"},
+			ClaimMappings:  map[string]string{"project": "project"},
+		},
+	}
+	wm, err := am.Upsert(method, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	methods, qm, err := am.List(nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Len(methods, 1)
+
+	out, qm, err := am.Info(method.Name, nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Equal(method.Config.JWKSURL, out.Config.JWKSURL)
+
+	// Create a binding rule for the auth method
+	br := c.ACLBindingRules()
+	rule, wm, err := br.Create(&ACLBindingRule{
+		AuthMethod: method.Name,
+		Selector:   `value.project == "web"`,
+		BindType:   "policy",
+		BindName:   "web",
+	}, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+	require.NotEmpty(rule.ID)
+
+	rules, qm, err := br.List(method.Name, nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Len(rules, 1)
+
+	outRule, qm, err := br.Info(rule.ID, nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Equal(rule.Selector, outRule.Selector)
+
+	// Deleting the auth method deletes its binding rules
+	wm, err = am.Delete(method.Name, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	rules, _, err = br.List("", nil)
+	require.NoError(err)
+	require.Empty(rules)
+}
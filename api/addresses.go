@@ -0,0 +1,191 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ServerFailureBackoff is how long a server address is skipped after a
+	// request to it fails, when the client is configured with more than one
+	// address.
+	ServerFailureBackoff = 30 * time.Second
+)
+
+const (
+	// srvSchemePrefix prefixes the scheme of an address that names a DNS SRV
+	// record to resolve, such as srv+https://_nomad-http._tcp.example.com.
+	srvSchemePrefix = "srv+"
+)
+
+// srvLookup resolves the targets of a DNS SRV record. It is largely used to
+// mock DNS in tests.
+type srvLookup func(name string) ([]*net.SRV, error)
+
+func lookupSRV(name string) ([]*net.SRV, error) {
+	_, addrs, err := net.LookupSRV("", "", name)
+	return addrs, err
+}
+
+// serverAddr is a single address requests may be sent to.
+type serverAddr struct {
+	url         *url.URL
+	failedUntil time.Time
+}
+
+// serverList is the set of addresses the client rotates requests between.
+// SRV records are resolved when the first request is made, addresses that
+// fail are skipped until ServerFailureBackoff has passed, and SRV records are
+// resolved again once every address has failed.
+type serverList struct {
+	l       sync.Mutex
+	entries []*url.URL
+	servers []*serverAddr
+	next    int
+	lookup  srvLookup
+}
+
+// parseAddresses parses a comma separated list of addresses.
+func parseAddresses(address string) ([]*url.URL, error) {
+	var entries []*url.URL
+	for _, addr := range strings.Split(address, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			return nil, fmt.Errorf("invalid address '%s': empty address in list", address)
+		}
+
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%s': %v", addr, err)
+		}
+		if strings.HasPrefix(u.Scheme, srvSchemePrefix) && u.Host == "" {
+			return nil, fmt.Errorf("invalid address '%s': missing SRV record name", addr)
+		}
+		entries = append(entries, u)
+	}
+	return entries, nil
+}
+
+// newServerList returns the server list for the given address. Any SRV
+// records it contains aren't resolved until an address is picked.
+func newServerList(address string, lookup srvLookup) (*serverList, error) {
+	entries, err := parseAddresses(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &serverList{
+		entries: entries,
+		lookup:  lookup,
+	}, nil
+}
+
+// resolve expands the configured entries into the addresses to use.
+func (s *serverList) resolve() ([]*serverAddr, error) {
+	var servers []*serverAddr
+	for _, entry := range s.entries {
+		if !strings.HasPrefix(entry.Scheme, srvSchemePrefix) {
+			servers = append(servers, &serverAddr{url: entry})
+			continue
+		}
+
+		targets, err := s.lookup(entry.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve SRV record %q: %v", entry.Host, err)
+		}
+		for _, target := range targets {
+			servers = append(servers, &serverAddr{
+				url: &url.URL{
+					Scheme: strings.TrimPrefix(entry.Scheme, srvSchemePrefix),
+					User:   entry.User,
+					Host:   net.JoinHostPort(strings.TrimSuffix(target.Target, "."), strconv.Itoa(int(target.Port))),
+				},
+			})
+		}
+	}
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers found for address")
+	}
+	return servers, nil
+}
+
+// len returns the number of resolved addresses in the list.
+func (s *serverList) len() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return len(s.servers)
+}
+
+// pick returns the next healthy address in the rotation, resolving the
+// addresses on first use. If every address has failed, the addresses are
+// resolved again and their failures forgotten.
+func (s *serverList) pick() (*url.URL, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.servers == nil {
+		servers, err := s.resolve()
+		if err != nil {
+			return nil, err
+		}
+		s.servers = servers
+	}
+
+	now := time.Now()
+	n := len(s.servers)
+	for i := 0; i < n; i++ {
+		idx := (s.next + i) % n
+		if server := s.servers[idx]; now.After(server.failedUntil) {
+			s.next = idx + 1
+			return copyURL(server.url), nil
+		}
+	}
+
+	// Every address has failed, so resolve them again and start afresh
+	if servers, err := s.resolve(); err == nil {
+		s.servers = servers
+	}
+	idx := s.next % len(s.servers)
+	s.next = idx + 1
+	return copyURL(s.servers[idx].url), nil
+}
+
+// failed marks the address as unhealthy so it is skipped by pick.
+func (s *serverList) failed(u *url.URL) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	for _, server := range s.servers {
+		if server.url.Scheme == u.Scheme && server.url.Host == u.Host {
+			server.failedUntil = time.Now().Add(ServerFailureBackoff)
+		}
+	}
+}
+
+// retryable returns whether a request that failed with the given error may be
+// sent to another server. Requests that may have reached the server are only
+// retried if they are safe to repeat.
+func retryable(method string, err error) bool {
+	switch method {
+	case "GET", "HEAD":
+		return true
+	}
+
+	// The request was never sent if connecting to the server failed
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	operr, ok := err.(*net.OpError)
+	return ok && operr.Op == "dial"
+}
+
+func copyURL(u *url.URL) *url.URL {
+	c := *u
+	return &c
+}
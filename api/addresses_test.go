@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerList_Parse(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	_, err := newServerList("http://a:4646,,http://b:4646", lookupSRV)
+	require.Error(err)
+	require.Contains(err.Error(), "empty address")
+
+	_, err = newServerList("srv+http://", lookupSRV)
+	require.Error(err)
+	require.Contains(err.Error(), "missing SRV record name")
+
+	s, err := newServerList("http://a:4646, https://b:4646", lookupSRV)
+	require.NoError(err)
+	require.Equal("http://a:4646", pick(t, s))
+	require.Equal(2, s.len())
+	require.Equal("https://b:4646", pick(t, s))
+	require.Equal("http://a:4646", pick(t, s))
+}
+
+// pick returns the next address of the server list as a string.
+func pick(t *testing.T, s *serverList) string {
+	u, err := s.pick()
+	require.NoError(t, err)
+	return u.String()
+}
+
+func TestServerList_SRV(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	lookups := 0
+	lookup := func(name string) ([]*net.SRV, error) {
+		lookups++
+		if name != "_nomad-http._tcp.example.com" {
+			return nil, fmt.Errorf("unknown record %q", name)
+		}
+		return []*net.SRV{
+			{Target: "server-1.example.com.", Port: 4646},
+			{Target: "server-2.example.com.", Port: 5656},
+		}, nil
+	}
+
+	// Records are resolved when the first address is picked
+	s, err := newServerList("srv+https://_nomad._tcp.example.com", lookup)
+	require.NoError(err)
+	require.Zero(lookups)
+	_, err = s.pick()
+	require.Error(err)
+	require.Equal(1, lookups)
+
+	s, err = newServerList("srv+https://_nomad-http._tcp.example.com", lookup)
+	require.NoError(err)
+
+	first, err := s.pick()
+	require.NoError(err)
+	require.Equal("https://server-1.example.com:4646", first.String())
+	require.Equal(2, s.len())
+
+	// A failed server is skipped
+	s.failed(first)
+	require.Equal("https://server-2.example.com:5656", pick(t, s))
+	require.Equal("https://server-2.example.com:5656", pick(t, s))
+
+	// Once every server has failed the record is resolved again
+	s.failed(&url.URL{Scheme: "https", Host: "server-2.example.com:5656"})
+	before := lookups
+	pick(t, s)
+	require.Equal(before+1, lookups)
+	require.Equal("https://server-2.example.com:5656", pick(t, s))
+}
+
+func TestClient_Failover(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Create an address nothing is listening on
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	requests := 0
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var in map[string]string
+		if r.Method == "PUT" {
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(in)
+	}))
+	defer live.Close()
+
+	conf := DefaultConfig()
+	conf.Address = dead.URL + "," + live.URL
+	client, err := NewClient(conf)
+	require.NoError(err)
+
+	// The request to the dead server fails over to the live one
+	var out map[string]string
+	_, err = client.query("/v1/test", &out, nil)
+	require.NoError(err)
+	require.Equal(1, requests)
+
+	// Request bodies are replayed against the next server
+	for _, server := range client.servers.servers {
+		server.failedUntil = time.Time{}
+	}
+	client.servers.next = 0
+	in := map[string]string{"key": "value"}
+	_, err = client.write("/v1/test", in, &out, nil)
+	require.NoError(err)
+	require.Equal(in, out)
+	require.Equal(2, requests)
+
+	// The dead server is skipped while it is backing off
+	_, err = client.query("/v1/test", &out, nil)
+	require.NoError(err)
+	require.Equal(3, requests)
+	for _, server := range client.servers.servers {
+		if server.url.Host == live.Listener.Addr().String() {
+			require.True(server.failedUntil.IsZero())
+		} else {
+			require.True(server.failedUntil.After(time.Now()))
+		}
+	}
+}
+
+func TestClient_Failover_NotRetried(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// A server that drops requests after reading them
+	drops := 0
+	dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		drops++
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(err)
+		conn.Close()
+	}))
+	defer dropping.Close()
+
+	requests := 0
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("{}"))
+	}))
+	defer live.Close()
+
+	conf := DefaultConfig()
+	conf.Address = dropping.URL + "," + live.URL
+	client, err := NewClient(conf)
+	require.NoError(err)
+
+	// A write that may have reached the server isn't repeated
+	var out map[string]string
+	_, err = client.write("/v1/test", map[string]string{"key": "value"}, &out, nil)
+	require.Error(err)
+	require.Equal(0, requests)
+
+	// Reads are safe to repeat
+	for _, server := range client.servers.servers {
+		server.failedUntil = time.Time{}
+	}
+	client.servers.next = 0
+	_, err = client.query("/v1/test", &out, nil)
+	require.NoError(err)
+	require.Equal(1, requests)
+	require.True(drops >= 2)
+}
+
+func TestClient_SRVResolvedLazily(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	conf := DefaultConfig()
+	conf.Address = "srv+http://_nomad-http._tcp.example.com"
+	client, err := NewClient(conf)
+	require.NoError(err)
+
+	// Failing to resolve the record fails the request
+	client.servers.lookup = func(name string) ([]*net.SRV, error) {
+		return nil, fmt.Errorf("no such host")
+	}
+	_, err = client.newRequest("GET", "/v1/jobs")
+	require.Error(err)
+	require.Contains(err.Error(), "no such host")
+	require.Equal(conf.Address, client.ServerAddress())
+}
@@ -79,6 +79,111 @@ func (a *Allocations) GC(alloc *Allocation, q *QueryOptions) error {
 	return err
 }
 
+// Restart restarts the task with the given name, or all the tasks of the
+// allocation if the name is empty. It returns once the tasks have been killed,
+// after which they are started again by the client.
+func (a *Allocations) Restart(alloc *Allocation, taskName string, q *QueryOptions) error {
+	req := AllocationRestartRequest{
+		TaskName: taskName,
+	}
+
+	var resp struct{}
+	_, err := a.client.putQuery("/v1/client/allocation/"+alloc.ID+"/restart", &req, &resp, q)
+	return err
+}
+
+// Stop stops the allocation and reschedules it elsewhere. The returned
+// response contains the evaluation created to place the replacement.
+func (a *Allocations) Stop(alloc *Allocation, q *WriteOptions) (*AllocStopResponse, error) {
+	var resp AllocStopResponse
+	wm, err := a.client.write("/v1/allocation/"+alloc.ID+"/stop", nil, &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	resp.WriteMeta = *wm
+	return &resp, nil
+}
+
+// Reschedule reschedules a failed allocation immediately instead of waiting
+// for the delay of its reschedule policy. The returned response contains the
+// evaluation created to place the replacement.
+func (a *Allocations) Reschedule(alloc *Allocation, q *WriteOptions) (*AllocRescheduleResponse, error) {
+	var resp AllocRescheduleResponse
+	wm, err := a.client.write("/v1/allocation/"+alloc.ID+"/reschedule", nil, &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	resp.WriteMeta = *wm
+	return &resp, nil
+}
+
+// RescheduleStatus returns when the allocation is rescheduled according to
+// the reschedule policy of its task group.
+func (a *Allocations) RescheduleStatus(allocID string, q *QueryOptions) (*AllocRescheduleStatus, *QueryMeta, error) {
+	var resp AllocRescheduleStatus
+	qm, err := a.client.query("/v1/allocation/"+allocID+"/reschedule", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Signal sends a signal, such as "SIGHUP", to the task with the given name,
+// or to all the tasks of the allocation if the name is empty.
+func (a *Allocations) Signal(alloc *Allocation, task, signal string, q *QueryOptions) error {
+	req := AllocSignalRequest{
+		Task:   task,
+		Signal: signal,
+	}
+
+	var resp struct{}
+	_, err := a.client.putQuery("/v1/client/allocation/"+alloc.ID+"/signal", &req, &resp, q)
+	return err
+}
+
+// AllocationRestartRequest is used to restart the tasks of an allocation.
+type AllocationRestartRequest struct {
+	TaskName string
+}
+
+// AllocSignalRequest is used to send a signal to the tasks of an allocation.
+type AllocSignalRequest struct {
+	Task   string
+	Signal string
+}
+
+// AllocStopResponse is the response to stopping an allocation.
+type AllocStopResponse struct {
+	// EvalID is the id of the evaluation created to reschedule the
+	// allocation.
+	EvalID string
+
+	WriteMeta
+}
+
+// AllocRescheduleResponse is the response to rescheduling an allocation.
+type AllocRescheduleResponse struct {
+	// EvalID is the id of the evaluation created to place the replacement
+	// allocation.
+	EvalID string
+
+	WriteMeta
+}
+
+// AllocRescheduleStatus describes when a failed allocation is rescheduled.
+type AllocRescheduleStatus struct {
+	AllocID            string
+	NextAllocation     string
+	Eligible           bool
+	NextRescheduleTime time.Time
+	Delay              time.Duration
+	Attempted          int
+	Attempts           int
+	Unlimited          bool
+	FollowupEvalID     string
+	ForceReschedule    bool
+}
+
 // Allocation is used for serialization of allocations.
 type Allocation struct {
 	ID                    string
@@ -161,6 +266,7 @@ type AllocationListStub struct {
 	DeploymentStatus   *AllocDeploymentStatus
 	FollowupEvalID     string
 	RescheduleTracker  *RescheduleTracker
+	AllocatedResources *AllocatedResources `json:",omitempty"`
 	CreateIndex        uint64
 	ModifyIndex        uint64
 	CreateTime         int64
@@ -272,6 +378,10 @@ type DesiredTransition struct {
 	// Reschedule is used to indicate that this allocation is eligible to be
 	// rescheduled.
 	Reschedule *bool
+
+	// ForceReschedule is used to indicate that this allocation must be
+	// rescheduled immediately.
+	ForceReschedule *bool
 }
 
 // ShouldMigrate returns whether the transition object dictates a migration.
@@ -245,3 +245,36 @@ func TestAllocations_ShouldMigrate(t *testing.T) {
 	require.False(t, DesiredTransition{}.ShouldMigrate())
 	require.False(t, DesiredTransition{Migrate: boolToPtr(false)}.ShouldMigrate())
 }
+
+func TestAllocations_Lifecycle_UnknownAlloc(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	a := c.Allocations()
+
+	alloc := &Allocation{
+		ID:     uuid.Generate(),
+		NodeID: uuid.Generate(),
+	}
+
+	err := a.Restart(alloc, "web", nil)
+	require.Error(err)
+	require.Contains(err.Error(), "Unknown allocation")
+
+	err = a.Signal(alloc, "web", "SIGHUP", nil)
+	require.Error(err)
+	require.Contains(err.Error(), "Unknown allocation")
+
+	_, err = a.Stop(alloc, nil)
+	require.Error(err)
+	require.Contains(err.Error(), "Unknown allocation")
+
+	_, err = a.Reschedule(alloc, nil)
+	require.Error(err)
+	require.Contains(err.Error(), "Unknown allocation")
+
+	_, _, err = a.RescheduleStatus(alloc.ID, nil)
+	require.Error(err)
+	require.Contains(err.Error(), "alloc not found")
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -55,6 +56,29 @@ type QueryOptions struct {
 
 	// AuthToken is the secret ID of an ACL token
 	AuthToken string
+
+	// ctx is an optional context used to cancel the HTTP requests of the
+	// query
+	ctx context.Context
+}
+
+// Context returns the context used to cancel the HTTP requests of the query.
+func (o *QueryOptions) Context() context.Context {
+	if o != nil && o.ctx != nil {
+		return o.ctx
+	}
+	return context.Background()
+}
+
+// WithContext returns a copy of the query options using the context to
+// cancel the HTTP requests of the query.
+func (o *QueryOptions) WithContext(ctx context.Context) *QueryOptions {
+	o2 := new(QueryOptions)
+	if o != nil {
+		*o2 = *o
+	}
+	o2.ctx = ctx
+	return o2
 }
 
 // WriteOptions are used to parameterize a write
@@ -108,7 +132,11 @@ type HttpBasicAuth struct {
 
 // Config is used to configure the creation of a client
 type Config struct {
-	// Address is the address of the Nomad agent
+	// Address is the address of the Nomad agent. It may be a comma separated
+	// list of addresses, and an address of the form srv+http://name or
+	// srv+https://name is resolved as a DNS SRV record. Requests are rotated
+	// between the addresses and fail over to the next address when one can't
+	// be reached.
 	Address string
 
 	// Region to use. If not provided, the default agent region is used.
@@ -180,7 +208,9 @@ type TLSConfig struct {
 	ClientKey string
 
 	// TLSServerName, if set, is used to set the SNI host when connecting via
-	// TLS.
+	// TLS, and to verify the certificate of the server. It allows reaching
+	// Nomad through a proxy that routes on the SNI host, at an address that
+	// differs from the name of the server.
 	TLSServerName string
 
 	// Insecure enables or disables SSL verification
@@ -248,6 +278,9 @@ func DefaultConfig() *Config {
 	if v := os.Getenv("NOMAD_CLIENT_KEY"); v != "" {
 		config.TLSConfig.ClientKey = v
 	}
+	if v := os.Getenv("NOMAD_TLS_SERVER_NAME"); v != "" {
+		config.TLSConfig.TLSServerName = v
+	}
 	if v := os.Getenv("NOMAD_SKIP_VERIFY"); v != "" {
 		if insecure, err := strconv.ParseBool(v); err == nil {
 			config.TLSConfig.Insecure = insecure
@@ -334,7 +367,8 @@ func (c *Config) ConfigureTLS() error {
 
 // Client provides a client to the Nomad API
 type Client struct {
-	config Config
+	config  Config
+	servers *serverList
 }
 
 // NewClient returns a new client
@@ -344,8 +378,10 @@ func NewClient(config *Config) (*Client, error) {
 
 	if config.Address == "" {
 		config.Address = defConfig.Address
-	} else if _, err := url.Parse(config.Address); err != nil {
-		return nil, fmt.Errorf("invalid address '%s': %v", config.Address, err)
+	}
+	servers, err := newServerList(config.Address, lookupSRV)
+	if err != nil {
+		return nil, err
 	}
 
 	if config.httpClient == nil {
@@ -358,7 +394,8 @@ func NewClient(config *Config) (*Client, error) {
 	}
 
 	client := &Client{
-		config: *config,
+		config:  *config,
+		servers: servers,
 	}
 	return client, nil
 }
@@ -368,6 +405,19 @@ func (c *Client) Address() string {
 	return c.config.Address
 }
 
+// ServerAddress returns a single address of the Nomad agent, chosen from the
+// configured addresses the same way as for a request.
+func (c *Client) ServerAddress() string {
+	if c.servers == nil {
+		return c.config.Address
+	}
+	u, err := c.servers.pick()
+	if err != nil {
+		return c.config.Address
+	}
+	return u.String()
+}
+
 // SetRegion sets the region to forward API requests to.
 func (c *Client) SetRegion(region string) {
 	c.config.Region = region
@@ -447,6 +497,7 @@ type request struct {
 	token  string
 	body   io.Reader
 	obj    interface{}
+	ctx    context.Context
 }
 
 // setQueryOptions is used to annotate the request with
@@ -479,6 +530,7 @@ func (r *request) setQueryOptions(q *QueryOptions) {
 	for k, v := range q.Params {
 		r.params.Set(k, v)
 	}
+	r.ctx = q.ctx
 }
 
 // durToMsec converts a duration to a millisecond specified string
@@ -540,12 +592,23 @@ func (r *request) toHTTP() (*http.Request, error) {
 	req.URL.Host = r.url.Host
 	req.URL.Scheme = r.url.Scheme
 	req.Host = r.url.Host
+	if r.ctx != nil {
+		req = req.WithContext(r.ctx)
+	}
 	return req, nil
 }
 
 // newRequest is used to create a new request
 func (c *Client) newRequest(method, path string) (*request, error) {
-	base, _ := url.Parse(c.config.Address)
+	var base *url.URL
+	if c.servers != nil {
+		var err error
+		if base, err = c.servers.pick(); err != nil {
+			return nil, err
+		}
+	} else {
+		base, _ = url.Parse(c.config.Address)
+	}
 	u, err := url.Parse(path)
 	if err != nil {
 		return nil, err
@@ -604,14 +667,52 @@ func (m *multiCloser) Read(p []byte) (int, error) {
 	return m.reader.Read(p)
 }
 
-// doRequest runs a request with our client
+// doRequest runs a request with our client. If the client has multiple
+// server addresses and the request fails to reach one, the address is marked
+// failed and the request is retried against the next if that is safe.
 func (c *Client) doRequest(r *request) (time.Duration, *http.Response, error) {
 	req, err := r.toHTTP()
 	if err != nil {
 		return 0, nil, err
 	}
+
+	// Only requests whose body can be replayed are retried
+	var body []byte
+	retries := 0
+	if c.servers != nil {
+		retries = c.servers.len() - 1
+	}
+	switch b := r.body.(type) {
+	case nil:
+	case *bytes.Buffer:
+		body = append([]byte(nil), b.Bytes()...)
+	default:
+		retries = 0
+	}
+
 	start := time.Now()
 	resp, err := c.config.httpClient.Do(req)
+	for err != nil && c.servers != nil && (r.ctx == nil || r.ctx.Err() == nil) {
+		c.servers.failed(r.url)
+		if retries <= 0 || !retryable(r.method, err) {
+			break
+		}
+		retries--
+
+		next, perr := c.servers.pick()
+		if perr != nil {
+			break
+		}
+		r.url.Scheme, r.url.User, r.url.Host = next.Scheme, next.User, next.Host
+		if body != nil {
+			r.body = bytes.NewBuffer(body)
+		}
+
+		if req, err = r.toHTTP(); err != nil {
+			return 0, nil, err
+		}
+		resp, err = c.config.httpClient.Do(req)
+	}
 	diff := time.Now().Sub(start)
 
 	// If the response is compressed, we swap the body's reader.
@@ -7,12 +7,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type configCallback func(c *Config)
@@ -118,6 +120,7 @@ func TestDefaultConfig_env(t *testing.T) {
 	region := "test"
 	namespace := "dev"
 	token := "foobar"
+	serverName := "nomad.example.com"
 
 	os.Setenv("NOMAD_ADDR", url)
 	defer os.Setenv("NOMAD_ADDR", "")
@@ -134,6 +137,9 @@ func TestDefaultConfig_env(t *testing.T) {
 	os.Setenv("NOMAD_TOKEN", token)
 	defer os.Setenv("NOMAD_TOKEN", "")
 
+	os.Setenv("NOMAD_TLS_SERVER_NAME", serverName)
+	defer os.Setenv("NOMAD_TLS_SERVER_NAME", "")
+
 	config := DefaultConfig()
 
 	if config.Address != url {
@@ -159,6 +165,10 @@ func TestDefaultConfig_env(t *testing.T) {
 	if config.SecretID != token {
 		t.Errorf("Expected %q to be %q", config.SecretID, token)
 	}
+
+	if config.TLSConfig.TLSServerName != serverName {
+		t.Errorf("expected %q to be %q", config.TLSConfig.TLSServerName, serverName)
+	}
 }
 
 func TestSetQueryOptions(t *testing.T) {
@@ -313,6 +323,51 @@ func TestQueryString(t *testing.T) {
 	}
 }
 
+func TestClient_TLSServerName(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Record the SNI host and token of the requests, as a proxy routing many
+	// clusters through one endpoint would see them
+	var l sync.Mutex
+	var serverNames, tokens []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.Lock()
+		defer l.Unlock()
+		serverNames = append(serverNames, r.TLS.ServerName)
+		tokens = append(tokens, r.Header.Get("X-Nomad-Token"))
+		w.Write([]byte("[]"))
+	}))
+	ts.Config.SetKeepAlivesEnabled(false)
+	ts.StartTLS()
+	defer ts.Close()
+
+	config := DefaultConfig()
+	config.Address = ts.URL
+	config.SecretID = "default-token"
+	config.TLSConfig = &TLSConfig{
+		TLSServerName: "cluster1.nomad.example.com",
+		Insecure:      true,
+	}
+	c, err := NewClient(config)
+	require.NoError(err)
+
+	// The client token is used by default
+	_, _, err = c.Jobs().List(nil)
+	require.NoError(err)
+
+	// The token can be overridden per request
+	_, _, err = c.Jobs().List(&QueryOptions{AuthToken: "query-token"})
+	require.NoError(err)
+	_, err = c.Raw().Write("/v1/jobs", nil, nil, &WriteOptions{AuthToken: "write-token"})
+	require.NoError(err)
+
+	l.Lock()
+	defer l.Unlock()
+	require.Equal([]string{"cluster1.nomad.example.com", "cluster1.nomad.example.com", "cluster1.nomad.example.com"}, serverNames)
+	require.Equal([]string{"default-token", "query-token", "write-token"}, tokens)
+}
+
 func TestClient_NodeClient(t *testing.T) {
 	http := "testdomain:4646"
 	tlsNode := func(string, *QueryOptions) (*Node, *QueryMeta, error) {
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"net/url"
+	"time"
+)
+
+// CSIVolumes is used to manage the CSI volumes registered with Nomad.
+type CSIVolumes struct {
+	client *Client
+}
+
+// CSIVolumes returns a new handle on the CSI volumes.
+func (c *Client) CSIVolumes() *CSIVolumes {
+	return &CSIVolumes{client: c}
+}
+
+// List is used to list the CSI volumes in a namespace.
+func (v *CSIVolumes) List(q *QueryOptions) ([]*CSIVolumeListStub, *QueryMeta, error) {
+	var resp []*CSIVolumeListStub
+	qm, err := v.client.query("/v1/volumes", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// ListByPlugin is used to list the CSI volumes provided by a plugin.
+func (v *CSIVolumes) ListByPlugin(pluginID string, q *QueryOptions) ([]*CSIVolumeListStub, *QueryMeta, error) {
+	var resp []*CSIVolumeListStub
+	qm, err := v.client.query("/v1/volumes?plugin_id="+url.QueryEscape(pluginID), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Info is used to get a single CSI volume.
+func (v *CSIVolumes) Info(id string, q *QueryOptions) (*CSIVolume, *QueryMeta, error) {
+	var resp CSIVolume
+	qm, err := v.client.query("/v1/volume/csi/"+id, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Register is used to register a CSI volume that already exists in the
+// storage provider.
+func (v *CSIVolumes) Register(vol *CSIVolume, w *WriteOptions) (*WriteMeta, error) {
+	req := CSIVolumeRegisterRequest{
+		Volumes: []*CSIVolume{vol},
+	}
+	return v.client.write("/v1/volume/csi/"+vol.ID, req, nil, w)
+}
+
+// Deregister is used to deregister a CSI volume without deleting it from the
+// storage provider. Volumes in use are only deregistered if force is set.
+func (v *CSIVolumes) Deregister(id string, force bool, w *WriteOptions) (*WriteMeta, error) {
+	endpoint := "/v1/volume/csi/" + id
+	if force {
+		endpoint += "?force=true"
+	}
+	return v.client.delete(endpoint, nil, w)
+}
+
+// Create is used to create a CSI volume through its controller plugin and
+// register it.
+func (v *CSIVolumes) Create(vol *CSIVolume, w *WriteOptions) ([]*CSIVolume, *WriteMeta, error) {
+	req := CSIVolumeCreateRequest{
+		Volumes: []*CSIVolume{vol},
+	}
+	var resp []*CSIVolume
+	wm, err := v.client.write("/v1/volume/csi/"+vol.ID+"/create", req, &resp, w)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, wm, nil
+}
+
+// Delete is used to delete an unclaimed CSI volume through its controller
+// plugin and deregister it.
+func (v *CSIVolumes) Delete(id string, w *WriteOptions) (*WriteMeta, error) {
+	return v.client.delete("/v1/volume/csi/"+id+"/delete", nil, w)
+}
+
+// Detach is used to release the claim of a terminal allocation on a CSI
+// volume, detaching the volume from the allocation's node.
+func (v *CSIVolumes) Detach(id, allocID string, w *WriteOptions) (*WriteMeta, error) {
+	return v.client.delete("/v1/volume/csi/"+id+"/detach?alloc="+url.QueryEscape(allocID), nil, w)
+}
+
+// CSIVolumeAccessMode is how allocations may access a CSI volume.
+type CSIVolumeAccessMode string
+
+const (
+	CSIVolumeAccessModeSingleNodeReader      CSIVolumeAccessMode = "single-node-reader-only"
+	CSIVolumeAccessModeSingleNodeWriter      CSIVolumeAccessMode = "single-node-writer"
+	CSIVolumeAccessModeMultiNodeReader       CSIVolumeAccessMode = "multi-node-reader-only"
+	CSIVolumeAccessModeMultiNodeSingleWriter CSIVolumeAccessMode = "multi-node-single-writer"
+	CSIVolumeAccessModeMultiNodeMultiWriter  CSIVolumeAccessMode = "multi-node-multi-writer"
+)
+
+// CSIVolumeAttachmentMode is how a CSI volume is presented to allocations.
+type CSIVolumeAttachmentMode string
+
+const (
+	CSIVolumeAttachmentModeBlockDevice CSIVolumeAttachmentMode = "block-device"
+	CSIVolumeAttachmentModeFilesystem  CSIVolumeAttachmentMode = "file-system"
+)
+
+// CSIVolumeClaim is an allocation's claim on a CSI volume.
+type CSIVolumeClaim struct {
+	AllocationID   string
+	NodeID         string
+	ExternalNodeID string
+	Mode           int
+	State          int
+}
+
+// CSIVolume is a volume provided by a CSI plugin.
+type CSIVolume struct {
+	ID              string
+	Name            string
+	Namespace       string
+	ExternalID      string
+	PluginID        string
+	Provider        string
+	ProviderVersion string
+	AccessMode      CSIVolumeAccessMode
+	AttachmentMode  CSIVolumeAttachmentMode
+	CapacityMin     int64
+	CapacityMax     int64
+	Capacity        int64
+	Secrets         map[string]string
+	Parameters      map[string]string
+	Context         map[string]string
+
+	ReadClaims  map[string]*CSIVolumeClaim
+	WriteClaims map[string]*CSIVolumeClaim
+	PastClaims  map[string]*CSIVolumeClaim
+
+	Schedulable         bool
+	ControllerRequired  bool
+	ControllersHealthy  int
+	ControllersExpected int
+	NodesHealthy        int
+	NodesExpected       int
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// CSIVolumeListStub is a summary of a CSI volume.
+type CSIVolumeListStub struct {
+	ID                  string
+	Name                string
+	Namespace           string
+	ExternalID          string
+	PluginID            string
+	Provider            string
+	AccessMode          CSIVolumeAccessMode
+	AttachmentMode      CSIVolumeAttachmentMode
+	CurrentReaders      int
+	CurrentWriters      int
+	Schedulable         bool
+	ControllerRequired  bool
+	ControllersHealthy  int
+	ControllersExpected int
+	NodesHealthy        int
+	NodesExpected       int
+	CreateIndex         uint64
+	ModifyIndex         uint64
+}
+
+// CSIVolumeRegisterRequest is used to register CSI volumes.
+type CSIVolumeRegisterRequest struct {
+	Volumes []*CSIVolume
+	WriteRequest
+}
+
+// CSIVolumeCreateRequest is used to create CSI volumes.
+type CSIVolumeCreateRequest struct {
+	Volumes []*CSIVolume
+	WriteRequest
+}
+
+// CSIPlugins is used to query the CSI plugins running on the clients.
+type CSIPlugins struct {
+	client *Client
+}
+
+// CSIPlugins returns a new handle on the CSI plugins.
+func (c *Client) CSIPlugins() *CSIPlugins {
+	return &CSIPlugins{client: c}
+}
+
+// List is used to list the CSI plugins.
+func (p *CSIPlugins) List(q *QueryOptions) ([]*CSIPluginListStub, *QueryMeta, error) {
+	var resp []*CSIPluginListStub
+	qm, err := p.client.query("/v1/plugins", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Info is used to get a single CSI plugin with its instances.
+func (p *CSIPlugins) Info(id string, q *QueryOptions) (*CSIPlugin, *QueryMeta, error) {
+	var resp CSIPlugin
+	qm, err := p.client.query("/v1/plugin/csi/"+id, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// CSIInfo is the state of a CSI plugin running on a node.
+type CSIInfo struct {
+	PluginID                 string
+	Healthy                  bool
+	HealthDescription        string
+	UpdateTime               time.Time
+	Provider                 string
+	ProviderVersion          string
+	RequiresControllerPlugin bool
+	ControllerInfo           *CSIControllerInfo
+	NodeInfo                 *CSINodeInfo
+}
+
+// CSIControllerInfo is the capabilities of a controller plugin.
+type CSIControllerInfo struct {
+	SupportsAttachDetach bool
+	SupportsCreateDelete bool
+}
+
+// CSINodeInfo is the identity of a node as reported by a node plugin.
+type CSINodeInfo struct {
+	ID         string
+	MaxVolumes int64
+}
+
+// CSIPlugin aggregates the instances of a CSI plugin running on the nodes.
+type CSIPlugin struct {
+	ID                 string
+	Provider           string
+	Version            string
+	ControllerRequired bool
+	Controllers        map[string]*CSIInfo
+	Nodes              map[string]*CSIInfo
+	ControllersHealthy int
+	NodesHealthy       int
+	CreateIndex        uint64
+	ModifyIndex        uint64
+}
+
+// CSIPluginListStub is a summary of a CSI plugin.
+type CSIPluginListStub struct {
+	ID                  string
+	Provider            string
+	ControllerRequired  bool
+	ControllersHealthy  int
+	ControllersExpected int
+	NodesHealthy        int
+	NodesExpected       int
+	CreateIndex         uint64
+	ModifyIndex         uint64
+}
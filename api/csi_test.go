@@ -0,0 +1,37 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSI_VolumesPlugins(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	// No plugins run without clients fingerprinting them
+	plugins, _, err := c.CSIPlugins().List(nil)
+	require.NoError(err)
+	require.Empty(plugins)
+
+	volumes, _, err := c.CSIVolumes().List(nil)
+	require.NoError(err)
+	require.Empty(volumes)
+
+	// Volumes can only be registered for known plugins
+	_, err = c.CSIVolumes().Register(&CSIVolume{
+		ID:             "data",
+		ExternalID:     "vol-1",
+		PluginID:       "ebs",
+		AccessMode:     CSIVolumeAccessModeSingleNodeWriter,
+		AttachmentMode: CSIVolumeAttachmentModeFilesystem,
+	}, nil)
+	require.Error(err)
+	require.Contains(err.Error(), "no CSI plugin")
+
+	_, _, err = c.CSIPlugins().Info("ebs", nil)
+	require.Error(err)
+}
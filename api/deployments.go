@@ -123,6 +123,23 @@ func (d *Deployments) SetAllocHealth(deploymentID string, healthy, unhealthy []s
 	return &resp, wm, nil
 }
 
+// SetGroupHealth is used to mark every pending allocation of the given task
+// groups healthy, such as when their health has been validated by a system
+// external to Nomad. The reason is recorded on the deployment.
+func (d *Deployments) SetGroupHealth(deploymentID string, groups []string, reason string, q *WriteOptions) (*DeploymentUpdateResponse, *WriteMeta, error) {
+	var resp DeploymentUpdateResponse
+	req := &DeploymentGroupHealthRequest{
+		DeploymentID: deploymentID,
+		Groups:       groups,
+		Reason:       reason,
+	}
+	wm, err := d.client.write("/v1/deployment/group-health/"+deploymentID, req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
 // Deployment is used to serialize an deployment.
 type Deployment struct {
 	// ID is a generated UUID for the deployment
@@ -167,16 +184,28 @@ type Deployment struct {
 
 // DeploymentState tracks the state of a deployment for a given task group.
 type DeploymentState struct {
-	PlacedCanaries    []string
-	AutoRevert        bool
-	ProgressDeadline  time.Duration
-	RequireProgressBy time.Time
-	Promoted          bool
-	DesiredCanaries   int
-	DesiredTotal      int
-	PlacedAllocs      int
-	HealthyAllocs     int
-	UnhealthyAllocs   int
+	PlacedCanaries          []string
+	AutoRevert              bool
+	AutoPromote             bool
+	ManualPromotionPerGroup bool
+	ProgressDeadline        time.Duration
+	RequireProgressBy       time.Time
+	Promoted                bool
+	DesiredCanaries         int
+	DesiredTotal            int
+	PlacedAllocs            int
+	HealthyAllocs           int
+	UnhealthyAllocs         int
+	HealthOverrides         []*DeploymentHealthOverride
+}
+
+// DeploymentHealthOverride records that the allocations of a task group were
+// manually marked healthy.
+type DeploymentHealthOverride struct {
+	Reason        string
+	AccessorID    string
+	AllocationIDs []string
+	Timestamp     time.Time
 }
 
 // DeploymentIndexSort is a wrapper to sort deployments by CreateIndex. We
@@ -221,6 +250,16 @@ type DeploymentAllocHealthRequest struct {
 	WriteRequest
 }
 
+// DeploymentGroupHealthRequest is used to mark the task groups of a
+// deployment healthy.
+type DeploymentGroupHealthRequest struct {
+	DeploymentID string
+	Groups       []string
+	Reason       string
+
+	WriteRequest
+}
+
 // DeploymentPromoteRequest is used to promote task groups in a deployment
 type DeploymentPromoteRequest struct {
 	DeploymentID string
@@ -52,6 +52,33 @@ func (e *Evaluations) Allocations(evalID string, q *QueryOptions) ([]*Allocation
 	return resp, qm, nil
 }
 
+// Related is used to retrieve the evaluations related to an evaluation,
+// including itself, sorted by creation. Evaluations are related when they are
+// linked through their PreviousEval, NextEval and BlockedEval, such as the
+// blocked evaluation created by an evaluation that failed to place
+// allocations and the evaluation replacing it once it is unblocked. The
+// TriggeredBy and StatusDescription of each evaluation describe why it was
+// created and its outcome.
+func (e *Evaluations) Related(evalID string, q *QueryOptions) ([]*Evaluation, *QueryMeta, error) {
+	var resp []*Evaluation
+	qm, err := e.client.query("/v1/evaluation/"+evalID+"/related", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// PlacementFailures is used to list why the allocations of the blocked
+// evaluations could not be placed.
+func (e *Evaluations) PlacementFailures(q *QueryOptions) ([]*PlacementFailure, *QueryMeta, error) {
+	var resp []*PlacementFailure
+	qm, err := e.client.query("/v1/evaluations/placement-failures", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
 // Evaluation is used to serialize an evaluation.
 type Evaluation struct {
 	ID                   string
@@ -82,6 +109,17 @@ type Evaluation struct {
 	ModifyIndex          uint64
 }
 
+// PlacementFailure describes why the allocations of a task group of a job
+// could not be placed, as recorded by its blocked evaluation.
+type PlacementFailure struct {
+	Namespace string
+	JobID     string
+	TaskGroup string
+	EvalID    string
+	Unplaced  int
+	Metrics   *AllocationMetric
+}
+
 // EvalIndexSort is a wrapper to sort evaluations by CreateIndex.
 // We reverse the test so that we get the highest index first.
 type EvalIndexSort []*Evaluation
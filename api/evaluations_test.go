@@ -1,10 +1,13 @@
 package api
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
 )
 
 func TestEvaluations_List(t *testing.T) {
@@ -142,6 +145,49 @@ func TestEvaluations_Allocations(t *testing.T) {
 	}
 }
 
+func TestEvaluations_PlacementFailures(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	e := c.Evaluations()
+
+	// Returns empty if nothing is blocked
+	failures, _, err := e.PlacementFailures(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n := len(failures); n != 0 {
+		t.Fatalf("expected 0 placement failures, got: %d", n)
+	}
+
+	// Register a job that can't be placed
+	job := testJob()
+	job.Datacenters = []string{"not-a-datacenter"}
+	_, wm, err := c.Jobs().Register(job, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	testutil.WaitForResult(func() (bool, error) {
+		failures, qm, err := e.PlacementFailures(nil)
+		if err != nil {
+			return false, err
+		}
+		assertQueryMeta(t, qm)
+		if len(failures) != 1 {
+			return false, fmt.Errorf("expected 1 placement failure, got: %d", len(failures))
+		}
+		f := failures[0]
+		if f.JobID != *job.ID || f.TaskGroup != "group1" || f.Unplaced != 1 || f.Metrics == nil {
+			return false, fmt.Errorf("bad placement failure: %#v", f)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+}
+
 func TestEvaluations_Sort(t *testing.T) {
 	t.Parallel()
 	evals := []*Evaluation{
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Topic is the topic of events in the event stream.
+type Topic string
+
+const (
+	TopicAll        Topic = "*"
+	TopicJob        Topic = "Job"
+	TopicAllocation Topic = "Allocation"
+	TopicNode       Topic = "Node"
+	TopicDeployment Topic = "Deployment"
+)
+
+// Events is the set of events created by applying a raft log.
+type Events struct {
+	Index  uint64
+	Events []Event
+}
+
+// IsHeartbeat returns whether the events are a heartbeat sent while no
+// events are published.
+func (e *Events) IsHeartbeat() bool {
+	return e.Index == 0 && len(e.Events) == 0
+}
+
+// Event is a change of an object in the state store.
+type Event struct {
+	Topic      Topic
+	Type       string
+	Key        string
+	FilterKeys []string
+	Namespace  string
+	Index      uint64
+	Payload    map[string]interface{}
+}
+
+// Job returns the job of a Job event.
+func (e *Event) Job() (*Job, error) {
+	var out Job
+	if err := e.decodePayload("Job", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Allocation returns the allocation of an Allocation event. The job of the
+// allocation is not set.
+func (e *Event) Allocation() (*Allocation, error) {
+	var out Allocation
+	if err := e.decodePayload("Allocation", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Node returns the node of a Node event.
+func (e *Event) Node() (*Node, error) {
+	var out Node
+	if err := e.decodePayload("Node", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Deployment returns the deployment of a Deployment event.
+func (e *Event) Deployment() (*Deployment, error) {
+	var out Deployment
+	if err := e.decodePayload("Deployment", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// decodePayload decodes the object stored under key in the payload into out.
+func (e *Event) decodePayload(key string, out interface{}) error {
+	obj, ok := e.Payload[key]
+	if !ok {
+		return fmt.Errorf("event of topic %q has no %s payload", e.Topic, key)
+	}
+
+	// Round trip the object to decode it into the typed struct
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// EventStream is used to stream the events of state changes.
+type EventStream struct {
+	client *Client
+}
+
+// EventStream returns a handle to the event stream.
+func (c *Client) EventStream() *EventStream {
+	return &EventStream{client: c}
+}
+
+// Stream subscribes to the events of the topics, each mapped to the keys of
+// the events to receive or "*" for all keys. If index is non-zero, events
+// after the index that are still buffered by the server are replayed first.
+// Heartbeats are discarded. The stream ends when cancel is closed.
+func (e *EventStream) Stream(topics map[Topic][]string, index uint64,
+	cancel <-chan struct{}, q *QueryOptions) (<-chan *Events, <-chan error) {
+
+	errCh := make(chan error, 1)
+
+	r, err := e.client.newRequest("GET", "/v1/event/stream")
+	if err != nil {
+		errCh <- err
+		return nil, errCh
+	}
+	r.setQueryOptions(q)
+	for topic, keys := range topics {
+		for _, key := range keys {
+			r.params.Add("topic", fmt.Sprintf("%s:%s", topic, key))
+		}
+	}
+	if index != 0 {
+		r.params.Set("index", strconv.FormatUint(index, 10))
+	}
+
+	_, resp, err := requireOK(e.client.doRequest(r))
+	if err != nil {
+		errCh <- err
+		return nil, errCh
+	}
+
+	// Close the body when cancelled to unblock the decoder
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-cancel:
+		case <-done:
+		}
+		resp.Body.Close()
+	}()
+
+	eventsCh := make(chan *Events, 10)
+	go func() {
+		defer close(done)
+		dec := json.NewDecoder(resp.Body)
+
+		for {
+			var events Events
+			if err := dec.Decode(&events); err != nil {
+				select {
+				case <-cancel:
+				default:
+					errCh <- err
+				}
+				close(eventsCh)
+				return
+			}
+
+			// Discard heartbeats
+			if events.IsHeartbeat() {
+				continue
+			}
+
+			select {
+			case eventsCh <- &events:
+			case <-cancel:
+				close(eventsCh)
+				return
+			}
+		}
+	}()
+
+	return eventsCh, errCh
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	// Register a job before subscribing so that it is replayed
+	job := testJob()
+	resp, _, err := c.Jobs().Register(job, nil)
+	require.NoError(err)
+	require.NotNil(resp)
+
+	cancel := make(chan struct{})
+	defer close(cancel)
+	topics := map[Topic][]string{TopicJob: {*job.ID}}
+	eventsCh, errCh := c.EventStream().Stream(topics, 1, cancel, nil)
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("err: %v", err)
+	case events := <-eventsCh:
+		require.Len(events.Events, 1)
+		event := events.Events[0]
+		require.Equal(TopicJob, event.Topic)
+		require.Equal(*job.ID, event.Key)
+
+		eventJob, err := event.Job()
+		require.NoError(err)
+		require.Equal(*job.ID, *eventJob.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for job event")
+	}
+}
+
+func TestEvent_DecodePayload(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	event := &Event{
+		Topic: TopicNode,
+		Payload: map[string]interface{}{
+			"Node": map[string]interface{}{"ID": "foo", "Name": "bar"},
+		},
+	}
+	node, err := event.Node()
+	require.NoError(err)
+	require.Equal("foo", node.ID)
+	require.Equal("bar", node.Name)
+
+	_, err = event.Job()
+	require.Error(err)
+}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"net"
 	"strconv"
@@ -25,6 +28,10 @@ type AllocFileInfo struct {
 	Size     int64
 	FileMode string
 	ModTime  time.Time
+
+	// Checksum is the checksum of the contents of the file, in the form
+	// "sha256:<hex>". It is only set by Checksum.
+	Checksum string `json:",omitempty"`
 }
 
 // StreamFrame is used to frame data of a file when streaming
@@ -159,6 +166,104 @@ func (a *AllocFS) Cat(alloc *Allocation, path string, q *QueryOptions) (io.ReadC
 	return r, nil
 }
 
+// Checksum is used to compute the checksum of the contents of a file at the
+// given path of an allocation directory. The checksum is in the form
+// "sha256:<hex>".
+func (a *AllocFS) Checksum(alloc *Allocation, path string, q *QueryOptions) (string, *QueryMeta, error) {
+	if q == nil {
+		q = &QueryOptions{}
+	}
+	if q.Params == nil {
+		q.Params = make(map[string]string)
+	}
+
+	q.Params["path"] = path
+	q.Params["checksum"] = "true"
+
+	var resp AllocFileInfo
+	qm, err := a.client.query(fmt.Sprintf("/v1/client/fs/stat/%s", alloc.ID), &resp, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Checksum, qm, nil
+}
+
+// CatVerified is used to read the contents of a file at the given path in an
+// allocation directory, verifying them against the checksum of the file. The
+// returned reader returns an error instead of io.EOF if the contents don't
+// match the checksum, such as when the file is modified while being read.
+func (a *AllocFS) CatVerified(alloc *Allocation, path string, q *QueryOptions) (io.ReadCloser, error) {
+	checksum, _, err := a.Checksum(alloc, path, q)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := a.Cat(alloc, path, q)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumReader{r: r, h: sha256.New(), checksum: checksum}, nil
+}
+
+// checksumReader verifies the checksum of the contents of a reader once they
+// have been read.
+type checksumReader struct {
+	r        io.ReadCloser
+	h        hash.Hash
+	checksum string
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.h.Write(p[:n])
+	if err == io.EOF {
+		if actual := "sha256:" + hex.EncodeToString(c.h.Sum(nil)); actual != c.checksum {
+			return n, fmt.Errorf("checksum mismatch: expected %s, got %s", c.checksum, actual)
+		}
+	}
+	return n, err
+}
+
+func (c *checksumReader) Close() error {
+	return c.r.Close()
+}
+
+// Archive is used to read a tar archive of the file or directory at the given
+// path in an allocation directory, recursing into directories. The entries of
+// the archive are named relative to the allocation directory and the secrets
+// directories of the tasks are not included.
+func (a *AllocFS) Archive(alloc *Allocation, path string, q *QueryOptions) (io.ReadCloser, error) {
+	nodeClient, err := a.client.GetNodeClientWithTimeout(alloc.NodeID, ClientConnTimeout, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if q == nil {
+		q = &QueryOptions{}
+	}
+	if q.Params == nil {
+		q.Params = make(map[string]string)
+	}
+
+	q.Params["path"] = path
+	reqPath := fmt.Sprintf("/v1/client/fs/archive/%s", alloc.ID)
+	r, err := nodeClient.rawQuery(reqPath, q)
+	if err != nil {
+		// There was a networking error when talking directly to the client.
+		if _, ok := err.(net.Error); !ok {
+			return nil, err
+		}
+
+		// Try via the server
+		r, err = a.client.rawQuery(reqPath, q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
 // Stream streams the content of a file blocking on EOF.
 // The parameters are:
 // * path: path to file to stream.
@@ -2,8 +2,11 @@ package api
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"reflect"
 	"strings"
 	"testing"
@@ -289,3 +292,24 @@ func TestFS_FrameReader_Error(t *testing.T) {
 		t.Fatalf("bad error: %v", err)
 	}
 }
+
+func TestFS_ChecksumReader(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	contents := "Hello from the other side"
+	sum := sha256.Sum256([]byte(contents))
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	// Matching contents are read until EOF
+	r := &checksumReader{r: ioutil.NopCloser(strings.NewReader(contents)), h: sha256.New(), checksum: checksum}
+	out, err := ioutil.ReadAll(r)
+	require.NoError(err)
+	require.Equal(contents, string(out))
+
+	// Mismatching contents return an error at EOF
+	r = &checksumReader{r: ioutil.NopCloser(strings.NewReader(contents + "!")), h: sha256.New(), checksum: checksum}
+	_, err = ioutil.ReadAll(r)
+	require.Error(err)
+	require.Contains(err.Error(), "checksum mismatch")
+}
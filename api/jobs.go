@@ -119,6 +119,25 @@ func (j *Jobs) RegisterOpts(job *Job, opts *RegisterOptions, q *WriteOptions) (*
 	return &resp, wm, nil
 }
 
+// BatchRegister is used to register a set of jobs atomically. Either all of
+// the jobs are registered or none are, and the registration fails if the
+// modify index of any job that enforces it doesn't match.
+func (j *Jobs) BatchRegister(jobs []*JobBatchRegisterEntry, opts *RegisterOptions, q *WriteOptions) (*JobBatchRegisterResponse, *WriteMeta, error) {
+	req := &JobBatchRegisterRequest{
+		Jobs: jobs,
+	}
+	if opts != nil && opts.PolicyOverride {
+		req.PolicyOverride = true
+	}
+
+	var resp JobBatchRegisterResponse
+	wm, err := j.client.write("/v1/jobs/batch", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
 // List is used to list all of the existing jobs.
 func (j *Jobs) List(q *QueryOptions) ([]*JobListStub, *QueryMeta, error) {
 	var resp []*JobListStub
@@ -260,6 +279,18 @@ func (j *Jobs) PeriodicForce(jobID string, q *WriteOptions) (string, *WriteMeta,
 	return resp.EvalID, wm, nil
 }
 
+// PeriodicHistory returns the recent launches of the periodic job, from
+// oldest to newest, including launches skipped or queued because of its
+// overlap policy.
+func (j *Jobs) PeriodicHistory(jobID string, q *QueryOptions) ([]*PeriodicLaunchEvent, *QueryMeta, error) {
+	var resp []*PeriodicLaunchEvent
+	qm, err := j.client.query("/v1/job/"+jobID+"/periodic/history", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
 // PlanOptions is used to pass through job planning parameters
 type PlanOptions struct {
 	Diff           bool
@@ -317,6 +348,35 @@ func (j *Jobs) Dispatch(jobID string, meta map[string]string,
 	return &resp, wm, nil
 }
 
+// DispatchArray is used to dispatch count indexed child jobs of a
+// parameterized job. The children share the passed meta data and payload.
+func (j *Jobs) DispatchArray(jobID string, count int, meta map[string]string,
+	payload []byte, q *WriteOptions) (*JobDispatchResponse, *WriteMeta, error) {
+	var resp JobDispatchResponse
+	req := &JobDispatchRequest{
+		JobID:   jobID,
+		Meta:    meta,
+		Payload: payload,
+		Count:   count,
+	}
+	wm, err := j.client.write("/v1/job/"+jobID+"/dispatch", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// DispatchArrayStatus is used to retrieve the aggregate status of the child
+// jobs dispatched as an array.
+func (j *Jobs) DispatchArrayStatus(jobID, arrayID string, q *QueryOptions) (*DispatchArraySummary, *QueryMeta, error) {
+	var resp DispatchArraySummary
+	qm, err := j.client.query("/v1/job/"+jobID+"/dispatch-array?array_id="+arrayID, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // Revert is used to revert the given job to the passed version. If
 // enforceVersion is set, the job is only reverted if the current version is at
 // the passed version.
@@ -353,6 +413,97 @@ func (j *Jobs) Stable(jobID string, version uint64, stable bool,
 	return &resp, wm, nil
 }
 
+// VersionDiff is used to diff two versions of a job, selected by their number
+// or tag name. The current version is used if the version to diff to is empty.
+func (j *Jobs) VersionDiff(jobID, from, to string, q *QueryOptions) (*JobVersionDiffResponse, *QueryMeta, error) {
+	var resp JobVersionDiffResponse
+	u, err := url.Parse("/v1/job/" + jobID + "/versions/diff")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := u.Query()
+	v.Add("from", from)
+	if to != "" {
+		v.Add("to", to)
+	}
+	u.RawQuery = v.Encode()
+
+	qm, err := j.client.query(u.String(), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// TagVersion is used to tag a job version. A tag name may only be applied to
+// one version of the job, and tagged versions are not garbage collected.
+func (j *Jobs) TagVersion(jobID string, version uint64, name, description string,
+	q *WriteOptions) (*JobVersionTagResponse, *WriteMeta, error) {
+
+	var resp JobVersionTagResponse
+	req := &JobVersionTagRequest{
+		JobID:      jobID,
+		JobVersion: version,
+		Tag: &JobVersionTag{
+			Name:        name,
+			Description: description,
+		},
+	}
+	wm, err := j.client.write("/v1/job/"+jobID+"/tag", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// UntagVersion is used to remove the tag with the given name from the job
+// version that has it.
+func (j *Jobs) UntagVersion(jobID, name string, q *WriteOptions) (*JobVersionTagResponse, *WriteMeta, error) {
+	var resp JobVersionTagResponse
+	wm, err := j.client.delete("/v1/job/"+jobID+"/tag?name="+url.QueryEscape(name), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// Scale is used to set the count of a task group of a job, giving the reason
+// in the message. If count is nil, only a scaling event is recorded, such as
+// an error of an external autoscaler when isError is set.
+func (j *Jobs) Scale(jobID, group string, count *int, message string, isError bool,
+	meta map[string]string, q *WriteOptions) (*JobRegisterResponse, *WriteMeta, error) {
+
+	req := &ScalingRequest{
+		Group:   group,
+		Message: message,
+		Error:   isError,
+		Meta:    meta,
+	}
+	if count != nil {
+		c := int64(*count)
+		req.Count = &c
+	}
+
+	var resp JobRegisterResponse
+	wm, err := j.client.write("/v1/job/"+jobID+"/scale", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// ScaleStatus is used to get the counts and recent scaling events of the task
+// groups of a job.
+func (j *Jobs) ScaleStatus(jobID string, q *QueryOptions) (*JobScaleStatus, *QueryMeta, error) {
+	var resp JobScaleStatus
+	qm, err := j.client.query("/v1/job/"+jobID+"/scale", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // periodicForceResponse is used to deserialize a force response
 type periodicForceResponse struct {
 	EvalID string
@@ -360,28 +511,32 @@ type periodicForceResponse struct {
 
 // UpdateStrategy defines a task groups update strategy.
 type UpdateStrategy struct {
-	Stagger          *time.Duration `mapstructure:"stagger"`
-	MaxParallel      *int           `mapstructure:"max_parallel"`
-	HealthCheck      *string        `mapstructure:"health_check"`
-	MinHealthyTime   *time.Duration `mapstructure:"min_healthy_time"`
-	HealthyDeadline  *time.Duration `mapstructure:"healthy_deadline"`
-	ProgressDeadline *time.Duration `mapstructure:"progress_deadline"`
-	AutoRevert       *bool          `mapstructure:"auto_revert"`
-	Canary           *int           `mapstructure:"canary"`
+	Stagger                 *time.Duration `mapstructure:"stagger"`
+	MaxParallel             *int           `mapstructure:"max_parallel"`
+	HealthCheck             *string        `mapstructure:"health_check"`
+	MinHealthyTime          *time.Duration `mapstructure:"min_healthy_time"`
+	HealthyDeadline         *time.Duration `mapstructure:"healthy_deadline"`
+	ProgressDeadline        *time.Duration `mapstructure:"progress_deadline"`
+	AutoRevert              *bool          `mapstructure:"auto_revert"`
+	AutoPromote             *bool          `mapstructure:"auto_promote"`
+	ManualPromotionPerGroup *bool          `mapstructure:"manual_promotion_per_group"`
+	Canary                  *int           `mapstructure:"canary"`
 }
 
 // DefaultUpdateStrategy provides a baseline that can be used to upgrade
 // jobs with the old policy or for populating field defaults.
 func DefaultUpdateStrategy() *UpdateStrategy {
 	return &UpdateStrategy{
-		Stagger:          timeToPtr(30 * time.Second),
-		MaxParallel:      intToPtr(1),
-		HealthCheck:      stringToPtr("checks"),
-		MinHealthyTime:   timeToPtr(10 * time.Second),
-		HealthyDeadline:  timeToPtr(5 * time.Minute),
-		ProgressDeadline: timeToPtr(10 * time.Minute),
-		AutoRevert:       boolToPtr(false),
-		Canary:           intToPtr(0),
+		Stagger:                 timeToPtr(30 * time.Second),
+		MaxParallel:             intToPtr(1),
+		HealthCheck:             stringToPtr("checks"),
+		MinHealthyTime:          timeToPtr(10 * time.Second),
+		HealthyDeadline:         timeToPtr(5 * time.Minute),
+		ProgressDeadline:        timeToPtr(10 * time.Minute),
+		AutoRevert:              boolToPtr(false),
+		AutoPromote:             boolToPtr(false),
+		ManualPromotionPerGroup: boolToPtr(false),
+		Canary:                  intToPtr(0),
 	}
 }
 
@@ -420,6 +575,14 @@ func (u *UpdateStrategy) Copy() *UpdateStrategy {
 		copy.AutoRevert = boolToPtr(*u.AutoRevert)
 	}
 
+	if u.AutoPromote != nil {
+		copy.AutoPromote = boolToPtr(*u.AutoPromote)
+	}
+
+	if u.ManualPromotionPerGroup != nil {
+		copy.ManualPromotionPerGroup = boolToPtr(*u.ManualPromotionPerGroup)
+	}
+
 	if u.Canary != nil {
 		copy.Canary = intToPtr(*u.Canary)
 	}
@@ -460,6 +623,14 @@ func (u *UpdateStrategy) Merge(o *UpdateStrategy) {
 		u.AutoRevert = boolToPtr(*o.AutoRevert)
 	}
 
+	if o.AutoPromote != nil {
+		u.AutoPromote = boolToPtr(*o.AutoPromote)
+	}
+
+	if o.ManualPromotionPerGroup != nil {
+		u.ManualPromotionPerGroup = boolToPtr(*o.ManualPromotionPerGroup)
+	}
+
 	if o.Canary != nil {
 		u.Canary = intToPtr(*o.Canary)
 	}
@@ -496,6 +667,14 @@ func (u *UpdateStrategy) Canonicalize() {
 		u.AutoRevert = d.AutoRevert
 	}
 
+	if u.AutoPromote == nil {
+		u.AutoPromote = d.AutoPromote
+	}
+
+	if u.ManualPromotionPerGroup == nil {
+		u.ManualPromotionPerGroup = d.ManualPromotionPerGroup
+	}
+
 	if u.Canary == nil {
 		u.Canary = d.Canary
 	}
@@ -535,6 +714,14 @@ func (u *UpdateStrategy) Empty() bool {
 		return false
 	}
 
+	if u.AutoPromote != nil && *u.AutoPromote {
+		return false
+	}
+
+	if u.ManualPromotionPerGroup != nil && *u.ManualPromotionPerGroup {
+		return false
+	}
+
 	if u.Canary != nil && *u.Canary != 0 {
 		return false
 	}
@@ -542,12 +729,32 @@ func (u *UpdateStrategy) Empty() bool {
 	return true
 }
 
+const (
+	// PeriodicOverlapSkip, PeriodicOverlapCancelRunning and
+	// PeriodicOverlapEnqueue are the overlap policies of periodic jobs.
+	PeriodicOverlapSkip          = "skip"
+	PeriodicOverlapCancelRunning = "cancel_running"
+	PeriodicOverlapEnqueue       = "enqueue"
+)
+
+// PeriodicLaunchEvent records a launch, or a skipped launch, of a periodic
+// job.
+type PeriodicLaunchEvent struct {
+	Type          string
+	LaunchTime    time.Time
+	ChildJobID    string
+	EvalID        string
+	StoppedJobIDs []string
+	Message       string
+}
+
 // PeriodicConfig is for serializing periodic config for a job.
 type PeriodicConfig struct {
 	Enabled         *bool
 	Spec            *string
 	SpecType        *string
 	ProhibitOverlap *bool   `mapstructure:"prohibit_overlap"`
+	OverlapPolicy   *string `mapstructure:"overlap_policy"`
 	TimeZone        *string `mapstructure:"time_zone"`
 }
 
@@ -564,6 +771,9 @@ func (p *PeriodicConfig) Canonicalize() {
 	if p.ProhibitOverlap == nil {
 		p.ProhibitOverlap = boolToPtr(false)
 	}
+	if p.OverlapPolicy == nil {
+		p.OverlapPolicy = stringToPtr("")
+	}
 	if p.TimeZone == nil || *p.TimeZone == "" {
 		p.TimeZone = stringToPtr("UTC")
 	}
@@ -607,9 +817,50 @@ func (p *PeriodicConfig) GetLocation() (*time.Location, error) {
 
 // ParameterizedJobConfig is used to configure the parameterized job.
 type ParameterizedJobConfig struct {
-	Payload      string
-	MetaRequired []string `mapstructure:"meta_required"`
-	MetaOptional []string `mapstructure:"meta_optional"`
+	Payload       string
+	MetaRequired  []string          `mapstructure:"meta_required"`
+	MetaOptional  []string          `mapstructure:"meta_optional"`
+	MetaTypes     map[string]string `mapstructure:"meta_types"`
+	PayloadSchema string            `mapstructure:"payload_schema"`
+}
+
+// Multiregion is used to register a job in multiple regions and to stage its
+// deployments across them.
+type Multiregion struct {
+	Strategy *MultiregionStrategy
+	Regions  []*MultiregionRegion
+}
+
+func (m *Multiregion) Canonicalize() {
+	if m.Strategy == nil {
+		m.Strategy = &MultiregionStrategy{}
+	}
+	if m.Strategy.MaxParallel == nil {
+		m.Strategy.MaxParallel = intToPtr(0)
+	}
+	if m.Strategy.OnFailure == nil {
+		m.Strategy.OnFailure = stringToPtr("")
+	}
+	for _, region := range m.Regions {
+		if region.Count == nil {
+			region.Count = intToPtr(0)
+		}
+	}
+}
+
+// MultiregionStrategy configures how deployments are staged across regions.
+type MultiregionStrategy struct {
+	MaxParallel *int    `mapstructure:"max_parallel"`
+	OnFailure   *string `mapstructure:"on_failure"`
+}
+
+// MultiregionRegion is a region of a multiregion job and the overrides of the
+// job in that region.
+type MultiregionRegion struct {
+	Name        string
+	Count       *int
+	Datacenters []string
+	Meta        map[string]string
 }
 
 // Job is used to serialize a job.
@@ -628,6 +879,7 @@ type Job struct {
 	Affinities        []*Affinity
 	TaskGroups        []*TaskGroup
 	Update            *UpdateStrategy
+	Multiregion       *Multiregion
 	Spreads           []*Spread
 	Periodic          *PeriodicConfig
 	ParameterizedJob  *ParameterizedJobConfig
@@ -641,6 +893,7 @@ type Job struct {
 	StatusDescription *string
 	Stable            *bool
 	Version           *uint64
+	VersionTag        *JobVersionTag
 	SubmitTime        *int64
 	CreateIndex       *uint64
 	ModifyIndex       *uint64
@@ -657,6 +910,11 @@ func (j *Job) IsParameterized() bool {
 	return j.ParameterizedJob != nil && !j.Dispatched
 }
 
+// IsMultiregion returns whether a job is registered in multiple regions.
+func (j *Job) IsMultiregion() bool {
+	return j.Multiregion != nil && len(j.Multiregion.Regions) != 0
+}
+
 func (j *Job) Canonicalize() {
 	if j.ID == nil {
 		j.ID = stringToPtr("")
@@ -718,6 +976,9 @@ func (j *Job) Canonicalize() {
 	if j.Update != nil {
 		j.Update.Canonicalize()
 	}
+	if j.Multiregion != nil {
+		j.Multiregion.Canonicalize()
+	}
 
 	for _, tg := range j.TaskGroups {
 		tg.Canonicalize(j)
@@ -967,6 +1228,47 @@ type JobRegisterResponse struct {
 	QueryMeta
 }
 
+// JobBatchRegisterEntry is a job registered as part of a batch.
+type JobBatchRegisterEntry struct {
+	Job *Job
+
+	// If EnforceIndex is set then the batch will only be registered if the
+	// passed JobModifyIndex matches the current Jobs index. If the index is
+	// zero, the batch is only registered if the job is new.
+	EnforceIndex   bool   `json:",omitempty"`
+	JobModifyIndex uint64 `json:",omitempty"`
+}
+
+// JobBatchRegisterRequest is used to serialize a batch job registration
+type JobBatchRegisterRequest struct {
+	Jobs           []*JobBatchRegisterEntry
+	PolicyOverride bool `json:",omitempty"`
+
+	WriteRequest
+}
+
+// JobBatchRegisterResponse is used to respond to a batch job registration
+type JobBatchRegisterResponse struct {
+	// Jobs is the result of the registration of each job, in the order of
+	// the request
+	Jobs []*JobBatchRegisterResult
+
+	EvalCreateIndex uint64
+	QueryMeta
+}
+
+// JobBatchRegisterResult is the result of registering a job as part of a
+// batch.
+type JobBatchRegisterResult struct {
+	JobID          string
+	EvalID         string
+	JobModifyIndex uint64
+
+	// Warnings contains any warnings about the given job. These may include
+	// deprecation warnings.
+	Warnings string
+}
+
 // JobDeregisterResponse is used to respond to a job deregistration
 type JobDeregisterResponse struct {
 	EvalID          string
@@ -1054,16 +1356,32 @@ type JobDispatchRequest struct {
 	JobID   string
 	Payload []byte
 	Meta    map[string]string
+	Count   int
 }
 
 type JobDispatchResponse struct {
-	DispatchedJobID string
-	EvalID          string
-	EvalCreateIndex uint64
-	JobCreateIndex  uint64
+	DispatchedJobID  string
+	EvalID           string
+	EvalCreateIndex  uint64
+	JobCreateIndex   uint64
+	ArrayID          string
+	DispatchedJobIDs []string
+	EvalIDs          []string
 	WriteMeta
 }
 
+// DispatchArraySummary is the aggregate status of the child jobs dispatched
+// as a single array.
+type DispatchArraySummary struct {
+	JobID   string
+	ArrayID string
+	Count   int
+	Pending int
+	Running int
+	Dead    int
+	JobIDs  []string
+}
+
 // JobVersionsResponse is used for a job get versions request
 type JobVersionsResponse struct {
 	Versions []*Job
@@ -1088,6 +1406,34 @@ type JobStabilityResponse struct {
 	WriteMeta
 }
 
+// JobVersionTag names a version of a job.
+type JobVersionTag struct {
+	Name        string
+	Description string
+	TaggedTime  int64
+}
+
+// JobVersionTagRequest is used to tag a job version.
+type JobVersionTagRequest struct {
+	JobID      string
+	JobVersion uint64
+	Tag        *JobVersionTag
+	WriteRequest
+}
+
+// JobVersionTagResponse is the response when tagging a job version.
+type JobVersionTagResponse struct {
+	WriteMeta
+}
+
+// JobVersionDiffResponse is used for a job version diff request
+type JobVersionDiffResponse struct {
+	FromVersion uint64
+	ToVersion   uint64
+	Diff        *JobDiff
+	QueryMeta
+}
+
 // JobEvaluateRequest is used when we just need to re-evaluate a target job
 type JobEvaluateRequest struct {
 	JobID       string
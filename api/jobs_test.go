@@ -327,6 +327,7 @@ func TestJobs_Canonicalize(t *testing.T) {
 										DestPath:     stringToPtr("local/file.env"),
 										Envvars:      boolToPtr(true),
 										VaultGrace:   timeToPtr(3 * time.Second),
+										Sandbox:      boolToPtr(false),
 									},
 								},
 							},
@@ -354,14 +355,16 @@ func TestJobs_Canonicalize(t *testing.T) {
 				JobModifyIndex:    uint64ToPtr(0),
 				Datacenters:       []string{"dc1"},
 				Update: &UpdateStrategy{
-					Stagger:          timeToPtr(30 * time.Second),
-					MaxParallel:      intToPtr(1),
-					HealthCheck:      stringToPtr("checks"),
-					MinHealthyTime:   timeToPtr(10 * time.Second),
-					HealthyDeadline:  timeToPtr(5 * time.Minute),
-					ProgressDeadline: timeToPtr(10 * time.Minute),
-					AutoRevert:       boolToPtr(false),
-					Canary:           intToPtr(0),
+					Stagger:                 timeToPtr(30 * time.Second),
+					MaxParallel:             intToPtr(1),
+					HealthCheck:             stringToPtr("checks"),
+					MinHealthyTime:          timeToPtr(10 * time.Second),
+					HealthyDeadline:         timeToPtr(5 * time.Minute),
+					ProgressDeadline:        timeToPtr(10 * time.Minute),
+					AutoRevert:              boolToPtr(false),
+					AutoPromote:             boolToPtr(false),
+					ManualPromotionPerGroup: boolToPtr(false),
+					Canary:                  intToPtr(0),
 				},
 				TaskGroups: []*TaskGroup{
 					{
@@ -388,14 +391,16 @@ func TestJobs_Canonicalize(t *testing.T) {
 						},
 
 						Update: &UpdateStrategy{
-							Stagger:          timeToPtr(30 * time.Second),
-							MaxParallel:      intToPtr(1),
-							HealthCheck:      stringToPtr("checks"),
-							MinHealthyTime:   timeToPtr(10 * time.Second),
-							HealthyDeadline:  timeToPtr(5 * time.Minute),
-							ProgressDeadline: timeToPtr(10 * time.Minute),
-							AutoRevert:       boolToPtr(false),
-							Canary:           intToPtr(0),
+							Stagger:                 timeToPtr(30 * time.Second),
+							MaxParallel:             intToPtr(1),
+							HealthCheck:             stringToPtr("checks"),
+							MinHealthyTime:          timeToPtr(10 * time.Second),
+							HealthyDeadline:         timeToPtr(5 * time.Minute),
+							ProgressDeadline:        timeToPtr(10 * time.Minute),
+							AutoRevert:              boolToPtr(false),
+							AutoPromote:             boolToPtr(false),
+							ManualPromotionPerGroup: boolToPtr(false),
+							Canary:                  intToPtr(0),
 						},
 						Migrate: DefaultMigrateStrategy(),
 						Tasks: []*Task{
@@ -454,6 +459,7 @@ func TestJobs_Canonicalize(t *testing.T) {
 										RightDelim:   stringToPtr("}}"),
 										Envvars:      boolToPtr(false),
 										VaultGrace:   timeToPtr(15 * time.Second),
+										Sandbox:      boolToPtr(false),
 									},
 									{
 										SourcePath:   stringToPtr(""),
@@ -467,6 +473,7 @@ func TestJobs_Canonicalize(t *testing.T) {
 										RightDelim:   stringToPtr("}}"),
 										Envvars:      boolToPtr(true),
 										VaultGrace:   timeToPtr(3 * time.Second),
+										Sandbox:      boolToPtr(false),
 									},
 								},
 							},
@@ -505,6 +512,7 @@ func TestJobs_Canonicalize(t *testing.T) {
 					Spec:            stringToPtr(""),
 					SpecType:        stringToPtr(PeriodicSpecCron),
 					ProhibitOverlap: boolToPtr(false),
+					OverlapPolicy:   stringToPtr(""),
 					TimeZone:        stringToPtr("UTC"),
 				},
 			},
@@ -572,14 +580,16 @@ func TestJobs_Canonicalize(t *testing.T) {
 				ModifyIndex:       uint64ToPtr(0),
 				JobModifyIndex:    uint64ToPtr(0),
 				Update: &UpdateStrategy{
-					Stagger:          timeToPtr(1 * time.Second),
-					MaxParallel:      intToPtr(1),
-					HealthCheck:      stringToPtr("checks"),
-					MinHealthyTime:   timeToPtr(10 * time.Second),
-					HealthyDeadline:  timeToPtr(6 * time.Minute),
-					ProgressDeadline: timeToPtr(7 * time.Minute),
-					AutoRevert:       boolToPtr(false),
-					Canary:           intToPtr(0),
+					Stagger:                 timeToPtr(1 * time.Second),
+					MaxParallel:             intToPtr(1),
+					HealthCheck:             stringToPtr("checks"),
+					MinHealthyTime:          timeToPtr(10 * time.Second),
+					HealthyDeadline:         timeToPtr(6 * time.Minute),
+					ProgressDeadline:        timeToPtr(7 * time.Minute),
+					AutoRevert:              boolToPtr(false),
+					AutoPromote:             boolToPtr(false),
+					ManualPromotionPerGroup: boolToPtr(false),
+					Canary:                  intToPtr(0),
 				},
 				TaskGroups: []*TaskGroup{
 					{
@@ -605,14 +615,16 @@ func TestJobs_Canonicalize(t *testing.T) {
 							Unlimited:     boolToPtr(true),
 						},
 						Update: &UpdateStrategy{
-							Stagger:          timeToPtr(2 * time.Second),
-							MaxParallel:      intToPtr(2),
-							HealthCheck:      stringToPtr("manual"),
-							MinHealthyTime:   timeToPtr(1 * time.Second),
-							HealthyDeadline:  timeToPtr(6 * time.Minute),
-							ProgressDeadline: timeToPtr(7 * time.Minute),
-							AutoRevert:       boolToPtr(true),
-							Canary:           intToPtr(1),
+							Stagger:                 timeToPtr(2 * time.Second),
+							MaxParallel:             intToPtr(2),
+							HealthCheck:             stringToPtr("manual"),
+							MinHealthyTime:          timeToPtr(1 * time.Second),
+							HealthyDeadline:         timeToPtr(6 * time.Minute),
+							ProgressDeadline:        timeToPtr(7 * time.Minute),
+							AutoRevert:              boolToPtr(true),
+							AutoPromote:             boolToPtr(false),
+							ManualPromotionPerGroup: boolToPtr(false),
+							Canary:                  intToPtr(1),
 						},
 						Migrate: DefaultMigrateStrategy(),
 						Tasks: []*Task{
@@ -647,14 +659,16 @@ func TestJobs_Canonicalize(t *testing.T) {
 							Unlimited:     boolToPtr(true),
 						},
 						Update: &UpdateStrategy{
-							Stagger:          timeToPtr(1 * time.Second),
-							MaxParallel:      intToPtr(1),
-							HealthCheck:      stringToPtr("checks"),
-							MinHealthyTime:   timeToPtr(10 * time.Second),
-							HealthyDeadline:  timeToPtr(6 * time.Minute),
-							ProgressDeadline: timeToPtr(7 * time.Minute),
-							AutoRevert:       boolToPtr(false),
-							Canary:           intToPtr(0),
+							Stagger:                 timeToPtr(1 * time.Second),
+							MaxParallel:             intToPtr(1),
+							HealthCheck:             stringToPtr("checks"),
+							MinHealthyTime:          timeToPtr(10 * time.Second),
+							HealthyDeadline:         timeToPtr(6 * time.Minute),
+							ProgressDeadline:        timeToPtr(7 * time.Minute),
+							AutoRevert:              boolToPtr(false),
+							AutoPromote:             boolToPtr(false),
+							ManualPromotionPerGroup: boolToPtr(false),
+							Canary:                  intToPtr(0),
 						},
 						Migrate: DefaultMigrateStrategy(),
 						Tasks: []*Task{
@@ -727,6 +741,50 @@ func TestJobs_EnforceRegister(t *testing.T) {
 	assertWriteMeta(t, wm)
 }
 
+func TestJobs_BatchRegister(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	job1 := testJob()
+	job2 := testJob()
+	job2.ID = stringToPtr("job2")
+	job2.Name = stringToPtr("job2")
+
+	// A stale index fails the whole batch
+	_, _, err := jobs.BatchRegister([]*JobBatchRegisterEntry{
+		{Job: job1},
+		{Job: job2, EnforceIndex: true, JobModifyIndex: 10},
+	}, nil, nil)
+	require.NotNil(err)
+	require.Contains(err.Error(), RegisterEnforceIndexErrPrefix)
+
+	resp, _, err := jobs.List(nil)
+	require.Nil(err)
+	require.Empty(resp)
+
+	// Register both jobs, enforcing that they are new
+	resp2, wm, err := jobs.BatchRegister([]*JobBatchRegisterEntry{
+		{Job: job1, EnforceIndex: true},
+		{Job: job2, EnforceIndex: true},
+	}, nil, nil)
+	require.Nil(err)
+	require.NotNil(resp2)
+	require.Len(resp2.Jobs, 2)
+	assertWriteMeta(t, wm)
+	for i, id := range []string{*job1.ID, *job2.ID} {
+		require.Equal(id, resp2.Jobs[i].JobID)
+		require.NotZero(resp2.Jobs[i].EvalID)
+		require.Equal(resp2.EvalCreateIndex, resp2.Jobs[i].JobModifyIndex)
+	}
+
+	resp, _, err = jobs.List(nil)
+	require.Nil(err)
+	require.Len(resp, 2)
+}
+
 func TestJobs_Revert(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
@@ -844,6 +902,94 @@ func TestJobs_Versions(t *testing.T) {
 	}
 }
 
+func TestJobs_TagVersion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Register the job twice
+	job := testJob()
+	_, _, err := jobs.Register(job, nil)
+	require.NoError(err)
+	job.Meta = map[string]string{"version": "1"}
+	_, _, err = jobs.Register(job, nil)
+	require.NoError(err)
+
+	// Tag the first version
+	_, wm, err := jobs.TagVersion(*job.ID, 0, "golden", "known good", nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	versions, _, _, err := jobs.Versions(*job.ID, false, nil)
+	require.NoError(err)
+	require.Len(versions, 2)
+	require.Nil(versions[0].VersionTag)
+	require.NotNil(versions[1].VersionTag)
+	require.Equal("golden", versions[1].VersionTag.Name)
+	require.Equal("known good", versions[1].VersionTag.Description)
+
+	// Diff the tagged version with the current version
+	diff, qm, err := jobs.VersionDiff(*job.ID, "golden", "", nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.EqualValues(0, diff.FromVersion)
+	require.EqualValues(1, diff.ToVersion)
+	require.Equal("Edited", diff.Diff.Type)
+
+	// Remove the tag
+	_, wm, err = jobs.UntagVersion(*job.ID, "golden", nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	_, _, err = jobs.VersionDiff(*job.ID, "golden", "", nil)
+	require.Error(err)
+}
+
+func TestJobs_Scale(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	job := testJob()
+	_, _, err := jobs.Register(job, nil)
+	require.NoError(err)
+
+	// Scale the group
+	resp, wm, err := jobs.Scale(*job.ID, "group1", intToPtr(3), "more capacity", false,
+		map[string]string{"source": "test"}, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+	require.NotEmpty(resp.EvalID)
+
+	// Record an error event
+	_, _, err = jobs.Scale(*job.ID, "group1", nil, "metrics unavailable", true, nil, nil)
+	require.NoError(err)
+
+	status, qm, err := jobs.ScaleStatus(*job.ID, nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Equal(*job.ID, status.JobID)
+	require.Contains(status.TaskGroups, "group1")
+	group := status.TaskGroups["group1"]
+	require.Equal(3, group.Desired)
+	require.Len(group.Events, 2)
+	require.True(group.Events[0].Error)
+	require.Nil(group.Events[0].Count)
+	require.Equal("more capacity", group.Events[1].Message)
+	require.EqualValues(3, *group.Events[1].Count)
+	require.EqualValues(1, group.Events[1].PreviousCount)
+	require.Equal(resp.EvalID, group.Events[1].EvalID)
+	require.Equal(map[string]string{"source": "test"}, group.Events[1].Meta)
+
+	// Unknown groups fail
+	_, _, err = jobs.Scale(*job.ID, "unknown", intToPtr(3), "", false, nil, nil)
+	require.Error(err)
+}
+
 func TestJobs_PrefixList(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
@@ -1133,6 +1279,34 @@ func TestJobs_PeriodicForce(t *testing.T) {
 	t.Fatalf("evaluation %q missing", evalID)
 }
 
+func TestJobs_PeriodicHistory(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Reading the history of a nonexistent job fails
+	_, _, err := jobs.PeriodicHistory("job1", nil)
+	require.Error(err)
+	require.Contains(err.Error(), "not found")
+
+	// Create a new job and force launch it
+	job := testPeriodicJob()
+	_, _, err = jobs.Register(job, nil)
+	require.NoError(err)
+	evalID, _, err := jobs.PeriodicForce(*job.ID, nil)
+	require.NoError(err)
+
+	launches, qm, err := jobs.PeriodicHistory(*job.ID, nil)
+	require.NoError(err)
+	assertQueryMeta(t, qm)
+	require.Len(launches, 1)
+	require.Equal("forced", launches[0].Type)
+	require.Equal(evalID, launches[0].EvalID)
+	require.NotEmpty(launches[0].ChildJobID)
+}
+
 func TestJobs_Plan(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
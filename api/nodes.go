@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"sort"
 	"strconv"
 	"time"
@@ -381,6 +382,114 @@ func (n *Nodes) ToggleEligibility(nodeID string, eligible bool, q *WriteOptions)
 	return &resp, nil
 }
 
+// RegisterHostVolume is used to register a host volume on a node or update an
+// already registered volume of the same name.
+func (n *Nodes) RegisterHostVolume(nodeID string, volume *HostVolume, q *WriteOptions) (*WriteMeta, error) {
+	if volume == nil || volume.Name == "" {
+		return nil, fmt.Errorf("missing host volume name")
+	}
+	wm, err := n.client.write("/v1/node/"+nodeID+"/volume/"+volume.Name, volume, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// DeregisterHostVolume is used to deregister a host volume from a node.
+func (n *Nodes) DeregisterHostVolume(nodeID, name string, q *WriteOptions) (*WriteMeta, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing host volume name")
+	}
+	wm, err := n.client.delete("/v1/node/"+nodeID+"/volume/"+name, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// ScaleIn starts the scale-in of a node, which drains the node and keeps it
+// ineligible for scheduling until the scale-in is cancelled or completed. If
+// spec is nil, the node is drained without deadline and system jobs are left
+// running.
+func (n *Nodes) ScaleIn(nodeID string, spec *DrainSpec, message string, q *WriteOptions) (*NodeDrainUpdateResponse, error) {
+	req := &NodeScaleInRequest{
+		DrainSpec: spec,
+		Message:   message,
+	}
+
+	var resp NodeDrainUpdateResponse
+	wm, err := n.client.write("/v1/node/"+nodeID+"/scale-in", req, &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	resp.WriteMeta = *wm
+	return &resp, nil
+}
+
+// CancelScaleIn cancels the scale-in of a node, stopping its drain and
+// marking it eligible for scheduling.
+func (n *Nodes) CancelScaleIn(nodeID string, q *WriteOptions) (*NodeDrainUpdateResponse, error) {
+	var resp NodeDrainUpdateResponse
+	wm, err := n.client.delete("/v1/node/"+nodeID+"/scale-in", &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	resp.WriteMeta = *wm
+	return &resp, nil
+}
+
+// ScaleInStatus returns the progress of the scale-in of a node. The instance
+// of the node can be terminated once its status is ready.
+func (n *Nodes) ScaleInStatus(nodeID string, q *QueryOptions) (*NodeScaleInStatus, *QueryMeta, error) {
+	var resp NodeScaleInStatus
+	qm, err := n.client.query("/v1/node/"+nodeID+"/scale-in", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// CompleteScaleIn purges a node whose instance has been terminated. Unless
+// force is set, the node must have finished draining.
+func (n *Nodes) CompleteScaleIn(nodeID string, force bool, q *WriteOptions) (*NodeDrainUpdateResponse, error) {
+	endpoint := "/v1/node/" + nodeID + "/scale-in/complete"
+	if force {
+		endpoint += "?force=true"
+	}
+
+	var resp NodeDrainUpdateResponse
+	wm, err := n.client.write(endpoint, nil, &resp, q)
+	if err != nil {
+		return nil, err
+	}
+	resp.WriteMeta = *wm
+	return &resp, nil
+}
+
+// ScalingStatus returns the capacity of the node pools of the cluster, where
+// a pool is the nodes of a datacenter and node class. The datacenter and
+// node class filter the pools if set.
+func (n *Nodes) ScalingStatus(datacenter, nodeClass string, q *QueryOptions) ([]*NodePoolScalingStatus, *QueryMeta, error) {
+	v := url.Values{}
+	if datacenter != "" {
+		v.Set("datacenter", datacenter)
+	}
+	if nodeClass != "" {
+		v.Set("node_class", nodeClass)
+	}
+	endpoint := "/v1/nodes/scaling"
+	if len(v) != 0 {
+		endpoint += "?" + v.Encode()
+	}
+
+	var resp []*NodePoolScalingStatus
+	qm, err := n.client.query(endpoint, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
 // Allocations is used to return the allocations associated with a node.
 func (n *Nodes) Allocations(nodeID string, q *QueryOptions) ([]*Allocation, *QueryMeta, error) {
 	var resp []*Allocation
@@ -454,11 +563,15 @@ type Node struct {
 	Drain                 bool
 	DrainStrategy         *DrainStrategy
 	SchedulingEligibility string
+	ScaleIn               *NodeScaleIn
 	Status                string
 	StatusDescription     string
 	StatusUpdatedAt       int64
 	Events                []*NodeEvent
 	Drivers               map[string]*DriverInfo
+	HostVolumes           map[string]*HostVolume
+	CSIControllerPlugins  map[string]*CSIInfo
+	CSINodePlugins        map[string]*CSIInfo
 	CreateIndex           uint64
 	ModifyIndex           uint64
 }
@@ -560,6 +673,24 @@ const (
 	NodeEventSubsystemCluster   = "Cluster"
 )
 
+const (
+	HostVolumeAccessModeSingleWriter = "single-writer"
+	HostVolumeAccessModeMultiWriter  = "multi-writer"
+	HostVolumeAccessModeReadOnly     = "read-only"
+)
+
+// HostVolume is a directory on a node, registered by an operator, that can be
+// made available to tasks running on the node.
+type HostVolume struct {
+	Name        string
+	Path        string
+	CapacityMB  int64
+	AccessMode  string
+	Meta        map[string]string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
 // NodeEvent is a single unit representing a node’s state change
 type NodeEvent struct {
 	Message     string
@@ -709,6 +840,8 @@ type NodeListStub struct {
 	Status                string
 	StatusDescription     string
 	Drivers               map[string]*DriverInfo
+	NodeResources         *NodeResources         `json:",omitempty"`
+	ReservedResources     *NodeReservedResources `json:",omitempty"`
 	CreateIndex           uint64
 	ModifyIndex           uint64
 }
@@ -276,6 +276,60 @@ func TestNodes_ToggleEligibility(t *testing.T) {
 	}
 }
 
+func TestNodes_HostVolumes(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	nodes := c.Nodes()
+
+	// Wait for node registration and get the ID
+	var nodeID string
+	testutil.WaitForResult(func() (bool, error) {
+		out, _, err := nodes.List(nil)
+		if err != nil {
+			return false, err
+		}
+		if n := len(out); n != 1 {
+			return false, fmt.Errorf("expected 1 node, got: %d", n)
+		}
+		nodeID = out[0].ID
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	// Register a volume
+	volume := &HostVolume{
+		Name:       "data",
+		Path:       "/srv/data",
+		CapacityMB: 1024,
+		Meta:       map[string]string{"tier": "ssd"},
+	}
+	wm, err := nodes.RegisterHostVolume(nodeID, volume, nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	out, _, err := nodes.Info(nodeID, nil)
+	require.NoError(err)
+	require.Len(out.HostVolumes, 1)
+	require.Equal("/srv/data", out.HostVolumes["data"].Path)
+	require.EqualValues(1024, out.HostVolumes["data"].CapacityMB)
+	require.Equal(HostVolumeAccessModeSingleWriter, out.HostVolumes["data"].AccessMode)
+	require.Equal("ssd", out.HostVolumes["data"].Meta["tier"])
+
+	// Deregister it
+	wm, err = nodes.DeregisterHostVolume(nodeID, "data", nil)
+	require.NoError(err)
+	assertWriteMeta(t, wm)
+
+	out, _, err = nodes.Info(nodeID, nil)
+	require.NoError(err)
+	require.Empty(out.HostVolumes)
+}
+
 func TestNodes_Allocations(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
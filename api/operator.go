@@ -1,6 +1,13 @@
 package api
 
-import "strconv"
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
 
 // Operator can be used to perform low-level operator tasks for Nomad.
 type Operator struct {
@@ -174,3 +181,212 @@ func (op *Operator) SchedulerCASConfiguration(conf *SchedulerConfiguration, q *W
 
 	return &out, wm, nil
 }
+
+// GCConfiguration is the config for tuning garbage collection at runtime. A
+// zero threshold falls back to the value set in the server's agent config.
+type GCConfiguration struct {
+	// EvalGCThreshold is how old an evaluation and its allocations must be
+	// to be eligible for GC.
+	EvalGCThreshold time.Duration
+
+	// JobGCThreshold is how old a terminal job must be to be eligible for GC.
+	JobGCThreshold time.Duration
+
+	// DeploymentGCThreshold is how old a terminal deployment must be to be
+	// eligible for GC.
+	DeploymentGCThreshold time.Duration
+
+	// CreateIndex/ModifyIndex store the create/modify indexes of this configuration.
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// GCConfigurationResponse is the response object that wraps GCConfiguration
+type GCConfigurationResponse struct {
+	// GCConfig contains the GC config options
+	GCConfig *GCConfiguration
+
+	QueryMeta
+}
+
+// GCSetConfigurationResponse is the response object used when updating the
+// GC configuration
+type GCSetConfigurationResponse struct {
+	// Updated returns whether the config was actually updated
+	// Only set when the request uses CAS
+	Updated bool
+
+	WriteMeta
+}
+
+// GCGetConfiguration is used to query the GC thresholds set at runtime.
+func (op *Operator) GCGetConfiguration(q *QueryOptions) (*GCConfigurationResponse, *QueryMeta, error) {
+	var resp GCConfigurationResponse
+	qm, err := op.c.query("/v1/operator/gc/configuration", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// GCSetConfiguration is used to set the GC thresholds at runtime.
+func (op *Operator) GCSetConfiguration(conf *GCConfiguration, q *WriteOptions) (*GCSetConfigurationResponse, *WriteMeta, error) {
+	var out GCSetConfigurationResponse
+	wm, err := op.c.write("/v1/operator/gc/configuration", conf, &out, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
+
+// GCCASConfiguration is used to perform a Check-And-Set update on the GC
+// configuration. The ModifyIndex value will be respected. Returns true on
+// success or false on failures.
+func (op *Operator) GCCASConfiguration(conf *GCConfiguration, q *WriteOptions) (*GCSetConfigurationResponse, *WriteMeta, error) {
+	var out GCSetConfigurationResponse
+	wm, err := op.c.write("/v1/operator/gc/configuration?cas="+strconv.FormatUint(conf.ModifyIndex, 10), conf, &out, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
+// Snapshot is used to capture a snapshot of the state of the servers. The
+// returned reader must be closed by the caller once the archive is consumed.
+func (op *Operator) Snapshot(q *QueryOptions) (io.ReadCloser, error) {
+	return op.c.rawQuery("/v1/operator/snapshot", q)
+}
+
+// SnapshotRestore is used to restore the state of the servers from a snapshot
+// archive read from in.
+func (op *Operator) SnapshotRestore(in io.Reader, q *WriteOptions) (*WriteMeta, error) {
+	r, err := op.c.newRequest("PUT", "/v1/operator/snapshot")
+	if err != nil {
+		return nil, err
+	}
+	r.setWriteOptions(q)
+	r.body = in
+
+	_, resp, err := requireOK(op.c.doRequest(r))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	wm := &WriteMeta{}
+	if err := parseWriteMeta(resp, wm); err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// ClusterServerHealth is the health of a server in the Raft configuration,
+// combining its Raft, Serf and Autopilot state.
+type ClusterServerHealth struct {
+	// ID is the Raft ID of the server.
+	ID string
+
+	// Name is the node name of the server, or "(unknown)" if the server is
+	// not known to Serf.
+	Name string
+
+	// Address is the IP:port of the server, used for Raft communications.
+	Address string
+
+	// Version is the Nomad version of the server.
+	Version string
+
+	// RaftProtocol is the version of the Raft protocol spoken by this server.
+	RaftProtocol string
+
+	// SerfStatus is the status of the server in the gossip pool.
+	SerfStatus string
+
+	// Leader is true if this server is the current cluster leader.
+	Leader bool
+
+	// Voter is true if this server has a vote in the cluster.
+	Voter bool
+
+	// Healthy is whether the server is healthy. It is the Autopilot verdict
+	// if Autopilot is available and whether the server is alive otherwise.
+	Healthy bool
+
+	// LastContact, LastTerm, LastIndex and StableSince are reported by
+	// Autopilot and are only set if it is available.
+	LastContact time.Duration
+	LastTerm    uint64
+	LastIndex   uint64
+	StableSince time.Time
+}
+
+// ClusterHealth is the aggregated health of the servers of a region and of
+// the heartbeats of its nodes.
+type ClusterHealth struct {
+	// Healthy is true if all the servers are healthy.
+	Healthy bool
+
+	// FailureTolerance is the number of healthy servers that could be lost
+	// without an outage occurring.
+	FailureTolerance int
+
+	// Autopilot is true if the server health was determined by Autopilot,
+	// which requires all servers to use Raft protocol 3 or higher.
+	Autopilot bool
+
+	// Servers holds the health of each server in the Raft configuration.
+	Servers []*ClusterServerHealth
+
+	// Versions is the sorted set of Nomad versions run by the servers.
+	// VersionSkew is true if there is more than one.
+	Versions    []string
+	VersionSkew bool
+
+	// HeartbeatsTracked is the number of nodes whose heartbeats are tracked
+	// by the leader, HeartbeatsOverdue the number of those whose heartbeat
+	// is late and HeartbeatsDeferred the number of those that would have
+	// been marked down if the missed heartbeat grace hadn't deferred it.
+	HeartbeatsTracked  int
+	HeartbeatsOverdue  int
+	HeartbeatsDeferred int
+
+	// DownNodes is the number of nodes marked down.
+	DownNodes int
+}
+
+// ClusterHealth is used to query the aggregated health of the servers and of
+// the node heartbeats of the region, for use by monitoring and upgrade
+// tooling. The health is returned even if the servers are unhealthy, which
+// the endpoint signals with a 429 status code.
+func (op *Operator) ClusterHealth(q *QueryOptions) (*ClusterHealth, *QueryMeta, error) {
+	r, err := op.c.newRequest("GET", "/v1/operator/health")
+	if err != nil {
+		return nil, nil, err
+	}
+	r.setQueryOptions(q)
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusTooManyRequests {
+		var buf bytes.Buffer
+		io.Copy(&buf, resp.Body)
+		return nil, nil, fmt.Errorf("Unexpected response code: %d (%s)", resp.StatusCode, buf.Bytes())
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out ClusterHealth
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, qm, nil
+}
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 	"time"
 )
@@ -207,11 +211,35 @@ func (op *Operator) AutopilotCASConfiguration(conf *AutopilotConfiguration, q *W
 }
 
 // AutopilotServerHealth is used to query Autopilot's top-level view of the health
-// of each Nomad server.
+// of each Nomad server. The health is returned even if the servers are
+// unhealthy, which the endpoint signals with a 429 status code.
 func (op *Operator) AutopilotServerHealth(q *QueryOptions) (*OperatorHealthReply, *QueryMeta, error) {
-	var out OperatorHealthReply
-	qm, err := op.c.query("/v1/operator/autopilot/health", &out, q)
+	r, err := op.c.newRequest("GET", "/v1/operator/autopilot/health")
+	if err != nil {
+		return nil, nil, err
+	}
+	r.setQueryOptions(q)
+	rtt, resp, err := op.c.doRequest(r)
 	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusTooManyRequests {
+		var buf bytes.Buffer
+		io.Copy(&buf, resp.Body)
+		return nil, nil, fmt.Errorf("Unexpected response code: %d (%s)", resp.StatusCode, buf.Bytes())
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out OperatorHealthReply
+	if err := decodeBody(resp, &out); err != nil {
 		return nil, nil, err
 	}
 	return &out, qm, nil
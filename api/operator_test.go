@@ -1,8 +1,11 @@
 package api
 
 import (
+	"bytes"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/testutil/retry"
 	"github.com/stretchr/testify/require"
@@ -121,3 +124,68 @@ func TestAPI_OperatorSchedulerCASConfiguration(t *testing.T) {
 		require.True(resp.Updated)
 	}
 }
+
+func TestOperator_SnapshotSaveRestore(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	// Register a job to have some state in the snapshot
+	job := testJob()
+	_, _, err := c.Jobs().Register(job, nil)
+	require.NoError(err)
+
+	operator := c.Operator()
+	snap, err := operator.Snapshot(nil)
+	require.NoError(err)
+	var archive bytes.Buffer
+	_, err = io.Copy(&archive, snap)
+	require.NoError(snap.Close())
+	require.NoError(err)
+	require.NotZero(archive.Len())
+
+	// Restoring the snapshot brings the job back after it is purged
+	_, _, err = c.Jobs().Deregister(*job.ID, true, nil)
+	require.NoError(err)
+
+	wm, err := operator.SnapshotRestore(&archive, nil)
+	require.NoError(err)
+	require.NotZero(wm.LastIndex)
+
+	_, _, err = c.Jobs().Info(*job.ID, nil)
+	require.NoError(err)
+
+	// Garbage is rejected
+	_, err = operator.SnapshotRestore(strings.NewReader("not a snapshot"), nil)
+	require.Error(err)
+}
+
+func TestAPI_OperatorGCConfiguration(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	operator := c.Operator()
+	config, _, err := operator.GCGetConfiguration(nil)
+	require.NoError(err)
+	require.Zero(config.GCConfig.DeploymentGCThreshold)
+
+	newConf := &GCConfiguration{DeploymentGCThreshold: 30 * time.Minute}
+	resp, wm, err := operator.GCCASConfiguration(newConf, nil)
+	require.NoError(err)
+	require.NotZero(wm.LastIndex)
+	require.True(resp.Updated)
+
+	config, _, err = operator.GCGetConfiguration(nil)
+	require.NoError(err)
+	require.Equal(30*time.Minute, config.GCConfig.DeploymentGCThreshold)
+
+	// Stale CAS updates are not applied
+	_, _, err = operator.GCCASConfiguration(&GCConfiguration{}, nil)
+	require.NoError(err)
+	config, _, err = operator.GCGetConfiguration(nil)
+	require.NoError(err)
+	require.Equal(30*time.Minute, config.GCConfig.DeploymentGCThreshold)
+}
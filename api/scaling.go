@@ -0,0 +1,131 @@
+package api
+
+// ScalingRequest is used to scale a task group of a job, or to record a
+// scaling event for it.
+type ScalingRequest struct {
+	// Group is the name of the task group to scale.
+	Group string
+
+	// Count is the new count of the task group. If nil, only the event is
+	// recorded.
+	Count *int64
+
+	// Message is the reason for scaling the task group.
+	Message string
+
+	// Error marks the event as a failure to scale the task group.
+	Error bool
+
+	// Meta is arbitrary metadata recorded with the event.
+	Meta map[string]string
+
+	PolicyOverride bool
+	WriteRequest
+}
+
+// JobScaleStatus is the counts and recent scaling events of the task groups
+// of a job.
+type JobScaleStatus struct {
+	JobID          string
+	Namespace      string
+	JobCreateIndex uint64
+	JobModifyIndex uint64
+	JobStopped     bool
+	TaskGroups     map[string]TaskGroupScaleStatus
+}
+
+// TaskGroupScaleStatus is the counts and recent scaling events of a task
+// group.
+type TaskGroupScaleStatus struct {
+	Desired   int
+	Placed    int
+	Running   int
+	Healthy   int
+	Unhealthy int
+
+	// Events is the scaling events of the task group, from newest to oldest.
+	Events []ScalingEvent
+}
+
+// ScalingEvent records a change of the count of a task group, or an event
+// about scaling it.
+type ScalingEvent struct {
+	// Time is the time of the event, in Unix nanoseconds.
+	Time int64
+
+	// Count is the new count of the task group. It is nil for events that
+	// didn't change the count.
+	Count         *int64
+	PreviousCount int64
+	Message       string
+	Error         bool
+	Meta          map[string]string
+
+	// EvalID is the ID of the evaluation created by the event, if any.
+	EvalID string
+
+	// AccessorID is the accessor ID of the ACL token used to scale the task
+	// group, if ACLs are enabled.
+	AccessorID  string
+	CreateIndex uint64
+}
+
+const (
+	// NodeScaleInStatusDraining is the scale-in status of nodes whose
+	// allocations are still being drained.
+	NodeScaleInStatusDraining = "draining"
+
+	// NodeScaleInStatusReady is the scale-in status of nodes that have been
+	// drained and can be terminated and purged.
+	NodeScaleInStatusReady = "ready"
+)
+
+// NodeScaleIn marks a node that is being removed from the cluster by an
+// autoscaler.
+type NodeScaleIn struct {
+	// RequestedAt is the time scale-in started, in Unix nanoseconds.
+	RequestedAt int64
+	Message     string
+	AccessorID  string
+}
+
+// NodeScaleInRequest is used to start the scale-in of a node.
+type NodeScaleInRequest struct {
+	DrainSpec *DrainSpec
+	Message   string
+}
+
+// NodeScaleInStatus is the progress of the scale-in of a node.
+type NodeScaleInStatus struct {
+	NodeID  string
+	ScaleIn *NodeScaleIn
+
+	// Status is the scale-in status of the node, draining or ready.
+	Status     string
+	NodeStatus string
+
+	// RemainingAllocs is the number of allocations on the node that are not
+	// terminal, excluding system allocations.
+	RemainingAllocs int
+	NodeModifyIndex uint64
+}
+
+// NodePoolScalingStatus is the capacity of the nodes of a datacenter and node
+// class, used to evaluate cluster scaling policies.
+type NodePoolScalingStatus struct {
+	Datacenter string
+	NodeClass  string
+
+	Nodes     int
+	Ready     int
+	Draining  int
+	ScalingIn int
+
+	// The total and allocated resources of the ready and eligible nodes.
+	TotalCPU          int64
+	AllocatedCPU      int64
+	TotalMemoryMB     int64
+	AllocatedMemoryMB int64
+
+	Allocations int
+}
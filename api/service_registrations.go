@@ -0,0 +1,72 @@
+package api
+
+// Services is used to query the services registered in Nomad's built-in
+// service catalog, so that applications can discover their peers without a
+// sidecar.
+type Services struct {
+	client *Client
+}
+
+// Services returns a new handle on the services.
+func (c *Client) Services() *Services {
+	return &Services{client: c}
+}
+
+// ServiceRegistrations returns a new handle on the services.
+//
+// Deprecated: use Services.
+func (c *Client) ServiceRegistrations() *Services {
+	return c.Services()
+}
+
+// List is used to list the services registered in a namespace. The
+// WaitIndex query option blocks until the catalog changes.
+func (s *Services) List(q *QueryOptions) ([]*ServiceRegistrationStub, *QueryMeta, error) {
+	var resp []*ServiceRegistrationStub
+	qm, err := s.client.query("/v1/services", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Get is used to get the registered instances of a service. The WaitIndex
+// query option blocks until the catalog changes.
+func (s *Services) Get(name string, q *QueryOptions) ([]*ServiceRegistration, *QueryMeta, error) {
+	var resp []*ServiceRegistration
+	qm, err := s.client.query("/v1/service/"+name, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Delete is used to deregister an instance of a service, such as one left
+// behind by a lost node.
+func (s *Services) Delete(name, id string, q *WriteOptions) (*WriteMeta, error) {
+	return s.client.delete("/v1/service/"+name+"/"+id, nil, q)
+}
+
+// ServiceRegistration is an instance of a service registered by an
+// allocation using the nomad service provider.
+type ServiceRegistration struct {
+	ID          string
+	ServiceName string
+	Namespace   string
+	NodeID      string
+	Datacenter  string
+	JobID       string
+	AllocID     string
+	Task        string
+	Tags        []string
+	Address     string
+	Port        int
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ServiceRegistrationStub summarizes the registered instances of a service.
+type ServiceRegistrationStub struct {
+	ServiceName string
+	Tags        []string
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServices_ListGetDelete(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	services := c.Services()
+
+	// No services are registered without running allocations
+	stubs, _, err := services.List(nil)
+	require.NoError(err)
+	require.Empty(stubs)
+
+	instances, _, err := services.Get("web", nil)
+	require.NoError(err)
+	require.Empty(instances)
+
+	// Unknown instances can't be deleted
+	_, err = services.Delete("web", "5a2b1c2d-6f1e-4b7a-9c3d-0e8f7a6b5c4d", nil)
+	require.Error(err)
+	require.Contains(err.Error(), "not found")
+}
@@ -41,11 +41,21 @@ type CpuStats struct {
 	Measured         []string
 }
 
+// NetworkStats holds network usage related stats
+type NetworkStats struct {
+	RxBytes   uint64
+	TxBytes   uint64
+	RxDropped uint64
+	TxDropped uint64
+	Measured  []string
+}
+
 // ResourceUsage holds information related to cpu and memory stats
 type ResourceUsage struct {
-	MemoryStats *MemoryStats
-	CpuStats    *CpuStats
-	DeviceStats []*DeviceGroupStats
+	MemoryStats  *MemoryStats
+	CpuStats     *CpuStats
+	NetworkStats *NetworkStats
+	DeviceStats  []*DeviceGroupStats
 }
 
 // TaskResourceUsage holds aggregated resource usage of all processes in a Task
@@ -372,6 +382,7 @@ type Service struct {
 	AddressMode  string   `mapstructure:"address_mode"`
 	Checks       []ServiceCheck
 	CheckRestart *CheckRestart `mapstructure:"check_restart"`
+	Provider     string
 }
 
 func (s *Service) Canonicalize(t *Task, tg *TaskGroup, job *Job) {
@@ -646,16 +657,21 @@ func (g *TaskGroup) AddSpread(s *Spread) *TaskGroup {
 	return g
 }
 
-// LogConfig provides configuration for log rotation
+// LogConfig provides configuration for log rotation and log sinks
 type LogConfig struct {
-	MaxFiles      *int `mapstructure:"max_files"`
-	MaxFileSizeMB *int `mapstructure:"max_file_size"`
+	MaxFiles      *int              `mapstructure:"max_files"`
+	MaxFileSizeMB *int              `mapstructure:"max_file_size"`
+	Sink          *string           `mapstructure:"sink"`
+	SinkOptions   map[string]string `mapstructure:"sink_options"`
+	DisableFiles  *bool             `mapstructure:"disable_files"`
 }
 
 func DefaultLogConfig() *LogConfig {
 	return &LogConfig{
 		MaxFiles:      intToPtr(10),
 		MaxFileSizeMB: intToPtr(10),
+		Sink:          stringToPtr(""),
+		DisableFiles:  boolToPtr(false),
 	}
 }
 
@@ -666,6 +682,12 @@ func (l *LogConfig) Canonicalize() {
 	if l.MaxFileSizeMB == nil {
 		l.MaxFileSizeMB = intToPtr(10)
 	}
+	if l.Sink == nil {
+		l.Sink = stringToPtr("")
+	}
+	if l.DisableFiles == nil {
+		l.DisableFiles = boolToPtr(false)
+	}
 }
 
 // DispatchPayloadConfig configures how a task gets its input from a job dispatch
@@ -769,6 +791,7 @@ type Template struct {
 	RightDelim   *string        `mapstructure:"right_delimiter"`
 	Envvars      *bool          `mapstructure:"env"`
 	VaultGrace   *time.Duration `mapstructure:"vault_grace"`
+	Sandbox      *bool          `mapstructure:"sandbox"`
 }
 
 func (tmpl *Template) Canonicalize() {
@@ -812,6 +835,9 @@ func (tmpl *Template) Canonicalize() {
 	if tmpl.VaultGrace == nil {
 		tmpl.VaultGrace = timeToPtr(15 * time.Second)
 	}
+	if tmpl.Sandbox == nil {
+		tmpl.Sandbox = boolToPtr(false)
+	}
 }
 
 type Vault struct {
@@ -819,6 +845,7 @@ type Vault struct {
 	Env          *bool
 	ChangeMode   *string `mapstructure:"change_mode"`
 	ChangeSignal *string `mapstructure:"change_signal"`
+	Cluster      *string
 }
 
 func (v *Vault) Canonicalize() {
@@ -831,6 +858,9 @@ func (v *Vault) Canonicalize() {
 	if v.ChangeSignal == nil {
 		v.ChangeSignal = stringToPtr("SIGHUP")
 	}
+	if v.Cluster == nil {
+		v.Cluster = stringToPtr("")
+	}
 }
 
 // NewTask creates and initializes a new Task.
@@ -887,13 +917,14 @@ func (t *Task) SetLogConfig(l *LogConfig) *Task {
 // TaskState tracks the current state of a task and events that caused state
 // transitions.
 type TaskState struct {
-	State       string
-	Failed      bool
-	Restarts    uint64
-	LastRestart time.Time
-	StartedAt   time.Time
-	FinishedAt  time.Time
-	Events      []*TaskEvent
+	State        string
+	Failed       bool
+	Restarts     uint64
+	LastRestart  time.Time
+	StartedAt    time.Time
+	StartLatency time.Duration
+	FinishedAt   time.Time
+	Events       []*TaskEvent
 }
 
 const (
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// Variables is used to access encrypted variables.
+type Variables struct {
+	client *Client
+}
+
+// Variables returns a new handle on the variables.
+func (c *Client) Variables() *Variables {
+	return &Variables{client: c}
+}
+
+// List is used to list the metadata of the variables in a namespace. The
+// Prefix query option filters the variables by path.
+func (v *Variables) List(q *QueryOptions) ([]*VariableMetadata, *QueryMeta, error) {
+	var resp []*VariableMetadata
+	qm, err := v.client.query("/v1/vars", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Read is used to read a variable and its decrypted items.
+func (v *Variables) Read(path string, q *QueryOptions) (*Variable, *QueryMeta, error) {
+	var resp Variable
+	qm, err := v.client.query("/v1/var/"+path, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Upsert is used to create or update a variable.
+func (v *Variables) Upsert(variable *Variable, q *WriteOptions) (*VariableMetadata, *WriteMeta, error) {
+	return v.upsert("/v1/var/"+variable.Path, variable, q)
+}
+
+// CheckedUpsert is used to write a variable only if its modify index
+// matches the given index. An index of zero only creates the variable.
+func (v *Variables) CheckedUpsert(variable *Variable, index uint64, q *WriteOptions) (*VariableMetadata, *WriteMeta, error) {
+	endpoint := fmt.Sprintf("/v1/var/%s?cas=%d", variable.Path, index)
+	return v.upsert(endpoint, variable, q)
+}
+
+func (v *Variables) upsert(endpoint string, variable *Variable, q *WriteOptions) (*VariableMetadata, *WriteMeta, error) {
+	var resp VariableMetadata
+	wm, err := v.client.write(endpoint, variable, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// AcquireLock is used to acquire the lock of a variable, creating the
+// variable if it doesn't exist. The items of the variable are written if set,
+// and the TTL of its Lock is used if set. The returned metadata holds the ID
+// of the lock, which is required to renew or release it.
+//
+// Locks expire unless they are renewed within their TTL, so they can be used
+// for leader election among processes that may fail without releasing them.
+func (v *Variables) AcquireLock(variable *Variable, q *WriteOptions) (*VariableMetadata, *WriteMeta, error) {
+	return v.upsert(fmt.Sprintf("/v1/var/%s?lock-acquire", variable.Path), variable, q)
+}
+
+// RenewLock is used to renew the lock of a variable. The ID of the lock must
+// be set on the variable.
+func (v *Variables) RenewLock(variable *Variable, q *WriteOptions) (*VariableMetadata, *WriteMeta, error) {
+	return v.upsert(fmt.Sprintf("/v1/var/%s?lock-renew", variable.Path), variable, q)
+}
+
+// ReleaseLock is used to release the lock of a variable. The ID of the lock
+// must be set on the variable.
+func (v *Variables) ReleaseLock(variable *Variable, q *WriteOptions) (*VariableMetadata, *WriteMeta, error) {
+	return v.upsert(fmt.Sprintf("/v1/var/%s?lock-release", variable.Path), variable, q)
+}
+
+// Delete is used to delete a variable.
+func (v *Variables) Delete(path string, q *WriteOptions) (*WriteMeta, error) {
+	return v.client.delete("/v1/var/"+path, nil, q)
+}
+
+// CheckedDelete is used to delete a variable only if its modify index
+// matches the given index.
+func (v *Variables) CheckedDelete(path string, index uint64, q *WriteOptions) (*WriteMeta, error) {
+	endpoint := fmt.Sprintf("/v1/var/%s?cas=%d", path, index)
+	return v.client.delete(endpoint, nil, q)
+}
+
+// VariableMetadata is the unencrypted metadata of a variable.
+type VariableMetadata struct {
+	Namespace   string
+	Path        string
+	CreateIndex uint64
+	ModifyIndex uint64
+	CreateTime  int64
+	ModifyTime  int64
+	Lock        *VariableLock `json:",omitempty"`
+}
+
+// Variable is a variable along with its decrypted items.
+type Variable struct {
+	Namespace   string
+	Path        string
+	CreateIndex uint64
+	ModifyIndex uint64
+	CreateTime  int64
+	ModifyTime  int64
+	Lock        *VariableLock `json:",omitempty"`
+	Items       map[string]string
+}
+
+// VariableLock is a lease-style lock held on a variable. The ID is only
+// returned to the holder of the lock.
+type VariableLock struct {
+	ID         string        `json:",omitempty"`
+	TTL        time.Duration `json:",omitempty"`
+	ExpireTime int64         `json:",omitempty"`
+}
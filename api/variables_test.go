@@ -0,0 +1,87 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariables_UpsertReadDelete(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	vars := c.Variables()
+
+	// Dev agents generate a variables encryption key
+	variable := &Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}
+	meta, _, err := vars.CheckedUpsert(variable, 0, nil)
+	require.NoError(err)
+	require.Equal("app/db", meta.Path)
+
+	// Creating it again conflicts
+	_, _, err = vars.CheckedUpsert(variable, 0, nil)
+	require.Error(err)
+
+	out, _, err := vars.Read("app/db", nil)
+	require.NoError(err)
+	require.Equal(variable.Items, out.Items)
+	require.Equal(meta.ModifyIndex, out.ModifyIndex)
+
+	list, _, err := vars.List(&QueryOptions{Prefix: "app"})
+	require.NoError(err)
+	require.Len(list, 1)
+
+	_, err = vars.CheckedDelete("app/db", meta.ModifyIndex, nil)
+	require.NoError(err)
+
+	list, _, err = vars.List(nil)
+	require.NoError(err)
+	require.Empty(list)
+}
+
+func TestVariables_Lock(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	vars := c.Variables()
+
+	variable := &Variable{
+		Path:  "jobs/leader",
+		Items: map[string]string{"leader": "alloc-1"},
+		Lock:  &VariableLock{TTL: 30 * time.Second},
+	}
+	meta, _, err := vars.AcquireLock(variable, nil)
+	require.NoError(err)
+	require.NotNil(meta.Lock)
+	require.NotEmpty(meta.Lock.ID)
+	require.Equal(30*time.Second, meta.Lock.TTL)
+
+	// A second candidate doesn't get the lock
+	_, _, err = vars.AcquireLock(&Variable{Path: "jobs/leader"}, nil)
+	require.Error(err)
+	require.Contains(err.Error(), "variable is locked")
+
+	held := &Variable{Path: "jobs/leader", Lock: &VariableLock{ID: meta.Lock.ID}}
+	_, _, err = vars.RenewLock(held, nil)
+	require.NoError(err)
+
+	meta, _, err = vars.ReleaseLock(held, nil)
+	require.NoError(err)
+	require.Nil(meta.Lock)
+
+	out, _, err := vars.Read("jobs/leader", nil)
+	require.NoError(err)
+	require.Equal(variable.Items, out.Items)
+	require.Nil(out.Lock)
+}
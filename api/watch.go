@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	// watchMinBackoff and watchMaxBackoff bound the delay before retrying a
+	// query that failed. The delay doubles on each consecutive failure.
+	watchMinBackoff = 1 * time.Second
+	watchMaxBackoff = 1 * time.Minute
+)
+
+// WatchEvent is a result of a blocking query run by Watch.
+type WatchEvent[T any] struct {
+	// Value is the result of the query. It is the zero value if Err is set.
+	Value T
+
+	// Meta is the metadata of the query. It is nil if Err is set.
+	Meta *QueryMeta
+
+	// Err is the error of a failed query. The query is retried after a
+	// backoff, so the watch continues after an error.
+	Err error
+}
+
+// Watch runs a blocking query in a loop until the context is done, sending
+// each new result on the returned channel, which is closed once the context is
+// done. The fetch function must run the query with the given options, such
+// as:
+//
+//	events := api.Watch(ctx, func(q api.QueryOptions) ([]*api.JobListStub, *api.QueryMeta, error) {
+//		return client.Jobs().List(&q)
+//	})
+//
+// The options set the index of the last result to wait on, and allow stale
+// reads. Results are only sent when the index has moved forward, so queries
+// that time out without changes are not sent. An index that moves backwards
+// is first confirmed with a consistent query, since a lagging server can
+// return older results, and is then sent and followed from there. Failed
+// queries are sent as errors and retried with a backoff.
+func Watch[T any](ctx context.Context, fetch func(QueryOptions) (T, *QueryMeta, error)) <-chan *WatchEvent[T] {
+	eventsCh := make(chan *WatchEvent[T], 1)
+
+	go func() {
+		defer close(eventsCh)
+
+		send := func(event *WatchEvent[T]) bool {
+			select {
+			case eventsCh <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var (
+			index      uint64
+			first      = true
+			consistent bool
+			backoff    time.Duration
+		)
+		for ctx.Err() == nil {
+			// Results at index zero are waited on as index one, so that the
+			// next query blocks
+			q := QueryOptions{
+				WaitIndex:  index,
+				AllowStale: !consistent,
+			}
+			if !first && q.WaitIndex == 0 {
+				q.WaitIndex = 1
+			}
+			q = *q.WithContext(ctx)
+
+			value, meta, err := fetch(q)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !send(&WatchEvent[T]{Err: err}) {
+					return
+				}
+
+				// Back off before retrying
+				backoff = nextWatchBackoff(backoff)
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			backoff = 0
+
+			switch {
+			case first || meta.LastIndex > index:
+				first = false
+			case meta.LastIndex == index:
+				// The query timed out without changes
+				consistent = false
+				continue
+			case !consistent:
+				// The server may be lagging behind, so confirm that the
+				// index moved backwards with a consistent query
+				consistent = true
+				continue
+			}
+
+			// Follow the index, which may have moved backwards after a
+			// consistent query, such as when restoring a snapshot
+			consistent = false
+			index = meta.LastIndex
+			if !send(&WatchEvent[T]{Value: value, Meta: meta}) {
+				return
+			}
+		}
+	}()
+
+	return eventsCh
+}
+
+// nextWatchBackoff returns the delay before retrying a failed query, given
+// the previous delay.
+func nextWatchBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff < watchMinBackoff {
+		backoff = watchMinBackoff
+	}
+	if backoff > watchMaxBackoff {
+		backoff = watchMaxBackoff
+	}
+	return backoff
+}
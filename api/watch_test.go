@@ -0,0 +1,291 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedQuery is a fetch function for Watch that returns the results of a
+// script in order, recording the options of each query. Once the script is
+// exhausted, queries block until the context is done.
+type scriptedQuery struct {
+	results []scriptedResult
+
+	l       sync.Mutex
+	queries []QueryOptions
+}
+
+type scriptedResult struct {
+	value string
+	index uint64
+	err   error
+}
+
+func (s *scriptedQuery) fetch(q QueryOptions) (string, *QueryMeta, error) {
+	s.l.Lock()
+	n := len(s.queries)
+	s.queries = append(s.queries, q)
+	s.l.Unlock()
+
+	if n >= len(s.results) {
+		<-q.Context().Done()
+		return "", nil, q.Context().Err()
+	}
+
+	r := s.results[n]
+	if r.err != nil {
+		return "", nil, r.err
+	}
+	return r.value, &QueryMeta{LastIndex: r.index}, nil
+}
+
+func (s *scriptedQuery) recorded() []QueryOptions {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return append([]QueryOptions(nil), s.queries...)
+}
+
+// waitRecorded waits for n queries to have been made and returns them.
+func (s *scriptedQuery) waitRecorded(t *testing.T, n int) []QueryOptions {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		queries := s.recorded()
+		if len(queries) >= n {
+			return queries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d queries, got %d", n, len(queries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// nextWatchEvent returns the next event of the watch, failing the test if
+// there is none.
+func nextWatchEvent(t *testing.T, events <-chan *WatchEvent[string]) *WatchEvent[string] {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "watch closed")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for watch event")
+	}
+	return nil
+}
+
+func TestWatch_Index(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := &scriptedQuery{
+		results: []scriptedResult{
+			{value: "a", index: 5},
+			{value: "a", index: 5}, // timed out without changes
+			{value: "b", index: 7},
+		},
+	}
+	events := Watch(ctx, query.fetch)
+
+	event := nextWatchEvent(t, events)
+	require.NoError(event.Err)
+	require.Equal("a", event.Value)
+	require.Equal(uint64(5), event.Meta.LastIndex)
+
+	event = nextWatchEvent(t, events)
+	require.NoError(event.Err)
+	require.Equal("b", event.Value)
+	require.Equal(uint64(7), event.Meta.LastIndex)
+
+	// Each query waits on the index of the last result and allows stale
+	// reads
+	queries := query.waitRecorded(t, 4)
+	require.Len(queries, 4)
+	for i, wait := range []uint64{0, 5, 5, 7} {
+		require.Equal(wait, queries[i].WaitIndex)
+		require.True(queries[i].AllowStale)
+	}
+
+	// The watch is closed when the context is done
+	cancel()
+	select {
+	case _, ok := <-events:
+		require.False(ok)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("watch not closed")
+	}
+}
+
+func TestWatch_IndexZero(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := &scriptedQuery{
+		results: []scriptedResult{
+			{value: "a", index: 0},
+			{value: "b", index: 2},
+		},
+	}
+	events := Watch(ctx, query.fetch)
+
+	require.Equal("a", nextWatchEvent(t, events).Value)
+	require.Equal("b", nextWatchEvent(t, events).Value)
+
+	// The query after a result at index zero still blocks
+	queries := query.recorded()
+	require.Equal(uint64(0), queries[0].WaitIndex)
+	require.Equal(uint64(1), queries[1].WaitIndex)
+}
+
+func TestWatch_Backwards(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := &scriptedQuery{
+		results: []scriptedResult{
+			{value: "a", index: 10},
+			{value: "stale", index: 8}, // lagging server
+			{value: "b", index: 12},    // confirmed by the leader
+			{value: "old", index: 4},   // index moved backwards
+			{value: "restored", index: 3},
+		},
+	}
+	events := Watch(ctx, query.fetch)
+
+	require.Equal("a", nextWatchEvent(t, events).Value)
+
+	// The result of the lagging server is skipped
+	require.Equal("b", nextWatchEvent(t, events).Value)
+
+	// An index that moves backwards on a consistent query is followed
+	event := nextWatchEvent(t, events)
+	require.Equal("restored", event.Value)
+	require.Equal(uint64(3), event.Meta.LastIndex)
+
+	queries := query.waitRecorded(t, 6)
+	require.Len(queries, 6)
+	require.True(queries[1].AllowStale)
+	require.False(queries[2].AllowStale)
+	require.Equal(uint64(10), queries[2].WaitIndex)
+	require.True(queries[3].AllowStale)
+	require.False(queries[4].AllowStale)
+	require.Equal(uint64(3), queries[5].WaitIndex)
+	require.True(queries[5].AllowStale)
+}
+
+func TestWatch_Errors(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := &scriptedQuery{
+		results: []scriptedResult{
+			{value: "a", index: 5},
+			{err: errors.New("connection refused")},
+			{value: "b", index: 6},
+		},
+	}
+
+	start := time.Now()
+	events := Watch(ctx, query.fetch)
+
+	require.Equal("a", nextWatchEvent(t, events).Value)
+
+	event := nextWatchEvent(t, events)
+	require.EqualError(event.Err, "connection refused")
+	require.Nil(event.Meta)
+
+	// The query is retried after a backoff
+	event = nextWatchEvent(t, events)
+	require.NoError(event.Err)
+	require.Equal("b", event.Value)
+	require.True(time.Since(start) >= watchMinBackoff)
+
+	queries := query.recorded()
+	require.Equal(uint64(5), queries[2].WaitIndex)
+}
+
+func TestWatch_Backoff(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var backoff time.Duration
+	var got []time.Duration
+	for i := 0; i < 8; i++ {
+		backoff = nextWatchBackoff(backoff)
+		got = append(got, backoff)
+	}
+	require.Equal([]time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		32 * time.Second,
+		1 * time.Minute,
+		1 * time.Minute,
+	}, got)
+}
+
+func TestWatch_Jobs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := Watch(ctx, func(q QueryOptions) ([]*JobListStub, *QueryMeta, error) {
+		return jobs.List(&q)
+	})
+
+	select {
+	case event := <-events:
+		require.NoError(event.Err)
+		require.Empty(event.Value)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for jobs")
+	}
+
+	// Registering a job unblocks the watch
+	job := testJob()
+	_, _, err := jobs.Register(job, nil)
+	require.NoError(err)
+
+	select {
+	case event := <-events:
+		require.NoError(event.Err)
+		require.Len(event.Value, 1)
+		require.Equal(*job.ID, event.Value[0].ID)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for jobs")
+	}
+
+	// Cancelling the context aborts the blocking query
+	cancel()
+	select {
+	case _, ok := <-events:
+		require.False(ok)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("watch not closed")
+	}
+}
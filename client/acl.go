@@ -21,6 +21,10 @@ const (
 	// tokenCacheSize is the number of ACL tokens to keep cached. Tokens have a fetching cost,
 	// so we keep the hot tokens cached to reduce the lookups.
 	tokenCacheSize = 64
+
+	// roleCacheSize is the number of ACL roles to keep cached. Roles have a fetching cost,
+	// so we keep the hot roles cached to reduce the ACL token resolution time.
+	roleCacheSize = 64
 )
 
 // clientACLResolver holds the state required for client resolution
@@ -34,6 +38,9 @@ type clientACLResolver struct {
 
 	// tokenCache is used to maintain the fetched token objects
 	tokenCache *lru.TwoQueueCache
+
+	// roleCache is used to maintain the fetched role objects
+	roleCache *lru.TwoQueueCache
 }
 
 // init is used to setup the client resolver state
@@ -52,13 +59,18 @@ func (c *clientACLResolver) init() error {
 	if err != nil {
 		return err
 	}
+	c.roleCache, err = lru.New2Q(roleCacheSize)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-// cachedACLValue is used to manage ACL Token or Policy TTLs
+// cachedACLValue is used to manage ACL Token, Policy or Role TTLs
 type cachedACLValue struct {
 	Token     *structs.ACLToken
 	Policy    *structs.ACLPolicy
+	Role      *structs.ACLRole
 	CacheTime time.Time
 }
 
@@ -67,6 +79,22 @@ func (c *cachedACLValue) Age() time.Duration {
 	return time.Since(c.CacheTime)
 }
 
+// aclTokenTTL returns how long tokens are cached. It may change when the
+// configuration is reloaded.
+func (c *Client) aclTokenTTL() time.Duration {
+	c.configLock.RLock()
+	defer c.configLock.RUnlock()
+	return c.config.ACLTokenTTL
+}
+
+// aclPolicyTTL returns how long policies and roles are cached. It may change
+// when the configuration is reloaded.
+func (c *Client) aclPolicyTTL() time.Duration {
+	c.configLock.RLock()
+	defer c.configLock.RUnlock()
+	return c.config.ACLPolicyTTL
+}
+
 // ResolveToken is used to translate an ACL Token Secret ID into
 // an ACL object, nil if ACLs are disabled, or an error.
 func (c *Client) ResolveToken(secretID string) (*acl.ACL, error) {
@@ -84,14 +112,27 @@ func (c *Client) ResolveToken(secretID string) (*acl.ACL, error) {
 	if token == nil {
 		return nil, structs.ErrTokenNotFound
 	}
+	if token.IsExpired(time.Now()) {
+		return nil, structs.ErrTokenExpired
+	}
 
 	// Check if this is a management token
 	if token.Type == structs.ACLManagementToken {
 		return acl.ManagementACL, nil
 	}
 
+	// Resolve the roles to add the policies they grant
+	policyNames := token.Policies
+	if len(token.Roles) != 0 {
+		roles, err := c.resolveRoles(token.SecretID, token.Roles)
+		if err != nil {
+			return nil, err
+		}
+		policyNames = rolePolicyNames(token.Policies, roles)
+	}
+
 	// Resolve the policies
-	policies, err := c.resolvePolicies(token.SecretID, token.Policies)
+	policies, err := c.resolvePolicies(token.SecretID, policyNames)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +145,17 @@ func (c *Client) ResolveToken(secretID string) (*acl.ACL, error) {
 	return aclObj, nil
 }
 
+// allowAllocJobOp checks if the operation is allowed on the job of the
+// allocation in the namespace. Allocations unknown to the client are only
+// checked against the namespace.
+func (c *Client) allowAllocJobOp(aclObj *acl.ACL, ns, allocID, op string) bool {
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return aclObj.AllowNsOp(ns, op)
+	}
+	return aclObj.AllowJobOp(ns, ar.Alloc().JobID, op)
+}
+
 // resolveTokenValue is used to translate a secret ID into an ACL token with caching
 // We use a local cache up to the TTL limit, and then resolve via a server. If we cannot
 // reach a server, but have a cached value we extend the TTL to gracefully handle outages.
@@ -117,7 +169,7 @@ func (c *Client) resolveTokenValue(secretID string) (*structs.ACLToken, error) {
 	raw, ok := c.tokenCache.Get(secretID)
 	if ok {
 		cached := raw.(*cachedACLValue)
-		if cached.Age() <= c.config.ACLTokenTTL {
+		if cached.Age() <= c.aclTokenTTL() {
 			return cached.Token, nil
 		}
 	}
@@ -169,7 +221,7 @@ func (c *Client) resolvePolicies(secretID string, policies []string) ([]*structs
 
 		// Check if the cached value is valid or expired
 		cached := raw.(*cachedACLValue)
-		if cached.Age() <= c.config.ACLPolicyTTL {
+		if cached.Age() <= c.aclPolicyTTL() {
 			out = append(out, cached.Policy)
 		} else {
 			expired = append(expired, cached.Policy)
@@ -217,3 +269,92 @@ func (c *Client) resolvePolicies(secretID string, policies []string) ([]*structs
 	// Return the valid policies
 	return out, nil
 }
+
+// resolveRoles is used to translate a set of named ACL roles into the objects.
+// Roles are cached like policies, using the policy TTL.
+func (c *Client) resolveRoles(secretID string, roles []string) ([]*structs.ACLRole, error) {
+	var out []*structs.ACLRole
+	var expired []*structs.ACLRole
+	var missing []string
+
+	// Scan the cache for each role
+	for _, roleName := range roles {
+		// Lookup the role in the cache
+		raw, ok := c.roleCache.Get(roleName)
+		if !ok {
+			missing = append(missing, roleName)
+			continue
+		}
+
+		// Check if the cached value is valid or expired
+		cached := raw.(*cachedACLValue)
+		if cached.Age() <= c.aclPolicyTTL() {
+			out = append(out, cached.Role)
+		} else {
+			expired = append(expired, cached.Role)
+		}
+	}
+
+	// Hot-path if we have no missing or expired roles
+	if len(missing)+len(expired) == 0 {
+		return out, nil
+	}
+
+	// Lookup the missing and expired roles
+	fetch := missing
+	for _, r := range expired {
+		fetch = append(fetch, r.Name)
+	}
+	req := structs.ACLRoleSetRequest{
+		Names: fetch,
+		QueryOptions: structs.QueryOptions{
+			Region:     c.Region(),
+			AuthToken:  secretID,
+			AllowStale: true,
+		},
+	}
+	var resp structs.ACLRoleSetResponse
+	if err := c.RPC("ACL.GetRoles", &req, &resp); err != nil {
+		// If we encounter an error but have cached roles, mask the error and extend the cache
+		if len(missing) == 0 {
+			c.logger.Warn("failed to resolve roles, using expired cached value", "error", err)
+			out = append(out, expired...)
+			return out, nil
+		}
+		return nil, err
+	}
+
+	// Handle each output
+	for _, role := range resp.Roles {
+		c.roleCache.Add(role.Name, &cachedACLValue{
+			Role:      role,
+			CacheTime: time.Now(),
+		})
+		out = append(out, role)
+	}
+
+	// Return the valid roles
+	return out, nil
+}
+
+// rolePolicyNames returns the union of the policies and those granted by
+// the roles.
+func rolePolicyNames(policies []string, roles []*structs.ACLRole) []string {
+	seen := make(map[string]struct{}, len(policies))
+	var names []string
+	for _, policyName := range policies {
+		if _, ok := seen[policyName]; !ok {
+			seen[policyName] = struct{}{}
+			names = append(names, policyName)
+		}
+	}
+	for _, role := range roles {
+		for _, policyName := range role.Policies {
+			if _, ok := seen[policyName]; !ok {
+				seen[policyName] = struct{}{}
+				names = append(names, policyName)
+			}
+		}
+	}
+	return names
+}
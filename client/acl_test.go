@@ -2,6 +2,7 @@ package client
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/client/config"
@@ -10,6 +11,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClient_ACL_resolveTokenValue(t *testing.T) {
@@ -165,3 +167,41 @@ func TestClient_ACL_ResolveToken(t *testing.T) {
 	assert.Equal(t, structs.ErrTokenNotFound, err)
 	assert.Nil(t, out4)
 }
+
+func TestClient_ACL_ResolveToken_Roles(t *testing.T) {
+	require := require.New(t)
+	s1, _, _ := testACLServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	c1, cleanup := TestClient(t, func(c *config.Config) {
+		c.RPCHandler = s1
+		c.ACLEnabled = true
+	})
+	defer cleanup()
+
+	// Create a token that is granted its policy through a role
+	policy := mock.ACLPolicy()
+	role := mock.ACLRole()
+	role.Policies = []string{policy.Name}
+	token := mock.ACLToken()
+	token.Policies = nil
+	token.Roles = []string{role.Name}
+	require.NoError(s1.State().UpsertACLPolicies(100, []*structs.ACLPolicy{policy}))
+	require.NoError(s1.State().UpsertACLRoles(105, []*structs.ACLRole{role}))
+	require.NoError(s1.State().UpsertACLTokens(110, []*structs.ACLToken{token}))
+
+	out, err := c1.ResolveToken(token.SecretID)
+	require.NoError(err)
+	require.True(out.AllowNamespaceOperation("default", acl.NamespaceCapabilityListJobs))
+
+	// Expired tokens are rejected
+	expired := mock.ACLToken()
+	expiry := time.Now().Add(-time.Minute)
+	expired.ExpirationTime = &expiry
+	require.NoError(s1.State().UpsertACLTokens(120, []*structs.ACLToken{expired}))
+
+	out, err = c1.ResolveToken(expired.SecretID)
+	require.Equal(structs.ErrTokenExpired, err)
+	require.Nil(out)
+}
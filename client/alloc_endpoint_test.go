@@ -175,6 +175,166 @@ func TestAllocations_GarbageCollect_ACL(t *testing.T) {
 	}
 }
 
+// runningMockAlloc adds a long running mock driver allocation to the client
+// and waits for its task to start.
+func runningMockAlloc(t *testing.T, client *Client) *nstructs.Allocation {
+	a := mock.Alloc()
+	a.Job.TaskGroups[0].Tasks[0].Driver = "mock_driver"
+	a.Job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{
+		"run_for": "10s",
+	}
+	require.Nil(t, client.addAlloc(a, ""))
+
+	testutil.WaitForResult(func() (bool, error) {
+		ar, err := client.getAllocRunner(a.ID)
+		if err != nil {
+			return false, err
+		}
+		ts, ok := ar.AllocState().TaskStates["web"]
+		if !ok || ts.State != nstructs.TaskStateRunning {
+			return false, fmt.Errorf("task not running: %#v", ts)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	return a
+}
+
+// waitForTaskEvent waits for the task of the allocation to have an event of
+// the given type.
+func waitForTaskEvent(t *testing.T, client *Client, allocID, task, eventType string) {
+	testutil.WaitForResult(func() (bool, error) {
+		ar, err := client.getAllocRunner(allocID)
+		if err != nil {
+			return false, err
+		}
+		ts, ok := ar.AllocState().TaskStates[task]
+		if !ok {
+			return false, fmt.Errorf("missing state of task %q", task)
+		}
+		for _, e := range ts.Events {
+			if e.Type == eventType {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("missing %q event: %v", eventType, ts.Events)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestAllocations_Restart(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	client, cleanup := TestClient(t, nil)
+	defer cleanup()
+
+	a := runningMockAlloc(t, client)
+
+	// Try with bad alloc
+	req := &nstructs.AllocRestartRequest{}
+	var resp nstructs.GenericResponse
+	err := client.ClientRPC("Allocations.Restart", &req, &resp)
+	require.True(nstructs.IsErrUnknownAllocation(err))
+
+	// Try with bad task
+	req.AllocID = a.ID
+	req.TaskName = "foo"
+	err = client.ClientRPC("Allocations.Restart", &req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "Could not find task runner for task: foo")
+
+	// Try with good task
+	req.TaskName = "web"
+	require.Nil(client.ClientRPC("Allocations.Restart", &req, &resp))
+	waitForTaskEvent(t, client, a.ID, "web", nstructs.TaskRestartSignal)
+}
+
+func TestAllocations_Restart_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	server, addr, root := testACLServer(t, nil)
+	defer server.Shutdown()
+
+	client, cleanup := TestClient(t, func(c *config.Config) {
+		c.Servers = []string{addr}
+		c.ACLEnabled = true
+	})
+	defer cleanup()
+
+	// Try request without a token and expect failure
+	{
+		req := &nstructs.AllocRestartRequest{}
+		var resp nstructs.GenericResponse
+		err := client.ClientRPC("Allocations.Restart", &req, &resp)
+		require.NotNil(err)
+		require.EqualError(err, nstructs.ErrPermissionDenied.Error())
+	}
+
+	// Try request with an invalid token and expect failure
+	{
+		token := mock.CreatePolicyAndToken(t, server.State(), 1005, "invalid",
+			mock.NamespacePolicy(nstructs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+		req := &nstructs.AllocRestartRequest{}
+		req.AuthToken = token.SecretID
+		req.Namespace = nstructs.DefaultNamespace
+
+		var resp nstructs.GenericResponse
+		err := client.ClientRPC("Allocations.Restart", &req, &resp)
+		require.NotNil(err)
+		require.EqualError(err, nstructs.ErrPermissionDenied.Error())
+	}
+
+	// Try request with a valid token
+	{
+		token := mock.CreatePolicyAndToken(t, server.State(), 1007, "test-valid",
+			mock.NamespacePolicy(nstructs.DefaultNamespace, "", []string{acl.NamespaceCapabilitySubmitJob}))
+		req := &nstructs.AllocRestartRequest{}
+		req.AuthToken = token.SecretID
+		req.Namespace = nstructs.DefaultNamespace
+
+		var resp nstructs.GenericResponse
+		err := client.ClientRPC("Allocations.Restart", &req, &resp)
+		require.True(nstructs.IsErrUnknownAllocation(err))
+	}
+
+	// Try request with a management token
+	{
+		req := &nstructs.AllocRestartRequest{}
+		req.AuthToken = root.SecretID
+
+		var resp nstructs.GenericResponse
+		err := client.ClientRPC("Allocations.Restart", &req, &resp)
+		require.True(nstructs.IsErrUnknownAllocation(err))
+	}
+}
+
+func TestAllocations_Signal(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	client, cleanup := TestClient(t, nil)
+	defer cleanup()
+
+	a := runningMockAlloc(t, client)
+
+	// Try with bad task
+	req := &nstructs.AllocSignalRequest{
+		AllocID: a.ID,
+		Task:    "foo",
+		Signal:  "SIGHUP",
+	}
+	var resp nstructs.GenericResponse
+	err := client.ClientRPC("Allocations.Signal", &req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "Could not find task runner for task: foo")
+
+	// Signal all the tasks
+	req.Task = ""
+	require.Nil(client.ClientRPC("Allocations.Signal", &req, &resp))
+	waitForTaskEvent(t, client, a.ID, "web", nstructs.TaskSignaling)
+}
+
 func TestAllocations_Stats(t *testing.T) {
 	t.Skip("missing exec driver plugin implementation")
 	t.Parallel()
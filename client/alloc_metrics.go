@@ -0,0 +1,72 @@
+package client
+
+import (
+	"sync"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// allocMetricsLimiter bounds the number of tasks publishing resource usage
+// metrics. Each task publishing metrics adds a set of time series labelled
+// with its allocation to the metrics sinks, so the limit bounds the
+// cardinality of the metrics of busy clients.
+type allocMetricsLimiter struct {
+	// limit is the maximum number of tasks publishing metrics. There is no
+	// limit if zero.
+	limit int
+
+	// publishing is the set of tasks publishing metrics and limited the set
+	// of tasks refused because of the limit.
+	publishing map[string]struct{}
+	limited    map[string]struct{}
+	l          sync.Mutex
+}
+
+func newAllocMetricsLimiter(limit int) *allocMetricsLimiter {
+	return &allocMetricsLimiter{
+		limit:      limit,
+		publishing: make(map[string]struct{}),
+		limited:    make(map[string]struct{}),
+	}
+}
+
+// AcquireAllocMetrics returns whether the task may publish metrics, either
+// because it already does or because the limit isn't reached.
+func (a *allocMetricsLimiter) AcquireAllocMetrics(allocID, task string) bool {
+	if a.limit <= 0 {
+		return true
+	}
+
+	key := allocID + "/" + task
+	a.l.Lock()
+	defer a.l.Unlock()
+
+	if _, ok := a.publishing[key]; ok {
+		return true
+	}
+	if len(a.publishing) < a.limit {
+		delete(a.limited, key)
+		a.publishing[key] = struct{}{}
+		return true
+	}
+
+	// Only count the task once until it's allowed to publish metrics
+	if _, ok := a.limited[key]; !ok {
+		a.limited[key] = struct{}{}
+		metrics.IncrCounter([]string{"client", "allocs", "metrics_limited"}, 1)
+	}
+	return false
+}
+
+// ReleaseAllocMetrics frees the slot of a task that stopped running.
+func (a *allocMetricsLimiter) ReleaseAllocMetrics(allocID, task string) {
+	if a.limit <= 0 {
+		return
+	}
+
+	key := allocID + "/" + task
+	a.l.Lock()
+	defer a.l.Unlock()
+	delete(a.publishing, key)
+	delete(a.limited, key)
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocMetricsLimiter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	l := newAllocMetricsLimiter(2)
+	require.True(l.AcquireAllocMetrics("a1", "web"))
+	require.True(l.AcquireAllocMetrics("a1", "sidecar"))
+
+	// Tasks already publishing metrics keep their slot
+	require.True(l.AcquireAllocMetrics("a1", "web"))
+
+	// New tasks are refused until a slot is released
+	require.False(l.AcquireAllocMetrics("a2", "web"))
+	require.False(l.AcquireAllocMetrics("a2", "web"))
+	l.ReleaseAllocMetrics("a1", "web")
+	require.True(l.AcquireAllocMetrics("a2", "web"))
+	require.False(l.AcquireAllocMetrics("a1", "web"))
+
+	// Releasing tasks that don't publish metrics is a no-op
+	l.ReleaseAllocMetrics("a3", "web")
+	require.False(l.AcquireAllocMetrics("a3", "web"))
+}
+
+func TestAllocMetricsLimiter_Unlimited(t *testing.T) {
+	t.Parallel()
+
+	l := newAllocMetricsLimiter(0)
+	for _, id := range []string{"a1", "a2", "a3"} {
+		require.True(t, l.AcquireAllocMetrics(id, "web"))
+	}
+}
@@ -86,6 +86,7 @@ type AllocDirFS interface {
 	List(path string) ([]*cstructs.AllocFileInfo, error)
 	Stat(path string) (*cstructs.AllocFileInfo, error)
 	ReadAt(path string, offset int64) (io.ReadCloser, error)
+	Archive(path string, w io.Writer) error
 	Snapshot(w io.Writer) error
 	BlockUntilExists(ctx context.Context, path string) (chan error, error)
 	ChangeEvents(ctx context.Context, path string, curOffset int64) (*watch.FileChanges, error)
@@ -431,6 +432,96 @@ func (d *AllocDir) ReadAt(path string, offset int64) (io.ReadCloser, error) {
 	return f, nil
 }
 
+// Archive writes a tar archive of the file or directory at the path relative
+// to the alloc dir, recursing into directories. Entries are named relative to
+// the alloc dir and the secret directories of the tasks are skipped.
+func (d *AllocDir) Archive(path string, w io.Writer) error {
+	if escapes, err := structs.PathEscapesAllocDir("", path); err != nil {
+		return fmt.Errorf("Failed to check if path escapes alloc directory: %v", err)
+	} else if escapes {
+		return fmt.Errorf("Path escapes the alloc directory")
+	}
+
+	root := filepath.Join(d.AllocDir, path)
+
+	d.mu.RLock()
+	var secretDirs []string
+	for _, dir := range d.TaskDirs {
+		secretDirs = append(secretDirs, dir.SecretsDir)
+	}
+	d.mu.RUnlock()
+
+	isSecret := func(p string) bool {
+		for _, dir := range secretDirs {
+			if filepath.HasPrefix(p, dir) {
+				return true
+			}
+		}
+		return false
+	}
+	if isSecret(root) {
+		return fmt.Errorf("Reading secret file prohibited: %s", path)
+	}
+	if _, err := os.Lstat(root); err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	walkFn := func(p string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if isSecret(p) {
+			if fileInfo.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(d.AllocDir, p)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if fileInfo.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return fmt.Errorf("error reading symlink: %v", err)
+			}
+		}
+		hdr, err := tar.FileInfoHeader(fileInfo, link)
+		if err != nil {
+			return fmt.Errorf("error creating file header: %v", err)
+		}
+		hdr.Name = filepath.ToSlash(relPath)
+		if fileInfo.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		// Only regular files have contents
+		if !fileInfo.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// Copy exactly the size in the header in case the file is growing
+		_, err = io.CopyN(tw, file, hdr.Size)
+		return err
+	}
+
+	if err := filepath.Walk(root, walkFn); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
 // BlockUntilExists blocks until the passed file relative the allocation
 // directory exists. The block can be cancelled with the passed context.
 func (d *AllocDir) BlockUntilExists(ctx context.Context, path string) (chan error, error) {
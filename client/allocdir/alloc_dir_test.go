@@ -355,6 +355,75 @@ func TestAllocDir_ReadAt_SecretDir(t *testing.T) {
 	}
 }
 
+func TestAllocDir_Archive(t *testing.T) {
+	require := require.New(t)
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	require.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(testlog.HCLogger(t), tmp)
+	require.NoError(d.Build())
+	defer d.Destroy()
+
+	td := d.NewTaskDir(t1.Name)
+	require.NoError(td.Build(false, nil))
+
+	// Write files to the shared data dir, the task local dir and the task
+	// secrets dir
+	require.NoError(os.MkdirAll(filepath.Join(d.SharedDir, SharedDataDir, "sub"), 0777))
+	require.NoError(ioutil.WriteFile(filepath.Join(d.SharedDir, SharedDataDir, "sub", "bar"), []byte("foo"), 0666))
+	require.NoError(ioutil.WriteFile(filepath.Join(td.LocalDir, "lol"), []byte("bar"), 0666))
+	require.NoError(ioutil.WriteFile(filepath.Join(td.SecretsDir, "token"), []byte("secret"), 0666))
+
+	archive := func(path string) map[string]string {
+		var b bytes.Buffer
+		require.NoError(d.Archive(path, &b))
+
+		files := make(map[string]string)
+		tr := tar.NewReader(&b)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(err)
+			contents, err := ioutil.ReadAll(tr)
+			require.NoError(err)
+			files[hdr.Name] = string(contents)
+		}
+		return files
+	}
+
+	// Archiving a directory recurses into it
+	files := archive("alloc/data")
+	require.Equal(map[string]string{
+		"alloc/data/":        "",
+		"alloc/data/sub/":    "",
+		"alloc/data/sub/bar": "foo",
+	}, files)
+
+	// Archiving a file only includes it
+	files = archive("web/local/lol")
+	require.Equal(map[string]string{"web/local/lol": "bar"}, files)
+
+	// Secrets are skipped when archiving the whole alloc dir
+	files = archive("/")
+	require.Equal("foo", files["alloc/data/sub/bar"])
+	require.Equal("bar", files["web/local/lol"])
+	for name := range files {
+		require.False(strings.Contains(name, TaskSecrets), "archived secret %q", name)
+	}
+
+	// Archiving the secrets dir or escaping the alloc dir fails
+	err = d.Archive(filepath.Join(t1.Name, TaskSecrets), ioutil.Discard)
+	require.Error(err)
+	require.Contains(err.Error(), "secret file prohibited")
+
+	err = d.Archive("../foo", ioutil.Discard)
+	require.Error(err)
+	require.Contains(err.Error(), "escapes")
+}
+
 func TestAllocDir_SplitPath(t *testing.T) {
 	dir, err := ioutil.TempDir("", "tmpdirtest")
 	if err != nil {
@@ -0,0 +1,204 @@
+package allocdir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// ChrootCacheDirName is the name of the directory within the client's
+	// alloc dir that holds the chroot skeletons.
+	ChrootCacheDirName = "chroot_cache"
+)
+
+// ChrootCache keeps the chroot skeletons of tasks using chroot filesystem
+// isolation. A skeleton is embedded from the host once per chroot
+// configuration and then hardlinked into each task directory, which avoids
+// walking and possibly copying the host paths for every task.
+//
+// Skeletons are keyed by a hash of the chroot configuration, so changing the
+// client's chroot_env builds a new skeleton. Changes to the embedded host files
+// are not detected; remove the cache directory to rebuild the skeletons.
+type ChrootCache struct {
+	// Dir is the directory the skeletons are stored in. It should be on the
+	// same filesystem as the allocation directories so skeletons can be
+	// hardlinked into them rather than copied.
+	Dir string
+
+	logger hclog.Logger
+
+	// buildLock serializes building skeletons so concurrently starting tasks
+	// wait for a single build.
+	buildLock sync.Mutex
+}
+
+// NewChrootCache returns a cache storing the chroot skeletons in dir.
+func NewChrootCache(logger hclog.Logger, dir string) *ChrootCache {
+	return &ChrootCache{
+		Dir:    dir,
+		logger: logger.Named("chroot_cache"),
+	}
+}
+
+// Prune removes the skeletons built for any chroot configuration other than
+// the given one as well as the remains of interrupted builds.
+func (c *ChrootCache) Prune(chroot map[string]string) error {
+	c.buildLock.Lock()
+	defer c.buildLock.Unlock()
+
+	entries, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read chroot cache: %v", err)
+	}
+
+	key := chrootKey(chroot)
+	for _, entry := range entries {
+		if entry.Name() == key {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(c.Dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove stale chroot skeleton %q: %v", entry.Name(), err)
+		}
+		c.logger.Debug("removed stale chroot skeleton", "key", entry.Name())
+	}
+
+	return nil
+}
+
+// Embed links the skeleton of the chroot into dir, building the skeleton from
+// the host first if it doesn't exist yet.
+func (c *ChrootCache) Embed(dir string, chroot map[string]string) error {
+	skeleton, err := c.skeleton(chroot)
+	if err != nil {
+		return err
+	}
+
+	return linkTree(skeleton, dir)
+}
+
+// skeleton returns the path of the skeleton of the chroot, building it if
+// necessary.
+func (c *ChrootCache) skeleton(chroot map[string]string) (string, error) {
+	c.buildLock.Lock()
+	defer c.buildLock.Unlock()
+
+	path := filepath.Join(c.Dir, chrootKey(chroot))
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create chroot cache: %v", err)
+	}
+
+	// Build the skeleton in a temporary directory and move it in place once
+	// complete so an interrupted build is never used.
+	start := time.Now()
+	tmp, err := ioutil.TempDir(c.Dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create chroot skeleton: %v", err)
+	}
+
+	if err := embedDirs(tmp, chroot); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("failed to build chroot skeleton: %v", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("failed to store chroot skeleton: %v", err)
+	}
+
+	c.logger.Debug("built chroot skeleton", "path", path, "duration", time.Since(start))
+	return path, nil
+}
+
+// chrootKey returns the key of the skeleton of a chroot configuration.
+func chrootKey(chroot map[string]string) string {
+	sources := make([]string, 0, len(chroot))
+	for source := range chroot {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	h := sha256.New()
+	for _, source := range sources {
+		fmt.Fprintf(h, "%s\x00%s\x00", source, chroot[source])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// linkTree recreates the directories and symlinks of src in dst and hardlinks
+// its files, falling back to copying them. Entries already existing in dst are
+// kept, which happens when a failed task is restarted.
+func linkTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		dest := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			if pathExists(dest) {
+				return nil
+			}
+			if err := os.MkdirAll(dest, info.Mode()); err != nil {
+				return fmt.Errorf("Couldn't create destination directory %v: %v", dest, err)
+			}
+			if uid, gid := getOwner(info); uid != idUnsupported && gid != idUnsupported {
+				if err := os.Chown(dest, uid, gid); err != nil {
+					return err
+				}
+			}
+
+			// Let the files of the skeleton be hardlinked into the task
+			// directory despite the allocation's project quota
+			if !strings.Contains(rel, string(filepath.Separator)) {
+				if err := clearProjectQuota(dest); err != nil {
+					return err
+				}
+			}
+		case info.Mode()&os.ModeSymlink != 0:
+			if _, err := os.Lstat(dest); err == nil {
+				return nil
+			}
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("Couldn't resolve symlink for %v: %v", path, err)
+			}
+			if err := os.Symlink(link, dest); err != nil {
+				return fmt.Errorf("Couldn't create symlink: %v", err)
+			}
+		case info.Mode().IsRegular():
+			uid, gid := getOwner(info)
+			if err := linkOrCopy(path, dest, uid, gid, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
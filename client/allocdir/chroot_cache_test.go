@@ -0,0 +1,97 @@
+package allocdir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the chroot skeleton is built once and linked into task dirs.
+func TestChrootCache_Embed(t *testing.T) {
+	require := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "ChrootCache")
+	require.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	// Create a fake host directory with a file, a symlink and a subfolder
+	// that contains a file.
+	host, err := ioutil.TempDir("", "ChrootCacheHost")
+	require.NoError(err)
+	defer os.RemoveAll(host)
+
+	require.NoError(os.MkdirAll(filepath.Join(host, "subdir"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(host, "file"), []byte("foo"), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(host, "subdir", "file"), []byte("bar"), 0644))
+	require.NoError(os.Symlink("file", filepath.Join(host, "link")))
+
+	cache := NewChrootCache(testlog.HCLogger(t), filepath.Join(tmp, ChrootCacheDirName))
+	chroot := map[string]string{host: "/host"}
+
+	task1 := filepath.Join(tmp, "task1")
+	task2 := filepath.Join(tmp, "task2")
+	require.NoError(cache.Embed(task1, chroot))
+	require.NoError(cache.Embed(task2, chroot))
+
+	// Embedding again, as when restarting a task, is a no-op
+	require.NoError(cache.Embed(task1, chroot))
+
+	skeleton := filepath.Join(cache.Dir, chrootKey(chroot))
+	for _, file := range []string{"file", filepath.Join("subdir", "file")} {
+		sfi, err := os.Stat(filepath.Join(skeleton, "host", file))
+		require.NoError(err)
+
+		for _, task := range []string{task1, task2} {
+			fi, err := os.Stat(filepath.Join(task, "host", file))
+			require.NoError(err)
+			require.True(os.SameFile(sfi, fi), "%s not linked from skeleton", file)
+		}
+	}
+
+	link, err := os.Readlink(filepath.Join(task2, "host", "link"))
+	require.NoError(err)
+	require.Equal("file", link)
+
+	// Only one skeleton was built
+	entries, err := ioutil.ReadDir(cache.Dir)
+	require.NoError(err)
+	require.Len(entries, 1)
+}
+
+// Test that changing the chroot builds a new skeleton and pruning removes the
+// stale ones.
+func TestChrootCache_Prune(t *testing.T) {
+	require := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "ChrootCache")
+	require.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	host, err := ioutil.TempDir("", "ChrootCacheHost")
+	require.NoError(err)
+	defer os.RemoveAll(host)
+	require.NoError(ioutil.WriteFile(filepath.Join(host, "file"), []byte("foo"), 0644))
+
+	cache := NewChrootCache(testlog.HCLogger(t), filepath.Join(tmp, ChrootCacheDirName))
+
+	// Pruning a cache that doesn't exist yet is a no-op
+	require.NoError(cache.Prune(nil))
+
+	old := map[string]string{host: "/old"}
+	current := map[string]string{host: "/new"}
+	require.NotEqual(chrootKey(old), chrootKey(current))
+
+	require.NoError(cache.Embed(filepath.Join(tmp, "task1"), old))
+	require.NoError(cache.Embed(filepath.Join(tmp, "task2"), current))
+	require.FileExists(filepath.Join(tmp, "task2", "new", "file"))
+
+	require.NoError(cache.Prune(current))
+	entries, err := ioutil.ReadDir(cache.Dir)
+	require.NoError(err)
+	require.Len(entries, 1)
+	require.Equal(chrootKey(current), entries[0].Name())
+}
@@ -0,0 +1,59 @@
+package allocdir
+
+import (
+	"hash/fnv"
+	"sync"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// projectIDBase is the first project ID assigned to allocation
+	// directories. It is high enough to stay clear of the projects an
+	// operator defines in /etc/projid.
+	projectIDBase = 1 << 30
+
+	// projectIDRange is the number of project IDs assigned to allocation
+	// directories.
+	projectIDRange = 1 << 24
+
+	// projectIDProbes is the number of project IDs tried before giving up on
+	// finding an unused one.
+	projectIDProbes = 64
+)
+
+// ProjectQuota enforces the ephemeral disk size of allocations with the
+// project quotas of the filesystem holding the allocation directories. Each
+// allocation directory is assigned its own project, inherited by everything
+// created in it, whose block limit is the disk size of the allocation.
+type ProjectQuota struct {
+	// device is the block device of the filesystem the quotas are set on.
+	device string
+
+	logger hclog.Logger
+
+	// lock serializes assigning project IDs.
+	lock sync.Mutex
+}
+
+// DiskUsage is the disk usage of an allocation directory as accounted by its
+// project.
+type DiskUsage struct {
+	// UsedBytes is the space used by the directory.
+	UsedBytes uint64
+
+	// LimitBytes is the space the directory may use.
+	LimitBytes uint64
+
+	// Inodes is the number of inodes used by the directory.
+	Inodes uint64
+}
+
+// projectID returns the project ID to try for an allocation on the given
+// attempt. IDs are derived from the allocation ID so concurrent clients
+// sharing a filesystem rarely contend.
+func projectID(allocID string, attempt int) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(allocID))
+	return projectIDBase + (h.Sum32()+uint32(attempt))%projectIDRange
+}
@@ -0,0 +1,35 @@
+// +build !linux
+
+package allocdir
+
+import (
+	"fmt"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+// NewProjectQuota returns an error as project quotas are only supported on
+// Linux.
+func NewProjectQuota(logger hclog.Logger, allocDir string) (*ProjectQuota, error) {
+	return nil, fmt.Errorf("project quotas are only supported on Linux")
+}
+
+// Apply is unreachable as a ProjectQuota can't be created on this platform.
+func (q *ProjectQuota) Apply(dir, allocID string, sizeMB int64) (uint32, error) {
+	return 0, fmt.Errorf("project quotas are only supported on Linux")
+}
+
+// Usage is unreachable as a ProjectQuota can't be created on this platform.
+func (q *ProjectQuota) Usage(id uint32) (*DiskUsage, error) {
+	return nil, fmt.Errorf("project quotas are only supported on Linux")
+}
+
+// Release is unreachable as a ProjectQuota can't be created on this platform.
+func (q *ProjectQuota) Release(id uint32) error {
+	return nil
+}
+
+// clearProjectQuota is a noop as project quotas are only supported on Linux.
+func clearProjectQuota(dir string) error {
+	return nil
+}
@@ -0,0 +1,333 @@
+package allocdir
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// fsIocFsgetxattr and fsIocFssetxattr are the FS_IOC_FSGETXATTR and
+	// FS_IOC_FSSETXATTR ioctls getting and setting the project of an inode.
+	fsIocFsgetxattr = 0x801c581f
+	fsIocFssetxattr = 0x401c5820
+
+	// fsXflagProjinherit is FS_XFLAG_PROJINHERIT, which makes the inodes
+	// created in a directory inherit its project.
+	fsXflagProjinherit = 0x200
+
+	// quotactl commands and flags, see quotactl(2)
+	qGetinfo   = 0x800005
+	qGetquota  = 0x800007
+	qSetquota  = 0x800008
+	prjQuota   = 2
+	qifBlimits = 1
+	qifIlimits = 4
+
+	// quotaBlockSize is the size of the blocks dqb_bhardlimit is set in.
+	quotaBlockSize = 1024
+)
+
+// fsxattr is struct fsxattr of linux/fs.h.
+type fsxattr struct {
+	Xflags     uint32
+	Extsize    uint32
+	Nextents   uint32
+	Projid     uint32
+	Cowextsize uint32
+	Pad        [8]byte
+}
+
+// ifDqblk is struct if_dqblk of linux/quota.h.
+type ifDqblk struct {
+	Bhardlimit uint64
+	Bsoftlimit uint64
+	Curspace   uint64
+	Ihardlimit uint64
+	Isoftlimit uint64
+	Curinodes  uint64
+	Btime      uint64
+	Itime      uint64
+	Valid      uint32
+}
+
+// ifDqinfo is struct if_dqinfo of linux/quota.h.
+type ifDqinfo struct {
+	Bgrace uint64
+	Igrace uint64
+	Flags  uint32
+	Valid  uint32
+}
+
+// NewProjectQuota returns a ProjectQuota for the filesystem holding allocDir
+// or an error if project quotas can't be enforced on it. The filesystem must
+// be XFS or ext4 mounted with project quotas enabled, and the client must run
+// as root.
+func NewProjectQuota(logger hclog.Logger, allocDir string) (*ProjectQuota, error) {
+	if unix.Geteuid() != 0 {
+		return nil, fmt.Errorf("project quotas require running as root")
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(allocDir, &st); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	device, fstype, err := mountInfoDevice(f, unix.Major(uint64(st.Dev)), unix.Minor(uint64(st.Dev)))
+	if err != nil {
+		return nil, err
+	}
+	if fstype != "xfs" && fstype != "ext4" {
+		return nil, fmt.Errorf("project quotas are not supported on %s filesystems", fstype)
+	}
+
+	var info ifDqinfo
+	if err := quotactl(qGetinfo, device, 0, unsafe.Pointer(&info)); err != nil {
+		return nil, fmt.Errorf("project quotas are not enabled on %s: %v", device, err)
+	}
+
+	return &ProjectQuota{
+		device: device,
+		logger: logger.Named("project_quota"),
+	}, nil
+}
+
+// Apply assigns a project to the directory of an allocation, limited to the
+// given size. The project is inherited by everything created in the directory
+// afterwards and applied to what it already contains. If the directory was
+// assigned a project before, as when the client restarts, the project is kept
+// and only its limit is updated. Apply returns the ID of the project.
+func (q *ProjectQuota) Apply(dir, allocID string, sizeMB int64) (uint32, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	attr, err := getFsxattr(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project of %q: %v", dir, err)
+	}
+
+	id := attr.Projid
+	assigned := id != 0 && attr.Xflags&fsXflagProjinherit != 0
+	if !assigned {
+		if id, err = q.unusedProjectID(allocID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := q.setLimit(id, uint64(sizeMB)*1024*1024/quotaBlockSize); err != nil {
+		return 0, fmt.Errorf("failed to set limit of project %d: %v", id, err)
+	}
+
+	if !assigned {
+		if err := setProjectTree(dir, id); err != nil {
+			return 0, fmt.Errorf("failed to assign project %d to %q: %v", id, dir, err)
+		}
+		q.logger.Debug("assigned project to alloc dir", "alloc_id", allocID, "project_id", id, "size_mb", sizeMB)
+	}
+
+	return id, nil
+}
+
+// Usage returns the disk usage accounted to a project.
+func (q *ProjectQuota) Usage(id uint32) (*DiskUsage, error) {
+	var dq ifDqblk
+	if err := quotactl(qGetquota, q.device, id, unsafe.Pointer(&dq)); err != nil {
+		return nil, err
+	}
+
+	return &DiskUsage{
+		UsedBytes:  dq.Curspace,
+		LimitBytes: dq.Bhardlimit * quotaBlockSize,
+		Inodes:     dq.Curinodes,
+	}, nil
+}
+
+// Release removes the limit of a project once the directory of its
+// allocation has been destroyed, making the project ID available again.
+func (q *ProjectQuota) Release(id uint32) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.setLimit(id, 0)
+}
+
+// unusedProjectID returns a project ID for the allocation that has neither a
+// limit nor any usage.
+func (q *ProjectQuota) unusedProjectID(allocID string) (uint32, error) {
+	for i := 0; i < projectIDProbes; i++ {
+		id := projectID(allocID, i)
+
+		var dq ifDqblk
+		if err := quotactl(qGetquota, q.device, id, unsafe.Pointer(&dq)); err != nil {
+			return 0, fmt.Errorf("failed to get quota of project %d: %v", id, err)
+		}
+		if dq.Bhardlimit == 0 && dq.Ihardlimit == 0 && dq.Curspace == 0 && dq.Curinodes == 0 {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to find an unused project ID after %d attempts", projectIDProbes)
+}
+
+// setLimit sets the block hard limit of a project, in blocks of
+// quotaBlockSize. A limit of zero removes the limit.
+func (q *ProjectQuota) setLimit(id uint32, blocks uint64) error {
+	dq := ifDqblk{
+		Bhardlimit: blocks,
+		Valid:      qifBlimits | qifIlimits,
+	}
+	return quotactl(qSetquota, q.device, id, unsafe.Pointer(&dq))
+}
+
+// setProjectTree assigns the project to dir and everything it contains on the
+// same filesystem. Directories are made to pass the project on to the inodes
+// created in them. Files with more than one link, such as the files of a
+// chroot hardlinked from the host, are left alone as they aren't owned by the
+// allocation.
+func setProjectTree(dir string, id uint32) error {
+	var root unix.Stat_t
+	if err := unix.Stat(dir, &root); err != nil {
+		return err
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return nil
+		}
+
+		switch {
+		case uint64(st.Dev) != uint64(root.Dev):
+			// Skip mounts such as the secrets tmpfs
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+		case info.IsDir():
+			return setProject(path, id, true)
+		case info.Mode().IsRegular() && st.Nlink == 1:
+			return setProject(path, id, false)
+		}
+		return nil
+	})
+}
+
+// clearProjectQuota removes a directory from the project it inherited so the
+// files of the host can be hardlinked into it. Linking a file into a directory
+// that passes on a different project fails, which would make the chroot of a
+// task be copied and count against the allocation's disk.
+func clearProjectQuota(dir string) error {
+	attr, err := getFsxattr(dir)
+	if err != nil {
+		// The filesystem doesn't support projects
+		if err == unix.ENOTTY || err == unix.EOPNOTSUPP || err == unix.EINVAL {
+			return nil
+		}
+		return err
+	}
+
+	if attr.Projid == 0 && attr.Xflags&fsXflagProjinherit == 0 {
+		return nil
+	}
+	return setProject(dir, 0, false)
+}
+
+// setProject sets the project of the inode at path, and whether it is
+// inherited by the inodes created in it.
+func setProject(path string, id uint32, inherit bool) error {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var attr fsxattr
+	if err := ioctl(f.Fd(), fsIocFsgetxattr, unsafe.Pointer(&attr)); err != nil {
+		return err
+	}
+
+	attr.Projid = id
+	if inherit {
+		attr.Xflags |= fsXflagProjinherit
+	} else {
+		attr.Xflags &^= fsXflagProjinherit
+	}
+	return ioctl(f.Fd(), fsIocFssetxattr, unsafe.Pointer(&attr))
+}
+
+// getFsxattr returns the extended attributes holding the project of the
+// inode at path.
+func getFsxattr(path string) (*fsxattr, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var attr fsxattr
+	if err := ioctl(f.Fd(), fsIocFsgetxattr, unsafe.Pointer(&attr)); err != nil {
+		return nil, err
+	}
+	return &attr, nil
+}
+
+func ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func quotactl(cmd int, device string, id uint32, addr unsafe.Pointer) error {
+	dev, err := unix.BytePtrFromString(device)
+	if err != nil {
+		return err
+	}
+
+	qcmd := cmd<<8 | prjQuota
+	if _, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, uintptr(qcmd), uintptr(unsafe.Pointer(dev)), uintptr(id), uintptr(addr), 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// mountInfoDevice returns the source device and filesystem type of the mount
+// with the given device numbers from the contents of /proc/self/mountinfo.
+func mountInfoDevice(r io.Reader, major, minor uint32) (string, string, error) {
+	want := fmt.Sprintf("%d:%d", major, minor)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != want {
+			continue
+		}
+
+		for i, f := range fields {
+			if f == "-" && i+2 < len(fields) {
+				return fields[i+2], fields[i+1], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+
+	return "", "", fmt.Errorf("no mount found for device %s", want)
+}
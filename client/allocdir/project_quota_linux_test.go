@@ -0,0 +1,87 @@
+package allocdir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectQuota_MountInfoDevice(t *testing.T) {
+	require := require.New(t)
+
+	mountinfo := `18 41 0:17 / /sys rw,nosuid,nodev,noexec,relatime shared:6 - sysfs sysfs rw
+41 0 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/root rw,attr2,inode64,prjquota
+50 41 8:1 / /var/lib/nomad rw,relatime shared:30 - ext4 /dev/sda1 rw,prjquota
+`
+
+	device, fstype, err := mountInfoDevice(strings.NewReader(mountinfo), 8, 1)
+	require.NoError(err)
+	require.Equal("/dev/sda1", device)
+	require.Equal("ext4", fstype)
+
+	device, fstype, err = mountInfoDevice(strings.NewReader(mountinfo), 253, 0)
+	require.NoError(err)
+	require.Equal("/dev/mapper/root", device)
+	require.Equal("xfs", fstype)
+
+	_, _, err = mountInfoDevice(strings.NewReader(mountinfo), 8, 2)
+	require.Error(err)
+}
+
+// TestProjectQuota_Apply requires running as root on a filesystem with project
+// quotas enabled, given by NOMAD_TEST_PROJECT_QUOTA_DIR.
+func TestProjectQuota_Apply(t *testing.T) {
+	base := os.Getenv("NOMAD_TEST_PROJECT_QUOTA_DIR")
+	if base == "" {
+		t.Skip("NOMAD_TEST_PROJECT_QUOTA_DIR not set")
+	}
+	require := require.New(t)
+
+	quota, err := NewProjectQuota(testlog.HCLogger(t), base)
+	require.NoError(err)
+
+	tmp, err := ioutil.TempDir(base, "ProjectQuota")
+	require.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	// Existing files are assigned the project
+	require.NoError(ioutil.WriteFile(filepath.Join(tmp, "existing"), make([]byte, 512*1024), 0644))
+
+	allocID := uuid.Generate()
+	id, err := quota.Apply(tmp, allocID, 1)
+	require.NoError(err)
+	require.NotZero(id)
+
+	usage, err := quota.Usage(id)
+	require.NoError(err)
+	require.Equal(uint64(1024*1024), usage.LimitBytes)
+	require.True(usage.UsedBytes >= 512*1024, "existing file not accounted: %d", usage.UsedBytes)
+
+	// Writing past the limit fails
+	err = ioutil.WriteFile(filepath.Join(tmp, "new"), make([]byte, 2*1024*1024), 0644)
+	require.Error(err)
+
+	// Applying again keeps the project
+	again, err := quota.Apply(tmp, allocID, 2)
+	require.NoError(err)
+	require.Equal(id, again)
+
+	require.NoError(os.RemoveAll(tmp))
+	require.NoError(quota.Release(id))
+}
+
+func TestProjectQuota_ClearProjectQuota(t *testing.T) {
+	// Clearing a directory without a project is a noop, even as a non-root
+	// user or on filesystems without projects
+	tmp, err := ioutil.TempDir("", "ProjectQuota")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	require.NoError(t, clearProjectQuota(tmp))
+}
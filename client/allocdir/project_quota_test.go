@@ -0,0 +1,24 @@
+package allocdir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectQuota_ProjectID(t *testing.T) {
+	require := require.New(t)
+
+	allocID := "f2a6a5f4-8d2c-4d24-8a57-5e1f10e7d1e9"
+	seen := make(map[uint32]struct{})
+	for i := 0; i < projectIDProbes; i++ {
+		id := projectID(allocID, i)
+		require.True(id >= projectIDBase && id < projectIDBase+projectIDRange, "id %d out of range", id)
+		require.Equal(id, projectID(allocID, i), "ids must be deterministic")
+		seen[id] = struct{}{}
+	}
+
+	// Every attempt tries another ID
+	require.Len(seen, projectIDProbes)
+	require.NotEqual(projectID(allocID, 0), projectID("0b9bb67e-4c45-4bde-9e0d-7c8a4c9c0a4e", 0))
+}
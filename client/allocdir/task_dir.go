@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	hclog "github.com/hashicorp/go-hclog"
 )
@@ -75,6 +76,13 @@ func (t *TaskDir) Copy() *TaskDir {
 // allows skipping chroot creation if the caller knows it has already been
 // done.
 func (t *TaskDir) Build(createChroot bool, chroot map[string]string) error {
+	return t.BuildWithCache(createChroot, chroot, nil)
+}
+
+// BuildWithCache builds the task directory like Build but links the chroot
+// from the skeleton kept by the cache rather than embedding every entry from
+// the host. If the cache is nil the chroot is embedded from the host.
+func (t *TaskDir) BuildWithCache(createChroot bool, chroot map[string]string, cache *ChrootCache) error {
 	if err := os.MkdirAll(t.Dir, 0777); err != nil {
 		return err
 	}
@@ -130,7 +138,7 @@ func (t *TaskDir) Build(createChroot bool, chroot map[string]string) error {
 
 	// Build chroot if chroot filesystem isolation is going to be used
 	if createChroot {
-		if err := t.buildChroot(chroot); err != nil {
+		if err := t.buildChroot(chroot, cache); err != nil {
 			return err
 		}
 	}
@@ -141,12 +149,22 @@ func (t *TaskDir) Build(createChroot bool, chroot map[string]string) error {
 // buildChroot takes a mapping of absolute directory or file paths on the host
 // to their intended, relative location within the task directory. This
 // attempts hardlink and then defaults to copying. If the path exists on the
-// host and can't be embedded an error is returned.
-func (t *TaskDir) buildChroot(entries map[string]string) error {
+// host and can't be embedded an error is returned. If a cache is given the
+// chroot is linked from its skeleton instead.
+func (t *TaskDir) buildChroot(entries map[string]string, cache *ChrootCache) error {
+	if cache != nil {
+		return cache.Embed(t.Dir, entries)
+	}
 	return t.embedDirs(entries)
 }
 
 func (t *TaskDir) embedDirs(entries map[string]string) error {
+	return embedDirs(t.Dir, entries)
+}
+
+// embedDirs embeds the host paths of entries into their relative location
+// within dir.
+func embedDirs(dir string, entries map[string]string) error {
 	subdirs := make(map[string]string)
 	for source, dest := range entries {
 		// Check to see if directory exists on host.
@@ -157,12 +175,12 @@ func (t *TaskDir) embedDirs(entries map[string]string) error {
 
 		// Embedding a single file
 		if !s.IsDir() {
-			if err := createDir(t.Dir, filepath.Dir(dest)); err != nil {
+			if err := createChrootDir(dir, filepath.Dir(dest)); err != nil {
 				return fmt.Errorf("Couldn't create destination directory %v: %v", dest, err)
 			}
 
 			// Copy the file.
-			taskEntry := filepath.Join(t.Dir, dest)
+			taskEntry := filepath.Join(dir, dest)
 			uid, gid := getOwner(s)
 			if err := linkOrCopy(source, taskEntry, uid, gid, s.Mode().Perm()); err != nil {
 				return err
@@ -172,9 +190,9 @@ func (t *TaskDir) embedDirs(entries map[string]string) error {
 		}
 
 		// Create destination directory.
-		destDir := filepath.Join(t.Dir, dest)
+		destDir := filepath.Join(dir, dest)
 
-		if err := createDir(t.Dir, dest); err != nil {
+		if err := createChrootDir(dir, dest); err != nil {
 			return fmt.Errorf("Couldn't create destination directory %v: %v", destDir, err)
 		}
 
@@ -227,8 +245,25 @@ func (t *TaskDir) embedDirs(entries map[string]string) error {
 
 	// Recurse on self to copy subdirectories.
 	if len(subdirs) != 0 {
-		return t.embedDirs(subdirs)
+		return embedDirs(dir, subdirs)
 	}
 
 	return nil
 }
+
+// createChrootDir creates the destination directory of a chroot entry within
+// dir. Its top level directory is removed from the allocation's project quota
+// so the files of the host can be hardlinked into it.
+func createChrootDir(dir, dest string) error {
+	rel := strings.TrimPrefix(filepath.Clean(dest), string(filepath.Separator))
+	if top := strings.SplitN(rel, string(filepath.Separator), 2)[0]; top != "" && top != "." {
+		if err := createDir(dir, string(filepath.Separator)+top); err != nil {
+			return err
+		}
+		if err := clearProjectQuota(filepath.Join(dir, top)); err != nil {
+			return err
+		}
+	}
+
+	return createDir(dir, dest)
+}
@@ -0,0 +1,293 @@
+package allocrunner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/config"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/client/taskenv"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// AllocHookEventEnv is set to the lifecycle event the alloc hook
+	// command is run for.
+	AllocHookEventEnv = "NOMAD_ALLOC_HOOK_EVENT"
+
+	// allocHookMaxOutput is the number of bytes of command output included
+	// in hook failure messages.
+	allocHookMaxOutput = 512
+)
+
+// taskKiller is able to emit events on and kill all of an allocation's
+// tasks.
+type taskKiller interface {
+	// EmitEvent emits the event on all tasks.
+	EmitEvent(event *structs.TaskEvent)
+
+	// KillTasks emits the event on all tasks and then kills them.
+	KillTasks(event *structs.TaskEvent)
+}
+
+// allocExecHook runs the operator configured alloc hook commands. Poststart
+// commands are run once the allocation is running and prestop commands are
+// run before its tasks are killed. Prestop commands are also run after the
+// allocation's tasks exit on their own so that anything done by the
+// poststart commands is undone.
+type allocExecHook struct {
+	alloc    *structs.Allocation
+	allocDir *allocdir.AllocDir
+
+	poststart []*config.AllocHookConfig
+	prestop   []*config.AllocHookConfig
+
+	killer taskKiller
+
+	// listener is used to detect when the allocation is running and is
+	// closed when the hook stops watching.
+	listener *cstructs.AllocListener
+
+	// hookLock serializes poststart and prestop commands and guards started
+	// and stopped.
+	hookLock sync.Mutex
+
+	// started is set once the poststart commands have run.
+	started bool
+
+	// stopped is set once the prestop commands have run or should no
+	// longer run.
+	stopped bool
+
+	// cancelFn stops the poststart watcher. Wait on watchDone to block
+	// until it exits.
+	cancelFn context.CancelFunc
+
+	// watchDone is closed when the poststart watcher exits. Initialized
+	// already closed in case Postrun is called without Prerun.
+	watchDone chan struct{}
+
+	logger log.Logger
+}
+
+func newAllocExecHook(logger log.Logger, hooks []*config.AllocHookConfig, alloc *structs.Allocation,
+	allocDir *allocdir.AllocDir, killer taskKiller, listener *cstructs.AllocListener) interfaces.RunnerHook {
+
+	if len(hooks) == 0 {
+		listener.Close()
+		return noopAllocExecHook{}
+	}
+
+	closedDone := make(chan struct{})
+	close(closedDone)
+
+	h := &allocExecHook{
+		alloc:     alloc,
+		allocDir:  allocDir,
+		killer:    killer,
+		listener:  listener,
+		cancelFn:  func() {}, // initialize to prevent nil func panics
+		watchDone: closedDone,
+	}
+
+	for _, hook := range hooks {
+		switch hook.Event {
+		case config.AllocHookEventPoststart:
+			h.poststart = append(h.poststart, hook)
+		case config.AllocHookEventPrestop:
+			h.prestop = append(h.prestop, hook)
+		}
+	}
+
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+func (h *allocExecHook) Name() string {
+	return "alloc_exec"
+}
+
+func (h *allocExecHook) Prerun(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancelFn = cancel
+	h.watchDone = make(chan struct{})
+	go h.watchStart(ctx, h.watchDone)
+	return nil
+}
+
+func (h *allocExecHook) Prestop(ctx context.Context) error {
+	h.hookLock.Lock()
+	defer h.hookLock.Unlock()
+
+	// Nothing to undo if the poststart commands never ran
+	if !h.started || h.stopped {
+		h.stopped = true
+		return nil
+	}
+	h.stopped = true
+
+	for _, hook := range h.prestop {
+		if err := h.exec(ctx, hook); err != nil {
+			h.handleError(hook, err)
+			if hook.OnFailure == config.AllocHookFailureFail {
+				return fmt.Errorf("alloc hook %q failed: %v", hook.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h *allocExecHook) Postrun() error {
+	h.stopWatching()
+
+	// Tasks exited on their own so prestop was never called
+	if err := h.Prestop(context.Background()); err != nil {
+		h.logger.Warn("error running prestop commands after tasks exited", "error", err)
+	}
+
+	return nil
+}
+
+func (h *allocExecHook) Shutdown() {
+	// Tasks are left running when the agent shuts down, so only stop
+	// watching.
+	h.stopWatching()
+}
+
+// stopWatching cancels the poststart watcher and waits for it to exit.
+func (h *allocExecHook) stopWatching() {
+	h.cancelFn()
+	h.listener.Close()
+	<-h.watchDone
+}
+
+// watchStart waits for the allocation to be running before running the
+// poststart commands. It exits without running them if the allocation
+// becomes terminal first or the context is canceled.
+func (h *allocExecHook) watchStart(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alloc, ok := <-h.listener.Ch():
+			if !ok {
+				return
+			}
+
+			switch alloc.ClientStatus {
+			case structs.AllocClientStatusRunning:
+				h.runPoststart(ctx)
+				return
+			case structs.AllocClientStatusComplete, structs.AllocClientStatusFailed, structs.AllocClientStatusLost:
+				return
+			}
+		}
+	}
+}
+
+// runPoststart runs the poststart commands in order. If a command with the
+// fail policy fails the allocation's tasks are killed.
+func (h *allocExecHook) runPoststart(ctx context.Context) {
+	h.hookLock.Lock()
+	defer h.hookLock.Unlock()
+
+	// Allocation is already being stopped
+	if h.stopped {
+		return
+	}
+
+	for _, hook := range h.poststart {
+		err := h.exec(ctx, hook)
+		if err == nil {
+			continue
+		}
+
+		if hook.OnFailure != config.AllocHookFailureFail {
+			h.handleError(hook, err)
+			continue
+		}
+
+		h.logger.Error("poststart command failed; killing tasks", "hook", hook.Name, "error", err)
+		h.stopped = true
+		h.killer.KillTasks(allocHookFailedEvent(hook, err).SetFailsTask())
+		return
+	}
+
+	h.started = true
+}
+
+// handleError logs the error and emits a task event for commands whose
+// failure does not stop the allocation.
+func (h *allocExecHook) handleError(hook *config.AllocHookConfig, err error) {
+	h.logger.Warn("alloc hook command failed", "hook", hook.Name, "event", hook.Event, "error", err)
+	h.killer.EmitEvent(allocHookFailedEvent(hook, err))
+}
+
+// exec runs the hook's command and returns an error including its output if
+// it exits non-zero or does not finish before its timeout.
+func (h *allocExecHook) exec(ctx context.Context, hook *config.AllocHookConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+
+	h.logger.Debug("running alloc hook command", "hook", hook.Name, "event", hook.Event, "command", hook.Command)
+
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Env = append(os.Environ(), h.env(hook)...)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", hook.Timeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(string(out))
+		if len(output) > allocHookMaxOutput {
+			output = output[len(output)-allocHookMaxOutput:]
+		}
+		if output == "" {
+			return err
+		}
+		return fmt.Errorf("%v: %s", err, output)
+	}
+
+	return nil
+}
+
+// env returns the environment variables describing the allocation that are
+// passed to hook commands in addition to the client's environment.
+func (h *allocExecHook) env(hook *config.AllocHookConfig) []string {
+	env := []string{
+		fmt.Sprintf("%s=%s", AllocHookEventEnv, hook.Event),
+		fmt.Sprintf("%s=%s", taskenv.AllocID, h.alloc.ID),
+		fmt.Sprintf("%s=%s", taskenv.AllocName, h.alloc.Name),
+		fmt.Sprintf("%s=%s", taskenv.GroupName, h.alloc.TaskGroup),
+		fmt.Sprintf("%s=%s", taskenv.AllocDir, h.allocDir.SharedDir),
+	}
+	if h.alloc.Job != nil {
+		env = append(env, fmt.Sprintf("%s=%s", taskenv.JobName, h.alloc.Job.Name))
+	}
+	return env
+}
+
+// allocHookFailedEvent returns the task event emitted when an alloc hook
+// command fails.
+func allocHookFailedEvent(hook *config.AllocHookConfig, err error) *structs.TaskEvent {
+	message := fmt.Sprintf("alloc hook %q (%s): %v", hook.Name, hook.Event, err)
+	return structs.NewTaskEvent(structs.TaskHookFailed).SetMessage(message)
+}
+
+// noopAllocExecHook is an empty hook implementation returned by
+// newAllocExecHook when no alloc hooks are configured.
+type noopAllocExecHook struct{}
+
+func (noopAllocExecHook) Name() string {
+	return "alloc_exec"
+}
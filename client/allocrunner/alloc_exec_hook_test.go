@@ -0,0 +1,249 @@
+package allocrunner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/config"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// statically assert alloc exec hook implements the expected interfaces
+var _ interfaces.RunnerPrerunHook = (*allocExecHook)(nil)
+var _ interfaces.RunnerPrestopHook = (*allocExecHook)(nil)
+var _ interfaces.RunnerPostrunHook = (*allocExecHook)(nil)
+var _ interfaces.ShutdownHook = (*allocExecHook)(nil)
+
+// mockTaskKiller implements taskKiller and records the events it was given
+type mockTaskKiller struct {
+	events []*structs.TaskEvent
+	killed bool
+	mu     sync.Mutex
+}
+
+func (m *mockTaskKiller) EmitEvent(event *structs.TaskEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockTaskKiller) KillTasks(event *structs.TaskEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	m.killed = true
+}
+
+func (m *mockTaskKiller) state() ([]*structs.TaskEvent, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.events, m.killed
+}
+
+// newTestAllocExecHook returns an alloc exec hook with no commands along with
+// the broadcaster used to signal alloc state changes, the task killer, and a
+// directory the hook commands may write to. The caller must call the cleanup
+// func.
+func newTestAllocExecHook(t *testing.T) (*allocExecHook, *cstructs.AllocBroadcaster, *mockTaskKiller, string, func()) {
+	dir, err := ioutil.TempDir("", "nomad_alloc_exec_hook")
+	require.NoError(t, err)
+
+	logger := testlog.HCLogger(t)
+	alloc := mock.Alloc()
+	b := cstructs.NewAllocBroadcaster(logger)
+	killer := &mockTaskKiller{}
+	allocDir := allocdir.NewAllocDir(logger, filepath.Join(dir, alloc.ID))
+
+	// Commands are set by tests so a placeholder is needed to avoid the
+	// noop hook
+	hooks := []*config.AllocHookConfig{{Name: "placeholder"}}
+	h := newAllocExecHook(logger, hooks, alloc, allocDir, killer, b.Listen())
+	return h.(*allocExecHook), b, killer, dir, func() { os.RemoveAll(dir) }
+}
+
+// sendClientStatus broadcasts a copy of the hook's alloc with the given client
+// status.
+func sendClientStatus(t *testing.T, h *allocExecHook, b *cstructs.AllocBroadcaster, status string) {
+	alloc := h.alloc.Copy()
+	alloc.ClientStatus = status
+	require.NoError(t, b.Send(alloc))
+}
+
+func TestAllocExecHook_NoHooks(t *testing.T) {
+	t.Parallel()
+
+	logger := testlog.HCLogger(t)
+	b := cstructs.NewAllocBroadcaster(logger)
+	h := newAllocExecHook(logger, nil, mock.Alloc(), nil, &mockTaskKiller{}, b.Listen())
+	require.IsType(t, noopAllocExecHook{}, h)
+}
+
+func TestAllocExecHook_PoststartPrestop(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	h, b, killer, dir, cleanup := newTestAllocExecHook(t)
+	defer cleanup()
+	started := filepath.Join(dir, "started")
+	stopped := filepath.Join(dir, "stopped")
+	h.poststart = []*config.AllocHookConfig{{
+		Name:      "register",
+		Event:     config.AllocHookEventPoststart,
+		Command:   "/bin/sh",
+		Args:      []string{"-c", `echo -n "$NOMAD_ALLOC_HOOK_EVENT $NOMAD_ALLOC_ID" > ` + started},
+		Timeout:   5 * time.Second,
+		OnFailure: config.AllocHookFailureFail,
+	}}
+	h.prestop = []*config.AllocHookConfig{{
+		Name:      "deregister",
+		Event:     config.AllocHookEventPrestop,
+		Command:   "/bin/sh",
+		Args:      []string{"-c", `echo -n "$NOMAD_ALLOC_HOOK_EVENT $NOMAD_ALLOC_ID" > ` + stopped},
+		Timeout:   5 * time.Second,
+		OnFailure: config.AllocHookFailureIgnore,
+	}}
+
+	require.NoError(h.Prerun(context.Background()))
+
+	// Poststart commands only run once the alloc is running
+	sendClientStatus(t, h, b, structs.AllocClientStatusPending)
+	sendClientStatus(t, h, b, structs.AllocClientStatusRunning)
+
+	testutil.WaitForResult(func() (bool, error) {
+		h.hookLock.Lock()
+		defer h.hookLock.Unlock()
+		return h.started, nil
+	}, func(err error) {
+		t.Fatalf("poststart commands did not run")
+	})
+
+	out, err := ioutil.ReadFile(started)
+	require.NoError(err)
+	require.Equal("poststart "+h.alloc.ID, string(out))
+
+	require.NoError(h.Prestop(context.Background()))
+	out, err = ioutil.ReadFile(stopped)
+	require.NoError(err)
+	require.Equal("prestop "+h.alloc.ID, string(out))
+
+	// Prestop commands only run once
+	require.NoError(os.Remove(stopped))
+	require.NoError(h.Postrun())
+	_, err = os.Stat(stopped)
+	require.True(os.IsNotExist(err))
+
+	events, killed := killer.state()
+	require.Empty(events)
+	require.False(killed)
+}
+
+func TestAllocExecHook_Postrun_RunsPrestop(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	h, _, _, dir, cleanup := newTestAllocExecHook(t)
+	defer cleanup()
+	stopped := filepath.Join(dir, "stopped")
+	h.prestop = []*config.AllocHookConfig{{
+		Name:      "deregister",
+		Event:     config.AllocHookEventPrestop,
+		Command:   "/bin/sh",
+		Args:      []string{"-c", "touch " + stopped},
+		Timeout:   5 * time.Second,
+		OnFailure: config.AllocHookFailureIgnore,
+	}}
+
+	// Prestop commands are skipped if the poststart commands never ran
+	require.NoError(h.Postrun())
+	_, err := os.Stat(stopped)
+	require.True(os.IsNotExist(err))
+
+	// Tasks exiting on their own still run prestop commands
+	h.started = true
+	h.stopped = false
+	require.NoError(h.Postrun())
+	_, err = os.Stat(stopped)
+	require.NoError(err)
+}
+
+func TestAllocExecHook_Poststart_Fail(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	h, b, killer, _, cleanup := newTestAllocExecHook(t)
+	defer cleanup()
+	h.poststart = []*config.AllocHookConfig{{
+		Name:      "register",
+		Event:     config.AllocHookEventPoststart,
+		Command:   "/bin/sh",
+		Args:      []string{"-c", "echo agent unavailable; exit 3"},
+		Timeout:   5 * time.Second,
+		OnFailure: config.AllocHookFailureFail,
+	}}
+
+	require.NoError(h.Prerun(context.Background()))
+	sendClientStatus(t, h, b, structs.AllocClientStatusRunning)
+
+	testutil.WaitForResult(func() (bool, error) {
+		_, killed := killer.state()
+		return killed, nil
+	}, func(err error) {
+		t.Fatalf("tasks were not killed")
+	})
+
+	events, _ := killer.state()
+	require.Len(events, 1)
+	require.Equal(structs.TaskHookFailed, events[0].Type)
+	require.True(events[0].FailsTask)
+	require.Contains(events[0].Message, `alloc hook "register" (poststart)`)
+	require.Contains(events[0].Message, "agent unavailable")
+
+	require.NoError(h.Postrun())
+	require.False(h.started)
+}
+
+func TestAllocExecHook_Timeout(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	h, _, killer, _, cleanup := newTestAllocExecHook(t)
+	defer cleanup()
+	h.started = true
+	h.prestop = []*config.AllocHookConfig{
+		{
+			Name:      "flush",
+			Event:     config.AllocHookEventPrestop,
+			Command:   "/bin/sh",
+			Args:      []string{"-c", "exec sleep 10"},
+			Timeout:   100 * time.Millisecond,
+			OnFailure: config.AllocHookFailureFail,
+		},
+		{
+			Name:    "never",
+			Event:   config.AllocHookEventPrestop,
+			Command: "/bin/false",
+			Timeout: time.Second,
+		},
+	}
+
+	err := h.Prestop(context.Background())
+	require.EqualError(err, `alloc hook "flush" failed: timed out after 100ms`)
+
+	// The failed prestop command is reported and later ones are skipped
+	events, killed := killer.state()
+	require.Len(events, 1)
+	require.False(events[0].FailsTask)
+	require.False(killed)
+}
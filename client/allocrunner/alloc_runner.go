@@ -8,10 +8,12 @@ import (
 	"time"
 
 	log "github.com/hashicorp/go-hclog"
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/state"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner"
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	"github.com/hashicorp/nomad/client/allocwatcher"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/consul"
@@ -63,6 +65,9 @@ type allocRunner struct {
 	// vaultClient is the used to manage Vault tokens
 	vaultClient vaultclient.VaultClient
 
+	// vaultClusters are the clients of the named Vault clusters
+	vaultClusters map[string]vaultclient.VaultClient
+
 	// waitCh is closed when the Run() loop has exited
 	waitCh chan struct{}
 
@@ -122,6 +127,10 @@ type allocRunner struct {
 	// deviceStatsReporter is used to lookup resource usage for alloc devices
 	deviceStatsReporter cinterfaces.DeviceStatsReporter
 
+	// allocMetricsLimiter bounds the number of tasks publishing resource
+	// usage metrics
+	allocMetricsLimiter cinterfaces.AllocMetricsLimiter
+
 	// allocBroadcaster sends client allocation updates to all listeners
 	allocBroadcaster *cstructs.AllocBroadcaster
 
@@ -136,6 +145,18 @@ type allocRunner struct {
 	// statistics
 	devicemanager devicemanager.Manager
 
+	// chrootCache keeps the chroot skeleton of tasks if chroot caching is
+	// enabled
+	chrootCache *allocdir.ChrootCache
+
+	// diskQuota enforces the ephemeral disk size of the allocation if disk
+	// quotas are enabled
+	diskQuota *allocdir.ProjectQuota
+
+	// artifactCache caches the artifacts downloaded by tasks if artifact
+	// caching is enabled
+	artifactCache *getter.Cache
+
 	// driverManager is responsible for dispensing driver plugins and registering
 	// event handlers
 	driverManager drivermanager.Manager
@@ -155,6 +176,7 @@ func NewAllocRunner(config *Config) (*allocRunner, error) {
 		clientConfig:             config.ClientConfig,
 		consulClient:             config.Consul,
 		vaultClient:              config.Vault,
+		vaultClusters:            config.VaultClusters,
 		tasks:                    make(map[string]*taskrunner.TaskRunner, len(tg.Tasks)),
 		waitCh:                   make(chan struct{}),
 		destroyCh:                make(chan struct{}),
@@ -166,10 +188,14 @@ func NewAllocRunner(config *Config) (*allocRunner, error) {
 		taskStateUpdateHandlerCh: make(chan struct{}),
 		allocUpdatedCh:           make(chan *structs.Allocation, 1),
 		deviceStatsReporter:      config.DeviceStatsReporter,
+		allocMetricsLimiter:      config.AllocMetricsLimiter,
 		prevAllocWatcher:         config.PrevAllocWatcher,
 		prevAllocMigrator:        config.PrevAllocMigrator,
 		devicemanager:            config.DeviceManager,
 		driverManager:            config.DriverManager,
+		chrootCache:              config.ChrootCache,
+		diskQuota:                config.DiskQuota,
+		artifactCache:            config.ArtifactCache,
 	}
 
 	// Create the logger based on the allocation ID
@@ -205,9 +231,13 @@ func (ar *allocRunner) initTaskRunners(tasks []*structs.Task) error {
 			StateUpdater:        ar,
 			Consul:              ar.consulClient,
 			Vault:               ar.vaultClient,
+			VaultClusters:       ar.vaultClusters,
 			DeviceStatsReporter: ar.deviceStatsReporter,
+			AllocMetricsLimiter: ar.allocMetricsLimiter,
 			DeviceManager:       ar.devicemanager,
 			DriverManager:       ar.driverManager,
+			ChrootCache:         ar.chrootCache,
+			ArtifactCache:       ar.artifactCache,
 		}
 
 		// Create, but do not Run, the task runner
@@ -732,6 +762,9 @@ func (ar *allocRunner) handleAllocUpdate(update *structs.Allocation) {
 
 	// If alloc is being terminated, kill all tasks, leader first
 	if stopping {
+		if err := ar.prestop(); err != nil {
+			ar.logger.Error("error running prestop hooks", "error", err)
+		}
 		ar.killTasks()
 	}
 
@@ -744,6 +777,9 @@ func (ar *allocRunner) Listener() *cstructs.AllocListener {
 func (ar *allocRunner) destroyImpl() {
 	// Stop any running tasks and persist states in case the client is
 	// shutdown before Destroy finishes.
+	if err := ar.prestop(); err != nil {
+		ar.logger.Warn("error running prestop hooks", "error", err)
+	}
 	states := ar.killTasks()
 	calloc := ar.clientAlloc(states)
 	ar.stateUpdater.AllocStateUpdated(calloc)
@@ -946,13 +982,71 @@ func (ar *allocRunner) LatestAllocStats(taskFilter string) (*cstructs.AllocResou
 func (ar *allocRunner) GetTaskEventHandler(taskName string) drivermanager.EventHandler {
 	if tr, ok := ar.tasks[taskName]; ok {
 		return func(ev *drivers.TaskEvent) {
-			tr.EmitEvent(&structs.TaskEvent{
+			event := &structs.TaskEvent{
 				Type:          structs.TaskDriverMessage,
 				Time:          ev.Timestamp.UnixNano(),
-				Details:       ev.Annotations,
+				Details:       helper.CopyMapStringString(ev.Annotations),
 				DriverMessage: ev.Message,
-			})
+			}
+
+			// Propagate the structured exit reported by the driver
+			if res := ev.ExitResult; res != nil {
+				if event.Details == nil {
+					event.Details = make(map[string]string)
+				}
+				event.SetExitCode(res.ExitCode).
+					SetSignal(res.Signal).
+					SetOOMKilled(res.OOMKilled).
+					SetExitDetails(res.Details)
+			}
+
+			tr.EmitEvent(event)
 		}
 	}
 	return nil
 }
+
+// RestartTask restarts the task with the given name. It blocks until the task
+// has been killed, after which it is started again by its task runner.
+func (ar *allocRunner) RestartTask(taskName string, taskEvent *structs.TaskEvent) error {
+	tr, ok := ar.tasks[taskName]
+	if !ok {
+		return fmt.Errorf("Could not find task runner for task: %s", taskName)
+	}
+
+	return tr.Restart(context.TODO(), taskEvent, false)
+}
+
+// RestartAll restarts all the tasks of the allocation, each with its own copy
+// of the task event. Errors restarting the tasks are combined.
+func (ar *allocRunner) RestartAll(taskEvent *structs.TaskEvent) error {
+	var mErr multierror.Error
+	for name := range ar.tasks {
+		if err := ar.RestartTask(name, taskEvent.Copy()); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Failed to restart task %s: %v", name, err))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// Signal sends a signal to the task with the given name, or to all the tasks
+// of the allocation if the name is empty.
+func (ar *allocRunner) Signal(taskName, signal string) error {
+	event := structs.NewTaskEvent(structs.TaskSignaling).SetSignalText(signal)
+
+	if taskName != "" {
+		tr, ok := ar.tasks[taskName]
+		if !ok {
+			return fmt.Errorf("Could not find task runner for task: %s", taskName)
+		}
+		return tr.Signal(event, signal)
+	}
+
+	var mErr multierror.Error
+	for name, tr := range ar.tasks {
+		if err := tr.Signal(event.Copy(), signal); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Failed to signal task %s: %v", name, err))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
@@ -76,6 +76,25 @@ func (a *allocHealthSetter) SetHealth(healthy, isDeploy bool, trackerTaskEvents
 	a.ar.allocBroadcaster.Send(calloc)
 }
 
+// allocTaskKiller is a shim to allow the alloc exec hook to fail the
+// allocation without full access to the alloc runner state.
+type allocTaskKiller struct {
+	ar *allocRunner
+}
+
+// KillTasks emits the event on all tasks and then kills them.
+func (a *allocTaskKiller) KillTasks(event *structs.TaskEvent) {
+	a.EmitEvent(event)
+	a.ar.killTasks()
+}
+
+// EmitEvent emits the event on all tasks.
+func (a *allocTaskKiller) EmitEvent(event *structs.TaskEvent) {
+	for _, tr := range a.ar.tasks {
+		tr.EmitEvent(event)
+	}
+}
+
 // initRunnerHooks intializes the runners hooks.
 func (ar *allocRunner) initRunnerHooks() {
 	hookLogger := ar.logger.Named("runner_hook")
@@ -89,8 +108,19 @@ func (ar *allocRunner) initRunnerHooks() {
 		newAllocDirHook(hookLogger, ar.allocDir),
 		newUpstreamAllocsHook(hookLogger, ar.prevAllocWatcher),
 		newDiskMigrationHook(hookLogger, ar.prevAllocMigrator, ar.allocDir),
-		newAllocHealthWatcherHook(hookLogger, ar.Alloc(), hs, ar.Listener(), ar.consulClient),
 	}
+
+	// Enforce the ephemeral disk size once the previous allocation's data has
+	// been migrated, as files can't be moved into a directory assigned to
+	// another project.
+	if ar.diskQuota != nil {
+		ar.runnerHooks = append(ar.runnerHooks, newDiskQuotaHook(hookLogger, ar.Alloc(), ar.allocDir, ar.diskQuota, ar.clientConfig))
+	}
+
+	ar.runnerHooks = append(ar.runnerHooks,
+		newAllocHealthWatcherHook(hookLogger, ar.Alloc(), hs, ar.Listener(), ar.consulClient),
+		newAllocExecHook(hookLogger, ar.clientConfig.AllocHooks, ar.Alloc(), ar.allocDir, &allocTaskKiller{ar}, ar.Listener()),
+	)
 }
 
 // prerun is used to run the runners prerun hooks.
@@ -214,6 +244,45 @@ func (ar *allocRunner) postrun() error {
 	return nil
 }
 
+// prestop is used to run the runners prestop hooks. All hooks are run and
+// errors are returned as a multierror.
+func (ar *allocRunner) prestop() error {
+	if ar.logger.IsTrace() {
+		start := time.Now()
+		ar.logger.Trace("running prestop hooks", "start", start)
+		defer func() {
+			end := time.Now()
+			ar.logger.Trace("finished prestop hooks", "end", end, "duration", end.Sub(start))
+		}()
+	}
+
+	var merr multierror.Error
+	for _, hook := range ar.runnerHooks {
+		h, ok := hook.(interfaces.RunnerPrestopHook)
+		if !ok {
+			continue
+		}
+
+		name := h.Name()
+		var start time.Time
+		if ar.logger.IsTrace() {
+			start = time.Now()
+			ar.logger.Trace("running prestop hook", "name", name, "start", start)
+		}
+
+		if err := h.Prestop(context.TODO()); err != nil {
+			merr.Errors = append(merr.Errors, fmt.Errorf("prestop hook %q failed: %v", name, err))
+		}
+
+		if ar.logger.IsTrace() {
+			end := time.Now()
+			ar.logger.Trace("finished prestop hooks", "name", name, "end", end, "duration", end.Sub(start))
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
 // destroy is used to run the runners destroy hooks. All hooks are run and
 // errors are returned as a multierror.
 func (ar *allocRunner) destroy() error {
@@ -2,6 +2,8 @@ package allocrunner
 
 import (
 	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	"github.com/hashicorp/nomad/client/allocwatcher"
 	clientconfig "github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/consul"
@@ -33,12 +35,20 @@ type Config struct {
 	// Vault is the Vault client to use to retrieve Vault tokens
 	Vault vaultclient.VaultClient
 
+	// VaultClusters are the clients of the named Vault clusters, indexed by
+	// name
+	VaultClusters map[string]vaultclient.VaultClient
+
 	// StateUpdater is used to emit updated task state
 	StateUpdater interfaces.AllocStateHandler
 
 	// DeviceStatsReporter is used to lookup resource usage for alloc devices
 	DeviceStatsReporter interfaces.DeviceStatsReporter
 
+	// AllocMetricsLimiter bounds the number of tasks publishing resource
+	// usage metrics
+	AllocMetricsLimiter interfaces.AllocMetricsLimiter
+
 	// PrevAllocWatcher handles waiting on previous or preempted allocations
 	PrevAllocWatcher allocwatcher.PrevAllocWatcher
 
@@ -51,4 +61,16 @@ type Config struct {
 
 	// DriverManager handles dispensing of driver plugins
 	DriverManager drivermanager.Manager
+
+	// ChrootCache keeps the chroot skeleton of tasks. Nil if chroot caching
+	// is disabled.
+	ChrootCache *allocdir.ChrootCache
+
+	// DiskQuota enforces the ephemeral disk size of the allocation. Nil if
+	// disk quotas are disabled or unsupported.
+	DiskQuota *allocdir.ProjectQuota
+
+	// ArtifactCache caches the artifacts downloaded by tasks. Nil if
+	// artifact caching is disabled.
+	ArtifactCache *getter.Cache
 }
@@ -0,0 +1,160 @@
+package allocrunner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// diskQuotaHook enforces the ephemeral disk size of an allocation with a
+// project quota on its directory and publishes the disk usage of the
+// allocation as accounted by the quota.
+type diskQuotaHook struct {
+	alloc        *structs.Allocation
+	allocDir     *allocdir.AllocDir
+	quota        *allocdir.ProjectQuota
+	clientConfig *config.Config
+
+	// projectID is the project of the allocation directory, set once Prerun
+	// has applied the quota.
+	projectID uint32
+
+	// stopCh stops publishing the disk usage metrics.
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	// lock guards projectID
+	lock sync.Mutex
+
+	logger log.Logger
+}
+
+func newDiskQuotaHook(logger log.Logger, alloc *structs.Allocation, allocDir *allocdir.AllocDir,
+	quota *allocdir.ProjectQuota, clientConfig *config.Config) *diskQuotaHook {
+	h := &diskQuotaHook{
+		alloc:        alloc,
+		allocDir:     allocDir,
+		quota:        quota,
+		clientConfig: clientConfig,
+		stopCh:       make(chan struct{}),
+	}
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+func (h *diskQuotaHook) Name() string {
+	return "disk_quota"
+}
+
+func (h *diskQuotaHook) Prerun(context.Context) error {
+	// COMPAT(0.11): Remove in 0.11
+	var diskMB int64
+	if h.alloc.AllocatedResources != nil {
+		diskMB = h.alloc.AllocatedResources.Shared.DiskMB
+	} else {
+		diskMB = int64(h.alloc.Resources.DiskMB)
+	}
+
+	id, err := h.quota.Apply(h.allocDir.AllocDir, h.alloc.ID, diskMB)
+	if err != nil {
+		return fmt.Errorf("failed to enforce disk quota: %v", err)
+	}
+
+	h.lock.Lock()
+	h.projectID = id
+	h.lock.Unlock()
+
+	if h.clientConfig.PublishAllocationMetrics && !h.clientConfig.DisableTaggedMetrics {
+		go h.publishUsage(id)
+	}
+	return nil
+}
+
+func (h *diskQuotaHook) Postrun() error {
+	h.stop()
+	return nil
+}
+
+func (h *diskQuotaHook) Shutdown() {
+	h.stop()
+}
+
+// Destroy releases the project of the allocation. It runs after the
+// allocation directory has been destroyed, so nothing is accounted to the
+// project anymore.
+func (h *diskQuotaHook) Destroy() error {
+	h.stop()
+
+	h.lock.Lock()
+	id := h.projectID
+	h.lock.Unlock()
+
+	if id == 0 {
+		return nil
+	}
+	return h.quota.Release(id)
+}
+
+func (h *diskQuotaHook) stop() {
+	h.stopOnce.Do(func() { close(h.stopCh) })
+}
+
+// publishUsage periodically publishes the disk usage of the allocation until
+// the hook is stopped.
+func (h *diskQuotaHook) publishUsage(id uint32) {
+	labels := h.labels()
+
+	ticker := time.NewTicker(h.clientConfig.StatsCollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		usage, err := h.quota.Usage(id)
+		if err != nil {
+			h.logger.Debug("failed to get disk usage", "project_id", id, "error", err)
+			continue
+		}
+
+		metrics.SetGaugeWithLabels([]string{"client", "allocs", "disk", "used"}, float32(usage.UsedBytes), labels)
+		metrics.SetGaugeWithLabels([]string{"client", "allocs", "disk", "limit"}, float32(usage.LimitBytes), labels)
+		metrics.SetGaugeWithLabels([]string{"client", "allocs", "disk", "inodes"}, float32(usage.Inodes), labels)
+	}
+}
+
+// labels returns the labels of the disk usage metrics, restricted to the
+// allowed allocation metrics labels.
+func (h *diskQuotaHook) labels() []metrics.Label {
+	labels := []metrics.Label{
+		{Name: "job", Value: h.alloc.Job.Name},
+		{Name: "task_group", Value: h.alloc.TaskGroup},
+		{Name: "alloc_id", Value: h.alloc.ID},
+	}
+
+	allowed := h.clientConfig.AllocationMetricsLabels
+	if len(allowed) == 0 {
+		return labels
+	}
+
+	filtered := labels[:0]
+	for _, l := range labels {
+		for _, name := range allowed {
+			if l.Name == name {
+				filtered = append(filtered, l)
+				break
+			}
+		}
+	}
+	return filtered
+}
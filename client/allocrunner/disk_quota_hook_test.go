@@ -0,0 +1,50 @@
+package allocrunner
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// statically assert disk quota hook implements the expected interfaces
+var _ interfaces.RunnerPrerunHook = (*diskQuotaHook)(nil)
+var _ interfaces.RunnerPostrunHook = (*diskQuotaHook)(nil)
+var _ interfaces.RunnerDestroyHook = (*diskQuotaHook)(nil)
+var _ interfaces.ShutdownHook = (*diskQuotaHook)(nil)
+
+// TestDiskQuotaHook_Labels asserts the disk usage metrics are labeled with
+// the allowed allocation metrics labels.
+func TestDiskQuotaHook_Labels(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	alloc := mock.Alloc()
+	conf := config.DefaultConfig()
+
+	h := newDiskQuotaHook(testlog.HCLogger(t), alloc, nil, nil, conf)
+	labels := h.labels()
+	require.Len(labels, 3)
+	require.Equal("alloc_id", labels[2].Name)
+	require.Equal(alloc.ID, labels[2].Value)
+
+	conf.AllocationMetricsLabels = []string{"job"}
+	labels = h.labels()
+	require.Len(labels, 1)
+	require.Equal("job", labels[0].Name)
+	require.Equal(alloc.Job.Name, labels[0].Value)
+}
+
+// TestDiskQuotaHook_Destroy asserts destroying the hook before the quota has
+// been applied, as for restored terminal allocations, is a noop.
+func TestDiskQuotaHook_Destroy(t *testing.T) {
+	t.Parallel()
+
+	h := newDiskQuotaHook(testlog.HCLogger(t), mock.Alloc(), nil, nil, config.DefaultConfig())
+	require.NoError(t, h.Postrun())
+	require.NoError(t, h.Destroy())
+	h.Shutdown()
+}
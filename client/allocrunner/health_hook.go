@@ -71,8 +71,10 @@ func newAllocHealthWatcherHook(logger log.Logger, alloc *structs.Allocation, hs
 	listener *cstructs.AllocListener, consul consul.ConsulServiceAPI) interfaces.RunnerHook {
 
 	// Neither deployments nor migrations care about the health of
-	// non-service jobs so never watch their health
-	if alloc.Job.Type != structs.JobTypeService {
+	// non-service jobs so never watch their health. The exception is system
+	// jobs with an update strategy as their rolling updates are paced by the
+	// health of the updated allocations.
+	if alloc.Job.Type != structs.JobTypeService && !isSystemRollingUpdate(alloc) {
 		return noopAllocHealthWatcherHook{}
 	}
 
@@ -112,7 +114,7 @@ func (h *allocHealthWatcherHook) init() error {
 		return fmt.Errorf("task group %q does not exist in job %q", h.alloc.TaskGroup, h.alloc.Job.ID)
 	}
 
-	h.isDeploy = h.alloc.DeploymentID != ""
+	h.isDeploy = h.alloc.DeploymentID != "" || isSystemRollingUpdate(h.alloc)
 
 	// No need to watch allocs for deployments that rely on operators
 	// manually setting health
@@ -242,6 +244,17 @@ func getHealthParams(now time.Time, tg *structs.TaskGroup, isDeploy bool) (deadl
 	return
 }
 
+// isSystemRollingUpdate returns whether the allocation belongs to a system
+// job whose task group defines an update strategy. The health of such
+// allocations is determined using the update strategy.
+func isSystemRollingUpdate(alloc *structs.Allocation) bool {
+	if alloc.Job.Type != structs.JobTypeSystem {
+		return false
+	}
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	return tg != nil && tg.Update != nil
+}
+
 // noopAllocHealthWatcherHook is an empty hook implementation returned by
 // newAllocHealthWatcherHook when an allocation will never need its health
 // monitored.
@@ -322,6 +322,22 @@ func TestHealthHook_SystemNoop(t *testing.T) {
 	require.False(t, ok)
 }
 
+// TestHealthHook_SystemRollingUpdate asserts that system jobs with an update
+// strategy have their health watched using the update strategy.
+func TestHealthHook_SystemRollingUpdate(t *testing.T) {
+	t.Parallel()
+
+	alloc := mock.SystemAlloc()
+	alloc.Job.TaskGroups[0].Update = structs.DefaultUpdateStrategy.Copy()
+
+	h := newAllocHealthWatcherHook(testlog.HCLogger(t), alloc, nil, nil, nil)
+
+	// Assert that it's not the noop impl
+	_, ok := h.(noopAllocHealthWatcherHook)
+	require.False(t, ok)
+	require.True(t, isSystemRollingUpdate(alloc))
+}
+
 // TestHealthHook_BatchNoop asserts that batch jobs return the noop tracker.
 func TestHealthHook_BatchNoop(t *testing.T) {
 	t.Parallel()
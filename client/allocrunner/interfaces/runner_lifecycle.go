@@ -17,6 +17,13 @@ type RunnerPrerunHook interface {
 	Prerun(context.Context) error
 }
 
+// RunnerPrestopHook is called before the allocation's tasks are killed
+// because the allocation is being stopped or destroyed.
+type RunnerPrestopHook interface {
+	RunnerHook
+	Prestop(context.Context) error
+}
+
 type RunnerPostrunHook interface {
 	RunnerHook
 	Postrun() error
@@ -8,18 +8,23 @@ import (
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	ti "github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
+	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
 // artifactHook downloads artifacts for a task.
 type artifactHook struct {
 	eventEmitter ti.EventEmitter
+	clientConfig *config.Config
+	cache        *getter.Cache
 	logger       log.Logger
 }
 
-func newArtifactHook(e ti.EventEmitter, logger log.Logger) *artifactHook {
+func newArtifactHook(e ti.EventEmitter, clientConfig *config.Config, cache *getter.Cache, logger log.Logger) *artifactHook {
 	h := &artifactHook{
 		eventEmitter: e,
+		clientConfig: clientConfig,
+		cache:        cache,
 	}
 	h.logger = logger.Named(h.Name())
 	return h
@@ -37,11 +42,17 @@ func (h *artifactHook) Prestart(ctx context.Context, req *interfaces.TaskPrestar
 		return nil
 	}
 
+	sandboxConfig, err := getter.NewSandboxConfig(h.clientConfig)
+	if err != nil {
+		return err
+	}
+	sandboxConfig.Cache = h.cache
+	sandbox := getter.NewSandbox(h.logger, sandboxConfig)
+
 	h.eventEmitter.EmitEvent(structs.NewTaskEvent(structs.TaskDownloadingArtifacts))
 
 	for _, artifact := range req.Task.Artifacts {
-		//XXX add ctx to GetArtifact to allow cancelling long downloads
-		if err := getter.GetArtifact(req.TaskEnv, artifact, req.TaskDir.Dir); err != nil {
+		if err := sandbox.Get(ctx, req.TaskEnv, artifact, req.TaskDir.Dir); err != nil {
 			wrapped := fmt.Errorf("failed to download artifact %q: %v", artifact.GetterSource, err)
 			herr := NewHookError(wrapped, structs.NewTaskEvent(structs.TaskArtifactDownloadFailed).SetDownloadError(wrapped))
 
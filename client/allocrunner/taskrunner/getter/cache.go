@@ -0,0 +1,336 @@
+package getter
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	units "github.com/docker/go-units"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// CacheDirName is the name of the directory within the client's alloc
+	// dir that holds the artifact cache.
+	CacheDirName = "artifact_cache"
+
+	// Client options controlling the artifact cache
+	cacheEnableOption  = "artifact.cache.enable"
+	cacheMaxSizeOption = "artifact.cache.max_size"
+
+	// defaultCacheMaxSize is the default maximum size of the artifact cache.
+	defaultCacheMaxSize = "10GB"
+
+	// cacheArtifact is the name of the artifact within a cache entry.
+	cacheArtifact = "artifact"
+
+	// cacheTmpSuffix is the suffix of entries being stored.
+	cacheTmpSuffix = ".tmp"
+)
+
+// Cache is a content addressable cache of downloaded artifacts shared by the
+// allocations of a client. Artifacts are keyed by their checksum, so only
+// artifacts with a checksum are cached, and the least recently used ones are
+// evicted once the cache exceeds its maximum size. Cached artifacts are
+// copied into the task directory so tasks can't modify the cache.
+type Cache struct {
+	dir     string
+	maxSize int64
+	logger  hclog.Logger
+
+	// lock guards the fields below
+	lock sync.Mutex
+
+	// entries maps keys to their element of lru, which is ordered from the
+	// most to the least recently used entry.
+	entries map[string]*list.Element
+	lru     *list.List
+
+	// size is the total size of the cached artifacts.
+	size int64
+}
+
+// cacheEntry is a cached artifact.
+type cacheEntry struct {
+	key  string
+	size int64
+
+	// refs is the number of artifacts being copied out of the entry, which
+	// prevents it from being evicted.
+	refs int
+}
+
+// NewCacheFromConfig returns the artifact cache configured by the client's
+// options, or nil if the cache is disabled.
+func NewCacheFromConfig(logger hclog.Logger, c *config.Config) (*Cache, error) {
+	if !c.ReadBoolDefault(cacheEnableOption, false) {
+		return nil, nil
+	}
+
+	maxSize, err := units.RAMInBytes(c.ReadDefault(cacheMaxSizeOption, defaultCacheMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q option: %v", cacheMaxSizeOption, err)
+	}
+
+	return NewCache(logger, filepath.Join(c.AllocDir, CacheDirName), maxSize)
+}
+
+// NewCache returns a cache of at most maxSize bytes stored in dir. Artifacts
+// cached by a previous run of the client are kept, ordered by when they were
+// last used.
+func NewCache(logger hclog.Logger, dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create artifact cache: %v", err)
+	}
+
+	c := &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+		logger:  logger.Named("artifact_cache"),
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes the entries stored in the cache directory and removes the
+// remains of interrupted stores.
+func (c *Cache) load() error {
+	infos, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact cache: %v", err)
+	}
+
+	// Most recently used first
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().After(infos[j].ModTime())
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, info := range infos {
+		path := filepath.Join(c.dir, info.Name())
+		if !info.IsDir() || strings.HasSuffix(info.Name(), cacheTmpSuffix) {
+			os.RemoveAll(path)
+			continue
+		}
+
+		size, err := pathSize(filepath.Join(path, cacheArtifact))
+		if err != nil {
+			c.logger.Warn("removing unreadable cached artifact", "key", info.Name(), "error", err)
+			os.RemoveAll(path)
+			continue
+		}
+
+		e := &cacheEntry{key: info.Name(), size: size}
+		c.entries[e.key] = c.lru.PushBack(e)
+		c.size += size
+	}
+
+	c.evict()
+	return nil
+}
+
+// CacheKey returns the key an artifact is cached under, or an empty string if
+// the artifact can't be cached because it has no checksum. Besides the
+// checksum, the key covers the options changing what is written to the task
+// directory.
+func CacheKey(taskEnv EnvReplacer, artifact *structs.TaskArtifact) string {
+	checksum := taskEnv.ReplaceEnv(artifact.GetterOptions["checksum"])
+	if checksum == "" {
+		return ""
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", checksum, artifact.GetterMode,
+		taskEnv.ReplaceEnv(artifact.GetterOptions["archive"]))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fetch copies the artifact cached under key to dest. It returns false if the
+// artifact isn't cached.
+func (c *Cache) Fetch(key, dest string) (bool, error) {
+	c.lock.Lock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.lock.Unlock()
+		metrics.IncrCounter([]string{"client", "artifact_cache", "miss"}, 1)
+		return false, nil
+	}
+	e := elem.Value.(*cacheEntry)
+	e.refs++
+	c.lru.MoveToFront(elem)
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		e.refs--
+		c.lock.Unlock()
+	}()
+
+	path := filepath.Join(c.dir, key)
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	if err := copyTree(filepath.Join(path, cacheArtifact), dest); err != nil {
+		return false, fmt.Errorf("failed to copy cached artifact: %v", err)
+	}
+
+	metrics.IncrCounter([]string{"client", "artifact_cache", "hit"}, 1)
+	return true, nil
+}
+
+// Store copies the downloaded artifact at src into the cache under key and
+// evicts the least recently used artifacts if the cache grows beyond its
+// maximum size. Artifacts larger than the cache are not stored.
+func (c *Cache) Store(key, src string) error {
+	size, err := pathSize(src)
+	if err != nil {
+		return err
+	}
+	if size > c.maxSize {
+		return nil
+	}
+
+	c.lock.Lock()
+	_, ok := c.entries[key]
+	c.lock.Unlock()
+	if ok {
+		return nil
+	}
+
+	// Copy into a temporary entry and move it in place once complete so an
+	// interrupted store is never used
+	tmp, err := ioutil.TempDir(c.dir, key+".")
+	if err != nil {
+		return err
+	}
+	tmpEntry := tmp + cacheTmpSuffix
+	if err := os.Rename(tmp, tmpEntry); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := copyTree(src, filepath.Join(tmpEntry, cacheArtifact)); err != nil {
+		os.RemoveAll(tmpEntry)
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Another task stored the artifact concurrently
+	if _, ok := c.entries[key]; ok {
+		os.RemoveAll(tmpEntry)
+		return nil
+	}
+
+	if err := os.Rename(tmpEntry, filepath.Join(c.dir, key)); err != nil {
+		os.RemoveAll(tmpEntry)
+		return err
+	}
+
+	e := &cacheEntry{key: key, size: size}
+	c.entries[key] = c.lru.PushFront(e)
+	c.size += size
+	c.evict()
+	return nil
+}
+
+// evict removes the least recently used artifacts until the cache fits its
+// maximum size. Artifacts being copied out of the cache are skipped. The lock
+// must be held.
+func (c *Cache) evict() {
+	for elem := c.lru.Back(); elem != nil && c.size > c.maxSize; {
+		prev := elem.Prev()
+		e := elem.Value.(*cacheEntry)
+		if e.refs == 0 {
+			if err := os.RemoveAll(filepath.Join(c.dir, e.key)); err != nil {
+				c.logger.Warn("failed to evict cached artifact", "key", e.key, "error", err)
+			} else {
+				c.lru.Remove(elem)
+				delete(c.entries, e.key)
+				c.size -= e.size
+				metrics.IncrCounter([]string{"client", "artifact_cache", "evicted"}, 1)
+			}
+		}
+		elem = prev
+	}
+
+	metrics.SetGauge([]string{"client", "artifact_cache", "size"}, float32(c.size))
+}
+
+// copyTree copies src to dst. Directories are merged into existing
+// directories at dst and files replace existing files.
+func copyTree(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		os.Remove(dst)
+		return os.Symlink(link, dst)
+
+	case !info.IsDir():
+		if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+			return err
+		}
+		return copyFile(src, dst, info.Mode().Perm())
+	}
+
+	if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies the contents of the file src to dst, replacing it.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
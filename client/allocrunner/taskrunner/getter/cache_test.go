@@ -0,0 +1,202 @@
+package getter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+// testArtifactDir returns a directory holding an artifact of size bytes.
+func testArtifactDir(t *testing.T, size int) string {
+	dir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "app"), make([]byte, size), 0755))
+	require.NoError(t, os.Symlink("bin/app", filepath.Join(dir, "app")))
+	return dir
+}
+
+func TestNewCacheFromConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	allocDir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(allocDir)
+
+	c := config.DefaultConfig()
+	c.AllocDir = allocDir
+
+	// Disabled by default
+	cache, err := NewCacheFromConfig(testlog.HCLogger(t), c)
+	require.NoError(err)
+	require.Nil(cache)
+
+	c.Options = map[string]string{
+		cacheEnableOption:  "true",
+		cacheMaxSizeOption: "1GB",
+	}
+	cache, err = NewCacheFromConfig(testlog.HCLogger(t), c)
+	require.NoError(err)
+	require.NotNil(cache)
+	require.EqualValues(1024*1024*1024, cache.maxSize)
+	require.DirExists(filepath.Join(allocDir, CacheDirName))
+
+	c.Options[cacheMaxSizeOption] = "large"
+	_, err = NewCacheFromConfig(testlog.HCLogger(t), c)
+	require.Error(err)
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: "http://example.com/app.tar.gz",
+	}
+	require.Empty(CacheKey(taskEnv, artifact))
+
+	artifact.GetterOptions = map[string]string{"checksum": "sha256:abcd"}
+	key := CacheKey(taskEnv, artifact)
+	require.NotEmpty(key)
+
+	// The source doesn't matter, only the content
+	other := artifact.Copy()
+	other.GetterSource = "http://mirror.example.com/app.tar.gz"
+	require.Equal(key, CacheKey(taskEnv, other))
+
+	// Unpacking the artifact or not does
+	other.GetterOptions["archive"] = "false"
+	require.NotEqual(key, CacheKey(taskEnv, other))
+
+	other = artifact.Copy()
+	other.GetterMode = structs.GetterModeFile
+	require.NotEqual(key, CacheKey(taskEnv, other))
+}
+
+func TestCache_StoreFetch(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cache, err := NewCache(testlog.HCLogger(t), dir, 1024)
+	require.NoError(err)
+
+	dest, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dest)
+
+	hit, err := cache.Fetch("key", dest)
+	require.NoError(err)
+	require.False(hit)
+
+	src := testArtifactDir(t, 100)
+	defer os.RemoveAll(src)
+	require.NoError(cache.Store("key", src))
+
+	// Existing files in the destination are kept
+	require.NoError(ioutil.WriteFile(filepath.Join(dest, "existing"), []byte("hello"), 0644))
+
+	hit, err = cache.Fetch("key", dest)
+	require.NoError(err)
+	require.True(hit)
+	require.FileExists(filepath.Join(dest, "existing"))
+
+	info, err := os.Stat(filepath.Join(dest, "bin", "app"))
+	require.NoError(err)
+	require.EqualValues(100, info.Size())
+	require.Equal(os.FileMode(0755), info.Mode().Perm())
+
+	link, err := os.Readlink(filepath.Join(dest, "app"))
+	require.NoError(err)
+	require.Equal("bin/app", link)
+
+	// Changing the fetched artifact doesn't change the cache
+	require.NoError(ioutil.WriteFile(filepath.Join(dest, "bin", "app"), []byte("changed"), 0755))
+	dest2, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dest2)
+	_, err = cache.Fetch("key", dest2)
+	require.NoError(err)
+	info, err = os.Stat(filepath.Join(dest2, "bin", "app"))
+	require.NoError(err)
+	require.EqualValues(100, info.Size())
+}
+
+func TestCache_Evict(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cache, err := NewCache(testlog.HCLogger(t), dir, 250)
+	require.NoError(err)
+
+	src := testArtifactDir(t, 100)
+	defer os.RemoveAll(src)
+
+	dest, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dest)
+
+	require.NoError(cache.Store("a", src))
+	require.NoError(cache.Store("b", src))
+
+	// Using a makes b the least recently used artifact
+	hit, err := cache.Fetch("a", dest)
+	require.NoError(err)
+	require.True(hit)
+
+	require.NoError(cache.Store("c", src))
+	require.EqualValues(200, cache.size)
+	require.Contains(cache.entries, "a")
+	require.NotContains(cache.entries, "b")
+	require.Contains(cache.entries, "c")
+	_, err = os.Stat(filepath.Join(dir, "b"))
+	require.True(os.IsNotExist(err))
+
+	// Artifacts larger than the cache aren't stored
+	large := testArtifactDir(t, 300)
+	defer os.RemoveAll(large)
+	require.NoError(cache.Store("d", large))
+	require.NotContains(cache.entries, "d")
+	require.Len(cache.entries, 2)
+}
+
+func TestCache_Load(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cache, err := NewCache(testlog.HCLogger(t), dir, 1024)
+	require.NoError(err)
+
+	src := testArtifactDir(t, 100)
+	defer os.RemoveAll(src)
+	require.NoError(cache.Store("key", src))
+
+	// Leftovers of an interrupted store are removed
+	tmp := filepath.Join(dir, "other.1234"+cacheTmpSuffix)
+	require.NoError(os.MkdirAll(filepath.Join(tmp, cacheArtifact), 0777))
+
+	cache, err = NewCache(testlog.HCLogger(t), dir, 1024)
+	require.NoError(err)
+	require.Contains(cache.entries, "key")
+	require.EqualValues(100, cache.size)
+	_, err = os.Stat(tmp)
+	require.True(os.IsNotExist(err))
+}
@@ -97,21 +97,25 @@ func GetArtifact(taskEnv EnvReplacer, artifact *structs.TaskArtifact, taskDir st
 
 	// Download the artifact
 	dest := filepath.Join(taskDir, artifact.RelativeDest)
+	if err := get(url, artifact.GetterMode, dest); err != nil {
+		return newGetError(url, err, true)
+	}
+
+	return nil
+}
 
+// get downloads the go-getter URL to dest using the given getter mode.
+func get(url, getterMode, dest string) error {
 	// Convert from string getter mode to go-getter const
 	mode := gg.ClientModeAny
-	switch artifact.GetterMode {
+	switch getterMode {
 	case structs.GetterModeFile:
 		mode = gg.ClientModeFile
 	case structs.GetterModeDir:
 		mode = gg.ClientModeDir
 	}
 
-	if err := getClient(url, mode, dest).Get(); err != nil {
-		return newGetError(url, err, true)
-	}
-
-	return nil
+	return getClient(url, mode, dest).Get()
 }
 
 // GetError wraps the underlying artifact fetching error with the URL. It
@@ -0,0 +1,379 @@
+package getter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/discover"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// SandboxCommand is the hidden nomad command run to download artifacts
+	// in a sandboxed subprocess.
+	SandboxCommand = "artifact-getter"
+
+	// Client options controlling the artifact sandbox
+	sandboxEnableOption = "artifact.sandbox.enable"
+	sandboxUserOption   = "artifact.sandbox.user"
+	timeoutOption       = "artifact.timeout"
+	maxSizeOption       = "artifact.max_size"
+
+	// defaultSandboxUser is the user artifacts are downloaded as when the
+	// client runs as root.
+	defaultSandboxUser = "nobody"
+
+	// defaultTimeout is the default maximum time an artifact download may
+	// take.
+	defaultTimeout = 30 * time.Minute
+
+	// defaultMaxSize is the default maximum size of a downloaded artifact.
+	defaultMaxSize = "20GB"
+
+	// stagingArtifact and stagingTmp are the directories within the staging
+	// directory the artifact is downloaded into and used for temporary files
+	// while downloading.
+	stagingArtifact = "artifact"
+	stagingTmp      = "tmp"
+)
+
+// SandboxConfig configures how artifacts are downloaded.
+type SandboxConfig struct {
+	// Enabled downloads artifacts in a subprocess. When disabled artifacts
+	// are downloaded within the client and timeouts and size limits are not
+	// enforced.
+	Enabled bool
+
+	// User is the user the subprocess runs as when the client runs as root.
+	User string
+
+	// Timeout is the maximum time a download may take.
+	Timeout time.Duration
+
+	// MaxSize is the maximum size in bytes of a downloaded artifact. Zero
+	// disables the limit.
+	MaxSize int64
+
+	// HiddenPaths are paths on the host the subprocess may not see, such as
+	// the client's state and allocation directories.
+	HiddenPaths []string
+
+	// Cache is the cache artifacts with a checksum are fetched from and
+	// stored in. Nil disables caching.
+	Cache *Cache
+}
+
+// NewSandboxConfig returns the sandbox configuration from the client's
+// options.
+func NewSandboxConfig(c *config.Config) (*SandboxConfig, error) {
+	timeout := defaultTimeout
+	if _, ok := c.Options[timeoutOption]; ok {
+		var err error
+		if timeout, err = c.ReadDuration(timeoutOption); err != nil {
+			return nil, fmt.Errorf("invalid %q option: %v", timeoutOption, err)
+		}
+	}
+
+	maxSize, err := units.RAMInBytes(c.ReadDefault(maxSizeOption, defaultMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q option: %v", maxSizeOption, err)
+	}
+
+	var hidden []string
+	for _, dir := range []string{c.StateDir, c.AllocDir} {
+		if dir != "" {
+			hidden = append(hidden, dir)
+		}
+	}
+
+	return &SandboxConfig{
+		Enabled:     c.ReadBoolDefault(sandboxEnableOption, true),
+		User:        c.ReadDefault(sandboxUserOption, defaultSandboxUser),
+		Timeout:     timeout,
+		MaxSize:     maxSize,
+		HiddenPaths: hidden,
+	}, nil
+}
+
+// sandboxRequest is passed to the sandboxed subprocess on stdin.
+type sandboxRequest struct {
+	// Source is the go-getter URL to download
+	Source string
+
+	// Mode is the task artifact getter mode
+	Mode string
+
+	// Dir is the path of the staging directory as seen by the subprocess
+	Dir string
+
+	// MaxSize is the maximum size of any file written, or zero for no
+	// limit
+	MaxSize int64
+
+	// Isolate indicates the subprocess should hide HiddenPaths, bind the
+	// staging directory at Dir and switch to UID and GID before
+	// downloading.
+	Isolate     bool
+	Staging     string
+	HiddenPaths []string
+	UID         int
+	GID         int
+}
+
+// Sandbox downloads artifacts in a subprocess so that a malicious artifact
+// can not read host files or hang the client.
+type Sandbox struct {
+	config *SandboxConfig
+	logger hclog.Logger
+
+	// bin is the nomad binary run as the subprocess. If empty it is
+	// discovered.
+	bin string
+}
+
+// NewSandbox returns a sandbox for downloading artifacts.
+func NewSandbox(logger hclog.Logger, config *SandboxConfig) *Sandbox {
+	return &Sandbox{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Get downloads an artifact into the specified task directory. Artifacts
+// found in the cache are copied from it instead of being downloaded.
+func (s *Sandbox) Get(ctx context.Context, taskEnv EnvReplacer, artifact *structs.TaskArtifact, taskDir string) error {
+	var key string
+	if s.config.Cache != nil {
+		key = CacheKey(taskEnv, artifact)
+	}
+
+	dest := filepath.Join(taskDir, artifact.RelativeDest)
+	if key != "" {
+		hit, err := s.config.Cache.Fetch(key, dest)
+		if err != nil {
+			s.logger.Warn("failed to fetch cached artifact", "artifact", artifact.GetterSource, "error", err)
+		} else if hit {
+			s.logger.Debug("fetched artifact from cache", "artifact", artifact.GetterSource)
+			return nil
+		}
+	}
+
+	if !s.config.Enabled && key == "" {
+		return GetArtifact(taskEnv, artifact, taskDir)
+	}
+
+	url, err := getGetterUrl(taskEnv, artifact)
+	if err != nil {
+		return newGetError(artifact.GetterSource, err, false)
+	}
+
+	// Download into a staging directory within the task directory so the
+	// result can be checked before it is moved into place. When isolated,
+	// the staging directory is all the subprocess can see of the task.
+	staging, err := ioutil.TempDir(taskDir, ".artifact-")
+	if err != nil {
+		return newGetError(url, fmt.Errorf("failed to create staging directory: %v", err), false)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := os.Mkdir(filepath.Join(staging, stagingTmp), 0700); err != nil {
+		return newGetError(url, fmt.Errorf("failed to create staging directory: %v", err), false)
+	}
+
+	downloaded := filepath.Join(staging, stagingArtifact)
+	if s.config.Enabled {
+		if err := s.download(ctx, url, artifact, staging); err != nil {
+			return err
+		}
+	} else if err := get(url, artifact.GetterMode, downloaded); err != nil {
+		return newGetError(url, err, true)
+	}
+
+	if key != "" {
+		if err := s.config.Cache.Store(key, downloaded); err != nil {
+			s.logger.Warn("failed to cache artifact", "artifact", artifact.GetterSource, "error", err)
+		}
+	}
+
+	if err := moveTree(downloaded, dest); err != nil {
+		return newGetError(url, fmt.Errorf("failed to move artifact into task directory: %v", err), false)
+	}
+	return nil
+}
+
+// download runs the subprocess downloading the artifact into the staging
+// directory and checks the result.
+func (s *Sandbox) download(ctx context.Context, url string, artifact *structs.TaskArtifact, staging string) error {
+	bin := s.bin
+	if bin == "" {
+		var err error
+		bin, err = discover.NomadExecutable()
+		if err != nil {
+			return newGetError(url, err, false)
+		}
+	}
+
+	req := &sandboxRequest{
+		Source:  url,
+		Mode:    artifact.GetterMode,
+		Dir:     staging,
+		MaxSize: s.config.MaxSize,
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	var stdin bytes.Buffer
+	var stderr bytes.Buffer
+	cmd := exec.Command(bin, SandboxCommand)
+	cmd.Stdin = &stdin
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+
+	if err := s.isolate(cmd, req, staging); err != nil {
+		return newGetError(url, err, false)
+	}
+	if err := json.NewEncoder(&stdin).Encode(req); err != nil {
+		return newGetError(url, err, false)
+	}
+
+	s.logger.Debug("downloading artifact", "artifact", artifact.GetterSource, "isolated", req.Isolate)
+	if err := cmd.Start(); err != nil {
+		return newGetError(url, fmt.Errorf("failed to start artifact download: %v", err), false)
+	}
+
+	// Kill the subprocess, and any processes it started such as git, if the
+	// download times out or is cancelled
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-tctx.Done():
+			killSandbox(cmd.Process)
+		case <-done:
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+	if err != nil {
+		switch {
+		case tctx.Err() == context.DeadlineExceeded:
+			err = fmt.Errorf("download timed out after %v", s.config.Timeout)
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		default:
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s", msg)
+			}
+		}
+		return newGetError(url, err, true)
+	}
+
+	downloaded := filepath.Join(staging, stagingArtifact)
+	size, err := pathSize(downloaded)
+	if err != nil {
+		return newGetError(url, err, true)
+	}
+	if s.config.MaxSize > 0 && size > s.config.MaxSize {
+		return newGetError(url, fmt.Errorf("artifact size %s exceeds the maximum of %s",
+			units.BytesSize(float64(size)), units.BytesSize(float64(s.config.MaxSize))), false)
+	}
+
+	if req.Isolate {
+		if err := chownTree(downloaded, os.Getuid(), os.Getgid()); err != nil {
+			return newGetError(url, err, false)
+		}
+	}
+	return nil
+}
+
+// RunSandbox is run by the sandboxed subprocess. It reads the request from
+// r, isolates itself and downloads the artifact into the staging directory.
+func RunSandbox(r io.Reader) error {
+	var req sandboxRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("failed to decode request: %v", err)
+	}
+
+	if err := sandboxProcess(&req); err != nil {
+		return err
+	}
+
+	// Keep temporary files, such as archives being decompressed, within the
+	// staging directory
+	os.Setenv("TMPDIR", filepath.Join(req.Dir, stagingTmp))
+
+	return get(req.Source, req.Mode, filepath.Join(req.Dir, stagingArtifact))
+}
+
+// pathSize returns the total size of the regular files at path.
+func pathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// chownTree changes the owner of path and everything beneath it.
+func chownTree(path string, uid, gid int) error {
+	return filepath.Walk(path, func(p string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, uid, gid)
+	})
+}
+
+// moveTree moves src to dst. Directories are merged into existing
+// directories at dst and files replace existing files.
+func moveTree(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+			return err
+		}
+		return os.Rename(src, dst)
+	}
+
+	if dstInfo, err := os.Lstat(dst); err == nil && !dstInfo.IsDir() {
+		return fmt.Errorf("%q exists and is not a directory", dst)
+	} else if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+			return err
+		}
+		return os.Rename(src, dst)
+	}
+
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := moveTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// +build !linux
+
+package getter
+
+import (
+	"os"
+	"os/exec"
+)
+
+// isolate is a no-op on platforms other than Linux, where the download runs
+// as the client's user with only the timeout and size limits applied.
+func (s *Sandbox) isolate(cmd *exec.Cmd, req *sandboxRequest, staging string) error {
+	return nil
+}
+
+// killSandbox kills the subprocess.
+func killSandbox(p *os.Process) {
+	p.Kill()
+}
+
+// sandboxProcess is a no-op on platforms other than Linux.
+func sandboxProcess(req *sandboxRequest) error {
+	return nil
+}
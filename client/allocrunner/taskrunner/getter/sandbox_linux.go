@@ -0,0 +1,139 @@
+package getter
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// sandboxTmp is where a private tmpfs is mounted in the subprocess's
+	// mount namespace, hiding the host's temporary files.
+	sandboxTmp = "/tmp"
+
+	// sandboxDir is where the staging directory is bound in the
+	// subprocess's mount namespace.
+	sandboxDir = "/tmp/nomad-artifact"
+)
+
+// isolate configures the subprocess to download as the sandbox user within
+// its own mount namespace. Isolation requires the client to run as root;
+// otherwise the download runs as the client's user with only the timeout and
+// size limits applied.
+func (s *Sandbox) isolate(cmd *exec.Cmd, req *sandboxRequest, staging string) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	if os.Geteuid() != 0 {
+		return nil
+	}
+
+	u, err := user.Lookup(s.config.User)
+	if err != nil {
+		return fmt.Errorf("failed to look up artifact sandbox user %q: %v", s.config.User, err)
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return fmt.Errorf("invalid uid %q for user %q: %v", u.Uid, s.config.User, err)
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return fmt.Errorf("invalid gid %q for user %q: %v", u.Gid, s.config.User, err)
+	}
+
+	for _, dir := range []string{staging, filepath.Join(staging, stagingTmp)} {
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return fmt.Errorf("failed to chown staging directory: %v", err)
+		}
+	}
+
+	cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWNS
+
+	// The client's home directory is not readable by the sandbox user, so
+	// give getters such as git one within the staging directory
+	cmd.Env = append(cmd.Env, "HOME="+filepath.Join(sandboxDir, stagingTmp))
+
+	req.Isolate = true
+	req.Staging = staging
+	req.Dir = sandboxDir
+	req.HiddenPaths = s.config.HiddenPaths
+	req.UID = uid
+	req.GID = gid
+	return nil
+}
+
+// killSandbox kills the subprocess's process group.
+func killSandbox(p *os.Process) {
+	syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+// sandboxProcess is called in the subprocess to limit the size of files it
+// writes and, if requested, to hide host paths and drop privileges.
+func sandboxProcess(req *sandboxRequest) error {
+	if req.MaxSize > 0 {
+		limit := &syscall.Rlimit{Cur: uint64(req.MaxSize), Max: uint64(req.MaxSize)}
+		if err := syscall.Setrlimit(syscall.RLIMIT_FSIZE, limit); err != nil {
+			return fmt.Errorf("failed to limit file size: %v", err)
+		}
+	}
+
+	if !req.Isolate {
+		return nil
+	}
+
+	// Ensure mounts made here do not propagate back to the host
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %v", err)
+	}
+
+	// Hold the staging directory open so it can be bound after hiding the
+	// paths that contain it
+	staging, err := os.Open(req.Staging)
+	if err != nil {
+		return fmt.Errorf("failed to open staging directory: %v", err)
+	}
+	defer staging.Close()
+
+	// Hide parents before their children so that no mount is hidden by a
+	// later one
+	hidden := append([]string{}, req.HiddenPaths...)
+	sort.Strings(hidden)
+	for _, path := range hidden {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := unix.Mount("tmpfs", path, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "mode=0700,size=1m"); err != nil {
+			return fmt.Errorf("failed to hide %q: %v", path, err)
+		}
+	}
+
+	if err := unix.Mount("tmpfs", sandboxTmp, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777,size=1m"); err != nil {
+		return fmt.Errorf("failed to mount %q: %v", sandboxTmp, err)
+	}
+	if err := os.Mkdir(req.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %q: %v", req.Dir, err)
+	}
+	fdPath := fmt.Sprintf("/proc/self/fd/%d", staging.Fd())
+	if err := unix.Mount(fdPath, req.Dir, "", unix.MS_BIND, ""); err != nil {
+		return fmt.Errorf("failed to bind staging directory: %v", err)
+	}
+
+	if err := syscall.Setgroups(nil); err != nil {
+		return fmt.Errorf("failed to drop supplementary groups: %v", err)
+	}
+	if err := syscall.Setgid(req.GID); err != nil {
+		return fmt.Errorf("failed to set gid: %v", err)
+	}
+	if err := syscall.Setuid(req.UID); err != nil {
+		return fmt.Errorf("failed to set uid: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package getter
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox_Get_Isolated(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Must be run as root")
+	}
+	t.Parallel()
+	require := require.New(t)
+
+	ts := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir("./test-fixtures/"))))
+	defer ts.Close()
+
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(taskDir)
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: fmt.Sprintf("%s/test.sh", ts.URL),
+	}
+
+	// Hiding the directory containing the task directory must not prevent
+	// the download
+	config := testSandboxConfig()
+	config.HiddenPaths = []string{filepath.Dir(taskDir)}
+	s := testSandbox(t, config)
+	require.NoError(s.Get(context.Background(), taskEnv, artifact, taskDir))
+
+	// Downloaded files are owned by the client rather than the sandbox user
+	info, err := os.Stat(filepath.Join(taskDir, "test.sh"))
+	require.NoError(err)
+	require.EqualValues(0, info.Sys().(*syscall.Stat_t).Uid)
+}
+
+func TestSandbox_Get_HiddenPaths(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Must be run as root")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	t.Parallel()
+	require := require.New(t)
+
+	// Create a git repository in a directory the sandbox hides, to be read
+	// from the local filesystem
+	hidden, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(hidden)
+	require.NoError(os.Chmod(hidden, 0755))
+
+	repo := filepath.Join(hidden, "repo")
+	require.NoError(os.Mkdir(repo, 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(repo, "secret"), []byte("secret"), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "secret"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "secret"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(err, string(out))
+	}
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: "git::file://" + repo,
+		RelativeDest: "local/repo",
+	}
+
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(taskDir)
+
+	config := testSandboxConfig()
+	config.HiddenPaths = []string{hidden}
+	s := testSandbox(t, config)
+	require.Error(s.Get(context.Background(), taskEnv, artifact, taskDir))
+	_, err = os.Stat(filepath.Join(taskDir, "local", "repo", "secret"))
+	require.True(os.IsNotExist(err))
+}
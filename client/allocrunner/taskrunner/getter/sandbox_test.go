@@ -0,0 +1,267 @@
+package getter
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the test binary as the sandboxed subprocess when invoked
+// with the sandbox command, standing in for the nomad binary.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == SandboxCommand {
+		if err := RunSandbox(os.Stdin); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func testSandbox(t *testing.T, config *SandboxConfig) *Sandbox {
+	s := NewSandbox(testlog.HCLogger(t), config)
+	s.bin = os.Args[0]
+	return s
+}
+
+func testSandboxConfig() *SandboxConfig {
+	return &SandboxConfig{
+		Enabled: true,
+		User:    defaultSandboxUser,
+		Timeout: time.Minute,
+		MaxSize: 1024 * 1024,
+	}
+}
+
+func TestNewSandboxConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c := config.DefaultConfig()
+	c.StateDir = "/var/nomad/client"
+	c.AllocDir = "/var/nomad/alloc"
+
+	sc, err := NewSandboxConfig(c)
+	require.NoError(err)
+	require.True(sc.Enabled)
+	require.Equal(defaultSandboxUser, sc.User)
+	require.Equal(defaultTimeout, sc.Timeout)
+	require.EqualValues(20*1024*1024*1024, sc.MaxSize)
+	require.Equal([]string{"/var/nomad/client", "/var/nomad/alloc"}, sc.HiddenPaths)
+
+	c.Options = map[string]string{
+		sandboxEnableOption: "false",
+		sandboxUserOption:   "artifacts",
+		timeoutOption:       "5m",
+		maxSizeOption:       "1GB",
+	}
+	sc, err = NewSandboxConfig(c)
+	require.NoError(err)
+	require.False(sc.Enabled)
+	require.Equal("artifacts", sc.User)
+	require.Equal(5*time.Minute, sc.Timeout)
+	require.EqualValues(1024*1024*1024, sc.MaxSize)
+
+	c.Options = map[string]string{timeoutOption: "soon"}
+	_, err = NewSandboxConfig(c)
+	require.Error(err)
+
+	c.Options = map[string]string{maxSizeOption: "large"}
+	_, err = NewSandboxConfig(c)
+	require.Error(err)
+}
+
+func TestSandbox_Get(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ts := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir("./test-fixtures/"))))
+	defer ts.Close()
+
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(taskDir)
+
+	// Existing files in the destination are kept
+	require.NoError(os.MkdirAll(filepath.Join(taskDir, "local"), 0777))
+	require.NoError(ioutil.WriteFile(filepath.Join(taskDir, "local", "existing"), []byte("hello"), 0644))
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: fmt.Sprintf("%s/test.sh", ts.URL),
+		GetterOptions: map[string]string{
+			"checksum": "md5:bce963762aa2dbfed13caf492a45fb72",
+		},
+		RelativeDest: "local/",
+	}
+
+	s := testSandbox(t, testSandboxConfig())
+	require.NoError(s.Get(context.Background(), taskEnv, artifact, taskDir))
+
+	require.FileExists(filepath.Join(taskDir, "local", "test.sh"))
+	require.FileExists(filepath.Join(taskDir, "local", "existing"))
+
+	// The staging directory is removed
+	entries, err := ioutil.ReadDir(taskDir)
+	require.NoError(err)
+	require.Len(entries, 1)
+}
+
+func TestSandbox_Get_Cache(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("sandbox=%v", enabled), func(t *testing.T) {
+			require := require.New(t)
+
+			var requests int32
+			fs := http.FileServer(http.Dir(filepath.Dir("./test-fixtures/")))
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				fs.ServeHTTP(w, r)
+			}))
+			defer ts.Close()
+
+			cacheDir, err := ioutil.TempDir("", "nomad-test")
+			require.NoError(err)
+			defer os.RemoveAll(cacheDir)
+
+			cache, err := NewCache(testlog.HCLogger(t), cacheDir, 1024*1024)
+			require.NoError(err)
+
+			config := testSandboxConfig()
+			config.Enabled = enabled
+			config.Cache = cache
+			s := testSandbox(t, config)
+
+			artifact := &structs.TaskArtifact{
+				GetterSource: fmt.Sprintf("%s/test.sh", ts.URL),
+				GetterOptions: map[string]string{
+					"checksum": "md5:bce963762aa2dbfed13caf492a45fb72",
+				},
+				RelativeDest: "local/",
+			}
+
+			// The artifact is only downloaded by the first task
+			for i := 0; i < 2; i++ {
+				taskDir, err := ioutil.TempDir("", "nomad-test")
+				require.NoError(err)
+				defer os.RemoveAll(taskDir)
+
+				require.NoError(s.Get(context.Background(), taskEnv, artifact, taskDir))
+				require.FileExists(filepath.Join(taskDir, "local", "test.sh"))
+			}
+			require.EqualValues(1, atomic.LoadInt32(&requests))
+
+			// Artifacts without a checksum are always downloaded
+			artifact.GetterOptions = nil
+			for i := 0; i < 2; i++ {
+				taskDir, err := ioutil.TempDir("", "nomad-test")
+				require.NoError(err)
+				defer os.RemoveAll(taskDir)
+
+				require.NoError(s.Get(context.Background(), taskEnv, artifact, taskDir))
+			}
+			require.EqualValues(3, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestSandbox_Get_MaxSize(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ts := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir("./test-fixtures/"))))
+	defer ts.Close()
+
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(taskDir)
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: fmt.Sprintf("%s/test.sh", ts.URL),
+	}
+
+	config := testSandboxConfig()
+	config.MaxSize = 4
+	s := testSandbox(t, config)
+	require.Error(s.Get(context.Background(), taskEnv, artifact, taskDir))
+	_, err = os.Stat(filepath.Join(taskDir, "test.sh"))
+	require.True(os.IsNotExist(err))
+}
+
+func TestSandbox_Get_Timeout(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// The server never responds
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(taskDir)
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: fmt.Sprintf("%s/test.sh", ts.URL),
+	}
+
+	config := testSandboxConfig()
+	config.Timeout = 500 * time.Millisecond
+	s := testSandbox(t, config)
+
+	start := time.Now()
+	err = s.Get(context.Background(), taskEnv, artifact, taskDir)
+	require.Error(err)
+	require.Contains(err.Error(), "timed out")
+	require.True(err.(*GetError).IsRecoverable())
+	require.True(time.Since(start) < 10*time.Second)
+}
+
+func TestMoveTree(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(os.MkdirAll(filepath.Join(src, "a", "b"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(src, "a", "b", "new"), []byte("new"), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(src, "a", "replaced"), []byte("new"), 0644))
+	require.NoError(os.MkdirAll(filepath.Join(dst, "a"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(dst, "a", "replaced"), []byte("old"), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(dst, "a", "kept"), []byte("old"), 0644))
+
+	require.NoError(moveTree(src, dst))
+
+	b, err := ioutil.ReadFile(filepath.Join(dst, "a", "b", "new"))
+	require.NoError(err)
+	require.Equal("new", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(dst, "a", "replaced"))
+	require.NoError(err)
+	require.Equal("new", string(b))
+	require.FileExists(filepath.Join(dst, "a", "kept"))
+
+	// A file can't replace a directory
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644))
+	require.Error(moveTree(filepath.Join(dst, "a"), filepath.Join(dir, "file")))
+}
@@ -11,10 +11,22 @@ import (
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/logmon"
+	"github.com/hashicorp/nomad/client/logmon/sink"
 	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
 	pstructs "github.com/hashicorp/nomad/plugins/shared/structs"
 )
 
+const (
+	// logSinkFile is the name of the log sink writing to files in a
+	// directory chosen by the operator
+	logSinkFile = "file"
+
+	// logSinkFileDirOption is the client option setting the directory the
+	// file log sink writes to
+	logSinkFileDirOption = "logs.sink.file.dir"
+)
+
 // logmonHook launches logmon and manages task logging
 type logmonHook struct {
 	// logmon is the handle to the log monitor process for the task.
@@ -30,6 +42,13 @@ type logmonHookConfig struct {
 	logDir     string
 	stdoutFifo string
 	stderrFifo string
+
+	// allocID is the ID of the task's allocation, passed to log sinks
+	allocID string
+
+	// fileSinkDir is the directory the file log sink writes to, set by the
+	// logs.sink.file.dir client option
+	fileSinkDir string
 }
 
 func newLogMonHook(cfg *logmonHookConfig, logger hclog.Logger) *logmonHook {
@@ -94,6 +113,11 @@ func (h *logmonHook) Prestart(ctx context.Context,
 		return err
 	}
 
+	sinkOpts, err := h.sinkOptions(req.Task.LogConfig)
+	if err != nil {
+		return err
+	}
+
 	// Launch or reattach logmon instance for the task.
 	if err := h.launchLogMon(reattachConfig); err != nil {
 		h.logger.Error("failed to launch logmon process", "error", err)
@@ -110,6 +134,11 @@ func (h *logmonHook) Prestart(ctx context.Context,
 			StderrFifo:    h.config.stderrFifo,
 			MaxFiles:      req.Task.LogConfig.MaxFiles,
 			MaxFileSizeMB: req.Task.LogConfig.MaxFileSizeMB,
+			TaskName:      req.Task.Name,
+			AllocID:       h.config.allocID,
+			Sink:          req.Task.LogConfig.Sink,
+			SinkOptions:   sinkOpts,
+			DisableFiles:  req.Task.LogConfig.DisableFiles,
 		})
 		if err != nil {
 			h.logger.Error("failed to start logmon", "error", err)
@@ -128,6 +157,28 @@ func (h *logmonHook) Prestart(ctx context.Context,
 	return nil
 }
 
+// sinkOptions returns the options of the task's log sink. The directory of the
+// file sink is set by the client, ignoring any directory set by the job, so
+// that tasks can only write to the directory chosen by the operator.
+func (h *logmonHook) sinkOptions(cfg *structs.LogConfig) (map[string]string, error) {
+	if cfg.Sink != logSinkFile {
+		return cfg.SinkOptions, nil
+	}
+	if h.config.fileSinkDir == "" {
+		return nil, fmt.Errorf("file log sink is not enabled: the %q client option is not set", logSinkFileDirOption)
+	}
+
+	opts := make(map[string]string, len(cfg.SinkOptions)+1)
+	for k, v := range cfg.SinkOptions {
+		opts[k] = v
+	}
+	if _, ok := opts[sink.FileDirOption]; ok {
+		h.logger.Warn("ignoring log sink option set by the job", "option", sink.FileDirOption)
+	}
+	opts[sink.FileDirOption] = h.config.fileSinkDir
+	return opts, nil
+}
+
 func (h *logmonHook) Stop(context.Context, *interfaces.TaskStopRequest, *interfaces.TaskStopResponse) error {
 
 	if h.logmon != nil {
@@ -61,7 +61,7 @@ func (h *taskDirHook) Prestart(ctx context.Context, req *interfaces.TaskPrestart
 	h.runner.EmitEvent(structs.NewTaskEvent(structs.TaskSetup).SetMessage(structs.TaskBuildingTaskDir))
 
 	// Build the task directory structure
-	err := h.runner.taskDir.Build(fsi == drivers.FSIsolationChroot, chroot)
+	err := h.runner.taskDir.BuildWithCache(fsi == drivers.FSIsolationChroot, chroot, h.runner.chrootCache)
 	if err != nil {
 		return err
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/hashicorp/hcl2/hcldec"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/restarts"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
 	"github.com/hashicorp/nomad/client/config"
@@ -161,6 +162,9 @@ type TaskRunner struct {
 	// vaultClient is the client to use to derive and renew Vault tokens
 	vaultClient vaultclient.VaultClient
 
+	// vaultClusters are the clients of the named Vault clusters
+	vaultClusters map[string]vaultclient.VaultClient
+
 	// vaultToken is the current Vault token. It should be accessed with the
 	// getter.
 	vaultToken     string
@@ -182,6 +186,10 @@ type TaskRunner struct {
 	// deviceStatsReporter is used to lookup resource usage for alloc devices
 	deviceStatsReporter cinterfaces.DeviceStatsReporter
 
+	// allocMetricsLimiter bounds the number of tasks publishing resource
+	// usage metrics
+	allocMetricsLimiter cinterfaces.AllocMetricsLimiter
+
 	// devicemanager is used to mount devices as well as lookup device
 	// statistics
 	devicemanager devicemanager.Manager
@@ -190,6 +198,18 @@ type TaskRunner struct {
 	// handlers
 	driverManager drivermanager.Manager
 
+	// chrootCache keeps the chroot skeleton of tasks if chroot caching is
+	// enabled
+	chrootCache *allocdir.ChrootCache
+
+	// artifactCache caches the artifacts downloaded by tasks if artifact
+	// caching is enabled
+	artifactCache *getter.Cache
+
+	// startAttemptAt is when the current attempt to start the task began
+	// and is used to compute its start latency. It is guarded by stateLock.
+	startAttemptAt time.Time
+
 	// runLaunched marks whether the Run goroutine has been started. It should
 	// be accessed via helpers
 	runLaunched     bool
@@ -211,6 +231,10 @@ type Config struct {
 	// Vault is the client to use to derive and renew Vault tokens
 	Vault vaultclient.VaultClient
 
+	// VaultClusters are the clients of the named Vault clusters, indexed by
+	// name
+	VaultClusters map[string]vaultclient.VaultClient
+
 	// StateDB is used to store and restore state.
 	StateDB cstate.StateDB
 
@@ -220,6 +244,10 @@ type Config struct {
 	// deviceStatsReporter is used to lookup resource usage for alloc devices
 	DeviceStatsReporter cinterfaces.DeviceStatsReporter
 
+	// AllocMetricsLimiter bounds the number of tasks publishing resource
+	// usage metrics
+	AllocMetricsLimiter cinterfaces.AllocMetricsLimiter
+
 	// DeviceManager is used to mount devices as well as lookup device
 	// statistics
 	DeviceManager devicemanager.Manager
@@ -227,6 +255,14 @@ type Config struct {
 	// DriverManager is used to dispense driver plugins and register event
 	// handlers
 	DriverManager drivermanager.Manager
+
+	// ChrootCache keeps the chroot skeleton of tasks. Nil if chroot caching
+	// is disabled.
+	ChrootCache *allocdir.ChrootCache
+
+	// ArtifactCache caches the artifacts downloaded by tasks. Nil if
+	// artifact caching is disabled.
+	ArtifactCache *getter.Cache
 }
 
 func NewTaskRunner(config *Config) (*TaskRunner, error) {
@@ -261,11 +297,13 @@ func NewTaskRunner(config *Config) (*TaskRunner, error) {
 		envBuilder:          envBuilder,
 		consulClient:        config.Consul,
 		vaultClient:         config.Vault,
+		vaultClusters:       config.VaultClusters,
 		state:               tstate,
 		localState:          state.NewLocalState(),
 		stateDB:             config.StateDB,
 		stateUpdater:        config.StateUpdater,
 		deviceStatsReporter: config.DeviceStatsReporter,
+		allocMetricsLimiter: config.AllocMetricsLimiter,
 		killCtx:             killCtx,
 		killCtxCancel:       killCancel,
 		shutdownCtx:         trCtx,
@@ -274,6 +312,8 @@ func NewTaskRunner(config *Config) (*TaskRunner, error) {
 		waitCh:              make(chan struct{}),
 		devicemanager:       config.DeviceManager,
 		driverManager:       config.DriverManager,
+		chrootCache:         config.ChrootCache,
+		artifactCache:       config.ArtifactCache,
 		maxEvents:           defaultMaxEvents,
 	}
 
@@ -373,6 +413,20 @@ func (tr *TaskRunner) initLabels() {
 			})
 		}
 	}
+
+	// Only keep the allowed labels to bound the cardinality of the metrics
+	if allowed := tr.clientConfig.AllocationMetricsLabels; len(allowed) > 0 {
+		labels := tr.baseLabels[:0]
+		for _, l := range tr.baseLabels {
+			for _, name := range allowed {
+				if l.Name == name {
+					labels = append(labels, l)
+					break
+				}
+			}
+		}
+		tr.baseLabels = labels
+	}
 }
 
 // Run the TaskRunner. Starts the user's task or reattaches to a restored task.
@@ -383,6 +437,7 @@ func (tr *TaskRunner) Run() {
 	tr.setRunLaunched()
 
 	defer close(tr.waitCh)
+	defer tr.releaseAllocMetrics()
 	var result *drivers.ExitResult
 
 	// Updates are handled asynchronously with the other hooks but each
@@ -402,6 +457,10 @@ MAIN:
 		default:
 		}
 
+		tr.stateLock.Lock()
+		tr.startAttemptAt = time.Now()
+		tr.stateLock.Unlock()
+
 		// Run the prestart hooks
 		if err := tr.prestart(); err != nil {
 			tr.logger.Error("prestart failed", "error", err)
@@ -518,6 +577,7 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 		// Initialize a new driver handle
 		if err := tr.initDriver(); err != nil {
 			tr.logger.Error("failed to initialize driver after it exited unexpectedly", "error", err, "driver", dn)
+			tr.failUnrecoveredTask(fmt.Errorf("driver plugin exited and could not be restarted: %v", err))
 			return false
 		}
 
@@ -528,6 +588,7 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 		tr.stateLock.RUnlock()
 		if !tr.restoreHandle(h, net) {
 			tr.logger.Error("failed to restore handle on driver after it exited unexpectedly", "driver", dn)
+			tr.failUnrecoveredTask(fmt.Errorf("driver plugin exited and the task could not be recovered"))
 			return false
 		}
 
@@ -539,6 +600,7 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 		SetExitCode(result.ExitCode).
 		SetSignal(result.Signal).
 		SetOOMKilled(result.OOMKilled).
+		SetExitDetails(result.Details).
 		SetExitMessage(result.Err)
 
 	tr.EmitEvent(event)
@@ -550,6 +612,14 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 	return false
 }
 
+// failUnrecoveredTask fails a task lost along with its driver plugin. The task
+// isn't restarted locally, where it may still be running outside of the
+// driver's control, so the allocation is rescheduled instead.
+func (tr *TaskRunner) failUnrecoveredTask(err error) {
+	tr.EmitEvent(structs.NewTaskEvent(structs.TaskDriverFailure).SetDriverError(err))
+	tr.restartTracker.SetStartError(structs.NewRecoverableError(err, false))
+}
+
 // handleUpdates runs update hooks when triggerUpdateCh is ticked and exits
 // when Run has returned. Should only be run in a goroutine from Run.
 func (tr *TaskRunner) handleUpdates() {
@@ -810,6 +880,7 @@ func (tr *TaskRunner) buildTaskConfig() *drivers.TaskConfig {
 				MemoryLimitBytes: taskResources.Memory.MemoryMB * 1024 * 1024,
 				CPUShares:        taskResources.Cpu.CpuShares,
 				PercentTicks:     float64(taskResources.Cpu.CpuShares) / float64(tr.clientConfig.Node.NodeResources.Cpu.CpuShares),
+				CpusetCPUs:       tr.clientConfig.TaskCpuset,
 			},
 		},
 		Devices:    tr.hookResources.getDevices(),
@@ -927,7 +998,11 @@ func (tr *TaskRunner) updateStateImpl(state string) error {
 	case structs.TaskStateRunning:
 		// Capture the start time if it is just starting
 		if oldState != structs.TaskStateRunning {
-			taskState.StartedAt = time.Now().UTC()
+			now := time.Now()
+			taskState.StartedAt = now.UTC()
+			if !tr.startAttemptAt.IsZero() {
+				taskState.StartLatency = now.Sub(tr.startAttemptAt)
+			}
 			if !tr.clientConfig.DisableTaggedMetrics {
 				metrics.IncrCounterWithLabels([]string{"client", "allocs", "running"}, 1, tr.baseLabels)
 			}
@@ -1182,6 +1257,11 @@ func (tr *TaskRunner) emitStats(ru *cstructs.TaskResourceUsage) {
 		return
 	}
 
+	// Skip the task if the limit of tasks publishing metrics is reached
+	if tr.allocMetricsLimiter != nil && !tr.allocMetricsLimiter.AcquireAllocMetrics(tr.allocID, tr.taskName) {
+		return
+	}
+
 	if ru.ResourceUsage.MemoryStats != nil {
 		tr.setGaugeForMemory(ru)
 	}
@@ -1191,6 +1271,14 @@ func (tr *TaskRunner) emitStats(ru *cstructs.TaskResourceUsage) {
 	}
 }
 
+// releaseAllocMetrics frees the slot of the task in the limit of tasks
+// publishing metrics once it stops running.
+func (tr *TaskRunner) releaseAllocMetrics() {
+	if tr.allocMetricsLimiter != nil {
+		tr.allocMetricsLimiter.ReleaseAllocMetrics(tr.allocID, tr.taskName)
+	}
+}
+
 // appendTaskEvent updates the task status by appending the new event.
 func appendTaskEvent(state *structs.TaskState, event *structs.TaskEvent, capacity int) {
 	if state.Events == nil {
@@ -1,7 +1,9 @@
 package taskrunner
 
 import (
+	"github.com/hashicorp/nomad/client/vaultclient"
 	"github.com/hashicorp/nomad/nomad/structs"
+	sconfig "github.com/hashicorp/nomad/nomad/structs/config"
 )
 
 func (tr *TaskRunner) Alloc() *structs.Allocation {
@@ -45,6 +47,19 @@ func (tr *TaskRunner) getVaultToken() string {
 	return tr.vaultToken
 }
 
+// vaultClientFor returns the client of the named Vault cluster, or nil if the
+// cluster isn't configured on the client. An empty name selects the default
+// cluster.
+func (tr *TaskRunner) vaultClientFor(cluster string) vaultclient.VaultClient {
+	if cluster == "" || cluster == sconfig.DefaultVaultClusterName {
+		return tr.vaultClient
+	}
+	if v, ok := tr.vaultClusters[cluster]; ok {
+		return v
+	}
+	return nil
+}
+
 // setVaultToken updates the vault token on the task runner as well as in the
 // task's environment. These two places must be set atomically to avoid a task
 // seeing a different token on the task runner and in its environment.
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
 	"github.com/hashicorp/nomad/nomad/structs"
+	sconfig "github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/plugins/drivers"
 )
 
@@ -50,6 +51,8 @@ func (tr *TaskRunner) initHooks() {
 	task := tr.Task()
 
 	tr.logmonHookConfig = newLogMonHookConfig(task.Name, tr.taskDir.LogDir)
+	tr.logmonHookConfig.allocID = tr.allocID
+	tr.logmonHookConfig.fileSinkDir = tr.clientConfig.ReadDefault(logSinkFileDirOption, "")
 
 	// Add the hook resources
 	tr.hookResources = &hookResources{}
@@ -61,16 +64,18 @@ func (tr *TaskRunner) initHooks() {
 		newTaskDirHook(tr, hookLogger),
 		newLogMonHook(tr.logmonHookConfig, hookLogger),
 		newDispatchHook(tr.Alloc(), hookLogger),
-		newArtifactHook(tr, hookLogger),
+		newArtifactHook(tr, tr.clientConfig, tr.artifactCache, hookLogger),
 		newStatsHook(tr, tr.clientConfig.StatsCollectionInterval, hookLogger),
 		newDeviceHook(tr.devicemanager, hookLogger),
 	}
 
 	// If Vault is enabled, add the hook
+	var vaultConfig *sconfig.VaultConfig
 	if task.Vault != nil {
+		vaultConfig = tr.clientConfig.VaultConfigFor(task.Vault.Cluster)
 		tr.runnerHooks = append(tr.runnerHooks, newVaultHook(&vaultHookConfig{
 			vaultStanza: task.Vault,
-			client:      tr.vaultClient,
+			client:      tr.vaultClientFor(task.Vault.Cluster),
 			events:      tr,
 			lifecycle:   tr,
 			updater:     tr,
@@ -88,6 +93,7 @@ func (tr *TaskRunner) initHooks() {
 			events:       tr,
 			templates:    task.Templates,
 			clientConfig: tr.clientConfig,
+			vaultConfig:  vaultConfig,
 			envBuilder:   tr.envBuilder,
 		}))
 	}
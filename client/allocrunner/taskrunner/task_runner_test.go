@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/golang/snappy"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
@@ -157,6 +158,33 @@ func TestTaskRunner_Restore_Running(t *testing.T) {
 	assert.Equal(t, 1, started)
 }
 
+// TestTaskRunner_StartLatency asserts the time taken to start a task is
+// recorded in its state.
+func TestTaskRunner_StartLatency(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "mock_driver"
+	task.Config = map[string]interface{}{
+		"start_block_for": "200ms",
+		"run_for":         "10s",
+	}
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name)
+	defer cleanup()
+
+	tr, err := NewTaskRunner(conf)
+	require.NoError(err)
+	go tr.Run()
+	defer tr.Kill(context.Background(), structs.NewTaskEvent("cleanup"))
+
+	testWaitForTaskToStart(t, tr)
+
+	state := tr.TaskState()
+	require.True(state.StartLatency >= 200*time.Millisecond, "latency %v", state.StartLatency)
+}
+
 // TestTaskRunner_TaskEnv asserts driver configurations are interpolated.
 func TestTaskRunner_TaskEnv(t *testing.T) {
 	t.Parallel()
@@ -776,3 +804,52 @@ func testWaitForTaskToStart(t *testing.T, tr *TaskRunner) {
 		require.NoError(t, err)
 	})
 }
+
+// TestTaskRunner_AllocationMetricsLabels asserts that only the allowed labels
+// are attached to the metrics of the task.
+func TestTaskRunner_AllocationMetricsLabels(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name)
+	defer cleanup()
+
+	conf.ClientConfig.AllocationMetricsLabels = []string{"job", "task"}
+	tr, err := NewTaskRunner(conf)
+	require.NoError(err)
+
+	require.Equal([]metrics.Label{
+		{Name: "job", Value: alloc.Job.Name},
+		{Name: "task", Value: task.Name},
+	}, tr.baseLabels)
+}
+
+// TestTaskRunner_FailUnrecoveredTask asserts a task lost along with its
+// driver plugin fails instead of being restarted locally.
+func TestTaskRunner_FailUnrecoveredTask(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "mock_driver"
+	alloc.Job.TaskGroups[0].RestartPolicy.Attempts = 3
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name)
+	defer cleanup()
+
+	tr, err := NewTaskRunner(conf)
+	require.NoError(err)
+
+	tr.failUnrecoveredTask(fmt.Errorf("driver plugin exited and the task could not be recovered"))
+
+	events := tr.TaskState().Events
+	require.NotEmpty(events)
+	last := events[len(events)-1]
+	require.Equal(structs.TaskDriverFailure, last.Type)
+	require.Contains(last.DriverError, "could not be recovered")
+
+	state, _ := tr.restartTracker.GetState()
+	require.Equal(structs.TaskNotRestarting, state)
+}
@@ -3,6 +3,7 @@ package template
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"text/template/parse"
 	"time"
 
 	ctconf "github.com/hashicorp/consul-template/config"
@@ -22,6 +24,7 @@ import (
 	"github.com/hashicorp/nomad/client/taskenv"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs"
+	sconfig "github.com/hashicorp/nomad/nomad/structs/config"
 )
 
 const (
@@ -32,6 +35,14 @@ const (
 	// source may be from the host
 	hostSrcOption = "template.allow_host_source"
 
+	// functionDenylistOption is the Client option that lists the template
+	// functions templates may not call
+	functionDenylistOption = "template.function_denylist"
+
+	// defaultFunctionDenylist is the default list of denied template
+	// functions. The plugin function runs arbitrary commands on the host.
+	defaultFunctionDenylist = "plugin"
+
 	// missingDepEventLimit is the number of missing dependencies that will be
 	// logged before we switch to showing just the number of missing
 	// dependencies.
@@ -42,6 +53,11 @@ const (
 	DefaultMaxTemplateEventRate = 3 * time.Second
 )
 
+// sandboxDeniedFunctions are the template functions sandboxed templates may not
+// call since they access the host: plugin runs commands, file reads files and
+// env falls back to the environment of the client.
+var sandboxDeniedFunctions = []string{"plugin", "file", "env"}
+
 // TaskTemplateManager is used to run a set of templates for a given task
 type TaskTemplateManager struct {
 	// config holds the template managers configuration
@@ -87,6 +103,10 @@ type TaskTemplateManagerConfig struct {
 	// VaultToken is the Vault token for the task.
 	VaultToken string
 
+	// VaultConfig is the config of the Vault cluster the task uses. If nil,
+	// the Vault config of the client is used.
+	VaultConfig *sconfig.VaultConfig
+
 	// TaskDir is the task's directory
 	TaskDir string
 
@@ -529,6 +549,7 @@ func templateRunner(config *TaskTemplateManagerConfig) (
 // consul-templates
 func parseTemplateConfigs(config *TaskTemplateManagerConfig) (map[ctconf.TemplateConfig]*structs.Template, error) {
 	allowAbs := config.ClientConfig.ReadBoolDefault(hostSrcOption, true)
+	denied := config.ClientConfig.ReadStringListToMapDefault(functionDenylistOption, defaultFunctionDenylist)
 	taskEnv := config.EnvBuilder.Build()
 
 	ctmpls := make(map[ctconf.TemplateConfig]*structs.Template, len(config.Templates))
@@ -549,6 +570,10 @@ func parseTemplateConfigs(config *TaskTemplateManagerConfig) (map[ctconf.Templat
 			dest = filepath.Join(config.TaskDir, taskEnv.ReplaceEnv(tmpl.DestPath))
 		}
 
+		if err := checkTemplateFunctions(tmpl, src, denied); err != nil {
+			return nil, err
+		}
+
 		ct := ctconf.DefaultTemplateConfig()
 		ct.Source = &src
 		ct.Destination = &dest
@@ -641,22 +666,26 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 	emptyStr := ""
 	conf.Vault.RenewToken = helper.BoolToPtr(false)
 	conf.Vault.Token = &emptyStr
-	if cc.VaultConfig != nil && cc.VaultConfig.IsEnabled() {
-		conf.Vault.Address = &cc.VaultConfig.Addr
+	vc := cc.VaultConfig
+	if config.VaultConfig != nil {
+		vc = config.VaultConfig
+	}
+	if vc != nil && vc.IsEnabled() {
+		conf.Vault.Address = &vc.Addr
 		conf.Vault.Token = &config.VaultToken
 		conf.Vault.Grace = helper.TimeToPtr(vaultGrace)
 
-		if strings.HasPrefix(cc.VaultConfig.Addr, "https") || cc.VaultConfig.TLSCertFile != "" {
-			skipVerify := cc.VaultConfig.TLSSkipVerify != nil && *cc.VaultConfig.TLSSkipVerify
+		if strings.HasPrefix(vc.Addr, "https") || vc.TLSCertFile != "" {
+			skipVerify := vc.TLSSkipVerify != nil && *vc.TLSSkipVerify
 			verify := !skipVerify
 			conf.Vault.SSL = &ctconf.SSLConfig{
 				Enabled:    helper.BoolToPtr(true),
 				Verify:     &verify,
-				Cert:       &cc.VaultConfig.TLSCertFile,
-				Key:        &cc.VaultConfig.TLSKeyFile,
-				CaCert:     &cc.VaultConfig.TLSCaFile,
-				CaPath:     &cc.VaultConfig.TLSCaPath,
-				ServerName: &cc.VaultConfig.TLSServerName,
+				Cert:       &vc.TLSCertFile,
+				Key:        &vc.TLSKeyFile,
+				CaCert:     &vc.TLSCaFile,
+				CaPath:     &vc.TLSCaPath,
+				ServerName: &vc.TLSServerName,
 			}
 		} else {
 			conf.Vault.SSL = &ctconf.SSLConfig{
@@ -701,3 +730,105 @@ func loadTemplateEnv(tmpls []*structs.Template, taskDir string, taskEnv *taskenv
 	}
 	return all, nil
 }
+
+// checkTemplateFunctions returns an error if the template calls a function
+// denied by the client or, for sandboxed templates, one accessing the host.
+// Templates that fail to parse are left for consul-template to report.
+func checkTemplateFunctions(tmpl *structs.Template, src string, denied map[string]struct{}) error {
+	if tmpl.Sandbox {
+		sandboxed := make(map[string]struct{}, len(denied)+len(sandboxDeniedFunctions))
+		for f := range denied {
+			sandboxed[f] = struct{}{}
+		}
+		for _, f := range sandboxDeniedFunctions {
+			sandboxed[f] = struct{}{}
+		}
+		denied = sandboxed
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+
+	contents := tmpl.EmbeddedTmpl
+	if src != "" {
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil
+		}
+		contents = string(data)
+	}
+
+	t := parse.New("")
+	t.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := t.Parse(contents, tmpl.LeftDelim, tmpl.RightDelim, trees); err != nil {
+		return nil
+	}
+
+	for _, tree := range trees {
+		if f := deniedFunction(tree.Root, denied); f != "" {
+			return fmt.Errorf("Template %q calls disallowed function %q", tmpl.DestPath, f)
+		}
+	}
+	return nil
+}
+
+// deniedFunction returns the first denied function called within the parse
+// tree node, or "" if there is none.
+func deniedFunction(node parse.Node, denied map[string]struct{}) string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return ""
+		}
+		for _, c := range n.Nodes {
+			if f := deniedFunction(c, denied); f != "" {
+				return f
+			}
+		}
+	case *parse.ActionNode:
+		return deniedFunction(n.Pipe, denied)
+	case *parse.IfNode:
+		return deniedBranchFunction(&n.BranchNode, denied)
+	case *parse.RangeNode:
+		return deniedBranchFunction(&n.BranchNode, denied)
+	case *parse.WithNode:
+		return deniedBranchFunction(&n.BranchNode, denied)
+	case *parse.TemplateNode:
+		return deniedFunction(n.Pipe, denied)
+	case *parse.PipeNode:
+		if n == nil {
+			return ""
+		}
+		for _, c := range n.Cmds {
+			if f := deniedFunction(c, denied); f != "" {
+				return f
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if f := deniedFunction(arg, denied); f != "" {
+				return f
+			}
+		}
+	case *parse.ChainNode:
+		return deniedFunction(n.Node, denied)
+	case *parse.IdentifierNode:
+		if _, ok := denied[n.Ident]; ok {
+			return n.Ident
+		}
+	}
+	return ""
+}
+
+// deniedBranchFunction returns the first denied function called within the
+// pipeline or lists of an if, range or with node.
+func deniedBranchFunction(n *parse.BranchNode, denied map[string]struct{}) string {
+	if f := deniedFunction(n.Pipe, denied); f != "" {
+		return f
+	}
+	if f := deniedFunction(n.List, denied); f != "" {
+		return f
+	}
+	return deniedFunction(n.ElseList, denied)
+}
@@ -395,6 +395,44 @@ func TestTaskTemplateManager_HostPath(t *testing.T) {
 	}
 }
 
+func TestTaskTemplateManager_FunctionDenylist(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	template := &structs.Template{
+		EmbeddedTmpl: `{{ if true }}{{ plugin "echo" "hi" }}{{ end }}`,
+		DestPath:     "my.tmpl",
+		ChangeMode:   structs.TemplateChangeModeNoop,
+	}
+
+	// The plugin function is denied by default
+	harness := newTestHarness(t, []*structs.Template{template}, false, false)
+	defer harness.stop()
+	err := harness.startWithErr()
+	require.Error(err)
+	require.Contains(err.Error(), `"plugin"`)
+
+	// Clients may change the denied functions
+	template.EmbeddedTmpl = `{{ "foo" | toUpper }}`
+	harness.config.Options = map[string]string{
+		functionDenylistOption: "toUpper, toLower",
+	}
+	err = harness.startWithErr()
+	require.Error(err)
+	require.Contains(err.Error(), `"toUpper"`)
+
+	// Sandboxed templates may not access the host
+	template.EmbeddedTmpl = `{{ with $v := env "HOME" }}{{ $v }}{{ end }}`
+	template.Sandbox = true
+	harness.config.Options = nil
+	err = harness.startWithErr()
+	require.Error(err)
+	require.Contains(err.Error(), `"env"`)
+
+	template.EmbeddedTmpl = `{{ env "HOME" }}`
+	template.Sandbox = false
+	require.NoError(harness.startWithErr())
+}
+
 func TestTaskTemplateManager_Unblock_Static(t *testing.T) {
 	t.Parallel()
 	// Make a template that will render immediately
@@ -1281,6 +1319,32 @@ func TestTaskTemplateManager_Config_ServerName(t *testing.T) {
 	}
 }
 
+// TestTaskTemplateManager_Config_VaultCluster asserts the config of the task's
+// Vault cluster is used instead of the client's default Vault config.
+func TestTaskTemplateManager_Config_VaultCluster(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	c := config.DefaultConfig()
+	c.VaultConfig = &sconfig.VaultConfig{
+		Enabled: helper.BoolToPtr(true),
+		Addr:    "https://localhost/",
+	}
+	config := &TaskTemplateManagerConfig{
+		ClientConfig: c,
+		VaultToken:   "token",
+		VaultConfig: &sconfig.VaultConfig{
+			Name:          "eu",
+			Enabled:       helper.BoolToPtr(true),
+			Addr:          "https://vault.eu/",
+			TLSServerName: "vault.eu",
+		},
+	}
+	ctconf, err := newRunnerConfig(config, nil)
+	require.NoError(err)
+	require.Equal("https://vault.eu/", *ctconf.Vault.Address)
+	require.Equal("vault.eu", *ctconf.Vault.SSL.ServerName)
+}
+
 // TestTaskTemplateManager_Config_VaultGrace asserts the vault_grace setting is
 // propagated to consul-template's configuration.
 func TestTaskTemplateManager_Config_VaultGrace(t *testing.T) {
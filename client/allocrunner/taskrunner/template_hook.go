@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/taskenv"
 	"github.com/hashicorp/nomad/nomad/structs"
+	sconfig "github.com/hashicorp/nomad/nomad/structs/config"
 )
 
 type templateHookConfig struct {
@@ -30,6 +31,9 @@ type templateHookConfig struct {
 	// clientConfig is the Nomad Client configuration
 	clientConfig *config.Config
 
+	// vaultConfig is the config of the Vault cluster the task uses
+	vaultConfig *sconfig.VaultConfig
+
 	// envBuilder is the environment variable builder for the task.
 	envBuilder *taskenv.Builder
 }
@@ -98,6 +102,7 @@ func (h *templateHook) newManager() (unblock chan struct{}, err error) {
 		Templates:            h.config.templates,
 		ClientConfig:         h.config.clientConfig,
 		VaultToken:           h.vaultToken,
+		VaultConfig:          h.config.vaultConfig,
 		TaskDir:              h.taskDir,
 		EnvBuilder:           h.config.envBuilder,
 		MaxTemplateEventRate: template.DefaultMaxTemplateEventRate,
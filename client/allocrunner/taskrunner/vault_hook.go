@@ -118,6 +118,11 @@ func (*vaultHook) Name() string {
 }
 
 func (h *vaultHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartRequest, resp *interfaces.TaskPrestartResponse) error {
+	if h.client == nil {
+		return structs.NewRecoverableError(
+			fmt.Errorf("Vault cluster %q not configured on client", h.vaultStanza.Cluster), false)
+	}
+
 	// If we have already run prestart before exit early. We do not use the
 	// PrestartDone value because we want to recover the token on restoration.
 	first := h.firstRun
@@ -23,6 +23,7 @@ import (
 	"github.com/hashicorp/nomad/client/allocrunner"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	arstate "github.com/hashicorp/nomad/client/allocrunner/state"
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	"github.com/hashicorp/nomad/client/allocwatcher"
 	"github.com/hashicorp/nomad/client/config"
 	consulApi "github.com/hashicorp/nomad/client/consul"
@@ -124,6 +125,10 @@ type AllocRunner interface {
 	DestroyCh() <-chan struct{}
 	ShutdownCh() <-chan struct{}
 	GetTaskEventHandler(taskName string) drivermanager.EventHandler
+
+	RestartTask(taskName string, taskEvent *structs.TaskEvent) error
+	RestartAll(taskEvent *structs.TaskEvent) error
+	Signal(taskName, signal string) error
 }
 
 // Client is used to implement the client interaction with Nomad. Clients
@@ -215,6 +220,10 @@ type Client struct {
 	// vaultClient is used to interact with Vault for token and secret renewals
 	vaultClient vaultclient.VaultClient
 
+	// vaultClusters are the clients of the named Vault clusters, indexed by
+	// name
+	vaultClusters map[string]vaultclient.VaultClient
+
 	// garbageCollector is used to garbage collect terminal allocations present
 	// in the node automatically
 	garbageCollector *AllocGarbageCollector
@@ -240,6 +249,22 @@ type Client struct {
 	// have these tags, and optionally more.
 	baseLabels []metrics.Label
 
+	// allocMetrics bounds the number of tasks publishing resource usage
+	// metrics
+	allocMetrics *allocMetricsLimiter
+
+	// chrootCache keeps the chroot skeleton of tasks if chroot caching is
+	// enabled
+	chrootCache *allocdir.ChrootCache
+
+	// diskQuota enforces the ephemeral disk size of allocations if disk
+	// quotas are enabled and supported
+	diskQuota *allocdir.ProjectQuota
+
+	// artifactCache caches the artifacts downloaded by tasks if artifact
+	// caching is enabled
+	artifactCache *getter.Cache
+
 	// batchNodeUpdates is used to batch initial updates to the node
 	batchNodeUpdates *batchNodeUpdates
 
@@ -296,8 +321,12 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulServic
 		triggerEmitNodeEvent: make(chan *structs.NodeEvent, 8),
 		fpInitialized:        make(chan struct{}),
 		invalidAllocs:        make(map[string]struct{}),
+		allocMetrics:         newAllocMetricsLimiter(cfg.AllocationMetricsLimit),
 	}
 
+	// Register services using the nomad provider in the servers' catalog
+	c.consulService = newServiceRegistrationHandler(logger, consulService, c)
+
 	c.batchNodeUpdates = newBatchNodeUpdates(
 		c.updateNodeFromDriver,
 		c.updateNodeFromDevices,
@@ -525,6 +554,37 @@ func (c *Client) init() error {
 	}
 
 	c.logger.Info("using alloc directory", "alloc_dir", c.config.AllocDir)
+
+	// Remove the chroot skeletons built for a previous chroot_env
+	if c.config.ChrootCache {
+		chroot := config.DefaultChrootEnv
+		if len(c.config.ChrootEnv) > 0 {
+			chroot = c.config.ChrootEnv
+		}
+
+		c.chrootCache = allocdir.NewChrootCache(c.logger, filepath.Join(c.config.AllocDir, allocdir.ChrootCacheDirName))
+		if err := c.chrootCache.Prune(chroot); err != nil {
+			return err
+		}
+	}
+
+	// Enforce the ephemeral disk size of allocations if the filesystem of
+	// the alloc dir supports it, otherwise it stays advisory
+	if c.config.EphemeralDiskQuota {
+		quota, err := allocdir.NewProjectQuota(c.logger, c.config.AllocDir)
+		if err != nil {
+			c.logger.Warn("ephemeral disk quotas can't be enforced", "alloc_dir", c.config.AllocDir, "error", err)
+		} else {
+			c.diskQuota = quota
+		}
+	}
+
+	// Share artifacts with a checksum between allocations
+	cache, err := getter.NewCacheFromConfig(c.logger, c.config)
+	if err != nil {
+		return err
+	}
+	c.artifactCache = cache
 	return nil
 }
 
@@ -563,6 +623,8 @@ func (c *Client) reloadTLSConnections(newConfig *nconfig.TLSConfig) error {
 
 // Reload allows a client to reload its configuration on the fly
 func (c *Client) Reload(newConfig *config.Config) error {
+	c.reloadACLConfig(newConfig)
+
 	shouldReloadTLS, err := tlsutil.ShouldReloadRPCConnections(c.config.TLSConfig, newConfig.TLSConfig)
 	if err != nil {
 		c.logger.Error("error parsing TLS configuration", "error", err)
@@ -576,9 +638,23 @@ func (c *Client) Reload(newConfig *config.Config) error {
 	return nil
 }
 
-// Leave is used to prepare the client to leave the cluster
+// reloadACLConfig updates how long ACL tokens and policies are cached.
+func (c *Client) reloadACLConfig(newConfig *config.Config) {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+
+	c.config.ACLTokenTTL = newConfig.ACLTokenTTL
+	c.config.ACLPolicyTTL = newConfig.ACLPolicyTTL
+	c.configCopy = c.config.Copy()
+}
+
+// Leave is used to prepare the client to leave the cluster. If the client is
+// configured to drain on shutdown the node is drained and Leave blocks until
+// the drain completes.
 func (c *Client) Leave() error {
-	// TODO
+	if c.config.DrainOnShutdown != nil {
+		return c.drainSelf()
+	}
 	return nil
 }
 
@@ -636,6 +712,9 @@ func (c *Client) Shutdown() error {
 	if c.vaultClient != nil {
 		c.vaultClient.Stop()
 	}
+	for _, v := range c.vaultClusters {
+		v.Stop()
+	}
 
 	// Stop Garbage collector
 	c.garbageCollector.Stop()
@@ -703,6 +782,34 @@ func (c *Client) CollectAllAllocs() {
 	c.garbageCollector.CollectAll()
 }
 
+// RestartAllocation restarts the task with the given name, or all the tasks
+// of the allocation if the name is empty.
+func (c *Client) RestartAllocation(allocID, taskName string) error {
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return err
+	}
+
+	event := structs.NewTaskEvent(structs.TaskRestartSignal).
+		SetRestartReason("User requested restart")
+
+	if taskName != "" {
+		return ar.RestartTask(taskName, event)
+	}
+	return ar.RestartAll(event)
+}
+
+// SignalAllocation sends a signal to the task with the given name, or to all
+// the tasks of the allocation if the name is empty.
+func (c *Client) SignalAllocation(allocID, task, signal string) error {
+	ar, err := c.getAllocRunner(allocID)
+	if err != nil {
+		return err
+	}
+
+	return ar.Signal(task, signal)
+}
+
 // Node returns the locally registered node
 func (c *Client) Node() *structs.Node {
 	c.configLock.RLock()
@@ -952,12 +1059,17 @@ func (c *Client) restoreState() error {
 			StateDB:             c.stateDB,
 			StateUpdater:        c,
 			DeviceStatsReporter: c,
+			AllocMetricsLimiter: c.allocMetrics,
 			Consul:              c.consulService,
 			Vault:               c.vaultClient,
+			VaultClusters:       c.vaultClusters,
 			PrevAllocWatcher:    prevAllocWatcher,
 			PrevAllocMigrator:   prevAllocMigrator,
 			DeviceManager:       c.devicemanager,
 			DriverManager:       c.drivermanager,
+			ChrootCache:         c.chrootCache,
+			DiskQuota:           c.diskQuota,
+			ArtifactCache:       c.artifactCache,
 		}
 		c.configLock.RUnlock()
 
@@ -1582,7 +1694,7 @@ func (c *Client) updateNodeStatus() error {
 
 	// Convert []*NodeServerInfo to []*servers.Server
 	nomadServers := make([]*servers.Server, 0, len(resp.Servers))
-	for _, s := range resp.Servers {
+	for _, s := range c.segmentServers(resp.Servers) {
 		addr, err := resolveServer(s.RPCAdvertiseAddr)
 		if err != nil {
 			c.logger.Warn("ignoring invalid server", "error", err, "server", s.RPCAdvertiseAddr)
@@ -1608,6 +1720,34 @@ func (c *Client) updateNodeStatus() error {
 	return nil
 }
 
+// segmentServers returns the servers to connect to. If the client is on a
+// network segment, these are the servers advertising an address for the
+// segment, with that address as the RPC address. If no server advertises the
+// segment, for example while servers are being upgraded, all servers are
+// returned.
+func (c *Client) segmentServers(all []*structs.NodeServerInfo) []*structs.NodeServerInfo {
+	segment := c.config.NetworkSegment
+	if segment == "" {
+		return all
+	}
+
+	var out []*structs.NodeServerInfo
+	for _, s := range all {
+		addr, ok := s.RPCSegmentAddrs[segment]
+		if !ok {
+			continue
+		}
+		info := *s
+		info.RPCAdvertiseAddr = addr
+		out = append(out, &info)
+	}
+	if len(out) == 0 {
+		c.logger.Warn("no server advertises an address for the network segment, using default addresses", "segment", segment)
+		return all
+	}
+	return out
+}
+
 // AllocStateUpdated asynchronously updates the server with the current state
 // of an allocations and its tasks.
 func (c *Client) AllocStateUpdated(alloc *structs.Allocation) {
@@ -2116,12 +2256,17 @@ func (c *Client) addAlloc(alloc *structs.Allocation, migrateToken string) error
 		StateDB:             c.stateDB,
 		Consul:              c.consulService,
 		Vault:               c.vaultClient,
+		VaultClusters:       c.vaultClusters,
 		StateUpdater:        c,
 		DeviceStatsReporter: c,
+		AllocMetricsLimiter: c.allocMetrics,
 		PrevAllocWatcher:    prevAllocWatcher,
 		PrevAllocMigrator:   prevAllocMigrator,
 		DeviceManager:       c.devicemanager,
 		DriverManager:       c.drivermanager,
+		ChrootCache:         c.chrootCache,
+		DiskQuota:           c.diskQuota,
+		ArtifactCache:       c.artifactCache,
 	}
 	c.configLock.RUnlock()
 
@@ -2154,6 +2299,16 @@ func (c *Client) setupVaultClient() error {
 	// Start renewing tokens and secrets
 	c.vaultClient.Start()
 
+	c.vaultClusters = make(map[string]vaultclient.VaultClient, len(c.config.VaultClusters))
+	for name, conf := range c.config.VaultClusters {
+		v, err := vaultclient.NewVaultClient(conf, c.logger.With("vault_cluster", name), c.deriveToken)
+		if err != nil {
+			return fmt.Errorf("failed to create client of Vault cluster %q: %v", name, err)
+		}
+		v.Start()
+		c.vaultClusters[name] = v
+	}
+
 	return nil
 }
 
@@ -2214,15 +2369,35 @@ func (c *Client) deriveToken(alloc *structs.Allocation, taskNames []string, vcli
 		vlogger.Error("error deriving vault tokens", "error", resp.Error)
 		return nil, structs.NewWrappedServerError(resp.Error)
 	}
-	if resp.Tasks == nil {
+	if resp.Tasks == nil && resp.Identities == nil {
 		vlogger.Error("error derivng vault token", "error", "invalid response")
 		return nil, fmt.Errorf("failed to derive vault tokens: invalid response")
 	}
 
+	// Tasks of Vault clusters using JWT auth get workload identities to log
+	// in with instead of wrapped tokens
+	var identityTasks, wrappedTasks []string
+	for _, taskName := range verifiedTasks {
+		if _, ok := resp.Identities[taskName]; ok {
+			identityTasks = append(identityTasks, taskName)
+		} else {
+			wrappedTasks = append(wrappedTasks, taskName)
+		}
+	}
+
 	unwrappedTokens := make(map[string]string)
+	if len(identityTasks) != 0 {
+		tokens, err := c.loginVaultIdentities(group, identityTasks, resp.Identities, vclient)
+		if err != nil {
+			return nil, err
+		}
+		for taskName, token := range tokens {
+			unwrappedTokens[taskName] = token
+		}
+	}
 
 	// Retrieve the wrapped tokens from the response and unwrap it
-	for _, taskName := range verifiedTasks {
+	for _, taskName := range wrappedTasks {
 		// Get the wrapped token
 		wrappedToken, ok := resp.Tasks[taskName]
 		if !ok {
@@ -2263,6 +2438,73 @@ func (c *Client) deriveToken(alloc *structs.Allocation, taskNames []string, vcli
 	return unwrappedTokens, nil
 }
 
+// loginVaultIdentities exchanges the workload identities of the tasks for
+// Vault tokens by logging in to the Vault JWT auth method. The Vault role
+// decides the policies of the tokens, which must include the policies the
+// tasks request.
+func (c *Client) loginVaultIdentities(group *structs.TaskGroup, taskNames []string,
+	identities map[string]string, vclient *vaultapi.Client) (map[string]string, error) {
+
+	vlogger := c.logger.Named("vault")
+
+	tokens := make(map[string]string, len(taskNames))
+	for _, taskName := range taskNames {
+		identity, ok := identities[taskName]
+		if !ok {
+			vlogger.Error("workload identity missing for task", "task_name", taskName)
+			return nil, fmt.Errorf("workload identity missing for task %q", taskName)
+		}
+
+		// Log in with the JWT auth method of the task's Vault cluster
+		task := group.LookupTask(taskName)
+		vconf := c.config.VaultConfig
+		if task != nil && task.Vault != nil {
+			vconf = c.config.VaultConfigFor(task.Vault.Cluster)
+		}
+		if vconf == nil {
+			return nil, fmt.Errorf("Vault cluster of task %q not configured on client", taskName)
+		}
+		path := fmt.Sprintf("auth/%s/login", vconf.GetJWTAuthPath())
+		role := vconf.GetJWTAuthRole()
+
+		secret, err := vclient.Logical().Write(path, map[string]interface{}{
+			"role": role,
+			"jwt":  identity,
+		})
+		if err != nil {
+			if structs.VaultUnrecoverableError.MatchString(err.Error()) {
+				return nil, err
+			}
+
+			// The error is recoverable
+			return nil, structs.NewRecoverableError(
+				fmt.Errorf("failed to log in to Vault for task %q: %v", taskName, err), true)
+		}
+		if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+			return nil, structs.NewRecoverableError(
+				fmt.Errorf("Vault login for task %q returned no token", taskName), true)
+		}
+
+		// Tasks must get the policies they request
+		if task != nil && task.Vault != nil {
+			if ok, missing := helper.SliceStringIsSubset(secret.Auth.Policies, task.Vault.Policies); !ok {
+				vclient.SetToken(secret.Auth.ClientToken)
+				if err := vclient.Auth().Token().RevokeSelf(""); err != nil {
+					vlogger.Warn("failed to revoke Vault token", "task_name", taskName, "error", err)
+				}
+				vclient.SetToken("")
+
+				return nil, fmt.Errorf("Vault role %q doesn't grant policies requested by task %q: %s",
+					role, taskName, strings.Join(missing, ", "))
+			}
+		}
+
+		tokens[taskName] = secret.Auth.ClientToken
+	}
+
+	return tokens, nil
+}
+
 // triggerDiscovery causes a Consul discovery to begin (if one hasn't already)
 func (c *Client) triggerDiscovery() {
 	select {
@@ -2514,6 +2756,18 @@ func (c *Client) setGaugeForAllocationStats(nodeID string) {
 	res := node.ReservedResources
 	allocated := c.getAllocatedResources(node)
 
+	// Emit the total resources available to allocations and the resources
+	// allocations are actually using, so headroom can be computed against both
+	// what the scheduler has allocated and what is utilized
+	if !c.config.DisableTaggedMetrics {
+		utilizedCpu, utilizedMem := c.getUtilizedResources()
+		metrics.SetGaugeWithLabels([]string{"client", "total", "memory"}, float32(total.Memory.MemoryMB-res.Memory.MemoryMB), c.baseLabels)
+		metrics.SetGaugeWithLabels([]string{"client", "total", "disk"}, float32(total.Disk.DiskMB-res.Disk.DiskMB), c.baseLabels)
+		metrics.SetGaugeWithLabels([]string{"client", "total", "cpu"}, float32(total.Cpu.CpuShares-res.Cpu.CpuShares), c.baseLabels)
+		metrics.SetGaugeWithLabels([]string{"client", "utilized", "memory"}, float32(utilizedMem), c.baseLabels)
+		metrics.SetGaugeWithLabels([]string{"client", "utilized", "cpu"}, float32(utilizedCpu), c.baseLabels)
+	}
+
 	// Emit allocated
 	if !c.config.DisableTaggedMetrics {
 		metrics.SetGaugeWithLabels([]string{"client", "allocated", "memory"}, float32(allocated.Flattened.Memory.MemoryMB), c.baseLabels)
@@ -2710,6 +2964,29 @@ func (c *Client) getAllocatedResources(selfNode *structs.Node) *structs.Comparab
 	return &allocated
 }
 
+// getUtilizedResources returns the CPU in MHz and the memory in MB used by
+// the non-terminal allocations of the client, based on their latest stats.
+func (c *Client) getUtilizedResources() (cpu float64, memoryMB uint64) {
+	var memory uint64
+	for _, ar := range c.getAllocRunners() {
+		if ar.Alloc().TerminalStatus() {
+			continue
+		}
+
+		usage, err := ar.StatsReporter().LatestAllocStats("")
+		if err != nil || usage == nil || usage.ResourceUsage == nil {
+			continue
+		}
+		if cs := usage.ResourceUsage.CpuStats; cs != nil {
+			cpu += cs.TotalTicks
+		}
+		if ms := usage.ResourceUsage.MemoryStats; ms != nil {
+			memory += ms.RSS
+		}
+	}
+	return cpu, memory / 1024 / 1024
+}
+
 // allAllocs returns all the allocations managed by the client
 func (c *Client) allAllocs() map[string]*structs.Allocation {
 	ars := c.getAllocRunners()
@@ -1,8 +1,11 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sort"
@@ -24,6 +27,7 @@ import (
 	"github.com/hashicorp/nomad/plugins/device"
 	psstructs "github.com/hashicorp/nomad/plugins/shared/structs"
 	"github.com/hashicorp/nomad/testutil"
+	vaultapi "github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
 
 	hclog "github.com/hashicorp/go-hclog"
@@ -981,6 +985,62 @@ func TestClient_ValidateMigrateToken_ACLDisabled(t *testing.T) {
 	assert.Equal(c.ValidateMigrateToken("", ""), true)
 }
 
+func TestClient_SegmentServers(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c1, cleanup := TestClient(t, nil)
+	defer cleanup()
+
+	all := []*structs.NodeServerInfo{
+		{
+			RPCAdvertiseAddr: "10.0.0.1:4647",
+			Datacenter:       "dc1",
+			RPCSegmentAddrs:  map[string]string{"dmz": "192.168.0.1:5647"},
+		},
+		{
+			RPCAdvertiseAddr: "10.0.0.2:4647",
+			Datacenter:       "dc1",
+		},
+	}
+
+	// Clients outside of segments use the default addresses
+	require.Equal(all, c1.segmentServers(all))
+
+	// Clients on a segment only use the servers advertising it
+	c1.config.NetworkSegment = "dmz"
+	out := c1.segmentServers(all)
+	require.Len(out, 1)
+	require.Equal("192.168.0.1:5647", out[0].RPCAdvertiseAddr)
+	require.Equal("dc1", out[0].Datacenter)
+	require.Equal("10.0.0.1:4647", all[0].RPCAdvertiseAddr)
+
+	// Clients fall back to the default addresses if no server advertises
+	// their segment
+	c1.config.NetworkSegment = "edge"
+	require.Equal(all, c1.segmentServers(all))
+}
+
+func TestClient_Reload_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c1, cleanup := TestClient(t, func(c *config.Config) {
+		c.ACLEnabled = true
+	})
+	defer cleanup()
+
+	newConfig := c1.GetConfig().Copy()
+	newConfig.ACLTokenTTL = 5 * time.Minute
+	newConfig.ACLPolicyTTL = 10 * time.Minute
+	require.NoError(c1.Reload(newConfig))
+
+	require.Equal(5*time.Minute, c1.aclTokenTTL())
+	require.Equal(10*time.Minute, c1.aclPolicyTTL())
+	require.Equal(5*time.Minute, c1.GetConfig().ACLTokenTTL)
+	require.Equal(10*time.Minute, c1.GetConfig().ACLPolicyTTL)
+}
+
 func TestClient_ReloadTLS_UpgradePlaintextToTLS(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -1420,3 +1480,70 @@ func TestClient_updateNodeFromDriverUpdatesAll(t *testing.T) {
 		assert.EqualValues(t, n, un)
 	}
 }
+
+func TestClient_LoginVaultIdentities(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Fake the Vault JWT auth method
+	var revoked []string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/nomad-jwt/login":
+			var body map[string]string
+			require.NoError(json.NewDecoder(r.Body).Decode(&body))
+			require.Equal("workloads", body["role"])
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"auth": map[string]interface{}{
+					"client_token": "token-" + body["jwt"],
+					"policies":     []string{"default", "web"},
+				},
+			})
+		case "/v1/auth/token/revoke-self":
+			revoked = append(revoked, r.Header.Get("X-Vault-Token"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	vconf := vaultapi.DefaultConfig()
+	vconf.Address = vault.URL
+	vclient, err := vaultapi.NewClient(vconf)
+	require.NoError(err)
+	vclient.SetToken("")
+
+	c := &Client{
+		config: &config.Config{
+			VaultConfig: &nconfig.VaultConfig{
+				JWTAuthPath: "nomad-jwt",
+				JWTAuthRole: "workloads",
+			},
+		},
+		logger: testlog.HCLogger(t),
+	}
+
+	job := mock.Job()
+	group := job.TaskGroups[0]
+	task := group.Tasks[0]
+	task.Vault = &structs.Vault{Policies: []string{"web"}}
+
+	// The identity is exchanged for a token
+	tokens, err := c.loginVaultIdentities(group, []string{task.Name},
+		map[string]string{task.Name: "identity"}, vclient)
+	require.NoError(err)
+	require.Equal(map[string]string{task.Name: "token-identity"}, tokens)
+
+	// Tokens missing requested policies are revoked
+	task.Vault.Policies = []string{"web", "db"}
+	_, err = c.loginVaultIdentities(group, []string{task.Name},
+		map[string]string{task.Name: "identity"}, vclient)
+	require.Error(err)
+	require.Contains(err.Error(), "db")
+	require.Equal([]string{"token-identity"}, revoked)
+
+	// Missing identities are an error
+	_, err = c.loginVaultIdentities(group, []string{task.Name}, map[string]string{}, vclient)
+	require.Error(err)
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+const (
+	// AllocHookEventPoststart runs the hook once the allocation is running.
+	AllocHookEventPoststart = "poststart"
+
+	// AllocHookEventPrestop runs the hook before the allocation's tasks are
+	// stopped.
+	AllocHookEventPrestop = "prestop"
+
+	// AllocHookFailureIgnore logs hook failures and emits a task event but
+	// otherwise leaves the allocation alone.
+	AllocHookFailureIgnore = "ignore"
+
+	// AllocHookFailureFail fails the allocation if a poststart hook fails
+	// and skips the remaining prestop hooks if a prestop hook fails.
+	AllocHookFailureFail = "fail"
+
+	// DefaultAllocHookTimeout is the timeout applied to alloc hooks that do
+	// not specify one.
+	DefaultAllocHookTimeout = 30 * time.Second
+)
+
+// AllocHookConfig is an operator defined command run by the client on
+// allocation lifecycle events. Unlike task lifecycle hooks these are
+// configured per client and apply to every allocation on it.
+type AllocHookConfig struct {
+	// Name identifies the hook in logs and task events.
+	Name string
+
+	// Event is the allocation lifecycle event the hook runs on.
+	Event string
+
+	// Command and Args are executed on the host as the client's user.
+	Command string
+	Args    []string
+
+	// Timeout bounds how long the command may run before it is killed.
+	Timeout time.Duration
+
+	// OnFailure is the failure policy applied when the command exits
+	// non-zero or times out.
+	OnFailure string
+}
+
+// Canonicalize sets defaults for unset fields.
+func (h *AllocHookConfig) Canonicalize() {
+	if h.Timeout == 0 {
+		h.Timeout = DefaultAllocHookTimeout
+	}
+	if h.OnFailure == "" {
+		h.OnFailure = AllocHookFailureIgnore
+	}
+}
+
+// Validate returns an error if the hook is not runnable.
+func (h *AllocHookConfig) Validate() error {
+	var mErr multierror.Error
+	if h.Name == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing name"))
+	}
+	switch h.Event {
+	case AllocHookEventPoststart, AllocHookEventPrestop:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid event %q; must be %q or %q",
+			h.Event, AllocHookEventPoststart, AllocHookEventPrestop))
+	}
+	if h.Command == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing command"))
+	}
+	if h.Timeout < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("timeout must be positive"))
+	}
+	switch h.OnFailure {
+	case "", AllocHookFailureIgnore, AllocHookFailureFail:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid on_failure %q; must be %q or %q",
+			h.OnFailure, AllocHookFailureIgnore, AllocHookFailureFail))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// Copy returns a deep copy of the hook.
+func (h *AllocHookConfig) Copy() *AllocHookConfig {
+	if h == nil {
+		return nil
+	}
+	nh := *h
+	nh.Args = helper.CopySliceString(h.Args)
+	return &nh
+}
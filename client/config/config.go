@@ -93,6 +93,11 @@ type Config struct {
 	// be determined dynamically.
 	NetworkSpeed int
 
+	// NetworkSegment is the network segment of the client. If set, the client
+	// only connects to servers through the RPC addresses they advertise for
+	// the segment.
+	NetworkSegment string
+
 	// CpuCompute is the default total CPU compute if they can not be determined
 	// dynamically. It should be given as Cores * MHz (2 Cores * 2 Ghz = 4000)
 	CpuCompute int
@@ -101,6 +106,10 @@ type Config struct {
 	// determined dynamically.
 	MemoryMB int
 
+	// TaskCpuset is the set of cores tasks are allowed to run on, excluding
+	// the cores reserved for the host. An empty value allows all cores.
+	TaskCpuset string
+
 	// MaxKillTimeout allows capping the user-specifiable KillTimeout. If the
 	// task's KillTimeout is greater than the MaxKillTimeout, MaxKillTimeout is
 	// used.
@@ -128,6 +137,14 @@ type Config struct {
 	// task's chroot.
 	ChrootEnv map[string]string
 
+	// ChrootCache enables building the chroot of tasks once and hardlinking
+	// it into each task directory from the chroot cache.
+	ChrootCache bool
+
+	// EphemeralDiskQuota enables enforcing the ephemeral disk size of
+	// allocations with project quotas where the filesystem supports them.
+	EphemeralDiskQuota bool
+
 	// Options provides arbitrary key-value configuration for nomad internals,
 	// like fingerprinters and drivers. The format is:
 	//
@@ -143,6 +160,10 @@ type Config struct {
 	// VaultConfig is this Agent's Vault configuration
 	VaultConfig *config.VaultConfig
 
+	// VaultClusters are the configurations of the additional Vault clusters
+	// jobs can select, indexed by name
+	VaultClusters map[string]*config.VaultConfig
+
 	// StatsCollectionInterval is the interval at which the Nomad client
 	// collects resource usage stats
 	StatsCollectionInterval time.Duration
@@ -155,6 +176,14 @@ type Config struct {
 	// allocation metrics to remote Telemetry sinks
 	PublishAllocationMetrics bool
 
+	// AllocationMetricsLabels restricts the labels of allocation metrics to
+	// the listed ones. All labels are published if empty.
+	AllocationMetricsLabels []string
+
+	// AllocationMetricsLimit is the maximum number of tasks whose resource
+	// usage metrics are published at once. There is no limit if zero.
+	AllocationMetricsLimit int
+
 	// TLSConfig holds various TLS related configurations
 	TLSConfig *config.TLSConfig
 
@@ -218,6 +247,14 @@ type Config struct {
 
 	// StateDBFactory is used to override stateDB implementations,
 	StateDBFactory state.NewStateDBFunc
+
+	// AllocHooks are operator defined commands run by the client when an
+	// allocation starts or stops.
+	AllocHooks []*AllocHookConfig
+
+	// DrainOnShutdown is the drain spec the node drains itself with when the
+	// agent leaves gracefully. Draining is disabled if nil.
+	DrainOnShutdown *structs.DrainSpec
 }
 
 func (c *Config) Copy() *Config {
@@ -226,8 +263,25 @@ func (c *Config) Copy() *Config {
 	nc.Node = nc.Node.Copy()
 	nc.Servers = helper.CopySliceString(nc.Servers)
 	nc.Options = helper.CopyMapStringString(nc.Options)
+	nc.AllocationMetricsLabels = helper.CopySliceString(nc.AllocationMetricsLabels)
 	nc.ConsulConfig = c.ConsulConfig.Copy()
 	nc.VaultConfig = c.VaultConfig.Copy()
+	if c.VaultClusters != nil {
+		nc.VaultClusters = make(map[string]*config.VaultConfig, len(c.VaultClusters))
+		for name, v := range c.VaultClusters {
+			nc.VaultClusters[name] = v.Copy()
+		}
+	}
+	if c.DrainOnShutdown != nil {
+		spec := *c.DrainOnShutdown
+		nc.DrainOnShutdown = &spec
+	}
+	if c.AllocHooks != nil {
+		nc.AllocHooks = make([]*AllocHookConfig, len(c.AllocHooks))
+		for i, h := range c.AllocHooks {
+			nc.AllocHooks[i] = h.Copy()
+		}
+	}
 	return nc
 }
 
@@ -254,6 +308,15 @@ func DefaultConfig() *Config {
 	}
 }
 
+// VaultConfigFor returns the configuration of the named Vault cluster, or nil
+// if the cluster isn't configured. An empty name selects the default cluster.
+func (c *Config) VaultConfigFor(cluster string) *config.VaultConfig {
+	if cluster == "" || cluster == config.DefaultVaultClusterName {
+		return c.VaultConfig
+	}
+	return c.VaultClusters[cluster]
+}
+
 // Read returns the specified configuration value or "".
 func (c *Config) Read(id string) string {
 	return c.Options[id]
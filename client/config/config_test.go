@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
 
 func TestConfigRead(t *testing.T) {
 	config := Config{}
@@ -34,3 +38,29 @@ func TestConfigReadDefault(t *testing.T) {
 		t.Errorf("Expected %s, found %s", expected, actual)
 	}
 }
+
+func TestAllocHookConfig_Validate(t *testing.T) {
+	require := require.New(t)
+
+	hook := &AllocHookConfig{
+		Name:    "register",
+		Event:   AllocHookEventPoststart,
+		Command: "/bin/register",
+	}
+	require.NoError(hook.Validate())
+
+	hook.Canonicalize()
+	require.Equal(DefaultAllocHookTimeout, hook.Timeout)
+	require.Equal(AllocHookFailureIgnore, hook.OnFailure)
+
+	hook = &AllocHookConfig{
+		Event:     "poststop",
+		OnFailure: "retry",
+	}
+	err := hook.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "missing name")
+	require.Contains(err.Error(), `invalid event "poststop"`)
+	require.Contains(err.Error(), "missing command")
+	require.Contains(err.Error(), `invalid on_failure "retry"`)
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// drainShutdownGrace is added to the drain deadline when waiting for a drain
+// on shutdown to complete so that allocations stopped at the deadline have
+// time to exit.
+const drainShutdownGrace = 30 * time.Second
+
+// LeaveTimeout returns how long Leave may block. It is zero unless the client
+// is configured to drain itself on shutdown.
+func (c *Client) LeaveTimeout() time.Duration {
+	if c.config.DrainOnShutdown == nil {
+		return 0
+	}
+	return c.config.DrainOnShutdown.Deadline + drainShutdownGrace
+}
+
+// drainSelf marks the node for draining using the drain on shutdown spec and
+// blocks until the drain completes or LeaveTimeout elapses.
+func (c *Client) drainSelf() error {
+	spec := c.config.DrainOnShutdown
+	c.logger.Info("draining node before shutdown", "deadline", spec.Deadline, "ignore_system_jobs", spec.IgnoreSystemJobs)
+
+	deadline := time.Now().Add(c.LeaveTimeout())
+	req := &structs.NodeUpdateDrainRequest{
+		NodeID: c.NodeID(),
+		DrainStrategy: &structs.DrainStrategy{
+			DrainSpec: *spec,
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    c.Region(),
+			AuthToken: c.secretNodeID(),
+		},
+	}
+	var resp structs.NodeDrainUpdateResponse
+	if err := c.RPC("Node.UpdateDrain", req, &resp); err != nil {
+		return fmt.Errorf("failed to drain node: %v", err)
+	}
+
+	// Wait for the drainer to remove the drain strategy which marks the
+	// drain as complete
+	index := resp.NodeModifyIndex
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("timed out waiting for node drain to complete")
+		}
+
+		req := &structs.NodeSpecificRequest{
+			NodeID:   c.NodeID(),
+			SecretID: c.secretNodeID(),
+			QueryOptions: structs.QueryOptions{
+				Region:        c.Region(),
+				AuthToken:     c.secretNodeID(),
+				MinQueryIndex: index,
+				MaxQueryTime:  remaining,
+			},
+		}
+		var resp structs.SingleNodeResponse
+		if err := c.RPC("Node.GetNode", req, &resp); err != nil {
+			return fmt.Errorf("failed to monitor node drain: %v", err)
+		}
+		if resp.Node == nil {
+			return fmt.Errorf("node not found")
+		}
+		if resp.Node.DrainStrategy == nil {
+			c.logger.Info("node drain complete")
+			return nil
+		}
+		index = resp.Index
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Leave_DrainOnShutdown(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Use an ACL server as the client drains itself without an ACL token
+	s1, addr, _ := testACLServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	c1, cleanup := TestClient(t, func(c *config.Config) {
+		c.Servers = []string{addr}
+		c.DrainOnShutdown = &structs.DrainSpec{
+			Deadline: 10 * time.Second,
+		}
+	})
+	defer cleanup()
+	require.Equal(10*time.Second+drainShutdownGrace, c1.LeaveTimeout())
+
+	// Wait for the node to register
+	state := s1.State()
+	testutil.WaitForResult(func() (bool, error) {
+		node, err := state.NodeByID(nil, c1.NodeID())
+		if err != nil {
+			return false, err
+		}
+		return node != nil && node.Status == structs.NodeStatusReady, nil
+	}, func(err error) {
+		t.Fatalf("node did not register: %v", err)
+	})
+
+	// The node has no allocations so the drain completes immediately
+	require.NoError(c1.Leave())
+
+	node, err := state.NodeByID(nil, c1.NodeID())
+	require.NoError(err)
+	require.Nil(node.DrainStrategy)
+	require.Equal(structs.NodeSchedulingIneligible, node.SchedulingEligibility)
+	require.NotEmpty(node.Events)
+	require.Equal("Node drain complete", node.Events[len(node.Events)-1].Message)
+}
+
+func TestClient_Leave_NoDrain(t *testing.T) {
+	t.Parallel()
+
+	c1, cleanup := TestClient(t, nil)
+	defer cleanup()
+
+	require.Zero(t, c1.LeaveTimeout())
+	require.NoError(t, c1.Leave())
+}
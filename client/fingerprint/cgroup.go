@@ -47,6 +47,7 @@ func NewCGroupFingerprint(logger log.Logger) Fingerprint {
 // have been set in a previous fingerprint run.
 func (f *CGroupFingerprint) clearCGroupAttributes(r *FingerprintResponse) {
 	r.RemoveAttribute("unique.cgroup.mountpoint")
+	r.RemoveAttribute("unique.cgroup.version")
 }
 
 // Periodic determines the interval at which the periodic fingerprinter will run.
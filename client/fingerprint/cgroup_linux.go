@@ -5,6 +5,7 @@ package fingerprint
 import (
 	"fmt"
 
+	"github.com/hashicorp/nomad/client/lib/cgutil"
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 )
 
@@ -15,6 +16,11 @@ const (
 // FindCgroupMountpointDir is used to find the cgroup mount point on a Linux
 // system.
 func FindCgroupMountpointDir() (string, error) {
+	// The unified hierarchy is mounted as a single cgroup2 filesystem
+	if cgutil.UseV2() {
+		return cgutil.CgroupRoot, nil
+	}
+
 	mount, err := cgroups.FindCgroupMountpointDir()
 	if err != nil {
 		switch e := err.(type) {
@@ -50,6 +56,7 @@ func (f *CGroupFingerprint) Fingerprint(req *FingerprintRequest, resp *Fingerpri
 	}
 
 	resp.AddAttribute("unique.cgroup.mountpoint", mount)
+	resp.AddAttribute("unique.cgroup.version", cgutil.Version())
 	resp.Detected = true
 
 	if f.lastState == cgroupUnavailable {
@@ -1,8 +1,11 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"math"
@@ -78,6 +81,7 @@ func NewFileSystemEndpoint(c *Client) *FileSystem {
 	f := &FileSystem{c}
 	f.c.streamingRpcs.Register("FileSystem.Logs", f.logs)
 	f.c.streamingRpcs.Register("FileSystem.Stream", f.stream)
+	f.c.streamingRpcs.Register("FileSystem.Archive", f.archive)
 	return f
 }
 
@@ -102,7 +106,7 @@ func (f *FileSystem) List(args *cstructs.FsListRequest, reply *cstructs.FsListRe
 	// Check read permissions
 	if aclObj, err := f.c.ResolveToken(args.QueryOptions.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.Namespace, acl.NamespaceCapabilityReadFS) {
+	} else if aclObj != nil && !f.c.allowAllocJobOp(aclObj, args.Namespace, args.AllocID, acl.NamespaceCapabilityReadFS) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -126,7 +130,7 @@ func (f *FileSystem) Stat(args *cstructs.FsStatRequest, reply *cstructs.FsStatRe
 	// Check read permissions
 	if aclObj, err := f.c.ResolveToken(args.QueryOptions.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.Namespace, acl.NamespaceCapabilityReadFS) {
+	} else if aclObj != nil && !f.c.allowAllocJobOp(aclObj, args.Namespace, args.AllocID, acl.NamespaceCapabilityReadFS) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -139,10 +143,107 @@ func (f *FileSystem) Stat(args *cstructs.FsStatRequest, reply *cstructs.FsStatRe
 		return err
 	}
 
+	if args.Checksum && !info.IsDir {
+		if info.Checksum, err = fileChecksum(fs, args.Path); err != nil {
+			return err
+		}
+	}
+
 	reply.Info = info
 	return nil
 }
 
+// fileChecksum returns the checksum of the contents of the file at the path
+// in the allocation's directory.
+func fileChecksum(fs allocdir.AllocDirFS, path string) (string, error) {
+	r, err := fs.ReadAt(path, 0)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// archive is used to stream a tar archive of a file or directory in an
+// allocation's directory.
+func (f *FileSystem) archive(conn io.ReadWriteCloser) {
+	defer metrics.MeasureSince([]string{"client", "file_system", "archive"}, time.Now())
+	defer conn.Close()
+
+	// Decode the arguments
+	var req cstructs.FsArchiveRequest
+	decoder := codec.NewDecoder(conn, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(conn, structs.MsgpackHandle)
+
+	if err := decoder.Decode(&req); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+
+	// Check read permissions
+	if aclObj, err := f.c.ResolveToken(req.QueryOptions.AuthToken); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(403), encoder)
+		return
+	} else if aclObj != nil && !f.c.allowAllocJobOp(aclObj, req.Namespace, req.AllocID, acl.NamespaceCapabilityReadFS) {
+		f.handleStreamResultError(structs.ErrPermissionDenied, helper.Int64ToPtr(403), encoder)
+		return
+	}
+
+	// Validate the arguments
+	if req.AllocID == "" {
+		f.handleStreamResultError(allocIDNotPresentErr, helper.Int64ToPtr(400), encoder)
+		return
+	}
+	if req.Path == "" {
+		f.handleStreamResultError(pathNotPresentErr, helper.Int64ToPtr(400), encoder)
+		return
+	}
+
+	fs, err := f.c.GetAllocFS(req.AllocID)
+	if err != nil {
+		code := helper.Int64ToPtr(500)
+		if structs.IsErrUnknownAllocation(err) {
+			code = helper.Int64ToPtr(404)
+		}
+
+		f.handleStreamResultError(err, code, encoder)
+		return
+	}
+
+	if _, err := fs.Stat(req.Path); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(400), encoder)
+		return
+	}
+
+	// Batch the archive into payloads of up to a frame
+	w := bufio.NewWriterSize(&payloadWriter{encoder: encoder}, streamFrameSize)
+	if err := fs.Archive(req.Path, w); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+	if err := w.Flush(); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+	}
+}
+
+// payloadWriter writes data to a stream as the payloads of StreamErrWrapper
+// messages.
+type payloadWriter struct {
+	encoder *codec.Encoder
+}
+
+func (w *payloadWriter) Write(p []byte) (int, error) {
+	if err := w.encoder.Encode(&cstructs.StreamErrWrapper{Payload: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // stream is is used to stream the contents of file in an allocation's
 // directory.
 func (f *FileSystem) stream(conn io.ReadWriteCloser) {
@@ -163,7 +264,7 @@ func (f *FileSystem) stream(conn io.ReadWriteCloser) {
 	if aclObj, err := f.c.ResolveToken(req.QueryOptions.AuthToken); err != nil {
 		f.handleStreamResultError(err, nil, encoder)
 		return
-	} else if aclObj != nil && !aclObj.AllowNsOp(req.Namespace, acl.NamespaceCapabilityReadFS) {
+	} else if aclObj != nil && !f.c.allowAllocJobOp(aclObj, req.Namespace, req.AllocID, acl.NamespaceCapabilityReadFS) {
 		f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
 		return
 	}
@@ -337,8 +438,8 @@ func (f *FileSystem) logs(conn io.ReadWriteCloser) {
 		f.handleStreamResultError(err, nil, encoder)
 		return
 	} else if aclObj != nil {
-		readfs := aclObj.AllowNsOp(req.QueryOptions.Namespace, acl.NamespaceCapabilityReadFS)
-		logs := aclObj.AllowNsOp(req.QueryOptions.Namespace, acl.NamespaceCapabilityReadLogs)
+		readfs := f.c.allowAllocJobOp(aclObj, req.QueryOptions.Namespace, req.AllocID, acl.NamespaceCapabilityReadFS)
+		logs := f.c.allowAllocJobOp(aclObj, req.QueryOptions.Namespace, req.AllocID, acl.NamespaceCapabilityReadLogs)
 		if !readfs && !logs {
 			f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
 			return
@@ -21,3 +21,15 @@ type AllocStateHandler interface {
 type DeviceStatsReporter interface {
 	LatestDeviceResourceStats([]*structs.AllocatedDeviceResource) []*device.DeviceGroupStats
 }
+
+// AllocMetricsLimiter bounds the number of tasks whose resource usage metrics
+// are published, as each task adds a set of time series to the metrics sinks.
+type AllocMetricsLimiter interface {
+	// AcquireAllocMetrics returns whether the metrics of the task may be
+	// published. It may be called repeatedly for the same task.
+	AcquireAllocMetrics(allocID, task string) bool
+
+	// ReleaseAllocMetrics frees the slot of a task that stopped publishing
+	// metrics.
+	ReleaseAllocMetrics(allocID, task string)
+}
@@ -0,0 +1,18 @@
+// +build !linux
+
+package cgutil
+
+// UseV2 always returns false as cgroups are only supported on Linux.
+func UseV2() bool {
+	return false
+}
+
+// Version returns an empty string as cgroups are only supported on Linux.
+func Version() string {
+	return ""
+}
+
+// TaskCpuset returns an empty string as cores can only be reserved on Linux.
+func TaskCpuset(reserved string) (string, error) {
+	return "", nil
+}
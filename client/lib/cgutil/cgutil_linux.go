@@ -0,0 +1,74 @@
+// +build linux
+
+package cgutil
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// CgroupRoot is the mount point of the cgroup filesystem.
+	CgroupRoot = "/sys/fs/cgroup"
+
+	// cgroup2Magic is the filesystem magic number of the unified cgroup
+	// hierarchy as reported by statfs.
+	cgroup2Magic = 0x63677270
+
+	// onlineCpusPath lists the cores the kernel currently has online.
+	onlineCpusPath = "/sys/devices/system/cpu/online"
+)
+
+var (
+	useV2     bool
+	useV2Once sync.Once
+)
+
+// UseV2 returns whether the host mounts the unified (v2) cgroup hierarchy at
+// CgroupRoot. The result is computed once and cached.
+func UseV2() bool {
+	useV2Once.Do(func() {
+		var st unix.Statfs_t
+		if err := unix.Statfs(CgroupRoot, &st); err != nil {
+			return
+		}
+		useV2 = st.Type == cgroup2Magic
+	})
+	return useV2
+}
+
+// Version returns "v2" if the host uses the unified cgroup hierarchy and "v1"
+// otherwise.
+func Version() string {
+	if UseV2() {
+		return "v2"
+	}
+	return "v1"
+}
+
+// OnlineCpuset returns the cpuset of the cores currently online.
+func OnlineCpuset() (string, error) {
+	b, err := ioutil.ReadFile(onlineCpusPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// TaskCpuset returns the cpuset tasks may run on: every online core except
+// for those reserved for the host. An empty string is returned if no cores
+// are reserved, indicating tasks are not restricted.
+func TaskCpuset(reserved string) (string, error) {
+	if strings.TrimSpace(reserved) == "" {
+		return "", nil
+	}
+
+	online, err := OnlineCpuset()
+	if err != nil {
+		return "", err
+	}
+	return SubtractCpuset(online, reserved)
+}
@@ -0,0 +1,113 @@
+package cgutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseCpuset parses a cpuset specification such as "0-3,7" as used by the
+// cpuset.cpus cgroup interface file and returns the sorted, deduplicated list
+// of cores.
+func ParseCpuset(spec string) ([]uint16, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	set := make(map[uint16]struct{})
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+
+		start, err := strconv.ParseUint(bounds[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpuset %q: %v", spec, err)
+		}
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.ParseUint(bounds[1], 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cpuset %q: %v", spec, err)
+			}
+		}
+		if end < start {
+			return nil, fmt.Errorf("invalid cpuset %q: range %q is reversed", spec, part)
+		}
+
+		for core := start; core <= end; core++ {
+			set[uint16(core)] = struct{}{}
+		}
+	}
+
+	cores := make([]uint16, 0, len(set))
+	for core := range set {
+		cores = append(cores, core)
+	}
+	sort.Slice(cores, func(i, j int) bool { return cores[i] < cores[j] })
+	return cores, nil
+}
+
+// FormatCpuset formats a list of cores as a cpuset specification, collapsing
+// consecutive cores into ranges.
+func FormatCpuset(cores []uint16) string {
+	if len(cores) == 0 {
+		return ""
+	}
+
+	sorted := make([]uint16, len(cores))
+	copy(sorted, cores)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var parts []string
+	start, prev := sorted[0], sorted[0]
+	flush := func() {
+		if start == prev {
+			parts = append(parts, strconv.Itoa(int(start)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, prev))
+		}
+	}
+	for _, core := range sorted[1:] {
+		if core == prev {
+			continue
+		}
+		if core != prev+1 {
+			flush()
+			start = core
+		}
+		prev = core
+	}
+	flush()
+	return strings.Join(parts, ",")
+}
+
+// SubtractCpuset returns the cpuset of the cores in total that are not in
+// reserved.
+func SubtractCpuset(total, reserved string) (string, error) {
+	totalCores, err := ParseCpuset(total)
+	if err != nil {
+		return "", err
+	}
+	reservedCores, err := ParseCpuset(reserved)
+	if err != nil {
+		return "", err
+	}
+
+	exclude := make(map[uint16]struct{}, len(reservedCores))
+	for _, core := range reservedCores {
+		exclude[core] = struct{}{}
+	}
+
+	var remaining []uint16
+	for _, core := range totalCores {
+		if _, ok := exclude[core]; !ok {
+			remaining = append(remaining, core)
+		}
+	}
+	if len(remaining) == 0 {
+		return "", fmt.Errorf("reserved cores %q leave no cores available out of %q", reserved, total)
+	}
+	return FormatCpuset(remaining), nil
+}
@@ -0,0 +1,56 @@
+package cgutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCpuset_ParseFormat(t *testing.T) {
+	cases := []struct {
+		spec     string
+		cores    []uint16
+		expected string
+		err      bool
+	}{
+		{spec: "", cores: nil, expected: ""},
+		{spec: "0", cores: []uint16{0}, expected: "0"},
+		{spec: "0-3", cores: []uint16{0, 1, 2, 3}, expected: "0-3"},
+		{spec: "0-2,5,7-8", cores: []uint16{0, 1, 2, 5, 7, 8}, expected: "0-2,5,7-8"},
+		{spec: "3,1,2,2", cores: []uint16{1, 2, 3}, expected: "1-3"},
+		{spec: "4-2", err: true},
+		{spec: "a", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			cores, err := ParseCpuset(c.spec)
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.cores, cores)
+			require.Equal(t, c.expected, FormatCpuset(cores))
+		})
+	}
+}
+
+func TestCpuset_Subtract(t *testing.T) {
+	require := require.New(t)
+
+	out, err := SubtractCpuset("0-7", "0,1")
+	require.NoError(err)
+	require.Equal("2-7", out)
+
+	out, err = SubtractCpuset("0-7", "3")
+	require.NoError(err)
+	require.Equal("0-2,4-7", out)
+
+	out, err = SubtractCpuset("0-3", "")
+	require.NoError(err)
+	require.Equal("0-3", out)
+
+	_, err = SubtractCpuset("0-1", "0-1")
+	require.Error(err)
+}
@@ -0,0 +1,3 @@
+// Package cgutil provides helpers for managing the cgroups of tasks on hosts
+// using either the legacy (v1) or the unified (v2) cgroup hierarchy.
+package cgutil
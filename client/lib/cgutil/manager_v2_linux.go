@@ -0,0 +1,302 @@
+// +build linux
+
+package cgutil
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// v2Controllers are the controllers enabled for task cgroups on hosts using
+// the unified hierarchy.
+var v2Controllers = []string{"cpu", "cpuset", "memory", "pids"}
+
+// V2Manager implements the libcontainer cgroups.Manager interface for the
+// unified cgroup hierarchy. Unlike the v1 managers, which track one path per
+// subsystem, a task lives in a single cgroup whose path is stored under the
+// empty key of the paths map.
+type V2Manager struct {
+	mu     sync.Mutex
+	cgroup *configs.Cgroup
+	path   string
+}
+
+// NewV2Manager returns a cgroups.Manager for the given cgroup config. If
+// paths contains a previously saved path it is used, otherwise the path is
+// derived from the config relative to CgroupRoot. Its signature matches the
+// NewCgroupsManager field of a libcontainer factory.
+func NewV2Manager(cgroup *configs.Cgroup, paths map[string]string) cgroups.Manager {
+	path := paths[""]
+	if path == "" && cgroup != nil {
+		path = filepath.Join(CgroupRoot, cgroup.Path)
+	}
+	return &V2Manager{
+		cgroup: cgroup,
+		path:   path,
+	}
+}
+
+// Apply creates the cgroup, enables the controllers tasks need in each of
+// its ancestors and moves the given pid into it.
+func (m *V2Manager) Apply(pid int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.path, 0755); err != nil {
+		return err
+	}
+	if err := enableControllers(m.path); err != nil {
+		return err
+	}
+	if pid == -1 {
+		return nil
+	}
+	return cgroups.WriteCgroupProc(m.path, pid)
+}
+
+// enableControllers writes the available task controllers into the
+// cgroup.subtree_control file of every ancestor of path below CgroupRoot.
+func enableControllers(path string) error {
+	rel, err := filepath.Rel(CgroupRoot, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("cgroup %q is not below %q", path, CgroupRoot)
+	}
+
+	// Controllers must be enabled top down as a cgroup may only enable
+	// controllers that are enabled in its parent
+	var ancestors []string
+	for dir := filepath.Dir(path); dir != CgroupRoot; dir = filepath.Dir(dir) {
+		ancestors = append(ancestors, dir)
+	}
+	ancestors = append(ancestors, CgroupRoot)
+
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if err := enableSubtreeControllers(ancestors[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func enableSubtreeControllers(dir string) error {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	available := make(map[string]struct{})
+	for _, c := range strings.Fields(string(b)) {
+		available[c] = struct{}{}
+	}
+
+	var enable []string
+	for _, c := range v2Controllers {
+		if _, ok := available[c]; ok {
+			enable = append(enable, "+"+c)
+		}
+	}
+	if len(enable) == 0 {
+		return nil
+	}
+	return writeFile(dir, "cgroup.subtree_control", strings.Join(enable, " "))
+}
+
+// GetPids returns the pids of the processes in the cgroup.
+func (m *V2Manager) GetPids() ([]int, error) {
+	return cgroups.GetPids(m.path)
+}
+
+// GetAllPids returns the pids of the processes in the cgroup and all of its
+// descendants.
+func (m *V2Manager) GetAllPids() ([]int, error) {
+	return cgroups.GetAllPids(m.path)
+}
+
+// GetStats returns the memory and cpu usage of the cgroup, translated into
+// the v1 style statistics the executor reports.
+func (m *V2Manager) GetStats() (*cgroups.Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := cgroups.NewStats()
+
+	memStat, err := readKeyValues(m.path, "memory.stat")
+	if err != nil {
+		return nil, err
+	}
+	stats.MemoryStats.Stats = memStat
+	stats.MemoryStats.Stats["rss"] = memStat["anon"]
+	stats.MemoryStats.Stats["cache"] = memStat["file"]
+	stats.MemoryStats.Cache = memStat["file"]
+
+	if stats.MemoryStats.Usage.Usage, err = readUint(m.path, "memory.current"); err != nil {
+		return nil, err
+	}
+	// Swap accounting may be disabled on the host
+	if swap, err := readUint(m.path, "memory.swap.current"); err == nil {
+		stats.MemoryStats.SwapUsage.Usage = swap
+	}
+
+	cpuStat, err := readKeyValues(m.path, "cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	// cpu.stat reports microseconds while v1 reports nanoseconds
+	stats.CpuStats.CpuUsage.TotalUsage = cpuStat["usage_usec"] * 1000
+	stats.CpuStats.CpuUsage.UsageInUsermode = cpuStat["user_usec"] * 1000
+	stats.CpuStats.CpuUsage.UsageInKernelmode = cpuStat["system_usec"] * 1000
+	stats.CpuStats.ThrottlingData.Periods = cpuStat["nr_periods"]
+	stats.CpuStats.ThrottlingData.ThrottledPeriods = cpuStat["nr_throttled"]
+	stats.CpuStats.ThrottlingData.ThrottledTime = cpuStat["throttled_usec"] * 1000
+
+	if pids, err := readUint(m.path, "pids.current"); err == nil {
+		stats.PidsStats.Current = pids
+	}
+
+	return stats, nil
+}
+
+// Freeze freezes or thaws the processes in the cgroup.
+func (m *V2Manager) Freeze(state configs.FreezerState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var value string
+	switch state {
+	case configs.Frozen:
+		value = "1"
+	case configs.Thawed:
+		value = "0"
+	default:
+		return fmt.Errorf("invalid freezer state %q", state)
+	}
+	if err := writeFile(m.path, "cgroup.freeze", value); err != nil {
+		return err
+	}
+	m.cgroup.Resources.Freezer = state
+	return nil
+}
+
+// Destroy removes the cgroup.
+func (m *V2Manager) Destroy() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := cgroups.RemovePaths(map[string]string{"": m.path}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPaths returns the path of the cgroup under the empty key.
+func (m *V2Manager) GetPaths() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return map[string]string{"": m.path}
+}
+
+// Set applies the resource limits of the container config to the cgroup.
+func (m *V2Manager) Set(container *configs.Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := container.Cgroups.Resources
+	if r == nil {
+		return nil
+	}
+
+	if r.Memory > 0 {
+		if err := writeFile(m.path, "memory.max", strconv.FormatInt(r.Memory, 10)); err != nil {
+			return err
+		}
+	}
+	if r.MemorySwappiness != nil && *r.MemorySwappiness == 0 {
+		// memory.swappiness does not exist in v2 so disable swap outright.
+		// The file is missing if swap accounting is disabled on the host.
+		if err := writeFile(m.path, "memory.swap.max", "0"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if r.CpuShares > 0 {
+		weight := CpuSharesToWeight(r.CpuShares)
+		if err := writeFile(m.path, "cpu.weight", strconv.FormatUint(weight, 10)); err != nil {
+			return err
+		}
+	}
+	if r.CpuQuota > 0 {
+		period := r.CpuPeriod
+		if period == 0 {
+			period = 100000
+		}
+		if err := writeFile(m.path, "cpu.max", fmt.Sprintf("%d %d", r.CpuQuota, period)); err != nil {
+			return err
+		}
+	}
+	if r.CpusetCpus != "" {
+		if err := writeFile(m.path, "cpuset.cpus", r.CpusetCpus); err != nil {
+			return err
+		}
+	}
+
+	m.cgroup = container.Cgroups
+	return nil
+}
+
+// CpuSharesToWeight converts v1 cpu shares in the range [2, 262144] to a v2
+// cpu weight in the range [1, 10000].
+func CpuSharesToWeight(shares uint64) uint64 {
+	if shares < 2 {
+		shares = 2
+	} else if shares > 262144 {
+		shares = 262144
+	}
+	return 1 + ((shares-2)*9999)/262142
+}
+
+func writeFile(dir, file, value string) error {
+	return ioutil.WriteFile(filepath.Join(dir, file), []byte(value), 0644)
+}
+
+func readUint(dir, file string) (uint64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(b))
+	if value == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readKeyValues parses a flat keyed cgroup file such as memory.stat.
+func readKeyValues(dir, file string) (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+		values[fields[0]] = v
+	}
+	return values, sc.Err()
+}
@@ -0,0 +1,97 @@
+// +build linux
+
+package cgutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV2Manager_CpuSharesToWeight(t *testing.T) {
+	require := require.New(t)
+	require.EqualValues(1, CpuSharesToWeight(0))
+	require.EqualValues(1, CpuSharesToWeight(2))
+	require.EqualValues(39, CpuSharesToWeight(1024))
+	require.EqualValues(10000, CpuSharesToWeight(262144))
+	require.EqualValues(10000, CpuSharesToWeight(1000000))
+}
+
+func TestV2Manager_GetStats(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "cgutil")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"memory.stat":    "anon 4096\nfile 8192\nkernel_stack 16384\n",
+		"memory.current": "20480\n",
+		"cpu.stat":       "usage_usec 300\nuser_usec 200\nsystem_usec 100\nnr_periods 5\nnr_throttled 2\nthrottled_usec 40\n",
+		"pids.current":   "3\n",
+	}
+	for name, content := range files {
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	m := NewV2Manager(&configs.Cgroup{Resources: &configs.Resources{}}, map[string]string{"": dir})
+	require.Equal(map[string]string{"": dir}, m.GetPaths())
+
+	stats, err := m.GetStats()
+	require.NoError(err)
+
+	require.EqualValues(4096, stats.MemoryStats.Stats["rss"])
+	require.EqualValues(8192, stats.MemoryStats.Stats["cache"])
+	require.EqualValues(16384, stats.MemoryStats.Stats["kernel_stack"])
+	require.EqualValues(20480, stats.MemoryStats.Usage.Usage)
+	require.Zero(stats.MemoryStats.SwapUsage.Usage)
+
+	require.EqualValues(300000, stats.CpuStats.CpuUsage.TotalUsage)
+	require.EqualValues(200000, stats.CpuStats.CpuUsage.UsageInUsermode)
+	require.EqualValues(100000, stats.CpuStats.CpuUsage.UsageInKernelmode)
+	require.EqualValues(2, stats.CpuStats.ThrottlingData.ThrottledPeriods)
+	require.EqualValues(40000, stats.CpuStats.ThrottlingData.ThrottledTime)
+	require.EqualValues(3, stats.PidsStats.Current)
+}
+
+func TestV2Manager_Set(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "cgutil")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	var swappiness uint64
+	cfg := &configs.Config{
+		Cgroups: &configs.Cgroup{
+			Resources: &configs.Resources{
+				Memory:           256 * 1024 * 1024,
+				MemorySwappiness: &swappiness,
+				CpuShares:        1024,
+				CpusetCpus:       "2-3",
+			},
+		},
+	}
+
+	m := NewV2Manager(cfg.Cgroups, map[string]string{"": dir})
+	require.NoError(m.Set(cfg))
+
+	expected := map[string]string{
+		"memory.max":      "268435456",
+		"memory.swap.max": "0",
+		"cpu.weight":      "39",
+		"cpuset.cpus":     "2-3",
+	}
+	for name, content := range expected {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(err)
+		require.Equal(content, string(b), name)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "cpu.max"))
+	require.True(os.IsNotExist(err))
+}
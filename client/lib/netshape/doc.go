@@ -0,0 +1,4 @@
+// Package netshape provides helpers for limiting the network bandwidth of
+// tasks with their own network namespace, by shaping traffic on the host side
+// of the veth pair connecting the task to the host.
+package netshape
@@ -0,0 +1,68 @@
+package netshape
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// minBurst is the smallest burst allowed, which must be larger than the
+	// interface's MTU for any traffic to pass.
+	minBurst = 32 * 1024
+
+	// latency is the longest a packet may wait in the egress queue before
+	// being dropped.
+	latency = "50ms"
+)
+
+// burst returns the number of bytes that may be sent at once when limited to
+// mbits, which is the amount sent at that rate in 10ms.
+func burst(mbits int) int {
+	b := mbits * 1000 * 1000 / 8 / 100
+	if b < minBurst {
+		return minBurst
+	}
+	return b
+}
+
+// rate returns the tc rate for mbits.
+func rate(mbits int) string {
+	return strconv.Itoa(mbits) + "mbit"
+}
+
+// egressArgs returns the tc arguments limiting traffic sent from the host to
+// the task, which leaves the host through the host side of the veth.
+func egressArgs(dev string, mbits int) []string {
+	return []string{
+		"qdisc", "replace", "dev", dev, "root",
+		"tbf", "rate", rate(mbits), "burst", strconv.Itoa(burst(mbits)), "latency", latency,
+	}
+}
+
+// ingressQdiscArgs returns the tc arguments adding the ingress qdisc the
+// ingress policing filter is attached to.
+func ingressQdiscArgs(dev string) []string {
+	return []string{"qdisc", "replace", "dev", dev, "handle", "ffff:", "ingress"}
+}
+
+// ingressFilterArgs returns the tc arguments limiting traffic sent by the
+// task, which arrives at the host side of the veth. Ingress traffic can't
+// be queued, so traffic over the limit is dropped.
+func ingressFilterArgs(dev string, mbits int) []string {
+	return []string{
+		"filter", "replace", "dev", dev, "parent", "ffff:", "protocol", "all", "prio", "1",
+		"u32", "match", "u32", "0", "0",
+		"police", "rate", rate(mbits), "burst", strconv.Itoa(burst(mbits)), "drop", "flowid", ":1",
+	}
+}
+
+// validate returns an error if mbits can't be enforced.
+func validate(dev string, mbits int) error {
+	if dev == "" {
+		return fmt.Errorf("no interface to shape")
+	}
+	if mbits <= 0 {
+		return fmt.Errorf("invalid bandwidth %d mbits", mbits)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+package netshape
+
+import "fmt"
+
+// HostVeth returns an error as veth pairs are only supported on Linux.
+func HostVeth(pid int, ifName string) (string, error) {
+	return "", fmt.Errorf("bandwidth shaping is only supported on Linux")
+}
+
+// Shape returns an error as bandwidth shaping is only supported on Linux.
+func Shape(dev string, mbits int) error {
+	return fmt.Errorf("bandwidth shaping is only supported on Linux")
+}
@@ -0,0 +1,54 @@
+package netshape
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HostVeth returns the name of the host side of the veth pair whose other
+// end is the named interface in the network namespace of the process pid.
+// The process must have sysfs mounted within its network namespace, as is
+// the case for containers.
+func HostVeth(pid int, ifName string) (string, error) {
+	path := filepath.Join("/proc", strconv.Itoa(pid), "root", "sys", "class", "net", ifName, "iflink")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to find peer of %q: %v", ifName, err)
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return "", fmt.Errorf("invalid peer index of %q: %v", ifName, err)
+	}
+
+	iface, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return "", fmt.Errorf("failed to find host interface %d: %v", index, err)
+	}
+	return iface.Name, nil
+}
+
+// Shape limits traffic both to and from a task to mbits by shaping the host
+// side of the task's veth.
+func Shape(dev string, mbits int) error {
+	if err := validate(dev, mbits); err != nil {
+		return err
+	}
+
+	for _, args := range [][]string{
+		egressArgs(dev, mbits),
+		ingressQdiscArgs(dev),
+		ingressFilterArgs(dev, mbits),
+	} {
+		if out, err := exec.Command("tc", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to shape %q: tc %s: %v: %s",
+				dev, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package netshape
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostVeth(t *testing.T) {
+	// The loopback interface is its own peer
+	dev, err := HostVeth(os.Getpid(), "lo")
+	require.NoError(t, err)
+	require.Equal(t, "lo", dev)
+
+	_, err = HostVeth(os.Getpid(), "nomad-missing")
+	require.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package netshape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurst(t *testing.T) {
+	require.Equal(t, minBurst, burst(1))
+	require.Equal(t, 125000, burst(100))
+	require.Equal(t, 1250000, burst(1000))
+}
+
+func TestArgs(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal([]string{
+		"qdisc", "replace", "dev", "veth0", "root",
+		"tbf", "rate", "100mbit", "burst", "125000", "latency", "50ms",
+	}, egressArgs("veth0", 100))
+
+	require.Equal([]string{
+		"qdisc", "replace", "dev", "veth0", "handle", "ffff:", "ingress",
+	}, ingressQdiscArgs("veth0"))
+
+	require.Equal([]string{
+		"filter", "replace", "dev", "veth0", "parent", "ffff:", "protocol", "all", "prio", "1",
+		"u32", "match", "u32", "0", "0",
+		"police", "rate", "10mbit", "burst", "32768", "drop", "flowid", ":1",
+	}, ingressFilterArgs("veth0", 10))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validate("veth0", 10))
+	require.Error(t, validate("", 10))
+	require.Error(t, validate("veth0", 0))
+}
@@ -19,6 +19,11 @@ func (c *logmonClient) Start(cfg *LogConfig) error {
 		MaxFileSizeMb:  uint32(cfg.MaxFileSizeMB),
 		StdoutFifo:     cfg.StdoutFifo,
 		StderrFifo:     cfg.StderrFifo,
+		TaskName:       cfg.TaskName,
+		AllocId:        cfg.AllocID,
+		Sink:           cfg.Sink,
+		SinkOptions:    cfg.SinkOptions,
+		DisableFiles:   cfg.DisableFiles,
 	}
 	_, err := c.client.Start(context.Background(), req)
 	return err
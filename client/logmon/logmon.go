@@ -10,6 +10,7 @@ import (
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/client/lib/fifo"
 	"github.com/hashicorp/nomad/client/logmon/logging"
+	"github.com/hashicorp/nomad/client/logmon/sink"
 )
 
 const (
@@ -40,6 +41,21 @@ type LogConfig struct {
 
 	// MaxFileSizeMB is the max log file size in MB allowed before rotation occures
 	MaxFileSizeMB int
+
+	// TaskName and AllocID identify the task to the log sink
+	TaskName string
+	AllocID  string
+
+	// Sink is the name of the log sink output is streamed to. If empty, output
+	// is only written to log files.
+	Sink string
+
+	// SinkOptions are the options of the log sink
+	SinkOptions map[string]string
+
+	// DisableFiles disables writing output to log files, leaving the sink as
+	// the only destination.
+	DisableFiles bool
 }
 
 type LogMon interface {
@@ -106,51 +122,162 @@ func (tl *TaskLogger) Close() {
 func NewTaskLogger(cfg *LogConfig, logger hclog.Logger) (*TaskLogger, error) {
 	tl := &TaskLogger{config: cfg}
 
-	logFileSize := int64(cfg.MaxFileSizeMB * 1024 * 1024)
-	lro, err := logging.NewFileRotator(cfg.LogDir, cfg.StdoutLogFile,
-		cfg.MaxFiles, logFileSize, logger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout logfile for %q: %v", cfg.StdoutLogFile, err)
+	var logSink sink.Sink
+	if cfg.Sink != "" {
+		var err error
+		logSink, err = sink.New(cfg.Sink, &sink.Config{
+			TaskName: cfg.TaskName,
+			AllocID:  cfg.AllocID,
+			Options:  cfg.SinkOptions,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create log sink: %v", err)
+		}
+	} else if cfg.DisableFiles {
+		return nil, fmt.Errorf("log files can not be disabled without a log sink")
 	}
 
-	wrapperOut, err := newLogRotatorWrapper(cfg.StdoutFifo, logger, lro)
+	wrapperOut, err := newTaskOutput(cfg, cfg.StdoutLogFile, cfg.StdoutFifo, sink.StreamStdout, logSink, logger)
 	if err != nil {
 		return nil, err
 	}
 
 	tl.lro = wrapperOut
 
-	lre, err := logging.NewFileRotator(cfg.LogDir, cfg.StderrLogFile,
-		cfg.MaxFiles, logFileSize, logger)
+	wrapperErr, err := newTaskOutput(cfg, cfg.StderrLogFile, cfg.StderrFifo, sink.StreamStderr, logSink, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr logfile for %q: %v", cfg.StderrLogFile, err)
+		wrapperOut.Close()
+		return nil, err
+	}
+
+	tl.lre = wrapperErr
+
+	return tl, nil
+
+}
+
+// newTaskOutput returns a wrapper copying the output read from the fifo to
+// the rotated log file and the log sink's stream.
+func newTaskOutput(cfg *LogConfig, logFile, fifoPath, stream string, logSink sink.Sink, logger hclog.Logger) (*logRotatorWrapper, error) {
+	var writers []io.WriteCloser
+	closeWriters := func() {
+		for _, w := range writers {
+			w.Close()
+		}
+	}
+
+	if !cfg.DisableFiles {
+		logFileSize := int64(cfg.MaxFileSizeMB * 1024 * 1024)
+		lr, err := logging.NewFileRotator(cfg.LogDir, logFile,
+			cfg.MaxFiles, logFileSize, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s logfile for %q: %v", stream, logFile, err)
+		}
+		writers = append(writers, &rotatorCloser{lr})
+	}
+
+	if logSink != nil {
+		w, err := logSink.Stream(stream)
+		if err != nil {
+			closeWriters()
+			return nil, fmt.Errorf("failed to open %s log sink: %v", stream, err)
+		}
+		writers = append(writers, newSinkWriter(w, stream, logger))
 	}
 
-	wrapperErr, err := newLogRotatorWrapper(cfg.StderrFifo, logger, lre)
+	wrapper, err := newLogRotatorWrapper(fifoPath, logger, newMultiWriteCloser(writers))
 	if err != nil {
+		closeWriters()
 		return nil, err
 	}
+	return wrapper, nil
+}
 
-	tl.lre = wrapperErr
+// rotatorCloser adapts a FileRotator to an io.WriteCloser.
+type rotatorCloser struct {
+	*logging.FileRotator
+}
 
-	return tl, nil
+func (r *rotatorCloser) Close() error {
+	r.FileRotator.Close()
+	return nil
+}
 
+// sinkWriter writes to a log sink stream. Errors are logged rather than
+// returned so that a failing sink does not stop output being copied to the
+// log files.
+type sinkWriter struct {
+	w       io.WriteCloser
+	stream  string
+	failing bool
+	logger  hclog.Logger
+}
+
+func newSinkWriter(w io.WriteCloser, stream string, logger hclog.Logger) *sinkWriter {
+	return &sinkWriter{
+		w:      w,
+		stream: stream,
+		logger: logger,
+	}
+}
+
+func (s *sinkWriter) Write(p []byte) (int, error) {
+	_, err := s.w.Write(p)
+	if err != nil && !s.failing {
+		s.logger.Warn("failed to write to log sink, output may be lost", "stream", s.stream, "error", err)
+	} else if err == nil && s.failing {
+		s.logger.Info("log sink recovered", "stream", s.stream)
+	}
+	s.failing = err != nil
+	return len(p), nil
+}
+
+func (s *sinkWriter) Close() error {
+	return s.w.Close()
+}
+
+// multiWriteCloser duplicates writes to each of its writers and closes all of
+// them when closed.
+type multiWriteCloser struct {
+	io.Writer
+	writers []io.WriteCloser
+}
+
+func newMultiWriteCloser(writers []io.WriteCloser) *multiWriteCloser {
+	ws := make([]io.Writer, len(writers))
+	for i, w := range writers {
+		ws[i] = w
+	}
+	return &multiWriteCloser{
+		Writer:  io.MultiWriter(ws...),
+		writers: writers,
+	}
+}
+
+func (m *multiWriteCloser) Close() error {
+	var err error
+	for _, w := range m.writers {
+		if cerr := w.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
 
 // logRotatorWrapper wraps our log rotator and exposes a pipe that can feed the
 // log rotator data. The processOutWriter should be attached to the process and
-// data will be copied from the reader to the rotator.
+// data will be copied from the reader to the rotator and log sink.
 type logRotatorWrapper struct {
 	fifoPath          string
 	processOutReader  io.ReadCloser
-	rotatorWriter     *logging.FileRotator
+	rotatorWriter     io.WriteCloser
 	hasFinishedCopied chan struct{}
 	logger            hclog.Logger
 }
 
 // newLogRotatorWrapper takes a rotator and returns a wrapper that has the
 // processOutWriter to attach to the stdout or stderr of a process.
-func newLogRotatorWrapper(path string, logger hclog.Logger, rotator *logging.FileRotator) (*logRotatorWrapper, error) {
+func newLogRotatorWrapper(path string, logger hclog.Logger, rotator io.WriteCloser) (*logRotatorWrapper, error) {
 	logger.Info("opening fifo", "path", path)
 	f, err := fifo.New(path)
 	if err != nil {
@@ -24,23 +24,28 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type StartRequest struct {
-	LogDir               string   `protobuf:"bytes,1,opt,name=log_dir,json=logDir,proto3" json:"log_dir,omitempty"`
-	StdoutFileName       string   `protobuf:"bytes,2,opt,name=stdout_file_name,json=stdoutFileName,proto3" json:"stdout_file_name,omitempty"`
-	StderrFileName       string   `protobuf:"bytes,3,opt,name=stderr_file_name,json=stderrFileName,proto3" json:"stderr_file_name,omitempty"`
-	MaxFiles             uint32   `protobuf:"varint,4,opt,name=max_files,json=maxFiles,proto3" json:"max_files,omitempty"`
-	MaxFileSizeMb        uint32   `protobuf:"varint,5,opt,name=max_file_size_mb,json=maxFileSizeMb,proto3" json:"max_file_size_mb,omitempty"`
-	StdoutFifo           string   `protobuf:"bytes,6,opt,name=stdout_fifo,json=stdoutFifo,proto3" json:"stdout_fifo,omitempty"`
-	StderrFifo           string   `protobuf:"bytes,7,opt,name=stderr_fifo,json=stderrFifo,proto3" json:"stderr_fifo,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	LogDir               string            `protobuf:"bytes,1,opt,name=log_dir,json=logDir,proto3" json:"log_dir,omitempty"`
+	StdoutFileName       string            `protobuf:"bytes,2,opt,name=stdout_file_name,json=stdoutFileName,proto3" json:"stdout_file_name,omitempty"`
+	StderrFileName       string            `protobuf:"bytes,3,opt,name=stderr_file_name,json=stderrFileName,proto3" json:"stderr_file_name,omitempty"`
+	MaxFiles             uint32            `protobuf:"varint,4,opt,name=max_files,json=maxFiles,proto3" json:"max_files,omitempty"`
+	MaxFileSizeMb        uint32            `protobuf:"varint,5,opt,name=max_file_size_mb,json=maxFileSizeMb,proto3" json:"max_file_size_mb,omitempty"`
+	StdoutFifo           string            `protobuf:"bytes,6,opt,name=stdout_fifo,json=stdoutFifo,proto3" json:"stdout_fifo,omitempty"`
+	StderrFifo           string            `protobuf:"bytes,7,opt,name=stderr_fifo,json=stderrFifo,proto3" json:"stderr_fifo,omitempty"`
+	TaskName             string            `protobuf:"bytes,8,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	AllocId              string            `protobuf:"bytes,9,opt,name=alloc_id,json=allocId,proto3" json:"alloc_id,omitempty"`
+	Sink                 string            `protobuf:"bytes,10,opt,name=sink,proto3" json:"sink,omitempty"`
+	SinkOptions          map[string]string `protobuf:"bytes,11,rep,name=sink_options,json=sinkOptions,proto3" json:"sink_options,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	DisableFiles         bool              `protobuf:"varint,12,opt,name=disable_files,json=disableFiles,proto3" json:"disable_files,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *StartRequest) Reset()         { *m = StartRequest{} }
 func (m *StartRequest) String() string { return proto.CompactTextString(m) }
 func (*StartRequest) ProtoMessage()    {}
 func (*StartRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_logmon_6dbff459851a9ae9, []int{0}
+	return fileDescriptor_logmon_9411d2b775946682, []int{0}
 }
 func (m *StartRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StartRequest.Unmarshal(m, b)
@@ -109,6 +114,41 @@ func (m *StartRequest) GetStderrFifo() string {
 	return ""
 }
 
+func (m *StartRequest) GetTaskName() string {
+	if m != nil {
+		return m.TaskName
+	}
+	return ""
+}
+
+func (m *StartRequest) GetAllocId() string {
+	if m != nil {
+		return m.AllocId
+	}
+	return ""
+}
+
+func (m *StartRequest) GetSink() string {
+	if m != nil {
+		return m.Sink
+	}
+	return ""
+}
+
+func (m *StartRequest) GetSinkOptions() map[string]string {
+	if m != nil {
+		return m.SinkOptions
+	}
+	return nil
+}
+
+func (m *StartRequest) GetDisableFiles() bool {
+	if m != nil {
+		return m.DisableFiles
+	}
+	return false
+}
+
 type StartResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -119,7 +159,7 @@ func (m *StartResponse) Reset()         { *m = StartResponse{} }
 func (m *StartResponse) String() string { return proto.CompactTextString(m) }
 func (*StartResponse) ProtoMessage()    {}
 func (*StartResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_logmon_6dbff459851a9ae9, []int{1}
+	return fileDescriptor_logmon_9411d2b775946682, []int{1}
 }
 func (m *StartResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StartResponse.Unmarshal(m, b)
@@ -149,7 +189,7 @@ func (m *StopRequest) Reset()         { *m = StopRequest{} }
 func (m *StopRequest) String() string { return proto.CompactTextString(m) }
 func (*StopRequest) ProtoMessage()    {}
 func (*StopRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_logmon_6dbff459851a9ae9, []int{2}
+	return fileDescriptor_logmon_9411d2b775946682, []int{2}
 }
 func (m *StopRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StopRequest.Unmarshal(m, b)
@@ -179,7 +219,7 @@ func (m *StopResponse) Reset()         { *m = StopResponse{} }
 func (m *StopResponse) String() string { return proto.CompactTextString(m) }
 func (*StopResponse) ProtoMessage()    {}
 func (*StopResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_logmon_6dbff459851a9ae9, []int{3}
+	return fileDescriptor_logmon_9411d2b775946682, []int{3}
 }
 func (m *StopResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StopResponse.Unmarshal(m, b)
@@ -201,6 +241,7 @@ var xxx_messageInfo_StopResponse proto.InternalMessageInfo
 
 func init() {
 	proto.RegisterType((*StartRequest)(nil), "hashicorp.nomad.client.logmon.proto.StartRequest")
+	proto.RegisterMapType((map[string]string)(nil), "hashicorp.nomad.client.logmon.proto.StartRequest.SinkOptionsEntry")
 	proto.RegisterType((*StartResponse)(nil), "hashicorp.nomad.client.logmon.proto.StartResponse")
 	proto.RegisterType((*StopRequest)(nil), "hashicorp.nomad.client.logmon.proto.StopRequest")
 	proto.RegisterType((*StopResponse)(nil), "hashicorp.nomad.client.logmon.proto.StopResponse")
@@ -312,29 +353,37 @@ var _LogMon_serviceDesc = grpc.ServiceDesc{
 }
 
 func init() {
-	proto.RegisterFile("client/logmon/proto/logmon.proto", fileDescriptor_logmon_6dbff459851a9ae9)
-}
-
-var fileDescriptor_logmon_6dbff459851a9ae9 = []byte{
-	// 320 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x90, 0x31, 0x6f, 0xc2, 0x30,
-	0x10, 0x85, 0x1b, 0x0a, 0xa1, 0x1c, 0x0d, 0x45, 0x5e, 0x1a, 0xd1, 0xa1, 0x28, 0x1d, 0xca, 0x14,
-	0x0a, 0xfd, 0x07, 0x55, 0xd5, 0xa9, 0x74, 0x80, 0xad, 0x4b, 0x64, 0xc0, 0x09, 0x96, 0x62, 0x5f,
-	0x6a, 0x1b, 0x09, 0xb1, 0xf6, 0xd7, 0xf6, 0x5f, 0x54, 0x71, 0x4c, 0x94, 0x11, 0x26, 0xeb, 0xee,
-	0x7d, 0x4f, 0xf7, 0xfc, 0x60, 0xbc, 0xc9, 0x39, 0x93, 0x66, 0x9a, 0x63, 0x26, 0x50, 0x4e, 0x0b,
-	0x85, 0x06, 0xdd, 0x10, 0xdb, 0x81, 0x3c, 0xed, 0xa8, 0xde, 0xf1, 0x0d, 0xaa, 0x22, 0x96, 0x28,
-	0xe8, 0x36, 0xae, 0x1c, 0x71, 0x13, 0x8a, 0x7e, 0x5b, 0x70, 0xbb, 0x32, 0x54, 0x99, 0x25, 0xfb,
-	0xd9, 0x33, 0x6d, 0xc8, 0x3d, 0x74, 0x73, 0xcc, 0x92, 0x2d, 0x57, 0xa1, 0x37, 0xf6, 0x26, 0xbd,
-	0xa5, 0x9f, 0x63, 0xf6, 0xce, 0x15, 0x99, 0xc0, 0x50, 0x9b, 0x2d, 0xee, 0x4d, 0x92, 0xf2, 0x9c,
-	0x25, 0x92, 0x0a, 0x16, 0xb6, 0x2c, 0x31, 0xa8, 0xf6, 0x1f, 0x3c, 0x67, 0x5f, 0x54, 0x30, 0x47,
-	0x32, 0xa5, 0x1a, 0xe4, 0x75, 0x4d, 0x32, 0xa5, 0x6a, 0xf2, 0x01, 0x7a, 0x82, 0x1e, 0x2c, 0xa6,
-	0xc3, 0xf6, 0xd8, 0x9b, 0x04, 0xcb, 0x1b, 0x41, 0x0f, 0xa5, 0xae, 0xc9, 0x33, 0x0c, 0x4f, 0x62,
-	0xa2, 0xf9, 0x91, 0x25, 0x62, 0x1d, 0x76, 0x2c, 0x13, 0x38, 0x66, 0xc5, 0x8f, 0x6c, 0xb1, 0x26,
-	0x8f, 0xd0, 0xaf, 0x93, 0xa5, 0x18, 0xfa, 0xf6, 0x14, 0x9c, 0x42, 0xa5, 0xe8, 0x80, 0x2a, 0x50,
-	0x8a, 0x61, 0xb7, 0x06, 0x6c, 0x96, 0x14, 0xa3, 0x3b, 0x08, 0x5c, 0x09, 0xba, 0x40, 0xa9, 0x59,
-	0x14, 0x40, 0x7f, 0x65, 0xb0, 0x70, 0xa5, 0x44, 0x83, 0xb2, 0xa4, 0x72, 0xac, 0xe4, 0xf9, 0x9f,
-	0x07, 0xfe, 0x27, 0x66, 0x0b, 0x94, 0xa4, 0x80, 0x8e, 0xb5, 0x92, 0x59, 0x7c, 0x46, 0xdf, 0x71,
-	0xb3, 0xeb, 0xd1, 0xfc, 0x12, 0x8b, 0x4b, 0x76, 0x45, 0x04, 0xb4, 0xcb, 0x30, 0xe4, 0xe5, 0x4c,
-	0x77, 0xfd, 0x8d, 0xd1, 0xec, 0x02, 0xc7, 0xe9, 0xdc, 0x5b, 0xf7, 0xbb, 0x63, 0xf7, 0x6b, 0xdf,
-	0x3e, 0xaf, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff, 0xd8, 0xb4, 0x87, 0xfc, 0x7a, 0x02, 0x00, 0x00,
+	proto.RegisterFile("client/logmon/proto/logmon.proto", fileDescriptor_logmon_9411d2b775946682)
+}
+
+var fileDescriptor_logmon_9411d2b775946682 = []byte{
+	// 446 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0xcd, 0x8e, 0xd3, 0x30,
+	0x10, 0x80, 0xc9, 0xf6, 0x2f, 0x9d, 0x24, 0x4b, 0x65, 0x21, 0x11, 0xca, 0x81, 0xa8, 0x7b, 0x20,
+	0xa7, 0x2c, 0x5b, 0x2e, 0x88, 0x03, 0x87, 0x15, 0x20, 0x21, 0xb1, 0x20, 0xa5, 0x37, 0x2e, 0x91,
+	0xdb, 0x38, 0x5d, 0xab, 0x8e, 0x27, 0xd8, 0x2e, 0xda, 0xdd, 0x57, 0xe1, 0x09, 0x79, 0x0b, 0x14,
+	0xc7, 0x0d, 0x15, 0xa7, 0xed, 0xc9, 0x9e, 0x99, 0x6f, 0xec, 0xcf, 0x1e, 0x48, 0x36, 0x82, 0x33,
+	0x69, 0x2e, 0x05, 0x6e, 0x6b, 0x94, 0x97, 0x8d, 0x42, 0x83, 0x2e, 0xc8, 0x6c, 0x40, 0x2e, 0x6e,
+	0xa9, 0xbe, 0xe5, 0x1b, 0x54, 0x4d, 0x26, 0xb1, 0xa6, 0x65, 0xd6, 0x75, 0x64, 0xc7, 0xd0, 0xe2,
+	0xf7, 0x10, 0xc2, 0x95, 0xa1, 0xca, 0xe4, 0xec, 0xe7, 0x9e, 0x69, 0x43, 0x9e, 0xc3, 0x44, 0xe0,
+	0xb6, 0x28, 0xb9, 0x8a, 0xbd, 0xc4, 0x4b, 0xa7, 0xf9, 0x58, 0xe0, 0xf6, 0x23, 0x57, 0x24, 0x85,
+	0x99, 0x36, 0x25, 0xee, 0x4d, 0x51, 0x71, 0xc1, 0x0a, 0x49, 0x6b, 0x16, 0x9f, 0x59, 0xe2, 0xbc,
+	0xcb, 0x7f, 0xe6, 0x82, 0x7d, 0xa3, 0x35, 0x73, 0x24, 0x53, 0xea, 0x88, 0x1c, 0xf4, 0x24, 0x53,
+	0xaa, 0x27, 0x5f, 0xc2, 0xb4, 0xa6, 0x77, 0x16, 0xd3, 0xf1, 0x30, 0xf1, 0xd2, 0x28, 0xf7, 0x6b,
+	0x7a, 0xd7, 0xd6, 0x35, 0x79, 0x0d, 0xb3, 0x43, 0xb1, 0xd0, 0xfc, 0x81, 0x15, 0xf5, 0x3a, 0x1e,
+	0x59, 0x26, 0x72, 0xcc, 0x8a, 0x3f, 0xb0, 0x9b, 0x35, 0x79, 0x05, 0x41, 0x6f, 0x56, 0x61, 0x3c,
+	0xb6, 0x57, 0xc1, 0x41, 0xaa, 0x42, 0x07, 0x74, 0x42, 0x15, 0xc6, 0x93, 0x1e, 0xb0, 0x2e, 0x15,
+	0xb6, 0x1e, 0x86, 0xea, 0x5d, 0xa7, 0xea, 0xdb, 0xb2, 0xdf, 0x26, 0xac, 0xe4, 0x0b, 0xf0, 0xa9,
+	0x10, 0xb8, 0x29, 0x78, 0x19, 0x4f, 0x6d, 0x6d, 0x62, 0xe3, 0x2f, 0x25, 0x21, 0x30, 0xd4, 0x5c,
+	0xee, 0x62, 0xb0, 0x69, 0xbb, 0x27, 0x0c, 0xc2, 0x76, 0x2d, 0xb0, 0x31, 0x1c, 0xa5, 0x8e, 0x83,
+	0x64, 0x90, 0x06, 0xcb, 0xeb, 0xec, 0x11, 0xd3, 0xc8, 0x8e, 0x27, 0x91, 0xad, 0xb8, 0xdc, 0x7d,
+	0xef, 0x0e, 0xf9, 0x24, 0x8d, 0xba, 0xcf, 0x03, 0xfd, 0x2f, 0x43, 0x2e, 0x20, 0x2a, 0xb9, 0xa6,
+	0x6b, 0xc1, 0xdc, 0xf7, 0x85, 0x89, 0x97, 0xfa, 0x79, 0xe8, 0x92, 0xf6, 0x0b, 0xe7, 0x1f, 0x60,
+	0xf6, 0xff, 0x29, 0x64, 0x06, 0x83, 0x1d, 0xbb, 0x77, 0xc3, 0x6d, 0xb7, 0xe4, 0x19, 0x8c, 0x7e,
+	0x51, 0xb1, 0x3f, 0x8c, 0xb3, 0x0b, 0xde, 0x9f, 0xbd, 0xf3, 0x16, 0x4f, 0x21, 0x72, 0x4a, 0xba,
+	0x41, 0xa9, 0xd9, 0x22, 0x82, 0x60, 0x65, 0xb0, 0x71, 0x8a, 0x8b, 0x73, 0x08, 0xbb, 0xb0, 0x2b,
+	0x2f, 0xff, 0x78, 0x30, 0xfe, 0x8a, 0xdb, 0x1b, 0x94, 0xa4, 0x81, 0x91, 0x6d, 0x25, 0x57, 0x27,
+	0xbf, 0x7c, 0xbe, 0x3c, 0xa5, 0xc5, 0x99, 0x3d, 0x21, 0x35, 0x0c, 0x5b, 0x19, 0xf2, 0xe6, 0x91,
+	0xdd, 0xfd, 0x33, 0xe6, 0x57, 0x27, 0x74, 0x1c, 0xae, 0xbb, 0x9e, 0xfc, 0x18, 0xd9, 0xfc, 0x7a,
+	0x6c, 0x97, 0xb7, 0x7f, 0x07, 0x00, 0x14, 0x45, 0x53, 0xc4, 0x92, 0x03, 0x00, 0x00,
 }
@@ -21,6 +21,11 @@ func (s *logmonServer) Start(ctx context.Context, req *proto.StartRequest) (*pro
 		MaxFileSizeMB: int(req.MaxFileSizeMb),
 		StdoutFifo:    req.StdoutFifo,
 		StderrFifo:    req.StderrFifo,
+		TaskName:      req.TaskName,
+		AllocID:       req.AllocId,
+		Sink:          req.Sink,
+		SinkOptions:   req.SinkOptions,
+		DisableFiles:  req.DisableFiles,
 	}
 
 	err := s.impl.Start(cfg)
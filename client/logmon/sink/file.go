@@ -0,0 +1,138 @@
+package sink
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// FileDirOption is the option holding the directory the file sink writes
+	// to. It is set by the client rather than the job so that jobs can not
+	// write to arbitrary paths on the host.
+	FileDirOption = "dir"
+
+	// fileReopenInterval is how often the file sink checks whether its files
+	// have been moved or removed by an external log rotator.
+	fileReopenInterval = time.Second
+)
+
+// fileSink appends a task's output to files in a shared directory. Rotation
+// and retention are left to external collectors: when a file is moved or
+// removed the sink reopens it at its original path.
+type fileSink struct {
+	dir    string
+	prefix string
+	logger hclog.Logger
+}
+
+// NewFileSink returns a sink writing a task's output to files named
+// <alloc id>.<task>.stdout and <alloc id>.<task>.stderr in the directory
+// given by FileDirOption.
+func NewFileSink(cfg *Config, logger hclog.Logger) (Sink, error) {
+	dir := cfg.Options[FileDirOption]
+	if dir == "" {
+		return nil, fmt.Errorf("file log sink requires a directory")
+	}
+	if !filepath.IsAbs(dir) {
+		return nil, fmt.Errorf("file log sink directory %q must be absolute", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create file log sink directory: %v", err)
+	}
+
+	return &fileSink{
+		dir:    dir,
+		prefix: fmt.Sprintf("%s.%s", cfg.AllocID, cfg.TaskName),
+		logger: logger,
+	}, nil
+}
+
+func (s *fileSink) Stream(name string) (io.WriteCloser, error) {
+	w := &reopeningFile{
+		path:   filepath.Join(s.dir, fmt.Sprintf("%s.%s", s.prefix, name)),
+		logger: s.logger,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// reopeningFile appends to a file, reopening it if it is moved or removed.
+type reopeningFile struct {
+	path      string
+	f         *os.File
+	lastCheck time.Time
+	closed    bool
+	logger    hclog.Logger
+	l         sync.Mutex
+}
+
+func (r *reopeningFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	r.f = f
+	r.lastCheck = time.Now()
+	return nil
+}
+
+// rotated returns whether the open file is no longer at the path.
+func (r *reopeningFile) rotated() bool {
+	cur, err := os.Stat(r.path)
+	if err != nil {
+		return true
+	}
+	open, err := r.f.Stat()
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(cur, open)
+}
+
+func (r *reopeningFile) Write(p []byte) (int, error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+
+	if r.f != nil && time.Since(r.lastCheck) >= fileReopenInterval {
+		r.lastCheck = time.Now()
+		if r.rotated() {
+			r.logger.Debug("reopening rotated log file", "path", r.path)
+			r.f.Close()
+			r.f = nil
+		}
+	}
+
+	// Open the file if it was rotated or a previous attempt failed
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	return r.f.Write(p)
+}
+
+func (r *reopeningFile) Close() error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.closed = true
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
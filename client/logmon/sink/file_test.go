@@ -0,0 +1,58 @@
+package sink
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomadtest")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// The directory is required
+	cfg := &Config{TaskName: "web", AllocID: "1234"}
+	_, err = New("file", cfg, testlog.HCLogger(t))
+	require.Error(err)
+
+	cfg.Options = map[string]string{FileDirOption: dir}
+	s, err := New("file", cfg, testlog.HCLogger(t))
+	require.NoError(err)
+
+	w, err := s.Stream(StreamStdout)
+	require.NoError(err)
+	defer w.Close()
+
+	path := filepath.Join(dir, "1234.web.stdout")
+	_, err = w.Write([]byte("foo\n"))
+	require.NoError(err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(err)
+	require.Equal("foo\n", string(out))
+
+	// Rotate the file as an external collector would and check the sink
+	// reopens it
+	require.NoError(os.Rename(path, path+".1"))
+	w.(*reopeningFile).lastCheck = time.Now().Add(-fileReopenInterval)
+
+	_, err = w.Write([]byte("bar\n"))
+	require.NoError(err)
+
+	out, err = ioutil.ReadFile(path)
+	require.NoError(err)
+	require.Equal("bar\n", string(out))
+
+	out, err = ioutil.ReadFile(path + ".1")
+	require.NoError(err)
+	require.Equal("foo\n", string(out))
+}
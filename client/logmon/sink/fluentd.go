@@ -0,0 +1,175 @@
+package sink
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-msgpack/codec"
+)
+
+const (
+	// defaultFluentdAddress is the default address of the fluentd forward
+	// input.
+	defaultFluentdAddress = "127.0.0.1:24224"
+
+	// fluentdTimeout is the timeout for connecting and writing to fluentd.
+	fluentdTimeout = 5 * time.Second
+
+	// fluentdRetryInterval is how long the sink waits after a failure
+	// before reconnecting. Output written in the meantime is dropped.
+	fluentdRetryInterval = 5 * time.Second
+)
+
+// fluentdSink forwards each line of a task's output to fluentd using the
+// forward protocol.
+type fluentdSink struct {
+	network string
+	address string
+	tag     string
+	record  map[string]string
+	logger  hclog.Logger
+}
+
+// NewFluentdSink returns a sink forwarding a task's output to fluentd. The
+// "address" option is a host:port or a tcp:// or unix:// URL and defaults to
+// the local forward input. The "tag" option defaults to nomad.<task>.
+func NewFluentdSink(cfg *Config, logger hclog.Logger) (Sink, error) {
+	network, address, err := parseAddress(cfg.Options["address"], "tcp")
+	if err != nil {
+		return nil, err
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, fmt.Errorf("fluentd log sink does not support %q addresses", network)
+	}
+	if address == "" {
+		address = defaultFluentdAddress
+	}
+
+	tag := cfg.Options["tag"]
+	if tag == "" {
+		tag = "nomad." + cfg.TaskName
+	}
+
+	return &fluentdSink{
+		network: network,
+		address: address,
+		tag:     tag,
+		record: map[string]string{
+			"alloc_id": cfg.AllocID,
+			"task":     cfg.TaskName,
+		},
+		logger: logger,
+	}, nil
+}
+
+func (s *fluentdSink) Stream(name string) (io.WriteCloser, error) {
+	record := make(map[string]string, len(s.record)+2)
+	for k, v := range s.record {
+		record[k] = v
+	}
+	record["source"] = name
+
+	f := &fluentdForwarder{
+		sink:   s,
+		record: record,
+	}
+	return newLineWriter(f.forward, f.close), nil
+}
+
+// fluentdForwarder holds the connection to fluentd for a single stream.
+type fluentdForwarder struct {
+	sink   *fluentdSink
+	record map[string]string
+
+	conn      net.Conn
+	buf       *bufio.Writer
+	enc       *codec.Encoder
+	nextRetry time.Time
+}
+
+// msgpackHandle encodes forward protocol messages.
+var msgpackHandle = &codec.MsgpackHandle{
+	WriteExt: true,
+}
+
+func (f *fluentdForwarder) connect() error {
+	if time.Now().Before(f.nextRetry) {
+		return fmt.Errorf("waiting to reconnect to fluentd at %s", f.sink.address)
+	}
+
+	conn, err := net.DialTimeout(f.sink.network, f.sink.address, fluentdTimeout)
+	if err != nil {
+		f.nextRetry = time.Now().Add(fluentdRetryInterval)
+		return fmt.Errorf("failed to connect to fluentd: %v", err)
+	}
+
+	f.conn = conn
+	f.buf = bufio.NewWriter(conn)
+	f.enc = codec.NewEncoder(f.buf, msgpackHandle)
+	return nil
+}
+
+// forward sends a line as a single forward protocol message of the form
+// [tag, time, record].
+func (f *fluentdForwarder) forward(line []byte) error {
+	if f.conn == nil {
+		if err := f.connect(); err != nil {
+			return err
+		}
+	}
+
+	f.record["log"] = string(line)
+	msg := []interface{}{f.sink.tag, time.Now().Unix(), f.record}
+
+	f.conn.SetWriteDeadline(time.Now().Add(fluentdTimeout))
+	err := f.enc.Encode(msg)
+	if err == nil {
+		err = f.buf.Flush()
+	}
+	if err != nil {
+		f.conn.Close()
+		f.conn = nil
+		f.nextRetry = time.Now().Add(fluentdRetryInterval)
+		return fmt.Errorf("failed to forward to fluentd: %v", err)
+	}
+	return nil
+}
+
+func (f *fluentdForwarder) close() error {
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+// parseAddress parses an address that is either a host:port using the
+// default network or a URL of the form tcp://host:port, udp://host:port or
+// unix:///path.
+func parseAddress(addr, defaultNetwork string) (string, string, error) {
+	if addr == "" {
+		return defaultNetwork, "", nil
+	}
+
+	idx := strings.Index(addr, "://")
+	if idx < 0 {
+		return defaultNetwork, addr, nil
+	}
+
+	network, address := addr[:idx], addr[idx+3:]
+	switch network {
+	case "tcp", "udp", "unix", "unixgram":
+	default:
+		return "", "", fmt.Errorf("unsupported network %q in address %q", network, addr)
+	}
+	if address == "" {
+		return "", "", fmt.Errorf("missing address in %q", addr)
+	}
+	return network, address, nil
+}
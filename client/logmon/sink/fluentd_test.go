@@ -0,0 +1,83 @@
+package sink
+
+import (
+	"net"
+	"testing"
+
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFluentdSink(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer l.Close()
+
+	msgs := make(chan []interface{}, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		dec := codec.NewDecoder(conn, &codec.MsgpackHandle{RawToString: true})
+		for {
+			var msg []interface{}
+			if err := dec.Decode(&msg); err != nil {
+				close(msgs)
+				return
+			}
+			msgs <- msg
+		}
+	}()
+
+	cfg := &Config{
+		TaskName: "web",
+		AllocID:  "1234",
+		Options: map[string]string{
+			"address": "tcp://" + l.Addr().String(),
+		},
+	}
+	s, err := New("fluentd", cfg, testlog.HCLogger(t))
+	require.NoError(err)
+
+	w, err := s.Stream(StreamStderr)
+	require.NoError(err)
+
+	_, err = w.Write([]byte("foo\nbar"))
+	require.NoError(err)
+	require.NoError(w.Close())
+
+	for _, line := range []string{"foo", "bar"} {
+		msg := <-msgs
+		require.Len(msg, 3)
+		require.Equal("nomad.web", msg[0])
+
+		record := map[string]string{}
+		for k, v := range msg[2].(map[interface{}]interface{}) {
+			record[k.(string)] = v.(string)
+		}
+		require.Equal(map[string]string{
+			"alloc_id": "1234",
+			"task":     "web",
+			"source":   "stderr",
+			"log":      line,
+		}, record)
+	}
+}
+
+func TestFluentdSink_InvalidAddress(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		TaskName: "web",
+		Options:  map[string]string{"address": "udp://127.0.0.1:24224"},
+	}
+	_, err := New("fluentd", cfg, testlog.HCLogger(t))
+	require.Error(t, err)
+}
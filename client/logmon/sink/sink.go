@@ -0,0 +1,159 @@
+// Package sink provides log sinks which receive the output of tasks in
+// addition to, or instead of, the task's log files. Sinks stream output to
+// external systems such as syslog or fluentd that handle retention and
+// rotation themselves.
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// StreamStdout and StreamStderr are the names of a task's output streams
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+
+	// maxLineSize is the maximum length of a line passed to line oriented
+	// sinks. Longer lines are split.
+	maxLineSize = 16 * 1024
+)
+
+// Config is the configuration of a sink for a task's output.
+type Config struct {
+	// TaskName is the name of the task whose output is sent to the sink
+	TaskName string
+
+	// AllocID is the ID of the allocation the task belongs to
+	AllocID string
+
+	// Options are the sink specific options
+	Options map[string]string
+}
+
+// Sink receives the output streams of a task.
+type Sink interface {
+	// Stream returns a writer for the named output stream of the task,
+	// either StreamStdout or StreamStderr. Closing the writer flushes any
+	// buffered output and releases its resources.
+	Stream(name string) (io.WriteCloser, error)
+}
+
+// Factory returns a new sink for the given configuration.
+type Factory func(cfg *Config, logger hclog.Logger) (Sink, error)
+
+var (
+	factories     = map[string]Factory{}
+	factoriesLock sync.RWMutex
+)
+
+func init() {
+	Register("file", NewFileSink)
+	Register("fluentd", NewFluentdSink)
+}
+
+// Register makes a sink available by name. Registering a name twice replaces
+// the previous factory.
+func Register(name string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	factories[name] = factory
+}
+
+// Names returns the sorted names of the registered sinks.
+func Names() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the named sink.
+func New(name string, cfg *Config, logger hclog.Logger) (Sink, error) {
+	factoriesLock.RLock()
+	factory, ok := factories[name]
+	factoriesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown log sink %q", name)
+	}
+
+	if cfg.Options == nil {
+		cfg.Options = map[string]string{}
+	}
+	return factory(cfg, logger.Named("sink").With("sink", name))
+}
+
+// lineWriter splits the output written to it into lines and passes each line,
+// without its trailing newline, to a line oriented sink. Lines longer than
+// maxLineSize are split.
+type lineWriter struct {
+	buf   bytes.Buffer
+	emit  func(line []byte) error
+	close func() error
+}
+
+func newLineWriter(emit func([]byte) error, close func() error) *lineWriter {
+	return &lineWriter{
+		emit:  emit,
+		close: close,
+	}
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buf.Write(p)
+
+	// Emit every complete line, returning the first error once the buffer
+	// holds only a partial line
+	var err error
+	for {
+		var lerr error
+		data := l.buf.Bytes()
+		idx := bytes.IndexByte(data, '\n')
+		switch {
+		case idx >= 0 && idx <= maxLineSize:
+			lerr = l.emitLine(data[:idx])
+			l.buf.Next(idx + 1)
+		case len(data) >= maxLineSize:
+			lerr = l.emitLine(data[:maxLineSize])
+			l.buf.Next(maxLineSize)
+		default:
+			return len(p), err
+		}
+		if err == nil {
+			err = lerr
+		}
+	}
+}
+
+// emitLine passes a line to the sink, skipping empty lines.
+func (l *lineWriter) emitLine(line []byte) error {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return nil
+	}
+	return l.emit(line)
+}
+
+// Close emits any partial line and closes the sink.
+func (l *lineWriter) Close() error {
+	var err error
+	if l.buf.Len() > 0 {
+		err = l.emitLine(l.buf.Bytes())
+		l.buf.Reset()
+	}
+	if l.close != nil {
+		if cerr := l.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
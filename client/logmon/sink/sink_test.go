@@ -0,0 +1,57 @@
+package sink
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSink_New_Unknown(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	_, err := New("foo", &Config{TaskName: "web"}, testlog.HCLogger(t))
+	require.Error(err)
+	require.Contains(err.Error(), `unknown log sink "foo"`)
+
+	require.Contains(Names(), "file")
+	require.Contains(Names(), "fluentd")
+}
+
+func TestSink_LineWriter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var lines []string
+	closed := false
+	w := newLineWriter(func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	}, func() error {
+		closed = true
+		return nil
+	})
+
+	_, err := w.Write([]byte("foo\nba"))
+	require.NoError(err)
+	require.Equal([]string{"foo"}, lines)
+
+	_, err = w.Write([]byte("r\r\n\nbaz"))
+	require.NoError(err)
+	require.Equal([]string{"foo", "bar"}, lines)
+
+	// Long lines are split
+	long := strings.Repeat("a", maxLineSize+10)
+	_, err = w.Write([]byte("\n" + long + "\n"))
+	require.NoError(err)
+	require.Equal([]string{"foo", "bar", "baz", long[:maxLineSize], long[maxLineSize:]}, lines)
+
+	// Close emits the partial line
+	_, err = w.Write([]byte("end"))
+	require.NoError(err)
+	require.NoError(w.Close())
+	require.Equal("end", lines[len(lines)-1])
+	require.True(closed)
+}
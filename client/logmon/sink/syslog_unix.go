@@ -0,0 +1,102 @@
+// +build !windows
+
+package sink
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"strings"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+func init() {
+	Register("syslog", NewSyslogSink)
+}
+
+// syslogFacilities maps facility names to their syslog values.
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// syslogSink sends each line of a task's output to syslog. Lines written to
+// stderr are logged at the error severity and lines written to stdout at the
+// info severity.
+type syslogSink struct {
+	network  string
+	address  string
+	facility syslog.Priority
+	tag      string
+}
+
+// NewSyslogSink returns a sink sending a task's output to syslog. The
+// "address" option is a tcp://, udp:// or unix:// URL and defaults to the
+// local syslog daemon. The "facility" option defaults to user and the "tag"
+// option to the task's name.
+func NewSyslogSink(cfg *Config, logger hclog.Logger) (Sink, error) {
+	network, address, err := parseAddress(cfg.Options["address"], "udp")
+	if err != nil {
+		return nil, err
+	}
+	if address == "" {
+		network = ""
+	}
+
+	facility := syslog.LOG_USER
+	if name := cfg.Options["facility"]; name != "" {
+		var ok bool
+		facility, ok = syslogFacilities[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", name)
+		}
+	}
+
+	tag := cfg.Options["tag"]
+	if tag == "" {
+		tag = cfg.TaskName
+	}
+
+	return &syslogSink{
+		network:  network,
+		address:  address,
+		facility: facility,
+		tag:      tag,
+	}, nil
+}
+
+func (s *syslogSink) Stream(name string) (io.WriteCloser, error) {
+	severity := syslog.LOG_INFO
+	if name == StreamStderr {
+		severity = syslog.LOG_ERR
+	}
+
+	w, err := syslog.Dial(s.network, s.address, s.facility|severity, s.tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %v", err)
+	}
+
+	return newLineWriter(func(line []byte) error {
+		_, err := w.Write(line)
+		return err
+	}, w.Close), nil
+}
@@ -0,0 +1,68 @@
+// +build !windows
+
+package sink
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSink(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomadtest")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	conn, err := net.ListenPacket("unixgram", filepath.Join(dir, "syslog.sock"))
+	require.NoError(err)
+	defer conn.Close()
+
+	cfg := &Config{
+		TaskName: "web",
+		Options: map[string]string{
+			"address":  "unixgram://" + conn.LocalAddr().String(),
+			"facility": "local0",
+		},
+	}
+	s, err := New("syslog", cfg, testlog.HCLogger(t))
+	require.NoError(err)
+
+	w, err := s.Stream(StreamStderr)
+	require.NoError(err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("hello world\n"))
+	require.NoError(err)
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(err)
+
+	// local0 (16) * 8 + err (3)
+	msg := string(buf[:n])
+	require.True(strings.HasPrefix(msg, "<131>"), msg)
+	require.Contains(msg, "web[")
+	require.True(strings.HasSuffix(strings.TrimSpace(msg), "hello world"), msg)
+}
+
+func TestSyslogSink_InvalidFacility(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		TaskName: "web",
+		Options:  map[string]string{"facility": "foo"},
+	}
+	_, err := New("syslog", cfg, testlog.HCLogger(t))
+	require.Error(t, err)
+}
@@ -23,6 +23,17 @@ const (
 	// driverFPBackoffLimit is the limit of the exponential backoff for fingerprinting
 	// a driver.
 	driverFPBackoffLimit = 2 * time.Minute
+
+	// pluginPingInterval is the interval at which external driver plugins are
+	// checked to be alive.
+	pluginPingInterval = 10 * time.Second
+
+	// pluginPingTimeout is the time a driver plugin has to respond to a ping.
+	pluginPingTimeout = 5 * time.Second
+
+	// pluginPingFailures is the number of consecutive failed pings after
+	// which an unresponsive driver plugin is restarted.
+	pluginPingFailures = 3
 )
 
 // instanceManagerConfig configures a driver instance manager
@@ -53,6 +64,10 @@ type instanceManagerConfig struct {
 
 	// EventHandlerFactory is used to fetch a task event handler
 	EventHandlerFactory TaskEventHandlerFactory
+
+	// PingInterval is the interval at which the plugin is checked to be
+	// alive. Defaults to pluginPingInterval.
+	PingInterval time.Duration
 }
 
 // instanceManager is used to manage a single driver plugin
@@ -107,6 +122,10 @@ type instanceManager struct {
 	// lastHealthState is the last known health fingerprinted by the manager
 	lastHealthState   drivers.HealthState
 	lastHealthStateMu sync.Mutex
+
+	// pingInterval is the interval at which the plugin is checked to be
+	// alive.
+	pingInterval time.Duration
 }
 
 // newInstanceManager returns a new driver instance manager. It is expected that
@@ -127,6 +146,11 @@ func newInstanceManager(c *instanceManagerConfig) *instanceManager {
 		updateNodeFromDriver: c.UpdateNodeFromDriver,
 		eventHandlerFactory:  c.EventHandlerFactory,
 		firstFingerprintCh:   make(chan struct{}),
+		pingInterval:         c.PingInterval,
+	}
+
+	if i.pingInterval == 0 {
+		i.pingInterval = pluginPingInterval
 	}
 
 	go i.run()
@@ -170,6 +194,13 @@ func (i *instanceManager) run() {
 		wg.Done()
 	}()
 
+	// Start the supervisor restarting the plugin if it dies
+	wg.Add(1)
+	go func() {
+		i.supervise()
+		wg.Done()
+	}()
+
 	// Do a final cleanup
 	wg.Wait()
 	i.cleanup()
@@ -252,6 +283,141 @@ func (i *instanceManager) cleanup() {
 	i.cancel()
 }
 
+// supervise checks that an external plugin is alive and restarts it if it
+// exits or stops responding. While the plugin is down the driver is marked
+// unhealthy so no tasks are placed on it. Tasks of the plugin recover onto
+// the restarted plugin.
+func (i *instanceManager) supervise() {
+	i.pluginLock.Lock()
+	internal := i.plugin != nil && i.plugin.Internal()
+	i.pluginLock.Unlock()
+	if internal {
+		return
+	}
+
+	var failures int
+	var backoff time.Duration
+	var retry int
+	for {
+		wait := i.pingInterval
+		if backoff > 0 {
+			wait = backoff
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-i.ctx.Done():
+			return
+		}
+
+		err := i.ping()
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if i.ctx.Err() != nil {
+			return
+		}
+
+		failures++
+		if err != bstructs.ErrPluginShutdown && failures < pluginPingFailures {
+			i.logger.Warn("driver plugin failed to respond", "error", err, "failures", failures)
+			continue
+		}
+
+		i.logger.Error("driver plugin is not alive; restarting", "error", err)
+		i.handlePluginFailure(err)
+
+		if err := i.restart(); err != nil {
+			i.logger.Error("failed to restart driver plugin", "error", err, "retry", retry)
+
+			// Calculate the new backoff
+			backoff = (1 << (2 * uint64(retry))) * driverFPBackoffBaseline
+			if backoff > driverFPBackoffLimit {
+				backoff = driverFPBackoffLimit
+			}
+			retry++
+			continue
+		}
+
+		i.logger.Info("restarted driver plugin")
+
+		// Reset backoff
+		failures = 0
+		backoff = 0
+		retry = 0
+	}
+}
+
+// ping returns ErrPluginShutdown if the plugin has exited or an error if it
+// doesn't respond to a ping.
+func (i *instanceManager) ping() error {
+	i.pluginLock.Lock()
+	pluginInstance, driver := i.plugin, i.driver
+	i.pluginLock.Unlock()
+
+	if pluginInstance == nil || pluginInstance.Exited() {
+		return bstructs.ErrPluginShutdown
+	}
+
+	// Plugins only speaking the driver protocol are alive until they exit
+	pinger, ok := driver.(base.Pinger)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(i.ctx, pluginPingTimeout)
+	defer cancel()
+	return pinger.Ping(ctx)
+}
+
+// restart kills the plugin, if it is still running, and dispenses a new one.
+func (i *instanceManager) restart() error {
+	i.shutdownLock.Lock()
+	defer i.shutdownLock.Unlock()
+
+	if i.ctx.Err() != nil {
+		return i.ctx.Err()
+	}
+
+	i.pluginLock.Lock()
+	if i.plugin != nil {
+		i.plugin.Kill()
+		i.plugin = nil
+		i.driver = nil
+	}
+	i.pluginLock.Unlock()
+
+	// The plugin can't be reattached to anymore
+	if err := i.storeReattach(nil); err != nil {
+		i.logger.Warn("error clearing plugin reattach config from state store", "error", err)
+	}
+
+	_, err := i.dispense()
+	return err
+}
+
+// handlePluginFailure marks the driver unhealthy when its plugin has died or
+// stopped responding.
+func (i *instanceManager) handlePluginFailure(err error) {
+	desc := "driver plugin exited"
+	if err != bstructs.ErrPluginShutdown {
+		desc = fmt.Sprintf("driver plugin is unresponsive: %v", err)
+	}
+
+	di := &structs.DriverInfo{
+		Detected:          true,
+		Healthy:           false,
+		HealthDescription: desc,
+		UpdateTime:        time.Now(),
+	}
+	i.updateNodeFromDriver(i.id.Name, di)
+
+	i.lastHealthStateMu.Lock()
+	i.lastHealthState = drivers.HealthStateUnhealthy
+	i.lastHealthStateMu.Unlock()
+}
+
 // dispenseFingerprintCh dispenses a driver and makes a Fingerprint RPC call
 // to the driver. The fingerprint chan is returned along with the cancel func
 // for the context used in the RPC. This cancel func should always be called
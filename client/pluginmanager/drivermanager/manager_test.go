@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -295,3 +296,144 @@ func TestManager_Run_AllowedBlockedDrivers_Combined(t *testing.T) {
 	mgr.instancesMu.Unlock()
 	require.True(ok)
 }
+
+// pingDriver is a mock driver whose responses to pings are controlled by
+// setPingErr.
+type pingDriver struct {
+	*dtu.MockDriver
+
+	pingErr     error
+	pingErrLock sync.Mutex
+}
+
+func (d *pingDriver) Ping(context.Context) error {
+	d.pingErrLock.Lock()
+	defer d.pingErrLock.Unlock()
+	return d.pingErr
+}
+
+func (d *pingDriver) setPingErr(err error) {
+	d.pingErrLock.Lock()
+	defer d.pingErrLock.Unlock()
+	d.pingErr = err
+}
+
+// testSupervisedInstance returns an instance manager for a pingDriver that
+// counts the plugins dispensed and records the driver infos it reports.
+func testSupervisedInstance(t *testing.T, ctx context.Context) (*instanceManager, *pingDriver, *int32, func() []*structs.DriverInfo) {
+	drv := &pingDriver{
+		MockDriver: mockDriver(make(chan *drivers.Fingerprint), make(chan *drivers.TaskEvent)).(*dtu.MockDriver),
+	}
+
+	var dispensed int32
+	cat := mockCatalog(map[string]drivers.DriverPlugin{"mock": drv})
+	cat.DispenseF = func(name, pluginType string, cfg *base.AgentConfig, logger log.Logger) (loader.PluginInstance, error) {
+		atomic.AddInt32(&dispensed, 1)
+		return loader.MockBasicExternalPlugin(drv, "0.1.0"), nil
+	}
+
+	var lock sync.Mutex
+	var infos []*structs.DriverInfo
+	instance := newInstanceManager(&instanceManagerConfig{
+		Logger:        testlog.HCLogger(t),
+		Ctx:           ctx,
+		Loader:        cat,
+		StoreReattach: func(*plugin.ReattachConfig) error { return nil },
+		FetchReattach: func() (*plugin.ReattachConfig, bool) { return nil, false },
+		PluginConfig:  &base.AgentConfig{},
+		ID:            &loader.PluginID{Name: "mock", PluginType: base.PluginTypeDriver},
+		UpdateNodeFromDriver: func(_ string, info *structs.DriverInfo) {
+			lock.Lock()
+			defer lock.Unlock()
+			infos = append(infos, info)
+		},
+		EventHandlerFactory: noopEventHandlerFactory,
+		PingInterval:        10 * time.Millisecond,
+	})
+
+	testutil.WaitForResult(func() (bool, error) {
+		return atomic.LoadInt32(&dispensed) == 1, fmt.Errorf("plugin not dispensed")
+	}, func(err error) {
+		t.Fatal(err)
+	})
+
+	return instance, drv, &dispensed, func() []*structs.DriverInfo {
+		lock.Lock()
+		defer lock.Unlock()
+		return infos
+	}
+}
+
+func TestInstanceManager_Supervise_Alive(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, dispensed, infos := testSupervisedInstance(t, ctx)
+
+	// The plugin keeps running while it responds
+	time.Sleep(100 * time.Millisecond)
+	require.EqualValues(1, atomic.LoadInt32(dispensed))
+	require.Empty(infos())
+}
+
+func TestInstanceManager_Supervise_Exited(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	instance, _, dispensed, infos := testSupervisedInstance(t, ctx)
+
+	// Crash the plugin
+	instance.pluginLock.Lock()
+	instance.plugin.Kill()
+	instance.pluginLock.Unlock()
+
+	testutil.WaitForResult(func() (bool, error) {
+		return atomic.LoadInt32(dispensed) == 2, fmt.Errorf("plugin not restarted")
+	}, func(err error) {
+		require.NoError(err)
+	})
+
+	// The driver is unhealthy until the restarted plugin fingerprints
+	require.Len(infos(), 1)
+	info := infos()[0]
+	require.True(info.Detected)
+	require.False(info.Healthy)
+	require.Equal("driver plugin exited", info.HealthDescription)
+	require.Equal(drivers.HealthStateUnhealthy, instance.getLastHealth())
+}
+
+func TestInstanceManager_Supervise_Unresponsive(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	instance, drv, dispensed, infos := testSupervisedInstance(t, ctx)
+
+	instance.pluginLock.Lock()
+	hung := instance.plugin
+	instance.pluginLock.Unlock()
+
+	// An unresponsive plugin is killed and restarted after consecutive
+	// failed pings
+	drv.setPingErr(fmt.Errorf("deadline exceeded"))
+	testutil.WaitForResult(func() (bool, error) {
+		return atomic.LoadInt32(dispensed) >= 2, fmt.Errorf("plugin not restarted")
+	}, func(err error) {
+		require.NoError(err)
+	})
+	drv.setPingErr(nil)
+
+	require.True(hung.Exited())
+	require.NotEmpty(infos())
+	info := infos()[0]
+	require.False(info.Healthy)
+	require.Contains(info.HealthDescription, "unresponsive")
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"fmt"
+
+	log "github.com/hashicorp/go-hclog"
+	consulApi "github.com/hashicorp/nomad/client/consul"
+	"github.com/hashicorp/nomad/command/agent/consul"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// serviceRegistrationRPC is the subset of the client used to register
+// services with the servers.
+type serviceRegistrationRPC interface {
+	RPC(method string, args interface{}, reply interface{}) error
+	Region() string
+	Datacenter() string
+	NodeID() string
+	secretNodeID() string
+}
+
+// serviceRegistrationHandler registers services using the nomad provider in
+// the servers' built-in service catalog and passes all other services to
+// Consul.
+type serviceRegistrationHandler struct {
+	consul consulApi.ConsulServiceAPI
+	rpc    serviceRegistrationRPC
+	logger log.Logger
+}
+
+func newServiceRegistrationHandler(logger log.Logger, consul consulApi.ConsulServiceAPI, rpc serviceRegistrationRPC) *serviceRegistrationHandler {
+	return &serviceRegistrationHandler{
+		consul: consul,
+		rpc:    rpc,
+		logger: logger.Named("service_registration"),
+	}
+}
+
+func (h *serviceRegistrationHandler) RegisterTask(task *consul.TaskServices) error {
+	consulTask, nomadTask := splitTaskServices(task)
+	if err := h.consul.RegisterTask(consulTask); err != nil {
+		return err
+	}
+	return h.upsert(nomadTask)
+}
+
+func (h *serviceRegistrationHandler) RemoveTask(task *consul.TaskServices) {
+	consulTask, nomadTask := splitTaskServices(task)
+	h.consul.RemoveTask(consulTask)
+
+	ids := make([]string, 0, len(nomadTask.Services))
+	for _, service := range nomadTask.Services {
+		ids = append(ids, nomadTask.ServiceID(service))
+	}
+	if err := h.delete(ids); err != nil {
+		h.logger.Warn("failed to deregister services", "alloc_id", task.AllocID, "task", task.Name, "error", err)
+	}
+}
+
+func (h *serviceRegistrationHandler) UpdateTask(old, newTask *consul.TaskServices) error {
+	oldConsul, oldNomad := splitTaskServices(old)
+	newConsul, newNomad := splitTaskServices(newTask)
+	if err := h.consul.UpdateTask(oldConsul, newConsul); err != nil {
+		return err
+	}
+
+	// Deregister the services that were removed or changed, which changes
+	// their ID
+	current := make(map[string]struct{}, len(newNomad.Services))
+	for _, service := range newNomad.Services {
+		current[newNomad.ServiceID(service)] = struct{}{}
+	}
+	var stale []string
+	for _, service := range oldNomad.Services {
+		id := oldNomad.ServiceID(service)
+		if _, ok := current[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	if err := h.delete(stale); err != nil {
+		return err
+	}
+
+	return h.upsert(newNomad)
+}
+
+// AllocRegistrations returns the allocation's Consul registrations. Services
+// in the built-in catalog have no checks so they are not included.
+func (h *serviceRegistrationHandler) AllocRegistrations(allocID string) (*consul.AllocRegistration, error) {
+	return h.consul.AllocRegistrations(allocID)
+}
+
+// upsert registers the task's services in the built-in catalog.
+func (h *serviceRegistrationHandler) upsert(task *consul.TaskServices) error {
+	if len(task.Services) == 0 {
+		return nil
+	}
+
+	services := make([]*structs.ServiceRegistration, 0, len(task.Services))
+	for _, service := range task.Services {
+		ip, port, err := task.ServiceAddress(service)
+		if err != nil {
+			return fmt.Errorf("unable to get address for service %q: %v", service.Name, err)
+		}
+
+		services = append(services, &structs.ServiceRegistration{
+			ID:          task.ServiceID(service),
+			ServiceName: service.Name,
+			Namespace:   task.Namespace,
+			NodeID:      h.rpc.NodeID(),
+			Datacenter:  h.rpc.Datacenter(),
+			JobID:       task.JobID,
+			AllocID:     task.AllocID,
+			Task:        task.Name,
+			Tags:        task.ServiceTags(service),
+			Address:     ip,
+			Port:        port,
+		})
+	}
+
+	req := structs.ServiceRegistrationUpsertRequest{
+		Services: services,
+		WriteRequest: structs.WriteRequest{
+			Region:    h.rpc.Region(),
+			AuthToken: h.rpc.secretNodeID(),
+		},
+	}
+	var resp structs.GenericResponse
+	if err := h.rpc.RPC("ServiceRegistration.Upsert", &req, &resp); err != nil {
+		return fmt.Errorf("failed to register services: %v", err)
+	}
+	return nil
+}
+
+// delete deregisters the services with the given IDs from the built-in
+// catalog.
+func (h *serviceRegistrationHandler) delete(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	req := structs.ServiceRegistrationDeleteRequest{
+		NodeID: h.rpc.NodeID(),
+		IDs:    ids,
+		WriteRequest: structs.WriteRequest{
+			Region:    h.rpc.Region(),
+			AuthToken: h.rpc.secretNodeID(),
+		},
+	}
+	var resp structs.GenericResponse
+	if err := h.rpc.RPC("ServiceRegistration.Delete", &req, &resp); err != nil {
+		return fmt.Errorf("failed to deregister services: %v", err)
+	}
+	return nil
+}
+
+// splitTaskServices returns copies of the task services split into the
+// services registered with Consul and those registered in Nomad.
+func splitTaskServices(task *consul.TaskServices) (*consul.TaskServices, *consul.TaskServices) {
+	consulTask, nomadTask := *task, *task
+	consulTask.Services, nomadTask.Services = nil, nil
+	for _, service := range task.Services {
+		if service.UsesNomadProvider() {
+			nomadTask.Services = append(nomadTask.Services, service)
+		} else {
+			consulTask.Services = append(consulTask.Services, service)
+		}
+	}
+	return &consulTask, &nomadTask
+}
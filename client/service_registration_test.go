@@ -0,0 +1,112 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent/consul"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServiceRegistrationRPC records the service registration RPCs.
+type fakeServiceRegistrationRPC struct {
+	upserts []*structs.ServiceRegistrationUpsertRequest
+	deletes []*structs.ServiceRegistrationDeleteRequest
+}
+
+func (f *fakeServiceRegistrationRPC) RPC(method string, args interface{}, reply interface{}) error {
+	switch method {
+	case "ServiceRegistration.Upsert":
+		f.upserts = append(f.upserts, args.(*structs.ServiceRegistrationUpsertRequest))
+	case "ServiceRegistration.Delete":
+		f.deletes = append(f.deletes, args.(*structs.ServiceRegistrationDeleteRequest))
+	}
+	return nil
+}
+
+func (f *fakeServiceRegistrationRPC) Region() string       { return "global" }
+func (f *fakeServiceRegistrationRPC) Datacenter() string   { return "dc1" }
+func (f *fakeServiceRegistrationRPC) NodeID() string       { return "node" }
+func (f *fakeServiceRegistrationRPC) secretNodeID() string { return "secret" }
+
+// fakeConsulServices records the services passed to Consul.
+type fakeConsulServices struct {
+	registered []*structs.Service
+	removed    []*structs.Service
+}
+
+func (f *fakeConsulServices) RegisterTask(task *consul.TaskServices) error {
+	f.registered = append(f.registered, task.Services...)
+	return nil
+}
+
+func (f *fakeConsulServices) RemoveTask(task *consul.TaskServices) {
+	f.removed = append(f.removed, task.Services...)
+}
+
+func (f *fakeConsulServices) UpdateTask(old, newTask *consul.TaskServices) error {
+	f.registered = append(f.registered, newTask.Services...)
+	return nil
+}
+
+func (f *fakeConsulServices) AllocRegistrations(allocID string) (*consul.AllocRegistration, error) {
+	return nil, nil
+}
+
+func TestServiceRegistrationHandler(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	rpc := &fakeServiceRegistrationRPC{}
+	consulServices := &fakeConsulServices{}
+	h := newServiceRegistrationHandler(testlog.HCLogger(t), consulServices, rpc)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Services = []*structs.Service{
+		{
+			Name:      "consul-svc",
+			PortLabel: "http",
+		},
+		{
+			Name:      "nomad-svc",
+			PortLabel: "http",
+			Tags:      []string{"web"},
+			Provider:  structs.ServiceProviderNomad,
+		},
+	}
+	ts := consul.NewTaskServices(alloc, task, nil, nil, nil)
+
+	require.NoError(h.RegisterTask(ts))
+	require.Len(consulServices.registered, 1)
+	require.Equal("consul-svc", consulServices.registered[0].Name)
+
+	require.Len(rpc.upserts, 1)
+	req := rpc.upserts[0]
+	require.Equal("secret", req.AuthToken)
+	require.Len(req.Services, 1)
+	service := req.Services[0]
+	require.Equal(ts.ServiceID(task.Services[1]), service.ID)
+	require.Equal("nomad-svc", service.ServiceName)
+	require.Equal(alloc.Namespace, service.Namespace)
+	require.Equal(alloc.JobID, service.JobID)
+	require.Equal("node", service.NodeID)
+	require.Equal([]string{"web"}, service.Tags)
+	require.Equal("192.168.0.100", service.Address)
+	require.NotZero(service.Port)
+
+	// Changing a service deregisters its old ID
+	update := ts.Copy()
+	update.Services[1].Tags = []string{"api"}
+	require.NoError(h.UpdateTask(ts, update))
+	require.Len(rpc.deletes, 1)
+	require.Equal([]string{service.ID}, rpc.deletes[0].IDs)
+	require.Len(rpc.upserts, 2)
+
+	h.RemoveTask(update)
+	require.Len(consulServices.removed, 1)
+	require.Len(rpc.deletes, 2)
+	require.Equal([]string{update.ServiceID(update.Services[1])}, rpc.deletes[1].IDs)
+}
@@ -0,0 +1,214 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper/boltdd"
+)
+
+const (
+	// compactInterval is how often the state database is checked for space
+	// that can be reclaimed by compacting it.
+	compactInterval = 1 * time.Hour
+
+	// compactMinSize is the size the state database file must reach before
+	// it is compacted. BoltDB never shrinks its file, but small files are
+	// not worth rewriting.
+	compactMinSize = 16 * 1024 * 1024
+)
+
+// errCorruptDB wraps errors that indicate the state database is corrupt.
+type errCorruptDB struct {
+	err error
+}
+
+func (e *errCorruptDB) Error() string {
+	return fmt.Sprintf("state database is corrupt: %v", e.err)
+}
+
+// isCorruptDB returns true if the error opening the state database indicates
+// it is corrupt rather than inaccessible.
+func isCorruptDB(err error) bool {
+	switch err {
+	case bolt.ErrInvalid, bolt.ErrVersionMismatch, bolt.ErrChecksum:
+		return true
+	}
+	_, ok := err.(*errCorruptDB)
+	return ok
+}
+
+// openStateDB opens the state database at the given path and verifies its
+// consistency. A corrupt database is moved aside and replaced by an empty
+// one, in which case the client rebuilds its state from the servers. It
+// returns whether the database was replaced.
+func openStateDB(logger hclog.Logger, path string) (*boltdd.DB, bool, error) {
+	db, err := boltdd.Open(path, 0600, nil)
+	if err == nil {
+		if err = checkDB(db.BoltDB()); err == nil {
+			return db, false, nil
+		}
+		db.Close()
+	}
+
+	if !isCorruptDB(err) {
+		return nil, false, fmt.Errorf("failed to create state database: %v", err)
+	}
+
+	// Keep the corrupt database for inspection, overwriting any previous one
+	corruptPath := path + ".corrupt"
+	logger.Error("state database is corrupt and will be rebuilt from server state",
+		"error", err, "corrupt_path", corruptPath)
+	if err := os.Rename(path, corruptPath); err != nil {
+		return nil, false, fmt.Errorf("failed to move corrupt state database: %v", err)
+	}
+
+	db, err = boltdd.Open(path, 0600, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create state database: %v", err)
+	}
+	return db, true, nil
+}
+
+// checkDB verifies the consistency of the pages of the database.
+func checkDB(bdb *bolt.DB) error {
+	return bdb.View(func(tx *bolt.Tx) error {
+		// The check must be drained for its transaction to be released
+		var first error
+		for err := range tx.Check() {
+			if first == nil {
+				first = &errCorruptDB{err: err}
+			}
+		}
+		return first
+	})
+}
+
+// needsCompaction returns true if the database file is large enough to be
+// compacted and at least half of it is free.
+func needsCompaction(size, inuse int64) bool {
+	return size >= compactMinSize && size-inuse >= size/2
+}
+
+// compactLoop periodically compacts the state database until it is closed.
+func (s *BoltStateDB) compactLoop() {
+	ticker := time.NewTicker(compactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.maybeCompact(); err != nil {
+				s.logger.Warn("failed to compact state database", "error", err)
+			}
+		case <-s.shutdownCh:
+			return
+		}
+	}
+}
+
+// maybeCompact compacts the state database if enough of it is free.
+func (s *BoltStateDB) maybeCompact() error {
+	s.dbLock.RLock()
+	var size, inuse int64
+	err := s.db.BoltDB().View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return tx.ForEach(func(_ []byte, b *bolt.Bucket) error {
+			stats := b.Stats()
+			inuse += int64(stats.BranchAlloc + stats.LeafAlloc)
+			return nil
+		})
+	})
+	s.dbLock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if !needsCompaction(size, inuse) {
+		return nil
+	}
+
+	s.logger.Info("compacting state database", "size_bytes", size, "inuse_bytes", inuse)
+	return s.compact()
+}
+
+// compact rewrites the state database into a new file containing only the
+// live data and swaps it in place of the existing file.
+func (s *BoltStateDB) compact() error {
+	s.dbLock.Lock()
+	defer s.dbLock.Unlock()
+
+	path := s.db.BoltDB().Path()
+	tmpPath := path + ".compact"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+
+	dst, err := bolt.Open(tmpPath, 0600, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted state database: %v", err)
+	}
+
+	err = s.db.BoltDB().View(func(srcTx *bolt.Tx) error {
+		return dst.Update(func(dstTx *bolt.Tx) error {
+			return srcTx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				dstBkt, err := dstTx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(b, dstBkt)
+			})
+		})
+	})
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy state database: %v", err)
+	}
+
+	// Swap the compacted database in place of the existing one
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close state database: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		db, openErr := boltdd.Open(path, 0600, nil)
+		if openErr != nil {
+			return fmt.Errorf("failed to reopen state database: %v", openErr)
+		}
+		s.db = db
+		return fmt.Errorf("failed to replace state database: %v", err)
+	}
+
+	db, err := boltdd.Open(path, 0600, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open compacted state database: %v", err)
+	}
+	s.db = db
+	return nil
+}
+
+// copyBucket recursively copies the keys and nested buckets of src into dst.
+func copyBucket(src, dst *bolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		// Nested buckets have nil values
+		if v != nil {
+			return dst.Put(k, v)
+		}
+
+		dstBkt, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(src.Bucket(k), dstBkt)
+	})
+}
@@ -0,0 +1,112 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBoltStateDB_Compact asserts compacting the database shrinks its file
+// while preserving its contents.
+func TestBoltStateDB_Compact(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	db, cleanup := setupBoltStateDB(t)
+	defer cleanup()
+
+	// Fill the database and delete most of it
+	var allocs []*structs.Allocation
+	for i := 0; i < 200; i++ {
+		alloc := mock.Alloc()
+		require.NoError(db.PutAllocation(alloc))
+		require.NoError(db.PutTaskState(alloc.ID, "web", structs.NewTaskState()))
+		allocs = append(allocs, alloc)
+	}
+	for _, alloc := range allocs[1:] {
+		require.NoError(db.DeleteAllocationBucket(alloc.ID))
+	}
+
+	path := filepath.Join(db.stateDir, "state.db")
+	before, err := os.Stat(path)
+	require.NoError(err)
+
+	require.NoError(db.compact())
+
+	after, err := os.Stat(path)
+	require.NoError(err)
+	require.True(after.Size() < before.Size(), "expected %d < %d", after.Size(), before.Size())
+
+	// The remaining allocation and task state are preserved
+	out, errs, err := db.GetAllAllocations()
+	require.NoError(err)
+	require.Empty(errs)
+	require.Len(out, 1)
+	require.Equal(allocs[0].ID, out[0].ID)
+
+	_, ts, err := db.GetTaskRunnerState(allocs[0].ID, "web")
+	require.NoError(err)
+	require.NotNil(ts)
+
+	// The database is usable after being compacted
+	require.NoError(db.PutAllocation(allocs[1]))
+	out, _, err = db.GetAllAllocations()
+	require.NoError(err)
+	require.Len(out, 2)
+
+	needsUpgrade, err := NeedsUpgrade(db.DB().BoltDB())
+	require.NoError(err)
+	require.False(needsUpgrade)
+}
+
+func TestBoltStateDB_needsCompaction(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.False(needsCompaction(compactMinSize/2, 0))
+	require.False(needsCompaction(compactMinSize, compactMinSize*3/4))
+	require.True(needsCompaction(compactMinSize, compactMinSize/4))
+}
+
+// TestBoltStateDB_Corrupt asserts a corrupt database is moved aside and
+// replaced by an empty one.
+func TestBoltStateDB_Corrupt(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomadtest")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.db")
+	garbage := make([]byte, 16*1024)
+	for i := range garbage {
+		garbage[i] = byte(i)
+	}
+	require.NoError(ioutil.WriteFile(path, garbage, 0600))
+
+	db, err := NewBoltStateDB(testlog.HCLogger(t), dir)
+	require.NoError(err)
+	defer db.Close()
+
+	// The corrupt database is kept for inspection
+	corrupt, err := ioutil.ReadFile(path + ".corrupt")
+	require.NoError(err)
+	require.Equal(garbage, corrupt)
+
+	// The new database is initialized and empty
+	needsUpgrade, err := NeedsUpgrade(db.(*BoltStateDB).DB().BoltDB())
+	require.NoError(err)
+	require.False(needsUpgrade)
+
+	allocs, errs, err := db.GetAllAllocations()
+	require.NoError(err)
+	require.Empty(errs)
+	require.Empty(allocs)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
@@ -106,6 +107,14 @@ type BoltStateDB struct {
 	stateDir string
 	db       *boltdd.DB
 	logger   hclog.Logger
+
+	// dbLock is held for reading by every operation on db and for writing
+	// while db is swapped for a compacted copy.
+	dbLock sync.RWMutex
+
+	// shutdownCh is closed to stop the background compaction.
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
 }
 
 // NewBoltStateDB creates or opens an existing boltdb state file or returns an
@@ -120,25 +129,33 @@ func NewBoltStateDB(logger hclog.Logger, stateDir string) (StateDB, error) {
 	}
 	firstRun := fi == nil
 
-	// Create or open the boltdb state database
-	db, err := boltdd.Open(fn, 0600, nil)
+	// Create or open the boltdb state database, replacing it if it is
+	// corrupt
+	db, rebuilt, err := openStateDB(logger, fn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create state database: %v", err)
+		return nil, err
 	}
 
 	sdb := &BoltStateDB{
-		stateDir: stateDir,
-		db:       db,
-		logger:   logger,
+		stateDir:   stateDir,
+		db:         db,
+		logger:     logger,
+		shutdownCh: make(chan struct{}),
 	}
 
 	// If db did not already exist, initialize metadata fields
-	if firstRun {
+	if firstRun || rebuilt {
 		if err := sdb.init(); err != nil {
 			return nil, err
 		}
 	}
 
+	// Reclaim the space freed since the database was last compacted
+	if err := sdb.maybeCompact(); err != nil {
+		logger.Warn("failed to compact state database", "error", err)
+	}
+	go sdb.compactLoop()
+
 	return sdb, nil
 }
 
@@ -154,7 +171,7 @@ func (s *BoltStateDB) Name() string {
 func (s *BoltStateDB) GetAllAllocations() ([]*structs.Allocation, map[string]error, error) {
 	var allocs []*structs.Allocation
 	var errs map[string]error
-	err := s.db.View(func(tx *boltdd.Tx) error {
+	err := s.view(func(tx *boltdd.Tx) error {
 		allocs, errs = s.getAllAllocations(tx)
 		return nil
 	})
@@ -208,7 +225,7 @@ func (s *BoltStateDB) getAllAllocations(tx *boltdd.Tx) ([]*structs.Allocation, m
 
 // PutAllocation stores an allocation or returns an error.
 func (s *BoltStateDB) PutAllocation(alloc *structs.Allocation) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		// Retrieve the root allocations bucket
 		allocsBkt, err := tx.CreateBucketIfNotExists(allocationsBucketName)
 		if err != nil {
@@ -237,7 +254,7 @@ type deployStatusEntry struct {
 // PutDeploymentStatus stores an allocation's DeploymentStatus or returns an
 // error.
 func (s *BoltStateDB) PutDeploymentStatus(allocID string, ds *structs.AllocDeploymentStatus) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		return putDeploymentStatusImpl(tx, allocID, ds)
 	})
 }
@@ -259,7 +276,7 @@ func putDeploymentStatusImpl(tx *boltdd.Tx, allocID string, ds *structs.AllocDep
 func (s *BoltStateDB) GetDeploymentStatus(allocID string) (*structs.AllocDeploymentStatus, error) {
 	var entry deployStatusEntry
 
-	err := s.db.View(func(tx *boltdd.Tx) error {
+	err := s.view(func(tx *boltdd.Tx) error {
 		allAllocsBkt := tx.Bucket(allocationsBucketName)
 		if allAllocsBkt == nil {
 			// No state, return
@@ -295,7 +312,7 @@ func (s *BoltStateDB) GetTaskRunnerState(allocID, taskName string) (*trstate.Loc
 	var ls *trstate.LocalState
 	var ts *structs.TaskState
 
-	err := s.db.View(func(tx *boltdd.Tx) error {
+	err := s.view(func(tx *boltdd.Tx) error {
 		allAllocsBkt := tx.Bucket(allocationsBucketName)
 		if allAllocsBkt == nil {
 			// No state, return
@@ -348,7 +365,7 @@ func (s *BoltStateDB) GetTaskRunnerState(allocID, taskName string) (*trstate.Loc
 
 // PutTaskRunnerLocalState stores TaskRunner's LocalState or returns an error.
 func (s *BoltStateDB) PutTaskRunnerLocalState(allocID, taskName string, val *trstate.LocalState) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		return putTaskRunnerLocalStateImpl(tx, allocID, taskName, val)
 	})
 }
@@ -370,7 +387,7 @@ func putTaskRunnerLocalStateImpl(tx *boltdd.Tx, allocID, taskName string, val *t
 
 // PutTaskState stores a task's state or returns an error.
 func (s *BoltStateDB) PutTaskState(allocID, taskName string, state *structs.TaskState) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		return putTaskStateImpl(tx, allocID, taskName, state)
 	})
 }
@@ -388,7 +405,7 @@ func putTaskStateImpl(tx *boltdd.Tx, allocID, taskName string, state *structs.Ta
 
 // DeleteTaskBucket is used to delete a task bucket if it exists.
 func (s *BoltStateDB) DeleteTaskBucket(allocID, taskName string) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		// Retrieve the root allocations bucket
 		allocations := tx.Bucket(allocationsBucketName)
 		if allocations == nil {
@@ -409,7 +426,7 @@ func (s *BoltStateDB) DeleteTaskBucket(allocID, taskName string) error {
 
 // DeleteAllocationBucket is used to delete an allocation bucket if it exists.
 func (s *BoltStateDB) DeleteAllocationBucket(allocID string) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		// Retrieve the root allocations bucket
 		allocations := tx.Bucket(allocationsBucketName)
 		if allocations == nil {
@@ -424,9 +441,27 @@ func (s *BoltStateDB) DeleteAllocationBucket(allocID string) error {
 // Close releases all database resources and unlocks the database file on disk.
 // All transactions must be closed before closing the database.
 func (s *BoltStateDB) Close() error {
+	s.shutdownOnce.Do(func() { close(s.shutdownCh) })
+
+	s.dbLock.Lock()
+	defer s.dbLock.Unlock()
 	return s.db.Close()
 }
 
+// view runs fn in a read-only transaction.
+func (s *BoltStateDB) view(fn func(*boltdd.Tx) error) error {
+	s.dbLock.RLock()
+	defer s.dbLock.RUnlock()
+	return s.db.View(fn)
+}
+
+// update runs fn in a read-write transaction.
+func (s *BoltStateDB) update(fn func(*boltdd.Tx) error) error {
+	s.dbLock.RLock()
+	defer s.dbLock.RUnlock()
+	return s.db.Update(fn)
+}
+
 // getAllocationBucket returns the bucket used to persist state about a
 // particular allocation. If the root allocation bucket or the specific
 // allocation bucket doesn't exist, it will be created as long as the
@@ -496,7 +531,7 @@ func getTaskBucket(tx *boltdd.Tx, allocID, taskName string) (*boltdd.Bucket, err
 // PutDevicePluginState stores the device manager's plugin state or returns an
 // error.
 func (s *BoltStateDB) PutDevicePluginState(ps *dmstate.PluginState) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		// Retrieve the root device manager bucket
 		devBkt, err := tx.CreateBucketIfNotExists(devManagerBucket)
 		if err != nil {
@@ -512,7 +547,7 @@ func (s *BoltStateDB) PutDevicePluginState(ps *dmstate.PluginState) error {
 func (s *BoltStateDB) GetDevicePluginState() (*dmstate.PluginState, error) {
 	var ps *dmstate.PluginState
 
-	err := s.db.View(func(tx *boltdd.Tx) error {
+	err := s.view(func(tx *boltdd.Tx) error {
 		devBkt := tx.Bucket(devManagerBucket)
 		if devBkt == nil {
 			// No state, return
@@ -543,7 +578,7 @@ func (s *BoltStateDB) GetDevicePluginState() (*dmstate.PluginState, error) {
 // PutDriverPluginState stores the driver manager's plugin state or returns an
 // error.
 func (s *BoltStateDB) PutDriverPluginState(ps *driverstate.PluginState) error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		// Retrieve the root driver manager bucket
 		driverBkt, err := tx.CreateBucketIfNotExists(driverManagerBucket)
 		if err != nil {
@@ -559,7 +594,7 @@ func (s *BoltStateDB) PutDriverPluginState(ps *driverstate.PluginState) error {
 func (s *BoltStateDB) GetDriverPluginState() (*driverstate.PluginState, error) {
 	var ps *driverstate.PluginState
 
-	err := s.db.View(func(tx *boltdd.Tx) error {
+	err := s.view(func(tx *boltdd.Tx) error {
 		driverBkt := tx.Bucket(driverManagerBucket)
 		if driverBkt == nil {
 			// No state, return
@@ -589,7 +624,7 @@ func (s *BoltStateDB) GetDriverPluginState() (*driverstate.PluginState, error) {
 
 // init initializes metadata entries in a newly created state database.
 func (s *BoltStateDB) init() error {
-	return s.db.Update(func(tx *boltdd.Tx) error {
+	return s.update(func(tx *boltdd.Tx) error {
 		return addMeta(tx.BoltTx())
 	})
 }
@@ -597,6 +632,9 @@ func (s *BoltStateDB) init() error {
 // Upgrade bolt state db from 0.8 schema to 0.9 schema. Noop if already using
 // 0.9 schema. Creates a backup before upgrading.
 func (s *BoltStateDB) Upgrade() error {
+	s.dbLock.RLock()
+	defer s.dbLock.RUnlock()
+
 	// Check to see if the underlying DB needs upgrading.
 	upgrade, err := NeedsUpgrade(s.db.BoltDB())
 	if err != nil {
@@ -640,5 +678,7 @@ func (s *BoltStateDB) Upgrade() error {
 
 // DB allows access to the underlying BoltDB for testing purposes.
 func (s *BoltStateDB) DB() *boltdd.DB {
+	s.dbLock.RLock()
+	defer s.dbLock.RUnlock()
 	return s.db
 }
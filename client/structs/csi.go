@@ -0,0 +1,105 @@
+package structs
+
+import (
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// CSIControllerQuery is used to address the controller plugin of a CSI
+// plugin running on a specific node
+type CSIControllerQuery struct {
+	// ControllerNodeID is the node running the controller plugin
+	ControllerNodeID string
+
+	// PluginID is the plugin to send the request to
+	PluginID string
+}
+
+// ClientCSIControllerAttachVolumeRequest is used to make a volume available
+// on a node by calling ControllerPublishVolume on the controller plugin
+type ClientCSIControllerAttachVolumeRequest struct {
+	// VolumeID is the ID of the volume known to the storage provider
+	VolumeID string
+
+	// ClientCSINodeID is the ID of the node known to the storage provider
+	ClientCSINodeID string
+
+	AttachmentMode structs.CSIVolumeAttachmentMode
+	AccessMode     structs.CSIVolumeAccessMode
+	ReadOnly       bool
+	Secrets        map[string]string
+	VolumeContext  map[string]string
+
+	CSIControllerQuery
+}
+
+// ClientCSIControllerAttachVolumeResponse returns the publish context that
+// is passed to the node plugin when mounting the volume
+type ClientCSIControllerAttachVolumeResponse struct {
+	PublishContext map[string]string
+}
+
+// ClientCSIControllerDetachVolumeRequest is used to detach a volume from a
+// node by calling ControllerUnpublishVolume on the controller plugin
+type ClientCSIControllerDetachVolumeRequest struct {
+	VolumeID        string
+	ClientCSINodeID string
+	Secrets         map[string]string
+
+	CSIControllerQuery
+}
+
+type ClientCSIControllerDetachVolumeResponse struct{}
+
+// ClientCSIControllerCreateVolumeRequest is used to create a volume by
+// calling CreateVolume on the controller plugin
+type ClientCSIControllerCreateVolumeRequest struct {
+	// Name is the name of the volume requested from the storage provider
+	Name string
+
+	CapacityMin    int64
+	CapacityMax    int64
+	AttachmentMode structs.CSIVolumeAttachmentMode
+	AccessMode     structs.CSIVolumeAccessMode
+	Parameters     map[string]string
+	Secrets        map[string]string
+
+	CSIControllerQuery
+}
+
+// ClientCSIControllerCreateVolumeResponse returns the volume created by the
+// storage provider
+type ClientCSIControllerCreateVolumeResponse struct {
+	ExternalVolumeID string
+	CapacityBytes    int64
+	VolumeContext    map[string]string
+}
+
+// ClientCSIControllerDeleteVolumeRequest is used to delete a volume by
+// calling DeleteVolume on the controller plugin
+type ClientCSIControllerDeleteVolumeRequest struct {
+	ExternalVolumeID string
+	Secrets          map[string]string
+
+	CSIControllerQuery
+}
+
+type ClientCSIControllerDeleteVolumeResponse struct{}
+
+// ClientCSINodeDetachVolumeRequest is used to unmount a volume from an
+// allocation on a node by calling the node plugin
+type ClientCSINodeDetachVolumeRequest struct {
+	PluginID string
+	NodeID   string
+	AllocID  string
+
+	// VolumeID is the Nomad ID of the volume and ExternalID its ID known to
+	// the storage provider
+	VolumeID   string
+	ExternalID string
+
+	AttachmentMode structs.CSIVolumeAttachmentMode
+	AccessMode     structs.CSIVolumeAccessMode
+	ReadOnly       bool
+}
+
+type ClientCSINodeDetachVolumeResponse struct{}
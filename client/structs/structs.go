@@ -41,6 +41,10 @@ type AllocFileInfo struct {
 	Size     int64
 	FileMode string
 	ModTime  time.Time
+
+	// Checksum is the checksum of the contents of the file, in the form
+	// "sha256:<hex>". It is only set when requested.
+	Checksum string `json:",omitempty"`
 }
 
 // FsListRequest is used to list an allocation's directory.
@@ -70,6 +74,9 @@ type FsStatRequest struct {
 	// Path is the path to list
 	Path string
 
+	// Checksum requests the checksum of the contents of the file.
+	Checksum bool
+
 	structs.QueryOptions
 }
 
@@ -135,6 +142,18 @@ type FsLogsRequest struct {
 	structs.QueryOptions
 }
 
+// FsArchiveRequest is the initial request for streaming a tar archive of a
+// file or directory.
+type FsArchiveRequest struct {
+	// AllocID is the allocation to archive from
+	AllocID string
+
+	// Path is the path to the file or directory to archive
+	Path string
+
+	structs.QueryOptions
+}
+
 // StreamErrWrapper is used to serialize output of a stream of a file or logs.
 type StreamErrWrapper struct {
 	// Error stores any error that may have occurred.
@@ -219,16 +238,47 @@ func (cs *CpuStats) Add(other *CpuStats) {
 	cs.Measured = joinStringSet(cs.Measured, other.Measured)
 }
 
+// NetworkStats holds network usage related stats. Received and transmitted
+// are from the perspective of the task.
+type NetworkStats struct {
+	RxBytes   uint64
+	TxBytes   uint64
+	RxDropped uint64
+	TxDropped uint64
+
+	// A list of fields whose values were actually sampled
+	Measured []string
+}
+
+func (ns *NetworkStats) Add(other *NetworkStats) {
+	if other == nil {
+		return
+	}
+
+	ns.RxBytes += other.RxBytes
+	ns.TxBytes += other.TxBytes
+	ns.RxDropped += other.RxDropped
+	ns.TxDropped += other.TxDropped
+	ns.Measured = joinStringSet(ns.Measured, other.Measured)
+}
+
 // ResourceUsage holds information related to cpu and memory stats
 type ResourceUsage struct {
-	MemoryStats *MemoryStats
-	CpuStats    *CpuStats
-	DeviceStats []*device.DeviceGroupStats
+	MemoryStats  *MemoryStats
+	CpuStats     *CpuStats
+	NetworkStats *NetworkStats
+	DeviceStats  []*device.DeviceGroupStats
 }
 
 func (ru *ResourceUsage) Add(other *ResourceUsage) {
 	ru.MemoryStats.Add(other.MemoryStats)
 	ru.CpuStats.Add(other.CpuStats)
+	if other.NetworkStats != nil {
+		if ru.NetworkStats == nil {
+			ru.NetworkStats = &NetworkStats{}
+		}
+		ru.NetworkStats.Add(other.NetworkStats)
+	}
 	ru.DeviceStats = append(ru.DeviceStats, other.DeviceStats...)
 }
 
@@ -70,6 +70,27 @@ func RktCompatible(t *testing.T) {
 	}
 }
 
+var podmanExists bool
+var podmanOnce sync.Once
+
+func PodmanCompatible(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Must be on Linux to run test")
+	}
+
+	// else see if podman exists
+	podmanOnce.Do(func() {
+		_, err := exec.Command("podman", "--version").CombinedOutput()
+		if err == nil {
+			podmanExists = true
+		}
+	})
+
+	if !podmanExists {
+		t.Skip("Must have podman installed for podman specific tests to run")
+	}
+}
+
 func MountCompatible(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Windows does not support mount")
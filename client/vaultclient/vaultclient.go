@@ -162,6 +162,9 @@ func NewVaultClient(config *config.VaultConfig, logger hclog.Logger, tokenDerive
 	client.SetHeaders(http.Header{
 		"User-Agent": []string{"hashicorp/nomad"},
 	})
+	if config.Namespace != "" {
+		client.SetNamespace(config.Namespace)
+	}
 
 	c.client = client
 
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type ACLAuthMethodCommand struct {
+	Meta
+}
+
+func (f *ACLAuthMethodCommand) Help() string {
+	helpText := `
+Usage: nomad acl auth-method <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with ACL auth methods. An
+  ACL auth method allows JWTs issued by a trusted identity provider, such as
+  an OIDC provider or a CI system, to be exchanged for Nomad ACL tokens using
+  "nomad login". For a full guide see: https://www.nomadproject.io/guides/acl.html
+
+  Create an ACL auth method:
+
+      $ nomad acl auth-method apply -config=<path> <name>
+
+  List ACL auth methods:
+
+      $ nomad acl auth-method list
+
+  Inspect an ACL auth method:
+
+      $ nomad acl auth-method info <name>
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (f *ACLAuthMethodCommand) Synopsis() string {
+	return "Interact with ACL auth methods"
+}
+
+func (f *ACLAuthMethodCommand) Name() string { return "acl auth-method" }
+
+func (f *ACLAuthMethodCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
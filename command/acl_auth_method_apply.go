@@ -0,0 +1,164 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type ACLAuthMethodApplyCommand struct {
+	Meta
+}
+
+func (c *ACLAuthMethodApplyCommand) Help() string {
+	helpText := `
+Usage: nomad acl auth-method apply [options] <name>
+
+  Apply is used to create or update an ACL auth method. The auth method is
+  replaced with the given options and configuration. Requires a management
+  token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Apply Options:
+
+  -type="JWT"
+    Specifies the type of the auth method, JWT or OIDC.
+
+  -token-locality="local"
+    Specifies whether the tokens created by the auth method are local to the
+    region or global.
+
+  -max-token-ttl="1h"
+    Specifies the lifetime of the tokens created by the auth method.
+
+  -config=""
+    Specifies the path to a JSON file containing the auth method
+    configuration. The ClockSkewLeeway may be given as a duration string.
+    Required.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLAuthMethodApplyCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"type":           complete.PredictSet("JWT", "OIDC"),
+			"token-locality": complete.PredictSet("local", "global"),
+			"max-token-ttl":  complete.PredictAnything,
+			"config":         complete.PredictFiles("*.json"),
+		})
+}
+
+func (c *ACLAuthMethodApplyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ACLAuthMethodApplyCommand) Synopsis() string {
+	return "Create or update an ACL auth method"
+}
+
+func (c *ACLAuthMethodApplyCommand) Name() string { return "acl auth-method apply" }
+
+func (c *ACLAuthMethodApplyCommand) Run(args []string) int {
+	var methodType, locality, configPath string
+	var maxTTL time.Duration
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&methodType, "type", "JWT", "")
+	flags.StringVar(&locality, "token-locality", "local", "")
+	flags.DurationVar(&maxTTL, "max-token-ttl", time.Hour, "")
+	flags.StringVar(&configPath, "config", "", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if configPath == "" {
+		c.Ui.Error("An auth method configuration file must be specified")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Read the configuration
+	raw, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading auth method configuration: %s", err))
+		return 1
+	}
+	config, err := parseAuthMethodConfig(raw)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing auth method configuration: %s", err))
+		return 1
+	}
+
+	// Construct the auth method
+	methodName := args[0]
+	m := &api.ACLAuthMethod{
+		Name:          methodName,
+		Type:          methodType,
+		TokenLocality: locality,
+		MaxTokenTTL:   maxTTL,
+		Config:        config,
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Upsert the auth method
+	_, err = client.ACLAuthMethods().Upsert(m, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing ACL auth method: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully wrote %q ACL auth method!",
+		methodName))
+	return 0
+}
+
+// parseAuthMethodConfig decodes a JSON auth method configuration, allowing
+// the clock skew leeway to be given as a duration string.
+func parseAuthMethodConfig(raw []byte) (*api.ACLAuthMethodConfig, error) {
+	var config struct {
+		*api.ACLAuthMethodConfig
+		ClockSkewLeeway interface{}
+	}
+	config.ACLAuthMethodConfig = &api.ACLAuthMethodConfig{}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+
+	switch v := config.ClockSkewLeeway.(type) {
+	case nil:
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ClockSkewLeeway: %v", err)
+		}
+		config.ACLAuthMethodConfig.ClockSkewLeeway = d
+	case float64:
+		config.ACLAuthMethodConfig.ClockSkewLeeway = time.Duration(v)
+	default:
+		return nil, fmt.Errorf("invalid ClockSkewLeeway %v", v)
+	}
+	return config.ACLAuthMethodConfig, nil
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLAuthMethodApplyCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	defer srv.Shutdown()
+
+	// Bootstrap an initial ACL token
+	token := srv.RootToken
+	require.NotNil(token, "failed to bootstrap ACL token")
+
+	// Write the auth method configuration
+	dir, err := ioutil.TempDir("", "nomad-auth-method")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(ioutil.WriteFile(configPath, []byte(`{
+  "JWKSURL": "https://example.com/.well-known/jwks.json",
+  "BoundAudiences": ["nomad"],
+  "ClaimMappings": {"project": "project"},
+  "ClockSkewLeeway": "30s"
+}`), 0600))
+
+	ui := new(cli.MockUi)
+	cmd := &ACLAuthMethodApplyCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// A configuration is required
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code := cmd.Run([]string{"-address=" + url, "ci"})
+	require.Equal(1, code)
+
+	// Apply an auth method without a valid management token
+	invalidToken := mock.ACLToken()
+	os.Setenv("NOMAD_TOKEN", invalidToken.SecretID)
+	code = cmd.Run([]string{"-address=" + url, "-config=" + configPath, "ci"})
+	require.Equal(1, code)
+
+	// Apply an auth method with a valid management token
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code = cmd.Run([]string{"-address=" + url, "-config=" + configPath, "-max-token-ttl=2h", "ci"})
+	require.Equal(0, code)
+
+	// Check the auth method was written
+	method, err := srv.Agent.Server().State().ACLAuthMethodByName(nil, "ci")
+	require.NoError(err)
+	require.NotNil(method)
+	require.Equal("JWT", method.Type)
+	require.Equal(2*time.Hour, method.MaxTokenTTL)
+	require.Equal(30*time.Second, method.Config.ClockSkewLeeway)
+	require.Equal([]string{"nomad"}, method.Config.BoundAudiences)
+
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, "Successfully wrote"), out)
+}
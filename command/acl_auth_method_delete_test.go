@@ -0,0 +1,52 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLAuthMethodDeleteCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	state := srv.Agent.Server().State()
+	defer srv.Shutdown()
+
+	// Bootstrap an initial ACL token
+	token := srv.RootToken
+	require.NotNil(token, "failed to bootstrap ACL token")
+
+	// Create a test ACLAuthMethod
+	method := mock.ACLAuthMethod()
+	require.NoError(state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method}))
+
+	ui := new(cli.MockUi)
+	cmd := &ACLAuthMethodDeleteCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// Delete the auth method without a valid token fails
+	invalidToken := mock.ACLToken()
+	os.Setenv("NOMAD_TOKEN", invalidToken.SecretID)
+	code := cmd.Run([]string{"-address=" + url, method.Name})
+	require.Equal(1, code)
+
+	// Delete the auth method with a valid management token
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code = cmd.Run([]string{"-address=" + url, method.Name})
+	require.Equal(0, code)
+
+	// Check the output
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, fmt.Sprintf("Successfully deleted %s auth method", method.Name)), out)
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type ACLAuthMethodListCommand struct {
+	Meta
+}
+
+func (c *ACLAuthMethodListCommand) Help() string {
+	helpText := `
+Usage: nomad acl auth-method list
+
+  List is used to list available ACL auth methods.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+List Options:
+
+  -json
+    Output the ACL auth methods in a JSON format.
+
+  -t
+    Format and display the ACL auth methods using a Go template.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLAuthMethodListCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags(FlagSetClient | FlagSetFormat)
+}
+
+func (c *ACLAuthMethodListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ACLAuthMethodListCommand) Synopsis() string {
+	return "List ACL auth methods"
+}
+
+func (c *ACLAuthMethodListCommand) Name() string { return "acl auth-method list" }
+
+func (c *ACLAuthMethodListCommand) Run(args []string) int {
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	args = flags.Args()
+	if l := len(args); l != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Fetch the auth methods
+	methods, _, err := client.ACLAuthMethods().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing ACL auth methods: %s", err))
+		return 1
+	}
+
+	if c.formatted() {
+		return c.outputFormatted(methods)
+	}
+
+	c.Ui.Output(formatAuthMethods(methods))
+	return 0
+}
+
+func formatAuthMethods(methods []*api.ACLAuthMethodListStub) string {
+	if len(methods) == 0 {
+		return "No auth methods found"
+	}
+
+	output := make([]string, 0, len(methods)+1)
+	output = append(output, fmt.Sprintf("Name|Type|Token Locality|Max Token TTL"))
+	for _, m := range methods {
+		output = append(output, fmt.Sprintf("%s|%s|%s|%v",
+			m.Name, m.Type, m.TokenLocality, m.MaxTokenTTL))
+	}
+
+	return formatList(output)
+}
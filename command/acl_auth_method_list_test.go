@@ -0,0 +1,52 @@
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLAuthMethodListCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	state := srv.Agent.Server().State()
+	defer srv.Shutdown()
+
+	// Bootstrap an initial ACL token
+	token := srv.RootToken
+	require.NotNil(token, "failed to bootstrap ACL token")
+
+	// Create a test ACLAuthMethod
+	method := mock.ACLAuthMethod()
+	require.NoError(state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method}))
+
+	ui := new(cli.MockUi)
+	cmd := &ACLAuthMethodListCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// Attempt to list auth methods with a valid management token
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code := cmd.Run([]string{"-address=" + url})
+	require.Equal(0, code)
+
+	// Check the output
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, method.Name), out)
+
+	// List json
+	ui.OutputWriter.Reset()
+	code = cmd.Run([]string{"-address=" + url, "-json"})
+	require.Equal(0, code)
+	out = ui.OutputWriter.String()
+	require.True(strings.Contains(out, "CreateIndex"), out)
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type ACLBindingRuleCommand struct {
+	Meta
+}
+
+func (f *ACLBindingRuleCommand) Help() string {
+	helpText := `
+Usage: nomad acl binding-rule <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with ACL binding rules. An
+  ACL binding rule grants a policy or role to the tokens created by an auth
+  method when the claims of the login token match its selector. For a full
+  guide see: https://www.nomadproject.io/guides/acl.html
+
+  Create an ACL binding rule:
+
+      $ nomad acl binding-rule create -auth-method=<name> \
+          -bind-type=policy -bind-name=<policy>
+
+  List ACL binding rules:
+
+      $ nomad acl binding-rule list
+
+  Inspect an ACL binding rule:
+
+      $ nomad acl binding-rule info <id>
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (f *ACLBindingRuleCommand) Synopsis() string {
+	return "Interact with ACL binding rules"
+}
+
+func (f *ACLBindingRuleCommand) Name() string { return "acl binding-rule" }
+
+func (f *ACLBindingRuleCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
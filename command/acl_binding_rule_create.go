@@ -0,0 +1,122 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type ACLBindingRuleCreateCommand struct {
+	Meta
+}
+
+func (c *ACLBindingRuleCreateCommand) Help() string {
+	helpText := `
+Usage: nomad acl binding-rule create [options]
+
+  Create is used to create a new ACL binding rule for an auth method. Requires
+  a management token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Create Options:
+
+  -auth-method=""
+    Specifies the auth method the binding rule applies to. Required.
+
+  -description=""
+    Specifies a human readable description for the binding rule.
+
+  -selector=""
+    Specifies the expression matched against the mapped claims of the login
+    token, such as 'value.project == "web" and "admins" in list.groups'. The
+    binding rule applies to every login when no selector is given.
+
+  -bind-type="policy"
+    Specifies whether the binding rule grants a "policy" or a "role".
+
+  -bind-name=""
+    Specifies the name of the policy or role to grant. The name may reference
+    mapped claim values, such as "project-${value.project}". Required.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLBindingRuleCreateCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"auth-method": complete.PredictAnything,
+			"description": complete.PredictAnything,
+			"selector":    complete.PredictAnything,
+			"bind-type":   complete.PredictSet("policy", "role"),
+			"bind-name":   complete.PredictAnything,
+		})
+}
+
+func (c *ACLBindingRuleCreateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ACLBindingRuleCreateCommand) Synopsis() string {
+	return "Create a new ACL binding rule"
+}
+
+func (c *ACLBindingRuleCreateCommand) Name() string { return "acl binding-rule create" }
+
+func (c *ACLBindingRuleCreateCommand) Run(args []string) int {
+	var authMethod, description, selector, bindType, bindName string
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&authMethod, "auth-method", "", "")
+	flags.StringVar(&description, "description", "", "")
+	flags.StringVar(&selector, "selector", "", "")
+	flags.StringVar(&bindType, "bind-type", "policy", "")
+	flags.StringVar(&bindName, "bind-name", "", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	args = flags.Args()
+	if l := len(args); l != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if authMethod == "" || bindName == "" {
+		c.Ui.Error("The -auth-method and -bind-name options must be specified")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Construct the binding rule
+	r := &api.ACLBindingRule{
+		Description: description,
+		AuthMethod:  authMethod,
+		Selector:    selector,
+		BindType:    bindType,
+		BindName:    bindName,
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Create the binding rule
+	rule, _, err := client.ACLBindingRules().Create(r, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error creating ACL binding rule: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(formatKVBindingRule(rule))
+	return 0
+}
@@ -0,0 +1,65 @@
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLBindingRuleCreateCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	state := srv.Agent.Server().State()
+	defer srv.Shutdown()
+
+	// Bootstrap an initial ACL token
+	token := srv.RootToken
+	require.NotNil(token, "failed to bootstrap ACL token")
+
+	// Create a test ACLAuthMethod
+	method := mock.ACLAuthMethod()
+	require.NoError(state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method}))
+
+	ui := new(cli.MockUi)
+	cmd := &ACLBindingRuleCreateCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// A bind name is required
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code := cmd.Run([]string{"-address=" + url, "-auth-method=" + method.Name})
+	require.Equal(1, code)
+
+	// Create a binding rule without a valid management token
+	invalidToken := mock.ACLToken()
+	os.Setenv("NOMAD_TOKEN", invalidToken.SecretID)
+	code = cmd.Run([]string{"-address=" + url, "-auth-method=" + method.Name, "-bind-name=web"})
+	require.Equal(1, code)
+
+	// Create a binding rule with a valid management token
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code = cmd.Run([]string{"-address=" + url, "-auth-method=" + method.Name,
+		`-selector=value.project == "web"`, "-bind-type=role", "-bind-name=web-${value.project}"})
+	require.Equal(0, code)
+
+	// Check the binding rule was written
+	iter, err := state.ACLBindingRulesByAuthMethod(nil, method.Name)
+	require.NoError(err)
+	raw := iter.Next()
+	require.NotNil(raw)
+	rule := raw.(*structs.ACLBindingRule)
+	require.Equal(structs.ACLBindingRuleBindTypeRole, rule.BindType)
+	require.Equal("web-${value.project}", rule.BindName)
+
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, rule.ID), out)
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type ACLBindingRuleDeleteCommand struct {
+	Meta
+}
+
+func (c *ACLBindingRuleDeleteCommand) Help() string {
+	helpText := `
+Usage: nomad acl binding-rule delete <id>
+
+  Delete is used to delete an existing ACL binding rule. Requires a management
+  token.
+
+General Options:
+
+  ` + generalOptionsUsage()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLBindingRuleDeleteCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{})
+}
+
+func (c *ACLBindingRuleDeleteCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ACLBindingRuleDeleteCommand) Synopsis() string {
+	return "Delete an existing ACL binding rule"
+}
+
+func (c *ACLBindingRuleDeleteCommand) Name() string { return "acl binding-rule delete" }
+
+func (c *ACLBindingRuleDeleteCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <id>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Get the binding rule ID
+	ruleID := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Delete the binding rule
+	_, err = client.ACLBindingRules().Delete(ruleID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error deleting ACL binding rule: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully deleted %s binding rule!",
+		ruleID))
+	return 0
+}
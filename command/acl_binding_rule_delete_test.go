@@ -0,0 +1,52 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLBindingRuleDeleteCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	state := srv.Agent.Server().State()
+	defer srv.Shutdown()
+
+	// Bootstrap an initial ACL token
+	token := srv.RootToken
+	require.NotNil(token, "failed to bootstrap ACL token")
+
+	// Create a test ACLBindingRule
+	rule := mock.ACLBindingRule()
+	require.NoError(state.UpsertACLBindingRules(1000, []*structs.ACLBindingRule{rule}))
+
+	ui := new(cli.MockUi)
+	cmd := &ACLBindingRuleDeleteCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// Delete the binding rule without a valid token fails
+	invalidToken := mock.ACLToken()
+	os.Setenv("NOMAD_TOKEN", invalidToken.SecretID)
+	code := cmd.Run([]string{"-address=" + url, rule.ID})
+	require.Equal(1, code)
+
+	// Delete the binding rule with a valid management token
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code = cmd.Run([]string{"-address=" + url, rule.ID})
+	require.Equal(0, code)
+
+	// Check the output
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, fmt.Sprintf("Successfully deleted %s binding rule", rule.ID)), out)
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type ACLBindingRuleInfoCommand struct {
+	Meta
+}
+
+func (c *ACLBindingRuleInfoCommand) Help() string {
+	helpText := `
+Usage: nomad acl binding-rule info <id>
+
+  Info is used to fetch information on an existing ACL binding rule.
+
+General Options:
+
+  ` + generalOptionsUsage()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLBindingRuleInfoCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{})
+}
+
+func (c *ACLBindingRuleInfoCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ACLBindingRuleInfoCommand) Synopsis() string {
+	return "Fetch info on an existing ACL binding rule"
+}
+
+func (c *ACLBindingRuleInfoCommand) Name() string { return "acl binding-rule info" }
+
+func (c *ACLBindingRuleInfoCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <id>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Get the binding rule ID
+	ruleID := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Fetch info on the binding rule
+	rule, _, err := client.ACLBindingRules().Info(ruleID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error fetching info on ACL binding rule: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(formatKVBindingRule(rule))
+	return 0
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
 	"github.com/posener/complete"
@@ -87,6 +88,62 @@ func formatKVPolicy(policy *api.ACLPolicy) string {
 	return formatKV(output)
 }
 
+// formatKVRole returns a K/V formatted ACL role
+func formatKVRole(role *api.ACLRole) string {
+	output := []string{
+		fmt.Sprintf("Name|%s", role.Name),
+		fmt.Sprintf("Description|%s", role.Description),
+		fmt.Sprintf("Policies|%s", strings.Join(role.Policies, ",")),
+		fmt.Sprintf("CreateIndex|%v", role.CreateIndex),
+		fmt.Sprintf("ModifyIndex|%v", role.ModifyIndex),
+	}
+	return formatKV(output)
+}
+
+// formatKVAuthMethod returns a K/V formatted ACL auth method
+func formatKVAuthMethod(method *api.ACLAuthMethod) string {
+	output := []string{
+		fmt.Sprintf("Name|%s", method.Name),
+		fmt.Sprintf("Type|%s", method.Type),
+		fmt.Sprintf("Token Locality|%s", method.TokenLocality),
+		fmt.Sprintf("Max Token TTL|%v", method.MaxTokenTTL),
+	}
+	if config := method.Config; config != nil {
+		switch {
+		case len(config.JWTValidationPubKeys) != 0:
+			output = append(output, fmt.Sprintf("Public Keys|%d", len(config.JWTValidationPubKeys)))
+		case config.JWKSURL != "":
+			output = append(output, fmt.Sprintf("JWKS URL|%s", config.JWKSURL))
+		case config.OIDCDiscoveryURL != "":
+			output = append(output, fmt.Sprintf("OIDC Discovery URL|%s", config.OIDCDiscoveryURL))
+		}
+		output = append(output,
+			fmt.Sprintf("Bound Audiences|%s", strings.Join(config.BoundAudiences, ",")),
+			fmt.Sprintf("Bound Issuer|%s", strings.Join(config.BoundIssuer, ",")),
+		)
+	}
+	output = append(output,
+		fmt.Sprintf("CreateIndex|%v", method.CreateIndex),
+		fmt.Sprintf("ModifyIndex|%v", method.ModifyIndex),
+	)
+	return formatKV(output)
+}
+
+// formatKVBindingRule returns a K/V formatted ACL binding rule
+func formatKVBindingRule(rule *api.ACLBindingRule) string {
+	output := []string{
+		fmt.Sprintf("ID|%s", rule.ID),
+		fmt.Sprintf("Description|%s", rule.Description),
+		fmt.Sprintf("Auth Method|%s", rule.AuthMethod),
+		fmt.Sprintf("Selector|%s", rule.Selector),
+		fmt.Sprintf("Bind Type|%s", rule.BindType),
+		fmt.Sprintf("Bind Name|%s", rule.BindName),
+		fmt.Sprintf("CreateIndex|%v", rule.CreateIndex),
+		fmt.Sprintf("ModifyIndex|%v", rule.ModifyIndex),
+	}
+	return formatKV(output)
+}
+
 // formatKVACLToken returns a K/V formatted ACL token
 func formatKVACLToken(token *api.ACLToken) string {
 	// Add the fixed preamble
@@ -104,12 +161,27 @@ func formatKVACLToken(token *api.ACLToken) string {
 	} else {
 		output = append(output, fmt.Sprintf("Policies|%v", token.Policies))
 	}
+	if len(token.Roles) != 0 {
+		output = append(output, fmt.Sprintf("Roles|%v", token.Roles))
+	}
+	if token.AuthMethod != "" {
+		output = append(output, fmt.Sprintf("Auth Method|%s", token.AuthMethod))
+	}
 
 	// Add the generic output
 	output = append(output,
 		fmt.Sprintf("Create Time|%v", token.CreateTime),
+		fmt.Sprintf("Expiry Time|%s", formatACLTokenExpiry(token.ExpirationTime)),
 		fmt.Sprintf("Create Index|%d", token.CreateIndex),
 		fmt.Sprintf("Modify Index|%d", token.ModifyIndex),
 	)
 	return formatKV(output)
 }
+
+// formatACLTokenExpiry returns a printable expiration time for a token
+func formatACLTokenExpiry(expiry *time.Time) string {
+	if expiry == nil {
+		return "<none>"
+	}
+	return expiry.String()
+}
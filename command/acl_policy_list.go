@@ -35,11 +35,7 @@ List Options:
 }
 
 func (c *ACLPolicyListCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
-		complete.Flags{
-			"-json": complete.PredictNothing,
-			"-t":    complete.PredictAnything,
-		})
+	return c.Meta.AutocompleteFlags(FlagSetClient | FlagSetFormat)
 }
 
 func (c *ACLPolicyListCommand) AutocompleteArgs() complete.Predictor {
@@ -53,13 +49,9 @@ func (c *ACLPolicyListCommand) Synopsis() string {
 func (c *ACLPolicyListCommand) Name() string { return "acl policy list" }
 
 func (c *ACLPolicyListCommand) Run(args []string) int {
-	var json bool
-	var tmpl string
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	flags.BoolVar(&json, "json", false, "")
-	flags.StringVar(&tmpl, "t", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -87,15 +79,8 @@ func (c *ACLPolicyListCommand) Run(args []string) int {
 		return 1
 	}
 
-	if json || len(tmpl) > 0 {
-		out, err := Format(json, tmpl, policies)
-		if err != nil {
-			c.Ui.Error(err.Error())
-			return 1
-		}
-
-		c.Ui.Output(out)
-		return 0
+	if c.formatted() {
+		return c.outputFormatted(policies)
 	}
 
 	c.Ui.Output(formatPolicies(policies))
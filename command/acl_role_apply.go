@@ -0,0 +1,109 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type ACLRoleApplyCommand struct {
+	Meta
+}
+
+func (c *ACLRoleApplyCommand) Help() string {
+	helpText := `
+Usage: nomad acl role apply [options] <name>
+
+  Apply is used to create or update an ACL role. The role is replaced with the
+  given description and policies. Requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Apply Options:
+
+  -description=""
+    Specifies a human readable description for the role.
+
+  -policy=""
+    Specifies a policy to associate with the role. Must be specified at least
+    once and can be specified multiple times.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLRoleApplyCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"description": complete.PredictAnything,
+			"policy":      complete.PredictAnything,
+		})
+}
+
+func (c *ACLRoleApplyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ACLRoleApplyCommand) Synopsis() string {
+	return "Create or update an ACL role"
+}
+
+func (c *ACLRoleApplyCommand) Name() string { return "acl role apply" }
+
+func (c *ACLRoleApplyCommand) Run(args []string) int {
+	var description string
+	var policies []string
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&description, "description", "", "")
+	flags.Var((funcVar)(func(s string) error {
+		policies = append(policies, s)
+		return nil
+	}), "policy", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if len(policies) == 0 {
+		c.Ui.Error("At least one policy must be specified")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Construct the role
+	roleName := args[0]
+	r := &api.ACLRole{
+		Name:        roleName,
+		Description: description,
+		Policies:    policies,
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Upsert the role
+	_, err = client.ACLRoles().Upsert(r, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing ACL role: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully wrote %q ACL role!",
+		roleName))
+	return 0
+}
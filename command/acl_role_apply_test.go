@@ -0,0 +1,56 @@
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLRoleApplyCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	defer srv.Shutdown()
+
+	// Bootstrap an initial ACL token
+	token := srv.RootToken
+	require.NotNil(token, "failed to bootstrap ACL token")
+
+	ui := new(cli.MockUi)
+	cmd := &ACLRoleApplyCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// A policy is required
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code := cmd.Run([]string{"-address=" + url, "ops"})
+	require.Equal(1, code)
+
+	// Apply a role without a valid management token
+	invalidToken := mock.ACLToken()
+	os.Setenv("NOMAD_TOKEN", invalidToken.SecretID)
+	code = cmd.Run([]string{"-address=" + url, "-policy=foo", "ops"})
+	require.Equal(1, code)
+
+	// Apply a role with a valid management token
+	os.Setenv("NOMAD_TOKEN", token.SecretID)
+	code = cmd.Run([]string{"-address=" + url, "-description=operators", "-policy=foo", "-policy=bar", "ops"})
+	require.Equal(0, code)
+
+	// Check the role was written
+	role, err := srv.Agent.Server().State().ACLRoleByName(nil, "ops")
+	require.NoError(err)
+	require.NotNil(role)
+	require.Equal([]string{"foo", "bar"}, role.Policies)
+	require.Equal("operators", role.Description)
+
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, "Successfully wrote"), out)
+}
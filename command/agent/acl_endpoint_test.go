@@ -5,9 +5,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTP_ACLPolicyList(t *testing.T) {
@@ -187,6 +189,210 @@ func TestHTTP_ACLPolicyDelete(t *testing.T) {
 	})
 }
 
+func TestHTTP_ACLRoleCRUD(t *testing.T) {
+	t.Parallel()
+	httpACLTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+		state := s.Agent.server.State()
+		policy := mock.ACLPolicy()
+		require.NoError(state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}))
+
+		// Create the role
+		r1 := mock.ACLRole()
+		r1.Policies = []string{policy.Name}
+		req, err := http.NewRequest("PUT", "/v1/acl/role/"+r1.Name, encodeReq(r1))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		setToken(req, s.RootToken)
+
+		obj, err := s.Server.ACLRoleSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Nil(obj)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		out, err := state.ACLRoleByName(nil, r1.Name)
+		require.NoError(err)
+		require.NotNil(out)
+
+		// The name in the body must match the path
+		req, err = http.NewRequest("PUT", "/v1/acl/role/other", encodeReq(r1))
+		require.NoError(err)
+		setToken(req, s.RootToken)
+		_, err = s.Server.ACLRoleSpecificRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+
+		// List the roles
+		req, err = http.NewRequest("GET", "/v1/acl/roles", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		obj, err = s.Server.ACLRolesRequest(respW, req)
+		require.NoError(err)
+		require.Len(obj.([]*structs.ACLRoleListStub), 1)
+
+		// Query the role
+		req, err = http.NewRequest("GET", "/v1/acl/role/"+r1.Name, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		obj, err = s.Server.ACLRoleSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Equal(r1.Name, obj.(*structs.ACLRole).Name)
+
+		// Delete the role
+		req, err = http.NewRequest("DELETE", "/v1/acl/role/"+r1.Name, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		_, err = s.Server.ACLRoleSpecificRequest(respW, req)
+		require.NoError(err)
+
+		out, err = state.ACLRoleByName(nil, r1.Name)
+		require.NoError(err)
+		require.Nil(out)
+
+		// Querying a missing role returns a 404
+		req, err = http.NewRequest("GET", "/v1/acl/role/"+r1.Name, nil)
+		require.NoError(err)
+		setToken(req, s.RootToken)
+		_, err = s.Server.ACLRoleSpecificRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+		require.Contains(err.Error(), "not found")
+	})
+}
+
+func TestHTTP_ACLAuthMethodCRUD(t *testing.T) {
+	t.Parallel()
+	httpACLTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+		state := s.Agent.server.State()
+
+		// Create the auth method
+		m1 := mock.ACLAuthMethod()
+		req, err := http.NewRequest("PUT", "/v1/acl/auth-method/"+m1.Name, encodeReq(m1))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		setToken(req, s.RootToken)
+
+		obj, err := s.Server.ACLAuthMethodSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Nil(obj)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		out, err := state.ACLAuthMethodByName(nil, m1.Name)
+		require.NoError(err)
+		require.NotNil(out)
+
+		// List the auth methods, which does not require a token
+		req, err = http.NewRequest("GET", "/v1/acl/auth-methods", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.ACLAuthMethodsRequest(respW, req)
+		require.NoError(err)
+		require.Len(obj.([]*structs.ACLAuthMethodListStub), 1)
+
+		// Query the auth method
+		req, err = http.NewRequest("GET", "/v1/acl/auth-method/"+m1.Name, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		obj, err = s.Server.ACLAuthMethodSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Equal(m1.Config, obj.(*structs.ACLAuthMethod).Config)
+
+		// Delete the auth method
+		req, err = http.NewRequest("DELETE", "/v1/acl/auth-method/"+m1.Name, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		_, err = s.Server.ACLAuthMethodSpecificRequest(respW, req)
+		require.NoError(err)
+
+		out, err = state.ACLAuthMethodByName(nil, m1.Name)
+		require.NoError(err)
+		require.Nil(out)
+	})
+}
+
+func TestHTTP_ACLBindingRuleCRUD(t *testing.T) {
+	t.Parallel()
+	httpACLTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+		state := s.Agent.server.State()
+		method := mock.ACLAuthMethod()
+		require.NoError(state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method}))
+
+		// Create the binding rule
+		r1 := mock.ACLBindingRule()
+		r1.ID = ""
+		r1.AuthMethod = method.Name
+		req, err := http.NewRequest("PUT", "/v1/acl/binding-rule", encodeReq(r1))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		setToken(req, s.RootToken)
+
+		obj, err := s.Server.ACLBindingRuleSpecificRequest(respW, req)
+		require.NoError(err)
+		created := obj.(*structs.ACLBindingRule)
+		require.NotEmpty(created.ID)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// List the binding rules of the auth method
+		req, err = http.NewRequest("GET", "/v1/acl/binding-rules?auth_method="+method.Name, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		obj, err = s.Server.ACLBindingRulesRequest(respW, req)
+		require.NoError(err)
+		require.Len(obj.([]*structs.ACLBindingRuleListStub), 1)
+
+		// Query the binding rule
+		req, err = http.NewRequest("GET", "/v1/acl/binding-rule/"+created.ID, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		obj, err = s.Server.ACLBindingRuleSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Equal(created.Selector, obj.(*structs.ACLBindingRule).Selector)
+
+		// Delete the binding rule
+		req, err = http.NewRequest("DELETE", "/v1/acl/binding-rule/"+created.ID, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+		_, err = s.Server.ACLBindingRuleSpecificRequest(respW, req)
+		require.NoError(err)
+
+		out, err := state.ACLBindingRuleByID(nil, created.ID)
+		require.NoError(err)
+		require.Nil(out)
+	})
+}
+
+func TestHTTP_ACLLogin(t *testing.T) {
+	t.Parallel()
+	httpACLTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+
+		// Logging in with an unknown auth method fails
+		args := structs.ACLLoginRequest{
+			AuthMethod: "missing",
+			LoginToken: "foo.bar.baz",
+		}
+		req, err := http.NewRequest("PUT", "/v1/acl/login", encodeReq(args))
+		require.NoError(err)
+		_, err = s.Server.ACLLoginRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+		require.Contains(err.Error(), "not found")
+
+		// Only writes are allowed
+		req, err = http.NewRequest("GET", "/v1/acl/login", nil)
+		require.NoError(err)
+		_, err = s.Server.ACLLoginRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+	})
+}
+
 func TestHTTP_ACLTokenBootstrap(t *testing.T) {
 	t.Parallel()
 	conf := func(c *Config) {
@@ -371,6 +577,29 @@ func TestHTTP_ACLTokenSelf(t *testing.T) {
 	})
 }
 
+func TestHTTP_ACLTokenSelfCapabilities(t *testing.T) {
+	t.Parallel()
+	httpACLTest(t, nil, func(s *TestAgent) {
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/acl/token/self/capabilities", nil)
+		require.NoError(t, err)
+		respW := httptest.NewRecorder()
+		setToken(req, s.RootToken)
+
+		// Make the request
+		obj, err := s.Server.ACLTokenSpecificRequest(respW, req)
+		require.NoError(t, err)
+
+		// Check for the index
+		require.NotEmpty(t, respW.HeaderMap.Get("X-Nomad-Index"))
+		require.Equal(t, "true", respW.HeaderMap.Get("X-Nomad-KnownLeader"))
+
+		// Check the output
+		out := obj.(*acl.Capabilities)
+		require.True(t, out.Management)
+	})
+}
+
 func TestHTTP_ACLTokenCreate(t *testing.T) {
 	t.Parallel()
 	httpACLTest(t, nil, func(s *TestAgent) {
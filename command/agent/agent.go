@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -21,9 +23,13 @@ import (
 	uuidparse "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/nomad/client"
 	clientconfig "github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/lib/cgutil"
 	"github.com/hashicorp/nomad/client/state"
+	"github.com/hashicorp/nomad/command/agent/audit"
 	"github.com/hashicorp/nomad/command/agent/consul"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/pluginutils/loader"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -42,6 +48,10 @@ const (
 	// roles used in identifying Consul entries for Nomad agents
 	consulRoleServer = "server"
 	consulRoleClient = "client"
+
+	// defaultDrainOnShutdownDeadline is the drain deadline used when
+	// drain_on_shutdown does not set one
+	defaultDrainOnShutdownDeadline = 1 * time.Hour
 )
 
 // Agent is a long running daemon that is used to run both
@@ -83,6 +93,14 @@ type Agent struct {
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
 
+	// auditor writes the audit events of HTTP API requests. It is disabled
+	// unless audit logging is enabled in the configuration.
+	auditor *audit.Auditor
+
+	// decisionLog is the sink the schedulers of the server record their
+	// placement decisions to, if enabled.
+	decisionLog io.WriteCloser
+
 	InmemSink *metrics.InmemSink
 }
 
@@ -107,6 +125,12 @@ func NewAgent(config *Config, logOutput io.Writer, inmem *metrics.InmemSink) (*A
 	// Global logger should match internal logger as much as possible
 	golog.SetFlags(golog.LstdFlags | golog.Lmicroseconds)
 
+	auditor, err := audit.NewAuditor(a.logger, config.Audit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize audit logging: %v", err)
+	}
+	a.auditor = auditor
+
 	if err := a.setupConsul(config.Consul); err != nil {
 		return nil, fmt.Errorf("Failed to initialize Consul client: %v", err)
 	}
@@ -200,6 +224,15 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	if agentConfig.ACL.ReplicationToken != "" {
 		conf.ReplicationToken = agentConfig.ACL.ReplicationToken
 	}
+	if agentConfig.ACL.TokenMinExpirationTTL != 0 {
+		conf.ACLTokenMinExpirationTTL = agentConfig.ACL.TokenMinExpirationTTL
+	}
+	if agentConfig.ACL.TokenMaxExpirationTTL != 0 {
+		conf.ACLTokenMaxExpirationTTL = agentConfig.ACL.TokenMaxExpirationTTL
+	}
+	if conf.ACLTokenMinExpirationTTL > conf.ACLTokenMaxExpirationTTL {
+		return nil, fmt.Errorf("acl token_min_expiration_ttl must not be greater than token_max_expiration_ttl")
+	}
 	if agentConfig.Sentinel != nil {
 		conf.SentinelConfig = agentConfig.Sentinel
 	}
@@ -212,6 +245,67 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	if agentConfig.Server.UpgradeVersion != "" {
 		conf.UpgradeVersion = agentConfig.Server.UpgradeVersion
 	}
+	if key := agentConfig.Server.VariablesEncryptionKey; key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode variables_encryption_key: %v", err)
+		}
+		if len(decoded) != nomad.VariablesEncryptionKeyLen {
+			return nil, fmt.Errorf("variables_encryption_key must be %d bytes; got %d", nomad.VariablesEncryptionKeyLen, len(decoded))
+		}
+		conf.VariablesEncryptionKey = decoded
+	} else if agentConfig.DevMode {
+		// Dev agents are a single server so a random key can be used
+		conf.VariablesEncryptionKey = make([]byte, nomad.VariablesEncryptionKeyLen)
+		if _, err := rand.Read(conf.VariablesEncryptionKey); err != nil {
+			return nil, fmt.Errorf("Failed to generate variables encryption key: %v", err)
+		}
+	}
+	if agentConfig.Server.EnableEventBroker != nil {
+		conf.EnableEventBroker = *agentConfig.Server.EnableEventBroker
+	}
+	if size := agentConfig.Server.EventBufferSize; size != nil {
+		if *size < 0 {
+			return nil, fmt.Errorf("event_buffer_size must be non-negative; got %d", *size)
+		}
+		conf.EventBufferSize = *size
+	}
+	if limit := agentConfig.Server.JobRateLimit; limit != nil {
+		conf.JobNamespaceRateLimit = limit.NamespaceRate
+		conf.JobNamespaceRateBurst = limit.NamespaceBurst
+		conf.JobTokenRateLimit = limit.TokenRate
+		conf.JobTokenRateBurst = limit.TokenBurst
+	}
+	if webhook := agentConfig.Server.DeploymentWebhook; webhook != nil {
+		conf.DeploymentWebhookURL = webhook.URL
+		conf.DeploymentWebhookHMACKey = webhook.HMACKey
+		conf.DeploymentWebhookTimeout = webhook.Timeout
+	}
+	for _, w := range agentConfig.Server.JobAdmissionWebhooks {
+		webhook := &nomad.JobAdmissionWebhook{
+			Name:          w.Name,
+			URL:           w.URL,
+			HMACKey:       w.HMACKey,
+			Mutate:        w.Mutate,
+			IgnoreFailure: w.IgnoreFailure,
+		}
+		if w.Timeout != "" {
+			dur, err := time.ParseDuration(w.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing job admission webhook %q timeout: %s", w.Name, err)
+			}
+			webhook.Timeout = dur
+		}
+		conf.JobAdmissionWebhooks = append(conf.JobAdmissionWebhooks, webhook)
+	}
+	for _, r := range agentConfig.Server.JobAdmissionRules {
+		conf.JobAdmissionRules = append(conf.JobAdmissionRules, &nomad.JobAdmissionRule{
+			Name:             r.Name,
+			Condition:        r.Condition,
+			Message:          r.Message,
+			EnforcementLevel: r.EnforcementLevel,
+		})
+	}
 	if agentConfig.Autopilot != nil {
 		if agentConfig.Autopilot.CleanupDeadServers != nil {
 			conf.AutopilotConfig.CleanupDeadServers = *agentConfig.Autopilot.CleanupDeadServers
@@ -272,6 +366,17 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	conf.ClientRPCAdvertise = rpcAddr
 	conf.ServerRPCAdvertise = serverAddr
 
+	if segments := agentConfig.AdvertiseAddrs.RPCSegments; len(segments) > 0 {
+		conf.ClientRPCAdvertiseSegments = make(map[string]*net.TCPAddr, len(segments))
+		for segment, addr := range segments {
+			segmentAddr, err := net.ResolveTCPAddr("tcp", addr)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse RPC advertise address %q of segment %q: %v", addr, segment, err)
+			}
+			conf.ClientRPCAdvertiseSegments[segment] = segmentAddr
+		}
+	}
+
 	// Set up gc threshold and heartbeat grace period
 	if gcThreshold := agentConfig.Server.NodeGCThreshold; gcThreshold != "" {
 		dur, err := time.ParseDuration(gcThreshold)
@@ -305,6 +410,9 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	if heartbeatGrace := agentConfig.Server.HeartbeatGrace; heartbeatGrace != 0 {
 		conf.HeartbeatGrace = heartbeatGrace
 	}
+	if missedGrace := agentConfig.Server.MissedHeartbeatGrace; missedGrace != 0 {
+		conf.MissedHeartbeatGrace = missedGrace
+	}
 	if min := agentConfig.Server.MinHeartbeatTTL; min != 0 {
 		conf.MinHeartbeatTTL = min
 	}
@@ -319,6 +427,10 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	// Add the Consul and Vault configs
 	conf.ConsulConfig = agentConfig.Consul
 	conf.VaultConfig = agentConfig.Vault
+	conf.VaultClusters = agentConfig.VaultClusters
+	if err := config.ValidateVaultClusters(conf.VaultClusters); err != nil {
+		return nil, err
+	}
 
 	// Set the TLS config
 	conf.TLSConfig = agentConfig.TLSConfig
@@ -329,6 +441,25 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	conf.DisableDispatchedJobSummaryMetrics = agentConfig.Telemetry.DisableDispatchedJobSummaryMetrics
 	conf.BackwardsCompatibleMetrics = agentConfig.Telemetry.BackwardsCompatibleMetrics
 
+	// Setup the export of traces
+	if telemetry := agentConfig.Telemetry; telemetry.OTLPEndpoint != "" {
+		sampleRate := 1.0
+		if telemetry.TraceSampleRate != nil {
+			sampleRate = *telemetry.TraceSampleRate
+		}
+		conf.TracingConfig = &tracing.Config{
+			Endpoint:    telemetry.OTLPEndpoint,
+			Headers:     telemetry.OTLPHeaders,
+			SampleRate:  sampleRate,
+			ServiceName: "nomad",
+			ResourceAttributes: map[string]string{
+				"nomad.node.name":  conf.NodeName,
+				"nomad.region":     conf.Region,
+				"nomad.datacenter": conf.Datacenter,
+			},
+		}
+	}
+
 	return conf, nil
 }
 
@@ -446,10 +577,13 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 		conf.NetworkInterface = agentConfig.Client.NetworkInterface
 	}
 	conf.ChrootEnv = agentConfig.Client.ChrootEnv
+	conf.ChrootCache = agentConfig.Client.ChrootCache
+	conf.EphemeralDiskQuota = agentConfig.Client.EphemeralDiskQuota
 	conf.Options = agentConfig.Client.Options
 	if agentConfig.Client.NetworkSpeed != 0 {
 		conf.NetworkSpeed = agentConfig.Client.NetworkSpeed
 	}
+	conf.NetworkSegment = agentConfig.Client.NetworkSegment
 	if agentConfig.Client.CpuCompute != 0 {
 		conf.CpuCompute = agentConfig.Client.CpuCompute
 	}
@@ -466,6 +600,46 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	conf.ClientMaxPort = uint(agentConfig.Client.ClientMaxPort)
 	conf.ClientMinPort = uint(agentConfig.Client.ClientMinPort)
 
+	if drain := agentConfig.Client.DrainOnShutdown; drain != nil {
+		deadline := defaultDrainOnShutdownDeadline
+		if drain.Deadline != "" {
+			dur, err := time.ParseDuration(drain.Deadline)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing drain on shutdown deadline: %s", err)
+			}
+			if dur <= 0 {
+				return nil, fmt.Errorf("Drain on shutdown deadline must be positive")
+			}
+			deadline = dur
+		}
+		conf.DrainOnShutdown = &structs.DrainSpec{
+			Deadline:         deadline,
+			IgnoreSystemJobs: drain.IgnoreSystemJobs,
+		}
+	}
+
+	for _, h := range agentConfig.Client.AllocHooks {
+		hook := &clientconfig.AllocHookConfig{
+			Name:      h.Name,
+			Event:     h.Event,
+			Command:   h.Command,
+			Args:      helper.CopySliceString(h.Args),
+			OnFailure: h.OnFailure,
+		}
+		if h.Timeout != "" {
+			dur, err := time.ParseDuration(h.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing alloc hook %q timeout: %s", h.Name, err)
+			}
+			hook.Timeout = dur
+		}
+		if err := hook.Validate(); err != nil {
+			return nil, fmt.Errorf("Invalid alloc hook %q: %v", h.Name, err)
+		}
+		hook.Canonicalize()
+		conf.AllocHooks = append(conf.AllocHooks, hook)
+	}
+
 	// Setup the node
 	conf.Node = new(structs.Node)
 	conf.Node.Datacenter = agentConfig.Datacenter
@@ -497,6 +671,13 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	res.Disk.DiskMB = int64(agentConfig.Client.Reserved.DiskMB)
 	res.Networks.ReservedHostPorts = agentConfig.Client.Reserved.ReservedPorts
 
+	// Restrict tasks to the cores not reserved for the host
+	taskCpuset, err := cgutil.TaskCpuset(agentConfig.Client.Reserved.Cores)
+	if err != nil {
+		return nil, fmt.Errorf("error reserving cores %q: %v", agentConfig.Client.Reserved.Cores, err)
+	}
+	conf.TaskCpuset = taskCpuset
+
 	conf.Version = agentConfig.Version
 
 	if *agentConfig.Consul.AutoAdvertise && agentConfig.Consul.ClientServiceName == "" {
@@ -505,11 +686,17 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 
 	conf.ConsulConfig = agentConfig.Consul
 	conf.VaultConfig = agentConfig.Vault
+	conf.VaultClusters = agentConfig.VaultClusters
+	if err := config.ValidateVaultClusters(conf.VaultClusters); err != nil {
+		return nil, err
+	}
 
 	// Set up Telemetry configuration
 	conf.StatsCollectionInterval = agentConfig.Telemetry.collectionInterval
 	conf.PublishNodeMetrics = agentConfig.Telemetry.PublishNodeMetrics
 	conf.PublishAllocationMetrics = agentConfig.Telemetry.PublishAllocationMetrics
+	conf.AllocationMetricsLabels = agentConfig.Telemetry.AllocationMetricsLabels
+	conf.AllocationMetricsLimit = agentConfig.Telemetry.AllocationMetricsLimit
 	conf.DisableTaggedMetrics = agentConfig.Telemetry.DisableTaggedMetrics
 	conf.BackwardsCompatibleMetrics = agentConfig.Telemetry.BackwardsCompatibleMetrics
 
@@ -561,9 +748,22 @@ func (a *Agent) setupServer() error {
 		return fmt.Errorf("failed to configure keyring: %v", err)
 	}
 
+	// Open the sink of the placement decisions
+	if dl := a.config.Server.DecisionLog; dl != nil {
+		sink, err := audit.OpenSink(dl.Sink())
+		if err != nil {
+			return fmt.Errorf("failed to open decision log: %v", err)
+		}
+		conf.DecisionLogOutput = sink
+		a.decisionLog = sink
+	}
+
 	// Create the server
 	server, err := nomad.NewServer(conf, a.consulCatalog)
 	if err != nil {
+		if a.decisionLog != nil {
+			a.decisionLog.Close()
+		}
 		return fmt.Errorf("server setup failed: %v", err)
 	}
 	a.server = server
@@ -884,11 +1084,20 @@ func (a *Agent) Shutdown() error {
 			a.logger.Error("server shutdown failed", "error", err)
 		}
 	}
+	if a.decisionLog != nil {
+		if err := a.decisionLog.Close(); err != nil {
+			a.logger.Error("closing decision log failed", "error", err)
+		}
+	}
 
 	if err := a.consulService.Shutdown(); err != nil {
 		a.logger.Error("shutting down Consul client failed", "error", err)
 	}
 
+	if err := a.auditor.Close(); err != nil {
+		a.logger.Error("shutting down audit logging failed", "error", err)
+	}
+
 	a.logger.Info("shutdown complete")
 	a.shutdown = true
 	close(a.shutdownCh)
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"encoding/base64"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/structs"
 	sconfig "github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/stretchr/testify/assert"
@@ -46,6 +48,7 @@ func TestAgent_ServerConfig(t *testing.T) {
 	conf.AdvertiseAddrs.Serf = "127.0.0.1:4000"
 	conf.AdvertiseAddrs.RPC = "127.0.0.1:4001"
 	conf.AdvertiseAddrs.HTTP = "10.10.11.1:4005"
+	conf.AdvertiseAddrs.RPCSegments = map[string]string{"dmz": "10.10.12.1"}
 	conf.ACL.Enabled = true
 
 	// Parses the advertise addrs correctly
@@ -70,6 +73,9 @@ func TestAgent_ServerConfig(t *testing.T) {
 	if !out.ACLEnabled {
 		t.Fatalf("ACL not enabled")
 	}
+	if addr := out.ClientRPCAdvertiseSegments["dmz"]; addr == nil || addr.String() != "10.10.12.1:4647" {
+		t.Fatalf("expected dmz segment address 10.10.12.1:4647, got: %v", addr)
+	}
 
 	// Assert addresses weren't changed
 	if addr := conf.AdvertiseAddrs.RPC; addr != "127.0.0.1:4001" {
@@ -182,6 +188,12 @@ func TestAgent_ServerConfig(t *testing.T) {
 		t.Fatalf("expect 37s, got: %s", threshold)
 	}
 
+	conf.Server.MissedHeartbeatGrace = 41 * time.Second
+	out, err = a.serverConfig()
+	if grace := out.MissedHeartbeatGrace; grace != time.Second*41 {
+		t.Fatalf("expect 41s, got: %s", grace)
+	}
+
 	conf.Server.MinHeartbeatTTL = 37 * time.Second
 	out, err = a.serverConfig()
 	if min := out.MinHeartbeatTTL; min != time.Second*37 {
@@ -262,6 +274,62 @@ func TestAgent_ServerConfig(t *testing.T) {
 	}
 }
 
+func TestAgent_ServerConfig_Tracing(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	conf := DefaultConfig()
+	conf.Server.Enabled = true
+	require.NoError(conf.normalizeAddrs())
+	out, err := convertServerConfig(conf)
+	require.NoError(err)
+	require.Nil(out.TracingConfig)
+
+	conf.Telemetry.OTLPEndpoint = "http://127.0.0.1:4318"
+	out, err = convertServerConfig(conf)
+	require.NoError(err)
+	require.NotNil(out.TracingConfig)
+	require.Equal("http://127.0.0.1:4318", out.TracingConfig.Endpoint)
+	require.Equal(1.0, out.TracingConfig.SampleRate)
+	require.Equal("nomad", out.TracingConfig.ServiceName)
+	require.Equal(conf.Region, out.TracingConfig.ResourceAttributes["nomad.region"])
+
+	rate := 0.1
+	conf.Telemetry.TraceSampleRate = &rate
+	out, err = convertServerConfig(conf)
+	require.NoError(err)
+	require.Equal(0.1, out.TracingConfig.SampleRate)
+}
+
+func TestAgent_ServerConfig_VariablesEncryptionKey(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	conf := DefaultConfig()
+	conf.Server.Enabled = true
+	require.NoError(conf.normalizeAddrs())
+
+	// Variables are disabled without a key
+	out, err := convertServerConfig(conf)
+	require.NoError(err)
+	require.Nil(out.VariablesEncryptionKey)
+
+	// Dev agents generate a key
+	conf.DevMode = true
+	out, err = convertServerConfig(conf)
+	require.NoError(err)
+	require.Len(out.VariablesEncryptionKey, nomad.VariablesEncryptionKeyLen)
+
+	key := make([]byte, nomad.VariablesEncryptionKeyLen)
+	conf.Server.VariablesEncryptionKey = base64.StdEncoding.EncodeToString(key)
+	out, err = convertServerConfig(conf)
+	require.NoError(err)
+	require.Equal(key, out.VariablesEncryptionKey)
+
+	conf.Server.VariablesEncryptionKey = base64.StdEncoding.EncodeToString(key[:16])
+	_, err = convertServerConfig(conf)
+	require.Error(err)
+}
+
 func TestAgent_ClientConfig(t *testing.T) {
 	t.Parallel()
 	conf := DefaultConfig()
@@ -306,6 +374,39 @@ func TestAgent_ClientConfig(t *testing.T) {
 	}
 }
 
+func TestAgent_ClientConfig_DrainOnShutdown(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	conf := DefaultConfig()
+	conf.DevMode = true
+	conf.Client.Enabled = true
+	a := &Agent{config: conf}
+
+	c, err := a.clientConfig()
+	require.NoError(err)
+	require.Nil(c.DrainOnShutdown)
+
+	// Deadline defaults when unset
+	conf.Client.DrainOnShutdown = &DrainOnShutdown{IgnoreSystemJobs: true}
+	c, err = a.clientConfig()
+	require.NoError(err)
+	require.Equal(&structs.DrainSpec{
+		Deadline:         defaultDrainOnShutdownDeadline,
+		IgnoreSystemJobs: true,
+	}, c.DrainOnShutdown)
+
+	conf.Client.DrainOnShutdown = &DrainOnShutdown{Deadline: "15m"}
+	c, err = a.clientConfig()
+	require.NoError(err)
+	require.Equal(15*time.Minute, c.DrainOnShutdown.Deadline)
+
+	// Deadlines must be positive
+	conf.Client.DrainOnShutdown = &DrainOnShutdown{Deadline: "-1s"}
+	_, err = a.clientConfig()
+	require.Error(err)
+}
+
 // Clients should inherit telemetry configuration
 func TestAget_Client_TelemetryConfiguration(t *testing.T) {
 	assert := assert.New(t)
@@ -1066,3 +1167,26 @@ func TestAgent_ProxyRPC_Dev(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 }
+
+func TestAgent_DecisionLog(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "decisions.json")
+	agent := NewTestAgent(t, t.Name(), func(c *Config) {
+		c.Server.DecisionLog = &DecisionLog{
+			Type: "file",
+			Path: path,
+		}
+	})
+	defer agent.Shutdown()
+
+	// The sink is opened along with the server
+	require.NotNil(agent.decisionLog)
+	_, err = os.Stat(path)
+	require.NoError(err)
+}
@@ -2,7 +2,9 @@ package agent
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/golang/snappy"
@@ -20,11 +22,22 @@ func (s *HTTPServer) AllocsRequest(resp http.ResponseWriter, req *http.Request)
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	args := structs.AllocListRequest{}
+	args := structs.AllocListRequest{
+		ClientStatus: req.URL.Query().Get("status"),
+	}
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
 	}
 
+	// Include the allocated resources if requested
+	if raw := req.URL.Query().Get("resources"); raw != "" {
+		resources, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, CodedError(400, "resources must be a boolean value")
+		}
+		args.Fields = &structs.AllocStubFields{Resources: resources}
+	}
+
 	var out structs.AllocListResponse
 	if err := s.agent.RPC("Alloc.List", &args, &out); err != nil {
 		return nil, err
@@ -41,7 +54,31 @@ func (s *HTTPServer) AllocsRequest(resp http.ResponseWriter, req *http.Request)
 }
 
 func (s *HTTPServer) AllocSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
-	allocID := strings.TrimPrefix(req.URL.Path, "/v1/allocation/")
+	reqSuffix := strings.TrimPrefix(req.URL.Path, "/v1/allocation/")
+
+	// tokenize the suffix of the path to get the alloc id and find the action
+	// invoked on the alloc id
+	tokens := strings.Split(reqSuffix, "/")
+	if len(tokens) > 2 {
+		return nil, CodedError(404, resourceNotFoundErr)
+	}
+	allocID := tokens[0]
+
+	if len(tokens) == 1 {
+		return s.allocGet(allocID, resp, req)
+	}
+
+	switch tokens[1] {
+	case "stop":
+		return s.allocStop(allocID, resp, req)
+	case "reschedule":
+		return s.allocReschedule(allocID, resp, req)
+	}
+
+	return nil, CodedError(404, resourceNotFoundErr)
+}
+
+func (s *HTTPServer) allocGet(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if req.Method != "GET" {
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
@@ -78,6 +115,76 @@ func (s *HTTPServer) AllocSpecificRequest(resp http.ResponseWriter, req *http.Re
 	return alloc, nil
 }
 
+func (s *HTTPServer) allocStop(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if !(req.Method == "POST" || req.Method == "PUT") {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.AllocStopRequest{
+		AllocID: allocID,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.AllocStopResponse
+	if err := s.agent.RPC("Alloc.Stop", &args, &out); err != nil {
+		if structs.IsErrUnknownAllocation(err) {
+			return nil, CodedError(404, err.Error())
+		}
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return &out, nil
+}
+
+// allocReschedule returns when the allocation is rescheduled on GET, and
+// forces it to be rescheduled immediately on PUT and POST.
+func (s *HTTPServer) allocReschedule(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		return s.allocRescheduleStatus(allocID, resp, req)
+	case "PUT", "POST":
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.AllocRescheduleRequest{
+		AllocID: allocID,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.AllocRescheduleResponse
+	if err := s.agent.RPC("Alloc.Reschedule", &args, &out); err != nil {
+		if structs.IsErrUnknownAllocation(err) {
+			return nil, CodedError(404, err.Error())
+		}
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return &out, nil
+}
+
+func (s *HTTPServer) allocRescheduleStatus(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	args := structs.AllocSpecificRequest{
+		AllocID: allocID,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.SingleAllocResponse
+	if err := s.agent.RPC("Alloc.GetAlloc", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Alloc == nil {
+		return nil, CodedError(404, "alloc not found")
+	}
+	return out.Alloc.RescheduleStatus(), nil
+}
+
 func (s *HTTPServer) ClientAllocRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 
 	reqSuffix := strings.TrimPrefix(req.URL.Path, "/v1/client/allocation/")
@@ -100,6 +207,10 @@ func (s *HTTPServer) ClientAllocRequest(resp http.ResponseWriter, req *http.Requ
 		return s.allocSnapshot(allocID, resp, req)
 	case "gc":
 		return s.allocGC(allocID, resp, req)
+	case "restart":
+		return s.allocRestart(allocID, resp, req)
+	case "signal":
+		return s.allocSignal(allocID, resp, req)
 	}
 
 	return nil, CodedError(404, resourceNotFoundErr)
@@ -172,6 +283,102 @@ func (s *HTTPServer) allocGC(allocID string, resp http.ResponseWriter, req *http
 	return nil, rpcErr
 }
 
+func (s *HTTPServer) allocRestart(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if !(req.Method == "POST" || req.Method == "PUT") {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	// The body is optional, and all the tasks are restarted if it doesn't
+	// name one. It is decoded separately so that it can't override the
+	// allocation or the ACL token.
+	var body struct {
+		TaskName string
+	}
+	if err := decodeBody(req, &body); err != nil && err != io.EOF {
+		return nil, CodedError(400, err.Error())
+	}
+
+	// Build the request and parse the ACL token
+	args := structs.AllocRestartRequest{
+		AllocID:  allocID,
+		TaskName: body.TaskName,
+	}
+	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
+
+	// Determine the handler to use
+	useLocalClient, useClientRPC, useServerRPC := s.rpcHandlerForAlloc(allocID)
+
+	// Make the RPC
+	var reply structs.GenericResponse
+	var rpcErr error
+	if useLocalClient {
+		rpcErr = s.agent.Client().ClientRPC("Allocations.Restart", &args, &reply)
+	} else if useClientRPC {
+		rpcErr = s.agent.Client().RPC("ClientAllocations.Restart", &args, &reply)
+	} else if useServerRPC {
+		rpcErr = s.agent.Server().RPC("ClientAllocations.Restart", &args, &reply)
+	} else {
+		rpcErr = CodedError(400, "No local Node and node_id not provided")
+	}
+
+	if rpcErr != nil {
+		if structs.IsErrNoNodeConn(rpcErr) || structs.IsErrUnknownAllocation(rpcErr) {
+			rpcErr = CodedError(404, rpcErr.Error())
+		}
+	}
+
+	return reply, rpcErr
+}
+
+func (s *HTTPServer) allocSignal(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if !(req.Method == "POST" || req.Method == "PUT") {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var body struct {
+		Task   string
+		Signal string
+	}
+	if err := decodeBody(req, &body); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if body.Signal == "" {
+		return nil, CodedError(400, "Signal must be specified")
+	}
+
+	// Build the request and parse the ACL token
+	args := structs.AllocSignalRequest{
+		AllocID: allocID,
+		Task:    body.Task,
+		Signal:  body.Signal,
+	}
+	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
+
+	// Determine the handler to use
+	useLocalClient, useClientRPC, useServerRPC := s.rpcHandlerForAlloc(allocID)
+
+	// Make the RPC
+	var reply structs.GenericResponse
+	var rpcErr error
+	if useLocalClient {
+		rpcErr = s.agent.Client().ClientRPC("Allocations.Signal", &args, &reply)
+	} else if useClientRPC {
+		rpcErr = s.agent.Client().RPC("ClientAllocations.Signal", &args, &reply)
+	} else if useServerRPC {
+		rpcErr = s.agent.Server().RPC("ClientAllocations.Signal", &args, &reply)
+	} else {
+		rpcErr = CodedError(400, "No local Node and node_id not provided")
+	}
+
+	if rpcErr != nil {
+		if structs.IsErrNoNodeConn(rpcErr) || structs.IsErrUnknownAllocation(rpcErr) {
+			rpcErr = CodedError(404, rpcErr.Error())
+		}
+	}
+
+	return reply, rpcErr
+}
+
 func (s *HTTPServer) allocSnapshot(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	var secret string
 	s.parseToken(req, &secret)
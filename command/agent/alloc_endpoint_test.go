@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/snappy"
 	"github.com/hashicorp/nomad/acl"
@@ -85,6 +86,33 @@ func TestHTTP_AllocsList(t *testing.T) {
 	})
 }
 
+func TestHTTP_AllocsList_Resources(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		state := s.Agent.server.State()
+		alloc := mock.Alloc()
+		require.NoError(t, state.UpsertJobSummary(998, mock.JobSummary(alloc.JobID)))
+		require.NoError(t, state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+		// Resources are only included if requested
+		req, err := http.NewRequest("GET", "/v1/allocations", nil)
+		require.NoError(t, err)
+		obj, err := s.Server.AllocsRequest(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		allocs := obj.([]*structs.AllocListStub)
+		require.Len(t, allocs, 1)
+		require.Nil(t, allocs[0].AllocatedResources)
+
+		req, err = http.NewRequest("GET", "/v1/allocations?resources=true", nil)
+		require.NoError(t, err)
+		obj, err = s.Server.AllocsRequest(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		allocs = obj.([]*structs.AllocListStub)
+		require.Len(t, allocs, 1)
+		require.Equal(t, alloc.AllocatedResources, allocs[0].AllocatedResources)
+	})
+}
+
 func TestHTTP_AllocsPrefixList(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -669,6 +697,193 @@ func TestHTTP_AllocGC_ACL(t *testing.T) {
 	})
 }
 
+func TestHTTP_AllocRestart(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	path := fmt.Sprintf("/v1/client/allocation/%s/restart", uuid.Generate())
+	httpTest(t, nil, func(s *TestAgent) {
+		// Only writes are allowed
+		{
+			req, err := http.NewRequest("GET", path, nil)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			require.NotNil(err)
+			require.Equal(405, err.(HTTPCodedError).Code())
+		}
+
+		// Local node, unknown alloc
+		{
+			body := encodeReq(map[string]string{"TaskName": "web"})
+			req, err := http.NewRequest("PUT", path, body)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			require.NotNil(err)
+			require.True(structs.IsErrUnknownAllocation(err))
+			require.Equal(404, err.(HTTPCodedError).Code())
+		}
+
+		// The body is optional
+		{
+			req, err := http.NewRequest("PUT", path, http.NoBody)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			require.NotNil(err)
+			require.True(structs.IsErrUnknownAllocation(err))
+		}
+	})
+}
+
+func TestHTTP_AllocSignal(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	path := fmt.Sprintf("/v1/client/allocation/%s/signal", uuid.Generate())
+	httpTest(t, nil, func(s *TestAgent) {
+		// The signal is required
+		{
+			body := encodeReq(map[string]string{"Task": "web"})
+			req, err := http.NewRequest("PUT", path, body)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			require.NotNil(err)
+			require.Equal(400, err.(HTTPCodedError).Code())
+		}
+
+		// Local node, unknown alloc
+		{
+			body := encodeReq(map[string]string{"Task": "web", "Signal": "SIGHUP"})
+			req, err := http.NewRequest("PUT", path, body)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			require.NotNil(err)
+			require.True(structs.IsErrUnknownAllocation(err))
+			require.Equal(404, err.(HTTPCodedError).Code())
+		}
+	})
+}
+
+func TestHTTP_AllocStop(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		alloc := mock.Alloc()
+		require.Nil(state.UpsertJobSummary(999, mock.JobSummary(alloc.JobID)))
+		require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+		// Only writes are allowed
+		{
+			req, err := http.NewRequest("GET", "/v1/allocation/"+alloc.ID+"/stop", nil)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.AllocSpecificRequest(respW, req)
+			require.NotNil(err)
+			require.Equal(405, err.(HTTPCodedError).Code())
+		}
+
+		// Unknown alloc
+		{
+			req, err := http.NewRequest("PUT", "/v1/allocation/"+uuid.Generate()+"/stop", nil)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.AllocSpecificRequest(respW, req)
+			require.NotNil(err)
+			require.Equal(404, err.(HTTPCodedError).Code())
+		}
+
+		// Stop the alloc
+		req, err := http.NewRequest("PUT", "/v1/allocation/"+alloc.ID+"/stop", nil)
+		require.Nil(err)
+
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.AllocSpecificRequest(respW, req)
+		require.Nil(err)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		resp := obj.(*structs.AllocStopResponse)
+		require.NotEmpty(resp.EvalID)
+
+		out, err := state.AllocByID(nil, alloc.ID)
+		require.Nil(err)
+		require.True(out.DesiredTransition.ShouldMigrate())
+	})
+}
+
+func TestHTTP_AllocReschedule(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		alloc := mock.Alloc()
+		require.Nil(state.UpsertJobSummary(999, mock.JobSummary(alloc.JobID)))
+		require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+		failed := alloc.Copy()
+		failed.ClientStatus = structs.AllocClientStatusFailed
+		failed.TaskStates = map[string]*structs.TaskState{
+			"web": {State: structs.TaskStateDead, FinishedAt: time.Now()},
+		}
+		require.Nil(state.UpdateAllocsFromClient(1001, []*structs.Allocation{failed}))
+
+		// Unknown alloc
+		{
+			req, err := http.NewRequest("PUT", "/v1/allocation/"+uuid.Generate()+"/reschedule", nil)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			_, err = s.Server.AllocSpecificRequest(respW, req)
+			require.NotNil(err)
+			require.Equal(404, err.(HTTPCodedError).Code())
+		}
+
+		// Read when the alloc is rescheduled
+		{
+			req, err := http.NewRequest("GET", "/v1/allocation/"+alloc.ID+"/reschedule", nil)
+			require.Nil(err)
+
+			respW := httptest.NewRecorder()
+			obj, err := s.Server.AllocSpecificRequest(respW, req)
+			require.Nil(err)
+			require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+			status := obj.(*structs.AllocRescheduleStatus)
+			require.Equal(alloc.ID, status.AllocID)
+			require.True(status.Eligible)
+			require.False(status.NextRescheduleTime.IsZero())
+			require.False(status.ForceReschedule)
+		}
+
+		// Reschedule the alloc
+		req, err := http.NewRequest("PUT", "/v1/allocation/"+alloc.ID+"/reschedule", nil)
+		require.Nil(err)
+
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.AllocSpecificRequest(respW, req)
+		require.Nil(err)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		resp := obj.(*structs.AllocRescheduleResponse)
+		require.NotEmpty(resp.EvalID)
+
+		out, err := state.AllocByID(nil, alloc.ID)
+		require.Nil(err)
+		require.True(out.DesiredTransition.ShouldForceReschedule())
+	})
+}
+
 func TestHTTP_AllocAllGC(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -0,0 +1,292 @@
+// Package audit implements the audit logging of the HTTP API requests handled
+// by a Nomad agent. Each request produces an event when it is received and
+// another one once it completes, which are written as JSON to the configured
+// sinks unless they are excluded by a filter.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	glob "github.com/ryanuber/go-glob"
+)
+
+const (
+	// EventVersion is the version of the format of the events.
+	EventVersion = 1
+
+	// EventType is the type of the events of HTTP requests.
+	EventType = "audit"
+
+	// FilterTypeHTTPEvent is the only supported type of filters.
+	FilterTypeHTTPEvent = "HTTPEvent"
+
+	// DeliveryEnforced fails requests whose events can't be written.
+	DeliveryEnforced = "enforced"
+
+	// DeliveryBestEffort logs the failures to write events.
+	DeliveryBestEffort = "best-effort"
+
+	// FormatJSON writes events as newline delimited JSON.
+	FormatJSON = "json"
+
+	// SinkTypeFile writes events to a file.
+	SinkTypeFile = "file"
+
+	// SinkTypeSocket sends events to a socket.
+	SinkTypeSocket = "socket"
+)
+
+// Stage is the stage of a request an event is emitted at.
+type Stage string
+
+const (
+	// OperationReceived is the stage of requests before they are handled.
+	OperationReceived Stage = "OperationReceived"
+
+	// OperationComplete is the stage of requests once they are handled.
+	OperationComplete Stage = "OperationComplete"
+)
+
+// Event is an audit event of an HTTP request.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Stage     Stage     `json:"stage"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   int       `json:"version"`
+	Auth      *Auth     `json:"auth,omitempty"`
+	Request   *Request  `json:"request"`
+	Response  *Response `json:"response,omitempty"`
+}
+
+// Auth is the actor of a request, from the ACL token it was made with.
+type Auth struct {
+	AccessorID string    `json:"accessor_id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Policies   []string  `json:"policies"`
+	Global     bool      `json:"global"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+// Request is the request an event is about.
+type Request struct {
+	ID         string   `json:"id"`
+	Operation  string   `json:"operation"`
+	Endpoint   string   `json:"endpoint"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Region     string   `json:"region,omitempty"`
+	RemoteAddr string   `json:"remote_addr"`
+	UserAgent  string   `json:"user_agent,omitempty"`
+	Payload    *Payload `json:"payload,omitempty"`
+}
+
+// Payload summarizes the body of a request without recording its contents,
+// since it may hold secrets.
+type Payload struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Response is the result of a request.
+type Response struct {
+	StatusCode int           `json:"status_code"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// Auditor writes the events of requests to sinks.
+type Auditor struct {
+	logger  log.Logger
+	enabled bool
+	sinks   []*sink
+	filters []*config.AuditFilter
+
+	// l serializes writes so that events are not interleaved
+	l sync.Mutex
+}
+
+// NewAuditor returns an auditor for the given configuration, opening its
+// sinks. A disabled auditor doesn't open any sink and drops all events.
+func NewAuditor(logger log.Logger, conf *config.AuditConfig) (*Auditor, error) {
+	a := &Auditor{
+		logger: logger.Named("audit"),
+	}
+	if conf == nil || conf.Enabled == nil || !*conf.Enabled {
+		return a, nil
+	}
+
+	if err := validate(conf); err != nil {
+		return nil, err
+	}
+
+	for _, sc := range conf.Sinks {
+		s, err := newSink(sc)
+		if err != nil {
+			a.Close()
+			return nil, fmt.Errorf("failed to open audit sink %q: %v", sc.Name, err)
+		}
+		a.sinks = append(a.sinks, s)
+	}
+	a.filters = conf.Filters
+	a.enabled = true
+	return a, nil
+}
+
+// validate returns an error if the configuration of an enabled auditor is
+// invalid.
+func validate(conf *config.AuditConfig) error {
+	var mErr multierror.Error
+	if len(conf.Sinks) == 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("at least one sink is required"))
+	}
+
+	names := make(map[string]struct{}, len(conf.Sinks))
+	for _, s := range conf.Sinks {
+		if _, ok := names[s.Name]; ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("duplicate sink %q", s.Name))
+		}
+		names[s.Name] = struct{}{}
+
+		switch s.DeliveryGuarantee {
+		case "", DeliveryEnforced, DeliveryBestEffort:
+		default:
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: invalid delivery guarantee %q", s.Name, s.DeliveryGuarantee))
+		}
+		switch s.Format {
+		case "", FormatJSON:
+		default:
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: invalid format %q", s.Name, s.Format))
+		}
+
+		switch s.Type {
+		case SinkTypeFile:
+			if s.Path == "" {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: path is required", s.Name))
+			}
+			if s.RotateBytes < 0 || s.RotateDuration < 0 || s.RotateMaxFiles < 0 {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: rotation settings must not be negative", s.Name))
+			}
+		case SinkTypeSocket:
+			if s.Address == "" {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: address is required", s.Name))
+			}
+			switch s.SocketType {
+			case "", "tcp", "udp", "unix":
+			default:
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: invalid socket type %q", s.Name, s.SocketType))
+			}
+		default:
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: invalid type %q", s.Name, s.Type))
+		}
+	}
+
+	for _, f := range conf.Filters {
+		if f.Type != FilterTypeHTTPEvent {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("filter %q: invalid type %q", f.Name, f.Type))
+		}
+		for _, stage := range f.Stages {
+			switch Stage(stage) {
+			case "*", OperationReceived, OperationComplete:
+			default:
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("filter %q: invalid stage %q", f.Name, stage))
+			}
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// Enabled returns whether the auditor writes events.
+func (a *Auditor) Enabled() bool {
+	return a != nil && a.enabled
+}
+
+// Event writes the event to all sinks unless it is filtered. An error is only
+// returned if a sink with an enforced delivery guarantee failed, in which case
+// the request should not be served.
+func (a *Auditor) Event(e *Event) error {
+	if !a.Enabled() || a.filtered(e) {
+		return nil
+	}
+	defer metrics.MeasureSince([]string{"nomad", "audit", "event"}, time.Now())
+
+	e.Type = EventType
+	e.Version = EventVersion
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %v", err)
+	}
+	buf = append(buf, '\n')
+
+	a.l.Lock()
+	defer a.l.Unlock()
+
+	var mErr multierror.Error
+	for _, s := range a.sinks {
+		if _, err := s.Write(buf); err != nil {
+			metrics.IncrCounter([]string{"nomad", "audit", "failed"}, 1)
+			a.logger.Error("failed to write audit event", "sink", s.name, "error", err)
+			if s.enforced {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("sink %q: %v", s.name, err))
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// filtered returns whether the event matches any filter.
+func (a *Auditor) filtered(e *Event) bool {
+	for _, f := range a.filters {
+		if matchAny(f.Stages, string(e.Stage), false) &&
+			matchAny(f.Operations, e.Request.Operation, true) &&
+			matchAny(f.Endpoints, e.Request.Endpoint, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny returns whether the value matches any of the glob patterns. An
+// empty list of patterns matches everything.
+func matchAny(patterns []string, value string, fold bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if fold {
+			p, value = strings.ToUpper(p), strings.ToUpper(value)
+		}
+		if glob.Glob(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes all sinks.
+func (a *Auditor) Close() error {
+	if a == nil {
+		return nil
+	}
+
+	a.l.Lock()
+	defer a.l.Unlock()
+
+	var mErr multierror.Error
+	for _, s := range a.sinks {
+		if err := s.Close(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	a.enabled = false
+	return mErr.ErrorOrNil()
+}
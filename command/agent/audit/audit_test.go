@@ -0,0 +1,270 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(stage Stage, method, endpoint string) *Event {
+	return &Event{
+		ID:        "foo",
+		Stage:     stage,
+		Timestamp: time.Now(),
+		Request: &Request{
+			ID:        "foo",
+			Operation: method,
+			Endpoint:  endpoint,
+		},
+	}
+}
+
+func readEvents(t *testing.T, path string) []*Event {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []*Event
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		events = append(events, &e)
+	}
+	require.NoError(t, s.Err())
+	return events
+}
+
+func TestAuditor_Disabled(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	a, err := NewAuditor(testlog.HCLogger(t), config.DefaultAuditConfig())
+	require.NoError(err)
+	require.False(a.Enabled())
+	require.NoError(a.Event(testEvent(OperationReceived, "GET", "/v1/jobs")))
+	require.NoError(a.Close())
+}
+
+func TestAuditor_FileSink_Filters(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-audit")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit", "audit.log")
+
+	a, err := NewAuditor(testlog.HCLogger(t), &config.AuditConfig{
+		Enabled: helper.BoolToPtr(true),
+		Sinks: []*config.AuditSink{
+			{Name: "file", Type: SinkTypeFile, Path: path},
+		},
+		Filters: []*config.AuditFilter{
+			{
+				Name:       "metrics",
+				Type:       FilterTypeHTTPEvent,
+				Endpoints:  []string{"/v1/metrics"},
+				Stages:     []string{"*"},
+				Operations: []string{"*"},
+			},
+			{
+				Name:       "reads",
+				Type:       FilterTypeHTTPEvent,
+				Endpoints:  []string{"/v1/job/*"},
+				Stages:     []string{string(OperationReceived)},
+				Operations: []string{"get"},
+			},
+		},
+	})
+	require.NoError(err)
+	defer a.Close()
+	require.True(a.Enabled())
+
+	require.NoError(a.Event(testEvent(OperationReceived, "GET", "/v1/metrics")))
+	require.NoError(a.Event(testEvent(OperationReceived, "GET", "/v1/job/example")))
+	require.NoError(a.Event(testEvent(OperationComplete, "GET", "/v1/job/example")))
+	require.NoError(a.Event(testEvent(OperationReceived, "PUT", "/v1/job/example")))
+
+	events := readEvents(t, path)
+	require.Len(events, 2)
+	require.Equal(OperationComplete, events[0].Stage)
+	require.Equal("GET", events[0].Request.Operation)
+	require.Equal(OperationReceived, events[1].Stage)
+	require.Equal("PUT", events[1].Request.Operation)
+	require.Equal(EventType, events[1].Type)
+	require.Equal(EventVersion, events[1].Version)
+}
+
+func TestAuditor_FileSink_Rotate(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-audit")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	a, err := NewAuditor(testlog.HCLogger(t), &config.AuditConfig{
+		Enabled: helper.BoolToPtr(true),
+		Sinks: []*config.AuditSink{
+			{
+				Name:           "file",
+				Type:           SinkTypeFile,
+				Path:           path,
+				RotateBytes:    10,
+				RotateMaxFiles: 2,
+			},
+		},
+	})
+	require.NoError(err)
+	defer a.Close()
+
+	// Every event is larger than the rotation size so each is written to
+	// its own file
+	for i := 0; i < 5; i++ {
+		require.NoError(a.Event(testEvent(OperationReceived, "GET", "/v1/jobs")))
+	}
+
+	require.Len(readEvents(t, path), 1)
+	rotated, err := filepath.Glob(filepath.Join(dir, "audit-*.log"))
+	require.NoError(err)
+	require.Len(rotated, 2)
+}
+
+func TestAuditor_SocketSink(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer l.Close()
+
+	received := make(chan *Event, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var e Event
+		if err := json.NewDecoder(conn).Decode(&e); err == nil {
+			received <- &e
+		}
+	}()
+
+	a, err := NewAuditor(testlog.HCLogger(t), &config.AuditConfig{
+		Enabled: helper.BoolToPtr(true),
+		Sinks: []*config.AuditSink{
+			{Name: "socket", Type: SinkTypeSocket, Address: l.Addr().String()},
+		},
+	})
+	require.NoError(err)
+	defer a.Close()
+
+	require.NoError(a.Event(testEvent(OperationReceived, "DELETE", "/v1/job/example")))
+	select {
+	case e := <-received:
+		require.Equal("DELETE", e.Request.Operation)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for event")
+	}
+}
+
+func TestAuditor_DeliveryGuarantee(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Find an address nothing listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	addr := l.Addr().String()
+	l.Close()
+
+	for _, guarantee := range []string{DeliveryEnforced, DeliveryBestEffort} {
+		a, err := NewAuditor(testlog.HCLogger(t), &config.AuditConfig{
+			Enabled: helper.BoolToPtr(true),
+			Sinks: []*config.AuditSink{
+				{
+					Name:              "socket",
+					Type:              SinkTypeSocket,
+					Address:           addr,
+					DeliveryGuarantee: guarantee,
+				},
+			},
+		})
+		require.NoError(err)
+
+		err = a.Event(testEvent(OperationReceived, "GET", "/v1/jobs"))
+		if guarantee == DeliveryEnforced {
+			require.Error(err)
+		} else {
+			require.NoError(err)
+		}
+		a.Close()
+	}
+}
+
+func TestAuditor_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		conf *config.AuditConfig
+		err  string
+	}{
+		{
+			name: "no sinks",
+			conf: &config.AuditConfig{},
+			err:  "at least one sink is required",
+		},
+		{
+			name: "bad sink type",
+			conf: &config.AuditConfig{
+				Sinks: []*config.AuditSink{{Name: "foo", Type: "syslog"}},
+			},
+			err: `invalid type "syslog"`,
+		},
+		{
+			name: "missing path",
+			conf: &config.AuditConfig{
+				Sinks: []*config.AuditSink{{Name: "foo", Type: SinkTypeFile}},
+			},
+			err: "path is required",
+		},
+		{
+			name: "bad guarantee",
+			conf: &config.AuditConfig{
+				Sinks: []*config.AuditSink{{Name: "foo", Type: SinkTypeSocket, Address: "foo:1", DeliveryGuarantee: "maybe"}},
+			},
+			err: "invalid delivery guarantee",
+		},
+		{
+			name: "bad filter",
+			conf: &config.AuditConfig{
+				Sinks:   []*config.AuditSink{{Name: "foo", Type: SinkTypeSocket, Address: "foo:1"}},
+				Filters: []*config.AuditFilter{{Name: "bar", Type: FilterTypeHTTPEvent, Stages: []string{"Never"}}},
+			},
+			err: `invalid stage "Never"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.conf.Enabled = helper.BoolToPtr(true)
+			_, err := NewAuditor(testlog.HCLogger(t), tc.conf)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+const (
+	// socketTimeout bounds the time to connect and write to a socket sink.
+	socketTimeout = 5 * time.Second
+)
+
+// sink is a destination of audit events.
+type sink struct {
+	name     string
+	enforced bool
+	io.WriteCloser
+}
+
+// newSink opens the sink for the given configuration.
+func newSink(conf *config.AuditSink) (*sink, error) {
+	s := &sink{
+		name:     conf.Name,
+		enforced: conf.DeliveryGuarantee != DeliveryBestEffort,
+	}
+
+	switch conf.Type {
+	case SinkTypeFile:
+		f := &fileSink{
+			path:           conf.Path,
+			rotateBytes:    int64(conf.RotateBytes),
+			rotateDuration: conf.RotateDuration,
+			rotateMaxFiles: conf.RotateMaxFiles,
+		}
+		if err := f.open(); err != nil {
+			return nil, err
+		}
+		s.WriteCloser = f
+	case SinkTypeSocket:
+		network := conf.SocketType
+		if network == "" {
+			network = "tcp"
+		}
+		s.WriteCloser = &socketSink{
+			network: network,
+			address: conf.Address,
+		}
+	default:
+		return nil, fmt.Errorf("invalid type %q", conf.Type)
+	}
+
+	return s, nil
+}
+
+// OpenSink opens a sink for records other than audit events, such as the
+// placement decisions of the schedulers. Callers serialize their writes.
+func OpenSink(conf *config.AuditSink) (io.WriteCloser, error) {
+	s, err := newSink(conf)
+	if err != nil {
+		return nil, err
+	}
+	return s.WriteCloser, nil
+}
+
+// fileSink writes events to a file which is rotated by size or age. Rotated
+// files are renamed with the time of the rotation before the extension.
+type fileSink struct {
+	path           string
+	rotateBytes    int64
+	rotateDuration time.Duration
+	rotateMaxFiles int
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// open opens the file for appending, creating it and its directory if needed.
+func (f *fileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+func (f *fileSink) Write(p []byte) (int, error) {
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// shouldRotate returns whether the file must be rotated before writing n more
+// bytes to it. An empty file is never rotated.
+func (f *fileSink) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.rotateBytes > 0 && f.size+n > f.rotateBytes {
+		return true
+	}
+	return f.rotateDuration > 0 && time.Since(f.openedAt) >= f.rotateDuration
+}
+
+// rotate renames the current file, opens a new one and removes the oldest
+// rotated files beyond the maximum number of files.
+func (f *fileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+	rotated := fmt.Sprintf("%s-%d%s", base, time.Now().UnixNano(), ext)
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.rotateMaxFiles == 0 {
+		return nil
+	}
+	matches, err := filepath.Glob(base + "-*" + ext)
+	if err != nil {
+		return err
+	}
+	if len(matches) <= f.rotateMaxFiles {
+		return nil
+	}
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-f.rotateMaxFiles] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fileSink) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// socketSink sends events to a socket. The connection is established lazily
+// and re-established once if a write fails.
+type socketSink struct {
+	network string
+	address string
+	conn    net.Conn
+}
+
+func (s *socketSink) Write(p []byte) (int, error) {
+	n, err := s.write(p)
+	if err == nil {
+		return n, nil
+	}
+
+	// Reconnect in case the connection was closed by the remote end
+	s.Close()
+	return s.write(p)
+}
+
+func (s *socketSink) write(p []byte) (int, error) {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, socketTimeout)
+		if err != nil {
+			return 0, err
+		}
+		s.conn = conn
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(socketTimeout)); err != nil {
+		return 0, err
+	}
+	return s.conn.Write(p)
+}
+
+func (s *socketSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
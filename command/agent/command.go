@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/consul/lib"
 	checkpoint "github.com/hashicorp/go-checkpoint"
 	discover "github.com/hashicorp/go-discover"
+	multierror "github.com/hashicorp/go-multierror"
 	gsyslog "github.com/hashicorp/go-syslog"
 	"github.com/hashicorp/logutils"
 	"github.com/hashicorp/nomad/helper"
@@ -288,6 +289,11 @@ func (c *Command) isValidConfig(config *Config) bool {
 		}
 	}
 
+	if err := validateTLSConfig(config.TLSConfig); err != nil {
+		c.Ui.Error(fmt.Sprintf("Invalid TLS configuration: %s", err))
+		return false
+	}
+
 	if config.Server.EncryptKey != "" {
 		if _, err := config.Server.EncryptBytes(); err != nil {
 			c.Ui.Error(fmt.Sprintf("Invalid encryption key: %s", err))
@@ -358,6 +364,29 @@ func (c *Command) isValidConfig(config *Config) bool {
 	return true
 }
 
+// validateTLSConfig checks that the files required by the enabled TLS
+// settings are given.
+func validateTLSConfig(tls *config.TLSConfig) error {
+	if tls == nil {
+		return nil
+	}
+
+	var mErr multierror.Error
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("cert_file and key_file must be given together"))
+	}
+	if tls.EnableHTTP && (tls.CertFile == "" || tls.KeyFile == "") {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("http requires cert_file and key_file"))
+	}
+	if tls.EnableRPC && (tls.CAFile == "" || tls.CertFile == "" || tls.KeyFile == "") {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("rpc requires ca_file, cert_file and key_file"))
+	}
+	if tls.VerifyHTTPSClient && tls.CAFile == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("verify_https_client requires ca_file"))
+	}
+	return mErr.ErrorOrNil()
+}
+
 // setupLoggers is used to setup the logGate, logWriter, and our logOutput
 func (c *Command) setupLoggers(config *Config) (*gatedwriter.Writer, *logWriter, io.Writer) {
 	// Setup logging. First create the gated log writer, which will
@@ -738,11 +767,12 @@ WAIT:
 		goto WAIT
 	}
 
-	// Check if we should do a graceful leave
+	// Check if we should do a graceful leave. Clients that drain on shutdown
+	// always leave gracefully on SIGTERM.
 	graceful := false
 	if sig == os.Interrupt && c.agent.GetConfig().LeaveOnInt {
 		graceful = true
-	} else if sig == syscall.SIGTERM && c.agent.GetConfig().LeaveOnTerm {
+	} else if sig == syscall.SIGTERM && (c.agent.GetConfig().LeaveOnTerm || c.drainOnShutdown()) {
 		graceful = true
 	}
 
@@ -762,17 +792,30 @@ WAIT:
 		close(gracefulCh)
 	}()
 
+	// Allow time for the client to drain
+	timeout := gracefulTimeout
+	if client := c.agent.Client(); client != nil {
+		timeout += client.LeaveTimeout()
+	}
+
 	// Wait for leave or another signal
 	select {
 	case <-signalCh:
 		return 1
-	case <-time.After(gracefulTimeout):
+	case <-time.After(timeout):
 		return 1
 	case <-gracefulCh:
 		return 0
 	}
 }
 
+// drainOnShutdown returns true if the agent runs a client configured to drain
+// itself on shutdown.
+func (c *Command) drainOnShutdown() bool {
+	client := c.agent.GetConfig().Client
+	return client != nil && client.Enabled && client.DrainOnShutdown != nil
+}
+
 // reloadHTTPServer shuts down the existing HTTP server and restarts it. This
 // is helpful when reloading the agent configuration.
 func (c *Command) reloadHTTPServer() error {
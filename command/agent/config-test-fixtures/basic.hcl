@@ -20,6 +20,9 @@ addresses {
 advertise {
 	rpc = "127.0.0.3"
 	serf = "127.0.0.4"
+	rpc_segments {
+		dmz = "10.0.0.3:5647"
+	}
 }
 
 client {
@@ -45,18 +48,33 @@ client {
 		"/opt/myapp/etc" = "/etc"
 		"/opt/myapp/bin" = "/bin"
 	}
+	chroot_cache = true
+	ephemeral_disk_quota = true
 	network_interface = "eth0"
 	network_speed = 100
+	network_segment = "dmz"
 	cpu_total_compute = 4444
 	reserved {
 		cpu = 10
 		memory = 10
 		disk = 10
 		reserved_ports = "1,100,10-12"
+		cores = "0-1"
 	}
 	client_min_port = 1000
 	client_max_port = 2000
 	max_kill_timeout = "10s"
+	alloc_hook "register" {
+		event = "poststart"
+		command = "/usr/local/bin/register"
+		args = ["-local"]
+		timeout = "5s"
+		on_failure = "fail"
+	}
+	drain_on_shutdown {
+		deadline = "30m"
+		ignore_system_jobs = true
+	}
 	stats {
 		data_points = 35
 		collection_interval = "5s"
@@ -82,6 +100,7 @@ server {
 	eval_gc_threshold = "12h"
 	deployment_gc_threshold = "12h"
 	heartbeat_grace   = "30s"
+	missed_heartbeat_grace = "45s"
 	min_heartbeat_ttl = "33s"
 	max_heartbeats_per_second = 11.0
 	retry_join = [ "1.1.1.1", "2.2.2.2" ]
@@ -93,6 +112,39 @@ server {
 	redundancy_zone = "foo"
 	upgrade_version = "0.8.0"
 	encrypt = "abc"
+	variables_encryption_key = "def"
+	enable_event_broker = false
+	event_buffer_size = 200
+	job_rate_limit {
+		namespace_rate = 10
+		namespace_burst = 20
+		token_rate = 0.5
+		token_burst = 5
+	}
+	decision_log {
+		type = "file"
+		path = "/tmp/nomad/decisions.json"
+		rotate_bytes = 1048576
+		rotate_duration = "24h"
+		rotate_max_files = 3
+	}
+	deployment_webhook {
+		url = "https://alerts.example.com/nomad"
+		hmac_key = "ghi"
+		timeout = "5s"
+	}
+	job_admission_webhook "quota" {
+		url = "https://admission.example.com/nomad"
+		hmac_key = "jkl"
+		timeout = "2s"
+		mutate = true
+		ignore_failure = true
+	}
+	job_admission_rule "no-raw-exec" {
+		condition = "!contains([for tg in job.TaskGroups : tg.Tasks[0].Driver], \"raw_exec\")"
+		message = "raw_exec is not allowed"
+		enforcement_level = "advisory"
+	}
 	server_join {
 		retry_join = [ "1.1.1.1", "2.2.2.2" ]
 		retry_max = 3
@@ -104,6 +156,8 @@ acl {
 	token_ttl = "60s"
 	policy_ttl = "60s"
 	replication_token = "foobar"
+	token_min_expiration_ttl = "2m"
+	token_max_expiration_ttl = "48h"
 }
 telemetry {
 	statsite_address = "127.0.0.1:1234"
@@ -158,6 +212,9 @@ vault {
 	tls_server_name = "foobar"
 	tls_skip_verify = true
 	create_from_role = "test_role"
+	jwt_signing_key_file = "/path/to/identity/key"
+	jwt_auth_path = "nomad-jwt"
+	jwt_auth_role = "workloads"
 }
 tls {
 	http = true
@@ -191,6 +248,24 @@ autopilot {
 	server_stabilization_time = "23057s"
 	enable_custom_upgrades = true
 }
+audit {
+	enabled = true
+	sink "file" {
+		type = "file"
+		delivery_guarantee = "enforced"
+		format = "json"
+		path = "/var/lib/nomad/audit/audit.log"
+		rotate_bytes = 100
+		rotate_duration = "24h"
+		rotate_max_files = 10
+	}
+	filter "metrics" {
+		type = "HTTPEvent"
+		endpoints = ["/v1/metrics"]
+		stages = ["*"]
+		operations = ["*"]
+	}
+}
 plugin "docker" {
   args = ["foo", "bar"]
   config {
@@ -0,0 +1,12 @@
+vault {
+  enabled = true
+  address = "https://vault.service.consul:8200"
+}
+
+vault {
+  name             = "eu"
+  enabled          = true
+  address          = "https://vault.eu.example.com:8200"
+  namespace        = "nomad"
+  nomad_namespaces = ["web", "batch"]
+}
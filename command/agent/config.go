@@ -16,6 +16,7 @@ import (
 
 	"github.com/hashicorp/go-sockaddr/template"
 	client "github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/command/agent/audit"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -109,6 +110,10 @@ type Config struct {
 	// parameters necessary to derive tokens.
 	Vault *config.VaultConfig `mapstructure:"vault"`
 
+	// VaultClusters are the additional Vault clusters, configured by named
+	// vault blocks, indexed by name.
+	VaultClusters map[string]*config.VaultConfig `mapstructure:"-"`
+
 	// NomadConfig is used to override the default config.
 	// This is largely used for testing purposes.
 	NomadConfig *nomad.Config `mapstructure:"-" json:"-"`
@@ -140,6 +145,10 @@ type Config struct {
 	// Autopilot contains the configuration for Autopilot behavior.
 	Autopilot *config.AutopilotConfig `mapstructure:"autopilot"`
 
+	// Audit contains the configuration for the audit logging of HTTP API
+	// requests.
+	Audit *config.AuditConfig `mapstructure:"audit"`
+
 	// Plugins is the set of configured plugins
 	Plugins []*config.PluginConfig `hcl:"plugin,expand"`
 }
@@ -174,6 +183,14 @@ type ClientConfig struct {
 	// task's chroot.
 	ChrootEnv map[string]string `mapstructure:"chroot_env"`
 
+	// ChrootCache enables building the chroot of tasks once and hardlinking
+	// it into each task directory.
+	ChrootCache bool `mapstructure:"chroot_cache"`
+
+	// EphemeralDiskQuota enables enforcing the ephemeral disk size of
+	// allocations with project quotas of the alloc dir's filesystem.
+	EphemeralDiskQuota bool `mapstructure:"ephemeral_disk_quota"`
+
 	// Interface to use for network fingerprinting
 	NetworkInterface string `mapstructure:"network_interface"`
 
@@ -181,6 +198,11 @@ type ClientConfig struct {
 	// speed.
 	NetworkSpeed int `mapstructure:"network_speed"`
 
+	// NetworkSegment is the network segment of the client. If set, the client
+	// connects to servers through the RPC addresses they advertise for the
+	// segment.
+	NetworkSegment string `mapstructure:"network_segment"`
+
 	// CpuCompute is used to override any detected or default total CPU compute.
 	CpuCompute int `mapstructure:"cpu_total_compute"`
 
@@ -229,6 +251,65 @@ type ClientConfig struct {
 
 	// ServerJoin contains information that is used to attempt to join servers
 	ServerJoin *ServerJoin `mapstructure:"server_join"`
+
+	// AllocHooks are commands run by the client when allocations start or
+	// stop.
+	AllocHooks []*AllocHook `mapstructure:"alloc_hook"`
+
+	// DrainOnShutdown configures the client to drain itself when the agent
+	// receives SIGTERM.
+	DrainOnShutdown *DrainOnShutdown `mapstructure:"drain_on_shutdown"`
+}
+
+// DrainOnShutdown is the drain the client applies to itself before shutting
+// down.
+type DrainOnShutdown struct {
+	// Deadline is the duration after which remaining allocations are
+	// stopped.
+	Deadline string `mapstructure:"deadline"`
+
+	// IgnoreSystemJobs leaves system job allocations running.
+	IgnoreSystemJobs bool `mapstructure:"ignore_system_jobs"`
+}
+
+// AllocHook is an operator defined command run by the client on allocation
+// lifecycle events.
+type AllocHook struct {
+	Name      string   `hcl:",key"`
+	Event     string   `hcl:"event"`
+	Command   string   `hcl:"command"`
+	Args      []string `hcl:"args"`
+	Timeout   string   `hcl:"timeout"`
+	OnFailure string   `hcl:"on_failure"`
+}
+
+// Copy returns a deep copy of the alloc hook.
+func (h *AllocHook) Copy() *AllocHook {
+	nh := *h
+	nh.Args = helper.CopySliceString(h.Args)
+	return &nh
+}
+
+// allocHookSetMerge merges two sets of alloc hooks. Hooks in the second set
+// replace hooks of the same name in the first.
+func allocHookSetMerge(first, second []*AllocHook) []*AllocHook {
+	sindex := make(map[string]*AllocHook, len(second))
+	for _, h := range second {
+		sindex[h.Name] = h
+	}
+
+	var out []*AllocHook
+	for _, h := range first {
+		if _, ok := sindex[h.Name]; ok {
+			continue
+		}
+		out = append(out, h.Copy())
+	}
+	for _, h := range second {
+		out = append(out, h.Copy())
+	}
+
+	return out
 }
 
 // ACLConfig is configuration specific to the ACL system
@@ -252,6 +333,11 @@ type ACLConfig struct {
 	// from the authoritative region. This must be a valid management token
 	// within the authoritative region.
 	ReplicationToken string `mapstructure:"replication_token"`
+
+	// TokenMinExpirationTTL and TokenMaxExpirationTTL bound the expiration
+	// TTL that may be set when creating a token. Default to "1m" and "24h".
+	TokenMinExpirationTTL time.Duration `mapstructure:"token_min_expiration_ttl"`
+	TokenMaxExpirationTTL time.Duration `mapstructure:"token_max_expiration_ttl"`
 }
 
 // ServerConfig is configuration specific to the server mode
@@ -311,6 +397,11 @@ type ServerConfig struct {
 	// processing delays and clock skew before marking a node as "down".
 	HeartbeatGrace time.Duration `mapstructure:"heartbeat_grace"`
 
+	// MissedHeartbeatGrace is the additional time given before marking nodes
+	// "down" when their heartbeats expire while many other nodes are also
+	// missing heartbeats, such as when the servers are briefly unavailable.
+	MissedHeartbeatGrace time.Duration `mapstructure:"missed_heartbeat_grace"`
+
 	// MinHeartbeatTTL is the minimum time between heartbeats. This is used as
 	// a floor to prevent excessive updates.
 	MinHeartbeatTTL time.Duration `mapstructure:"min_heartbeat_ttl"`
@@ -362,10 +453,275 @@ type ServerConfig struct {
 	// Encryption key to use for the Serf communication
 	EncryptKey string `mapstructure:"encrypt" json:"-"`
 
+	// VariablesEncryptionKey is the base64 encoded AES-256 key used to
+	// encrypt variables. It must be the same on all servers in the region.
+	VariablesEncryptionKey string `mapstructure:"variables_encryption_key" json:"-"`
+
+	// EnableEventBroker controls whether the server publishes the events of
+	// state changes to the event stream. Defaults to true.
+	EnableEventBroker *bool `mapstructure:"enable_event_broker"`
+
+	// EventBufferSize is the number of raft logs whose events are kept for
+	// subscribers resuming the event stream from an index.
+	EventBufferSize *int `mapstructure:"event_buffer_size"`
+
+	// JobRateLimit limits the rate at which jobs are registered and
+	// dispatched.
+	JobRateLimit *JobRateLimit `mapstructure:"job_rate_limit"`
+
+	// DecisionLog is where the schedulers of the server record their
+	// placement decisions. Decisions aren't recorded if it is nil.
+	DecisionLog *DecisionLog `mapstructure:"decision_log"`
+
+	// DeploymentWebhook is notified when a deployment fails its progress
+	// deadline. No notifications are sent if it is nil.
+	DeploymentWebhook *DeploymentWebhook `mapstructure:"deployment_webhook"`
+
+	// JobAdmissionWebhooks and JobAdmissionRules validate, and may mutate,
+	// the jobs registered, planned and validated.
+	JobAdmissionWebhooks []*JobAdmissionWebhook `mapstructure:"job_admission_webhook"`
+	JobAdmissionRules    []*JobAdmissionRule    `mapstructure:"job_admission_rule"`
+
 	// ServerJoin contains information that is used to attempt to join servers
 	ServerJoin *ServerJoin `mapstructure:"server_join"`
 }
 
+// JobRateLimit limits the rate of job registrations and dispatches handled by
+// the servers, per namespace and per ACL token. A zero rate disables the
+// corresponding limit.
+type JobRateLimit struct {
+	// NamespaceRate is the number of submissions per second allowed in each
+	// namespace.
+	NamespaceRate float64 `mapstructure:"namespace_rate"`
+
+	// NamespaceBurst is the number of submissions allowed at once in each
+	// namespace. It defaults to the rate rounded up.
+	NamespaceBurst int `mapstructure:"namespace_burst"`
+
+	// TokenRate is the number of submissions per second allowed for each
+	// ACL token. Requests without a token share the same limit.
+	TokenRate float64 `mapstructure:"token_rate"`
+
+	// TokenBurst is the number of submissions allowed at once for each ACL
+	// token. It defaults to the rate rounded up.
+	TokenBurst int `mapstructure:"token_burst"`
+}
+
+func (j *JobRateLimit) Merge(b *JobRateLimit) *JobRateLimit {
+	if j == nil {
+		return b
+	}
+
+	result := *j
+
+	if b == nil {
+		return &result
+	}
+
+	if b.NamespaceRate != 0 {
+		result.NamespaceRate = b.NamespaceRate
+	}
+	if b.NamespaceBurst != 0 {
+		result.NamespaceBurst = b.NamespaceBurst
+	}
+	if b.TokenRate != 0 {
+		result.TokenRate = b.TokenRate
+	}
+	if b.TokenBurst != 0 {
+		result.TokenBurst = b.TokenBurst
+	}
+
+	return &result
+}
+
+// DecisionLog is the sink the schedulers write a JSON record to for each
+// placement decision, for offline analysis of the scheduling. It supports the
+// same sinks as the audit log.
+type DecisionLog struct {
+	// Type is the type of the sink, either "file" or "socket".
+	Type string `mapstructure:"type"`
+
+	// Path is the file records are written to by file sinks.
+	Path string `mapstructure:"path"`
+
+	// RotateBytes is the size after which the file of a file sink is
+	// rotated. Zero disables rotating by size.
+	RotateBytes int `mapstructure:"rotate_bytes"`
+
+	// RotateDuration is the time after which the file of a file sink is
+	// rotated. Zero disables rotating by time.
+	RotateDuration time.Duration `mapstructure:"rotate_duration"`
+
+	// RotateMaxFiles is the number of rotated files kept by a file sink.
+	// Zero keeps all of them.
+	RotateMaxFiles int `mapstructure:"rotate_max_files"`
+
+	// Address is the address records are sent to by socket sinks.
+	Address string `mapstructure:"address"`
+
+	// SocketType is the network of socket sinks: "tcp", "udp" or "unix".
+	SocketType string `mapstructure:"socket_type"`
+}
+
+// Sink returns the configuration of the audit sink records are written to.
+func (d *DecisionLog) Sink() *config.AuditSink {
+	return &config.AuditSink{
+		Name:              "decision_log",
+		Type:              d.Type,
+		DeliveryGuarantee: audit.DeliveryBestEffort,
+		Format:            audit.FormatJSON,
+		Path:              d.Path,
+		RotateBytes:       d.RotateBytes,
+		RotateDuration:    d.RotateDuration,
+		RotateMaxFiles:    d.RotateMaxFiles,
+		Address:           d.Address,
+		SocketType:        d.SocketType,
+	}
+}
+
+func (d *DecisionLog) Merge(b *DecisionLog) *DecisionLog {
+	if d == nil {
+		return b
+	}
+
+	result := *d
+
+	if b == nil {
+		return &result
+	}
+
+	if b.Type != "" {
+		result.Type = b.Type
+	}
+	if b.Path != "" {
+		result.Path = b.Path
+	}
+	if b.RotateBytes != 0 {
+		result.RotateBytes = b.RotateBytes
+	}
+	if b.RotateDuration != 0 {
+		result.RotateDuration = b.RotateDuration
+	}
+	if b.RotateMaxFiles != 0 {
+		result.RotateMaxFiles = b.RotateMaxFiles
+	}
+	if b.Address != "" {
+		result.Address = b.Address
+	}
+	if b.SocketType != "" {
+		result.SocketType = b.SocketType
+	}
+
+	return &result
+}
+
+// DeploymentWebhook is the HTTP endpoint the leader posts a JSON notification
+// to when a deployment fails its progress deadline, so external incident
+// tooling can alert on it.
+type DeploymentWebhook struct {
+	// URL is the endpoint notifications are posted to.
+	URL string `mapstructure:"url"`
+
+	// HMACKey signs the body of the notifications with HMAC-SHA256 in the
+	// X-Nomad-Signature header. Notifications aren't signed if it is empty.
+	HMACKey string `mapstructure:"hmac_key" json:"-"`
+
+	// Timeout bounds each request to the endpoint.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (d *DeploymentWebhook) Merge(b *DeploymentWebhook) *DeploymentWebhook {
+	if d == nil {
+		return b
+	}
+
+	result := *d
+
+	if b == nil {
+		return &result
+	}
+
+	if b.URL != "" {
+		result.URL = b.URL
+	}
+	if b.HMACKey != "" {
+		result.HMACKey = b.HMACKey
+	}
+	if b.Timeout != 0 {
+		result.Timeout = b.Timeout
+	}
+
+	return &result
+}
+
+// JobAdmissionWebhook is an HTTP endpoint the jobs being submitted are posted
+// to. It allows or denies the jobs and, if Mutate is set, may replace them.
+type JobAdmissionWebhook struct {
+	Name          string `hcl:",key"`
+	URL           string `hcl:"url"`
+	HMACKey       string `hcl:"hmac_key" json:"-"`
+	Timeout       string `hcl:"timeout"`
+	Mutate        bool   `hcl:"mutate"`
+	IgnoreFailure bool   `hcl:"ignore_failure"`
+}
+
+// JobAdmissionRule is an HCL expression the jobs being submitted must
+// satisfy.
+type JobAdmissionRule struct {
+	Name             string `hcl:",key"`
+	Condition        string `hcl:"condition"`
+	Message          string `hcl:"message"`
+	EnforcementLevel string `hcl:"enforcement_level"`
+}
+
+// jobAdmissionWebhookSetMerge merges two sets of admission webhooks. Webhooks
+// in the second set replace webhooks of the same name in the first.
+func jobAdmissionWebhookSetMerge(first, second []*JobAdmissionWebhook) []*JobAdmissionWebhook {
+	sindex := make(map[string]*JobAdmissionWebhook, len(second))
+	for _, w := range second {
+		sindex[w.Name] = w
+	}
+
+	var out []*JobAdmissionWebhook
+	for _, w := range first {
+		if _, ok := sindex[w.Name]; ok {
+			continue
+		}
+		nw := *w
+		out = append(out, &nw)
+	}
+	for _, w := range second {
+		nw := *w
+		out = append(out, &nw)
+	}
+
+	return out
+}
+
+// jobAdmissionRuleSetMerge merges two sets of admission rules. Rules in the
+// second set replace rules of the same name in the first.
+func jobAdmissionRuleSetMerge(first, second []*JobAdmissionRule) []*JobAdmissionRule {
+	sindex := make(map[string]*JobAdmissionRule, len(second))
+	for _, r := range second {
+		sindex[r.Name] = r
+	}
+
+	var out []*JobAdmissionRule
+	for _, r := range first {
+		if _, ok := sindex[r.Name]; ok {
+			continue
+		}
+		nr := *r
+		out = append(out, &nr)
+	}
+	for _, r := range second {
+		nr := *r
+		out = append(out, &nr)
+	}
+
+	return out
+}
+
 // ServerJoin is used in both clients and servers to bootstrap connections to
 // servers
 type ServerJoin struct {
@@ -435,6 +791,16 @@ type Telemetry struct {
 	PublishAllocationMetrics bool          `mapstructure:"publish_allocation_metrics"`
 	PublishNodeMetrics       bool          `mapstructure:"publish_node_metrics"`
 
+	// AllocationMetricsLabels restricts the labels of allocation metrics to
+	// the listed ones, such as job, task_group and task, to bound their
+	// cardinality. All labels are published if empty.
+	AllocationMetricsLabels []string `mapstructure:"allocation_metrics_labels"`
+
+	// AllocationMetricsLimit is the maximum number of tasks of a client whose
+	// resource usage metrics are published at once. There is no limit if
+	// zero.
+	AllocationMetricsLimit int `mapstructure:"allocation_metrics_limit"`
+
 	// DisableTaggedMetrics disables a new version of generating metrics which
 	// uses tags
 	DisableTaggedMetrics bool `mapstructure:"disable_tagged_metrics"`
@@ -456,6 +822,19 @@ type Telemetry struct {
 	// a small memory overhead.
 	DisableDispatchedJobSummaryMetrics bool `mapstructure:"disable_dispatched_job_summary_metrics"`
 
+	// OTLPEndpoint is the base URL of the OTLP/HTTP receiver of an
+	// OpenTelemetry collector, such as "http://localhost:4318". Servers
+	// export the spans of their RPCs and evaluations to it when set.
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"`
+
+	// OTLPHeaders are added to the requests exporting spans, such as the
+	// authentication headers of hosted collectors.
+	OTLPHeaders map[string]string `mapstructure:"otlp_headers"`
+
+	// TraceSampleRate is the ratio of traces that are exported, from 0 to 1.
+	// All traces are exported if unset.
+	TraceSampleRate *float64 `mapstructure:"trace_sample_rate"`
+
 	// Circonus: see https://github.com/circonus-labs/circonus-gometrics
 	// for more details on the various configuration options.
 	// Valid configuration combinations:
@@ -569,6 +948,11 @@ type AdvertiseAddrs struct {
 	HTTP string `mapstructure:"http"`
 	RPC  string `mapstructure:"rpc"`
 	Serf string `mapstructure:"serf"`
+
+	// RPCSegments maps the name of a network segment to the RPC address
+	// advertised to the clients of that segment by a server. Clients on a
+	// segment only connect to servers through these addresses.
+	RPCSegments map[string]string `mapstructure:"rpc_segments"`
 }
 
 type Resources struct {
@@ -576,6 +960,7 @@ type Resources struct {
 	MemoryMB      int    `mapstructure:"memory"`
 	DiskMB        int    `mapstructure:"disk"`
 	ReservedPorts string `mapstructure:"reserved_ports"`
+	Cores         string `mapstructure:"cores"`
 }
 
 // CanParseReserved returns if the reserved ports specification is parsable.
@@ -674,6 +1059,7 @@ func DefaultConfig() *Config {
 		Sentinel:           &config.SentinelConfig{},
 		Version:            version.GetVersion(),
 		Autopilot:          config.DefaultAutopilotConfig(),
+		Audit:              config.DefaultAuditConfig(),
 		DisableUpdateCheck: helper.BoolToPtr(false),
 	}
 }
@@ -830,6 +1216,16 @@ func (c *Config) Merge(b *Config) *Config {
 	} else if b.Vault != nil {
 		result.Vault = result.Vault.Merge(b.Vault)
 	}
+	for name, bv := range b.VaultClusters {
+		if result.VaultClusters == nil {
+			result.VaultClusters = make(map[string]*config.VaultConfig)
+		}
+		if v, ok := result.VaultClusters[name]; ok {
+			result.VaultClusters[name] = v.Merge(bv)
+		} else {
+			result.VaultClusters[name] = bv.Copy()
+		}
+	}
 
 	// Apply the sentinel config
 	if result.Sentinel == nil && b.Sentinel != nil {
@@ -846,6 +1242,12 @@ func (c *Config) Merge(b *Config) *Config {
 		result.Autopilot = result.Autopilot.Merge(b.Autopilot)
 	}
 
+	if result.Audit == nil && b.Audit != nil {
+		result.Audit = b.Audit.Copy()
+	} else if b.Audit != nil {
+		result.Audit = result.Audit.Merge(b.Audit)
+	}
+
 	if len(result.Plugins) == 0 && len(b.Plugins) != 0 {
 		copy := make([]*config.PluginConfig, len(b.Plugins))
 		for i, v := range b.Plugins {
@@ -924,6 +1326,17 @@ func (c *Config) normalizeAddrs() error {
 			return fmt.Errorf("Failed to parse Serf advertise address: %v", err)
 		}
 		c.AdvertiseAddrs.Serf = addr
+
+		for segment, segmentAddr := range c.AdvertiseAddrs.RPCSegments {
+			if segment == "" || segmentAddr == "" {
+				return fmt.Errorf("RPC segment advertise addresses require a segment name and an address")
+			}
+			addr, err = normalizeAdvertise(segmentAddr, c.Addresses.RPC, c.Ports.RPC, c.DevMode)
+			if err != nil {
+				return fmt.Errorf("Failed to parse RPC advertise address of segment %q: %v", segment, err)
+			}
+			c.AdvertiseAddrs.RPCSegments[segment] = addr
+		}
 	}
 
 	return nil
@@ -1051,6 +1464,12 @@ func (a *ACLConfig) Merge(b *ACLConfig) *ACLConfig {
 	if b.ReplicationToken != "" {
 		result.ReplicationToken = b.ReplicationToken
 	}
+	if b.TokenMinExpirationTTL != 0 {
+		result.TokenMinExpirationTTL = b.TokenMinExpirationTTL
+	}
+	if b.TokenMaxExpirationTTL != 0 {
+		result.TokenMaxExpirationTTL = b.TokenMaxExpirationTTL
+	}
 	return &result
 }
 
@@ -1094,6 +1513,9 @@ func (a *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	if b.HeartbeatGrace != 0 {
 		result.HeartbeatGrace = b.HeartbeatGrace
 	}
+	if b.MissedHeartbeatGrace != 0 {
+		result.MissedHeartbeatGrace = b.MissedHeartbeatGrace
+	}
 	if b.MinHeartbeatTTL != 0 {
 		result.MinHeartbeatTTL = b.MinHeartbeatTTL
 	}
@@ -1121,6 +1543,30 @@ func (a *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	if b.EncryptKey != "" {
 		result.EncryptKey = b.EncryptKey
 	}
+	if b.VariablesEncryptionKey != "" {
+		result.VariablesEncryptionKey = b.VariablesEncryptionKey
+	}
+	if b.EnableEventBroker != nil {
+		result.EnableEventBroker = helper.BoolToPtr(*b.EnableEventBroker)
+	}
+	if b.EventBufferSize != nil {
+		result.EventBufferSize = helper.IntToPtr(*b.EventBufferSize)
+	}
+	if b.JobRateLimit != nil {
+		result.JobRateLimit = result.JobRateLimit.Merge(b.JobRateLimit)
+	}
+	if b.DecisionLog != nil {
+		result.DecisionLog = result.DecisionLog.Merge(b.DecisionLog)
+	}
+	if b.DeploymentWebhook != nil {
+		result.DeploymentWebhook = result.DeploymentWebhook.Merge(b.DeploymentWebhook)
+	}
+	if len(b.JobAdmissionWebhooks) != 0 {
+		result.JobAdmissionWebhooks = jobAdmissionWebhookSetMerge(result.JobAdmissionWebhooks, b.JobAdmissionWebhooks)
+	}
+	if len(b.JobAdmissionRules) != 0 {
+		result.JobAdmissionRules = jobAdmissionRuleSetMerge(result.JobAdmissionRules, b.JobAdmissionRules)
+	}
 	if b.ServerJoin != nil {
 		result.ServerJoin = result.ServerJoin.Merge(b.ServerJoin)
 	}
@@ -1163,6 +1609,9 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.NetworkSpeed != 0 {
 		result.NetworkSpeed = b.NetworkSpeed
 	}
+	if b.NetworkSegment != "" {
+		result.NetworkSegment = b.NetworkSegment
+	}
 	if b.CpuCompute != 0 {
 		result.CpuCompute = b.CpuCompute
 	}
@@ -1231,10 +1680,27 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 		result.ChrootEnv[k] = v
 	}
 
+	if b.ChrootCache {
+		result.ChrootCache = true
+	}
+
+	if b.EphemeralDiskQuota {
+		result.EphemeralDiskQuota = true
+	}
+
 	if b.ServerJoin != nil {
 		result.ServerJoin = result.ServerJoin.Merge(b.ServerJoin)
 	}
 
+	if len(b.AllocHooks) != 0 {
+		result.AllocHooks = allocHookSetMerge(result.AllocHooks, b.AllocHooks)
+	}
+
+	if b.DrainOnShutdown != nil {
+		drain := *b.DrainOnShutdown
+		result.DrainOnShutdown = &drain
+	}
+
 	return &result
 }
 
@@ -1276,6 +1742,12 @@ func (a *Telemetry) Merge(b *Telemetry) *Telemetry {
 	if b.PublishAllocationMetrics {
 		result.PublishAllocationMetrics = true
 	}
+	if len(b.AllocationMetricsLabels) != 0 {
+		result.AllocationMetricsLabels = b.AllocationMetricsLabels
+	}
+	if b.AllocationMetricsLimit != 0 {
+		result.AllocationMetricsLimit = b.AllocationMetricsLimit
+	}
 	if b.CirconusAPIToken != "" {
 		result.CirconusAPIToken = b.CirconusAPIToken
 	}
@@ -1336,6 +1808,24 @@ func (a *Telemetry) Merge(b *Telemetry) *Telemetry {
 		result.DisableDispatchedJobSummaryMetrics = b.DisableDispatchedJobSummaryMetrics
 	}
 
+	if b.OTLPEndpoint != "" {
+		result.OTLPEndpoint = b.OTLPEndpoint
+	}
+
+	if b.OTLPHeaders != nil {
+		result.OTLPHeaders = helper.CopyMapStringString(result.OTLPHeaders)
+		if result.OTLPHeaders == nil {
+			result.OTLPHeaders = make(map[string]string, len(b.OTLPHeaders))
+		}
+		for k, v := range b.OTLPHeaders {
+			result.OTLPHeaders[k] = v
+		}
+	}
+
+	if b.TraceSampleRate != nil {
+		result.TraceSampleRate = b.TraceSampleRate
+	}
+
 	return &result
 }
 
@@ -1384,6 +1874,16 @@ func (a *AdvertiseAddrs) Merge(b *AdvertiseAddrs) *AdvertiseAddrs {
 	if b.HTTP != "" {
 		result.HTTP = b.HTTP
 	}
+
+	if a.RPCSegments != nil || b.RPCSegments != nil {
+		result.RPCSegments = make(map[string]string, len(a.RPCSegments)+len(b.RPCSegments))
+		for k, v := range a.RPCSegments {
+			result.RPCSegments[k] = v
+		}
+		for k, v := range b.RPCSegments {
+			result.RPCSegments[k] = v
+		}
+	}
 	return &result
 }
 
@@ -1401,6 +1901,9 @@ func (r *Resources) Merge(b *Resources) *Resources {
 	if b.ReservedPorts != "" {
 		result.ReservedPorts = b.ReservedPorts
 	}
+	if b.Cores != "" {
+		result.Cores = b.Cores
+	}
 	return &result
 }
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,6 +13,8 @@ import (
 	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/nomad/client/lib/cgutil"
+	"github.com/hashicorp/nomad/command/agent/audit"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad/structs/config"
@@ -103,6 +106,7 @@ func parseConfig(result *Config, list *ast.ObjectList) error {
 		"acl",
 		"sentinel",
 		"autopilot",
+		"audit",
 		"plugin",
 	}
 	if err := helper.CheckHCLKeys(list, valid); err != nil {
@@ -128,6 +132,7 @@ func parseConfig(result *Config, list *ast.ObjectList) error {
 	delete(m, "acl")
 	delete(m, "sentinel")
 	delete(m, "autopilot")
+	delete(m, "audit")
 	delete(m, "plugin")
 
 	// Decode the rest
@@ -193,7 +198,7 @@ func parseConfig(result *Config, list *ast.ObjectList) error {
 
 	// Parse the vault config
 	if o := list.Filter("vault"); len(o.Items) > 0 {
-		if err := parseVaultConfig(&result.Vault, o); err != nil {
+		if err := parseVaultConfig(&result.Vault, &result.VaultClusters, o); err != nil {
 			return multierror.Prefix(err, "vault ->")
 		}
 	}
@@ -219,6 +224,13 @@ func parseConfig(result *Config, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse Audit config
+	if o := list.Filter("audit"); len(o.Items) > 0 {
+		if err := parseAudit(&result.Audit, o); err != nil {
+			return multierror.Prefix(err, "audit->")
+		}
+	}
+
 	// Parse Plugin configs
 	if o := list.Filter("plugin"); len(o.Items) > 0 {
 		if err := parsePlugins(&result.Plugins, o); err != nil {
@@ -314,13 +326,22 @@ func parseAdvertise(result **AdvertiseAddrs, list *ast.ObjectList) error {
 	}
 
 	// Get our advertise object
-	listVal := list.Items[0].Val
+	obj := list.Items[0]
+
+	// Value should be an object
+	var listVal *ast.ObjectList
+	if ot, ok := obj.Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("advertise value: should be an object")
+	}
 
 	// Check for invalid keys
 	valid := []string{
 		"http",
 		"rpc",
 		"serf",
+		"rpc_segments",
 	}
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return err
@@ -330,11 +351,27 @@ func parseAdvertise(result **AdvertiseAddrs, list *ast.ObjectList) error {
 	if err := hcl.DecodeObject(&m, listVal); err != nil {
 		return err
 	}
+	delete(m, "rpc_segments")
 
 	var advertise AdvertiseAddrs
 	if err := mapstructure.WeakDecode(m, &advertise); err != nil {
 		return err
 	}
+
+	// Parse out the segment addresses. These are in HCL as a list so we need
+	// to iterate over them and merge them.
+	if segmentsO := listVal.Filter("rpc_segments"); len(segmentsO.Items) > 0 {
+		for _, o := range segmentsO.Elem().Items {
+			var m map[string]interface{}
+			if err := hcl.DecodeObject(&m, o.Val); err != nil {
+				return err
+			}
+			if err := mapstructure.WeakDecode(m, &advertise.RPCSegments); err != nil {
+				return err
+			}
+		}
+	}
+
 	*result = &advertise
 	return nil
 }
@@ -366,8 +403,11 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		"options",
 		"meta",
 		"chroot_env",
+		"chroot_cache",
+		"ephemeral_disk_quota",
 		"network_interface",
 		"network_speed",
+		"network_segment",
 		"memory_total_mb",
 		"cpu_total_compute",
 		"max_kill_timeout",
@@ -382,6 +422,8 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		"gc_max_allocs",
 		"no_host_uuid",
 		"server_join",
+		"alloc_hook",
+		"drain_on_shutdown",
 	}
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return err
@@ -398,6 +440,8 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 	delete(m, "reserved")
 	delete(m, "stats")
 	delete(m, "server_join")
+	delete(m, "alloc_hook")
+	delete(m, "drain_on_shutdown")
 
 	var config ClientConfig
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
@@ -468,10 +512,93 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse alloc hooks
+	if o := listVal.Filter("alloc_hook"); len(o.Items) > 0 {
+		if err := parseAllocHooks(&config.AllocHooks, o); err != nil {
+			return multierror.Prefix(err, "alloc_hook ->")
+		}
+	}
+
+	// Parse drain on shutdown config
+	if o := listVal.Filter("drain_on_shutdown"); len(o.Items) > 0 {
+		if err := parseDrainOnShutdown(&config.DrainOnShutdown, o); err != nil {
+			return multierror.Prefix(err, "drain_on_shutdown ->")
+		}
+	}
+
 	*result = &config
 	return nil
 }
 
+func parseAllocHooks(result *[]*AllocHook, list *ast.ObjectList) error {
+	listLen := len(list.Items)
+	hooks := make([]*AllocHook, listLen)
+
+	// Check for invalid keys
+	valid := []string{
+		"event",
+		"command",
+		"args",
+		"timeout",
+		"on_failure",
+	}
+
+	for i := 0; i < listLen; i++ {
+		listVal := list.Items[i]
+
+		// Ensure there is a key
+		if len(listVal.Keys) != 1 {
+			return fmt.Errorf("alloc hook %d doesn't include a name key", i+1)
+		}
+
+		if err := helper.CheckHCLKeys(listVal.Val, valid); err != nil {
+			return fmt.Errorf("invalid keys in alloc hook %d: %v", i+1, err)
+		}
+
+		var hook AllocHook
+		if err := hcl.DecodeObject(&hook, listVal); err != nil {
+			return fmt.Errorf("error decoding alloc hook %d: %v", i+1, err)
+		}
+
+		hooks[i] = &hook
+	}
+
+	*result = hooks
+	return nil
+}
+
+func parseDrainOnShutdown(result **DrainOnShutdown, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'drain_on_shutdown' block allowed")
+	}
+
+	// Get our object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"deadline",
+		"ignore_system_jobs",
+	}
+	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var drain DrainOnShutdown
+	if err := mapstructure.WeakDecode(m, &drain); err != nil {
+		return err
+	}
+
+	*result = &drain
+	return nil
+}
+
 func parseReserved(result **Resources, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -495,6 +622,7 @@ func parseReserved(result **Resources, list *ast.ObjectList) error {
 		"memory",
 		"disk",
 		"reserved_ports",
+		"cores",
 	}
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return err
@@ -512,6 +640,9 @@ func parseReserved(result **Resources, list *ast.ObjectList) error {
 	if err := reserved.CanParseReserved(); err != nil {
 		return err
 	}
+	if _, err := cgutil.ParseCpuset(reserved.Cores); err != nil {
+		return err
+	}
 
 	*result = &reserved
 	return nil
@@ -548,14 +679,23 @@ func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 		"job_gc_threshold",
 		"deployment_gc_threshold",
 		"heartbeat_grace",
+		"missed_heartbeat_grace",
 		"min_heartbeat_ttl",
 		"max_heartbeats_per_second",
 		"rejoin_after_leave",
 		"encrypt",
+		"variables_encryption_key",
+		"enable_event_broker",
+		"event_buffer_size",
 		"authoritative_region",
 		"non_voting_server",
 		"redundancy_zone",
 		"upgrade_version",
+		"job_rate_limit",
+		"decision_log",
+		"deployment_webhook",
+		"job_admission_webhook",
+		"job_admission_rule",
 
 		"server_join",
 
@@ -575,6 +715,11 @@ func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 	}
 
 	delete(m, "server_join")
+	delete(m, "job_rate_limit")
+	delete(m, "decision_log")
+	delete(m, "deployment_webhook")
+	delete(m, "job_admission_webhook")
+	delete(m, "job_admission_rule")
 
 	var config ServerConfig
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
@@ -602,10 +747,294 @@ func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse the job rate limits
+	if o := listVal.Filter("job_rate_limit"); len(o.Items) > 0 {
+		if err := parseJobRateLimit(&config.JobRateLimit, o); err != nil {
+			return multierror.Prefix(err, "job_rate_limit->")
+		}
+	}
+
+	// Parse the decision log sink
+	if o := listVal.Filter("decision_log"); len(o.Items) > 0 {
+		if err := parseDecisionLog(&config.DecisionLog, o); err != nil {
+			return multierror.Prefix(err, "decision_log->")
+		}
+	}
+
+	// Parse the deployment webhook
+	if o := listVal.Filter("deployment_webhook"); len(o.Items) > 0 {
+		if err := parseDeploymentWebhook(&config.DeploymentWebhook, o); err != nil {
+			return multierror.Prefix(err, "deployment_webhook->")
+		}
+	}
+
+	// Parse the job admission controllers
+	if o := listVal.Filter("job_admission_webhook"); len(o.Items) > 0 {
+		if err := parseJobAdmissionWebhooks(&config.JobAdmissionWebhooks, o); err != nil {
+			return multierror.Prefix(err, "job_admission_webhook ->")
+		}
+	}
+	if o := listVal.Filter("job_admission_rule"); len(o.Items) > 0 {
+		if err := parseJobAdmissionRules(&config.JobAdmissionRules, o); err != nil {
+			return multierror.Prefix(err, "job_admission_rule ->")
+		}
+	}
+
 	*result = &config
 	return nil
 }
 
+func parseDecisionLog(result **DecisionLog, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'decision_log' block allowed")
+	}
+
+	// Get our object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"type",
+		"path",
+		"rotate_bytes",
+		"rotate_duration",
+		"rotate_max_files",
+		"address",
+		"socket_type",
+	}
+	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var decisionLog DecisionLog
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           &decisionLog,
+	})
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(m); err != nil {
+		return err
+	}
+
+	switch decisionLog.Type {
+	case audit.SinkTypeFile:
+		if decisionLog.Path == "" {
+			return fmt.Errorf("path is required for file sinks")
+		}
+	case audit.SinkTypeSocket:
+		if decisionLog.Address == "" {
+			return fmt.Errorf("address is required for socket sinks")
+		}
+	default:
+		return fmt.Errorf("invalid type %q, must be %q or %q", decisionLog.Type, audit.SinkTypeFile, audit.SinkTypeSocket)
+	}
+	if decisionLog.RotateBytes < 0 || decisionLog.RotateDuration < 0 || decisionLog.RotateMaxFiles < 0 {
+		return fmt.Errorf("rotation settings must not be negative")
+	}
+
+	*result = &decisionLog
+	return nil
+}
+
+func parseDeploymentWebhook(result **DeploymentWebhook, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'deployment_webhook' block allowed")
+	}
+
+	// Get our object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"url",
+		"hmac_key",
+		"timeout",
+	}
+	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var webhook DeploymentWebhook
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           &webhook,
+	})
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(m); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(webhook.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", webhook.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url must be an http or https URL, got %q", webhook.URL)
+	}
+	if webhook.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	*result = &webhook
+	return nil
+}
+
+func parseJobAdmissionWebhooks(result *[]*JobAdmissionWebhook, list *ast.ObjectList) error {
+	listLen := len(list.Items)
+	webhooks := make([]*JobAdmissionWebhook, listLen)
+
+	// Check for invalid keys
+	valid := []string{
+		"url",
+		"hmac_key",
+		"timeout",
+		"mutate",
+		"ignore_failure",
+	}
+
+	for i := 0; i < listLen; i++ {
+		listVal := list.Items[i]
+
+		// Ensure there is a key
+		if len(listVal.Keys) != 1 {
+			return fmt.Errorf("job admission webhook %d doesn't include a name key", i+1)
+		}
+
+		if err := helper.CheckHCLKeys(listVal.Val, valid); err != nil {
+			return fmt.Errorf("invalid keys in job admission webhook %d: %v", i+1, err)
+		}
+
+		var webhook JobAdmissionWebhook
+		if err := hcl.DecodeObject(&webhook, listVal); err != nil {
+			return fmt.Errorf("error decoding job admission webhook %d: %v", i+1, err)
+		}
+
+		u, err := url.Parse(webhook.URL)
+		if err != nil {
+			return fmt.Errorf("job admission webhook %q has invalid url %q: %v", webhook.Name, webhook.URL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("job admission webhook %q url must be an http or https URL, got %q", webhook.Name, webhook.URL)
+		}
+		if webhook.Timeout != "" {
+			if dur, err := time.ParseDuration(webhook.Timeout); err != nil {
+				return fmt.Errorf("job admission webhook %q has invalid timeout: %v", webhook.Name, err)
+			} else if dur < 0 {
+				return fmt.Errorf("job admission webhook %q timeout must not be negative", webhook.Name)
+			}
+		}
+
+		webhooks[i] = &webhook
+	}
+
+	*result = webhooks
+	return nil
+}
+
+func parseJobAdmissionRules(result *[]*JobAdmissionRule, list *ast.ObjectList) error {
+	listLen := len(list.Items)
+	rules := make([]*JobAdmissionRule, listLen)
+
+	// Check for invalid keys
+	valid := []string{
+		"condition",
+		"message",
+		"enforcement_level",
+	}
+
+	for i := 0; i < listLen; i++ {
+		listVal := list.Items[i]
+
+		// Ensure there is a key
+		if len(listVal.Keys) != 1 {
+			return fmt.Errorf("job admission rule %d doesn't include a name key", i+1)
+		}
+
+		if err := helper.CheckHCLKeys(listVal.Val, valid); err != nil {
+			return fmt.Errorf("invalid keys in job admission rule %d: %v", i+1, err)
+		}
+
+		var rule JobAdmissionRule
+		if err := hcl.DecodeObject(&rule, listVal); err != nil {
+			return fmt.Errorf("error decoding job admission rule %d: %v", i+1, err)
+		}
+		if rule.Condition == "" {
+			return fmt.Errorf("job admission rule %q requires a condition", rule.Name)
+		}
+
+		rules[i] = &rule
+	}
+
+	*result = rules
+	return nil
+}
+
+func parseJobRateLimit(result **JobRateLimit, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'job_rate_limit' block allowed")
+	}
+
+	// Get our object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"namespace_rate",
+		"namespace_burst",
+		"token_rate",
+		"token_burst",
+	}
+	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var limit JobRateLimit
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           &limit,
+	})
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(m); err != nil {
+		return err
+	}
+
+	if limit.NamespaceRate < 0 || limit.TokenRate < 0 {
+		return fmt.Errorf("rates must not be negative")
+	}
+	if limit.NamespaceBurst < 0 || limit.TokenBurst < 0 {
+		return fmt.Errorf("bursts must not be negative")
+	}
+
+	*result = &limit
+	return nil
+}
+
 func parseServerJoin(result **ServerJoin, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -671,6 +1100,8 @@ func parseACL(result **ACLConfig, list *ast.ObjectList) error {
 		"token_ttl",
 		"policy_ttl",
 		"replication_token",
+		"token_min_expiration_ttl",
+		"token_max_expiration_ttl",
 	}
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return err
@@ -698,6 +1129,17 @@ func parseACL(result **ACLConfig, list *ast.ObjectList) error {
 	return nil
 }
 
+// allocationMetricsLabels are the labels of allocation metrics
+var allocationMetricsLabels = []string{
+	"job",
+	"task_group",
+	"alloc_id",
+	"task",
+	"parent_id",
+	"dispatch_id",
+	"periodic_id",
+}
+
 func parseTelemetry(result **Telemetry, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -716,6 +1158,8 @@ func parseTelemetry(result **Telemetry, list *ast.ObjectList) error {
 		"collection_interval",
 		"publish_allocation_metrics",
 		"publish_node_metrics",
+		"allocation_metrics_labels",
+		"allocation_metrics_limit",
 		"datadog_address",
 		"datadog_tags",
 		"prometheus_metrics",
@@ -737,6 +1181,9 @@ func parseTelemetry(result **Telemetry, list *ast.ObjectList) error {
 		"prefix_filter",
 		"filter_default",
 		"disable_dispatched_job_summary_metrics",
+		"otlp_endpoint",
+		"otlp_headers",
+		"trace_sample_rate",
 	}
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return err
@@ -747,6 +1194,8 @@ func parseTelemetry(result **Telemetry, list *ast.ObjectList) error {
 		return err
 	}
 
+	delete(m, "otlp_headers")
+
 	var telemetry Telemetry
 	if err := mapstructure.WeakDecode(m, &telemetry); err != nil {
 		return err
@@ -758,6 +1207,31 @@ func parseTelemetry(result **Telemetry, list *ast.ObjectList) error {
 			telemetry.collectionInterval = dur
 		}
 	}
+	if rate := telemetry.TraceSampleRate; rate != nil && (*rate < 0 || *rate > 1) {
+		return fmt.Errorf("trace_sample_rate must be between 0 and 1")
+	}
+	if ok, invalid := helper.SliceStringIsSubset(allocationMetricsLabels, telemetry.AllocationMetricsLabels); !ok {
+		return fmt.Errorf("invalid allocation_metrics_labels %v, must be within %v", invalid, allocationMetricsLabels)
+	}
+	if telemetry.AllocationMetricsLimit < 0 {
+		return fmt.Errorf("allocation_metrics_limit must not be negative")
+	}
+
+	// Parse out the OTLP headers. These are in HCL as a list so we need to
+	// iterate over them and merge them.
+	if ot, ok := listVal.(*ast.ObjectType); ok {
+		if headersO := ot.List.Filter("otlp_headers"); len(headersO.Items) > 0 {
+			for _, o := range headersO.Elem().Items {
+				var m map[string]interface{}
+				if err := hcl.DecodeObject(&m, o.Val); err != nil {
+					return err
+				}
+				if err := mapstructure.WeakDecode(m, &telemetry.OTLPHeaders); err != nil {
+					return err
+				}
+			}
+		}
+	}
 	*result = &telemetry
 	return nil
 }
@@ -869,15 +1343,42 @@ func parseTLSConfig(result **config.TLSConfig, list *ast.ObjectList) error {
 	return nil
 }
 
-func parseVaultConfig(result **config.VaultConfig, list *ast.ObjectList) error {
+func parseVaultConfig(result **config.VaultConfig, clusters *map[string]*config.VaultConfig, list *ast.ObjectList) error {
 	list = list.Elem()
-	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'vault' block allowed")
+
+	// The unnamed block configures the default cluster and named blocks
+	// configure additional clusters
+	var defaultSet bool
+	for _, item := range list.Items {
+		vaultConfig, err := parseVaultBlock(item.Val)
+		if err != nil {
+			return err
+		}
+
+		name := vaultConfig.GetName()
+		if name == config.DefaultVaultClusterName {
+			if defaultSet {
+				return fmt.Errorf("only one unnamed 'vault' block allowed")
+			}
+			defaultSet = true
+			*result = vaultConfig
+			continue
+		}
+
+		if *clusters == nil {
+			*clusters = make(map[string]*config.VaultConfig)
+		}
+		if _, ok := (*clusters)[name]; ok {
+			return fmt.Errorf("only one 'vault' block named %q allowed", name)
+		}
+		(*clusters)[name] = vaultConfig
 	}
 
-	// Get our Vault object
-	listVal := list.Items[0].Val
+	return config.ValidateVaultClusters(*clusters)
+}
 
+// parseVaultBlock parses a single vault block.
+func parseVaultBlock(listVal ast.Node) (*config.VaultConfig, error) {
 	// Check for invalid keys
 	valid := []string{
 		"address",
@@ -888,19 +1389,25 @@ func parseVaultConfig(result **config.VaultConfig, list *ast.ObjectList) error {
 		"ca_path",
 		"cert_file",
 		"create_from_role",
+		"jwt_auth_path",
+		"jwt_auth_role",
+		"jwt_signing_key_file",
 		"key_file",
+		"name",
+		"namespace",
+		"nomad_namespaces",
 		"tls_server_name",
 		"tls_skip_verify",
 		"token",
 	}
 
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
-		return err
+		return nil, err
 	}
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, listVal); err != nil {
-		return err
+		return nil, err
 	}
 
 	vaultConfig := config.DefaultVaultConfig()
@@ -910,14 +1417,13 @@ func parseVaultConfig(result **config.VaultConfig, list *ast.ObjectList) error {
 		Result:           &vaultConfig,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := dec.Decode(m); err != nil {
-		return err
+		return nil, err
 	}
 
-	*result = vaultConfig
-	return nil
+	return vaultConfig, nil
 }
 
 func parseSentinel(result **config.SentinelConfig, list *ast.ObjectList) error {
@@ -1000,6 +1506,81 @@ func parseAutopilot(result **config.AutopilotConfig, list *ast.ObjectList) error
 	return nil
 }
 
+func parseAudit(result **config.AuditConfig, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'audit' block allowed")
+	}
+
+	// Get our audit object
+	obj := list.Items[0]
+
+	// Value should be an object
+	var listVal *ast.ObjectList
+	if ot, ok := obj.Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("audit value: should be an object")
+	}
+
+	// Check for invalid keys
+	valid := []string{
+		"enabled",
+		"sink",
+		"filter",
+	}
+	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	validSink := []string{
+		"type",
+		"delivery_guarantee",
+		"format",
+		"path",
+		"rotate_bytes",
+		"rotate_duration",
+		"rotate_max_files",
+		"address",
+		"socket_type",
+	}
+	for _, o := range listVal.Filter("sink").Items {
+		if err := helper.CheckHCLKeys(o.Val, validSink); err != nil {
+			return multierror.Prefix(err, "sink ->")
+		}
+	}
+
+	validFilter := []string{
+		"type",
+		"endpoints",
+		"stages",
+		"operations",
+	}
+	for _, o := range listVal.Filter("filter").Items {
+		if err := helper.CheckHCLKeys(o.Val, validFilter); err != nil {
+			return multierror.Prefix(err, "filter ->")
+		}
+	}
+
+	var audit config.AuditConfig
+	if err := hcl.DecodeObject(&audit, listVal); err != nil {
+		return err
+	}
+
+	for _, sink := range audit.Sinks {
+		if sink.RotateDurationHCL != "" {
+			d, err := time.ParseDuration(sink.RotateDurationHCL)
+			if err != nil {
+				return fmt.Errorf("sink %q: invalid rotate_duration: %v", sink.Name, err)
+			}
+			sink.RotateDuration = d
+		}
+	}
+
+	*result = &audit
+	return nil
+}
+
 func parsePlugins(result *[]*config.PluginConfig, list *ast.ObjectList) error {
 	listLen := len(list.Items)
 	plugins := make([]*config.PluginConfig, listLen)
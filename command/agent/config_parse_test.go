@@ -42,6 +42,9 @@ func TestConfig_Parse(t *testing.T) {
 				AdvertiseAddrs: &AdvertiseAddrs{
 					RPC:  "127.0.0.3",
 					Serf: "127.0.0.4",
+					RPCSegments: map[string]string{
+						"dmz": "10.0.0.3:5647",
+					},
 				},
 				Client: &ClientConfig{
 					Enabled:   true,
@@ -66,18 +69,36 @@ func TestConfig_Parse(t *testing.T) {
 						"/opt/myapp/etc": "/etc",
 						"/opt/myapp/bin": "/bin",
 					},
-					NetworkInterface: "eth0",
-					NetworkSpeed:     100,
-					CpuCompute:       4444,
-					MemoryMB:         0,
-					MaxKillTimeout:   "10s",
-					ClientMinPort:    1000,
-					ClientMaxPort:    2000,
+					ChrootCache:        true,
+					EphemeralDiskQuota: true,
+					NetworkInterface:   "eth0",
+					NetworkSpeed:       100,
+					NetworkSegment:     "dmz",
+					CpuCompute:         4444,
+					MemoryMB:           0,
+					MaxKillTimeout:     "10s",
+					ClientMinPort:      1000,
+					ClientMaxPort:      2000,
+					AllocHooks: []*AllocHook{
+						{
+							Name:      "register",
+							Event:     "poststart",
+							Command:   "/usr/local/bin/register",
+							Args:      []string{"-local"},
+							Timeout:   "5s",
+							OnFailure: "fail",
+						},
+					},
+					DrainOnShutdown: &DrainOnShutdown{
+						Deadline:         "30m",
+						IgnoreSystemJobs: true,
+					},
 					Reserved: &Resources{
 						CPU:           10,
 						MemoryMB:      10,
 						DiskMB:        10,
 						ReservedPorts: "1,100,10-12",
+						Cores:         "0-1",
 					},
 					GCInterval:            6 * time.Second,
 					GCParallelDestroys:    6,
@@ -100,6 +121,7 @@ func TestConfig_Parse(t *testing.T) {
 					JobGCThreshold:         "12h",
 					DeploymentGCThreshold:  "12h",
 					HeartbeatGrace:         30 * time.Second,
+					MissedHeartbeatGrace:   45 * time.Second,
 					MinHeartbeatTTL:        33 * time.Second,
 					MaxHeartbeatsPerSecond: 11.0,
 					RetryJoin:              []string{"1.1.1.1", "2.2.2.2"},
@@ -111,6 +133,45 @@ func TestConfig_Parse(t *testing.T) {
 					RedundancyZone:         "foo",
 					UpgradeVersion:         "0.8.0",
 					EncryptKey:             "abc",
+					VariablesEncryptionKey: "def",
+					EnableEventBroker:      helper.BoolToPtr(false),
+					EventBufferSize:        helper.IntToPtr(200),
+					JobRateLimit: &JobRateLimit{
+						NamespaceRate:  10,
+						NamespaceBurst: 20,
+						TokenRate:      0.5,
+						TokenBurst:     5,
+					},
+					DecisionLog: &DecisionLog{
+						Type:           "file",
+						Path:           "/tmp/nomad/decisions.json",
+						RotateBytes:    1048576,
+						RotateDuration: 24 * time.Hour,
+						RotateMaxFiles: 3,
+					},
+					DeploymentWebhook: &DeploymentWebhook{
+						URL:     "https://alerts.example.com/nomad",
+						HMACKey: "ghi",
+						Timeout: 5 * time.Second,
+					},
+					JobAdmissionWebhooks: []*JobAdmissionWebhook{
+						{
+							Name:          "quota",
+							URL:           "https://admission.example.com/nomad",
+							HMACKey:       "jkl",
+							Timeout:       "2s",
+							Mutate:        true,
+							IgnoreFailure: true,
+						},
+					},
+					JobAdmissionRules: []*JobAdmissionRule{
+						{
+							Name:             "no-raw-exec",
+							Condition:        `!contains([for tg in job.TaskGroups : tg.Tasks[0].Driver], "raw_exec")`,
+							Message:          "raw_exec is not allowed",
+							EnforcementLevel: "advisory",
+						},
+					},
 					ServerJoin: &ServerJoin{
 						RetryJoin:        []string{"1.1.1.1", "2.2.2.2"},
 						RetryInterval:    time.Duration(15) * time.Second,
@@ -118,10 +179,12 @@ func TestConfig_Parse(t *testing.T) {
 					},
 				},
 				ACL: &ACLConfig{
-					Enabled:          true,
-					TokenTTL:         60 * time.Second,
-					PolicyTTL:        60 * time.Second,
-					ReplicationToken: "foobar",
+					Enabled:               true,
+					TokenTTL:              60 * time.Second,
+					PolicyTTL:             60 * time.Second,
+					ReplicationToken:      "foobar",
+					TokenMinExpirationTTL: 2 * time.Minute,
+					TokenMaxExpirationTTL: 48 * time.Hour,
 				},
 				Telemetry: &Telemetry{
 					StatsiteAddr:               "127.0.0.1:1234",
@@ -167,6 +230,9 @@ func TestConfig_Parse(t *testing.T) {
 					AllowUnauthenticated: &trueValue,
 					Enabled:              &falseValue,
 					Role:                 "test_role",
+					JWTSigningKeyFile:    "/path/to/identity/key",
+					JWTAuthPath:          "nomad-jwt",
+					JWTAuthRole:          "workloads",
 					TLSCaFile:            "/path/to/ca/file",
 					TLSCaPath:            "/path/to/ca",
 					TLSCertFile:          "/path/to/cert/file",
@@ -215,6 +281,31 @@ func TestConfig_Parse(t *testing.T) {
 					DisableUpgradeMigration: &trueValue,
 					EnableCustomUpgrades:    &trueValue,
 				},
+				Audit: &config.AuditConfig{
+					Enabled: &trueValue,
+					Sinks: []*config.AuditSink{
+						{
+							Name:              "file",
+							Type:              "file",
+							DeliveryGuarantee: "enforced",
+							Format:            "json",
+							Path:              "/var/lib/nomad/audit/audit.log",
+							RotateBytes:       100,
+							RotateDuration:    24 * time.Hour,
+							RotateDurationHCL: "24h",
+							RotateMaxFiles:    10,
+						},
+					},
+					Filters: []*config.AuditFilter{
+						{
+							Name:       "metrics",
+							Type:       "HTTPEvent",
+							Endpoints:  []string{"/v1/metrics"},
+							Stages:     []string{"*"},
+							Operations: []string{"*"},
+						},
+					},
+				},
 				Plugins: []*config.PluginConfig{
 					{
 						Name: "docker",
@@ -293,6 +384,25 @@ func TestConfig_Parse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"vault-clusters.hcl",
+			&Config{
+				Vault: &config.VaultConfig{
+					Enabled: &trueValue,
+					Addr:    "https://vault.service.consul:8200",
+				},
+				VaultClusters: map[string]*config.VaultConfig{
+					"eu": {
+						Name:            "eu",
+						Enabled:         &trueValue,
+						Addr:            "https://vault.eu.example.com:8200",
+						Namespace:       "nomad",
+						NomadNamespaces: []string{"web", "batch"},
+					},
+				},
+			},
+			false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -88,16 +88,19 @@ func TestConfig_Merge(t *testing.T) {
 			Options: map[string]string{
 				"foo": "bar",
 			},
-			NetworkSpeed:   100,
-			CpuCompute:     100,
-			MemoryMB:       100,
-			MaxKillTimeout: "20s",
-			ClientMaxPort:  19996,
+			ChrootCache:        true,
+			EphemeralDiskQuota: true,
+			NetworkSpeed:       100,
+			CpuCompute:         100,
+			MemoryMB:           100,
+			MaxKillTimeout:     "20s",
+			ClientMaxPort:      19996,
 			Reserved: &Resources{
 				CPU:           10,
 				MemoryMB:      10,
 				DiskMB:        10,
 				ReservedPorts: "1,10-30,55",
+				Cores:         "0",
 			},
 		},
 		Server: &ServerConfig{
@@ -176,6 +179,16 @@ func TestConfig_Merge(t *testing.T) {
 			DisableUpgradeMigration: &falseValue,
 			EnableCustomUpgrades:    &falseValue,
 		},
+		Audit: &config.AuditConfig{
+			Enabled: &falseValue,
+			Sinks: []*config.AuditSink{
+				{
+					Name: "file",
+					Type: "file",
+					Path: "/tmp/audit.log",
+				},
+			},
+		},
 		Plugins: []*config.PluginConfig{
 			{
 				Name: "docker",
@@ -244,18 +257,22 @@ func TestConfig_Merge(t *testing.T) {
 				"foo": "bar",
 				"baz": "zip",
 			},
-			ChrootEnv:      map[string]string{},
-			ClientMaxPort:  20000,
-			ClientMinPort:  22000,
-			NetworkSpeed:   105,
-			CpuCompute:     105,
-			MemoryMB:       105,
-			MaxKillTimeout: "50s",
+			ChrootEnv:          map[string]string{},
+			ChrootCache:        true,
+			EphemeralDiskQuota: true,
+			ClientMaxPort:      20000,
+			ClientMinPort:      22000,
+			NetworkSpeed:       105,
+			NetworkSegment:     "dmz",
+			CpuCompute:         105,
+			MemoryMB:           105,
+			MaxKillTimeout:     "50s",
 			Reserved: &Resources{
 				CPU:           15,
 				MemoryMB:      15,
 				DiskMB:        15,
 				ReservedPorts: "2,10-30,55",
+				Cores:         "1",
 			},
 			GCInterval:            6 * time.Second,
 			GCParallelDestroys:    6,
@@ -273,6 +290,7 @@ func TestConfig_Merge(t *testing.T) {
 			EnabledSchedulers:      []string{structs.JobTypeBatch},
 			NodeGCThreshold:        "12h",
 			HeartbeatGrace:         2 * time.Minute,
+			MissedHeartbeatGrace:   time.Minute,
 			MinHeartbeatTTL:        2 * time.Minute,
 			MaxHeartbeatsPerSecond: 200.0,
 			RejoinAfterLeave:       true,
@@ -282,12 +300,30 @@ func TestConfig_Merge(t *testing.T) {
 			NonVotingServer:        true,
 			RedundancyZone:         "bar",
 			UpgradeVersion:         "bar",
+			EnableEventBroker:      helper.BoolToPtr(false),
+			EventBufferSize:        helper.IntToPtr(50),
+			JobRateLimit: &JobRateLimit{
+				NamespaceRate: 5,
+				TokenRate:     1,
+				TokenBurst:    2,
+			},
+			DecisionLog: &DecisionLog{
+				Type:    "socket",
+				Address: "127.0.0.1:9090",
+			},
+			DeploymentWebhook: &DeploymentWebhook{
+				URL:     "https://alerts.example.com/nomad",
+				HMACKey: "secret",
+			},
 		},
 		ACL: &ACLConfig{
 			Enabled:          true,
 			TokenTTL:         20 * time.Second,
 			PolicyTTL:        20 * time.Second,
 			ReplicationToken: "foobar",
+
+			TokenMinExpirationTTL: 5 * time.Minute,
+			TokenMaxExpirationTTL: 12 * time.Hour,
 		},
 		Ports: &Ports{
 			HTTP: 20000,
@@ -302,6 +338,9 @@ func TestConfig_Merge(t *testing.T) {
 		AdvertiseAddrs: &AdvertiseAddrs{
 			RPC:  "127.0.0.2",
 			Serf: "127.0.0.2",
+			RPCSegments: map[string]string{
+				"dmz": "10.0.0.2",
+			},
 		},
 		HTTPAPIResponseHeaders: map[string]string{
 			"Access-Control-Allow-Origin":  "*",
@@ -354,6 +393,24 @@ func TestConfig_Merge(t *testing.T) {
 			DisableUpgradeMigration: &trueValue,
 			EnableCustomUpgrades:    &trueValue,
 		},
+		Audit: &config.AuditConfig{
+			Enabled: &trueValue,
+			Sinks: []*config.AuditSink{
+				{
+					Name:           "file",
+					Type:           "file",
+					Path:           "/var/log/audit.log",
+					RotateDuration: time.Hour,
+				},
+			},
+			Filters: []*config.AuditFilter{
+				{
+					Name:      "metrics",
+					Type:      "HTTPEvent",
+					Endpoints: []string{"/v1/metrics"},
+				},
+			},
+		},
 		Plugins: []*config.PluginConfig{
 			{
 				Name: "docker",
@@ -878,6 +935,45 @@ func TestConfig_normalizeAddrs(t *testing.T) {
 	}
 }
 
+func TestConfig_normalizeAddrs_RPCSegments(t *testing.T) {
+	c := &Config{
+		BindAddr: "169.254.1.5",
+		Ports: &Ports{
+			HTTP: 4646,
+			RPC:  4647,
+			Serf: 4648,
+		},
+		Addresses: &Addresses{},
+		AdvertiseAddrs: &AdvertiseAddrs{
+			RPCSegments: map[string]string{
+				"dmz":  "10.0.0.5",
+				"edge": "{{ \"192.168.0.5\" }}:5647",
+			},
+		},
+		Server: &ServerConfig{
+			Enabled: true,
+		},
+	}
+
+	if err := c.normalizeAddrs(); err != nil {
+		t.Fatalf("unable to normalize addresses: %s", err)
+	}
+
+	expected := map[string]string{
+		"dmz":  "10.0.0.5:4647",
+		"edge": "192.168.0.5:5647",
+	}
+	if !reflect.DeepEqual(c.AdvertiseAddrs.RPCSegments, expected) {
+		t.Fatalf("expected RPC segment advertise addresses %v, got %v", expected, c.AdvertiseAddrs.RPCSegments)
+	}
+
+	// An address is required for each segment
+	c.AdvertiseAddrs.RPCSegments = map[string]string{"dmz": ""}
+	if err := c.normalizeAddrs(); err == nil {
+		t.Fatalf("expected an error for a segment without an address")
+	}
+}
+
 func TestIsMissingPort(t *testing.T) {
 	_, _, err := net.SplitHostPort("localhost")
 	if missing := isMissingPort(err); !missing {
@@ -1048,6 +1144,13 @@ func TestTelemetry_Parse(t *testing.T) {
 		prefix_filter = ["+nomad.raft"]
 		filter_default = false
 		disable_dispatched_job_summary_metrics = true
+		otlp_endpoint = "http://127.0.0.1:4318"
+		otlp_headers {
+			"x-api-key" = "secret"
+		}
+		trace_sample_rate = 0.25
+		allocation_metrics_labels = ["job", "task_group", "task"]
+		allocation_metrics_limit = 100
 	}`), 0600)
 	require.NoError(err)
 
@@ -1058,4 +1161,50 @@ func TestTelemetry_Parse(t *testing.T) {
 	require.False(*config.Telemetry.FilterDefault)
 	require.Exactly([]string{"+nomad.raft"}, config.Telemetry.PrefixFilter)
 	require.True(config.Telemetry.DisableDispatchedJobSummaryMetrics)
+	require.Equal("http://127.0.0.1:4318", config.Telemetry.OTLPEndpoint)
+	require.Equal(map[string]string{"x-api-key": "secret"}, config.Telemetry.OTLPHeaders)
+	require.Equal(0.25, *config.Telemetry.TraceSampleRate)
+	require.Equal([]string{"job", "task_group", "task"}, config.Telemetry.AllocationMetricsLabels)
+	require.Equal(100, config.Telemetry.AllocationMetricsLimit)
+
+	// Sample rates are ratios
+	file2 := filepath.Join(dir, "config2.hcl")
+	err = ioutil.WriteFile(file2, []byte(`telemetry{
+		trace_sample_rate = 2
+	}`), 0600)
+	require.NoError(err)
+	_, err = LoadConfig(dir)
+	require.Error(err)
+	require.Contains(err.Error(), "trace_sample_rate must be between 0 and 1")
+
+	// Only the labels of allocation metrics may be allowed
+	err = ioutil.WriteFile(file2, []byte(`telemetry{
+		allocation_metrics_labels = ["job", "node_id"]
+	}`), 0600)
+	require.NoError(err)
+	_, err = LoadConfig(dir)
+	require.Error(err)
+	require.Contains(err.Error(), "invalid allocation_metrics_labels [node_id]")
+}
+
+func TestMergeAllocHooks(t *testing.T) {
+	require := require.New(t)
+
+	a := &ClientConfig{
+		AllocHooks: []*AllocHook{
+			{Name: "register", Event: "poststart", Command: "/bin/register"},
+			{Name: "flush", Event: "prestop", Command: "/bin/flush"},
+		},
+	}
+	b := &ClientConfig{
+		AllocHooks: []*AllocHook{
+			{Name: "flush", Event: "prestop", Command: "/bin/flush", Timeout: "1m"},
+		},
+	}
+
+	result := a.Merge(b)
+	require.Equal([]*AllocHook{
+		{Name: "register", Event: "poststart", Command: "/bin/register"},
+		{Name: "flush", Event: "prestop", Command: "/bin/flush", Timeout: "1m"},
+	}, result.AllocHooks)
 }
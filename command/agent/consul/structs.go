@@ -2,6 +2,7 @@ package consul
 
 import (
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/plugins/drivers"
 )
@@ -9,6 +10,10 @@ import (
 type TaskServices struct {
 	AllocID string
 
+	// Namespace and JobID of the allocation
+	Namespace string
+	JobID     string
+
 	// Name of the task
 	Name string
 
@@ -35,6 +40,8 @@ type TaskServices struct {
 func NewTaskServices(alloc *structs.Allocation, task *structs.Task, restarter TaskRestarter, exec interfaces.ScriptExecutor, net *drivers.DriverNetwork) *TaskServices {
 	ts := TaskServices{
 		AllocID:       alloc.ID,
+		Namespace:     alloc.Namespace,
+		JobID:         alloc.JobID,
 		Name:          task.Name,
 		Restarter:     restarter,
 		Services:      task.Services,
@@ -58,6 +65,28 @@ func NewTaskServices(alloc *structs.Allocation, task *structs.Task, restarter Ta
 	return &ts
 }
 
+// ServiceID returns the ID the service is registered with.
+func (t *TaskServices) ServiceID(service *structs.Service) string {
+	return makeTaskServiceID(t.AllocID, t.Name, service, t.Canary)
+}
+
+// ServiceAddress returns the IP and port the service is advertised on.
+func (t *TaskServices) ServiceAddress(service *structs.Service) (string, int, error) {
+	addrMode := service.AddressMode
+	if addrMode == "" {
+		addrMode = structs.AddressModeAuto
+	}
+	return getAddress(addrMode, service.PortLabel, t.Networks, t.DriverNetwork)
+}
+
+// ServiceTags returns the tags the service is registered with.
+func (t *TaskServices) ServiceTags(service *structs.Service) []string {
+	if t.Canary && len(service.CanaryTags) > 0 {
+		return helper.CopySliceString(service.CanaryTags)
+	}
+	return helper.CopySliceString(service.Tags)
+}
+
 // Copy method for easing tests
 func (t *TaskServices) Copy() *TaskServices {
 	newTS := new(TaskServices)
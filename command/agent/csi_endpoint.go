@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func (s *HTTPServer) CSIVolumesRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.CSIVolumeListRequest{
+		PluginID: req.URL.Query().Get("plugin_id"),
+		NodeID:   req.URL.Query().Get("node_id"),
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.CSIVolumeListResponse
+	if err := s.agent.RPC("CSIVolume.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Volumes == nil {
+		out.Volumes = make([]*structs.CSIVolumeListStub, 0)
+	}
+	return out.Volumes, nil
+}
+
+// CSIVolumeSpecificRequest dispatches GET, PUT and DELETE requests for a CSI
+// volume and the create, delete and detach actions on it
+func (s *HTTPServer) CSIVolumeSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/volume/csi/")
+	id := path
+	action := ""
+	if i := strings.LastIndex(path, "/"); i != -1 {
+		id, action = path[:i], path[i+1:]
+	}
+	if id == "" {
+		return nil, CodedError(400, "Missing volume ID")
+	}
+
+	switch action {
+	case "":
+		switch req.Method {
+		case "GET":
+			return s.csiVolumeGet(resp, req, id)
+		case "PUT", "POST":
+			return s.csiVolumeRegister(resp, req)
+		case "DELETE":
+			return s.csiVolumeDeregister(resp, req, id)
+		}
+	case "create":
+		if req.Method == "PUT" || req.Method == "POST" {
+			return s.csiVolumeCreate(resp, req)
+		}
+	case "delete":
+		if req.Method == "DELETE" {
+			return s.csiVolumeDelete(resp, req, id)
+		}
+	case "detach":
+		if req.Method == "DELETE" {
+			return s.csiVolumeDetach(resp, req, id)
+		}
+	default:
+		return nil, CodedError(404, resourceNotFoundErr)
+	}
+	return nil, CodedError(405, ErrInvalidMethod)
+}
+
+func (s *HTTPServer) csiVolumeGet(resp http.ResponseWriter, req *http.Request, id string) (interface{}, error) {
+	args := structs.CSIVolumeGetRequest{
+		ID: id,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.CSIVolumeGetResponse
+	if err := s.agent.RPC("CSIVolume.Get", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Volume == nil {
+		return nil, CodedError(404, "volume not found")
+	}
+	return out.Volume, nil
+}
+
+func (s *HTTPServer) csiVolumeRegister(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.CSIVolumeRegisterRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("CSIVolume.Register", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) csiVolumeDeregister(resp http.ResponseWriter, req *http.Request, id string) (interface{}, error) {
+	force := false
+	if raw := req.URL.Query().Get("force"); raw != "" {
+		var err error
+		force, err = strconv.ParseBool(raw)
+		if err != nil {
+			return nil, CodedError(400, "invalid force value")
+		}
+	}
+
+	args := structs.CSIVolumeDeregisterRequest{
+		VolumeIDs: []string{id},
+		Force:     force,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("CSIVolume.Deregister", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) csiVolumeCreate(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.CSIVolumeCreateRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.CSIVolumeCreateResponse
+	if err := s.agent.RPC("CSIVolume.Create", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return out.Volumes, nil
+}
+
+func (s *HTTPServer) csiVolumeDelete(resp http.ResponseWriter, req *http.Request, id string) (interface{}, error) {
+	args := structs.CSIVolumeDeleteRequest{
+		VolumeIDs: []string{id},
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("CSIVolume.Delete", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) csiVolumeDetach(resp http.ResponseWriter, req *http.Request, id string) (interface{}, error) {
+	allocID := req.URL.Query().Get("alloc")
+	if allocID == "" {
+		return nil, CodedError(400, "Missing alloc ID")
+	}
+
+	args := structs.CSIVolumeUnpublishRequest{
+		VolumeID:     id,
+		AllocationID: allocID,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("CSIVolume.Unpublish", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) CSIPluginsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.CSIPluginListRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.CSIPluginListResponse
+	if err := s.agent.RPC("CSIPlugin.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Plugins == nil {
+		out.Plugins = make([]*structs.CSIPluginListStub, 0)
+	}
+	return out.Plugins, nil
+}
+
+func (s *HTTPServer) CSIPluginSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	id := strings.TrimPrefix(req.URL.Path, "/v1/plugin/csi/")
+	if id == "" {
+		return nil, CodedError(400, "Missing plugin ID")
+	}
+
+	args := structs.CSIPluginGetRequest{
+		ID: id,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.CSIPluginGetResponse
+	if err := s.agent.RPC("CSIPlugin.Get", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Plugin == nil {
+		return nil, CodedError(404, "plugin not found")
+	}
+	return out.Plugin, nil
+}
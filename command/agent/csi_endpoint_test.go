@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_CSIVolumes(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		require.NoError(state.UpsertNode(1000, mock.CSINode("ebs")))
+
+		// Register a volume
+		vol := mock.CSIVolume("ebs")
+		buf, err := json.Marshal(structs.CSIVolumeRegisterRequest{Volumes: []*structs.CSIVolume{vol}})
+		require.NoError(err)
+		req, err := http.NewRequest("PUT", "/v1/volume/csi/"+vol.ID, bytes.NewReader(buf))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		_, err = s.Server.CSIVolumeSpecificRequest(respW, req)
+		require.NoError(err)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// List the volumes of the plugin
+		req, err = http.NewRequest("GET", "/v1/volumes?plugin_id=ebs", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err := s.Server.CSIVolumesRequest(respW, req)
+		require.NoError(err)
+
+		stubs := obj.([]*structs.CSIVolumeListStub)
+		require.Len(stubs, 1)
+		require.Equal(vol.ID, stubs[0].ID)
+		require.True(stubs[0].Schedulable)
+
+		// Get the volume
+		req, err = http.NewRequest("GET", "/v1/volume/csi/"+vol.ID, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.CSIVolumeSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Equal(vol.ExternalID, obj.(*structs.CSIVolume).ExternalID)
+
+		// Unknown actions are not found
+		req, err = http.NewRequest("PUT", "/v1/volume/csi/"+vol.ID+"/foo", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.CSIVolumeSpecificRequest(respW, req)
+		require.Error(err)
+		require.Equal(404, err.(HTTPCodedError).Code())
+
+		// Deregister the volume
+		req, err = http.NewRequest("DELETE", "/v1/volume/csi/"+vol.ID, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.CSIVolumeSpecificRequest(respW, req)
+		require.NoError(err)
+
+		req, err = http.NewRequest("GET", "/v1/volume/csi/"+vol.ID, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.CSIVolumeSpecificRequest(respW, req)
+		require.Error(err)
+		require.Equal(404, err.(HTTPCodedError).Code())
+	})
+}
+
+func TestHTTP_CSIPlugins(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		require.NoError(state.UpsertNode(1000, mock.CSINode("ebs")))
+
+		req, err := http.NewRequest("GET", "/v1/plugins", nil)
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.CSIPluginsRequest(respW, req)
+		require.NoError(err)
+		require.Equal("1000", respW.HeaderMap.Get("X-Nomad-Index"))
+
+		stubs := obj.([]*structs.CSIPluginListStub)
+		require.Len(stubs, 1)
+		require.Equal("ebs", stubs[0].ID)
+		require.Equal(1, stubs[0].NodesHealthy)
+
+		req, err = http.NewRequest("GET", "/v1/plugin/csi/ebs", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.CSIPluginSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Len(obj.(*structs.CSIPlugin).Nodes, 1)
+	})
+}
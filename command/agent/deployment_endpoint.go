@@ -12,7 +12,9 @@ func (s *HTTPServer) DeploymentsRequest(resp http.ResponseWriter, req *http.Requ
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	args := structs.DeploymentListRequest{}
+	args := structs.DeploymentListRequest{
+		Status: req.URL.Query().Get("status"),
+	}
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
 	}
@@ -47,6 +49,9 @@ func (s *HTTPServer) DeploymentSpecificRequest(resp http.ResponseWriter, req *ht
 	case strings.HasPrefix(path, "allocation-health/"):
 		deploymentID := strings.TrimPrefix(path, "allocation-health/")
 		return s.deploymentSetAllocHealth(resp, req, deploymentID)
+	case strings.HasPrefix(path, "group-health/"):
+		deploymentID := strings.TrimPrefix(path, "group-health/")
+		return s.deploymentSetGroupHealth(resp, req, deploymentID)
 	default:
 		return s.deploymentQuery(resp, req, path)
 	}
@@ -145,6 +150,31 @@ func (s *HTTPServer) deploymentSetAllocHealth(resp http.ResponseWriter, req *htt
 	return out, nil
 }
 
+func (s *HTTPServer) deploymentSetGroupHealth(resp http.ResponseWriter, req *http.Request, deploymentID string) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var healthRequest structs.DeploymentGroupHealthRequest
+	if err := decodeBody(req, &healthRequest); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if healthRequest.DeploymentID == "" {
+		return nil, CodedError(400, "DeploymentID must be specified")
+	}
+	if healthRequest.DeploymentID != deploymentID {
+		return nil, CodedError(400, "Deployment ID does not match")
+	}
+	s.parseWriteRequest(req, &healthRequest.WriteRequest)
+
+	var out structs.DeploymentUpdateResponse
+	if err := s.agent.RPC("Deployment.SetGroupHealth", &healthRequest, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
 func (s *HTTPServer) deploymentAllocations(resp http.ResponseWriter, req *http.Request, deploymentID string) (interface{}, error) {
 	if req.Method != "GET" {
 		return nil, CodedError(405, ErrInvalidMethod)
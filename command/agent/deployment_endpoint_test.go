@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTP_DeploymentList(t *testing.T) {
@@ -292,6 +293,56 @@ func TestHTTP_DeploymentAllocHealth(t *testing.T) {
 	})
 }
 
+func TestHTTP_DeploymentGroupHealth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		j := mock.Job()
+		d := mock.Deployment()
+		d.JobID = j.ID
+		a := mock.Alloc()
+		a.JobID = j.ID
+		a.DeploymentID = d.ID
+		require.Nil(state.UpsertJob(998, j))
+		require.Nil(state.UpsertDeployment(999, d))
+		require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{a}))
+
+		// Create the group health request
+		args := structs.DeploymentGroupHealthRequest{
+			DeploymentID: d.ID,
+			Groups:       []string{"web"},
+			Reason:       "verified externally",
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		buf := encodeReq(args)
+
+		// Make the HTTP request
+		req, err := http.NewRequest("PUT", "/v1/deployment/group-health/"+d.ID, buf)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.DeploymentSpecificRequest(respW, req)
+		require.Nil(err)
+
+		// Check the response
+		resp := obj.(structs.DeploymentUpdateResponse)
+		require.NotZero(resp.EvalID)
+		require.NotZero(resp.DeploymentModifyIndex)
+		require.NotZero(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		dout, err := state.DeploymentByID(nil, d.ID)
+		require.Nil(err)
+		require.Len(dout.TaskGroups["web"].HealthOverrides, 1)
+		require.Equal("verified externally", dout.TaskGroups["web"].HealthOverrides[0].Reason)
+	})
+}
+
 func TestHTTP_DeploymentFail(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -12,7 +12,9 @@ func (s *HTTPServer) EvalsRequest(resp http.ResponseWriter, req *http.Request) (
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	args := structs.EvalListRequest{}
+	args := structs.EvalListRequest{
+		Status: req.URL.Query().Get("status"),
+	}
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
 	}
@@ -29,12 +31,37 @@ func (s *HTTPServer) EvalsRequest(resp http.ResponseWriter, req *http.Request) (
 	return out.Evaluations, nil
 }
 
+func (s *HTTPServer) EvalPlacementFailuresRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.EvalPlacementFailuresRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.EvalPlacementFailuresResponse
+	if err := s.agent.RPC("Eval.PlacementFailures", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Failures == nil {
+		out.Failures = make([]*structs.PlacementFailure, 0)
+	}
+	return out.Failures, nil
+}
+
 func (s *HTTPServer) EvalSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	path := strings.TrimPrefix(req.URL.Path, "/v1/evaluation/")
 	switch {
 	case strings.HasSuffix(path, "/allocations"):
 		evalID := strings.TrimSuffix(path, "/allocations")
 		return s.evalAllocations(resp, req, evalID)
+	case strings.HasSuffix(path, "/related"):
+		evalID := strings.TrimSuffix(path, "/related")
+		return s.evalRelated(resp, req, evalID)
 	default:
 		return s.evalQuery(resp, req, path)
 	}
@@ -64,6 +91,30 @@ func (s *HTTPServer) evalAllocations(resp http.ResponseWriter, req *http.Request
 	return out.Allocations, nil
 }
 
+func (s *HTTPServer) evalRelated(resp http.ResponseWriter, req *http.Request, evalID string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.EvalSpecificRequest{
+		EvalID: evalID,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.EvalRelatedResponse
+	if err := s.agent.RPC("Eval.Related", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Evaluations == nil {
+		return nil, CodedError(404, "eval not found")
+	}
+	return out.Evaluations, nil
+}
+
 func (s *HTTPServer) evalQuery(resp http.ResponseWriter, req *http.Request, evalID string) (interface{}, error) {
 	if req.Method != "GET" {
 		return nil, CodedError(405, ErrInvalidMethod)
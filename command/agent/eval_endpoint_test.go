@@ -5,8 +5,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTP_EvalList(t *testing.T) {
@@ -54,6 +56,44 @@ func TestHTTP_EvalList(t *testing.T) {
 	})
 }
 
+func TestHTTP_EvalPlacementFailures(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		eval := mock.Eval()
+		eval.Status = structs.EvalStatusBlocked
+		eval.FailedTGAllocs = map[string]*structs.AllocMetric{
+			"web": {NodesExhausted: 1},
+		}
+		require.NoError(state.UpsertEvals(1000, []*structs.Evaluation{eval}))
+
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/evaluations/placement-failures", nil)
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.EvalPlacementFailuresRequest(respW, req)
+		require.NoError(err)
+		require.Equal("1000", respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Check the placement failures
+		failures := obj.([]*structs.PlacementFailure)
+		require.Len(failures, 1)
+		require.Equal(eval.ID, failures[0].EvalID)
+		require.Equal("web", failures[0].TaskGroup)
+		require.Equal(1, failures[0].Metrics.NodesExhausted)
+
+		// Other methods are not allowed
+		req, err = http.NewRequest("PUT", "/v1/evaluations/placement-failures", nil)
+		require.NoError(err)
+		_, err = s.Server.EvalPlacementFailuresRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+	})
+}
+
 func TestHTTP_EvalPrefixList(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -155,6 +195,41 @@ func TestHTTP_EvalAllocations(t *testing.T) {
 	})
 }
 
+func TestHTTP_EvalRelated(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		eval1 := mock.Eval()
+		eval2 := mock.Eval()
+		eval2.JobID = eval1.JobID
+		eval2.PreviousEval = eval1.ID
+		eval1.NextEval = eval2.ID
+		require.NoError(state.UpsertEvals(1000, []*structs.Evaluation{eval1, eval2}))
+
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/evaluation/"+eval2.ID+"/related", nil)
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.EvalSpecificRequest(respW, req)
+		require.NoError(err)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		evals := obj.([]*structs.Evaluation)
+		require.Len(evals, 2)
+
+		// Unknown evaluations are not found
+		req, err = http.NewRequest("GET", "/v1/evaluation/"+uuid.Generate()+"/related", nil)
+		require.NoError(err)
+		_, err = s.Server.EvalSpecificRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+		require.Contains(err.Error(), "eval not found")
+	})
+}
+
 func TestHTTP_EvalQuery(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// EventStream streams the events of state changes as newline delimited JSON.
+// The parameters are:
+// * topic: A topic to subscribe to, optionally followed by a colon and the key
+//          of the events to receive, such as "Job:web". May be repeated.
+// * index: The raft index after which to stream events. Defaults to streaming
+//          only new events.
+func (s *HTTPServer) EventStream(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	q := req.URL.Query()
+	topics, err := parseEventTopics(q["topic"])
+	if err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+
+	args := &structs.EventStreamRequest{
+		Topics: topics,
+	}
+	if indexStr := q.Get("index"); indexStr != "" {
+		index, err := strconv.ParseUint(indexStr, 10, 64)
+		if err != nil {
+			return nil, CodedError(400, fmt.Sprintf("failed to parse index: %v", err))
+		}
+		args.Index = index
+	}
+	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
+
+	handler, err := s.serverStreamingRpcHandler("Event.Stream")
+	if err != nil {
+		return nil, CodedError(500, err.Error())
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	return s.streamingRpcImpl(resp, req, handler, args)
+}
+
+// parseEventTopics parses topic query parameters of the form "Topic:key" into
+// the keys of each topic. A topic without a key matches all keys. All topics
+// are subscribed to if none are given.
+func parseEventTopics(params []string) (map[structs.Topic][]string, error) {
+	topics := make(map[structs.Topic][]string)
+	if len(params) == 0 {
+		topics[structs.TopicAll] = []string{"*"}
+		return topics, nil
+	}
+
+	for _, param := range params {
+		parts := strings.SplitN(param, ":", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("invalid topic %q", param)
+		}
+
+		key := "*"
+		if len(parts) == 2 {
+			if parts[1] == "" {
+				return nil, fmt.Errorf("invalid topic %q: key must not be empty", param)
+			}
+			key = parts[1]
+		}
+		topic := structs.Topic(parts[0])
+		topics[topic] = append(topics[topic], key)
+	}
+	return topics, nil
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_EventStream_ParseTopics(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// No topics subscribes to everything
+	topics, err := parseEventTopics(nil)
+	require.NoError(err)
+	require.Equal(map[structs.Topic][]string{structs.TopicAll: {"*"}}, topics)
+
+	topics, err = parseEventTopics([]string{"Job:web", "Job:api", "Node"})
+	require.NoError(err)
+	require.Equal(map[structs.Topic][]string{
+		structs.TopicJob:  {"web", "api"},
+		structs.TopicNode: {"*"},
+	}, topics)
+
+	_, err = parseEventTopics([]string{":web"})
+	require.Error(err)
+	_, err = parseEventTopics([]string{"Job:"})
+	require.Error(err)
+}
+
+func TestHTTP_EventStream(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Register a job before subscribing so that it is replayed
+		job := mock.Job()
+		args := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		require.NoError(s.Agent.RPC("Job.Register", &args, &resp))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		url := fmt.Sprintf("%s/v1/event/stream?topic=Job:%s&index=1", s.HTTPAddr(), job.ID)
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(err)
+		httpResp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		require.NoError(err)
+		defer httpResp.Body.Close()
+		require.Equal(200, httpResp.StatusCode)
+
+		scanner := bufio.NewScanner(httpResp.Body)
+		for scanner.Scan() {
+			var events structs.Events
+			require.NoError(json.Unmarshal(scanner.Bytes(), &events))
+
+			// Skip heartbeats
+			if len(events.Events) == 0 {
+				continue
+			}
+			require.Equal(resp.JobModifyIndex, events.Index)
+			require.Equal(structs.TypeJobRegistered, events.Events[0].Type)
+			require.Equal(job.ID, events.Events[0].Key)
+			return
+		}
+		t.Fatalf("stream ended: %v", scanner.Err())
+	})
+}
@@ -40,6 +40,8 @@ func (s *HTTPServer) FsRequest(resp http.ResponseWriter, req *http.Request) (int
 		return s.Stream(resp, req)
 	case strings.HasPrefix(path, "logs/"):
 		return s.Logs(resp, req)
+	case strings.HasPrefix(path, "archive/"):
+		return s.FileArchiveRequest(resp, req)
 	default:
 		return nil, CodedError(404, ErrInvalidMethod)
 	}
@@ -100,10 +102,22 @@ func (s *HTTPServer) FileStatRequest(resp http.ResponseWriter, req *http.Request
 		AllocID: allocID,
 		Path:    path,
 	}
+	if checksum := req.URL.Query().Get("checksum"); checksum != "" {
+		var err error
+		if args.Checksum, err = strconv.ParseBool(checksum); err != nil {
+			return nil, CodedError(400, fmt.Sprintf("error parsing checksum: %v", err))
+		}
+	}
 	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
 
+	return s.fileStat(args)
+}
+
+// fileStat stats a file using the handler that can reach the node of the
+// allocation.
+func (s *HTTPServer) fileStat(args *cstructs.FsStatRequest) (*cstructs.AllocFileInfo, error) {
 	// Make the RPC
-	localClient, remoteClient, localServer := s.rpcHandlerForAlloc(allocID)
+	localClient, remoteClient, localServer := s.rpcHandlerForAlloc(args.AllocID)
 
 	var reply cstructs.FsStatResponse
 	var rpcErr error
@@ -187,10 +201,142 @@ func (s *HTTPServer) FileCatRequest(resp http.ResponseWriter, req *http.Request)
 	}
 	s.parse(resp, req, &fsReq.QueryOptions.Region, &fsReq.QueryOptions)
 
+	// Serve a single byte range of the file if requested
+	resp.Header().Set("Accept-Ranges", "bytes")
+	if byteRange := req.Header.Get("Range"); byteRange != "" {
+		info, err := s.fileStat(&cstructs.FsStatRequest{
+			AllocID:      allocID,
+			Path:         path,
+			QueryOptions: fsReq.QueryOptions,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir {
+			return nil, CodedError(400, fmt.Sprintf("file %q is a directory", path))
+		}
+
+		offset, length, err := parseByteRange(byteRange, info.Size)
+		if err != nil {
+			resp.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			return nil, CodedError(http.StatusRequestedRangeNotSatisfiable, err.Error())
+		}
+		fsReq.Offset = offset
+		fsReq.Limit = length
+
+		// The file may change after it was stat'd, so the length isn't
+		// declared and the status is only sent with the content, leaving
+		// errors before then to be reported with their own status
+		w := &partialContentWriter{
+			ResponseWriter: resp,
+			contentRange:   fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size),
+		}
+		return s.fsStreamImpl(w, req, "FileSystem.Stream", fsReq, fsReq.AllocID)
+	}
+
 	// Make the request
 	return s.fsStreamImpl(resp, req, "FileSystem.Stream", fsReq, fsReq.AllocID)
 }
 
+// partialContentWriter writes the status and Content-Range header of a
+// partial content response along with the first write of the body.
+type partialContentWriter struct {
+	http.ResponseWriter
+	contentRange string
+	wroteHeader  bool
+}
+
+func (w *partialContentWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Content-Range", w.contentRange)
+		w.ResponseWriter.WriteHeader(http.StatusPartialContent)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *partialContentWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// parseByteRange parses the value of a Range header selecting a single range
+// of bytes of a file of the given size. It returns the offset and length of
+// the range, or an error if it is invalid or can't be satisfied.
+func parseByteRange(header string, size int64) (offset, length int64, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return 0, 0, fmt.Errorf("invalid range %q: only byte ranges are supported", header)
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if strings.Contains(spec, ",") {
+		return 0, 0, fmt.Errorf("invalid range %q: multiple ranges are not supported", header)
+	}
+
+	i := strings.Index(spec, "-")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", header)
+	}
+	startStr, endStr := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+	if startStr == "" {
+		// A suffix range selects the last bytes of the file
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, fmt.Errorf("invalid range %q", header)
+		}
+		if size == 0 {
+			return 0, 0, fmt.Errorf("range %q is not satisfiable for an empty file", header)
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", header)
+	}
+	if start >= size {
+		return 0, 0, fmt.Errorf("range %q starts beyond the end of the file", header)
+	}
+
+	end := size - 1
+	if endStr != "" {
+		if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid range %q", header)
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, nil
+}
+
+// FileArchiveRequest streams a tar archive of the file or directory at the
+// path, recursing into directories.
+func (s *HTTPServer) FileArchiveRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var allocID, path string
+	if allocID = strings.TrimPrefix(req.URL.Path, "/v1/client/fs/archive/"); allocID == "" {
+		return nil, allocIDNotPresentErr
+	}
+	if path = req.URL.Query().Get("path"); path == "" {
+		path = "/"
+	}
+
+	// Create the request arguments
+	fsReq := &cstructs.FsArchiveRequest{
+		AllocID: allocID,
+		Path:    path,
+	}
+	s.parse(resp, req, &fsReq.QueryOptions.Region, &fsReq.QueryOptions)
+
+	resp.Header().Set("Content-Type", "application/x-tar")
+	return s.fsStreamImpl(resp, req, "FileSystem.Archive", fsReq, fsReq.AllocID)
+}
+
 // Stream streams the content of a file blocking on EOF.
 // The parameters are:
 // * path: path to file to stream.
@@ -316,9 +462,8 @@ func (s *HTTPServer) Logs(resp http.ResponseWriter, req *http.Request) (interfac
 	return s.fsStreamImpl(resp, req, "FileSystem.Logs", fsReq, fsReq.AllocID)
 }
 
-// fsStreamImpl is used to make a streaming filesystem call that serializes the
-// args and then expects a stream of StreamErrWrapper results where the payload
-// is copied to the response body.
+// fsStreamImpl is used to make a streaming filesystem call using the handler
+// that can reach the node of the allocation.
 func (s *HTTPServer) fsStreamImpl(resp http.ResponseWriter,
 	req *http.Request, method string, args interface{}, allocID string) (interface{}, error) {
 
@@ -338,6 +483,24 @@ func (s *HTTPServer) fsStreamImpl(resp http.ResponseWriter,
 		return nil, CodedError(500, handlerErr.Error())
 	}
 
+	return s.streamingRpcImpl(resp, req, handler, args)
+}
+
+// serverStreamingRpcHandler returns the handler of a streaming RPC handled
+// by the servers, which is forwarded to a server by client agents.
+func (s *HTTPServer) serverStreamingRpcHandler(method string) (structs.StreamingRpcHandler, error) {
+	if server := s.agent.Server(); server != nil {
+		return server.StreamingRpcHandler(method)
+	}
+	return s.agent.Client().RemoteStreamingRpcHandler(method)
+}
+
+// streamingRpcImpl is used to make a streaming RPC call that serializes the
+// args and then expects a stream of StreamErrWrapper results where the payload
+// is copied to the response body.
+func (s *HTTPServer) streamingRpcImpl(resp http.ResponseWriter, req *http.Request,
+	handler structs.StreamingRpcHandler, args interface{}) (interface{}, error) {
+
 	// Create a pipe connecting the (possibly remote) handler to the http response
 	httpPipe, handlerPipe := net.Pipe()
 	decoder := codec.NewDecoder(httpPipe, structs.MsgpackHandle)
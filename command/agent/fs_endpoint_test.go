@@ -1,7 +1,10 @@
 package agent
 
 import (
+	"archive/tar"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -341,6 +344,144 @@ func TestHTTP_FS_Cat(t *testing.T) {
 	})
 }
 
+func TestHTTP_FS_Cat_Range(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		a := mockFSAlloc(s.client.NodeID(), nil)
+		addAllocToClient(s, a, terminalClientAlloc)
+
+		path := fmt.Sprintf("/v1/client/fs/cat/%s?path=alloc/logs/web.stdout.0", a.ID)
+		size := len(defaultLoggerMockDriverStdout)
+
+		req, err := http.NewRequest("GET", path, nil)
+		require.Nil(err)
+		req.Header.Set("Range", "bytes=1-3")
+		respW := httptest.NewRecorder()
+		_, err = s.Server.FileCatRequest(respW, req)
+		require.Nil(err)
+
+		require.Equal(http.StatusPartialContent, respW.Code)
+		require.Equal(fmt.Sprintf("bytes 1-3/%d", size), respW.Header().Get("Content-Range"))
+		output, err := ioutil.ReadAll(respW.Result().Body)
+		require.Nil(err)
+		require.EqualValues(defaultLoggerMockDriverStdout[1:4], output)
+
+		// Ranges starting beyond the end of the file are not satisfiable
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", size))
+		respW = httptest.NewRecorder()
+		_, err = s.Server.FileCatRequest(respW, req)
+		require.Error(err)
+		codedErr, ok := err.(HTTPCodedError)
+		require.True(ok)
+		require.Equal(http.StatusRequestedRangeNotSatisfiable, codedErr.Code())
+		require.Equal(fmt.Sprintf("bytes */%d", size), respW.Header().Get("Content-Range"))
+	})
+}
+
+func TestHTTP_FS_partialContentWriter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Nothing is committed until the content is written
+	respW := httptest.NewRecorder()
+	w := &partialContentWriter{ResponseWriter: respW, contentRange: "bytes 1-3/10"}
+	require.False(respW.Flushed)
+	require.Empty(respW.Header().Get("Content-Range"))
+
+	_, err := w.Write([]byte("abc"))
+	require.NoError(err)
+	w.Flush()
+	require.Equal(http.StatusPartialContent, respW.Code)
+	require.Equal("bytes 1-3/10", respW.Header().Get("Content-Range"))
+	require.True(respW.Flushed)
+	require.Equal("abc", respW.Body.String())
+}
+
+func TestHTTP_FS_parseByteRange(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		header string
+		offset int64
+		length int64
+		err    string
+	}{
+		{header: "bytes=0-9", offset: 0, length: 10},
+		{header: "bytes=5-", offset: 5, length: 15},
+		{header: "bytes=-5", offset: 15, length: 5},
+		{header: "bytes=-50", offset: 0, length: 20},
+		{header: "bytes=10-100", offset: 10, length: 10},
+		{header: "bytes=20-", err: "beyond the end"},
+		{header: "bytes=5-2", err: "invalid range"},
+		{header: "bytes=0-1,3-4", err: "multiple ranges"},
+		{header: "lines=0-1", err: "only byte ranges"},
+	}
+
+	for _, c := range cases {
+		offset, length, err := parseByteRange(c.header, 20)
+		if c.err != "" {
+			require.Error(t, err, c.header)
+			require.Contains(t, err.Error(), c.err, c.header)
+			continue
+		}
+		require.NoError(t, err, c.header)
+		require.Equal(t, c.offset, offset, c.header)
+		require.Equal(t, c.length, length, c.header)
+	}
+}
+
+func TestHTTP_FS_Stat_Checksum(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		a := mockFSAlloc(s.client.NodeID(), nil)
+		addAllocToClient(s, a, terminalClientAlloc)
+
+		path := fmt.Sprintf("/v1/client/fs/stat/%s?path=alloc/logs/web.stdout.0&checksum=true", a.ID)
+		req, err := http.NewRequest("GET", path, nil)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+		raw, err := s.Server.FileStatRequest(respW, req)
+		require.Nil(err)
+
+		sum := sha256.Sum256([]byte(defaultLoggerMockDriverStdout))
+		info, ok := raw.(*cstructs.AllocFileInfo)
+		require.True(ok)
+		require.Equal("sha256:"+hex.EncodeToString(sum[:]), info.Checksum)
+	})
+}
+
+func TestHTTP_FS_Archive(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		a := mockFSAlloc(s.client.NodeID(), nil)
+		addAllocToClient(s, a, terminalClientAlloc)
+
+		path := fmt.Sprintf("/v1/client/fs/archive/%s?path=alloc/logs", a.ID)
+		req, err := http.NewRequest("GET", path, nil)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+		_, err = s.Server.FileArchiveRequest(respW, req)
+		require.Nil(err)
+		require.Equal("application/x-tar", respW.Header().Get("Content-Type"))
+
+		files := make(map[string]string)
+		tr := tar.NewReader(respW.Result().Body)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.Nil(err)
+			contents, err := ioutil.ReadAll(tr)
+			require.Nil(err)
+			files[hdr.Name] = string(contents)
+		}
+		require.Equal(defaultLoggerMockDriverStdout, files["alloc/logs/web.stdout.0"])
+	})
+}
+
 func TestHTTP_FS_Stream(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/nomad/command/agent/audit"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// auditErrorLimit is the number of bytes of the body of failed responses
+	// recorded as their error in audit events.
+	auditErrorLimit = 1024
+)
+
+// auditHandler wraps the handler to emit an audit event when a request is
+// received and another one once it completes. Requests are rejected if the
+// first event can't be written to a sink enforcing delivery.
+func (s *HTTPServer) auditHandler(handler http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		auditor := s.agent.auditor
+		if !auditor.Enabled() {
+			handler(resp, req)
+			return
+		}
+
+		start := time.Now()
+		request := &audit.Request{
+			ID:         uuid.Generate(),
+			Operation:  req.Method,
+			Endpoint:   req.URL.Path,
+			RemoteAddr: req.RemoteAddr,
+			UserAgent:  req.UserAgent(),
+		}
+		s.parseRegion(req, &request.Region)
+		parseNamespace(req, &request.Namespace)
+
+		received := &audit.Event{
+			ID:        uuid.Generate(),
+			Stage:     audit.OperationReceived,
+			Timestamp: start,
+			Auth:      s.auditAuth(req),
+			Request:   request,
+		}
+		if err := auditor.Event(received); err != nil {
+			s.logger.Error("failed to audit request", "method", req.Method, "path", req.URL.Path, "error", err)
+			resp.WriteHeader(http.StatusInternalServerError)
+			resp.Write([]byte("failed to audit request"))
+			return
+		}
+
+		// Summarize the payload as it is read and record the response
+		payload := &auditPayload{ReadCloser: req.Body, hash: sha256.New()}
+		if req.Body != nil {
+			req.Body = payload
+		}
+		recorder := &auditResponseWriter{ResponseWriter: resp, status: http.StatusOK}
+		handler(recorder, req)
+
+		completedRequest := *request
+		if payload.size > 0 {
+			completedRequest.Payload = &audit.Payload{
+				Size:   payload.size,
+				SHA256: hex.EncodeToString(payload.hash.Sum(nil)),
+			}
+		}
+		completed := &audit.Event{
+			ID:        uuid.Generate(),
+			Stage:     audit.OperationComplete,
+			Timestamp: time.Now(),
+			Auth:      received.Auth,
+			Request:   &completedRequest,
+			Response: &audit.Response{
+				StatusCode: recorder.status,
+				Error:      string(recorder.errBody),
+				Duration:   time.Since(start),
+			},
+		}
+
+		// The response has already been sent so the failure is only logged
+		if err := auditor.Event(completed); err != nil {
+			s.logger.Error("failed to audit request completion", "method", req.Method, "path", req.URL.Path, "error", err)
+		}
+	}
+}
+
+// auditAuth returns the actor of the request from its ACL token, or nil if
+// ACLs are disabled or the token can't be resolved.
+func (s *HTTPServer) auditAuth(req *http.Request) *audit.Auth {
+	if !s.agent.config.ACL.Enabled {
+		return nil
+	}
+
+	var secretID string
+	s.parseToken(req, &secretID)
+
+	token := structs.AnonymousACLToken
+	if secretID != "" {
+		args := structs.ResolveACLTokenRequest{
+			SecretID: secretID,
+			QueryOptions: structs.QueryOptions{
+				Region:     s.agent.config.Region,
+				AllowStale: true,
+			},
+		}
+		var reply structs.ResolveACLTokenResponse
+		if err := s.agent.RPC("ACL.ResolveToken", &args, &reply); err != nil {
+			s.logger.Debug("failed to resolve token of audited request", "error", err)
+			return nil
+		}
+		if reply.Token == nil {
+			return nil
+		}
+		token = reply.Token
+	}
+
+	return &audit.Auth{
+		AccessorID: token.AccessorID,
+		Name:       token.Name,
+		Type:       token.Type,
+		Policies:   token.Policies,
+		Global:     token.Global,
+		CreateTime: token.CreateTime,
+	}
+}
+
+// auditPayload hashes and counts the bytes read from a request body.
+type auditPayload struct {
+	io.ReadCloser
+	size int64
+	hash hash.Hash
+}
+
+func (p *auditPayload) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	p.size += int64(n)
+	p.hash.Write(b[:n])
+	return n, err
+}
+
+// auditResponseWriter records the status code of a response and the start of
+// its body if it failed.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	errBody     []byte
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if w.status >= 400 && len(w.errBody) < auditErrorLimit {
+		n := len(b)
+		if rem := auditErrorLimit - len(w.errBody); n > rem {
+			n = rem
+		}
+		w.errBody = append(w.errBody, b[:n]...)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer so that streaming endpoints
+// keep working.
+func (w *auditResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent/audit"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_Audit(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-audit")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	httpACLTest(t, func(c *Config) {
+		c.Audit = &config.AuditConfig{
+			Enabled: helper.BoolToPtr(true),
+			Sinks: []*config.AuditSink{
+				{Name: "file", Type: audit.SinkTypeFile, Path: path},
+			},
+			Filters: []*config.AuditFilter{
+				{
+					Name:      "status",
+					Type:      audit.FilterTypeHTTPEvent,
+					Endpoints: []string{"/v1/status/*"},
+				},
+			},
+		}
+	}, func(s *TestAgent) {
+		// A filtered request
+		req, err := http.NewRequest("GET", "/v1/status/leader", nil)
+		require.NoError(err)
+		s.Server.mux.ServeHTTP(httptest.NewRecorder(), req)
+
+		// A request with a payload made with the root token
+		body := `{"Name": "readonly", "Rules": "namespace \"default\" { policy = \"read\" }"}`
+		req, err = http.NewRequest("PUT", "/v1/acl/policy/readonly", strings.NewReader(body))
+		require.NoError(err)
+		setToken(req, s.RootToken)
+		resp := httptest.NewRecorder()
+		s.Server.mux.ServeHTTP(resp, req)
+		require.Equal(200, resp.Code)
+
+		// An anonymous request that is denied
+		req, err = http.NewRequest("GET", "/v1/jobs", nil)
+		require.NoError(err)
+		resp = httptest.NewRecorder()
+		s.Server.mux.ServeHTTP(resp, req)
+		require.Equal(403, resp.Code)
+
+		f, err := os.Open(path)
+		require.NoError(err)
+		defer f.Close()
+		var events []*audit.Event
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e audit.Event
+			require.NoError(json.Unmarshal(scanner.Bytes(), &e))
+			events = append(events, &e)
+		}
+		require.NoError(scanner.Err())
+		require.Len(events, 4)
+
+		// Both stages of the policy request are recorded with the actor
+		received, completed := events[0], events[1]
+		require.Equal(audit.OperationReceived, received.Stage)
+		require.Equal(audit.OperationComplete, completed.Stage)
+		require.Equal(received.Request.ID, completed.Request.ID)
+		require.Equal("PUT", completed.Request.Operation)
+		require.Equal("/v1/acl/policy/readonly", completed.Request.Endpoint)
+		require.Equal(s.RootToken.AccessorID, completed.Auth.AccessorID)
+		require.Nil(received.Request.Payload)
+		require.Equal(int64(len(body)), completed.Request.Payload.Size)
+		require.NotEmpty(completed.Request.Payload.SHA256)
+		require.Equal(200, completed.Response.StatusCode)
+
+		// The denied request is recorded with its error
+		denied := events[3]
+		require.Equal("anonymous", denied.Auth.AccessorID)
+		require.Equal(403, denied.Response.StatusCode)
+		require.Contains(denied.Response.Error, "Permission denied")
+	})
+}
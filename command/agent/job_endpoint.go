@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/jobspec"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -56,6 +57,9 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/periodic/force"):
 		jobName := strings.TrimSuffix(path, "/periodic/force")
 		return s.periodicForceRequest(resp, req, jobName)
+	case strings.HasSuffix(path, "/periodic/history"):
+		jobName := strings.TrimSuffix(path, "/periodic/history")
+		return s.periodicHistoryRequest(resp, req, jobName)
 	case strings.HasSuffix(path, "/plan"):
 		jobName := strings.TrimSuffix(path, "/plan")
 		return s.jobPlan(resp, req, jobName)
@@ -65,6 +69,12 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/dispatch"):
 		jobName := strings.TrimSuffix(path, "/dispatch")
 		return s.jobDispatchRequest(resp, req, jobName)
+	case strings.HasSuffix(path, "/dispatch-array"):
+		jobName := strings.TrimSuffix(path, "/dispatch-array")
+		return s.jobDispatchArrayRequest(resp, req, jobName)
+	case strings.HasSuffix(path, "/versions/diff"):
+		jobName := strings.TrimSuffix(path, "/versions/diff")
+		return s.jobVersionDiff(resp, req, jobName)
 	case strings.HasSuffix(path, "/versions"):
 		jobName := strings.TrimSuffix(path, "/versions")
 		return s.jobVersions(resp, req, jobName)
@@ -80,6 +90,12 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/stable"):
 		jobName := strings.TrimSuffix(path, "/stable")
 		return s.jobStable(resp, req, jobName)
+	case strings.HasSuffix(path, "/tag"):
+		jobName := strings.TrimSuffix(path, "/tag")
+		return s.jobVersionTag(resp, req, jobName)
+	case strings.HasSuffix(path, "/scale"):
+		jobName := strings.TrimSuffix(path, "/scale")
+		return s.jobScale(resp, req, jobName)
 	default:
 		return s.jobCRUD(resp, req, path)
 	}
@@ -211,6 +227,30 @@ func (s *HTTPServer) periodicForceRequest(resp http.ResponseWriter, req *http.Re
 	return out, nil
 }
 
+func (s *HTTPServer) periodicHistoryRequest(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.PeriodicHistoryRequest{
+		JobID: jobName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.PeriodicHistoryResponse
+	if err := s.agent.RPC("Periodic.History", &args, &out); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &out.QueryMeta)
+	if out.Launches == nil {
+		out.Launches = make([]*structs.PeriodicLaunchEvent, 0)
+	}
+	return out.Launches, nil
+}
+
 func (s *HTTPServer) jobAllocations(resp http.ResponseWriter, req *http.Request,
 	jobName string) (interface{}, error) {
 	if req.Method != "GET" {
@@ -219,8 +259,9 @@ func (s *HTTPServer) jobAllocations(resp http.ResponseWriter, req *http.Request,
 	allAllocs, _ := strconv.ParseBool(req.URL.Query().Get("all"))
 
 	args := structs.JobSpecificRequest{
-		JobID:     jobName,
-		AllAllocs: allAllocs,
+		JobID:        jobName,
+		AllAllocs:    allAllocs,
+		DeploymentID: req.URL.Query().Get("deployment"),
 	}
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
@@ -458,6 +499,137 @@ func (s *HTTPServer) jobVersions(resp http.ResponseWriter, req *http.Request,
 	return out, nil
 }
 
+func (s *HTTPServer) jobVersionDiff(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	query := req.URL.Query()
+	args := structs.JobVersionDiffRequest{
+		JobID: jobName,
+		From:  query.Get("from"),
+		To:    query.Get("to"),
+	}
+	if args.From == "" {
+		return nil, CodedError(400, "Version to diff from must be specified")
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.JobVersionDiffResponse
+	if err := s.agent.RPC("Job.GetJobVersionDiff", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	return out, nil
+}
+
+func (s *HTTPServer) jobVersionTag(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+
+	var args structs.JobVersionTagRequest
+	switch req.Method {
+	case "PUT", "POST":
+		if err := decodeBody(req, &args); err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+		if args.JobID == "" {
+			return nil, CodedError(400, "JobID must be specified")
+		}
+		if args.JobID != jobName {
+			return nil, CodedError(400, "Job ID does not match")
+		}
+		if args.Tag == nil {
+			return nil, CodedError(400, "Tag must be specified")
+		}
+		args.Unset = false
+	case "DELETE":
+		name := req.URL.Query().Get("name")
+		if name == "" {
+			return nil, CodedError(400, "Tag name must be specified")
+		}
+		args = structs.JobVersionTagRequest{
+			JobID: jobName,
+			Tag:   &structs.JobVersionTag{Name: name},
+			Unset: true,
+		}
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.JobVersionTagResponse
+	if err := s.agent.RPC("Job.TagVersion", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+func (s *HTTPServer) jobScale(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+
+	switch req.Method {
+	case "GET":
+		return s.jobScaleStatus(resp, req, jobName)
+	case "PUT", "POST":
+		return s.jobScaleAction(resp, req, jobName)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) jobScaleStatus(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+
+	args := structs.JobScaleStatusRequest{
+		JobID: jobName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.JobScaleStatusResponse
+	if err := s.agent.RPC("Job.ScaleStatus", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.JobScaleStatus == nil {
+		return nil, CodedError(404, "job not found")
+	}
+	return out.JobScaleStatus, nil
+}
+
+func (s *HTTPServer) jobScaleAction(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+
+	var args structs.JobScaleRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if args.JobID != "" && args.JobID != jobName {
+		return nil, CodedError(400, "Job ID does not match")
+	}
+	if args.Group == "" {
+		return nil, CodedError(400, "Group must be specified")
+	}
+	args.JobID = jobName
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.JobRegisterResponse
+	if err := s.agent.RPC("Job.Scale", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
 func (s *HTTPServer) jobRevert(resp http.ResponseWriter, req *http.Request,
 	jobName string) (interface{}, error) {
 
@@ -562,6 +734,34 @@ func (s *HTTPServer) jobDispatchRequest(resp http.ResponseWriter, req *http.Requ
 	return out, nil
 }
 
+func (s *HTTPServer) jobDispatchArrayRequest(resp http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.JobDispatchArrayRequest{
+		JobID:   name,
+		ArrayID: req.URL.Query().Get("array_id"),
+	}
+	if args.ArrayID == "" {
+		return nil, CodedError(400, "array_id must be specified")
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.JobDispatchArrayResponse
+	if err := s.agent.RPC("Job.DispatchArray", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Summary == nil {
+		return nil, CodedError(404, "dispatch array not found")
+	}
+	return out.Summary, nil
+}
+
 // JobsParseRequest parses a hcl jobspec and returns a api.Job
 func (s *HTTPServer) JobsParseRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if req.Method != http.MethodPut && req.Method != http.MethodPost {
@@ -576,8 +776,7 @@ func (s *HTTPServer) JobsParseRequest(resp http.ResponseWriter, req *http.Reques
 		return nil, CodedError(400, "Job spec is empty")
 	}
 
-	jobfile := strings.NewReader(args.JobHCL)
-	jobStruct, err := jobspec.Parse(jobfile)
+	jobStruct, err := jobspec.ParseString(args.JobHCL, "")
 	if err != nil {
 		return nil, CodedError(400, err.Error())
 	}
@@ -588,6 +787,62 @@ func (s *HTTPServer) JobsParseRequest(resp http.ResponseWriter, req *http.Reques
 	return jobStruct, nil
 }
 
+// JobsSchemaRequest returns the JSON Schema of JSON jobs
+func (s *HTTPServer) JobsSchemaRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != http.MethodGet {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	return jobspec.JobSchema(), nil
+}
+
+// JobsBatchRegisterRequest registers a set of jobs atomically
+func (s *HTTPServer) JobsBatchRegisterRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != http.MethodPut && req.Method != http.MethodPost {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var args api.JobBatchRegisterRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if len(args.Jobs) == 0 {
+		return nil, CodedError(400, "Jobs must be specified")
+	}
+
+	regReq := structs.JobBatchRegisterRequest{
+		Jobs:           make([]*structs.JobBatchRegisterEntry, len(args.Jobs)),
+		PolicyOverride: args.PolicyOverride,
+		WriteRequest: structs.WriteRequest{
+			Region:    args.WriteRequest.Region,
+			AuthToken: args.WriteRequest.SecretID,
+		},
+	}
+	for i, entry := range args.Jobs {
+		if entry == nil || entry.Job == nil {
+			return nil, CodedError(400, "Job must be specified")
+		}
+		if entry.Job.ID == nil {
+			return nil, CodedError(400, "Job ID hasn't been provided")
+		}
+		regReq.Jobs[i] = &structs.JobBatchRegisterEntry{
+			Job:            ApiJobToStructJob(entry.Job),
+			EnforceIndex:   entry.EnforceIndex,
+			JobModifyIndex: entry.JobModifyIndex,
+		}
+	}
+	s.parseWriteRequest(req, &regReq.WriteRequest)
+
+	// The jobs of a batch share the namespace of the first one
+	regReq.Namespace = regReq.Jobs[0].Job.Namespace
+
+	var out structs.JobBatchRegisterResponse
+	if err := s.agent.RPC("Job.BatchRegister", &regReq, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
 func ApiJobToStructJob(job *api.Job) *structs.Job {
 	job.Canonicalize()
 
@@ -633,6 +888,7 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 			Enabled:         *job.Periodic.Enabled,
 			SpecType:        *job.Periodic.SpecType,
 			ProhibitOverlap: *job.Periodic.ProhibitOverlap,
+			OverlapPolicy:   *job.Periodic.OverlapPolicy,
 			TimeZone:        *job.Periodic.TimeZone,
 		}
 
@@ -643,9 +899,32 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 
 	if job.ParameterizedJob != nil {
 		j.ParameterizedJob = &structs.ParameterizedJobConfig{
-			Payload:      job.ParameterizedJob.Payload,
-			MetaRequired: job.ParameterizedJob.MetaRequired,
-			MetaOptional: job.ParameterizedJob.MetaOptional,
+			Payload:       job.ParameterizedJob.Payload,
+			MetaRequired:  job.ParameterizedJob.MetaRequired,
+			MetaOptional:  job.ParameterizedJob.MetaOptional,
+			MetaTypes:     job.ParameterizedJob.MetaTypes,
+			PayloadSchema: job.ParameterizedJob.PayloadSchema,
+		}
+	}
+
+	if job.Multiregion != nil {
+		j.Multiregion = &structs.Multiregion{
+			Strategy: &structs.MultiregionStrategy{
+				MaxParallel: *job.Multiregion.Strategy.MaxParallel,
+				OnFailure:   *job.Multiregion.Strategy.OnFailure,
+			},
+		}
+
+		if l := len(job.Multiregion.Regions); l != 0 {
+			j.Multiregion.Regions = make([]*structs.MultiregionRegion, l)
+			for i, region := range job.Multiregion.Regions {
+				j.Multiregion.Regions[i] = &structs.MultiregionRegion{
+					Name:        region.Name,
+					Count:       *region.Count,
+					Datacenters: region.Datacenters,
+					Meta:        region.Meta,
+				}
+			}
 		}
 	}
 
@@ -710,14 +989,16 @@ func ApiTgToStructsTG(taskGroup *api.TaskGroup, tg *structs.TaskGroup) {
 
 	if taskGroup.Update != nil {
 		tg.Update = &structs.UpdateStrategy{
-			Stagger:          *taskGroup.Update.Stagger,
-			MaxParallel:      *taskGroup.Update.MaxParallel,
-			HealthCheck:      *taskGroup.Update.HealthCheck,
-			MinHealthyTime:   *taskGroup.Update.MinHealthyTime,
-			HealthyDeadline:  *taskGroup.Update.HealthyDeadline,
-			ProgressDeadline: *taskGroup.Update.ProgressDeadline,
-			AutoRevert:       *taskGroup.Update.AutoRevert,
-			Canary:           *taskGroup.Update.Canary,
+			Stagger:                 *taskGroup.Update.Stagger,
+			MaxParallel:             *taskGroup.Update.MaxParallel,
+			HealthCheck:             *taskGroup.Update.HealthCheck,
+			MinHealthyTime:          *taskGroup.Update.MinHealthyTime,
+			HealthyDeadline:         *taskGroup.Update.HealthyDeadline,
+			ProgressDeadline:        *taskGroup.Update.ProgressDeadline,
+			AutoRevert:              *taskGroup.Update.AutoRevert,
+			AutoPromote:             *taskGroup.Update.AutoPromote,
+			ManualPromotionPerGroup: *taskGroup.Update.ManualPromotionPerGroup,
+			Canary:                  *taskGroup.Update.Canary,
 		}
 	}
 
@@ -756,6 +1037,7 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 				Tags:        service.Tags,
 				CanaryTags:  service.CanaryTags,
 				AddressMode: service.AddressMode,
+				Provider:    service.Provider,
 			}
 
 			if l := len(service.Checks); l != 0 {
@@ -796,6 +1078,9 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 	structsTask.LogConfig = &structs.LogConfig{
 		MaxFiles:      *apiTask.LogConfig.MaxFiles,
 		MaxFileSizeMB: *apiTask.LogConfig.MaxFileSizeMB,
+		Sink:          *apiTask.LogConfig.Sink,
+		SinkOptions:   helper.CopyMapStringString(apiTask.LogConfig.SinkOptions),
+		DisableFiles:  *apiTask.LogConfig.DisableFiles,
 	}
 
 	if l := len(apiTask.Artifacts); l != 0 {
@@ -816,6 +1101,7 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 			Env:          *apiTask.Vault.Env,
 			ChangeMode:   *apiTask.Vault.ChangeMode,
 			ChangeSignal: *apiTask.Vault.ChangeSignal,
+			Cluster:      *apiTask.Vault.Cluster,
 		}
 	}
 
@@ -834,6 +1120,7 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 				RightDelim:   *template.RightDelim,
 				Envvars:      *template.Envvars,
 				VaultGrace:   *template.VaultGrace,
+				Sandbox:      *template.Sandbox,
 			}
 		}
 	}
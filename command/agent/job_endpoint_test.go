@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTP_JobsList(t *testing.T) {
@@ -272,6 +273,74 @@ func TestHTTP_JobsRegister_Defaulting(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobsBatchRegister(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		job1 := MockJob()
+		job2 := MockJob()
+		args := api.JobBatchRegisterRequest{
+			Jobs: []*api.JobBatchRegisterEntry{
+				{Job: job1},
+				{Job: job2, EnforceIndex: true},
+			},
+			WriteRequest: api.WriteRequest{Region: "global"},
+		}
+		buf := encodeReq(args)
+
+		// Make the HTTP request
+		req, err := http.NewRequest("PUT", "/v1/jobs/batch", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.JobsBatchRegisterRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check the response
+		reg := obj.(structs.JobBatchRegisterResponse)
+		if len(reg.Jobs) != 2 || reg.Jobs[0].EvalID == "" || reg.Jobs[1].EvalID == "" {
+			t.Fatalf("bad: %#v", reg)
+		}
+
+		// Check for the index
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// Check the jobs are registered
+		for _, job := range []*api.Job{job1, job2} {
+			getReq := structs.JobSpecificRequest{
+				JobID: *job.ID,
+				QueryOptions: structs.QueryOptions{
+					Region:    "global",
+					Namespace: structs.DefaultNamespace,
+				},
+			}
+			var getResp structs.SingleJobResponse
+			if err := s.Agent.RPC("Job.GetJob", &getReq, &getResp); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if getResp.Job == nil {
+				t.Fatalf("job %q does not exist", *job.ID)
+			}
+		}
+
+		// A batch without jobs is rejected
+		req, err = http.NewRequest("PUT", "/v1/jobs/batch", encodeReq(api.JobBatchRegisterRequest{}))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.JobsBatchRegisterRequest(httptest.NewRecorder(), req)
+		if err == nil || !strings.Contains(err.Error(), "Jobs must be specified") {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+}
+
 func TestHTTP_JobsParse(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -304,6 +373,59 @@ func TestHTTP_JobsParse(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTP_JobsSchema(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		req, err := http.NewRequest("GET", "/v1/jobs/schema", nil)
+		require.NoError(t, err)
+
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.JobsSchemaRequest(respW, req)
+		require.NoError(t, err)
+
+		schema := obj.(map[string]interface{})
+		require.Contains(t, schema["properties"], "TaskGroups")
+
+		req, err = http.NewRequest("POST", "/v1/jobs/schema", nil)
+		require.NoError(t, err)
+		_, err = s.Server.JobsSchemaRequest(respW, req)
+		require.Error(t, err)
+	})
+}
+
+func TestHTTP_JobsParse_TemplateDataFile(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Job specs submitted over the API can't read files of the agent
+		hcl := `
+job "example" {
+  group "cache" {
+    task "redis" {
+      driver = "docker"
+
+      template {
+        data_file   = "/etc/passwd"
+        destination = "local/passwd"
+      }
+    }
+  }
+}
+`
+		buf := encodeReq(api.JobsParseRequest{JobHCL: hcl})
+		req, err := http.NewRequest("POST", "/v1/jobs/parse", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		respW := httptest.NewRecorder()
+		_, err = s.Server.JobsParseRequest(respW, req)
+		if err == nil || !strings.Contains(err.Error(), "can only be used when parsing a job file") {
+			t.Fatalf("expected data_file error, got %v", err)
+		}
+	})
+}
+
 func TestHTTP_JobQuery(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -981,6 +1103,52 @@ func TestHTTP_PeriodicForce(t *testing.T) {
 	})
 }
 
+func TestHTTP_PeriodicHistory(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create, register and force launch a periodic job.
+		job := mock.PeriodicJob()
+		args := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		require.Nil(s.Agent.RPC("Job.Register", &args, &resp))
+
+		forceArgs := structs.PeriodicForceRequest{
+			JobID:        job.ID,
+			WriteRequest: args.WriteRequest,
+		}
+		var forceResp structs.PeriodicForceResponse
+		require.Nil(s.Agent.RPC("Periodic.Force", &forceArgs, &forceResp))
+
+		// Only reads are allowed
+		req, err := http.NewRequest("PUT", "/v1/job/"+job.ID+"/periodic/history", nil)
+		require.Nil(err)
+		_, err = s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		require.NotNil(err)
+		require.Equal(405, err.(HTTPCodedError).Code())
+
+		// Make the HTTP request
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"/periodic/history", nil)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		require.Nil(err)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Check the response
+		launches := obj.([]*structs.PeriodicLaunchEvent)
+		require.Len(launches, 1)
+		require.Equal(structs.PeriodicLaunchForced, launches[0].Type)
+		require.Equal(forceResp.EvalID, launches[0].EvalID)
+	})
+}
+
 func TestHTTP_JobPlan(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -1075,6 +1243,56 @@ func TestHTTP_JobDispatch(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobDispatchArray(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+
+		// Create the parameterized job
+		job := mock.BatchJob()
+		job.ParameterizedJob = &structs.ParameterizedJobConfig{}
+
+		args := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		require.NoError(s.Agent.RPC("Job.Register", &args, &resp))
+
+		// Dispatch an array of children
+		args2 := structs.JobDispatchRequest{
+			Count: 2,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		req, err := http.NewRequest("PUT", "/v1/job/"+job.ID+"/dispatch", encodeReq(args2))
+		require.NoError(err)
+		obj, err := s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		require.NoError(err)
+
+		dispatch := obj.(structs.JobDispatchResponse)
+		require.NotEmpty(dispatch.ArrayID)
+		require.Len(dispatch.DispatchedJobIDs, 2)
+
+		// Lookup the status of the array
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"/dispatch-array?array_id="+dispatch.ArrayID, nil)
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err = s.Server.JobSpecificRequest(respW, req)
+		require.NoError(err)
+
+		summary := obj.(*structs.DispatchArraySummary)
+		require.Equal(2, summary.Count)
+		require.Equal(dispatch.DispatchedJobIDs, summary.JobIDs)
+		require.NotZero(respW.HeaderMap.Get("X-Nomad-Index"))
+	})
+}
+
 func TestHTTP_JobRevert(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -1192,6 +1410,143 @@ func TestHTTP_JobStable(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobVersionTag(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+
+		// Create the job and register it twice
+		job := mock.Job()
+		regReq := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var regResp structs.JobRegisterResponse
+		require.NoError(s.Agent.RPC("Job.Register", &regReq, &regResp))
+		regReq.Job = job.Copy()
+		regReq.Job.Priority++
+		require.NoError(s.Agent.RPC("Job.Register", &regReq, &regResp))
+
+		// Tag the first version
+		args := structs.JobVersionTagRequest{
+			JobID:      job.ID,
+			JobVersion: 0,
+			Tag:        &structs.JobVersionTag{Name: "golden"},
+		}
+		req, err := http.NewRequest("PUT", "/v1/job/"+job.ID+"/tag", encodeReq(args))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		require.NoError(err)
+		require.NotZero(obj.(structs.JobVersionTagResponse).Index)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Diff the tagged version with the current version
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"/versions/diff?from=golden", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.JobSpecificRequest(respW, req)
+		require.NoError(err)
+		diff := obj.(structs.JobVersionDiffResponse)
+		require.EqualValues(0, diff.FromVersion)
+		require.EqualValues(1, diff.ToVersion)
+		require.Equal(structs.DiffTypeEdited, diff.Diff.Type)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// The version to diff from is required
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"/versions/diff", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.JobSpecificRequest(respW, req)
+		require.Error(err)
+
+		// Remove the tag
+		req, err = http.NewRequest("DELETE", "/v1/job/"+job.ID+"/tag?name=golden", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.JobSpecificRequest(respW, req)
+		require.NoError(err)
+
+		versionsReq := structs.JobVersionsRequest{
+			JobID: job.ID,
+			QueryOptions: structs.QueryOptions{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var versions structs.JobVersionsResponse
+		require.NoError(s.Agent.RPC("Job.GetJobVersions", &versionsReq, &versions))
+		require.Len(versions.Versions, 2)
+		for _, v := range versions.Versions {
+			require.Nil(v.VersionTag)
+		}
+	})
+}
+
+func TestHTTP_JobScale(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+
+		// Create the job
+		job := mock.Job()
+		regReq := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var regResp structs.JobRegisterResponse
+		require.NoError(s.Agent.RPC("Job.Register", &regReq, &regResp))
+
+		// Scale the group
+		args := structs.JobScaleRequest{
+			Group:   "web",
+			Count:   helper.Int64ToPtr(2),
+			Message: "scale down",
+		}
+		req, err := http.NewRequest("PUT", "/v1/job/"+job.ID+"/scale", encodeReq(args))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		require.NoError(err)
+		scaleResp := obj.(structs.JobRegisterResponse)
+		require.NotEmpty(scaleResp.EvalID)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Read the status
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"/scale", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.JobSpecificRequest(respW, req)
+		require.NoError(err)
+		status := obj.(*structs.JobScaleStatus)
+		require.Equal(2, status.TaskGroups["web"].Desired)
+		require.Len(status.TaskGroups["web"].Events, 1)
+		require.Equal(scaleResp.EvalID, status.TaskGroups["web"].Events[0].EvalID)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// The group is required
+		req, err = http.NewRequest("PUT", "/v1/job/"+job.ID+"/scale", encodeReq(structs.JobScaleRequest{}))
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.JobSpecificRequest(respW, req)
+		require.Error(err)
+		require.Contains(err.Error(), "Group must be specified")
+
+		// Unknown jobs are not found
+		req, err = http.NewRequest("GET", "/v1/job/unknown/scale", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.JobSpecificRequest(respW, req)
+		require.Error(err)
+		require.Contains(err.Error(), "job not found")
+	})
+}
 func TestJobs_ApiJobToStructsJob(t *testing.T) {
 	apiJob := &api.Job{
 		Stop:        helper.BoolToPtr(true),
@@ -1246,12 +1601,29 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 			Spec:            helper.StringToPtr("spec"),
 			SpecType:        helper.StringToPtr("cron"),
 			ProhibitOverlap: helper.BoolToPtr(true),
+			OverlapPolicy:   helper.StringToPtr("skip"),
 			TimeZone:        helper.StringToPtr("test zone"),
 		},
 		ParameterizedJob: &api.ParameterizedJobConfig{
-			Payload:      "payload",
-			MetaRequired: []string{"a", "b"},
-			MetaOptional: []string{"c", "d"},
+			Payload:       "payload",
+			MetaRequired:  []string{"a", "b"},
+			MetaOptional:  []string{"c", "d"},
+			MetaTypes:     map[string]string{"a": "int"},
+			PayloadSchema: `{"type": "object"}`,
+		},
+		Multiregion: &api.Multiregion{
+			Strategy: &api.MultiregionStrategy{
+				MaxParallel: helper.IntToPtr(1),
+				OnFailure:   helper.StringToPtr("fail_all"),
+			},
+			Regions: []*api.MultiregionRegion{
+				{
+					Name:        "west",
+					Count:       helper.IntToPtr(2),
+					Datacenters: []string{"west-1"},
+					Meta:        map[string]string{"region_code": "W"},
+				},
+			},
 		},
 		Payload: []byte("payload"),
 		Meta: map[string]string{
@@ -1319,6 +1691,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 					HealthyDeadline:  helper.TimeToPtr(5 * time.Minute),
 					ProgressDeadline: helper.TimeToPtr(5 * time.Minute),
 					AutoRevert:       helper.BoolToPtr(true),
+					AutoPromote:      helper.BoolToPtr(true),
 				},
 
 				Meta: map[string]string{
@@ -1466,6 +1839,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 							Env:          helper.BoolToPtr(true),
 							ChangeMode:   helper.StringToPtr("c"),
 							ChangeSignal: helper.StringToPtr("sighup"),
+							Cluster:      helper.StringToPtr("c2"),
 						},
 						Templates: []*api.Template{
 							{
@@ -1480,6 +1854,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 								RightDelim:   helper.StringToPtr("def"),
 								Envvars:      helper.BoolToPtr(true),
 								VaultGrace:   helper.TimeToPtr(3 * time.Second),
+								Sandbox:      helper.BoolToPtr(true),
 							},
 						},
 						DispatchPayload: &api.DispatchPayloadConfig{
@@ -1545,12 +1920,29 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 			Spec:            "spec",
 			SpecType:        "cron",
 			ProhibitOverlap: true,
+			OverlapPolicy:   "skip",
 			TimeZone:        "test zone",
 		},
 		ParameterizedJob: &structs.ParameterizedJobConfig{
-			Payload:      "payload",
-			MetaRequired: []string{"a", "b"},
-			MetaOptional: []string{"c", "d"},
+			Payload:       "payload",
+			MetaRequired:  []string{"a", "b"},
+			MetaOptional:  []string{"c", "d"},
+			MetaTypes:     map[string]string{"a": "int"},
+			PayloadSchema: `{"type": "object"}`,
+		},
+		Multiregion: &structs.Multiregion{
+			Strategy: &structs.MultiregionStrategy{
+				MaxParallel: 1,
+				OnFailure:   "fail_all",
+			},
+			Regions: []*structs.MultiregionRegion{
+				{
+					Name:        "west",
+					Count:       2,
+					Datacenters: []string{"west-1"},
+					Meta:        map[string]string{"region_code": "W"},
+				},
+			},
 		},
 		Payload: []byte("payload"),
 		Meta: map[string]string{
@@ -1620,6 +2012,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 					HealthyDeadline:  5 * time.Minute,
 					ProgressDeadline: 5 * time.Minute,
 					AutoRevert:       true,
+					AutoPromote:      true,
 					Canary:           1,
 				},
 				Meta: map[string]string{
@@ -1765,6 +2158,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 							Env:          true,
 							ChangeMode:   "c",
 							ChangeSignal: "sighup",
+							Cluster:      "c2",
 						},
 						Templates: []*structs.Template{
 							{
@@ -1779,6 +2173,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 								RightDelim:   "def",
 								Envvars:      true,
 								VaultGrace:   3 * time.Second,
+								Sandbox:      true,
 							},
 						},
 						DispatchPayload: &structs.DispatchPayloadConfig{
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,6 +21,15 @@ func (s *HTTPServer) NodesRequest(resp http.ResponseWriter, req *http.Request) (
 		return nil, nil
 	}
 
+	// Include the resources of the nodes if requested
+	if raw := req.URL.Query().Get("resources"); raw != "" {
+		resources, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, CodedError(400, "resources must be a boolean value")
+		}
+		args.Fields = &structs.NodeStubFields{Resources: resources}
+	}
+
 	var out structs.NodeListResponse
 	if err := s.agent.RPC("Node.List", &args, &out); err != nil {
 		return nil, err
@@ -47,9 +57,18 @@ func (s *HTTPServer) NodeSpecificRequest(resp http.ResponseWriter, req *http.Req
 	case strings.HasSuffix(path, "/eligibility"):
 		nodeName := strings.TrimSuffix(path, "/eligibility")
 		return s.nodeToggleEligibility(resp, req, nodeName)
+	case strings.HasSuffix(path, "/scale-in/complete"):
+		nodeName := strings.TrimSuffix(path, "/scale-in/complete")
+		return s.nodeCompleteScaleIn(resp, req, nodeName)
+	case strings.HasSuffix(path, "/scale-in"):
+		nodeName := strings.TrimSuffix(path, "/scale-in")
+		return s.nodeScaleIn(resp, req, nodeName)
 	case strings.HasSuffix(path, "/purge"):
 		nodeName := strings.TrimSuffix(path, "/purge")
 		return s.nodePurge(resp, req, nodeName)
+	case strings.Contains(path, "/volume/"):
+		parts := strings.SplitN(path, "/volume/", 2)
+		return s.nodeHostVolume(resp, req, parts[0], parts[1])
 	default:
 		return s.nodeQuery(resp, req, path)
 	}
@@ -176,6 +195,60 @@ func (s *HTTPServer) nodeToggleEligibility(resp http.ResponseWriter, req *http.R
 	return out, nil
 }
 
+func (s *HTTPServer) nodeHostVolume(resp http.ResponseWriter, req *http.Request,
+	nodeID, name string) (interface{}, error) {
+	if name == "" {
+		return nil, CodedError(400, "missing host volume name")
+	}
+
+	switch req.Method {
+	case "PUT", "POST":
+		return s.nodeRegisterHostVolume(resp, req, nodeID, name)
+	case "DELETE":
+		return s.nodeDeregisterHostVolume(resp, req, nodeID, name)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) nodeRegisterHostVolume(resp http.ResponseWriter, req *http.Request,
+	nodeID, name string) (interface{}, error) {
+	var volume structs.HostVolume
+	if err := decodeBody(req, &volume); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	volume.Name = name
+
+	args := structs.NodeHostVolumeRegisterRequest{
+		NodeID: nodeID,
+		Volume: &volume,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("Node.RegisterHostVolume", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) nodeDeregisterHostVolume(resp http.ResponseWriter, req *http.Request,
+	nodeID, name string) (interface{}, error) {
+	args := structs.NodeHostVolumeDeregisterRequest{
+		NodeID: nodeID,
+		Name:   name,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("Node.DeregisterHostVolume", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
 func (s *HTTPServer) nodeQuery(resp http.ResponseWriter, req *http.Request,
 	nodeID string) (interface{}, error) {
 	if req.Method != "GET" {
@@ -215,3 +288,134 @@ func (s *HTTPServer) nodePurge(resp http.ResponseWriter, req *http.Request, node
 	setIndex(resp, out.Index)
 	return out, nil
 }
+
+// NodesScalingRequest returns the capacity of the node pools of the cluster
+// for evaluating cluster scaling policies.
+func (s *HTTPServer) NodesScalingRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.NodeScalingStatusRequest{
+		Datacenter: req.URL.Query().Get("datacenter"),
+		NodeClass:  req.URL.Query().Get("node_class"),
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.NodeScalingStatusResponse
+	if err := s.agent.RPC("Node.ScalingStatus", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Pools == nil {
+		out.Pools = make([]*structs.NodePoolScalingStatus, 0)
+	}
+	return out.Pools, nil
+}
+
+func (s *HTTPServer) nodeScaleIn(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		return s.nodeScaleInStatus(resp, req, nodeID)
+	case "PUT", "POST":
+		return s.nodeStartScaleIn(resp, req, nodeID)
+	case "DELETE":
+		return s.nodeCancelScaleIn(resp, req, nodeID)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) nodeScaleInStatus(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	args := structs.NodeSpecificRequest{
+		NodeID: nodeID,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.NodeScaleInStatusResponse
+	if err := s.agent.RPC("Node.ScaleInStatus", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Status == nil {
+		return nil, CodedError(404, "node is not being scaled in")
+	}
+	return out.Status, nil
+}
+
+func (s *HTTPServer) nodeStartScaleIn(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	var scaleIn api.NodeScaleInRequest
+	if err := decodeBody(req, &scaleIn); err != nil && err != io.EOF {
+		return nil, CodedError(400, err.Error())
+	}
+
+	args := structs.NodeScaleInRequest{
+		NodeID:  nodeID,
+		Message: scaleIn.Message,
+	}
+	if scaleIn.DrainSpec != nil {
+		args.DrainSpec = &structs.DrainSpec{
+			Deadline:         scaleIn.DrainSpec.Deadline,
+			IgnoreSystemJobs: scaleIn.DrainSpec.IgnoreSystemJobs,
+		}
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.NodeDrainUpdateResponse
+	if err := s.agent.RPC("Node.ScaleIn", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+func (s *HTTPServer) nodeCancelScaleIn(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	args := structs.NodeScaleInRequest{
+		NodeID: nodeID,
+		Cancel: true,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.NodeDrainUpdateResponse
+	if err := s.agent.RPC("Node.ScaleIn", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+func (s *HTTPServer) nodeCompleteScaleIn(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.NodeScaleInCompleteRequest{
+		NodeID: nodeID,
+	}
+	if raw := req.URL.Query().Get("force"); raw != "" {
+		force, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, CodedError(400, "force must be a boolean value")
+		}
+		args.Force = force
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.NodeUpdateResponse
+	if err := s.agent.RPC("Node.CompleteScaleIn", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,6 +63,45 @@ func TestHTTP_NodesList(t *testing.T) {
 	})
 }
 
+func TestHTTP_NodesList_Resources(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		node := mock.Node()
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		require.NoError(t, s.Agent.RPC("Node.Register", &args, &resp))
+
+		findNode := func(url string) *structs.NodeListStub {
+			req, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+			obj, err := s.Server.NodesRequest(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			for _, n := range obj.([]*structs.NodeListStub) {
+				if n.ID == node.ID {
+					return n
+				}
+			}
+			t.Fatalf("node %s not listed", node.ID)
+			return nil
+		}
+
+		// Resources are only included if requested
+		require.Nil(t, findNode("/v1/nodes").NodeResources)
+
+		stub := findNode("/v1/nodes?resources=true")
+		require.Equal(t, node.NodeResources.Cpu.CpuShares, stub.NodeResources.Cpu.CpuShares)
+		require.Equal(t, node.ReservedResources.Memory.MemoryMB, stub.ReservedResources.Memory.MemoryMB)
+
+		req, err := http.NewRequest("GET", "/v1/nodes?resources=maybe", nil)
+		require.NoError(t, err)
+		_, err = s.Server.NodesRequest(httptest.NewRecorder(), req)
+		require.Error(t, err)
+	})
+}
+
 func TestHTTP_NodesPrefixList(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -412,6 +453,165 @@ func TestHTTP_NodeEligible(t *testing.T) {
 	})
 }
 
+func TestHTTP_NodeHostVolume(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the node
+		node := mock.Node()
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		require.Nil(s.Agent.RPC("Node.Register", &args, &resp))
+
+		volume := api.HostVolume{
+			Path:       "/srv/data",
+			CapacityMB: 1024,
+			AccessMode: api.HostVolumeAccessModeMultiWriter,
+		}
+
+		// Make the HTTP request
+		buf := encodeReq(volume)
+		req, err := http.NewRequest("PUT", "/v1/node/"+node.ID+"/volume/data", buf)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		_, err = s.Server.NodeSpecificRequest(respW, req)
+		require.Nil(err)
+
+		// Check for the index
+		require.NotZero(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Check that the volume has been registered
+		state := s.Agent.server.State()
+		out, err := state.NodeByID(nil, node.ID)
+		require.Nil(err)
+		require.Len(out.HostVolumes, 1)
+		require.Equal("data", out.HostVolumes["data"].Name)
+		require.Equal("/srv/data", out.HostVolumes["data"].Path)
+		require.Equal(structs.HostVolumeAccessModeMultiWriter, out.HostVolumes["data"].AccessMode)
+
+		// Deregister the volume
+		req, err = http.NewRequest("DELETE", "/v1/node/"+node.ID+"/volume/data", nil)
+		require.Nil(err)
+		respW = httptest.NewRecorder()
+
+		_, err = s.Server.NodeSpecificRequest(respW, req)
+		require.Nil(err)
+		require.NotZero(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		out, err = state.NodeByID(nil, node.ID)
+		require.Nil(err)
+		require.Empty(out.HostVolumes)
+
+		// Reading a volume is not supported
+		req, err = http.NewRequest("GET", "/v1/node/"+node.ID+"/volume/data", nil)
+		require.Nil(err)
+		_, err = s.Server.NodeSpecificRequest(httptest.NewRecorder(), req)
+		require.NotNil(err)
+		require.Contains(err.Error(), ErrInvalidMethod)
+	})
+}
+
+func TestHTTP_NodeScaleIn(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the node
+		node := mock.Node()
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		require.Nil(s.Agent.RPC("Node.Register", &args, &resp))
+
+		// The node isn't being scaled in
+		req, err := http.NewRequest("GET", "/v1/node/"+node.ID+"/scale-in", nil)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+		_, err = s.Server.NodeSpecificRequest(respW, req)
+		require.NotNil(err)
+		require.Equal(404, err.(HTTPCodedError).Code())
+
+		// Start the scale-in
+		buf := encodeReq(api.NodeScaleInRequest{
+			DrainSpec: &api.DrainSpec{Deadline: time.Minute},
+			Message:   "scaling down",
+		})
+		req, err = http.NewRequest("PUT", "/v1/node/"+node.ID+"/scale-in", buf)
+		require.Nil(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.NodeSpecificRequest(respW, req)
+		require.Nil(err)
+		require.NotZero(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		state := s.Agent.server.State()
+		out, err := state.NodeByID(nil, node.ID)
+		require.Nil(err)
+		require.NotNil(out.ScaleIn)
+		require.Equal("scaling down", out.ScaleIn.Message)
+		require.Equal(time.Minute, out.DrainStrategy.Deadline)
+
+		// Wait for the drain of the empty node to complete
+		testutil.WaitForResult(func() (bool, error) {
+			req, err := http.NewRequest("GET", "/v1/node/"+node.ID+"/scale-in", nil)
+			if err != nil {
+				return false, err
+			}
+			obj, err := s.Server.NodeSpecificRequest(httptest.NewRecorder(), req)
+			if err != nil {
+				return false, err
+			}
+			status := obj.(*structs.NodeScaleInStatus)
+			return status.Status == structs.NodeScaleInStatusReady, fmt.Errorf("scale-in status is %q", status.Status)
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+
+		// Complete the scale-in
+		req, err = http.NewRequest("POST", "/v1/node/"+node.ID+"/scale-in/complete", nil)
+		require.Nil(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.NodeSpecificRequest(respW, req)
+		require.Nil(err)
+
+		out, err = state.NodeByID(nil, node.ID)
+		require.Nil(err)
+		require.Nil(out)
+	})
+}
+
+func TestHTTP_NodesScaling(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the node
+		node := mock.Node()
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		require.Nil(s.Agent.RPC("Node.Register", &args, &resp))
+
+		req, err := http.NewRequest("GET", "/v1/nodes/scaling?node_class="+node.NodeClass, nil)
+		require.Nil(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.NodesScalingRequest(respW, req)
+		require.Nil(err)
+		require.NotZero(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		pools := obj.([]*structs.NodePoolScalingStatus)
+		require.Len(pools, 1)
+		require.Equal(node.NodeClass, pools[0].NodeClass)
+		require.Equal(1, pools[0].Nodes)
+	})
+}
+
 func TestHTTP_NodePurge(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
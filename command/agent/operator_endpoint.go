@@ -1,6 +1,9 @@
 package agent
 
 import (
+	"context"
+	"io"
+	"net"
 	"net/http"
 	"strings"
 
@@ -10,8 +13,10 @@ import (
 
 	"github.com/hashicorp/consul/agent/consul/autopilot"
 	"github.com/hashicorp/nomad/api"
+	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/raft"
+	"github.com/ugorji/go/codec"
 )
 
 func (s *HTTPServer) OperatorRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
@@ -209,6 +214,31 @@ func (s *HTTPServer) OperatorServerHealth(resp http.ResponseWriter, req *http.Re
 	return out, nil
 }
 
+// OperatorClusterHealth is used to get the aggregated health of the servers
+// and node heartbeats of the given Region.
+func (s *HTTPServer) OperatorClusterHealth(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var args structs.GenericRequest
+	if done := s.parse(resp, req, &args.Region, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	var out structs.ClusterHealthResponse
+	if err := s.agent.RPC("Operator.ClusterHealth", &args, &out); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &out.QueryMeta)
+
+	// Reply with status 429 if something is unhealthy
+	if !out.Healthy {
+		resp.WriteHeader(http.StatusTooManyRequests)
+	}
+	return out, nil
+}
+
 // OperatorSchedulerConfiguration is used to inspect the current Scheduler configuration.
 // This supports the stale query mode in case the cluster doesn't have a leader.
 func (s *HTTPServer) OperatorSchedulerConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
@@ -271,3 +301,202 @@ func (s *HTTPServer) schedulerUpdateConfig(resp http.ResponseWriter, req *http.R
 	setIndex(resp, reply.Index)
 	return reply, nil
 }
+
+// OperatorGCConfiguration is used to inspect and update the GC thresholds set
+// at runtime.
+func (s *HTTPServer) OperatorGCConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		return s.gcGetConfig(resp, req)
+
+	case "PUT", "POST":
+		return s.gcUpdateConfig(resp, req)
+
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) gcGetConfig(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.GenericRequest
+	if done := s.parse(resp, req, &args.Region, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	var reply structs.GCConfigurationResponse
+	if err := s.agent.RPC("Operator.GCGetConfiguration", &args, &reply); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &reply.QueryMeta)
+
+	return reply, nil
+}
+
+func (s *HTTPServer) gcUpdateConfig(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.GCSetConfigRequest
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var conf api.GCConfiguration
+	if err := decodeBody(req, &conf); err != nil {
+		return nil, CodedError(http.StatusBadRequest, fmt.Sprintf("Error parsing GC config: %v", err))
+	}
+
+	args.Config = structs.GCConfiguration{
+		EvalGCThreshold:       conf.EvalGCThreshold,
+		JobGCThreshold:        conf.JobGCThreshold,
+		DeploymentGCThreshold: conf.DeploymentGCThreshold,
+	}
+
+	// Check for cas value
+	params := req.URL.Query()
+	if _, ok := params["cas"]; ok {
+		casVal, err := strconv.ParseUint(params.Get("cas"), 10, 64)
+		if err != nil {
+			return nil, CodedError(http.StatusBadRequest, fmt.Sprintf("Error parsing cas value: %v", err))
+		}
+		args.Config.ModifyIndex = casVal
+		args.CAS = true
+	}
+
+	var reply structs.GCSetConfigurationResponse
+	if err := s.agent.RPC("Operator.GCSetConfiguration", &args, &reply); err != nil {
+		return nil, err
+	}
+	setIndex(resp, reply.Index)
+	return reply, nil
+}
+
+// SnapshotRequest is used to save a snapshot of the state of the servers with
+// a GET, or to restore the state from a snapshot with a PUT.
+func (s *HTTPServer) SnapshotRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		return s.snapshotSaveRequest(resp, req)
+	case "PUT", "POST":
+		return s.snapshotRestoreRequest(resp, req)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) snapshotSaveRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	args := &structs.SnapshotSaveRequest{}
+	if done := s.parse(resp, req, &args.Region, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	handler, err := s.serverStreamingRpcHandler("Operator.SnapshotSave")
+	if err != nil {
+		return nil, CodedError(500, err.Error())
+	}
+
+	httpPipe, handlerPipe := net.Pipe()
+	decoder := codec.NewDecoder(httpPipe, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(httpPipe, structs.MsgpackHandle)
+
+	// Close the pipe if the connection closes
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		httpPipe.Close()
+	}()
+
+	errCh := make(chan HTTPCodedError, 1)
+	go func() {
+		defer cancel()
+
+		// Send the request
+		if err := encoder.Encode(args); err != nil {
+			errCh <- CodedError(500, err.Error())
+			return
+		}
+
+		var res structs.SnapshotSaveResponse
+		if err := decoder.Decode(&res); err != nil {
+			errCh <- CodedError(500, err.Error())
+			return
+		}
+		if res.ErrorCode != 0 {
+			errCh <- CodedError(res.ErrorCode, res.ErrorMsg)
+			return
+		}
+
+		// The archive follows the response
+		resp.Header().Add("Digest", res.SnapshotChecksum)
+		setMeta(resp, &res.QueryMeta)
+		if _, err := io.Copy(resp, httpPipe); err != nil && !isClosedErr(err) {
+			errCh <- CodedError(500, err.Error())
+			return
+		}
+		errCh <- nil
+	}()
+
+	handler(handlerPipe)
+	return nil, <-errCh
+}
+
+func (s *HTTPServer) snapshotRestoreRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	args := &structs.SnapshotRestoreRequest{}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	handler, err := s.serverStreamingRpcHandler("Operator.SnapshotRestore")
+	if err != nil {
+		return nil, CodedError(500, err.Error())
+	}
+
+	httpPipe, handlerPipe := net.Pipe()
+	defer httpPipe.Close()
+	decoder := codec.NewDecoder(httpPipe, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(httpPipe, structs.MsgpackHandle)
+
+	// Send the request followed by the archive in chunks, ending with a
+	// chunk without a payload
+	go func() {
+		if err := encoder.Encode(args); err != nil {
+			return
+		}
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := req.Body.Read(buf)
+			if n > 0 {
+				if err := encoder.Encode(&cstructs.StreamErrWrapper{Payload: buf[:n]}); err != nil {
+					return
+				}
+			}
+			if err == io.EOF {
+				encoder.Encode(&cstructs.StreamErrWrapper{})
+				return
+			} else if err != nil {
+				encoder.Encode(&cstructs.StreamErrWrapper{Error: cstructs.NewRpcError(err, nil)})
+				return
+			}
+		}
+	}()
+
+	errCh := make(chan HTTPCodedError, 1)
+	go func() {
+		var res structs.SnapshotRestoreResponse
+		if err := decoder.Decode(&res); err != nil {
+			errCh <- CodedError(500, err.Error())
+			return
+		}
+		if res.ErrorCode != 0 {
+			errCh <- CodedError(res.ErrorCode, res.ErrorMsg)
+			return
+		}
+		setMeta(resp, &res.QueryMeta)
+		errCh <- nil
+	}()
+
+	handler(handlerPipe)
+	return nil, <-errCh
+}
+
+// isClosedErr returns whether the error is due to a closed connection.
+func isClosedErr(err error) bool {
+	return err == io.EOF ||
+		strings.Contains(err.Error(), "closed") ||
+		strings.Contains(err.Error(), "EOF")
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/hashicorp/consul/testutil/retry"
 	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper/snapshot"
+	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -259,6 +261,27 @@ func TestOperator_ServerHealth_Unhealthy(t *testing.T) {
 	})
 }
 
+func TestOperator_ClusterHealth(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+		body := bytes.NewBuffer(nil)
+		req, _ := http.NewRequest("GET", "/v1/operator/health", body)
+		resp := httptest.NewRecorder()
+		obj, err := s.Server.OperatorClusterHealth(resp, req)
+		require.NoError(err)
+		require.Equal(200, resp.Code)
+		require.NotEmpty(resp.Header().Get("X-Nomad-Index"))
+
+		out, ok := obj.(structs.ClusterHealthResponse)
+		require.True(ok)
+		require.True(out.Healthy)
+		require.Len(out.Servers, 1)
+		require.Equal(s.server.LocalMember().Name, out.Servers[0].Name)
+		require.False(out.VersionSkew)
+	})
+}
+
 func TestOperator_SchedulerGetConfiguration(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -371,3 +394,106 @@ func TestOperator_SchedulerCASConfiguration(t *testing.T) {
 		require.False(reply.SchedulerConfig.PreemptionConfig.SystemSchedulerEnabled)
 	})
 }
+
+func TestOperator_SnapshotRequests(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Register a job to have some state in the snapshot
+		job := mock.Job()
+		regReq := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var regResp structs.JobRegisterResponse
+		require.NoError(s.Agent.RPC("Job.Register", &regReq, &regResp))
+
+		// Save a snapshot
+		req, err := http.NewRequest("GET", "/v1/operator/snapshot", nil)
+		require.NoError(err)
+		resp := httptest.NewRecorder()
+		_, err = s.Server.SnapshotRequest(resp, req)
+		require.NoError(err)
+		require.Equal(200, resp.Code)
+		require.Contains(resp.Header().Get("Digest"), "sha-256=")
+		require.NotEmpty(resp.Header().Get("X-Nomad-Index"))
+
+		archive := resp.Body.Bytes()
+		_, err = snapshot.Verify(bytes.NewReader(archive))
+		require.NoError(err)
+
+		// Purge the job and restore the snapshot
+		deregReq := structs.JobDeregisterRequest{
+			JobID: job.ID,
+			Purge: true,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var deregResp structs.JobDeregisterResponse
+		require.NoError(s.Agent.RPC("Job.Deregister", &deregReq, &deregResp))
+
+		req, err = http.NewRequest("PUT", "/v1/operator/snapshot", bytes.NewReader(archive))
+		require.NoError(err)
+		resp = httptest.NewRecorder()
+		_, err = s.Server.SnapshotRequest(resp, req)
+		require.NoError(err)
+		require.Equal(200, resp.Code)
+
+		state := s.Agent.server.State()
+		out, err := state.JobByID(nil, job.Namespace, job.ID)
+		require.NoError(err)
+		require.NotNil(out)
+
+		// A corrupt snapshot is rejected
+		req, err = http.NewRequest("PUT", "/v1/operator/snapshot", strings.NewReader("not a snapshot"))
+		require.NoError(err)
+		resp = httptest.NewRecorder()
+		_, err = s.Server.SnapshotRequest(resp, req)
+		require.Error(err)
+
+		// Other methods are not allowed
+		req, err = http.NewRequest("DELETE", "/v1/operator/snapshot", nil)
+		require.NoError(err)
+		resp = httptest.NewRecorder()
+		_, err = s.Server.SnapshotRequest(resp, req)
+		require.Error(err)
+		require.Contains(err.Error(), ErrInvalidMethod)
+	})
+}
+
+func TestOperator_GCConfiguration(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		require := require.New(t)
+		body := bytes.NewBuffer([]byte(`{"JobGCThreshold": 3600000000000}`))
+		req, _ := http.NewRequest("PUT", "/v1/operator/gc/configuration", body)
+		resp := httptest.NewRecorder()
+		setResp, err := s.Server.OperatorGCConfiguration(resp, req)
+		require.NoError(err)
+		gcSetResp, ok := setResp.(structs.GCSetConfigurationResponse)
+		require.True(ok)
+		require.NotZero(gcSetResp.Index)
+
+		// A CAS with a stale index is not applied
+		body = bytes.NewBuffer([]byte(`{"JobGCThreshold": 60000000000}`))
+		req, _ = http.NewRequest("PUT", fmt.Sprintf("/v1/operator/gc/configuration?cas=%d", gcSetResp.Index-1), body)
+		resp = httptest.NewRecorder()
+		setResp, err = s.Server.OperatorGCConfiguration(resp, req)
+		require.NoError(err)
+		require.False(setResp.(structs.GCSetConfigurationResponse).Updated)
+
+		req, _ = http.NewRequest("GET", "/v1/operator/gc/configuration", nil)
+		resp = httptest.NewRecorder()
+		obj, err := s.Server.OperatorGCConfiguration(resp, req)
+		require.NoError(err)
+		out, ok := obj.(structs.GCConfigurationResponse)
+		require.True(ok)
+		require.Equal(time.Hour, out.GCConfig.JobGCThreshold)
+		require.Zero(out.GCConfig.EvalGCThreshold)
+	})
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func (s *HTTPServer) ServicesRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.ServiceRegistrationListRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.ServiceRegistrationListResponse
+	if err := s.agent.RPC("ServiceRegistration.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Services == nil {
+		out.Services = make([]*structs.ServiceRegistrationStub, 0)
+	}
+	return out.Services, nil
+}
+
+func (s *HTTPServer) ServiceSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/service/")
+	switch req.Method {
+	case "GET":
+		if path == "" {
+			return nil, CodedError(400, "Missing service name")
+		}
+		return s.serviceQuery(resp, req, path)
+	case "DELETE":
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, CodedError(400, "Must specify the service name and ID")
+		}
+		return s.serviceDelete(resp, req, parts[0], parts[1])
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) serviceQuery(resp http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+	args := structs.ServiceRegistrationByNameRequest{
+		ServiceName: name,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.ServiceRegistrationByNameResponse
+	if err := s.agent.RPC("ServiceRegistration.GetService", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Services == nil {
+		out.Services = make([]*structs.ServiceRegistration, 0)
+	}
+	return out.Services, nil
+}
+
+func (s *HTTPServer) serviceDelete(resp http.ResponseWriter, req *http.Request, name, id string) (interface{}, error) {
+	args := structs.ServiceRegistrationDeleteByIDRequest{
+		ServiceName: name,
+		ID:          id,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("ServiceRegistration.DeleteByID", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return nil, nil
+}
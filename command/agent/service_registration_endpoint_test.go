@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_ServiceRegistrations(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Directly manipulate the state
+		state := s.Agent.server.State()
+		alloc := mock.Alloc()
+		require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+		service := mock.ServiceRegistration(alloc)
+		require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{service}))
+
+		// List the services
+		req, err := http.NewRequest("GET", "/v1/services", nil)
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.ServicesRequest(respW, req)
+		require.NoError(err)
+		require.Equal("1001", respW.HeaderMap.Get("X-Nomad-Index"))
+
+		stubs := obj.([]*structs.ServiceRegistrationStub)
+		require.Len(stubs, 1)
+		require.Equal("web", stubs[0].ServiceName)
+		require.Equal([]string{"http"}, stubs[0].Tags)
+
+		// Get the service's instances
+		req, err = http.NewRequest("GET", "/v1/service/web", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.ServiceSpecificRequest(respW, req)
+		require.NoError(err)
+
+		services := obj.([]*structs.ServiceRegistration)
+		require.Len(services, 1)
+		require.Equal(service.ID, services[0].ID)
+		require.Equal(service.Address, services[0].Address)
+		require.Equal(service.Port, services[0].Port)
+
+		// Unknown services have no instances
+		req, err = http.NewRequest("GET", "/v1/service/unknown", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.ServiceSpecificRequest(respW, req)
+		require.NoError(err)
+		require.Empty(obj.([]*structs.ServiceRegistration))
+
+		// Deleting requires the service name and ID
+		req, err = http.NewRequest("DELETE", "/v1/service/web", nil)
+		require.NoError(err)
+		_, err = s.Server.ServiceSpecificRequest(httptest.NewRecorder(), req)
+		require.Error(err)
+		require.Equal(400, err.(HTTPCodedError).Code())
+
+		// Delete the instance
+		req, err = http.NewRequest("DELETE", "/v1/service/web/"+service.ID, nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.ServiceSpecificRequest(respW, req)
+		require.NoError(err)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		out, err := state.ServiceRegistrationByID(nil, service.ID)
+		require.NoError(err)
+		require.Nil(out)
+	})
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/hashicorp/nomad/version"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// ValidateCommand is a Command implementation that checks the configuration
+// of a Nomad agent without running it.
+type ValidateCommand struct {
+	Version *version.VersionInfo
+	Ui      cli.Ui
+}
+
+func (c *ValidateCommand) Help() string {
+	helpText := `
+Usage: nomad agent validate [options] <path> [<path>...]
+
+  Checks the configuration files of a Nomad agent without starting it. This
+  can be used to catch errors in the configuration before rolling it out.
+
+  Each path may be a file or a directory of files, and they are loaded and
+  merged in the same order as the -config flags of "nomad agent". Syntax
+  errors, unknown keys along with their position, and inconsistent settings
+  are reported, and the command exits with a non-zero status if there are any.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ValidateCommand) Synopsis() string {
+	return "Checks if the configuration of an agent is valid"
+}
+
+func (c *ValidateCommand) AutocompleteFlags() complete.Flags {
+	return nil
+}
+
+func (c *ValidateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictOr(complete.PredictDirs("*"), complete.PredictFiles("*.hcl"), complete.PredictFiles("*.json"))
+}
+
+func (c *ValidateCommand) Run(args []string) int {
+	flags := flag.NewFlagSet("agent validate", flag.ContinueOnError)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		c.Ui.Error("This command takes at least one argument: <path>")
+		c.Ui.Error(`For additional help try 'nomad agent validate -help'`)
+		return 1
+	}
+
+	// Load the configuration the same way the agent does, so that the files
+	// are checked exactly as they would be on startup.
+	agentArgs := make([]string, 0, 2*len(paths))
+	for _, path := range paths {
+		agentArgs = append(agentArgs, "-config", path)
+	}
+	cmd := &Command{
+		Version: c.Version,
+		Ui:      c.Ui,
+		args:    agentArgs,
+	}
+	if cmd.readConfig() == nil {
+		return 1
+	}
+
+	c.Ui.Output("Configuration validation successful")
+	return 0
+}
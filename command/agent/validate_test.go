@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/version"
+	"github.com/mitchellh/cli"
+)
+
+func TestValidateCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &ValidateCommand{}
+}
+
+func TestValidateCommand(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// To prevent test failures on hosts whose hostname resolves to
+	// a loopback address, we must set a bind address
+	base := `
+data_dir  = "` + tmpDir + `"
+bind_addr = "169.254.0.1"
+`
+
+	tcases := []struct {
+		name   string
+		config string
+		code   int
+		out    string
+		errOut string
+	}{
+		{
+			name: "valid",
+			config: base + `
+server {
+  enabled = true
+}
+`,
+			out: "Configuration validation successful",
+		},
+		{
+			name: "unknown key",
+			config: base + `
+server {
+  enabled = true
+  bootstrap_expec = 1
+}
+`,
+			code:   1,
+			errOut: "server -> invalid key: bootstrap_expec (line 7, column 3)",
+		},
+		{
+			name: "syntax error",
+			config: base + `
+server {
+  enabled = true
+`,
+			code:   1,
+			errOut: "error parsing",
+		},
+		{
+			name: "no role",
+			config: base + `
+client {
+  enabled = false
+}
+`,
+			code:   1,
+			errOut: "Must specify either server, client or dev mode for the agent.",
+		},
+		{
+			name: "tls without certs",
+			config: base + `
+server {
+  enabled = true
+}
+
+tls {
+  http = true
+  rpc  = true
+}
+`,
+			code:   1,
+			errOut: "rpc requires ca_file, cert_file and key_file",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			configFile := filepath.Join(tmpDir, strings.Replace(tc.name, " ", "_", -1)+".hcl")
+			if err := ioutil.WriteFile(configFile, []byte(tc.config), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			ui := new(cli.MockUi)
+			cmd := &ValidateCommand{
+				Version: version.GetVersion(),
+				Ui:      ui,
+			}
+			if code := cmd.Run([]string{configFile}); code != tc.code {
+				t.Fatalf("expected exit %d, got %d\n\n%s", tc.code, code, ui.ErrorWriter.String())
+			}
+			if out := ui.OutputWriter.String(); !strings.Contains(out, tc.out) {
+				t.Fatalf("expect to find %q\n\n%s", tc.out, out)
+			}
+			if out := ui.ErrorWriter.String(); !strings.Contains(out, tc.errOut) {
+				t.Fatalf("expect to find %q\n\n%s", tc.errOut, out)
+			}
+		})
+	}
+
+	// At least one path is required
+	ui := new(cli.MockUi)
+	cmd := &ValidateCommand{Ui: ui}
+	if code := cmd.Run(nil); code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "at least one argument") {
+		t.Fatalf("expected usage error, got %q", out)
+	}
+}
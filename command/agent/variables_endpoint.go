@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func (s *HTTPServer) VariablesListRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.VariablesListRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.VariablesListResponse
+	if err := s.agent.RPC("Variables.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Data == nil {
+		out.Data = make([]*structs.VariableMetadata, 0)
+	}
+	return out.Data, nil
+}
+
+func (s *HTTPServer) VariableSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/var/")
+	if path == "" {
+		return nil, CodedError(400, "Missing variable path")
+	}
+
+	switch req.Method {
+	case "GET":
+		return s.variableQuery(resp, req, path)
+	case "PUT", "POST":
+		if op := variableLockOp(req); op != "" {
+			return s.variableLock(resp, req, path, op)
+		}
+		return s.variableUpsert(resp, req, path)
+	case "DELETE":
+		return s.variableDelete(resp, req, path)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) variableQuery(resp http.ResponseWriter, req *http.Request, path string) (interface{}, error) {
+	args := structs.VariablesReadRequest{
+		Path: path,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.VariablesReadResponse
+	if err := s.agent.RPC("Variables.Read", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Data == nil {
+		return nil, CodedError(404, "variable not found")
+	}
+	return out.Data, nil
+}
+
+func (s *HTTPServer) variableUpsert(resp http.ResponseWriter, req *http.Request, path string) (interface{}, error) {
+	var variable structs.VariableDecrypted
+	if err := decodeBody(req, &variable); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	variable.Path = path
+
+	args := structs.VariablesUpsertRequest{
+		Var: &variable,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	cas, index, err := parseCAS(req)
+	if err != nil {
+		return nil, err
+	}
+	args.CAS = cas
+	variable.ModifyIndex = index
+
+	var out structs.VariablesWriteResponse
+	if err := s.agent.RPC("Variables.Upsert", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return out.Var, nil
+}
+
+func (s *HTTPServer) variableLock(resp http.ResponseWriter, req *http.Request, path, op string) (interface{}, error) {
+	var variable structs.VariableDecrypted
+	if err := decodeBody(req, &variable); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	variable.Path = path
+
+	args := structs.VariablesLockRequest{
+		Op:  op,
+		Var: &variable,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.VariablesWriteResponse
+	if err := s.agent.RPC("Variables.Lock", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return out.Var, nil
+}
+
+// variableLockOp returns the lock operation set by the query params of a
+// variable write, if any.
+func variableLockOp(req *http.Request) string {
+	query := req.URL.Query()
+	for _, op := range []string{
+		structs.VariableLockOpAcquire,
+		structs.VariableLockOpRenew,
+		structs.VariableLockOpRelease,
+	} {
+		if _, ok := query[op]; ok {
+			return op
+		}
+	}
+	return ""
+}
+
+func (s *HTTPServer) variableDelete(resp http.ResponseWriter, req *http.Request, path string) (interface{}, error) {
+	args := structs.VariablesDeleteRequest{
+		Path: path,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	cas, index, err := parseCAS(req)
+	if err != nil {
+		return nil, err
+	}
+	args.CAS = cas
+	args.ModifyIndex = index
+
+	var out structs.VariablesWriteResponse
+	if err := s.agent.RPC("Variables.Delete", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+// parseCAS parses the ?cas query param used for check-and-set writes
+func parseCAS(req *http.Request) (bool, uint64, error) {
+	cas := req.URL.Query().Get("cas")
+	if cas == "" {
+		return false, 0, nil
+	}
+
+	index, err := strconv.ParseUint(cas, 10, 64)
+	if err != nil {
+		return false, 0, CodedError(400, "Invalid cas index")
+	}
+	return true, index, nil
+}
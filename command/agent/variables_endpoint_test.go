@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_Variables(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the variable
+		body, err := json.Marshal(map[string]interface{}{
+			"Items": map[string]string{"password": "hunter2"},
+		})
+		require.NoError(err)
+		req, err := http.NewRequest("PUT", "/v1/var/app/db?cas=0", bytes.NewReader(body))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.VariableSpecificRequest(respW, req)
+		require.NoError(err)
+		meta := obj.(*structs.VariableMetadata)
+		require.Equal("app/db", meta.Path)
+		require.NotEmpty(respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Creating it again conflicts
+		req, err = http.NewRequest("PUT", "/v1/var/app/db?cas=0", bytes.NewReader(body))
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.VariableSpecificRequest(respW, req)
+		require.EqualError(err, structs.ErrCASConflict.Error())
+
+		// Read it
+		req, err = http.NewRequest("GET", "/v1/var/app/db", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.VariableSpecificRequest(respW, req)
+		require.NoError(err)
+		variable := obj.(*structs.VariableDecrypted)
+		require.Equal("hunter2", variable.Items["password"])
+
+		// List it
+		req, err = http.NewRequest("GET", "/v1/vars?prefix=app", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.VariablesListRequest(respW, req)
+		require.NoError(err)
+		require.Len(obj.([]*structs.VariableMetadata), 1)
+
+		// Delete it
+		req, err = http.NewRequest("DELETE", "/v1/var/app/db", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.VariableSpecificRequest(respW, req)
+		require.NoError(err)
+
+		req, err = http.NewRequest("GET", "/v1/var/app/db", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.VariableSpecificRequest(respW, req)
+		require.Error(err)
+		require.Equal(404, err.(HTTPCodedError).Code())
+	})
+}
+
+func TestHTTP_Variables_Lock(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Acquire the lock, creating the variable
+		body, err := json.Marshal(map[string]interface{}{
+			"Items": map[string]string{"leader": "alloc-1"},
+		})
+		require.NoError(err)
+		req, err := http.NewRequest("PUT", "/v1/var/jobs/leader?lock-acquire", bytes.NewReader(body))
+		require.NoError(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.VariableSpecificRequest(respW, req)
+		require.NoError(err)
+		meta := obj.(*structs.VariableMetadata)
+		require.NotNil(meta.Lock)
+		require.NotEmpty(meta.Lock.ID)
+
+		// It can't be acquired twice
+		req, err = http.NewRequest("PUT", "/v1/var/jobs/leader?lock-acquire", bytes.NewReader(body))
+		require.NoError(err)
+		_, err = s.Server.VariableSpecificRequest(httptest.NewRecorder(), req)
+		require.EqualError(err, structs.ErrVariableLocked.Error())
+
+		// Release it
+		body, err = json.Marshal(map[string]interface{}{
+			"Lock": map[string]string{"ID": meta.Lock.ID},
+		})
+		require.NoError(err)
+		req, err = http.NewRequest("PUT", "/v1/var/jobs/leader?lock-release", bytes.NewReader(body))
+		require.NoError(err)
+		obj, err = s.Server.VariableSpecificRequest(httptest.NewRecorder(), req)
+		require.NoError(err)
+		require.Nil(obj.(*structs.VariableMetadata).Lock)
+	})
+}
@@ -20,7 +20,16 @@ Usage: nomad agent-info [options]
 
 General Options:
 
-  ` + generalOptionsUsage()
+  ` + generalOptionsUsage() + `
+
+Agent Info Options:
+
+  -json
+    Output the agent info in a JSON format.
+
+  -t
+    Format and display the agent info using a Go template.
+`
 	return strings.TrimSpace(helpText)
 }
 
@@ -29,7 +38,7 @@ func (c *AgentInfoCommand) Synopsis() string {
 }
 
 func (c *AgentInfoCommand) AutocompleteFlags() complete.Flags {
-	return c.Meta.AutocompleteFlags(FlagSetClient)
+	return c.Meta.AutocompleteFlags(FlagSetClient | FlagSetFormat)
 }
 
 func (c *AgentInfoCommand) AutocompleteArgs() complete.Predictor {
@@ -39,7 +48,8 @@ func (c *AgentInfoCommand) AutocompleteArgs() complete.Predictor {
 func (c *AgentInfoCommand) Name() string { return "agent-info" }
 
 func (c *AgentInfoCommand) Run(args []string) int {
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -67,6 +77,10 @@ func (c *AgentInfoCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.formatted() {
+		return c.outputFormatted(info.Stats)
+	}
+
 	// Sort and output agent info
 	statsKeys := make([]string, 0, len(info.Stats))
 	for key := range info.Stats {
@@ -24,6 +24,15 @@ func TestAgentInfoCommand_Run(t *testing.T) {
 	if code != 0 {
 		t.Fatalf("expected exit 0, got: %d", code)
 	}
+	ui.OutputWriter.Reset()
+
+	code = cmd.Run([]string{"-address=" + url, "-t", "{{.nomad.server}}"})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	if out := strings.TrimSpace(ui.OutputWriter.String()); out != "true" {
+		t.Fatalf("expected server stat, got: %q", out)
+	}
 }
 
 func TestAgentInfoCommand_Fails(t *testing.T) {
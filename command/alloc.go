@@ -25,6 +25,10 @@ Usage: nomad alloc <subcommand> [options] [args]
 
       $ nomad alloc logs -f <alloc-id> <task>
 
+  Reschedule a failed allocation without waiting for its reschedule delay:
+
+      $ nomad alloc reschedule <alloc-id>
+
   Please see the individual subcommand help for detailed usage information.
 `
 
@@ -0,0 +1,265 @@
+package command
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/posener/complete"
+)
+
+type AllocFSCpCommand struct {
+	Meta
+}
+
+func (f *AllocFSCpCommand) Help() string {
+	helpText := `
+Usage: nomad alloc fs cp [options] <allocation>:<path> <local path>
+
+  cp copies a file or directory from an allocation directory to the local
+  filesystem. The path is relative to the root of the alloc dir. If the local
+  path is an existing directory, the file or directory is copied into it.
+  Files in task secrets directories are never copied.
+
+  Copying into an allocation is not supported, as the allocation filesystem is
+  read-only through the API.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Cp Options:
+
+  -r
+    Recursively copy a directory.
+
+  -job
+    Use a random allocation from the job ID given in place of the allocation.
+
+  -verbose
+    Show full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (f *AllocFSCpCommand) Synopsis() string {
+	return "Copy files from an allocation directory"
+}
+
+func (f *AllocFSCpCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(f.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-r":       complete.PredictNothing,
+			"-job":     complete.PredictNothing,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (f *AllocFSCpCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (f *AllocFSCpCommand) Name() string { return "alloc fs cp" }
+
+func (f *AllocFSCpCommand) Run(args []string) int {
+	var recursive, job, verbose bool
+
+	flags := f.Meta.FlagSet(f.Name(), FlagSetClient)
+	flags.Usage = func() { f.Ui.Output(f.Help()) }
+	flags.BoolVar(&recursive, "r", false, "")
+	flags.BoolVar(&job, "job", false, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	args = flags.Args()
+
+	if len(args) != 2 {
+		f.Ui.Error("This command takes two arguments: <allocation>:<path> <local path>")
+		f.Ui.Error(commandErrorText(f))
+		return 1
+	}
+
+	src, dest := args[0], args[1]
+	split := strings.SplitN(src, ":", 2)
+	if len(split) != 2 {
+		if strings.Contains(dest, ":") {
+			f.Ui.Error("Copying into an allocation is not supported")
+		} else {
+			f.Ui.Error("The source must be given as <allocation>:<path>")
+		}
+		return 1
+	}
+	allocID, srcPath := split[0], split[1]
+	if srcPath == "" {
+		srcPath = "/"
+	}
+
+	client, err := f.Meta.Client()
+	if err != nil {
+		f.Ui.Error(fmt.Sprintf("Error initializing client: %v", err))
+		return 1
+	}
+
+	// If -job is specified, use random allocation, otherwise use provided allocation
+	if job {
+		allocID, err = getRandomJobAlloc(client, allocID)
+		if err != nil {
+			f.Ui.Error(fmt.Sprintf("Error fetching allocations: %v", err))
+			return 1
+		}
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+	// Query the allocation info
+	if len(allocID) == 1 {
+		f.Ui.Error(fmt.Sprintf("Alloc ID must contain at least two characters."))
+		return 1
+	}
+
+	allocID = sanitizeUUIDPrefix(allocID)
+	allocs, _, err := client.Allocations().PrefixList(allocID)
+	if err != nil {
+		f.Ui.Error(fmt.Sprintf("Error querying allocation: %v", err))
+		return 1
+	}
+	if len(allocs) == 0 {
+		f.Ui.Error(fmt.Sprintf("No allocation(s) with prefix or id %q found", allocID))
+		return 1
+	}
+	if len(allocs) > 1 {
+		// Format the allocs
+		out := formatAllocListStubs(allocs, verbose, length)
+		f.Ui.Error(fmt.Sprintf("Prefix matched multiple allocations\n\n%s", out))
+		return 1
+	}
+	// Prefix lookup matched a single allocation
+	alloc, _, err := client.Allocations().Info(allocs[0].ID, nil)
+	if err != nil {
+		f.Ui.Error(fmt.Sprintf("Error querying allocation: %s", err))
+		return 1
+	}
+
+	// Directories are only copied when asked to
+	file, _, err := client.AllocFS().Stat(alloc, srcPath, nil)
+	if err != nil {
+		f.Ui.Error(err.Error())
+		return 1
+	}
+	if file.IsDir && !recursive {
+		f.Ui.Error(fmt.Sprintf("%q is a directory, use -r to copy it", srcPath))
+		return 1
+	}
+
+	// Copy into an existing directory under the name of the source
+	name := path.Base(path.Clean("/" + srcPath))
+	if name == "/" {
+		name = limit(alloc.ID, length)
+	}
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, name)
+	}
+
+	r, err := client.AllocFS().Archive(alloc, srcPath, nil)
+	if err != nil {
+		f.Ui.Error(fmt.Sprintf("Error reading %q: %s", srcPath, err))
+		return 1
+	}
+	defer r.Close()
+
+	files, size, err := extractAllocArchive(r, srcPath, dest, func(name, target string, size int64) {
+		f.Ui.Output(fmt.Sprintf("%s -> %s (%s)", name, target, humanize.IBytes(uint64(size))))
+	}, func(name string) {
+		f.Ui.Warn(fmt.Sprintf("Skipping %q as only files and directories are copied", name))
+	})
+	if err != nil {
+		f.Ui.Error(fmt.Sprintf("Error copying %q: %s", srcPath, err))
+		return 1
+	}
+
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	f.Ui.Output(fmt.Sprintf("Copied %d %s (%s) from allocation %q", files, noun, humanize.IBytes(uint64(size)), limit(alloc.ID, length)))
+	return 0
+}
+
+// extractAllocArchive writes the tar archive of the alloc dir path srcPath to
+// dest, calling copied for each file written and skipped for each entry that
+// is neither a file nor a directory. It returns the number of files and bytes
+// written.
+func extractAllocArchive(r io.Reader, srcPath, dest string,
+	copied func(name, target string, size int64), skipped func(name string)) (int, int64, error) {
+
+	// Archive entries are named relative to the alloc dir
+	prefix := strings.TrimPrefix(path.Clean("/"+srcPath), "/")
+
+	var files int
+	var total int64
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, total, nil
+		} else if err != nil {
+			return files, total, err
+		}
+
+		// Find the entry's path below the copied path, refusing any that
+		// would be written outside of the destination
+		rel := path.Clean("/" + hdr.Name)
+		if prefix != "" {
+			if rel != "/"+prefix && !strings.HasPrefix(rel, "/"+prefix+"/") {
+				return files, total, fmt.Errorf("unexpected archive entry %q", hdr.Name)
+			}
+			rel = strings.TrimPrefix(rel, "/"+prefix)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+
+		mode := hdr.FileInfo().Mode().Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return files, total, err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return files, total, err
+			}
+			n, err := writeArchiveFile(tr, target, mode)
+			if err != nil {
+				return files, total, err
+			}
+			files++
+			total += n
+			copied(hdr.Name, target, n)
+		default:
+			skipped(hdr.Name)
+		}
+	}
+}
+
+// writeArchiveFile writes the current archive entry to the target file.
+func writeArchiveFile(r io.Reader, target string, mode os.FileMode) (int64, error) {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(file, r)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
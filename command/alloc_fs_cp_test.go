@@ -0,0 +1,107 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSCpCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &AllocFSCpCommand{}
+}
+
+func TestFSCpCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &AllocFSCpCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"foobar:/alloc"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on copying into an allocation
+	if code := cmd.Run([]string{"./local", "foobar:/alloc"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Copying into an allocation is not supported") {
+		t.Fatalf("expected unsupported error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "foobar:/alloc", "./local"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error querying allocation") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestFSCpCommand_ExtractArchive(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Build an archive of alloc/logs as the alloc filesystem API does
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	write := func(hdr *tar.Header, contents string) {
+		hdr.Size = int64(len(contents))
+		require.NoError(tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(err)
+	}
+	write(&tar.Header{Name: "alloc/logs/", Typeflag: tar.TypeDir, Mode: 0755}, "")
+	write(&tar.Header{Name: "alloc/logs/web.stdout.0", Typeflag: tar.TypeReg, Mode: 0640}, "hello")
+	write(&tar.Header{Name: "alloc/logs/nested/", Typeflag: tar.TypeDir, Mode: 0755}, "")
+	write(&tar.Header{Name: "alloc/logs/nested/web.stderr.0", Typeflag: tar.TypeReg, Mode: 0644}, "world!")
+	write(&tar.Header{Name: "alloc/logs/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}, "")
+	require.NoError(tw.Close())
+
+	dir, err := ioutil.TempDir("", "nomad-fs-cp")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	dest := filepath.Join(dir, "logs")
+
+	var copied, skipped []string
+	files, size, err := extractAllocArchive(bytes.NewReader(buf.Bytes()), "/alloc/logs/", dest,
+		func(name, target string, size int64) { copied = append(copied, name) },
+		func(name string) { skipped = append(skipped, name) })
+	require.NoError(err)
+	require.Equal(2, files)
+	require.EqualValues(11, size)
+	require.Equal([]string{"alloc/logs/web.stdout.0", "alloc/logs/nested/web.stderr.0"}, copied)
+	require.Equal([]string{"alloc/logs/link"}, skipped)
+
+	contents, err := ioutil.ReadFile(filepath.Join(dest, "nested", "web.stderr.0"))
+	require.NoError(err)
+	require.Equal("world!", string(contents))
+
+	info, err := os.Stat(filepath.Join(dest, "web.stdout.0"))
+	require.NoError(err)
+	require.Equal(os.FileMode(0640), info.Mode().Perm())
+	_, err = os.Lstat(filepath.Join(dest, "link"))
+	require.True(os.IsNotExist(err))
+
+	// Entries outside of the copied path are rejected
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	write(&tar.Header{Name: "alloc/data/secret", Typeflag: tar.TypeReg, Mode: 0644}, "nope")
+	require.NoError(tw.Close())
+	_, _, err = extractAllocArchive(bytes.NewReader(buf.Bytes()), "alloc/logs", dest,
+		func(string, string, int64) {}, func(string) {})
+	require.Error(err)
+	require.Contains(err.Error(), "unexpected archive entry")
+}
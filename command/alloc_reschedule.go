@@ -0,0 +1,187 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type AllocRescheduleCommand struct {
+	Meta
+}
+
+func (c *AllocRescheduleCommand) Help() string {
+	helpText := `
+Usage: nomad alloc reschedule [options] <allocation>
+
+  Reschedule a failed allocation immediately, instead of waiting for the delay
+  of the reschedule policy of its task group. The attempt is made even if the
+  policy has run out of attempts. With -status, the command instead displays
+  when the allocation will be rescheduled by its policy.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Reschedule Options:
+
+  -status
+    Display when the allocation will be rescheduled instead of rescheduling
+    it.
+
+  -detach
+    Return immediately instead of entering monitor mode. The ID of the
+    evaluation created will be printed to the screen, which can be used to
+    examine the evaluation using the eval status command.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocRescheduleCommand) Synopsis() string {
+	return "Reschedule a failed allocation or display when it is rescheduled"
+}
+
+func (c *AllocRescheduleCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-status":  complete.PredictNothing,
+			"-detach":  complete.PredictNothing,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (c *AllocRescheduleCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Allocs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Allocs]
+	})
+}
+
+func (c *AllocRescheduleCommand) Name() string { return "alloc reschedule" }
+
+func (c *AllocRescheduleCommand) Run(args []string) int {
+	var status, detach, verbose bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&status, "status", false, "")
+	flags.BoolVar(&detach, "detach", false, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one allocation ID
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <allocation>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	allocID := args[0]
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	if len(allocID) == 1 {
+		c.Ui.Error(fmt.Sprintf("Identifier must contain at least two characters."))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	allocID = sanitizeUUIDPrefix(allocID)
+	allocs, _, err := client.Allocations().PrefixList(allocID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying allocation: %v", err))
+		return 1
+	}
+	if len(allocs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No allocation(s) with prefix or id %q found", allocID))
+		return 1
+	}
+	if len(allocs) > 1 {
+		out := formatAllocListStubs(allocs, verbose, length)
+		c.Ui.Output(fmt.Sprintf("Prefix matched multiple allocations\n\n%s", out))
+		return 0
+	}
+
+	if status {
+		resp, _, err := client.Allocations().RescheduleStatus(allocs[0].ID, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error querying reschedule status: %s", err))
+			return 1
+		}
+		c.Ui.Output(formatRescheduleStatus(resp, length, time.Now()))
+		return 0
+	}
+
+	resp, err := client.Allocations().Reschedule(&api.Allocation{ID: allocs[0].ID}, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error rescheduling allocation: %s", err))
+		return 1
+	}
+
+	if detach {
+		c.Ui.Output(fmt.Sprintf("Created eval ID: %q ", limit(resp.EvalID, length)))
+		return 0
+	}
+
+	mon := newMonitor(c.Ui, client, length)
+	return mon.monitor(resp.EvalID, false)
+}
+
+// formatRescheduleStatus formats when an allocation is rescheduled relative to
+// the given time.
+func formatRescheduleStatus(s *api.AllocRescheduleStatus, length int, now time.Time) string {
+	attempts := fmt.Sprintf("%d/%d", s.Attempted, s.Attempts)
+	if s.Unlimited {
+		attempts = fmt.Sprintf("%d/unlimited", s.Attempted)
+	}
+
+	out := []string{
+		fmt.Sprintf("ID|%s", limit(s.AllocID, length)),
+		fmt.Sprintf("Eligible|%t", s.Eligible),
+		fmt.Sprintf("Attempts|%s", attempts),
+	}
+	if !s.NextRescheduleTime.IsZero() {
+		out = append(out,
+			fmt.Sprintf("Delay|%s", s.Delay),
+			fmt.Sprintf("Next Reschedule|%s (%s)", formatTime(s.NextRescheduleTime),
+				prettyTimeDiff(s.NextRescheduleTime, now)))
+	}
+	if s.FollowupEvalID != "" {
+		out = append(out, fmt.Sprintf("Follow-up Eval|%s", limit(s.FollowupEvalID, length)))
+	}
+	if s.ForceReschedule {
+		out = append(out, "Forced|true")
+	}
+	if s.NextAllocation != "" {
+		out = append(out, fmt.Sprintf("Replacement Alloc|%s", limit(s.NextAllocation, length)))
+	}
+	return formatKV(out)
+}
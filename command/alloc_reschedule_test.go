@@ -0,0 +1,91 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocRescheduleCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &AllocRescheduleCommand{}
+}
+
+func TestAllocRescheduleCommand_Fails(t *testing.T) {
+	t.Parallel()
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &AllocRescheduleCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on missing alloc
+	if code := cmd.Run([]string{"-address=" + url, "26470238-5CF2-438F-8772-DC67CFB0705C"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "No allocation(s) with prefix or id") {
+		t.Fatalf("expected not found error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fail on identifier with too few characters
+	if code := cmd.Run([]string{"-address=" + url, "2"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "must contain at least two characters.") {
+		t.Fatalf("expected too few characters error, got: %s", out)
+	}
+}
+
+func TestAllocRescheduleCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	// Create a failed allocation
+	state := srv.Agent.Server().State()
+	alloc := mock.Alloc()
+	require.Nil(state.UpsertJobSummary(999, mock.JobSummary(alloc.JobID)))
+	require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+	failed := alloc.Copy()
+	failed.ClientStatus = structs.AllocClientStatusFailed
+	failed.TaskStates = map[string]*structs.TaskState{
+		"web": {State: structs.TaskStateDead, FinishedAt: time.Now()},
+	}
+	require.Nil(state.UpdateAllocsFromClient(1001, []*structs.Allocation{failed}))
+
+	ui := new(cli.MockUi)
+	cmd := &AllocRescheduleCommand{Meta: Meta{Ui: ui}}
+
+	// Display when the allocation is rescheduled
+	require.Zero(cmd.Run([]string{"-address=" + url, "-status", alloc.ID}))
+	out := ui.OutputWriter.String()
+	require.Contains(out, "Eligible")
+	require.Contains(out, "Attempts")
+	require.Contains(out, "0/2")
+	require.Contains(out, "Next Reschedule")
+	ui.OutputWriter.Reset()
+
+	// Reschedule it
+	require.Zero(cmd.Run([]string{"-address=" + url, "-detach", alloc.ID}))
+	require.Contains(ui.OutputWriter.String(), "Created eval ID")
+
+	out2, err := state.AllocByID(nil, alloc.ID)
+	require.Nil(err)
+	require.True(out2.DesiredTransition.ShouldForceReschedule())
+}
@@ -0,0 +1,171 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type AllocRestartCommand struct {
+	Meta
+}
+
+func (c *AllocRestartCommand) Help() string {
+	helpText := `
+Usage: nomad alloc restart [options] <allocation> [<task>]
+
+  Restart the tasks of an allocation in place. If a task name is given only
+  that task is restarted, otherwise all the tasks of the allocation are. The
+  tasks are restarted on the same client, keeping their allocation directory
+  and ports, and the restart doesn't count against their restart policy.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Restart Options:
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocRestartCommand) Synopsis() string {
+	return "Restart the tasks of an allocation in place"
+}
+
+func (c *AllocRestartCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (c *AllocRestartCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Allocs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Allocs]
+	})
+}
+
+func (c *AllocRestartCommand) Name() string { return "alloc restart" }
+
+func (c *AllocRestartCommand) Run(args []string) int {
+	var verbose bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got an allocation ID and optionally a task name
+	args = flags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Ui.Error("This command takes one or two arguments: <allocation> [<task>]")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	allocID := args[0]
+
+	var taskName string
+	if len(args) == 2 {
+		taskName = args[1]
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	if len(allocID) == 1 {
+		c.Ui.Error(fmt.Sprintf("Identifier must contain at least two characters."))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	allocID = sanitizeUUIDPrefix(allocID)
+	allocs, _, err := client.Allocations().PrefixList(allocID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying allocation: %v", err))
+		return 1
+	}
+	if len(allocs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No allocation(s) with prefix or id %q found", allocID))
+		return 1
+	}
+	if len(allocs) > 1 {
+		out := formatAllocListStubs(allocs, verbose, length)
+		c.Ui.Output(fmt.Sprintf("Prefix matched multiple allocations\n\n%s", out))
+		return 0
+	}
+
+	alloc, _, err := client.Allocations().Info(allocs[0].ID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying allocation: %s", err))
+		return 1
+	}
+
+	// Validate the task name against the tasks of the allocation's group
+	if taskName != "" && !allocHasTask(alloc, taskName) {
+		c.Ui.Error(fmt.Sprintf("Allocation %q has no task %q. It is running the following tasks:", limit(alloc.ID, length), taskName))
+		for _, t := range allocTasks(alloc) {
+			c.Ui.Error(fmt.Sprintf("  * %s", t.Name))
+		}
+		return 1
+	}
+
+	if err := client.Allocations().Restart(alloc, taskName, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error restarting allocation: %s", err))
+		return 1
+	}
+
+	if taskName != "" {
+		c.Ui.Output(fmt.Sprintf("Restarted task %q of allocation %q", taskName, limit(alloc.ID, length)))
+	} else {
+		c.Ui.Output(fmt.Sprintf("Restarted the tasks of allocation %q", limit(alloc.ID, length)))
+	}
+	return 0
+}
+
+// allocTasks returns the tasks of the allocation's task group.
+func allocTasks(alloc *api.Allocation) []*api.Task {
+	for _, tg := range alloc.Job.TaskGroups {
+		if *tg.Name == alloc.TaskGroup {
+			return tg.Tasks
+		}
+	}
+	return nil
+}
+
+// allocHasTask returns whether the allocation's task group has a task with
+// the given name.
+func allocHasTask(alloc *api.Allocation, name string) bool {
+	for _, t := range allocTasks(alloc) {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
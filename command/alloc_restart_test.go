@@ -0,0 +1,72 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocRestartCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &AllocRestartCommand{}
+}
+
+func TestAllocRestartCommand_Fails(t *testing.T) {
+	t.Parallel()
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &AllocRestartCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on missing alloc
+	if code := cmd.Run([]string{"-address=" + url, "26470238-5CF2-438F-8772-DC67CFB0705C"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "No allocation(s) with prefix or id") {
+		t.Fatalf("expected not found error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fail on identifier with too few characters
+	if code := cmd.Run([]string{"-address=" + url, "2"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "must contain at least two characters.") {
+		t.Fatalf("expected too few characters error, got: %s", out)
+	}
+}
+
+func TestAllocRestartCommand_UnknownTask(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	state := srv.Agent.Server().State()
+	alloc := mock.Alloc()
+	require.Nil(state.UpsertJobSummary(999, mock.JobSummary(alloc.JobID)))
+	require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+	ui := new(cli.MockUi)
+	cmd := &AllocRestartCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on a task that isn't part of the allocation
+	require.Equal(1, cmd.Run([]string{"-address=" + url, alloc.ID, "foo"}))
+	out := ui.ErrorWriter.String()
+	require.Contains(out, `has no task "foo"`)
+	require.Contains(out, "* web")
+}
@@ -58,12 +58,10 @@ func (c *AllocStatusCommand) Synopsis() string {
 }
 
 func (c *AllocStatusCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
 		complete.Flags{
 			"-short":   complete.PredictNothing,
 			"-verbose": complete.PredictNothing,
-			"-json":    complete.PredictNothing,
-			"-t":       complete.PredictAnything,
 		})
 }
 
@@ -85,16 +83,13 @@ func (c *AllocStatusCommand) AutocompleteArgs() complete.Predictor {
 func (c *AllocStatusCommand) Name() string { return "alloc status" }
 
 func (c *AllocStatusCommand) Run(args []string) int {
-	var short, displayStats, verbose, json bool
-	var tmpl string
+	var short, displayStats, verbose bool
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&short, "short", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
 	flags.BoolVar(&displayStats, "stats", false, "")
-	flags.BoolVar(&json, "json", false, "")
-	flags.StringVar(&tmpl, "t", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -111,21 +106,14 @@ func (c *AllocStatusCommand) Run(args []string) int {
 	}
 
 	// If args not specified but output format is specified, format and output the allocations data list
-	if len(args) == 0 && json || len(tmpl) > 0 {
+	if len(args) == 0 && c.formatted() {
 		allocs, _, err := client.Allocations().List(nil)
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("Error querying allocations: %v", err))
 			return 1
 		}
 
-		out, err := Format(json, tmpl, allocs)
-		if err != nil {
-			c.Ui.Error(err.Error())
-			return 1
-		}
-
-		c.Ui.Output(out)
-		return 0
+		return c.outputFormatted(allocs)
 	}
 
 	if len(args) != 1 {
@@ -172,15 +160,8 @@ func (c *AllocStatusCommand) Run(args []string) int {
 	}
 
 	// If output format is specified, format and output the data
-	if json || len(tmpl) > 0 {
-		out, err := Format(json, tmpl, alloc)
-		if err != nil {
-			c.Ui.Error(err.Error())
-			return 1
-		}
-
-		c.Ui.Output(out)
-		return 0
+	if c.formatted() {
+		return c.outputFormatted(alloc)
 	}
 
 	// Format the allocation data
@@ -330,11 +311,20 @@ func formatTaskTimes(t time.Time) string {
 	return formatTime(t)
 }
 
+func formatTaskLatency(d time.Duration) string {
+	if d == 0 {
+		return "N/A"
+	}
+
+	return d.Round(time.Millisecond).String()
+}
+
 // outputTaskStatus prints out a list of the most recent events for the given
 // task state.
 func (c *AllocStatusCommand) outputTaskStatus(state *api.TaskState) {
 	basic := []string{
 		fmt.Sprintf("Started At|%s", formatTaskTimes(state.StartedAt)),
+		fmt.Sprintf("Start Latency|%s", formatTaskLatency(state.StartLatency)),
 		fmt.Sprintf("Finished At|%s", formatTaskTimes(state.FinishedAt)),
 		fmt.Sprintf("Total Restarts|%d", state.Restarts),
 		fmt.Sprintf("Last Restart|%s", formatTaskTimes(state.LastRestart))}
@@ -540,6 +530,7 @@ func (c *AllocStatusCommand) outputTaskResources(alloc *api.Allocation, task str
 func (c *AllocStatusCommand) outputVerboseResourceUsage(task string, resourceUsage *api.ResourceUsage) {
 	memoryStats := resourceUsage.MemoryStats
 	cpuStats := resourceUsage.CpuStats
+	networkStats := resourceUsage.NetworkStats
 	deviceStats := resourceUsage.DeviceStats
 
 	if memoryStats != nil && len(memoryStats.Measured) > 0 {
@@ -606,6 +597,60 @@ func (c *AllocStatusCommand) outputVerboseResourceUsage(task string, resourceUsa
 		c.Ui.Output(formatList(out))
 	}
 
+	if networkStats != nil && len(networkStats.Measured) > 0 {
+		c.Ui.Output("")
+		c.Ui.Output("Network Stats")
+
+		// Sort the measured stats
+		sort.Strings(networkStats.Measured)
+
+		var measuredStats []string
+		for _, measured := range networkStats.Measured {
+			switch measured {
+			case "Rx Bytes":
+				measuredStats = append(measuredStats, humanize.IBytes(networkStats.RxBytes))
+			case "Tx Bytes":
+				measuredStats = append(measuredStats, humanize.IBytes(networkStats.TxBytes))
+			case "Rx Dropped":
+				measuredStats = append(measuredStats, fmt.Sprintf("%v", networkStats.RxDropped))
+			case "Tx Dropped":
+				measuredStats = append(measuredStats, fmt.Sprintf("%v", networkStats.TxDropped))
+			}
+		}
+
+		out := make([]string, 2)
+		out[0] = strings.Join(networkStats.Measured, "|")
+		out[1] = strings.Join(measuredStats, "|")
+		c.Ui.Output(formatList(out))
+	}
+
+	if networkStats != nil && len(networkStats.Measured) > 0 {
+		c.Ui.Output("")
+		c.Ui.Output("Network Stats")
+
+		// Sort the measured stats
+		sort.Strings(networkStats.Measured)
+
+		var measuredStats []string
+		for _, measured := range networkStats.Measured {
+			switch measured {
+			case "Rx Bytes":
+				measuredStats = append(measuredStats, humanize.IBytes(networkStats.RxBytes))
+			case "Tx Bytes":
+				measuredStats = append(measuredStats, humanize.IBytes(networkStats.TxBytes))
+			case "Rx Dropped":
+				measuredStats = append(measuredStats, fmt.Sprintf("%v", networkStats.RxDropped))
+			case "Tx Dropped":
+				measuredStats = append(measuredStats, fmt.Sprintf("%v", networkStats.TxDropped))
+			}
+		}
+
+		out := make([]string, 2)
+		out[0] = strings.Join(networkStats.Measured, "|")
+		out[1] = strings.Join(measuredStats, "|")
+		c.Ui.Output(formatList(out))
+	}
+
 	if len(deviceStats) > 0 {
 		c.Ui.Output("")
 		c.Ui.Output("Device Stats")
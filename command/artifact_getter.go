@@ -0,0 +1,33 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
+)
+
+type ArtifactGetterCommand struct {
+	Meta
+}
+
+func (c *ArtifactGetterCommand) Help() string {
+	helpText := `
+	This is a command used by Nomad internally to download artifacts in a
+	sandboxed process
+	`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ArtifactGetterCommand) Synopsis() string {
+	return "internal - download an artifact"
+}
+
+func (c *ArtifactGetterCommand) Run(args []string) int {
+	if err := getter.RunSandbox(os.Stdin); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -34,7 +34,8 @@ Promote Options:
 
   -group
     Group may be specified many times and is used to promote that particular
-    group. If no specific groups are specified, all groups are promoted.
+    group. If no specific groups are specified, all groups are promoted except
+    those setting "manual_promotion_per_group", which must be named.
 
   -detach
     Return immediately instead of entering monitor mode. After deployment
@@ -44,11 +44,9 @@ func (c *DeploymentStatusCommand) Synopsis() string {
 }
 
 func (c *DeploymentStatusCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
 		complete.Flags{
 			"-verbose": complete.PredictNothing,
-			"-json":    complete.PredictNothing,
-			"-t":       complete.PredictAnything,
 		})
 }
 
@@ -70,14 +68,11 @@ func (c *DeploymentStatusCommand) AutocompleteArgs() complete.Predictor {
 func (c *DeploymentStatusCommand) Name() string { return "deployment status" }
 
 func (c *DeploymentStatusCommand) Run(args []string) int {
-	var json, verbose bool
-	var tmpl string
+	var verbose bool
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&verbose, "verbose", false, "")
-	flags.BoolVar(&json, "json", false, "")
-	flags.StringVar(&tmpl, "t", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -118,15 +113,8 @@ func (c *DeploymentStatusCommand) Run(args []string) int {
 		return 1
 	}
 
-	if json || len(tmpl) > 0 {
-		out, err := Format(json, tmpl, deploy)
-		if err != nil {
-			c.Ui.Error(err.Error())
-			return 1
-		}
-
-		c.Ui.Output(out)
-		return 0
+	if c.formatted() {
+		return c.outputFormatted(deploy)
 	}
 
 	c.Ui.Output(c.Colorize().Color(formatDeployment(deploy, length)))
@@ -195,13 +183,19 @@ func formatDeployment(d *api.Deployment, uuidLength int) string {
 
 func formatDeploymentGroups(d *api.Deployment, uuidLength int) string {
 	// Detect if we need to add these columns
-	var canaries, autorevert, progressDeadline bool
+	var canaries, autorevert, autopromote, perGroup, progressDeadline bool
 	tgNames := make([]string, 0, len(d.TaskGroups))
 	for name, state := range d.TaskGroups {
 		tgNames = append(tgNames, name)
 		if state.AutoRevert {
 			autorevert = true
 		}
+		if state.AutoPromote {
+			autopromote = true
+		}
+		if state.ManualPromotionPerGroup {
+			perGroup = true
+		}
 		if state.DesiredCanaries > 0 {
 			canaries = true
 		}
@@ -218,6 +212,12 @@ func formatDeploymentGroups(d *api.Deployment, uuidLength int) string {
 	if autorevert {
 		rowString += "Auto Revert|"
 	}
+	if autopromote {
+		rowString += "Auto Promote|"
+	}
+	if perGroup {
+		rowString += "Promote By Name|"
+	}
 	if canaries {
 		rowString += "Promoted|"
 	}
@@ -239,6 +239,12 @@ func formatDeploymentGroups(d *api.Deployment, uuidLength int) string {
 		if autorevert {
 			row += fmt.Sprintf("%v|", state.AutoRevert)
 		}
+		if autopromote {
+			row += fmt.Sprintf("%v|", state.AutoPromote)
+		}
+		if perGroup {
+			row += fmt.Sprintf("%v|", state.ManualPromotionPerGroup)
+		}
 		if canaries {
 			if state.DesiredCanaries > 0 {
 				row += fmt.Sprintf("%v|", state.Promoted)
@@ -76,6 +76,7 @@ func (e *ExecutorPluginCommand) Run(args []string) int {
 		Plugins: executor.GetPluginMap(
 			logger,
 			executorConfig.FSIsolation,
+			executorConfig.UserNamespace,
 		),
 		GRPCServer: plugin.DefaultGRPCServer,
 		Logger:     logger,
@@ -383,6 +383,7 @@ type JobGetter struct {
 // StructJob returns the Job struct from jobfile.
 func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
 	var jobfile io.Reader
+	var filename string
 	switch jpath {
 	case "-":
 		if j.testStdin != nil {
@@ -395,6 +396,12 @@ func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
 			return nil, fmt.Errorf("Error jobfile path has to be specified.")
 		}
 
+		// Local job files are parsed with their path, so that the files they
+		// reference are resolved from their directory
+		if fi, err := os.Stat(jpath); err == nil && fi.Mode().IsRegular() {
+			filename = jpath
+		}
+
 		job, err := ioutil.TempFile("", "jobfile")
 		if err != nil {
 			return nil, err
@@ -430,8 +437,16 @@ func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
 	}
 
 	// Parse the JobFile
-	jobStruct, err := jobspec.Parse(jobfile)
+	src, err := ioutil.ReadAll(jobfile)
 	if err != nil {
+		return nil, fmt.Errorf("Error reading job file from %s: %v", jpath, err)
+	}
+	jobStruct, err := jobspec.ParseBytes(src, filename)
+	if err != nil {
+		// Errors are already prefixed with the path of local job files
+		if filename != "" {
+			return nil, fmt.Errorf("Error parsing job file: %v", err)
+		}
 		return nil, fmt.Errorf("Error parsing job file from %s: %v", jpath, err)
 	}
 
@@ -41,6 +41,13 @@ Dispatch Options:
     once to inject multiple metadata key/value pairs. Arbitrary keys are not
     allowed. The parameterized job must allow the key to be merged.
 
+  -count <n>
+    Dispatch n indexed instances of the parameterized job as an array. Each
+    instance receives the same metadata and payload, as well as the
+    "dispatch_array_id" and "dispatch_array_index" metadata keys identifying the
+    array and the instance's index within it. When dispatching an array the
+    evaluations are not monitored.
+
   -detach
     Return immediately instead of entering monitor mode. After job dispatch,
     the evaluation ID will be printed to the screen, which can be used to
@@ -60,6 +67,7 @@ func (c *JobDispatchCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
 			"-meta":    complete.PredictAnything,
+			"-count":   complete.PredictAnything,
 			"-detach":  complete.PredictNothing,
 			"-verbose": complete.PredictNothing,
 		})
@@ -85,12 +93,14 @@ func (c *JobDispatchCommand) Name() string { return "job dispatch" }
 func (c *JobDispatchCommand) Run(args []string) int {
 	var detach, verbose bool
 	var meta []string
+	var count int
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&detach, "detach", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
 	flags.Var((*flaghelper.StringFlag)(&meta), "meta", "")
+	flags.IntVar(&count, "count", 0, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -147,6 +157,30 @@ func (c *JobDispatchCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Dispatch an array of the job
+	if count > 1 {
+		resp, _, err := client.Jobs().DispatchArray(job, count, metaMap, payload, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to dispatch job: %s", err))
+			return 1
+		}
+
+		c.Ui.Output(formatKV([]string{fmt.Sprintf("Dispatch Array ID|%s", resp.ArrayID)}))
+		c.Ui.Output("")
+
+		rows := make([]string, len(resp.DispatchedJobIDs)+1)
+		rows[0] = "Index|Dispatched Job ID|Evaluation ID"
+		for i, id := range resp.DispatchedJobIDs {
+			evalID := ""
+			if i < len(resp.EvalIDs) {
+				evalID = limit(resp.EvalIDs[i], length)
+			}
+			rows[i+1] = fmt.Sprintf("%d|%s|%s", i, id, evalID)
+		}
+		c.Ui.Output(formatList(rows))
+		return 0
+	}
+
 	// Dispatch the job
 	resp, _, err := client.Jobs().Dispatch(job, metaMap, payload, nil)
 	if err != nil {
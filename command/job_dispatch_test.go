@@ -45,6 +45,14 @@ func TestJobDispatchCommand_Fails(t *testing.T) {
 		t.Fatalf("expected failed query error, got: %s", out)
 	}
 	ui.ErrorWriter.Reset()
+
+	if code := cmd.Run([]string{"-address=nope", "-count=3", "foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Failed to dispatch") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
 }
 
 func TestJobDispatchCommand_AutocompleteArgs(t *testing.T) {
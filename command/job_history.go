@@ -41,6 +41,10 @@ History Options:
   -version <job version>
     Display only the history for the given job version.
 
+  -since <duration|time>
+    Display only the versions submitted since the given time, either a
+    duration before now such as "2h" or an RFC3339 timestamp.
+
   -json
     Output the job versions in a JSON format.
 
@@ -60,6 +64,7 @@ func (c *JobHistoryCommand) AutocompleteFlags() complete.Flags {
 			"-p":       complete.PredictNothing,
 			"-full":    complete.PredictNothing,
 			"-version": complete.PredictAnything,
+			"-since":   complete.PredictAnything,
 			"-json":    complete.PredictNothing,
 			"-t":       complete.PredictAnything,
 		})
@@ -84,7 +89,7 @@ func (c *JobHistoryCommand) Name() string { return "job history" }
 
 func (c *JobHistoryCommand) Run(args []string) int {
 	var json, diff, full bool
-	var tmpl, versionStr string
+	var tmpl, versionStr, sinceStr string
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
@@ -92,6 +97,7 @@ func (c *JobHistoryCommand) Run(args []string) int {
 	flags.BoolVar(&full, "full", false, "")
 	flags.BoolVar(&json, "json", false, "")
 	flags.StringVar(&versionStr, "version", "", "")
+	flags.StringVar(&sinceStr, "since", "", "")
 	flags.StringVar(&tmpl, "t", "", "")
 
 	if err := flags.Parse(args); err != nil {
@@ -111,6 +117,21 @@ func (c *JobHistoryCommand) Run(args []string) int {
 		return 1
 	}
 
+	if versionStr != "" && sinceStr != "" {
+		c.Ui.Error("-version is exclusive with -since")
+		return 1
+	}
+
+	var since time.Time
+	if sinceStr != "" {
+		var err error
+		since, err = parseSince(sinceStr, time.Now())
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error parsing since value %q: %v", sinceStr, err))
+			return 1
+		}
+	}
+
 	// Get the HTTP client
 	client, err := c.Meta.Client()
 	if err != nil {
@@ -189,7 +210,7 @@ func (c *JobHistoryCommand) Run(args []string) int {
 
 	} else {
 		if json || len(tmpl) > 0 {
-			out, err := Format(json, tmpl, versions)
+			out, err := Format(json, tmpl, versionsSince(versions, since))
 			if err != nil {
 				c.Ui.Error(err.Error())
 				return 1
@@ -199,7 +220,7 @@ func (c *JobHistoryCommand) Run(args []string) int {
 			return 0
 		}
 
-		if err := c.formatJobVersions(versions, diffs, full); err != nil {
+		if err := c.formatJobVersions(versions, diffs, since, full); err != nil {
 			c.Ui.Error(err.Error())
 			return 1
 		}
@@ -219,14 +240,51 @@ func parseVersion(input string) (uint64, bool, error) {
 	return u, true, err
 }
 
-func (c *JobHistoryCommand) formatJobVersions(versions []*api.Job, diffs []*api.JobDiff, full bool) error {
+// parseSince parses the since flag, either a duration before now or an
+// RFC3339 timestamp.
+func parseSince(input string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(input); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, input)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be a duration or an RFC3339 timestamp")
+	}
+	return t, nil
+}
+
+// versionsSince returns the versions submitted at or after since. Versions
+// are ordered newest first.
+func versionsSince(versions []*api.Job, since time.Time) []*api.Job {
+	if since.IsZero() {
+		return versions
+	}
+
+	for i, v := range versions {
+		if time.Unix(0, *v.SubmitTime).Before(since) {
+			return versions[:i]
+		}
+	}
+	return versions
+}
+
+func (c *JobHistoryCommand) formatJobVersions(versions []*api.Job, diffs []*api.JobDiff, since time.Time, full bool) error {
 	vLen := len(versions)
 	dLen := len(diffs)
 	if dLen != 0 && vLen != dLen+1 {
 		return fmt.Errorf("Number of job versions %d doesn't match number of diffs %d", vLen, dLen)
 	}
 
-	for i, version := range versions {
+	// Only display the recent versions, still diffing the oldest of them
+	// against its predecessor
+	shown := len(versionsSince(versions, since))
+	if shown == 0 {
+		c.Ui.Output("No job versions submitted since " + formatTime(since))
+		return nil
+	}
+
+	for i, version := range versions[:shown] {
 		var diff *api.JobDiff
 		var nextVersion uint64
 		if i+1 <= dLen {
@@ -239,7 +297,7 @@ func (c *JobHistoryCommand) formatJobVersions(versions []*api.Job, diffs []*api.
 		}
 
 		// Insert a blank
-		if i != vLen-1 {
+		if i != shown-1 {
 			c.Ui.Output("")
 		}
 	}
@@ -258,9 +316,15 @@ func (c *JobHistoryCommand) formatJobVersion(job *api.Job, diff *api.JobDiff, ne
 		fmt.Sprintf("Submit Date|%v", formatTime(time.Unix(0, *job.SubmitTime))),
 	}
 
+	if tag := job.VersionTag; tag != nil {
+		basic = append(basic, fmt.Sprintf("Tag|%s", tag.Name))
+		if tag.Description != "" {
+			basic = append(basic, fmt.Sprintf("Tag Description|%s", tag.Description))
+		}
+	}
+
 	if diff != nil {
-		//diffStr := fmt.Sprintf("Difference between version %d and %d:", *job.Version, nextVersion)
-		basic = append(basic, fmt.Sprintf("Diff|\n%s", strings.TrimSpace(formatJobDiff(diff, false))))
+		basic = append(basic, fmt.Sprintf("Diff|Changes since version %d\n%s", nextVersion, strings.TrimSpace(formatJobDiff(diff, false))))
 	}
 
 	if full {
@@ -3,11 +3,13 @@ package command
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJobHistoryCommand_Implements(t *testing.T) {
@@ -61,3 +63,47 @@ func TestJobHistoryCommand_AutocompleteArgs(t *testing.T) {
 	assert.Equal(1, len(res))
 	assert.Equal(j.ID, res[0])
 }
+
+func TestJobHistoryCommand_Since(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	// Submit two versions of a job, the first well in the past
+	state := srv.Agent.Server().State()
+	job := mock.Job()
+	job.SubmitTime = time.Now().Add(-2 * time.Hour).UnixNano()
+	require.NoError(state.UpsertJob(100, job))
+	job = job.Copy()
+	job.TaskGroups[0].Count = 5
+	job.SubmitTime = time.Now().Add(-10 * time.Minute).UnixNano()
+	require.NoError(state.UpsertJob(101, job))
+
+	ui := new(cli.MockUi)
+	cmd := &JobHistoryCommand{Meta: Meta{Ui: ui}}
+
+	// Only the recent version is shown, with its diff against the previous
+	code := cmd.Run([]string{"-address=" + url, "-p", "-since=1h", job.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	out := ui.OutputWriter.String()
+	require.Contains(out, "Version     = 1")
+	require.NotContains(out, "Version     = 0")
+	require.Contains(out, "Changes since version 0")
+	require.Contains(out, `Count: "10" => "5"`)
+	ui.OutputWriter.Reset()
+
+	// No versions were submitted since the timestamp
+	code = cmd.Run([]string{"-address=" + url, "-since=" + time.Now().Format(time.RFC3339), job.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "No job versions submitted since")
+
+	// Invalid values and conflicting flags are rejected
+	code = cmd.Run([]string{"-address=" + url, "-since=yesterday", job.ID})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "must be a duration or an RFC3339 timestamp")
+
+	code = cmd.Run([]string{"-address=" + url, "-since=1h", "-version=1", job.ID})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "-version is exclusive with -since")
+}
@@ -16,8 +16,10 @@ func (c *JobRevertCommand) Help() string {
 	helpText := `
 Usage: nomad job revert [options] <job> <version>
 
-  Revert is used to revert a job to a prior version of the job. The available
-  versions to revert to can be found using "nomad job history" command.
+  Revert is used to revert a job to a prior version of the job. The version is
+  either a version number or the name of a tag applied using the "nomad job tag"
+  command. The available versions to revert to can be found using "nomad job
+  history" command.
 
 General Options:
 
@@ -104,9 +106,11 @@ func (c *JobRevertCommand) Run(args []string) int {
 		c.Ui.Error("The job version to revert to must be specified using the -job-version flag")
 		return 1
 	}
+
+	// Versions that aren't numbers are tag names
+	revertTag := ""
 	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Failed to parse job-version flag: %v", err))
-		return 1
+		revertTag = args[1]
 	}
 
 	// Check if the job exists
@@ -124,6 +128,27 @@ func (c *JobRevertCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Look up the version with the tag
+	if revertTag != "" {
+		versions, _, _, err := client.Jobs().Versions(jobs[0].ID, false, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error retrieving job versions: %s", err))
+			return 1
+		}
+
+		found := false
+		for _, v := range versions {
+			if v.VersionTag != nil && v.VersionTag.Name == revertTag {
+				revertVersion, found = *v.Version, true
+				break
+			}
+		}
+		if !found {
+			c.Ui.Error(fmt.Sprintf("No version of job %q is tagged %q", jobs[0].ID, revertTag))
+			return 1
+		}
+	}
+
 	// Prefix lookup matched a single job
 	resp, _, err := client.Jobs().Revert(jobs[0].ID, revertVersion, nil, nil)
 	if err != nil {
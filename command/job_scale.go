@@ -0,0 +1,205 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api/contexts"
+	flaghelper "github.com/hashicorp/nomad/helper/flag-helpers"
+	"github.com/posener/complete"
+)
+
+type JobScaleCommand struct {
+	Meta
+}
+
+func (c *JobScaleCommand) Help() string {
+	helpText := `
+Usage: nomad job scale [options] <job> [<group>] <count>
+
+  Scale is used to change the count of a task group of a job. The group may be
+  omitted if the job has a single task group. Upon successful scaling, an
+  interactive monitor session will start to display log lines as the job
+  starts or stops allocations. The scaling events of the job can be viewed
+  using the "nomad job scaling-events" command.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Scale Options:
+
+  -detach
+    Return immediately instead of entering monitor mode. After scaling the
+    job, the evaluation ID will be printed to the screen, which can be used to
+    examine the evaluation using the eval-status command.
+
+  -message <message>
+    The reason for scaling the task group, recorded with the scaling event.
+
+  -meta <key>=<value>
+    Metadata recorded with the scaling event, such as the source of the
+    decision to scale. The flag can be provided more than once to record
+    multiple metadata key/value pairs.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobScaleCommand) Synopsis() string {
+	return "Change the count of a task group"
+}
+
+func (c *JobScaleCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-detach":  complete.PredictNothing,
+			"-message": complete.PredictAnything,
+			"-meta":    complete.PredictAnything,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (c *JobScaleCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Jobs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Jobs]
+	})
+}
+
+func (c *JobScaleCommand) Name() string { return "job scale" }
+
+func (c *JobScaleCommand) Run(args []string) int {
+	var detach, verbose bool
+	var message string
+	var meta []string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&detach, "detach", false, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.StringVar(&message, "message", "", "")
+	flags.Var((*flaghelper.StringFlag)(&meta), "meta", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Check that we got two or three args
+	args = flags.Args()
+	if l := len(args); l != 2 && l != 3 {
+		c.Ui.Error("This command takes two or three arguments: <job> [<group>] <count>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	jobID := args[0]
+	var group string
+	if len(args) == 3 {
+		group = args[1]
+	}
+	count, err := strconv.Atoi(args[len(args)-1])
+	if err != nil || count < 0 {
+		c.Ui.Error(fmt.Sprintf("Invalid count %q: must be a non-negative integer", args[len(args)-1]))
+		return 1
+	}
+
+	// Build the meta
+	metaMap := make(map[string]string, len(meta))
+	for _, m := range meta {
+		split := strings.SplitN(m, "=", 2)
+		if len(split) != 2 {
+			c.Ui.Error(fmt.Sprintf("Error parsing meta value: %v", m))
+			return 1
+		}
+
+		metaMap[split[0]] = split[1]
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing jobs: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+
+	// Prefix lookup matched a single job
+	job, _, err := client.Jobs().Info(jobs[0].ID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error retrieving job: %s", err))
+		return 1
+	}
+
+	// Default to the only task group of the job
+	if group == "" {
+		if len(job.TaskGroups) != 1 {
+			names := make([]string, len(job.TaskGroups))
+			for i, tg := range job.TaskGroups {
+				names[i] = *tg.Name
+			}
+			sort.Strings(names)
+			c.Ui.Error(fmt.Sprintf("Job %q has multiple task groups, so the group to scale must be given: %s",
+				*job.ID, strings.Join(names, ", ")))
+			return 1
+		}
+		group = *job.TaskGroups[0].Name
+	}
+
+	resp, _, err := client.Jobs().Scale(*job.ID, group, &count, message, false, metaMap, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error scaling job: %s", err))
+		return 1
+	}
+
+	if resp.Warnings != "" {
+		c.Ui.Output(
+			c.Colorize().Color(fmt.Sprintf("[bold][yellow]Job Warnings:\n%s[reset]\n", resp.Warnings)))
+	}
+
+	// Nothing to do
+	evalCreated := resp.EvalID != ""
+	if !evalCreated {
+		return 0
+	}
+
+	if detach {
+		c.Ui.Output("Evaluation ID: " + resp.EvalID)
+		return 0
+	}
+
+	mon := newMonitor(c.Ui, client, length)
+	return mon.monitor(resp.EvalID, false)
+}
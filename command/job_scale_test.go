@@ -0,0 +1,97 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobScaleCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &JobScaleCommand{}
+}
+
+func TestJobScaleCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &JobScaleCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"some"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"job", "group", "many"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Invalid count")
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"-meta=invalid", "job", "1"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error parsing meta value")
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	code = cmd.Run([]string{"-address=nope", "job", "1"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error listing jobs")
+}
+
+func TestJobScaleCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	state := srv.Agent.Server().State()
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1000, job))
+
+	ui := new(cli.MockUi)
+	cmd := &JobScaleCommand{Meta: Meta{Ui: ui}}
+
+	// The group defaults to the only task group of the job
+	code := cmd.Run([]string{"-address=" + url, "-detach", "-message=more capacity",
+		"-meta=source=test", job.ID, "3"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "Evaluation ID")
+
+	status, _, err := client.Jobs().ScaleStatus(job.ID, nil)
+	require.NoError(err)
+	require.Equal(3, status.TaskGroups["web"].Desired)
+	events := status.TaskGroups["web"].Events
+	require.Len(events, 1)
+	require.Equal("more capacity", events[0].Message)
+	require.Equal(map[string]string{"source": "test"}, events[0].Meta)
+
+	// Jobs with multiple groups require the group
+	multi := mock.Job()
+	group := multi.TaskGroups[0].Copy()
+	group.Name = "api"
+	multi.TaskGroups = append(multi.TaskGroups, group)
+	require.NoError(state.UpsertJob(1001, multi))
+
+	code = cmd.Run([]string{"-address=" + url, "-detach", multi.ID, "3"})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "group to scale must be given: api, web")
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"-address=" + url, "-detach", multi.ID, "api", "0"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+
+	out, err := state.JobByID(nil, structs.DefaultNamespace, multi.ID)
+	require.NoError(err)
+	require.Equal(0, out.LookupTaskGroup("api").Count)
+	require.Equal(10, out.LookupTaskGroup("web").Count)
+
+	// Unknown groups fail
+	code = cmd.Run([]string{"-address=" + url, "-detach", multi.ID, "unknown", "1"})
+	require.Equal(1, code)
+	require.True(strings.Contains(ui.ErrorWriter.String(), `task group "unknown" not found`), ui.ErrorWriter.String())
+}
@@ -0,0 +1,210 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type JobScalingEventsCommand struct {
+	Meta
+}
+
+func (c *JobScalingEventsCommand) Help() string {
+	helpText := `
+Usage: nomad job scaling-events [options] <job>
+
+  Scaling-events is used to display the recent scaling events of the task
+  groups of a job, from newest to oldest. Events record who changed the count
+  of a task group, from what to what and why, as well as the failures of
+  external autoscalers to scale it.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Scaling-Events Options:
+
+  -verbose
+    Display full information, including the metadata of the events.
+
+  -json
+    Output the scaling events in a JSON format.
+
+  -t
+    Format and display the scaling events using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobScalingEventsCommand) Synopsis() string {
+	return "Display the scaling events of a job"
+}
+
+func (c *JobScalingEventsCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-verbose": complete.PredictNothing,
+			"-json":    complete.PredictNothing,
+			"-t":       complete.PredictAnything,
+		})
+}
+
+func (c *JobScalingEventsCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Jobs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Jobs]
+	})
+}
+
+func (c *JobScalingEventsCommand) Name() string { return "job scaling-events" }
+
+func (c *JobScalingEventsCommand) Run(args []string) int {
+	var json, verbose bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Check that we got exactly one job
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <job>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	jobID := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing jobs: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+
+	// Prefix lookup matched a single job
+	status, _, err := client.Jobs().ScaleStatus(jobs[0].ID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error retrieving scaling events: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, status)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatScalingEvents(status, verbose, length))
+	return 0
+}
+
+// formatScalingEvents formats the scaling events of all the task groups of a
+// job, from newest to oldest.
+func formatScalingEvents(status *api.JobScaleStatus, verbose bool, length int) string {
+	type groupEvent struct {
+		group string
+		event api.ScalingEvent
+	}
+	var events []groupEvent
+	for group, tg := range status.TaskGroups {
+		for _, event := range tg.Events {
+			events = append(events, groupEvent{group, event})
+		}
+	}
+	if len(events) == 0 {
+		return "No scaling events found"
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].event.Time != events[j].event.Time {
+			return events[i].event.Time > events[j].event.Time
+		}
+		return events[i].group < events[j].group
+	})
+
+	header := "Task Group|Count|Prev Count|Error|Date|Message"
+	if verbose {
+		header += "|Eval ID|Accessor ID|Meta"
+	}
+	rows := []string{header}
+	for _, e := range events {
+		count := ""
+		if e.event.Count != nil {
+			count = fmt.Sprintf("%d", *e.event.Count)
+		}
+		row := fmt.Sprintf("%s|%s|%d|%t|%s|%s",
+			e.group,
+			count,
+			e.event.PreviousCount,
+			e.event.Error,
+			formatUnixNanoTime(e.event.Time),
+			e.event.Message)
+		if verbose {
+			row += fmt.Sprintf("|%s|%s|%s",
+				limit(e.event.EvalID, length),
+				e.event.AccessorID,
+				formatScalingEventMeta(e.event.Meta))
+		}
+		rows = append(rows, row)
+	}
+	return formatList(rows)
+}
+
+func formatScalingEventMeta(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, meta[k])
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobScalingEventsCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &JobScalingEventsCommand{}
+}
+
+func TestJobScalingEventsCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &JobScalingEventsCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"some", "bad", "args"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	code = cmd.Run([]string{"-address=nope", "job"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error listing jobs")
+}
+
+func TestJobScalingEventsCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	state := srv.Agent.Server().State()
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1000, job))
+
+	ui := new(cli.MockUi)
+	cmd := &JobScalingEventsCommand{Meta: Meta{Ui: ui}}
+
+	code := cmd.Run([]string{"-address=" + url, job.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "No scaling events found")
+	ui.OutputWriter.Reset()
+
+	for i, event := range []*structs.ScalingEvent{
+		{Time: 1, Count: helper.Int64ToPtr(5), PreviousCount: 10, Message: "scale down",
+			EvalID: "3e19a3a4-7d26-f8ac-9f39-7a0d2c8a6f4e", Meta: map[string]string{"source": "test"}},
+		{Time: 2, Message: "metrics unavailable", Error: true},
+	} {
+		require.NoError(state.UpsertScalingEvent(uint64(1001+i), &structs.ScalingEventRequest{
+			JobID:        job.ID,
+			TaskGroup:    "web",
+			ScalingEvent: event,
+			WriteRequest: structs.WriteRequest{Namespace: job.Namespace},
+		}))
+	}
+
+	code = cmd.Run([]string{"-address=" + url, job.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	out := ui.OutputWriter.String()
+	require.Contains(out, "Task Group")
+	require.Contains(out, "scale down")
+	require.Contains(out, "metrics unavailable")
+	require.NotContains(out, "Eval ID")
+	require.True(strings.Index(out, "metrics unavailable") < strings.Index(out, "scale down"))
+	ui.OutputWriter.Reset()
+
+	// Verbose output includes the eval and metadata
+	code = cmd.Run([]string{"-address=" + url, "-verbose", job.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	out = ui.OutputWriter.String()
+	require.Contains(out, "3e19a3a4-7d26-f8ac-9f39-7a0d2c8a6f4e")
+	require.Contains(out, "source=test")
+	ui.OutputWriter.Reset()
+
+	// JSON output
+	code = cmd.Run([]string{"-address=" + url, "-json", job.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `"Message": "scale down"`)
+}
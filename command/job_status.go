@@ -26,6 +26,15 @@ type JobStatusCommand struct {
 	verbose   bool
 }
 
+// JobStatus is the status of a single job output by the -json and -t flags.
+type JobStatus struct {
+	Job              *api.Job
+	Summary          *api.JobSummary
+	Allocations      []*api.AllocationListStub
+	Evaluations      []*api.Evaluation
+	LatestDeployment *api.Deployment
+}
+
 func (c *JobStatusCommand) Help() string {
 	helpText := `
 Usage: nomad status [options] <job>
@@ -52,6 +61,14 @@ Status Options:
 
   -verbose
     Display full information.
+
+  -json
+    Output the job, or the list of jobs if no job ID is given, in a JSON
+    format.
+
+  -t
+    Format and display the job, or the list of jobs if no job ID is given,
+    using a Go template.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -61,7 +78,7 @@ func (c *JobStatusCommand) Synopsis() string {
 }
 
 func (c *JobStatusCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
 		complete.Flags{
 			"-all-allocs": complete.PredictNothing,
 			"-evals":      complete.PredictNothing,
@@ -90,7 +107,7 @@ func (c *JobStatusCommand) Name() string { return "status" }
 func (c *JobStatusCommand) Run(args []string) int {
 	var short bool
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&short, "short", false, "")
 	flags.BoolVar(&c.evals, "evals", false, "")
@@ -130,6 +147,10 @@ func (c *JobStatusCommand) Run(args []string) int {
 			return 1
 		}
 
+		if c.formatted() {
+			return c.outputFormatted(jobs)
+		}
+
 		if len(jobs) == 0 {
 			// No output if we have no jobs
 			c.Ui.Output("No running jobs")
@@ -162,6 +183,16 @@ func (c *JobStatusCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.formatted() {
+		status, err := c.jobStatus(client, job)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		return c.outputFormatted(status)
+	}
+
 	periodic := job.IsPeriodic()
 	parameterized := job.IsParameterized()
 
@@ -223,6 +254,38 @@ func (c *JobStatusCommand) Run(args []string) int {
 	return 0
 }
 
+// jobStatus returns the status of the job for the -json and -t flags. If a
+// request fails, an error is returned.
+func (c *JobStatusCommand) jobStatus(client *api.Client, job *api.Job) (*JobStatus, error) {
+	summary, _, err := client.Jobs().Summary(*job.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying job summary: %s", err)
+	}
+
+	allocs, _, err := client.Jobs().Allocations(*job.ID, c.allAllocs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying job allocations: %s", err)
+	}
+
+	evals, _, err := client.Jobs().Evaluations(*job.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying job evaluations: %s", err)
+	}
+
+	deploy, _, err := client.Jobs().LatestDeployment(*job.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying latest job deployment: %s", err)
+	}
+
+	return &JobStatus{
+		Job:              job,
+		Summary:          summary,
+		Allocations:      allocs,
+		Evaluations:      evals,
+		LatestDeployment: deploy,
+	}, nil
+}
+
 // outputPeriodicInfo prints information about the passed periodic job. If a
 // request fails, an error is returned.
 func (c *JobStatusCommand) outputPeriodicInfo(client *api.Client, job *api.Job) error {
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -200,6 +201,54 @@ func TestJobStatusCommand_Run(t *testing.T) {
 	}
 }
 
+func TestJobStatusCommand_JSON(t *testing.T) {
+	t.Parallel()
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &JobStatusCommand{Meta: Meta{Ui: ui}}
+
+	// An empty list is still valid JSON
+	if code := cmd.Run([]string{"-address=" + url, "-json"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	var stubs []*api.JobListStub
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &stubs))
+	require.Empty(t, stubs)
+	ui.OutputWriter.Reset()
+
+	job := testJob("job1_sfx")
+	_, _, err := client.Jobs().Register(job, nil)
+	require.NoError(t, err)
+
+	// List the jobs
+	if code := cmd.Run([]string{"-address=" + url, "-json"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &stubs))
+	require.Len(t, stubs, 1)
+	require.Equal(t, "job1_sfx", stubs[0].ID)
+	ui.OutputWriter.Reset()
+
+	// Query a single job
+	if code := cmd.Run([]string{"-address=" + url, "-json", "job1"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &status))
+	require.Equal(t, "job1_sfx", *status.Job.ID)
+	require.NotNil(t, status.Summary)
+	require.NotEmpty(t, status.Evaluations)
+	ui.OutputWriter.Reset()
+
+	// Query a single job with a template
+	if code := cmd.Run([]string{"-address=" + url, "-t", "{{.Job.ID}}", "job1"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	require.Equal(t, "job1_sfx", strings.TrimSpace(ui.OutputWriter.String()))
+}
+
 func TestJobStatusCommand_Fails(t *testing.T) {
 	t.Parallel()
 	ui := new(cli.MockUi)
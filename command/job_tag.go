@@ -0,0 +1,163 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type JobTagCommand struct {
+	Meta
+}
+
+func (c *JobTagCommand) Help() string {
+	helpText := `
+Usage: nomad job tag [options] <job>
+
+  Tag is used to name a version of a job, such as the last version known to be
+  good. Tagged versions are not garbage collected, can be reverted to by name
+  using the "nomad job revert" command and are shown by the "nomad job history"
+  command. A tag name may only be applied to one version of the job.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Tag Options:
+
+  -name
+    Name of the tag. It can't be a version number.
+
+  -description
+    Description of the tag.
+
+  -version
+    Version of the job to tag. Defaults to the current version.
+
+  -unset
+    Remove the tag with the given name from the version that has it.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobTagCommand) Synopsis() string {
+	return "Tag a version of the job"
+}
+
+func (c *JobTagCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-name":        complete.PredictAnything,
+			"-description": complete.PredictAnything,
+			"-version":     complete.PredictAnything,
+			"-unset":       complete.PredictNothing,
+		})
+}
+
+func (c *JobTagCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Jobs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Jobs]
+	})
+}
+
+func (c *JobTagCommand) Name() string { return "job tag" }
+
+func (c *JobTagCommand) Run(args []string) int {
+	var name, description, versionStr string
+	var unset bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&name, "name", "", "")
+	flags.StringVar(&description, "description", "", "")
+	flags.StringVar(&versionStr, "version", "", "")
+	flags.BoolVar(&unset, "unset", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <job>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if name == "" {
+		c.Ui.Error("The tag name must be specified using the -name flag")
+		return 1
+	}
+	if unset && (description != "" || versionStr != "") {
+		c.Ui.Error("The -description and -version flags can't be used with -unset")
+		return 1
+	}
+
+	version, versionSet, err := parseVersion(versionStr)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse version flag: %v", err))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobID := args[0]
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing jobs: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+	jobID = jobs[0].ID
+
+	if unset {
+		if _, _, err := client.Jobs().UntagVersion(jobID, name, nil); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error removing tag: %s", err))
+			return 1
+		}
+		c.Ui.Output(fmt.Sprintf("Removed tag %q from job %q", name, jobID))
+		return 0
+	}
+
+	// Default to the current version
+	if !versionSet {
+		job, _, err := client.Jobs().Info(jobID, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error retrieving job: %s", err))
+			return 1
+		}
+		version = *job.Version
+	}
+
+	if _, _, err := client.Jobs().TagVersion(jobID, version, name, description, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error tagging job version: %s", err))
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf("Tagged version %d of job %q as %q", version, jobID, name))
+	return 0
+}
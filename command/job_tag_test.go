@@ -0,0 +1,83 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobTagCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &JobTagCommand{}
+}
+
+func TestJobTagCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &JobTagCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails without a tag name
+	if code := cmd.Run([]string{"foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "-name") {
+		t.Fatalf("expected missing name error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	if code := cmd.Run([]string{"-address=nope", "-name=golden", "foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error listing jobs") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+}
+
+func TestJobTagCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, _, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	// Create a job with two versions
+	state := srv.Agent.Server().State()
+	j := mock.Job()
+	require.NoError(state.UpsertJob(1000, j))
+	require.NoError(state.UpsertJob(1001, j.Copy()))
+
+	ui := new(cli.MockUi)
+	cmd := &JobTagCommand{Meta: Meta{Ui: ui}}
+
+	// Tag the current version
+	code := cmd.Run([]string{"-address=" + url, "-name=golden", "-description=known good", j.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "Tagged version 1")
+
+	out, err := state.JobVersionByTag(nil, structs.DefaultNamespace, j.ID, "golden")
+	require.NoError(err)
+	require.NotNil(out)
+	require.EqualValues(1, out.Version)
+	require.Equal("known good", out.VersionTag.Description)
+
+	// Remove the tag
+	code = cmd.Run([]string{"-address=" + url, "-unset", "-name=golden", j.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+
+	out, err = state.JobVersionByTag(nil, structs.DefaultNamespace, j.ID, "golden")
+	require.NoError(err)
+	require.Nil(out)
+}
@@ -7,6 +7,7 @@ import (
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/jobspec"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/posener/complete"
 )
@@ -27,6 +28,13 @@ Alias: nomad validate
   If the supplied path is "-", the jobfile is read from stdin. Otherwise
   it is read from the file at the supplied path or downloaded and
   read from URL specified.
+
+Validate Options:
+
+  -lint
+    Warn about references to node attributes and fields that Nomad doesn't
+    set in the constraints, affinities and spreads of the job, which are
+    likely to be misspelled.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -36,7 +44,9 @@ func (c *JobValidateCommand) Synopsis() string {
 }
 
 func (c *JobValidateCommand) AutocompleteFlags() complete.Flags {
-	return nil
+	return complete.Flags{
+		"-lint": complete.PredictNothing,
+	}
 }
 
 func (c *JobValidateCommand) AutocompleteArgs() complete.Predictor {
@@ -46,8 +56,11 @@ func (c *JobValidateCommand) AutocompleteArgs() complete.Predictor {
 func (c *JobValidateCommand) Name() string { return "job validate" }
 
 func (c *JobValidateCommand) Run(args []string) int {
+	var lint bool
+
 	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&lint, "lint", false, "")
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
@@ -67,6 +80,12 @@ func (c *JobValidateCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Lint the job before it is canonicalized
+	var lintWarnings []string
+	if lint {
+		lintWarnings = jobspec.Lint(job)
+	}
+
 	// Get the HTTP client
 	client, err := c.Meta.Client()
 	if err != nil {
@@ -106,6 +125,10 @@ func (c *JobValidateCommand) Run(args []string) int {
 		c.Ui.Output(
 			c.Colorize().Color(fmt.Sprintf("[bold][yellow]Job Warnings:\n%s[reset]\n", jr.Warnings)))
 	}
+	if len(lintWarnings) != 0 {
+		c.Ui.Output(
+			c.Colorize().Color(fmt.Sprintf("[bold][yellow]Lint Warnings:\n%s[reset]\n", strings.Join(lintWarnings, "\n"))))
+	}
 
 	// Done!
 	c.Ui.Output(
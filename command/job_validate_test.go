@@ -57,6 +57,54 @@ job "job1" {
 	}
 }
 
+func TestValidateCommand_Lint(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &JobValidateCommand{Meta: Meta{Ui: ui}}
+
+	// Create a server
+	s := testutil.NewTestServer(t, nil)
+	defer s.Stop()
+	os.Setenv("NOMAD_ADDR", fmt.Sprintf("http://%s", s.HTTPAddr))
+
+	fh, err := ioutil.TempFile("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(fh.Name())
+	_, err = fh.WriteString(`
+job "job1" {
+	type = "service"
+	datacenters = [ "dc1" ]
+	constraint {
+		attribute = "${attr.cpu.archh}"
+		value = "amd64"
+	}
+	group "group1" {
+		count = 1
+		task "task1" {
+			driver = "exec"
+			config {
+				command = "/bin/sleep"
+			}
+			resources = {
+				cpu = 1000
+				memory = 512
+			}
+		}
+	}
+}`)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if code := cmd.Run([]string{"-lint", fh.Name()}); code != 0 {
+		t.Fatalf("expect exit 0, got: %d: %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, `did you mean "cpu.arch"?`) {
+		t.Fatalf("expected lint warning, got: %s", out)
+	}
+}
+
 func TestValidateCommand_Fails(t *testing.T) {
 	t.Parallel()
 	ui := new(cli.MockUi)
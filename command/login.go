@@ -0,0 +1,205 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type LoginCommand struct {
+	Meta
+}
+
+func (c *LoginCommand) Help() string {
+	helpText := `
+Usage: nomad login [options]
+
+  Login is used to exchange a JWT issued by the identity provider of an ACL
+  auth method for a Nomad ACL token. The token is granted the policies and
+  roles of the auth method's binding rules that match the claims of the JWT.
+  For OIDC auth methods the JWT is the ID token issued by the provider.
+
+  The token is stored so that subsequent commands use it when neither the
+  -token flag nor the NOMAD_TOKEN environment variable is set. By default it
+  is stored in ~/.nomad-token. If NOMAD_TOKEN_HELPER is set, it names a
+  program, such as one backed by the system keyring, that is run with the
+  "store" argument and the token on stdin to store it, and with "get" to print
+  the stored token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Login Options:
+
+  -method=""
+    Specifies the name or type ("jwt" or "oidc") of the ACL auth method to log
+    in with. A type may be given when only one auth method has that type, and
+    the option may be omitted when only one auth method exists.
+
+  -login-token=""
+    Specifies the JWT to exchange for an ACL token. If "-" the JWT is read
+    from stdin. Required.
+
+  -no-store
+    Do not store the ACL token for use by subsequent commands.
+
+  -json
+    Output the ACL token in a JSON format.
+
+  -t
+    Format and display the ACL token using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *LoginCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-method":      complete.PredictAnything,
+			"-login-token": complete.PredictAnything,
+			"-no-store":    complete.PredictNothing,
+			"-json":        complete.PredictNothing,
+			"-t":           complete.PredictAnything,
+		})
+}
+
+func (c *LoginCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *LoginCommand) Synopsis() string {
+	return "Exchange a JWT for an ACL token"
+}
+
+func (c *LoginCommand) Name() string { return "login" }
+
+func (c *LoginCommand) Run(args []string) int {
+	var method, loginToken, tmpl string
+	var json, noStore bool
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&method, "method", "", "")
+	flags.StringVar(&loginToken, "login-token", "", "")
+	flags.BoolVar(&noStore, "no-store", false, "")
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	args = flags.Args()
+	if l := len(args); l != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if loginToken == "" {
+		c.Ui.Error("The -login-token option must be specified")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Read the login token from stdin
+	if loginToken == "-" {
+		raw, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error reading login token: %s", err))
+			return 1
+		}
+		loginToken = strings.TrimSpace(string(raw))
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	method, err = selectAuthMethod(client, method)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Exchange the login token
+	token, _, err := client.ACLTokens().Login(&api.ACLLoginRequest{
+		AuthMethod: method,
+		LoginToken: loginToken,
+	}, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error logging in: %s", err))
+		return 1
+	}
+
+	// Output the token before storing it, so that the token isn't lost if
+	// it can't be stored
+	code := 0
+	formatted := json || len(tmpl) > 0
+	if formatted {
+		out, err := Format(json, tmpl, token)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			code = 1
+		} else {
+			c.Ui.Output(out)
+		}
+	} else {
+		c.Ui.Output(formatKVACLToken(token))
+	}
+
+	// Store the token for subsequent commands
+	if noStore {
+		return code
+	}
+	helper, err := c.Meta.getTokenHelper()
+	if err == nil {
+		err = helper.Store(token.SecretID)
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error storing ACL token: %s", err))
+		return 1
+	}
+	if !formatted {
+		c.Ui.Output(fmt.Sprintf("\nACL token stored with %q", helper.Path()))
+	}
+	return code
+}
+
+// selectAuthMethod returns the name of the auth method to log in with. The
+// method may be given by name or by type, and may be omitted when only one
+// auth method exists.
+func selectAuthMethod(client *api.Client, method string) (string, error) {
+	methods, _, err := client.ACLAuthMethods().List(nil)
+	if err != nil {
+		return "", fmt.Errorf("Error listing auth methods: %s", err)
+	}
+
+	var matches []string
+	for _, m := range methods {
+		if m.Name == method {
+			return m.Name, nil
+		}
+		if method == "" || strings.EqualFold(m.Type, method) {
+			matches = append(matches, m.Name)
+		}
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("Multiple auth methods match, specify one with -method: %s", strings.Join(matches, ", "))
+	case method == "":
+		return "", fmt.Errorf("No auth methods are configured")
+	default:
+		return "", fmt.Errorf("No auth method is named or has the type %q", method)
+	}
+}
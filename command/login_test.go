@@ -0,0 +1,125 @@
+package command
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginCommand(t *testing.T) {
+	require := require.New(t)
+	t.Parallel()
+	config := func(c *agent.Config) {
+		c.ACL.Enabled = true
+	}
+
+	srv, _, url := testServer(t, true, config)
+	state := srv.Agent.Server().State()
+	defer srv.Shutdown()
+
+	// Create an auth method that trusts a static key
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(err)
+
+	method := mock.ACLAuthMethod()
+	method.Config.JWKSURL = ""
+	method.Config.JWTValidationPubKeys = []string{
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}
+	method.SetHash()
+	require.NoError(state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method}))
+
+	rule := mock.ACLBindingRule()
+	rule.AuthMethod = method.Name
+	require.NoError(state.UpsertACLBindingRules(1001, []*structs.ACLBindingRule{rule}))
+
+	// Sign a login token
+	header, _ := json.Marshal(map[string]string{"alg": "RS256"})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"aud":     "nomad",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"project": "web",
+	})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(err)
+	loginToken := signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	dir, err := ioutil.TempDir("", "nomad-login")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	helper := &fileTokenHelper{path: filepath.Join(dir, "token")}
+
+	ui := new(cli.MockUi)
+	cmd := &LoginCommand{Meta: Meta{Ui: ui, flagAddress: url, tokenHelper: helper}}
+
+	// An unknown auth method is rejected
+	code := cmd.Run([]string{"-address=" + url, "-method=oidc", "-login-token=" + loginToken})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "No auth method")
+
+	// An invalid login token is rejected
+	code = cmd.Run([]string{"-address=" + url, "-method=" + method.Name, "-login-token=" + signed + ".c2ln"})
+	require.Equal(1, code)
+
+	// Log in with a valid login token
+	code = cmd.Run([]string{"-address=" + url, "-method=" + method.Name, "-login-token=" + loginToken})
+	require.Equal(0, code)
+
+	out := ui.OutputWriter.String()
+	require.True(strings.Contains(out, "web-web"), out)
+	require.True(strings.Contains(out, method.Name), out)
+
+	// The stored token is the one created by logging in
+	stored, err := helper.Get()
+	require.NoError(err)
+	require.NotEmpty(stored)
+	client, err := cmd.Meta.Client()
+	require.NoError(err)
+	self, _, err := client.ACLTokens().Self(&api.QueryOptions{AuthToken: stored})
+	require.NoError(err)
+	require.Equal(stored, self.SecretID)
+
+	// The auth method may be selected by type or omitted when there is only
+	// one, and storing the token may be skipped
+	require.NoError(os.Remove(helper.path))
+	code = cmd.Run([]string{"-address=" + url, "-method=jwt", "-no-store", "-login-token=" + loginToken})
+	require.Equal(0, code)
+	_, err = os.Stat(helper.path)
+	require.True(os.IsNotExist(err))
+
+	code = cmd.Run([]string{"-address=" + url, "-login-token=" + loginToken})
+	require.Equal(0, code)
+	_, err = os.Stat(helper.path)
+	require.NoError(err)
+
+	// The token is output even when it can't be stored
+	ui = new(cli.MockUi)
+	broken := &fileTokenHelper{path: filepath.Join(dir, "missing", "token")}
+	cmd = &LoginCommand{Meta: Meta{Ui: ui, flagAddress: url, tokenHelper: broken}}
+	code = cmd.Run([]string{"-address=" + url, "-json", "-login-token=" + loginToken})
+	require.Equal(1, code)
+	require.Contains(ui.OutputWriter.String(), "SecretID")
+	require.Contains(ui.ErrorWriter.String(), "Error storing ACL token")
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"bufio"
 	"flag"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -25,9 +26,10 @@ const (
 type FlagSetFlags uint
 
 const (
-	FlagSetNone    FlagSetFlags = 0
-	FlagSetClient  FlagSetFlags = 1 << iota
-	FlagSetDefault              = FlagSetClient
+	FlagSetNone   FlagSetFlags = 0
+	FlagSetClient FlagSetFlags = 1 << iota
+	FlagSetFormat
+	FlagSetDefault = FlagSetClient
 )
 
 // Meta contains the meta-options and functionality that nearly every
@@ -50,11 +52,21 @@ type Meta struct {
 	// token is used for ACLs to access privileged information
 	token string
 
-	caCert     string
-	caPath     string
-	clientCert string
-	clientKey  string
-	insecure   bool
+	// tokenHelper stores the token obtained by the login command. If nil the
+	// default token helper is used.
+	tokenHelper tokenHelper
+
+	caCert        string
+	caPath        string
+	clientCert    string
+	clientKey     string
+	tlsServerName string
+	insecure      bool
+
+	// formatJSON and formatTemplate are set by the -json and -t flags to
+	// format the output of the command.
+	formatJSON     bool
+	formatTemplate string
 }
 
 // FlagSet returns a FlagSet with the common flags that every
@@ -75,12 +87,20 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 		f.StringVar(&m.caPath, "ca-path", "", "")
 		f.StringVar(&m.clientCert, "client-cert", "", "")
 		f.StringVar(&m.clientKey, "client-key", "", "")
+		f.StringVar(&m.tlsServerName, "tls-server-name", "", "")
 		f.BoolVar(&m.insecure, "insecure", false, "")
 		f.BoolVar(&m.insecure, "tls-skip-verify", false, "")
 		f.StringVar(&m.token, "token", "", "")
 
 	}
 
+	// FlagSetFormat is used to enable the flags for formatting the output
+	// of the command as JSON or using a Go template.
+	if fs&FlagSetFormat != 0 {
+		f.BoolVar(&m.formatJSON, "json", false, "")
+		f.StringVar(&m.formatTemplate, "t", "", "")
+	}
+
 	// Create an io.Writer that writes to our UI properly for errors.
 	// This is kind of a hack, but it does the job. Basically: create
 	// a pipe, use a scanner to break it into lines, and output each line
@@ -99,23 +119,32 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 
 // AutocompleteFlags returns a set of flag completions for the given flag set.
 func (m *Meta) AutocompleteFlags(fs FlagSetFlags) complete.Flags {
-	if fs&FlagSetClient == 0 {
-		return nil
+	var flags complete.Flags
+	if fs&FlagSetClient != 0 {
+		flags = complete.Flags{
+			"-address":         complete.PredictAnything,
+			"-region":          complete.PredictAnything,
+			"-namespace":       NamespacePredictor(m.Client, nil),
+			"-no-color":        complete.PredictNothing,
+			"-ca-cert":         complete.PredictFiles("*"),
+			"-ca-path":         complete.PredictDirs("*"),
+			"-client-cert":     complete.PredictFiles("*"),
+			"-client-key":      complete.PredictFiles("*"),
+			"-tls-server-name": complete.PredictAnything,
+			"-insecure":        complete.PredictNothing,
+			"-tls-skip-verify": complete.PredictNothing,
+			"-token":           complete.PredictAnything,
+		}
 	}
 
-	return complete.Flags{
-		"-address":         complete.PredictAnything,
-		"-region":          complete.PredictAnything,
-		"-namespace":       NamespacePredictor(m.Client, nil),
-		"-no-color":        complete.PredictNothing,
-		"-ca-cert":         complete.PredictFiles("*"),
-		"-ca-path":         complete.PredictDirs("*"),
-		"-client-cert":     complete.PredictFiles("*"),
-		"-client-key":      complete.PredictFiles("*"),
-		"-insecure":        complete.PredictNothing,
-		"-tls-skip-verify": complete.PredictNothing,
-		"-token":           complete.PredictAnything,
+	if fs&FlagSetFormat != 0 {
+		flags = mergeAutocompleteFlags(flags, complete.Flags{
+			"-json": complete.PredictNothing,
+			"-t":    complete.PredictAnything,
+		})
 	}
+
+	return flags
 }
 
 // ApiClientFactory is the signature of a API client factory
@@ -136,32 +165,92 @@ func (m *Meta) Client() (*api.Client, error) {
 	}
 
 	// If we need custom TLS configuration, then set it
-	if m.caCert != "" || m.caPath != "" || m.clientCert != "" || m.clientKey != "" || m.insecure {
+	if m.caCert != "" || m.caPath != "" || m.clientCert != "" || m.clientKey != "" || m.tlsServerName != "" || m.insecure {
 		t := &api.TLSConfig{
-			CACert:     m.caCert,
-			CAPath:     m.caPath,
-			ClientCert: m.clientCert,
-			ClientKey:  m.clientKey,
-			Insecure:   m.insecure,
+			CACert:        m.caCert,
+			CAPath:        m.caPath,
+			ClientCert:    m.clientCert,
+			ClientKey:     m.clientKey,
+			TLSServerName: m.tlsServerName,
+			Insecure:      m.insecure,
 		}
 		config.TLSConfig = t
 	}
 
 	if m.token != "" {
 		config.SecretID = m.token
+	} else if config.SecretID == "" {
+		// Fall back to the token stored by the login command. Failing to
+		// read it shouldn't prevent commands that don't need a token.
+		token, err := m.storedToken()
+		if err != nil && m.Ui != nil {
+			m.Ui.Warn(fmt.Sprintf("Warning: %s", err))
+		}
+		config.SecretID = token
 	}
 
 	return api.NewClient(config)
 }
 
+// getTokenHelper returns the helper used to store the ACL token obtained by
+// the login command.
+func (m *Meta) getTokenHelper() (tokenHelper, error) {
+	if m.tokenHelper != nil {
+		return m.tokenHelper, nil
+	}
+	return defaultTokenHelper()
+}
+
+// storedToken returns the ACL token stored by the login command, if any.
+func (m *Meta) storedToken() (string, error) {
+	helper, err := m.getTokenHelper()
+	if err != nil {
+		// Without a home directory there is no stored token
+		return "", nil
+	}
+
+	token, err := helper.Get()
+	if err != nil {
+		return "", fmt.Errorf("failed to read stored ACL token: %v", err)
+	}
+	return token, nil
+}
+
 func (m *Meta) Colorize() *colorstring.Colorize {
 	return &colorstring.Colorize{
 		Colors:  colorstring.DefaultColors,
-		Disable: m.noColor || !terminal.IsTerminal(int(os.Stdout.Fd())),
+		Disable: m.colorDisabled() || !terminal.IsTerminal(int(os.Stdout.Fd())),
 		Reset:   true,
 	}
 }
 
+// colorDisabled returns whether colored output was disabled by the -no-color
+// flag or the NOMAD_CLI_NO_COLOR or NO_COLOR env vars.
+func (m *Meta) colorDisabled() bool {
+	return m.noColor ||
+		os.Getenv(EnvNomadCLINoColor) != "" ||
+		os.Getenv(EnvNoColor) != ""
+}
+
+// formatted returns whether the -json or -t flags were given to format the
+// output of the command.
+func (m *Meta) formatted() bool {
+	return m.formatJSON || len(m.formatTemplate) > 0
+}
+
+// outputFormatted formats the data as requested by the -json or -t flags and
+// outputs it. It returns the exit code of the command.
+func (m *Meta) outputFormatted(data interface{}) int {
+	out, err := Format(m.formatJSON, m.formatTemplate, data)
+	if err != nil {
+		m.Ui.Error(err.Error())
+		return 1
+	}
+
+	m.Ui.Output(out)
+	return 0
+}
+
 // generalOptionsUsage returns the help string for the global options.
 func generalOptionsUsage() string {
 	helpText := `
@@ -181,8 +270,8 @@ func generalOptionsUsage() string {
     Defaults to the "default" namespace.
 
   -no-color
-    Disables colored command output. Alternatively, NOMAD_CLI_NO_COLOR may be
-    set.
+    Disables colored command output. Alternatively, NOMAD_CLI_NO_COLOR or
+    NO_COLOR may be set.
 
   -ca-cert=<path>
     Path to a PEM encoded CA cert file to use to verify the
@@ -205,6 +294,11 @@ func generalOptionsUsage() string {
     client certificate from -client-cert. Overrides the
     NOMAD_CLIENT_KEY environment variable if set.
 
+  -tls-server-name=<value>
+    The server name to use as the SNI host when connecting via TLS, and to
+    verify the certificate of the server. Overrides the NOMAD_TLS_SERVER_NAME
+    environment variable if set.
+
   -tls-skip-verify
     Do not verify TLS certificate. This is highly not recommended. Verification
     will also be skipped if NOMAD_SKIP_VERIFY is set.
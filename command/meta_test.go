@@ -2,9 +2,18 @@ package command
 
 import (
 	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
 )
 
 func TestMeta_FlagSet(t *testing.T) {
@@ -28,11 +37,19 @@ func TestMeta_FlagSet(t *testing.T) {
 				"ca-path",
 				"client-cert",
 				"client-key",
+				"tls-server-name",
 				"insecure",
 				"tls-skip-verify",
 				"token",
 			},
 		},
+		{
+			FlagSetFormat,
+			[]string{
+				"json",
+				"t",
+			},
+		},
 	}
 
 	for i, tc := range cases {
@@ -52,3 +69,101 @@ func TestMeta_FlagSet(t *testing.T) {
 		}
 	}
 }
+
+func TestMeta_ColorDisabled(t *testing.T) {
+	for _, env := range []string{EnvNomadCLINoColor, EnvNoColor} {
+		os.Unsetenv(env)
+	}
+
+	var m Meta
+	if m.colorDisabled() {
+		t.Fatalf("expected color to be enabled")
+	}
+
+	m.noColor = true
+	if !m.colorDisabled() {
+		t.Fatalf("expected -no-color to disable color")
+	}
+	m.noColor = false
+
+	for _, env := range []string{EnvNomadCLINoColor, EnvNoColor} {
+		os.Setenv(env, "1")
+		if !m.colorDisabled() {
+			t.Fatalf("expected %s to disable color", env)
+		}
+		os.Unsetenv(env)
+	}
+}
+
+func TestMeta_Client_StoredToken(t *testing.T) {
+	// Not parallel as the environment is modified
+	var seen string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get("X-Nomad-Token")
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "nomad-meta")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	helper := &fileTokenHelper{path: filepath.Join(dir, "token")}
+	if err := helper.Store("stored"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A helper that fails to read the token
+	broken := &externalTokenHelper{program: filepath.Join(dir, "missing")}
+
+	cases := []struct {
+		Token    string
+		Env      string
+		Helper   tokenHelper
+		Expected string
+		Warning  bool
+	}{
+		{"", "", helper, "stored", false},
+		{"", "env", helper, "env", false},
+		{"flag", "env", helper, "flag", false},
+		{"", "", broken, "", true},
+		{"flag", "", broken, "flag", false},
+	}
+
+	for i, tc := range cases {
+		restore := testSetenv("NOMAD_TOKEN", tc.Env)
+		ui := cli.NewMockUi()
+		m := Meta{Ui: ui, flagAddress: srv.URL, token: tc.Token, tokenHelper: tc.Helper}
+		client, err := m.Client()
+		if err != nil {
+			restore()
+			t.Fatalf("%d: err: %v", i, err)
+		}
+		_, _, err = client.Jobs().List(&api.QueryOptions{})
+		restore()
+		if err != nil {
+			t.Fatalf("%d: err: %v", i, err)
+		}
+		if seen != tc.Expected {
+			t.Fatalf("%d: expected token %q, got %q", i, tc.Expected, seen)
+		}
+		if warned := strings.Contains(ui.ErrorWriter.String(), "failed to read stored ACL token"); warned != tc.Warning {
+			t.Fatalf("%d: expected warning %v, got %q", i, tc.Warning, ui.ErrorWriter.String())
+		}
+	}
+}
+
+// testSetenv sets an environment variable and returns a function restoring
+// its previous value, unsetting it if it wasn't set.
+func testSetenv(key, value string) func() {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 )
 
@@ -29,6 +31,11 @@ Usage: nomad node drain [options] <node>
   that either -enable or -disable is specified, but not both.
   The -self flag is useful to drain the local node.
 
+  The -selector flag toggles draining on every node matching an
+  expression instead, such as:
+
+      nomad node drain -enable -selector 'node.class == "gpu"'
+
 General Options:
 
   ` + generalOptionsUsage() + `
@@ -71,6 +78,22 @@ Node Drain Options:
   -self
     Set the drain status of the local node.
 
+  -selector <expression>
+    Set the drain status of the nodes matching the expression rather than of
+    a single node. The expression compares node fields to quoted strings
+    with == or !=, joined by "and" and "or". The supported fields are
+    node.unique.id, node.unique.name, node.datacenter, node.class,
+    meta.<key> and attr.<key>. When enabling drains, each node is monitored
+    until its drain completes before another node is drained.
+
+  -max-parallel <n>
+    The maximum number of nodes matching -selector to drain at a time.
+    Defaults to 1.
+
+  -overall-deadline <duration>
+    Set the deadline by which the drains of all nodes matching -selector
+    must complete. Node drains are forced as needed to meet it.
+
   -yes
     Automatic yes to prompts.
 `
@@ -84,16 +107,19 @@ func (c *NodeDrainCommand) Synopsis() string {
 func (c *NodeDrainCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-disable":         complete.PredictNothing,
-			"-enable":          complete.PredictNothing,
-			"-deadline":        complete.PredictAnything,
-			"-detach":          complete.PredictNothing,
-			"-force":           complete.PredictNothing,
-			"-no-deadline":     complete.PredictNothing,
-			"-ignore-system":   complete.PredictNothing,
-			"-keep-ineligible": complete.PredictNothing,
-			"-self":            complete.PredictNothing,
-			"-yes":             complete.PredictNothing,
+			"-disable":          complete.PredictNothing,
+			"-enable":           complete.PredictNothing,
+			"-deadline":         complete.PredictAnything,
+			"-detach":           complete.PredictNothing,
+			"-force":            complete.PredictNothing,
+			"-no-deadline":      complete.PredictNothing,
+			"-ignore-system":    complete.PredictNothing,
+			"-keep-ineligible":  complete.PredictNothing,
+			"-self":             complete.PredictNothing,
+			"-selector":         complete.PredictAnything,
+			"-max-parallel":     complete.PredictAnything,
+			"-overall-deadline": complete.PredictAnything,
+			"-yes":              complete.PredictNothing,
 		})
 }
 
@@ -118,7 +144,8 @@ func (c *NodeDrainCommand) Run(args []string) int {
 	var enable, disable, detach, force,
 		noDeadline, ignoreSystem, keepIneligible,
 		self, autoYes, monitor bool
-	var deadline string
+	var deadline, selector, overallDeadline string
+	var maxParallel int
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
@@ -133,6 +160,9 @@ func (c *NodeDrainCommand) Run(args []string) int {
 	flags.BoolVar(&self, "self", false, "")
 	flags.BoolVar(&autoYes, "yes", false, "Automatic yes to prompts.")
 	flags.BoolVar(&monitor, "monitor", false, "Monitor drain status.")
+	flags.StringVar(&selector, "selector", "", "")
+	flags.IntVar(&maxParallel, "max-parallel", 1, "")
+	flags.StringVar(&overallDeadline, "overall-deadline", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -154,12 +184,35 @@ func (c *NodeDrainCommand) Run(args []string) int {
 
 	// Check that we got a node ID
 	args = flags.Args()
-	if l := len(args); self && l != 0 || !self && l != 1 {
+	if selector != "" {
+		if self || len(args) != 0 || monitor {
+			c.Ui.Error("-selector can't be combined with a node ID, -self or -monitor")
+			c.Ui.Error(commandErrorText(c))
+			return 1
+		}
+	} else if l := len(args); self && l != 0 || !self && l != 1 {
 		c.Ui.Error("Node ID must be specified if -self isn't being used")
 		c.Ui.Error(commandErrorText(c))
 		return 1
 	}
 
+	// Validate the flags for draining by selector
+	if selector == "" && (maxParallel != 1 || overallDeadline != "") {
+		c.Ui.Error("-max-parallel and -overall-deadline can only be used with -selector")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	if maxParallel < 1 {
+		c.Ui.Error("-max-parallel must be at least 1")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	if (disable || detach) && (maxParallel != 1 || overallDeadline != "") {
+		c.Ui.Error("-max-parallel and -overall-deadline can't be combined with -disable or -detach")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
 	// Validate a compatible set of flags were set
 	if disable && (deadline != "" || force || noDeadline || ignoreSystem) {
 		c.Ui.Error("-disable can't be combined with flags configuring drain strategy")
@@ -199,6 +252,29 @@ func (c *NodeDrainCommand) Run(args []string) int {
 		d = defaultDrainDuration
 	}
 
+	var overall time.Duration
+	if overallDeadline != "" {
+		dur, err := time.ParseDuration(overallDeadline)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to parse overall deadline %q: %v", overallDeadline, err))
+			return 1
+		}
+		if dur <= 0 {
+			c.Ui.Error("A positive overall deadline must be given")
+			return 1
+		}
+		overall = dur
+	}
+
+	var sel *nodeSelector
+	if selector != "" {
+		var err error
+		if sel, err = parseNodeSelector(selector); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error parsing selector: %v", err))
+			return 1
+		}
+	}
+
 	// Get the HTTP client
 	client, err := c.Meta.Client()
 	if err != nil {
@@ -206,6 +282,18 @@ func (c *NodeDrainCommand) Run(args []string) int {
 		return 1
 	}
 
+	var spec *api.DrainSpec
+	if enable {
+		spec = &api.DrainSpec{
+			Deadline:         d,
+			IgnoreSystemJobs: ignoreSystem,
+		}
+	}
+
+	if sel != nil {
+		return c.drainSelected(client, sel, spec, !keepIneligible, detach, autoYes, maxParallel, overall)
+	}
+
 	// If -self flag is set then determine the current node.
 	var nodeID string
 	if !self {
@@ -255,7 +343,7 @@ func (c *NodeDrainCommand) Run(args []string) int {
 			return 0
 		}
 		c.Ui.Info(fmt.Sprintf("%s: Monitoring node %q: Ctrl-C to detach monitoring", formatTime(time.Now()), node.ID))
-		c.monitorDrain(client, context.Background(), node, meta.LastIndex, ignoreSystem)
+		c.monitorDrain(c.Ui, client, context.Background(), node.ID, meta.LastIndex, ignoreSystem)
 		return 0
 	}
 
@@ -286,14 +374,6 @@ func (c *NodeDrainCommand) Run(args []string) int {
 		}
 	}
 
-	var spec *api.DrainSpec
-	if enable {
-		spec = &api.DrainSpec{
-			Deadline:         d,
-			IgnoreSystemJobs: ignoreSystem,
-		}
-	}
-
 	// Toggle node draining
 	updateMeta, err := client.Nodes().UpdateDrain(node.ID, spec, !keepIneligible, nil)
 	if err != nil {
@@ -313,23 +393,166 @@ func (c *NodeDrainCommand) Run(args []string) int {
 		now := time.Now()
 		c.Ui.Info(fmt.Sprintf("%s: Ctrl-C to stop monitoring: will not cancel the node drain", formatTime(now)))
 		c.Ui.Output(fmt.Sprintf("%s: Node %q drain strategy set", formatTime(now), node.ID))
-		c.monitorDrain(client, context.Background(), node, updateMeta.LastIndex, ignoreSystem)
+		c.monitorDrain(c.Ui, client, context.Background(), node.ID, updateMeta.LastIndex, ignoreSystem)
+	}
+	return 0
+}
+
+// drainSelected toggles draining on the nodes matching the selector. When
+// enabling drains, at most maxParallel nodes are drained at a time and each
+// node is monitored until its drain completes before another is drained.
+func (c *NodeDrainCommand) drainSelected(client *api.Client, sel *nodeSelector, spec *api.DrainSpec,
+	markEligible, detach, autoYes bool, maxParallel int, overall time.Duration) int {
+
+	nodes, err := selectNodes(client, sel)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting nodes: %s", err))
+		return 1
 	}
+	if len(nodes) == 0 {
+		c.Ui.Error("No nodes match the selector")
+		return 1
+	}
+
+	// Confirm the nodes to toggle
+	if !autoYes {
+		verb := "enable"
+		if spec == nil {
+			verb = "disable"
+		}
+		c.Ui.Output(formatNodeStubList(nodes, true))
+		question := fmt.Sprintf("Are you sure you want to %s drain mode for %d nodes? [y/N]", verb, len(nodes))
+		answer, err := c.Ui.Ask(question)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to parse answer: %v", err))
+			return 1
+		}
+		if answer != "y" {
+			c.Ui.Output("Canceling drain toggle")
+			return 0
+		}
+	}
+
+	// Toggle all of the nodes at once when not monitoring drains
+	if spec == nil || detach {
+		failed := false
+		for _, node := range nodes {
+			if _, err := client.Nodes().UpdateDrain(node.ID, spec, markEligible, nil); err != nil {
+				c.Ui.Error(fmt.Sprintf("Error updating drain specification of node %q: %s", node.ID, err))
+				failed = true
+				continue
+			}
+			if spec == nil {
+				c.Ui.Output(fmt.Sprintf("Node %q drain strategy unset", node.ID))
+			} else {
+				c.Ui.Output(fmt.Sprintf("Node %q drain strategy set", node.ID))
+			}
+		}
+		if failed {
+			return 1
+		}
+		return 0
+	}
+
+	var deadline time.Time
+	if overall > 0 {
+		deadline = time.Now().Add(overall)
+	}
+
+	ui := &cli.ConcurrentUi{Ui: c.Ui}
+	ui.Info(fmt.Sprintf("%s: Draining %d nodes, %d at a time: Ctrl-C to stop monitoring: will not cancel the node drains",
+		formatTime(time.Now()), len(nodes), maxParallel))
+
+	nodeCh := make(chan *api.NodeListStub)
+	var wg sync.WaitGroup
+	var failedLock sync.Mutex
+	failed := 0
+	for i := 0; i < maxParallel && i < len(nodes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range nodeCh {
+				nodeSpec := capDrainSpec(spec, deadline)
+				meta, err := client.Nodes().UpdateDrain(node.ID, nodeSpec, markEligible, nil)
+				if err != nil {
+					ui.Error(fmt.Sprintf("%s: Error updating drain specification of node %q: %s", formatTime(time.Now()), node.ID, err))
+					failedLock.Lock()
+					failed++
+					failedLock.Unlock()
+					continue
+				}
+				ui.Output(fmt.Sprintf("%s: Node %q drain strategy set", formatTime(time.Now()), node.ID))
+				c.monitorDrain(ui, client, context.Background(), node.ID, meta.LastIndex, nodeSpec.IgnoreSystemJobs)
+			}
+		}()
+	}
+	for _, node := range nodes {
+		nodeCh <- node
+	}
+	close(nodeCh)
+	wg.Wait()
+
+	if failed > 0 {
+		ui.Error(fmt.Sprintf("%s: Failed to drain %d of %d nodes", formatTime(time.Now()), failed, len(nodes)))
+		return 1
+	}
+	ui.Info(fmt.Sprintf("%s: Drained %d nodes", formatTime(time.Now()), len(nodes)))
 	return 0
 }
 
-func (c *NodeDrainCommand) monitorDrain(client *api.Client, ctx context.Context, node *api.Node, index uint64, ignoreSystem bool) {
-	outCh := client.Nodes().MonitorDrain(ctx, node.ID, index, ignoreSystem)
+// selectNodes returns the nodes matching the selector.
+func selectNodes(client *api.Client, sel *nodeSelector) ([]*api.NodeListStub, error) {
+	stubs, _, err := client.Nodes().List(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*api.NodeListStub
+	for _, stub := range stubs {
+		var node *api.Node
+		if sel.needsNode() {
+			if node, _, err = client.Nodes().Info(stub.ID, nil); err != nil {
+				return nil, err
+			}
+		}
+		if sel.matches(stub, node) {
+			nodes = append(nodes, stub)
+		}
+	}
+	return nodes, nil
+}
+
+// capDrainSpec returns the drain spec to apply to a node so that its drain
+// completes by the overall deadline, if one is set. Drains started after the
+// overall deadline are forced.
+func capDrainSpec(spec *api.DrainSpec, deadline time.Time) *api.DrainSpec {
+	if deadline.IsZero() || spec.Deadline < 0 {
+		return spec
+	}
+
+	capped := *spec
+	remaining := time.Until(deadline)
+	switch {
+	case remaining <= 0:
+		capped.Deadline = -1 * time.Second
+	case spec.Deadline == 0 || spec.Deadline > remaining:
+		capped.Deadline = remaining
+	}
+	return &capped
+}
+
+func (c *NodeDrainCommand) monitorDrain(ui cli.Ui, client *api.Client, ctx context.Context, nodeID string, index uint64, ignoreSystem bool) {
+	outCh := client.Nodes().MonitorDrain(ctx, nodeID, index, ignoreSystem)
 	for msg := range outCh {
 		switch msg.Level {
 		case api.MonitorMsgLevelInfo:
-			c.Ui.Info(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
+			ui.Info(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
 		case api.MonitorMsgLevelWarn:
-			c.Ui.Warn(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
+			ui.Warn(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
 		case api.MonitorMsgLevelError:
-			c.Ui.Error(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
+			ui.Error(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
 		default:
-			c.Ui.Output(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
+			ui.Output(fmt.Sprintf("%s: %s", formatTime(time.Now()), msg))
 		}
 	}
 }
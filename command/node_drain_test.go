@@ -409,6 +409,42 @@ func TestNodeDrainCommand_Fails(t *testing.T) {
 		}
 		ui.ErrorWriter.Reset()
 	}
+
+	// Fail on combining a selector with a node ID
+	if code := cmd.Run([]string{"-address=" + url, "-enable", "-selector", `node.class == "gpu"`, "12345678-abcd-efab-cdef-123456789abc"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "-selector can't be combined") {
+		t.Fatalf("got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fail on setting a concurrency limit without a selector
+	if code := cmd.Run([]string{"-address=" + url, "-enable", "-max-parallel=2", "12345678-abcd-efab-cdef-123456789abc"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "can only be used with -selector") {
+		t.Fatalf("got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fail on an invalid selector
+	if code := cmd.Run([]string{"-address=" + url, "-enable", "-selector", `node.color == "red"`}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "unsupported field") {
+		t.Fatalf("got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fail on a selector matching no nodes
+	if code := cmd.Run([]string{"-address=" + url, "-enable", "-yes", "-selector", `node.class == "gpu"`}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "No nodes match") {
+		t.Fatalf("got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
 }
 
 func TestNodeDrainCommand_AutocompleteArgs(t *testing.T) {
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// nodeSelector is a compiled expression selecting nodes by their attributes.
+// A selector is a set of clauses joined by "and" and "or", where "and" binds
+// more tightly. Each clause compares a node field to a quoted string:
+//
+//	node.class == "gpu"
+//	node.datacenter != "dc1"
+//	meta.rack == "r1" or attr.kernel.name == "linux"
+//
+// The supported fields are node.unique.id, node.unique.name,
+// node.datacenter, node.class, meta.<key> and attr.<key>, as in constraints.
+type nodeSelector struct {
+	// anyOf is a disjunction of conjunctions of clauses
+	anyOf [][]nodeClause
+}
+
+// nodeClause is a single comparison of a node selector.
+type nodeClause struct {
+	field  string
+	equal  bool
+	target string
+}
+
+// needsNode returns whether evaluating the selector requires the full node
+// rather than its list stub.
+func (s *nodeSelector) needsNode() bool {
+	for _, allOf := range s.anyOf {
+		for _, c := range allOf {
+			if strings.HasPrefix(c.field, "meta.") || strings.HasPrefix(c.field, "attr.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches returns whether the node is selected. The full node is only
+// required if needsNode returns true.
+func (s *nodeSelector) matches(stub *api.NodeListStub, node *api.Node) bool {
+	for _, allOf := range s.anyOf {
+		matched := true
+		for _, c := range allOf {
+			if (c.value(stub, node) == c.target) != c.equal {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of the clause's field for the node.
+func (c nodeClause) value(stub *api.NodeListStub, node *api.Node) string {
+	switch {
+	case c.field == "node.unique.id":
+		return stub.ID
+	case c.field == "node.unique.name":
+		return stub.Name
+	case c.field == "node.datacenter":
+		return stub.Datacenter
+	case c.field == "node.class":
+		return stub.NodeClass
+	case strings.HasPrefix(c.field, "meta."):
+		return node.Meta[strings.TrimPrefix(c.field, "meta.")]
+	case strings.HasPrefix(c.field, "attr."):
+		return node.Attributes[strings.TrimPrefix(c.field, "attr.")]
+	}
+	return ""
+}
+
+// validNodeSelectorField returns whether the field can be used in a node
+// selector.
+func validNodeSelectorField(field string) bool {
+	switch field {
+	case "node.unique.id", "node.unique.name", "node.datacenter", "node.class":
+		return true
+	}
+	for _, prefix := range []string{"meta.", "attr."} {
+		if strings.HasPrefix(field, prefix) && len(field) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNodeSelector compiles a node selector expression.
+func parseNodeSelector(selector string) (*nodeSelector, error) {
+	tokens, err := tokenizeNodeSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	s := &nodeSelector{}
+	var allOf []nodeClause
+	for {
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("invalid selector %q: incomplete clause", selector)
+		}
+		field, op, target := tokens[0], tokens[1], tokens[2]
+		if field.quoted || !validNodeSelectorField(field.text) {
+			return nil, fmt.Errorf("invalid selector %q: unsupported field %q", selector, field.text)
+		}
+		if op.quoted || (op.text != "==" && op.text != "!=") {
+			return nil, fmt.Errorf("invalid selector %q: expected == or != after %q", selector, field.text)
+		}
+		if !target.quoted {
+			return nil, fmt.Errorf("invalid selector %q: expected a quoted string after %q", selector, op.text)
+		}
+		allOf = append(allOf, nodeClause{field: field.text, equal: op.text == "==", target: target.text})
+		tokens = tokens[3:]
+
+		if len(tokens) == 0 {
+			break
+		}
+		switch {
+		case !tokens[0].quoted && tokens[0].text == "and":
+		case !tokens[0].quoted && tokens[0].text == "or":
+			s.anyOf = append(s.anyOf, allOf)
+			allOf = nil
+		default:
+			return nil, fmt.Errorf("invalid selector %q: expected \"and\" or \"or\", got %q", selector, tokens[0].text)
+		}
+		tokens = tokens[1:]
+	}
+	s.anyOf = append(s.anyOf, allOf)
+	return s, nil
+}
+
+// selectorToken is a lexical token of a node selector.
+type selectorToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeNodeSelector splits the selector into quoted strings, operators and
+// words.
+func tokenizeNodeSelector(s string) ([]selectorToken, error) {
+	var tokens []selectorToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '"':
+			// Find the closing quote, skipping escaped characters
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("invalid selector %q: unterminated string", s)
+			}
+			text, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q: %v", s, err)
+			}
+			tokens = append(tokens, selectorToken{text: text, quoted: true})
+			i = end + 1
+		case c == '=' || c == '!':
+			if i+1 >= len(s) || s[i+1] != '=' {
+				return nil, fmt.Errorf("invalid selector %q: unexpected %q", s, c)
+			}
+			tokens = append(tokens, selectorToken{text: s[i : i+2]})
+			i += 2
+		default:
+			end := i
+			for end < len(s) && !unicode.IsSpace(rune(s[end])) && !strings.ContainsRune(`"=!`, rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, selectorToken{text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
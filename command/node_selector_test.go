@@ -0,0 +1,88 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeSelector(t *testing.T) {
+	t.Parallel()
+
+	stub := &api.NodeListStub{
+		ID:         "12345678-abcd-efab-cdef-123456789abc",
+		Name:       "client-1",
+		Datacenter: "dc1",
+		NodeClass:  "gpu",
+	}
+	node := &api.Node{
+		Meta:       map[string]string{"rack": "r1"},
+		Attributes: map[string]string{"kernel.name": "linux"},
+	}
+
+	cases := []struct {
+		selector  string
+		matches   bool
+		needsNode bool
+	}{
+		{`node.class == "gpu"`, true, false},
+		{`node.class != "gpu"`, false, false},
+		{`node.datacenter == "dc2" or node.unique.name == "client-1"`, true, false},
+		{`node.class == "gpu" and node.datacenter == "dc2"`, false, false},
+		{`node.datacenter == "dc2" and node.class == "gpu" or meta.rack == "r1"`, true, true},
+		{`attr.kernel.name == "linux" and meta.missing == ""`, true, true},
+		{`node.unique.id == "12345678-abcd-efab-cdef-123456789abc"`, true, false},
+	}
+	for _, c := range cases {
+		sel, err := parseNodeSelector(c.selector)
+		require.NoError(t, err, c.selector)
+		require.Equal(t, c.needsNode, sel.needsNode(), c.selector)
+		require.Equal(t, c.matches, sel.matches(stub, node), c.selector)
+	}
+}
+
+func TestNodeSelector_Invalid(t *testing.T) {
+	t.Parallel()
+
+	cases := []string{
+		``,
+		`node.class`,
+		`node.class = "gpu"`,
+		`node.class == gpu`,
+		`node.color == "red"`,
+		`meta. == "x"`,
+		`"gpu" == node.class`,
+		`node.class == "gpu" and`,
+		`node.class == "gpu" xor node.datacenter == "dc1"`,
+		`node.class == "gpu`,
+	}
+	for _, c := range cases {
+		_, err := parseNodeSelector(c)
+		require.Error(t, err, c)
+	}
+}
+
+func TestNodeDrainCommand_capDrainSpec(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	spec := &api.DrainSpec{Deadline: time.Hour}
+
+	// No overall deadline leaves the spec unchanged
+	require.Equal(spec, capDrainSpec(spec, time.Time{}))
+
+	// Deadlines past the overall deadline are capped
+	capped := capDrainSpec(spec, time.Now().Add(10*time.Minute))
+	require.True(capped.Deadline <= 10*time.Minute && capped.Deadline > 9*time.Minute)
+	require.Equal(time.Hour, spec.Deadline)
+
+	// Drains without a deadline are given one
+	capped = capDrainSpec(&api.DrainSpec{}, time.Now().Add(10*time.Minute))
+	require.True(capped.Deadline > 9*time.Minute)
+
+	// Drains started after the overall deadline are forced
+	capped = capDrainSpec(spec, time.Now().Add(-time.Second))
+	require.Equal(-1*time.Second, capped.Deadline)
+}
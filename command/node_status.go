@@ -31,8 +31,6 @@ type NodeStatusCommand struct {
 	list_allocs bool
 	self        bool
 	stats       bool
-	json        bool
-	tmpl        string
 }
 
 func (c *NodeStatusCommand) Help() string {
@@ -84,14 +82,12 @@ func (c *NodeStatusCommand) Synopsis() string {
 }
 
 func (c *NodeStatusCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
 		complete.Flags{
 			"-allocs":  complete.PredictNothing,
-			"-json":    complete.PredictNothing,
 			"-self":    complete.PredictNothing,
 			"-short":   complete.PredictNothing,
 			"-stats":   complete.PredictNothing,
-			"-t":       complete.PredictAnything,
 			"-verbose": complete.PredictNothing,
 		})
 }
@@ -115,15 +111,13 @@ func (c *NodeStatusCommand) Name() string { return "node-status" }
 
 func (c *NodeStatusCommand) Run(args []string) int {
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&c.short, "short", false, "")
 	flags.BoolVar(&c.verbose, "verbose", false, "")
 	flags.BoolVar(&c.list_allocs, "allocs", false, "")
 	flags.BoolVar(&c.self, "self", false, "")
 	flags.BoolVar(&c.stats, "stats", false, "")
-	flags.BoolVar(&c.json, "json", false, "")
-	flags.StringVar(&c.tmpl, "t", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -161,15 +155,8 @@ func (c *NodeStatusCommand) Run(args []string) int {
 		}
 
 		// If output format is specified, format and output the node data list
-		if c.json || len(c.tmpl) > 0 {
-			out, err := Format(c.json, c.tmpl, nodes)
-			if err != nil {
-				c.Ui.Error(err.Error())
-				return 1
-			}
-
-			c.Ui.Output(out)
-			return 0
+		if c.formatted() {
+			return c.outputFormatted(nodes)
 		}
 
 		// Return nothing if no nodes found
@@ -265,15 +252,8 @@ func (c *NodeStatusCommand) Run(args []string) int {
 	}
 
 	// If output format is specified, format and output the data
-	if c.json || len(c.tmpl) > 0 {
-		out, err := Format(c.json, c.tmpl, node)
-		if err != nil {
-			c.Ui.Error(err.Error())
-			return 1
-		}
-
-		c.Ui.Output(out)
-		return 0
+	if c.formatted() {
+		return c.outputFormatted(node)
 	}
 
 	return c.formatNode(client, node)
@@ -359,6 +339,11 @@ func (c *NodeStatusCommand) formatNode(client *api.Client, node *api.Node) int {
 			c.outputNodeDriverInfo(node)
 		}
 
+		// Emit the host volumes
+		if len(node.HostVolumes) > 0 {
+			c.outputNodeHostVolumes(node)
+		}
+
 		// Emit node events
 		c.outputNodeStatusEvents(node)
 
@@ -465,6 +450,39 @@ func (c *NodeStatusCommand) outputNodeDriverInfo(node *api.Node) {
 	c.Ui.Output(formatList(nodeDrivers))
 }
 
+func (c *NodeStatusCommand) outputNodeHostVolumes(node *api.Node) {
+	c.Ui.Output(c.Colorize().Color("\n[bold]Host Volumes"))
+
+	names := make([]string, 0, len(node.HostVolumes))
+	for name := range node.HostVolumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	volumes := make([]string, 0, len(names)+1)
+	volumes = append(volumes, "Name|Path|Capacity|Access Mode")
+	for _, name := range names {
+		volume := node.HostVolumes[name]
+		capacity := "<none>"
+		if volume.CapacityMB > 0 {
+			capacity = humanize.IBytes(uint64(volume.CapacityMB * bytesPerMegabyte))
+		}
+		volumes = append(volumes, fmt.Sprintf("%s|%s|%s|%s", name, volume.Path, capacity, volume.AccessMode))
+	}
+	c.Ui.Output(formatList(volumes))
+
+	if c.verbose {
+		for _, name := range names {
+			volume := node.HostVolumes[name]
+			if len(volume.Meta) == 0 {
+				continue
+			}
+			c.Ui.Output(c.Colorize().Color(fmt.Sprintf("\n[bold]Host Volume %q Meta[reset]", name)))
+			c.Ui.Output(formatKV(metaKV(volume.Meta)))
+		}
+	}
+}
+
 func (c *NodeStatusCommand) outputNodeStatusEvents(node *api.Node) {
 	c.Ui.Output(c.Colorize().Color("\n[bold]Node Events"))
 	c.outputNodeEvent(node.Events)
@@ -560,8 +578,14 @@ func (c *NodeStatusCommand) formatDeviceAttributes(node *api.Node) {
 
 func (c *NodeStatusCommand) formatMeta(node *api.Node) {
 	// Print the meta
-	keys := make([]string, 0, len(node.Meta))
-	for k := range node.Meta {
+	c.Ui.Output(c.Colorize().Color("\n[bold]Meta[reset]"))
+	c.Ui.Output(formatKV(metaKV(node.Meta)))
+}
+
+// metaKV returns the key value pairs of the meta sorted by key
+func metaKV(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
@@ -569,11 +593,10 @@ func (c *NodeStatusCommand) formatMeta(node *api.Node) {
 	var meta []string
 	for _, k := range keys {
 		if k != "" {
-			meta = append(meta, fmt.Sprintf("%s|%s", k, node.Meta[k]))
+			meta = append(meta, fmt.Sprintf("%s|%s", k, m[k]))
 		}
 	}
-	c.Ui.Output(c.Colorize().Color("\n[bold]Meta[reset]"))
-	c.Ui.Output(formatKV(meta))
+	return meta
 }
 
 func (c *NodeStatusCommand) printCpuStats(hostStats *api.HostStats) {
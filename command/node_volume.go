@@ -0,0 +1,69 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+)
+
+type NodeVolumeCommand struct {
+	Meta
+}
+
+func (f *NodeVolumeCommand) Help() string {
+	helpText := `
+Usage: nomad node volume <subcommand> [options] [args]
+
+  This command groups subcommands for managing the host volumes of nodes. Host
+  volumes are directories on a node that are registered by operators, along
+  with their capacity and access mode, without changing the configuration of
+  the node's agent.
+
+  Register a host volume on a node:
+
+      $ nomad node volume register -path /srv/data -capacity 100GiB <node-id> data
+
+  Deregister a host volume from a node:
+
+      $ nomad node volume deregister <node-id> data
+
+  The host volumes of a node are displayed by the node status command.
+
+  Please see the individual subcommand help for detailed usage information.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (f *NodeVolumeCommand) Synopsis() string {
+	return "Interact with the host volumes of nodes"
+}
+
+func (f *NodeVolumeCommand) Name() string { return "node volume" }
+
+func (f *NodeVolumeCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// lookupNodeID returns the ID of the single node matching the given node ID
+// prefix.
+func lookupNodeID(client *api.Client, nodeID string) (string, error) {
+	if len(nodeID) == 1 {
+		return "", fmt.Errorf("Identifier must contain at least two characters.")
+	}
+
+	nodeID = sanitizeUUIDPrefix(nodeID)
+	nodes, _, err := client.Nodes().PrefixList(nodeID)
+	if err != nil {
+		return "", fmt.Errorf("Error querying node info: %s", err)
+	}
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("No node(s) with prefix or id %q found", nodeID)
+	}
+	if len(nodes) > 1 {
+		return "", fmt.Errorf("Prefix matched multiple nodes\n\n%s", formatNodeStubList(nodes, true))
+	}
+	return nodes[0].ID, nil
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type NodeVolumeDeregisterCommand struct {
+	Meta
+}
+
+func (c *NodeVolumeDeregisterCommand) Help() string {
+	helpText := `
+Usage: nomad node volume deregister [options] <node> <name>
+
+  Deregisters a host volume from a node. The volume's directory is left
+  untouched on the node.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeVolumeDeregisterCommand) Synopsis() string {
+	return "Deregister a host volume from a node"
+}
+
+func (c *NodeVolumeDeregisterCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags(FlagSetClient)
+}
+
+func (c *NodeVolumeDeregisterCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Nodes, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Nodes]
+	})
+}
+
+func (c *NodeVolumeDeregisterCommand) Name() string { return "node-volume-deregister" }
+
+func (c *NodeVolumeDeregisterCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the node ID and volume name
+	args = flags.Args()
+	if len(args) != 2 {
+		c.Ui.Error("This command takes two arguments: <node> <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	nodeID, err := lookupNodeID(client, args[0])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if _, err := client.Nodes().DeregisterHostVolume(nodeID, args[1], nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error deregistering host volume: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Host volume %q deregistered from node %q", args[1], nodeID))
+	return 0
+}
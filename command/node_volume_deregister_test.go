@@ -0,0 +1,85 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeVolumeDeregisterCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &NodeVolumeDeregisterCommand{}
+}
+
+func TestNodeVolumeDeregisterCommand_Fails(t *testing.T) {
+	t.Parallel()
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &NodeVolumeDeregisterCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on non-existent node
+	if code := cmd.Run([]string{"-address=" + url, "12345678-abcd-efab-cdef-123456789abc", "data"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "No node(s) with prefix or id") {
+		t.Fatalf("expected not exist error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+}
+
+func TestNodeVolumeDeregisterCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, client, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	// Wait for a node to appear
+	var nodeID string
+	testutil.WaitForResult(func() (bool, error) {
+		nodes, _, err := client.Nodes().List(nil)
+		if err != nil {
+			return false, err
+		}
+		if len(nodes) == 0 {
+			return false, fmt.Errorf("missing node")
+		}
+		nodeID = nodes[0].ID
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	_, err := client.Nodes().RegisterHostVolume(nodeID, &api.HostVolume{Name: "data", Path: "/srv/data"}, nil)
+	require.NoError(err)
+
+	ui := new(cli.MockUi)
+	cmd := &NodeVolumeDeregisterCommand{Meta: Meta{Ui: ui}}
+
+	require.Equal(0, cmd.Run([]string{"-address=" + url, nodeID, "data"}), ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `Host volume "data" deregistered`)
+
+	node, _, err := client.Nodes().Info(nodeID, nil)
+	require.NoError(err)
+	require.Empty(node.HostVolumes)
+
+	// Fails on an unknown volume
+	ui.ErrorWriter.Reset()
+	require.Equal(1, cmd.Run([]string{"-address=" + url, nodeID, "data"}))
+	require.Contains(ui.ErrorWriter.String(), "not found")
+}
@@ -0,0 +1,165 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/api/contexts"
+	flaghelper "github.com/hashicorp/nomad/helper/flag-helpers"
+	"github.com/posener/complete"
+)
+
+type NodeVolumeRegisterCommand struct {
+	Meta
+}
+
+func (c *NodeVolumeRegisterCommand) Help() string {
+	helpText := `
+Usage: nomad node volume register [options] <node> <name>
+
+  Registers a host volume on a node, or updates the host volume of the same
+  name already registered on the node. The volume's directory must exist on the
+  node.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Register Options:
+
+  -path
+    The absolute path of the volume's directory on the node. Required.
+
+  -capacity
+    The capacity of the volume, such as "500MiB" or "100GiB". If not set the
+    capacity is unknown.
+
+  -access-mode
+    How allocations may access the volume. Must be one of "single-writer",
+    "multi-writer" or "read-only". Defaults to "single-writer".
+
+  -meta <key>=<value>
+    Metadata about the volume. This flag can be specified multiple times.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeVolumeRegisterCommand) Synopsis() string {
+	return "Register a host volume on a node"
+}
+
+func (c *NodeVolumeRegisterCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-path":     complete.PredictAnything,
+			"-capacity": complete.PredictAnything,
+			"-access-mode": complete.PredictSet(
+				api.HostVolumeAccessModeSingleWriter,
+				api.HostVolumeAccessModeMultiWriter,
+				api.HostVolumeAccessModeReadOnly,
+			),
+			"-meta": complete.PredictAnything,
+		})
+}
+
+func (c *NodeVolumeRegisterCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Nodes, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Nodes]
+	})
+}
+
+func (c *NodeVolumeRegisterCommand) Name() string { return "node-volume-register" }
+
+func (c *NodeVolumeRegisterCommand) Run(args []string) int {
+	var path, capacity, accessMode string
+	var meta []string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&path, "path", "", "")
+	flags.StringVar(&capacity, "capacity", "", "")
+	flags.StringVar(&accessMode, "access-mode", "", "")
+	flags.Var((*flaghelper.StringFlag)(&meta), "meta", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the node ID and volume name
+	args = flags.Args()
+	if len(args) != 2 {
+		c.Ui.Error("This command takes two arguments: <node> <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if path == "" {
+		c.Ui.Error("The -path flag must be set")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	volume := &api.HostVolume{
+		Name:       args[1],
+		Path:       path,
+		AccessMode: accessMode,
+	}
+
+	if capacity != "" {
+		bytes, err := humanize.ParseBytes(capacity)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error parsing capacity %q: %v", capacity, err))
+			return 1
+		}
+		volume.CapacityMB = int64(bytes / bytesPerMegabyte)
+		if volume.CapacityMB == 0 {
+			c.Ui.Error(fmt.Sprintf("Capacity %q must be at least 1MiB", capacity))
+			return 1
+		}
+	}
+
+	// Build the meta
+	if len(meta) != 0 {
+		volume.Meta = make(map[string]string, len(meta))
+		for _, m := range meta {
+			split := strings.SplitN(m, "=", 2)
+			if len(split) != 2 {
+				c.Ui.Error(fmt.Sprintf("Error parsing meta value: %v", m))
+				return 1
+			}
+			volume.Meta[split[0]] = split[1]
+		}
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	nodeID, err := lookupNodeID(client, args[0])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if _, err := client.Nodes().RegisterHostVolume(nodeID, volume, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error registering host volume: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Host volume %q registered on node %q", volume.Name, nodeID))
+	return 0
+}
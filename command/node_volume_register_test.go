@@ -0,0 +1,116 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeVolumeRegisterCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &NodeVolumeRegisterCommand{}
+}
+
+func TestNodeVolumeRegisterCommand_Fails(t *testing.T) {
+	t.Parallel()
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &NodeVolumeRegisterCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails without a path
+	if code := cmd.Run([]string{"12345678-abcd-efab-cdef-123456789abc", "data"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "-path flag must be set") {
+		t.Fatalf("expected path error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on an invalid capacity
+	if code := cmd.Run([]string{"-path=/srv/data", "-capacity=lots", "12345678-abcd-efab-cdef-123456789abc", "data"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error parsing capacity") {
+		t.Fatalf("expected capacity error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on non-existent node
+	if code := cmd.Run([]string{"-address=" + url, "-path=/srv/data", "12345678-abcd-efab-cdef-123456789abc", "data"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "No node(s) with prefix or id") {
+		t.Fatalf("expected not exist error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+}
+
+func TestNodeVolumeRegisterCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, client, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	// Wait for a node to appear
+	var nodeID string
+	testutil.WaitForResult(func() (bool, error) {
+		nodes, _, err := client.Nodes().List(nil)
+		if err != nil {
+			return false, err
+		}
+		if len(nodes) == 0 {
+			return false, fmt.Errorf("missing node")
+		}
+		nodeID = nodes[0].ID
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	ui := new(cli.MockUi)
+	cmd := &NodeVolumeRegisterCommand{Meta: Meta{Ui: ui}}
+
+	code := cmd.Run([]string{"-address=" + url, "-path=/srv/data", "-capacity=2GiB",
+		"-access-mode=read-only", "-meta=tier=ssd", nodeID[:8], "data"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `Host volume "data" registered`)
+
+	node, _, err := client.Nodes().Info(nodeID, nil)
+	require.NoError(err)
+	require.Equal(map[string]*api.HostVolume{
+		"data": {
+			Name:        "data",
+			Path:        "/srv/data",
+			CapacityMB:  2048,
+			AccessMode:  api.HostVolumeAccessModeReadOnly,
+			Meta:        map[string]string{"tier": "ssd"},
+			CreateIndex: node.HostVolumes["data"].CreateIndex,
+			ModifyIndex: node.HostVolumes["data"].ModifyIndex,
+		},
+	}, node.HostVolumes)
+
+	// The volume is shown in the node's status
+	ui = new(cli.MockUi)
+	statusCmd := &NodeStatusCommand{Meta: Meta{Ui: ui}}
+	require.Equal(0, statusCmd.Run([]string{"-address=" + url, nodeID}))
+	out := ui.OutputWriter.String()
+	require.Contains(out, "Host Volumes")
+	require.Contains(out, "/srv/data")
+	require.Contains(out, "2.0 GiB")
+}
@@ -27,12 +27,16 @@ Usage: nomad operator autopilot <subcommand> [options]
   This command groups subcommands for interacting with Nomad's Autopilot
   subsystem. Autopilot provides automatic, operator-friendly management of Nomad
   servers. The command can be used to view or modify the current Autopilot
-  configuration. For a full guide see: https://www.nomadproject.io/guides/autopilot.html
+  configuration, or to view the health of the servers. For a full guide see: https://www.nomadproject.io/guides/autopilot.html
 
   Get the current Autopilot configuration:
 
       $ nomad operator autopilot get-config
 
+  Display the health of the servers:
+
+      $ nomad operator autopilot health
+
   Set a new Autopilot configuration, enabling automatic dead server cleanup:
 
       $ nomad operator autopilot set-config -cleanup-dead-servers=true
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type OperatorAutopilotHealthCommand struct {
+	Meta
+}
+
+func (c *OperatorAutopilotHealthCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-json": complete.PredictNothing,
+			"-t":    complete.PredictAnything,
+		})
+}
+
+func (c *OperatorAutopilotHealthCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *OperatorAutopilotHealthCommand) Name() string { return "operator autopilot health" }
+
+func (c *OperatorAutopilotHealthCommand) Run(args []string) int {
+	var json bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet("autopilot", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse args: %v", err))
+		return 1
+	}
+
+	// Check that we got no arguments
+	if len(flags.Args()) != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Set up a client.
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Fetch the health of the servers.
+	health, _, err := client.Operator().AutopilotServerHealth(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying Autopilot health: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, health)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatAutopilotHealth(health))
+	return 0
+}
+
+// formatAutopilotHealth formats the health of the cluster followed by a table
+// of the health of each server.
+func formatAutopilotHealth(health *api.OperatorHealthReply) string {
+	out := formatKV([]string{
+		fmt.Sprintf("Healthy|%v", health.Healthy),
+		fmt.Sprintf("Failure Tolerance|%d", health.FailureTolerance),
+	})
+
+	servers := []string{"ID|Name|Address|Serf Status|Leader|Voter|Healthy|Last Contact|Last Term|Last Index|Stable Since"}
+	for _, s := range health.Servers {
+		servers = append(servers, fmt.Sprintf("%s|%s|%s|%s|%v|%v|%v|%s|%d|%d|%s",
+			s.ID,
+			s.Name,
+			s.Address,
+			s.SerfStatus,
+			s.Leader,
+			s.Voter,
+			s.Healthy,
+			s.LastContact,
+			s.LastTerm,
+			s.LastIndex,
+			formatTime(s.StableSince),
+		))
+	}
+	return out + "\n\n" + formatList(servers)
+}
+
+func (c *OperatorAutopilotHealthCommand) Synopsis() string {
+	return "Display the health of the servers as seen by Autopilot"
+}
+
+func (c *OperatorAutopilotHealthCommand) Help() string {
+	helpText := `
+Usage: nomad operator autopilot health [options]
+
+  Displays the health of the servers as seen by Autopilot on the leader,
+  including whether each server is healthy, its last contact with the leader
+  and the last Raft term and index it has a record of.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Health Options:
+
+  -json
+    Output the health in its JSON format.
+
+  -t
+    Format and display the health using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
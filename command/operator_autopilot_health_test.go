@@ -0,0 +1,44 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/mitchellh/cli"
+)
+
+func TestOperator_Autopilot_Health_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorAutopilotHealthCommand{}
+}
+
+func TestOperatorAutopilotHealthCommand(t *testing.T) {
+	t.Parallel()
+	s, _, addr := testServer(t, false, func(c *agent.Config) {
+		c.Server.RaftProtocol = 3
+	})
+	defer s.Shutdown()
+
+	ui := new(cli.MockUi)
+	c := &OperatorAutopilotHealthCommand{Meta: Meta{Ui: ui}}
+	args := []string{"-address=" + addr}
+
+	code := c.Run(args)
+	if code != 0 {
+		t.Fatalf("bad: %d. %#v", code, ui.ErrorWriter.String())
+	}
+	output := strings.TrimSpace(ui.OutputWriter.String())
+	if !strings.Contains(output, "Failure Tolerance") || !strings.Contains(output, "Last Contact") {
+		t.Fatalf("bad: %s", output)
+	}
+
+	// Fails with arguments
+	ui.ErrorWriter.Reset()
+	if code := c.Run([]string{"-address=" + addr, "foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "takes no arguments") {
+		t.Fatalf("bad: %s", out)
+	}
+}
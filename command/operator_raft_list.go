@@ -29,12 +29,18 @@ List Peers Options:
     The -stale argument defaults to "false" which means the leader provides the
     result. If the cluster is in an outage state without a leader, you may need
     to set -stale to "true" to get the configuration from a non-leader server.
+
+  -json
+    Output the peers in a JSON format.
+
+  -t
+    Format and display the peers using a Go template.
 `
 	return strings.TrimSpace(helpText)
 }
 
 func (c *OperatorRaftListCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
 		complete.Flags{
 			"-stale": complete.PredictAnything,
 		})
@@ -53,7 +59,7 @@ func (c *OperatorRaftListCommand) Name() string { return "operator raft list-pee
 func (c *OperatorRaftListCommand) Run(args []string) int {
 	var stale bool
 
-	flags := c.Meta.FlagSet("raft", FlagSetClient)
+	flags := c.Meta.FlagSet("raft", FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 
 	flags.BoolVar(&stale, "stale", false, "")
@@ -80,6 +86,10 @@ func (c *OperatorRaftListCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.formatted() {
+		return c.outputFormatted(reply.Servers)
+	}
+
 	// Format it as a nice table.
 	result := []string{"Node|ID|Address|State|Voter|RaftProtocol"}
 	for _, s := range reply.Servers {
@@ -29,4 +29,15 @@ func TestOperator_Raft_ListPeers(t *testing.T) {
 	if !strings.Contains(output, "leader") {
 		t.Fatalf("bad: %s", output)
 	}
+	ui.OutputWriter.Reset()
+
+	// Output the leader with a template
+	code = c.Run([]string{"-address=" + addr, "-t", "{{range .}}{{.Leader}}{{end}}"})
+	if code != 0 {
+		t.Fatalf("bad: %d. %#v", code, ui.ErrorWriter.String())
+	}
+	output = strings.TrimSpace(ui.OutputWriter.String())
+	if output != "true" {
+		t.Fatalf("bad: %s", output)
+	}
 }
@@ -25,6 +25,9 @@ Usage: nomad operator raft remove-peer [options]
   server-members" command, it is preferable to clean up by simply running "nomad
   server-force-leave" instead of this command.
 
+  Before removing the peer, the command describes the effect of the removal on
+  the Raft quorum and asks for confirmation.
+
 General Options:
 
   ` + generalOptionsUsage() + `
@@ -36,6 +39,9 @@ Remove Peer Options:
 
   -peer-id="id"
 	Remove a Nomad server with the given ID from the Raft configuration.
+
+  -yes
+	Automatic yes to the prompt confirming the removal.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -45,6 +51,7 @@ func (c *OperatorRaftRemoveCommand) AutocompleteFlags() complete.Flags {
 		complete.Flags{
 			"-peer-address": complete.PredictAnything,
 			"-peer-id":      complete.PredictAnything,
+			"-yes":          complete.PredictNothing,
 		})
 }
 
@@ -61,12 +68,14 @@ func (c *OperatorRaftRemoveCommand) Name() string { return "operator raft remove
 func (c *OperatorRaftRemoveCommand) Run(args []string) int {
 	var peerAddress string
 	var peerID string
+	var autoYes bool
 
 	flags := c.Meta.FlagSet("raft", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 
 	flags.StringVar(&peerAddress, "peer-address", "", "")
 	flags.StringVar(&peerID, "peer-id", "", "")
+	flags.BoolVar(&autoYes, "yes", false, "")
 	if err := flags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to parse args: %v", err))
 		return 1
@@ -80,6 +89,13 @@ func (c *OperatorRaftRemoveCommand) Run(args []string) int {
 	}
 	operator := client.Operator()
 
+	// Confirm the removal
+	if !autoYes && (peerAddress != "") != (peerID != "") {
+		if confirmed, code := c.confirmRemove(operator, peerAddress, peerID); !confirmed {
+			return code
+		}
+	}
+
 	if err := raftRemovePeers(peerAddress, peerID, operator); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error removing peer: %v", err))
 		return 1
@@ -93,6 +109,71 @@ func (c *OperatorRaftRemoveCommand) Run(args []string) int {
 	return 0
 }
 
+// confirmRemove describes the effect of removing the peer on the Raft quorum
+// and asks for confirmation. The removal is refused if the Raft configuration
+// can't be read, so the effect can't be described.
+func (c *OperatorRaftRemoveCommand) confirmRemove(operator *api.Operator, address, id string) (bool, int) {
+	config, err := operator.RaftGetConfiguration(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading Raft configuration: %s", err))
+		return false, 1
+	}
+
+	var peer *api.RaftServer
+	voters := 0
+	for _, s := range config.Servers {
+		if s.Voter {
+			voters++
+		}
+		if (address != "" && s.Address == address) || (id != "" && s.ID == id) {
+			peer = s
+		}
+	}
+
+	var question string
+	switch {
+	case peer == nil && address != "":
+		c.Ui.Warn(fmt.Sprintf("No peer with address %q is in the Raft configuration.", address))
+		question = fmt.Sprintf("Are you sure you want to remove peer with address %q? [y/N]", address)
+	case peer == nil:
+		c.Ui.Warn(fmt.Sprintf("No peer with ID %q is in the Raft configuration.", id))
+		question = fmt.Sprintf("Are you sure you want to remove peer with ID %q? [y/N]", id)
+	default:
+		if peer.Leader {
+			c.Ui.Warn(fmt.Sprintf("Peer %q is the current leader and a new leader will be elected.", peer.Node))
+		}
+		if peer.Voter {
+			remaining := voters - 1
+			tolerance := 0
+			if remaining > 0 {
+				tolerance = (remaining - 1) / 2
+			}
+			c.Ui.Warn(fmt.Sprintf("Removing it leaves %d voters, which can tolerate %d failed servers.", remaining, tolerance))
+		}
+		question = fmt.Sprintf("Are you sure you want to remove peer %q (ID %s, address %s)? [y/N]", peer.Node, peer.ID, peer.Address)
+	}
+
+	answer, err := c.Ui.Ask(question)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse answer: %v", err))
+		return false, 1
+	}
+
+	if answer == "" || strings.ToLower(answer)[0] == 'n' {
+		// No case
+		c.Ui.Output("Cancelling peer removal")
+		return false, 0
+	} else if strings.ToLower(answer)[0] == 'y' && len(answer) > 1 {
+		// Non exact match yes
+		c.Ui.Output("For confirmation, an exact ‘y’ is required.")
+		return false, 0
+	} else if answer != "y" {
+		c.Ui.Output("No confirmation detected. For confirmation, an exact 'y' is required.")
+		return false, 1
+	}
+	return true, 0
+}
+
 func raftRemovePeers(address, id string, operator *api.Operator) error {
 	if len(address) == 0 && len(id) == 0 {
 		return fmt.Errorf("an address or id is required for the peer to remove")
@@ -1,10 +1,12 @@
 package command
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOperator_Raft_RemovePeers_Implements(t *testing.T) {
@@ -47,6 +49,7 @@ func TestOperator_Raft_RemovePeerAddress(t *testing.T) {
 	defer s.Shutdown()
 
 	ui := new(cli.MockUi)
+	ui.InputReader = strings.NewReader("y\n")
 	c := &OperatorRaftRemoveCommand{Meta: Meta{Ui: ui}}
 	args := []string{"-address=" + addr, "-peer-address=nope"}
 
@@ -56,6 +59,7 @@ func TestOperator_Raft_RemovePeerAddress(t *testing.T) {
 	}
 
 	// If we get this error, it proves we sent the address all they through.
+	assert.Contains(ui.ErrorWriter.String(), "No peer with address \"nope\" is in the Raft configuration")
 	assert.Contains(ui.ErrorWriter.String(), "address \"nope\" was not found in the Raft configuration")
 }
 
@@ -66,6 +70,7 @@ func TestOperator_Raft_RemovePeerID(t *testing.T) {
 	defer s.Shutdown()
 
 	ui := new(cli.MockUi)
+	ui.InputReader = strings.NewReader("y\n")
 	c := &OperatorRaftRemoveCommand{Meta: Meta{Ui: ui}}
 	args := []string{"-address=" + addr, "-peer-id=nope"}
 
@@ -75,5 +80,40 @@ func TestOperator_Raft_RemovePeerID(t *testing.T) {
 	}
 
 	// If we get this error, it proves we sent the address all they through.
+	assert.Contains(ui.ErrorWriter.String(), "No peer with ID \"nope\" is in the Raft configuration")
 	assert.Contains(ui.ErrorWriter.String(), "id \"nope\" was not found in the Raft configuration")
 }
+
+func TestOperator_Raft_RemovePeer_Confirm(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s, client, addr := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	config, err := client.Operator().RaftGetConfiguration(nil)
+	require.NoError(err)
+	require.Len(config.Servers, 1)
+	peer := config.Servers[0]
+
+	// Declining the prompt leaves the peer in place
+	ui := new(cli.MockUi)
+	ui.InputReader = strings.NewReader("n\n")
+	c := &OperatorRaftRemoveCommand{Meta: Meta{Ui: ui}}
+	code := c.Run([]string{"-address=" + addr, "-peer-id=" + peer.ID})
+	require.Equal(0, code, ui.ErrorWriter.String())
+
+	require.Contains(ui.ErrorWriter.String(), "is the current leader")
+	require.Contains(ui.ErrorWriter.String(), "leaves 0 voters")
+	require.Contains(ui.OutputWriter.String(), "Cancelling peer removal")
+
+	config, err = client.Operator().RaftGetConfiguration(nil)
+	require.NoError(err)
+	require.Len(config.Servers, 1)
+
+	// The removal is refused when the Raft configuration can't be read
+	ui = new(cli.MockUi)
+	c = &OperatorRaftRemoveCommand{Meta: Meta{Ui: ui}}
+	code = c.Run([]string{"-address=http://127.0.0.1:1", "-peer-id=" + peer.ID})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "Error reading Raft configuration")
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type OperatorSnapshotCommand struct {
+	Meta
+}
+
+func (c *OperatorSnapshotCommand) Help() string {
+	helpText := `
+Usage: nomad operator snapshot <subcommand> [options]
+
+  This command groups subcommands for saving and restoring the state of the
+  Nomad servers for disaster recovery. These are atomic, point-in-time
+  snapshots which include jobs, nodes, allocations, periodic jobs, and ACLs.
+
+  If ACLs are enabled, a management token must be supplied in order to perform
+  snapshot operations.
+
+  Create a snapshot:
+
+      $ nomad operator snapshot save backup.snap
+
+  Restore a snapshot:
+
+      $ nomad operator snapshot restore backup.snap
+
+  Inspect a snapshot:
+
+      $ nomad operator snapshot inspect backup.snap
+
+  Run a snapshot agent saving a snapshot every hour:
+
+      $ nomad operator snapshot agent -interval=1h -local-path=/var/backups/nomad
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSnapshotCommand) Synopsis() string {
+	return "Saves and restores snapshots of Nomad server state"
+}
+
+func (c *OperatorSnapshotCommand) Name() string { return "operator snapshot" }
+
+func (c *OperatorSnapshotCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
@@ -0,0 +1,423 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+const (
+	// snapshotAgentSuffix is the extension of the snapshots saved by the
+	// snapshot agent.
+	snapshotAgentSuffix = ".snap"
+)
+
+type OperatorSnapshotAgentCommand struct {
+	Meta
+}
+
+func (c *OperatorSnapshotAgentCommand) Help() string {
+	helpText := `
+Usage: nomad operator snapshot agent [options]
+
+  Starts a long running process that periodically saves snapshots of the state
+  of the Nomad servers and stores them in a local directory or in an
+  S3-compatible bucket. Older snapshots are removed so that only the most
+  recent ones are retained. The agent runs until it is interrupted.
+
+  If ACLs are enabled, a management token must be supplied in order to perform
+  snapshot operations.
+
+  To save a snapshot every hour into a local directory, keeping the last 24:
+
+      $ nomad operator snapshot agent -interval=1h -retain=24 \
+          -local-path=/var/backups/nomad
+
+  To save snapshots into an S3 bucket:
+
+      $ nomad operator snapshot agent -s3-bucket=backups -s3-region=us-east-1
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Snapshot Agent Options:
+
+  -interval=<duration>
+    How often to save a snapshot. Defaults to "1h".
+
+  -retain=<count>
+    The number of snapshots to keep. Older snapshots with the same prefix are
+    removed after each successful snapshot. Set to 0 to keep all snapshots.
+    Defaults to 30.
+
+  -prefix=<prefix>
+    The prefix of the snapshot file names, followed by the time the snapshot
+    was taken. Defaults to "nomad-snapshot".
+
+  -stale=[true|false]
+    Allow a non-leader server to provide the snapshots. Defaults to "false".
+
+  -local-path=<path>
+    The directory to store the snapshots in.
+
+  -s3-bucket=<bucket>
+    The S3 bucket to store the snapshots in. Credentials are read from the
+    environment, the shared credentials file or the instance metadata.
+
+  -s3-region=<region>
+    The region of the S3 bucket.
+
+  -s3-key-prefix=<prefix>
+    The prefix of the object keys of the snapshots in the S3 bucket.
+
+  -s3-endpoint=<address>
+    The address of an S3-compatible service to use instead of AWS.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSnapshotAgentCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-interval":      complete.PredictAnything,
+			"-retain":        complete.PredictAnything,
+			"-prefix":        complete.PredictAnything,
+			"-stale":         complete.PredictNothing,
+			"-local-path":    complete.PredictDirs("*"),
+			"-s3-bucket":     complete.PredictAnything,
+			"-s3-region":     complete.PredictAnything,
+			"-s3-key-prefix": complete.PredictAnything,
+			"-s3-endpoint":   complete.PredictAnything,
+		})
+}
+
+func (c *OperatorSnapshotAgentCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *OperatorSnapshotAgentCommand) Synopsis() string {
+	return "Periodically saves snapshots of the state of the Nomad servers"
+}
+
+func (c *OperatorSnapshotAgentCommand) Name() string { return "operator snapshot agent" }
+
+func (c *OperatorSnapshotAgentCommand) Run(args []string) int {
+	var interval time.Duration
+	var retain int
+	var prefix, localPath string
+	var s3Bucket, s3Region, s3KeyPrefix, s3Endpoint string
+	var stale bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.DurationVar(&interval, "interval", time.Hour, "")
+	flags.IntVar(&retain, "retain", 30, "")
+	flags.StringVar(&prefix, "prefix", "nomad-snapshot", "")
+	flags.BoolVar(&stale, "stale", false, "")
+	flags.StringVar(&localPath, "local-path", "", "")
+	flags.StringVar(&s3Bucket, "s3-bucket", "", "")
+	flags.StringVar(&s3Region, "s3-region", "", "")
+	flags.StringVar(&s3KeyPrefix, "s3-key-prefix", "", "")
+	flags.StringVar(&s3Endpoint, "s3-endpoint", "", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	if len(flags.Args()) != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if interval <= 0 {
+		c.Ui.Error("Interval must be greater than zero")
+		return 1
+	}
+	if retain < 0 {
+		c.Ui.Error("Retain must not be negative")
+		return 1
+	}
+	if prefix == "" {
+		c.Ui.Error("Prefix must not be empty")
+		return 1
+	}
+
+	// Determine where the snapshots are stored
+	var store snapshotStore
+	switch {
+	case localPath != "" && s3Bucket != "":
+		c.Ui.Error("Only one of -local-path and -s3-bucket may be set")
+		return 1
+	case localPath != "":
+		if err := os.MkdirAll(localPath, 0755); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error creating local path: %s", err))
+			return 1
+		}
+		store = &localSnapshotStore{dir: localPath}
+	case s3Bucket != "":
+		store = newS3SnapshotStore(s3Bucket, s3Region, s3KeyPrefix, s3Endpoint)
+	default:
+		c.Ui.Error("One of -local-path or -s3-bucket must be set")
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	agent := &snapshotAgent{
+		client: client,
+		store:  store,
+		prefix: prefix,
+		retain: retain,
+		stale:  stale,
+	}
+
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalCh)
+
+	c.Ui.Output(fmt.Sprintf("Saving snapshots every %s to %s", interval, store))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		name, index, err := agent.snapshot()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("%s: Error saving snapshot: %s", formatTime(time.Now()), err))
+		} else {
+			c.Ui.Output(fmt.Sprintf("%s: Saved snapshot %q at index %d", formatTime(time.Now()), name, index))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-signalCh:
+			c.Ui.Output("Shutting down snapshot agent")
+			return 0
+		}
+	}
+}
+
+// snapshotAgent saves snapshots into a store and removes the oldest ones
+// beyond the retention count.
+type snapshotAgent struct {
+	client *api.Client
+	store  snapshotStore
+	prefix string
+	retain int
+	stale  bool
+}
+
+// snapshot saves a verified snapshot into the store and applies the retention
+// policy. It returns the name of the saved snapshot and its Raft index.
+func (a *snapshotAgent) snapshot() (string, uint64, error) {
+	tmpDir, err := ioutil.TempDir("", "nomad-snapshot")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The snapshot is verified while saving it locally before it is stored
+	tmp := filepath.Join(tmpDir, "snapshot")
+	meta, err := saveSnapshot(a.client, a.stale, tmp)
+	if err != nil {
+		return "", 0, err
+	}
+
+	f, err := os.Open(tmp)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	name := fmt.Sprintf("%s-%d%s", a.prefix, time.Now().UnixNano(), snapshotAgentSuffix)
+	if err := a.store.Put(name, f); err != nil {
+		return "", 0, fmt.Errorf("failed to store snapshot: %v", err)
+	}
+
+	if err := a.prune(); err != nil {
+		return name, meta.Index, fmt.Errorf("failed to remove old snapshots: %v", err)
+	}
+	return name, meta.Index, nil
+}
+
+// prune removes the oldest snapshots beyond the retention count. Names embed
+// the time the snapshot was taken so sorting them sorts by age.
+func (a *snapshotAgent) prune() error {
+	if a.retain == 0 {
+		return nil
+	}
+
+	names, err := a.store.List()
+	if err != nil {
+		return err
+	}
+
+	var snaps []string
+	for _, name := range names {
+		if strings.HasPrefix(name, a.prefix+"-") && strings.HasSuffix(name, snapshotAgentSuffix) {
+			snaps = append(snaps, name)
+		}
+	}
+	if len(snaps) <= a.retain {
+		return nil
+	}
+
+	sort.Strings(snaps)
+	for _, name := range snaps[:len(snaps)-a.retain] {
+		if err := a.store.Delete(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotStore is where the snapshot agent stores snapshots.
+type snapshotStore interface {
+	// Put stores the snapshot read from r under the given name.
+	Put(name string, r io.ReadSeeker) error
+
+	// List returns the names of the stored snapshots.
+	List() ([]string, error)
+
+	// Delete removes the snapshot with the given name.
+	Delete(name string) error
+
+	fmt.Stringer
+}
+
+// localSnapshotStore stores snapshots in a local directory.
+type localSnapshotStore struct {
+	dir string
+}
+
+func (l *localSnapshotStore) Put(name string, r io.ReadSeeker) error {
+	// Write to a temporary file first so that a partial snapshot is never
+	// seen under its final name
+	dst := filepath.Join(l.dir, name)
+	f, err := os.Create(dst + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), dst)
+}
+
+func (l *localSnapshotStore) List() ([]string, error) {
+	infos, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.Mode().IsRegular() {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+func (l *localSnapshotStore) Delete(name string) error {
+	return os.Remove(filepath.Join(l.dir, name))
+}
+
+func (l *localSnapshotStore) String() string {
+	return fmt.Sprintf("local path %q", l.dir)
+}
+
+// s3SnapshotStore stores snapshots in an S3-compatible bucket.
+type s3SnapshotStore struct {
+	client    *s3.S3
+	bucket    string
+	keyPrefix string
+}
+
+// newS3SnapshotStore returns a store for the given bucket. Credentials are
+// read from the default AWS credential chain.
+func newS3SnapshotStore(bucket, region, keyPrefix, endpoint string) *s3SnapshotStore {
+	conf := &aws.Config{}
+	if region != "" {
+		conf.Region = aws.String(region)
+	}
+	if endpoint != "" {
+		conf.Endpoint = aws.String(endpoint)
+		conf.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	return &s3SnapshotStore{
+		client:    s3.New(session.New(conf)),
+		bucket:    bucket,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *s3SnapshotStore) key(name string) string {
+	return path.Join(s.keyPrefix, name)
+}
+
+func (s *s3SnapshotStore) Put(name string, r io.ReadSeeker) error {
+	_, err := s.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+		Body:   r,
+	})
+	return err
+}
+
+func (s *s3SnapshotStore) List() ([]string, error) {
+	var names []string
+	input := &s3.ListObjectsInput{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.key("")),
+	}
+	err := s.client.ListObjectsPages(input, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			names = append(names, path.Base(aws.StringValue(obj.Key)))
+		}
+		return true
+	})
+	return names, err
+}
+
+func (s *s3SnapshotStore) Delete(name string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	return err
+}
+
+func (s *s3SnapshotStore) String() string {
+	return fmt.Sprintf("S3 bucket %q", s.bucket)
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperator_Snapshot_Agent_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorSnapshotAgentCommand{}
+}
+
+func TestOperator_Snapshot_Agent_Retain(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s, client, _ := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	dir, err := ioutil.TempDir("", "nomad-snapshot")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// Files not matching the prefix are left alone
+	require.NoError(ioutil.WriteFile(dir+"/other.snap", []byte("other"), 0600))
+
+	agent := &snapshotAgent{
+		client: client,
+		store:  &localSnapshotStore{dir: dir},
+		prefix: "test",
+		retain: 2,
+	}
+
+	var saved []string
+	for i := 0; i < 4; i++ {
+		name, index, err := agent.snapshot()
+		require.NoError(err)
+		require.NotZero(index)
+		saved = append(saved, name)
+	}
+
+	// Only the two most recent snapshots are kept
+	names, err := agent.store.List()
+	require.NoError(err)
+	require.ElementsMatch([]string{"other.snap", saved[2], saved[3]}, names)
+}
+
+func TestOperator_Snapshot_Agent_Args(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		args []string
+		err  string
+	}{
+		{nil, "One of -local-path or -s3-bucket must be set"},
+		{[]string{"-local-path=foo", "-s3-bucket=bar"}, "Only one of"},
+		{[]string{"-local-path=foo", "-interval=0s"}, "Interval must be greater than zero"},
+		{[]string{"-local-path=foo", "-retain=-1"}, "Retain must not be negative"},
+		{[]string{"foo"}, "This command takes no arguments"},
+	}
+
+	for _, tc := range cases {
+		ui := new(cli.MockUi)
+		c := &OperatorSnapshotAgentCommand{Meta: Meta{Ui: ui}}
+		code := c.Run(tc.args)
+		if code != 1 {
+			t.Fatalf("%v: expected exit code 1, got: %d", tc.args, code)
+		}
+		if out := ui.ErrorWriter.String(); !strings.Contains(out, tc.err) {
+			t.Fatalf("%v: expected %q, got: %s", tc.args, tc.err, out)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperator_Snapshot_Inspect_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorSnapshotInspectCommand{}
+}
+
+func TestOperator_Snapshot_Inspect(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s, client, _ := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	dir, err := ioutil.TempDir("", "nomad-snapshot")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "backup.snap")
+
+	meta, err := saveSnapshot(client, false, file)
+	require.NoError(err)
+
+	ui := new(cli.MockUi)
+	c := &OperatorSnapshotInspectCommand{Meta: Meta{Ui: ui}}
+	code := c.Run([]string{file})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), meta.ID)
+
+	// Fails on a corrupt snapshot
+	bad := filepath.Join(dir, "bad.snap")
+	require.NoError(ioutil.WriteFile(bad, []byte("not a snapshot"), 0600))
+	ui.ErrorWriter.Reset()
+	code = c.Run([]string{bad})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "Error verifying snapshot")
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type OperatorSnapshotRestoreCommand struct {
+	Meta
+}
+
+func (c *OperatorSnapshotRestoreCommand) Help() string {
+	helpText := `
+Usage: nomad operator snapshot restore [options] <file>
+
+  Restores an atomic, point-in-time snapshot of the state of the Nomad servers
+  which includes jobs, nodes, allocations, periodic jobs, and ACLs.
+
+  Restores involve a potentially dangerous low-level Raft operation that is not
+  designed to handle server failures during a restore. This command is
+  primarily intended to be used when recovering from a disaster, restoring
+  into a fresh cluster of Nomad servers.
+
+  If ACLs are enabled, a management token must be supplied in order to perform
+  snapshot operations.
+
+  To restore a snapshot from the file "backup.snap":
+
+      $ nomad operator snapshot restore backup.snap
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSnapshotRestoreCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags(FlagSetClient)
+}
+
+func (c *OperatorSnapshotRestoreCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *OperatorSnapshotRestoreCommand) Synopsis() string {
+	return "Restores the state of the Nomad servers from a snapshot"
+}
+
+func (c *OperatorSnapshotRestoreCommand) Name() string { return "operator snapshot restore" }
+
+func (c *OperatorSnapshotRestoreCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <file>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	file := args[0]
+
+	f, err := os.Open(file)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening snapshot file: %s", err))
+		return 1
+	}
+	defer f.Close()
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if _, err := client.Operator().SnapshotRestore(f, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error restoring snapshot: %s", err))
+		return 1
+	}
+
+	c.Ui.Output("Restored snapshot")
+	return 0
+}
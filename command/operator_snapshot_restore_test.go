@@ -0,0 +1,55 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperator_Snapshot_Restore_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorSnapshotRestoreCommand{}
+}
+
+func TestOperator_Snapshot_Restore(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s, client, addr := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	// Save a snapshot holding a job
+	job := testJob("snapshot")
+	_, _, err := client.Jobs().Register(job, nil)
+	require.NoError(err)
+
+	dir, err := ioutil.TempDir("", "nomad-snapshot")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "backup.snap")
+	_, err = saveSnapshot(client, false, file)
+	require.NoError(err)
+
+	// Purge the job and restore the snapshot
+	_, _, err = client.Jobs().Deregister("snapshot", true, nil)
+	require.NoError(err)
+
+	ui := new(cli.MockUi)
+	c := &OperatorSnapshotRestoreCommand{Meta: Meta{Ui: ui}}
+	code := c.Run([]string{"-address=" + addr, file})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "Restored snapshot")
+
+	_, _, err = client.Jobs().Info("snapshot", &api.QueryOptions{})
+	require.NoError(err)
+
+	// Fails on a missing file
+	ui.ErrorWriter.Reset()
+	code = c.Run([]string{"-address=" + addr, filepath.Join(dir, "missing.snap")})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "Error opening snapshot file")
+}
@@ -0,0 +1,150 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper/snapshot"
+	"github.com/hashicorp/raft"
+	"github.com/posener/complete"
+)
+
+type OperatorSnapshotSaveCommand struct {
+	Meta
+}
+
+func (c *OperatorSnapshotSaveCommand) Help() string {
+	helpText := `
+Usage: nomad operator snapshot save [options] <file>
+
+  Retrieves an atomic, point-in-time snapshot of the state of the Nomad servers
+  which includes jobs, nodes, allocations, periodic jobs, and ACLs. The snapshot
+  is verified before it is written to the given file.
+
+  If ACLs are enabled, a management token must be supplied in order to perform
+  snapshot operations.
+
+  To create a snapshot from the leader server and save it to "backup.snap":
+
+      $ nomad operator snapshot save backup.snap
+
+  To create a potentially stale snapshot from any available server (useful if
+  no leader is available):
+
+      $ nomad operator snapshot save -stale backup.snap
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Snapshot Save Options:
+
+  -stale=[true|false]
+    The -stale argument defaults to "false" which means the leader provides the
+    snapshot. If the cluster is in an outage state without a leader, you may
+    need to set -stale to "true" to get a snapshot from a non-leader server.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSnapshotSaveCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-stale": complete.PredictNothing,
+		})
+}
+
+func (c *OperatorSnapshotSaveCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *OperatorSnapshotSaveCommand) Synopsis() string {
+	return "Saves a snapshot of the state of the Nomad servers"
+}
+
+func (c *OperatorSnapshotSaveCommand) Name() string { return "operator snapshot save" }
+
+func (c *OperatorSnapshotSaveCommand) Run(args []string) int {
+	var stale bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&stale, "stale", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <file>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	file := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	meta, err := saveSnapshot(client, stale, file)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error saving snapshot: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Saved snapshot to %q at index %d", file, meta.Index))
+	return 0
+}
+
+// saveSnapshot retrieves a snapshot from the servers and writes it to the
+// given path once it has been verified. The snapshot is first written to a
+// temporary file next to the destination so that a partial or corrupt snapshot
+// never replaces a good one.
+func saveSnapshot(client *api.Client, stale bool, path string) (*raft.SnapshotMeta, error) {
+	q := &api.QueryOptions{
+		AllowStale: stale,
+	}
+	snap, err := client.Operator().Snapshot(q)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot file: %v", err)
+	}
+	defer os.Remove(tmp)
+
+	if _, err := io.Copy(f, snap); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write snapshot file: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to sync snapshot file: %v", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to rewind snapshot file: %v", err)
+	}
+
+	meta, err := snapshot.Verify(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify snapshot: %v", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to move snapshot file: %v", err)
+	}
+	return meta, nil
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/snapshot"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperator_Snapshot_Save_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorSnapshotSaveCommand{}
+}
+
+func TestOperator_Snapshot_Save(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s, _, addr := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	dir, err := ioutil.TempDir("", "nomad-snapshot")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "backup.snap")
+
+	ui := new(cli.MockUi)
+	c := &OperatorSnapshotSaveCommand{Meta: Meta{Ui: ui}}
+
+	code := c.Run([]string{"-address=" + addr, file})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "Saved snapshot")
+
+	// The saved file is a valid snapshot and no temp file is left behind
+	f, err := os.Open(file)
+	require.NoError(err)
+	defer f.Close()
+	_, err = snapshot.Verify(f)
+	require.NoError(err)
+	_, err = os.Stat(file + ".tmp")
+	require.True(os.IsNotExist(err))
+
+	// Fails without a file
+	ui.ErrorWriter.Reset()
+	code = c.Run([]string{"-address=" + addr})
+	require.Equal(1, code)
+	require.True(strings.Contains(ui.ErrorWriter.String(), "This command takes one argument"))
+}
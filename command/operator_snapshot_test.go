@@ -0,0 +1,12 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestOperator_Snapshot_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorSnapshotCommand{}
+}
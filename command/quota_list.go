@@ -35,11 +35,7 @@ List Options:
 }
 
 func (c *QuotaListCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
-		complete.Flags{
-			"-json": complete.PredictNothing,
-			"-t":    complete.PredictAnything,
-		})
+	return c.Meta.AutocompleteFlags(FlagSetClient | FlagSetFormat)
 }
 
 func (c *QuotaListCommand) AutocompleteArgs() complete.Predictor {
@@ -52,13 +48,9 @@ func (c *QuotaListCommand) Synopsis() string {
 
 func (c *QuotaListCommand) Name() string { return "quota list" }
 func (c *QuotaListCommand) Run(args []string) int {
-	var json bool
-	var tmpl string
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	flags.BoolVar(&json, "json", false, "")
-	flags.StringVar(&tmpl, "t", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -85,15 +77,8 @@ func (c *QuotaListCommand) Run(args []string) int {
 		return 1
 	}
 
-	if json || len(tmpl) > 0 {
-		out, err := Format(json, tmpl, quotas)
-		if err != nil {
-			c.Ui.Error(err.Error())
-			return 1
-		}
-
-		c.Ui.Output(out)
-		return 0
+	if c.formatted() {
+		return c.outputFormatted(quotas)
 	}
 
 	c.Ui.Output(formatQuotaSpecs(quotas))
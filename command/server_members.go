@@ -33,12 +33,18 @@ Server Members Options:
     Show detailed information about each member. This dumps
     a raw set of tags which shows more information than the
     default output format.
+
+  -json
+    Output the server members in a JSON format.
+
+  -t
+    Format and display the server members using a Go template.
 `
 	return strings.TrimSpace(helpText)
 }
 
 func (c *ServerMembersCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
 		complete.Flags{
 			"-detailed": complete.PredictNothing,
 		})
@@ -57,7 +63,7 @@ func (c *ServerMembersCommand) Name() string { return "server members" }
 func (c *ServerMembersCommand) Run(args []string) int {
 	var detailed bool
 
-	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&detailed, "detailed", false, "Show detailed output")
 
@@ -95,6 +101,10 @@ func (c *ServerMembersCommand) Run(args []string) int {
 	// Sort the members
 	sort.Sort(api.AgentMembersNameSort(srvMembers.Members))
 
+	if c.formatted() {
+		return c.outputFormatted(srvMembers.Members)
+	}
+
 	// Determine the leaders per region.
 	leaders, leaderErr := regionLeaders(client, srvMembers.Members)
 
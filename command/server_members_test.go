@@ -1,10 +1,12 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/command/agent"
 	"github.com/mitchellh/cli"
 )
@@ -44,6 +46,28 @@ func TestServerMembersCommand_Run(t *testing.T) {
 	if out := ui.OutputWriter.String(); !strings.Contains(out, "Tags") {
 		t.Fatalf("expected tags in output, got: %s", out)
 	}
+	ui.OutputWriter.Reset()
+
+	// Query members with JSON output
+	if code := cmd.Run([]string{"-address=" + url, "-json"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	var members []*api.AgentMember
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &members); err != nil {
+		t.Fatalf("expected JSON output, got: %v", err)
+	}
+	if len(members) != 1 || members[0].Name != name {
+		t.Fatalf("expected member %q, got: %#v", name, members)
+	}
+	ui.OutputWriter.Reset()
+
+	// Query members with a template
+	if code := cmd.Run([]string{"-address=" + url, "-t", "{{range .}}{{.Name}}{{end}}"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	if out := ui.OutputWriter.String(); out != name+"\n" {
+		t.Fatalf("expected %q, got: %q", name, out)
+	}
 }
 
 func TestMembersCommand_Fails(t *testing.T) {
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+const (
+	// EnvNomadTokenHelper is the environment variable naming a program used
+	// to store and retrieve the ACL token saved by the login command.
+	EnvNomadTokenHelper = "NOMAD_TOKEN_HELPER"
+
+	// tokenFileName is the name of the file in the home directory the
+	// default token helper stores the token in.
+	tokenFileName = ".nomad-token"
+)
+
+// tokenHelper stores the ACL token obtained by logging in so that it is used
+// by subsequent commands.
+type tokenHelper interface {
+	// Get returns the stored token, or an empty string if there is none.
+	Get() (string, error)
+
+	// Store saves the token, replacing any stored token.
+	Store(token string) error
+
+	// Path describes where the token is stored.
+	Path() string
+}
+
+// defaultTokenHelper returns the program set by NOMAD_TOKEN_HELPER, falling
+// back to storing the token in ~/.nomad-token.
+func defaultTokenHelper() (tokenHelper, error) {
+	if program := os.Getenv(EnvNomadTokenHelper); program != "" {
+		return &externalTokenHelper{program: program}, nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine home directory: %v", err)
+	}
+	return &fileTokenHelper{path: filepath.Join(home, tokenFileName)}, nil
+}
+
+// fileTokenHelper stores the token in a file only readable by the user.
+type fileTokenHelper struct {
+	path string
+}
+
+func (h *fileTokenHelper) Get() (string, error) {
+	raw, err := ioutil.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+func (h *fileTokenHelper) Store(token string) error {
+	// Write to a new temporary file, which is only readable by the user, and
+	// rename it so the token file is never partially written or readable by
+	// others
+	tmp, err := ioutil.TempFile(filepath.Dir(h.path), filepath.Base(h.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.WriteString(token); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.path)
+}
+
+func (h *fileTokenHelper) Path() string {
+	return h.path
+}
+
+// externalTokenHelper delegates storing the token to a program, such as one
+// backed by the system keyring. The program is run with the "get" argument to
+// print the stored token, and with "store" to save the token read from stdin.
+type externalTokenHelper struct {
+	program string
+}
+
+func (h *externalTokenHelper) Get() (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(h.program, "get")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("token helper %q failed: %v: %s", h.program, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (h *externalTokenHelper) Store(token string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(h.program, "store")
+	cmd.Stdin = strings.NewReader(token)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("token helper %q failed: %v: %s", h.program, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (h *externalTokenHelper) Path() string {
+	return h.program
+}
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenHelper_File(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-token-helper")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	h := &fileTokenHelper{path: filepath.Join(dir, "token")}
+	token, err := h.Get()
+	require.NoError(err)
+	require.Empty(token)
+
+	require.NoError(h.Store("secret"))
+	token, err = h.Get()
+	require.NoError(err)
+	require.Equal("secret", token)
+
+	info, err := os.Stat(h.path)
+	require.NoError(err)
+	require.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// Replacing a token file readable by others tightens its permissions
+	require.NoError(os.Chmod(h.path, 0644))
+	require.NoError(h.Store("other"))
+	token, err = h.Get()
+	require.NoError(err)
+	require.Equal("other", token)
+
+	info, err = os.Stat(h.path)
+	require.NoError(err)
+	require.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// No temporary files are left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(err)
+	require.Len(files, 1)
+}
+
+func TestTokenHelper_External(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomad-token-helper")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// A helper that keeps the token in a file next to itself
+	program := filepath.Join(dir, "helper.sh")
+	script := `#!/bin/sh
+case "$1" in
+get) cat "$(dirname "$0")/token" 2>/dev/null || true ;;
+store) cat > "$(dirname "$0")/token" ;;
+*) echo "unknown command $1" >&2; exit 1 ;;
+esac
+`
+	require.NoError(ioutil.WriteFile(program, []byte(script), 0700))
+
+	h := &externalTokenHelper{program: program}
+	token, err := h.Get()
+	require.NoError(err)
+	require.Empty(token)
+
+	require.NoError(h.Store("secret"))
+	token, err = h.Get()
+	require.NoError(err)
+	require.Equal("secret", token)
+
+	// A helper that can't be run is an error
+	h = &externalTokenHelper{program: filepath.Join(dir, "missing")}
+	_, err = h.Get()
+	require.Error(err)
+}
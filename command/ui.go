@@ -3,6 +3,7 @@ package command
 import (
 	"fmt"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/hashicorp/nomad/api/contexts"
@@ -13,6 +14,15 @@ import (
 var (
 	// uiContexts is the contexts the ui can open automatically.
 	uiContexts = []contexts.Context{contexts.Jobs, contexts.Allocs, contexts.Nodes}
+
+	// uiTypes maps the types that may be given to restrict the search to
+	// their contexts.
+	uiTypes = map[string]contexts.Context{
+		"job":        contexts.Jobs,
+		"alloc":      contexts.Allocs,
+		"allocation": contexts.Allocs,
+		"node":       contexts.Nodes,
+	}
 )
 
 type UiCommand struct {
@@ -21,21 +31,33 @@ type UiCommand struct {
 
 func (c *UiCommand) Help() string {
 	helpText := `
-Usage: nomad ui [options] <identifier>
+Usage: nomad ui [options] [<type>] <identifier>
 
 Open the Nomad Web UI in the default browser. An optional identifier may be
 provided, in which case the UI will be opened to view the details for that
-object. Supported identifiers are jobs, allocations and nodes.
+object. Supported identifiers are jobs, allocations and nodes. The identifier
+may be preceded by its type, one of "job", "alloc" or "node", to only search
+objects of that type. The UI is opened in the region and namespace used by the
+command.
 
 General Options:
 
-  ` + generalOptionsUsage()
+  ` + generalOptionsUsage() + `
+
+UI Options:
+
+  -show-url
+    Print the URL instead of opening it in the browser.
+`
 
 	return strings.TrimSpace(helpText)
 }
 
 func (c *UiCommand) AutocompleteFlags() complete.Flags {
-	return c.Meta.AutocompleteFlags(FlagSetClient)
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-show-url": complete.PredictNothing,
+		})
 }
 
 func (c *UiCommand) AutocompleteArgs() complete.Predictor {
@@ -75,21 +97,35 @@ func (c *UiCommand) Synopsis() string {
 func (c *UiCommand) Name() string { return "ui" }
 
 func (c *UiCommand) Run(args []string) int {
+	var showURL bool
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&showURL, "show-url", false, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
 
-	// Check that we got no more than one argument
+	// Check that we got no more than a type and an identifier
 	args = flags.Args()
-	if l := len(args); l > 1 {
-		c.Ui.Error("This command takes no or one optional argument, [<identifier>]")
+	if l := len(args); l > 2 {
+		c.Ui.Error("This command takes up to two optional arguments, [<type>] [<identifier>]")
 		c.Ui.Error(commandErrorText(c))
 		return 1
 	}
 
+	// Restrict the search to the given type of object
+	searchContexts := uiContexts
+	if len(args) == 2 {
+		ctx, ok := uiTypes[args[0]]
+		if !ok {
+			c.Ui.Error(fmt.Sprintf("Unsupported type %q, must be one of job, alloc or node", args[0]))
+			return 1
+		}
+		searchContexts = []contexts.Context{ctx}
+		args = args[1:]
+	}
+
 	// Get the HTTP client
 	client, err := c.Meta.Client()
 	if err != nil {
@@ -97,9 +133,10 @@ func (c *UiCommand) Run(args []string) int {
 		return 1
 	}
 
-	url, err := url.Parse(client.Address())
+	addr := client.ServerAddress()
+	u, err := url.Parse(addr)
 	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Error parsing Nomad address %q: %s", client.Address(), err))
+		c.Ui.Error(fmt.Sprintf("Error parsing Nomad address %q: %s", addr, err))
 		return 1
 	}
 
@@ -108,7 +145,11 @@ func (c *UiCommand) Run(args []string) int {
 		id := args[0]
 
 		// Query for the context associated with the id
-		res, _, err := client.Search().PrefixSearch(id, contexts.All, nil)
+		searchContext := contexts.All
+		if len(searchContexts) == 1 {
+			searchContext = searchContexts[0]
+		}
+		res, _, err := client.Search().PrefixSearch(id, searchContext, nil)
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("Error querying search with id: %q", err))
 			return 1
@@ -122,7 +163,7 @@ func (c *UiCommand) Run(args []string) int {
 		var match contexts.Context
 		var fullID string
 		matchCount := 0
-		for _, ctx := range uiContexts {
+		for _, ctx := range searchContexts {
 			vers, ok := res.Matches[ctx]
 			if !ok {
 				continue
@@ -146,21 +187,39 @@ func (c *UiCommand) Run(args []string) int {
 			}
 		}
 
+		var path string
 		switch match {
 		case contexts.Nodes:
-			url.Path = fmt.Sprintf("ui/nodes/%s", fullID)
+			path = "ui/nodes/"
 		case contexts.Allocs:
-			url.Path = fmt.Sprintf("ui/allocations/%s", fullID)
+			path = "ui/allocations/"
 		case contexts.Jobs:
-			url.Path = fmt.Sprintf("ui/jobs/%s", fullID)
+			path = "ui/jobs/"
 		default:
 			c.Ui.Error(fmt.Sprintf("Unable to resolve ID: %q", id))
 			return 1
 		}
+		u.Path = path + fullID
+		u.RawPath = path + url.PathEscape(fullID)
 	}
 
-	c.Ui.Output(fmt.Sprintf("Opening URL %q", url.String()))
-	if err := open.Start(url.String()); err != nil {
+	// Open the UI in the region and namespace used by the command
+	query := u.Query()
+	if region := c.uiRegion(); region != "" {
+		query.Set("region", region)
+	}
+	if namespace := c.uiNamespace(); namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	u.RawQuery = query.Encode()
+
+	if showURL {
+		c.Ui.Output(u.String())
+		return 0
+	}
+
+	c.Ui.Output(fmt.Sprintf("Opening URL %q", u.String()))
+	if err := open.Start(u.String()); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error opening URL: %s", err))
 		return 1
 	}
@@ -168,6 +227,23 @@ func (c *UiCommand) Run(args []string) int {
 	return 0
 }
 
+// uiRegion returns the region set by the -region flag or NOMAD_REGION.
+func (c *UiCommand) uiRegion() string {
+	if c.Meta.region != "" {
+		return c.Meta.region
+	}
+	return os.Getenv("NOMAD_REGION")
+}
+
+// uiNamespace returns the namespace set by the -namespace flag or
+// NOMAD_NAMESPACE.
+func (c *UiCommand) uiNamespace() string {
+	if c.Meta.namespace != "" {
+		return c.Meta.namespace
+	}
+	return os.Getenv("NOMAD_NAMESPACE")
+}
+
 // logMultiMatchError is used to log an error message when multiple matches are
 // found. The error message logged displays the matched IDs per context.
 func (c *UiCommand) logMultiMatchError(id string, matches map[contexts.Context][]string) {
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUiCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &UiCommand{}
+}
+
+func TestUiCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &UiCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on too many args
+	if code := cmd.Run([]string{"job", "foo", "bar"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, commandErrorText(cmd)) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on an unsupported type
+	if code := cmd.Run([]string{"deployment", "foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Unsupported type") {
+		t.Fatalf("expected unsupported type error, got: %s", out)
+	}
+}
+
+func TestUiCommand_ShowURL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	state := srv.Agent.Server().State()
+	job := mock.Job()
+	job.ID = "example/web"
+	require.NoError(state.UpsertJob(100, job))
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	require.NoError(state.UpsertAllocs(101, []*structs.Allocation{alloc}))
+
+	ui := new(cli.MockUi)
+	cmd := &UiCommand{Meta: Meta{Ui: ui}}
+
+	// The UI homepage
+	code := cmd.Run([]string{"-address=" + url, "-show-url"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Equal(url+"\n", ui.OutputWriter.String())
+	ui.OutputWriter.Reset()
+
+	// A job, escaping its ID and carrying the region and namespace
+	code = cmd.Run([]string{"-address=" + url, "-show-url", "-region=global", "-namespace=default", "job", "example/w"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Equal(url+"/ui/jobs/example%2Fweb?namespace=default&region=global\n", ui.OutputWriter.String())
+	ui.OutputWriter.Reset()
+
+	// An allocation by prefix
+	code = cmd.Run([]string{"-address=" + url, "-show-url", "alloc", alloc.ID[:8]})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Equal(url+"/ui/allocations/"+alloc.ID+"\n", ui.OutputWriter.String())
+	ui.OutputWriter.Reset()
+
+	// The type restricts the search
+	code = cmd.Run([]string{"-address=" + url, "-show-url", "node", alloc.ID[:8]})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "Unable to resolve ID")
+}
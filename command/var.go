@@ -0,0 +1,163 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// hclIdentifier matches the keys that don't need to be quoted in HCL.
+var hclIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-.]*$`)
+
+type VarCommand struct {
+	Meta
+}
+
+func (f *VarCommand) Help() string {
+	helpText := `
+Usage: nomad var <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with variables. Variables
+  are encrypted key/value items stored at a path, which can be read by
+  operators and jobs of the same namespace.
+
+  Create or update a variable:
+
+      $ nomad var put app/db username=admin password=hunter2
+
+  Read a variable:
+
+      $ nomad var get app/db
+
+  List variables:
+
+      $ nomad var list app/
+
+  Delete a variable:
+
+      $ nomad var purge app/db
+
+  Please see the individual subcommand help for detailed usage information.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (f *VarCommand) Synopsis() string {
+	return "Interact with variables"
+}
+
+func (f *VarCommand) Name() string { return "var" }
+
+func (f *VarCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// VariablePathPredictor returns a predictor of the paths of variables.
+func VariablePathPredictor(factory ApiClientFactory) complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := factory()
+		if err != nil {
+			return nil
+		}
+
+		vars, _, err := client.Variables().List(&api.QueryOptions{Prefix: a.Last})
+		if err != nil {
+			return []string{}
+		}
+
+		paths := make([]string, len(vars))
+		for i, v := range vars {
+			paths[i] = v.Path
+		}
+		return paths
+	})
+}
+
+// isCASConflict returns whether the error is from a check-and-set write whose
+// index didn't match.
+func isCASConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "check-and-set conflict")
+}
+
+// formatVariable formats a variable in the given output format, which is
+// either "table", "json" or "hcl".
+func formatVariable(v *api.Variable, format string) (string, error) {
+	switch format {
+	case "table":
+		return formatVariableTable(v), nil
+	case "json":
+		return Format(true, "", v)
+	case "hcl":
+		return formatVariableHCL(v), nil
+	default:
+		return "", fmt.Errorf("Invalid output format %q: must be one of table, json or hcl", format)
+	}
+}
+
+func formatVariableTable(v *api.Variable) string {
+	basic := []string{
+		fmt.Sprintf("Namespace|%s", v.Namespace),
+		fmt.Sprintf("Path|%s", v.Path),
+		fmt.Sprintf("Create Time|%s", formatUnixNanoTime(v.CreateTime)),
+		fmt.Sprintf("Modify Time|%s", formatUnixNanoTime(v.ModifyTime)),
+		fmt.Sprintf("Check Index|%d", v.ModifyIndex),
+	}
+	if v.Lock != nil {
+		basic = append(basic, fmt.Sprintf("Lock TTL|%s", v.Lock.TTL))
+	}
+	out := formatKV(basic)
+
+	keys := sortedItemKeys(v.Items)
+	if len(keys) == 0 {
+		return out
+	}
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		items[i] = fmt.Sprintf("%s|%s", k, v.Items[k])
+	}
+	return out + "\n\n[bold]Items[reset]\n" + formatKV(items)
+}
+
+// formatVariableHCL formats a variable as HCL, aligning the values of the
+// items as hclfmt would.
+func formatVariableHCL(v *api.Variable) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "namespace = %s\n", strconv.Quote(v.Namespace))
+	fmt.Fprintf(&b, "path      = %s\n", strconv.Quote(v.Path))
+
+	keys := sortedItemKeys(v.Items)
+	width := 0
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = k
+		if !hclIdentifier.MatchString(k) {
+			quoted[i] = strconv.Quote(k)
+		}
+		if len(quoted[i]) > width {
+			width = len(quoted[i])
+		}
+	}
+
+	b.WriteString("\nitems {\n")
+	for i, k := range keys {
+		fmt.Fprintf(&b, "  %-*s = %s\n", width, quoted[i], strconv.Quote(v.Items[k]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func sortedItemKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type VarGetCommand struct {
+	Meta
+}
+
+func (c *VarGetCommand) Help() string {
+	helpText := `
+Usage: nomad var get [options] <path>
+
+  Get is used to read the variable at a path, along with its items.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Get Options:
+
+  -item <key>
+    Only output the raw value of the given item.
+
+  -out <format>
+    Format of the output, one of "table", "json" or "hcl". Defaults to
+    "table".
+
+  -t
+    Format and display the variable using a Go template.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarGetCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-item": complete.PredictAnything,
+			"-out":  complete.PredictSet("table", "json", "hcl"),
+			"-t":    complete.PredictAnything,
+		})
+}
+
+func (c *VarGetCommand) AutocompleteArgs() complete.Predictor {
+	return VariablePathPredictor(c.Meta.Client)
+}
+
+func (c *VarGetCommand) Synopsis() string {
+	return "Read a variable"
+}
+
+func (c *VarGetCommand) Name() string { return "var get" }
+
+func (c *VarGetCommand) Run(args []string) int {
+	var item, out, tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&item, "item", "", "")
+	flags.StringVar(&out, "out", "table", "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	path := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	variable, _, err := client.Variables().Read(path, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading variable: %s", err))
+		return 1
+	}
+
+	if item != "" {
+		value, ok := variable.Items[item]
+		if !ok {
+			c.Ui.Error(fmt.Sprintf("Variable %q has no item %q", path, item))
+			return 1
+		}
+		c.Ui.Output(value)
+		return 0
+	}
+
+	if tmpl != "" {
+		formatted, err := Format(false, tmpl, variable)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(formatted)
+		return 0
+	}
+
+	formatted, err := formatVariable(variable, out)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	c.Ui.Output(c.Colorize().Color(formatted))
+	return 0
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarGetCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &VarGetCommand{}
+}
+
+func TestVarGetCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &VarGetCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"some", "bad", "args"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	code = cmd.Run([]string{"-address=nope", "app/db"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error reading variable")
+}
+
+func TestVarGetCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	_, _, err := client.Variables().Upsert(&api.Variable{
+		Path: "app/db",
+		Items: map[string]string{
+			"username":    "admin",
+			"db.password": "hunter2",
+			"tls/ca":      "line1\nline2",
+		},
+	}, nil)
+	require.NoError(err)
+
+	ui := new(cli.MockUi)
+	cmd := &VarGetCommand{Meta: Meta{Ui: ui}}
+
+	// Table output
+	code := cmd.Run([]string{"-address=" + url, "app/db"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	out := ui.OutputWriter.String()
+	require.Contains(out, "app/db")
+	require.Contains(out, "username")
+	require.Contains(out, "hunter2")
+	ui.OutputWriter.Reset()
+
+	// JSON output
+	code = cmd.Run([]string{"-address=" + url, "-out=json", "app/db"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `"username": "admin"`)
+	ui.OutputWriter.Reset()
+
+	// HCL output quotes keys that aren't identifiers
+	code = cmd.Run([]string{"-address=" + url, "-out=hcl", "app/db"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `path      = "app/db"
+`)
+	require.Contains(ui.OutputWriter.String(), `items {
+  db.password = "hunter2"
+  "tls/ca"    = "line1\nline2"
+  username    = "admin"
+}`)
+	ui.OutputWriter.Reset()
+
+	// Template output
+	code = cmd.Run([]string{"-address=" + url, "-t", "{{ .Items.username }}", "app/db"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Equal("admin\n", ui.OutputWriter.String())
+	ui.OutputWriter.Reset()
+
+	// A single item
+	code = cmd.Run([]string{"-address=" + url, "-item=db.password", "app/db"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Equal("hunter2\n", ui.OutputWriter.String())
+	ui.OutputWriter.Reset()
+
+	code = cmd.Run([]string{"-address=" + url, "-item=missing", "app/db"})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), `has no item "missing"`)
+	ui.ErrorWriter.Reset()
+
+	// Invalid formats fail
+	code = cmd.Run([]string{"-address=" + url, "-out=yaml", "app/db"})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "Invalid output format")
+}
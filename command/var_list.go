@@ -0,0 +1,132 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type VarListCommand struct {
+	Meta
+}
+
+func (c *VarListCommand) Help() string {
+	helpText := `
+Usage: nomad var list [options] [<prefix>]
+
+  List is used to list the variables of a namespace, optionally filtered by a
+  path prefix. The items of the variables are not shown.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+List Options:
+
+  -out <format>
+    Format of the output, one of "table" or "json". Defaults to "table".
+
+  -t
+    Format and display the variables using a Go template.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarListCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-out": complete.PredictSet("table", "json"),
+			"-t":   complete.PredictAnything,
+		})
+}
+
+func (c *VarListCommand) AutocompleteArgs() complete.Predictor {
+	return VariablePathPredictor(c.Meta.Client)
+}
+
+func (c *VarListCommand) Synopsis() string {
+	return "List variables"
+}
+
+func (c *VarListCommand) Name() string { return "var list" }
+
+func (c *VarListCommand) Run(args []string) int {
+	var out, tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&out, "out", "table", "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got at most one argument
+	args = flags.Args()
+	if l := len(args); l > 1 {
+		c.Ui.Error("This command takes at most one argument: <prefix>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	if out != "table" && out != "json" {
+		c.Ui.Error(fmt.Sprintf("Invalid output format %q: must be one of table or json", out))
+		return 1
+	}
+
+	var prefix string
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	vars, _, err := client.Variables().List(&api.QueryOptions{Prefix: prefix})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error retrieving variables: %s", err))
+		return 1
+	}
+
+	if out == "json" || tmpl != "" {
+		formatted, err := Format(out == "json", tmpl, vars)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(formatted)
+		return 0
+	}
+
+	c.Ui.Output(formatVariables(vars))
+	return 0
+}
+
+func formatVariables(vars []*api.VariableMetadata) string {
+	if len(vars) == 0 {
+		return "No variables found"
+	}
+
+	// Sort the output by path
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Path < vars[j].Path })
+
+	rows := make([]string, len(vars)+1)
+	rows[0] = "Namespace|Path|Check Index|Last Updated"
+	for i, v := range vars {
+		rows[i+1] = fmt.Sprintf("%s|%s|%d|%s",
+			v.Namespace,
+			v.Path,
+			v.ModifyIndex,
+			formatUnixNanoTime(v.ModifyTime))
+	}
+	return formatList(rows)
+}
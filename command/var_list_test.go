@@ -0,0 +1,81 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarListCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &VarListCommand{}
+}
+
+func TestVarListCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &VarListCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"some", "bad", "args"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"-out=hcl"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Invalid output format")
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	code = cmd.Run([]string{"-address=nope"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error retrieving variables")
+}
+
+func TestVarListCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &VarListCommand{Meta: Meta{Ui: ui}}
+
+	code := cmd.Run([]string{"-address=" + url})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), "No variables found")
+	ui.OutputWriter.Reset()
+
+	for _, path := range []string{"app/db", "app/web", "other"} {
+		_, _, err := client.Variables().Upsert(&api.Variable{
+			Path:  path,
+			Items: map[string]string{"secret": "hunter2"},
+		}, nil)
+		require.NoError(err)
+	}
+
+	// List by prefix
+	code = cmd.Run([]string{"-address=" + url, "app/"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	out := ui.OutputWriter.String()
+	require.Contains(out, "app/db")
+	require.Contains(out, "app/web")
+	require.NotContains(out, "other")
+	require.NotContains(out, "hunter2")
+	ui.OutputWriter.Reset()
+
+	// JSON output
+	code = cmd.Run([]string{"-address=" + url, "-out=json"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `"Path": "other"`)
+	ui.OutputWriter.Reset()
+
+	// Template output
+	code = cmd.Run([]string{"-address=" + url, "-t", "{{ range . }}{{ .Path }} {{ end }}", "app/"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Equal("app/db app/web \n", ui.OutputWriter.String())
+}
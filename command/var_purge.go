@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type VarPurgeCommand struct {
+	Meta
+}
+
+func (c *VarPurgeCommand) Help() string {
+	helpText := `
+Usage: nomad var purge [options] <path>
+
+  Purge is used to permanently delete the variable at a path.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Purge Options:
+
+  -check-index
+    Only delete the variable if its check index, the modify index shown by
+    "nomad var get", matches the given index.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarPurgeCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-check-index": complete.PredictAnything,
+		})
+}
+
+func (c *VarPurgeCommand) AutocompleteArgs() complete.Predictor {
+	return VariablePathPredictor(c.Meta.Client)
+}
+
+func (c *VarPurgeCommand) Synopsis() string {
+	return "Purge a variable"
+}
+
+func (c *VarPurgeCommand) Name() string { return "var purge" }
+
+func (c *VarPurgeCommand) Run(args []string) int {
+	var checkIndexStr string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&checkIndexStr, "check-index", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Parse the check-index
+	checkIndex, cas, err := parseCheckIndex(checkIndexStr)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing check-index value %q: %v", checkIndexStr, err))
+		return 1
+	}
+
+	path := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if cas {
+		_, err = client.Variables().CheckedDelete(path, checkIndex, nil)
+	} else {
+		_, err = client.Variables().Delete(path, nil)
+	}
+	if err != nil {
+		if cas && isCASConflict(err) {
+			c.Ui.Error(fmt.Sprintf("Error purging variable: check index %d does not match the variable %q", checkIndex, path))
+			return 1
+		}
+		c.Ui.Error(fmt.Sprintf("Error purging variable: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully purged variable %q", path))
+	return 0
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarPurgeCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &VarPurgeCommand{}
+}
+
+func TestVarPurgeCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &VarPurgeCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"some", "bad", "args"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	code = cmd.Run([]string{"-address=nope", "app/db"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error purging variable")
+}
+
+func TestVarPurgeCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	meta, _, err := client.Variables().Upsert(&api.Variable{
+		Path:  "app/db",
+		Items: map[string]string{"password": "hunter2"},
+	}, nil)
+	require.NoError(err)
+
+	ui := new(cli.MockUi)
+	cmd := &VarPurgeCommand{Meta: Meta{Ui: ui}}
+
+	// Purging at a stale index conflicts
+	code := cmd.Run([]string{"-address=" + url, fmt.Sprintf("-check-index=%d", meta.ModifyIndex-1), "app/db"})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "does not match")
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"-address=" + url, fmt.Sprintf("-check-index=%d", meta.ModifyIndex), "app/db"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `Successfully purged variable "app/db"`)
+
+	list, _, err := client.Variables().List(nil)
+	require.NoError(err)
+	require.Empty(list)
+}
@@ -0,0 +1,166 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type VarPutCommand struct {
+	Meta
+
+	// testStdin is the input read for items set to "-" in tests.
+	testStdin io.Reader
+}
+
+func (c *VarPutCommand) Help() string {
+	helpText := `
+Usage: nomad var put [options] <path> <key>=<value> [<key>=<value>...]
+
+  Put is used to create or update the variable at a path. The items of the
+  variable are replaced by the given items. A value starting with "@" is read
+  from the file at the following path, and a value of "-" is read from stdin.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Put Options:
+
+  -check-index
+    Only write the variable if its check index, the modify index shown by
+    "nomad var get", matches the given index. An index of 0 only creates the
+    variable if it doesn't already exist. This allows safe concurrent updates
+    of a variable with a read-modify-write cycle.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarPutCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-check-index": complete.PredictAnything,
+		})
+}
+
+func (c *VarPutCommand) AutocompleteArgs() complete.Predictor {
+	return VariablePathPredictor(c.Meta.Client)
+}
+
+func (c *VarPutCommand) Synopsis() string {
+	return "Create or update a variable"
+}
+
+func (c *VarPutCommand) Name() string { return "var put" }
+
+func (c *VarPutCommand) Run(args []string) int {
+	var checkIndexStr string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&checkIndexStr, "check-index", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got a path and items
+	args = flags.Args()
+	if l := len(args); l < 2 {
+		c.Ui.Error("This command takes at least two arguments: <path> <key>=<value>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Parse the check-index
+	checkIndex, cas, err := parseCheckIndex(checkIndexStr)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing check-index value %q: %v", checkIndexStr, err))
+		return 1
+	}
+
+	path := args[0]
+	items, err := c.parseItems(args[1:])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	variable := &api.Variable{
+		Path:  path,
+		Items: items,
+	}
+
+	var meta *api.VariableMetadata
+	if cas {
+		meta, _, err = client.Variables().CheckedUpsert(variable, checkIndex, nil)
+	} else {
+		meta, _, err = client.Variables().Upsert(variable, nil)
+	}
+	if err != nil {
+		if cas && isCASConflict(err) {
+			c.Ui.Error(fmt.Sprintf("Error writing variable: check index %d does not match the variable %q", checkIndex, path))
+			return 1
+		}
+		c.Ui.Error(fmt.Sprintf("Error writing variable: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully wrote variable %q with check index %d", meta.Path, meta.ModifyIndex))
+	return 0
+}
+
+// parseItems parses the key=value arguments into the items of a variable.
+func (c *VarPutCommand) parseItems(args []string) (map[string]string, error) {
+	items := make(map[string]string, len(args))
+	readStdin := false
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid item %q: must be in the format <key>=<value>", arg)
+		}
+		key, value := parts[0], parts[1]
+		if _, ok := items[key]; ok {
+			return nil, fmt.Errorf("Item %q set more than once", key)
+		}
+
+		switch {
+		case value == "-":
+			if readStdin {
+				return nil, fmt.Errorf("Only one item can be read from stdin")
+			}
+			readStdin = true
+
+			var stdin io.Reader = os.Stdin
+			if c.testStdin != nil {
+				stdin = c.testStdin
+			}
+			raw, err := ioutil.ReadAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading item %q from stdin: %v", key, err)
+			}
+			value = string(raw)
+		case strings.HasPrefix(value, "@"):
+			raw, err := ioutil.ReadFile(value[1:])
+			if err != nil {
+				return nil, fmt.Errorf("Error reading item %q: %v", key, err)
+			}
+			value = string(raw)
+		}
+		items[key] = value
+	}
+	return items, nil
+}
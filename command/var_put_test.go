@@ -0,0 +1,95 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarPutCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &VarPutCommand{}
+}
+
+func TestVarPutCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &VarPutCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"app/db"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	// Fails on invalid items
+	code = cmd.Run([]string{"app/db", "password"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "must be in the format <key>=<value>")
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"-check-index=-1", "app/db", "a=b"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error parsing check-index value")
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	code = cmd.Run([]string{"-address=nope", "app/db", "a=b"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error writing variable")
+}
+
+func TestVarPutCommand_Run(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	dir, err := ioutil.TempDir("", "nomad-var")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "cert.pem")
+	require.NoError(ioutil.WriteFile(certFile, []byte("certificate"), 0600))
+
+	ui := new(cli.MockUi)
+	cmd := &VarPutCommand{
+		Meta:      Meta{Ui: ui},
+		testStdin: strings.NewReader("from stdin"),
+	}
+
+	// Create the variable only if it doesn't exist
+	code := cmd.Run([]string{"-address=" + url, "-check-index=0", "app/db",
+		"username=admin", "cert=@" + certFile, "key=-"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+	require.Contains(ui.OutputWriter.String(), `Successfully wrote variable "app/db"`)
+
+	variable, _, err := client.Variables().Read("app/db", nil)
+	require.NoError(err)
+	require.Equal(map[string]string{
+		"username": "admin",
+		"cert":     "certificate",
+		"key":      "from stdin",
+	}, variable.Items)
+
+	// Creating it again conflicts
+	code = cmd.Run([]string{"-address=" + url, "-check-index=0", "app/db", "username=other"})
+	require.Equal(1, code)
+	require.Contains(ui.ErrorWriter.String(), "check index 0 does not match")
+	ui.ErrorWriter.Reset()
+
+	// Updating it at its index replaces the items
+	code = cmd.Run([]string{"-address=" + url, fmt.Sprintf("-check-index=%d", variable.ModifyIndex),
+		"app/db", "username=other"})
+	require.Equal(0, code, ui.ErrorWriter.String())
+
+	variable, _, err = client.Variables().Read("app/db", nil)
+	require.NoError(err)
+	require.Equal(map[string]string{"username": "other"}, variable.Items)
+}
@@ -33,6 +33,10 @@ const (
 const (
 	// Nvidia-container-runtime environment variable names
 	NvidiaVisibleDevices = "NVIDIA_VISIBLE_DEVICES"
+
+	// CudaVisibleDevices is the CUDA runtime environment variable limiting
+	// the devices visible to applications
+	CudaVisibleDevices = "CUDA_VISIBLE_DEVICES"
 )
 
 var (
@@ -67,6 +71,10 @@ var (
 			hclspec.NewAttr("fingerprint_period", "string", false),
 			hclspec.NewLiteral("\"1m\""),
 		),
+		"gpu_slices": hclspec.NewDefault(
+			hclspec.NewAttr("gpu_slices", "number", false),
+			hclspec.NewLiteral("1"),
+		),
 	})
 )
 
@@ -74,6 +82,7 @@ var (
 type Config struct {
 	IgnoredGPUIDs     []string `codec:"ignored_gpu_ids"`
 	FingerprintPeriod string   `codec:"fingerprint_period"`
+	GPUSlices         int      `codec:"gpu_slices"`
 }
 
 // NvidiaDevice contains all plugin specific data
@@ -91,8 +100,17 @@ type NvidiaDevice struct {
 	// fingerprintPeriod is how often we should call nvml to get list of devices
 	fingerprintPeriod time.Duration
 
+	// gpuSlices is the number of devices each GPU or MIG device is exposed
+	// as
+	gpuSlices int
+
 	// devices is the set of detected eligible devices
-	devices    map[string]struct{}
+	devices map[string]struct{}
+
+	// instances maps the IDs of MIG devices and GPU slices to the physical
+	// GPU they run on
+	instances map[string]*gpuInstance
+
 	deviceLock sync.RWMutex
 
 	logger log.Logger
@@ -108,6 +126,7 @@ func NewNvidiaDevice(log log.Logger) *NvidiaDevice {
 	return &NvidiaDevice{
 		logger:        logger,
 		devices:       make(map[string]struct{}),
+		instances:     make(map[string]*gpuInstance),
 		ignoredGPUIDs: make(map[string]struct{}),
 		nvmlClient:    nvmlClient,
 		initErr:       err,
@@ -143,6 +162,11 @@ func (d *NvidiaDevice) SetConfig(cfg *base.Config) error {
 	}
 	d.fingerprintPeriod = period
 
+	if config.GPUSlices < 1 {
+		return fmt.Errorf("gpu_slices must be greater than zero: %d", config.GPUSlices)
+	}
+	d.gpuSlices = config.GPUSlices
+
 	return nil
 }
 
@@ -183,9 +207,22 @@ func (d *NvidiaDevice) Reserve(deviceIDs []string) (*device.ContainerReservation
 	// any of provided deviceIDs is not found in d.devices map
 	d.deviceLock.RLock()
 	var notExistingIDs []string
+	var visibleIDs []string
+	seen := make(map[string]struct{}, len(deviceIDs))
 	for _, id := range deviceIDs {
 		if _, deviceIDExists := d.devices[id]; !deviceIDExists {
 			notExistingIDs = append(notExistingIDs, id)
+			continue
+		}
+
+		// Slices of the same device are exposed to the task once
+		visibleID := id
+		if instance, ok := d.instances[id]; ok {
+			visibleID = instance.VisibleID
+		}
+		if _, ok := seen[visibleID]; !ok {
+			seen[visibleID] = struct{}{}
+			visibleIDs = append(visibleIDs, visibleID)
 		}
 	}
 	d.deviceLock.RUnlock()
@@ -193,9 +230,11 @@ func (d *NvidiaDevice) Reserve(deviceIDs []string) (*device.ContainerReservation
 		return nil, &reservationError{notExistingIDs}
 	}
 
+	visible := strings.Join(visibleIDs, ",")
 	return &device.ContainerReservation{
 		Envs: map[string]string{
-			NvidiaVisibleDevices: strings.Join(deviceIDs, ","),
+			NvidiaVisibleDevices: visible,
+			CudaVisibleDevices:   visible,
 		},
 	}, nil
 }
@@ -74,6 +74,7 @@ func TestReserve(t *testing.T) {
 			ExpectedReservation: &device.ContainerReservation{
 				Envs: map[string]string{
 					NvidiaVisibleDevices: "UUID1,UUID2,UUID3",
+					CudaVisibleDevices:   "UUID1,UUID2,UUID3",
 				},
 			},
 			ExpectedError: nil,
@@ -91,6 +92,34 @@ func TestReserve(t *testing.T) {
 				logger: hclog.NewNullLogger(),
 			},
 		},
+		{
+			Name: "MIG devices and slices",
+			ExpectedReservation: &device.ContainerReservation{
+				Envs: map[string]string{
+					NvidiaVisibleDevices: "MIG-UUID1/7/0,UUID2",
+					CudaVisibleDevices:   "MIG-UUID1/7/0,UUID2",
+				},
+			},
+			ExpectedError: nil,
+			RequestedIDs: []string{
+				"MIG-UUID1/7/0",
+				"UUID2:0",
+				"UUID2:1",
+			},
+			Device: &NvidiaDevice{
+				devices: map[string]struct{}{
+					"MIG-UUID1/7/0": {},
+					"UUID2:0":       {},
+					"UUID2:1":       {},
+				},
+				instances: map[string]*gpuInstance{
+					"MIG-UUID1/7/0": {GPUUUID: "UUID1", VisibleID: "MIG-UUID1/7/0"},
+					"UUID2:0":       {GPUUUID: "UUID2", VisibleID: "UUID2"},
+					"UUID2:1":       {GPUUUID: "UUID2", VisibleID: "UUID2"},
+				},
+				logger: hclog.NewNullLogger(),
+			},
+		},
 		{
 			Name:                "No IDs requested",
 			ExpectedReservation: &device.ContainerReservation{},
@@ -2,6 +2,7 @@ package nvidia
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/nomad/devices/gpu/nvidia/nvml"
@@ -21,8 +22,24 @@ const (
 	PCIBandwidthAttr    = "pci_bandwidth"
 	DisplayStateAttr    = "display_state"
 	PersistenceModeAttr = "persistence_mode"
+	MIGProfileAttr      = "mig_profile"
+	SlicesAttr          = "slices"
 )
 
+// gpuInstance describes a device that is not a whole physical GPU, either a
+// MIG device or a slice of a GPU or MIG device
+type gpuInstance struct {
+	// GPUUUID is the UUID of the physical GPU the instance runs on
+	GPUUUID string
+
+	// VisibleID is the ID made visible to tasks the instance is reserved
+	// for
+	VisibleID string
+
+	// Name is the device name the instance's stats are grouped by
+	Name *string
+}
+
 // fingerprint is the long running goroutine that detects hardware
 func (d *NvidiaDevice) fingerprint(ctx context.Context, devices chan<- *device.FingerprintResponse) {
 	defer close(devices)
@@ -63,6 +80,12 @@ func (d *NvidiaDevice) writeFingerprintToChannel(devices chan<- *device.Fingerpr
 
 	// ignore devices from fingerprint output
 	fingerprintDevices := ignoreFingerprintedDevices(fingerprintData.Devices, d.ignoredGPUIDs)
+	// replace GPUs with their MIG devices and slices
+	fingerprintDevices, instances := expandFingerprintedDevices(fingerprintDevices, d.ignoredGPUIDs, d.gpuSlices)
+	d.deviceLock.Lock()
+	d.instances = instances
+	d.deviceLock.Unlock()
+
 	// check if any device health was updated or any device was added to host
 	if !d.fingerprintChanged(fingerprintDevices) {
 		return
@@ -73,6 +96,11 @@ func (d *NvidiaDevice) writeFingerprintToChannel(devices chan<- *device.Fingerpr
 			String: helper.StringToPtr(fingerprintData.DriverVersion),
 		},
 	}
+	if d.gpuSlices > 1 {
+		commonAttributes[SlicesAttr] = &structs.Attribute{
+			Int: helper.Int64ToPtr(int64(d.gpuSlices)),
+		}
+	}
 
 	// Group all FingerprintDevices by DeviceName attribute
 	deviceListByDeviceName := make(map[string][]*nvml.FingerprintDeviceData)
@@ -107,6 +135,73 @@ func ignoreFingerprintedDevices(deviceData []*nvml.FingerprintDeviceData, ignore
 	return result
 }
 
+// expandFingerprintedDevices replaces GPUs that are partitioned into MIG
+// devices with their non-ignored MIG devices and, if slices is greater than
+// one, replaces every device with slices devices that each have an equal share
+// of its memory. The returned map describes every device that is not a whole
+// GPU.
+func expandFingerprintedDevices(deviceData []*nvml.FingerprintDeviceData, ignoredGPUIDs map[string]struct{},
+	slices int) ([]*nvml.FingerprintDeviceData, map[string]*gpuInstance) {
+
+	instances := make(map[string]*gpuInstance)
+	var units []*nvml.FingerprintDeviceData
+	for _, gpu := range deviceData {
+		if len(gpu.MIGDevices) == 0 {
+			units = append(units, gpu)
+			continue
+		}
+
+		for _, mig := range gpu.MIGDevices {
+			if _, ignored := ignoredGPUIDs[mig.UUID]; ignored {
+				continue
+			}
+			units = append(units, mig)
+			instances[mig.UUID] = &gpuInstance{
+				GPUUUID:   gpu.UUID,
+				VisibleID: mig.UUID,
+				Name:      mig.DeviceName,
+			}
+		}
+	}
+
+	if slices <= 1 {
+		return units, instances
+	}
+
+	result := make([]*nvml.FingerprintDeviceData, 0, len(units)*slices)
+	for _, unit := range units {
+		gpuUUID := unit.UUID
+		if instance, ok := instances[unit.UUID]; ok {
+			gpuUUID = instance.GPUUUID
+			delete(instances, unit.UUID)
+		}
+
+		var memory *uint64
+		if unit.MemoryMiB != nil {
+			memory = helper.Uint64ToPtr(*unit.MemoryMiB / uint64(slices))
+		}
+
+		for i := 0; i < slices; i++ {
+			slice := *unit
+			slice.DeviceData = &nvml.DeviceData{
+				UUID:       fmt.Sprintf("%s:%d", unit.UUID, i),
+				DeviceName: unit.DeviceName,
+				MemoryMiB:  memory,
+				PowerW:     unit.PowerW,
+				BAR1MiB:    unit.BAR1MiB,
+			}
+			result = append(result, &slice)
+			instances[slice.UUID] = &gpuInstance{
+				GPUUUID:   gpuUUID,
+				VisibleID: unit.UUID,
+				Name:      unit.DeviceName,
+			}
+		}
+	}
+
+	return result, instances
+}
+
 // fingerprintChanged checks if there are any previously unseen nvidia devices located
 // or any of fingerprinted nvidia devices disappeared since the last fingerprint run.
 // Also, this func updates device map on NvidiaDevice with the latest data
@@ -188,6 +283,11 @@ func attributesFromFingerprintDeviceData(d *nvml.FingerprintDeviceData) map[stri
 		},
 	}
 
+	if d.MIGProfile != "" {
+		attrs[MIGProfileAttr] = &structs.Attribute{
+			String: helper.StringToPtr(d.MIGProfile),
+		}
+	}
 	if d.MemoryMiB != nil {
 		attrs[MemoryAttr] = &structs.Attribute{
 			Int:  helper.Int64ToPtr(int64(*d.MemoryMiB)),
@@ -205,6 +205,91 @@ func TestIgnoreFingerprintedDevices(t *testing.T) {
 	}
 }
 
+func TestExpandFingerprintedDevices(t *testing.T) {
+	gpu := &nvml.FingerprintDeviceData{
+		DeviceData: &nvml.DeviceData{
+			DeviceName: helper.StringToPtr("A100"),
+			UUID:       "UUID1",
+			MemoryMiB:  helper.Uint64ToPtr(40960),
+		},
+		MIGDevices: []*nvml.FingerprintDeviceData{
+			{
+				DeviceData: &nvml.DeviceData{
+					DeviceName: helper.StringToPtr("A100 MIG 3g.20gb"),
+					UUID:       "MIG-UUID1/1/0",
+					MemoryMiB:  helper.Uint64ToPtr(20480),
+				},
+				MIGProfile: "3g.20gb",
+			},
+			{
+				DeviceData: &nvml.DeviceData{
+					DeviceName: helper.StringToPtr("A100 MIG 3g.20gb"),
+					UUID:       "MIG-UUID1/2/0",
+					MemoryMiB:  helper.Uint64ToPtr(20480),
+				},
+				MIGProfile: "3g.20gb",
+			},
+		},
+	}
+	other := &nvml.FingerprintDeviceData{
+		DeviceData: &nvml.DeviceData{
+			DeviceName: helper.StringToPtr("Tesla T4"),
+			UUID:       "UUID2",
+			MemoryMiB:  helper.Uint64ToPtr(16000),
+		},
+	}
+
+	t.Run("MIG devices", func(t *testing.T) {
+		require := require.New(t)
+		ignored := map[string]struct{}{"MIG-UUID1/2/0": {}}
+		devices, instances := expandFingerprintedDevices([]*nvml.FingerprintDeviceData{gpu, other}, ignored, 1)
+		require.Equal([]*nvml.FingerprintDeviceData{gpu.MIGDevices[0], other}, devices)
+		require.Equal(map[string]*gpuInstance{
+			"MIG-UUID1/1/0": {
+				GPUUUID:   "UUID1",
+				VisibleID: "MIG-UUID1/1/0",
+				Name:      helper.StringToPtr("A100 MIG 3g.20gb"),
+			},
+		}, instances)
+	})
+
+	t.Run("Slices", func(t *testing.T) {
+		require := require.New(t)
+		devices, instances := expandFingerprintedDevices([]*nvml.FingerprintDeviceData{gpu, other}, nil, 2)
+
+		var ids []string
+		for _, d := range devices {
+			ids = append(ids, d.UUID)
+		}
+		require.Equal([]string{
+			"MIG-UUID1/1/0:0",
+			"MIG-UUID1/1/0:1",
+			"MIG-UUID1/2/0:0",
+			"MIG-UUID1/2/0:1",
+			"UUID2:0",
+			"UUID2:1",
+		}, ids)
+		require.Equal(uint64(10240), *devices[0].MemoryMiB)
+		require.Equal("3g.20gb", devices[0].MIGProfile)
+		require.Equal(uint64(8000), *devices[5].MemoryMiB)
+
+		require.Len(instances, 6)
+		require.Equal(&gpuInstance{
+			GPUUUID:   "UUID1",
+			VisibleID: "MIG-UUID1/2/0",
+			Name:      helper.StringToPtr("A100 MIG 3g.20gb"),
+		}, instances["MIG-UUID1/2/0:1"])
+		require.Equal(&gpuInstance{
+			GPUUUID:   "UUID2",
+			VisibleID: "UUID2",
+			Name:      helper.StringToPtr("Tesla T4"),
+		}, instances["UUID2:0"])
+
+		// The fingerprinted devices are not modified
+		require.Equal(uint64(16000), *other.MemoryMiB)
+	})
+}
+
 func TestCheckFingerprintUpdates(t *testing.T) {
 	for _, testCase := range []struct {
 		Name                     string
@@ -24,6 +24,14 @@ type FingerprintDeviceData struct {
 	DisplayState       string
 	PersistenceMode    string
 	PCIBusID           string
+
+	// MIGProfile is the profile of a MIG device, such as 1g.5gb. It is
+	// empty for GPUs.
+	MIGProfile string
+
+	// MIGDevices are the MIG devices the GPU is partitioned into. It is
+	// empty unless MIG is enabled on the GPU.
+	MIGDevices []*FingerprintDeviceData
 }
 
 // FingerprintData represets attributes of driver/devices
@@ -89,6 +97,7 @@ func (c *nvmlClient) GetFingerprintData() (*FingerprintData, error) {
 		9  - Memory, Cores Clock        # nvmlDeviceGetMaxClockInfo
 		10 - Display Mode               # nvmlDeviceGetDisplayMode
 		11 - Persistence Mode           # nvmlDeviceGetPersistenceMode
+		12 - MIG Devices                # nvidia-smi -L
 	*/
 
 	// Assumed that this method is called with receiver retrieved from
@@ -105,6 +114,11 @@ func (c *nvmlClient) GetFingerprintData() (*FingerprintData, error) {
 		return nil, fmt.Errorf("nvidia nvml DeviceCount() error: %v\n", err)
 	}
 
+	migDevices, err := c.driver.MIGDevices()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia MIGDevices() error: %v\n", err)
+	}
+
 	allNvidiaGPUResources := make([]*FingerprintDeviceData, numDevices)
 
 	for i := 0; i < int(numDevices); i++ {
@@ -128,6 +142,7 @@ func (c *nvmlClient) GetFingerprintData() (*FingerprintData, error) {
 			PersistenceMode:    deviceInfo.PersistenceMode,
 			PCIBusID:           deviceInfo.PCIBusID,
 		}
+		allNvidiaGPUResources[i].MIGDevices = migFingerprintData(allNvidiaGPUResources[i], migDevices[deviceInfo.UUID])
 	}
 	return &FingerprintData{
 		Devices:       allNvidiaGPUResources,
@@ -135,6 +150,37 @@ func (c *nvmlClient) GetFingerprintData() (*FingerprintData, error) {
 	}, nil
 }
 
+// migFingerprintData returns FingerprintDeviceData for the MIG devices of
+// gpu. MIG devices share the attributes of their GPU except for their name and
+// memory.
+func migFingerprintData(gpu *FingerprintDeviceData, migDevices []*MIGDeviceInfo) []*FingerprintDeviceData {
+	if len(migDevices) == 0 {
+		return nil
+	}
+
+	gpuName := notAvailable
+	if gpu.DeviceName != nil {
+		gpuName = *gpu.DeviceName
+	}
+
+	result := make([]*FingerprintDeviceData, len(migDevices))
+	for i, mig := range migDevices {
+		name := fmt.Sprintf("%s MIG %s", gpuName, mig.Profile)
+		data := *gpu
+		data.DeviceData = &DeviceData{
+			UUID:       mig.UUID,
+			DeviceName: &name,
+			MemoryMiB:  mig.MemoryMiB,
+			PowerW:     gpu.PowerW,
+			BAR1MiB:    gpu.BAR1MiB,
+		}
+		data.MIGProfile = mig.Profile
+		data.MIGDevices = nil
+		result[i] = &data
+	}
+	return result
+}
+
 // GetStatsData returns statistics data for all devices on this machine
 func (c *nvmlClient) GetStatsData() ([]*StatsData, error) {
 	/*
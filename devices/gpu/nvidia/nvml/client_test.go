@@ -16,6 +16,7 @@ type MockNVMLDriver struct {
 	driverVersion                            string
 	devices                                  []*DeviceInfo
 	deviceStatus                             []*DeviceStatus
+	migDevices                               map[string][]*MIGDeviceInfo
 }
 
 func (m *MockNVMLDriver) Initialize() error {
@@ -60,6 +61,10 @@ func (m *MockNVMLDriver) DeviceInfoAndStatusByIndex(index uint) (*DeviceInfo, *D
 	return m.devices[index], m.deviceStatus[index], nil
 }
 
+func (m *MockNVMLDriver) MIGDevices() (map[string][]*MIGDeviceInfo, error) {
+	return m.migDevices, nil
+}
+
 func TestGetFingerprintDataFromNVML(t *testing.T) {
 	for _, testCase := range []struct {
 		Name                string
@@ -191,6 +196,87 @@ func TestGetFingerprintDataFromNVML(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:          "MIG devices",
+			ExpectedError: false,
+			ExpectedResult: &FingerprintData{
+				DriverVersion: "driverVersion",
+				Devices: []*FingerprintDeviceData{
+					{
+						DeviceData: &DeviceData{
+							DeviceName: helper.StringToPtr("A100"),
+							UUID:       "UUID1",
+							MemoryMiB:  helper.Uint64ToPtr(40960),
+							PowerW:     helper.UintToPtr(400),
+							BAR1MiB:    helper.Uint64ToPtr(100),
+						},
+						PCIBusID:        "busId1",
+						DisplayState:    "Enabled",
+						PersistenceMode: "Enabled",
+						MIGDevices: []*FingerprintDeviceData{
+							{
+								DeviceData: &DeviceData{
+									DeviceName: helper.StringToPtr("A100 MIG 1g.5gb"),
+									UUID:       "MIG-UUID1/7/0",
+									MemoryMiB:  helper.Uint64ToPtr(5120),
+									PowerW:     helper.UintToPtr(400),
+									BAR1MiB:    helper.Uint64ToPtr(100),
+								},
+								PCIBusID:        "busId1",
+								DisplayState:    "Enabled",
+								PersistenceMode: "Enabled",
+								MIGProfile:      "1g.5gb",
+							},
+							{
+								DeviceData: &DeviceData{
+									DeviceName: helper.StringToPtr("A100 MIG 3g.20gb"),
+									UUID:       "MIG-UUID1/2/0",
+									MemoryMiB:  helper.Uint64ToPtr(20480),
+									PowerW:     helper.UintToPtr(400),
+									BAR1MiB:    helper.Uint64ToPtr(100),
+								},
+								PCIBusID:        "busId1",
+								DisplayState:    "Enabled",
+								PersistenceMode: "Enabled",
+								MIGProfile:      "3g.20gb",
+							},
+						},
+					},
+				},
+			},
+			DriverConfiguration: &MockNVMLDriver{
+				systemDriverCallSuccessful:      true,
+				deviceCountCallSuccessful:       true,
+				deviceInfoByIndexCallSuccessful: true,
+				driverVersion:                   "driverVersion",
+				devices: []*DeviceInfo{
+					{
+						UUID:            "UUID1",
+						Name:            helper.StringToPtr("A100"),
+						MemoryMiB:       helper.Uint64ToPtr(40960),
+						PCIBusID:        "busId1",
+						PowerW:          helper.UintToPtr(400),
+						BAR1MiB:         helper.Uint64ToPtr(100),
+						DisplayState:    "Enabled",
+						PersistenceMode: "Enabled",
+					},
+				},
+				migDevices: map[string][]*MIGDeviceInfo{
+					"UUID1": {
+						{
+							UUID:      "MIG-UUID1/7/0",
+							Profile:   "1g.5gb",
+							MemoryMiB: helper.Uint64ToPtr(5120),
+						},
+						{
+							UUID:      "MIG-UUID1/2/0",
+							Profile:   "3g.20gb",
+							MemoryMiB: helper.Uint64ToPtr(20480),
+						},
+					},
+				},
+			},
+		},
 	} {
 		cli := nvmlClient{driver: testCase.DriverConfiguration}
 		fingerprintData, err := cli.GetFingerprintData()
@@ -31,3 +31,8 @@ func (n *nvmlDriver) DeviceInfoByIndex(index uint) (*DeviceInfo, error) {
 func (n *nvmlDriver) DeviceInfoAndStatusByIndex(index uint) (*DeviceInfo, *DeviceStatus, error) {
 	return nil, nil, UnavailableLib
 }
+
+// MIGDevices returns the MIG devices of every MIG enabled GPU
+func (n *nvmlDriver) MIGDevices() (map[string][]*MIGDeviceInfo, error) {
+	return nil, UnavailableLib
+}
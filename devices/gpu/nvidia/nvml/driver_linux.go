@@ -1,6 +1,10 @@
 package nvml
 
 import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
 	"github.com/NVIDIA/gpu-monitoring-tools/bindings/go/nvml"
 )
 
@@ -83,3 +87,19 @@ func (n *nvmlDriver) DeviceInfoAndStatusByIndex(index uint) (*DeviceInfo, *Devic
 			BAR1UsedMiB:        status.PCI.BAR1Used,
 		}, nil
 }
+
+// MIGDevices returns the MIG devices of every MIG enabled GPU. An empty map
+// is returned if nvidia-smi is not installed.
+func (n *nvmlDriver) MIGDevices() (map[string][]*MIGDeviceInfo, error) {
+	path, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil, nil
+	}
+
+	out, err := exec.Command(path, "-L").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %v", err)
+	}
+
+	return parseMIGDevices(bytes.NewReader(out))
+}
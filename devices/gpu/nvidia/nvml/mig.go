@@ -0,0 +1,74 @@
+package nvml
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// notAvailable is used in place of the name of GPUs nvml was not able to
+// detect the name of
+const notAvailable = "N/A"
+
+var (
+	// gpuListRe matches the GPU lines of `nvidia-smi -L`
+	gpuListRe = regexp.MustCompile(`^GPU\s+\d+:.*\(UUID:\s*([^)\s]+)\)`)
+
+	// migListRe matches the MIG device lines of `nvidia-smi -L`
+	migListRe = regexp.MustCompile(`^\s+MIG\s+(\S+)\s+Device\s+\d+:\s*\(UUID:\s*([^)\s]+)\)`)
+
+	// migProfileMemoryRe extracts the memory size in GB from a MIG profile
+	// such as 1g.5gb
+	migProfileMemoryRe = regexp.MustCompile(`^\d+g\.(\d+)gb`)
+)
+
+// parseMIGDevices parses the output of `nvidia-smi -L` and returns the MIG
+// devices keyed by the UUID of their parent GPU. The vendored NVML bindings
+// predate MIG so the device list is used to discover them instead.
+func parseMIGDevices(r io.Reader) (map[string][]*MIGDeviceInfo, error) {
+	devices := make(map[string][]*MIGDeviceInfo)
+
+	parent := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := gpuListRe.FindStringSubmatch(line); m != nil {
+			parent = m[1]
+			continue
+		}
+
+		m := migListRe.FindStringSubmatch(line)
+		if m == nil || parent == "" {
+			continue
+		}
+
+		devices[parent] = append(devices[parent], &MIGDeviceInfo{
+			UUID:      m[2],
+			Profile:   m[1],
+			MemoryMiB: migProfileMemoryMiB(m[1]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// migProfileMemoryMiB returns the memory of a MIG profile or nil if the
+// profile is not in the expected format
+func migProfileMemoryMiB(profile string) *uint64 {
+	m := migProfileMemoryRe.FindStringSubmatch(profile)
+	if m == nil {
+		return nil
+	}
+
+	gb, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	mib := gb * 1024
+	return &mib
+}
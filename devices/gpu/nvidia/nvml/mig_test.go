@@ -0,0 +1,50 @@
+package nvml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMIGDevices(t *testing.T) {
+	require := require.New(t)
+
+	out := `GPU 0: A100-SXM4-40GB (UUID: GPU-5d5ba0d6-d33d-2b2c-524d-9e3d8d2b8a77)
+  MIG 1g.5gb Device 0: (UUID: MIG-GPU-5d5ba0d6-d33d-2b2c-524d-9e3d8d2b8a77/7/0)
+  MIG 3g.20gb     Device  1: (UUID: MIG-16d9d5b5-8d5c-5d33-8d0b-a0d8d6e5f8c5)
+GPU 1: A100-SXM4-40GB (UUID: GPU-a1b2c3d4-0000-1111-2222-333344445555)
+GPU 2: Tesla T4 (UUID: GPU-0f0e0d0c-aaaa-bbbb-cccc-ddddeeeeffff)
+  MIG 1g.5gb+me Device 0: (UUID: MIG-0f0e0d0c-aaaa-bbbb-cccc-000000000000)
+  MIG 7g.unknown Device 1: (UUID: MIG-0f0e0d0c-aaaa-bbbb-cccc-111111111111)
+`
+
+	devices, err := parseMIGDevices(strings.NewReader(out))
+	require.NoError(err)
+	require.Equal(map[string][]*MIGDeviceInfo{
+		"GPU-5d5ba0d6-d33d-2b2c-524d-9e3d8d2b8a77": {
+			{
+				UUID:      "MIG-GPU-5d5ba0d6-d33d-2b2c-524d-9e3d8d2b8a77/7/0",
+				Profile:   "1g.5gb",
+				MemoryMiB: helper.Uint64ToPtr(5120),
+			},
+			{
+				UUID:      "MIG-16d9d5b5-8d5c-5d33-8d0b-a0d8d6e5f8c5",
+				Profile:   "3g.20gb",
+				MemoryMiB: helper.Uint64ToPtr(20480),
+			},
+		},
+		"GPU-0f0e0d0c-aaaa-bbbb-cccc-ddddeeeeffff": {
+			{
+				UUID:      "MIG-0f0e0d0c-aaaa-bbbb-cccc-000000000000",
+				Profile:   "1g.5gb+me",
+				MemoryMiB: helper.Uint64ToPtr(5120),
+			},
+			{
+				UUID:    "MIG-0f0e0d0c-aaaa-bbbb-cccc-111111111111",
+				Profile: "7g.unknown",
+			},
+		},
+	}, devices)
+}
@@ -19,6 +19,19 @@ type NvmlDriver interface {
 	DeviceCount() (uint, error)
 	DeviceInfoByIndex(uint) (*DeviceInfo, error)
 	DeviceInfoAndStatusByIndex(uint) (*DeviceInfo, *DeviceStatus, error)
+	MIGDevices() (map[string][]*MIGDeviceInfo, error)
+}
+
+// MIGDeviceInfo represents a MIG device a GPU is partitioned into
+// this struct is returned by NvmlDriver MIGDevices method keyed by the UUID
+// of the parent GPU
+type MIGDeviceInfo struct {
+	UUID    string
+	Profile string
+
+	// MemoryMiB is derived from the profile and is nil if the profile
+	// could not be parsed
+	MemoryMiB *uint64
 }
 
 // DeviceInfo represents nvml device data
@@ -91,6 +91,32 @@ func filterStatsByID(stats []*nvml.StatsData, IDs map[string]struct{}) []*nvml.S
 	return filteredStats
 }
 
+// instanceStats returns StatsData for every MIG device and GPU slice. NVML
+// only reports stats for physical GPUs so the stats of the GPU an instance
+// runs on are reported for it.
+func instanceStats(stats []*nvml.StatsData, instances map[string]*gpuInstance) []*nvml.StatsData {
+	statsByID := make(map[string]*nvml.StatsData, len(stats))
+	for _, statsItem := range stats {
+		statsByID[statsItem.UUID] = statsItem
+	}
+
+	var result []*nvml.StatsData
+	for id, instance := range instances {
+		gpuStats, ok := statsByID[instance.GPUUUID]
+		if !ok {
+			continue
+		}
+
+		statsItem := *gpuStats
+		deviceData := *gpuStats.DeviceData
+		deviceData.UUID = id
+		deviceData.DeviceName = instance.Name
+		statsItem.DeviceData = &deviceData
+		result = append(result, &statsItem)
+	}
+	return result
+}
+
 // writeStatsToChannel collects StatsData from NVML backend, groups StatsData
 // by DeviceName attribute, populates DeviceGroupStats structure for every group
 // and sends data over provided channel
@@ -106,6 +132,7 @@ func (d *NvidiaDevice) writeStatsToChannel(stats chan<- *device.StatsResponse, t
 
 	// filter only stats from devices that are stored in NvidiaDevice struct
 	d.deviceLock.RLock()
+	statsData = append(statsData, instanceStats(statsData, d.instances)...)
 	statsData = filterStatsByID(statsData, d.devices)
 	d.deviceLock.RUnlock()
 
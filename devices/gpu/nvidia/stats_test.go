@@ -415,6 +415,46 @@ func TestFilterStatsByID(t *testing.T) {
 	}
 }
 
+func TestInstanceStats(t *testing.T) {
+	require := require.New(t)
+
+	stats := []*nvml.StatsData{
+		{
+			DeviceData: &nvml.DeviceData{
+				UUID:       "UUID1",
+				DeviceName: helper.StringToPtr("A100"),
+				MemoryMiB:  helper.Uint64ToPtr(40960),
+			},
+			GPUUtilization: helper.UintToPtr(50),
+		},
+	}
+	instances := map[string]*gpuInstance{
+		"MIG-UUID1/1/0": {
+			GPUUUID:   "UUID1",
+			VisibleID: "MIG-UUID1/1/0",
+			Name:      helper.StringToPtr("A100 MIG 3g.20gb"),
+		},
+		"UUID2:0": {
+			GPUUUID:   "UUID2",
+			VisibleID: "UUID2",
+			Name:      helper.StringToPtr("Tesla T4"),
+		},
+	}
+
+	// Instances of GPUs without stats are skipped
+	require.Equal([]*nvml.StatsData{
+		{
+			DeviceData: &nvml.DeviceData{
+				UUID:       "MIG-UUID1/1/0",
+				DeviceName: helper.StringToPtr("A100 MIG 3g.20gb"),
+				MemoryMiB:  helper.Uint64ToPtr(40960),
+			},
+			GPUUtilization: helper.UintToPtr(50),
+		},
+	}, instanceStats(stats, instances))
+	require.Equal("UUID1", stats[0].UUID)
+}
+
 func TestStatsForItem(t *testing.T) {
 	for _, testCase := range []struct {
 		Name           string
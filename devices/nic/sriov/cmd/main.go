@@ -0,0 +1,18 @@
+package main
+
+import (
+	log "github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/nomad/devices/nic/sriov"
+	"github.com/hashicorp/nomad/plugins"
+)
+
+func main() {
+	// Serve the plugin
+	plugins.Serve(factory)
+}
+
+// factory returns a new instance of the SR-IOV NIC plugin
+func factory(log log.Logger) interface{} {
+	return sriov.NewSriovDevice(log)
+}
@@ -0,0 +1,261 @@
+package sriov
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper/pluginutils/loader"
+	"github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/device"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+)
+
+const (
+	// pluginName is the name of the plugin
+	pluginName = "sriov-nic"
+
+	// deviceType is the type of device being returned
+	deviceType = device.DeviceTypeNIC
+
+	// vfioDriver is the driver virtual functions are bound to for userspace
+	// drivers such as DPDK or virtual machines.
+	vfioDriver = "vfio-pci"
+
+	// vfioDir is the directory of the VFIO device files
+	vfioDir = "/dev/vfio"
+)
+
+const (
+	// PCIAddressesEnv is the environment variable listing the PCI addresses
+	// of the virtual functions assigned to the task.
+	PCIAddressesEnv = "SRIOV_PCI_ADDRESSES"
+
+	// NetdevsEnv is the environment variable listing the network interfaces
+	// of the virtual functions bound to a kernel driver. Task drivers that
+	// create a network namespace for the task move these interfaces into it.
+	NetdevsEnv = "SRIOV_NETDEVS"
+)
+
+var (
+	// PluginID is the SR-IOV plugin metadata registered in the plugin
+	// catalog.
+	PluginID = loader.PluginID{
+		Name:       pluginName,
+		PluginType: base.PluginTypeDevice,
+	}
+
+	// PluginConfig is the SR-IOV factory function registered in the
+	// plugin catalog.
+	PluginConfig = &loader.InternalPluginConfig{
+		Factory: func(l log.Logger) interface{} { return NewSriovDevice(l) },
+	}
+
+	// pluginInfo describes the plugin
+	pluginInfo = &base.PluginInfoResponse{
+		Type:              base.PluginTypeDevice,
+		PluginApiVersions: []string{device.ApiVersion010},
+		PluginVersion:     "0.1.0",
+		Name:              pluginName,
+	}
+
+	// configSpec is the specification of the plugin's configuration
+	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"physical_functions": hclspec.NewDefault(
+			hclspec.NewAttr("physical_functions", "list(string)", false),
+			hclspec.NewLiteral("[]"),
+		),
+		"ignored_vf_ids": hclspec.NewDefault(
+			hclspec.NewAttr("ignored_vf_ids", "list(string)", false),
+			hclspec.NewLiteral("[]"),
+		),
+		"fingerprint_period": hclspec.NewDefault(
+			hclspec.NewAttr("fingerprint_period", "string", false),
+			hclspec.NewLiteral("\"1m\""),
+		),
+	})
+)
+
+// Config contains configuration information for the plugin.
+type Config struct {
+	PhysicalFunctions []string `codec:"physical_functions"`
+	IgnoredVFIDs      []string `codec:"ignored_vf_ids"`
+	FingerprintPeriod string   `codec:"fingerprint_period"`
+}
+
+// SriovDevice fingerprints the virtual functions of SR-IOV capable network
+// interfaces and assigns them to tasks.
+type SriovDevice struct {
+	// sysfs is the mount point of sysfs
+	sysfs string
+
+	// physicalFunctions is the set of physical function interfaces whose
+	// virtual functions are exposed. If empty all are exposed.
+	physicalFunctions map[string]struct{}
+
+	// ignoredVFIDs is a set of virtual function PCI addresses that would
+	// not be exposed to nomad
+	ignoredVFIDs map[string]struct{}
+
+	// fingerprintPeriod is how often sysfs is scanned for virtual functions
+	fingerprintPeriod time.Duration
+
+	// devices is the set of detected virtual functions by PCI address
+	devices    map[string]*virtualFunction
+	deviceLock sync.RWMutex
+
+	logger log.Logger
+}
+
+// NewSriovDevice returns a new SR-IOV device plugin.
+func NewSriovDevice(log log.Logger) *SriovDevice {
+	return &SriovDevice{
+		sysfs:             "/sys",
+		physicalFunctions: make(map[string]struct{}),
+		ignoredVFIDs:      make(map[string]struct{}),
+		devices:           make(map[string]*virtualFunction),
+		logger:            log.Named(pluginName),
+	}
+}
+
+// PluginInfo returns information describing the plugin.
+func (d *SriovDevice) PluginInfo() (*base.PluginInfoResponse, error) {
+	return pluginInfo, nil
+}
+
+// ConfigSchema returns the plugins configuration schema.
+func (d *SriovDevice) ConfigSchema() (*hclspec.Spec, error) {
+	return configSpec, nil
+}
+
+// SetConfig is used to set the configuration of the plugin.
+func (d *SriovDevice) SetConfig(cfg *base.Config) error {
+	var config Config
+	if len(cfg.PluginConfig) != 0 {
+		if err := base.MsgPackDecode(cfg.PluginConfig, &config); err != nil {
+			return err
+		}
+	}
+
+	for _, pf := range config.PhysicalFunctions {
+		d.physicalFunctions[pf] = struct{}{}
+	}
+	for _, id := range config.IgnoredVFIDs {
+		d.ignoredVFIDs[id] = struct{}{}
+	}
+
+	period, err := time.ParseDuration(config.FingerprintPeriod)
+	if err != nil {
+		return fmt.Errorf("failed to parse fingerprint period %q: %v", config.FingerprintPeriod, err)
+	}
+	d.fingerprintPeriod = period
+
+	return nil
+}
+
+// Fingerprint streams detected devices. If device changes are detected or the
+// devices health changes, messages will be emitted.
+func (d *SriovDevice) Fingerprint(ctx context.Context) (<-chan *device.FingerprintResponse, error) {
+	outCh := make(chan *device.FingerprintResponse)
+	go d.fingerprint(ctx, outCh)
+	return outCh, nil
+}
+
+type reservationError struct {
+	notExistingIDs []string
+}
+
+func (e *reservationError) Error() string {
+	return fmt.Sprintf("unknown device IDs: %s", strings.Join(e.notExistingIDs, ","))
+}
+
+// Reserve returns how to pass the given virtual functions to a task. Virtual
+// functions bound to vfio-pci are passed as VFIO devices, while those bound
+// to a kernel driver are listed in NetdevsEnv for the task driver to move
+// into the task's network namespace.
+func (d *SriovDevice) Reserve(deviceIDs []string) (*device.ContainerReservation, error) {
+	if len(deviceIDs) == 0 {
+		return &device.ContainerReservation{}, nil
+	}
+
+	d.deviceLock.RLock()
+	defer d.deviceLock.RUnlock()
+
+	var notExistingIDs []string
+	vfs := make([]*virtualFunction, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		vf, ok := d.devices[id]
+		if !ok {
+			notExistingIDs = append(notExistingIDs, id)
+			continue
+		}
+		vfs = append(vfs, vf)
+	}
+	if len(notExistingIDs) != 0 {
+		return nil, &reservationError{notExistingIDs}
+	}
+
+	res := &device.ContainerReservation{
+		Envs: map[string]string{
+			PCIAddressesEnv: strings.Join(deviceIDs, ","),
+		},
+	}
+
+	var netdevs []string
+	groups := make(map[string]struct{})
+	for _, vf := range vfs {
+		if vf.Driver == vfioDriver {
+			if vf.IOMMUGroup == "" {
+				return nil, fmt.Errorf("virtual function %s has no IOMMU group", vf.PCIAddress)
+			}
+			groups[vf.IOMMUGroup] = struct{}{}
+			continue
+		}
+
+		if vf.Netdev == "" {
+			return nil, fmt.Errorf("virtual function %s has no network interface", vf.PCIAddress)
+		}
+		netdevs = append(netdevs, vf.Netdev)
+	}
+
+	if len(netdevs) != 0 {
+		res.Envs[NetdevsEnv] = strings.Join(netdevs, ",")
+	}
+
+	if len(groups) != 0 {
+		res.Devices = append(res.Devices, vfioDevice("vfio"))
+
+		sorted := make([]string, 0, len(groups))
+		for group := range groups {
+			sorted = append(sorted, group)
+		}
+		sort.Strings(sorted)
+		for _, group := range sorted {
+			res.Devices = append(res.Devices, vfioDevice(group))
+		}
+	}
+
+	return res, nil
+}
+
+// vfioDevice returns the spec of the named VFIO device file.
+func vfioDevice(name string) *device.DeviceSpec {
+	path := filepath.Join(vfioDir, name)
+	return &device.DeviceSpec{
+		TaskPath:    path,
+		HostPath:    path,
+		CgroupPerms: "rw",
+	}
+}
+
+// Stats streams statistics for the detected devices.
+func (d *SriovDevice) Stats(ctx context.Context, interval time.Duration) (<-chan *device.StatsResponse, error) {
+	outCh := make(chan *device.StatsResponse)
+	go d.stats(ctx, outCh, interval)
+	return outCh, nil
+}
@@ -0,0 +1,113 @@
+package sriov
+
+import (
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/plugins/device"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserve(t *testing.T) {
+	devices := map[string]*virtualFunction{
+		"0000:03:02.0": {
+			PCIAddress: "0000:03:02.0",
+			Driver:     "iavf",
+			Netdev:     "eth2",
+		},
+		"0000:03:02.1": {
+			PCIAddress: "0000:03:02.1",
+			Driver:     "iavf",
+			Netdev:     "eth3",
+		},
+		"0000:03:02.2": {
+			PCIAddress: "0000:03:02.2",
+			Driver:     vfioDriver,
+			IOMMUGroup: "42",
+		},
+		"0000:03:02.3": {
+			PCIAddress: "0000:03:02.3",
+			Driver:     vfioDriver,
+			IOMMUGroup: "41",
+		},
+		"0000:03:02.4": {
+			PCIAddress: "0000:03:02.4",
+			Driver:     vfioDriver,
+		},
+	}
+
+	for _, testCase := range []struct {
+		Name                string
+		RequestedIDs        []string
+		ExpectedReservation *device.ContainerReservation
+		ExpectedError       error
+		ExpectedErrorMsg    string
+	}{
+		{
+			Name:                "No IDs requested",
+			RequestedIDs:        nil,
+			ExpectedReservation: &device.ContainerReservation{},
+		},
+		{
+			Name:          "Some RequestedIDs are not managed by Device",
+			RequestedIDs:  []string{"0000:03:02.0", "0000:05:00.0", "0000:05:00.1"},
+			ExpectedError: &reservationError{[]string{"0000:05:00.0", "0000:05:00.1"}},
+		},
+		{
+			Name:         "Netdev virtual functions",
+			RequestedIDs: []string{"0000:03:02.0", "0000:03:02.1"},
+			ExpectedReservation: &device.ContainerReservation{
+				Envs: map[string]string{
+					PCIAddressesEnv: "0000:03:02.0,0000:03:02.1",
+					NetdevsEnv:      "eth2,eth3",
+				},
+			},
+		},
+		{
+			Name:         "VFIO virtual functions",
+			RequestedIDs: []string{"0000:03:02.2", "0000:03:02.3"},
+			ExpectedReservation: &device.ContainerReservation{
+				Envs: map[string]string{
+					PCIAddressesEnv: "0000:03:02.2,0000:03:02.3",
+				},
+				Devices: []*device.DeviceSpec{
+					{TaskPath: "/dev/vfio/vfio", HostPath: "/dev/vfio/vfio", CgroupPerms: "rw"},
+					{TaskPath: "/dev/vfio/41", HostPath: "/dev/vfio/41", CgroupPerms: "rw"},
+					{TaskPath: "/dev/vfio/42", HostPath: "/dev/vfio/42", CgroupPerms: "rw"},
+				},
+			},
+		},
+		{
+			Name:         "Mixed virtual functions",
+			RequestedIDs: []string{"0000:03:02.2", "0000:03:02.0"},
+			ExpectedReservation: &device.ContainerReservation{
+				Envs: map[string]string{
+					PCIAddressesEnv: "0000:03:02.2,0000:03:02.0",
+					NetdevsEnv:      "eth2",
+				},
+				Devices: []*device.DeviceSpec{
+					{TaskPath: "/dev/vfio/vfio", HostPath: "/dev/vfio/vfio", CgroupPerms: "rw"},
+					{TaskPath: "/dev/vfio/42", HostPath: "/dev/vfio/42", CgroupPerms: "rw"},
+				},
+			},
+		},
+		{
+			Name:             "VFIO virtual function without IOMMU group",
+			RequestedIDs:     []string{"0000:03:02.4"},
+			ExpectedErrorMsg: "virtual function 0000:03:02.4 has no IOMMU group",
+		},
+	} {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := NewSriovDevice(hclog.NewNullLogger())
+			d.devices = devices
+
+			actualReservation, actualError := d.Reserve(testCase.RequestedIDs)
+			if testCase.ExpectedErrorMsg != "" {
+				require.EqualError(t, actualError, testCase.ExpectedErrorMsg)
+				return
+			}
+			require.Equal(t, testCase.ExpectedError, actualError)
+			require.Equal(t, testCase.ExpectedReservation, actualReservation)
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package sriov
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/plugins/device"
+	"github.com/hashicorp/nomad/plugins/shared/structs"
+)
+
+const (
+	// Attribute names for reporting Fingerprint output
+	PhysicalFunctionAttr = "physical_function"
+	DriverAttr           = "driver"
+	PassthroughAttr      = "passthrough"
+	SpeedAttr            = "speed"
+	NumaNodeAttr         = "numa_node"
+	DeviceIDAttr         = "device_id"
+
+	// Passthrough modes reported in PassthroughAttr
+	passthroughVFIO   = "vfio"
+	passthroughNetdev = "netdev"
+)
+
+// pciVendors maps the PCI vendor IDs of common SR-IOV capable network
+// interfaces to vendor names. Other vendors are reported by their ID.
+var pciVendors = map[string]string{
+	"8086": "intel",
+	"15b3": "mellanox",
+	"14e4": "broadcom",
+	"1077": "qlogic",
+	"19ee": "netronome",
+	"1924": "solarflare",
+	"1137": "cisco",
+}
+
+// virtualFunction is a detected SR-IOV virtual function.
+type virtualFunction struct {
+	// PCIAddress is the address of the virtual function and its device ID
+	PCIAddress string
+
+	// PhysicalFunction is the interface of the virtual function's physical
+	// function
+	PhysicalFunction string
+
+	// Vendor and DeviceID are the PCI vendor and device IDs
+	Vendor   string
+	DeviceID string
+
+	// Driver is the driver the virtual function is bound to
+	Driver string
+
+	// Netdev is the network interface of a virtual function bound to a
+	// kernel driver. It is remembered while the interface is moved into a
+	// task's network namespace and no longer visible.
+	Netdev string
+
+	// IOMMUGroup is the IOMMU group of the virtual function
+	IOMMUGroup string
+
+	// NumaNode is the NUMA node of the device or -1 if unknown
+	NumaNode int
+
+	// SpeedMbits is the link speed of the physical function or zero if
+	// unknown
+	SpeedMbits int
+
+	// Healthy is whether the physical function's link is up
+	Healthy bool
+}
+
+// fingerprint is the long running goroutine that detects hardware
+func (d *SriovDevice) fingerprint(ctx context.Context, devices chan<- *device.FingerprintResponse) {
+	defer close(devices)
+
+	// Create a timer that will fire immediately for the first detection
+	ticker := time.NewTimer(0)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ticker.Reset(d.fingerprintPeriod)
+		}
+		d.writeFingerprintToChannel(devices)
+	}
+}
+
+// writeFingerprintToChannel scans sysfs and writes the response to the
+// channel if the detected virtual functions changed.
+func (d *SriovDevice) writeFingerprintToChannel(devices chan<- *device.FingerprintResponse) {
+	vfs, err := d.scanVirtualFunctions()
+	if err != nil {
+		d.logger.Error("failed to fingerprint SR-IOV virtual functions", "error", err)
+		devices <- device.NewFingerprintError(err)
+		return
+	}
+
+	if !d.fingerprintChanged(vfs) {
+		return
+	}
+
+	devices <- device.NewFingerprint(deviceGroups(vfs)...)
+}
+
+// fingerprintChanged checks whether the detected virtual functions or their
+// health differ from the previous fingerprint and stores them. Interfaces
+// of known virtual functions that are no longer visible, because they were
+// moved into a task's network namespace, are carried over.
+func (d *SriovDevice) fingerprintChanged(vfs map[string]*virtualFunction) bool {
+	d.deviceLock.Lock()
+	defer d.deviceLock.Unlock()
+
+	for addr, vf := range vfs {
+		if old, ok := d.devices[addr]; ok && vf.Netdev == "" && vf.Driver == old.Driver {
+			vf.Netdev = old.Netdev
+		}
+	}
+
+	changed := !reflect.DeepEqual(vfs, d.devices)
+	d.devices = vfs
+	return changed
+}
+
+// scanVirtualFunctions returns the eligible virtual functions of the host's
+// SR-IOV physical functions by PCI address.
+func (d *SriovDevice) scanVirtualFunctions() (map[string]*virtualFunction, error) {
+	netDir := filepath.Join(d.sysfs, "class", "net")
+	ifaces, err := ioutil.ReadDir(netDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	vfs := make(map[string]*virtualFunction)
+	for _, iface := range ifaces {
+		pf := iface.Name()
+		if len(d.physicalFunctions) != 0 {
+			if _, ok := d.physicalFunctions[pf]; !ok {
+				continue
+			}
+		}
+
+		pfDir := filepath.Join(netDir, pf)
+		numVFs, err := readInt(filepath.Join(pfDir, "device", "sriov_numvfs"))
+		if err != nil || numVFs <= 0 {
+			continue
+		}
+
+		speed, _ := readInt(filepath.Join(pfDir, "speed"))
+		if speed < 0 {
+			speed = 0
+		}
+		state, _ := readString(filepath.Join(pfDir, "operstate"))
+
+		links, err := filepath.Glob(filepath.Join(pfDir, "device", "virtfn*"))
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			addr, err := linkName(link)
+			if err != nil {
+				d.logger.Debug("failed to read virtual function", "physical_function", pf, "error", err)
+				continue
+			}
+			if _, ok := d.ignoredVFIDs[addr]; ok {
+				continue
+			}
+
+			vf, err := d.readVirtualFunction(addr)
+			if err != nil {
+				d.logger.Debug("failed to read virtual function", "physical_function", pf, "pci_address", addr, "error", err)
+				continue
+			}
+
+			// Virtual functions not bound to a driver can not be used
+			if vf.Driver == "" {
+				continue
+			}
+
+			vf.PhysicalFunction = pf
+			vf.SpeedMbits = speed
+			vf.Healthy = state == "up"
+			vfs[addr] = vf
+		}
+	}
+
+	return vfs, nil
+}
+
+// readVirtualFunction reads the PCI device of a virtual function.
+func (d *SriovDevice) readVirtualFunction(addr string) (*virtualFunction, error) {
+	dir := filepath.Join(d.sysfs, "bus", "pci", "devices", addr)
+
+	vendor, err := readString(filepath.Join(dir, "vendor"))
+	if err != nil {
+		return nil, err
+	}
+	deviceID, err := readString(filepath.Join(dir, "device"))
+	if err != nil {
+		return nil, err
+	}
+
+	vf := &virtualFunction{
+		PCIAddress: addr,
+		Vendor:     strings.TrimPrefix(vendor, "0x"),
+		DeviceID:   strings.TrimPrefix(deviceID, "0x"),
+		NumaNode:   -1,
+	}
+
+	vf.Driver, _ = linkName(filepath.Join(dir, "driver"))
+	vf.IOMMUGroup, _ = linkName(filepath.Join(dir, "iommu_group"))
+	if node, err := readInt(filepath.Join(dir, "numa_node")); err == nil {
+		vf.NumaNode = node
+	}
+
+	if vf.Driver != vfioDriver {
+		if netdevs, err := ioutil.ReadDir(filepath.Join(dir, "net")); err == nil && len(netdevs) > 0 {
+			vf.Netdev = netdevs[0].Name()
+		}
+	}
+
+	return vf, nil
+}
+
+// deviceGroups groups virtual functions by physical function and driver. A
+// group is named after its physical function, suffixed with the driver if
+// the physical function's virtual functions are bound to different drivers.
+func deviceGroups(vfs map[string]*virtualFunction) []*device.DeviceGroup {
+	type groupKey struct{ pf, driver string }
+	byGroup := make(map[groupKey][]*virtualFunction)
+	drivers := make(map[string]map[string]struct{})
+	for _, vf := range vfs {
+		key := groupKey{vf.PhysicalFunction, vf.Driver}
+		byGroup[key] = append(byGroup[key], vf)
+		if drivers[vf.PhysicalFunction] == nil {
+			drivers[vf.PhysicalFunction] = make(map[string]struct{})
+		}
+		drivers[vf.PhysicalFunction][vf.Driver] = struct{}{}
+	}
+
+	keys := make([]groupKey, 0, len(byGroup))
+	for key := range byGroup {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pf != keys[j].pf {
+			return keys[i].pf < keys[j].pf
+		}
+		return keys[i].driver < keys[j].driver
+	})
+
+	groups := make([]*device.DeviceGroup, 0, len(keys))
+	for _, key := range keys {
+		name := key.pf
+		if len(drivers[key.pf]) > 1 {
+			name = fmt.Sprintf("%s-%s", key.pf, key.driver)
+		}
+		groups = append(groups, deviceGroup(name, byGroup[key]))
+	}
+	return groups
+}
+
+// deviceGroup returns the device group of virtual functions sharing a
+// physical function and driver.
+func deviceGroup(name string, vfs []*virtualFunction) *device.DeviceGroup {
+	sort.Slice(vfs, func(i, j int) bool {
+		return vfs[i].PCIAddress < vfs[j].PCIAddress
+	})
+
+	devices := make([]*device.Device, len(vfs))
+	for i, vf := range vfs {
+		devices[i] = &device.Device{
+			ID:      vf.PCIAddress,
+			Healthy: vf.Healthy,
+			HwLocality: &device.DeviceLocality{
+				PciBusID: vf.PCIAddress,
+			},
+		}
+		if !vf.Healthy {
+			devices[i].HealthDesc = fmt.Sprintf("link of physical function %s is not up", vf.PhysicalFunction)
+		}
+	}
+
+	// Virtual functions of the same physical function bound to the same
+	// driver share their attributes
+	vf := vfs[0]
+	passthrough := passthroughNetdev
+	if vf.Driver == vfioDriver {
+		passthrough = passthroughVFIO
+	}
+	attrs := map[string]*structs.Attribute{
+		PhysicalFunctionAttr: {
+			String: helper.StringToPtr(vf.PhysicalFunction),
+		},
+		DriverAttr: {
+			String: helper.StringToPtr(vf.Driver),
+		},
+		PassthroughAttr: {
+			String: helper.StringToPtr(passthrough),
+		},
+		DeviceIDAttr: {
+			String: helper.StringToPtr(vf.DeviceID),
+		},
+	}
+	if vf.SpeedMbits > 0 {
+		attrs[SpeedAttr] = &structs.Attribute{
+			Int:  helper.Int64ToPtr(int64(vf.SpeedMbits)),
+			Unit: structs.UnitMbPerS,
+		}
+	}
+	if vf.NumaNode >= 0 {
+		attrs[NumaNodeAttr] = &structs.Attribute{
+			Int: helper.Int64ToPtr(int64(vf.NumaNode)),
+		}
+	}
+
+	return &device.DeviceGroup{
+		Vendor:     vendorName(vf.Vendor),
+		Type:       deviceType,
+		Name:       name,
+		Devices:    devices,
+		Attributes: attrs,
+	}
+}
+
+// vendorName returns the name of the PCI vendor or its ID if unknown.
+func vendorName(id string) string {
+	if name, ok := pciVendors[id]; ok {
+		return name
+	}
+	return id
+}
+
+// readString returns the trimmed contents of a sysfs file.
+func readString(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// readInt returns the integer contents of a sysfs file.
+func readInt(path string) (int, error) {
+	s, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(s)
+}
+
+// linkName returns the name of the file a sysfs symlink points to.
+func linkName(path string) (string, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(target), nil
+}
@@ -0,0 +1,334 @@
+package sriov
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/plugins/device"
+	"github.com/hashicorp/nomad/plugins/shared/structs"
+	"github.com/stretchr/testify/require"
+)
+
+// testVF describes a virtual function created in a fake sysfs tree
+type testVF struct {
+	addr    string
+	driver  string
+	netdev  string
+	group   string
+	numa    string
+	rxBytes string
+	txBytes string
+}
+
+// testSysfs builds a fake sysfs tree with a physical function and its
+// virtual functions.
+type testSysfs struct {
+	t    *testing.T
+	root string
+}
+
+func newTestSysfs(t *testing.T) *testSysfs {
+	root, err := ioutil.TempDir("", "sriov")
+	require.NoError(t, err)
+	return &testSysfs{t: t, root: root}
+}
+
+func (s *testSysfs) cleanup() {
+	os.RemoveAll(s.root)
+}
+
+func (s *testSysfs) write(path, contents string) {
+	path = filepath.Join(s.root, path)
+	require.NoError(s.t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(s.t, ioutil.WriteFile(path, []byte(contents+"\n"), 0644))
+}
+
+func (s *testSysfs) link(path, target string) {
+	path = filepath.Join(s.root, path)
+	require.NoError(s.t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(s.t, os.Symlink(target, path))
+}
+
+func (s *testSysfs) addPF(name, speed, state string, vfs ...testVF) {
+	pfDir := filepath.Join("class", "net", name)
+	s.write(filepath.Join(pfDir, "device", "sriov_numvfs"), "2")
+	s.write(filepath.Join(pfDir, "speed"), speed)
+	s.write(filepath.Join(pfDir, "operstate"), state)
+
+	for i, vf := range vfs {
+		s.link(filepath.Join(pfDir, "device", fmt.Sprintf("virtfn%d", i)), "../"+vf.addr)
+
+		devDir := filepath.Join("bus", "pci", "devices", vf.addr)
+		s.write(filepath.Join(devDir, "vendor"), "0x8086")
+		s.write(filepath.Join(devDir, "device"), "0x154c")
+		if vf.numa != "" {
+			s.write(filepath.Join(devDir, "numa_node"), vf.numa)
+		}
+		if vf.driver != "" {
+			s.link(filepath.Join(devDir, "driver"), "../../../bus/pci/drivers/"+vf.driver)
+		}
+		if vf.group != "" {
+			s.link(filepath.Join(devDir, "iommu_group"), "../../../kernel/iommu_groups/"+vf.group)
+		}
+		if vf.netdev != "" {
+			require.NoError(s.t, os.MkdirAll(filepath.Join(s.root, devDir, "net", vf.netdev), 0755))
+			statsDir := filepath.Join("class", "net", vf.netdev, "statistics")
+			s.write(filepath.Join(statsDir, "rx_bytes"), vf.rxBytes)
+			s.write(filepath.Join(statsDir, "tx_bytes"), vf.txBytes)
+		}
+	}
+}
+
+func testDevice(sysfs string) *SriovDevice {
+	d := NewSriovDevice(hclog.NewNullLogger())
+	d.sysfs = sysfs
+	d.fingerprintPeriod = time.Minute
+	return d
+}
+
+func TestScanVirtualFunctions(t *testing.T) {
+	require := require.New(t)
+	sysfs := newTestSysfs(t)
+	defer sysfs.cleanup()
+
+	sysfs.addPF("eth0", "10000", "up",
+		testVF{addr: "0000:03:02.0", driver: "iavf", netdev: "eth2", group: "40", numa: "0"},
+		testVF{addr: "0000:03:02.1", driver: "vfio-pci", group: "41", numa: "0"},
+	)
+	sysfs.addPF("eth1", "25000", "down",
+		testVF{addr: "0000:04:02.0", driver: "iavf", netdev: "eth3", group: "50"},
+		testVF{addr: "0000:04:02.1"},
+	)
+
+	// A regular interface without virtual functions
+	sysfs.write(filepath.Join("class", "net", "lo", "operstate"), "unknown")
+
+	d := testDevice(sysfs.root)
+	vfs, err := d.scanVirtualFunctions()
+	require.NoError(err)
+	require.Equal(map[string]*virtualFunction{
+		"0000:03:02.0": {
+			PCIAddress:       "0000:03:02.0",
+			PhysicalFunction: "eth0",
+			Vendor:           "8086",
+			DeviceID:         "154c",
+			Driver:           "iavf",
+			Netdev:           "eth2",
+			IOMMUGroup:       "40",
+			NumaNode:         0,
+			SpeedMbits:       10000,
+			Healthy:          true,
+		},
+		"0000:03:02.1": {
+			PCIAddress:       "0000:03:02.1",
+			PhysicalFunction: "eth0",
+			Vendor:           "8086",
+			DeviceID:         "154c",
+			Driver:           "vfio-pci",
+			IOMMUGroup:       "41",
+			NumaNode:         0,
+			SpeedMbits:       10000,
+			Healthy:          true,
+		},
+		"0000:04:02.0": {
+			PCIAddress:       "0000:04:02.0",
+			PhysicalFunction: "eth1",
+			Vendor:           "8086",
+			DeviceID:         "154c",
+			Driver:           "iavf",
+			Netdev:           "eth3",
+			IOMMUGroup:       "50",
+			NumaNode:         -1,
+			SpeedMbits:       25000,
+		},
+	}, vfs)
+
+	// Only expose the configured physical functions and skip ignored
+	// virtual functions
+	d.physicalFunctions = map[string]struct{}{"eth0": {}}
+	d.ignoredVFIDs = map[string]struct{}{"0000:03:02.1": {}}
+	vfs, err = d.scanVirtualFunctions()
+	require.NoError(err)
+	require.Len(vfs, 1)
+	require.Contains(vfs, "0000:03:02.0")
+}
+
+func TestDeviceGroups(t *testing.T) {
+	require := require.New(t)
+
+	vfs := map[string]*virtualFunction{
+		"0000:03:02.0": {
+			PCIAddress:       "0000:03:02.0",
+			PhysicalFunction: "eth0",
+			Vendor:           "8086",
+			DeviceID:         "154c",
+			Driver:           "iavf",
+			Netdev:           "eth2",
+			NumaNode:         0,
+			SpeedMbits:       10000,
+			Healthy:          true,
+		},
+		"0000:03:02.1": {
+			PCIAddress:       "0000:03:02.1",
+			PhysicalFunction: "eth0",
+			Vendor:           "8086",
+			DeviceID:         "154c",
+			Driver:           "vfio-pci",
+			IOMMUGroup:       "41",
+			NumaNode:         0,
+			SpeedMbits:       10000,
+			Healthy:          true,
+		},
+		"0000:04:02.0": {
+			PCIAddress:       "0000:04:02.0",
+			PhysicalFunction: "eth1",
+			Vendor:           "1af4",
+			DeviceID:         "1041",
+			Driver:           "mlx5_core",
+			Netdev:           "eth3",
+			NumaNode:         -1,
+		},
+	}
+
+	expected := []*device.DeviceGroup{
+		{
+			Vendor: "intel",
+			Type:   deviceType,
+			Name:   "eth0-iavf",
+			Devices: []*device.Device{
+				{
+					ID:         "0000:03:02.0",
+					Healthy:    true,
+					HwLocality: &device.DeviceLocality{PciBusID: "0000:03:02.0"},
+				},
+			},
+			Attributes: map[string]*structs.Attribute{
+				PhysicalFunctionAttr: {String: helper.StringToPtr("eth0")},
+				DriverAttr:           {String: helper.StringToPtr("iavf")},
+				PassthroughAttr:      {String: helper.StringToPtr(passthroughNetdev)},
+				DeviceIDAttr:         {String: helper.StringToPtr("154c")},
+				SpeedAttr:            {Int: helper.Int64ToPtr(10000), Unit: structs.UnitMbPerS},
+				NumaNodeAttr:         {Int: helper.Int64ToPtr(0)},
+			},
+		},
+		{
+			Vendor: "intel",
+			Type:   deviceType,
+			Name:   "eth0-vfio-pci",
+			Devices: []*device.Device{
+				{
+					ID:         "0000:03:02.1",
+					Healthy:    true,
+					HwLocality: &device.DeviceLocality{PciBusID: "0000:03:02.1"},
+				},
+			},
+			Attributes: map[string]*structs.Attribute{
+				PhysicalFunctionAttr: {String: helper.StringToPtr("eth0")},
+				DriverAttr:           {String: helper.StringToPtr("vfio-pci")},
+				PassthroughAttr:      {String: helper.StringToPtr(passthroughVFIO)},
+				DeviceIDAttr:         {String: helper.StringToPtr("154c")},
+				SpeedAttr:            {Int: helper.Int64ToPtr(10000), Unit: structs.UnitMbPerS},
+				NumaNodeAttr:         {Int: helper.Int64ToPtr(0)},
+			},
+		},
+		{
+			Vendor: "1af4",
+			Type:   deviceType,
+			Name:   "eth1",
+			Devices: []*device.Device{
+				{
+					ID:         "0000:04:02.0",
+					HealthDesc: "link of physical function eth1 is not up",
+					HwLocality: &device.DeviceLocality{PciBusID: "0000:04:02.0"},
+				},
+			},
+			Attributes: map[string]*structs.Attribute{
+				PhysicalFunctionAttr: {String: helper.StringToPtr("eth1")},
+				DriverAttr:           {String: helper.StringToPtr("mlx5_core")},
+				PassthroughAttr:      {String: helper.StringToPtr(passthroughNetdev)},
+				DeviceIDAttr:         {String: helper.StringToPtr("1041")},
+			},
+		},
+	}
+
+	require.Equal(expected, deviceGroups(vfs))
+}
+
+func TestWriteFingerprintToChannel(t *testing.T) {
+	require := require.New(t)
+	sysfs := newTestSysfs(t)
+	defer sysfs.cleanup()
+
+	sysfs.addPF("eth0", "10000", "up",
+		testVF{addr: "0000:03:02.0", driver: "iavf", netdev: "eth2", group: "40"},
+	)
+
+	d := testDevice(sysfs.root)
+	ch := make(chan *device.FingerprintResponse, 1)
+
+	d.writeFingerprintToChannel(ch)
+	require.Len(ch, 1)
+	resp := <-ch
+	require.NoError(resp.Error)
+	require.Len(resp.Devices, 1)
+	require.Equal("eth0", resp.Devices[0].Name)
+
+	// Nothing changed so nothing is sent
+	d.writeFingerprintToChannel(ch)
+	require.Len(ch, 0)
+
+	// Moving the interface into a task's network namespace hides it but the
+	// virtual function keeps its interface name
+	require.NoError(os.RemoveAll(filepath.Join(sysfs.root, "bus", "pci", "devices", "0000:03:02.0", "net")))
+	d.writeFingerprintToChannel(ch)
+	require.Len(ch, 0)
+	require.Equal("eth2", d.devices["0000:03:02.0"].Netdev)
+
+	// A link going down changes the health of the devices
+	sysfs.write(filepath.Join("class", "net", "eth0", "operstate"), "down")
+	d.writeFingerprintToChannel(ch)
+	require.Len(ch, 1)
+	resp = <-ch
+	require.False(resp.Devices[0].Devices[0].Healthy)
+}
+
+func TestWriteStatsToChannel(t *testing.T) {
+	require := require.New(t)
+	sysfs := newTestSysfs(t)
+	defer sysfs.cleanup()
+
+	sysfs.addPF("eth0", "10000", "up",
+		testVF{addr: "0000:03:02.0", driver: "iavf", netdev: "eth2", rxBytes: "100", txBytes: "20"},
+		testVF{addr: "0000:03:02.1", driver: "vfio-pci", group: "41"},
+	)
+
+	d := testDevice(sysfs.root)
+	vfs, err := d.scanVirtualFunctions()
+	require.NoError(err)
+	d.devices = vfs
+
+	ch := make(chan *device.StatsResponse, 1)
+	now := time.Now()
+	d.writeStatsToChannel(ch, now)
+	resp := <-ch
+	require.NoError(resp.Error)
+	require.Len(resp.Groups, 2)
+
+	netdevStats := resp.Groups[0].InstanceStats["0000:03:02.0"]
+	require.NotNil(netdevStats)
+	require.Equal(now, netdevStats.Timestamp)
+	require.Equal(int64(120), *netdevStats.Summary.IntNumeratorVal)
+	require.Equal(int64(100), *netdevStats.Stats.Attributes[RxBytesAttr].IntNumeratorVal)
+	require.Equal(int64(20), *netdevStats.Stats.Attributes[TxBytesAttr].IntNumeratorVal)
+	require.Equal(notAvailable, *netdevStats.Stats.Attributes[RxPacketsAttr].StringVal)
+
+	vfioStats := resp.Groups[1].InstanceStats["0000:03:02.1"]
+	require.NotNil(vfioStats)
+	require.Equal(notAvailable, *vfioStats.Summary.StringVal)
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package sriov
+
+import "fmt"
+
+// MoveToNetns moves the network interface of a virtual function into the
+// network namespace of the given process.
+func MoveToNetns(netdev string, pid int) error {
+	return fmt.Errorf("moving interface %q to a network namespace is only supported on linux", netdev)
+}
@@ -0,0 +1,21 @@
+package sriov
+
+import (
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+)
+
+// MoveToNetns moves the network interface of a virtual function into the
+// network namespace of the given process.
+func MoveToNetns(netdev string, pid int) error {
+	link, err := netlink.LinkByName(netdev)
+	if err != nil {
+		return fmt.Errorf("failed to find interface %q: %v", netdev, err)
+	}
+
+	if err := netlink.LinkSetNsPid(link, pid); err != nil {
+		return fmt.Errorf("failed to move interface %q to network namespace of pid %d: %v", netdev, pid, err)
+	}
+	return nil
+}
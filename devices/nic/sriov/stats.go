@@ -0,0 +1,136 @@
+package sriov
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/plugins/device"
+	"github.com/hashicorp/nomad/plugins/shared/structs"
+)
+
+const (
+	// Attribute names for reporting stats output
+	RxBytesAttr    = "Bytes received"
+	RxBytesDesc    = "Bytes received by the virtual function"
+	TxBytesAttr    = "Bytes transmitted"
+	TxBytesDesc    = "Bytes transmitted by the virtual function"
+	RxPacketsAttr  = "Packets received"
+	RxPacketsDesc  = "Packets received by the virtual function"
+	TxPacketsAttr  = "Packets transmitted"
+	TxPacketsDesc  = "Packets transmitted by the virtual function"
+	TotalBytesDesc = "Bytes received and transmitted by the virtual function"
+	BytesUnit      = "bytes"
+	PacketsUnit    = "packets"
+
+	// notAvailable value is returned for statistics that can not be read,
+	// such as for virtual functions bound to vfio-pci or whose interface
+	// was moved into a task's network namespace
+	notAvailable = "N/A"
+)
+
+// stats is the long running goroutine that streams device statistics
+func (d *SriovDevice) stats(ctx context.Context, stats chan<- *device.StatsResponse, interval time.Duration) {
+	defer close(stats)
+
+	// Create a timer that will fire immediately for the first detection
+	ticker := time.NewTimer(0)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ticker.Reset(interval)
+		}
+
+		d.writeStatsToChannel(stats, time.Now())
+	}
+}
+
+// writeStatsToChannel reads the interface counters of the detected virtual
+// functions, grouped as in the fingerprint, and sends them over the channel
+func (d *SriovDevice) writeStatsToChannel(stats chan<- *device.StatsResponse, timestamp time.Time) {
+	d.deviceLock.RLock()
+	groups := deviceGroups(d.devices)
+	netdevs := make(map[string]string, len(d.devices))
+	for addr, vf := range d.devices {
+		if vf.Driver != vfioDriver {
+			netdevs[addr] = vf.Netdev
+		}
+	}
+	d.deviceLock.RUnlock()
+
+	groupStats := make([]*device.DeviceGroupStats, 0, len(groups))
+	for _, group := range groups {
+		instanceStats := make(map[string]*device.DeviceStats, len(group.Devices))
+		for _, dev := range group.Devices {
+			instanceStats[dev.ID] = d.statsForNetdev(netdevs[dev.ID], timestamp)
+		}
+
+		groupStats = append(groupStats, &device.DeviceGroupStats{
+			Vendor:        group.Vendor,
+			Type:          group.Type,
+			Name:          group.Name,
+			InstanceStats: instanceStats,
+		})
+	}
+
+	stats <- &device.StatsResponse{
+		Groups: groupStats,
+	}
+}
+
+// statsForNetdev returns the statistics of a virtual function's interface.
+// Counters that can not be read are reported as not available.
+func (d *SriovDevice) statsForNetdev(netdev string, timestamp time.Time) *device.DeviceStats {
+	var dir string
+	if netdev != "" {
+		dir = filepath.Join(d.sysfs, "class", "net", netdev, "statistics")
+	}
+
+	counter := func(file, unit, desc string) (*structs.StatValue, int64, bool) {
+		if dir != "" {
+			if v, err := readInt(filepath.Join(dir, file)); err == nil {
+				return &structs.StatValue{
+					Unit:            unit,
+					Desc:            desc,
+					IntNumeratorVal: helper.Int64ToPtr(int64(v)),
+				}, int64(v), true
+			}
+		}
+		return newNotAvailableStat(unit, desc), 0, false
+	}
+
+	rxBytes, rx, rxOk := counter("rx_bytes", BytesUnit, RxBytesDesc)
+	txBytes, tx, txOk := counter("tx_bytes", BytesUnit, TxBytesDesc)
+	rxPackets, _, _ := counter("rx_packets", PacketsUnit, RxPacketsDesc)
+	txPackets, _, _ := counter("tx_packets", PacketsUnit, TxPacketsDesc)
+
+	summary := newNotAvailableStat(BytesUnit, TotalBytesDesc)
+	if rxOk && txOk {
+		summary = &structs.StatValue{
+			Unit:            BytesUnit,
+			Desc:            TotalBytesDesc,
+			IntNumeratorVal: helper.Int64ToPtr(rx + tx),
+		}
+	}
+
+	return &device.DeviceStats{
+		Summary: summary,
+		Stats: &structs.StatObject{
+			Attributes: map[string]*structs.StatValue{
+				RxBytesAttr:   rxBytes,
+				TxBytesAttr:   txBytes,
+				RxPacketsAttr: rxPackets,
+				TxPacketsAttr: txPackets,
+			},
+		},
+		Timestamp: timestamp,
+	}
+}
+
+func newNotAvailableStat(unit, desc string) *structs.StatValue {
+	return &structs.StatValue{Unit: unit, Desc: desc, StringVal: helper.StringToPtr(notAvailable)}
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/hashicorp/nomad/client/lib/netshape"
+	"github.com/hashicorp/nomad/plugins/drivers"
+)
+
+// containerIfName is the name of a container's interface on its network
+const containerIfName = "eth0"
+
+// shapeBandwidth limits the bandwidth of the container to the bandwidth of
+// the task's network resources if bandwidth enforcement is enabled. Only
+// containers on a bridge network, with a veth on the host, are limited.
+func (d *Driver) shapeBandwidth(container *docker.Container, task *drivers.TaskConfig) error {
+	if !d.config.EnforceBandwidth {
+		return nil
+	}
+
+	mbits := taskBandwidth(task)
+	if mbits == 0 {
+		return nil
+	}
+
+	if !hasVeth(container) {
+		d.logger.Debug("not limiting bandwidth of container without its own network",
+			"container_id", container.ID, "network_mode", container.HostConfig.NetworkMode)
+		return nil
+	}
+
+	dev, err := netshape.HostVeth(container.State.Pid, containerIfName)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("limiting container bandwidth", "container_id", container.ID, "interface", dev, "mbits", mbits)
+	return netshape.Shape(dev, mbits)
+}
+
+// taskBandwidth returns the bandwidth in megabits allocated to the task.
+func taskBandwidth(task *drivers.TaskConfig) int {
+	if task.Resources == nil || task.Resources.NomadResources == nil {
+		return 0
+	}
+	mbits := 0
+	for _, n := range task.Resources.NomadResources.Networks {
+		mbits += n.MBits
+	}
+	return mbits
+}
+
+// hasVeth returns whether the container is attached to a bridge network by a
+// veth pair.
+func hasVeth(container *docker.Container) bool {
+	if container.HostConfig == nil {
+		return false
+	}
+	switch mode := container.HostConfig.NetworkMode; {
+	case mode == "host", mode == "none", len(mode) > 10 && mode[:10] == "container:":
+		return false
+	}
+	return container.State.Pid != 0
+}
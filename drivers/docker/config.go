@@ -8,6 +8,7 @@ import (
 
 	docker "github.com/fsouza/go-dockerclient"
 	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
 	"github.com/hashicorp/nomad/helper/pluginutils/loader"
 	"github.com/hashicorp/nomad/plugins/base"
 	"github.com/hashicorp/nomad/plugins/drivers"
@@ -152,6 +153,10 @@ var (
 	//			image = true
 	//			image_delay = "5m"
 	//			container = false
+	//			prune {
+	//				enabled = true
+	//				max_disk_usage_mb = 20480
+	//			}
 	//		}
 	//		volumes {
 	//			enabled = true
@@ -160,6 +165,7 @@ var (
 	//		allow_privileged = false
 	//		allow_caps = ["CHOWN", "NET_RAW" ... ]
 	//		nvidia_runtime = "nvidia"
+	//		enforce_bandwidth = false
 	//		}
 	//	}
 	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{
@@ -191,6 +197,7 @@ var (
 				hclspec.NewAttr("container", "bool", false),
 				hclspec.NewLiteral("true"),
 			),
+			"prune": imagegc.ConfigSpec,
 		})), hclspec.NewLiteral(`{
 			image = true
 			container = true
@@ -215,6 +222,7 @@ var (
 			hclspec.NewAttr("nvidia_runtime", "string", false),
 			hclspec.NewLiteral(`"nvidia"`),
 		),
+		"enforce_bandwidth": hclspec.NewAttr("enforce_bandwidth", "bool", false),
 	})
 
 	// taskConfigSpec is the hcl specification for the driver config section of
@@ -229,12 +237,13 @@ var (
 			"email":          hclspec.NewAttr("email", "string", false),
 			"server_address": hclspec.NewAttr("server_address", "string", false),
 		})),
-		"auth_soft_fail": hclspec.NewAttr("auth_soft_fail", "bool", false),
-		"cap_add":        hclspec.NewAttr("cap_add", "list(string)", false),
-		"cap_drop":       hclspec.NewAttr("cap_drop", "list(string)", false),
-		"command":        hclspec.NewAttr("command", "string", false),
-		"cpu_hard_limit": hclspec.NewAttr("cpu_hard_limit", "bool", false),
-		"cpu_cfs_period": hclspec.NewAttr("cpu_cfs_period", "number", false),
+		"auth_soft_fail":  hclspec.NewAttr("auth_soft_fail", "bool", false),
+		"cap_add":         hclspec.NewAttr("cap_add", "list(string)", false),
+		"cap_drop":        hclspec.NewAttr("cap_drop", "list(string)", false),
+		"command":         hclspec.NewAttr("command", "string", false),
+		"cpu_hard_limit":  hclspec.NewAttr("cpu_hard_limit", "bool", false),
+		"credential_spec": hclspec.NewAttr("credential_spec", "string", false),
+		"cpu_cfs_period":  hclspec.NewAttr("cpu_cfs_period", "number", false),
 		"devices": hclspec.NewBlockList("devices", hclspec.NewObject(map[string]*hclspec.Spec{
 			"host_path":          hclspec.NewAttr("host_path", "string", false),
 			"container_path":     hclspec.NewAttr("container_path", "string", false),
@@ -322,6 +331,7 @@ type TaskConfig struct {
 	Command           string            `codec:"command"`
 	CPUCFSPeriod      int64             `codec:"cpu_cfs_period"`
 	CPUHardLimit      bool              `codec:"cpu_hard_limit"`
+	CredentialSpec    string            `codec:"credential_spec"`
 	Devices           []DockerDevice    `codec:"devices"`
 	DNSSearchDomains  []string          `codec:"dns_search_domains"`
 	DNSOptions        []string          `codec:"dns_options"`
@@ -482,6 +492,10 @@ type DriverConfig struct {
 	AllowPrivileged bool         `codec:"allow_privileged"`
 	AllowCaps       []string     `codec:"allow_caps"`
 	GPURuntimeName  string       `codec:"nvidia_runtime"`
+
+	// EnforceBandwidth limits the bandwidth of containers on a bridge
+	// network to the task's network resources
+	EnforceBandwidth bool `codec:"enforce_bandwidth"`
 }
 
 type AuthConfig struct {
@@ -500,6 +514,9 @@ type GCConfig struct {
 	ImageDelay         string        `codec:"image_delay"`
 	imageDelayDuration time.Duration `codec:"-"`
 	Container          bool          `codec:"container"`
+
+	// Prune configures periodically pruning unused images
+	Prune imagegc.Config `codec:"prune"`
 }
 
 type VolumeConfig struct {
@@ -532,6 +549,11 @@ func (d *Driver) SetConfig(c *base.Config) error {
 		d.config.GC.imageDelayDuration = dur
 	}
 
+	prunePolicy, err := d.config.GC.Prune.Policy()
+	if err != nil {
+		return fmt.Errorf("invalid 'prune' configuration: %v", err)
+	}
+
 	if c.AgentConfig != nil {
 		d.clientConfig = c.AgentConfig.Driver
 	}
@@ -549,6 +571,11 @@ func (d *Driver) SetConfig(c *base.Config) error {
 
 	d.coordinator = newDockerCoordinator(coordinatorConfig)
 
+	if prunePolicy != nil && d.imagePruner == nil {
+		d.imagePruner = imagegc.NewPruner(d.logger, pluginName, prunePolicy, &imageGCClient{client: dockerClient})
+		go d.imagePruner.Run(d.ctx)
+	}
+
 	return nil
 }
 
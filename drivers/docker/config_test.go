@@ -3,6 +3,7 @@ package docker
 import (
 	"testing"
 
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
 	"github.com/hashicorp/nomad/helper/pluginutils/hclutils"
 	"github.com/stretchr/testify/require"
 )
@@ -294,3 +295,30 @@ config {
 
 	require.EqualValues(t, expected, tc)
 }
+
+func TestConfig_PluginConfig_Prune(t *testing.T) {
+	require := require.New(t)
+	parser := hclutils.NewConfigParser(configSpec)
+
+	var dc *DriverConfig
+	parser.ParseHCL(t, `config {}`, &dc)
+	require.False(dc.GC.Prune.Enabled)
+
+	parser.ParseHCL(t, `
+config {
+  gc {
+    prune {
+      enabled = true
+      min_age = "30m"
+      max_disk_usage_mb = 1024
+    }
+  }
+}`, &dc)
+	require.True(dc.GC.Image)
+	require.Equal(imagegc.Config{
+		Enabled:        true,
+		MinAge:         "30m",
+		MaxDiskUsageMB: 1024,
+		ProtectInUse:   true,
+	}, dc.GC.Prune)
+}
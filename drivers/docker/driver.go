@@ -20,6 +20,8 @@ import (
 	"github.com/hashicorp/nomad/devices/gpu/nvidia"
 	"github.com/hashicorp/nomad/drivers/docker/docklog"
 	"github.com/hashicorp/nomad/drivers/shared/eventer"
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
+	"github.com/hashicorp/nomad/helper"
 	nstructs "github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/plugins/base"
 	"github.com/hashicorp/nomad/plugins/drivers"
@@ -43,6 +45,7 @@ var (
 	// The statistics the Docker driver exposes
 	DockerMeasuredMemStats = []string{"RSS", "Cache", "Swap", "Usage", "Max Usage"}
 	DockerMeasuredCpuStats = []string{"Throttled Periods", "Throttled Time", "Percent"}
+	DockerMeasuredNetStats = []string{"Rx Bytes", "Tx Bytes", "Rx Dropped", "Tx Dropped"}
 
 	// recoverableErrTimeouts returns a recoverable error if the error was due
 	// to timeouts
@@ -87,6 +90,9 @@ type Driver struct {
 	// coordinator is what tracks multiple image pulls against the same docker image
 	coordinator *dockerCoordinator
 
+	// imagePruner removes unused images if image pruning is enabled
+	imagePruner *imagegc.Pruner
+
 	// logger will log to the Nomad agent
 	logger hclog.Logger
 
@@ -193,6 +199,9 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 	if err != nil {
 		return nil, nil, err
 	}
+	if d.imagePruner != nil {
+		d.imagePruner.Touch(id)
+	}
 
 	containerCfg, err := d.createContainerConfig(cfg, &driverConfig, driverConfig.Image)
 	if err != nil {
@@ -242,11 +251,28 @@ CREATE:
 		}
 		container = runningContainer
 		d.logger.Info("started container", "container_id", container.ID)
+
+		// Attach the task's SR-IOV interfaces. This only happens when the
+		// container is started since the interfaces have already left the
+		// host's network namespace when re-attaching.
+		if err := d.attachNetdevs(container, cfg); err != nil {
+			d.logger.Error("failed to attach SR-IOV interfaces, terminating container", "container_id", container.ID, "error", err)
+			client.RemoveContainer(docker.RemoveContainerOptions{ID: container.ID, Force: true})
+			return nil, nil, fmt.Errorf("failed to attach SR-IOV interfaces to container %s: %v", container.ID, err)
+		}
 	} else {
 		d.logger.Debug("re-attaching to container", "container_id",
 			container.ID, "container_state", container.State.String())
 	}
 
+	// Enforce the task's network bandwidth. Shaping is idempotent, so it is
+	// reapplied when re-attaching to a container.
+	if err := d.shapeBandwidth(container, cfg); err != nil {
+		d.logger.Error("failed to limit container bandwidth, terminating container", "container_id", container.ID, "error", err)
+		client.RemoveContainer(docker.RemoveContainerOptions{ID: container.ID, Force: true})
+		return nil, nil, fmt.Errorf("failed to limit bandwidth of container %s: %v", container.ID, err)
+	}
+
 	dlogger, pluginClient, err := docklog.LaunchDockerLogger(d.logger)
 	if err != nil {
 		if pluginClient != nil {
@@ -632,6 +658,10 @@ func (d *Driver) createContainerConfig(task *drivers.TaskConfig, driverConfig *T
 		Memory:    task.Resources.LinuxResources.MemoryLimitBytes,
 		CPUShares: task.Resources.LinuxResources.CPUShares,
 
+		// CPUSetCPUs restricts the container to the cores not reserved for
+		// the host.
+		CPUSetCPUs: task.Resources.LinuxResources.CpusetCPUs,
+
 		// Binds are used to mount a host volume into the container. We mount a
 		// local directory for storage and a shared alloc directory that can be
 		// used to share data between different tasks in the same task group.
@@ -681,7 +711,7 @@ func (d *Driver) createContainerConfig(task *drivers.TaskConfig, driverConfig *T
 
 	logger.Debug("configured resources", "memory", hostConfig.Memory,
 		"cpu_shares", hostConfig.CPUShares, "cpu_quota", hostConfig.CPUQuota,
-		"cpu_period", hostConfig.CPUPeriod)
+		"cpu_period", hostConfig.CPUPeriod, "cpuset_cpus", hostConfig.CPUSetCPUs)
 	logger.Debug("binding directories", "binds", hclog.Fmt("%#v", hostConfig.Binds))
 
 	//  set privileged mode
@@ -791,6 +821,20 @@ func (d *Driver) createContainerConfig(task *drivers.TaskConfig, driverConfig *T
 	hostConfig.SecurityOpt = driverConfig.SecurityOpt
 	hostConfig.Sysctls = driverConfig.Sysctl
 
+	// The gMSA credential spec of a Windows container is passed as a
+	// security option
+	if driverConfig.CredentialSpec != "" {
+		if runtime.GOOS != "windows" {
+			return c, fmt.Errorf("credential_spec is only supported on Windows")
+		}
+		if !strings.HasPrefix(driverConfig.CredentialSpec, "file://") &&
+			!strings.HasPrefix(driverConfig.CredentialSpec, "registry://") {
+			return c, fmt.Errorf("credential_spec must start with file:// or registry://: %q", driverConfig.CredentialSpec)
+		}
+		hostConfig.SecurityOpt = append(helper.CopySliceString(hostConfig.SecurityOpt),
+			"credentialspec="+driverConfig.CredentialSpec)
+	}
+
 	ulimits, err := sliceMergeUlimit(driverConfig.Ulimit)
 	if err != nil {
 		return c, fmt.Errorf("failed to parse ulimit configuration: %v", err)
@@ -925,6 +925,47 @@ func TestDockerDriver_CreateContainerConfig(t *testing.T) {
 	require.EqualValues(t, opt, c.HostConfig.StorageOpt)
 }
 
+func TestDockerDriver_CreateContainerConfig_CredentialSpec(t *testing.T) {
+	t.Parallel()
+
+	task, cfg, _ := dockerTask(t)
+	cfg.SecurityOpt = []string{"foo=bar"}
+	cfg.CredentialSpec = "file://web.json"
+	require.NoError(t, task.EncodeConcreteDriverConfig(cfg))
+
+	dh := dockerDriverHarness(t, nil)
+	driver := dh.Impl().(*Driver)
+
+	c, err := driver.createContainerConfig(task, cfg, "org/repo:0.1")
+	if runtime.GOOS != "windows" {
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only supported on Windows")
+		return
+	}
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo=bar", "credentialspec=file://web.json"}, c.HostConfig.SecurityOpt)
+
+	cfg.CredentialSpec = "web.json"
+	_, err = driver.createContainerConfig(task, cfg, "org/repo:0.1")
+	require.Error(t, err)
+}
+
+func TestDockerDriver_CreateContainerConfig_Cpuset(t *testing.T) {
+	t.Parallel()
+
+	task, cfg, _ := dockerTask(t)
+	task.Resources.LinuxResources.CpusetCPUs = "2-3"
+	require.NoError(t, task.EncodeConcreteDriverConfig(cfg))
+
+	dh := dockerDriverHarness(t, nil)
+	driver := dh.Impl().(*Driver)
+
+	c, err := driver.createContainerConfig(task, cfg, "org/repo:0.1")
+	require.NoError(t, err)
+
+	require.Equal(t, "2-3", c.HostConfig.CPUSetCPUs)
+}
+
 func TestDockerDriver_CreateContainerConfigWithRuntimes(t *testing.T) {
 	if !tu.IsTravis() {
 		t.Parallel()
@@ -3,6 +3,7 @@ package docker
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -34,6 +35,11 @@ type taskHandle struct {
 
 	exitResult     *drivers.ExitResult
 	exitResultLock sync.Mutex
+
+	// memoryRSS is the last resident set size of the container reported by
+	// docker, used to describe the exit of OOM killed containers
+	memoryRSS     uint64
+	memoryRSSLock sync.Mutex
 }
 
 func (h *taskHandle) ExitResult() *drivers.ExitResult {
@@ -192,11 +198,13 @@ func (h *taskHandle) run() {
 
 	container, ierr := h.waitClient.InspectContainer(h.containerID)
 	oom := false
+	var details map[string]string
 	if ierr != nil {
 		h.logger.Error("failed to inspect container", "error", ierr)
 	} else if container.State.OOMKilled {
 		oom = true
 		werr = fmt.Errorf("OOM Killed")
+		details = h.oomDetails(container)
 	}
 
 	// Shutdown stats collection
@@ -228,7 +236,33 @@ func (h *taskHandle) run() {
 		Signal:    0,
 		OOMKilled: oom,
 		Err:       werr,
+		Details:   details,
 	}
 	h.exitResultLock.Unlock()
 	close(h.waitCh)
 }
+
+// recordStats records the memory usage of the container from its stats.
+func (h *taskHandle) recordStats(s *docker.Stats) {
+	h.memoryRSSLock.Lock()
+	h.memoryRSS = s.MemoryStats.Stats.Rss
+	h.memoryRSSLock.Unlock()
+}
+
+// oomDetails returns the exit details of an OOM killed container: its last
+// known memory usage and its memory limit.
+func (h *taskHandle) oomDetails(container *docker.Container) map[string]string {
+	details := make(map[string]string, 2)
+
+	h.memoryRSSLock.Lock()
+	rss := h.memoryRSS
+	h.memoryRSSLock.Unlock()
+	if rss > 0 {
+		details[drivers.ExitDetailMemoryRSS] = strconv.FormatUint(rss, 10)
+	}
+
+	if container.HostConfig != nil && container.HostConfig.Memory > 0 {
+		details[drivers.ExitDetailMemoryLimit] = strconv.FormatInt(container.HostConfig.Memory, 10)
+	}
+	return details
+}
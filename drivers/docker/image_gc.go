@@ -0,0 +1,56 @@
+package docker
+
+import (
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
+)
+
+// imageGCClient implements imagegc.ImageClient for Docker images
+type imageGCClient struct {
+	client *docker.Client
+}
+
+// ListImages returns all top level images and whether they are used by
+// containers.
+func (c *imageGCClient) ListImages() ([]*imagegc.Image, error) {
+	apiImages, err := c.client.ListImages(docker.ListImagesOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := c.client.ListContainers(docker.ListContainersOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// The container listing includes the image name rather than its ID, so
+	// inspect each container to find the image it was created from
+	inUse := make(map[string]bool, len(containers))
+	for _, apiContainer := range containers {
+		container, err := c.client.InspectContainer(apiContainer.ID)
+		if err != nil {
+			if _, ok := err.(*docker.NoSuchContainer); ok {
+				continue
+			}
+			return nil, err
+		}
+		inUse[container.Image] = inUse[container.Image] || container.State.Running
+	}
+
+	images := make([]*imagegc.Image, len(apiImages))
+	for i, apiImage := range apiImages {
+		running, used := inUse[apiImage.ID]
+		images[i] = &imagegc.Image{
+			ID:      apiImage.ID,
+			Size:    apiImage.Size,
+			InUse:   used,
+			Running: running,
+		}
+	}
+	return images, nil
+}
+
+// RemoveImage removes the image, forcibly if requested.
+func (c *imageGCClient) RemoveImage(id string, force bool) error {
+	return c.client.RemoveImageExtended(id, docker.RemoveImageOptions{Force: force})
+}
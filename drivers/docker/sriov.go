@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/hashicorp/nomad/devices/nic/sriov"
+	"github.com/hashicorp/nomad/plugins/drivers"
+)
+
+// attachNetdevs moves the network interfaces of the SR-IOV virtual functions
+// assigned to the task into the container's network namespace. Containers
+// sharing the host's or another container's network are left untouched, as
+// are containers without network.
+func (d *Driver) attachNetdevs(container *docker.Container, task *drivers.TaskConfig) error {
+	netdevs := task.DeviceEnv[sriov.NetdevsEnv]
+	if netdevs == "" {
+		return nil
+	}
+
+	if !hasVeth(container) {
+		d.logger.Debug("not attaching SR-IOV interfaces to container without its own network",
+			"container_id", container.ID, "network_mode", container.HostConfig.NetworkMode)
+		return nil
+	}
+
+	for _, dev := range strings.Split(netdevs, ",") {
+		d.logger.Debug("attaching SR-IOV interface to container", "container_id", container.ID, "interface", dev)
+		if err := sriov.MoveToNetns(dev, container.State.Pid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
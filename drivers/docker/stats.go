@@ -57,7 +57,7 @@ func (h *taskHandle) collectStats(ctx context.Context, ch chan *cstructs.TaskRes
 		// receive stats from docker and emit nomad stats
 		// statsCh will always be closed by docker client.
 		statsCh := make(chan *docker.Stats)
-		go dockerStatsCollector(ch, statsCh, interval)
+		go dockerStatsCollector(ch, statsCh, interval, h.recordStats)
 
 		statsOpts := docker.StatsOptions{
 			ID:      h.containerID,
@@ -86,7 +86,7 @@ func (h *taskHandle) collectStats(ctx context.Context, ch chan *cstructs.TaskRes
 		return
 	}
 }
-func dockerStatsCollector(destCh chan *cstructs.TaskResourceUsage, statsCh <-chan *docker.Stats, interval time.Duration) {
+func dockerStatsCollector(destCh chan *cstructs.TaskResourceUsage, statsCh <-chan *docker.Stats, interval time.Duration, record func(*docker.Stats)) {
 	var resourceUsage *cstructs.TaskResourceUsage
 
 	// hasSentInitialStats is used so as to emit the first stats received from
@@ -117,6 +117,9 @@ func dockerStatsCollector(destCh chan *cstructs.TaskResourceUsage, statsCh <-cha
 			}
 			// s should always be set, but check and skip just in case
 			if s != nil {
+				if record != nil {
+					record(s)
+				}
 				resourceUsage = dockerStatsToTaskResourceUsage(s)
 				// send stats next interation if this is the first time received
 				// from docker
@@ -157,10 +160,25 @@ func dockerStatsToTaskResourceUsage(s *docker.Stats) *cstructs.TaskResourceUsage
 		s.CPUStats.CPUUsage.TotalUsage, s.PreCPUStats.CPUUsage.TotalUsage, runtime.NumCPU())
 	cs.TotalTicks = (cs.Percent / 100) * stats.TotalTicksAvailable() / float64(runtime.NumCPU())
 
+	// Sum the stats of each network the container is attached to
+	var ns *cstructs.NetworkStats
+	if len(s.Networks) > 0 {
+		ns = &cstructs.NetworkStats{
+			Measured: DockerMeasuredNetStats,
+		}
+		for _, n := range s.Networks {
+			ns.RxBytes += n.RxBytes
+			ns.TxBytes += n.TxBytes
+			ns.RxDropped += n.RxDropped
+			ns.TxDropped += n.TxDropped
+		}
+	}
+
 	return &cstructs.TaskResourceUsage{
 		ResourceUsage: &cstructs.ResourceUsage{
-			MemoryStats: ms,
-			CpuStats:    cs,
+			MemoryStats:  ms,
+			CpuStats:     cs,
+			NetworkStats: ns,
 		},
 		Timestamp: s.Read.UTC().UnixNano(),
 	}
@@ -26,7 +26,7 @@ func TestDriver_DockerStatsCollector(t *testing.T) {
 	stats.MemoryStats.Usage = 5651904
 	stats.MemoryStats.MaxUsage = 6651904
 
-	go dockerStatsCollector(dst, src, time.Second)
+	go dockerStatsCollector(dst, src, time.Second, nil)
 
 	select {
 	case src <- stats:
@@ -47,3 +47,50 @@ func TestDriver_DockerStatsCollector(t *testing.T) {
 		require.Fail("receiving stats should not block here")
 	}
 }
+
+func TestDriver_DockerStatsToTaskResourceUsage_Network(t *testing.T) {
+	require := require.New(t)
+
+	stats := &docker.Stats{
+		Networks: map[string]docker.NetworkStats{
+			"eth0": {RxBytes: 100, TxBytes: 200, RxDropped: 1, TxDropped: 2},
+			"eth1": {RxBytes: 10, TxBytes: 20, RxDropped: 3, TxDropped: 4},
+		},
+	}
+
+	ru := dockerStatsToTaskResourceUsage(stats)
+	ns := ru.ResourceUsage.NetworkStats
+	require.NotNil(ns)
+	require.EqualValues(110, ns.RxBytes)
+	require.EqualValues(220, ns.TxBytes)
+	require.EqualValues(4, ns.RxDropped)
+	require.EqualValues(6, ns.TxDropped)
+	require.Equal(DockerMeasuredNetStats, ns.Measured)
+
+	// Containers without a network report no network stats
+	ru = dockerStatsToTaskResourceUsage(&docker.Stats{})
+	require.Nil(ru.ResourceUsage.NetworkStats)
+}
+
+func TestDriver_DockerStats_OOMDetails(t *testing.T) {
+	require := require.New(t)
+
+	h := &taskHandle{}
+	container := &docker.Container{
+		HostConfig: &docker.HostConfig{Memory: 2147483648},
+	}
+
+	// Without stats only the limit is known
+	require.Equal(map[string]string{
+		drivers.ExitDetailMemoryLimit: "2147483648",
+	}, h.oomDetails(container))
+
+	stats := &docker.Stats{}
+	stats.MemoryStats.Stats.Rss = 2254857830
+	h.recordStats(stats)
+
+	require.Equal(map[string]string{
+		drivers.ExitDetailMemoryRSS:   "2254857830",
+		drivers.ExitDetailMemoryLimit: "2147483648",
+	}, h.oomDetails(container))
+}
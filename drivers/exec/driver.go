@@ -59,7 +59,9 @@ var (
 	}
 
 	// configSpec is the hcl specification returned by the ConfigSchema RPC
-	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{})
+	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"user_namespace": executor.UserNamespaceSpec,
+	})
 
 	// taskConfigSpec is the hcl specification for the driver config section of
 	// a task within a job. It is returned in the TaskConfigSchema RPC
@@ -75,6 +77,14 @@ var (
 		Exec:        true,
 		FSIsolation: drivers.FSIsolationChroot,
 	}
+
+	// windowsCapabilities is returned by the Capabilities RPC on Windows,
+	// where tasks are isolated by job objects but not by a chroot
+	windowsCapabilities = &drivers.Capabilities{
+		SendSignals: true,
+		Exec:        true,
+		FSIsolation: drivers.FSIsolationNone,
+	}
 )
 
 // Driver fork/execs tasks using many of the underlying OS's isolation
@@ -84,6 +94,12 @@ type Driver struct {
 	// event can be broadcast to all callers
 	eventer *eventer.Eventer
 
+	// config is the driver configuration set by the SetConfig RPC
+	config *Config
+
+	// userNamespace is the user namespace tasks are started in, if enabled
+	userNamespace *executor.UserNamespaceConfig
+
 	// nomadConfig is the client config from nomad
 	nomadConfig *base.ClientDriverConfig
 
@@ -107,6 +123,13 @@ type Driver struct {
 	fingerprintLock    sync.Mutex
 }
 
+// Config is the driver configuration set by the SetConfig RPC call
+type Config struct {
+	// UserNamespace configures tasks to run in a user namespace, which lets
+	// a client that isn't running as root isolate them
+	UserNamespace *executor.UserNamespacePluginConfig `codec:"user_namespace"`
+}
+
 // TaskConfig is the driver configuration of a task within a job
 type TaskConfig struct {
 	Command string   `codec:"command"`
@@ -129,6 +152,7 @@ func NewExecDriver(logger hclog.Logger) drivers.DriverPlugin {
 	logger = logger.Named(pluginName)
 	return &Driver{
 		eventer:        eventer.NewEventer(ctx, logger),
+		config:         &Config{},
 		tasks:          newTaskStore(),
 		ctx:            ctx,
 		signalShutdown: cancel,
@@ -167,6 +191,20 @@ func (d *Driver) ConfigSchema() (*hclspec.Spec, error) {
 }
 
 func (d *Driver) SetConfig(cfg *base.Config) error {
+	var config Config
+	if cfg != nil && len(cfg.PluginConfig) != 0 {
+		if err := base.MsgPackDecode(cfg.PluginConfig, &config); err != nil {
+			return err
+		}
+	}
+
+	userNamespace, err := config.UserNamespace.Config()
+	if err != nil {
+		return fmt.Errorf("invalid user_namespace config: %v", err)
+	}
+
+	d.config = &config
+	d.userNamespace = userNamespace
 	if cfg != nil && cfg.AgentConfig != nil {
 		d.nomadConfig = cfg.AgentConfig.Driver
 	}
@@ -182,6 +220,9 @@ func (d *Driver) TaskConfigSchema() (*hclspec.Spec, error) {
 }
 
 func (d *Driver) Capabilities() (*drivers.Capabilities, error) {
+	if runtime.GOOS == "windows" {
+		return windowsCapabilities, nil
+	}
 	return capabilities, nil
 }
 
@@ -208,6 +249,19 @@ func (d *Driver) handleFingerprint(ctx context.Context, ch chan<- *drivers.Finge
 }
 
 func (d *Driver) buildFingerprint() *drivers.Fingerprint {
+	// On Windows tasks are placed in job objects, which doesn't require
+	// special privileges
+	if runtime.GOOS == "windows" && d.userNamespace == nil {
+		d.setFingerprintSuccess()
+		return &drivers.Fingerprint{
+			Attributes: map[string]*pstructs.Attribute{
+				"driver.exec": pstructs.NewBoolAttribute(true),
+			},
+			Health:            drivers.HealthStateHealthy,
+			HealthDescription: drivers.DriverHealthy,
+		}
+	}
+
 	if runtime.GOOS != "linux" {
 		d.setFingerprintFailure()
 		return &drivers.Fingerprint{
@@ -222,7 +276,15 @@ func (d *Driver) buildFingerprint() *drivers.Fingerprint {
 		HealthDescription: drivers.DriverHealthy,
 	}
 
-	if !utils.IsUnixRoot() {
+	if d.userNamespace != nil {
+		if !executor.UserNamespacesSupported() {
+			fp.Health = drivers.HealthStateUndetected
+			fp.HealthDescription = "User namespaces are not supported by the kernel"
+			d.setFingerprintFailure()
+			return fp
+		}
+		fp.Attributes["driver.exec.user_namespace"] = pstructs.NewBoolAttribute(true)
+	} else if !utils.IsUnixRoot() {
 		fp.Health = drivers.HealthStateUndetected
 		fp.HealthDescription = drivers.DriverRequiresRootMessage
 		d.setFingerprintFailure()
@@ -324,9 +386,10 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 
 	pluginLogFile := filepath.Join(cfg.TaskDir().Dir, "executor.out")
 	executorConfig := &executor.ExecutorConfig{
-		LogFile:     pluginLogFile,
-		LogLevel:    "debug",
-		FSIsolation: true,
+		LogFile:       pluginLogFile,
+		LogLevel:      "debug",
+		FSIsolation:   runtime.GOOS == "linux",
+		UserNamespace: d.userNamespace,
 	}
 
 	exec, pluginClient, err := executor.CreateExecutor(
@@ -336,8 +399,10 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 		return nil, nil, fmt.Errorf("failed to create executor: %v", err)
 	}
 
+	// In a user namespace the task runs as the root of the namespace by
+	// default, as nobody is usually not mapped
 	user := cfg.User
-	if user == "" {
+	if user == "" && d.userNamespace == nil && runtime.GOOS == "linux" {
 		user = "nobody"
 	}
 
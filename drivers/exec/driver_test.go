@@ -51,8 +51,8 @@ func TestExecDriver_Fingerprint_NonLinux(t *testing.T) {
 		t.Parallel()
 	}
 	require := require.New(t)
-	if runtime.GOOS == "linux" {
-		t.Skip("Test only available not on Linux")
+	if runtime.GOOS == "linux" || runtime.GOOS == "windows" {
+		t.Skip("Test only available not on Linux or Windows")
 	}
 
 	d := NewExecDriver(testlog.HCLogger(t))
@@ -0,0 +1,240 @@
+package firecracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// netnsDir is the directory network namespaces created with ip netns
+	// are bound into
+	netnsDir = "/var/run/netns"
+
+	// cniIfName is the name of the interface CNI plugins create in the
+	// network namespace
+	cniIfName = "eth0"
+)
+
+// cniNetworkList is a CNI network configuration list as found in a
+// .conflist file.
+type cniNetworkList struct {
+	CNIVersion string                   `json:"cniVersion"`
+	Name       string                   `json:"name"`
+	Plugins    []map[string]interface{} `json:"plugins"`
+}
+
+// cniInterface is an interface in the result of a CNI ADD command.
+type cniInterface struct {
+	Name    string `json:"name"`
+	Mac     string `json:"mac"`
+	Sandbox string `json:"sandbox"`
+}
+
+// cniIPConfig is an address in the result of a CNI ADD command.
+type cniIPConfig struct {
+	Version   string `json:"version"`
+	Interface *int   `json:"interface"`
+	Address   string `json:"address"`
+	Gateway   string `json:"gateway"`
+}
+
+// cniResult is the result of a CNI ADD command.
+type cniResult struct {
+	CNIVersion string          `json:"cniVersion"`
+	Interfaces []*cniInterface `json:"interfaces"`
+	IPs        []*cniIPConfig  `json:"ips"`
+}
+
+// loadCNINetwork returns the network configuration list with the given name
+// from the .conflist files in dir.
+func loadCNINetwork(dir, name string) (*cniNetworkList, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.conflist"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var list cniNetworkList
+		if err := json.Unmarshal(b, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse CNI config %q: %v", file, err)
+		}
+		if list.Name != name {
+			continue
+		}
+		if len(list.Plugins) == 0 {
+			return nil, fmt.Errorf("CNI network %q in %q has no plugins", name, file)
+		}
+		return &list, nil
+	}
+
+	return nil, fmt.Errorf("CNI network %q not found in %q", name, dir)
+}
+
+// cniRuntime invokes CNI plugins for a VM's network namespace.
+type cniRuntime struct {
+	binDir      string
+	containerID string
+	netnsPath   string
+}
+
+// add runs the ADD command of each plugin in the network list, passing the
+// result of each plugin to the next, and returns the final result.
+func (c *cniRuntime) add(list *cniNetworkList) (*cniResult, error) {
+	var prevResult json.RawMessage
+	for _, plugin := range list.Plugins {
+		out, err := c.exec("ADD", list, plugin, prevResult)
+		if err != nil {
+			return nil, err
+		}
+		prevResult = out
+	}
+
+	var result cniResult
+	if err := json.Unmarshal(prevResult, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse CNI result: %v", err)
+	}
+	return &result, nil
+}
+
+// del runs the DEL command of each plugin in the network list in reverse
+// order.
+func (c *cniRuntime) del(list *cniNetworkList) error {
+	var errs []string
+	for i := len(list.Plugins) - 1; i >= 0; i-- {
+		if _, err := c.exec("DEL", list, list.Plugins[i], nil); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to delete CNI network: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// exec runs a single CNI plugin as described by the CNI specification.
+func (c *cniRuntime) exec(command string, list *cniNetworkList, plugin map[string]interface{}, prevResult json.RawMessage) (json.RawMessage, error) {
+	pluginType, ok := plugin["type"].(string)
+	if !ok || pluginType == "" {
+		return nil, fmt.Errorf("CNI plugin in network %q has no type", list.Name)
+	}
+
+	conf := make(map[string]interface{}, len(plugin)+3)
+	for k, v := range plugin {
+		conf[k] = v
+	}
+	conf["name"] = list.Name
+	conf["cniVersion"] = list.CNIVersion
+	if prevResult != nil {
+		conf["prevResult"] = prevResult
+	}
+	stdin, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(filepath.Join(c.binDir, pluginType))
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"CNI_COMMAND="+command,
+		"CNI_CONTAINERID="+c.containerID,
+		"CNI_NETNS="+c.netnsPath,
+		"CNI_IFNAME="+cniIfName,
+		"CNI_PATH="+c.binDir,
+	)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("CNI plugin %q %s failed: %v: %s%s", pluginType, command,
+			err, strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// vmNetwork is the network configuration of a VM derived from a CNI result.
+type vmNetwork struct {
+	// TapDevice is the tap device in the network namespace the VM's
+	// interface is attached to
+	TapDevice string
+
+	// MacAddress is the MAC address to assign the VM's interface
+	MacAddress string
+
+	// IP, Gateway and Mask configure the VM's interface
+	IP      net.IP
+	Gateway net.IP
+	Mask    net.IPMask
+}
+
+// KernelIPArg returns the kernel command line argument configuring the
+// VM's interface statically at boot.
+func (n *vmNetwork) KernelIPArg() string {
+	gateway := ""
+	if n.Gateway != nil {
+		gateway = n.Gateway.String()
+	}
+	return fmt.Sprintf("ip=%s::%s:%s::eth0:off", n.IP, gateway, net.IP(n.Mask))
+}
+
+// vmNetworkFromResult finds the tap device and address assigned to a VM in
+// a CNI result. The tap device is the interface within the network namespace
+// other than the one CNI created, and plugins redirecting traffic to a tap
+// device report the VM's interface with the VM's ID as its sandbox.
+func vmNetworkFromResult(result *cniResult, netnsPath, vmID string) (*vmNetwork, error) {
+	network := &vmNetwork{}
+	for _, iface := range result.Interfaces {
+		switch {
+		case iface.Sandbox == netnsPath && iface.Name != cniIfName:
+			network.TapDevice = iface.Name
+		case iface.Sandbox == vmID:
+			network.MacAddress = iface.Mac
+		}
+	}
+	if network.TapDevice == "" {
+		return nil, fmt.Errorf("CNI result has no tap device in %q", netnsPath)
+	}
+
+	if len(result.IPs) == 0 {
+		return nil, fmt.Errorf("CNI result has no addresses")
+	}
+	ip, ipNet, err := net.ParseCIDR(result.IPs[0].Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CNI address %q: %v", result.IPs[0].Address, err)
+	}
+	network.IP = ip
+	network.Mask = ipNet.Mask
+	if gw := result.IPs[0].Gateway; gw != "" {
+		network.Gateway = net.ParseIP(gw)
+	}
+	return network, nil
+}
+
+// createNetns creates a named network namespace and returns its path.
+func createNetns(name string) (string, error) {
+	if out, err := exec.Command("ip", "netns", "add", name).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to create network namespace %q: %v: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return filepath.Join(netnsDir, name), nil
+}
+
+// deleteNetns deletes a named network namespace.
+func deleteNetns(name string) error {
+	if out, err := exec.Command("ip", "netns", "delete", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete network namespace %q: %v: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,761 @@
+package firecracker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/drivers/shared/eventer"
+	"github.com/hashicorp/nomad/drivers/shared/executor"
+	"github.com/hashicorp/nomad/helper/pluginutils/loader"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+	pstructs "github.com/hashicorp/nomad/plugins/shared/structs"
+)
+
+const (
+	// pluginName is the name of the plugin
+	pluginName = "firecracker"
+
+	// fingerprintPeriod is the interval at which the driver will send fingerprint responses
+	fingerprintPeriod = 30 * time.Second
+
+	// The keys populated in Node Attributes to indicate presence of the
+	// firecracker driver
+	driverAttr        = "driver.firecracker"
+	driverVersionAttr = "driver.firecracker.version"
+	driverJailerAttr  = "driver.firecracker.jailer"
+
+	// kvmDevice is the device firecracker requires access to
+	kvmDevice = "/dev/kvm"
+
+	// taskHandleVersion is the version of task handle which this driver sets
+	// and understands how to decode driver state
+	taskHandleVersion = 1
+)
+
+var (
+	// PluginID is the firecracker plugin metadata registered in the plugin
+	// catalog.
+	PluginID = loader.PluginID{
+		Name:       pluginName,
+		PluginType: base.PluginTypeDriver,
+	}
+
+	// PluginConfig is the firecracker driver factory function registered in
+	// the plugin catalog.
+	PluginConfig = &loader.InternalPluginConfig{
+		Config:  map[string]interface{}{},
+		Factory: func(l hclog.Logger) interface{} { return NewFirecrackerDriver(l) },
+	}
+
+	versionRegex = regexp.MustCompile(`Firecracker v?(\d[.\d]+)`)
+
+	// pluginInfo is the response returned for the PluginInfo RPC
+	pluginInfo = &base.PluginInfoResponse{
+		Type:              base.PluginTypeDriver,
+		PluginApiVersions: []string{drivers.ApiVersion010},
+		PluginVersion:     "0.1.0",
+		Name:              pluginName,
+	}
+
+	// configSpec is the hcl specification returned by the ConfigSchema RPC
+	// and is used to parse the contents of the 'plugin "firecracker" {...}'
+	// block.
+	// Example:
+	//	plugin "firecracker" {
+	//		config {
+	//		firecracker_path = "/usr/bin/firecracker"
+	//		jailer {
+	//			enabled = true
+	//			uid = 10000
+	//			gid = 10000
+	//		}
+	//		cni {
+	//			config_dir = "/etc/cni/conf.d"
+	//		}
+	//		}
+	//	}
+	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"firecracker_path": hclspec.NewDefault(
+			hclspec.NewAttr("firecracker_path", "string", false),
+			hclspec.NewLiteral(`"firecracker"`),
+		),
+		"jailer": hclspec.NewDefault(hclspec.NewBlock("jailer", false, hclspec.NewObject(map[string]*hclspec.Spec{
+			"enabled": hclspec.NewDefault(
+				hclspec.NewAttr("enabled", "bool", false),
+				hclspec.NewLiteral("true"),
+			),
+			"path": hclspec.NewDefault(
+				hclspec.NewAttr("path", "string", false),
+				hclspec.NewLiteral(`"jailer"`),
+			),
+			"uid": hclspec.NewAttr("uid", "number", false),
+			"gid": hclspec.NewAttr("gid", "number", false),
+			"chroot_base_dir": hclspec.NewDefault(
+				hclspec.NewAttr("chroot_base_dir", "string", false),
+				hclspec.NewLiteral(`"/srv/jailer"`),
+			),
+		})), hclspec.NewLiteral(`{
+			enabled = true
+			path = "jailer"
+			chroot_base_dir = "/srv/jailer"
+		}`)),
+		"cni": hclspec.NewDefault(hclspec.NewBlock("cni", false, hclspec.NewObject(map[string]*hclspec.Spec{
+			"config_dir": hclspec.NewDefault(
+				hclspec.NewAttr("config_dir", "string", false),
+				hclspec.NewLiteral(`"/etc/cni/conf.d"`),
+			),
+			"bin_dir": hclspec.NewDefault(
+				hclspec.NewAttr("bin_dir", "string", false),
+				hclspec.NewLiteral(`"/opt/cni/bin"`),
+			),
+		})), hclspec.NewLiteral(`{
+			config_dir = "/etc/cni/conf.d"
+			bin_dir = "/opt/cni/bin"
+		}`)),
+	})
+
+	// taskConfigSpec is the hcl specification for the driver config section of
+	// a taskConfig within a job. It is returned in the TaskConfigSchema RPC
+	taskConfigSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"kernel_image": hclspec.NewAttr("kernel_image", "string", true),
+		"rootfs_image": hclspec.NewAttr("rootfs_image", "string", true),
+		"boot_args":    hclspec.NewAttr("boot_args", "string", false),
+		"vcpus":        hclspec.NewAttr("vcpus", "number", false),
+		"network":      hclspec.NewAttr("network", "string", false),
+		"port_map":     hclspec.NewBlockAttrs("port_map", "number", false),
+	})
+
+	// capabilities is returned by the Capabilities RPC and indicates what
+	// optional features this driver supports
+	capabilities = &drivers.Capabilities{
+		SendSignals: false,
+		Exec:        false,
+		FSIsolation: drivers.FSIsolationImage,
+	}
+
+	_ drivers.DriverPlugin = (*Driver)(nil)
+)
+
+// Config is the client configuration for the driver
+type Config struct {
+	// FirecrackerPath is the path to the firecracker binary
+	FirecrackerPath string `codec:"firecracker_path"`
+
+	Jailer JailerConfig `codec:"jailer"`
+	CNI    CNIConfig    `codec:"cni"`
+}
+
+// JailerConfig configures running firecracker within the jailer, which
+// isolates each VM in a chroot, namespaces and cgroups as an unprivileged
+// user.
+type JailerConfig struct {
+	Enabled       bool   `codec:"enabled"`
+	Path          string `codec:"path"`
+	UID           int    `codec:"uid"`
+	GID           int    `codec:"gid"`
+	ChrootBaseDir string `codec:"chroot_base_dir"`
+}
+
+// CNIConfig configures where CNI network configurations and plugins are
+// found.
+type CNIConfig struct {
+	ConfigDir string `codec:"config_dir"`
+	BinDir    string `codec:"bin_dir"`
+}
+
+// TaskConfig is the driver configuration of a taskConfig within a job
+type TaskConfig struct {
+	KernelImage string         `codec:"kernel_image"`
+	RootfsImage string         `codec:"rootfs_image"`
+	BootArgs    string         `codec:"boot_args"`
+	Vcpus       int            `codec:"vcpus"`
+	Network     string         `codec:"network"`
+	PortMap     map[string]int `codec:"port_map"`
+}
+
+// TaskState is the state which is encoded in the handle returned in StartTask.
+// This information is needed to rebuild the taskConfig state and handler
+// during recovery.
+type TaskState struct {
+	ReattachConfig *pstructs.ReattachConfig
+	TaskConfig     *drivers.TaskConfig
+	Pid            int
+	StartedAt      time.Time
+	VMID           string
+	Network        string
+	Netns          string
+	ChrootDir      string
+}
+
+// Driver is a driver for running VMs via Firecracker
+type Driver struct {
+	// eventer is used to handle multiplexing of TaskEvents calls such that an
+	// event can be broadcast to all callers
+	eventer *eventer.Eventer
+
+	// config is the driver configuration set by the SetConfig RPC
+	config *Config
+
+	// tasks is the in memory datastore mapping taskIDs to taskHandles
+	tasks *taskStore
+
+	// ctx is the context for the driver. It is passed to other subsystems to
+	// coordinate shutdown
+	ctx context.Context
+
+	// nomadConf is the client agent's configuration
+	nomadConfig *base.ClientDriverConfig
+
+	// signalShutdown is called when the driver is shutting down and cancels the
+	// ctx passed to any subsystems
+	signalShutdown context.CancelFunc
+
+	// logger will log to the Nomad agent
+	logger hclog.Logger
+}
+
+func NewFirecrackerDriver(logger hclog.Logger) drivers.DriverPlugin {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger = logger.Named(pluginName)
+	return &Driver{
+		eventer:        eventer.NewEventer(ctx, logger),
+		config:         &Config{},
+		tasks:          newTaskStore(),
+		ctx:            ctx,
+		signalShutdown: cancel,
+		logger:         logger,
+	}
+}
+
+func (d *Driver) PluginInfo() (*base.PluginInfoResponse, error) {
+	return pluginInfo, nil
+}
+
+func (d *Driver) ConfigSchema() (*hclspec.Spec, error) {
+	return configSpec, nil
+}
+
+func (d *Driver) SetConfig(cfg *base.Config) error {
+	var config Config
+	if len(cfg.PluginConfig) != 0 {
+		if err := base.MsgPackDecode(cfg.PluginConfig, &config); err != nil {
+			return err
+		}
+	}
+
+	if config.Jailer.Enabled && !filepath.IsAbs(config.Jailer.ChrootBaseDir) {
+		return fmt.Errorf("jailer chroot_base_dir must be an absolute path")
+	}
+
+	d.config = &config
+	if cfg.AgentConfig != nil {
+		d.nomadConfig = cfg.AgentConfig.Driver
+	}
+	return nil
+}
+
+func (d *Driver) TaskConfigSchema() (*hclspec.Spec, error) {
+	return taskConfigSpec, nil
+}
+
+func (d *Driver) Capabilities() (*drivers.Capabilities, error) {
+	return capabilities, nil
+}
+
+func (d *Driver) Fingerprint(ctx context.Context) (<-chan *drivers.Fingerprint, error) {
+	ch := make(chan *drivers.Fingerprint)
+	go d.handleFingerprint(ctx, ch)
+	return ch, nil
+}
+
+func (d *Driver) handleFingerprint(ctx context.Context, ch chan *drivers.Fingerprint) {
+	ticker := time.NewTimer(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			ticker.Reset(fingerprintPeriod)
+			ch <- d.buildFingerprint()
+		}
+	}
+}
+
+func (d *Driver) buildFingerprint() *drivers.Fingerprint {
+	fingerprint := &drivers.Fingerprint{
+		Attributes:        map[string]*pstructs.Attribute{},
+		Health:            drivers.HealthStateHealthy,
+		HealthDescription: drivers.DriverHealthy,
+	}
+
+	// Firecracker, and creating network namespaces for its VMs, requires
+	// root
+	if syscall.Geteuid() != 0 {
+		fingerprint.Health = drivers.HealthStateUndetected
+		fingerprint.HealthDescription = drivers.DriverRequiresRootMessage
+		return fingerprint
+	}
+
+	if _, err := os.Stat(kvmDevice); err != nil {
+		fingerprint.Health = drivers.HealthStateUndetected
+		fingerprint.HealthDescription = fmt.Sprintf("KVM is not available: %v", err)
+		return fingerprint
+	}
+
+	outBytes, err := exec.Command(d.config.firecrackerPath(), "--version").Output()
+	if err != nil {
+		fingerprint.Health = drivers.HealthStateUndetected
+		fingerprint.HealthDescription = ""
+		return fingerprint
+	}
+	out := strings.TrimSpace(string(outBytes))
+
+	matches := versionRegex.FindStringSubmatch(out)
+	if len(matches) != 2 {
+		fingerprint.Health = drivers.HealthStateUndetected
+		fingerprint.HealthDescription = fmt.Sprintf("Failed to parse firecracker version from %v", out)
+		return fingerprint
+	}
+
+	if d.config.Jailer.Enabled {
+		if d.config.Jailer.UID == 0 || d.config.Jailer.GID == 0 {
+			fingerprint.Health = drivers.HealthStateUnhealthy
+			fingerprint.HealthDescription = "Jailer uid and gid must be set to an unprivileged user and group"
+			return fingerprint
+		}
+		if _, err := exec.LookPath(d.config.Jailer.Path); err != nil {
+			fingerprint.Health = drivers.HealthStateUnhealthy
+			fingerprint.HealthDescription = fmt.Sprintf("Jailer is enabled but not found: %v", err)
+			return fingerprint
+		}
+	}
+
+	fingerprint.Attributes[driverAttr] = pstructs.NewBoolAttribute(true)
+	fingerprint.Attributes[driverVersionAttr] = pstructs.NewStringAttribute(matches[1])
+	fingerprint.Attributes[driverJailerAttr] = pstructs.NewBoolAttribute(d.config.Jailer.Enabled)
+	return fingerprint
+}
+
+// firecrackerPath returns the configured firecracker binary.
+func (c *Config) firecrackerPath() string {
+	if c.FirecrackerPath == "" {
+		return "firecracker"
+	}
+	return c.FirecrackerPath
+}
+
+func (d *Driver) RecoverTask(handle *drivers.TaskHandle) error {
+	if handle == nil {
+		return fmt.Errorf("error: handle cannot be nil")
+	}
+
+	// If already attached to handle there's nothing to recover.
+	if _, ok := d.tasks.Get(handle.Config.ID); ok {
+		d.logger.Trace("nothing to recover; task already exists",
+			"task_id", handle.Config.ID,
+			"task_name", handle.Config.Name,
+		)
+		return nil
+	}
+
+	var taskState TaskState
+	if err := handle.GetDriverState(&taskState); err != nil {
+		d.logger.Error("failed to decode taskConfig state from handle", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to decode taskConfig state from handle: %v", err)
+	}
+
+	plugRC, err := pstructs.ReattachConfigToGoPlugin(taskState.ReattachConfig)
+	if err != nil {
+		d.logger.Error("failed to build ReattachConfig from taskConfig state", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to build ReattachConfig from taskConfig state: %v", err)
+	}
+
+	execImpl, pluginClient, err := executor.ReattachToExecutor(plugRC,
+		d.logger.With("task_name", handle.Config.Name, "alloc_id", handle.Config.AllocID))
+	if err != nil {
+		d.logger.Error("failed to reattach to executor", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to reattach to executor: %v", err)
+	}
+
+	h := &taskHandle{
+		exec:         execImpl,
+		pid:          taskState.Pid,
+		pluginClient: pluginClient,
+		logger:       d.logger,
+		vmID:         taskState.VMID,
+		network:      taskState.Network,
+		netns:        taskState.Netns,
+		chrootDir:    taskState.ChrootDir,
+		taskConfig:   taskState.TaskConfig,
+		procState:    drivers.TaskStateRunning,
+		startedAt:    taskState.StartedAt,
+		exitResult:   &drivers.ExitResult{},
+	}
+
+	d.tasks.Set(taskState.TaskConfig.ID, h)
+
+	go h.run()
+	return nil
+}
+
+func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drivers.DriverNetwork, error) {
+	if _, ok := d.tasks.Get(cfg.ID); ok {
+		return nil, nil, fmt.Errorf("taskConfig with ID '%s' already started", cfg.ID)
+	}
+
+	var driverConfig TaskConfig
+	if err := cfg.DecodeDriverConfig(&driverConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode driver config: %v", err)
+	}
+
+	handle := drivers.NewTaskHandle(taskHandleVersion)
+	handle.Config = cfg
+
+	// The kernel and root filesystem images are expected to be downloaded
+	// into the task directory by artifacts
+	taskDir := cfg.TaskDir().Dir
+	kernel, err := taskFilePath(taskDir, driverConfig.KernelImage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid kernel_image: %v", err)
+	}
+	rootfs, err := taskFilePath(taskDir, driverConfig.RootfsImage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rootfs_image: %v", err)
+	}
+
+	mb := cfg.Resources.NomadResources.Memory.MemoryMB
+	if mb < 128 {
+		return nil, nil, fmt.Errorf("firecracker requires at least 128MB of memory")
+	}
+	if driverConfig.Vcpus < 0 || driverConfig.Vcpus > 32 {
+		return nil, nil, fmt.Errorf("vcpus must be between 1 and 32")
+	}
+
+	if d.config.Jailer.Enabled && (d.config.Jailer.UID == 0 || d.config.Jailer.GID == 0) {
+		return nil, nil, fmt.Errorf("jailer uid and gid must be set to an unprivileged user and group")
+	}
+
+	firecrackerPath, err := GetAbsolutePath(d.config.firecrackerPath())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := &taskHandle{
+		vmID:       uuid.Generate(),
+		network:    driverConfig.Network,
+		taskConfig: cfg,
+		procState:  drivers.TaskStateRunning,
+		logger:     d.logger.With("task_name", cfg.Name, "alloc_id", cfg.AllocID),
+	}
+
+	// Attach the VM to its CNI network, which must provide a tap device for
+	// firecracker within the network namespace
+	var network *vmNetwork
+	var netnsPath string
+	if driverConfig.Network != "" {
+		network, netnsPath, err = d.setupNetwork(h, driverConfig.Network)
+		if err != nil {
+			d.cleanupVM(h)
+			return nil, nil, err
+		}
+	}
+
+	var args []string
+	if d.config.Jailer.Enabled {
+		jailerPath, err := GetAbsolutePath(d.config.Jailer.Path)
+		if err != nil {
+			d.cleanupVM(h)
+			return nil, nil, err
+		}
+
+		uid, gid := d.config.Jailer.UID, d.config.Jailer.GID
+		h.chrootDir = jailerChrootDir(d.config.Jailer.ChrootBaseDir, firecrackerPath, h.vmID)
+		if err := prepareChroot(h.chrootDir, kernel, rootfs, uid, gid); err != nil {
+			d.cleanupVM(h)
+			return nil, nil, err
+		}
+
+		vmConfig := newVMConfig(vmKernelFile, vmRootfsFile, &driverConfig, mb, network)
+		if err := writeVMConfig(filepath.Join(h.chrootDir, vmConfigFile), vmConfig, uid, gid); err != nil {
+			d.cleanupVM(h)
+			return nil, nil, err
+		}
+
+		args = append([]string{jailerPath}, jailerArgs(&d.config.Jailer, firecrackerPath, h.vmID, netnsPath)...)
+	} else {
+		vmConfig := newVMConfig(kernel, rootfs, &driverConfig, mb, network)
+		if err := writeVMConfig(filepath.Join(taskDir, vmConfigFile), vmConfig, os.Getuid(), os.Getgid()); err != nil {
+			d.cleanupVM(h)
+			return nil, nil, err
+		}
+
+		args = append([]string{firecrackerPath}, firecrackerArgs(taskDir)...)
+		if h.netns != "" {
+			args = append([]string{"ip", "netns", "exec", h.netns}, args...)
+		}
+	}
+	d.logger.Debug("starting firecracker VM", "vm_id", h.vmID, "args", strings.Join(args, " "))
+
+	pluginLogFile := filepath.Join(taskDir, fmt.Sprintf("%s-executor.out", cfg.Name))
+	executorConfig := &executor.ExecutorConfig{
+		LogFile:  pluginLogFile,
+		LogLevel: "debug",
+	}
+
+	execImpl, pluginClient, err := executor.CreateExecutor(h.logger, d.nomadConfig, executorConfig)
+	if err != nil {
+		d.cleanupVM(h)
+		return nil, nil, err
+	}
+
+	execCmd := &executor.ExecCommand{
+		Cmd:        args[0],
+		Args:       args[1:],
+		Env:        cfg.EnvList(),
+		TaskDir:    taskDir,
+		StdoutPath: cfg.StdoutPath,
+		StderrPath: cfg.StderrPath,
+	}
+	ps, err := execImpl.Launch(execCmd)
+	if err != nil {
+		pluginClient.Kill()
+		d.cleanupVM(h)
+		return nil, nil, err
+	}
+	d.logger.Debug("started firecracker VM", "vm_id", h.vmID)
+
+	h.exec = execImpl
+	h.pid = ps.Pid
+	h.pluginClient = pluginClient
+	h.startedAt = time.Now().Round(time.Millisecond)
+
+	driverState := TaskState{
+		ReattachConfig: pstructs.ReattachConfigFromGoPlugin(pluginClient.ReattachConfig()),
+		Pid:            ps.Pid,
+		TaskConfig:     cfg,
+		StartedAt:      h.startedAt,
+		VMID:           h.vmID,
+		Network:        h.network,
+		Netns:          h.netns,
+		ChrootDir:      h.chrootDir,
+	}
+
+	if err := handle.SetDriverState(&driverState); err != nil {
+		d.logger.Error("failed to start task, error setting driver state", "error", err)
+		execImpl.Shutdown("", 0)
+		pluginClient.Kill()
+		d.cleanupVM(h)
+		return nil, nil, fmt.Errorf("failed to set driver state: %v", err)
+	}
+
+	d.tasks.Set(cfg.ID, h)
+	go h.run()
+
+	var driverNetwork *drivers.DriverNetwork
+	if network != nil {
+		driverNetwork = &drivers.DriverNetwork{
+			PortMap:       driverConfig.PortMap,
+			IP:            network.IP.String(),
+			AutoAdvertise: true,
+		}
+	}
+	return handle, driverNetwork, nil
+}
+
+// setupNetwork creates a network namespace for the VM and attaches it to
+// the named CNI network, returning the VM's network configuration and the
+// path of the namespace.
+func (d *Driver) setupNetwork(h *taskHandle, name string) (*vmNetwork, string, error) {
+	list, err := loadCNINetwork(d.config.CNI.ConfigDir, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	netns := "nomad-" + h.vmID
+	netnsPath, err := createNetns(netns)
+	if err != nil {
+		return nil, "", err
+	}
+	h.netns = netns
+
+	cni := &cniRuntime{
+		binDir:      d.config.CNI.BinDir,
+		containerID: h.vmID,
+		netnsPath:   netnsPath,
+	}
+	result, err := cni.add(list)
+	if err != nil {
+		return nil, "", err
+	}
+
+	network, err := vmNetworkFromResult(result, netnsPath, h.vmID)
+	if err != nil {
+		return nil, "", err
+	}
+	return network, netnsPath, nil
+}
+
+// cleanupVM removes the VM from its CNI network and deletes its network
+// namespace and jailer chroot. Errors are logged rather than returned so
+// that as much as possible is cleaned up.
+func (d *Driver) cleanupVM(h *taskHandle) {
+	if h.netns != "" {
+		netnsPath := filepath.Join(netnsDir, h.netns)
+		if list, err := loadCNINetwork(d.config.CNI.ConfigDir, h.network); err != nil {
+			h.logger.Warn("failed to load CNI network", "network", h.network, "error", err)
+		} else {
+			cni := &cniRuntime{
+				binDir:      d.config.CNI.BinDir,
+				containerID: h.vmID,
+				netnsPath:   netnsPath,
+			}
+			if err := cni.del(list); err != nil {
+				h.logger.Warn("failed to remove VM from CNI network", "network", h.network, "error", err)
+			}
+		}
+
+		if err := deleteNetns(h.netns); err != nil {
+			h.logger.Warn("failed to delete network namespace", "error", err)
+		}
+	}
+
+	if h.chrootDir != "" {
+		// Remove the VM's directory containing the chroot
+		if err := os.RemoveAll(filepath.Dir(h.chrootDir)); err != nil {
+			h.logger.Warn("failed to remove jailer chroot", "error", err)
+		}
+	}
+}
+
+func (d *Driver) WaitTask(ctx context.Context, taskID string) (<-chan *drivers.ExitResult, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	ch := make(chan *drivers.ExitResult)
+	go d.handleWait(ctx, handle, ch)
+
+	return ch, nil
+}
+
+func (d *Driver) StopTask(taskID string, timeout time.Duration, signal string) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	if err := handle.exec.Shutdown(signal, timeout); err != nil {
+		if handle.pluginClient.Exited() {
+			return nil
+		}
+		return fmt.Errorf("executor Shutdown failed: %v", err)
+	}
+
+	return nil
+}
+
+func (d *Driver) DestroyTask(taskID string, force bool) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	if handle.IsRunning() && !force {
+		return fmt.Errorf("cannot destroy running task")
+	}
+
+	if !handle.pluginClient.Exited() {
+		if handle.IsRunning() {
+			if err := handle.exec.Shutdown("", 0); err != nil {
+				handle.logger.Error("destroying executor failed", "err", err)
+			}
+		}
+
+		handle.pluginClient.Kill()
+	}
+
+	d.cleanupVM(handle)
+	d.tasks.Delete(taskID)
+	return nil
+}
+
+func (d *Driver) InspectTask(taskID string) (*drivers.TaskStatus, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	return handle.TaskStatus(), nil
+}
+
+func (d *Driver) TaskStats(ctx context.Context, taskID string, interval time.Duration) (<-chan *drivers.TaskResourceUsage, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	return handle.exec.Stats(ctx, interval)
+}
+
+func (d *Driver) TaskEvents(ctx context.Context) (<-chan *drivers.TaskEvent, error) {
+	return d.eventer.TaskEvents(ctx)
+}
+
+func (d *Driver) SignalTask(taskID string, signal string) error {
+	return fmt.Errorf("Firecracker driver can't signal commands")
+}
+
+func (d *Driver) ExecTask(taskID string, cmdArgs []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
+	return nil, fmt.Errorf("Firecracker driver can't execute commands")
+}
+
+// GetAbsolutePath returns the absolute path of the passed binary by resolving
+// it in the path and following symlinks.
+func GetAbsolutePath(bin string) (string, error) {
+	lp, err := exec.LookPath(bin)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path to %q executable: %v", bin, err)
+	}
+
+	return filepath.EvalSymlinks(lp)
+}
+
+func (d *Driver) handleWait(ctx context.Context, handle *taskHandle, ch chan *drivers.ExitResult) {
+	defer close(ch)
+	var result *drivers.ExitResult
+	ps, err := handle.exec.Wait(ctx)
+	if err != nil {
+		result = &drivers.ExitResult{
+			Err: fmt.Errorf("executor: error waiting on process: %v", err),
+		}
+	} else {
+		result = &drivers.ExitResult{
+			ExitCode: ps.ExitCode,
+			Signal:   ps.Signal,
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-d.ctx.Done():
+	case ch <- result:
+	}
+}
+
+func (d *Driver) Shutdown() {
+	d.signalShutdown()
+}
@@ -0,0 +1,248 @@
+package firecracker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	basePlug "github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	dtestutil "github.com/hashicorp/nomad/plugins/drivers/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+var _ drivers.DriverPlugin = (*Driver)(nil)
+
+func TestFirecrackerVersionRegex(t *testing.T) {
+	t.Parallel()
+
+	matches := versionRegex.FindStringSubmatch("Firecracker v0.21.1\n\nSupported snapshot data format versions: ")
+	require.Len(t, matches, 2)
+	require.Equal(t, "0.21.1", matches[1])
+
+	require.Empty(t, versionRegex.FindStringSubmatch("QEMU emulator version 2.11.1"))
+}
+
+// Tests setting driver config options
+func TestFirecrackerDriver_SetConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	d := NewFirecrackerDriver(testlog.HCLogger(t))
+	harness := dtestutil.NewDriverHarness(t, d)
+
+	config := &Config{
+		FirecrackerPath: "/usr/local/bin/firecracker",
+		Jailer: JailerConfig{
+			Enabled:       true,
+			Path:          "/usr/local/bin/jailer",
+			UID:           10000,
+			GID:           10000,
+			ChrootBaseDir: "/srv/jailer",
+		},
+		CNI: CNIConfig{
+			ConfigDir: "/etc/cni/conf.d",
+			BinDir:    "/opt/cni/bin",
+		},
+	}
+
+	var data []byte
+	require.NoError(basePlug.MsgPackEncode(&data, config))
+	require.NoError(harness.SetConfig(&basePlug.Config{PluginConfig: data}))
+	require.Exactly(config, d.(*Driver).config)
+
+	// A relative chroot is rejected
+	config.Jailer.ChrootBaseDir = "jailer"
+	data = nil
+	require.NoError(basePlug.MsgPackEncode(&data, config))
+	require.Error(harness.SetConfig(&basePlug.Config{PluginConfig: data}))
+}
+
+func TestFirecrackerDriver_VMConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	driverConfig := &TaskConfig{Vcpus: 2}
+	network := &vmNetwork{
+		TapDevice:  "tap0",
+		MacAddress: "aa:fc:00:00:00:01",
+		IP:         net.ParseIP("10.168.0.2"),
+		Gateway:    net.ParseIP("10.168.0.1"),
+		Mask:       net.CIDRMask(24, 32),
+	}
+
+	config := newVMConfig(vmKernelFile, vmRootfsFile, driverConfig, 256, network)
+	b, err := json.Marshal(config)
+	require.NoError(err)
+
+	expected := `{
+		"boot-source": {
+			"kernel_image_path": "vmlinux",
+			"boot_args": "console=ttyS0 reboot=k panic=1 pci=off ip=10.168.0.2::10.168.0.1:255.255.255.0::eth0:off"
+		},
+		"drives": [{
+			"drive_id": "rootfs",
+			"path_on_host": "rootfs.ext4",
+			"is_root_device": true,
+			"is_read_only": false
+		}],
+		"machine-config": {
+			"vcpu_count": 2,
+			"mem_size_mib": 256,
+			"ht_enabled": false
+		},
+		"network-interfaces": [{
+			"iface_id": "eth0",
+			"guest_mac": "aa:fc:00:00:00:01",
+			"host_dev_name": "tap0"
+		}]
+	}`
+	require.JSONEq(expected, string(b))
+
+	// Without a network no interfaces are configured
+	config = newVMConfig("/vmlinux", "/rootfs.ext4", &TaskConfig{BootArgs: "console=ttyS0"}, 128, nil)
+	require.Equal("console=ttyS0", config.BootSource.BootArgs)
+	require.Equal(1, config.MachineConfig.VcpuCount)
+	require.Empty(config.NetworkInterfaces)
+}
+
+func TestFirecrackerDriver_JailerArgs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	config := &JailerConfig{
+		Enabled:       true,
+		UID:           10000,
+		GID:           10001,
+		ChrootBaseDir: "/srv/jailer",
+	}
+
+	args := jailerArgs(config, "/usr/bin/firecracker", "vm1", "/var/run/netns/nomad-vm1")
+	expected := []string{
+		"--id", "vm1",
+		"--exec-file", "/usr/bin/firecracker",
+		"--uid", "10000",
+		"--gid", "10001",
+		"--chroot-base-dir", "/srv/jailer",
+		"--netns", "/var/run/netns/nomad-vm1",
+		"--", "--config-file", "firecracker.json",
+	}
+	require.Equal(expected, args)
+	require.Equal("/srv/jailer/firecracker/vm1/root", jailerChrootDir(config.ChrootBaseDir, "/usr/bin/firecracker", "vm1"))
+}
+
+func TestFirecrackerDriver_TaskFilePath(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "firecracker")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(os.MkdirAll(filepath.Join(dir, "local"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "local", "vmlinux"), []byte("kernel"), 0644))
+
+	path, err := taskFilePath(dir, "local/vmlinux")
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "local", "vmlinux"), path)
+
+	_, err = taskFilePath(dir, "local/missing")
+	require.Error(err)
+
+	_, err = taskFilePath(dir, "../etc/passwd")
+	require.Error(err)
+	require.Contains(err.Error(), "escapes the task directory")
+}
+
+func TestFirecrackerDriver_LoadCNINetwork(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "firecracker")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	conf := `{
+		"cniVersion": "0.4.0",
+		"name": "fcnet",
+		"plugins": [
+			{"type": "ptp", "ipam": {"type": "host-local", "subnet": "192.168.127.0/24"}},
+			{"type": "tc-redirect-tap"}
+		]
+	}`
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "10-fcnet.conflist"), []byte(conf), 0644))
+
+	list, err := loadCNINetwork(dir, "fcnet")
+	require.NoError(err)
+	require.Equal("0.4.0", list.CNIVersion)
+	require.Len(list.Plugins, 2)
+	require.Equal("tc-redirect-tap", list.Plugins[1]["type"])
+
+	_, err = loadCNINetwork(dir, "other")
+	require.Error(err)
+	require.Contains(err.Error(), "not found")
+}
+
+func TestFirecrackerDriver_CNIAdd(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "firecracker")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// The fake plugin records its stdin and environment and reports a
+	// result including a tap device in the namespace
+	result := `{
+		"cniVersion": "0.4.0",
+		"interfaces": [
+			{"name": "veth0", "mac": "12:34:56:78:9a:bc"},
+			{"name": "eth0", "mac": "12:34:56:78:9a:bd", "sandbox": "/var/run/netns/test"},
+			{"name": "tap0", "mac": "12:34:56:78:9a:be", "sandbox": "/var/run/netns/test"},
+			{"name": "eth0", "mac": "aa:fc:00:00:00:01", "sandbox": "vm1"}
+		],
+		"ips": [{"version": "4", "interface": 1, "address": "192.168.127.2/24", "gateway": "192.168.127.1"}]
+	}`
+	plugin := "#!/bin/sh\ncat > " + filepath.Join(dir, "stdin") + "\n" +
+		"echo $CNI_COMMAND $CNI_CONTAINERID $CNI_NETNS $CNI_IFNAME > " + filepath.Join(dir, "env") + "\n" +
+		"cat <<'EOF'\n" + result + "\nEOF\n"
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "fake"), []byte(plugin), 0755))
+
+	list := &cniNetworkList{
+		CNIVersion: "0.4.0",
+		Name:       "fcnet",
+		Plugins:    []map[string]interface{}{{"type": "fake"}},
+	}
+	cni := &cniRuntime{
+		binDir:      dir,
+		containerID: "vm1",
+		netnsPath:   "/var/run/netns/test",
+	}
+
+	res, err := cni.add(list)
+	require.NoError(err)
+
+	env, err := ioutil.ReadFile(filepath.Join(dir, "env"))
+	require.NoError(err)
+	require.Equal("ADD vm1 /var/run/netns/test eth0\n", string(env))
+
+	stdin, err := ioutil.ReadFile(filepath.Join(dir, "stdin"))
+	require.NoError(err)
+	require.JSONEq(`{"cniVersion": "0.4.0", "name": "fcnet", "type": "fake"}`, string(stdin))
+
+	network, err := vmNetworkFromResult(res, "/var/run/netns/test", "vm1")
+	require.NoError(err)
+	require.Equal("tap0", network.TapDevice)
+	require.Equal("aa:fc:00:00:00:01", network.MacAddress)
+	require.Equal("192.168.127.2", network.IP.String())
+	require.Equal("192.168.127.1", network.Gateway.String())
+
+	// A result without a tap device can't be used by firecracker
+	res.Interfaces = res.Interfaces[:2]
+	_, err = vmNetworkFromResult(res, "/var/run/netns/test", "vm1")
+	require.Error(err)
+}
@@ -0,0 +1,201 @@
+package firecracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// vmConfigFile is the name of the firecracker configuration file
+	vmConfigFile = "firecracker.json"
+
+	// vmAPISocket is the name of the firecracker API socket created in the
+	// task directory when the jailer is disabled
+	vmAPISocket = "firecracker.sock"
+
+	// vmKernelFile and vmRootfsFile are the names the kernel and root
+	// filesystem images are given within the jailer's chroot
+	vmKernelFile = "vmlinux"
+	vmRootfsFile = "rootfs.ext4"
+
+	// defaultBootArgs are the kernel arguments used when the task does not
+	// specify any
+	defaultBootArgs = "console=ttyS0 reboot=k panic=1 pci=off"
+)
+
+// vmConfig is the firecracker configuration file format accepted by the
+// --config-file flag.
+type vmConfig struct {
+	BootSource        vmBootSource          `json:"boot-source"`
+	Drives            []*vmDrive            `json:"drives"`
+	MachineConfig     vmMachineConfig       `json:"machine-config"`
+	NetworkInterfaces []*vmNetworkInterface `json:"network-interfaces,omitempty"`
+}
+
+type vmBootSource struct {
+	KernelImagePath string `json:"kernel_image_path"`
+	BootArgs        string `json:"boot_args"`
+}
+
+type vmDrive struct {
+	DriveID      string `json:"drive_id"`
+	PathOnHost   string `json:"path_on_host"`
+	IsRootDevice bool   `json:"is_root_device"`
+	IsReadOnly   bool   `json:"is_read_only"`
+}
+
+type vmMachineConfig struct {
+	VcpuCount  int  `json:"vcpu_count"`
+	MemSizeMib int  `json:"mem_size_mib"`
+	HtEnabled  bool `json:"ht_enabled"`
+}
+
+type vmNetworkInterface struct {
+	IfaceID     string `json:"iface_id"`
+	GuestMac    string `json:"guest_mac,omitempty"`
+	HostDevName string `json:"host_dev_name"`
+}
+
+// newVMConfig builds the firecracker configuration for a VM booting the
+// given kernel and root filesystem. The network may be nil if the VM has no
+// network interface.
+func newVMConfig(kernel, rootfs string, driverConfig *TaskConfig, memoryMB int64, network *vmNetwork) *vmConfig {
+	bootArgs := driverConfig.BootArgs
+	if bootArgs == "" {
+		bootArgs = defaultBootArgs
+	}
+
+	vcpus := driverConfig.Vcpus
+	if vcpus == 0 {
+		vcpus = 1
+	}
+
+	config := &vmConfig{
+		BootSource: vmBootSource{
+			KernelImagePath: kernel,
+			BootArgs:        bootArgs,
+		},
+		Drives: []*vmDrive{
+			{
+				DriveID:      "rootfs",
+				PathOnHost:   rootfs,
+				IsRootDevice: true,
+			},
+		},
+		MachineConfig: vmMachineConfig{
+			VcpuCount:  vcpus,
+			MemSizeMib: int(memoryMB),
+		},
+	}
+
+	if network != nil {
+		config.BootSource.BootArgs = bootArgs + " " + network.KernelIPArg()
+		config.NetworkInterfaces = []*vmNetworkInterface{
+			{
+				IfaceID:     "eth0",
+				GuestMac:    network.MacAddress,
+				HostDevName: network.TapDevice,
+			},
+		}
+	}
+
+	return config
+}
+
+// writeVMConfig writes the firecracker configuration to path.
+func writeVMConfig(path string, config *vmConfig, uid, gid int) error {
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("failed to write firecracker config: %v", err)
+	}
+	return os.Chown(path, uid, gid)
+}
+
+// jailerChrootDir returns the directory the jailer chroots a VM into.
+func jailerChrootDir(baseDir, firecrackerPath, vmID string) string {
+	return filepath.Join(baseDir, filepath.Base(firecrackerPath), vmID, "root")
+}
+
+// jailerArgs returns the arguments to the jailer to run firecracker with the
+// configuration file at the root of its chroot.
+func jailerArgs(config *JailerConfig, firecrackerPath, vmID, netnsPath string) []string {
+	args := []string{
+		"--id", vmID,
+		"--exec-file", firecrackerPath,
+		"--uid", strconv.Itoa(config.UID),
+		"--gid", strconv.Itoa(config.GID),
+		"--chroot-base-dir", config.ChrootBaseDir,
+	}
+	if netnsPath != "" {
+		args = append(args, "--netns", netnsPath)
+	}
+	return append(args, "--", "--config-file", vmConfigFile)
+}
+
+// firecrackerArgs returns the arguments to run firecracker directly, without
+// the jailer, with its configuration file and API socket in dir.
+func firecrackerArgs(dir string) []string {
+	return []string{
+		"--api-sock", filepath.Join(dir, vmAPISocket),
+		"--config-file", filepath.Join(dir, vmConfigFile),
+	}
+}
+
+// prepareChroot places the kernel and root filesystem images into the
+// jailer's chroot so firecracker can access them once jailed. The root
+// filesystem is copied rather than linked since the VM writes to it.
+func prepareChroot(dir, kernel, rootfs string, uid, gid int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create jailer chroot: %v", err)
+	}
+
+	if err := os.Link(kernel, filepath.Join(dir, vmKernelFile)); err != nil {
+		if err := copyFile(kernel, filepath.Join(dir, vmKernelFile), uid, gid); err != nil {
+			return err
+		}
+	}
+	return copyFile(rootfs, filepath.Join(dir, vmRootfsFile), uid, gid)
+}
+
+// copyFile copies src to dst, owned by the given user and group.
+func copyFile(src, dst string, uid, gid int) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %v", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %v", dst, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to copy %q to %q: %v", src, dst, err)
+	}
+	return out.Chown(uid, gid)
+}
+
+// taskFilePath resolves a path from the task configuration, which is
+// relative to the task directory, ensuring it does not escape the task
+// directory.
+func taskFilePath(taskDir, path string) (string, error) {
+	full := filepath.Join(taskDir, path)
+	if full != taskDir && !strings.HasPrefix(full, taskDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q escapes the task directory", path)
+	}
+	if _, err := os.Stat(full); err != nil {
+		return "", fmt.Errorf("failed to find %q: %v", path, err)
+	}
+	return full, nil
+}
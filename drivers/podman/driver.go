@@ -0,0 +1,1021 @@
+package podman
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/taskenv"
+	"github.com/hashicorp/nomad/drivers/shared/eventer"
+	"github.com/hashicorp/nomad/drivers/shared/executor"
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/pluginutils/loader"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+	pstructs "github.com/hashicorp/nomad/plugins/shared/structs"
+)
+
+const (
+	// pluginName is the name of the plugin
+	pluginName = "podman"
+
+	// fingerprintPeriod is the interval at which the driver will send fingerprint responses
+	fingerprintPeriod = 30 * time.Second
+
+	// podmanCmd is the command podman is installed as.
+	podmanCmd = "podman"
+
+	// pullTimeout is the length of time an image pull may take before it is
+	// aborted.
+	pullTimeout = 5 * time.Minute
+
+	// networkDeadline is how long to wait for container network
+	// information to become available.
+	networkDeadline = 30 * time.Second
+
+	// taskHandleVersion is the version of task handle which this driver sets
+	// and understands how to decode driver state
+	taskHandleVersion = 1
+)
+
+var (
+	// PluginID is the podman plugin metadata registered in the plugin
+	// catalog.
+	PluginID = loader.PluginID{
+		Name:       pluginName,
+		PluginType: base.PluginTypeDriver,
+	}
+
+	// PluginConfig is the podman factory function registered in the
+	// plugin catalog.
+	PluginConfig = &loader.InternalPluginConfig{
+		Config:  map[string]interface{}{},
+		Factory: func(l hclog.Logger) interface{} { return NewPodmanDriver(l) },
+	}
+
+	// pluginInfo is the response returned for the PluginInfo RPC
+	pluginInfo = &base.PluginInfoResponse{
+		Type:              base.PluginTypeDriver,
+		PluginApiVersions: []string{drivers.ApiVersion010},
+		PluginVersion:     "0.1.0",
+		Name:              pluginName,
+	}
+
+	// configSpec is the hcl specification returned by the ConfigSchema RPC
+	// and is used to parse the contents of the 'plugin "podman" {...}' block.
+	// Example:
+	//	plugin "podman" {
+	//		config {
+	//		gc {
+	//			container = true
+	//			prune {
+	//				enabled = true
+	//				max_disk_usage_mb = 20480
+	//			}
+	//		}
+	//		volumes {
+	//			enabled = true
+	//			selinuxlabel = "z"
+	//		}
+	//		allow_privileged = false
+	//		}
+	//	}
+	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		// garbage collection options
+		// default needed for both if the gc {...} block is not set and
+		// if the default fields are missing
+		"gc": hclspec.NewDefault(hclspec.NewBlock("gc", false, hclspec.NewObject(map[string]*hclspec.Spec{
+			"container": hclspec.NewDefault(
+				hclspec.NewAttr("container", "bool", false),
+				hclspec.NewLiteral("true"),
+			),
+			"prune": imagegc.ConfigSpec,
+		})), hclspec.NewLiteral(`{
+			container = true
+		}`)),
+
+		// podman volume options
+		// defaulted needed for both if the volumes {...} block is not set and
+		// if the default fields are missing
+		"volumes": hclspec.NewDefault(hclspec.NewBlock("volumes", false, hclspec.NewObject(map[string]*hclspec.Spec{
+			"enabled": hclspec.NewDefault(
+				hclspec.NewAttr("enabled", "bool", false),
+				hclspec.NewLiteral("true"),
+			),
+			"selinuxlabel": hclspec.NewAttr("selinuxlabel", "string", false),
+		})), hclspec.NewLiteral("{ enabled = true }")),
+		"allow_privileged": hclspec.NewAttr("allow_privileged", "bool", false),
+	})
+
+	// taskConfigSpec is the hcl specification for the driver config section of
+	// a taskConfig within a job. It is returned in the TaskConfigSchema RPC
+	taskConfigSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"image":        hclspec.NewAttr("image", "string", true),
+		"command":      hclspec.NewAttr("command", "string", false),
+		"args":         hclspec.NewAttr("args", "list(string)", false),
+		"entrypoint":   hclspec.NewAttr("entrypoint", "string", false),
+		"dns_servers":  hclspec.NewAttr("dns_servers", "list(string)", false),
+		"force_pull":   hclspec.NewAttr("force_pull", "bool", false),
+		"hostname":     hclspec.NewAttr("hostname", "string", false),
+		"labels":       hclspec.NewBlockAttrs("labels", "string", false),
+		"network_mode": hclspec.NewAttr("network_mode", "string", false),
+		"port_map":     hclspec.NewBlockAttrs("port_map", "number", false),
+		"privileged":   hclspec.NewAttr("privileged", "bool", false),
+		"volumes":      hclspec.NewAttr("volumes", "list(string)", false),
+		"work_dir":     hclspec.NewAttr("work_dir", "string", false),
+	})
+
+	// capabilities is returned by the Capabilities RPC and indicates what
+	// optional features this driver supports
+	capabilities = &drivers.Capabilities{
+		SendSignals: true,
+		Exec:        true,
+		FSIsolation: drivers.FSIsolationImage,
+	}
+
+	rePodmanVersion = regexp.MustCompile(`podman [vV]ersion (\d[.\d]+)`)
+)
+
+// Config is the client configuration for the driver
+type Config struct {
+	GC              GCConfig     `codec:"gc"`
+	Volumes         VolumeConfig `codec:"volumes"`
+	AllowPrivileged bool         `codec:"allow_privileged"`
+}
+
+type GCConfig struct {
+	// Container removes the container once the task exits
+	Container bool `codec:"container"`
+
+	// Prune configures periodically pruning unused images
+	Prune imagegc.Config `codec:"prune"`
+}
+
+type VolumeConfig struct {
+	// Enabled allows tasks to bind host paths (volumes) inside their
+	// container. Binding paths within the allocation directory is always
+	// allowed.
+	Enabled      bool   `codec:"enabled"`
+	SelinuxLabel string `codec:"selinuxlabel"`
+}
+
+// TaskConfig is the driver configuration of a taskConfig within a job
+type TaskConfig struct {
+	Image       string            `codec:"image"`
+	Command     string            `codec:"command"`
+	Args        []string          `codec:"args"`
+	Entrypoint  string            `codec:"entrypoint"`
+	DNSServers  []string          `codec:"dns_servers"`
+	ForcePull   bool              `codec:"force_pull"`
+	Hostname    string            `codec:"hostname"`
+	Labels      map[string]string `codec:"labels"`
+	NetworkMode string            `codec:"network_mode"`
+	PortMap     map[string]int    `codec:"port_map"`
+	Privileged  bool              `codec:"privileged"`
+	Volumes     []string          `codec:"volumes"`
+	WorkDir     string            `codec:"work_dir"`
+}
+
+// TaskState is the state which is encoded in the handle returned in
+// StartTask. This information is needed to rebuild the taskConfig state and handler
+// during recovery.
+type TaskState struct {
+	ReattachConfig *pstructs.ReattachConfig
+	TaskConfig     *drivers.TaskConfig
+	Pid            int
+	StartedAt      time.Time
+	ContainerName  string
+}
+
+// Driver is a driver for running OCI images via podman. Podman does not
+// require a daemon, so containers are run in the foreground of a podman
+// process supervised by an executor, which also captures the task's logs.
+type Driver struct {
+	// eventer is used to handle multiplexing of TaskEvents calls such that an
+	// event can be broadcast to all callers
+	eventer *eventer.Eventer
+
+	// config is the driver configuration set by the SetConfig RPC
+	config *Config
+
+	// nomadConfig is the client config from nomad
+	nomadConfig *base.ClientDriverConfig
+
+	// tasks is the in memory datastore mapping taskIDs to taskHandles
+	tasks *taskStore
+
+	// ctx is the context for the driver. It is passed to other subsystems to
+	// coordinate shutdown
+	ctx context.Context
+
+	// signalShutdown is called when the driver is shutting down and cancels the
+	// ctx passed to any subsystems
+	signalShutdown context.CancelFunc
+
+	// imagePruner removes unused images if image pruning is enabled
+	imagePruner *imagegc.Pruner
+
+	// logger will log to the Nomad agent
+	logger hclog.Logger
+
+	// A tri-state boolean to know if the fingerprinting has happened and
+	// whether it has been successful
+	fingerprintSuccess *bool
+	fingerprintLock    sync.Mutex
+}
+
+func NewPodmanDriver(logger hclog.Logger) drivers.DriverPlugin {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger = logger.Named(pluginName)
+	return &Driver{
+		eventer:        eventer.NewEventer(ctx, logger),
+		config:         &Config{},
+		tasks:          newTaskStore(),
+		ctx:            ctx,
+		signalShutdown: cancel,
+		logger:         logger,
+	}
+}
+
+func (d *Driver) PluginInfo() (*base.PluginInfoResponse, error) {
+	return pluginInfo, nil
+}
+
+func (d *Driver) ConfigSchema() (*hclspec.Spec, error) {
+	return configSpec, nil
+}
+
+func (d *Driver) SetConfig(cfg *base.Config) error {
+	var config Config
+	if len(cfg.PluginConfig) != 0 {
+		if err := base.MsgPackDecode(cfg.PluginConfig, &config); err != nil {
+			return err
+		}
+	}
+
+	prunePolicy, err := config.GC.Prune.Policy()
+	if err != nil {
+		return fmt.Errorf("invalid 'prune' configuration: %v", err)
+	}
+
+	d.config = &config
+	if cfg.AgentConfig != nil {
+		d.nomadConfig = cfg.AgentConfig.Driver
+	}
+
+	if prunePolicy != nil && d.imagePruner == nil {
+		d.imagePruner = imagegc.NewPruner(d.logger, pluginName, prunePolicy, imageGCClient{})
+		go d.imagePruner.Run(d.ctx)
+	}
+	return nil
+}
+
+func (d *Driver) TaskConfigSchema() (*hclspec.Spec, error) {
+	return taskConfigSpec, nil
+}
+
+func (d *Driver) Capabilities() (*drivers.Capabilities, error) {
+	return capabilities, nil
+}
+
+func (d *Driver) Fingerprint(ctx context.Context) (<-chan *drivers.Fingerprint, error) {
+	ch := make(chan *drivers.Fingerprint)
+	go d.handleFingerprint(ctx, ch)
+	return ch, nil
+}
+
+func (d *Driver) handleFingerprint(ctx context.Context, ch chan *drivers.Fingerprint) {
+	defer close(ch)
+	ticker := time.NewTimer(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			ticker.Reset(fingerprintPeriod)
+			ch <- d.buildFingerprint()
+		}
+	}
+}
+
+// setFingerprintSuccess marks the driver as having fingerprinted successfully
+func (d *Driver) setFingerprintSuccess() {
+	d.fingerprintLock.Lock()
+	d.fingerprintSuccess = helper.BoolToPtr(true)
+	d.fingerprintLock.Unlock()
+}
+
+// setFingerprintFailure marks the driver as having failed fingerprinting
+func (d *Driver) setFingerprintFailure() {
+	d.fingerprintLock.Lock()
+	d.fingerprintSuccess = helper.BoolToPtr(false)
+	d.fingerprintLock.Unlock()
+}
+
+// fingerprintSuccessful returns true if the driver has
+// never fingerprinted or has successfully fingerprinted
+func (d *Driver) fingerprintSuccessful() bool {
+	d.fingerprintLock.Lock()
+	defer d.fingerprintLock.Unlock()
+	return d.fingerprintSuccess == nil || *d.fingerprintSuccess
+}
+
+func (d *Driver) buildFingerprint() *drivers.Fingerprint {
+	fingerprint := &drivers.Fingerprint{
+		Attributes:        map[string]*pstructs.Attribute{},
+		Health:            drivers.HealthStateHealthy,
+		HealthDescription: drivers.DriverHealthy,
+	}
+
+	outBytes, err := exec.Command(podmanCmd, "--version").Output()
+	if err != nil {
+		if d.fingerprintSuccessful() {
+			d.logger.Debug("podman not found, disabling", "error", err)
+		}
+		fingerprint.Health = drivers.HealthStateUndetected
+		fingerprint.HealthDescription = fmt.Sprintf("Failed to execute %s --version: %v", podmanCmd, err)
+		d.setFingerprintFailure()
+		return fingerprint
+	}
+
+	matches := rePodmanVersion.FindStringSubmatch(strings.TrimSpace(string(outBytes)))
+	if len(matches) != 2 {
+		fingerprint.Health = drivers.HealthStateUndetected
+		fingerprint.HealthDescription = "Unable to parse podman version string"
+		d.setFingerprintFailure()
+		return fingerprint
+	}
+
+	fingerprint.Attributes["driver.podman"] = pstructs.NewBoolAttribute(true)
+	fingerprint.Attributes["driver.podman.version"] = pstructs.NewStringAttribute(matches[1])
+	fingerprint.Attributes["driver.podman.rootless"] = pstructs.NewBoolAttribute(syscall.Geteuid() != 0)
+	if d.config.Volumes.Enabled {
+		fingerprint.Attributes["driver.podman.volumes.enabled"] = pstructs.NewBoolAttribute(true)
+	}
+	if d.config.AllowPrivileged {
+		fingerprint.Attributes["driver.podman.privileged.enabled"] = pstructs.NewBoolAttribute(true)
+	}
+	d.setFingerprintSuccess()
+	return fingerprint
+}
+
+func (d *Driver) RecoverTask(handle *drivers.TaskHandle) error {
+	if handle == nil {
+		return fmt.Errorf("error: handle cannot be nil")
+	}
+
+	// If already attached to handle there's nothing to recover.
+	if _, ok := d.tasks.Get(handle.Config.ID); ok {
+		d.logger.Trace("nothing to recover; task already exists",
+			"task_id", handle.Config.ID,
+			"task_name", handle.Config.Name,
+		)
+		return nil
+	}
+
+	var taskState TaskState
+	if err := handle.GetDriverState(&taskState); err != nil {
+		d.logger.Error("failed to decode taskConfig state from handle", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to decode taskConfig state from handle: %v", err)
+	}
+
+	plugRC, err := pstructs.ReattachConfigToGoPlugin(taskState.ReattachConfig)
+	if err != nil {
+		d.logger.Error("failed to build ReattachConfig from taskConfig state", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to build ReattachConfig from taskConfig state: %v", err)
+	}
+
+	execImpl, pluginClient, err := executor.ReattachToExecutor(plugRC,
+		d.logger.With("task_name", handle.Config.Name, "alloc_id", handle.Config.AllocID))
+	if err != nil {
+		d.logger.Error("failed to reattach to executor", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to reattach to executor: %v", err)
+	}
+
+	h := &taskHandle{
+		exec:          execImpl,
+		env:           podmanEnv(),
+		pid:           taskState.Pid,
+		containerName: taskState.ContainerName,
+		pluginClient:  pluginClient,
+		taskConfig:    taskState.TaskConfig,
+		procState:     drivers.TaskStateRunning,
+		startedAt:     taskState.StartedAt,
+		exitResult:    &drivers.ExitResult{},
+		doneCh:        make(chan struct{}),
+		logger:        d.logger,
+	}
+
+	d.tasks.Set(taskState.TaskConfig.ID, h)
+
+	go h.run()
+	return nil
+}
+
+func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drivers.DriverNetwork, error) {
+	if _, ok := d.tasks.Get(cfg.ID); ok {
+		return nil, nil, fmt.Errorf("taskConfig with ID '%s' already started", cfg.ID)
+	}
+
+	var driverConfig TaskConfig
+	if err := cfg.DecodeDriverConfig(&driverConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode driver config: %v", err)
+	}
+
+	handle := drivers.NewTaskHandle(taskHandleVersion)
+	handle.Config = cfg
+
+	containerName := containerName(cfg)
+	runArgs, err := d.runArgs(cfg, &driverConfig, containerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := d.pullImage(cfg, &driverConfig); err != nil {
+		return nil, nil, err
+	}
+	if d.imagePruner != nil {
+		if id, err := imageID(driverConfig.Image); err != nil {
+			d.logger.Warn("failed to get image id", "image", driverConfig.Image, "error", err)
+		} else {
+			d.imagePruner.Touch(id)
+		}
+	}
+
+	// Remove any container left behind by a previous run of the task, as
+	// the container name would otherwise conflict
+	if err := podmanRemove(containerName); err != nil {
+		d.logger.Debug("no previous container to remove", "container_name", containerName, "error", err)
+	}
+
+	absPath, err := GetAbsolutePath(podmanCmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pluginLogFile := filepath.Join(cfg.TaskDir().Dir, fmt.Sprintf("%s-executor.out", cfg.Name))
+	executorConfig := &executor.ExecutorConfig{
+		LogFile:  pluginLogFile,
+		LogLevel: "debug",
+	}
+
+	execImpl, pluginClient, err := executor.CreateExecutor(
+		d.logger.With("task_name", handle.Config.Name, "alloc_id", handle.Config.AllocID),
+		d.nomadConfig, executorConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The task's environment is set via --env flags, but the podman command
+	// itself needs an environment with PATH set to find conmon and runc.
+	env := podmanEnv()
+
+	// podman runs the container attached, so its stdout and stderr are the
+	// task's log streams
+	execCmd := &executor.ExecCommand{
+		Cmd:        absPath,
+		Args:       runArgs,
+		Env:        env.List(),
+		TaskDir:    cfg.TaskDir().Dir,
+		StdoutPath: cfg.StdoutPath,
+		StderrPath: cfg.StderrPath,
+	}
+	ps, err := execImpl.Launch(execCmd)
+	if err != nil {
+		pluginClient.Kill()
+		return nil, nil, err
+	}
+
+	d.logger.Debug("started task", "image", driverConfig.Image, "container_name", containerName, "task_name", cfg.Name, "args", runArgs)
+	h := &taskHandle{
+		exec:          execImpl,
+		env:           env,
+		pid:           ps.Pid,
+		containerName: containerName,
+		pluginClient:  pluginClient,
+		taskConfig:    cfg,
+		procState:     drivers.TaskStateRunning,
+		startedAt:     time.Now().Round(time.Millisecond),
+		doneCh:        make(chan struct{}),
+		logger:        d.logger,
+	}
+
+	podmanDriverState := TaskState{
+		ReattachConfig: pstructs.ReattachConfigFromGoPlugin(pluginClient.ReattachConfig()),
+		Pid:            ps.Pid,
+		TaskConfig:     cfg,
+		StartedAt:      h.startedAt,
+		ContainerName:  containerName,
+	}
+
+	if err := handle.SetDriverState(&podmanDriverState); err != nil {
+		d.logger.Error("failed to start task, error setting driver state", "error", err, "task_name", cfg.Name)
+		execImpl.Shutdown("", 0)
+		pluginClient.Kill()
+		podmanRemove(containerName)
+		return nil, nil, fmt.Errorf("failed to set driver state: %v", err)
+	}
+
+	d.tasks.Set(cfg.ID, h)
+	go h.run()
+
+	// Only look up the container's address if ports are mapped into a
+	// container network
+	var driverNetwork *drivers.DriverNetwork
+	if len(driverConfig.PortMap) > 0 && driverConfig.NetworkMode != "host" && driverConfig.NetworkMode != "none" {
+		ip, err := podmanContainerIP(containerName, h.doneCh)
+		if err != nil {
+			d.logger.Warn("failed to retrieve container address", "container_name", containerName, "task_name", cfg.Name, "error", err)
+		} else {
+			driverNetwork = &drivers.DriverNetwork{
+				PortMap: driverConfig.PortMap,
+				IP:      ip,
+			}
+		}
+	}
+
+	return handle, driverNetwork, nil
+}
+
+// containerName returns the name of the container of a task. It is unique per
+// allocation and task so that it can be found again after a client restart.
+func containerName(cfg *drivers.TaskConfig) string {
+	return fmt.Sprintf("%s-%s", cfg.Name, cfg.AllocID)
+}
+
+// podmanEnv returns the environment the podman command itself runs with.
+func podmanEnv() *taskenv.TaskEnv {
+	// TODO need to figure out how to pass env.blacklist from client config
+	eb := taskenv.NewEmptyBuilder()
+	filter := strings.Split(config.DefaultEnvBlacklist, ",")
+	return eb.SetHostEnvvars(filter).Build()
+}
+
+// runArgs builds the arguments of the podman run command for a task.
+func (d *Driver) runArgs(cfg *drivers.TaskConfig, driverConfig *TaskConfig, name string) ([]string, error) {
+	if driverConfig.Image == "" {
+		return nil, fmt.Errorf("image name required")
+	}
+	if cfg.Resources == nil {
+		return nil, fmt.Errorf("task.Resources is empty")
+	}
+
+	args := []string{"run", fmt.Sprintf("--name=%s", name)}
+	if d.config.GC.Container {
+		args = append(args, "--rm")
+	}
+
+	// Resource limits
+	if lr := cfg.Resources.LinuxResources; lr != nil {
+		if lr.MemoryLimitBytes > 0 {
+			args = append(args, fmt.Sprintf("--memory=%d", lr.MemoryLimitBytes))
+		}
+		if lr.CPUShares > 0 {
+			args = append(args, fmt.Sprintf("--cpu-shares=%d", lr.CPUShares))
+		}
+		if lr.CpusetCPUs != "" {
+			args = append(args, fmt.Sprintf("--cpuset-cpus=%s", lr.CpusetCPUs))
+		}
+	}
+
+	// Volumes
+	binds, err := d.containerBinds(cfg, driverConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, bind := range binds {
+		args = append(args, fmt.Sprintf("--volume=%s", bind))
+	}
+
+	for _, m := range cfg.Mounts {
+		bind := fmt.Sprintf("%s:%s", m.HostPath, m.TaskPath)
+		if m.Readonly {
+			bind += ":ro"
+		}
+		args = append(args, fmt.Sprintf("--volume=%s", bind))
+	}
+
+	for _, dev := range cfg.Devices {
+		args = append(args, fmt.Sprintf("--device=%s:%s:%s", dev.HostPath, dev.TaskPath, dev.Permissions))
+	}
+
+	// Environment variables are sorted so the arguments are stable
+	keys := make([]string, 0, len(cfg.Env))
+	for k := range cfg.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, fmt.Sprintf("--env=%s=%s", k, cfg.Env[k]))
+	}
+
+	labels := make([]string, 0, len(driverConfig.Labels))
+	for k, v := range driverConfig.Labels {
+		labels = append(labels, fmt.Sprintf("--label=%s=%s", k, v))
+	}
+	sort.Strings(labels)
+	args = append(args, labels...)
+
+	if cfg.User != "" {
+		args = append(args, fmt.Sprintf("--user=%s", cfg.User))
+	}
+	if driverConfig.Hostname != "" {
+		args = append(args, fmt.Sprintf("--hostname=%s", driverConfig.Hostname))
+	}
+	if driverConfig.WorkDir != "" {
+		args = append(args, fmt.Sprintf("--workdir=%s", driverConfig.WorkDir))
+	}
+	if driverConfig.Entrypoint != "" {
+		args = append(args, fmt.Sprintf("--entrypoint=%s", driverConfig.Entrypoint))
+	}
+	if driverConfig.Privileged {
+		if !d.config.AllowPrivileged {
+			return nil, fmt.Errorf(`Podman privileged mode is disabled on this Nomad agent`)
+		}
+		args = append(args, "--privileged")
+	}
+	for _, ip := range driverConfig.DNSServers {
+		args = append(args, fmt.Sprintf("--dns=%s", ip))
+	}
+	if driverConfig.NetworkMode != "" {
+		args = append(args, fmt.Sprintf("--network=%s", driverConfig.NetworkMode))
+	}
+
+	// Setup port mapping
+	ports, err := publishedPorts(cfg, driverConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		args = append(args, fmt.Sprintf("--publish=%s", port))
+	}
+
+	// Image is set here, because the arguments that follow apply to it
+	args = append(args, driverConfig.Image)
+
+	if driverConfig.Command != "" {
+		args = append(args, driverConfig.Command)
+	}
+	args = append(args, driverConfig.Args...)
+
+	return args, nil
+}
+
+// containerBinds returns the bind mounts of the task directories and of the
+// volumes the task requested.
+func (d *Driver) containerBinds(cfg *drivers.TaskConfig, driverConfig *TaskConfig) ([]string, error) {
+	allocDirBind := fmt.Sprintf("%s:%s", cfg.TaskDir().SharedAllocDir, cfg.Env[taskenv.AllocDir])
+	taskLocalBind := fmt.Sprintf("%s:%s", cfg.TaskDir().LocalDir, cfg.Env[taskenv.TaskLocalDir])
+	secretDirBind := fmt.Sprintf("%s:%s", cfg.TaskDir().SecretsDir, cfg.Env[taskenv.SecretsDir])
+	binds := []string{allocDirBind, taskLocalBind, secretDirBind}
+
+	for _, userbind := range driverConfig.Volumes {
+		parts := strings.Split(userbind, ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid podman volume: %q", userbind)
+		}
+
+		// Paths inside the alloc dir are always allowed, relative paths
+		// are resolved relative to the task dir
+		parts[0] = expandPath(cfg.TaskDir().Dir, parts[0])
+		if !d.config.Volumes.Enabled && !isParentPath(cfg.AllocDir, parts[0]) {
+			return nil, fmt.Errorf("volumes are not enabled; cannot mount host paths: %+q", userbind)
+		}
+
+		binds = append(binds, strings.Join(parts, ":"))
+	}
+
+	if selinuxLabel := d.config.Volumes.SelinuxLabel; selinuxLabel != "" {
+		// Apply SELinux Label to each volume
+		for i := range binds {
+			binds[i] = fmt.Sprintf("%s:%s", binds[i], selinuxLabel)
+		}
+	}
+
+	return binds, nil
+}
+
+// publishedPorts returns the --publish specifications mapping the task's
+// allocated ports into the container.
+func publishedPorts(cfg *drivers.TaskConfig, driverConfig *TaskConfig) ([]string, error) {
+	if cfg.Resources.NomadResources == nil || len(cfg.Resources.NomadResources.Networks) == 0 {
+		if len(driverConfig.PortMap) > 0 {
+			return nil, fmt.Errorf("Trying to map ports but no network interface is available")
+		}
+		return nil, nil
+	}
+
+	// Port mapping is skipped when host networking is used
+	if driverConfig.NetworkMode == "host" {
+		return nil, nil
+	}
+
+	// TODO add support for more than one network
+	network := cfg.Resources.NomadResources.Networks[0]
+	ports := make([]structs.Port, 0, len(network.ReservedPorts)+len(network.DynamicPorts))
+	ports = append(ports, network.ReservedPorts...)
+	ports = append(ports, network.DynamicPorts...)
+
+	var published []string
+	for _, port := range ports {
+		// By default we will map the allocated port 1:1 to the container
+		containerPort := port.Value
+
+		// If the user has mapped a port using port_map we'll change it here
+		if mapped, ok := driverConfig.PortMap[port.Label]; ok {
+			containerPort = mapped
+		}
+
+		for _, proto := range []string{"tcp", "udp"} {
+			published = append(published, fmt.Sprintf("%s:%d:%d/%s", network.IP, port.Value, containerPort, proto))
+		}
+	}
+	return published, nil
+}
+
+// pullImage pulls the task's image unless it is already present.
+func (d *Driver) pullImage(cfg *drivers.TaskConfig, driverConfig *TaskConfig) error {
+	if !driverConfig.ForcePull {
+		if err := exec.Command(podmanCmd, "image", "exists", driverConfig.Image).Run(); err == nil {
+			d.logger.Debug("image already present", "image", driverConfig.Image, "task_name", cfg.Name)
+			return nil
+		}
+	}
+
+	d.eventer.EmitEvent(&drivers.TaskEvent{
+		TaskID:    cfg.ID,
+		AllocID:   cfg.AllocID,
+		TaskName:  cfg.Name,
+		Timestamp: time.Now(),
+		Message:   "Downloading image",
+		Annotations: map[string]string{
+			"image": driverConfig.Image,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(d.ctx, pullTimeout)
+	defer cancel()
+
+	var outBuf, errBuf bytes.Buffer
+	cmd := exec.CommandContext(ctx, podmanCmd, "pull", driverConfig.Image)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Failed to pull `%s`: %s\n\nError: %s", driverConfig.Image, err, errBuf.String())
+	}
+
+	d.logger.Debug("pulled image", "image", driverConfig.Image, "task_name", cfg.Name)
+	return nil
+}
+
+// podmanRemove forcefully removes a container.
+func podmanRemove(name string) error {
+	return podmanRun("rm", "--force", name)
+}
+
+// podmanKill sends a signal to the processes of a container.
+func podmanKill(name, signal string) error {
+	return podmanRun("kill", fmt.Sprintf("--signal=%s", signal), name)
+}
+
+// podmanRun runs a podman command and returns an error including its
+// output if it fails.
+func podmanRun(args ...string) error {
+	out, err := exec.Command(podmanCmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("podman %s failed: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// podmanContainerIP waits for the container to be created and returns its
+// address within the container network.
+func podmanContainerIP(name string, doneCh <-chan struct{}) (string, error) {
+	deadline := time.NewTimer(networkDeadline)
+	defer deadline.Stop()
+
+	var lastErr error
+	for {
+		out, err := exec.Command(podmanCmd, "inspect", "--format={{.NetworkSettings.IPAddress}}", name).Output()
+		if err == nil {
+			if ip := strings.TrimSpace(string(out)); ip != "" {
+				return ip, nil
+			}
+			lastErr = fmt.Errorf("container has no address")
+		} else {
+			lastErr = err
+		}
+
+		select {
+		case <-doneCh:
+			return "", fmt.Errorf("container exited: %v", lastErr)
+		case <-deadline.C:
+			return "", fmt.Errorf("timed out, last error: %v", lastErr)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (d *Driver) WaitTask(ctx context.Context, taskID string) (<-chan *drivers.ExitResult, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	ch := make(chan *drivers.ExitResult)
+	go d.handleWait(ctx, handle, ch)
+
+	return ch, nil
+}
+
+func (d *Driver) StopTask(taskID string, timeout time.Duration, signal string) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	if signal == "" {
+		signal = "SIGTERM"
+	}
+
+	// Signal the container directly, as killing the podman process would
+	// leave the container running
+	if err := podmanKill(handle.containerName, signal); err != nil {
+		d.logger.Debug("failed to signal container", "container_name", handle.containerName, "error", err)
+	}
+
+	select {
+	case <-handle.doneCh:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	if err := podmanKill(handle.containerName, "SIGKILL"); err != nil {
+		d.logger.Debug("failed to kill container", "container_name", handle.containerName, "error", err)
+	}
+
+	if err := handle.exec.Shutdown("", 0); err != nil {
+		if handle.pluginClient.Exited() {
+			return nil
+		}
+		return fmt.Errorf("executor Shutdown failed: %v", err)
+	}
+
+	return nil
+}
+
+func (d *Driver) DestroyTask(taskID string, force bool) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	if handle.IsRunning() && !force {
+		return fmt.Errorf("cannot destroy running task")
+	}
+
+	if handle.IsRunning() || d.config.GC.Container {
+		if err := podmanRemove(handle.containerName); err != nil {
+			handle.logger.Debug("failed to remove container", "container_name", handle.containerName, "error", err)
+		}
+	}
+
+	if !handle.pluginClient.Exited() {
+		if handle.IsRunning() {
+			if err := handle.exec.Shutdown("", 0); err != nil {
+				handle.logger.Error("destroying executor failed", "err", err)
+			}
+		}
+
+		handle.pluginClient.Kill()
+	}
+
+	d.tasks.Delete(taskID)
+	return nil
+}
+
+func (d *Driver) InspectTask(taskID string) (*drivers.TaskStatus, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	return handle.TaskStatus(), nil
+}
+
+func (d *Driver) TaskStats(ctx context.Context, taskID string, interval time.Duration) (<-chan *drivers.TaskResourceUsage, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	ch := make(chan *drivers.TaskResourceUsage)
+	go handle.collectStats(ctx, ch, interval)
+	return ch, nil
+}
+
+func (d *Driver) TaskEvents(ctx context.Context) (<-chan *drivers.TaskEvent, error) {
+	return d.eventer.TaskEvents(ctx)
+}
+
+func (d *Driver) SignalTask(taskID string, signal string) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	return podmanKill(handle.containerName, signal)
+}
+
+func (d *Driver) ExecTask(taskID string, cmdArgs []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
+	if len(cmdArgs) == 0 {
+		return nil, fmt.Errorf("error cmd must have atleast one value")
+	}
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	// exec + container name + cmd + args...
+	execArgs := append([]string{"exec", handle.containerName}, cmdArgs...)
+	out, exitCode, err := handle.exec.Exec(time.Now().Add(timeout), podmanCmd, execArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &drivers.ExecTaskResult{
+		Stdout: out,
+		ExitResult: &drivers.ExitResult{
+			ExitCode: exitCode,
+		},
+	}, nil
+}
+
+// GetAbsolutePath returns the absolute path of the passed binary by resolving
+// it in the path and following symlinks.
+func GetAbsolutePath(bin string) (string, error) {
+	lp, err := exec.LookPath(bin)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path to %q executable: %v", bin, err)
+	}
+
+	return filepath.EvalSymlinks(lp)
+}
+
+// expandPath returns the absolute path of dir, relative to base if dir is
+// relative.
+func expandPath(base, dir string) string {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+
+	return filepath.Clean(filepath.Join(base, dir))
+}
+
+// isParentPath returns true if path is a child or a descendant of parent path.
+// Both inputs need to be absolute paths.
+func isParentPath(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+func (d *Driver) handleWait(ctx context.Context, handle *taskHandle, ch chan *drivers.ExitResult) {
+	defer close(ch)
+	var result *drivers.ExitResult
+	ps, err := handle.exec.Wait(ctx)
+	if err != nil {
+		result = &drivers.ExitResult{
+			Err: fmt.Errorf("executor: error waiting on process: %v", err),
+		}
+	} else {
+		result = &drivers.ExitResult{
+			ExitCode: ps.ExitCode,
+			Signal:   ps.Signal,
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-d.ctx.Done():
+	case ch <- result:
+	}
+}
+
+func (d *Driver) Shutdown() {
+	d.signalShutdown()
+}
@@ -0,0 +1,251 @@
+package podman
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ctestutil "github.com/hashicorp/nomad/client/testutil"
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+	basePlug "github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	dtestutil "github.com/hashicorp/nomad/plugins/drivers/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+var _ drivers.DriverPlugin = (*Driver)(nil)
+
+func TestPodmanVersionRegex(t *testing.T) {
+	t.Parallel()
+
+	matches := rePodmanVersion.FindStringSubmatch("podman version 1.4.4")
+	require.Len(t, matches, 2)
+	require.Equal(t, "1.4.4", matches[1])
+
+	require.Empty(t, rePodmanVersion.FindStringSubmatch("docker version 1.4.4"))
+}
+
+// Tests setting driver config options
+func TestPodmanDriver_SetConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	d := NewPodmanDriver(testlog.HCLogger(t))
+	harness := dtestutil.NewDriverHarness(t, d)
+
+	config := &Config{
+		GC: GCConfig{Container: true},
+		Volumes: VolumeConfig{
+			Enabled:      true,
+			SelinuxLabel: "z",
+		},
+	}
+
+	var data []byte
+	require.NoError(basePlug.MsgPackEncode(&data, config))
+	bconfig := &basePlug.Config{PluginConfig: data}
+	require.NoError(harness.SetConfig(bconfig))
+	require.Exactly(config, d.(*Driver).config)
+}
+
+func podmanTask() *drivers.TaskConfig {
+	return &drivers.TaskConfig{
+		ID:       uuid.Generate(),
+		AllocID:  uuid.Generate(),
+		Name:     "web",
+		AllocDir: "/tmp/alloc",
+		Env: map[string]string{
+			"NOMAD_ALLOC_DIR":   "/alloc",
+			"NOMAD_TASK_DIR":    "/local",
+			"NOMAD_SECRETS_DIR": "/secrets",
+		},
+		Resources: &drivers.Resources{
+			NomadResources: &structs.AllocatedTaskResources{
+				Memory: structs.AllocatedMemoryResources{
+					MemoryMB: 256,
+				},
+				Cpu: structs.AllocatedCpuResources{
+					CpuShares: 512,
+				},
+				Networks: []*structs.NetworkResource{
+					{
+						IP:            "127.0.0.1",
+						ReservedPorts: []structs.Port{{Label: "main", Value: 8080}},
+						DynamicPorts:  []structs.Port{{Label: "http", Value: 23456}},
+					},
+				},
+			},
+			LinuxResources: &drivers.LinuxResources{
+				CPUShares:        512,
+				MemoryLimitBytes: 256 * 1024 * 1024,
+			},
+		},
+	}
+}
+
+func TestPodmanDriver_RunArgs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	d := NewPodmanDriver(testlog.HCLogger(t)).(*Driver)
+	d.config = &Config{
+		GC:      GCConfig{Container: true},
+		Volumes: VolumeConfig{Enabled: true},
+	}
+
+	task := podmanTask()
+	driverConfig := &TaskConfig{
+		Image:    "docker.io/library/redis:3.2",
+		Command:  "redis-server",
+		Args:     []string{"--port", "6379"},
+		Hostname: "redis",
+		PortMap:  map[string]int{"http": 6379},
+		Volumes:  []string{"data:/data", "/etc/ssl:/etc/ssl:ro"},
+	}
+
+	args, err := d.runArgs(task, driverConfig, containerName(task))
+	require.NoError(err)
+
+	expected := []string{
+		"run",
+		"--name=web-" + task.AllocID,
+		"--rm",
+		"--memory=268435456",
+		"--cpu-shares=512",
+		"--volume=/tmp/alloc/alloc:/alloc",
+		"--volume=/tmp/alloc/web/local:/local",
+		"--volume=/tmp/alloc/web/secrets:/secrets",
+		"--volume=/tmp/alloc/web/data:/data",
+		"--volume=/etc/ssl:/etc/ssl:ro",
+		"--env=NOMAD_ALLOC_DIR=/alloc",
+		"--env=NOMAD_SECRETS_DIR=/secrets",
+		"--env=NOMAD_TASK_DIR=/local",
+		"--hostname=redis",
+		"--publish=127.0.0.1:8080:8080/tcp",
+		"--publish=127.0.0.1:8080:8080/udp",
+		"--publish=127.0.0.1:23456:6379/tcp",
+		"--publish=127.0.0.1:23456:6379/udp",
+		"docker.io/library/redis:3.2",
+		"redis-server",
+		"--port",
+		"6379",
+	}
+	require.Equal(expected, args)
+}
+
+func TestPodmanDriver_RunArgs_Validation(t *testing.T) {
+	t.Parallel()
+
+	d := NewPodmanDriver(testlog.HCLogger(t)).(*Driver)
+	d.config = &Config{}
+
+	cases := []struct {
+		name   string
+		config *TaskConfig
+		err    string
+	}{
+		{
+			name:   "host volume disabled",
+			config: &TaskConfig{Image: "busybox", Volumes: []string{"/etc:/etc"}},
+			err:    "volumes are not enabled",
+		},
+		{
+			name:   "invalid volume",
+			config: &TaskConfig{Image: "busybox", Volumes: []string{"/etc"}},
+			err:    "invalid podman volume",
+		},
+		{
+			name:   "privileged disabled",
+			config: &TaskConfig{Image: "busybox", Privileged: true},
+			err:    "privileged mode is disabled",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := d.runArgs(podmanTask(), c.config, "test")
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.err)
+		})
+	}
+}
+
+func TestPodmanDriver_ParseStats(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	usage, err := parsePodmanStats("12.50%|10.5MB / 268.4MB\n")
+	require.NoError(err)
+	require.Equal(12.5, usage.ResourceUsage.CpuStats.Percent)
+	require.EqualValues(10500000, usage.ResourceUsage.MemoryStats.Usage)
+
+	_, err = parsePodmanStats("--")
+	require.Error(err)
+}
+
+func TestPodmanDriver_Start_Wait(t *testing.T) {
+	ctestutil.PodmanCompatible(t)
+	require := require.New(t)
+
+	d := NewPodmanDriver(testlog.HCLogger(t))
+	harness := dtestutil.NewDriverHarness(t, d)
+
+	var data []byte
+	config := &Config{GC: GCConfig{Container: true}}
+	require.NoError(basePlug.MsgPackEncode(&data, config))
+	require.NoError(harness.SetConfig(&basePlug.Config{PluginConfig: data}))
+
+	task := podmanTask()
+	task.Resources.NomadResources.Networks = nil
+
+	taskConfig := map[string]interface{}{
+		"image":   "docker.io/library/busybox:1.29",
+		"command": "/bin/sh",
+		"args":    []string{"-c", "echo hello > /alloc/output"},
+	}
+	require.NoError(task.EncodeConcreteDriverConfig(&taskConfig))
+
+	cleanup := harness.MkAllocDir(task, true)
+	defer cleanup()
+
+	_, _, err := harness.StartTask(task)
+	require.NoError(err)
+	defer harness.DestroyTask(task.ID, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	waitCh, err := harness.WaitTask(ctx, task.ID)
+	require.NoError(err)
+
+	result := <-waitCh
+	require.True(result.Successful(), "task failed: %#v", result)
+
+	out, err := ioutil.ReadFile(filepath.Join(task.TaskDir().SharedAllocDir, "output"))
+	require.NoError(err)
+	require.Equal("hello\n", string(out))
+}
+
+func TestPodmanDriver_ParseImageGC(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	images, err := parseImageInspect("sha256:aaaa 1024\nbbbb 2048\n")
+	require.NoError(err)
+	require.Equal([]*imagegc.Image{
+		{ID: "aaaa", Size: 1024},
+		{ID: "bbbb", Size: 2048},
+	}, images)
+
+	_, err = parseImageInspect("aaaa 1.2GB\n")
+	require.Error(err)
+
+	running, err := parseContainerImages("aaaa false\nsha256:bbbb false\naaaa true\n")
+	require.NoError(err)
+	require.Equal(map[string]bool{"aaaa": true, "bbbb": false}, running)
+}
@@ -0,0 +1,144 @@
+package podman
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/drivers/shared/imagegc"
+)
+
+// imageGCClient implements imagegc.ImageClient for podman images
+type imageGCClient struct{}
+
+// ListImages returns all images and whether they are used by containers.
+func (imageGCClient) ListImages() ([]*imagegc.Image, error) {
+	ids, err := podmanIDs("images", "--quiet", "--no-trunc")
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	out, err := podmanOutput(append([]string{"image", "inspect", "--format={{.Id}} {{.Size}}"}, ids...)...)
+	if err != nil {
+		return nil, err
+	}
+	images, err := parseImageInspect(out)
+	if err != nil {
+		return nil, err
+	}
+
+	containerIDs, err := podmanIDs("ps", "--all", "--quiet", "--no-trunc")
+	if err != nil || len(containerIDs) == 0 {
+		return images, err
+	}
+
+	out, err = podmanOutput(append([]string{"container", "inspect", "--format={{.Image}} {{.State.Running}}"}, containerIDs...)...)
+	if err != nil {
+		return nil, err
+	}
+	inUse, err := parseContainerImages(out)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range images {
+		image.Running, image.InUse = inUse[image.ID]
+	}
+	return images, nil
+}
+
+// RemoveImage removes the image. Forcibly removing an image also removes the
+// containers created from it.
+func (imageGCClient) RemoveImage(id string, force bool) error {
+	if force {
+		return podmanRun("rmi", "--force", id)
+	}
+	return podmanRun("rmi", id)
+}
+
+// imageID returns the ID of the named image.
+func imageID(image string) (string, error) {
+	out, err := podmanOutput("image", "inspect", "--format={{.Id}}", image)
+	if err != nil {
+		return "", err
+	}
+	return normalizeImageID(strings.TrimSpace(out)), nil
+}
+
+// podmanIDs runs the podman command and returns the IDs it outputs, one per
+// line.
+func podmanIDs(args ...string) ([]string, error) {
+	out, err := podmanOutput(args...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// podmanOutput runs the podman command and returns its output.
+func podmanOutput(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(podmanCmd, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("podman %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// parseImageInspect parses the IDs and sizes output by podman image inspect.
+func parseImageInspect(out string) ([]*imagegc.Image, error) {
+	var images []*imagegc.Image
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected image inspect output: %q", line)
+		}
+
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse size of image %q: %v", fields[0], err)
+		}
+		images = append(images, &imagegc.Image{
+			ID:   normalizeImageID(fields[0]),
+			Size: size,
+		})
+	}
+	return images, nil
+}
+
+// parseContainerImages parses the image IDs and running states output by
+// podman container inspect and returns whether a running container uses
+// each image.
+func parseContainerImages(out string) (map[string]bool, error) {
+	running := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected container inspect output: %q", line)
+		}
+
+		isRunning, err := strconv.ParseBool(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse state of container using image %q: %v", fields[0], err)
+		}
+		id := normalizeImageID(fields[0])
+		running[id] = running[id] || isRunning
+	}
+	return running, nil
+}
+
+// normalizeImageID strips the digest algorithm some podman versions prefix
+// image IDs with.
+func normalizeImageID(id string) string {
+	return strings.TrimPrefix(id, "sha256:")
+}
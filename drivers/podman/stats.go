@@ -0,0 +1,101 @@
+package podman
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/stats"
+)
+
+var (
+	// PodmanMeasuredCpuStats is the list of CPU stats reported by podman
+	PodmanMeasuredCpuStats = []string{"Percent"}
+
+	// PodmanMeasuredMemStats is the list of memory stats reported by podman
+	PodmanMeasuredMemStats = []string{"Usage"}
+)
+
+// podmanStatsFormat is the template used to retrieve container stats from
+// podman stats
+const podmanStatsFormat = "{{.CPUPerc}}|{{.MemUsage}}"
+
+// collectStats periodically sends the container's resource usage on ch
+// until the context is cancelled or the task exits.
+func (h *taskHandle) collectStats(ctx context.Context, ch chan *cstructs.TaskResourceUsage, interval time.Duration) {
+	defer close(ch)
+	timer := time.NewTimer(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.doneCh:
+			return
+		case <-timer.C:
+			timer.Reset(interval)
+		}
+
+		out, err := exec.CommandContext(ctx, podmanCmd, "stats", "--no-stream",
+			"--format="+podmanStatsFormat, h.containerName).Output()
+		if err != nil {
+			h.logger.Debug("error collecting stats", "container_name", h.containerName, "error", err)
+			continue
+		}
+
+		usage, err := parsePodmanStats(string(out))
+		if err != nil {
+			h.logger.Debug("error parsing stats", "container_name", h.containerName, "error", err)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case ch <- usage:
+		}
+	}
+}
+
+// parsePodmanStats parses the output of podman stats formatted with
+// podmanStatsFormat, e.g. "1.50%|12.3MB / 268.4MB".
+func parsePodmanStats(out string) (*cstructs.TaskResourceUsage, error) {
+	parts := strings.Split(strings.TrimSpace(out), "|")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("unexpected stats output %q", out)
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(parts[0]), "%"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpu percentage %q: %v", parts[0], err)
+	}
+
+	// Memory usage is reported as "usage / limit"
+	mem := strings.TrimSpace(strings.SplitN(parts[1], "/", 2)[0])
+	memUsage, err := units.FromHumanSize(mem)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory usage %q: %v", mem, err)
+	}
+
+	cs := &cstructs.CpuStats{
+		Percent:  percent,
+		Measured: PodmanMeasuredCpuStats,
+	}
+	cs.TotalTicks = (cs.Percent / 100) * stats.TotalTicksAvailable() / float64(runtime.NumCPU())
+
+	return &cstructs.TaskResourceUsage{
+		ResourceUsage: &cstructs.ResourceUsage{
+			MemoryStats: &cstructs.MemoryStats{
+				Usage:    uint64(memUsage),
+				Measured: PodmanMeasuredMemStats,
+			},
+			CpuStats: cs,
+		},
+		Timestamp: time.Now().UTC().UnixNano(),
+	}, nil
+}
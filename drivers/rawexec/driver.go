@@ -80,6 +80,7 @@ var (
 			hclspec.NewAttr("no_cgroups", "bool", false),
 			hclspec.NewLiteral("false"),
 		),
+		"user_namespace": executor.UserNamespaceSpec,
 	})
 
 	// taskConfigSpec is the hcl specification for the driver config section of
@@ -109,6 +110,9 @@ type Driver struct {
 	// config is the driver configuration set by the SetConfig RPC
 	config *Config
 
+	// userNamespace is the user namespace tasks are started in, if enabled
+	userNamespace *executor.UserNamespaceConfig
+
 	// nomadConfig is the client config from nomad
 	nomadConfig *base.ClientDriverConfig
 
@@ -135,6 +139,9 @@ type Config struct {
 
 	// Enabled is set to true to enable the raw_exec driver
 	Enabled bool `codec:"enabled"`
+
+	// UserNamespace configures tasks to run in user and PID namespaces
+	UserNamespace *executor.UserNamespacePluginConfig `codec:"user_namespace"`
 }
 
 // TaskConfig is the driver configuration of a task within a job
@@ -183,7 +190,13 @@ func (d *Driver) SetConfig(cfg *base.Config) error {
 		}
 	}
 
+	userNamespace, err := config.UserNamespace.Config()
+	if err != nil {
+		return fmt.Errorf("invalid user_namespace config: %v", err)
+	}
+
 	d.config = &config
+	d.userNamespace = userNamespace
 	if cfg.AgentConfig != nil {
 		d.nomadConfig = cfg.AgentConfig.Driver
 	}
@@ -228,10 +241,16 @@ func (d *Driver) buildFingerprint() *drivers.Fingerprint {
 	var health drivers.HealthState
 	var desc string
 	attrs := map[string]*pstructs.Attribute{}
-	if d.config.Enabled {
+	if d.config.Enabled && d.userNamespace != nil && !executor.UserNamespacesSupported() {
+		health = drivers.HealthStateUndetected
+		desc = "User namespaces are not supported by the kernel"
+	} else if d.config.Enabled {
 		health = drivers.HealthStateHealthy
 		desc = drivers.DriverHealthy
 		attrs["driver.raw_exec"] = pstructs.NewBoolAttribute(true)
+		if d.userNamespace != nil {
+			attrs["driver.raw_exec.user_namespace"] = pstructs.NewBoolAttribute(true)
+		}
 	} else {
 		health = drivers.HealthStateUndetected
 		desc = "disabled"
@@ -318,8 +337,9 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 
 	pluginLogFile := filepath.Join(cfg.TaskDir().Dir, "executor.out")
 	executorConfig := &executor.ExecutorConfig{
-		LogFile:  pluginLogFile,
-		LogLevel: "debug",
+		LogFile:       pluginLogFile,
+		LogLevel:      "debug",
+		UserNamespace: d.userNamespace,
 	}
 
 	exec, pluginClient, err := executor.CreateExecutor(
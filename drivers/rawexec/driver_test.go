@@ -14,6 +14,7 @@ import (
 	"time"
 
 	ctestutil "github.com/hashicorp/nomad/client/testutil"
+	"github.com/hashicorp/nomad/drivers/shared/executor"
 	"github.com/hashicorp/nomad/helper/pluginutils/hclutils"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/helper/testtask"
@@ -65,6 +66,28 @@ func TestRawExecDriver_SetConfig(t *testing.T) {
 	bconfig.PluginConfig = data
 	require.NoError(harness.SetConfig(bconfig))
 	require.Exactly(config, d.(*Driver).config)
+
+	// Enable user namespaces
+	config.UserNamespace = &executor.UserNamespacePluginConfig{
+		Enabled: true,
+		UIDMap:  []string{"0:1000:1"},
+	}
+	data = []byte{}
+	require.NoError(basePlug.MsgPackEncode(&data, config))
+	bconfig.PluginConfig = data
+	require.NoError(harness.SetConfig(bconfig))
+	require.Exactly(config, d.(*Driver).config)
+	require.Equal([]executor.IDMapping{{ContainerID: 0, HostID: 1000, Size: 1}},
+		d.(*Driver).userNamespace.UIDMappings)
+
+	// Invalid mappings are rejected
+	config.UserNamespace.GIDMap = []string{"0:1000"}
+	data = []byte{}
+	require.NoError(basePlug.MsgPackEncode(&data, config))
+	bconfig.PluginConfig = data
+	err := harness.SetConfig(bconfig)
+	require.Error(err)
+	require.Contains(err.Error(), "invalid gid_map")
 }
 
 func TestRawExecDriver_Fingerprint(t *testing.T) {
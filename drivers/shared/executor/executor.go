@@ -223,6 +223,9 @@ type UniversalExecutor struct {
 	systemCpuStats *stats.CpuStats
 	pidCollector   *pidCollector
 
+	// userNamespace configures the user namespace the command is started in
+	userNamespace *UserNamespaceConfig
+
 	logger hclog.Logger
 }
 
@@ -242,6 +245,12 @@ func NewExecutor(logger hclog.Logger) Executor {
 	}
 }
 
+// setUserNamespace configures the executor to start the command in a user
+// namespace
+func (e *UniversalExecutor) setUserNamespace(c *UserNamespaceConfig) {
+	e.userNamespace = c
+}
+
 // Version returns the api version of the executor
 func (e *UniversalExecutor) Version() (*ExecutorVersion, error) {
 	return &ExecutorVersion{Version: ExecutorVersionLatest}, nil
@@ -270,6 +279,11 @@ func (e *UniversalExecutor) Launch(command *ExecCommand) (*ProcessState, error)
 		return nil, err
 	}
 
+	// start command in a user namespace if configured
+	if err := e.configureUserNamespace(); err != nil {
+		return nil, err
+	}
+
 	// Setup cgroups on linux
 	if err := e.configureResourceContainer(os.Getpid()); err != nil {
 		return nil, err
@@ -309,6 +323,13 @@ func (e *UniversalExecutor) Launch(command *ExecCommand) (*ProcessState, error)
 		return nil, fmt.Errorf("failed to start command path=%q --- args=%q: %v", path, e.childCmd.Args, err)
 	}
 
+	// Assign the process to its resource container on platforms that can
+	// only do so once it's started
+	if err := e.assignResourceContainer(e.childCmd.Process.Pid); err != nil {
+		e.childCmd.Process.Kill()
+		return nil, fmt.Errorf("failed to assign command to its resource container: %v", err)
+	}
+
 	go e.pidCollector.collectPids(e.processExited, getAllPids)
 	go e.wait()
 	return &ProcessState{Pid: e.childCmd.Process.Pid, ExitCode: -1, Time: time.Now()}, nil
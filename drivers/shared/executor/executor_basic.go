@@ -1,4 +1,4 @@
-// +build !linux
+// +build !linux,!windows
 
 package executor
 
@@ -14,4 +14,6 @@ func NewExecutorWithIsolation(logger hclog.Logger) Executor {
 
 func (e *UniversalExecutor) configureResourceContainer(_ int) error { return nil }
 
+func (e *UniversalExecutor) assignResourceContainer(_ int) error { return nil }
+
 func (e *UniversalExecutor) runAs(_ string) error { return nil }
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/consul-template/signals"
 	hclog "github.com/hashicorp/go-hclog"
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/client/lib/cgutil"
 	"github.com/hashicorp/nomad/client/stats"
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/helper/discover"
@@ -79,6 +80,10 @@ type LibcontainerExecutor struct {
 	userProc       *libcontainer.Process
 	userProcExited chan interface{}
 	exitState      *ProcessState
+
+	// userNamespace configures the user namespace the container is started
+	// in
+	userNamespace *UserNamespaceConfig
 }
 
 func NewExecutorWithIsolation(logger hclog.Logger) Executor {
@@ -96,6 +101,12 @@ func NewExecutorWithIsolation(logger hclog.Logger) Executor {
 	}
 }
 
+// setUserNamespace configures the executor to start the container in a user
+// namespace
+func (l *LibcontainerExecutor) setUserNamespace(c *UserNamespaceConfig) {
+	l.userNamespace = c
+}
+
 // Launch creates a new container in libcontainer and starts a new process with it
 func (l *LibcontainerExecutor) Launch(command *ExecCommand) (*ProcessState, error) {
 	l.logger.Debug("launching command", "command", command.Cmd, "args", strings.Join(command.Args, " "))
@@ -113,27 +124,29 @@ func (l *LibcontainerExecutor) Launch(command *ExecCommand) (*ProcessState, erro
 
 	l.command = command
 
-	// Move to the root cgroup until process is started
-	subsystems, err := cgroups.GetAllSubsystems()
-	if err != nil {
-		return nil, err
+	// A rootless container can't manage cgroups or map IDs it doesn't own
+	rootless := l.userNamespace != nil && os.Geteuid() != 0
+	options := []func(*libcontainer.LinuxFactory) error{
+		cgroupManager,
+		libcontainer.InitArgs(bin, "libcontainer-shim"),
 	}
-	if err := JoinRootCgroup(subsystems); err != nil {
-		return nil, err
+	if rootless {
+		options = append(options, rootlessOptions()...)
+	} else {
+		// Move to the root cgroup until process is started
+		if err := joinRootCgroup(); err != nil {
+			return nil, err
+		}
 	}
 
 	// create a new factory which will store the container state in the allocDir
-	factory, err := libcontainer.New(
-		path.Join(command.TaskDir, "../alloc/container"),
-		libcontainer.Cgroupfs,
-		libcontainer.InitArgs(bin, "libcontainer-shim"),
-	)
+	factory, err := libcontainer.New(path.Join(command.TaskDir, "../alloc/container"), options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create factory: %v", err)
 	}
 
 	// A container groups processes under the same isolation enforcement
-	containerCfg, err := newLibcontainerConfig(command)
+	containerCfg, err := newLibcontainerConfig(command, l.userNamespace, rootless)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure container(%s): %v", l.id, err)
 	}
@@ -297,11 +310,7 @@ func (l *LibcontainerExecutor) Shutdown(signal string, grace time.Duration) erro
 	}
 
 	// move executor to root cgroup
-	subsystems, err := cgroups.GetAllSubsystems()
-	if err != nil {
-		return err
-	}
-	if err := JoinRootCgroup(subsystems); err != nil {
+	if err := joinRootCgroup(); err != nil {
 		return err
 	}
 
@@ -630,12 +639,24 @@ func configureCgroups(cfg *lconfigs.Config, command *ExecCommand) error {
 	// Set the relative CPU shares for this cgroup.
 	cfg.Cgroups.Resources.CpuShares = uint64(cpuShares)
 
+	// Restrict the task to the cores not reserved for the host
+	if lr := command.Resources.LinuxResources; lr != nil && lr.CpusetCPUs != "" {
+		cfg.Cgroups.Resources.CpusetCpus = lr.CpusetCPUs
+	}
+
 	return nil
 }
 
 func configureBasicCgroups(cfg *lconfigs.Config) error {
 	id := uuid.Generate()
 
+	// The unified hierarchy has no per subsystem mounts, so the cgroup
+	// manager creates the task's cgroup from its path
+	if cgutil.UseV2() {
+		cfg.Cgroups.Path = filepath.Join(defaultCgroupParent, id)
+		return nil
+	}
+
 	// Manually create freezer cgroup
 	cfg.Cgroups.Paths = map[string]string{}
 	root, err := cgroups.FindCgroupMountpointDir()
@@ -664,7 +685,7 @@ func configureBasicCgroups(cfg *lconfigs.Config) error {
 	return nil
 }
 
-func newLibcontainerConfig(command *ExecCommand) (*lconfigs.Config, error) {
+func newLibcontainerConfig(command *ExecCommand, userNamespace *UserNamespaceConfig, rootless bool) (*lconfigs.Config, error) {
 	cfg := &lconfigs.Config{
 		Cgroups: &lconfigs.Cgroup{
 			Resources: &lconfigs.Resources{
@@ -682,12 +703,102 @@ func newLibcontainerConfig(command *ExecCommand) (*lconfigs.Config, error) {
 	if err := configureIsolation(cfg, command); err != nil {
 		return nil, err
 	}
+	if userNamespace != nil {
+		configureUserNamespace(cfg, userNamespace, rootless)
+	}
+
+	// Without delegated cgroups a rootless container can't be placed in
+	// cgroups, so it runs without resource limits
+	if rootless {
+		cfg.Cgroups = &lconfigs.Cgroup{Resources: &lconfigs.Resources{}}
+		return cfg, nil
+	}
 	if err := configureCgroups(cfg, command); err != nil {
 		return nil, err
 	}
 	return cfg, nil
 }
 
+// configureUserNamespace starts the container in new user, PID and IPC
+// namespaces with the configured ID mappings. Mounts the container doesn't
+// have the privileges to create in the namespace are replaced with bind
+// mounts of the host's.
+func configureUserNamespace(cfg *lconfigs.Config, userNamespace *UserNamespaceConfig, rootless bool) {
+	cfg.Namespaces = append(cfg.Namespaces,
+		lconfigs.Namespace{Type: lconfigs.NEWUSER},
+		lconfigs.Namespace{Type: lconfigs.NEWPID},
+		lconfigs.Namespace{Type: lconfigs.NEWIPC},
+	)
+	cfg.UidMappings = libcontainerIDMappings(userNamespace.uidMappings())
+	cfg.GidMappings = libcontainerIDMappings(userNamespace.gidMappings())
+	cfg.Rootless = rootless
+
+	for _, m := range cfg.Mounts {
+		switch m.Device {
+		case "sysfs":
+			// sysfs can only be mounted by the owner of the network namespace
+			m.Source = "/sys"
+			m.Device = "bind"
+			m.Flags = unix.MS_BIND | unix.MS_REC | unix.MS_RDONLY | unix.MS_NOSUID | unix.MS_NOEXEC | unix.MS_NODEV
+		case "devpts":
+			// the tty group may not be mapped in the namespace
+			m.Data = "newinstance,ptmxmode=0666,mode=0620"
+		}
+	}
+}
+
+// rootlessOptions returns the factory options of a container started by a
+// user that isn't root
+func rootlessOptions() []func(*libcontainer.LinuxFactory) error {
+	options := []func(*libcontainer.LinuxFactory) error{libcontainer.RootlessCgroupfs}
+
+	// Mapping IDs other than the user's own requires the setuid helpers
+	if p, err := exec.LookPath("newuidmap"); err == nil {
+		options = append(options, libcontainer.NewuidmapPath(p))
+	}
+	if p, err := exec.LookPath("newgidmap"); err == nil {
+		options = append(options, libcontainer.NewgidmapPath(p))
+	}
+	return options
+}
+
+// libcontainerIDMappings converts ID mappings to libcontainer ID mappings
+func libcontainerIDMappings(mappings []IDMapping) []lconfigs.IDMap {
+	r := make([]lconfigs.IDMap, len(mappings))
+	for i, m := range mappings {
+		r[i] = lconfigs.IDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		}
+	}
+	return r
+}
+
+// cgroupManager configures a libcontainer factory to use the cgroup manager
+// matching the host's cgroup hierarchy
+func cgroupManager(l *libcontainer.LinuxFactory) error {
+	if cgutil.UseV2() {
+		l.NewCgroupsManager = cgutil.NewV2Manager
+		return nil
+	}
+	return libcontainer.Cgroupfs(l)
+}
+
+// joinRootCgroup moves the current process to the root cgroup of the host's
+// cgroup hierarchy
+func joinRootCgroup() error {
+	if cgutil.UseV2() {
+		return cgroups.WriteCgroupProc(cgutil.CgroupRoot, os.Getpid())
+	}
+
+	subsystems, err := cgroups.GetAllSubsystems()
+	if err != nil {
+		return err
+	}
+	return JoinRootCgroup(subsystems)
+}
+
 // JoinRootCgroup moves the current process to the cgroups of the init process
 func JoinRootCgroup(subsystems []string) error {
 	mErrs := new(multierror.Error)
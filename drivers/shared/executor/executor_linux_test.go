@@ -260,3 +260,68 @@ func TestExecutor_cmdMounts(t *testing.T) {
 
 	require.EqualValues(t, expected, cmdMounts(input))
 }
+
+func TestExecutor_configureUserNamespace(t *testing.T) {
+	require := require.New(t)
+
+	cfg := &lconfigs.Config{}
+	require.NoError(configureIsolation(cfg, &ExecCommand{TaskDir: "/tmp"}))
+
+	userNamespace := &UserNamespaceConfig{
+		UIDMappings: []IDMapping{{ContainerID: 0, HostID: 1000, Size: 1}},
+	}
+	configureUserNamespace(cfg, userNamespace, true)
+
+	require.True(cfg.Rootless)
+	require.True(cfg.Namespaces.Contains(lconfigs.NEWNS))
+	require.True(cfg.Namespaces.Contains(lconfigs.NEWUSER))
+	require.True(cfg.Namespaces.Contains(lconfigs.NEWPID))
+	require.Equal([]lconfigs.IDMap{{ContainerID: 0, HostID: 1000, Size: 1}}, cfg.UidMappings)
+	require.Equal([]lconfigs.IDMap{{ContainerID: 0, HostID: os.Getegid(), Size: 1}}, cfg.GidMappings)
+
+	for _, m := range cfg.Mounts {
+		switch m.Destination {
+		case "/sys":
+			require.Equal("bind", m.Device)
+			require.Equal("/sys", m.Source)
+			require.NotZero(m.Flags & unix.MS_RDONLY)
+		case "/dev/pts":
+			require.NotContains(m.Data, "gid=")
+		}
+	}
+}
+
+func TestUniversalExecutor_UserNamespace(t *testing.T) {
+	t.Parallel()
+	if !UserNamespacesSupported() {
+		t.Skip("user namespaces are not supported")
+	}
+	require := require.New(t)
+
+	execCmd, allocDir := testExecutorCommand(t)
+	execCmd.Cmd = "/bin/sh"
+	execCmd.Args = []string{"-c", "echo $(id -u) $$"}
+	defer allocDir.Destroy()
+
+	executor := NewExecutor(testlog.HCLogger(t))
+	executor.(*UniversalExecutor).setUserNamespace(&UserNamespaceConfig{})
+	defer executor.Shutdown("", 0)
+
+	_, err := executor.Launch(execCmd)
+	require.NoError(err)
+	ps, err := executor.Wait(context.Background())
+	require.NoError(err)
+	require.Zero(ps.ExitCode)
+
+	// The command runs as root of the namespace with PID 1
+	tu.WaitForResult(func() (bool, error) {
+		outWriter, _ := execCmd.GetWriters()
+		act := strings.TrimSpace(outWriter.(*bufferCloser).String())
+		if act != "0 1" {
+			return false, fmt.Errorf("expected: '0 1' actual: '%s'", act)
+		}
+		return true, nil
+	}, func(err error) {
+		require.NoError(err)
+	})
+}
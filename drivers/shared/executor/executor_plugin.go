@@ -12,16 +12,28 @@ import (
 type ExecutorPlugin struct {
 	// TODO: support backwards compatibility with pre 0.9 NetRPC plugin
 	plugin.NetRPCUnsupportedPlugin
-	logger      hclog.Logger
-	fsIsolation bool
+	logger        hclog.Logger
+	fsIsolation   bool
+	userNamespace *UserNamespaceConfig
+}
+
+// userNamespaceExecutor is an executor that can start its command in a user
+// namespace
+type userNamespaceExecutor interface {
+	setUserNamespace(*UserNamespaceConfig)
 }
 
 func (p *ExecutorPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	var impl Executor
 	if p.fsIsolation {
-		proto.RegisterExecutorServer(s, &grpcExecutorServer{impl: NewExecutorWithIsolation(p.logger)})
+		impl = NewExecutorWithIsolation(p.logger)
 	} else {
-		proto.RegisterExecutorServer(s, &grpcExecutorServer{impl: NewExecutor(p.logger)})
+		impl = NewExecutor(p.logger)
+	}
+	if p.userNamespace != nil {
+		impl.(userNamespaceExecutor).setUserNamespace(p.userNamespace)
 	}
+	proto.RegisterExecutorServer(s, &grpcExecutorServer{impl: impl})
 	return nil
 }
 
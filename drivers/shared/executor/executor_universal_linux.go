@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/client/lib/cgutil"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 	cgroupFs "github.com/opencontainers/runc/libcontainer/cgroups/fs"
@@ -64,6 +65,29 @@ func (e *UniversalExecutor) runAs(userid string) error {
 	return nil
 }
 
+// configureUserNamespace starts the command in new user, PID and mount
+// namespaces if the executor is configured to use a user namespace
+func (e *UniversalExecutor) configureUserNamespace() error {
+	if e.userNamespace == nil {
+		return nil
+	}
+
+	if e.childCmd.SysProcAttr == nil {
+		e.childCmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	e.childCmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWPID | syscall.CLONE_NEWNS
+	e.childCmd.SysProcAttr.UidMappings = sysProcIDMappings(e.userNamespace.uidMappings())
+	e.childCmd.SysProcAttr.GidMappings = sysProcIDMappings(e.userNamespace.gidMappings())
+
+	// An unprivileged process may only write the gid map once setgroups is
+	// denied
+	e.childCmd.SysProcAttr.GidMappingsEnableSetgroups = os.Geteuid() == 0
+
+	e.logger.Debug("launching command in a user namespace",
+		"uid_map", e.userNamespace.uidMappings(), "gid_map", e.userNamespace.gidMappings())
+	return nil
+}
+
 // configureResourceContainer configured the cgroups to be used to track pids
 // created by the executor
 func (e *UniversalExecutor) configureResourceContainer(pid int) error {
@@ -77,9 +101,24 @@ func (e *UniversalExecutor) configureResourceContainer(pid int) error {
 
 	configureBasicCgroups(cfg)
 	e.resConCtx.groups = cfg.Cgroups
+
+	if cgutil.UseV2() {
+		manager := cgutil.NewV2Manager(cfg.Cgroups, nil)
+		if err := manager.Apply(pid); err != nil {
+			return err
+		}
+		cfg.Cgroups.Paths = manager.GetPaths()
+		return nil
+	}
 	return cgroups.EnterPid(cfg.Cgroups.Paths, pid)
 }
 
+// assignResourceContainer is a no-op as the command joins the cgroups of the
+// executor when it's started
+func (e *UniversalExecutor) assignResourceContainer(_ int) error {
+	return nil
+}
+
 // DestroyCgroup kills all processes in the cgroup and removes the cgroup
 // configuration from the host. This function is idempotent.
 func DestroyCgroup(groups *lconfigs.Cgroup, executorPid int) error {
@@ -88,6 +127,10 @@ func DestroyCgroup(groups *lconfigs.Cgroup, executorPid int) error {
 		return fmt.Errorf("Can't destroy: cgroup configuration empty")
 	}
 
+	if cgutil.UseV2() {
+		return destroyCgroupV2(groups, executorPid)
+	}
+
 	// Move the executor into the global cgroup so that the task specific
 	// cgroup can be destroyed.
 	path, err := cgroups.GetInitCgroupPath("freezer")
@@ -154,3 +197,59 @@ func DestroyCgroup(groups *lconfigs.Cgroup, executorPid int) error {
 	}
 	return mErrs.ErrorOrNil()
 }
+
+// destroyCgroupV2 kills all processes in a cgroup of the unified hierarchy and
+// removes it.
+func destroyCgroupV2(groups *lconfigs.Cgroup, executorPid int) error {
+	mErrs := new(multierror.Error)
+
+	// Move the executor into the root cgroup so that the task specific
+	// cgroup can be destroyed.
+	if err := cgroups.WriteCgroupProc(cgutil.CgroupRoot, executorPid); err != nil {
+		return err
+	}
+
+	manager := cgutil.NewV2Manager(groups, groups.Paths)
+
+	// Freeze the Cgroup so that it can not continue to fork/exec.
+	if err := manager.Freeze(lconfigs.Frozen); err != nil {
+		return err
+	}
+
+	var procs []*os.Process
+	pids, err := manager.GetAllPids()
+	if err != nil {
+		multierror.Append(mErrs, fmt.Errorf("error getting pids: %v", err))
+	}
+
+	// Kill the processes in the cgroup
+	for _, pid := range pids {
+		proc, err := os.FindProcess(pid)
+		if err != nil {
+			multierror.Append(mErrs, fmt.Errorf("error finding process %v: %v", pid, err))
+			continue
+		}
+
+		procs = append(procs, proc)
+		if e := proc.Kill(); e != nil {
+			multierror.Append(mErrs, fmt.Errorf("error killing process %v: %v", pid, e))
+		}
+	}
+
+	// Unfreeze the cgroup so we can wait.
+	if err := manager.Freeze(lconfigs.Thawed); err != nil {
+		multierror.Append(mErrs, fmt.Errorf("failed to unfreeze cgroup: %v", err))
+		return mErrs.ErrorOrNil()
+	}
+
+	// Wait on the killed processes to ensure they are cleaned up.
+	for _, proc := range procs {
+		proc.Wait()
+	}
+
+	// Remove the cgroup.
+	if err := manager.Destroy(); err != nil {
+		multierror.Append(mErrs, fmt.Errorf("failed to delete the cgroup directories: %v", err))
+	}
+	return mErrs.ErrorOrNil()
+}
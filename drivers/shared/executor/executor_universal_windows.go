@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"fmt"
+	"syscall"
+
+	hclog "github.com/hashicorp/go-hclog"
+	shelpers "github.com/hashicorp/nomad/helper/stats"
+)
+
+func NewExecutorWithIsolation(logger hclog.Logger) Executor {
+	logger = logger.Named("executor")
+	logger.Error("isolation executor is not supported on this platform, using default")
+	return NewExecutor(logger)
+}
+
+func (e *UniversalExecutor) runAs(_ string) error { return nil }
+
+// configureResourceContainer creates the job object the command is assigned
+// to once it's started. The command is created suspended so that it can't
+// start processes outside of the job. The resource limits of the command are
+// enforced by the job object if configured.
+func (e *UniversalExecutor) configureResourceContainer(_ int) error {
+	if !(e.commandCfg.ResourceLimits || e.commandCfg.BasicProcessCgroup) {
+		return nil
+	}
+
+	job, err := createJobObject()
+	if err != nil {
+		return err
+	}
+
+	if e.commandCfg.ResourceLimits {
+		if err := e.limitJobObject(job); err != nil {
+			syscall.CloseHandle(job)
+			return err
+		}
+	}
+
+	e.resConCtx.jobLock.Lock()
+	e.resConCtx.job = job
+	e.resConCtx.jobLock.Unlock()
+
+	if e.childCmd.SysProcAttr == nil {
+		e.childCmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	e.childCmd.SysProcAttr.CreationFlags |= createSuspended
+	return nil
+}
+
+// limitJobObject sets the memory and CPU limits of the job object from the
+// resources of the task
+func (e *UniversalExecutor) limitJobObject(job syscall.Handle) error {
+	if e.commandCfg.Resources == nil || e.commandCfg.Resources.NomadResources == nil {
+		return nil
+	}
+	res := e.commandCfg.Resources.NomadResources
+
+	if mb := res.Memory.MemoryMB; mb > 0 {
+		if err := setJobObjectMemoryLimit(job, uint64(mb)*1024*1024); err != nil {
+			return fmt.Errorf("failed to set memory limit: %v", err)
+		}
+	}
+
+	if total := shelpers.TotalTicksAvailable(); res.Cpu.CpuShares > 0 && total > 0 {
+		if err := setJobObjectCpuRate(job, jobCpuRate(res.Cpu.CpuShares, total)); err != nil {
+			return fmt.Errorf("failed to set CPU limit: %v", err)
+		}
+	}
+
+	e.logger.Debug("configured job object limits",
+		"memory_mb", res.Memory.MemoryMB, "cpu_shares", res.Cpu.CpuShares)
+	return nil
+}
+
+// assignResourceContainer assigns the suspended command to its job object and
+// resumes it
+func (e *UniversalExecutor) assignResourceContainer(pid int) error {
+	e.resConCtx.jobLock.Lock()
+	defer e.resConCtx.jobLock.Unlock()
+	if e.resConCtx.job == 0 {
+		return nil
+	}
+
+	// Commands run by Exec reuse the attributes and must not be suspended
+	e.childCmd.SysProcAttr.CreationFlags &^= createSuspended
+	return assignAndResumeProcess(e.resConCtx.job, pid)
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// jobObjectExtendedLimitInformation and
+	// jobObjectCpuRateControlInformation are the information classes of
+	// SetInformationJobObject
+	jobObjectExtendedLimitInformation  = 9
+	jobObjectCpuRateControlInformation = 15
+
+	// jobObjectLimitJobMemory limits the committed memory of the job
+	jobObjectLimitJobMemory = 0x200
+
+	// jobObjectCpuRateControlEnable and jobObjectCpuRateControlHardCap cap
+	// the CPU time of the job at its CPU rate
+	jobObjectCpuRateControlEnable  = 0x1
+	jobObjectCpuRateControlHardCap = 0x4
+
+	// processSetQuota and processSuspendResume are the access rights needed
+	// to assign a process to a job and resume it
+	processSetQuota      = 0x0100
+	processSuspendResume = 0x0800
+
+	// createSuspended creates a process with its main thread suspended
+	createSuspended = 0x4
+)
+
+var (
+	modkernel32 = syscall.NewLazyDLL("kernel32.dll")
+	modntdll    = syscall.NewLazyDLL("ntdll.dll")
+
+	procCreateJobObjectW         = modkernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = modkernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = modkernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = modkernel32.NewProc("TerminateJobObject")
+	procNtResumeProcess          = modntdll.NewProc("NtResumeProcess")
+)
+
+// jobObjectBasicLimitInformation is JOBOBJECT_BASIC_LIMIT_INFORMATION
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+// ioCounters is IO_COUNTERS
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+// jobObjectExtendedLimitInfo is JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+type jobObjectExtendedLimitInfo struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// jobObjectCpuRateControlInfo is JOBOBJECT_CPU_RATE_CONTROL_INFORMATION
+type jobObjectCpuRateControlInfo struct {
+	ControlFlags uint32
+	CpuRate      uint32
+}
+
+// createJobObject creates an anonymous job object
+func createJobObject() (syscall.Handle, error) {
+	r, _, err := procCreateJobObjectW.Call(0, 0)
+	if r == 0 {
+		return 0, os.NewSyscallError("CreateJobObject", err)
+	}
+	return syscall.Handle(r), nil
+}
+
+// setJobObjectMemoryLimit limits the memory committed by all the processes
+// of the job
+func setJobObjectMemoryLimit(job syscall.Handle, bytes uint64) error {
+	info := jobObjectExtendedLimitInfo{
+		BasicLimitInformation: jobObjectBasicLimitInformation{
+			LimitFlags: jobObjectLimitJobMemory,
+		},
+		JobMemoryLimit: uintptr(bytes),
+	}
+	r, _, err := procSetInformationJobObject.Call(uintptr(job), jobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	if r == 0 {
+		return os.NewSyscallError("SetInformationJobObject", err)
+	}
+	return nil
+}
+
+// setJobObjectCpuRate caps the CPU time of all the processes of the job. The
+// rate is in hundredths of a percent of the CPU time of the host.
+func setJobObjectCpuRate(job syscall.Handle, rate uint32) error {
+	info := jobObjectCpuRateControlInfo{
+		ControlFlags: jobObjectCpuRateControlEnable | jobObjectCpuRateControlHardCap,
+		CpuRate:      rate,
+	}
+	r, _, err := procSetInformationJobObject.Call(uintptr(job), jobObjectCpuRateControlInformation,
+		uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	if r == 0 {
+		return os.NewSyscallError("SetInformationJobObject", err)
+	}
+	return nil
+}
+
+// assignAndResumeProcess assigns a process created suspended to the job and
+// then resumes it, so that all of its children are created in the job
+func assignAndResumeProcess(job syscall.Handle, pid int) error {
+	proc, err := syscall.OpenProcess(processSetQuota|syscall.PROCESS_TERMINATE|processSuspendResume, false, uint32(pid))
+	if err != nil {
+		return os.NewSyscallError("OpenProcess", err)
+	}
+	defer syscall.CloseHandle(proc)
+
+	if r, _, err := procAssignProcessToJobObject.Call(uintptr(job), uintptr(proc)); r == 0 {
+		return os.NewSyscallError("AssignProcessToJobObject", err)
+	}
+	if status, _, _ := procNtResumeProcess.Call(uintptr(proc)); status != 0 {
+		return fmt.Errorf("NtResumeProcess failed with status 0x%x", status)
+	}
+	return nil
+}
+
+// terminateJobObject kills all the processes of the job
+func terminateJobObject(job syscall.Handle) error {
+	if r, _, err := procTerminateJobObject.Call(uintptr(job), 1); r == 0 {
+		return os.NewSyscallError("TerminateJobObject", err)
+	}
+	return nil
+}
+
+// jobCpuRate converts CPU shares in MHz to the rate of a job object in
+// hundredths of a percent of the total MHz of the host
+func jobCpuRate(shares int64, totalMHz float64) uint32 {
+	rate := uint32(float64(shares) / totalMHz * 10000)
+	if rate < 1 {
+		return 1
+	}
+	if rate > 10000 {
+		return 10000
+	}
+	return rate
+}
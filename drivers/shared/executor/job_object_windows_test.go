@@ -0,0 +1,16 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobCpuRate(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.EqualValues(2500, jobCpuRate(1000, 4000))
+	require.EqualValues(1, jobCpuRate(0, 4000))
+	require.EqualValues(10000, jobCpuRate(8000, 4000))
+}
@@ -19,13 +19,17 @@ type ExecutorConfig struct {
 	// FSIsolation if set will use an executor implementation that support
 	// filesystem isolation
 	FSIsolation bool
+
+	// UserNamespace if set will start the command in a user namespace
+	UserNamespace *UserNamespaceConfig
 }
 
-func GetPluginMap(logger hclog.Logger, fsIsolation bool) map[string]plugin.Plugin {
+func GetPluginMap(logger hclog.Logger, fsIsolation bool, userNamespace *UserNamespaceConfig) map[string]plugin.Plugin {
 	return map[string]plugin.Plugin{
 		"executor": &ExecutorPlugin{
-			logger:      logger,
-			fsIsolation: fsIsolation,
+			logger:        logger,
+			fsIsolation:   fsIsolation,
+			userNamespace: userNamespace,
 		},
 	}
 }
@@ -1,4 +1,4 @@
-// +build darwin dragonfly freebsd netbsd openbsd solaris windows
+// +build darwin dragonfly freebsd netbsd openbsd solaris
 
 package executor
 
@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"sync"
+	"syscall"
+)
+
+// resourceContainerContext is a platform-specific struct for managing a
+// resource container. In the case of Windows, this is a job object.
+type resourceContainerContext struct {
+	job     syscall.Handle
+	jobLock sync.Mutex
+}
+
+// executorCleanup kills the processes of the job object and closes it
+func (rc *resourceContainerContext) executorCleanup() error {
+	rc.jobLock.Lock()
+	defer rc.jobLock.Unlock()
+	if rc.job == 0 {
+		return nil
+	}
+
+	err := terminateJobObject(rc.job)
+	syscall.CloseHandle(rc.job)
+	rc.job = 0
+	return err
+}
@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+)
+
+var (
+	// UserNamespaceSpec is the hcl specification of the user_namespace block
+	// the exec and raw_exec drivers embed in their plugin configuration.
+	// Example:
+	//	user_namespace {
+	//		enabled = true
+	//		uid_map = ["0:1000:1", "1:100000:65536"]
+	//		gid_map = ["0:1000:1", "1:100000:65536"]
+	//	}
+	UserNamespaceSpec = hclspec.NewBlock("user_namespace", false, hclspec.NewObject(map[string]*hclspec.Spec{
+		"enabled": hclspec.NewAttr("enabled", "bool", false),
+		"uid_map": hclspec.NewAttr("uid_map", "list(string)", false),
+		"gid_map": hclspec.NewAttr("gid_map", "list(string)", false),
+	}))
+)
+
+// UserNamespacePluginConfig is the decoded user_namespace block.
+type UserNamespacePluginConfig struct {
+	Enabled bool     `codec:"enabled"`
+	UIDMap  []string `codec:"uid_map"`
+	GIDMap  []string `codec:"gid_map"`
+}
+
+// Config returns the user namespace configuration of the executor described
+// by the block or nil if user namespaces are disabled.
+func (c *UserNamespacePluginConfig) Config() (*UserNamespaceConfig, error) {
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+
+	var mErr multierror.Error
+	uids, err := parseIDMappings(c.UIDMap)
+	if err != nil {
+		multierror.Append(&mErr, fmt.Errorf("invalid uid_map: %v", err))
+	}
+	gids, err := parseIDMappings(c.GIDMap)
+	if err != nil {
+		multierror.Append(&mErr, fmt.Errorf("invalid gid_map: %v", err))
+	}
+	if err := mErr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	return &UserNamespaceConfig{
+		UIDMappings: uids,
+		GIDMappings: gids,
+	}, nil
+}
+
+// UserNamespaceConfig configures the executor to run the task in new user
+// and PID namespaces. This lets a client that isn't running as root isolate
+// the filesystem and the processes of its tasks.
+type UserNamespaceConfig struct {
+	// UIDMappings maps the user IDs of the namespace to user IDs of the host.
+	// If empty, root of the namespace is mapped to the user running the
+	// executor.
+	UIDMappings []IDMapping
+
+	// GIDMappings maps the group IDs of the namespace to group IDs of the
+	// host. If empty, the root group of the namespace is mapped to the group
+	// running the executor.
+	GIDMappings []IDMapping
+}
+
+// IDMapping maps a range of IDs of a user namespace to IDs of the host.
+type IDMapping struct {
+	ContainerID int
+	HostID      int
+	Size        int
+}
+
+func (m IDMapping) String() string {
+	return fmt.Sprintf("%d:%d:%d", m.ContainerID, m.HostID, m.Size)
+}
+
+// uidMappings returns the user ID mappings, defaulting to mapping root to the
+// effective user of the executor.
+func (c *UserNamespaceConfig) uidMappings() []IDMapping {
+	if len(c.UIDMappings) == 0 {
+		return []IDMapping{{ContainerID: 0, HostID: os.Geteuid(), Size: 1}}
+	}
+	return c.UIDMappings
+}
+
+// gidMappings returns the group ID mappings, defaulting to mapping the root
+// group to the effective group of the executor.
+func (c *UserNamespaceConfig) gidMappings() []IDMapping {
+	if len(c.GIDMappings) == 0 {
+		return []IDMapping{{ContainerID: 0, HostID: os.Getegid(), Size: 1}}
+	}
+	return c.GIDMappings
+}
+
+// ParseIDMapping parses an ID mapping of the form
+// "<container_id>:<host_id>:<size>".
+func ParseIDMapping(s string) (IDMapping, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return IDMapping{}, fmt.Errorf("mapping %q must be of the form <container_id>:<host_id>:<size>", s)
+	}
+
+	ids := make([]int, len(parts))
+	for i, p := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || id < 0 {
+			return IDMapping{}, fmt.Errorf("mapping %q must contain non-negative integers", s)
+		}
+		ids[i] = id
+	}
+	if ids[2] == 0 {
+		return IDMapping{}, fmt.Errorf("mapping %q must have a size greater than zero", s)
+	}
+
+	return IDMapping{ContainerID: ids[0], HostID: ids[1], Size: ids[2]}, nil
+}
+
+// parseIDMappings parses a list of ID mappings and checks that the ranges of
+// the namespace don't overlap.
+func parseIDMappings(raw []string) ([]IDMapping, error) {
+	mappings := make([]IDMapping, 0, len(raw))
+	for _, s := range raw {
+		m, err := ParseIDMapping(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range mappings {
+			if m.ContainerID < o.ContainerID+o.Size && o.ContainerID < m.ContainerID+m.Size {
+				return nil, fmt.Errorf("mappings %q and %q overlap", o, m)
+			}
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
@@ -0,0 +1,20 @@
+// +build !linux
+
+package executor
+
+import "fmt"
+
+// UserNamespacesSupported returns whether the kernel lets the current user
+// create user namespaces.
+func UserNamespacesSupported() bool {
+	return false
+}
+
+// configureUserNamespace returns an error as user namespaces are only
+// supported on Linux.
+func (e *UniversalExecutor) configureUserNamespace() error {
+	if e.userNamespace == nil {
+		return nil
+	}
+	return fmt.Errorf("user namespaces are not supported on this platform")
+}
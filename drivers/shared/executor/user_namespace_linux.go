@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// UserNamespacesSupported returns whether the kernel lets the current user
+// create user namespaces.
+func UserNamespacesSupported() bool {
+	if _, err := os.Stat("/proc/self/ns/user"); err != nil {
+		return false
+	}
+
+	// Distributions may limit or disable unprivileged user namespaces
+	if b, err := ioutil.ReadFile("/proc/sys/user/max_user_namespaces"); err == nil && strings.TrimSpace(string(b)) == "0" {
+		return false
+	}
+	if os.Geteuid() != 0 {
+		if b, err := ioutil.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil && strings.TrimSpace(string(b)) == "0" {
+			return false
+		}
+	}
+	return true
+}
+
+// sysProcIDMappings converts ID mappings to the form used by the syscall
+// package.
+func sysProcIDMappings(mappings []IDMapping) []syscall.SysProcIDMap {
+	r := make([]syscall.SysProcIDMap, len(mappings))
+	for i, m := range mappings {
+		r[i] = syscall.SysProcIDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		}
+	}
+	return r
+}
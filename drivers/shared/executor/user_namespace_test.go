@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIDMapping(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	m, err := ParseIDMapping("1:100000:65536")
+	require.NoError(err)
+	require.Equal(IDMapping{ContainerID: 1, HostID: 100000, Size: 65536}, m)
+	require.Equal("1:100000:65536", m.String())
+
+	for _, s := range []string{"", "0:1000", "0:1000:1:1", "a:1000:1", "-1:1000:1", "0:1000:0"} {
+		_, err := ParseIDMapping(s)
+		require.Error(err, s)
+	}
+}
+
+func TestUserNamespacePluginConfig_Config(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Disabled
+	var c *UserNamespacePluginConfig
+	out, err := c.Config()
+	require.NoError(err)
+	require.Nil(out)
+
+	c = &UserNamespacePluginConfig{UIDMap: []string{"0:1000:1"}}
+	out, err = c.Config()
+	require.NoError(err)
+	require.Nil(out)
+
+	// Default mappings
+	c.Enabled = true
+	c.UIDMap = nil
+	out, err = c.Config()
+	require.NoError(err)
+	require.Equal([]IDMapping{{ContainerID: 0, HostID: os.Geteuid(), Size: 1}}, out.uidMappings())
+	require.Equal([]IDMapping{{ContainerID: 0, HostID: os.Getegid(), Size: 1}}, out.gidMappings())
+
+	// Configured mappings
+	c.UIDMap = []string{"0:1000:1", "1:100000:65536"}
+	c.GIDMap = []string{"0:1000:1"}
+	out, err = c.Config()
+	require.NoError(err)
+	require.Len(out.uidMappings(), 2)
+	require.Equal([]IDMapping{{ContainerID: 0, HostID: 1000, Size: 1}}, out.gidMappings())
+
+	// Overlapping mappings
+	c.UIDMap = []string{"0:1000:10", "5:100000:65536"}
+	_, err = c.Config()
+	require.Error(err)
+	require.Contains(err.Error(), "overlap")
+}
@@ -0,0 +1,285 @@
+package imagegc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+)
+
+const (
+	// defaultInterval is how often images are pruned if no interval is
+	// configured
+	defaultInterval = 5 * time.Minute
+
+	// defaultMinAge is how long an image must be unused before it may be
+	// pruned if no minimum age is configured
+	defaultMinAge = time.Hour
+)
+
+var (
+	// ConfigSpec is the hcl specification of the prune block drivers embed
+	// in their gc configuration.
+	// Example:
+	//	prune {
+	//		enabled = true
+	//		interval = "5m"
+	//		min_age = "1h"
+	//		max_disk_usage_mb = 20480
+	//		protect_in_use = true
+	//	}
+	ConfigSpec = hclspec.NewBlock("prune", false, hclspec.NewObject(map[string]*hclspec.Spec{
+		"enabled":           hclspec.NewAttr("enabled", "bool", false),
+		"interval":          hclspec.NewAttr("interval", "string", false),
+		"min_age":           hclspec.NewAttr("min_age", "string", false),
+		"max_disk_usage_mb": hclspec.NewAttr("max_disk_usage_mb", "number", false),
+		"protect_in_use": hclspec.NewDefault(
+			hclspec.NewAttr("protect_in_use", "bool", false),
+			hclspec.NewLiteral("true"),
+		),
+	}))
+)
+
+// Config is the decoded prune block.
+type Config struct {
+	Enabled        bool   `codec:"enabled"`
+	Interval       string `codec:"interval"`
+	MinAge         string `codec:"min_age"`
+	MaxDiskUsageMB int64  `codec:"max_disk_usage_mb"`
+	ProtectInUse   bool   `codec:"protect_in_use"`
+}
+
+// Policy returns the pruning policy described by the config or nil if
+// pruning is disabled.
+func (c *Config) Policy() (*Policy, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	p := &Policy{
+		Interval:     defaultInterval,
+		MinAge:       defaultMinAge,
+		ProtectInUse: c.ProtectInUse,
+	}
+
+	if c.Interval != "" {
+		dur, err := time.ParseDuration(c.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse 'interval' duration: %v", err)
+		}
+		if dur <= 0 {
+			return nil, fmt.Errorf("'interval' must be positive: %q", c.Interval)
+		}
+		p.Interval = dur
+	}
+
+	if c.MinAge != "" {
+		dur, err := time.ParseDuration(c.MinAge)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse 'min_age' duration: %v", err)
+		}
+		if dur < 0 {
+			return nil, fmt.Errorf("'min_age' must not be negative: %q", c.MinAge)
+		}
+		p.MinAge = dur
+	}
+
+	if c.MaxDiskUsageMB < 0 {
+		return nil, fmt.Errorf("'max_disk_usage_mb' must not be negative: %d", c.MaxDiskUsageMB)
+	}
+	p.MaxDiskUsage = c.MaxDiskUsageMB * 1024 * 1024
+
+	return p, nil
+}
+
+// Policy controls which images are pruned.
+type Policy struct {
+	// Interval is how often images are pruned
+	Interval time.Duration
+
+	// MinAge is how long an image must be unused before it may be pruned
+	MinAge time.Duration
+
+	// MaxDiskUsage is the number of bytes images may use before unused
+	// images are pruned, least recently used first. If zero, every unused
+	// image older than MinAge is pruned.
+	MaxDiskUsage int64
+
+	// ProtectInUse prevents pruning images used by stopped containers.
+	// Images used by running containers are never pruned.
+	ProtectInUse bool
+}
+
+// Image describes an image present on the client.
+type Image struct {
+	ID string
+
+	// Size is the disk space used by the image in bytes
+	Size int64
+
+	// InUse is set if any container was created from the image
+	InUse bool
+
+	// Running is set if a running container was created from the image
+	Running bool
+}
+
+// ImageClient is implemented by drivers to list and remove their images.
+type ImageClient interface {
+	// ListImages returns all images along with whether they are used by
+	// containers.
+	ListImages() ([]*Image, error)
+
+	// RemoveImage removes the image. Force is set when removing an image
+	// that is used by stopped containers.
+	RemoveImage(id string, force bool) error
+}
+
+// Pruner periodically removes unused images according to a Policy.
+type Pruner struct {
+	policy *Policy
+	client ImageClient
+	labels []metrics.Label
+	logger hclog.Logger
+
+	// lastUsed is the last time each image was seen used or, for images
+	// never seen used, the first time it was seen. It is reset when the
+	// pruner is created, so images are not pruned until MinAge after the
+	// driver starts.
+	lastUsed map[string]time.Time
+	lock     sync.Mutex
+}
+
+// NewPruner returns a pruner for the named driver's images.
+func NewPruner(logger hclog.Logger, driver string, policy *Policy, client ImageClient) *Pruner {
+	return &Pruner{
+		policy:   policy,
+		client:   client,
+		labels:   []metrics.Label{{Name: "driver", Value: driver}},
+		logger:   logger.Named("image_gc"),
+		lastUsed: make(map[string]time.Time),
+	}
+}
+
+// Run prunes images every interval until the context is canceled.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, _, err := p.Prune(); err != nil {
+			p.logger.Warn("failed to prune images", "error", err)
+		}
+	}
+}
+
+// Touch marks the image as used now. Drivers call it when a task is about
+// to use an image so it is not pruned before its container is created.
+func (p *Pruner) Touch(id string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.lastUsed[id] = time.Now()
+}
+
+// Prune removes the images eligible under the policy and returns the number
+// of images removed and the bytes reclaimed.
+func (p *Pruner) Prune() (int, int64, error) {
+	images, err := p.client.ListImages()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	candidates, total := p.candidates(images, time.Now())
+
+	removed := 0
+	var reclaimed int64
+	for _, image := range candidates {
+		if p.policy.MaxDiskUsage > 0 && total <= p.policy.MaxDiskUsage {
+			break
+		}
+
+		if err := p.client.RemoveImage(image.ID, image.InUse); err != nil {
+			p.logger.Debug("failed to remove image", "image_id", image.ID, "error", err)
+			continue
+		}
+
+		p.logger.Debug("pruned image", "image_id", image.ID, "size", image.Size)
+		p.lock.Lock()
+		delete(p.lastUsed, image.ID)
+		p.lock.Unlock()
+
+		total -= image.Size
+		reclaimed += image.Size
+		removed++
+	}
+
+	if removed > 0 {
+		p.logger.Info("pruned images", "removed", removed, "reclaimed_bytes", reclaimed)
+	}
+	metrics.IncrCounterWithLabels([]string{"client", "image_gc", "removed"}, float32(removed), p.labels)
+	metrics.IncrCounterWithLabels([]string{"client", "image_gc", "reclaimed_bytes"}, float32(reclaimed), p.labels)
+	metrics.SetGaugeWithLabels([]string{"client", "image_gc", "disk_usage"}, float32(total), p.labels)
+
+	return removed, reclaimed, nil
+}
+
+// candidates updates when images were last used and returns the images that
+// may be pruned, least recently used first, along with the total size of all
+// images.
+func (p *Pruner) candidates(images []*Image, now time.Time) ([]*Image, int64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	present := make(map[string]struct{}, len(images))
+	var total int64
+	var candidates []*Image
+	for _, image := range images {
+		present[image.ID] = struct{}{}
+		total += image.Size
+
+		if image.Running || (image.InUse && p.policy.ProtectInUse) {
+			p.lastUsed[image.ID] = now
+			continue
+		}
+
+		lastUsed, ok := p.lastUsed[image.ID]
+		if !ok {
+			p.lastUsed[image.ID] = now
+			lastUsed = now
+		}
+		if now.Sub(lastUsed) < p.policy.MinAge {
+			continue
+		}
+
+		candidates = append(candidates, image)
+	}
+
+	// Forget images that are no longer present. Recently touched images are
+	// kept as they may still be being pulled.
+	for id, lastUsed := range p.lastUsed {
+		if _, ok := present[id]; !ok && now.Sub(lastUsed) >= p.policy.MinAge {
+			delete(p.lastUsed, id)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := p.lastUsed[candidates[i].ID], p.lastUsed[candidates[j].ID]
+		if a.Equal(b) {
+			return candidates[i].ID < candidates[j].ID
+		}
+		return a.Before(b)
+	})
+
+	return candidates, total
+}
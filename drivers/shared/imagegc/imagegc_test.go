@@ -0,0 +1,162 @@
+package imagegc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+// mockImageClient implements ImageClient and records removed images
+type mockImageClient struct {
+	images  []*Image
+	removed []string
+	forced  map[string]bool
+	failIDs map[string]struct{}
+	mu      sync.Mutex
+}
+
+func (m *mockImageClient) ListImages() ([]*Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.images, nil
+}
+
+func (m *mockImageClient) RemoveImage(id string, force bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.failIDs[id]; ok {
+		return fmt.Errorf("image %q is in use", id)
+	}
+	m.removed = append(m.removed, id)
+	if m.forced == nil {
+		m.forced = make(map[string]bool)
+	}
+	m.forced[id] = force
+	return nil
+}
+
+// newTestPruner returns a pruner for the given images, which were all last
+// used an hour ago.
+func newTestPruner(t *testing.T, policy *Policy, images []*Image) (*Pruner, *mockImageClient) {
+	client := &mockImageClient{images: images}
+	p := NewPruner(testlog.HCLogger(t), "mock", policy, client)
+	for _, image := range images {
+		p.lastUsed[image.ID] = time.Now().Add(-time.Hour)
+	}
+	return p, client
+}
+
+func TestConfig_Policy(t *testing.T) {
+	require := require.New(t)
+
+	p, err := (&Config{}).Policy()
+	require.NoError(err)
+	require.Nil(p)
+
+	p, err = (&Config{Enabled: true, ProtectInUse: true}).Policy()
+	require.NoError(err)
+	require.Equal(&Policy{
+		Interval:     defaultInterval,
+		MinAge:       defaultMinAge,
+		ProtectInUse: true,
+	}, p)
+
+	p, err = (&Config{
+		Enabled:        true,
+		Interval:       "10m",
+		MinAge:         "24h",
+		MaxDiskUsageMB: 100,
+	}).Policy()
+	require.NoError(err)
+	require.Equal(&Policy{
+		Interval:     10 * time.Minute,
+		MinAge:       24 * time.Hour,
+		MaxDiskUsage: 100 * 1024 * 1024,
+	}, p)
+
+	_, err = (&Config{Enabled: true, Interval: "0s"}).Policy()
+	require.Error(err)
+	_, err = (&Config{Enabled: true, MinAge: "foo"}).Policy()
+	require.Error(err)
+	_, err = (&Config{Enabled: true, MaxDiskUsageMB: -1}).Policy()
+	require.Error(err)
+}
+
+func TestPruner_Prune(t *testing.T) {
+	require := require.New(t)
+
+	policy := &Policy{MinAge: time.Minute, ProtectInUse: true}
+	p, client := newTestPruner(t, policy, []*Image{
+		{ID: "unused", Size: 100},
+		{ID: "stopped", Size: 200, InUse: true},
+		{ID: "running", Size: 400, InUse: true, Running: true},
+		{ID: "recent", Size: 800},
+	})
+	p.Touch("recent")
+
+	removed, reclaimed, err := p.Prune()
+	require.NoError(err)
+	require.Equal(1, removed)
+	require.Equal(int64(100), reclaimed)
+	require.Equal([]string{"unused"}, client.removed)
+	require.False(client.forced["unused"])
+
+	// Without in use protection images of stopped containers are forcibly
+	// removed
+	policy.ProtectInUse = false
+	client.images = client.images[1:]
+	p.lastUsed["stopped"] = time.Now().Add(-time.Hour)
+	removed, reclaimed, err = p.Prune()
+	require.NoError(err)
+	require.Equal(1, removed)
+	require.Equal(int64(200), reclaimed)
+	require.Equal([]string{"unused", "stopped"}, client.removed)
+	require.True(client.forced["stopped"])
+}
+
+func TestPruner_Prune_MaxDiskUsage(t *testing.T) {
+	require := require.New(t)
+
+	policy := &Policy{MaxDiskUsage: 1000}
+	p, client := newTestPruner(t, policy, []*Image{
+		{ID: "a", Size: 400},
+		{ID: "b", Size: 400},
+		{ID: "c", Size: 400},
+		{ID: "d", Size: 400},
+	})
+	p.lastUsed["c"] = time.Now().Add(-2 * time.Hour)
+	p.lastUsed["d"] = time.Now().Add(-3 * time.Hour)
+	client.failIDs = map[string]struct{}{"d": {}}
+
+	// Least recently used images are removed until usage is under the
+	// limit, skipping images that fail to be removed
+	removed, reclaimed, err := p.Prune()
+	require.NoError(err)
+	require.Equal(2, removed)
+	require.Equal(int64(800), reclaimed)
+	require.Equal([]string{"c", "a"}, client.removed)
+}
+
+func TestPruner_Candidates_ForgetsImages(t *testing.T) {
+	require := require.New(t)
+
+	p, _ := newTestPruner(t, &Policy{MinAge: time.Minute}, nil)
+	now := time.Now()
+	p.lastUsed["gone"] = now.Add(-time.Hour)
+	p.lastUsed["pulling"] = now
+
+	candidates, total := p.candidates([]*Image{{ID: "new", Size: 10}}, now)
+	require.Empty(candidates)
+	require.Equal(int64(10), total)
+
+	// Newly seen images must be unused for the minimum age
+	require.Equal(now, p.lastUsed["new"])
+
+	// Removed images are forgotten unless recently touched
+	require.NotContains(p.lastUsed, "gone")
+	require.Contains(p.lastUsed, "pulling")
+}
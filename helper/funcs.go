@@ -355,6 +355,11 @@ func CheckHCLKeys(node ast.Node, valid []string) error {
 	for _, item := range list.Items {
 		key := item.Keys[0].Token.Value().(string)
 		if _, ok := validMap[key]; !ok {
+			if pos := item.Pos(); pos.IsValid() {
+				result = multierror.Append(result, fmt.Errorf(
+					"invalid key: %s (line %d, column %d)", key, pos.Line, pos.Column))
+				continue
+			}
 			result = multierror.Append(result, fmt.Errorf(
 				"invalid key: %s", key))
 		}
@@ -362,3 +367,42 @@ func CheckHCLKeys(node ast.Node, valid []string) error {
 
 	return result
 }
+
+// ClosestString returns the candidate most similar to s, or an empty string if
+// none is similar enough to be a likely misspelling of it. It is used to
+// suggest corrections for mistyped values.
+func ClosestString(s string, candidates []string) string {
+	closest, best := "", -1
+	for _, c := range candidates {
+		d := levenshtein(s, c)
+		if d > 2 && d > len(c)/3 {
+			continue
+		}
+		if best == -1 || d < best {
+			closest, best = c, d
+		}
+	}
+	return closest
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = IntMin(IntMin(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -87,3 +87,22 @@ func BenchmarkCleanEnvVar(b *testing.B) {
 		CleanEnvVar(in, replacement)
 	}
 }
+
+func TestClosestString(t *testing.T) {
+	candidates := []string{"set_contains", "set_contains_any", "regexp", "version", "<="}
+	cases := map[string]string{
+		"set_contain":  "set_contains",
+		"setcontains":  "set_contains",
+		"set_contans":  "set_contains",
+		"regex":        "regexp",
+		"verison":      "version",
+		"=<":           "<=",
+		"distinct":     "",
+		"set_contains": "set_contains",
+	}
+	for in, expected := range cases {
+		if out := ClosestString(in, candidates); out != expected {
+			t.Fatalf("expected %q for %q, got %q", expected, in, out)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+// Package jsonschema validates JSON documents against a subset of JSON Schema.
+//
+// The supported keywords are type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum and exclusiveMaximum. The
+// annotation keywords $schema, $id, title, description, default and examples
+// are accepted and ignored. Any other keyword is rejected when parsing the
+// schema, so that constraints are never silently skipped.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// annotations are the keywords that don't constrain the document.
+var annotations = map[string]struct{}{
+	"$schema":     {},
+	"$id":         {},
+	"title":       {},
+	"description": {},
+	"default":     {},
+	"examples":    {},
+}
+
+// types are the valid values of the type keyword.
+var types = map[string]struct{}{
+	"null":    {},
+	"boolean": {},
+	"object":  {},
+	"array":   {},
+	"number":  {},
+	"integer": {},
+	"string":  {},
+}
+
+// Schema is a parsed JSON schema.
+type Schema struct {
+	types []string
+	enum  []interface{}
+	cnst  *interface{}
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	noAdditional         bool
+
+	items    *Schema
+	minItems *int
+	maxItems *int
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+}
+
+// Parse parses a JSON schema document.
+func Parse(doc []byte) (*Schema, error) {
+	v, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	return parse("$", v)
+}
+
+// Validate validates a JSON document against the schema. All the violations
+// are returned, each prefixed by the path of the offending value.
+func (s *Schema) Validate(doc []byte) error {
+	v, err := decode(doc)
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	var mErr multierror.Error
+	s.validate("$", v, &mErr)
+	return mErr.ErrorOrNil()
+}
+
+// decode decodes a single JSON value, keeping numbers as json.Number.
+func decode(doc []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return v, nil
+}
+
+func parse(path string, v interface{}) (*Schema, error) {
+	// The boolean schemas accept everything or nothing
+	if b, ok := v.(bool); ok {
+		s := &Schema{}
+		if !b {
+			s.enum = []interface{}{}
+		}
+		return s, nil
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean", path)
+	}
+
+	// Parse the keywords in a stable order so errors are deterministic
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	s := &Schema{}
+	for _, k := range keys {
+		if err := s.parseKeyword(path, k, obj[k]); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) parseKeyword(path, k string, v interface{}) error {
+	var err error
+	switch k {
+	case "type":
+		s.types, err = parseTypes(path, v)
+	case "enum":
+		list, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: enum must be an array", path)
+		}
+		s.enum = list
+	case "const":
+		s.cnst = &v
+	case "properties":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: properties must be an object", path)
+		}
+		s.properties = make(map[string]*Schema, len(obj))
+		for name, prop := range obj {
+			if s.properties[name], err = parse(path+"."+name, prop); err != nil {
+				return err
+			}
+		}
+	case "required":
+		s.required, err = parseStrings(path, k, v)
+	case "additionalProperties":
+		if b, ok := v.(bool); ok {
+			s.noAdditional = !b
+			return nil
+		}
+		s.additionalProperties, err = parse(path+".*", v)
+	case "items":
+		s.items, err = parse(path+"[]", v)
+	case "minItems":
+		s.minItems, err = parseCount(path, k, v)
+	case "maxItems":
+		s.maxItems, err = parseCount(path, k, v)
+	case "minLength":
+		s.minLength, err = parseCount(path, k, v)
+	case "maxLength":
+		s.maxLength, err = parseCount(path, k, v)
+	case "pattern":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: pattern must be a string", path)
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %v", path, str, err)
+		}
+	case "minimum":
+		s.minimum, err = parseNumber(path, k, v)
+	case "maximum":
+		s.maximum, err = parseNumber(path, k, v)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = parseNumber(path, k, v)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = parseNumber(path, k, v)
+	default:
+		if _, ok := annotations[k]; !ok {
+			return fmt.Errorf("%s: unsupported keyword %q", path, k)
+		}
+	}
+	return err
+}
+
+func parseTypes(path string, v interface{}) ([]string, error) {
+	var list []string
+	switch t := v.(type) {
+	case string:
+		list = []string{t}
+	case []interface{}:
+		var err error
+		if list, err = parseStrings(path, "type", t); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s: type must be a string or an array of strings", path)
+	}
+
+	for _, t := range list {
+		if _, ok := types[t]; !ok {
+			return nil, fmt.Errorf("%s: unknown type %q", path, t)
+		}
+	}
+	return list, nil
+}
+
+func parseStrings(path, k string, v interface{}) ([]string, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: %s must be an array of strings", path, k)
+	}
+	out := make([]string, len(list))
+	for i, e := range list {
+		if out[i], ok = e.(string); !ok {
+			return nil, fmt.Errorf("%s: %s must be an array of strings", path, k)
+		}
+	}
+	return out, nil
+}
+
+func parseNumber(path, k string, v interface{}) (*float64, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s must be a number", path, k)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s must be a number", path, k)
+	}
+	return &f, nil
+}
+
+func parseCount(path, k string, v interface{}) (*int, error) {
+	f, err := parseNumber(path, k, v)
+	if err != nil || *f < 0 || *f != math.Trunc(*f) {
+		return nil, fmt.Errorf("%s: %s must be a non-negative integer", path, k)
+	}
+	i := int(*f)
+	return &i, nil
+}
+
+func (s *Schema) validate(path string, v interface{}, mErr *multierror.Error) {
+	if len(s.types) != 0 && !matchesType(s.types, v) {
+		multierror.Append(mErr, fmt.Errorf("%s: expected %s, got %s", path, typeList(s.types), typeOf(v)))
+		return
+	}
+
+	if s.enum != nil && !contains(s.enum, v) {
+		multierror.Append(mErr, fmt.Errorf("%s: value is not one of the allowed values", path))
+	}
+	if s.cnst != nil && !equal(*s.cnst, v) {
+		multierror.Append(mErr, fmt.Errorf("%s: value is not the allowed value", path))
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(path, t, mErr)
+	case []interface{}:
+		s.validateArray(path, t, mErr)
+	case string:
+		s.validateString(path, t, mErr)
+	case json.Number:
+		s.validateNumber(path, t, mErr)
+	}
+}
+
+func (s *Schema) validateObject(path string, obj map[string]interface{}, mErr *multierror.Error) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			multierror.Append(mErr, fmt.Errorf("%s: missing required property %q", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if prop, ok := s.properties[name]; ok {
+			prop.validate(path+"."+name, obj[name], mErr)
+		} else if s.noAdditional {
+			multierror.Append(mErr, fmt.Errorf("%s: property %q is not allowed", path, name))
+		} else if s.additionalProperties != nil {
+			s.additionalProperties.validate(path+"."+name, obj[name], mErr)
+		}
+	}
+}
+
+func (s *Schema) validateArray(path string, list []interface{}, mErr *multierror.Error) {
+	if s.minItems != nil && len(list) < *s.minItems {
+		multierror.Append(mErr, fmt.Errorf("%s: expected at least %d items, got %d", path, *s.minItems, len(list)))
+	}
+	if s.maxItems != nil && len(list) > *s.maxItems {
+		multierror.Append(mErr, fmt.Errorf("%s: expected at most %d items, got %d", path, *s.maxItems, len(list)))
+	}
+	if s.items != nil {
+		for i, e := range list {
+			s.items.validate(fmt.Sprintf("%s[%d]", path, i), e, mErr)
+		}
+	}
+}
+
+func (s *Schema) validateString(path, str string, mErr *multierror.Error) {
+	l := utf8.RuneCountInString(str)
+	if s.minLength != nil && l < *s.minLength {
+		multierror.Append(mErr, fmt.Errorf("%s: expected at least %d characters, got %d", path, *s.minLength, l))
+	}
+	if s.maxLength != nil && l > *s.maxLength {
+		multierror.Append(mErr, fmt.Errorf("%s: expected at most %d characters, got %d", path, *s.maxLength, l))
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		multierror.Append(mErr, fmt.Errorf("%s: %q does not match pattern %q", path, str, s.pattern))
+	}
+}
+
+func (s *Schema) validateNumber(path string, n json.Number, mErr *multierror.Error) {
+	f, err := n.Float64()
+	if err != nil {
+		multierror.Append(mErr, fmt.Errorf("%s: invalid number %q", path, n))
+		return
+	}
+	if s.minimum != nil && f < *s.minimum {
+		multierror.Append(mErr, fmt.Errorf("%s: %v is less than the minimum %v", path, n, *s.minimum))
+	}
+	if s.maximum != nil && f > *s.maximum {
+		multierror.Append(mErr, fmt.Errorf("%s: %v is greater than the maximum %v", path, n, *s.maximum))
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		multierror.Append(mErr, fmt.Errorf("%s: %v must be greater than %v", path, n, *s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		multierror.Append(mErr, fmt.Errorf("%s: %v must be less than %v", path, n, *s.exclusiveMaximum))
+	}
+}
+
+// matchesType returns whether the value is of one of the types.
+func matchesType(types []string, v interface{}) bool {
+	actual := typeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON schema type of a decoded value. Numbers without a
+// fractional part are integers.
+func typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := t.Float64(); err == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func typeList(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("one of %v", types)
+}
+
+func contains(list []interface{}, v interface{}) bool {
+	for _, e := range list {
+		if equal(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares decoded values, treating numbers by value so that 1 and 1.0
+// are equal.
+func equal(a, b interface{}) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+
+	switch at := a.(type) {
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !equal(at[i], bt[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for k, av := range at {
+			bv, ok := bt[k]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
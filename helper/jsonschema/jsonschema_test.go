@@ -0,0 +1,115 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["name", "replicas"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+    "replicas": {"type": "integer", "minimum": 1, "maximum": 5},
+    "ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+    "mode": {"enum": ["fast", "safe"]},
+    "tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+    "extra": {"type": ["string", "null"]}
+  }
+}`
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		schema string
+		err    string
+	}{
+		{`{`, "invalid schema"},
+		{`"string"`, "must be an object or a boolean"},
+		{`{"type": "text"}`, `unknown type "text"`},
+		{`{"type": 1}`, "type must be a string or an array"},
+		{`{"required": "name"}`, "required must be an array of strings"},
+		{`{"minLength": -1}`, "minLength must be a non-negative integer"},
+		{`{"pattern": "("}`, "invalid pattern"},
+		{`{"properties": {"a": {"oneOf": []}}}`, `$.a: unsupported keyword "oneOf"`},
+	}
+
+	for _, c := range cases {
+		_, err := Parse([]byte(c.schema))
+		require.Error(t, err, c.schema)
+		require.Contains(t, err.Error(), c.err, c.schema)
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(testSchema))
+	require.NoError(t, err)
+
+	valid := []string{
+		`{"name": "web", "replicas": 3}`,
+		`{"name": "web", "replicas": 3.0, "ratio": 0.5, "mode": "safe", "tags": ["a", "b"], "extra": null}`,
+	}
+	for _, doc := range valid {
+		require.NoError(t, s.Validate([]byte(doc)), doc)
+	}
+
+	cases := []struct {
+		doc string
+		err string
+	}{
+		{`{"name": "web"`, "invalid JSON"},
+		{`{"name": "web", "replicas": 1} {}`, "invalid JSON"},
+		{`[]`, "$: expected object, got array"},
+		{`{"name": "web"}`, `$: missing required property "replicas"`},
+		{`{"name": "web", "replicas": 1, "foo": 1}`, `$: property "foo" is not allowed`},
+		{`{"name": "", "replicas": 1}`, "$.name: expected at least 1 characters, got 0"},
+		{`{"name": "abcdefghi", "replicas": 1}`, "$.name: expected at most 8 characters, got 9"},
+		{`{"name": "Web", "replicas": 1}`, "does not match pattern"},
+		{`{"name": "web", "replicas": 1.5}`, "$.replicas: expected integer, got number"},
+		{`{"name": "web", "replicas": 6}`, "$.replicas: 6 is greater than the maximum 5"},
+		{`{"name": "web", "replicas": 0}`, "$.replicas: 0 is less than the minimum 1"},
+		{`{"name": "web", "replicas": 1, "ratio": 1}`, "$.ratio: 1 must be less than 1"},
+		{`{"name": "web", "replicas": 1, "mode": "slow"}`, "$.mode: value is not one of the allowed values"},
+		{`{"name": "web", "replicas": 1, "tags": ["a", 1]}`, "$.tags[1]: expected string, got integer"},
+		{`{"name": "web", "replicas": 1, "tags": ["a", "b", "c"]}`, "$.tags: expected at most 2 items, got 3"},
+		{`{"name": "web", "replicas": 1, "extra": 1}`, "$.extra: expected one of [string null], got integer"},
+	}
+	for _, c := range cases {
+		err := s.Validate([]byte(c.doc))
+		require.Error(t, err, c.doc)
+		require.Contains(t, err.Error(), c.err, c.doc)
+	}
+
+	// All the violations are reported
+	err = s.Validate([]byte(`{"name": 1, "replicas": 9}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "$.name: expected string")
+	require.Contains(t, err.Error(), "$.replicas: 9 is greater than the maximum")
+}
+
+func TestSchema_Validate_Boolean(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(`{"properties": {"any": true, "none": false}, "additionalProperties": {"type": "boolean"}}`))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate([]byte(`{"any": [1, {"a": null}], "flag": true}`)))
+	require.Error(t, s.Validate([]byte(`{"none": 1}`)))
+	require.Error(t, s.Validate([]byte(`{"flag": "yes"}`)))
+}
+
+func TestSchema_Validate_Const(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(`{"const": {"version": 1, "list": [1, "a"]}}`))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate([]byte(`{"list": [1.0, "a"], "version": 1}`)))
+	require.Error(t, s.Validate([]byte(`{"list": [1, "b"], "version": 1}`)))
+}
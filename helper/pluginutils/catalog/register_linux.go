@@ -2,6 +2,9 @@ package catalog
 
 import (
 	"github.com/hashicorp/nomad/devices/gpu/nvidia"
+	"github.com/hashicorp/nomad/devices/nic/sriov"
+	"github.com/hashicorp/nomad/drivers/firecracker"
+	"github.com/hashicorp/nomad/drivers/podman"
 	"github.com/hashicorp/nomad/drivers/rkt"
 )
 
@@ -10,5 +13,8 @@ import (
 // register_XXX.go file.
 func init() {
 	RegisterDeferredConfig(rkt.PluginID, rkt.PluginConfig, rkt.PluginLoader)
+	Register(podman.PluginID, podman.PluginConfig)
+	Register(firecracker.PluginID, firecracker.PluginConfig)
 	Register(nvidia.PluginID, nvidia.PluginConfig)
+	Register(sriov.PluginID, sriov.PluginConfig)
 }
@@ -0,0 +1,203 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+// MockFSM is a simple FSM for testing that simply stores its logs in a slice of
+// byte slices.
+type MockFSM struct {
+	sync.Mutex
+	logs [][]byte
+}
+
+// MockSnapshot is a snapshot sink for testing that encodes the contents of a
+// MockFSM using JSON.
+type MockSnapshot struct {
+	logs     [][]byte
+	maxIndex int
+}
+
+// Apply adds the data to the logs.
+func (m *MockFSM) Apply(log *raft.Log) interface{} {
+	m.Lock()
+	defer m.Unlock()
+	m.logs = append(m.logs, log.Data)
+	return len(m.logs)
+}
+
+// Snapshot takes a snapshot of the logs.
+func (m *MockFSM) Snapshot() (raft.FSMSnapshot, error) {
+	m.Lock()
+	defer m.Unlock()
+	return &MockSnapshot{m.logs, len(m.logs)}, nil
+}
+
+// Restore replaces the logs with the snapshot.
+func (m *MockFSM) Restore(in io.ReadCloser) error {
+	m.Lock()
+	defer m.Unlock()
+	defer in.Close()
+	return json.NewDecoder(in).Decode(&m.logs)
+}
+
+// Logs returns a copy of the logs.
+func (m *MockFSM) Logs() [][]byte {
+	m.Lock()
+	defer m.Unlock()
+	return append([][]byte{}, m.logs...)
+}
+
+// Persist writes the snapshot to the sink.
+func (m *MockSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(m.logs[:m.maxIndex]); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release is a no-op.
+func (m *MockSnapshot) Release() {}
+
+// makeRaft returns a bootstrapped single node Raft using the FSM.
+func makeRaft(t *testing.T, fsm raft.FSM) (*raft.Raft, func()) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+
+	snaps, err := raft.NewFileSnapshotStore(dir, 5, ioutil.Discard)
+	require.NoError(t, err)
+
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID("node")
+	conf.HeartbeatTimeout = 50 * time.Millisecond
+	conf.ElectionTimeout = 50 * time.Millisecond
+	conf.LeaderLeaseTimeout = 50 * time.Millisecond
+	conf.CommitTimeout = 5 * time.Millisecond
+	conf.LogOutput = ioutil.Discard
+
+	store := raft.NewInmemStore()
+	addr, trans := raft.NewInmemTransport("")
+	require.NoError(t, raft.BootstrapCluster(conf, store, store, snaps, trans, raft.Configuration{
+		Servers: []raft.Server{{ID: conf.LocalID, Address: addr}},
+	}))
+
+	r, err := raft.NewRaft(conf, fsm, store, store, snaps, trans)
+	require.NoError(t, err)
+
+	timeout := time.After(10 * time.Second)
+	for r.State() != raft.Leader {
+		select {
+		case <-r.LeaderCh():
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("timeout waiting for leader")
+		}
+	}
+
+	return r, func() {
+		r.Shutdown().Error()
+		trans.Close()
+	}
+}
+
+func TestSnapshot_SaveRestore(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	logger := testlog.HCLogger(t)
+
+	// Apply some logs and take a snapshot
+	fsm := &MockFSM{}
+	r, cleanup := makeRaft(t, fsm)
+	defer cleanup()
+	for i := 0; i < 64; i++ {
+		require.NoError(r.Apply([]byte(fmt.Sprintf("log %d", i)), time.Second).Error())
+	}
+	expected := fsm.Logs()
+
+	snap, err := New(logger, r)
+	require.NoError(err)
+	defer snap.Close()
+	require.NotZero(snap.Index())
+	require.Contains(snap.Checksum(), "sha-256=")
+
+	var archive bytes.Buffer
+	_, err = io.Copy(&archive, snap)
+	require.NoError(err)
+
+	// The snapshot is verified
+	meta, err := Verify(bytes.NewReader(archive.Bytes()))
+	require.NoError(err)
+	require.Equal(snap.Index(), meta.Index)
+
+	// Restoring into a new cluster replaces its state
+	fsm2 := &MockFSM{}
+	r2, cleanup2 := makeRaft(t, fsm2)
+	defer cleanup2()
+	require.NoError(r2.Apply([]byte("other"), time.Second).Error())
+	require.NoError(Restore(logger, bytes.NewReader(archive.Bytes()), r2))
+	require.Equal(expected, fsm2.Logs())
+}
+
+func TestSnapshot_Verify_Corrupt(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	logger := testlog.HCLogger(t)
+
+	fsm := &MockFSM{}
+	r, cleanup := makeRaft(t, fsm)
+	defer cleanup()
+	require.NoError(r.Apply([]byte("log"), time.Second).Error())
+
+	snap, err := New(logger, r)
+	require.NoError(err)
+	defer snap.Close()
+
+	var archive bytes.Buffer
+	_, err = io.Copy(&archive, snap)
+	require.NoError(err)
+
+	// Truncated archives fail to decompress or verify
+	_, err = Verify(bytes.NewReader(archive.Bytes()[:archive.Len()/2]))
+	require.Error(err)
+
+	// Garbage is not a snapshot
+	_, err = Verify(bytes.NewReader([]byte("not a snapshot")))
+	require.Error(err)
+}
+
+func TestHashList_DecodeAndVerify(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	hl := newHashList()
+	hl.Add("foo").Write([]byte("foo"))
+	hl.Add("bar").Write([]byte("bar"))
+
+	var buf bytes.Buffer
+	require.NoError(hl.Encode(&buf))
+	sums := buf.String()
+	require.NoError(hl.DecodeAndVerify(bytes.NewBufferString(sums)))
+
+	// Modified contents fail to verify
+	hl.Add("foo").Write([]byte("baz"))
+	require.Error(hl.DecodeAndVerify(bytes.NewBufferString(sums)))
+
+	// Missing files fail to verify
+	hl = newHashList()
+	hl.Add("foo").Write([]byte("foo"))
+	hl.Add("bar").Write([]byte("bar"))
+	hl.Add("baz")
+	require.Error(hl.DecodeAndVerify(bytes.NewBufferString(sums)))
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"sort"
+)
+
+// The types below are the JSON encoding of the OTLP trace export request.
+// Only the fields recorded by Nomad are included.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano uint64          `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   uint64          `json:"endTimeUnixNano,string"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpStatusError is the status code of failed spans
+const otlpStatusError = 2
+
+// encode converts a batch of spans to an OTLP export request.
+func (t *Tracer) encode(batch []*Span) *otlpRequest {
+	resource := map[string]string{
+		"service.name": t.config.ServiceName,
+	}
+	for k, v := range t.config.ResourceAttributes {
+		resource[k] = v
+	}
+
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: uint64(s.start.UnixNano()),
+			EndTimeUnixNano:   uint64(s.end.UnixNano()),
+			Attributes:        encodeAttributes(s.attributes),
+		}
+		if s.err != "" {
+			span.Status = &otlpStatus{Code: otlpStatusError, Message: s.err}
+		}
+		spans = append(spans, span)
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: encodeAttributes(resource)},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/hashicorp/nomad"},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+func encodeAttributes(attributes map[string]string) []otlpAttribute {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	out := make([]otlpAttribute, 0, len(attributes))
+	for k, v := range attributes {
+		out = append(out, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
@@ -0,0 +1,296 @@
+// Package tracing records spans of the work done by the Nomad servers and
+// exports them to an OpenTelemetry collector using the OTLP/HTTP protocol with
+// JSON encoding. A nil Tracer and the nil Spans it returns are valid and
+// record nothing, so callers don't need to check whether tracing is enabled.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// tracesPath is the path of the OTLP/HTTP traces receiver
+	tracesPath = "/v1/traces"
+
+	// maxQueuedSpans is the number of ended spans buffered before new spans
+	// are dropped because the collector can't keep up.
+	maxQueuedSpans = 2048
+
+	// maxBatchSize is the maximum number of spans sent in a single export
+	maxBatchSize = 512
+
+	// flushInterval is how often buffered spans are exported
+	flushInterval = 5 * time.Second
+
+	// exportTimeout bounds the duration of a single export request
+	exportTimeout = 10 * time.Second
+)
+
+// SpanKind describes the relationship of a span to the operation it records.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+)
+
+// Config configures the export of spans.
+type Config struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver, such as
+	// "http://localhost:4318". Spans are posted to its /v1/traces path.
+	Endpoint string
+
+	// Headers are added to the export requests, such as authentication
+	// headers of hosted collectors.
+	Headers map[string]string
+
+	// SampleRate is the ratio of traces that are recorded, from 0 to 1.
+	// Sampling is decided on the trace ID so all the spans of a trace are
+	// either recorded or dropped together.
+	SampleRate float64
+
+	// ServiceName is the service.name resource attribute of the spans.
+	ServiceName string
+
+	// ResourceAttributes are additional attributes identifying the process
+	// recording the spans.
+	ResourceAttributes map[string]string
+}
+
+// Tracer records spans and exports them in batches in the background.
+type Tracer struct {
+	config *Config
+	logger log.Logger
+	client *http.Client
+
+	// sampleBound is the upper bound of the trace IDs that are sampled
+	sampleBound uint64
+
+	spanCh     chan *Span
+	shutdownCh chan struct{}
+	doneCh     chan struct{}
+	shutdown   sync.Once
+}
+
+// NewTracer returns a tracer exporting spans as configured. It returns nil
+// if the config doesn't set an endpoint.
+func NewTracer(logger log.Logger, config *Config) *Tracer {
+	if config == nil || config.Endpoint == "" {
+		return nil
+	}
+
+	t := &Tracer{
+		config:      config,
+		logger:      logger.Named("tracing"),
+		client:      &http.Client{Timeout: exportTimeout},
+		sampleBound: sampleBound(config.SampleRate),
+		spanCh:      make(chan *Span, maxQueuedSpans),
+		shutdownCh:  make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// sampleBound converts a sample rate to the bound trace IDs are compared to,
+// the same way OpenTelemetry's trace ID ratio sampler does.
+func sampleBound(rate float64) uint64 {
+	switch {
+	case rate >= 1:
+		return 1 << 63
+	case rate <= 0:
+		return 0
+	default:
+		return uint64(rate * (1 << 63))
+	}
+}
+
+// Start starts a span of the given trace. The trace ID may be the ID of the
+// object the span operates on, such as an evaluation ID, to group the spans
+// of the different components handling that object in the same trace. A
+// random trace ID is used if it's empty or not a UUID.
+func (t *Tracer) Start(name, traceID string, kind SpanKind) *Span {
+	return t.StartAt(name, traceID, kind, time.Now())
+}
+
+// StartAt starts a span at a time in the past, for operations whose start is
+// only known once they complete.
+func (t *Tracer) StartAt(name, traceID string, kind SpanKind, start time.Time) *Span {
+	if t == nil {
+		return nil
+	}
+
+	var span Span
+	if !parseTraceID(traceID, span.traceID[:]) {
+		rand.Read(span.traceID[:])
+	}
+
+	// The low 63 bits of the trace ID are compared to the bound so that the
+	// same traces are sampled on every server.
+	if binary.BigEndian.Uint64(span.traceID[8:])>>1 >= t.sampleBound {
+		return nil
+	}
+
+	rand.Read(span.spanID[:])
+	span.tracer = t
+	span.name = name
+	span.kind = kind
+	span.start = start
+	return &span
+}
+
+// parseTraceID decodes a UUID into the 16 bytes of a trace ID.
+func parseTraceID(id string, out []byte) bool {
+	raw := strings.Replace(id, "-", "", -1)
+	if len(raw) != 2*len(out) {
+		return false
+	}
+	_, err := hex.Decode(out, []byte(raw))
+	return err == nil
+}
+
+// Shutdown exports the spans ended so far and stops the tracer.
+func (t *Tracer) Shutdown() {
+	if t == nil {
+		return
+	}
+	t.shutdown.Do(func() {
+		close(t.shutdownCh)
+		<-t.doneCh
+	})
+}
+
+// enqueue queues an ended span for export, dropping it if the queue is full.
+func (t *Tracer) enqueue(span *Span) {
+	select {
+	case t.spanCh <- span:
+	default:
+		metrics.IncrCounter([]string{"nomad", "tracing", "dropped_spans"}, 1)
+	}
+}
+
+// run exports the ended spans when a batch is full, periodically and when
+// the tracer shuts down.
+func (t *Tracer) run() {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatchSize)
+	for {
+		select {
+		case span := <-t.spanCh:
+			batch = append(batch, span)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-t.shutdownCh:
+			for len(t.spanCh) > 0 {
+				batch = append(batch, <-t.spanCh)
+			}
+			t.export(batch)
+			return
+		}
+
+		t.export(batch)
+		batch = batch[:0]
+	}
+}
+
+// export posts a batch of spans to the collector.
+func (t *Tracer) export(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+	defer metrics.MeasureSince([]string{"nomad", "tracing", "export"}, time.Now())
+
+	if err := t.post(batch); err != nil {
+		t.logger.Warn("failed to export spans", "spans", len(batch), "error", err)
+		metrics.IncrCounter([]string{"nomad", "tracing", "export_error"}, 1)
+	}
+}
+
+func (t *Tracer) post(batch []*Span) error {
+	body, err := json.Marshal(t.encode(batch))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(t.config.Endpoint, "/")+tracesPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Span is a timed operation of a trace. Its methods may be called on a nil
+// span, which records nothing.
+type Span struct {
+	tracer *Tracer
+
+	traceID [16]byte
+	spanID  [8]byte
+	name    string
+	kind    SpanKind
+	start   time.Time
+	end     time.Time
+
+	attributes map[string]string
+	err        string
+}
+
+// SetAttribute sets an attribute describing the operation of the span.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	if s.attributes == nil {
+		s.attributes = make(map[string]string)
+	}
+	s.attributes[key] = value
+}
+
+// SetError marks the operation of the span as failed.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End ends the span and queues it for export. The span must not be used
+// once ended.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.tracer.enqueue(s)
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer_Disabled(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	tracer := NewTracer(testlog.HCLogger(t), &Config{})
+	require.Nil(tracer)
+
+	// Disabled tracers and their spans are no-ops
+	span := tracer.Start("Job.Register", "", SpanKindServer)
+	require.Nil(span)
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("failed"))
+	span.End()
+	tracer.Shutdown()
+}
+
+func TestTracer_Export(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	reqCh := make(chan *http.Request, 1)
+	bodyCh := make(chan *otlpRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		reqCh <- r
+		bodyCh <- &body
+	}))
+	defer srv.Close()
+
+	tracer := NewTracer(testlog.HCLogger(t), &Config{
+		Endpoint:           srv.URL,
+		Headers:            map[string]string{"X-Api-Key": "secret"},
+		SampleRate:         1,
+		ServiceName:        "nomad",
+		ResourceAttributes: map[string]string{"nomad.region": "global"},
+	})
+	require.NotNil(tracer)
+
+	evalID := "d8c3b6e4-64b4-4a33-b5b0-3c6e1d1b7f2a"
+	span := tracer.Start("worker.invoke_scheduler", evalID, SpanKindInternal)
+	require.NotNil(span)
+	span.SetAttribute("eval_id", evalID)
+	span.SetError(errors.New("failed to process evaluation"))
+	span.End()
+
+	// Shutting down exports the pending spans
+	tracer.Shutdown()
+
+	req := <-reqCh
+	require.Equal("/v1/traces", req.URL.Path)
+	require.Equal("secret", req.Header.Get("X-Api-Key"))
+	require.Equal("application/json", req.Header.Get("Content-Type"))
+
+	body := <-bodyCh
+	require.Len(body.ResourceSpans, 1)
+	require.Equal([]otlpAttribute{
+		{Key: "nomad.region", Value: otlpValue{StringValue: "global"}},
+		{Key: "service.name", Value: otlpValue{StringValue: "nomad"}},
+	}, body.ResourceSpans[0].Resource.Attributes)
+
+	spans := body.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(spans, 1)
+	require.Equal("worker.invoke_scheduler", spans[0].Name)
+	require.Equal("d8c3b6e464b44a33b5b03c6e1d1b7f2a", spans[0].TraceID)
+	require.Len(spans[0].SpanID, 16)
+	require.Equal(SpanKindInternal, spans[0].Kind)
+	require.True(spans[0].EndTimeUnixNano >= spans[0].StartTimeUnixNano)
+	require.Equal(otlpStatusError, spans[0].Status.Code)
+	require.Equal("failed to process evaluation", spans[0].Status.Message)
+}
+
+func TestTracer_Sampling(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	tracer := NewTracer(testlog.HCLogger(t), &Config{
+		Endpoint:   "http://127.0.0.1:0",
+		SampleRate: 0.5,
+	})
+	defer tracer.Shutdown()
+
+	// The sampling decision only depends on the trace ID
+	low := "00000000-0000-0000-0000-000000000001"
+	high := "00000000-0000-0000-ffff-ffffffffffff"
+	require.NotNil(tracer.Start("a", low, SpanKindInternal))
+	require.NotNil(tracer.Start("b", low, SpanKindInternal))
+	require.Nil(tracer.Start("a", high, SpanKindInternal))
+	require.Nil(tracer.Start("b", high, SpanKindInternal))
+}
@@ -0,0 +1,115 @@
+package jobspec
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// intRange is the range of values allowed for an integer field.
+type intRange struct {
+	min, max int64
+}
+
+var (
+	// nonNegative allows any integer greater than or equal to zero.
+	nonNegative = intRange{0, math.MaxInt64}
+
+	// percentage allows integers between 0 and 100.
+	percentage = intRange{0, 100}
+)
+
+// checkDurations returns an error for each of the given fields of the object
+// that isn't a non-negative duration string, such as "30s". Without it,
+// values of the wrong type would be weakly decoded into unexpected
+// durations.
+func checkDurations(node ast.Node, fields ...string) error {
+	var mErr multierror.Error
+	for _, item := range fieldItems(node, fields) {
+		name := item.Keys[0].Token.Value().(string)
+		lit, ok := item.Val.(*ast.LiteralType)
+		if !ok || lit.Token.Type != token.STRING {
+			mErr.Errors = append(mErr.Errors, fieldError(item, "%s must be a duration string such as \"30s\"", name))
+			continue
+		}
+
+		value := lit.Token.Value().(string)
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			mErr.Errors = append(mErr.Errors, fieldError(item, "%s has an invalid duration %q", name, value))
+			continue
+		}
+		if d < 0 {
+			mErr.Errors = append(mErr.Errors, fieldError(item, "%s must not be negative", name))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// checkInts returns an error for each of the given fields of the object that
+// isn't an integer within the range.
+func checkInts(node ast.Node, r intRange, fields ...string) error {
+	var mErr multierror.Error
+	for _, item := range fieldItems(node, fields) {
+		name := item.Keys[0].Token.Value().(string)
+		lit, ok := item.Val.(*ast.LiteralType)
+		if !ok || lit.Token.Type != token.NUMBER {
+			mErr.Errors = append(mErr.Errors, fieldError(item, "%s must be an integer", name))
+			continue
+		}
+
+		value := lit.Token.Value().(int64)
+		if value < r.min || value > r.max {
+			if r.max == math.MaxInt64 {
+				mErr.Errors = append(mErr.Errors, fieldError(item, "%s must be greater than or equal to %d", name, r.min))
+			} else {
+				mErr.Errors = append(mErr.Errors, fieldError(item, "%s must be between %d and %d", name, r.min, r.max))
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// fieldItems returns the items of the object set to one of the fields.
+func fieldItems(node ast.Node, fields []string) []*ast.ObjectItem {
+	var list *ast.ObjectList
+	switch n := node.(type) {
+	case *ast.ObjectList:
+		list = n
+	case *ast.ObjectType:
+		list = n.List
+	default:
+		return nil
+	}
+
+	var items []*ast.ObjectItem
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+		name, ok := item.Keys[0].Token.Value().(string)
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			if name == field {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+	return items
+}
+
+// fieldError returns an error pointing at the position of the field.
+func fieldError(item *ast.ObjectItem, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if pos := item.Pos(); pos.IsValid() {
+		return fmt.Errorf("%s (line %d, column %d)", msg, pos.Line, pos.Column)
+	}
+	return fmt.Errorf("%s", msg)
+}
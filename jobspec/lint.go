@@ -0,0 +1,166 @@
+package jobspec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper"
+)
+
+var (
+	// knownAttributes is the catalog of node attributes set by Nomad's
+	// fingerprinters.
+	knownAttributes = []string{
+		"consul.datacenter",
+		"consul.revision",
+		"consul.server",
+		"consul.version",
+		"cpu.arch",
+		"cpu.frequency",
+		"cpu.modelname",
+		"cpu.numcores",
+		"cpu.totalcompute",
+		"kernel.name",
+		"kernel.version",
+		"memory.totalbytes",
+		"nomad.advertise.address",
+		"nomad.revision",
+		"nomad.version",
+		"os.name",
+		"os.signals",
+		"os.version",
+		"platform.aws.ami-id",
+		"platform.aws.instance-type",
+		"platform.aws.placement.availability-zone",
+		"unique.cgroup.mountpoint",
+		"unique.cgroup.version",
+		"unique.consul.name",
+		"unique.hostname",
+		"unique.network.ip-address",
+		"unique.platform.aws.hostname",
+		"unique.platform.aws.instance-id",
+		"unique.platform.aws.local-hostname",
+		"unique.platform.aws.local-ipv4",
+		"unique.platform.aws.public-hostname",
+		"unique.platform.aws.public-ipv4",
+		"unique.storage.bytesfree",
+		"unique.storage.bytestotal",
+		"unique.storage.volume",
+		"vault.accessible",
+		"vault.cluster_id",
+		"vault.cluster_name",
+		"vault.version",
+	}
+
+	// dynamicAttributePrefixes are the prefixes of node attributes whose names
+	// depend on the node, such as those set by driver plugins, and can't be
+	// checked against the catalog.
+	dynamicAttributePrefixes = []string{
+		"driver.",
+		"platform.gce.",
+		"unique.platform.gce.",
+	}
+
+	// knownNodeFields are the node fields that can be referenced by
+	// constraints, affinities and spreads.
+	knownNodeFields = []string{
+		"unique.id",
+		"unique.name",
+		"datacenter",
+		"class",
+	}
+)
+
+// Lint returns warnings about the node attributes and fields referenced by
+// the constraints, affinities and spreads of the job that Nomad doesn't set,
+// which are likely to be misspelled. Node metadata can't be checked since it
+// is set by operators.
+func Lint(job *api.Job) []string {
+	var warnings []string
+	lint := func(context string, constraints []*api.Constraint, affinities []*api.Affinity, spreads []*api.Spread) {
+		for _, c := range constraints {
+			warnings = appendTargetWarnings(warnings, context+" constraint", c.LTarget, c.RTarget)
+		}
+		for _, a := range affinities {
+			warnings = appendTargetWarnings(warnings, context+" affinity", a.LTarget, a.RTarget)
+		}
+		for _, s := range spreads {
+			warnings = appendTargetWarnings(warnings, context+" spread", s.Attribute)
+		}
+	}
+
+	lint(fmt.Sprintf("job %q", stringValue(job.ID)), job.Constraints, job.Affinities, job.Spreads)
+	for _, tg := range job.TaskGroups {
+		group := fmt.Sprintf("group %q", stringValue(tg.Name))
+		lint(group, tg.Constraints, tg.Affinities, tg.Spreads)
+		for _, task := range tg.Tasks {
+			lint(fmt.Sprintf("%s task %q", group, task.Name), task.Constraints, task.Affinities, nil)
+		}
+	}
+	return warnings
+}
+
+// appendTargetWarnings appends a warning for each target referencing an
+// unknown node attribute or field.
+func appendTargetWarnings(warnings []string, context string, targets ...string) []string {
+	for _, target := range targets {
+		if warning := lintTarget(target); warning != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", context, warning))
+		}
+	}
+	return warnings
+}
+
+// lintTarget returns a warning if the target references an unknown node
+// attribute or field, or an empty string otherwise.
+func lintTarget(target string) string {
+	if !strings.HasPrefix(target, "${") || !strings.HasSuffix(target, "}") {
+		return ""
+	}
+	ref := strings.TrimSuffix(strings.TrimPrefix(target, "${"), "}")
+
+	switch {
+	case strings.HasPrefix(ref, "meta."):
+		return ""
+
+	case strings.HasPrefix(ref, "attr."):
+		attr := strings.TrimPrefix(ref, "attr.")
+		for _, prefix := range dynamicAttributePrefixes {
+			if strings.HasPrefix(attr, prefix) {
+				return ""
+			}
+		}
+		return unknownReference("attribute", target, attr, knownAttributes)
+
+	case strings.HasPrefix(ref, "node."):
+		return unknownReference("node field", target, strings.TrimPrefix(ref, "node."), knownNodeFields)
+
+	default:
+		return fmt.Sprintf("%q does not reference a node attribute, field or metadata", target)
+	}
+}
+
+// unknownReference returns a warning if the name is not one of the known
+// names, suggesting the closest known name if there is one.
+func unknownReference(kind, target, name string, known []string) string {
+	for _, k := range known {
+		if name == k {
+			return ""
+		}
+	}
+
+	warning := fmt.Sprintf("%q references unknown %s %q", target, kind, name)
+	if suggestion := helper.ClosestString(name, known); suggestion != "" {
+		warning += fmt.Sprintf("; did you mean %q?", suggestion)
+	}
+	return warning
+}
+
+// stringValue returns the value of a string pointer or an empty string.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,51 @@
+package jobspec
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	job := &api.Job{
+		ID: helper.StringToPtr("example"),
+		Constraints: []*api.Constraint{
+			{LTarget: "${attr.cpu.archh}", RTarget: "amd64", Operand: "="},
+			{LTarget: "${attr.kernel.name}", RTarget: "linux", Operand: "="},
+		},
+		TaskGroups: []*api.TaskGroup{
+			{
+				Name: helper.StringToPtr("cache"),
+				Affinities: []*api.Affinity{
+					{LTarget: "${node.datacentre}", RTarget: "dc1", Operand: "="},
+					{LTarget: "${meta.anything}", RTarget: "x", Operand: "="},
+				},
+				Spreads: []*api.Spread{
+					{Attribute: "${attr.platform.aws.placement.availability-zone}"},
+					{Attribute: "${rack}"},
+				},
+				Tasks: []*api.Task{
+					{
+						Name: "redis",
+						Constraints: []*api.Constraint{
+							{LTarget: "${attr.driver.docker.privileged.enabled}", RTarget: "true", Operand: "="},
+							{LTarget: "${attr.unique.platform.gce.zone}", RTarget: "us-east1", Operand: "="},
+							{LTarget: "${attr.vault.verison}", RTarget: ">= 1.0", Operand: "version"},
+							{LTarget: "${attr.totally.unknown.attribute}", Operand: "is_set"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, []string{
+		`job "example" constraint: "${attr.cpu.archh}" references unknown attribute "cpu.archh"; did you mean "cpu.arch"?`,
+		`group "cache" affinity: "${node.datacentre}" references unknown node field "datacentre"; did you mean "datacenter"?`,
+		`group "cache" spread: "${rack}" does not reference a node attribute, field or metadata`,
+		`group "cache" task "redis" constraint: "${attr.vault.verison}" references unknown attribute "vault.verison"; did you mean "vault.version"?`,
+		`group "cache" task "redis" constraint: "${attr.totally.unknown.attribute}" references unknown attribute "totally.unknown.attribute"`,
+	}, Lint(job))
+}
@@ -2,9 +2,10 @@ package jobspec
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"io/ioutil"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -14,6 +15,7 @@ import (
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -34,12 +36,40 @@ func Parse(r io.Reader) (*api.Job, error) {
 		return nil, err
 	}
 
+	return ParseBytes(buf.Bytes(), "")
+}
+
+// ParseString parses the job spec from the given string. The filename is only
+// used in error messages and may be empty.
+func ParseString(src, filename string) (*api.Job, error) {
+	return ParseBytes([]byte(src), filename)
+}
+
+// ParseBytes parses the job spec from the given bytes. The filename is used in
+// error messages and to resolve the files referenced by the job spec, such as
+// template data files. It may be empty if the job spec doesn't reference any
+// file.
+func ParseBytes(src []byte, filename string) (*api.Job, error) {
+	var baseDir string
+	if filename != "" {
+		baseDir = filepath.Dir(filename)
+	}
+
+	job, err := parseBytes(src, baseDir)
+	if err != nil && filename != "" {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return job, err
+}
+
+// parseBytes parses the job spec from the given bytes. Relative paths in the
+// job spec are resolved from baseDir, and aren't allowed if it is empty.
+func parseBytes(src []byte, baseDir string) (*api.Job, error) {
 	// Parse the buffer
-	root, err := hcl.Parse(buf.String())
+	root, err := hcl.ParseBytes(src)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing: %s", err)
 	}
-	buf.Reset()
 
 	// Top-level item should be a list
 	list, ok := root.Node.(*ast.ObjectList)
@@ -62,30 +92,30 @@ func Parse(r io.Reader) (*api.Job, error) {
 	if len(matches.Items) == 0 {
 		return nil, fmt.Errorf("'job' stanza not found")
 	}
-	if err := parseJob(&job, matches); err != nil {
+	if err := parseJob(&job, matches, baseDir); err != nil {
 		return nil, fmt.Errorf("error parsing 'job': %s", err)
 	}
 
 	return &job, nil
 }
 
-// ParseFile parses the given path as a job spec.
+// ParseFile parses the given path as a job spec. Errors are prefixed with
+// the path.
 func ParseFile(path string) (*api.Job, error) {
-	path, err := filepath.Abs(path)
+	abs, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
 	}
 
-	f, err := os.Open(path)
+	src, err := ioutil.ReadFile(abs)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	return Parse(f)
+	return ParseBytes(src, path)
 }
 
-func parseJob(result *api.Job, list *ast.ObjectList) error {
+func parseJob(result *api.Job, list *ast.ObjectList, baseDir string) error {
 	if len(list.Items) != 1 {
 		return fmt.Errorf("only one 'job' block allowed")
 	}
@@ -97,15 +127,17 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 	// Get our job object
 	obj := list.Items[0]
 
-	// Decode the full thing into a map[string]interface for ease
+	// Decode the full thing into a map[string]interface for ease, except for
+	// the groups and tasks which are parsed below
 	var m map[string]interface{}
-	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
+	if err := hcl.DecodeObject(&m, withoutKeys(obj.Val, "group", "task")); err != nil {
 		return err
 	}
 	delete(m, "constraint")
 	delete(m, "affinity")
 	delete(m, "meta")
 	delete(m, "migrate")
+	delete(m, "multiregion")
 	delete(m, "parameterized")
 	delete(m, "periodic")
 	delete(m, "reschedule")
@@ -141,6 +173,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		"id",
 		"meta",
 		"migrate",
+		"multiregion",
 		"name",
 		"namespace",
 		"parameterized",
@@ -154,6 +187,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		"vault",
 		"vault_token",
 	}
+	valid = append(valid, customStanzaNames(StanzaScopeJob)...)
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return multierror.Prefix(err, "job:")
 	}
@@ -193,6 +227,13 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		}
 	}
 
+	// If we have a multiregion definition, then parse that
+	if o := listVal.Filter("multiregion"); len(o.Items) > 0 {
+		if err := parseMultiregion(&result.Multiregion, o); err != nil {
+			return multierror.Prefix(err, "multiregion ->")
+		}
+	}
+
 	// If we have a parameterized definition, then parse that
 	if o := listVal.Filter("parameterized"); len(o.Items) > 0 {
 		if err := parseParameterizedJob(&result.ParameterizedJob, o); err != nil {
@@ -228,10 +269,15 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse custom stanzas into meta
+	if err := parseCustomStanzas(StanzaScopeJob, &result.Meta, listVal); err != nil {
+		return err
+	}
+
 	// If we have tasks outside, create TaskGroups for them
 	if o := listVal.Filter("task"); len(o.Items) > 0 {
 		var tasks []*api.Task
-		if err := parseTasks(*result.Name, "", &tasks, o); err != nil {
+		if err := parseTasks(*result.Name, "", &tasks, o, baseDir); err != nil {
 			return multierror.Prefix(err, "task:")
 		}
 
@@ -246,7 +292,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 
 	// Parse the task groups
 	if o := listVal.Filter("group"); len(o.Items) > 0 {
-		if err := parseGroups(result, o); err != nil {
+		if err := parseGroups(result, o, baseDir); err != nil {
 			return multierror.Prefix(err, "group:")
 		}
 	}
@@ -275,7 +321,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 	return nil
 }
 
-func parseGroups(result *api.Job, list *ast.ObjectList) error {
+func parseGroups(result *api.Job, list *ast.ObjectList, baseDir string) error {
 	list = list.Children()
 	if len(list.Items) == 0 {
 		return nil
@@ -321,13 +367,12 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 		}
 
 		var m map[string]interface{}
-		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+		if err := hcl.DecodeObject(&m, withoutKeys(item.Val, "task")); err != nil {
 			return err
 		}
 		delete(m, "constraint")
 		delete(m, "affinity")
 		delete(m, "meta")
-		delete(m, "task")
 		delete(m, "restart")
 		delete(m, "ephemeral_disk")
 		delete(m, "update")
@@ -414,9 +459,12 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 
 		// Parse tasks
 		if o := listVal.Filter("task"); len(o.Items) > 0 {
-			if err := parseTasks(*result.Name, *g.Name, &g.Tasks, o); err != nil {
+			if err := parseTasks(*result.Name, *g.Name, &g.Tasks, o, baseDir); err != nil {
 				return multierror.Prefix(err, fmt.Sprintf("'%s', task:", n))
 			}
+			if err := checkGroupPorts(g.Tasks); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("'%s', port conflict ->", n))
+			}
 		}
 
 		// If we have a vault block, then parse that
@@ -464,6 +512,12 @@ func parseRestartPolicy(final **api.RestartPolicy, list *ast.ObjectList) error {
 	if err := helper.CheckHCLKeys(obj.Val, valid); err != nil {
 		return err
 	}
+	if err := checkDurations(obj.Val, "interval", "delay"); err != nil {
+		return err
+	}
+	if err := checkInts(obj.Val, nonNegative, "attempts"); err != nil {
+		return err
+	}
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
@@ -508,6 +562,12 @@ func parseReschedulePolicy(final **api.ReschedulePolicy, list *ast.ObjectList) e
 	if err := helper.CheckHCLKeys(obj.Val, valid); err != nil {
 		return err
 	}
+	if err := checkDurations(obj.Val, "interval", "delay", "max_delay"); err != nil {
+		return err
+	}
+	if err := checkInts(obj.Val, nonNegative, "attempts"); err != nil {
+		return err
+	}
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
@@ -531,6 +591,49 @@ func parseReschedulePolicy(final **api.ReschedulePolicy, list *ast.ObjectList) e
 	return nil
 }
 
+var (
+	// comparisonOperators are the operators shared by constraints and
+	// affinities
+	comparisonOperators = []string{
+		"=", "==", "is", "!=", "not", "<", "<=", ">", ">=",
+		structs.ConstraintRegex,
+		structs.ConstraintVersion,
+		structs.ConstraintSetContains,
+		structs.ConstraintSetContainsAll,
+		structs.ConstraintSetContainsAny,
+	}
+
+	// constraintOperators are the operators a constraint may use
+	constraintOperators = append([]string{
+		structs.ConstraintDistinctHosts,
+		structs.ConstraintDistinctProperty,
+		structs.ConstraintAttributeIsSet,
+		structs.ConstraintAttributeIsNotSet,
+	}, comparisonOperators...)
+
+	// affinityOperators are the operators an affinity may use
+	affinityOperators = comparisonOperators
+)
+
+// checkOperator returns an error if the operator is not one of the valid
+// operators, suggesting the closest valid operator if there is one.
+func checkOperator(node ast.Node, operator string, valid []string) error {
+	for _, v := range valid {
+		if operator == v {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("invalid operator %q", operator)
+	if pos := node.Pos(); pos.IsValid() {
+		msg += fmt.Sprintf(" (line %d, column %d)", pos.Line, pos.Column)
+	}
+	if suggestion := helper.ClosestString(operator, valid); suggestion != "" {
+		msg += fmt.Sprintf("; did you mean %q?", suggestion)
+	}
+	return errors.New(msg)
+}
+
 func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 	for _, o := range list.Elem().Items {
 		// Check for invalid keys
@@ -605,6 +708,9 @@ func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 		if c.Operand == "" {
 			c.Operand = "="
 		}
+		if err := checkOperator(o.Val, c.Operand, constraintOperators); err != nil {
+			return err
+		}
 
 		*result = append(*result, &c)
 	}
@@ -629,6 +735,9 @@ func parseAffinities(result *[]*api.Affinity, list *ast.ObjectList) error {
 		if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
 			return err
 		}
+		if err := checkInts(o.Val, intRange{-100, 100}, "weight"); err != nil {
+			return err
+		}
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, o.Val); err != nil {
@@ -681,6 +790,9 @@ func parseAffinities(result *[]*api.Affinity, list *ast.ObjectList) error {
 		if a.Operand == "" {
 			a.Operand = "="
 		}
+		if err := checkOperator(o.Val, a.Operand, affinityOperators); err != nil {
+			return err
+		}
 
 		*result = append(*result, &a)
 	}
@@ -732,6 +844,9 @@ func parseSpread(result *[]*api.Spread, list *ast.ObjectList) error {
 		if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
 			return err
 		}
+		if err := checkInts(o.Val, percentage, "weight"); err != nil {
+			return err
+		}
 
 		// We need this later
 		var listVal *ast.ObjectList
@@ -795,6 +910,9 @@ func parseSpreadTarget(result *[]*api.SpreadTarget, list *ast.ObjectList) error
 		if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
 		}
+		if err := checkInts(listVal, percentage, "percent"); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
+		}
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, item.Val); err != nil {
@@ -829,7 +947,7 @@ func parseBool(value interface{}) (bool, error) {
 	return enabled, err
 }
 
-func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list *ast.ObjectList) error {
+func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list *ast.ObjectList, baseDir string) error {
 	list = list.Children()
 	if len(list.Items) == 0 {
 		return nil
@@ -875,12 +993,17 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			"vault",
 			"kill_signal",
 		}
+		valid = append(valid, customStanzaNames(StanzaScopeTask)...)
 		if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
 		}
+		if err := checkDurations(listVal, "kill_timeout", "shutdown_delay"); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
+		}
 
+		// Env is decoded separately below, since it may mix blocks and strings
 		var m map[string]interface{}
-		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+		if err := hcl.DecodeObject(&m, withoutKeys(item.Val, "env")); err != nil {
 			return err
 		}
 		delete(m, "artifact")
@@ -888,7 +1011,6 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 		delete(m, "constraint")
 		delete(m, "affinity")
 		delete(m, "dispatch_payload")
-		delete(m, "env")
 		delete(m, "logs")
 		delete(m, "meta")
 		delete(m, "resources")
@@ -918,6 +1040,22 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 		// If we have env, then parse them
 		if o := listVal.Filter("env"); len(o.Items) > 0 {
 			for _, o := range o.Elem().Items {
+				// Strings hold variables in the env file format, inline or
+				// loaded from a file
+				if lit, ok := o.Val.(*ast.LiteralType); ok {
+					env, err := parseEnvString(lit, baseDir)
+					if err != nil {
+						return multierror.Prefix(err, fmt.Sprintf("'%s', env ->", n))
+					}
+					if t.Env == nil {
+						t.Env = make(map[string]string, len(env))
+					}
+					for k, v := range env {
+						t.Env[k] = v
+					}
+					continue
+				}
+
 				var m map[string]interface{}
 				if err := hcl.DecodeObject(&m, o.Val); err != nil {
 					return err
@@ -977,6 +1115,11 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			}
 		}
 
+		// Parse custom stanzas into meta
+		if err := parseCustomStanzas(StanzaScopeTask, &t.Meta, listVal); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s',", n))
+		}
+
 		// If we have resources, then parse that
 		if o := listVal.Filter("resources"); len(o.Items) > 0 {
 			var r api.Resources
@@ -999,6 +1142,9 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			valid := []string{
 				"max_files",
 				"max_file_size",
+				"sink",
+				"sink_options",
+				"disable_files",
 			}
 			if err := helper.CheckHCLKeys(logsBlock.Val, valid); err != nil {
 				return multierror.Prefix(err, fmt.Sprintf("'%s', logs ->", n))
@@ -1007,12 +1153,28 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			if err := hcl.DecodeObject(&m, logsBlock.Val); err != nil {
 				return err
 			}
+			delete(m, "sink_options")
 
 			var log api.LogConfig
 			if err := mapstructure.WeakDecode(m, &log); err != nil {
 				return err
 			}
 
+			// Parse the sink options
+			if ot, ok := logsBlock.Val.(*ast.ObjectType); ok {
+				if o := ot.List.Filter("sink_options"); len(o.Items) > 0 {
+					for _, o := range o.Elem().Items {
+						var m map[string]interface{}
+						if err := hcl.DecodeObject(&m, o.Val); err != nil {
+							return err
+						}
+						if err := mapstructure.WeakDecode(m, &log.SinkOptions); err != nil {
+							return err
+						}
+					}
+				}
+			}
+
 			t.LogConfig = &log
 		}
 
@@ -1025,7 +1187,7 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 
 		// Parse templates
 		if o := listVal.Filter("template"); len(o.Items) > 0 {
-			if err := parseTemplates(&t.Templates, o); err != nil {
+			if err := parseTemplates(&t.Templates, o, baseDir); err != nil {
 				return multierror.Prefix(err, fmt.Sprintf("'%s', template ->", n))
 			}
 		}
@@ -1143,13 +1305,14 @@ func parseArtifactOption(result map[string]string, list *ast.ObjectList) error {
 	return nil
 }
 
-func parseTemplates(result *[]*api.Template, list *ast.ObjectList) error {
+func parseTemplates(result *[]*api.Template, list *ast.ObjectList, baseDir string) error {
 	for _, o := range list.Elem().Items {
 		// Check for invalid keys
 		valid := []string{
 			"change_mode",
 			"change_signal",
 			"data",
+			"data_file",
 			"destination",
 			"left_delimiter",
 			"perms",
@@ -1158,6 +1321,7 @@ func parseTemplates(result *[]*api.Template, list *ast.ObjectList) error {
 			"splay",
 			"env",
 			"vault_grace",
+			"sandbox",
 		}
 		if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
 			return err
@@ -1186,12 +1350,143 @@ func parseTemplates(result *[]*api.Template, list *ast.ObjectList) error {
 			return err
 		}
 
+		// Inline the template data from the data file
+		if dataFile, ok := m["data_file"]; ok {
+			if templ.EmbeddedTmpl != nil {
+				return fmt.Errorf("only one of data and data_file may be set")
+			}
+			data, err := readTemplateDataFile(fmt.Sprint(dataFile), baseDir)
+			if err != nil {
+				return err
+			}
+			templ.EmbeddedTmpl = helper.StringToPtr(data)
+		}
+
 		*result = append(*result, templ)
 	}
 
 	return nil
 }
 
+// readTemplateDataFile returns the contents of a template data file. Relative
+// paths are resolved from baseDir, the directory of the job file. Data files
+// are only allowed when parsing a job file, so that job specs submitted
+// remotely can't read the files of the host parsing them.
+func readTemplateDataFile(path, baseDir string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("data_file must not be empty")
+	}
+	if baseDir == "" {
+		return "", fmt.Errorf("data_file %q can only be used when parsing a job file", path)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read data_file: %v", err)
+	}
+	return string(data), nil
+}
+
+// withoutKeys returns the object without the items with the given keys, so
+// that stanzas parsed separately aren't decoded with the rest of the object.
+// Nodes other than objects are returned unchanged.
+func withoutKeys(node ast.Node, keys ...string) ast.Node {
+	ot, ok := node.(*ast.ObjectType)
+	if !ok {
+		return node
+	}
+
+	filtered := &ast.ObjectList{}
+OUTER:
+	for _, item := range ot.List.Items {
+		for _, key := range keys {
+			if len(item.Keys) > 0 && item.Keys[0].Token.Value() == key {
+				continue OUTER
+			}
+		}
+		filtered.Add(item)
+	}
+	return &ast.ObjectType{List: filtered}
+}
+
+// reEnvFile matches an env value loading variables from a file, such as
+// "${file("env/app.env")}".
+var reEnvFile = regexp.MustCompile(`^\$\{\s*file\(\s*"([^"]*)"\s*\)\s*\}$`)
+
+// parseEnvString parses a string env value, which holds variables in the env
+// file format or loads them from a file with file("<path>"). Relative paths
+// are resolved from baseDir, the directory of the job file, and files are only
+// allowed when parsing a job file.
+func parseEnvString(lit *ast.LiteralType, baseDir string) (map[string]string, error) {
+	if lit.Token.Type != token.STRING && lit.Token.Type != token.HEREDOC {
+		return nil, fmt.Errorf("env must be a block or a string")
+	}
+	src := lit.Token.Value().(string)
+
+	if m := reEnvFile.FindStringSubmatch(strings.TrimSpace(src)); m != nil {
+		path := m[1]
+		if path == "" {
+			return nil, fmt.Errorf("env file must not be empty")
+		}
+		if baseDir == "" {
+			return nil, fmt.Errorf("env file %q can only be used when parsing a job file", path)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file: %v", err)
+		}
+		env, err := parseEnvFile(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("env file %q: %v", m[1], err)
+		}
+		return env, nil
+	}
+
+	return parseEnvFile(src)
+}
+
+// parseEnvFile parses variables in the env file format used by docker-compose:
+// one KEY=value per line, ignoring blank lines and lines starting with #. Lines
+// may be prefixed with "export" and values may be quoted.
+func parseEnvFile(src string) (map[string]string, error) {
+	env := make(map[string]string)
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		idx := strings.Index(line, "=")
+		if idx < 1 {
+			return nil, fmt.Errorf("line %d: expected KEY=value", i+1)
+		}
+		key, value := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+
+		if len(value) >= 2 {
+			switch {
+			case value[0] == '"' && value[len(value)-1] == '"':
+				unquoted, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid quoted value: %v", i+1, err)
+				}
+				value = unquoted
+			case value[0] == '\'' && value[len(value)-1] == '\'':
+				value = value[1 : len(value)-1]
+			}
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
 func parseServices(jobName string, taskGroupName string, task *api.Task, serviceObjs *ast.ObjectList) error {
 	task.Services = make([]*api.Service, len(serviceObjs.Items))
 	for idx, o := range serviceObjs.Items {
@@ -1203,6 +1498,7 @@ func parseServices(jobName string, taskGroupName string, task *api.Task, service
 			"port",
 			"check",
 			"address_mode",
+			"provider",
 			"check_restart",
 		}
 		if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
@@ -1248,12 +1544,64 @@ func parseServices(jobName string, taskGroupName string, task *api.Task, service
 			}
 		}
 
+		if err := checkServiceProvider(&service); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("service: '%s',", service.Name))
+		}
+
 		task.Services[idx] = &service
 	}
 
 	return nil
 }
 
+// serviceProviderChecks are the check types supported by each service
+// provider.
+var serviceProviderChecks = map[string][]string{
+	structs.ServiceProviderConsul: {
+		structs.ServiceCheckHTTP,
+		structs.ServiceCheckTCP,
+		structs.ServiceCheckScript,
+		structs.ServiceCheckGRPC,
+	},
+	structs.ServiceProviderNomad: nil,
+}
+
+// checkServiceProvider returns an error if the provider of the service is
+// unknown or doesn't support the types of its checks.
+func checkServiceProvider(service *api.Service) error {
+	provider := service.Provider
+	if provider == "" {
+		provider = structs.ServiceProviderConsul
+	}
+
+	checkTypes, ok := serviceProviderChecks[provider]
+	if !ok {
+		msg := fmt.Sprintf("invalid provider %q", provider)
+		providers := []string{structs.ServiceProviderConsul, structs.ServiceProviderNomad}
+		if suggestion := helper.ClosestString(provider, providers); suggestion != "" {
+			msg += fmt.Sprintf("; did you mean %q?", suggestion)
+		}
+		return errors.New(msg)
+	}
+
+	var mErr multierror.Error
+OUTER:
+	for _, check := range service.Checks {
+		for _, t := range checkTypes {
+			if strings.ToLower(check.Type) == t {
+				continue OUTER
+			}
+		}
+		if len(checkTypes) == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("check %q: the %q provider does not support checks", check.Name, provider))
+			continue
+		}
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("check %q: the %q provider does not support %q checks; supported types are %s",
+			check.Name, provider, check.Type, strings.Join(checkTypes, ", ")))
+	}
+	return mErr.ErrorOrNil()
+}
+
 func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 	service.Checks = make([]api.ServiceCheck, len(checkObjs.Items))
 	for idx, co := range checkObjs.Items {
@@ -1579,6 +1927,63 @@ func parsePorts(networkObj *ast.ObjectList, nw *api.NetworkResource) error {
 	return nil
 }
 
+// groupPort is a port requested by a task of a group.
+type groupPort struct {
+	task  string
+	label string
+	value int
+}
+
+// checkGroupPorts returns an error if the tasks of a group reserve the same
+// static port more than once, which can never be placed, or use the same port
+// label for both a static and a dynamic port or for different static ports.
+func checkGroupPorts(tasks []*api.Task) error {
+	var mErr multierror.Error
+	static := make(map[int]groupPort)
+	labels := make(map[string]groupPort)
+	for _, task := range tasks {
+		if task.Resources == nil {
+			continue
+		}
+		for _, nw := range task.Resources.Networks {
+			ports := append(append([]api.Port{}, nw.ReservedPorts...), nw.DynamicPorts...)
+			for _, port := range ports {
+				p := groupPort{task: task.Name, label: port.Label, value: port.Value}
+
+				if p.value > 0 {
+					if other, ok := static[p.value]; ok {
+						mErr.Errors = append(mErr.Errors, fmt.Errorf("static port %d is reserved by both task %q (port %q) and task %q (port %q)",
+							p.value, other.task, other.label, p.task, p.label))
+					} else {
+						static[p.value] = p
+					}
+				}
+
+				l := strings.ToLower(p.label)
+				other, ok := labels[l]
+				if !ok {
+					labels[l] = p
+					continue
+				}
+				if other.value == p.value {
+					continue
+				}
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("port %q is %s in task %q but %s in task %q",
+					p.label, describePort(other.value), other.task, describePort(p.value), p.task))
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// describePort returns a description of a port's value for errors.
+func describePort(value int) string {
+	if value > 0 {
+		return fmt.Sprintf("static port %d", value)
+	}
+	return "dynamic"
+}
+
 func parseUpdate(result **api.UpdateStrategy, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -1603,11 +2008,19 @@ func parseUpdate(result **api.UpdateStrategy, list *ast.ObjectList) error {
 		"healthy_deadline",
 		"progress_deadline",
 		"auto_revert",
+		"auto_promote",
+		"manual_promotion_per_group",
 		"canary",
 	}
 	if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
 		return err
 	}
+	if err := checkDurations(o.Val, "stagger", "min_healthy_time", "healthy_deadline", "progress_deadline"); err != nil {
+		return err
+	}
+	if err := checkInts(o.Val, nonNegative, "max_parallel", "canary"); err != nil {
+		return err
+	}
 
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
@@ -1644,6 +2057,12 @@ func parseMigrate(result **api.MigrateStrategy, list *ast.ObjectList) error {
 	if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
 		return err
 	}
+	if err := checkDurations(o.Val, "min_healthy_time", "healthy_deadline"); err != nil {
+		return err
+	}
+	if err := checkInts(o.Val, nonNegative, "max_parallel"); err != nil {
+		return err
+	}
 
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
@@ -1675,6 +2094,7 @@ func parsePeriodic(result **api.PeriodicConfig, list *ast.ObjectList) error {
 		"enabled",
 		"cron",
 		"prohibit_overlap",
+		"overlap_policy",
 		"time_zone",
 	}
 	if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
@@ -1704,6 +2124,146 @@ func parsePeriodic(result **api.PeriodicConfig, list *ast.ObjectList) error {
 	return nil
 }
 
+func parseMultiregion(result **api.Multiregion, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'multiregion' block allowed per job")
+	}
+
+	// Get our resource object
+	o := list.Items[0]
+
+	// We need this later
+	var listVal *ast.ObjectList
+	if ot, ok := o.Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("multiregion: should be an object")
+	}
+
+	// Check for invalid keys
+	valid := []string{
+		"strategy",
+		"region",
+	}
+	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var mr api.Multiregion
+
+	// Parse the strategy
+	if o := listVal.Filter("strategy"); len(o.Items) > 0 {
+		if err := parseMultiregionStrategy(&mr.Strategy, o); err != nil {
+			return multierror.Prefix(err, "strategy ->")
+		}
+	}
+
+	// Parse the regions
+	if o := listVal.Filter("region"); len(o.Items) > 0 {
+		if err := parseMultiregionRegions(&mr.Regions, o); err != nil {
+			return multierror.Prefix(err, "region ->")
+		}
+	}
+
+	*result = &mr
+	return nil
+}
+
+func parseMultiregionStrategy(result **api.MultiregionStrategy, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'strategy' block allowed")
+	}
+
+	// Get our resource object
+	o := list.Items[0]
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, o.Val); err != nil {
+		return err
+	}
+
+	// Check for invalid keys
+	valid := []string{
+		"max_parallel",
+		"on_failure",
+	}
+	if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
+		return err
+	}
+
+	var s api.MultiregionStrategy
+	if err := mapstructure.WeakDecode(m, &s); err != nil {
+		return err
+	}
+	*result = &s
+	return nil
+}
+
+func parseMultiregionRegions(result *[]*api.MultiregionRegion, list *ast.ObjectList) error {
+	seen := make(map[string]struct{})
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 {
+			return fmt.Errorf("missing region name")
+		}
+		n := item.Keys[0].Token.Value().(string)
+
+		// Make sure we haven't already found this
+		if _, ok := seen[n]; ok {
+			return fmt.Errorf("region '%s' defined more than once", n)
+		}
+		seen[n] = struct{}{}
+
+		// We need this later
+		var listVal *ast.ObjectList
+		if ot, ok := item.Val.(*ast.ObjectType); ok {
+			listVal = ot.List
+		} else {
+			return fmt.Errorf("region should be an object")
+		}
+
+		// Check for invalid keys
+		valid := []string{
+			"count",
+			"datacenters",
+			"meta",
+		}
+		if err := helper.CheckHCLKeys(listVal, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+		delete(m, "meta")
+
+		// Decode the region
+		var r api.MultiregionRegion
+		r.Name = n
+		if err := mapstructure.WeakDecode(m, &r); err != nil {
+			return err
+		}
+
+		// Parse out meta fields
+		if metaO := listVal.Filter("meta"); len(metaO.Items) > 0 {
+			for _, o := range metaO.Elem().Items {
+				var m map[string]interface{}
+				if err := hcl.DecodeObject(&m, o.Val); err != nil {
+					return err
+				}
+				if err := mapstructure.WeakDecode(m, &r.Meta); err != nil {
+					return err
+				}
+			}
+		}
+
+		*result = append(*result, &r)
+	}
+	return nil
+}
+
 func parseVault(result *api.Vault, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) == 0 {
@@ -1730,6 +2290,7 @@ func parseVault(result *api.Vault, list *ast.ObjectList) error {
 		"env",
 		"change_mode",
 		"change_signal",
+		"cluster",
 	}
 	if err := helper.CheckHCLKeys(listVal, valid); err != nil {
 		return multierror.Prefix(err, "vault ->")
@@ -1766,10 +2327,13 @@ func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.Object
 		"payload",
 		"meta_required",
 		"meta_optional",
+		"meta_types",
+		"payload_schema",
 	}
 	if err := helper.CheckHCLKeys(o.Val, valid); err != nil {
 		return err
 	}
+	delete(m, "meta_types")
 
 	// Build the parameterized job block
 	var d api.ParameterizedJobConfig
@@ -1777,6 +2341,25 @@ func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.Object
 		return err
 	}
 
+	// Parse the meta types
+	var listVal *ast.ObjectList
+	if ot, ok := o.Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("parameterized: should be an object")
+	}
+	if typesO := listVal.Filter("meta_types"); len(typesO.Items) > 0 {
+		for _, o := range typesO.Elem().Items {
+			var m map[string]interface{}
+			if err := hcl.DecodeObject(&m, o.Val); err != nil {
+				return err
+			}
+			if err := mapstructure.WeakDecode(m, &d.MetaTypes); err != nil {
+				return err
+			}
+		}
+	}
+
 	*result = &d
 	return nil
 }
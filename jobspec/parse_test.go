@@ -1,6 +1,8 @@
 package jobspec
 
 import (
+	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -163,6 +165,7 @@ func TestParse(t *testing.T) {
 							HealthyDeadline:  helper.TimeToPtr(1 * time.Minute),
 							ProgressDeadline: helper.TimeToPtr(1 * time.Minute),
 							AutoRevert:       helper.BoolToPtr(false),
+							AutoPromote:      helper.BoolToPtr(true),
 							Canary:           helper.IntToPtr(2),
 						},
 						Migrate: &api.MigrateStrategy{
@@ -260,6 +263,11 @@ func TestParse(t *testing.T) {
 								LogConfig: &api.LogConfig{
 									MaxFiles:      helper.IntToPtr(14),
 									MaxFileSizeMB: helper.IntToPtr(101),
+									Sink:          helper.StringToPtr("syslog"),
+									SinkOptions: map[string]string{
+										"facility": "local0",
+										"tag":      "binstore",
+									},
 								},
 								Artifacts: []*api.TaskArtifact{
 									{
@@ -292,6 +300,7 @@ func TestParse(t *testing.T) {
 										Perms:        helper.StringToPtr("0644"),
 										Envvars:      helper.BoolToPtr(true),
 										VaultGrace:   helper.TimeToPtr(33 * time.Second),
+										Sandbox:      helper.BoolToPtr(true),
 									},
 									{
 										SourcePath: helper.StringToPtr("bar"),
@@ -329,6 +338,7 @@ func TestParse(t *testing.T) {
 									Env:          helper.BoolToPtr(false),
 									ChangeMode:   helper.StringToPtr(structs.VaultChangeModeSignal),
 									ChangeSignal: helper.StringToPtr("SIGUSR1"),
+									Cluster:      helper.StringToPtr("eu"),
 								},
 							},
 						},
@@ -457,6 +467,21 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"periodic-overlap-policy.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("foo"),
+				Name: helper.StringToPtr("foo"),
+				Periodic: &api.PeriodicConfig{
+					SpecType:      helper.StringToPtr(api.PeriodicSpecCron),
+					Spec:          helper.StringToPtr("*/5 * * *"),
+					OverlapPolicy: helper.StringToPtr(api.PeriodicOverlapEnqueue),
+					TimeZone:      helper.StringToPtr("Europe/Minsk"),
+				},
+			},
+			false,
+		},
+
 		{
 			"specify-job.hcl",
 			&api.Job{
@@ -660,6 +685,25 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"parameterized-job-schema.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("parameterized_job"),
+				Name: helper.StringToPtr("parameterized_job"),
+
+				ParameterizedJob: &api.ParameterizedJobConfig{
+					Payload:      "optional",
+					MetaRequired: []string{"count"},
+					MetaOptional: []string{"verbose"},
+					MetaTypes: map[string]string{
+						"count":   "int",
+						"verbose": "bool",
+					},
+					PayloadSchema: "{\"type\": \"object\", \"required\": [\"name\"]}\n",
+				},
+			},
+			false,
+		},
 		{
 			"job-with-kill-signal.hcl",
 			&api.Job{
@@ -767,6 +811,33 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"service-provider.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("service_provider"),
+				Name: helper.StringToPtr("service_provider"),
+				Type: helper.StringToPtr("service"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("group"),
+						Tasks: []*api.Task{
+							{
+								Name: "task",
+								Services: []*api.Service{
+									{
+										Name:      "http-service",
+										PortLabel: "http",
+										Provider:  "nomad",
+										Tags:      []string{"web"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 		{
 			"reschedule-job.hcl",
 			&api.Job{
@@ -869,6 +940,49 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"multiregion.hcl",
+			&api.Job{
+				ID:          helper.StringToPtr("foo"),
+				Name:        helper.StringToPtr("foo"),
+				Datacenters: []string{"dc1"},
+				Multiregion: &api.Multiregion{
+					Strategy: &api.MultiregionStrategy{
+						MaxParallel: helper.IntToPtr(1),
+						OnFailure:   helper.StringToPtr("fail_all"),
+					},
+					Regions: []*api.MultiregionRegion{
+						{
+							Name:        "west",
+							Count:       helper.IntToPtr(2),
+							Datacenters: []string{"west-1"},
+							Meta:        map[string]string{"region_code": "W"},
+						},
+						{
+							Name:        "east",
+							Count:       helper.IntToPtr(1),
+							Datacenters: []string{"east-1", "east-2"},
+						},
+					},
+				},
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("bar"),
+						Tasks: []*api.Task{
+							{
+								Name:   "bar",
+								Driver: "raw_exec",
+								Config: map[string]interface{}{
+									"command": "bash",
+									"args":    []interface{}{"-c", "echo hi"},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -925,6 +1039,28 @@ func TestOverlappingPorts(t *testing.T) {
 	}
 }
 
+func TestGroupPortConflicts(t *testing.T) {
+	path, err := filepath.Abs(filepath.Join("./test-fixtures", "group-port-conflicts.hcl"))
+	if err != nil {
+		t.Fatalf("Can't get absolute path for file: %s", err)
+	}
+
+	_, err = ParseFile(path)
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+
+	expected := []string{
+		`static port 8080 is reserved by both task "web" (port "http") and task "sidecar" (port "proxy")`,
+		`port "admin" is dynamic in task "web" but static port 9000 in task "sidecar"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(err.Error(), e) {
+			t.Fatalf("Expected error containing %q; got %v", e, err)
+		}
+	}
+}
+
 func TestIncorrectKey(t *testing.T) {
 	path, err := filepath.Abs(filepath.Join("./test-fixtures", "basic_wrong_key.hcl"))
 	if err != nil {
@@ -941,3 +1077,309 @@ func TestIncorrectKey(t *testing.T) {
 		t.Fatalf("Expected key error; got %v", err)
 	}
 }
+
+func TestParseFile_ErrorFilename(t *testing.T) {
+	// Errors are prefixed with the path as given, so the failing file of a
+	// multi-file setup can be located
+	path := filepath.Join("test-fixtures", "basic_wrong_key.hcl")
+	_, err := ParseFile(path)
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if !strings.HasPrefix(err.Error(), path+": error parsing 'job': ") {
+		t.Fatalf("Expected error prefixed with %q; got %v", path, err)
+	}
+
+	// Files that can't be read report their path too
+	path = filepath.Join("test-fixtures", "missing.hcl")
+	if _, err := ParseFile(path); err == nil || !strings.Contains(err.Error(), "missing.hcl") {
+		t.Fatalf("Expected error about %q; got %v", path, err)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	src := `
+job "foo" {
+  datacenters = ["dc1"]
+
+  group "bar" {
+    task "baz" {
+      driver = "docker"
+    }
+  }
+}
+`
+
+	job, err := ParseBytes([]byte(src), "foo.nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if *job.ID != "foo" || len(job.TaskGroups) != 1 || job.TaskGroups[0].Tasks[0].Driver != "docker" {
+		t.Fatalf("bad job: %#v", job)
+	}
+
+	job2, err := ParseString(src, "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(job, job2) {
+		t.Fatalf("expected the same job from ParseString:\n%s", pretty.Diff(job, job2))
+	}
+
+	// The filename is included in errors
+	_, err = ParseString(`job "foo" { bad = 1 }`, "foo.nomad")
+	if err == nil || !strings.HasPrefix(err.Error(), "foo.nomad: ") {
+		t.Fatalf("expected error prefixed with the filename, got %v", err)
+	}
+
+	_, err = ParseString(`job "foo" {`, "")
+	if err == nil || !strings.HasPrefix(err.Error(), "error parsing: ") {
+		t.Fatalf("expected syntax error, got %v", err)
+	}
+}
+
+func TestParse_InvalidOperator(t *testing.T) {
+	cases := []struct {
+		stanza string
+		err    string
+	}{
+		{
+			stanza: `constraint { attribute = "${attr.kernel.name}" operator = "set_contain" value = "linux" }`,
+			err:    `invalid operator "set_contain" (line 4, column 16); did you mean "set_contains"?`,
+		},
+		{
+			stanza: `constraint { attribute = "${meta.rack}" operator = "eq" value = "r1" }`,
+			err:    `invalid operator "eq" (line 4, column 16)`,
+		},
+		{
+			stanza: `affinity { attribute = "${meta.rack}" operator = "is_set" weight = 50 }`,
+			err:    `invalid operator "is_set"`,
+		},
+		{
+			stanza: `affinity { attribute = "${node.datacenter}" operator = "regex" value = "dc.*" }`,
+			err:    `did you mean "regexp"?`,
+		},
+	}
+	for _, c := range cases {
+		src := fmt.Sprintf(`
+job "foo" {
+  group "bar" {
+    %s
+    task "baz" {
+      driver = "docker"
+    }
+  }
+}
+`, c.stanza)
+
+		_, err := ParseString(src, "")
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expected error containing %q, got %v", c.err, err)
+		}
+	}
+}
+
+func TestParse_TemplateDataFile(t *testing.T) {
+	path := filepath.Join("test-fixtures", "template-data-file.hcl")
+	job, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The data file is resolved from the directory of the job file
+	templ := job.TaskGroups[0].Tasks[0].Templates[0]
+	expected := "{{ key \"service/redis/maxconns\" }}\n"
+	if templ.EmbeddedTmpl == nil || *templ.EmbeddedTmpl != expected {
+		t.Fatalf("expected embedded template %q, got %v", expected, templ.EmbeddedTmpl)
+	}
+	if *templ.DestPath != "local/redis.conf" {
+		t.Fatalf("bad destination: %v", *templ.DestPath)
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Data files can't be read when the job isn't parsed from a file
+	_, err = ParseBytes(src, "")
+	if err == nil || !strings.Contains(err.Error(), "can only be used when parsing a job file") {
+		t.Fatalf("expected data_file error, got %v", err)
+	}
+
+	// Missing data files are reported
+	_, err = ParseBytes(src, filepath.Join("missing", "job.nomad"))
+	if err == nil || !strings.Contains(err.Error(), "failed to read data_file") {
+		t.Fatalf("expected missing data_file error, got %v", err)
+	}
+
+	// Only one of data and data_file may be set
+	conflict := strings.Replace(string(src), "data_file", "data = \"foo\"\n        data_file", 1)
+	_, err = ParseBytes([]byte(conflict), path)
+	if err == nil || !strings.Contains(err.Error(), "only one of data and data_file may be set") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+func TestParse_ServiceProvider_Invalid(t *testing.T) {
+	cases := []struct {
+		service string
+		err     string
+	}{
+		{
+			service: `provider = "nomda"`,
+			err:     `invalid provider "nomda"; did you mean "nomad"?`,
+		},
+		{
+			service: `provider = "nomad"
+        check { name = "alive" type = "tcp" interval = "10s" timeout = "2s" }`,
+			err: `check "alive": the "nomad" provider does not support checks`,
+		},
+		{
+			service: `check { name = "alive" type = "icmp" interval = "10s" timeout = "2s" }`,
+			err:     `check "alive": the "consul" provider does not support "icmp" checks; supported types are http, tcp, script, grpc`,
+		},
+	}
+	for _, c := range cases {
+		src := fmt.Sprintf(`
+job "foo" {
+  group "bar" {
+    task "baz" {
+      driver = "docker"
+      service {
+        name = "web"
+        port = "http"
+        %s
+      }
+    }
+  }
+}
+`, c.service)
+
+		_, err := ParseString(src, "")
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expected error containing %q, got %v", c.err, err)
+		}
+	}
+}
+
+func TestParse_EnvFile(t *testing.T) {
+	path := filepath.Join("test-fixtures", "env-file.hcl")
+	job, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Env values are merged in order, with files resolved from the directory
+	// of the job file
+	expected := map[string]string{
+		"DB_HOST":   "db.service.consul",
+		"DB_PORT":   "6432",
+		"GREETING":  "hello\tworld",
+		"LITERAL":   "${not.interpolated}",
+		"LOG_LEVEL": "debug",
+		"REGION":    "us-east-1",
+	}
+	if env := job.TaskGroups[0].Tasks[0].Env; !reflect.DeepEqual(env, expected) {
+		t.Fatalf("expected env %v, got %v", expected, env)
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Env files can't be read when the job isn't parsed from a file
+	_, err = ParseBytes(src, "")
+	if err == nil || !strings.Contains(err.Error(), "can only be used when parsing a job file") {
+		t.Fatalf("expected env file error, got %v", err)
+	}
+
+	// Missing env files are reported
+	_, err = ParseBytes(src, filepath.Join("missing", "job.nomad"))
+	if err == nil || !strings.Contains(err.Error(), "failed to read env file") {
+		t.Fatalf("expected missing env file error, got %v", err)
+	}
+
+	// Invalid lines are reported
+	invalid := strings.Replace(string(src), "LOG_LEVEL=debug", "LOG_LEVEL", 1)
+	_, err = ParseBytes([]byte(invalid), path)
+	if err == nil || !strings.Contains(err.Error(), "line 1: expected KEY=value") {
+		t.Fatalf("expected invalid line error, got %v", err)
+	}
+}
+
+func TestParse_StrictFieldTypes(t *testing.T) {
+	cases := []struct {
+		name   string
+		stanza string
+		err    string
+	}{
+		{
+			name:   "duration as number",
+			stanza: "update {\n    min_healthy_time = 10\n  }",
+			err:    "min_healthy_time must be a duration string such as \"30s\" (line 4, column 5)",
+		},
+		{
+			name:   "invalid duration",
+			stanza: "migrate {\n    healthy_deadline = \"5 minutes\"\n  }",
+			err:    "healthy_deadline has an invalid duration \"5 minutes\" (line 4, column 5)",
+		},
+		{
+			name:   "negative duration",
+			stanza: "reschedule {\n    delay = \"-15s\"\n  }",
+			err:    "delay must not be negative (line 4, column 5)",
+		},
+		{
+			name:   "integer as string",
+			stanza: "reschedule {\n    attempts = \"3\"\n  }",
+			err:    "attempts must be an integer (line 4, column 5)",
+		},
+		{
+			name:   "negative integer",
+			stanza: "update {\n    max_parallel = -1\n  }",
+			err:    "max_parallel must be greater than or equal to 0 (line 4, column 5)",
+		},
+		{
+			name:   "spread weight out of range",
+			stanza: "spread {\n    attribute = \"${node.datacenter}\"\n    weight = 150\n  }",
+			err:    "weight must be between 0 and 100 (line 5, column 5)",
+		},
+		{
+			name:   "spread percent out of range",
+			stanza: "spread {\n    attribute = \"${node.datacenter}\"\n    target \"dc1\" {\n      percent = 101\n    }\n  }",
+			err:    "'dc1' -> percent must be between 0 and 100 (line 6, column 7)",
+		},
+		{
+			name:   "affinity weight out of range",
+			stanza: "affinity {\n    attribute = \"${node.datacenter}\"\n    value = \"dc1\"\n    weight = -200\n  }",
+			err:    "weight must be between -100 and 100 (line 6, column 5)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := "job \"foo\" {\n  datacenters = [\"dc1\"]\n  " + c.stanza + "\n}\n"
+			_, err := ParseString(src, "")
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+
+	// Task durations are checked too
+	src := `
+job "foo" {
+  group "bar" {
+    task "baz" {
+      driver       = "docker"
+      kill_timeout = 30
+    }
+  }
+}
+`
+	_, err := ParseString(src, "")
+	if err == nil || !strings.Contains(err.Error(), "'baz' -> kill_timeout must be a duration string") {
+		t.Fatalf("expected kill_timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package jobspec
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+var (
+	jobSchema     map[string]interface{}
+	jobSchemaOnce sync.Once
+)
+
+// JobSchema returns a JSON Schema of the JSON jobs accepted by the jobs API,
+// generated from api.Job. Objects don't allow properties the API doesn't
+// decode, so clients such as the web UI can catch misspelled fields before
+// submitting a job. Durations are integers of nanoseconds, and null is
+// allowed for every field. The schema is shared and must not be modified.
+func JobSchema() map[string]interface{} {
+	jobSchemaOnce.Do(func() {
+		jobSchema = typeSchema(reflect.TypeOf(api.Job{}), make(map[reflect.Type]bool))
+		jobSchema["$schema"] = "http://json-schema.org/draft-07/schema#"
+		jobSchema["title"] = "Nomad job"
+	})
+	return jobSchema
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// typeSchema returns the JSON Schema of the JSON encoding of a type. Types
+// that reference themselves are allowed any value below the first level.
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case durationType:
+		return map[string]interface{}{"type": []string{"integer", "null"}}
+	case timeType:
+		return map[string]interface{}{"type": []string{"string", "null"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": []string{"boolean", "null"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": []string{"integer", "null"}}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": []string{"number", "null"}}
+	case reflect.String:
+		return map[string]interface{}{"type": []string{"string", "null"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 encoded strings
+			return map[string]interface{}{"type": []string{"string", "null"}}
+		}
+		return map[string]interface{}{
+			"type":  []string{"array", "null"},
+			"items": typeSchema(t.Elem(), visiting),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 []string{"object", "null"},
+			"additionalProperties": typeSchema(t.Elem(), visiting),
+		}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := make(map[string]interface{})
+		structProperties(t, properties, visiting)
+		return map[string]interface{}{
+			"type":                 []string{"object", "null"},
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	default:
+		// Interfaces, such as the config of task drivers, allow any value
+		return map[string]interface{}{}
+	}
+}
+
+// structProperties adds the JSON fields of a struct, including the fields of
+// its embedded structs, to the properties.
+func structProperties(t reflect.Type, properties map[string]interface{}, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				structProperties(ft, properties, visiting)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// Unexported fields aren't encoded
+			continue
+		}
+
+		properties[name] = typeSchema(f.Type, visiting)
+	}
+}
@@ -0,0 +1,34 @@
+package jobspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSchema(t *testing.T) {
+	schema := JobSchema()
+	require.Equal(t, false, schema["additionalProperties"])
+
+	props := schema["properties"].(map[string]interface{})
+	require.Contains(t, props, "ID")
+	require.Contains(t, props, "Datacenters")
+	require.Equal(t, []string{"string", "null"}, props["Region"].(map[string]interface{})["type"])
+
+	groups := props["TaskGroups"].(map[string]interface{})
+	require.Equal(t, []string{"array", "null"}, groups["type"])
+
+	group := groups["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Contains(t, group, "Count")
+
+	task := group["Tasks"].(map[string]interface{})["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Contains(t, task, "Driver")
+
+	// Driver configs allow any value
+	config := task["Config"].(map[string]interface{})
+	require.Equal(t, []string{"object", "null"}, config["type"])
+	require.Empty(t, config["additionalProperties"])
+
+	// Durations are nanoseconds
+	require.Equal(t, []string{"integer", "null"}, task["KillTimeout"].(map[string]interface{})["type"])
+}
@@ -0,0 +1,134 @@
+package jobspec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+const (
+	// StanzaScopeJob is the scope of custom stanzas placed directly in the
+	// job stanza. They are decoded into the meta of the job.
+	StanzaScopeJob = "job"
+
+	// StanzaScopeTask is the scope of custom stanzas placed in task
+	// stanzas. They are decoded into the meta of the task.
+	StanzaScopeTask = "task"
+
+	// customStanzaPrefix is the prefix required for the names of custom
+	// stanzas, so that they never shadow the stanzas of the job
+	// specification.
+	customStanzaPrefix = "x-"
+)
+
+// StanzaFunc decodes the body of a custom stanza into meta entries. It is
+// called once for each occurrence of the stanza.
+type StanzaFunc func(body map[string]interface{}) (map[string]string, error)
+
+var (
+	customStanzas     = make(map[string]map[string]StanzaFunc)
+	customStanzasLock sync.RWMutex
+)
+
+// RegisterStanza registers a handler for a custom stanza, allowing jobs to
+// contain vendor-specific stanzas such as:
+//
+//	x-costcenter {
+//	  team = "infra"
+//	}
+//
+// Stanza names must start with "x-", and may only be registered once per
+// scope. Custom stanzas are parsed into the meta of the job or task, and are
+// rejected as invalid keys if no handler is registered for them.
+func RegisterStanza(scope, name string, fn StanzaFunc) error {
+	switch scope {
+	case StanzaScopeJob, StanzaScopeTask:
+	default:
+		return fmt.Errorf("invalid stanza scope %q", scope)
+	}
+	if !strings.HasPrefix(name, customStanzaPrefix) || len(name) == len(customStanzaPrefix) {
+		return fmt.Errorf("custom stanza name %q must start with %q", name, customStanzaPrefix)
+	}
+	if fn == nil {
+		return fmt.Errorf("custom stanza %q requires a handler", name)
+	}
+
+	customStanzasLock.Lock()
+	defer customStanzasLock.Unlock()
+
+	if _, ok := customStanzas[scope][name]; ok {
+		return fmt.Errorf("custom stanza %q already registered for scope %q", name, scope)
+	}
+	if customStanzas[scope] == nil {
+		customStanzas[scope] = make(map[string]StanzaFunc)
+	}
+	customStanzas[scope][name] = fn
+	return nil
+}
+
+// DeregisterStanza removes the handler of a custom stanza.
+func DeregisterStanza(scope, name string) {
+	customStanzasLock.Lock()
+	defer customStanzasLock.Unlock()
+	delete(customStanzas[scope], name)
+}
+
+// customStanzaNames returns the sorted names of the custom stanzas registered
+// for a scope.
+func customStanzaNames(scope string) []string {
+	customStanzasLock.RLock()
+	defer customStanzasLock.RUnlock()
+
+	names := make([]string, 0, len(customStanzas[scope]))
+	for name := range customStanzas[scope] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseCustomStanzas decodes the custom stanzas of a scope into meta. Keys
+// already set in meta, or by another custom stanza, are an error.
+func parseCustomStanzas(scope string, result *map[string]string, list *ast.ObjectList) error {
+	customStanzasLock.RLock()
+	handlers := make(map[string]StanzaFunc, len(customStanzas[scope]))
+	names := make([]string, 0, len(customStanzas[scope]))
+	for name, fn := range customStanzas[scope] {
+		handlers[name] = fn
+		names = append(names, name)
+	}
+	customStanzasLock.RUnlock()
+
+	// Decode the stanzas in a stable order so conflicts are reported
+	// consistently
+	sort.Strings(names)
+	for _, name := range names {
+		fn := handlers[name]
+		o := list.Filter(name)
+		for _, item := range o.Elem().Items {
+			var m map[string]interface{}
+			if err := hcl.DecodeObject(&m, item.Val); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+
+			meta, err := fn(m)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			for k, v := range meta {
+				if _, ok := (*result)[k]; ok {
+					return fmt.Errorf("%s: meta key %q already set", name, k)
+				}
+				if *result == nil {
+					*result = make(map[string]string, len(meta))
+				}
+				(*result)[k] = v
+			}
+		}
+	}
+	return nil
+}
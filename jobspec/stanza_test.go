@@ -0,0 +1,81 @@
+package jobspec
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterStanza(t *testing.T) {
+	require := require.New(t)
+
+	noop := func(map[string]interface{}) (map[string]string, error) { return nil, nil }
+
+	require.Error(RegisterStanza("group", "x-foo", noop))
+	require.Error(RegisterStanza(StanzaScopeJob, "foo", noop))
+	require.Error(RegisterStanza(StanzaScopeJob, "x-", noop))
+	require.Error(RegisterStanza(StanzaScopeJob, "x-foo", nil))
+
+	require.NoError(RegisterStanza(StanzaScopeJob, "x-foo", noop))
+	defer DeregisterStanza(StanzaScopeJob, "x-foo")
+	require.Error(RegisterStanza(StanzaScopeJob, "x-foo", noop))
+	require.NoError(RegisterStanza(StanzaScopeTask, "x-foo", noop))
+	DeregisterStanza(StanzaScopeTask, "x-foo")
+
+	require.Equal([]string{"x-foo"}, customStanzaNames(StanzaScopeJob))
+	require.Empty(customStanzaNames(StanzaScopeTask))
+}
+
+func TestParse_CustomStanzas(t *testing.T) {
+	require := require.New(t)
+
+	path, err := filepath.Abs(filepath.Join("./test-fixtures", "custom-stanza.hcl"))
+	require.NoError(err)
+
+	// Custom stanzas are invalid keys until a handler is registered
+	_, err = ParseFile(path)
+	require.Error(err)
+	require.Contains(err.Error(), "invalid key: x-costcenter")
+
+	costCenter := func(body map[string]interface{}) (map[string]string, error) {
+		meta := make(map[string]string, len(body))
+		for k, v := range body {
+			meta["costcenter."+k] = fmt.Sprint(v)
+		}
+		return meta, nil
+	}
+	require.NoError(RegisterStanza(StanzaScopeJob, "x-costcenter", costCenter))
+	defer DeregisterStanza(StanzaScopeJob, "x-costcenter")
+	require.NoError(RegisterStanza(StanzaScopeTask, "x-costcenter", costCenter))
+	defer DeregisterStanza(StanzaScopeTask, "x-costcenter")
+
+	job, err := ParseFile(path)
+	require.NoError(err)
+	require.Equal(map[string]string{
+		"owner":           "ops",
+		"costcenter.team": "infra",
+		"costcenter.code": "1234",
+	}, job.Meta)
+	require.Equal(map[string]string{
+		"costcenter.team": "web",
+	}, job.TaskGroups[0].Tasks[0].Meta)
+
+	// Handler errors and meta conflicts fail the parsing
+	DeregisterStanza(StanzaScopeTask, "x-costcenter")
+	require.NoError(RegisterStanza(StanzaScopeTask, "x-costcenter", func(map[string]interface{}) (map[string]string, error) {
+		return nil, fmt.Errorf("unknown team")
+	}))
+	_, err = ParseFile(path)
+	require.Error(err)
+	require.Contains(err.Error(), "x-costcenter: unknown team")
+
+	DeregisterStanza(StanzaScopeJob, "x-costcenter")
+	require.NoError(RegisterStanza(StanzaScopeJob, "x-costcenter", func(map[string]interface{}) (map[string]string, error) {
+		return map[string]string{"owner": "finance"}, nil
+	}))
+	_, err = ParseFile(path)
+	require.Error(err)
+	require.Contains(err.Error(), `meta key "owner" already set`)
+}
@@ -84,6 +84,7 @@ job "binstore-storagelocker" {
         healthy_deadline = "1m"
         progress_deadline = "1m"
         auto_revert = false
+        auto_promote = true
         canary = 2
     }
 
@@ -139,6 +140,12 @@ job "binstore-storagelocker" {
       logs {
         max_files     = 14
         max_file_size = 101
+        sink          = "syslog"
+
+        sink_options {
+          facility = "local0"
+          tag      = "binstore"
+        }
       }
 
       env {
@@ -249,6 +256,7 @@ job "binstore-storagelocker" {
         splay = "10s"
         env = true
         vault_grace = "33s"
+        sandbox = true
       }
 
       template {
@@ -282,6 +290,7 @@ job "binstore-storagelocker" {
         env = false
         change_mode = "signal"
         change_signal = "SIGUSR1"
+        cluster = "eu"
       }
     }
 
@@ -0,0 +1,20 @@
+job "foo" {
+  meta {
+    owner = "ops"
+  }
+
+  x-costcenter {
+    team = "infra"
+    code = 1234
+  }
+
+  group "bar" {
+    task "baz" {
+      driver = "docker"
+
+      x-costcenter {
+        team = "web"
+      }
+    }
+  }
+}
@@ -0,0 +1,18 @@
+job "foo" {
+  group "bar" {
+    task "baz" {
+      driver = "docker"
+
+      env = "${file("env/app.env")}"
+
+      env = <<EOF
+LOG_LEVEL=debug
+DB_PORT=6432
+EOF
+
+      env {
+        REGION = "us-east-1"
+      }
+    }
+  }
+}
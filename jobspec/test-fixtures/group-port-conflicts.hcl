@@ -0,0 +1,33 @@
+job "foo" {
+  group "bar" {
+    task "web" {
+      driver = "docker"
+
+      resources {
+        network {
+          port "http" {
+            static = 8080
+          }
+
+          port "admin" {}
+        }
+      }
+    }
+
+    task "sidecar" {
+      driver = "docker"
+
+      resources {
+        network {
+          port "proxy" {
+            static = 8080
+          }
+
+          port "admin" {
+            static = 9000
+          }
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,14 @@
+job "parameterized_job" {
+    parameterized {
+        payload = "optional"
+        meta_required = ["count"]
+        meta_optional = ["verbose"]
+        meta_types {
+            count = "int"
+            verbose = "bool"
+        }
+        payload_schema = <<EOT
+{"type": "object", "required": ["name"]}
+EOT
+    }
+}
@@ -0,0 +1,7 @@
+job "foo" {
+    periodic {
+        cron = "*/5 * * *"
+        overlap_policy = "enqueue"
+        time_zone = "Europe/Minsk"
+    }
+}
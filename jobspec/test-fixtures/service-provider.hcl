@@ -0,0 +1,13 @@
+job "service_provider" {
+    type = "service"
+    group "group" {
+        task "task" {
+          service {
+            name     = "http-service"
+            port     = "http"
+            provider = "nomad"
+            tags     = ["web"]
+          }
+        }
+    }
+}
@@ -0,0 +1,12 @@
+job "foo" {
+  group "bar" {
+    task "baz" {
+      driver = "docker"
+
+      template {
+        data_file   = "templates/redis.tpl"
+        destination = "local/redis.conf"
+      }
+    }
+  }
+}
@@ -0,0 +1 @@
+{{ key "service/redis/maxconns" }}
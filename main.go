@@ -9,6 +9,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	"github.com/hashicorp/nomad/command"
 	"github.com/hashicorp/nomad/drivers/docker/docklog"
 	"github.com/hashicorp/nomad/version"
@@ -38,6 +39,7 @@ var (
 		"server-members",
 		"syslog",
 		docklog.PluginName,
+		getter.SandboxCommand,
 	}
 
 	// aliases is the list of aliases we want users to be aware of. We hide
@@ -84,7 +86,7 @@ func RunCustom(args []string) int {
 
 	// Don't use color if disabled
 	color := true
-	if os.Getenv(command.EnvNomadCLINoColor) != "" {
+	if os.Getenv(command.EnvNomadCLINoColor) != "" || os.Getenv(command.EnvNoColor) != "" {
 		color = false
 	}
 
@@ -54,6 +54,9 @@ func resolveTokenFromSnapshotCache(snap *state.StateSnapshot, cache *lru.TwoQueu
 		if token == nil {
 			return nil, structs.ErrTokenNotFound
 		}
+		if token.IsExpired(time.Now()) {
+			return nil, structs.ErrTokenExpired
+		}
 	}
 
 	// Check if this is a management token
@@ -61,9 +64,13 @@ func resolveTokenFromSnapshotCache(snap *state.StateSnapshot, cache *lru.TwoQueu
 		return acl.ManagementACL, nil
 	}
 
-	// Get all associated policies
-	policies := make([]*structs.ACLPolicy, 0, len(token.Policies))
-	for _, policyName := range token.Policies {
+	// Get all associated policies, including those granted by roles
+	policyNames, err := tokenPolicyNames(&snap.StateStore, token)
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*structs.ACLPolicy, 0, len(policyNames))
+	for _, policyName := range policyNames {
 		policy, err := snap.ACLPolicyByName(nil, policyName)
 		if err != nil {
 			return nil, err
@@ -84,3 +91,37 @@ func resolveTokenFromSnapshotCache(snap *state.StateSnapshot, cache *lru.TwoQueu
 	}
 	return aclObj, nil
 }
+
+// tokenPolicyNames returns the names of the policies associated with the
+// token directly and through its roles.
+func tokenPolicyNames(state *state.StateStore, token *structs.ACLToken) ([]string, error) {
+	if len(token.Roles) == 0 {
+		return token.Policies, nil
+	}
+
+	seen := make(map[string]struct{}, len(token.Policies))
+	names := make([]string, 0, len(token.Policies))
+	add := func(policies []string) {
+		for _, policyName := range policies {
+			if _, ok := seen[policyName]; ok {
+				continue
+			}
+			seen[policyName] = struct{}{}
+			names = append(names, policyName)
+		}
+	}
+	add(token.Policies)
+
+	for _, roleName := range token.Roles {
+		role, err := state.ACLRoleByName(nil, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			// Ignore roles that don't exist, since they don't grant any more privilege
+			continue
+		}
+		add(role.Policies)
+	}
+	return names, nil
+}
@@ -1,17 +1,21 @@
 package nomad
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	metrics "github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/auth"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -148,8 +152,12 @@ func (a *ACL) ListPolicies(args *structs.ACLPolicyListRequest, reply *structs.AC
 			return structs.ErrTokenNotFound
 		}
 
-		policies = make(map[string]struct{}, len(token.Policies))
-		for _, p := range token.Policies {
+		names, err := tokenPolicyNames(&snap.StateStore, token)
+		if err != nil {
+			return err
+		}
+		policies = make(map[string]struct{}, len(names))
+		for _, p := range names {
 			policies[p] = struct{}{}
 		}
 	}
@@ -159,33 +167,902 @@ func (a *ACL) ListPolicies(args *structs.ACLPolicyListRequest, reply *structs.AC
 		queryOpts: &args.QueryOptions,
 		queryMeta: &reply.QueryMeta,
 		run: func(ws memdb.WatchSet, state *state.StateStore) error {
-			// Iterate over all the policies
+			// Iterate over all the policies
+			var err error
+			var iter memdb.ResultIterator
+			if prefix := args.QueryOptions.Prefix; prefix != "" {
+				iter, err = state.ACLPolicyByNamePrefix(ws, prefix)
+			} else {
+				iter, err = state.ACLPolicies(ws)
+			}
+			if err != nil {
+				return err
+			}
+
+			// Convert all the policies to a list stub
+			reply.Policies = nil
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				policy := raw.(*structs.ACLPolicy)
+				if _, ok := policies[policy.Name]; ok || mgt {
+					reply.Policies = append(reply.Policies, policy.Stub())
+				}
+			}
+
+			// Use the last index that affected the policy table
+			index, err := state.Index("acl_policy")
+			if err != nil {
+				return err
+			}
+
+			// Ensure we never set the index to zero, otherwise a blocking query cannot be used.
+			// We floor the index at one, since realistically the first write must have a higher index.
+			if index == 0 {
+				index = 1
+			}
+			reply.Index = index
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetPolicy is used to get a specific policy
+func (a *ACL) GetPolicy(args *structs.ACLPolicySpecificRequest, reply *structs.SingleACLPolicyResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetPolicy", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_policy"}, time.Now())
+
+	// Check management level permissions
+	acl, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if acl == nil {
+		return structs.ErrPermissionDenied
+	}
+
+	// If it is not a management token determine if it can get this policy
+	mgt := acl.IsManagement()
+	if !mgt {
+		snap, err := a.srv.fsm.State().Snapshot()
+		if err != nil {
+			return err
+		}
+
+		token, err := snap.ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			return structs.ErrTokenNotFound
+		}
+
+		names, err := tokenPolicyNames(&snap.StateStore, token)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, p := range names {
+			if p == args.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Look for the policy
+			out, err := state.ACLPolicyByName(ws, args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Policy = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the policy table
+				index, err := state.Index("acl_policy")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetPolicies is used to get a set of policies
+func (a *ACL) GetPolicies(args *structs.ACLPolicySetRequest, reply *structs.ACLPolicySetResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetPolicies", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_policies"}, time.Now())
+
+	var token *structs.ACLToken
+	var err error
+	if args.AuthToken == "" {
+		// No need to look up the anonymous token
+		token = structs.AnonymousACLToken
+	} else {
+		// For client typed tokens, allow them to query any policies associated with that token.
+		// This is used by clients which are resolving the policies to enforce. Any associated
+		// policies need to be fetched so that the client can determine what to allow.
+		token, err = a.srv.State().ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+	}
+
+	if token == nil {
+		return structs.ErrTokenNotFound
+	}
+	if token.IsExpired(time.Now()) {
+		return structs.ErrTokenExpired
+	}
+	if token.Type != structs.ACLManagementToken {
+		// Policies granted by the token's roles may also be queried
+		names, err := tokenPolicyNames(a.srv.State(), token)
+		if err != nil {
+			return err
+		}
+		if ok, _ := helper.SliceStringIsSubset(names, args.Names); !ok {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Setup the output
+			reply.Policies = make(map[string]*structs.ACLPolicy, len(args.Names))
+
+			// Look for the policy
+			for _, policyName := range args.Names {
+				out, err := state.ACLPolicyByName(ws, policyName)
+				if err != nil {
+					return err
+				}
+				if out != nil {
+					reply.Policies[policyName] = out
+				}
+			}
+
+			// Use the last index that affected the policy table
+			index, err := state.Index("acl_policy")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// UpsertRoles is used to create or update a set of roles
+func (a *ACL) UpsertRoles(args *structs.ACLRoleUpsertRequest, reply *structs.GenericResponse) error {
+	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	args.Region = a.srv.config.AuthoritativeRegion
+
+	if done, err := a.srv.forward("ACL.UpsertRoles", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "upsert_roles"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of roles
+	if len(args.Roles) == 0 {
+		return fmt.Errorf("must specify as least one role")
+	}
+
+	// Validate each role, compute hash
+	for idx, role := range args.Roles {
+		if err := role.Validate(); err != nil {
+			return fmt.Errorf("role %d invalid: %v", idx, err)
+		}
+		role.SetHash()
+	}
+
+	// Update via Raft
+	_, index, err := a.srv.raftApply(structs.ACLRoleUpsertRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// DeleteRoles is used to delete roles
+func (a *ACL) DeleteRoles(args *structs.ACLRoleDeleteRequest, reply *structs.GenericResponse) error {
+	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	args.Region = a.srv.config.AuthoritativeRegion
+
+	if done, err := a.srv.forward("ACL.DeleteRoles", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "delete_roles"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of roles
+	if len(args.Names) == 0 {
+		return fmt.Errorf("must specify as least one role")
+	}
+
+	// Update via Raft
+	_, index, err := a.srv.raftApply(structs.ACLRoleDeleteRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// ListRoles is used to list the roles
+func (a *ACL) ListRoles(args *structs.ACLRoleListRequest, reply *structs.ACLRoleListResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.ListRoles", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "list_roles"}, time.Now())
+
+	// Check management level permissions
+	acl, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if acl == nil {
+		return structs.ErrPermissionDenied
+	}
+
+	// If it is not a management token determine the roles that may be listed
+	mgt := acl.IsManagement()
+	var roles map[string]struct{}
+	if !mgt {
+		token, err := a.srv.State().ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			return structs.ErrTokenNotFound
+		}
+
+		roles = make(map[string]struct{}, len(token.Roles))
+		for _, r := range token.Roles {
+			roles[r] = struct{}{}
+		}
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Iterate over all the roles
+			var err error
+			var iter memdb.ResultIterator
+			if prefix := args.QueryOptions.Prefix; prefix != "" {
+				iter, err = state.ACLRoleByNamePrefix(ws, prefix)
+			} else {
+				iter, err = state.ACLRoles(ws)
+			}
+			if err != nil {
+				return err
+			}
+
+			// Convert all the roles to a list stub
+			reply.Roles = nil
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				role := raw.(*structs.ACLRole)
+				if _, ok := roles[role.Name]; ok || mgt {
+					reply.Roles = append(reply.Roles, role.Stub())
+				}
+			}
+
+			// Use the last index that affected the role table
+			index, err := state.Index("acl_role")
+			if err != nil {
+				return err
+			}
+
+			// Ensure we never set the index to zero, otherwise a blocking query cannot be used.
+			// We floor the index at one, since realistically the first write must have a higher index.
+			if index == 0 {
+				index = 1
+			}
+			reply.Index = index
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetRole is used to get a specific role
+func (a *ACL) GetRole(args *structs.ACLRoleSpecificRequest, reply *structs.SingleACLRoleResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetRole", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_role"}, time.Now())
+
+	// Check management level permissions
+	acl, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if acl == nil {
+		return structs.ErrPermissionDenied
+	}
+
+	// If it is not a management token determine if it can get this role
+	if !acl.IsManagement() {
+		token, err := a.srv.State().ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			return structs.ErrTokenNotFound
+		}
+		if !token.RoleSubset([]string{args.Name}) {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Look for the role
+			out, err := state.ACLRoleByName(ws, args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Role = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the role table
+				index, err := state.Index("acl_role")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetRoles is used to get a set of roles
+func (a *ACL) GetRoles(args *structs.ACLRoleSetRequest, reply *structs.ACLRoleSetResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetRoles", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_roles"}, time.Now())
+
+	var token *structs.ACLToken
+	var err error
+	if args.AuthToken == "" {
+		// No need to look up the anonymous token
+		token = structs.AnonymousACLToken
+	} else {
+		// For client typed tokens, allow them to query any roles associated with that token.
+		// This is used by clients which are resolving the policies to enforce.
+		token, err = a.srv.State().ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+	}
+
+	if token == nil {
+		return structs.ErrTokenNotFound
+	}
+	if token.IsExpired(time.Now()) {
+		return structs.ErrTokenExpired
+	}
+	if !token.RoleSubset(args.Names) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Setup the output
+			reply.Roles = make(map[string]*structs.ACLRole, len(args.Names))
+
+			// Look for the role
+			for _, roleName := range args.Names {
+				out, err := state.ACLRoleByName(ws, roleName)
+				if err != nil {
+					return err
+				}
+				if out != nil {
+					reply.Roles[roleName] = out
+				}
+			}
+
+			// Use the last index that affected the role table
+			index, err := state.Index("acl_role")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// UpsertAuthMethods is used to create or update a set of auth methods
+func (a *ACL) UpsertAuthMethods(args *structs.ACLAuthMethodUpsertRequest, reply *structs.GenericResponse) error {
+	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	args.Region = a.srv.config.AuthoritativeRegion
+
+	if done, err := a.srv.forward("ACL.UpsertAuthMethods", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "upsert_auth_methods"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of auth methods
+	if len(args.AuthMethods) == 0 {
+		return fmt.Errorf("must specify as least one auth method")
+	}
+
+	// Validate each auth method, compute hash
+	minTTL, maxTTL := a.srv.aclTokenExpirationTTLs()
+	for idx, method := range args.AuthMethods {
+		if err := method.Validate(); err != nil {
+			return fmt.Errorf("auth method %d invalid: %v", idx, err)
+		}
+		if ttl := method.MaxTokenTTL; ttl < minTTL || ttl > maxTTL {
+			return fmt.Errorf("auth method %d invalid: max token TTL must be between %v and %v",
+				idx, minTTL, maxTTL)
+		}
+		if err := auth.ValidatePublicKeys(method.Config.JWTValidationPubKeys); err != nil {
+			return fmt.Errorf("auth method %d invalid: %v", idx, err)
+		}
+		method.SetHash()
+	}
+
+	// Update via Raft
+	_, index, err := a.srv.raftApply(structs.ACLAuthMethodUpsertRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// DeleteAuthMethods is used to delete auth methods along with their binding
+// rules
+func (a *ACL) DeleteAuthMethods(args *structs.ACLAuthMethodDeleteRequest, reply *structs.GenericResponse) error {
+	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	args.Region = a.srv.config.AuthoritativeRegion
+
+	if done, err := a.srv.forward("ACL.DeleteAuthMethods", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "delete_auth_methods"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of auth methods
+	if len(args.Names) == 0 {
+		return fmt.Errorf("must specify as least one auth method")
+	}
+
+	// Update via Raft
+	_, index, err := a.srv.raftApply(structs.ACLAuthMethodDeleteRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// ListAuthMethods is used to list the auth methods. No token is required so
+// that users can discover the auth methods they may log in with.
+func (a *ACL) ListAuthMethods(args *structs.ACLAuthMethodListRequest, reply *structs.ACLAuthMethodListResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.ListAuthMethods", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "list_auth_methods"}, time.Now())
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Iterate over all the auth methods
+			var err error
+			var iter memdb.ResultIterator
+			if prefix := args.QueryOptions.Prefix; prefix != "" {
+				iter, err = state.ACLAuthMethodByNamePrefix(ws, prefix)
+			} else {
+				iter, err = state.ACLAuthMethods(ws)
+			}
+			if err != nil {
+				return err
+			}
+
+			// Convert all the auth methods to a list stub
+			reply.AuthMethods = nil
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				method := raw.(*structs.ACLAuthMethod)
+				reply.AuthMethods = append(reply.AuthMethods, method.Stub())
+			}
+
+			// Use the last index that affected the auth method table
+			index, err := state.Index("acl_auth_method")
+			if err != nil {
+				return err
+			}
+
+			// Ensure we never set the index to zero, otherwise a blocking query cannot be used.
+			// We floor the index at one, since realistically the first write must have a higher index.
+			if index == 0 {
+				index = 1
+			}
+			reply.Index = index
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetAuthMethod is used to get a specific auth method
+func (a *ACL) GetAuthMethod(args *structs.ACLAuthMethodSpecificRequest, reply *structs.SingleACLAuthMethodResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetAuthMethod", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_auth_method"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Look for the auth method
+			out, err := state.ACLAuthMethodByName(ws, args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.AuthMethod = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the auth method table
+				index, err := state.Index("acl_auth_method")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetAuthMethods is used to get a set of auth methods
+func (a *ACL) GetAuthMethods(args *structs.ACLAuthMethodSetRequest, reply *structs.ACLAuthMethodSetResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetAuthMethods", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_auth_methods"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Setup the output
+			reply.AuthMethods = make(map[string]*structs.ACLAuthMethod, len(args.Names))
+
+			// Look for the auth method
+			for _, name := range args.Names {
+				out, err := state.ACLAuthMethodByName(ws, name)
+				if err != nil {
+					return err
+				}
+				if out != nil {
+					reply.AuthMethods[name] = out
+				}
+			}
+
+			// Use the last index that affected the auth method table
+			index, err := state.Index("acl_auth_method")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// UpsertBindingRules is used to create or update a set of binding rules
+func (a *ACL) UpsertBindingRules(args *structs.ACLBindingRuleUpsertRequest, reply *structs.ACLBindingRuleUpsertResponse) error {
+	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	args.Region = a.srv.config.AuthoritativeRegion
+
+	if done, err := a.srv.forward("ACL.UpsertBindingRules", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "upsert_binding_rules"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of binding rules
+	if len(args.Rules) == 0 {
+		return fmt.Errorf("must specify as least one binding rule")
+	}
+
+	// Snapshot the state
+	state, err := a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	// Validate each binding rule, compute hash
+	for idx, rule := range args.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("binding rule %d invalid: %v", idx, err)
+		}
+		if _, err := auth.CompileSelector(rule.Selector); err != nil {
+			return fmt.Errorf("binding rule %d invalid: %v", idx, err)
+		}
+		if err := auth.ValidateBindName(rule.BindName); err != nil {
+			return fmt.Errorf("binding rule %d invalid: %v", idx, err)
+		}
+
+		// Verify the auth method exists
+		method, err := state.ACLAuthMethodByName(nil, rule.AuthMethod)
+		if err != nil {
+			return fmt.Errorf("auth method lookup failed: %v", err)
+		}
+		if method == nil {
+			return fmt.Errorf("binding rule %d invalid: cannot find auth method %s", idx, rule.AuthMethod)
+		}
+
+		// Generate an ID if new, otherwise verify the binding rule exists
+		if rule.ID == "" {
+			rule.ID = uuid.Generate()
+		} else {
+			out, err := state.ACLBindingRuleByID(nil, rule.ID)
+			if err != nil {
+				return fmt.Errorf("binding rule lookup failed: %v", err)
+			}
+			if out == nil {
+				return fmt.Errorf("cannot find binding rule %s", rule.ID)
+			}
+			if out.AuthMethod != rule.AuthMethod {
+				return fmt.Errorf("cannot change auth method of binding rule %s", rule.ID)
+			}
+		}
+		rule.SetHash()
+	}
+
+	// Update via Raft
+	_, index, err := a.srv.raftApply(structs.ACLBindingRuleUpsertRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Populate the response. We do a lookup against the state to
+	// pickup the proper create / modify indexes.
+	state, err = a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	for _, rule := range args.Rules {
+		out, err := state.ACLBindingRuleByID(nil, rule.ID)
+		if err != nil {
+			return fmt.Errorf("binding rule lookup failed: %v", err)
+		}
+		reply.Rules = append(reply.Rules, out)
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// DeleteBindingRules is used to delete binding rules
+func (a *ACL) DeleteBindingRules(args *structs.ACLBindingRuleDeleteRequest, reply *structs.GenericResponse) error {
+	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	args.Region = a.srv.config.AuthoritativeRegion
+
+	if done, err := a.srv.forward("ACL.DeleteBindingRules", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "delete_binding_rules"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of binding rules
+	if len(args.RuleIDs) == 0 {
+		return fmt.Errorf("must specify as least one binding rule")
+	}
+
+	// Update via Raft
+	_, index, err := a.srv.raftApply(structs.ACLBindingRuleDeleteRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// ListBindingRules is used to list the binding rules
+func (a *ACL) ListBindingRules(args *structs.ACLBindingRuleListRequest, reply *structs.ACLBindingRuleListResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.ListBindingRules", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "list_binding_rules"}, time.Now())
+
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Iterate over all the binding rules
 			var err error
 			var iter memdb.ResultIterator
-			if prefix := args.QueryOptions.Prefix; prefix != "" {
-				iter, err = state.ACLPolicyByNamePrefix(ws, prefix)
+			if method := args.AuthMethod; method != "" {
+				iter, err = state.ACLBindingRulesByAuthMethod(ws, method)
 			} else {
-				iter, err = state.ACLPolicies(ws)
+				iter, err = state.ACLBindingRules(ws)
 			}
 			if err != nil {
 				return err
 			}
 
-			// Convert all the policies to a list stub
-			reply.Policies = nil
+			// Convert all the binding rules to a list stub
+			reply.Rules = nil
 			for {
 				raw := iter.Next()
 				if raw == nil {
 					break
 				}
-				policy := raw.(*structs.ACLPolicy)
-				if _, ok := policies[policy.Name]; ok || mgt {
-					reply.Policies = append(reply.Policies, policy.Stub())
-				}
+				rule := raw.(*structs.ACLBindingRule)
+				reply.Rules = append(reply.Rules, rule.Stub())
 			}
 
-			// Use the last index that affected the policy table
-			index, err := state.Index("acl_policy")
+			// Use the last index that affected the binding rule table
+			index, err := state.Index("acl_binding_rule")
 			if err != nil {
 				return err
 			}
@@ -201,71 +1078,41 @@ func (a *ACL) ListPolicies(args *structs.ACLPolicyListRequest, reply *structs.AC
 	return a.srv.blockingRPC(&opts)
 }
 
-// GetPolicy is used to get a specific policy
-func (a *ACL) GetPolicy(args *structs.ACLPolicySpecificRequest, reply *structs.SingleACLPolicyResponse) error {
+// GetBindingRule is used to get a specific binding rule
+func (a *ACL) GetBindingRule(args *structs.ACLBindingRuleSpecificRequest, reply *structs.SingleACLBindingRuleResponse) error {
 	if !a.srv.config.ACLEnabled {
 		return aclDisabled
 	}
-	if done, err := a.srv.forward("ACL.GetPolicy", args, args, reply); done {
+	if done, err := a.srv.forward("ACL.GetBindingRule", args, args, reply); done {
 		return err
 	}
-	defer metrics.MeasureSince([]string{"nomad", "acl", "get_policy"}, time.Now())
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_binding_rule"}, time.Now())
 
 	// Check management level permissions
-	acl, err := a.srv.ResolveToken(args.AuthToken)
-	if err != nil {
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if acl == nil {
+	} else if acl == nil || !acl.IsManagement() {
 		return structs.ErrPermissionDenied
 	}
 
-	// If it is not a management token determine if it can get this policy
-	mgt := acl.IsManagement()
-	if !mgt {
-		snap, err := a.srv.fsm.State().Snapshot()
-		if err != nil {
-			return err
-		}
-
-		token, err := snap.ACLTokenBySecretID(nil, args.AuthToken)
-		if err != nil {
-			return err
-		}
-		if token == nil {
-			return structs.ErrTokenNotFound
-		}
-
-		found := false
-		for _, p := range token.Policies {
-			if p == args.Name {
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			return structs.ErrPermissionDenied
-		}
-	}
-
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
 		queryMeta: &reply.QueryMeta,
 		run: func(ws memdb.WatchSet, state *state.StateStore) error {
-			// Look for the policy
-			out, err := state.ACLPolicyByName(ws, args.Name)
+			// Look for the binding rule
+			out, err := state.ACLBindingRuleByID(ws, args.RuleID)
 			if err != nil {
 				return err
 			}
 
 			// Setup the output
-			reply.Policy = out
+			reply.Rule = out
 			if out != nil {
 				reply.Index = out.ModifyIndex
 			} else {
-				// Use the last index that affected the policy table
-				index, err := state.Index("acl_policy")
+				// Use the last index that affected the binding rule table
+				index, err := state.Index("acl_binding_rule")
 				if err != nil {
 					return err
 				}
@@ -276,35 +1123,20 @@ func (a *ACL) GetPolicy(args *structs.ACLPolicySpecificRequest, reply *structs.S
 	return a.srv.blockingRPC(&opts)
 }
 
-// GetPolicies is used to get a set of policies
-func (a *ACL) GetPolicies(args *structs.ACLPolicySetRequest, reply *structs.ACLPolicySetResponse) error {
+// GetBindingRules is used to get a set of binding rules
+func (a *ACL) GetBindingRules(args *structs.ACLBindingRuleSetRequest, reply *structs.ACLBindingRuleSetResponse) error {
 	if !a.srv.config.ACLEnabled {
 		return aclDisabled
 	}
-	if done, err := a.srv.forward("ACL.GetPolicies", args, args, reply); done {
+	if done, err := a.srv.forward("ACL.GetBindingRules", args, args, reply); done {
 		return err
 	}
-	defer metrics.MeasureSince([]string{"nomad", "acl", "get_policies"}, time.Now())
-
-	var token *structs.ACLToken
-	var err error
-	if args.AuthToken == "" {
-		// No need to look up the anonymous token
-		token = structs.AnonymousACLToken
-	} else {
-		// For client typed tokens, allow them to query any policies associated with that token.
-		// This is used by clients which are resolving the policies to enforce. Any associated
-		// policies need to be fetched so that the client can determine what to allow.
-		token, err = a.srv.State().ACLTokenBySecretID(nil, args.AuthToken)
-		if err != nil {
-			return err
-		}
-	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_binding_rules"}, time.Now())
 
-	if token == nil {
-		return structs.ErrTokenNotFound
-	}
-	if token.Type != structs.ACLManagementToken && !token.PolicySubset(args.Names) {
+	// Check management level permissions
+	if acl, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
 		return structs.ErrPermissionDenied
 	}
 
@@ -314,21 +1146,21 @@ func (a *ACL) GetPolicies(args *structs.ACLPolicySetRequest, reply *structs.ACLP
 		queryMeta: &reply.QueryMeta,
 		run: func(ws memdb.WatchSet, state *state.StateStore) error {
 			// Setup the output
-			reply.Policies = make(map[string]*structs.ACLPolicy, len(args.Names))
+			reply.Rules = make(map[string]*structs.ACLBindingRule, len(args.RuleIDs))
 
-			// Look for the policy
-			for _, policyName := range args.Names {
-				out, err := state.ACLPolicyByName(ws, policyName)
+			// Look for the binding rule
+			for _, id := range args.RuleIDs {
+				out, err := state.ACLBindingRuleByID(ws, id)
 				if err != nil {
 					return err
 				}
 				if out != nil {
-					reply.Policies[policyName] = out
+					reply.Rules[id] = out
 				}
 			}
 
-			// Use the last index that affected the policy table
-			index, err := state.Index("acl_policy")
+			// Use the last index that affected the binding rule table
+			index, err := state.Index("acl_binding_rule")
 			if err != nil {
 				return err
 			}
@@ -338,6 +1170,160 @@ func (a *ACL) GetPolicies(args *structs.ACLPolicySetRequest, reply *structs.ACLP
 	return a.srv.blockingRPC(&opts)
 }
 
+// Login is used to exchange a JWT issued by the identity provider of an auth
+// method for an ACL token. The token is granted the policies and roles of the
+// auth method's binding rules that match the claims of the JWT.
+func (a *ACL) Login(args *structs.ACLLoginRequest, reply *structs.ACLLoginResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if args.AuthMethod == "" {
+		return fmt.Errorf("missing auth method")
+	}
+	if args.LoginToken == "" {
+		return fmt.Errorf("missing login token")
+	}
+
+	// Global tokens must be created in the authoritative region. Auth methods
+	// are replicated so they can be looked up in any region.
+	method, err := a.srv.State().ACLAuthMethodByName(nil, args.AuthMethod)
+	if err != nil {
+		return err
+	}
+	if method == nil {
+		return fmt.Errorf("auth method %q not found", args.AuthMethod)
+	}
+	if method.TokenGlobal() {
+		args.Region = a.srv.config.AuthoritativeRegion
+	}
+
+	if done, err := a.srv.forward("ACL.Login", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "login"}, time.Now())
+
+	// Snapshot the state so the auth method and its binding rules are
+	// consistent
+	state, err := a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	method, err = state.ACLAuthMethodByName(nil, args.AuthMethod)
+	if err != nil {
+		return err
+	}
+	if method == nil {
+		return fmt.Errorf("auth method %q not found", args.AuthMethod)
+	}
+
+	// Validate the login token
+	validator, err := a.srv.aclAuthValidators.Get(method)
+	if err != nil {
+		return fmt.Errorf("auth method %q invalid: %v", method.Name, err)
+	}
+	identity, err := validator.Validate(context.Background(), args.LoginToken)
+	if err != nil {
+		a.logger.Debug("login token rejected", "auth_method", method.Name, "error", err)
+		return structs.ErrPermissionDenied
+	}
+
+	// Evaluate the binding rules
+	policies, roles, err := a.evaluateBindingRules(state, method, identity)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 && len(roles) == 0 {
+		a.logger.Debug("login token matched no binding rules", "auth_method", method.Name)
+		return structs.ErrPermissionDenied
+	}
+
+	// Create the token
+	now := time.Now().UTC()
+	expiration := now.Add(method.MaxTokenTTL)
+	token := &structs.ACLToken{
+		AccessorID:     uuid.Generate(),
+		SecretID:       uuid.Generate(),
+		Name:           fmt.Sprintf("login via %s", method.Name),
+		Type:           structs.ACLClientToken,
+		Policies:       policies,
+		Roles:          roles,
+		Global:         method.TokenGlobal(),
+		AuthMethod:     method.Name,
+		CreateTime:     now,
+		ExpirationTTL:  method.MaxTokenTTL,
+		ExpirationTime: &expiration,
+	}
+	token.SetHash()
+
+	// Update via Raft
+	req := &structs.ACLTokenUpsertRequest{Tokens: []*structs.ACLToken{token}}
+	_, index, err := a.srv.raftApply(structs.ACLTokenUpsertRequestType, req)
+	if err != nil {
+		return err
+	}
+
+	// Lookup the token to pickup the proper create / modify indexes
+	out, err := a.srv.State().ACLTokenByAccessorID(nil, token.AccessorID)
+	if err != nil {
+		return fmt.Errorf("token lookup failed: %v", err)
+	}
+	reply.Token = out
+	reply.Index = index
+	return nil
+}
+
+// evaluateBindingRules returns the sorted policies and roles granted to the
+// identity by the binding rules of the auth method.
+func (a *ACL) evaluateBindingRules(state *state.StateSnapshot, method *structs.ACLAuthMethod,
+	identity *auth.Identity) ([]string, []string, error) {
+
+	iter, err := state.ACLBindingRulesByAuthMethod(nil, method.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policies := make(map[string]struct{})
+	roles := make(map[string]struct{})
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		rule := raw.(*structs.ACLBindingRule)
+		selector, err := auth.CompileSelector(rule.Selector)
+		if err != nil {
+			a.logger.Warn("skipping binding rule with invalid selector", "binding_rule", rule.ID, "error", err)
+			continue
+		}
+		if !selector.Matches(identity) {
+			continue
+		}
+
+		name, err := identity.InterpolateBindName(rule.BindName)
+		if err != nil || !structs.ValidACLBindName(name) {
+			a.logger.Debug("skipping binding rule with invalid bind name", "binding_rule", rule.ID, "bind_name", name, "error", err)
+			continue
+		}
+		switch rule.BindType {
+		case structs.ACLBindingRuleBindTypePolicy:
+			policies[name] = struct{}{}
+		case structs.ACLBindingRuleBindTypeRole:
+			roles[name] = struct{}{}
+		}
+	}
+
+	return sortedSet(policies), sortedSet(roles), nil
+}
+
+// sortedSet returns the members of the set in sorted order
+func sortedSet(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
 // Bootstrap is used to bootstrap the initial token
 func (a *ACL) Bootstrap(args *structs.ACLTokenBootstrapRequest, reply *structs.ACLTokenUpsertResponse) error {
 	// Ensure ACLs are enabled, and always flow modification requests to the authoritative region
@@ -499,10 +1485,26 @@ func (a *ACL) UpsertTokens(args *structs.ACLTokenUpsertRequest, reply *structs.A
 
 		// Generate an accessor and secret ID if new
 		if token.AccessorID == "" {
+			if token.AuthMethod != "" {
+				return fmt.Errorf("token %d invalid: auth method tokens must be created by logging in", idx)
+			}
 			token.AccessorID = uuid.Generate()
 			token.SecretID = uuid.Generate()
 			token.CreateTime = time.Now().UTC()
 
+			// Set the expiration time from the TTL
+			if token.ExpirationTime != nil {
+				return fmt.Errorf("token %d invalid: expiration must be set using a TTL", idx)
+			}
+			if ttl := token.ExpirationTTL; ttl != 0 {
+				if minTTL, maxTTL := a.srv.aclTokenExpirationTTLs(); ttl < minTTL || ttl > maxTTL {
+					return fmt.Errorf("token %d invalid: expiration TTL must be between %v and %v",
+						idx, minTTL, maxTTL)
+				}
+				expiration := token.CreateTime.Add(ttl)
+				token.ExpirationTime = &expiration
+			}
+
 		} else {
 			// Verify the token exists
 			out, err := state.ACLTokenByAccessorID(nil, token.AccessorID)
@@ -517,6 +1519,15 @@ func (a *ACL) UpsertTokens(args *structs.ACLTokenUpsertRequest, reply *structs.A
 			if token.Global != out.Global {
 				return fmt.Errorf("cannot toggle global mode of %s", token.AccessorID)
 			}
+
+			// Cannot change the expiration of an existing token
+			if (token.ExpirationTTL != 0 && token.ExpirationTTL != out.ExpirationTTL) ||
+				(token.ExpirationTime != nil && (out.ExpirationTime == nil || !token.ExpirationTime.Equal(*out.ExpirationTime))) {
+				return fmt.Errorf("cannot change expiration of %s", token.AccessorID)
+			}
+			token.ExpirationTTL = out.ExpirationTTL
+			token.ExpirationTime = out.ExpirationTime
+			token.AuthMethod = out.AuthMethod
 		}
 
 		// Compute the token hash
@@ -794,6 +1805,39 @@ func (a *ACL) GetTokens(args *structs.ACLTokenSetRequest, reply *structs.ACLToke
 	return a.srv.blockingRPC(&opts)
 }
 
+// GetTokenCapabilities is used to get the capabilities granted to the token of
+// the request by all of its policies, so that callers can check their
+// permissions before making requests.
+func (a *ACL) GetTokenCapabilities(args *structs.ACLTokenCapabilitiesRequest, reply *structs.ACLTokenCapabilitiesResponse) error {
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+	if done, err := a.srv.forward("ACL.GetTokenCapabilities", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_token_capabilities"}, time.Now())
+
+	acl, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	}
+	reply.Capabilities = acl.Capabilities()
+
+	// Use the last index that affected the tokens or policies
+	state := a.srv.fsm.State()
+	tindex, err := state.Index("acl_token")
+	if err != nil {
+		return err
+	}
+	pindex, err := state.Index("acl_policy")
+	if err != nil {
+		return err
+	}
+	reply.Index = helper.Uint64Max(tindex, pindex)
+	a.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
 // ResolveToken is used to lookup a specific token by a secret ID. This is used for enforcing ACLs by clients.
 func (a *ACL) ResolveToken(args *structs.ResolveACLTokenRequest, reply *structs.ResolveACLTokenResponse) error {
 	if !a.srv.config.ACLEnabled {
@@ -1,6 +1,14 @@
 package nomad
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -10,11 +18,13 @@ import (
 	"time"
 
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestACLEndpoint_GetPolicy(t *testing.T) {
@@ -230,6 +240,49 @@ func TestACLEndpoint_GetPolicies_TokenSubset(t *testing.T) {
 	}
 }
 
+func TestACLEndpoint_GetPolicies_RoleSubset(t *testing.T) {
+	t.Parallel()
+	s1, _ := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create a role granting the first policy
+	policy := mock.ACLPolicy()
+	policy2 := mock.ACLPolicy()
+	s1.fsm.State().UpsertACLPolicies(1000, []*structs.ACLPolicy{policy, policy2})
+
+	role := mock.ACLRole()
+	role.Policies = []string{policy.Name}
+	s1.fsm.State().UpsertACLRoles(1000, []*structs.ACLRole{role})
+
+	token := mock.ACLToken()
+	token.Policies = nil
+	token.Roles = []string{role.Name}
+	s1.fsm.State().UpsertACLTokens(1000, []*structs.ACLToken{token})
+
+	// Lookup the policy granted through the role
+	get := &structs.ACLPolicySetRequest{
+		Names: []string{policy.Name},
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp structs.ACLPolicySetResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.GetPolicies", get, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, policy, resp.Policies[policy.Name])
+
+	// Lookup non-associated policy
+	get.Names = []string{policy2.Name}
+	resp = structs.ACLPolicySetResponse{}
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.GetPolicies", get, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
 func TestACLEndpoint_GetPolicies_Blocking(t *testing.T) {
 	t.Parallel()
 	s1, root := TestACLServer(t, nil)
@@ -519,6 +572,434 @@ func TestACLEndpoint_UpsertPolicies_Invalid(t *testing.T) {
 	}
 }
 
+func TestACLEndpoint_UpsertDeleteRoles(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	r1 := mock.ACLRole()
+	req := &structs.ACLRoleUpsertRequest{
+		Roles: []*structs.ACLRole{r1},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+
+	// Writes require a management token
+	token := mock.ACLToken()
+	s1.fsm.State().UpsertACLTokens(1000, []*structs.ACLToken{token})
+	req.AuthToken = token.SecretID
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertRoles", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.UpsertRoles", req, &resp))
+	require.NotZero(resp.Index)
+
+	out, err := s1.fsm.State().ACLRoleByName(nil, r1.Name)
+	require.NoError(err)
+	require.NotNil(out)
+
+	// Invalid roles are rejected
+	r2 := mock.ACLRole()
+	r2.Policies = nil
+	req.Roles = []*structs.ACLRole{r2}
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertRoles", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "missing policies")
+
+	// Delete the role
+	del := &structs.ACLRoleDeleteRequest{
+		Names: []string{r1.Name},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.DeleteRoles", del, &resp))
+
+	out, err = s1.fsm.State().ACLRoleByName(nil, r1.Name)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestACLEndpoint_GetListRoles(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	r1 := mock.ACLRole()
+	r2 := mock.ACLRole()
+	s1.fsm.State().UpsertACLRoles(1000, []*structs.ACLRole{r1, r2})
+
+	token := mock.ACLToken()
+	token.Roles = []string{r1.Name}
+	s1.fsm.State().UpsertACLTokens(1001, []*structs.ACLToken{token})
+
+	// A management token sees every role
+	list := &structs.ACLRoleListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var listResp structs.ACLRoleListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.ListRoles", list, &listResp))
+	require.Len(listResp.Roles, 2)
+	require.EqualValues(1000, listResp.Index)
+
+	// A client token only sees its own roles
+	list.AuthToken = token.SecretID
+	listResp = structs.ACLRoleListResponse{}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.ListRoles", list, &listResp))
+	require.Len(listResp.Roles, 1)
+	require.Equal(r1.Name, listResp.Roles[0].Name)
+
+	// Lookup a single role
+	get := &structs.ACLRoleSpecificRequest{
+		Name: r1.Name,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var getResp structs.SingleACLRoleResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetRole", get, &getResp))
+	require.Equal(r1, getResp.Role)
+
+	// Lookup a set of roles, including one not associated with the token
+	set := &structs.ACLRoleSetRequest{
+		Names: []string{r1.Name},
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var setResp structs.ACLRoleSetResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetRoles", set, &setResp))
+	require.Equal(r1, setResp.Roles[r1.Name])
+
+	set.Names = []string{r2.Name}
+	err := msgpackrpc.CallWithCodec(codec, "ACL.GetRoles", set, &setResp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+}
+
+func TestACLEndpoint_UpsertDeleteAuthMethods(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	m1 := mock.ACLAuthMethod()
+	req := &structs.ACLAuthMethodUpsertRequest{
+		AuthMethods: []*structs.ACLAuthMethod{m1},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+
+	// Writes require a management token
+	token := mock.ACLToken()
+	s1.fsm.State().UpsertACLTokens(1000, []*structs.ACLToken{token})
+	req.AuthToken = token.SecretID
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertAuthMethods", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.UpsertAuthMethods", req, &resp))
+	require.NotZero(resp.Index)
+
+	out, err := s1.fsm.State().ACLAuthMethodByName(nil, m1.Name)
+	require.NoError(err)
+	require.NotNil(out)
+
+	// The max token TTL must be within the configured bounds
+	m2 := mock.ACLAuthMethod()
+	m2.MaxTokenTTL = 365 * 24 * time.Hour
+	req.AuthMethods = []*structs.ACLAuthMethod{m2}
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertAuthMethods", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "max token TTL")
+
+	// Public keys must be parseable
+	m2 = mock.ACLAuthMethod()
+	m2.Config.JWKSURL = ""
+	m2.Config.JWTValidationPubKeys = []string{"not a key"}
+	req.AuthMethods = []*structs.ACLAuthMethod{m2}
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertAuthMethods", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "public key 0 invalid")
+
+	// Add a binding rule and delete the auth method
+	rule := mock.ACLBindingRule()
+	rule.AuthMethod = m1.Name
+	s1.fsm.State().UpsertACLBindingRules(1001, []*structs.ACLBindingRule{rule})
+
+	del := &structs.ACLAuthMethodDeleteRequest{
+		Names: []string{m1.Name},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.DeleteAuthMethods", del, &resp))
+
+	out, err = s1.fsm.State().ACLAuthMethodByName(nil, m1.Name)
+	require.NoError(err)
+	require.Nil(out)
+
+	outRule, err := s1.fsm.State().ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(err)
+	require.Nil(outRule)
+}
+
+func TestACLEndpoint_GetListAuthMethods(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	m1 := mock.ACLAuthMethod()
+	m2 := mock.ACLAuthMethod()
+	s1.fsm.State().UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{m1, m2})
+
+	// Listing does not require a token
+	list := &structs.ACLAuthMethodListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+	var listResp structs.ACLAuthMethodListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.ListAuthMethods", list, &listResp))
+	require.Len(listResp.AuthMethods, 2)
+	require.EqualValues(1000, listResp.Index)
+
+	// Reading the configuration requires a management token
+	get := &structs.ACLAuthMethodSpecificRequest{
+		Name: m1.Name,
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+	var getResp structs.SingleACLAuthMethodResponse
+	err := msgpackrpc.CallWithCodec(codec, "ACL.GetAuthMethod", get, &getResp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	get.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetAuthMethod", get, &getResp))
+	require.Equal(m1, getResp.AuthMethod)
+
+	set := &structs.ACLAuthMethodSetRequest{
+		Names: []string{m1.Name, m2.Name},
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var setResp structs.ACLAuthMethodSetResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetAuthMethods", set, &setResp))
+	require.Len(setResp.AuthMethods, 2)
+	require.Equal(m2, setResp.AuthMethods[m2.Name])
+}
+
+func TestACLEndpoint_BindingRules(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	method := mock.ACLAuthMethod()
+	s1.fsm.State().UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method})
+
+	// Create a binding rule, the ID is generated by the server
+	rule := mock.ACLBindingRule()
+	rule.ID = ""
+	rule.AuthMethod = method.Name
+	req := &structs.ACLBindingRuleUpsertRequest{
+		Rules: []*structs.ACLBindingRule{rule},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var resp structs.ACLBindingRuleUpsertResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.UpsertBindingRules", req, &resp))
+	require.Len(resp.Rules, 1)
+	created := resp.Rules[0]
+	require.NotEmpty(created.ID)
+	require.Equal(resp.Index, created.CreateIndex)
+
+	// Binding rules must reference an existing auth method
+	bad := mock.ACLBindingRule()
+	bad.ID = ""
+	bad.AuthMethod = "missing"
+	req.Rules = []*structs.ACLBindingRule{bad}
+	err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertBindingRules", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "cannot find auth method")
+
+	// Selectors are validated
+	bad.AuthMethod = method.Name
+	bad.Selector = "value.project = web"
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertBindingRules", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "invalid selector")
+
+	// List and get the binding rule
+	list := &structs.ACLBindingRuleListRequest{
+		AuthMethod: method.Name,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var listResp structs.ACLBindingRuleListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.ListBindingRules", list, &listResp))
+	require.Len(listResp.Rules, 1)
+	require.Equal(created.ID, listResp.Rules[0].ID)
+
+	list.AuthMethod = "other"
+	listResp = structs.ACLBindingRuleListResponse{}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.ListBindingRules", list, &listResp))
+	require.Empty(listResp.Rules)
+
+	get := &structs.ACLBindingRuleSpecificRequest{
+		RuleID: created.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var getResp structs.SingleACLBindingRuleResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetBindingRule", get, &getResp))
+	require.Equal(created, getResp.Rule)
+
+	// Delete the binding rule
+	del := &structs.ACLBindingRuleDeleteRequest{
+		RuleIDs: []string{created.ID},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var delResp structs.GenericResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.DeleteBindingRules", del, &delResp))
+
+	out, err := s1.fsm.State().ACLBindingRuleByID(nil, created.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+// signTestJWT returns a RS256 signed JWT with the given claims
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestACLEndpoint_Login(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, _ := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(err)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	// Create an auth method that maps the project and groups claims
+	method := mock.ACLAuthMethod()
+	method.Config.JWKSURL = ""
+	method.Config.JWTValidationPubKeys = []string{string(pubPEM)}
+	method.Config.ListClaimMappings = map[string]string{"groups": "groups"}
+	method.SetHash()
+	s1.fsm.State().UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method})
+
+	policyRule := mock.ACLBindingRule()
+	policyRule.AuthMethod = method.Name
+	roleRule := mock.ACLBindingRule()
+	roleRule.AuthMethod = method.Name
+	roleRule.Selector = `"admins" in list.groups`
+	roleRule.BindType = structs.ACLBindingRuleBindTypeRole
+	roleRule.BindName = "admin"
+	s1.fsm.State().UpsertACLBindingRules(1001, []*structs.ACLBindingRule{policyRule, roleRule})
+
+	claims := map[string]interface{}{
+		"aud":     "nomad",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"project": "web",
+		"groups":  []string{"admins", "devs"},
+	}
+	req := &structs.ACLLoginRequest{
+		AuthMethod: method.Name,
+		LoginToken: signTestJWT(t, key, claims),
+		WriteRequest: structs.WriteRequest{
+			Region: "global",
+		},
+	}
+	var resp structs.ACLLoginResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.Login", req, &resp))
+	require.NotNil(resp.Token)
+	require.Equal(structs.ACLClientToken, resp.Token.Type)
+	require.Equal([]string{"web-web"}, resp.Token.Policies)
+	require.Equal([]string{"admin"}, resp.Token.Roles)
+	require.Equal(method.Name, resp.Token.AuthMethod)
+	require.False(resp.Token.Global)
+	require.Equal(method.MaxTokenTTL, resp.Token.ExpirationTTL)
+	require.NotNil(resp.Token.ExpirationTime)
+
+	out, err := s1.fsm.State().ACLTokenBySecretID(nil, resp.Token.SecretID)
+	require.NoError(err)
+	require.Equal(resp.Token, out)
+
+	// Tokens that match no binding rules are rejected
+	claims["project"] = "api"
+	claims["groups"] = []string{"devs"}
+	req.LoginToken = signTestJWT(t, key, claims)
+	err = msgpackrpc.CallWithCodec(codec, "ACL.Login", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Tokens for another audience are rejected
+	claims["project"] = "web"
+	claims["aud"] = "vault"
+	req.LoginToken = signTestJWT(t, key, claims)
+	err = msgpackrpc.CallWithCodec(codec, "ACL.Login", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Unknown auth methods are rejected
+	req.AuthMethod = "missing"
+	err = msgpackrpc.CallWithCodec(codec, "ACL.Login", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "not found")
+}
+
 func TestACLEndpoint_GetToken(t *testing.T) {
 	t.Parallel()
 	s1, root := TestACLServer(t, nil)
@@ -1116,6 +1597,61 @@ func TestACLEndpoint_UpsertTokens_Invalid(t *testing.T) {
 	}
 }
 
+func TestACLEndpoint_UpsertTokens_Expiration(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	p1 := mock.ACLToken()
+	p1.AccessorID = ""
+	p1.ExpirationTTL = time.Hour
+	req := &structs.ACLTokenUpsertRequest{
+		Tokens: []*structs.ACLToken{p1},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var resp structs.ACLTokenUpsertResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp))
+
+	created := resp.Tokens[0]
+	require.NotNil(created.ExpirationTime)
+	require.True(created.CreateTime.Add(time.Hour).Equal(*created.ExpirationTime))
+
+	// The expiration can't be changed once set
+	update := *created
+	update.ExpirationTTL = 2 * time.Hour
+	req.Tokens = []*structs.ACLToken{&update}
+	err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "cannot change expiration")
+
+	// TTLs outside the configured bounds are rejected
+	for _, ttl := range []time.Duration{time.Second, 365 * 24 * time.Hour} {
+		p2 := mock.ACLToken()
+		p2.AccessorID = ""
+		p2.ExpirationTTL = ttl
+		req.Tokens = []*structs.ACLToken{p2}
+		err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp)
+		require.Error(err)
+		require.Contains(err.Error(), "expiration TTL must be between")
+	}
+
+	// Expiration times can't be set directly
+	p3 := mock.ACLToken()
+	p3.AccessorID = ""
+	expiry := time.Now().Add(time.Hour)
+	p3.ExpirationTime = &expiry
+	req.Tokens = []*structs.ACLToken{p3}
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "must be set using a TTL")
+}
+
 func TestACLEndpoint_ResolveToken(t *testing.T) {
 	t.Parallel()
 	s1, _ := TestACLServer(t, nil)
@@ -1147,3 +1683,40 @@ func TestACLEndpoint_ResolveToken(t *testing.T) {
 	assert.Equal(t, uint64(1000), resp.Index)
 	assert.Nil(t, resp.Token)
 }
+
+func TestACLEndpoint_GetTokenCapabilities(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	token := mock.CreatePolicyAndToken(t, s1.fsm.State(), 1000, "test",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+
+	// Get the capabilities of the token
+	get := &structs.ACLTokenCapabilitiesRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp structs.ACLTokenCapabilitiesResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetTokenCapabilities", get, &resp))
+	require.Equal(uint64(1001), resp.Index)
+	require.False(resp.Capabilities.Management)
+	require.Contains(resp.Capabilities.Namespaces, structs.DefaultNamespace)
+	require.Equal([]string{acl.NamespaceCapabilityReadJob},
+		resp.Capabilities.Namespaces[structs.DefaultNamespace].Capabilities)
+
+	// Management tokens may do anything
+	get.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ACL.GetTokenCapabilities", get, &resp))
+	require.True(resp.Capabilities.Management)
+
+	// Unknown tokens are rejected
+	get.AuthToken = uuid.Generate()
+	err := msgpackrpc.CallWithCodec(codec, "ACL.GetTokenCapabilities", get, &resp)
+	require.EqualError(err, structs.ErrTokenNotFound.Error())
+}
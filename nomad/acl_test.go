@@ -2,6 +2,7 @@ package nomad
 
 import (
 	"testing"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/hashicorp/nomad/acl"
@@ -11,6 +12,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolveACLToken(t *testing.T) {
@@ -92,6 +94,45 @@ func TestResolveACLToken(t *testing.T) {
 	}
 }
 
+func TestResolveACLToken_Roles(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	state := state.TestStateStore(t)
+	cache, err := lru.New2Q(16)
+	require.NoError(err)
+
+	// Create a token that is granted its policy through a role
+	policy := mock.ACLPolicy()
+	role := mock.ACLRole()
+	role.Policies = []string{policy.Name}
+	token := mock.ACLToken()
+	token.Policies = nil
+	token.Roles = []string{role.Name}
+	require.NoError(state.UpsertACLPolicies(100, []*structs.ACLPolicy{policy}))
+	require.NoError(state.UpsertACLRoles(105, []*structs.ACLRole{role}))
+	require.NoError(state.UpsertACLTokens(110, []*structs.ACLToken{token}))
+
+	snap, err := state.Snapshot()
+	require.NoError(err)
+
+	aclObj, err := resolveTokenFromSnapshotCache(snap, cache, token.SecretID)
+	require.NoError(err)
+	require.True(aclObj.AllowNamespaceOperation("default", acl.NamespaceCapabilityListJobs))
+
+	// Expired tokens are rejected
+	expired := mock.ACLToken()
+	expiry := time.Now().Add(-time.Minute)
+	expired.ExpirationTime = &expiry
+	require.NoError(state.UpsertACLTokens(120, []*structs.ACLToken{expired}))
+	snap, err = state.Snapshot()
+	require.NoError(err)
+
+	aclObj, err = resolveTokenFromSnapshotCache(snap, cache, expired.SecretID)
+	require.Equal(structs.ErrTokenExpired, err)
+	require.Nil(aclObj)
+}
+
 func TestResolveACLToken_LeaderToken(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -10,6 +10,8 @@ import (
 	multierror "github.com/hashicorp/go-multierror"
 
 	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -28,9 +30,10 @@ func (a *Alloc) List(args *structs.AllocListRequest, reply *structs.AllocListRes
 	defer metrics.MeasureSince([]string{"nomad", "alloc", "list"}, time.Now())
 
 	// Check namespace read-job permissions
-	if aclObj, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -44,6 +47,8 @@ func (a *Alloc) List(args *structs.AllocListRequest, reply *structs.AllocListRes
 			var iter memdb.ResultIterator
 			if prefix := args.QueryOptions.Prefix; prefix != "" {
 				iter, err = state.AllocsByIDPrefix(ws, args.RequestNamespace(), prefix)
+			} else if args.ClientStatus != "" {
+				iter, err = state.AllocsByNamespaceStatus(ws, args.RequestNamespace(), args.ClientStatus)
 			} else {
 				iter, err = state.AllocsByNamespace(ws, args.RequestNamespace())
 			}
@@ -58,7 +63,13 @@ func (a *Alloc) List(args *structs.AllocListRequest, reply *structs.AllocListRes
 					break
 				}
 				alloc := raw.(*structs.Allocation)
-				allocs = append(allocs, alloc.Stub())
+				if args.ClientStatus != "" && alloc.ClientStatus != args.ClientStatus {
+					continue
+				}
+				if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadJob) {
+					continue
+				}
+				allocs = append(allocs, alloc.Stub(args.Fields))
 			}
 			reply.Allocations = allocs
 
@@ -85,7 +96,8 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 	defer metrics.MeasureSince([]string{"nomad", "alloc", "get_alloc"}, time.Now())
 
 	// Check namespace read-job permissions
-	if aclObj, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		// If ResolveToken had an unexpected error return that
 		if err != structs.ErrTokenNotFound {
 			return err
@@ -105,7 +117,7 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 		if node == nil {
 			return structs.ErrTokenNotFound
 		}
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -120,6 +132,11 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 				return err
 			}
 
+			// Check the read-job permissions of the allocation's job
+			if out != nil && aclObj != nil && !aclObj.AllowJobOp(out.Namespace, out.JobID, acl.NamespaceCapabilityReadJob) {
+				return structs.ErrPermissionDenied
+			}
+
 			// Setup the output
 			reply.Alloc = out
 			if out != nil {
@@ -236,3 +253,153 @@ func (a *Alloc) UpdateDesiredTransition(args *structs.AllocUpdateDesiredTransiti
 	reply.Index = index
 	return nil
 }
+
+// Stop is used to stop an allocation and reschedule it elsewhere, by marking
+// it for migration and creating an evaluation of its job.
+func (a *Alloc) Stop(args *structs.AllocStopRequest, reply *structs.AllocStopResponse) error {
+	if done, err := a.srv.forward("Alloc.Stop", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "stop"}, time.Now())
+
+	// Check namespace submit-job permissions
+	aclObj, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.AllocID == "" {
+		return fmt.Errorf("missing AllocID")
+	}
+
+	// Lookup the allocation. Allocations outside of the namespace of the
+	// request are treated as unknown.
+	alloc, err := a.srv.State().AllocByID(nil, args.AllocID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil || alloc.Namespace != args.RequestNamespace() {
+		return structs.NewErrUnknownAllocation(args.AllocID)
+	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+	if alloc.Job == nil {
+		return fmt.Errorf("allocation %q has no job", args.AllocID)
+	}
+
+	eval := &structs.Evaluation{
+		ID:             uuid.Generate(),
+		Namespace:      alloc.Namespace,
+		Priority:       alloc.Job.Priority,
+		Type:           alloc.Job.Type,
+		TriggeredBy:    structs.EvalTriggerAllocStop,
+		JobID:          alloc.Job.ID,
+		JobModifyIndex: alloc.Job.ModifyIndex,
+		Status:         structs.EvalStatusPending,
+	}
+
+	req := &structs.AllocUpdateDesiredTransitionRequest{
+		Allocs: map[string]*structs.DesiredTransition{
+			alloc.ID: {Migrate: helper.BoolToPtr(true)},
+		},
+		Evals:        []*structs.Evaluation{eval},
+		WriteRequest: args.WriteRequest,
+	}
+
+	// Commit this update via Raft
+	_, index, err := a.srv.raftApply(structs.AllocUpdateDesiredTransitionRequestType, req)
+	if err != nil {
+		a.logger.Error("AllocUpdateDesiredTransitionRequest failed", "error", err)
+		return err
+	}
+
+	// Setup the response
+	reply.EvalID = eval.ID
+	reply.Index = index
+	return nil
+}
+
+// Reschedule is used to reschedule a failed allocation immediately, instead
+// of waiting for the delay of its reschedule policy, by marking it for forced
+// rescheduling and creating an evaluation of its job.
+func (a *Alloc) Reschedule(args *structs.AllocRescheduleRequest, reply *structs.AllocRescheduleResponse) error {
+	if done, err := a.srv.forward("Alloc.Reschedule", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "reschedule"}, time.Now())
+
+	// Check namespace submit-job permissions
+	aclObj, err := a.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.AllocID == "" {
+		return fmt.Errorf("missing AllocID")
+	}
+
+	// Lookup the allocation. Allocations outside of the namespace of the
+	// request are treated as unknown.
+	alloc, err := a.srv.State().AllocByID(nil, args.AllocID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil || alloc.Namespace != args.RequestNamespace() {
+		return structs.NewErrUnknownAllocation(args.AllocID)
+	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+	if alloc.Job == nil {
+		return fmt.Errorf("allocation %q has no job", args.AllocID)
+	}
+
+	// Only failed allocations that haven't been replaced yet and whose task
+	// group allows rescheduling can be rescheduled
+	switch {
+	case alloc.ClientStatus != structs.AllocClientStatusFailed:
+		return fmt.Errorf("allocation %q is not failed", args.AllocID)
+	case alloc.DesiredStatus == structs.AllocDesiredStatusStop:
+		return fmt.Errorf("allocation %q is stopped", args.AllocID)
+	case alloc.NextAllocation != "":
+		return fmt.Errorf("allocation %q has already been rescheduled", args.AllocID)
+	case !alloc.ReschedulePolicy().Enabled():
+		return fmt.Errorf("rescheduling is disabled for task group %q", alloc.TaskGroup)
+	}
+
+	eval := &structs.Evaluation{
+		ID:             uuid.Generate(),
+		Namespace:      alloc.Namespace,
+		Priority:       alloc.Job.Priority,
+		Type:           alloc.Job.Type,
+		TriggeredBy:    structs.EvalTriggerAllocReschedule,
+		JobID:          alloc.Job.ID,
+		JobModifyIndex: alloc.Job.ModifyIndex,
+		Status:         structs.EvalStatusPending,
+	}
+
+	req := &structs.AllocUpdateDesiredTransitionRequest{
+		Allocs: map[string]*structs.DesiredTransition{
+			alloc.ID: {ForceReschedule: helper.BoolToPtr(true)},
+		},
+		Evals:        []*structs.Evaluation{eval},
+		WriteRequest: args.WriteRequest,
+	}
+
+	// Commit this update via Raft
+	_, index, err := a.srv.raftApply(structs.AllocUpdateDesiredTransitionRequestType, req)
+	if err != nil {
+		a.logger.Error("AllocUpdateDesiredTransitionRequest failed", "error", err)
+		return err
+	}
+
+	// Setup the response
+	reply.EvalID = eval.ID
+	reply.Index = index
+	return nil
+}
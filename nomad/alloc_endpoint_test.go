@@ -80,6 +80,32 @@ func TestAllocEndpoint_List(t *testing.T) {
 	if resp2.Allocations[0].ID != alloc.ID {
 		t.Fatalf("bad: %#v", resp2.Allocations[0])
 	}
+
+	// Lookup the allocations by client status
+	get = &structs.AllocListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+		ClientStatus: structs.AllocClientStatusRunning,
+	}
+
+	var resp3 structs.AllocListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Alloc.List", get, &resp3); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp3.Allocations) != 0 {
+		t.Fatalf("bad: %#v", resp3.Allocations)
+	}
+
+	get.ClientStatus = alloc.ClientStatus
+	var resp4 structs.AllocListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Alloc.List", get, &resp4); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp4.Allocations) != 1 || resp4.Allocations[0].ID != alloc.ID {
+		t.Fatalf("bad: %#v", resp4.Allocations)
+	}
 }
 
 func TestAllocEndpoint_List_ACL(t *testing.T) {
@@ -99,7 +125,7 @@ func TestAllocEndpoint_List_ACL(t *testing.T) {
 	assert.Nil(state.UpsertJobSummary(999, summary), "UpsertJobSummary")
 	assert.Nil(state.UpsertAllocs(1000, allocs), "UpsertAllocs")
 
-	stubAllocs := []*structs.AllocListStub{alloc.Stub()}
+	stubAllocs := []*structs.AllocListStub{alloc.Stub(nil)}
 	stubAllocs[0].CreateIndex = 1000
 	stubAllocs[0].ModifyIndex = 1000
 
@@ -567,3 +593,161 @@ func TestAllocEndpoint_UpdateDesiredTransition(t *testing.T) {
 	require.True(*out1.DesiredTransition.Migrate)
 	require.True(*out2.DesiredTransition.Migrate)
 }
+
+func TestAllocEndpoint_Stop(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the allocation
+	alloc := mock.Alloc()
+	state := s1.fsm.State()
+	require.Nil(state.UpsertJobSummary(998, mock.JobSummary(alloc.JobID)))
+	require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+	req := &structs.AllocStopRequest{
+		AllocID: alloc.ID,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+
+	// Try without permissions
+	var resp structs.AllocStopResponse
+	err := msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrPermissionDenied(err))
+
+	// Try with a read-only token
+	policyBad := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	tokenBad := mock.CreatePolicyAndToken(t, state, 1001, "invalid", policyBad)
+	req.AuthToken = tokenBad.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrPermissionDenied(err))
+
+	// Try with an unknown allocation
+	policyGood := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilitySubmitJob})
+	tokenGood := mock.CreatePolicyAndToken(t, state, 1003, "valid", policyGood)
+	req.AuthToken = tokenGood.SecretID
+	req.AllocID = uuid.Generate()
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrUnknownAllocation(err))
+
+	// Try with the allocation in another namespace
+	req.AuthToken = root.SecretID
+	req.AllocID = alloc.ID
+	req.Namespace = "other"
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrUnknownAllocation(err))
+
+	// Stop the allocation
+	req.AuthToken = tokenGood.SecretID
+	req.Namespace = structs.DefaultNamespace
+	var resp2 structs.AllocStopResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Alloc.Stop", req, &resp2))
+	require.NotZero(resp2.Index)
+	require.NotEmpty(resp2.EvalID)
+
+	// The allocation is marked for migration
+	out, err := state.AllocByID(nil, alloc.ID)
+	require.Nil(err)
+	require.True(out.DesiredTransition.ShouldMigrate())
+
+	// An evaluation of the job is created
+	eval, err := state.EvalByID(nil, resp2.EvalID)
+	require.Nil(err)
+	require.NotNil(eval)
+	require.Equal(structs.EvalTriggerAllocStop, eval.TriggeredBy)
+	require.Equal(alloc.JobID, eval.JobID)
+	require.Equal(alloc.Namespace, eval.Namespace)
+}
+
+func TestAllocEndpoint_Reschedule(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create a running allocation
+	alloc := mock.Alloc()
+	state := s1.fsm.State()
+	require.Nil(state.UpsertJobSummary(998, mock.JobSummary(alloc.JobID)))
+	require.Nil(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+	req := &structs.AllocRescheduleRequest{
+		AllocID: alloc.ID,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+
+	// Try without permissions
+	var resp structs.AllocRescheduleResponse
+	err := msgpackrpc.CallWithCodec(codec, "Alloc.Reschedule", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrPermissionDenied(err))
+
+	// Try with a read-only token
+	policyBad := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	tokenBad := mock.CreatePolicyAndToken(t, state, 1001, "invalid", policyBad)
+	req.AuthToken = tokenBad.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Reschedule", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrPermissionDenied(err))
+
+	// Try with an unknown allocation
+	req.AuthToken = root.SecretID
+	req.AllocID = uuid.Generate()
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Reschedule", req, &resp)
+	require.NotNil(err)
+	require.True(structs.IsErrUnknownAllocation(err))
+
+	// Try with an allocation that hasn't failed
+	req.AllocID = alloc.ID
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Reschedule", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "is not failed")
+
+	// Try with a failed allocation that has been replaced
+	failed := alloc.Copy()
+	failed.ClientStatus = structs.AllocClientStatusFailed
+	require.Nil(state.UpdateAllocsFromClient(1002, []*structs.Allocation{failed}))
+	failed.NextAllocation = uuid.Generate()
+	require.Nil(state.UpsertAllocs(1003, []*structs.Allocation{failed}))
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.Reschedule", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "already been rescheduled")
+
+	// Reschedule the failed allocation
+	failed = failed.Copy()
+	failed.NextAllocation = ""
+	require.Nil(state.UpsertAllocs(1004, []*structs.Allocation{failed}))
+	var resp2 structs.AllocRescheduleResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Alloc.Reschedule", req, &resp2))
+	require.NotZero(resp2.Index)
+	require.NotEmpty(resp2.EvalID)
+
+	// The allocation is marked for forced rescheduling
+	out, err := state.AllocByID(nil, alloc.ID)
+	require.Nil(err)
+	require.True(out.DesiredTransition.ShouldForceReschedule())
+
+	// An evaluation of the job is created
+	eval, err := state.EvalByID(nil, resp2.EvalID)
+	require.Nil(err)
+	require.NotNil(eval)
+	require.Equal(structs.EvalTriggerAllocReschedule, eval.TriggeredBy)
+	require.Equal(alloc.JobID, eval.JobID)
+}
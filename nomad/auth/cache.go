@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// ValidatorCache caches the validator of each auth method so that remote key
+// sets are not fetched on every login. A validator is replaced when the auth
+// method it was created for is modified.
+type ValidatorCache struct {
+	l          sync.Mutex
+	validators map[string]cachedValidator
+}
+
+// cachedValidator is a validator and the hash of the auth method it was
+// created for.
+type cachedValidator struct {
+	hash      []byte
+	validator *Validator
+}
+
+// NewValidatorCache returns an empty validator cache.
+func NewValidatorCache() *ValidatorCache {
+	return &ValidatorCache{
+		validators: make(map[string]cachedValidator),
+	}
+}
+
+// Get returns the validator for the auth method, creating it if needed.
+func (c *ValidatorCache) Get(method *structs.ACLAuthMethod) (*Validator, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if cached, ok := c.validators[method.Name]; ok && bytes.Equal(cached.hash, method.Hash) {
+		return cached.validator, nil
+	}
+
+	v, err := NewValidator(method)
+	if err != nil {
+		return nil, err
+	}
+	c.validators[method.Name] = cachedValidator{
+		hash:      method.Hash,
+		validator: v,
+	}
+	return v, nil
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// bindNameVar matches the variables that can be referenced by a binding
+// rule's bind name, such as "${value.project}".
+var bindNameVar = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// Identity is the set of mapped claims of a validated login token. Binding
+// rules select on and interpolate these values.
+type Identity struct {
+	// Values holds the claims mapped by ClaimMappings, available to
+	// selectors as "value.<name>".
+	Values map[string]string
+
+	// Lists holds the claims mapped by ListClaimMappings, available to
+	// selectors as "list.<name>".
+	Lists map[string][]string
+}
+
+// newIdentity maps the claims of a token as configured by the auth method.
+func newIdentity(config *structs.ACLAuthMethodConfig, claims map[string]interface{}) (*Identity, error) {
+	id := &Identity{
+		Values: make(map[string]string, len(config.ClaimMappings)),
+		Lists:  make(map[string][]string, len(config.ListClaimMappings)),
+	}
+
+	for claim, name := range config.ClaimMappings {
+		raw, ok := lookupClaim(claims, claim)
+		if !ok {
+			continue
+		}
+		value, ok := claimString(raw)
+		if !ok {
+			return nil, fmt.Errorf("%v: claim %q is not a string, number or bool", ErrInvalidToken, claim)
+		}
+		id.Values[name] = value
+	}
+
+	for claim, name := range config.ListClaimMappings {
+		raw, ok := lookupClaim(claims, claim)
+		if !ok {
+			continue
+		}
+		var list []string
+		switch v := raw.(type) {
+		case []interface{}:
+			for _, elem := range v {
+				s, ok := claimString(elem)
+				if !ok {
+					return nil, fmt.Errorf("%v: claim %q contains a value that is not a string, number or bool", ErrInvalidToken, claim)
+				}
+				list = append(list, s)
+			}
+		default:
+			s, ok := claimString(v)
+			if !ok {
+				return nil, fmt.Errorf("%v: claim %q is not a list", ErrInvalidToken, claim)
+			}
+			list = []string{s}
+		}
+		id.Lists[name] = list
+	}
+
+	return id, nil
+}
+
+// lookupClaim returns a claim by name. Names starting with a "/" are treated
+// as JSON pointers into nested claims, such as "/groups/admin".
+func lookupClaim(claims map[string]interface{}, name string) (interface{}, bool) {
+	if !strings.HasPrefix(name, "/") {
+		v, ok := claims[name]
+		return v, ok
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(name[1:], "/") {
+		part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// claimString converts a scalar claim value to a string.
+func claimString(raw interface{}) (string, bool) {
+	switch v := raw.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// InterpolateBindName replaces the "${value.<name>}" variables in the bind
+// name of a binding rule with the identity's values. An error is returned if
+// a variable is not a known value.
+func (i *Identity) InterpolateBindName(name string) (string, error) {
+	var err error
+	out := bindNameVar.ReplaceAllStringFunc(name, func(match string) string {
+		ref := strings.TrimSpace(match[2 : len(match)-1])
+		if !strings.HasPrefix(ref, "value.") {
+			err = fmt.Errorf("bind name variable %q must reference a value", ref)
+			return ""
+		}
+		v, ok := i.Values[strings.TrimPrefix(ref, "value.")]
+		if !ok {
+			err = fmt.Errorf("bind name variable %q is not set", ref)
+			return ""
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// ValidateBindName checks that the variables referenced by a bind name are
+// well formed.
+func ValidateBindName(name string) error {
+	for _, match := range bindNameVar.FindAllStringSubmatch(name, -1) {
+		ref := strings.TrimSpace(match[1])
+		if !strings.HasPrefix(ref, "value.") || len(ref) == len("value.") {
+			return fmt.Errorf("bind name variable %q must reference a value", ref)
+		}
+	}
+	return nil
+}
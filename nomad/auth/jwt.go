@@ -0,0 +1,319 @@
+// Package auth implements the validation of the JWTs presented to ACL auth
+// methods and the evaluation of binding rules against their claims.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	// Register the hash functions used by the supported algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// DefaultSigningAlg is the signing algorithm accepted when an auth method
+	// does not configure any.
+	DefaultSigningAlg = "RS256"
+)
+
+var (
+	// ErrInvalidToken is returned when a login token can not be parsed or
+	// its signature or claims are invalid.
+	ErrInvalidToken = errors.New("invalid login token")
+)
+
+// signingAlgs maps the supported JWS algorithms to their hash function.
+var signingAlgs = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// jwtHeader is the subset of the JOSE header used to verify a JWT.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwt is a parsed, but not yet verified, JSON Web Token.
+type jwt struct {
+	header    jwtHeader
+	claims    map[string]interface{}
+	signed    []byte
+	signature []byte
+}
+
+// parseJWT splits and decodes a compact serialized JWT.
+func parseJWT(raw string) (*jwt, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%v: malformed JWT", ErrInvalidToken)
+	}
+
+	headerRaw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%v: malformed header: %v", ErrInvalidToken, err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerRaw, &header); err != nil {
+		return nil, fmt.Errorf("%v: malformed header: %v", ErrInvalidToken, err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%v: malformed payload: %v", ErrInvalidToken, err)
+	}
+	var claims map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%v: malformed claims: %v", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%v: malformed signature: %v", ErrInvalidToken, err)
+	}
+
+	return &jwt{
+		header:    header,
+		claims:    claims,
+		signed:    []byte(parts[0] + "." + parts[1]),
+		signature: signature,
+	}, nil
+}
+
+// verifySignature checks the signature of the token against the public key.
+func (t *jwt) verifySignature(key crypto.PublicKey) error {
+	hash, ok := signingAlgs[t.header.Alg]
+	if !ok {
+		return fmt.Errorf("unsupported signing algorithm %q", t.header.Alg)
+	}
+	h := hash.New()
+	h.Write(t.signed)
+	digest := h.Sum(nil)
+
+	switch t.header.Alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type %T does not match algorithm %s", key, t.header.Alg)
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, t.signature)
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type %T does not match algorithm %s", key, t.header.Alg)
+		}
+		return rsa.VerifyPSS(pub, hash, digest, t.signature, nil)
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type %T does not match algorithm %s", key, t.header.Alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(t.signature) != 2*size {
+			return errors.New("invalid ECDSA signature length")
+		}
+		r := new(big.Int).SetBytes(t.signature[:size])
+		s := new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported signing algorithm %q", t.header.Alg)
+}
+
+// Validator validates the login tokens presented to a single auth method.
+type Validator struct {
+	method *structs.ACLAuthMethod
+	keys   keySet
+
+	// now is used to get the current time and can be overridden in tests
+	now func() time.Time
+}
+
+// NewValidator returns a validator for the auth method. Remote key sets are
+// fetched lazily, so creating a validator does not make network requests.
+func NewValidator(method *structs.ACLAuthMethod) (*Validator, error) {
+	if method.Config == nil {
+		return nil, fmt.Errorf("auth method %q has no config", method.Name)
+	}
+	keys, err := newKeySet(method.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{
+		method: method,
+		keys:   keys,
+		now:    time.Now,
+	}, nil
+}
+
+// Validate verifies the signature and claims of the login token and returns
+// the identity described by its mapped claims.
+func (v *Validator) Validate(ctx context.Context, loginToken string) (*Identity, error) {
+	token, err := parseJWT(loginToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.allowedAlg(token.header.Alg) {
+		return nil, fmt.Errorf("%v: signing algorithm %q not allowed", ErrInvalidToken, token.header.Alg)
+	}
+
+	keys, err := v.keys.keysFor(ctx, token.header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %v", err)
+	}
+	verified := false
+	for _, key := range keys {
+		if token.verifySignature(key) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("%v: signature verification failed", ErrInvalidToken)
+	}
+
+	if err := v.validateClaims(token.claims); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidToken, err)
+	}
+
+	return newIdentity(v.method.Config, token.claims)
+}
+
+// allowedAlg returns whether the auth method accepts the signing algorithm.
+func (v *Validator) allowedAlg(alg string) bool {
+	if _, ok := signingAlgs[alg]; !ok {
+		return false
+	}
+	algs := v.method.Config.SigningAlgs
+	if len(algs) == 0 {
+		algs = []string{DefaultSigningAlg}
+	}
+	for _, allowed := range algs {
+		if allowed == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// validateClaims checks the registered claims of the token.
+func (v *Validator) validateClaims(claims map[string]interface{}) error {
+	config := v.method.Config
+	now := v.now()
+	leeway := config.ClockSkewLeeway
+
+	exp, ok, err := numericDate(claims, "exp")
+	if err != nil {
+		return err
+	} else if !ok {
+		return errors.New("missing exp claim")
+	} else if now.After(exp.Add(leeway)) {
+		return errors.New("token is expired")
+	}
+
+	if nbf, ok, err := numericDate(claims, "nbf"); err != nil {
+		return err
+	} else if ok && now.Add(leeway).Before(nbf) {
+		return errors.New("token is not yet valid")
+	}
+
+	if iat, ok, err := numericDate(claims, "iat"); err != nil {
+		return err
+	} else if ok && now.Add(leeway).Before(iat) {
+		return errors.New("token was issued in the future")
+	}
+
+	iss, _ := claims["iss"].(string)
+	if issuer := v.keys.issuer(); issuer != "" && iss != issuer {
+		return fmt.Errorf("issuer %q does not match the OIDC provider", iss)
+	}
+	if len(config.BoundIssuer) != 0 && !contains(config.BoundIssuer, iss) {
+		return fmt.Errorf("issuer %q not allowed", iss)
+	}
+
+	if len(config.BoundAudiences) != 0 {
+		matched := false
+		for _, aud := range audiences(claims["aud"]) {
+			if contains(config.BoundAudiences, aud) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return errors.New("audience not allowed")
+		}
+	}
+	return nil
+}
+
+// numericDate returns the time encoded by a NumericDate claim and whether the
+// claim is present.
+func numericDate(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	raw, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	num, ok := raw.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("invalid %s claim", name)
+	}
+	f, err := num.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s claim: %v", name, err)
+	}
+	sec := int64(f)
+	nsec := int64((f - float64(sec)) * float64(time.Second))
+	return time.Unix(sec, nsec), true, nil
+}
+
+// audiences returns the values of an "aud" claim, which may be a single
+// string or a list of strings.
+func audiences(raw interface{}) []string {
+	switch aud := raw.(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		out := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,374 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+// signJWT returns a compact serialized JWT signed with the key.
+func signJWT(t *testing.T, key crypto.Signer, alg, kid string, claims map[string]interface{}) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	hash := signingAlgs[alg]
+	hasher := hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(alg, "PS") {
+			sig, err = rsa.SignPSS(rand.Reader, k, hash, digest, nil)
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+		}
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		require.NoError(t, err)
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[size-len(rb):size], rb)
+		copy(sig[2*size-len(sb):], sb)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":    "https://ci.example.com",
+		"aud":    []string{"nomad"},
+		"sub":    "project/42",
+		"iat":    now.Unix(),
+		"nbf":    now.Unix(),
+		"exp":    now.Add(time.Minute).Unix(),
+		"groups": []string{"eng", "ops"},
+		"nested": map[string]interface{}{"project": "web"},
+	}
+}
+
+func testMethod(config *structs.ACLAuthMethodConfig) *structs.ACLAuthMethod {
+	m := &structs.ACLAuthMethod{
+		Name:          "ci",
+		Type:          structs.ACLAuthMethodTypeJWT,
+		TokenLocality: structs.ACLAuthMethodTokenLocalityLocal,
+		MaxTokenTTL:   time.Hour,
+		Config:        config,
+	}
+	m.SetHash()
+	return m
+}
+
+func TestValidator_StaticKeys(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+
+	v, err := NewValidator(testMethod(&structs.ACLAuthMethodConfig{
+		JWTValidationPubKeys: []string{publicKeyPEM(t, &rsaKey.PublicKey), publicKeyPEM(t, &ecKey.PublicKey)},
+		BoundIssuer:          []string{"https://ci.example.com"},
+		BoundAudiences:       []string{"nomad"},
+		SigningAlgs:          []string{"RS256", "PS384", "ES256"},
+		ClaimMappings:        map[string]string{"sub": "subject", "/nested/project": "project"},
+		ListClaimMappings:    map[string]string{"groups": "groups"},
+	}))
+	require.NoError(err)
+	ctx := context.Background()
+
+	// Each allowed algorithm is accepted
+	for alg, key := range map[string]crypto.Signer{"RS256": rsaKey, "PS384": rsaKey, "ES256": ecKey} {
+		id, err := v.Validate(ctx, signJWT(t, key, alg, "", validClaims()))
+		require.NoError(err, alg)
+		require.Equal(map[string]string{"subject": "project/42", "project": "web"}, id.Values)
+		require.Equal(map[string][]string{"groups": {"eng", "ops"}}, id.Lists)
+	}
+
+	cases := []struct {
+		name   string
+		token  func() string
+		errMsg string
+	}{
+		{
+			name:   "malformed",
+			token:  func() string { return "not.a.jwt" },
+			errMsg: "malformed",
+		},
+		{
+			name:   "unknown key",
+			token:  func() string { return signJWT(t, otherKey, "RS256", "", validClaims()) },
+			errMsg: "signature verification failed",
+		},
+		{
+			name:   "disallowed algorithm",
+			token:  func() string { return signJWT(t, rsaKey, "RS512", "", validClaims()) },
+			errMsg: "not allowed",
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := validClaims()
+				c["exp"] = time.Now().Add(-time.Minute).Unix()
+				return signJWT(t, rsaKey, "RS256", "", c)
+			},
+			errMsg: "expired",
+		},
+		{
+			name: "missing expiry",
+			token: func() string {
+				c := validClaims()
+				delete(c, "exp")
+				return signJWT(t, rsaKey, "RS256", "", c)
+			},
+			errMsg: "missing exp",
+		},
+		{
+			name: "not yet valid",
+			token: func() string {
+				c := validClaims()
+				c["nbf"] = time.Now().Add(time.Hour).Unix()
+				return signJWT(t, rsaKey, "RS256", "", c)
+			},
+			errMsg: "not yet valid",
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				c := validClaims()
+				c["iss"] = "https://evil.example.com"
+				return signJWT(t, rsaKey, "RS256", "", c)
+			},
+			errMsg: "issuer",
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := validClaims()
+				c["aud"] = "other"
+				return signJWT(t, rsaKey, "RS256", "", c)
+			},
+			errMsg: "audience",
+		},
+		{
+			name: "unmappable claim",
+			token: func() string {
+				c := validClaims()
+				c["sub"] = map[string]string{"a": "b"}
+				return signJWT(t, rsaKey, "RS256", "", c)
+			},
+			errMsg: "not a string",
+		},
+	}
+	for _, c := range cases {
+		_, err := v.Validate(ctx, c.token())
+		require.Error(err, c.name)
+		require.Contains(err.Error(), c.errMsg, c.name)
+	}
+
+	// Tampering with the payload invalidates the signature
+	parts := strings.Split(signJWT(t, rsaKey, "RS256", "", validClaims()), ".")
+	c := validClaims()
+	c["sub"] = "project/1"
+	payload, _ := json.Marshal(c)
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	_, err = v.Validate(ctx, strings.Join(parts, "."))
+	require.Error(err)
+	require.Contains(err.Error(), "signature verification failed")
+}
+
+func TestValidator_ClockSkewLeeway(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	v, err := NewValidator(testMethod(&structs.ACLAuthMethodConfig{
+		JWTValidationPubKeys: []string{publicKeyPEM(t, &key.PublicKey)},
+		ClockSkewLeeway:      time.Minute,
+	}))
+	require.NoError(err)
+
+	c := validClaims()
+	c["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	_, err = v.Validate(context.Background(), signJWT(t, key, "RS256", "", c))
+	require.NoError(err)
+}
+
+// jwks returns the JSON Web Key Set encoding of the keys.
+func jwks(keys map[string]crypto.PublicKey) []byte {
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	enc := func(b *big.Int) string { return base64.RawURLEncoding.EncodeToString(b.Bytes()) }
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": enc(k.N), "e": enc(big.NewInt(int64(k.E))),
+			})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": "P-256",
+				"x": enc(k.X), "y": enc(k.Y),
+			})
+		}
+	}
+	out, _ := json.Marshal(set)
+	return out
+}
+
+func TestValidator_JWKS(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(jwks(map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey}))
+	}))
+	defer srv.Close()
+
+	v, err := NewValidator(testMethod(&structs.ACLAuthMethodConfig{
+		JWKSURL:     srv.URL,
+		SigningAlgs: []string{"RS256", "ES256"},
+	}))
+	require.NoError(err)
+	ctx := context.Background()
+
+	_, err = v.Validate(ctx, signJWT(t, rsaKey, "RS256", "rsa", validClaims()))
+	require.NoError(err)
+	_, err = v.Validate(ctx, signJWT(t, ecKey, "ES256", "ec", validClaims()))
+	require.NoError(err)
+	_, err = v.Validate(ctx, signJWT(t, ecKey, "ES256", "", validClaims()))
+	require.NoError(err)
+
+	// The key set is cached
+	require.Equal(1, fetches)
+
+	// Keys are selected by ID
+	_, err = v.Validate(ctx, signJWT(t, ecKey, "ES256", "rsa", validClaims()))
+	require.Error(err)
+	_, err = v.Validate(ctx, signJWT(t, rsaKey, "RS256", "missing", validClaims()))
+	require.Error(err)
+	require.Contains(err.Error(), "unknown key ID")
+}
+
+func TestValidator_OIDCDiscovery(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+
+	var issuer string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer": %q, "jwks_uri": %q}`, issuer, issuer+"/keys")
+		case "/keys":
+			w.Write(jwks(map[string]crypto.PublicKey{"k1": &key.PublicKey}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	issuer = srv.URL
+
+	method := testMethod(&structs.ACLAuthMethodConfig{
+		OIDCDiscoveryURL: srv.URL,
+		BoundAudiences:   []string{"nomad"},
+	})
+	method.Type = structs.ACLAuthMethodTypeOIDC
+	v, err := NewValidator(method)
+	require.NoError(err)
+	ctx := context.Background()
+
+	// Tokens must be issued by the provider
+	_, err = v.Validate(ctx, signJWT(t, key, "RS256", "k1", validClaims()))
+	require.Error(err)
+	require.Contains(err.Error(), "does not match the OIDC provider")
+
+	c := validClaims()
+	c["iss"] = issuer
+	_, err = v.Validate(ctx, signJWT(t, key, "RS256", "k1", c))
+	require.NoError(err)
+}
+
+func TestValidatePublicKeys(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	require.NoError(t, ValidatePublicKeys([]string{publicKeyPEM(t, &key.PublicKey)}))
+	require.Error(t, ValidatePublicKeys([]string{"not a key"}))
+}
+
+func TestValidatorCache(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	method := testMethod(&structs.ACLAuthMethodConfig{
+		JWTValidationPubKeys: []string{publicKeyPEM(t, &key.PublicKey)},
+	})
+
+	cache := NewValidatorCache()
+	v1, err := cache.Get(method)
+	require.NoError(err)
+	v2, err := cache.Get(method)
+	require.NoError(err)
+	require.True(v1 == v2)
+
+	// Modifying the method replaces the validator
+	method.MaxTokenTTL = 2 * time.Hour
+	method.SetHash()
+	v3, err := cache.Get(method)
+	require.NoError(err)
+	require.False(v1 == v3)
+}
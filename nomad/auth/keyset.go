@@ -0,0 +1,380 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// keySetRefreshInterval is how often a remote key set is refreshed
+	keySetRefreshInterval = 1 * time.Hour
+
+	// keySetMinRefreshInterval limits how often an unknown key ID can cause a
+	// remote key set to be refreshed early
+	keySetMinRefreshInterval = 1 * time.Minute
+
+	// keySetFetchTimeout bounds the time spent fetching remote keys
+	keySetFetchTimeout = 10 * time.Second
+
+	// maxRemoteDocumentSize bounds the size of the documents fetched from
+	// identity providers
+	maxRemoteDocumentSize = 1 << 20
+)
+
+// keySet is a source of the public keys used to verify login tokens.
+type keySet interface {
+	// keysFor returns the candidate keys for the key ID, which may be empty.
+	keysFor(ctx context.Context, kid string) ([]crypto.PublicKey, error)
+
+	// issuer returns the issuer tokens must be issued by, if the key set
+	// defines one.
+	issuer() string
+}
+
+// newKeySet returns the key set configured by the auth method config.
+func newKeySet(config *structs.ACLAuthMethodConfig) (keySet, error) {
+	switch {
+	case len(config.JWTValidationPubKeys) != 0:
+		keys := make([]crypto.PublicKey, 0, len(config.JWTValidationPubKeys))
+		for _, p := range config.JWTValidationPubKeys {
+			key, err := parsePublicKeyPEM(p)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		return staticKeySet(keys), nil
+	case config.JWKSURL != "":
+		client, err := httpClient(config.JWKSCACert)
+		if err != nil {
+			return nil, err
+		}
+		return &remoteKeySet{url: config.JWKSURL, client: client}, nil
+	case config.OIDCDiscoveryURL != "":
+		client, err := httpClient(config.OIDCDiscoveryCACert)
+		if err != nil {
+			return nil, err
+		}
+		return &oidcKeySet{discoveryURL: config.OIDCDiscoveryURL, client: client}, nil
+	}
+	return nil, errors.New("auth method has no source of signing keys")
+}
+
+// staticKeySet is a fixed set of keys.
+type staticKeySet []crypto.PublicKey
+
+func (s staticKeySet) keysFor(context.Context, string) ([]crypto.PublicKey, error) {
+	return s, nil
+}
+
+func (s staticKeySet) issuer() string { return "" }
+
+// remoteKeySet is a JSON Web Key Set fetched from a URL and cached.
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+
+	l       sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func (r *remoteKeySet) keysFor(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	// Refresh stale keys, or keys that don't contain the requested key ID
+	// unless they were just fetched.
+	age := time.Since(r.fetched)
+	_, known := r.keys[kid]
+	if r.keys == nil || age > keySetRefreshInterval ||
+		(kid != "" && !known && age > keySetMinRefreshInterval) {
+		keys, err := r.fetch(ctx)
+		if err != nil {
+			// Fall back to the cached keys if there are any
+			if r.keys == nil {
+				return nil, err
+			}
+		} else {
+			r.keys = keys
+			r.fetched = time.Now()
+		}
+	}
+
+	if kid != "" {
+		if key, ok := r.keys[kid]; ok {
+			return []crypto.PublicKey{key}, nil
+		}
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	keys := make([]crypto.PublicKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r *remoteKeySet) issuer() string { return "" }
+
+// jsonWebKey is the subset of RFC 7517 used for signature verification keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch retrieves and parses the key set. Keys that are not used for
+// signatures or can't be parsed are skipped.
+func (r *remoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, r.client, r.url, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		kid := jwk.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("#%d", i)
+		}
+		keys[kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no usable keys found at %s", r.url)
+	}
+	return keys, nil
+}
+
+// publicKey decodes the RSA or EC public key.
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// oidcKeySet locates the key set of an OpenID Connect provider using its
+// discovery document.
+type oidcKeySet struct {
+	discoveryURL string
+	client       *http.Client
+
+	l      sync.Mutex
+	iss    string
+	remote *remoteKeySet
+}
+
+func (o *oidcKeySet) keysFor(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	o.l.Lock()
+	if o.remote == nil {
+		if err := o.discover(ctx); err != nil {
+			o.l.Unlock()
+			return nil, err
+		}
+	}
+	remote := o.remote
+	o.l.Unlock()
+
+	return remote.keysFor(ctx, kid)
+}
+
+func (o *oidcKeySet) issuer() string {
+	o.l.Lock()
+	defer o.l.Unlock()
+	if o.iss == "" {
+		return o.discoveryURL
+	}
+	return o.iss
+}
+
+// discover fetches the provider's discovery document. It must be called with
+// the lock held.
+func (o *oidcKeySet) discover(ctx context.Context) error {
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	wellKnown := strings.TrimSuffix(o.discoveryURL, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, o.client, wellKnown, &doc); err != nil {
+		return err
+	}
+
+	// The OIDC specification requires the issuer to match the URL the
+	// discovery document was requested from.
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(o.discoveryURL, "/") {
+		return fmt.Errorf("OIDC provider issuer %q does not match discovery URL %q", doc.Issuer, o.discoveryURL)
+	}
+	if doc.JWKSURI == "" {
+		return errors.New("OIDC discovery document is missing jwks_uri")
+	}
+
+	o.iss = doc.Issuer
+	o.remote = &remoteKeySet{url: doc.JWKSURI, client: o.client}
+	return nil
+}
+
+// getJSON fetches a JSON document.
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, keySetFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxRemoteDocumentSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response code %d fetching %s", resp.StatusCode, url)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", url, err)
+	}
+	return nil
+}
+
+// httpClient returns a client that trusts the PEM encoded CA certificate in
+// addition to the system roots.
+func httpClient(caCert string) (*http.Client, error) {
+	client := &http.Client{Timeout: keySetFetchTimeout}
+	if caCert == "" {
+		return client, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("failed to parse CA certificate")
+	}
+	client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}
+	return client, nil
+}
+
+// parsePublicKeyPEM parses a PEM encoded public key or certificate.
+func parsePublicKeyPEM(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM public key")
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
+
+// ValidatePublicKeys returns an error if any of the PEM encoded keys can not
+// be parsed.
+func ValidatePublicKeys(keys []string) error {
+	for i, key := range keys {
+		if _, err := parsePublicKeyPEM(key); err != nil {
+			return fmt.Errorf("public key %d invalid: %v", i, err)
+		}
+	}
+	return nil
+}
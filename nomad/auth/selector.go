@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Selector is a compiled binding rule selector. A selector is a set of
+// clauses joined by "and" and "or", where "and" binds more tightly. The
+// supported clauses are:
+//
+//	value.<name> == "<string>"
+//	value.<name> != "<string>"
+//	"<string>" in list.<name>
+//	"<string>" not in list.<name>
+//
+// An empty selector matches every identity.
+type Selector struct {
+	// anyOf is a disjunction of conjunctions of clauses
+	anyOf [][]clause
+}
+
+// clause is a single comparison of a selector.
+type clause struct {
+	field  string
+	op     string
+	target string
+}
+
+// matches evaluates the clause against the identity.
+func (c clause) matches(id *Identity) bool {
+	switch c.op {
+	case "==", "!=":
+		v, ok := id.Values[strings.TrimPrefix(c.field, "value.")]
+		equal := ok && v == c.target
+		return equal == (c.op == "==")
+	case "in", "not in":
+		found := false
+		for _, v := range id.Lists[strings.TrimPrefix(c.field, "list.")] {
+			if v == c.target {
+				found = true
+				break
+			}
+		}
+		return found == (c.op == "in")
+	}
+	return false
+}
+
+// Matches returns whether the identity is selected.
+func (s *Selector) Matches(id *Identity) bool {
+	if len(s.anyOf) == 0 {
+		return true
+	}
+	for _, allOf := range s.anyOf {
+		matched := true
+		for _, c := range allOf {
+			if !c.matches(id) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileSelector parses a binding rule selector.
+func CompileSelector(selector string) (*Selector, error) {
+	tokens, err := tokenize(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Selector{}
+	if len(tokens) == 0 {
+		return s, nil
+	}
+
+	var allOf []clause
+	for len(tokens) > 0 {
+		c, rest, err := parseClause(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", selector, err)
+		}
+		allOf = append(allOf, c)
+		tokens = rest
+
+		if len(tokens) == 0 {
+			break
+		}
+		switch tokens[0].text {
+		case "and":
+		case "or":
+			s.anyOf = append(s.anyOf, allOf)
+			allOf = nil
+		default:
+			return nil, fmt.Errorf("invalid selector %q: expected \"and\" or \"or\", got %q", selector, tokens[0].text)
+		}
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("invalid selector %q: unexpected end of selector", selector)
+		}
+	}
+	s.anyOf = append(s.anyOf, allOf)
+	return s, nil
+}
+
+// parseClause parses a single clause from the start of the tokens.
+func parseClause(tokens []token) (clause, []token, error) {
+	if len(tokens) < 3 {
+		return clause{}, nil, fmt.Errorf("incomplete clause")
+	}
+
+	// value.<name> ==|!= "<string>"
+	if !tokens[0].quoted {
+		field, op, target := tokens[0], tokens[1], tokens[2]
+		if op.quoted || (op.text != "==" && op.text != "!=") {
+			return clause{}, nil, fmt.Errorf("expected == or != after %q", field.text)
+		}
+		if !target.quoted {
+			return clause{}, nil, fmt.Errorf("expected a quoted string after %q", op.text)
+		}
+		if err := validateField(field.text, "value."); err != nil {
+			return clause{}, nil, err
+		}
+		return clause{field: field.text, op: op.text, target: target.text}, tokens[3:], nil
+	}
+
+	// "<string>" [not] in list.<name>
+	target, rest := tokens[0], tokens[1:]
+	op := "in"
+	if !rest[0].quoted && rest[0].text == "not" {
+		op = "not in"
+		rest = rest[1:]
+	}
+	if len(rest) < 2 || rest[0].quoted || rest[0].text != "in" {
+		return clause{}, nil, fmt.Errorf("expected in or not in after %q", target.text)
+	}
+	field := rest[1]
+	if field.quoted {
+		return clause{}, nil, fmt.Errorf("expected a list field after in")
+	}
+	if err := validateField(field.text, "list."); err != nil {
+		return clause{}, nil, err
+	}
+	return clause{field: field.text, op: op, target: target.text}, rest[2:], nil
+}
+
+// validateField checks that the field has the given prefix and a name.
+func validateField(field, prefix string) error {
+	if !strings.HasPrefix(field, prefix) || len(field) == len(prefix) {
+		return fmt.Errorf("field %q must be of the form %s<name>", field, prefix)
+	}
+	return nil
+}
+
+// token is a lexical token of a selector.
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits the selector into quoted strings, operators and words.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '"':
+			// Find the closing quote, skipping escaped characters
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("invalid selector %q: unterminated string", s)
+			}
+			text, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q: %v", s, err)
+			}
+			tokens = append(tokens, token{text: text, quoted: true})
+			i = end + 1
+		case c == '=' || c == '!':
+			if i+1 >= len(s) || s[i+1] != '=' {
+				return nil, fmt.Errorf("invalid selector %q: unexpected %q", s, c)
+			}
+			tokens = append(tokens, token{text: s[i : i+2]})
+			i += 2
+		default:
+			end := i
+			for end < len(s) && !unicode.IsSpace(rune(s[end])) && !strings.ContainsRune(`"=!`, rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, token{text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector(t *testing.T) {
+	t.Parallel()
+	id := &Identity{
+		Values: map[string]string{"project": "web", "env": "prod"},
+		Lists:  map[string][]string{"groups": {"eng", "ops"}},
+	}
+
+	cases := []struct {
+		selector string
+		match    bool
+	}{
+		{``, true},
+		{`value.project == "web"`, true},
+		{`value.project == "api"`, false},
+		{`value.project != "api"`, true},
+		{`value.missing != "api"`, true},
+		{`value.missing == ""`, false},
+		{`"eng" in list.groups`, true},
+		{`"sales" in list.groups`, false},
+		{`"sales" not in list.groups`, true},
+		{`"eng" in list.missing`, false},
+		{`value.project == "web" and "ops" in list.groups`, true},
+		{`value.project == "web" and "sales" in list.groups`, false},
+		{`value.project == "api" or "ops" in list.groups`, true},
+		{`value.project == "api" or value.env == "prod" and "sales" in list.groups`, false},
+		{`value.project=="web"`, true},
+		{`value.project == "w\"eb"`, false},
+	}
+	for _, c := range cases {
+		s, err := CompileSelector(c.selector)
+		require.NoError(t, err, c.selector)
+		require.Equal(t, c.match, s.Matches(id), c.selector)
+	}
+}
+
+func TestSelector_Invalid(t *testing.T) {
+	t.Parallel()
+	cases := []string{
+		`value.project`,
+		`value.project = "web"`,
+		`value.project == web`,
+		`"web" == value.project`,
+		`list.groups == "eng"`,
+		`"eng" in value.project`,
+		`"eng" in`,
+		`value.project == "web" and`,
+		`value.project == "web" xor value.env == "prod"`,
+		`value.project == "web`,
+		`value. == "web"`,
+	}
+	for _, c := range cases {
+		_, err := CompileSelector(c)
+		require.Error(t, err, c)
+	}
+}
+
+func TestIdentity_InterpolateBindName(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	id := &Identity{
+		Values: map[string]string{"project": "web"},
+		Lists:  map[string][]string{"groups": {"eng"}},
+	}
+
+	out, err := id.InterpolateBindName("deploy-${value.project}")
+	require.NoError(err)
+	require.Equal("deploy-web", out)
+
+	out, err = id.InterpolateBindName("static")
+	require.NoError(err)
+	require.Equal("static", out)
+
+	_, err = id.InterpolateBindName("deploy-${value.missing}")
+	require.Error(err)
+	_, err = id.InterpolateBindName("deploy-${list.groups}")
+	require.Error(err)
+
+	require.NoError(ValidateBindName("deploy-${ value.project }"))
+	require.Error(ValidateBindName("deploy-${list.groups}"))
+	require.Error(ValidateBindName("deploy-${value.}"))
+}
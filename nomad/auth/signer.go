@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Signer signs JWTs with a private key. RSA keys sign with RS256 and ECDSA
+// keys with the ES algorithm matching their curve.
+type Signer struct {
+	key crypto.Signer
+	alg string
+	kid string
+}
+
+// NewSigner returns a signer for the PEM encoded private key, which may be a
+// PKCS #1 or SEC 1 key or a PKCS #8 RSA or ECDSA key.
+func NewSigner(keyPEM []byte) (*Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM private key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+
+	var alg string
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			alg = "ES256"
+		case 384:
+			alg = "ES384"
+		case 521:
+			alg = "ES512"
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve %s", k.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	signer := key.(crypto.Signer)
+	pub, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %v", err)
+	}
+	sum := sha256.Sum256(pub)
+
+	return &Signer{
+		key: signer,
+		alg: alg,
+		kid: hex.EncodeToString(sum[:8]),
+	}, nil
+}
+
+// Algorithm returns the signing algorithm of the signer.
+func (s *Signer) Algorithm() string {
+	return s.alg
+}
+
+// Sign returns the compact serialization of a JWT carrying the claims.
+func (s *Signer) Sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: s.alg, Kid: s.kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := signingAlgs[s.alg]
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		if err != nil {
+			return "", err
+		}
+	case *ecdsa.PrivateKey:
+		r, sig, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			return "", err
+		}
+
+		// JWS encodes ECDSA signatures as the fixed size concatenation of R
+		// and S
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		rb, sb := r.Bytes(), sig.Bytes()
+		copy(signature[size-len(rb):size], rb)
+		copy(signature[2*size-len(sb):], sb)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
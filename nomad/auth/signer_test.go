@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(err)
+	pkcs8DER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(err)
+
+	cases := []struct {
+		name   string
+		pem    []byte
+		pubKey interface{}
+		alg    string
+	}{
+		{
+			name:   "pkcs1",
+			pem:    pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+			pubKey: &rsaKey.PublicKey,
+			alg:    "RS256",
+		},
+		{
+			name:   "pkcs8",
+			pem:    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8DER}),
+			pubKey: &rsaKey.PublicKey,
+			alg:    "RS256",
+		},
+		{
+			name:   "ec",
+			pem:    pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}),
+			pubKey: &ecKey.PublicKey,
+			alg:    "ES384",
+		},
+	}
+
+	for _, c := range cases {
+		signer, err := NewSigner(c.pem)
+		require.NoError(err, c.name)
+		require.Equal(c.alg, signer.Algorithm(), c.name)
+
+		// Tokens signed by the signer are accepted by a validator trusting its
+		// public key
+		v, err := NewValidator(testMethod(&structs.ACLAuthMethodConfig{
+			JWTValidationPubKeys: []string{publicKeyPEM(t, c.pubKey)},
+			BoundIssuer:          []string{"https://ci.example.com"},
+			BoundAudiences:       []string{"nomad"},
+			SigningAlgs:          []string{c.alg},
+			ClaimMappings:        map[string]string{"sub": "subject"},
+		}))
+		require.NoError(err, c.name)
+
+		token, err := signer.Sign(validClaims())
+		require.NoError(err, c.name)
+		id, err := v.Validate(context.Background(), token)
+		require.NoError(err, c.name)
+		require.Equal("project/42", id.Values["subject"], c.name)
+	}
+
+	_, err = NewSigner([]byte("not a key"))
+	require.Error(err)
+}
@@ -785,3 +785,209 @@ func TestClientAllocations_Stats_Remote(t *testing.T) {
 	require.Nil(err)
 	require.NotNil(resp.Stats)
 }
+
+// runningMockAlloc places a long running mock driver allocation on the client
+// and waits for the client to report it as running.
+func runningMockAlloc(t *testing.T, s *Server, c *client.Client) *structs.Allocation {
+	a := mock.Alloc()
+	a.NodeID = c.NodeID()
+	a.Job.TaskGroups[0].Count = 1
+	a.Job.TaskGroups[0].Tasks[0] = &structs.Task{
+		Name:   "web",
+		Driver: "mock_driver",
+		Config: map[string]interface{}{
+			"run_for": "10s",
+		},
+		LogConfig: structs.DefaultLogConfig(),
+		Resources: &structs.Resources{
+			CPU:      500,
+			MemoryMB: 256,
+		},
+	}
+
+	testutil.WaitForResult(func() (bool, error) {
+		nodes := s.connectedNodes()
+		return len(nodes) == 1, nil
+	}, func(err error) {
+		t.Fatalf("should have a clients")
+	})
+
+	// Upsert the allocation
+	state := s.State()
+	require.Nil(t, state.UpsertJob(999, a.Job))
+	require.Nil(t, state.UpsertAllocs(1003, []*structs.Allocation{a}))
+
+	// Wait for the client to run the allocation
+	testutil.WaitForResult(func() (bool, error) {
+		alloc, err := state.AllocByID(nil, a.ID)
+		if err != nil {
+			return false, err
+		}
+		if alloc == nil {
+			return false, fmt.Errorf("unknown alloc")
+		}
+		if alloc.ClientStatus != structs.AllocClientStatusRunning {
+			return false, fmt.Errorf("alloc client status: %v", alloc.ClientStatus)
+		}
+
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("Alloc on node %q not running: %v", c.NodeID(), err)
+	})
+	return a
+}
+
+// waitForTaskEvent waits for the client to report an event of the given type
+// for the task of the allocation.
+func waitForTaskEvent(t *testing.T, s *Server, allocID, task, eventType string) {
+	testutil.WaitForResult(func() (bool, error) {
+		alloc, err := s.State().AllocByID(nil, allocID)
+		if err != nil {
+			return false, err
+		}
+		if alloc == nil || alloc.TaskStates[task] == nil {
+			return false, fmt.Errorf("missing state of task %q", task)
+		}
+		for _, e := range alloc.TaskStates[task].Events {
+			if e.Type == eventType {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("missing %q event", eventType)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestClientAllocations_Restart_Local(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Start a server and client
+	s := TestServer(t, nil)
+	defer s.Shutdown()
+	codec := rpcClient(t, s)
+	testutil.WaitForLeader(t, s.RPC)
+
+	c, cleanup := client.TestClient(t, func(c *config.Config) {
+		c.Servers = []string{s.config.RPCAddr.String()}
+	})
+	defer cleanup()
+
+	a := runningMockAlloc(t, s, c)
+
+	// Make the request without having an alloc id
+	req := &structs.AllocRestartRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ClientAllocations.Restart", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "missing")
+
+	// Make the request with an unknown task
+	req.AllocID = a.ID
+	req.TaskName = "foo"
+	err = msgpackrpc.CallWithCodec(codec, "ClientAllocations.Restart", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "Could not find task runner for task: foo")
+
+	// Restart all the tasks
+	req.TaskName = ""
+	require.Nil(msgpackrpc.CallWithCodec(codec, "ClientAllocations.Restart", req, &resp))
+	waitForTaskEvent(t, s, a.ID, "web", structs.TaskRestartSignal)
+}
+
+func TestClientAllocations_Restart_Local_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Start a server
+	s, root := TestACLServer(t, nil)
+	defer s.Shutdown()
+	codec := rpcClient(t, s)
+	testutil.WaitForLeader(t, s.RPC)
+
+	// Create a bad token
+	policyBad := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	tokenBad := mock.CreatePolicyAndToken(t, s.State(), 1005, "invalid", policyBad)
+
+	policyGood := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilitySubmitJob})
+	tokenGood := mock.CreatePolicyAndToken(t, s.State(), 1009, "valid2", policyGood)
+
+	cases := []struct {
+		Name          string
+		Token         string
+		ExpectedError string
+	}{
+		{
+			Name:          "bad token",
+			Token:         tokenBad.SecretID,
+			ExpectedError: structs.ErrPermissionDenied.Error(),
+		},
+		{
+			Name:          "good token",
+			Token:         tokenGood.SecretID,
+			ExpectedError: structs.ErrUnknownAllocationPrefix,
+		},
+		{
+			Name:          "root token",
+			Token:         root.SecretID,
+			ExpectedError: structs.ErrUnknownAllocationPrefix,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			req := &structs.AllocRestartRequest{
+				AllocID: uuid.Generate(),
+				QueryOptions: structs.QueryOptions{
+					AuthToken: c.Token,
+					Region:    "global",
+					Namespace: structs.DefaultNamespace,
+				},
+			}
+
+			var resp structs.GenericResponse
+			err := msgpackrpc.CallWithCodec(codec, "ClientAllocations.Restart", req, &resp)
+			require.NotNil(err)
+			require.Contains(err.Error(), c.ExpectedError)
+		})
+	}
+}
+
+func TestClientAllocations_Signal_Local(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Start a server and client
+	s := TestServer(t, nil)
+	defer s.Shutdown()
+	codec := rpcClient(t, s)
+	testutil.WaitForLeader(t, s.RPC)
+
+	c, cleanup := client.TestClient(t, func(c *config.Config) {
+		c.Servers = []string{s.config.RPCAddr.String()}
+	})
+	defer cleanup()
+
+	a := runningMockAlloc(t, s, c)
+
+	// Make the request without a signal
+	req := &structs.AllocSignalRequest{
+		AllocID:      a.ID,
+		Task:         "web",
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ClientAllocations.Signal", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "missing Signal")
+
+	// Signal the task
+	req.Signal = "SIGHUP"
+	require.Nil(msgpackrpc.CallWithCodec(codec, "ClientAllocations.Signal", req, &resp))
+	waitForTaskEvent(t, s, a.ID, "web", structs.TaskSignaling)
+}
@@ -0,0 +1,88 @@
+package nomad
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+)
+
+// ClientCSI is used to forward RPC requests to the CSI plugins running on the
+// targeted Nomad client. It is only called by servers orchestrating volumes.
+type ClientCSI struct {
+	srv    *Server
+	logger log.Logger
+}
+
+// ControllerAttachVolume is used to attach a volume to a node through the
+// controller plugin running on the targeted client
+func (a *ClientCSI) ControllerAttachVolume(args *cstructs.ClientCSIControllerAttachVolumeRequest,
+	reply *cstructs.ClientCSIControllerAttachVolumeResponse) error {
+	defer metrics.MeasureSince([]string{"nomad", "client_csi_controller", "attach_volume"}, time.Now())
+	return a.sendCSIRPC(args.ControllerNodeID, "ControllerAttachVolume", args, reply)
+}
+
+// ControllerDetachVolume is used to detach a volume from a node through the
+// controller plugin running on the targeted client
+func (a *ClientCSI) ControllerDetachVolume(args *cstructs.ClientCSIControllerDetachVolumeRequest,
+	reply *cstructs.ClientCSIControllerDetachVolumeResponse) error {
+	defer metrics.MeasureSince([]string{"nomad", "client_csi_controller", "detach_volume"}, time.Now())
+	return a.sendCSIRPC(args.ControllerNodeID, "ControllerDetachVolume", args, reply)
+}
+
+// ControllerCreateVolume is used to create a volume through the controller
+// plugin running on the targeted client
+func (a *ClientCSI) ControllerCreateVolume(args *cstructs.ClientCSIControllerCreateVolumeRequest,
+	reply *cstructs.ClientCSIControllerCreateVolumeResponse) error {
+	defer metrics.MeasureSince([]string{"nomad", "client_csi_controller", "create_volume"}, time.Now())
+	return a.sendCSIRPC(args.ControllerNodeID, "ControllerCreateVolume", args, reply)
+}
+
+// ControllerDeleteVolume is used to delete a volume through the controller
+// plugin running on the targeted client
+func (a *ClientCSI) ControllerDeleteVolume(args *cstructs.ClientCSIControllerDeleteVolumeRequest,
+	reply *cstructs.ClientCSIControllerDeleteVolumeResponse) error {
+	defer metrics.MeasureSince([]string{"nomad", "client_csi_controller", "delete_volume"}, time.Now())
+	return a.sendCSIRPC(args.ControllerNodeID, "ControllerDeleteVolume", args, reply)
+}
+
+// NodeDetachVolume is used to unmount a volume from an allocation through the
+// node plugin running on the targeted client
+func (a *ClientCSI) NodeDetachVolume(args *cstructs.ClientCSINodeDetachVolumeRequest,
+	reply *cstructs.ClientCSINodeDetachVolumeResponse) error {
+	defer metrics.MeasureSince([]string{"nomad", "client_csi_node", "detach_volume"}, time.Now())
+	return a.sendCSIRPC(args.NodeID, "NodeDetachVolume", args, reply)
+}
+
+// sendCSIRPC makes the RPC to the client's CSI endpoint, forwarding it to the
+// server connected to the client if needed
+func (a *ClientCSI) sendCSIRPC(nodeID, method string, args, reply interface{}) error {
+	if nodeID == "" {
+		return errors.New("missing NodeID")
+	}
+
+	// Make sure Node is valid and new enough to support RPC
+	snap, err := a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	if _, err := getNodeForRpc(snap, nodeID); err != nil {
+		return err
+	}
+
+	// Get the connection to the client
+	state, ok := a.srv.getNodeConn(nodeID)
+	if !ok {
+		return findNodeConnAndForward(a.srv, nodeID, "ClientCSI."+method, args, reply)
+	}
+
+	// Make the RPC
+	if err := NodeRpc(state.Session, "CSI."+method, args, reply); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
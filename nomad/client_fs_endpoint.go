@@ -28,6 +28,7 @@ type FileSystem struct {
 func (f *FileSystem) register() {
 	f.srv.streamingRpcs.Register("FileSystem.Logs", f.logs)
 	f.srv.streamingRpcs.Register("FileSystem.Stream", f.stream)
+	f.srv.streamingRpcs.Register("FileSystem.Archive", f.archive)
 }
 
 // handleStreamResultError is a helper for sending an error with a potential
@@ -109,9 +110,10 @@ func (f *FileSystem) List(args *cstructs.FsListRequest, reply *cstructs.FsListRe
 	defer metrics.MeasureSince([]string{"nomad", "file_system", "list"}, time.Now())
 
 	// Check filesystem read permissions
-	if aclObj, err := f.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := f.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.Namespace, acl.NamespaceCapabilityReadFS) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.Namespace, acl.NamespaceCapabilityReadFS) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -133,6 +135,9 @@ func (f *FileSystem) List(args *cstructs.FsListRequest, reply *cstructs.FsListRe
 	if alloc == nil {
 		return structs.NewErrUnknownAllocation(args.AllocID)
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadFS) {
+		return structs.ErrPermissionDenied
+	}
 
 	// Make sure Node is valid and new enough to support RPC
 	_, err = getNodeForRpc(snap, alloc.NodeID)
@@ -164,9 +169,10 @@ func (f *FileSystem) Stat(args *cstructs.FsStatRequest, reply *cstructs.FsStatRe
 	defer metrics.MeasureSince([]string{"nomad", "file_system", "stat"}, time.Now())
 
 	// Check filesystem read permissions
-	if aclObj, err := f.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := f.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.Namespace, acl.NamespaceCapabilityReadFS) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.Namespace, acl.NamespaceCapabilityReadFS) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -188,6 +194,9 @@ func (f *FileSystem) Stat(args *cstructs.FsStatRequest, reply *cstructs.FsStatRe
 	if alloc == nil {
 		return structs.NewErrUnknownAllocation(args.AllocID)
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadFS) {
+		return structs.ErrPermissionDenied
+	}
 
 	// Make sure Node is valid and new enough to support RPC
 	_, err = getNodeForRpc(snap, alloc.NodeID)
@@ -229,10 +238,11 @@ func (f *FileSystem) stream(conn io.ReadWriteCloser) {
 	}
 
 	// Check node read permissions
-	if aclObj, err := f.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := f.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		f.handleStreamResultError(err, nil, encoder)
 		return
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.Namespace, acl.NamespaceCapabilityReadFS) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.Namespace, acl.NamespaceCapabilityReadFS) {
 		f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
 		return
 	}
@@ -259,6 +269,10 @@ func (f *FileSystem) stream(conn io.ReadWriteCloser) {
 		f.handleStreamResultError(structs.NewErrUnknownAllocation(args.AllocID), helper.Int64ToPtr(404), encoder)
 		return
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadFS) {
+		f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
+		return
+	}
 	nodeID := alloc.NodeID
 
 	// Make sure Node is valid and new enough to support RPC
@@ -324,6 +338,130 @@ func (f *FileSystem) stream(conn io.ReadWriteCloser) {
 	return
 }
 
+// archive is used to stream a tar archive of a file or directory in an
+// allocation's directory.
+func (f *FileSystem) archive(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	defer metrics.MeasureSince([]string{"nomad", "file_system", "archive"}, time.Now())
+
+	// Decode the arguments
+	var args cstructs.FsArchiveRequest
+	decoder := codec.NewDecoder(conn, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(conn, structs.MsgpackHandle)
+
+	if err := decoder.Decode(&args); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+
+	// Check if we need to forward to a different region
+	if r := args.RequestRegion(); r != f.srv.Region() {
+		f.forwardRegionStreamingRpc(conn, encoder, &args, "FileSystem.Archive",
+			args.AllocID, &args.QueryOptions)
+		return
+	}
+
+	// Check node read permissions
+	aclObj, err := f.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		f.handleStreamResultError(err, nil, encoder)
+		return
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.Namespace, acl.NamespaceCapabilityReadFS) {
+		f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
+		return
+	}
+
+	// Verify the arguments.
+	if args.AllocID == "" {
+		f.handleStreamResultError(errors.New("missing AllocID"), helper.Int64ToPtr(400), encoder)
+		return
+	}
+
+	// Retrieve the allocation
+	snap, err := f.srv.State().Snapshot()
+	if err != nil {
+		f.handleStreamResultError(err, nil, encoder)
+		return
+	}
+
+	alloc, err := snap.AllocByID(nil, args.AllocID)
+	if err != nil {
+		f.handleStreamResultError(err, nil, encoder)
+		return
+	}
+	if alloc == nil {
+		f.handleStreamResultError(structs.NewErrUnknownAllocation(args.AllocID), helper.Int64ToPtr(404), encoder)
+		return
+	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadFS) {
+		f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
+		return
+	}
+	nodeID := alloc.NodeID
+
+	// Make sure Node is valid and new enough to support RPC
+	node, err := snap.NodeByID(nil, nodeID)
+	if err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+
+	if node == nil {
+		err := fmt.Errorf("Unknown node %q", nodeID)
+		f.handleStreamResultError(err, helper.Int64ToPtr(400), encoder)
+		return
+	}
+
+	if err := nodeSupportsRpc(node); err != nil {
+		f.handleStreamResultError(err, helper.Int64ToPtr(400), encoder)
+		return
+	}
+
+	// Get the connection to the client either by forwarding to another server
+	// or creating a direct stream
+	var clientConn net.Conn
+	state, ok := f.srv.getNodeConn(nodeID)
+	if !ok {
+		// Determine the Server that has a connection to the node.
+		srv, err := f.srv.serverWithNodeConn(nodeID, f.srv.Region())
+		if err != nil {
+			var code *int64
+			if structs.IsErrNoNodeConn(err) {
+				code = helper.Int64ToPtr(404)
+			}
+			f.handleStreamResultError(err, code, encoder)
+			return
+		}
+
+		// Get a connection to the server
+		conn, err := f.srv.streamingRpc(srv, "FileSystem.Archive")
+		if err != nil {
+			f.handleStreamResultError(err, nil, encoder)
+			return
+		}
+
+		clientConn = conn
+	} else {
+		stream, err := NodeStreamingRpc(state.Session, "FileSystem.Archive")
+		if err != nil {
+			f.handleStreamResultError(err, nil, encoder)
+			return
+		}
+		clientConn = stream
+	}
+	defer clientConn.Close()
+
+	// Send the request.
+	outEncoder := codec.NewEncoder(clientConn, structs.MsgpackHandle)
+	if err := outEncoder.Encode(args); err != nil {
+		f.handleStreamResultError(err, nil, encoder)
+		return
+	}
+
+	structs.Bridge(conn, clientConn)
+	return
+}
+
 // logs is used to access an task's logs for a given allocation
 func (f *FileSystem) logs(conn io.ReadWriteCloser) {
 	defer conn.Close()
@@ -347,12 +485,13 @@ func (f *FileSystem) logs(conn io.ReadWriteCloser) {
 	}
 
 	// Check node read permissions
-	if aclObj, err := f.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := f.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		f.handleStreamResultError(err, nil, encoder)
 		return
 	} else if aclObj != nil {
-		readfs := aclObj.AllowNsOp(args.QueryOptions.Namespace, acl.NamespaceCapabilityReadFS)
-		logs := aclObj.AllowNsOp(args.QueryOptions.Namespace, acl.NamespaceCapabilityReadLogs)
+		readfs := aclObj.AllowNamespaceJobsOperation(args.QueryOptions.Namespace, acl.NamespaceCapabilityReadFS)
+		logs := aclObj.AllowNamespaceJobsOperation(args.QueryOptions.Namespace, acl.NamespaceCapabilityReadLogs)
 		if !readfs && !logs {
 			f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
 			return
@@ -381,6 +520,11 @@ func (f *FileSystem) logs(conn io.ReadWriteCloser) {
 		f.handleStreamResultError(structs.NewErrUnknownAllocation(args.AllocID), helper.Int64ToPtr(404), encoder)
 		return
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadFS) &&
+		!aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadLogs) {
+		f.handleStreamResultError(structs.ErrPermissionDenied, nil, encoder)
+		return
+	}
 	nodeID := alloc.NodeID
 
 	// Make sure Node is valid and new enough to support RPC
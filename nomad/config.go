@@ -12,6 +12,7 @@ import (
 
 	"github.com/hashicorp/memberlist"
 	"github.com/hashicorp/nomad/helper/pluginutils/loader"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/nomad/structs/config"
@@ -75,6 +76,10 @@ type Config struct {
 	// logs will go to stderr.
 	LogOutput io.Writer
 
+	// DecisionLogOutput is where the schedulers write a JSON record for each
+	// placement decision. Decisions aren't recorded if it is not set.
+	DecisionLogOutput io.Writer
+
 	// Logger is the logger used by the server.
 	Logger log.Logger
 
@@ -92,6 +97,11 @@ type Config struct {
 	// reachable
 	ClientRPCAdvertise *net.TCPAddr
 
+	// ClientRPCAdvertiseSegments maps network segments to the address
+	// advertised to the client nodes of that segment for the RPC endpoint.
+	// Clients on a segment only connect to servers through these addresses.
+	ClientRPCAdvertiseSegments map[string]*net.TCPAddr
+
 	// ServerRPCAdvertise is the address that is advertised to other servers for
 	// the RPC endpoint. This can differ from the RPC address, if for example
 	// the RPCAddr is unspecified "0.0.0.0:4646", but this address must be
@@ -184,6 +194,14 @@ type Config struct {
 	// for GC. This gives users some time to view terminal deployments.
 	DeploymentGCThreshold time.Duration
 
+	// ACLTokenExpirationGCInterval is how often we dispatch a job to GC
+	// expired ACL tokens.
+	ACLTokenExpirationGCInterval time.Duration
+
+	// CSIVolumeClaimGCInterval is how often we dispatch a job to release the
+	// CSI volume claims of terminal allocations
+	CSIVolumeClaimGCInterval time.Duration
+
 	// EvalNackTimeout controls how long we allow a sub-scheduler to
 	// work on an evaluation before we consider it failed and Nack it.
 	// This allows that evaluation to be handed to another sub-scheduler
@@ -234,6 +252,13 @@ type Config struct {
 	// as well as clock skew.
 	HeartbeatGrace time.Duration
 
+	// MissedHeartbeatGrace is the additional time given to nodes whose
+	// heartbeats expire while many other nodes are also missing heartbeats,
+	// before they are marked down. This avoids rescheduling the allocations
+	// of many nodes when the servers are briefly unavailable. Zero disables
+	// it.
+	MissedHeartbeatGrace time.Duration
+
 	// FailoverHeartbeatTTL is the TTL applied to heartbeats after
 	// a new leader is elected, since we no longer know the status
 	// of all the heartbeats.
@@ -245,6 +270,10 @@ type Config struct {
 	// VaultConfig is this Agent's Vault configuration
 	VaultConfig *config.VaultConfig
 
+	// VaultClusters are the configurations of the additional Vault clusters
+	// jobs can select, indexed by name
+	VaultClusters map[string]*config.VaultConfig
+
 	// RPCHoldTimeout is how long an RPC can be "held" before it is errored.
 	// This is used to paper over a loss of leadership by instead holding RPCs,
 	// so that the caller experiences a slow response rather than an error.
@@ -266,6 +295,50 @@ type Config struct {
 	// the Authoritative Region.
 	ReplicationToken string
 
+	// ACLTokenMinExpirationTTL and ACLTokenMaxExpirationTTL bound the
+	// expiration TTL that may be set when creating an ACL token.
+	ACLTokenMinExpirationTTL time.Duration
+	ACLTokenMaxExpirationTTL time.Duration
+
+	// VariablesEncryptionKey is the AES-256 key variables are encrypted with.
+	// It must be the same on all servers in the region. Variables are
+	// unavailable when it is not set.
+	VariablesEncryptionKey []byte
+
+	// EnableEventBroker controls whether the events of state changes are
+	// buffered and made available to event stream subscribers.
+	EnableEventBroker bool
+
+	// EventBufferSize is the number of raft logs whose events are buffered
+	// for replay by event stream subscribers.
+	EventBufferSize int
+
+	// JobNamespaceRateLimit is the number of job registrations and dispatches
+	// per second allowed in each namespace, and JobNamespaceRateBurst the
+	// number allowed at once. A zero rate disables the limit.
+	JobNamespaceRateLimit float64
+	JobNamespaceRateBurst int
+
+	// JobTokenRateLimit is the number of job registrations and dispatches per
+	// second allowed for each ACL token, and JobTokenRateBurst the number
+	// allowed at once. A zero rate disables the limit.
+	JobTokenRateLimit float64
+	JobTokenRateBurst int
+
+	// DeploymentWebhookURL is the URL the leader posts a notification to
+	// when a deployment fails its progress deadline. No notifications are
+	// sent if it is empty. DeploymentWebhookHMACKey signs the notifications
+	// if set, and DeploymentWebhookTimeout bounds each request.
+	DeploymentWebhookURL     string
+	DeploymentWebhookHMACKey string
+	DeploymentWebhookTimeout time.Duration
+
+	// JobAdmissionWebhooks and JobAdmissionRules are the admission
+	// controllers that validate, and may mutate, the jobs registered,
+	// planned and validated.
+	JobAdmissionWebhooks []*JobAdmissionWebhook
+	JobAdmissionRules    []*JobAdmissionRule
+
 	// SentinelGCInterval is the interval that we GC unused policies.
 	SentinelGCInterval time.Duration
 
@@ -288,6 +361,10 @@ type Config struct {
 	// displaying metrics for older versions, or to only show the new format
 	BackwardsCompatibleMetrics bool
 
+	// TracingConfig configures the export of the spans of RPCs and
+	// evaluations to an OpenTelemetry collector. Tracing is disabled if nil.
+	TracingConfig *tracing.Config
+
 	// AutopilotConfig is used to apply the initial autopilot config when
 	// bootstrapping.
 	AutopilotConfig *structs.AutopilotConfig
@@ -321,6 +398,15 @@ func (c *Config) CheckVersion() error {
 	return nil
 }
 
+// VaultConfigFor returns the configuration of the named Vault cluster, or nil
+// if the cluster isn't configured. An empty name selects the default cluster.
+func (c *Config) VaultConfigFor(cluster string) *config.VaultConfig {
+	if cluster == "" || cluster == config.DefaultVaultClusterName {
+		return c.VaultConfig
+	}
+	return c.VaultClusters[cluster]
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	hostname, err := os.Hostname()
@@ -350,6 +436,10 @@ func DefaultConfig() *Config {
 		NodeGCThreshold:                  24 * time.Hour,
 		DeploymentGCInterval:             5 * time.Minute,
 		DeploymentGCThreshold:            1 * time.Hour,
+		ACLTokenExpirationGCInterval:     5 * time.Minute,
+		CSIVolumeClaimGCInterval:         5 * time.Minute,
+		ACLTokenMinExpirationTTL:         1 * time.Minute,
+		ACLTokenMaxExpirationTTL:         24 * time.Hour,
 		EvalNackTimeout:                  60 * time.Second,
 		EvalDeliveryLimit:                3,
 		EvalNackInitialReenqueueDelay:    1 * time.Second,
@@ -366,6 +456,8 @@ func DefaultConfig() *Config {
 		StatsCollectionInterval:          1 * time.Minute,
 		TLSConfig:                        &config.TLSConfig{},
 		ReplicationBackoff:               30 * time.Second,
+		EnableEventBroker:                true,
+		EventBufferSize:                  100,
 		SentinelGCInterval:               30 * time.Second,
 		AutopilotConfig: &structs.AutopilotConfig{
 			CleanupDeadServers:      true,
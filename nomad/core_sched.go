@@ -49,6 +49,10 @@ func (c *CoreScheduler) Process(eval *structs.Evaluation) error {
 		return c.jobGC(eval)
 	case structs.CoreJobDeploymentGC:
 		return c.deploymentGC(eval)
+	case structs.CoreJobExpiredACLTokenGC:
+		return c.expiredACLTokenGC(eval)
+	case structs.CoreJobCSIVolumeClaimGC:
+		return c.csiVolumeClaimGC(eval)
 	case structs.CoreJobForceGC:
 		return c.forceGC(eval)
 	default:
@@ -67,12 +71,47 @@ func (c *CoreScheduler) forceGC(eval *structs.Evaluation) error {
 	if err := c.deploymentGC(eval); err != nil {
 		return err
 	}
+	if err := c.expiredACLTokenGC(eval); err != nil {
+		return err
+	}
+	if err := c.csiVolumeClaimGC(eval); err != nil {
+		return err
+	}
 
 	// Node GC must occur after the others to ensure the allocations are
 	// cleared.
 	return c.nodeGC(eval)
 }
 
+// gcThresholds returns the GC thresholds of the server's agent config,
+// overridden by the thresholds operators set at runtime.
+func (c *CoreScheduler) gcThresholds() *structs.GCConfiguration {
+	thresholds := &structs.GCConfiguration{
+		EvalGCThreshold:       c.srv.config.EvalGCThreshold,
+		JobGCThreshold:        c.srv.config.JobGCThreshold,
+		DeploymentGCThreshold: c.srv.config.DeploymentGCThreshold,
+	}
+
+	_, config, err := c.snap.GCConfig()
+	if err != nil {
+		c.logger.Warn("failed to read GC config, using agent config", "error", err)
+		return thresholds
+	} else if config == nil {
+		return thresholds
+	}
+
+	if config.EvalGCThreshold != 0 {
+		thresholds.EvalGCThreshold = config.EvalGCThreshold
+	}
+	if config.JobGCThreshold != 0 {
+		thresholds.JobGCThreshold = config.JobGCThreshold
+	}
+	if config.DeploymentGCThreshold != 0 {
+		thresholds.DeploymentGCThreshold = config.DeploymentGCThreshold
+	}
+	return thresholds
+}
+
 // jobGC is used to garbage collect eligible jobs.
 func (c *CoreScheduler) jobGC(eval *structs.Evaluation) error {
 	// Get all the jobs eligible for garbage collection.
@@ -91,10 +130,11 @@ func (c *CoreScheduler) jobGC(eval *structs.Evaluation) error {
 	} else {
 		// Get the time table to calculate GC cutoffs.
 		tt := c.srv.fsm.TimeTable()
-		cutoff := time.Now().UTC().Add(-1 * c.srv.config.JobGCThreshold)
+		threshold := c.gcThresholds().JobGCThreshold
+		cutoff := time.Now().UTC().Add(-1 * threshold)
 		oldThreshold = tt.NearestIndex(cutoff)
 		c.logger.Debug("job GC scanning before cutoff index",
-			"index", oldThreshold, "job_gc_threshold", c.srv.config.JobGCThreshold)
+			"index", oldThreshold, "job_gc_threshold", threshold)
 	}
 
 	// Collect the allocations, evaluations and jobs to GC
@@ -230,10 +270,11 @@ func (c *CoreScheduler) evalGC(eval *structs.Evaluation) error {
 		// time table.  This is a rough mapping of a time to the
 		// Raft index it belongs to.
 		tt := c.srv.fsm.TimeTable()
-		cutoff := time.Now().UTC().Add(-1 * c.srv.config.EvalGCThreshold)
+		threshold := c.gcThresholds().EvalGCThreshold
+		cutoff := time.Now().UTC().Add(-1 * threshold)
 		oldThreshold = tt.NearestIndex(cutoff)
 		c.logger.Debug("eval GC scanning before cutoff index",
-			"index", oldThreshold, "eval_gc_threshold", c.srv.config.EvalGCThreshold)
+			"index", oldThreshold, "eval_gc_threshold", threshold)
 	}
 
 	// Collect the allocations and evaluations to GC
@@ -521,10 +562,11 @@ func (c *CoreScheduler) deploymentGC(eval *structs.Evaluation) error {
 		// time table.  This is a rough mapping of a time to the
 		// Raft index it belongs to.
 		tt := c.srv.fsm.TimeTable()
-		cutoff := time.Now().UTC().Add(-1 * c.srv.config.DeploymentGCThreshold)
+		threshold := c.gcThresholds().DeploymentGCThreshold
+		cutoff := time.Now().UTC().Add(-1 * threshold)
 		oldThreshold = tt.NearestIndex(cutoff)
 		c.logger.Debug("deployment GC scanning before cutoff index",
-			"index", oldThreshold, "deployment_gc_threshold", c.srv.config.DeploymentGCThreshold)
+			"index", oldThreshold, "deployment_gc_threshold", threshold)
 	}
 
 	// Collect the deployments to GC
@@ -614,6 +656,149 @@ func (c *CoreScheduler) partitionDeploymentReap(deployments []string) []*structs
 	return requests
 }
 
+// expiredACLTokenGC is used to garbage collect expired ACL tokens.
+func (c *CoreScheduler) expiredACLTokenGC(eval *structs.Evaluation) error {
+	if !c.srv.config.ACLEnabled {
+		return nil
+	}
+
+	ws := memdb.NewWatchSet()
+	iter, err := c.snap.ACLTokens(ws)
+	if err != nil {
+		return err
+	}
+
+	// Global tokens are only deleted by the authoritative region, the other
+	// regions remove them through replication.
+	authoritative := c.srv.config.Region == c.srv.config.AuthoritativeRegion
+
+	// Collect the expired tokens to GC
+	var gcLocal, gcGlobal []string
+	now := time.Now()
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		token := raw.(*structs.ACLToken)
+		if !token.IsExpired(now) {
+			continue
+		}
+		if !token.Global {
+			gcLocal = append(gcLocal, token.AccessorID)
+		} else if authoritative {
+			gcGlobal = append(gcGlobal, token.AccessorID)
+		}
+	}
+
+	// Fast-path the nothing case
+	if len(gcLocal)+len(gcGlobal) == 0 {
+		return nil
+	}
+	c.logger.Debug("expired ACL token GC found eligible tokens",
+		"local", len(gcLocal), "global", len(gcGlobal))
+
+	// Local and global tokens can not be deleted in the same request
+	for _, tokens := range [][]string{gcLocal, gcGlobal} {
+		if len(tokens) == 0 {
+			continue
+		}
+		if err := c.aclTokenReap(tokens, eval.LeaderACL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aclTokenReap contacts the leader and issues a reap on the passed tokens.
+func (c *CoreScheduler) aclTokenReap(tokens []string, leaderACL string) error {
+	for len(tokens) > 0 {
+		batch := tokens
+		if len(batch) > maxIdsPerReap {
+			batch = batch[:maxIdsPerReap]
+		}
+		tokens = tokens[len(batch):]
+
+		req := &structs.ACLTokenDeleteRequest{
+			AccessorIDs: batch,
+			WriteRequest: structs.WriteRequest{
+				Region:    c.srv.config.Region,
+				AuthToken: leaderACL,
+			},
+		}
+		var resp structs.GenericResponse
+		if err := c.srv.RPC("ACL.DeleteTokens", req, &resp); err != nil {
+			c.logger.Error("ACL token reap failed", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// csiVolumeClaimGC is used to release the CSI volume claims of terminal or
+// garbage collected allocations.
+func (c *CoreScheduler) csiVolumeClaimGC(eval *structs.Evaluation) error {
+	ws := memdb.NewWatchSet()
+	iter, err := c.snap.CSIVolumes(ws)
+	if err != nil {
+		return err
+	}
+
+	// Collect the claims to release, including claims whose release failed
+	var requests []*structs.CSIVolumeUnpublishRequest
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		volume := raw.(*structs.CSIVolume)
+
+		release := func(allocID string) {
+			requests = append(requests, &structs.CSIVolumeUnpublishRequest{
+				VolumeID:     volume.ID,
+				AllocationID: allocID,
+				WriteRequest: structs.WriteRequest{
+					Region:    c.srv.config.Region,
+					Namespace: volume.Namespace,
+					AuthToken: eval.LeaderACL,
+				},
+			})
+		}
+
+		for _, claims := range []map[string]*structs.CSIVolumeClaim{volume.ReadClaims, volume.WriteClaims} {
+			for allocID := range claims {
+				alloc, err := c.snap.AllocByID(ws, allocID)
+				if err != nil {
+					return err
+				}
+				if alloc == nil || alloc.ClientTerminalStatus() {
+					release(allocID)
+				}
+			}
+		}
+		for allocID := range volume.PastClaims {
+			release(allocID)
+		}
+	}
+
+	// Fast-path the nothing case
+	if len(requests) == 0 {
+		return nil
+	}
+	c.logger.Debug("CSI volume claim GC found eligible claims", "claims", len(requests))
+
+	// Release every claim we can. Failed releases are resumed by the next GC
+	// so they do not fail the evaluation.
+	for _, req := range requests {
+		var resp structs.GenericResponse
+		if err := c.srv.RPC("CSIVolume.Unpublish", req, &resp); err != nil {
+			c.logger.Error("CSI volume claim release failed", "volume_id", req.VolumeID,
+				"alloc_id", req.AllocationID, "error", err)
+		}
+	}
+	return nil
+}
+
 // allocGCEligible returns if the allocation is eligible to be garbage collected
 // according to its terminal status and its reschedule trackers
 func allocGCEligible(a *structs.Allocation, job *structs.Job, gcTime time.Time, thresholdIndex uint64) bool {
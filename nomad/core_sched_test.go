@@ -107,6 +107,49 @@ func TestCoreScheduler_EvalGC(t *testing.T) {
 }
 
 // Tests GC behavior on allocations being rescheduled
+func TestCoreScheduler_EvalGC_RuntimeThreshold(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	// COMPAT Remove in 0.6: Reset the FSM time table since we reconcile which sets index 0
+	s1.fsm.timetable.table = make([]TimeTableEntry, 1, 10)
+
+	// Insert "dead" eval
+	state := s1.fsm.State()
+	eval := mock.Eval()
+	eval.Status = structs.EvalStatusFailed
+	require.NoError(state.UpsertJobSummary(999, mock.JobSummary(eval.JobID)))
+	require.NoError(state.UpsertEvals(1000, []*structs.Evaluation{eval}))
+
+	// The eval is younger than the agent's threshold
+	tt := s1.fsm.TimeTable()
+	tt.Witness(2000, time.Now().UTC().Add(-10*time.Minute))
+
+	snap, err := state.Snapshot()
+	require.NoError(err)
+	core := NewCoreScheduler(s1, snap)
+	require.NoError(core.Process(s1.coreJobEval(structs.CoreJobEvalGC, 2000)))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.EvalByID(ws, eval.ID)
+	require.NoError(err)
+	require.NotNil(out)
+
+	// Lowering the threshold at runtime makes it eligible
+	require.NoError(state.GCSetConfig(2001, &structs.GCConfiguration{EvalGCThreshold: 5 * time.Minute}))
+	snap, err = state.Snapshot()
+	require.NoError(err)
+	core = NewCoreScheduler(s1, snap)
+	require.NoError(core.Process(s1.coreJobEval(structs.CoreJobEvalGC, 2001)))
+
+	out, err = state.EvalByID(ws, eval.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
 func TestCoreScheduler_EvalGC_ReschedulingAllocs(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -1771,6 +1814,87 @@ func TestCoreScheduler_DeploymentGC(t *testing.T) {
 	assert.NotNil(out3, "Terminal Deployment With Allocs")
 }
 
+func TestCoreScheduler_ExpiredACLTokenGC(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Insert an expired and an unexpired token
+	state := s1.fsm.State()
+	expired, valid := mock.ACLToken(), mock.ACLToken()
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	expired.ExpirationTime = &past
+	valid.ExpirationTime = &future
+	require.NoError(state.UpsertACLTokens(1000, []*structs.ACLToken{expired, valid}))
+
+	// Create a core scheduler
+	snap, err := state.Snapshot()
+	require.NoError(err)
+	core := NewCoreScheduler(s1, snap)
+
+	// Attempt the GC
+	gc := s1.coreJobEval(structs.CoreJobExpiredACLTokenGC, 2000)
+	require.NoError(core.Process(gc))
+
+	// Only the expired token should be gone
+	out, err := state.ACLTokenByAccessorID(nil, expired.AccessorID)
+	require.NoError(err)
+	require.Nil(out)
+	out, err = state.ACLTokenByAccessorID(nil, valid.AccessorID)
+	require.NoError(err)
+	require.NotNil(out)
+	out, err = state.ACLTokenByAccessorID(nil, root.AccessorID)
+	require.NoError(err)
+	require.NotNil(out)
+}
+
+func TestCoreScheduler_CSIVolumeClaimGC(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, _ := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Claim a volume for a running and a stopped allocation on a down node
+	state := s1.fsm.State()
+	node := mock.CSINode("ebs")
+	require.NoError(state.UpsertNode(1000, node))
+	vol := mock.CSIVolume("ebs")
+	vol.AccessMode = structs.CSIVolumeAccessModeMultiNodeMultiWriter
+	require.NoError(state.CSIVolumeRegister(1001, []*structs.CSIVolume{vol}))
+
+	running, stopped := mock.Alloc(), mock.Alloc()
+	running.NodeID, stopped.NodeID = node.ID, node.ID
+	require.NoError(state.UpsertAllocs(1002, []*structs.Allocation{running, stopped}))
+	for _, alloc := range []*structs.Allocation{running, stopped} {
+		claim := &structs.CSIVolumeClaim{AllocationID: alloc.ID, NodeID: node.ID, Mode: structs.CSIVolumeClaimWrite}
+		require.NoError(state.CSIVolumeClaim(1003, vol.Namespace, vol.ID, claim))
+	}
+
+	stopped = stopped.Copy()
+	stopped.ClientStatus = structs.AllocClientStatusComplete
+	require.NoError(state.UpdateAllocsFromClient(1004, []*structs.Allocation{stopped}))
+	require.NoError(state.UpdateNodeStatus(1005, node.ID, structs.NodeStatusDown, nil))
+
+	// Create a core scheduler
+	snap, err := state.Snapshot()
+	require.NoError(err)
+	core := NewCoreScheduler(s1, snap)
+
+	// Attempt the GC
+	gc := s1.coreJobEval(structs.CoreJobCSIVolumeClaimGC, 2000)
+	require.NoError(core.Process(gc))
+
+	// Only the claim of the stopped allocation should be released
+	out, err := state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Len(out.WriteClaims, 1)
+	require.Contains(out.WriteClaims, running.ID)
+	require.Empty(out.PastClaims)
+}
+
 func TestCoreScheduler_DeploymentGC_Force(t *testing.T) {
 	t.Parallel()
 	for _, withAcl := range []bool{false, true} {
@@ -0,0 +1,801 @@
+package nomad
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	memdb "github.com/hashicorp/go-memdb"
+	multierror "github.com/hashicorp/go-multierror"
+
+	"github.com/hashicorp/nomad/acl"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// CSIVolume endpoint is used to manage CSI volumes and their claims
+type CSIVolume struct {
+	srv    *Server
+	logger log.Logger
+}
+
+// List is used to list the CSI volumes in a namespace
+func (v *CSIVolume) List(args *structs.CSIVolumeListRequest, reply *structs.CSIVolumeListResponse) error {
+	if done, err := v.srv.forward("CSIVolume.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "list"}, time.Now())
+
+	// Check namespace csi-list-volume permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityCSIListVolume) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			var iter memdb.ResultIterator
+			var err error
+			if args.PluginID != "" {
+				iter, err = state.CSIVolumesByPluginID(ws, args.PluginID)
+			} else {
+				iter, err = state.CSIVolumes(ws)
+			}
+			if err != nil {
+				return err
+			}
+
+			volumes := []*structs.CSIVolumeListStub{}
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				volume := raw.(*structs.CSIVolume)
+				if volume.Namespace != args.RequestNamespace() {
+					continue
+				}
+				if args.NodeID != "" && volume.ClaimsOnNode(args.NodeID, "") == 0 {
+					continue
+				}
+
+				volume, err := state.CSIVolumeDenormalizePlugins(ws, volume)
+				if err != nil {
+					return err
+				}
+				volumes = append(volumes, volume.Stub())
+			}
+			reply.Volumes = volumes
+
+			// The health of the volumes is derived from their plugins so use
+			// the last index that affected either table
+			index, err := csiIndex(state)
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			v.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return v.srv.blockingRPC(&opts)
+}
+
+// Get is used to get a single CSI volume
+func (v *CSIVolume) Get(args *structs.CSIVolumeGetRequest, reply *structs.CSIVolumeGetResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Get", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "get"}, time.Now())
+
+	// Check namespace csi-read-volume permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityCSIReadVolume) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.ID == "" {
+		return fmt.Errorf("missing volume ID")
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			volume, err := state.CSIVolumeByID(ws, args.RequestNamespace(), args.ID)
+			if err != nil {
+				return err
+			}
+			reply.Volume = volume.Sanitize()
+
+			index, err := csiIndex(state)
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			v.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return v.srv.blockingRPC(&opts)
+}
+
+// Register is used to register CSI volumes that already exist in the storage
+// provider
+func (v *CSIVolume) Register(args *structs.CSIVolumeRegisterRequest, reply *structs.GenericResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Register", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "register"}, time.Now())
+
+	// Check namespace csi-write-volume permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityCSIWriteVolume) {
+		return structs.ErrPermissionDenied
+	}
+
+	if len(args.Volumes) == 0 {
+		return fmt.Errorf("must specify at least one volume")
+	}
+
+	for _, volume := range args.Volumes {
+		volume.Namespace = args.RequestNamespace()
+		if err := v.validateVolume(volume); err != nil {
+			return err
+		}
+		if volume.ExternalID == "" {
+			return fmt.Errorf("volume %q: missing external ID", volume.ID)
+		}
+	}
+
+	_, index, err := v.srv.raftApply(structs.CSIVolumeRegisterRequestType, args)
+	if err != nil {
+		v.logger.Error("register failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// Deregister is used to deregister CSI volumes without deleting them from
+// the storage provider
+func (v *CSIVolume) Deregister(args *structs.CSIVolumeDeregisterRequest, reply *structs.GenericResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Deregister", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "deregister"}, time.Now())
+
+	// Check namespace csi-write-volume permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityCSIWriteVolume) {
+		return structs.ErrPermissionDenied
+	}
+
+	if len(args.VolumeIDs) == 0 {
+		return fmt.Errorf("must specify at least one volume ID")
+	}
+
+	_, index, err := v.srv.raftApply(structs.CSIVolumeDeregisterRequestType, args)
+	if err != nil {
+		v.logger.Error("deregister failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// Create is used to create volumes through their controller plugin and
+// register them
+func (v *CSIVolume) Create(args *structs.CSIVolumeCreateRequest, reply *structs.CSIVolumeCreateResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Create", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "create"}, time.Now())
+
+	// Check namespace csi-write-volume permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityCSIWriteVolume) {
+		return structs.ErrPermissionDenied
+	}
+
+	if len(args.Volumes) == 0 {
+		return fmt.Errorf("must specify at least one volume")
+	}
+
+	snap, err := v.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	plugins := make(map[string]*structs.CSIPlugin, len(args.Volumes))
+	for _, volume := range args.Volumes {
+		volume.Namespace = args.RequestNamespace()
+		if err := v.validateVolume(volume); err != nil {
+			return err
+		}
+
+		existing, err := snap.CSIVolumeByID(nil, volume.Namespace, volume.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("volume %q already exists", volume.ID)
+		}
+
+		plugin, err := snap.CSIPluginByID(nil, volume.PluginID)
+		if err != nil {
+			return err
+		}
+		plugins[volume.ID] = plugin
+	}
+
+	// Create the volumes in the storage provider before registering them
+	for _, volume := range args.Volumes {
+		req := &cstructs.ClientCSIControllerCreateVolumeRequest{
+			Name:           volume.Name,
+			CapacityMin:    volume.CapacityMin,
+			CapacityMax:    volume.CapacityMax,
+			AttachmentMode: volume.AttachmentMode,
+			AccessMode:     volume.AccessMode,
+			Parameters:     volume.Parameters,
+			Secrets:        volume.Secrets,
+		}
+		if req.Name == "" {
+			req.Name = volume.ID
+		}
+		var resp cstructs.ClientCSIControllerCreateVolumeResponse
+		err := v.controllerRPC(plugins[volume.ID], "ControllerCreateVolume", &req.CSIControllerQuery, req, &resp,
+			func(info *structs.CSIInfo) bool {
+				return info.ControllerInfo != nil && info.ControllerInfo.SupportsCreateDelete
+			})
+		if err != nil {
+			return fmt.Errorf("volume %q: %v", volume.ID, err)
+		}
+
+		volume.ExternalID = resp.ExternalVolumeID
+		volume.Capacity = resp.CapacityBytes
+		volume.Context = resp.VolumeContext
+	}
+
+	req := &structs.CSIVolumeRegisterRequest{
+		Volumes:      args.Volumes,
+		WriteRequest: args.WriteRequest,
+	}
+	_, index, err := v.srv.raftApply(structs.CSIVolumeRegisterRequestType, req)
+	if err != nil {
+		v.logger.Error("register created volumes failed", "error", err)
+		return err
+	}
+
+	reply.Volumes = make([]*structs.CSIVolume, 0, len(args.Volumes))
+	for _, volume := range args.Volumes {
+		reply.Volumes = append(reply.Volumes, volume.Sanitize())
+	}
+	reply.Index = index
+	return nil
+}
+
+// Delete is used to delete unclaimed volumes through their controller plugin
+// and deregister them
+func (v *CSIVolume) Delete(args *structs.CSIVolumeDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Delete", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "delete"}, time.Now())
+
+	// Check namespace csi-write-volume permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityCSIWriteVolume) {
+		return structs.ErrPermissionDenied
+	}
+
+	if len(args.VolumeIDs) == 0 {
+		return fmt.Errorf("must specify at least one volume ID")
+	}
+
+	snap, err := v.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args.VolumeIDs {
+		volume, err := snap.CSIVolumeByID(nil, args.RequestNamespace(), id)
+		if err != nil {
+			return err
+		}
+		if volume == nil {
+			return fmt.Errorf("volume %q not found", id)
+		}
+		if volume.InUse() {
+			return fmt.Errorf("volume %q is in use", id)
+		}
+
+		plugin, err := snap.CSIPluginByID(nil, volume.PluginID)
+		if err != nil {
+			return err
+		}
+
+		req := &cstructs.ClientCSIControllerDeleteVolumeRequest{
+			ExternalVolumeID: volume.ExternalID,
+			Secrets:          volume.Secrets,
+		}
+		var resp cstructs.ClientCSIControllerDeleteVolumeResponse
+		err = v.controllerRPC(plugin, "ControllerDeleteVolume", &req.CSIControllerQuery, req, &resp,
+			func(info *structs.CSIInfo) bool {
+				return info.ControllerInfo != nil && info.ControllerInfo.SupportsCreateDelete
+			})
+		if err != nil {
+			return fmt.Errorf("volume %q: %v", id, err)
+		}
+	}
+
+	req := &structs.CSIVolumeDeregisterRequest{
+		VolumeIDs:    args.VolumeIDs,
+		WriteRequest: args.WriteRequest,
+	}
+	_, index, err := v.srv.raftApply(structs.CSIVolumeDeregisterRequestType, req)
+	if err != nil {
+		v.logger.Error("deregister deleted volumes failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// Claim is used by clients to claim a volume for an allocation. Volumes that
+// require a controller are attached to the allocation's node before the claim
+// is recorded.
+func (v *CSIVolume) Claim(args *structs.CSIVolumeClaimRequest, reply *structs.CSIVolumeClaimResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Claim", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "claim"}, time.Now())
+
+	if err := v.authorizeMount(args.AuthToken, args.RequestNamespace(), args.NodeID); err != nil {
+		return err
+	}
+
+	if args.VolumeID == "" {
+		return fmt.Errorf("missing volume ID")
+	}
+	if args.AllocationID == "" {
+		return fmt.Errorf("missing allocation ID")
+	}
+	if args.Claim != structs.CSIVolumeClaimRead && args.Claim != structs.CSIVolumeClaimWrite {
+		return fmt.Errorf("invalid claim mode %q", args.Claim)
+	}
+
+	snap, err := v.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	volume, err := snap.CSIVolumeByID(nil, args.RequestNamespace(), args.VolumeID)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", args.VolumeID)
+	}
+	if !volume.Schedulable {
+		return fmt.Errorf("volume %q is not schedulable: its plugin is unhealthy", args.VolumeID)
+	}
+
+	alloc, err := snap.AllocByID(nil, args.AllocationID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil {
+		return structs.NewErrUnknownAllocation(args.AllocationID)
+	}
+	args.NodeID = alloc.NodeID
+
+	node, err := snap.NodeByID(nil, alloc.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("unknown node %q", alloc.NodeID)
+	}
+	info, ok := node.CSINodePlugins[volume.PluginID]
+	if !ok || info.NodeInfo == nil {
+		return fmt.Errorf("node %q is not running plugin %q", node.ID, volume.PluginID)
+	}
+	args.ExternalNodeID = info.NodeInfo.ID
+	args.State = structs.CSIVolumeClaimStateTaken
+
+	// Check the claim can be made before attaching the volume
+	if err := volume.Copy().Claim(args.ToClaim()); err != nil {
+		return err
+	}
+
+	if volume.ControllerRequired {
+		plugin, err := snap.CSIPluginByID(nil, volume.PluginID)
+		if err != nil {
+			return err
+		}
+
+		req := &cstructs.ClientCSIControllerAttachVolumeRequest{
+			VolumeID:        volume.ExternalID,
+			ClientCSINodeID: args.ExternalNodeID,
+			AttachmentMode:  volume.AttachmentMode,
+			AccessMode:      volume.AccessMode,
+			ReadOnly:        args.Claim == structs.CSIVolumeClaimRead,
+			Secrets:         volume.Secrets,
+			VolumeContext:   volume.Context,
+		}
+		var resp cstructs.ClientCSIControllerAttachVolumeResponse
+		if err := v.controllerRPC(plugin, "ControllerAttachVolume", &req.CSIControllerQuery, req, &resp, nil); err != nil {
+			return fmt.Errorf("attaching volume %q: %v", args.VolumeID, err)
+		}
+		reply.PublishContext = resp.PublishContext
+	}
+
+	_, index, err := v.srv.raftApply(structs.CSIVolumeClaimRequestType, args)
+	if err != nil {
+		v.logger.Error("claim failed", "error", err)
+		return err
+	}
+
+	claimed, err := v.srv.State().CSIVolumeByID(nil, args.RequestNamespace(), args.VolumeID)
+	if err != nil {
+		return err
+	}
+	reply.Volume = claimed.Sanitize()
+	reply.Index = index
+	return nil
+}
+
+// Unpublish is used to release the claim of a terminal allocation on a volume.
+// The volume is unmounted from the allocation and detached from the node once
+// no other allocation on the node uses it. The progress of the release is
+// recorded so that a failed release can be resumed.
+func (v *CSIVolume) Unpublish(args *structs.CSIVolumeUnpublishRequest, reply *structs.GenericResponse) error {
+	if done, err := v.srv.forward("CSIVolume.Unpublish", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "volume", "unpublish"}, time.Now())
+
+	if args.VolumeID == "" {
+		return fmt.Errorf("missing volume ID")
+	}
+	if args.AllocationID == "" {
+		return fmt.Errorf("missing allocation ID")
+	}
+
+	snap, err := v.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	volume, err := snap.CSIVolumeByID(nil, args.RequestNamespace(), args.VolumeID)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", args.VolumeID)
+	}
+
+	var claim *structs.CSIVolumeClaim
+	for _, claims := range []map[string]*structs.CSIVolumeClaim{volume.ReadClaims, volume.WriteClaims, volume.PastClaims} {
+		if c, ok := claims[args.AllocationID]; ok {
+			claim = c
+		}
+	}
+
+	if err := v.authorizeMount(args.AuthToken, args.RequestNamespace(), nodeIDOfClaim(claim)); err != nil {
+		return err
+	}
+
+	// The claim has already been released
+	if claim == nil {
+		return nil
+	}
+
+	alloc, err := snap.AllocByID(nil, args.AllocationID)
+	if err != nil {
+		return err
+	}
+	if alloc != nil && !alloc.ClientTerminalStatus() {
+		return fmt.Errorf("alloc %q is still running", args.AllocationID)
+	}
+
+	update := func(claimState structs.CSIVolumeClaimState) (uint64, error) {
+		req := &structs.CSIVolumeClaimRequest{
+			VolumeID:       args.VolumeID,
+			AllocationID:   args.AllocationID,
+			NodeID:         claim.NodeID,
+			ExternalNodeID: claim.ExternalNodeID,
+			Claim:          structs.CSIVolumeClaimRelease,
+			State:          claimState,
+			WriteRequest:   args.WriteRequest,
+		}
+		_, index, err := v.srv.raftApply(structs.CSIVolumeClaimRequestType, req)
+		if err != nil {
+			v.logger.Error("release claim failed", "error", err)
+		}
+		claim.State = claimState
+		return index, err
+	}
+
+	// Record that the claim is being released so the allocation's claim is
+	// no longer counted
+	if claim.Mode != structs.CSIVolumeClaimRelease {
+		if _, err := update(structs.CSIVolumeClaimStateTaken); err != nil {
+			return err
+		}
+	}
+
+	// Unmount the volume from the allocation. Down nodes can not be reached
+	// and no longer run the allocation.
+	if claim.State == structs.CSIVolumeClaimStateTaken {
+		node, err := snap.NodeByID(nil, claim.NodeID)
+		if err != nil {
+			return err
+		}
+		if node != nil && node.Status != structs.NodeStatusDown {
+			req := &cstructs.ClientCSINodeDetachVolumeRequest{
+				PluginID:       volume.PluginID,
+				NodeID:         claim.NodeID,
+				AllocID:        args.AllocationID,
+				VolumeID:       volume.ID,
+				ExternalID:     volume.ExternalID,
+				AttachmentMode: volume.AttachmentMode,
+				AccessMode:     volume.AccessMode,
+				ReadOnly:       claim.Mode == structs.CSIVolumeClaimRead,
+			}
+			var resp cstructs.ClientCSINodeDetachVolumeResponse
+			if err := v.srv.RPC("ClientCSI.NodeDetachVolume", req, &resp); err != nil {
+				return fmt.Errorf("detaching volume %q from node: %v", args.VolumeID, err)
+			}
+		}
+		if _, err := update(structs.CSIVolumeClaimStateNodeDetached); err != nil {
+			return err
+		}
+	}
+
+	// Detach the volume from the node once no other allocation uses it there
+	if volume.ControllerRequired && volume.ClaimsOnNode(claim.NodeID, args.AllocationID) == 0 {
+		plugin, err := snap.CSIPluginByID(nil, volume.PluginID)
+		if err != nil {
+			return err
+		}
+
+		req := &cstructs.ClientCSIControllerDetachVolumeRequest{
+			VolumeID:        volume.ExternalID,
+			ClientCSINodeID: claim.ExternalNodeID,
+			Secrets:         volume.Secrets,
+		}
+		var resp cstructs.ClientCSIControllerDetachVolumeResponse
+		if err := v.controllerRPC(plugin, "ControllerDetachVolume", &req.CSIControllerQuery, req, &resp, nil); err != nil {
+			return fmt.Errorf("detaching volume %q: %v", args.VolumeID, err)
+		}
+	}
+
+	index, err := update(structs.CSIVolumeClaimStateReadyToFree)
+	if err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// validateVolume validates the volume and checks its plugin exists
+func (v *CSIVolume) validateVolume(volume *structs.CSIVolume) error {
+	if err := volume.Validate(); err != nil {
+		return fmt.Errorf("volume %q: %v", volume.ID, err)
+	}
+	volume.ReadClaims = nil
+	volume.WriteClaims = nil
+	volume.PastClaims = nil
+
+	plugin, err := v.srv.State().CSIPluginByID(nil, volume.PluginID)
+	if err != nil {
+		return err
+	}
+	if plugin == nil {
+		return fmt.Errorf("volume %q: no CSI plugin named %q", volume.ID, volume.PluginID)
+	}
+	return nil
+}
+
+// authorizeMount allows either the secret ID of the node the claim is for or
+// an ACL token with the csi-mount-volume capability in the namespace
+func (v *CSIVolume) authorizeMount(authToken, namespace, nodeID string) error {
+	if authToken != "" {
+		node, err := v.srv.State().NodeBySecretID(nil, authToken)
+		if err != nil {
+			return err
+		}
+		if node != nil {
+			if nodeID != "" && node.ID != nodeID {
+				return structs.ErrPermissionDenied
+			}
+			return nil
+		}
+	}
+
+	if aclObj, err := v.srv.ResolveToken(authToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(namespace, acl.NamespaceCapabilityCSIMountVolume) {
+		return structs.ErrPermissionDenied
+	}
+	return nil
+}
+
+// controllerRPC sends the request to the healthy controllers of the plugin,
+// in random order, until one succeeds. If filter is set only the controllers
+// it accepts are used.
+func (v *CSIVolume) controllerRPC(plugin *structs.CSIPlugin, method string, query *cstructs.CSIControllerQuery,
+	args, reply interface{}, filter func(*structs.CSIInfo) bool) error {
+
+	if plugin == nil {
+		return fmt.Errorf("plugin not found")
+	}
+
+	var nodes []string
+	for _, id := range plugin.HealthyControllers() {
+		if filter == nil || filter(plugin.Controllers[id]) {
+			nodes = append(nodes, id)
+		}
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no healthy controllers for plugin %q", plugin.ID)
+	}
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+
+	var mErr multierror.Error
+	for _, id := range nodes {
+		query.ControllerNodeID = id
+		query.PluginID = plugin.ID
+		err := v.srv.RPC("ClientCSI."+method, args, reply)
+		if err == nil {
+			return nil
+		}
+		v.logger.Warn("controller request failed", "method", method, "plugin_id", plugin.ID, "node_id", id, "error", err)
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	return mErr.ErrorOrNil()
+}
+
+// nodeIDOfClaim returns the node of the claim or an empty string
+func nodeIDOfClaim(claim *structs.CSIVolumeClaim) string {
+	if claim == nil {
+		return ""
+	}
+	return claim.NodeID
+}
+
+// csiIndex returns the last index that affected the CSI volumes or plugins
+func csiIndex(state *state.StateStore) (uint64, error) {
+	volumes, err := state.Index("csi_volumes")
+	if err != nil {
+		return 0, err
+	}
+	plugins, err := state.Index("csi_plugins")
+	if err != nil {
+		return 0, err
+	}
+	if plugins > volumes {
+		return plugins, nil
+	}
+	return volumes, nil
+}
+
+// CSIPlugin endpoint is used to look up the CSI plugins running on the
+// clients
+type CSIPlugin struct {
+	srv    *Server
+	logger log.Logger
+}
+
+// List is used to list the CSI plugins
+func (p *CSIPlugin) List(args *structs.CSIPluginListRequest, reply *structs.CSIPluginListResponse) error {
+	if done, err := p.srv.forward("CSIPlugin.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "plugin", "list"}, time.Now())
+
+	// Plugins are part of the nodes so check node read permissions
+	if aclObj, err := p.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			iter, err := state.CSIPlugins(ws)
+			if err != nil {
+				return err
+			}
+
+			plugins := []*structs.CSIPluginListStub{}
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				plugins = append(plugins, raw.(*structs.CSIPlugin).Stub())
+			}
+			reply.Plugins = plugins
+
+			// Use the last index that affected the plugins table
+			index, err := state.Index("csi_plugins")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			p.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return p.srv.blockingRPC(&opts)
+}
+
+// Get is used to get a single CSI plugin with its instances
+func (p *CSIPlugin) Get(args *structs.CSIPluginGetRequest, reply *structs.CSIPluginGetResponse) error {
+	if done, err := p.srv.forward("CSIPlugin.Get", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "plugin", "get"}, time.Now())
+
+	// Plugins are part of the nodes so check node read permissions
+	if aclObj, err := p.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.ID == "" {
+		return fmt.Errorf("missing plugin ID")
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			plugin, err := state.CSIPluginByID(ws, args.ID)
+			if err != nil {
+				return err
+			}
+			reply.Plugin = plugin
+
+			index, err := state.Index("csi_plugins")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			p.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return p.srv.blockingRPC(&opts)
+}
@@ -0,0 +1,249 @@
+package nomad
+
+import (
+	"testing"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSIVolumeEndpoint_RegisterGetList(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	node := mock.CSINode("ebs")
+	require.NoError(state.UpsertNode(1000, node))
+
+	vol := mock.CSIVolume("ebs")
+	vol.Secrets = map[string]string{"key": "secret"}
+	req := &structs.CSIVolumeRegisterRequest{
+		Volumes: []*structs.CSIVolume{vol},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+
+	// Registering requires csi-write-volume
+	readToken := mock.CreatePolicyAndToken(t, state, 1001, "csi-read",
+		mock.NamespacePolicy(structs.DefaultNamespace, "read", nil))
+	req.AuthToken = readToken.SecretID
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "CSIVolume.Register", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Volumes must be provided by a known plugin
+	req.AuthToken = root.SecretID
+	req.Volumes[0].PluginID = "unknown"
+	err = msgpackrpc.CallWithCodec(codec, "CSIVolume.Register", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "no CSI plugin")
+
+	req.Volumes[0].PluginID = "ebs"
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.Register", req, &resp))
+	require.NotZero(resp.Index)
+
+	// Reading returns the health of the volume without its secrets
+	get := &structs.CSIVolumeGetRequest{
+		ID: vol.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: readToken.SecretID,
+		},
+	}
+	var getResp structs.CSIVolumeGetResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.Get", get, &getResp))
+	require.NotNil(getResp.Volume)
+	require.True(getResp.Volume.Schedulable)
+	require.Equal("com.hashicorp:mock", getResp.Volume.Provider)
+	require.Nil(getResp.Volume.Secrets)
+
+	list := &structs.CSIVolumeListRequest{
+		PluginID: "ebs",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: readToken.SecretID,
+		},
+	}
+	var listResp structs.CSIVolumeListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.List", list, &listResp))
+	require.Len(listResp.Volumes, 1)
+	require.Equal(vol.ID, listResp.Volumes[0].ID)
+	require.Equal(1, listResp.Volumes[0].NodesHealthy)
+
+	// Volumes of other plugins are filtered
+	list.PluginID = "nfs"
+	var otherResp structs.CSIVolumeListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.List", list, &otherResp))
+	require.Empty(otherResp.Volumes)
+
+	dreq := &structs.CSIVolumeDeregisterRequest{
+		VolumeIDs: []string{vol.ID},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: root.SecretID,
+		},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.Deregister", dreq, &resp))
+
+	out, err := state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestCSIVolumeEndpoint_ClaimUnpublish(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	node := mock.CSINode("ebs")
+	other := mock.Node()
+	require.NoError(state.UpsertNode(1000, node))
+	require.NoError(state.UpsertNode(1001, other))
+	vol := mock.CSIVolume("ebs")
+	require.NoError(state.CSIVolumeRegister(1002, []*structs.CSIVolume{vol}))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	require.NoError(state.UpsertAllocs(1003, []*structs.Allocation{alloc}))
+
+	req := &structs.CSIVolumeClaimRequest{
+		VolumeID:     vol.ID,
+		AllocationID: alloc.ID,
+		NodeID:       node.ID,
+		Claim:        structs.CSIVolumeClaimWrite,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: other.SecretID,
+		},
+	}
+
+	// Nodes may only claim volumes for themselves
+	var resp structs.CSIVolumeClaimResponse
+	err := msgpackrpc.CallWithCodec(codec, "CSIVolume.Claim", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = node.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.Claim", req, &resp))
+	require.NotNil(resp.Volume)
+	require.Len(resp.Volume.WriteClaims, 1)
+	require.Equal("i-"+node.ID[:8], resp.Volume.WriteClaims[alloc.ID].ExternalNodeID)
+
+	unpub := &structs.CSIVolumeUnpublishRequest{
+		VolumeID:     vol.ID,
+		AllocationID: alloc.ID,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: node.SecretID,
+		},
+	}
+
+	// Claims of running allocations can not be released
+	var uresp structs.GenericResponse
+	err = msgpackrpc.CallWithCodec(codec, "CSIVolume.Unpublish", unpub, &uresp)
+	require.Error(err)
+	require.Contains(err.Error(), "still running")
+
+	// Down nodes can not be reached so the volume is released without
+	// detaching it from the node
+	stopped := alloc.Copy()
+	stopped.ClientStatus = structs.AllocClientStatusLost
+	require.NoError(state.UpsertAllocs(1004, []*structs.Allocation{stopped}))
+	require.NoError(state.UpdateNodeStatus(1005, node.ID, structs.NodeStatusDown, nil))
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIVolume.Unpublish", unpub, &uresp))
+
+	out, err := state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.False(out.InUse())
+	require.False(out.Schedulable)
+}
+
+func TestCSIVolumeEndpoint_Create_NoController(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	node := mock.CSINode("ebs")
+	require.NoError(state.UpsertNode(1000, node))
+
+	vol := mock.CSIVolume("ebs")
+	vol.ExternalID = ""
+	req := &structs.CSIVolumeCreateRequest{
+		Volumes: []*structs.CSIVolume{vol},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+	var resp structs.CSIVolumeCreateResponse
+	err := msgpackrpc.CallWithCodec(codec, "CSIVolume.Create", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "no healthy controllers")
+
+	// Nothing is registered when the volume could not be created
+	out, err := state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestCSIPluginEndpoint_ListGet(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, _ := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	require.NoError(state.UpsertNode(1000, mock.CSINode("ebs")))
+	require.NoError(state.UpsertNode(1001, mock.CSINode("ebs")))
+
+	// Plugins require node read permissions
+	token := mock.CreatePolicyAndToken(t, state, 1002, "node-read", mock.NodePolicy(acl.PolicyRead))
+	req := &structs.CSIPluginListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.CSIPluginListResponse
+	err := msgpackrpc.CallWithCodec(codec, "CSIPlugin.List", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = token.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIPlugin.List", req, &resp))
+	require.Len(resp.Plugins, 1)
+	require.Equal(2, resp.Plugins[0].NodesHealthy)
+	require.Equal(uint64(1001), resp.Index)
+
+	get := &structs.CSIPluginGetRequest{
+		ID: "ebs",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var getResp structs.CSIPluginGetResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "CSIPlugin.Get", get, &getResp))
+	require.NotNil(getResp.Plugin)
+	require.Len(getResp.Plugin.Nodes, 2)
+}
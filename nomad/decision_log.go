@@ -0,0 +1,224 @@
+package nomad
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// DecisionPlaced is the outcome of a placement committed by the plan
+	// applier.
+	DecisionPlaced = "placed"
+
+	// DecisionRejected is the outcome of a placement rejected by the plan
+	// applier, usually because the node changed since the scheduler's
+	// snapshot. The scheduler retries these placements.
+	DecisionRejected = "rejected"
+
+	// DecisionFailed is the outcome of a task group that couldn't be placed.
+	DecisionFailed = "failed"
+)
+
+// PlacementDecision is the record written to the decision log for each
+// placement made, or failed to be made, by a scheduler.
+type PlacementDecision struct {
+	Timestamp time.Time `json:"timestamp"`
+	Outcome   string    `json:"outcome"`
+	EvalID    string    `json:"eval_id"`
+	Namespace string    `json:"namespace"`
+	JobID     string    `json:"job_id"`
+	TaskGroup string    `json:"task_group"`
+
+	// AllocID, AllocName and NodeID identify the allocation placed and the
+	// node chosen for it. They are empty for failed placements.
+	AllocID   string `json:"alloc_id,omitempty"`
+	AllocName string `json:"alloc_name,omitempty"`
+	NodeID    string `json:"node_id,omitempty"`
+
+	// PreemptedAllocs are the IDs of the allocations preempted to make room
+	// for the placement.
+	PreemptedAllocs []string `json:"preempted_allocs,omitempty"`
+
+	// CoalescedFailures is the number of other allocations of the task group
+	// that failed to be placed for the same reasons.
+	CoalescedFailures int `json:"coalesced_failures,omitempty"`
+
+	NodesEvaluated     int                   `json:"nodes_evaluated"`
+	NodesFiltered      int                   `json:"nodes_filtered"`
+	NodesExhausted     int                   `json:"nodes_exhausted"`
+	NodesAvailable     map[string]int        `json:"nodes_available,omitempty"`
+	ClassFiltered      map[string]int        `json:"class_filtered,omitempty"`
+	ConstraintFiltered map[string]int        `json:"constraint_filtered,omitempty"`
+	ClassExhausted     map[string]int        `json:"class_exhausted,omitempty"`
+	DimensionExhausted map[string]int        `json:"dimension_exhausted,omitempty"`
+	QuotaExhausted     []string              `json:"quota_exhausted,omitempty"`
+	Scores             []*PlacementNodeScore `json:"scores,omitempty"`
+	AllocationTime     time.Duration         `json:"allocation_time"`
+}
+
+// PlacementNodeScore is the score of one of the top nodes considered for a
+// placement.
+type PlacementNodeScore struct {
+	NodeID    string             `json:"node_id"`
+	NormScore float64            `json:"norm_score"`
+	Scores    map[string]float64 `json:"scores"`
+}
+
+// decisionLog writes the placement decisions of the schedulers of a server as
+// JSON lines, for offline analysis of the scheduling. A nil decisionLog
+// writes nothing.
+type decisionLog struct {
+	logger log.Logger
+	w      io.Writer
+	l      sync.Mutex
+}
+
+// newDecisionLog returns a decision log writing to the given output, or nil
+// if it is nil.
+func newDecisionLog(logger log.Logger, w io.Writer) *decisionLog {
+	if w == nil {
+		return nil
+	}
+	return &decisionLog{
+		logger: logger.Named("decision_log"),
+		w:      w,
+	}
+}
+
+// planPlacements returns the decisions of the allocations placed by a plan.
+// It must be called before the plan is submitted, as applying the plan sets
+// the create index used to tell placements from in-place updates.
+func (d *decisionLog) planPlacements(plan *structs.Plan) []*PlacementDecision {
+	if d == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	var decisions []*PlacementDecision
+	for _, allocs := range plan.NodeAllocation {
+		for _, alloc := range allocs {
+			// In-place updates of existing allocations aren't placements
+			if alloc.CreateIndex != 0 || alloc.Metrics == nil {
+				continue
+			}
+
+			decision := newPlacementDecision(now, plan.EvalID, alloc.Namespace, alloc.JobID, alloc.TaskGroup, alloc.Metrics)
+			decision.AllocID = alloc.ID
+			decision.AllocName = alloc.Name
+			decision.NodeID = alloc.NodeID
+			decision.PreemptedAllocs = alloc.PreemptedAllocations
+			decisions = append(decisions, decision)
+		}
+	}
+
+	// Keep the records of a plan in a stable order
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].AllocName < decisions[j].AllocName
+	})
+	return decisions
+}
+
+// placements records the placements of a plan along with whether the plan
+// applier committed them.
+func (d *decisionLog) placements(decisions []*PlacementDecision, result *structs.PlanResult) {
+	if d == nil || len(decisions) == 0 {
+		return
+	}
+
+	committed := make(map[string]struct{})
+	for _, allocs := range result.NodeAllocation {
+		for _, alloc := range allocs {
+			committed[alloc.ID] = struct{}{}
+		}
+	}
+
+	for _, decision := range decisions {
+		decision.Outcome = DecisionRejected
+		if _, ok := committed[decision.AllocID]; ok {
+			decision.Outcome = DecisionPlaced
+		}
+	}
+	d.write(decisions)
+}
+
+// failures records the task groups an evaluation failed to place.
+func (d *decisionLog) failures(eval *structs.Evaluation) {
+	if d == nil || eval == nil || len(eval.FailedTGAllocs) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	decisions := make([]*PlacementDecision, 0, len(eval.FailedTGAllocs))
+	for tg, metric := range eval.FailedTGAllocs {
+		decision := newPlacementDecision(now, eval.ID, eval.Namespace, eval.JobID, tg, metric)
+		decision.Outcome = DecisionFailed
+		decision.CoalescedFailures = metric.CoalescedFailures
+		decisions = append(decisions, decision)
+	}
+
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].TaskGroup < decisions[j].TaskGroup
+	})
+	d.write(decisions)
+}
+
+func newPlacementDecision(now time.Time, evalID, namespace, jobID, tg string, metric *structs.AllocMetric) *PlacementDecision {
+	decision := &PlacementDecision{
+		Timestamp:          now,
+		EvalID:             evalID,
+		Namespace:          namespace,
+		JobID:              jobID,
+		TaskGroup:          tg,
+		NodesEvaluated:     metric.NodesEvaluated,
+		NodesFiltered:      metric.NodesFiltered,
+		NodesExhausted:     metric.NodesExhausted,
+		NodesAvailable:     metric.NodesAvailable,
+		ClassFiltered:      metric.ClassFiltered,
+		ConstraintFiltered: metric.ConstraintFiltered,
+		ClassExhausted:     metric.ClassExhausted,
+		DimensionExhausted: metric.DimensionExhausted,
+		QuotaExhausted:     metric.QuotaExhausted,
+		AllocationTime:     metric.AllocationTime,
+	}
+	for _, score := range metric.ScoreMetaData {
+		decision.Scores = append(decision.Scores, &PlacementNodeScore{
+			NodeID:    score.NodeID,
+			NormScore: score.NormScore,
+			Scores:    score.Scores,
+		})
+	}
+	return decision
+}
+
+// write writes the decisions as JSON lines. Failures are logged but don't
+// affect the scheduling.
+func (d *decisionLog) write(decisions []*PlacementDecision) {
+	if len(decisions) == 0 {
+		return
+	}
+
+	var buf []byte
+	for _, decision := range decisions {
+		line, err := json.Marshal(decision)
+		if err != nil {
+			d.logger.Warn("failed to encode placement decision", "eval_id", decision.EvalID, "error", err)
+			continue
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	d.l.Lock()
+	defer d.l.Unlock()
+	if _, err := d.w.Write(buf); err != nil {
+		d.logger.Warn("failed to write placement decisions", "error", err)
+		metrics.IncrCounter([]string{"nomad", "decision_log", "write_error"}, 1)
+	}
+}
@@ -0,0 +1,126 @@
+package nomad
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a buffer safe to read while the server writes to it.
+type lockedBuffer struct {
+	buf bytes.Buffer
+	l   sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) decisions(t *testing.T) []*PlacementDecision {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	var out []*PlacementDecision
+	scanner := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	for scanner.Scan() {
+		var d PlacementDecision
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &d))
+		out = append(out, &d)
+	}
+	return out
+}
+
+func TestDecisionLog(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	output := &lockedBuffer{}
+	s1 := TestServer(t, func(c *Config) {
+		c.DecisionLogOutput = output
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	node := mock.Node()
+	require.NoError(s1.fsm.State().UpsertNode(1000, node))
+
+	register := func(job *structs.Job) string {
+		req := &structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: job.Namespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+		return resp.EvalID
+	}
+	waitForEval := func(evalID string) {
+		testutil.WaitForResult(func() (bool, error) {
+			eval, err := s1.fsm.State().EvalByID(nil, evalID)
+			if err != nil {
+				return false, err
+			}
+			if eval == nil || eval.Status != structs.EvalStatusComplete {
+				return false, fmt.Errorf("eval not complete: %v", eval)
+			}
+			return true, nil
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	}
+
+	// Each placement is recorded with the node chosen for it
+	job := mock.Job()
+	job.TaskGroups[0].Count = 2
+	evalID := register(job)
+	waitForEval(evalID)
+
+	decisions := output.decisions(t)
+	require.Len(decisions, 2)
+	for _, d := range decisions {
+		require.Equal(DecisionPlaced, d.Outcome)
+		require.Equal(evalID, d.EvalID)
+		require.Equal(job.ID, d.JobID)
+		require.Equal("web", d.TaskGroup)
+		require.Equal(node.ID, d.NodeID)
+		require.NotEmpty(d.AllocID)
+		require.Equal(1, d.NodesEvaluated)
+		require.Len(d.Scores, 1)
+		require.Equal(node.ID, d.Scores[0].NodeID)
+	}
+
+	// Task groups that can't be placed are recorded with the reasons
+	job2 := mock.Job()
+	job2.TaskGroups[0].Count = 1
+	job2.Constraints = append(job2.Constraints, &structs.Constraint{
+		LTarget: "${attr.kernel.name}",
+		RTarget: "windows",
+		Operand: "=",
+	})
+	evalID = register(job2)
+	waitForEval(evalID)
+
+	decisions = output.decisions(t)
+	require.Len(decisions, 3)
+	failed := decisions[2]
+	require.Equal(DecisionFailed, failed.Outcome)
+	require.Equal(evalID, failed.EvalID)
+	require.Equal(job2.ID, failed.JobID)
+	require.Empty(failed.NodeID)
+	require.Equal(1, failed.NodesFiltered)
+	require.Equal(1, failed.ConstraintFiltered["${attr.kernel.name} = windows"])
+}
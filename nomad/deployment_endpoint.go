@@ -28,9 +28,10 @@ func (d *Deployment) GetDeployment(args *structs.DeploymentSpecificRequest,
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "get_deployment"}, time.Now())
 
 	// Check namespace read-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -50,6 +51,11 @@ func (d *Deployment) GetDeployment(args *structs.DeploymentSpecificRequest,
 				return err
 			}
 
+			// Check the read-job permissions of the deployment's job
+			if out != nil && aclObj != nil && !aclObj.AllowJobOp(out.Namespace, out.JobID, acl.NamespaceCapabilityReadJob) {
+				return structs.ErrPermissionDenied
+			}
+
 			// Setup the output
 			reply.Deployment = out
 			if out != nil {
@@ -78,9 +84,10 @@ func (d *Deployment) Fail(args *structs.DeploymentFailRequest, reply *structs.De
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "fail"}, time.Now())
 
 	// Check namespace submit-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -103,6 +110,9 @@ func (d *Deployment) Fail(args *structs.DeploymentFailRequest, reply *structs.De
 	if deploy == nil {
 		return fmt.Errorf("deployment not found")
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(deploy.Namespace, deploy.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
 
 	if !deploy.Active() {
 		return fmt.Errorf("can't fail terminal deployment")
@@ -120,9 +130,10 @@ func (d *Deployment) Pause(args *structs.DeploymentPauseRequest, reply *structs.
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "pause"}, time.Now())
 
 	// Check namespace submit-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -145,6 +156,9 @@ func (d *Deployment) Pause(args *structs.DeploymentPauseRequest, reply *structs.
 	if deploy == nil {
 		return fmt.Errorf("deployment not found")
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(deploy.Namespace, deploy.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
 
 	if !deploy.Active() {
 		if args.Pause {
@@ -166,9 +180,10 @@ func (d *Deployment) Promote(args *structs.DeploymentPromoteRequest, reply *stru
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "promote"}, time.Now())
 
 	// Check namespace submit-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -191,6 +206,9 @@ func (d *Deployment) Promote(args *structs.DeploymentPromoteRequest, reply *stru
 	if deploy == nil {
 		return fmt.Errorf("deployment not found")
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(deploy.Namespace, deploy.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
 
 	if !deploy.Active() {
 		return fmt.Errorf("can't promote terminal deployment")
@@ -209,9 +227,10 @@ func (d *Deployment) SetAllocHealth(args *structs.DeploymentAllocHealthRequest,
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "set_alloc_health"}, time.Now())
 
 	// Check namespace submit-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -238,6 +257,9 @@ func (d *Deployment) SetAllocHealth(args *structs.DeploymentAllocHealthRequest,
 	if deploy == nil {
 		return fmt.Errorf("deployment not found")
 	}
+	if aclObj != nil && !aclObj.AllowJobOp(deploy.Namespace, deploy.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
 
 	if !deploy.Active() {
 		return fmt.Errorf("can't set health of allocations for a terminal deployment")
@@ -247,6 +269,118 @@ func (d *Deployment) SetAllocHealth(args *structs.DeploymentAllocHealthRequest,
 	return d.srv.deploymentWatcher.SetAllocHealth(args, reply)
 }
 
+// SetGroupHealth is used to mark every placed allocation of the given task
+// groups healthy when their health has been validated outside of Nomad. The
+// override is recorded on the deployment along with the supplied reason.
+func (d *Deployment) SetGroupHealth(args *structs.DeploymentGroupHealthRequest, reply *structs.DeploymentUpdateResponse) error {
+	if done, err := d.srv.forward("Deployment.SetGroupHealth", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "deployment", "set_group_health"}, time.Now())
+
+	// Check namespace submit-job permissions
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if args.DeploymentID == "" {
+		return fmt.Errorf("missing deployment ID")
+	}
+	if len(args.Groups) == 0 {
+		return fmt.Errorf("must specify at least one task group")
+	}
+	if args.Reason == "" {
+		return fmt.Errorf("must specify a reason for marking the task groups healthy")
+	}
+
+	// Lookup the deployment
+	snap, err := d.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	ws := memdb.NewWatchSet()
+	deploy, err := snap.DeploymentByID(ws, args.DeploymentID)
+	if err != nil {
+		return err
+	}
+	if deploy == nil {
+		return fmt.Errorf("deployment not found")
+	}
+	if aclObj != nil && !aclObj.AllowJobOp(deploy.Namespace, deploy.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if !deploy.Active() {
+		return fmt.Errorf("can't set health of task groups for a terminal deployment")
+	}
+
+	groups := make(map[string]struct{}, len(args.Groups))
+	for _, group := range args.Groups {
+		if _, ok := deploy.TaskGroups[group]; !ok {
+			return fmt.Errorf("deployment has no task group %q", group)
+		}
+		groups[group] = struct{}{}
+	}
+
+	// Record who made the request when ACLs are enabled
+	var accessorID string
+	if aclObj != nil {
+		token, err := snap.ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+		if token != nil {
+			accessorID = token.AccessorID
+		}
+	}
+
+	// Find the allocations that have yet to be marked healthy
+	allocs, err := snap.AllocsByDeployment(ws, args.DeploymentID)
+	if err != nil {
+		return err
+	}
+
+	req := &structs.DeploymentAllocHealthRequest{
+		DeploymentID: args.DeploymentID,
+		WriteRequest: args.WriteRequest,
+	}
+	overrides := make(map[string]*structs.DeploymentHealthOverride, len(groups))
+	for _, alloc := range allocs {
+		if _, ok := groups[alloc.TaskGroup]; !ok {
+			continue
+		}
+		if alloc.TerminalStatus() || alloc.DeploymentStatus.IsHealthy() {
+			continue
+		}
+
+		override, ok := overrides[alloc.TaskGroup]
+		if !ok {
+			override = &structs.DeploymentHealthOverride{
+				Reason:     args.Reason,
+				AccessorID: accessorID,
+			}
+			overrides[alloc.TaskGroup] = override
+		}
+		override.AllocationIDs = append(override.AllocationIDs, alloc.ID)
+		req.HealthyAllocationIDs = append(req.HealthyAllocationIDs, alloc.ID)
+	}
+
+	if len(req.HealthyAllocationIDs) == 0 {
+		return fmt.Errorf("task groups have no allocations awaiting health")
+	}
+
+	d.logger.Info("marking deployment task groups healthy", "deployment_id", args.DeploymentID,
+		"groups", args.Groups, "allocs", len(req.HealthyAllocationIDs), "reason", args.Reason)
+
+	// Call into the deployment watcher
+	return d.srv.deploymentWatcher.SetGroupHealth(req, overrides, reply)
+}
+
 // List returns the list of deployments in the system
 func (d *Deployment) List(args *structs.DeploymentListRequest, reply *structs.DeploymentListResponse) error {
 	if done, err := d.srv.forward("Deployment.List", args, args, reply); done {
@@ -255,9 +389,10 @@ func (d *Deployment) List(args *structs.DeploymentListRequest, reply *structs.De
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "list"}, time.Now())
 
 	// Check namespace read-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -271,6 +406,8 @@ func (d *Deployment) List(args *structs.DeploymentListRequest, reply *structs.De
 			var iter memdb.ResultIterator
 			if prefix := args.QueryOptions.Prefix; prefix != "" {
 				iter, err = state.DeploymentsByIDPrefix(ws, args.RequestNamespace(), prefix)
+			} else if args.Status != "" {
+				iter, err = state.DeploymentsByNamespaceStatus(ws, args.RequestNamespace(), args.Status)
 			} else {
 				iter, err = state.DeploymentsByNamespace(ws, args.RequestNamespace())
 			}
@@ -285,6 +422,12 @@ func (d *Deployment) List(args *structs.DeploymentListRequest, reply *structs.De
 					break
 				}
 				deploy := raw.(*structs.Deployment)
+				if args.Status != "" && deploy.Status != args.Status {
+					continue
+				}
+				if aclObj != nil && !aclObj.AllowJobOp(deploy.Namespace, deploy.JobID, acl.NamespaceCapabilityReadJob) {
+					continue
+				}
 				deploys = append(deploys, deploy)
 			}
 			reply.Deployments = deploys
@@ -311,9 +454,10 @@ func (d *Deployment) Allocations(args *structs.DeploymentSpecificRequest, reply
 	defer metrics.MeasureSince([]string{"nomad", "deployment", "allocations"}, time.Now())
 
 	// Check namespace read-job permissions
-	if aclObj, err := d.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := d.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -330,7 +474,10 @@ func (d *Deployment) Allocations(args *structs.DeploymentSpecificRequest, reply
 
 			stubs := make([]*structs.AllocListStub, 0, len(allocs))
 			for _, alloc := range allocs {
-				stubs = append(stubs, alloc.Stub())
+				if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadJob) {
+					continue
+				}
+				stubs = append(stubs, alloc.Stub(nil))
 			}
 			reply.Allocations = stubs
 
@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeploymentEndpoint_GetDeployment(t *testing.T) {
@@ -679,6 +680,98 @@ func TestDeploymentEndpoint_SetAllocHealth(t *testing.T) {
 	assert.True(*aout.DeploymentStatus.Healthy, "alloc deployment healthy")
 }
 
+func TestDeploymentEndpoint_SetGroupHealth(t *testing.T) {
+	t.Parallel()
+	s1, _ := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	// Create the deployment, job, a pending alloc and an already healthy one
+	j := mock.Job()
+	j.TaskGroups[0].Update = structs.DefaultUpdateStrategy.Copy()
+	j.TaskGroups[0].Update.MaxParallel = 2
+	d := mock.Deployment()
+	d.JobID = j.ID
+	a1 := mock.Alloc()
+	a1.JobID = j.ID
+	a1.DeploymentID = d.ID
+	a2 := mock.Alloc()
+	a2.JobID = j.ID
+	a2.DeploymentID = d.ID
+	a2.DeploymentStatus = &structs.AllocDeploymentStatus{Healthy: helper.BoolToPtr(true)}
+
+	state := s1.fsm.State()
+	require.Nil(state.UpsertJob(999, j))
+	require.Nil(state.UpsertDeployment(1000, d))
+	require.Nil(state.UpsertAllocs(1001, []*structs.Allocation{a1, a2}))
+
+	validToken := mock.CreatePolicyAndToken(t, state, 1002, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilitySubmitJob}))
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+
+	req := &structs.DeploymentGroupHealthRequest{
+		DeploymentID: d.ID,
+		Groups:       []string{"web"},
+		Reason:       "canaries verified by external checks",
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: invalidToken.SecretID,
+		},
+	}
+
+	// Try with an invalid token
+	var resp structs.DeploymentUpdateResponse
+	err := msgpackrpc.CallWithCodec(codec, "Deployment.SetGroupHealth", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// An unknown group and a missing reason are rejected
+	req.AuthToken = validToken.SecretID
+	req.Groups = []string{"api"}
+	err = msgpackrpc.CallWithCodec(codec, "Deployment.SetGroupHealth", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "no task group")
+
+	req.Groups = []string{"web"}
+	req.Reason = ""
+	err = msgpackrpc.CallWithCodec(codec, "Deployment.SetGroupHealth", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "reason")
+
+	// Mark the group healthy
+	req.Reason = "canaries verified by external checks"
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Deployment.SetGroupHealth", req, &resp))
+	require.NotZero(resp.Index)
+	require.NotEmpty(resp.EvalID)
+
+	ws := memdb.NewWatchSet()
+	aout, err := state.AllocByID(ws, a1.ID)
+	require.Nil(err)
+	require.True(aout.DeploymentStatus.IsHealthy())
+
+	// Only the pending allocation is recorded on the deployment
+	dout, err := state.DeploymentByID(ws, d.ID)
+	require.Nil(err)
+	require.Equal(structs.DeploymentStatusRunning, dout.Status)
+	require.Equal(resp.DeploymentModifyIndex, dout.ModifyIndex)
+	require.Equal(1, dout.TaskGroups["web"].HealthyAllocs)
+	require.Len(dout.TaskGroups["web"].HealthOverrides, 1)
+	override := dout.TaskGroups["web"].HealthOverrides[0]
+	require.Equal(req.Reason, override.Reason)
+	require.Equal(validToken.AccessorID, override.AccessorID)
+	require.Equal([]string{a1.ID}, override.AllocationIDs)
+	require.False(override.Timestamp.IsZero())
+
+	// Nothing is left to mark healthy
+	err = msgpackrpc.CallWithCodec(codec, "Deployment.SetGroupHealth", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "no allocations awaiting health")
+}
+
 func TestDeploymentEndpoint_SetAllocHealth_ACL(t *testing.T) {
 	t.Parallel()
 	s1, _ := TestACLServer(t, func(c *Config) {
@@ -991,6 +1084,25 @@ func TestDeploymentEndpoint_List(t *testing.T) {
 	assert.EqualValues(resp.Index, 1000, "Wrong Index")
 	assert.Len(resp2.Deployments, 1, "Deployments")
 	assert.Equal(resp2.Deployments[0].ID, d.ID, "Deployment ID")
+
+	// Lookup the deploys by status
+	get = &structs.DeploymentListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+		Status: structs.DeploymentStatusFailed,
+	}
+
+	var resp3 structs.DeploymentListResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Deployment.List", get, &resp3), "RPC")
+	assert.Len(resp3.Deployments, 0, "Deployments")
+
+	get.Status = d.Status
+	var resp4 structs.DeploymentListResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Deployment.List", get, &resp4), "RPC")
+	assert.Len(resp4.Deployments, 1, "Deployments")
+	assert.Equal(resp4.Deployments[0].ID, d.ID, "Deployment ID")
 }
 
 func TestDeploymentEndpoint_List_ACL(t *testing.T) {
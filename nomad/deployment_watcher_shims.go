@@ -53,3 +53,26 @@ func (d *deploymentWatcherRaftShim) UpdateAllocDesiredTransition(req *structs.Al
 	fsmErrIntf, index, raftErr := d.apply(structs.AllocUpdateDesiredTransitionRequestType, req)
 	return d.convertApplyErrors(fsmErrIntf, index, raftErr)
 }
+
+// deploymentWatcherRegionShim is the shim that provides the deployments of
+// the peer regions of multiregion jobs to the deployment watcher.
+type deploymentWatcherRegionShim struct {
+	srv *Server
+}
+
+func (d *deploymentWatcherRegionShim) RegionJobDeployments(region, namespace, jobID string) ([]*structs.Deployment, error) {
+	args := &structs.JobSpecificRequest{
+		JobID: jobID,
+		QueryOptions: structs.QueryOptions{
+			Region:     region,
+			Namespace:  namespace,
+			AuthToken:  d.srv.ReplicationToken(),
+			AllowStale: true,
+		},
+	}
+	var resp structs.DeploymentListResponse
+	if err := d.srv.RPC("Job.Deployments", args, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Deployments, nil
+}
@@ -50,6 +50,14 @@ type deploymentTriggers interface {
 	// upsertDeploymentAllocHealth is used to set the health of allocations in a
 	// deployment
 	upsertDeploymentAllocHealth(req *structs.ApplyDeploymentAllocHealthRequest) (uint64, error)
+
+	// regionJobDeployments is used to lookup the deployments of a job in a
+	// peer region
+	regionJobDeployments(region, namespace, jobID string) ([]*structs.Deployment, error)
+
+	// notifyDeployment is used to notify external tooling of a deployment
+	// event
+	notifyDeployment(n *DeploymentNotification)
 }
 
 // deploymentWatcher is used to watch a single deployment and trigger the
@@ -77,6 +85,10 @@ type deploymentWatcher struct {
 	// j is the job the deployment is for
 	j *structs.Job
 
+	// multiregionPollInterval is the interval at which the deployments of
+	// the peer regions are checked when the job is multiregion
+	multiregionPollInterval time.Duration
+
 	// outstandingBatch marks whether an outstanding function exists to create
 	// the evaluation. Access should be done through the lock.
 	outstandingBatch bool
@@ -100,20 +112,22 @@ type deploymentWatcher struct {
 // deployments and trigger the scheduler as needed.
 func newDeploymentWatcher(parent context.Context, queryLimiter *rate.Limiter,
 	logger log.Logger, state *state.StateStore, d *structs.Deployment,
-	j *structs.Job, triggers deploymentTriggers) *deploymentWatcher {
+	j *structs.Job, triggers deploymentTriggers,
+	multiregionPollInterval time.Duration) *deploymentWatcher {
 
 	ctx, exitFn := context.WithCancel(parent)
 	w := &deploymentWatcher{
-		queryLimiter:       queryLimiter,
-		deploymentID:       d.ID,
-		deploymentUpdateCh: make(chan struct{}, 1),
-		d:                  d,
-		j:                  j,
-		state:              state,
-		deploymentTriggers: triggers,
-		logger:             logger.With("deployment_id", d.ID, "job", j.NamespacedID()),
-		ctx:                ctx,
-		exitFn:             exitFn,
+		queryLimiter:            queryLimiter,
+		deploymentID:            d.ID,
+		deploymentUpdateCh:      make(chan struct{}, 1),
+		d:                       d,
+		j:                       j,
+		multiregionPollInterval: multiregionPollInterval,
+		state:                   state,
+		deploymentTriggers:      triggers,
+		logger:                  logger.With("deployment_id", d.ID, "job", j.NamespacedID()),
+		ctx:                     ctx,
+		exitFn:                  exitFn,
 	}
 
 	// Start the long lived watcher that scans for allocation updates
@@ -145,6 +159,22 @@ func (w *deploymentWatcher) getDeployment() *structs.Deployment {
 func (w *deploymentWatcher) SetAllocHealth(
 	req *structs.DeploymentAllocHealthRequest,
 	resp *structs.DeploymentUpdateResponse) error {
+	return w.setAllocHealth(req, nil, resp)
+}
+
+// SetGroupHealth marks allocations healthy on behalf of an operator, recording
+// the given overrides on the deployment's task groups.
+func (w *deploymentWatcher) SetGroupHealth(
+	req *structs.DeploymentAllocHealthRequest,
+	overrides map[string]*structs.DeploymentHealthOverride,
+	resp *structs.DeploymentUpdateResponse) error {
+	return w.setAllocHealth(req, overrides, resp)
+}
+
+func (w *deploymentWatcher) setAllocHealth(
+	req *structs.DeploymentAllocHealthRequest,
+	overrides map[string]*structs.DeploymentHealthOverride,
+	resp *structs.DeploymentUpdateResponse) error {
 
 	// If we are failing the deployment, update the status and potentially
 	// rollback
@@ -209,6 +239,7 @@ func (w *deploymentWatcher) SetAllocHealth(
 		Eval:                         w.getEval(),
 		DeploymentUpdate:             u,
 		Job:                          j,
+		HealthOverrides:              overrides,
 	}
 
 	index, err := w.upsertDeploymentAllocHealth(areq)
@@ -372,10 +403,19 @@ func (w *deploymentWatcher) watch() {
 		deadlineTimer = time.NewTimer(currentDeadline.Sub(time.Now()))
 	}
 
+	// The deployments of multiregion jobs follow the deployments of their
+	// peer regions
+	var peerTicker <-chan time.Time
+	if w.j.IsMultiregion() {
+		ticker := time.NewTicker(w.multiregionPollInterval)
+		defer ticker.Stop()
+		peerTicker = ticker.C
+	}
+
 	allocIndex := uint64(1)
 	var updates *allocUpdates
 
-	rollback, deadlineHit := false, false
+	rollback, deadlineHit, peerFailed := false, false, false
 
 FAIL:
 	for {
@@ -430,6 +470,17 @@ FAIL:
 				}
 			}
 
+		case <-peerTicker:
+			fail, err := w.handlePeerRegions()
+			if err != nil {
+				w.logger.Error("failed to check deployments of peer regions", "error", err)
+				continue
+			}
+			if fail {
+				peerFailed = true
+				break FAIL
+			}
+
 		case updates = <-w.getAllocsCh(allocIndex):
 			if err := updates.err; err != nil {
 				if err == context.Canceled || w.ctx.Err() == context.Canceled {
@@ -460,6 +511,12 @@ FAIL:
 				break FAIL
 			}
 
+			// Promote the deployment once its canaries are healthy if
+			// requested
+			if err := w.autoPromoteDeployment(updates.allocs); err != nil {
+				w.logger.Error("failed to auto promote deployment", "error", err)
+			}
+
 			// Create an eval to push the deployment along
 			if res.createEval || len(res.allowReplacements) != 0 {
 				w.createBatchedUpdate(res.allowReplacements, allocIndex)
@@ -467,15 +524,25 @@ FAIL:
 		}
 	}
 
+	// The deployment may have already been failed, such as by marking an
+	// allocation unhealthy through the API, in which case there is nothing
+	// left to do
+	if d, err := w.state.DeploymentByID(nil, w.deploymentID); err == nil && d != nil && !d.Active() {
+		return
+	}
+
 	// Change the deployments status to failed
 	desc := structs.DeploymentStatusDescriptionFailedAllocations
 	if deadlineHit {
 		desc = structs.DeploymentStatusDescriptionProgressDeadline
+	} else if peerFailed {
+		desc = structs.DeploymentStatusDescriptionFailedByPeer
 	}
 
-	// Rollback to the old job if necessary
+	// Rollback to the old job if necessary. Multiregion jobs are not rolled
+	// back as the versions of their regions must stay in step.
 	var j *structs.Job
-	if rollback {
+	if rollback && !w.j.IsMultiregion() {
 		var err error
 		j, err = w.latestStableJob()
 		if err != nil {
@@ -496,7 +563,100 @@ FAIL:
 	u := w.getDeploymentStatusUpdate(structs.DeploymentStatusFailed, desc)
 	if _, err := w.upsertDeploymentStatusUpdate(u, e, j); err != nil {
 		w.logger.Error("failed to update deployment status", "error", err)
+		return
+	}
+
+	// Alert external tooling that the deployment didn't progress in time
+	if deadlineHit {
+		w.notifyDeployment(w.getDeploymentNotification(DeploymentEventProgressDeadline, u, j))
+	}
+}
+
+// handlePeerRegions checks the deployments of the same job version in the
+// peer regions of a multiregion job. A pending deployment is started once
+// enough of the earlier regions are done and a blocked deployment succeeds
+// once all the peer regions are done. It returns whether the deployment must
+// fail because of a failed peer region.
+func (w *deploymentWatcher) handlePeerRegions() (bool, error) {
+	d := w.getDeployment()
+	multiregion := w.j.Multiregion
+	local := multiregion.Index(w.j.Region)
+	if local < 0 {
+		return false, nil
+	}
+
+	var maxParallel int
+	var onFailure string
+	if s := multiregion.Strategy; s != nil {
+		maxParallel, onFailure = s.MaxParallel, s.OnFailure
+	}
+
+	allDone, doneBefore := true, 0
+	for i, region := range multiregion.Regions {
+		if i == local {
+			continue
+		}
+
+		deploys, err := w.regionJobDeployments(region.Name, w.j.Namespace, w.j.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to lookup deployments in region %q: %v", region.Name, err)
+		}
+
+		// Find the latest deployment of this version of the job
+		var peer *structs.Deployment
+		for _, pd := range deploys {
+			if pd.JobVersion == d.JobVersion && (peer == nil || pd.CreateIndex > peer.CreateIndex) {
+				peer = pd
+			}
+		}
+
+		done := false
+		if peer != nil {
+			switch peer.Status {
+			case structs.DeploymentStatusSuccessful, structs.DeploymentStatusBlocked:
+				done = true
+			case structs.DeploymentStatusFailed:
+				switch onFailure {
+				case structs.MultiregionOnFailureFailLocal:
+					done = true
+				case structs.MultiregionOnFailureFailAll:
+					return true, nil
+				default:
+					// Only the regions that haven't completed fail
+					if d.Status != structs.DeploymentStatusBlocked {
+						return true, nil
+					}
+				}
+			}
+		}
+
+		if !done {
+			allDone = false
+		} else if i < local {
+			doneBefore++
+		}
+	}
+
+	switch d.Status {
+	case structs.DeploymentStatusPending:
+		if maxParallel != 0 && local-doneBefore >= maxParallel {
+			return false, nil
+		}
+		u := w.getDeploymentStatusUpdate(structs.DeploymentStatusRunning, structs.DeploymentStatusDescriptionRunning)
+		if _, err := w.upsertDeploymentStatusUpdate(u, w.getEval(), nil); err != nil {
+			return false, err
+		}
+	case structs.DeploymentStatusBlocked:
+		if !allDone {
+			return false, nil
+		}
+		u := w.getDeploymentStatusUpdate(structs.DeploymentStatusSuccessful, structs.DeploymentStatusDescriptionSuccessful)
+		if _, err := w.upsertDeploymentStatusUpdate(u, nil, nil); err != nil {
+			return false, err
+		}
 	}
+
+	return false, nil
 }
 
 // allocUpdateResult is used to return the desired actions given the newest set
@@ -571,6 +731,43 @@ func (w *deploymentWatcher) handleAllocUpdate(allocs []*structs.AllocListStub) (
 	return res, nil
 }
 
+// autoPromoteDeployment promotes the deployment if all of its task groups that
+// require promotion have auto promote set and enough healthy canaries.
+func (w *deploymentWatcher) autoPromoteDeployment(allocs []*structs.AllocListStub) error {
+	d := w.getDeployment()
+	if !d.RequiresPromotion() || !d.HasAutoPromote() {
+		return nil
+	}
+
+	healthy := make(map[string]bool, len(allocs))
+	for _, alloc := range allocs {
+		healthy[alloc.ID] = alloc.DeploymentStatus.IsHealthy()
+	}
+
+	for _, dstate := range d.TaskGroups {
+		if dstate.DesiredCanaries == 0 || dstate.Promoted {
+			continue
+		}
+		count := 0
+		for _, id := range dstate.PlacedCanaries {
+			if healthy[id] {
+				count++
+			}
+		}
+		if count < dstate.DesiredCanaries {
+			return nil
+		}
+	}
+
+	w.logger.Debug("auto promoting deployment")
+	req := &structs.DeploymentPromoteRequest{
+		DeploymentID: d.ID,
+		All:          true,
+	}
+	var resp structs.DeploymentUpdateResponse
+	return w.PromoteDeployment(req, &resp)
+}
+
 // shouldFail returns whether the job should be failed and whether it should
 // rolled back to an earlier stable version by examining the allocations in the
 // deployment.
@@ -773,6 +970,30 @@ func (w *deploymentWatcher) getDeploymentStatusUpdate(status, desc string) *stru
 	}
 }
 
+// getDeploymentNotification returns the notification of an event updating the
+// deployment, and optionally rolling back the job.
+func (w *deploymentWatcher) getDeploymentNotification(event string,
+	u *structs.DeploymentStatusUpdate, rollback *structs.Job) *DeploymentNotification {
+
+	d := w.getDeployment().Copy()
+	n := &DeploymentNotification{
+		Event:             event,
+		Time:              time.Now().UTC(),
+		Region:            w.j.Region,
+		Namespace:         d.Namespace,
+		JobID:             d.JobID,
+		JobVersion:        d.JobVersion,
+		DeploymentID:      d.ID,
+		Status:            u.Status,
+		StatusDescription: u.StatusDescription,
+		TaskGroups:        d.TaskGroups,
+	}
+	if rollback != nil {
+		n.RollbackJobVersion = helper.Uint64ToPtr(rollback.Version)
+	}
+	return n
+}
+
 type allocUpdates struct {
 	allocs []*structs.AllocListStub
 	index  uint64
@@ -824,7 +1045,7 @@ func (w *deploymentWatcher) getAllocsImpl(ws memdb.WatchSet, state *state.StateS
 	maxIndex := uint64(0)
 	stubs := make([]*structs.AllocListStub, 0, len(allocs))
 	for _, alloc := range allocs {
-		stubs = append(stubs, alloc.Stub())
+		stubs = append(stubs, alloc.Stub(nil))
 
 		if maxIndex < alloc.ModifyIndex {
 			maxIndex = alloc.ModifyIndex
@@ -24,6 +24,10 @@ const (
 	// desired transition and evaluation creation updates are batched across
 	// all deployment watchers before committing to Raft.
 	CrossDeploymentUpdateBatchDuration = 250 * time.Millisecond
+
+	// defaultMultiregionPollInterval is the interval at which the deployments
+	// of multiregion jobs check the deployments of their peer regions.
+	defaultMultiregionPollInterval = 5 * time.Second
 )
 
 var (
@@ -54,6 +58,14 @@ type DeploymentRaftEndpoints interface {
 	UpdateAllocDesiredTransition(req *structs.AllocUpdateDesiredTransitionRequest) (uint64, error)
 }
 
+// DeploymentRegionEndpoints exposes the deployment watcher to the other
+// regions, so that the deployments of multiregion jobs can follow the
+// deployments of their peer regions.
+type DeploymentRegionEndpoints interface {
+	// RegionJobDeployments returns the deployments of the job in the region
+	RegionJobDeployments(region, namespace, jobID string) ([]*structs.Deployment, error)
+}
+
 // Watcher is used to watch deployments and their allocations created
 // by the scheduler and trigger the scheduler when allocation health
 // transitions.
@@ -72,6 +84,18 @@ type Watcher struct {
 	// deployments watcher
 	raft DeploymentRaftEndpoints
 
+	// regions is used to lookup the deployments of the peer regions of
+	// multiregion jobs
+	regions DeploymentRegionEndpoints
+
+	// multiregionPollInterval is the interval at which the peer regions of
+	// multiregion deployments are checked
+	multiregionPollInterval time.Duration
+
+	// webhook is used to notify external tooling of failed deployments. It
+	// is nil if no webhook is configured.
+	webhook *Webhook
+
 	// state is the state that is watched for state changes.
 	state *state.StateStore
 
@@ -92,17 +116,27 @@ type Watcher struct {
 // NewDeploymentsWatcher returns a deployments watcher that is used to watch
 // deployments and trigger the scheduler as needed.
 func NewDeploymentsWatcher(logger log.Logger,
-	raft DeploymentRaftEndpoints, stateQueriesPerSecond float64,
-	updateBatchDuration time.Duration) *Watcher {
+	raft DeploymentRaftEndpoints, regions DeploymentRegionEndpoints,
+	stateQueriesPerSecond float64, updateBatchDuration time.Duration) *Watcher {
 
 	return &Watcher{
-		raft:                raft,
-		queryLimiter:        rate.NewLimiter(rate.Limit(stateQueriesPerSecond), 100),
-		updateBatchDuration: updateBatchDuration,
-		logger:              logger.Named("deployments_watcher"),
+		raft:                    raft,
+		regions:                 regions,
+		multiregionPollInterval: defaultMultiregionPollInterval,
+		queryLimiter:            rate.NewLimiter(rate.Limit(stateQueriesPerSecond), 100),
+		updateBatchDuration:     updateBatchDuration,
+		logger:                  logger.Named("deployments_watcher"),
 	}
 }
 
+// SetWebhook sets the webhook notified of failed deployments. It must be
+// called before the watcher is enabled.
+func (w *Watcher) SetWebhook(webhook *Webhook) {
+	w.l.Lock()
+	defer w.l.Unlock()
+	w.webhook = webhook
+}
+
 // SetEnabled is used to control if the watcher is enabled. The watcher
 // should only be enabled on the active leader. When being enabled the state is
 // passed in as it is no longer valid once a leader election has taken place.
@@ -252,7 +286,8 @@ func (w *Watcher) addLocked(d *structs.Deployment) (*deploymentWatcher, error) {
 		return nil, fmt.Errorf("deployment %q references unknown job %q", d.ID, d.JobID)
 	}
 
-	watcher := newDeploymentWatcher(w.ctx, w.queryLimiter, w.logger, w.state, d, job, w)
+	watcher := newDeploymentWatcher(w.ctx, w.queryLimiter, w.logger, w.state, d, job, w,
+		w.multiregionPollInterval)
 	w.watchers[d.ID] = watcher
 	return watcher, nil
 }
@@ -325,6 +360,18 @@ func (w *Watcher) SetAllocHealth(req *structs.DeploymentAllocHealthRequest, resp
 	return watcher.SetAllocHealth(req, resp)
 }
 
+// SetGroupHealth is used to mark allocations of a deployment healthy on behalf
+// of an operator. The overrides are recorded on the deployment's task groups.
+func (w *Watcher) SetGroupHealth(req *structs.DeploymentAllocHealthRequest,
+	overrides map[string]*structs.DeploymentHealthOverride, resp *structs.DeploymentUpdateResponse) error {
+	watcher, err := w.getOrCreateWatcher(req.DeploymentID)
+	if err != nil {
+		return err
+	}
+
+	return watcher.SetGroupHealth(req, overrides, resp)
+}
+
 // PromoteDeployment is used to promote a deployment. If promote is false,
 // deployment is marked as failed. Otherwise the deployment is updated and an
 // evaluation is created.
@@ -392,3 +439,28 @@ func (w *Watcher) upsertDeploymentPromotion(req *structs.ApplyDeploymentPromoteR
 func (w *Watcher) upsertDeploymentAllocHealth(req *structs.ApplyDeploymentAllocHealthRequest) (uint64, error) {
 	return w.raft.UpdateDeploymentAllocHealth(req)
 }
+
+// regionJobDeployments returns the deployments of the job in the given region
+func (w *Watcher) regionJobDeployments(region, namespace, jobID string) ([]*structs.Deployment, error) {
+	return w.regions.RegionJobDeployments(region, namespace, jobID)
+}
+
+// notifyDeployment sends the notification to the webhook, if any, without
+// blocking. Notifications in flight are abandoned when the watcher is
+// disabled.
+func (w *Watcher) notifyDeployment(n *DeploymentNotification) {
+	w.l.RLock()
+	webhook, ctx := w.webhook, w.ctx
+	w.l.RUnlock()
+
+	if webhook == nil {
+		return
+	}
+
+	go func() {
+		if err := webhook.Notify(ctx, n); err != nil {
+			w.logger.Error("failed to notify deployment webhook",
+				"deployment_id", n.DeploymentID, "event", n.Event, "error", err)
+		}
+	}()
+}
@@ -2,6 +2,7 @@ package deploymentwatcher
 
 import (
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,7 +20,7 @@ import (
 
 func testDeploymentWatcher(t *testing.T, qps float64, batchDur time.Duration) (*Watcher, *mockBackend) {
 	m := newMockBackend(t)
-	w := NewDeploymentsWatcher(testlog.HCLogger(t), m, qps, batchDur)
+	w := NewDeploymentsWatcher(testlog.HCLogger(t), m, m, qps, batchDur)
 	return w, m
 }
 
@@ -441,6 +442,79 @@ func TestWatcher_PromoteDeployment_HealthyCanaries(t *testing.T) {
 	m.AssertCalled(t, "UpdateDeploymentPromotion", mocker.MatchedBy(matcher))
 }
 
+// Test that a deployment is promoted automatically once its canaries are
+// healthy
+func TestWatcher_AutoPromoteDeployment(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	w, m := defaultTestDeploymentWatcher(t)
+	now := time.Now()
+
+	// Create a job, two canary allocs, and a deployment
+	j := mock.Job()
+	j.TaskGroups[0].Update = structs.DefaultUpdateStrategy.Copy()
+	j.TaskGroups[0].Update.MaxParallel = 2
+	j.TaskGroups[0].Update.Canary = 2
+	j.TaskGroups[0].Update.AutoPromote = true
+	j.TaskGroups[0].Update.ProgressDeadline = 0
+	d := mock.Deployment()
+	d.JobID = j.ID
+	d.StatusDescription = structs.DeploymentStatusDescriptionRunningAutoPromotion
+	a1 := mock.Alloc()
+	a1.DeploymentID = d.ID
+	a2 := mock.Alloc()
+	a2.DeploymentID = d.ID
+	d.TaskGroups[a1.TaskGroup].DesiredCanaries = 2
+	d.TaskGroups[a1.TaskGroup].AutoPromote = true
+	d.TaskGroups[a1.TaskGroup].PlacedCanaries = []string{a1.ID, a2.ID}
+	require.Nil(m.state.UpsertJob(m.nextIndex(), j), "UpsertJob")
+	require.Nil(m.state.UpsertDeployment(m.nextIndex(), d), "UpsertDeployment")
+	require.Nil(m.state.UpsertAllocs(m.nextIndex(), []*structs.Allocation{a1, a2}), "UpsertAllocs")
+
+	matchConfig := &matchDeploymentPromoteRequestConfig{
+		Promotion: &structs.DeploymentPromoteRequest{
+			DeploymentID: d.ID,
+			All:          true,
+		},
+		Eval: true,
+	}
+	matcher := matchDeploymentPromoteRequest(matchConfig)
+	m.On("UpdateDeploymentPromotion", mocker.MatchedBy(matcher)).Return(nil)
+
+	m1 := matchUpdateAllocDesiredTransitions([]string{d.ID})
+	m.On("UpdateAllocDesiredTransition", mocker.MatchedBy(m1)).Return(nil)
+
+	w.SetEnabled(true, m.state)
+	testutil.WaitForResult(func() (bool, error) { return 1 == len(w.watchers), nil },
+		func(err error) { require.Equal(1, len(w.watchers), "Should have 1 deployment") })
+
+	// A single healthy canary doesn't promote the deployment
+	healthy := func(a *structs.Allocation) *structs.Allocation {
+		c := a.Copy()
+		c.DeploymentStatus = &structs.AllocDeploymentStatus{
+			Healthy:   helper.BoolToPtr(true),
+			Timestamp: now,
+		}
+		return c
+	}
+	require.Nil(m.state.UpdateAllocsFromClient(m.nextIndex(), []*structs.Allocation{healthy(a1)}))
+	time.Sleep(100 * time.Millisecond)
+	m.AssertNotCalled(t, "UpdateDeploymentPromotion", mocker.Anything)
+
+	// Both healthy canaries promote the deployment
+	require.Nil(m.state.UpdateAllocsFromClient(m.nextIndex(), []*structs.Allocation{healthy(a2)}))
+	testutil.WaitForResult(func() (bool, error) {
+		dout, err := m.state.DeploymentByID(nil, d.ID)
+		if err != nil {
+			return false, err
+		}
+		return dout.TaskGroups[a1.TaskGroup].Promoted, fmt.Errorf("deployment not promoted")
+	}, func(err error) {
+		require.NoError(err)
+	})
+	m.AssertCalled(t, "UpdateDeploymentPromotion", mocker.MatchedBy(matcher))
+}
+
 // Test promoting a deployment with unhealthy canaries
 func TestWatcher_PromoteDeployment_UnhealthyCanaries(t *testing.T) {
 	t.Parallel()
@@ -1325,6 +1399,178 @@ func TestDeploymentWatcher_RollbackFailed(t *testing.T) {
 }
 
 // Test allocation updates and evaluation creation is batched between watchers
+// Test that the deployments of multiregion jobs follow the deployments of
+// their peer regions
+func TestDeploymentWatcher_Multiregion_PeerRegions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		regions    []string
+		onFailure  string
+		status     string
+		peerStatus string
+		expStatus  string
+		expDesc    string
+		expEval    bool
+	}{
+		{
+			name:       "pending starts after earlier region",
+			regions:    []string{"west", "global"},
+			status:     structs.DeploymentStatusPending,
+			peerStatus: structs.DeploymentStatusBlocked,
+			expStatus:  structs.DeploymentStatusRunning,
+			expDesc:    structs.DeploymentStatusDescriptionRunning,
+			expEval:    true,
+		},
+		{
+			name:       "blocked succeeds after peers",
+			regions:    []string{"global", "west"},
+			status:     structs.DeploymentStatusBlocked,
+			peerStatus: structs.DeploymentStatusSuccessful,
+			expStatus:  structs.DeploymentStatusSuccessful,
+			expDesc:    structs.DeploymentStatusDescriptionSuccessful,
+		},
+		{
+			name:       "pending fails after peer failure",
+			regions:    []string{"west", "global"},
+			status:     structs.DeploymentStatusPending,
+			peerStatus: structs.DeploymentStatusFailed,
+			expStatus:  structs.DeploymentStatusFailed,
+			expDesc:    structs.DeploymentStatusDescriptionFailedByPeer,
+			expEval:    true,
+		},
+		{
+			name:       "blocked fails after peer failure with fail_all",
+			regions:    []string{"global", "west"},
+			onFailure:  structs.MultiregionOnFailureFailAll,
+			status:     structs.DeploymentStatusBlocked,
+			peerStatus: structs.DeploymentStatusFailed,
+			expStatus:  structs.DeploymentStatusFailed,
+			expDesc:    structs.DeploymentStatusDescriptionFailedByPeer,
+			expEval:    true,
+		},
+		{
+			name:       "pending starts after peer failure with fail_local",
+			regions:    []string{"west", "global"},
+			onFailure:  structs.MultiregionOnFailureFailLocal,
+			status:     structs.DeploymentStatusPending,
+			peerStatus: structs.DeploymentStatusFailed,
+			expStatus:  structs.DeploymentStatusRunning,
+			expDesc:    structs.DeploymentStatusDescriptionRunning,
+			expEval:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			w, m := defaultTestDeploymentWatcher(t)
+			w.multiregionPollInterval = 10 * time.Millisecond
+
+			// Create a multiregion job and a deployment
+			j := mock.Job()
+			j.Multiregion = &structs.Multiregion{
+				Strategy: &structs.MultiregionStrategy{
+					MaxParallel: 1,
+					OnFailure:   tc.onFailure,
+				},
+			}
+			for _, r := range tc.regions {
+				j.Multiregion.Regions = append(j.Multiregion.Regions, &structs.MultiregionRegion{Name: r})
+			}
+			d := mock.Deployment()
+			d.JobID = j.ID
+			d.JobVersion = j.Version
+			d.Status = tc.status
+			require.Nil(m.state.UpsertJob(m.nextIndex(), j), "UpsertJob")
+			require.Nil(m.state.UpsertDeployment(m.nextIndex(), d), "UpsertDeployment")
+
+			// The peer region has a deployment of the same version
+			peer := mock.Deployment()
+			peer.JobID = j.ID
+			peer.JobVersion = j.Version
+			peer.Status = tc.peerStatus
+			m.On("RegionJobDeployments", "west", j.Namespace, j.ID).Return([]*structs.Deployment{peer}, nil)
+
+			matchConfig := &matchDeploymentStatusUpdateConfig{
+				DeploymentID:      d.ID,
+				Status:            tc.expStatus,
+				StatusDescription: tc.expDesc,
+				Eval:              tc.expEval,
+			}
+			matcher := matchDeploymentStatusUpdateRequest(matchConfig)
+			m.On("UpdateDeploymentStatus", mocker.MatchedBy(matcher)).Return(nil)
+
+			w.SetEnabled(true, m.state)
+			testutil.WaitForResult(func() (bool, error) {
+				d, err := m.state.DeploymentByID(nil, d.ID)
+				if err != nil {
+					return false, err
+				}
+				return d.Status == tc.expStatus, fmt.Errorf("bad status %q", d.Status)
+			}, func(err error) {
+				t.Fatal(err)
+			})
+
+			out, err := m.state.DeploymentByID(nil, d.ID)
+			require.NoError(err)
+			require.Equal(tc.expDesc, out.StatusDescription)
+			m.AssertCalled(t, "UpdateDeploymentStatus", mocker.MatchedBy(matcher))
+		})
+	}
+}
+
+// Test that a pending deployment waits while earlier regions deploy
+func TestDeploymentWatcher_Multiregion_PendingWaits(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	w, m := defaultTestDeploymentWatcher(t)
+	w.multiregionPollInterval = 10 * time.Millisecond
+
+	j := mock.Job()
+	j.Multiregion = &structs.Multiregion{
+		Strategy: &structs.MultiregionStrategy{MaxParallel: 1},
+		Regions: []*structs.MultiregionRegion{
+			{Name: "west"},
+			{Name: "global"},
+		},
+	}
+	d := mock.Deployment()
+	d.JobID = j.ID
+	d.JobVersion = j.Version
+	d.Status = structs.DeploymentStatusPending
+	require.Nil(m.state.UpsertJob(m.nextIndex(), j), "UpsertJob")
+	require.Nil(m.state.UpsertDeployment(m.nextIndex(), d), "UpsertDeployment")
+
+	// The earlier region is still running and has a failed deployment of an
+	// older version
+	running := mock.Deployment()
+	running.JobID = j.ID
+	running.JobVersion = j.Version
+	old := mock.Deployment()
+	old.JobID = j.ID
+	old.JobVersion = j.Version + 1
+	old.Status = structs.DeploymentStatusFailed
+	var lookups int32
+	m.On("RegionJobDeployments", "west", j.Namespace, j.ID).Return([]*structs.Deployment{running, old}, nil).
+		Run(func(mocker.Arguments) { atomic.AddInt32(&lookups, 1) })
+
+	w.SetEnabled(true, m.state)
+	testutil.WaitForResult(func() (bool, error) {
+		calls := atomic.LoadInt32(&lookups)
+		return calls >= 3, fmt.Errorf("got %d peer lookups", calls)
+	}, func(err error) {
+		t.Fatal(err)
+	})
+
+	out, err := m.state.DeploymentByID(nil, d.ID)
+	require.NoError(err)
+	require.Equal(structs.DeploymentStatusPending, out.Status)
+	m.AssertNotCalled(t, "UpdateDeploymentStatus", mocker.Anything)
+}
+
 func TestWatcher_BatchAllocUpdates(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -84,6 +84,11 @@ func matchUpdateAllocDesiredTransitionReschedule(allocIDs []string) func(update
 	}
 }
 
+func (m *mockBackend) RegionJobDeployments(region, namespace, jobID string) ([]*structs.Deployment, error) {
+	rargs := m.Called(region, namespace, jobID)
+	return rargs.Get(0).([]*structs.Deployment), rargs.Error(1)
+}
+
 func (m *mockBackend) UpsertJob(job *structs.Job) (uint64, error) {
 	m.Called(job)
 	i := m.nextIndex()
@@ -0,0 +1,147 @@
+package deploymentwatcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// DeploymentEventProgressDeadline is the event of the notifications sent
+	// when a deployment fails because it hit its progress deadline.
+	DeploymentEventProgressDeadline = "DeploymentProgressDeadline"
+
+	// WebhookSignatureHeader is the header carrying the hex encoded
+	// HMAC-SHA256 of the body of the notifications, prefixed by "sha256=".
+	WebhookSignatureHeader = "X-Nomad-Signature"
+
+	// defaultWebhookTimeout is the timeout of a webhook request if none is
+	// configured.
+	defaultWebhookTimeout = 10 * time.Second
+
+	// webhookAttempts is the number of times a notification is sent before
+	// giving up on it.
+	webhookAttempts = 3
+
+	// webhookRetryBackoff is the wait before retrying a notification. It is
+	// doubled on every attempt.
+	webhookRetryBackoff = 1 * time.Second
+)
+
+// DeploymentNotification is the JSON body of the notifications sent to the
+// deployment webhook.
+type DeploymentNotification struct {
+	// Event is the event the notification is for.
+	Event string
+
+	// Time is the time of the event.
+	Time time.Time
+
+	Region            string
+	Namespace         string
+	JobID             string
+	JobVersion        uint64
+	DeploymentID      string
+	Status            string
+	StatusDescription string
+
+	// RollbackJobVersion is the version of the job being rolled back to, or
+	// nil if the job isn't being rolled back.
+	RollbackJobVersion *uint64
+
+	// TaskGroups is the state of the deployment of each task group.
+	TaskGroups map[string]*structs.DeploymentState
+}
+
+// Webhook sends the notifications of failed deployments to an HTTP endpoint,
+// so that external tooling can alert on them. When an HMAC key is set, the
+// body of each request is signed with it.
+type Webhook struct {
+	url     string
+	hmacKey []byte
+	client  *http.Client
+	logger  log.Logger
+}
+
+// NewWebhook returns a webhook posting notifications to the URL. A zero
+// timeout uses the default timeout.
+func NewWebhook(logger log.Logger, url, hmacKey string, timeout time.Duration) *Webhook {
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	return &Webhook{
+		url:     url,
+		hmacKey: []byte(hmacKey),
+		client:  &http.Client{Timeout: timeout},
+		logger:  logger.Named("deployment_webhook"),
+	}
+}
+
+// Notify sends the notification, retrying on failure until it is delivered,
+// the attempts are exhausted or the context is cancelled.
+func (h *Webhook) Notify(ctx context.Context, n *DeploymentNotification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %v", err)
+	}
+
+	backoff := webhookRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err = h.send(ctx, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+
+		h.logger.Warn("failed to send notification, retrying",
+			"deployment_id", n.DeploymentID, "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send posts the body to the webhook once.
+func (h *Webhook) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequest("POST", h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if len(h.hmacKey) != 0 {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+WebhookSignature(h.hmacKey, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookSignature returns the hex encoded HMAC-SHA256 of the body, which
+// receivers can compute to authenticate the notifications.
+func WebhookSignature(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
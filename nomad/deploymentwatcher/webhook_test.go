@@ -0,0 +1,167 @@
+package deploymentwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	mocker "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRequest struct {
+	signature string
+	body      []byte
+}
+
+// testWebhookServer returns a server recording the requests it receives. The
+// first failures requests are answered with an error.
+func testWebhookServer(t *testing.T, failures int32) (*httptest.Server, <-chan *webhookRequest) {
+	reqs := make(chan *webhookRequest, 10)
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+		reqs <- &webhookRequest{
+			signature: r.Header.Get(WebhookSignatureHeader),
+			body:      body,
+		}
+		if atomic.AddInt32(&count, 1) <= failures {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	return srv, reqs
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, reqs := testWebhookServer(t, 1)
+	defer srv.Close()
+
+	h := NewWebhook(testlog.HCLogger(t), srv.URL, "secret", 0)
+	n := &DeploymentNotification{
+		Event:        DeploymentEventProgressDeadline,
+		JobID:        "example",
+		DeploymentID: "abc",
+	}
+	require.NoError(h.Notify(context.Background(), n))
+
+	// The first attempt failed and was retried
+	require.Len(reqs, 2)
+	<-reqs
+	req := <-reqs
+
+	require.Equal("sha256="+WebhookSignature([]byte("secret"), req.body), req.signature)
+	var got DeploymentNotification
+	require.NoError(json.Unmarshal(req.body, &got))
+	require.Equal(n.JobID, got.JobID)
+	require.Equal(n.DeploymentID, got.DeploymentID)
+}
+
+func TestWebhook_Notify_Unsigned(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, reqs := testWebhookServer(t, 0)
+	defer srv.Close()
+
+	h := NewWebhook(testlog.HCLogger(t), srv.URL, "", 0)
+	require.NoError(h.Notify(context.Background(), &DeploymentNotification{}))
+	require.Empty((<-reqs).signature)
+}
+
+func TestWebhook_Notify_Cancelled(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, _ := testWebhookServer(t, webhookAttempts)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := NewWebhook(testlog.HCLogger(t), srv.URL, "", 0)
+	require.Error(h.Notify(ctx, &DeploymentNotification{}))
+}
+
+func TestDeploymentWatcher_Watch_ProgressDeadline_Webhook(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	w, m := testDeploymentWatcher(t, 1000.0, 1*time.Millisecond)
+
+	srv, reqs := testWebhookServer(t, 0)
+	defer srv.Close()
+	w.SetWebhook(NewWebhook(testlog.HCLogger(t), srv.URL, "secret", 0))
+
+	// Create a job, alloc, and a deployment
+	j := mock.Job()
+	j.TaskGroups[0].Update = structs.DefaultUpdateStrategy.Copy()
+	j.TaskGroups[0].Update.MaxParallel = 2
+	j.TaskGroups[0].Update.ProgressDeadline = 500 * time.Millisecond
+	j.Stable = true
+	d := mock.Deployment()
+	d.JobID = j.ID
+	d.TaskGroups["web"].ProgressDeadline = 500 * time.Millisecond
+	a := mock.Alloc()
+	now := time.Now()
+	a.CreateTime = now.UnixNano()
+	a.ModifyTime = now.UnixNano()
+	a.DeploymentID = d.ID
+	require.Nil(m.state.UpsertJob(m.nextIndex(), j), "UpsertJob")
+	require.Nil(m.state.UpsertDeployment(m.nextIndex(), d), "UpsertDeployment")
+	require.Nil(m.state.UpsertAllocs(m.nextIndex(), []*structs.Allocation{a}), "UpsertAllocs")
+
+	c := &matchDeploymentStatusUpdateConfig{
+		DeploymentID:      d.ID,
+		Status:            structs.DeploymentStatusFailed,
+		StatusDescription: structs.DeploymentStatusDescriptionProgressDeadline,
+		Eval:              true,
+	}
+	m2 := matchDeploymentStatusUpdateRequest(c)
+	m.On("UpdateDeploymentStatus", mocker.MatchedBy(m2)).Return(nil)
+
+	w.SetEnabled(true, m.state)
+	testutil.WaitForResult(func() (bool, error) { return 1 == len(w.watchers), nil },
+		func(err error) { require.Equal(1, len(w.watchers), "Should have 1 deployment") })
+
+	// Update the alloc to be unhealthy so the deadline fails the deployment
+	a2 := a.Copy()
+	a2.DeploymentStatus = &structs.AllocDeploymentStatus{
+		Healthy:   helper.BoolToPtr(false),
+		Timestamp: now,
+	}
+	require.Nil(m.state.UpdateAllocsFromClient(m.nextIndex(), []*structs.Allocation{a2}))
+
+	var req *webhookRequest
+	select {
+	case req = <-reqs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the webhook")
+	}
+
+	require.Equal("sha256="+WebhookSignature([]byte("secret"), req.body), req.signature)
+	var n DeploymentNotification
+	require.NoError(json.Unmarshal(req.body, &n))
+	require.Equal(DeploymentEventProgressDeadline, n.Event)
+	require.Equal(j.ID, n.JobID)
+	require.Equal(j.Namespace, n.Namespace)
+	require.Equal(d.ID, n.DeploymentID)
+	require.Equal(structs.DeploymentStatusFailed, n.Status)
+	require.Equal(structs.DeploymentStatusDescriptionProgressDeadline, n.StatusDescription)
+	require.Nil(n.RollbackJobVersion)
+	require.Contains(n.TaskGroups, "web")
+}
@@ -195,7 +195,7 @@ func (n *NodeDrainer) SetEnabled(enabled bool, state *state.StateStore) {
 	n.enabled = enabled
 	if enabled {
 		n.flush(state)
-		go n.run(n.ctx)
+		go n.run(n.ctx, n.deadlineNotifier, n.jobWatcher)
 	} else if !enabled && n.exitFn != nil {
 		n.exitFn()
 	}
@@ -221,17 +221,18 @@ func (n *NodeDrainer) flush(state *state.StateStore) {
 }
 
 // run is a long lived event handler that receives changes from the relevant
-// watchers and takes action based on them.
-func (n *NodeDrainer) run(ctx context.Context) {
+// watchers and takes action based on them. The watchers are passed in rather
+// than read from the drainer since they are replaced when it is re-enabled.
+func (n *NodeDrainer) run(ctx context.Context, deadlineNotifier DrainDeadlineNotifier, jobWatcher DrainingJobWatcher) {
 	for {
 		select {
-		case <-n.ctx.Done():
+		case <-ctx.Done():
 			return
-		case nodes := <-n.deadlineNotifier.NextBatch():
+		case nodes := <-deadlineNotifier.NextBatch():
 			n.handleDeadlinedNodes(nodes)
-		case req := <-n.jobWatcher.Drain():
+		case req := <-jobWatcher.Drain():
 			n.handleJobAllocDrain(req)
-		case allocs := <-n.jobWatcher.Migrated():
+		case allocs := <-jobWatcher.Migrated():
 			n.handleMigratedAllocs(allocs)
 		}
 	}
@@ -0,0 +1,108 @@
+package nomad
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// VariablesEncryptionKeyLen is the length of the AES-256 key used to
+	// encrypt variables.
+	VariablesEncryptionKeyLen = 32
+)
+
+// Encrypter encrypts variables before they are written to raft and decrypts
+// them when they are read. The namespace and path of a variable are used as
+// additional data so encrypted items can not be moved to another variable.
+type Encrypter struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewEncrypter returns an encrypter using the AES-256 key.
+func NewEncrypter(key []byte) (*Encrypter, error) {
+	if len(key) != VariablesEncryptionKeyLen {
+		return nil, fmt.Errorf("variables encryption key must be %d bytes; got %d", VariablesEncryptionKeyLen, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key ID allows detecting variables encrypted with another key
+	// without exposing the key
+	sum := sha256.Sum256(key)
+	return &Encrypter{
+		keyID: hex.EncodeToString(sum[:8]),
+		aead:  aead,
+	}, nil
+}
+
+// KeyID returns the ID of the encryption key.
+func (e *Encrypter) KeyID() string {
+	return e.keyID
+}
+
+// Encrypt returns the variable with its items encrypted.
+func (e *Encrypter) Encrypt(v *structs.VariableDecrypted) (*structs.VariableEncrypted, error) {
+	plaintext, err := json.Marshal(v.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode variable items: %v", err)
+	}
+
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	return &structs.VariableEncrypted{
+		VariableMetadata: v.VariableMetadata,
+		KeyID:            e.keyID,
+		Data:             e.aead.Seal(nonce, nonce, plaintext, additionalData(&v.VariableMetadata)),
+	}, nil
+}
+
+// Decrypt returns the variable with its items decrypted.
+func (e *Encrypter) Decrypt(v *structs.VariableEncrypted) (*structs.VariableDecrypted, error) {
+	if v.KeyID != e.keyID {
+		return nil, fmt.Errorf("variable %q was encrypted with unknown key %q", v.Path, v.KeyID)
+	}
+
+	nonceSize := e.aead.NonceSize()
+	if len(v.Data) < nonceSize {
+		return nil, fmt.Errorf("variable %q data is too short", v.Path)
+	}
+	nonce, ciphertext := v.Data[:nonceSize], v.Data[nonceSize:]
+
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, additionalData(&v.VariableMetadata))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt variable %q: %v", v.Path, err)
+	}
+
+	var items map[string]string
+	if err := json.Unmarshal(plaintext, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode variable items: %v", err)
+	}
+
+	return &structs.VariableDecrypted{
+		VariableMetadata: v.VariableMetadata,
+		Items:            items,
+	}, nil
+}
+
+// additionalData binds the encrypted items to the variable's location.
+func additionalData(v *structs.VariableMetadata) []byte {
+	return []byte(v.Namespace + "/" + v.Path)
+}
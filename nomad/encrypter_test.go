@@ -0,0 +1,79 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func testVariablesKey(b byte) []byte {
+	key := make([]byte, VariablesEncryptionKeyLen)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestEncrypter_RoundTrip(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	_, err := NewEncrypter([]byte("short"))
+	require.Error(err)
+
+	e, err := NewEncrypter(testVariablesKey(1))
+	require.NoError(err)
+	require.Len(e.KeyID(), 16)
+
+	v := &structs.VariableDecrypted{
+		VariableMetadata: structs.VariableMetadata{
+			Namespace: structs.DefaultNamespace,
+			Path:      "app/db",
+		},
+		Items: map[string]string{"password": "hunter2"},
+	}
+	encrypted, err := e.Encrypt(v)
+	require.NoError(err)
+	require.Equal(e.KeyID(), encrypted.KeyID)
+	require.NotContains(string(encrypted.Data), "hunter2")
+
+	out, err := e.Decrypt(encrypted)
+	require.NoError(err)
+	require.Equal(v, out)
+}
+
+func TestEncrypter_Decrypt_Invalid(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	e, err := NewEncrypter(testVariablesKey(1))
+	require.NoError(err)
+	v := &structs.VariableDecrypted{
+		VariableMetadata: structs.VariableMetadata{
+			Namespace: structs.DefaultNamespace,
+			Path:      "app/db",
+		},
+		Items: map[string]string{"password": "hunter2"},
+	}
+	encrypted, err := e.Encrypt(v)
+	require.NoError(err)
+
+	// A different key can not decrypt the variable
+	other, err := NewEncrypter(testVariablesKey(2))
+	require.NoError(err)
+	_, err = other.Decrypt(encrypted)
+	require.Error(err)
+
+	// The items can not be moved to another path
+	moved := encrypted.Copy()
+	moved.Path = "app/other"
+	_, err = e.Decrypt(moved)
+	require.Error(err)
+
+	// Tampered data fails authentication
+	tampered := encrypted.Copy()
+	tampered.Data[len(tampered.Data)-1] ^= 0xff
+	_, err = e.Decrypt(tampered)
+	require.Error(err)
+}
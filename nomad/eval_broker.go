@@ -11,6 +11,7 @@ import (
 	"context"
 
 	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/lib/delayheap"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -101,6 +102,11 @@ type EvalBroker struct {
 	// compounding after the first Nack.
 	subsequentNackDelay time.Duration
 
+	// tracer records the time evaluations spend in the ready queues, which
+	// are tracked by readyTimes. Tracing is disabled if it is nil.
+	tracer     *tracing.Tracer
+	readyTimes map[string]time.Time
+
 	l sync.RWMutex
 }
 
@@ -144,6 +150,7 @@ func NewEvalBroker(timeout, initialNackDelay, subsequentNackDelay time.Duration,
 		subsequentNackDelay:  subsequentNackDelay,
 		delayHeap:            delayheap.NewDelayHeap(),
 		delayedEvalsUpdateCh: make(chan struct{}, 1),
+		readyTimes:           make(map[string]time.Time),
 	}
 	b.stats.ByScheduler = make(map[string]*SchedulerStats)
 
@@ -299,6 +306,9 @@ func (b *EvalBroker) enqueueLocked(eval *structs.Evaluation, queue string) {
 	// Push onto the heap
 	heap.Push(&pending, eval)
 	b.ready[queue] = pending
+	if b.tracer != nil {
+		b.readyTimes[eval.ID] = time.Now()
+	}
 
 	// Update the stats
 	b.stats.TotalReady += 1
@@ -437,6 +447,18 @@ func (b *EvalBroker) dequeueForSched(sched string) (*structs.Evaluation, string,
 	// Increment the dequeue count
 	b.evals[eval.ID] += 1
 
+	// Record the time the evaluation waited for a worker
+	if readyTime, ok := b.readyTimes[eval.ID]; ok {
+		delete(b.readyTimes, eval.ID)
+		span := b.tracer.StartAt("eval_broker.ready", eval.ID, tracing.SpanKindInternal, readyTime)
+		span.SetAttribute("eval_id", eval.ID)
+		span.SetAttribute("job_id", eval.JobID)
+		span.SetAttribute("namespace", eval.Namespace)
+		span.SetAttribute("scheduler", sched)
+		span.SetAttribute("triggered_by", eval.TriggeredBy)
+		span.End()
+	}
+
 	// Update the stats
 	b.stats.TotalReady -= 1
 	b.stats.TotalUnacked += 1
@@ -720,6 +742,7 @@ func (b *EvalBroker) flush() {
 	b.unack = make(map[string]*unackEval)
 	b.timeWait = make(map[string]*time.Timer)
 	b.delayHeap = delayheap.NewDelayHeap()
+	b.readyTimes = make(map[string]time.Time)
 }
 
 // evalWrapper satisfies the HeapNode interface
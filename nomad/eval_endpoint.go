@@ -2,6 +2,7 @@ package nomad
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	metrics "github.com/armon/go-metrics"
@@ -35,9 +36,10 @@ func (e *Eval) GetEval(args *structs.EvalSpecificRequest,
 	defer metrics.MeasureSince([]string{"nomad", "eval", "get_eval"}, time.Now())
 
 	// Check for read-job permissions
-	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := e.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -52,6 +54,11 @@ func (e *Eval) GetEval(args *structs.EvalSpecificRequest,
 				return err
 			}
 
+			// Check the read-job permissions of the evaluation's job
+			if out != nil && aclObj != nil && !aclObj.AllowJobOp(out.Namespace, out.JobID, acl.NamespaceCapabilityReadJob) {
+				return structs.ErrPermissionDenied
+			}
+
 			// Setup the output
 			reply.Eval = out
 			if out != nil {
@@ -329,9 +336,10 @@ func (e *Eval) List(args *structs.EvalListRequest,
 	defer metrics.MeasureSince([]string{"nomad", "eval", "list"}, time.Now())
 
 	// Check for read-job permissions
-	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := e.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -345,6 +353,8 @@ func (e *Eval) List(args *structs.EvalListRequest,
 			var iter memdb.ResultIterator
 			if prefix := args.QueryOptions.Prefix; prefix != "" {
 				iter, err = state.EvalsByIDPrefix(ws, args.RequestNamespace(), prefix)
+			} else if args.Status != "" {
+				iter, err = state.EvalsByNamespaceStatus(ws, args.RequestNamespace(), args.Status)
 			} else {
 				iter, err = state.EvalsByNamespace(ws, args.RequestNamespace())
 			}
@@ -359,6 +369,12 @@ func (e *Eval) List(args *structs.EvalListRequest,
 					break
 				}
 				eval := raw.(*structs.Evaluation)
+				if args.Status != "" && eval.Status != args.Status {
+					continue
+				}
+				if aclObj != nil && !aclObj.AllowJobOp(eval.Namespace, eval.JobID, acl.NamespaceCapabilityReadJob) {
+					continue
+				}
 				evals = append(evals, eval)
 			}
 			reply.Evaluations = evals
@@ -377,6 +393,161 @@ func (e *Eval) List(args *structs.EvalListRequest,
 	return e.srv.blockingRPC(&opts)
 }
 
+// PlacementFailures is used to list the placement failures of the blocked
+// evaluations
+func (e *Eval) PlacementFailures(args *structs.EvalPlacementFailuresRequest,
+	reply *structs.EvalPlacementFailuresResponse) error {
+	if done, err := e.srv.forward("Eval.PlacementFailures", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "eval", "placement_failures"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			iter, err := state.EvalsByNamespace(ws, args.RequestNamespace())
+			if err != nil {
+				return err
+			}
+
+			failures, err := placementFailures(ws, state, iter)
+			if err != nil {
+				return err
+			}
+			reply.Failures = failures
+
+			// Use the last index that affected the evals table
+			index, err := state.Index("evals")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			e.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return e.srv.blockingRPC(&opts)
+}
+
+// Related is used to list the evaluations related to an evaluation, which are
+// linked to it through their previous, next and blocked evaluations, such as
+// the blocked evaluation created by an evaluation that failed to place
+// allocations and the evaluation replacing it once it is unblocked.
+func (e *Eval) Related(args *structs.EvalSpecificRequest,
+	reply *structs.EvalRelatedResponse) error {
+	if done, err := e.srv.forward("Eval.Related", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "eval", "related"}, time.Now())
+
+	// Check for read-job permissions
+	aclObj, err := e.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			reply.Evaluations = nil
+
+			eval, err := state.EvalByID(ws, args.EvalID)
+			if err != nil {
+				return err
+			}
+
+			if eval != nil {
+				// Check the read-job permissions of the evaluation's job
+				if aclObj != nil && !aclObj.AllowJobOp(eval.Namespace, eval.JobID, acl.NamespaceCapabilityReadJob) {
+					return structs.ErrPermissionDenied
+				}
+
+				// Related evaluations are always for the same job
+				evals, err := state.EvalsByJob(ws, eval.Namespace, eval.JobID)
+				if err != nil {
+					return err
+				}
+				reply.Evaluations = relatedEvals(eval, evals)
+			}
+
+			// Use the last index that affected the evals table
+			index, err := state.Index("evals")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			e.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return e.srv.blockingRPC(&opts)
+}
+
+// relatedEvals returns the evaluations connected to the evaluation by their
+// previous, next and blocked evaluation links, including itself, sorted by
+// creation.
+func relatedEvals(eval *structs.Evaluation, evals []*structs.Evaluation) []*structs.Evaluation {
+	byID := map[string]*structs.Evaluation{eval.ID: eval}
+	links := make(map[string][]string)
+	link := func(a, b string) {
+		if a == "" || b == "" {
+			return
+		}
+		links[a] = append(links[a], b)
+		links[b] = append(links[b], a)
+	}
+	for _, e := range evals {
+		byID[e.ID] = e
+		link(e.ID, e.PreviousEval)
+		link(e.ID, e.NextEval)
+		link(e.ID, e.BlockedEval)
+	}
+
+	// Walk the links from the evaluation, skipping those to evaluations that
+	// have been garbage collected
+	seen := map[string]struct{}{eval.ID: {}}
+	queue := []string{eval.ID}
+	var related []*structs.Evaluation
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		related = append(related, byID[id])
+
+		for _, next := range links[id] {
+			if _, ok := seen[next]; ok {
+				continue
+			}
+			seen[next] = struct{}{}
+			if _, ok := byID[next]; ok {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].CreateIndex != related[j].CreateIndex {
+			return related[i].CreateIndex < related[j].CreateIndex
+		}
+		return related[i].ID < related[j].ID
+	})
+	return related
+}
+
 // Allocations is used to list the allocations for an evaluation
 func (e *Eval) Allocations(args *structs.EvalSpecificRequest,
 	reply *structs.EvalAllocationsResponse) error {
@@ -386,9 +557,10 @@ func (e *Eval) Allocations(args *structs.EvalSpecificRequest,
 	defer metrics.MeasureSince([]string{"nomad", "eval", "allocations"}, time.Now())
 
 	// Check for read-job permissions
-	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := e.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -407,7 +579,10 @@ func (e *Eval) Allocations(args *structs.EvalSpecificRequest,
 			if len(allocs) > 0 {
 				reply.Allocations = make([]*structs.AllocListStub, 0, len(allocs))
 				for _, alloc := range allocs {
-					reply.Allocations = append(reply.Allocations, alloc.Stub())
+					if aclObj != nil && !aclObj.AllowJobOp(alloc.Namespace, alloc.JobID, acl.NamespaceCapabilityReadJob) {
+						continue
+					}
+					reply.Allocations = append(reply.Allocations, alloc.Stub(nil))
 				}
 			}
 
@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/nomad/scheduler"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEvalEndpoint_GetEval(t *testing.T) {
@@ -659,6 +660,25 @@ func TestEvalEndpoint_List(t *testing.T) {
 		t.Fatalf("bad: %#v", resp2.Evaluations)
 	}
 
+	// Lookup the evals by status
+	eval3 := mock.Eval()
+	eval3.Status = structs.EvalStatusBlocked
+	s1.fsm.State().UpsertEvals(1001, []*structs.Evaluation{eval3})
+
+	get = &structs.EvalListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+		Status: structs.EvalStatusBlocked,
+	}
+	var resp3 structs.EvalListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Eval.List", get, &resp3); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp3.Evaluations) != 1 || resp3.Evaluations[0].ID != eval3.ID {
+		t.Fatalf("bad: %#v", resp3.Evaluations)
+	}
 }
 
 func TestEvalEndpoint_List_ACL(t *testing.T) {
@@ -792,6 +812,70 @@ func TestEvalEndpoint_List_Blocking(t *testing.T) {
 	}
 }
 
+func TestEvalEndpoint_PlacementFailures(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// A failed evaluation and the blocked evaluation it created
+	failed := mock.Eval()
+	failed.FailedTGAllocs = map[string]*structs.AllocMetric{
+		"web": {
+			NodesEvaluated:     2,
+			DimensionExhausted: map[string]int{"memory": 2},
+			ClassExhausted:     map[string]int{"large": 2},
+			CoalescedFailures:  3,
+		},
+	}
+	blocked := failed.CreateBlockedEval(nil, false, "")
+	failed.BlockedEval = blocked.ID
+
+	// A reblocked evaluation recording its own failures
+	reblocked := mock.Eval()
+	reblocked.Status = structs.EvalStatusBlocked
+	reblocked.FailedTGAllocs = map[string]*structs.AllocMetric{
+		"cache": {
+			ConstraintFiltered: map[string]int{"${attr.kernel.name} = linux": 1},
+		},
+	}
+
+	// Evaluations that aren't blocked are ignored
+	complete := mock.Eval()
+	complete.FailedTGAllocs = reblocked.FailedTGAllocs
+	require.NoError(state.UpsertEvals(1000, []*structs.Evaluation{failed, blocked, reblocked, complete}))
+
+	get := &structs.EvalPlacementFailuresRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+
+	// Reading requires the read-job capability
+	var resp structs.EvalPlacementFailuresResponse
+	err := msgpackrpc.CallWithCodec(codec, "Eval.PlacementFailures", get, &resp)
+	require.True(structs.IsErrPermissionDenied(err))
+
+	get.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Eval.PlacementFailures", get, &resp))
+	require.EqualValues(1000, resp.Index)
+	require.Len(resp.Failures, 2)
+
+	byTG := make(map[string]*structs.PlacementFailure)
+	for _, f := range resp.Failures {
+		byTG[f.TaskGroup] = f
+	}
+	require.Equal(blocked.ID, byTG["web"].EvalID)
+	require.Equal(4, byTG["web"].Unplaced)
+	require.Equal(2, byTG["web"].Metrics.DimensionExhausted["memory"])
+	require.Equal(reblocked.ID, byTG["cache"].EvalID)
+	require.Equal(1, byTG["cache"].Unplaced)
+}
+
 func TestEvalEndpoint_Allocations(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -830,6 +914,63 @@ func TestEvalEndpoint_Allocations(t *testing.T) {
 	}
 }
 
+func TestEvalEndpoint_Related(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create an evaluation that failed to place allocations, its blocked
+	// evaluation and the evaluation created when it was unblocked, as well
+	// as an unrelated evaluation of the same job
+	eval1 := mock.Eval()
+	blocked := mock.Eval()
+	blocked.JobID = eval1.JobID
+	blocked.Status = structs.EvalStatusBlocked
+	blocked.TriggeredBy = structs.EvalTriggerQueuedAllocs
+	blocked.PreviousEval = eval1.ID
+	eval1.BlockedEval = blocked.ID
+	eval2 := mock.Eval()
+	eval2.JobID = eval1.JobID
+	eval2.PreviousEval = blocked.ID
+	other := mock.Eval()
+	other.JobID = eval1.JobID
+
+	state := s1.fsm.State()
+	require.NoError(state.UpsertEvals(1000, []*structs.Evaluation{eval1, blocked}))
+	require.NoError(state.UpsertEvals(1001, []*structs.Evaluation{eval2, other}))
+
+	// Each evaluation of the lineage returns the whole lineage
+	for _, eval := range []*structs.Evaluation{eval1, blocked, eval2} {
+		get := &structs.EvalSpecificRequest{
+			EvalID:       eval.ID,
+			QueryOptions: structs.QueryOptions{Region: "global"},
+		}
+		var resp structs.EvalRelatedResponse
+		require.NoError(msgpackrpc.CallWithCodec(codec, "Eval.Related", get, &resp))
+		require.EqualValues(1001, resp.Index)
+
+		var ids []string
+		for _, e := range resp.Evaluations {
+			ids = append(ids, e.ID)
+		}
+		require.Len(ids, 3)
+		require.Equal(eval2.ID, ids[2])
+		require.ElementsMatch([]string{eval1.ID, blocked.ID}, ids[:2])
+	}
+
+	// Unknown evaluations have no related evaluations
+	get := &structs.EvalSpecificRequest{
+		EvalID:       uuid.Generate(),
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.EvalRelatedResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Eval.Related", get, &resp))
+	require.Nil(resp.Evaluations)
+}
+
 func TestEvalEndpoint_Allocations_ACL(t *testing.T) {
 	t.Parallel()
 	s1, root := TestACLServer(t, nil)
@@ -0,0 +1,270 @@
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/stream"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	// eventStreamHeartbeatInterval is how often an empty JSON object is sent
+	// to event stream subscribers while no events are published, so that
+	// idle connections are not closed by proxies.
+	eventStreamHeartbeatInterval = 10 * time.Second
+)
+
+// Event endpoint is used for streaming the events of state changes.
+type Event struct {
+	srv    *Server
+	logger log.Logger
+}
+
+func (e *Event) register() {
+	e.srv.streamingRpcs.Register("Event.Stream", e.stream)
+}
+
+// handleStreamResultError is a helper for sending an error with a potential
+// error code. The transmission of the error is ignored if the error has been
+// generated by the closing of the underlying transport.
+func (e *Event) handleStreamResultError(err error, code *int64, encoder *codec.Encoder) {
+	// Nothing to do as the conn is closed
+	if err == io.EOF || strings.Contains(err.Error(), "closed") {
+		return
+	}
+
+	// Attempt to send the error
+	encoder.Encode(&cstructs.StreamErrWrapper{
+		Error: cstructs.NewRpcError(err, code),
+	})
+}
+
+// stream streams the events matching the request as newline delimited JSON
+// objects, each holding the events of a raft log.
+func (e *Event) stream(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	defer metrics.MeasureSince([]string{"nomad", "event", "stream"}, time.Now())
+
+	// Decode the arguments
+	var args structs.EventStreamRequest
+	decoder := codec.NewDecoder(conn, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(conn, structs.MsgpackHandle)
+
+	if err := decoder.Decode(&args); err != nil {
+		e.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+
+	// Check if we need to forward to a different region
+	if r := args.RequestRegion(); r != e.srv.Region() {
+		e.forwardRegionStreamingRpc(conn, encoder, &args, r)
+		return
+	}
+
+	if e.srv.eventBroker == nil {
+		e.handleStreamResultError(fmt.Errorf("event broker is disabled"), helper.Int64ToPtr(400), encoder)
+		return
+	}
+
+	// Validate the topics
+	if len(args.Topics) == 0 {
+		e.handleStreamResultError(fmt.Errorf("at least one topic is required"), helper.Int64ToPtr(400), encoder)
+		return
+	}
+	namespace := args.RequestNamespace()
+	for topic := range args.Topics {
+		switch topic {
+		case structs.TopicAll, structs.TopicJob, structs.TopicAllocation, structs.TopicNode, structs.TopicDeployment:
+		default:
+			e.handleStreamResultError(fmt.Errorf("unknown topic %q", topic), helper.Int64ToPtr(400), encoder)
+			return
+		}
+	}
+
+	// Check the token may subscribe to the topics
+	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+		e.handleStreamResultError(err, helper.Int64ToPtr(403), encoder)
+		return
+	} else if aclObj != nil {
+		for topic := range args.Topics {
+			if !aclAllowsTopic(aclObj, topic, namespace) {
+				e.handleStreamResultError(structs.ErrPermissionDenied, helper.Int64ToPtr(403), encoder)
+				return
+			}
+		}
+	}
+
+	sub := e.srv.eventBroker.Subscribe(&stream.SubscribeRequest{
+		Topics:    args.Topics,
+		Namespace: namespace,
+		Index:     args.Index,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Stop streaming when the subscriber closes the connection
+	go func() {
+		io.Copy(ioutil.Discard, conn)
+		cancel()
+	}()
+
+	// Wait for events in a separate goroutine so heartbeats can be sent
+	eventsCh := make(chan *structs.Events)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			events, err := sub.Next(ctx)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case eventsCh <- events:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var payload []byte
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-errCh:
+			if err != context.Canceled {
+				e.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+			}
+			return
+		case <-heartbeat.C:
+			payload = []byte("{}\n")
+		case events := <-eventsCh:
+			// Tokens may expire or be deleted while streaming, so the
+			// token is resolved again for every set of events.
+			aclObj, err := e.srv.ResolveToken(args.AuthToken)
+			if err != nil {
+				e.handleStreamResultError(err, helper.Int64ToPtr(403), encoder)
+				return
+			}
+			if events = allowedEvents(aclObj, events); events == nil {
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := codec.NewEncoder(&buf, structs.JsonHandle).Encode(events); err != nil {
+				e.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+				return
+			}
+			payload = append(buf.Bytes(), '\n')
+		}
+
+		if err := encoder.Encode(&cstructs.StreamErrWrapper{Payload: payload}); err != nil {
+			return
+		}
+	}
+}
+
+// forwardRegionStreamingRpc forwards the event stream to a random server of
+// the given region.
+func (e *Event) forwardRegionStreamingRpc(conn io.ReadWriteCloser, encoder *codec.Encoder,
+	args *structs.EventStreamRequest, region string) {
+
+	e.srv.peerLock.RLock()
+	servers := e.srv.peers[region]
+	if len(servers) == 0 {
+		e.srv.peerLock.RUnlock()
+		e.handleStreamResultError(structs.ErrNoRegionPath, helper.Int64ToPtr(400), encoder)
+		return
+	}
+	server := servers[rand.Intn(len(servers))]
+	e.srv.peerLock.RUnlock()
+
+	srvConn, err := e.srv.streamingRpc(server, "Event.Stream")
+	if err != nil {
+		e.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+	defer srvConn.Close()
+
+	// Send the request.
+	outEncoder := codec.NewEncoder(srvConn, structs.MsgpackHandle)
+	if err := outEncoder.Encode(args); err != nil {
+		e.handleStreamResultError(err, helper.Int64ToPtr(500), encoder)
+		return
+	}
+
+	structs.Bridge(conn, srvConn)
+}
+
+// aclAllowsTopic returns whether the ACL may subscribe to the topic in the
+// namespace. Subscriptions to every namespace are allowed, since events of
+// namespaces the ACL may not read are filtered out.
+func aclAllowsTopic(aclObj *acl.ACL, topic structs.Topic, namespace string) bool {
+	switch topic {
+	case structs.TopicAll:
+		return aclObj.IsManagement()
+	case structs.TopicNode:
+		return aclObj.AllowNodeRead()
+	default:
+		return namespace == "*" || aclObj.AllowNsOp(namespace, acl.NamespaceCapabilityReadJob)
+	}
+}
+
+// allowedEvents returns the events the ACL may read, or nil if there are
+// none. All events are returned when ACLs are disabled.
+func allowedEvents(aclObj *acl.ACL, events *structs.Events) *structs.Events {
+	if aclObj == nil || aclObj.IsManagement() {
+		return events
+	}
+
+	allowed := make([]structs.Event, 0, len(events.Events))
+	for _, event := range events.Events {
+		switch event.Topic {
+		case structs.TopicNode:
+			if !aclObj.AllowNodeRead() {
+				continue
+			}
+		default:
+			if !aclObj.AllowJobOp(event.Namespace, eventJobID(event), acl.NamespaceCapabilityReadJob) {
+				continue
+			}
+		}
+		allowed = append(allowed, event)
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	return &structs.Events{Index: events.Index, Events: allowed}
+}
+
+// eventJobID returns the ID of the job the event is about, or an empty string
+// if the event isn't about a job.
+func eventJobID(event structs.Event) string {
+	switch p := event.Payload.(type) {
+	case *structs.JobEvent:
+		return p.Job.ID
+	case *structs.AllocationEvent:
+		return p.Allocation.JobID
+	case *structs.DeploymentEvent:
+		return p.Deployment.JobID
+	}
+	return ""
+}
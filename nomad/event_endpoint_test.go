@@ -0,0 +1,228 @@
+package nomad
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/acl"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+// testEventStream starts an event stream on the server and returns channels
+// of the stream messages and decoding errors.
+func testEventStream(t *testing.T, s *Server, req *structs.EventStreamRequest) (<-chan *cstructs.StreamErrWrapper, <-chan error, func()) {
+	handler, err := s.StreamingRpcHandler("Event.Stream")
+	require.NoError(t, err)
+
+	p1, p2 := net.Pipe()
+	errCh := make(chan error, 1)
+	streamMsg := make(chan *cstructs.StreamErrWrapper)
+
+	go handler(p2)
+
+	go func() {
+		decoder := codec.NewDecoder(p1, structs.MsgpackHandle)
+		for {
+			var msg cstructs.StreamErrWrapper
+			if err := decoder.Decode(&msg); err != nil {
+				if err == io.EOF || strings.Contains(err.Error(), "closed") {
+					return
+				}
+				errCh <- fmt.Errorf("error decoding: %v", err)
+				return
+			}
+			streamMsg <- &msg
+		}
+	}()
+
+	encoder := codec.NewEncoder(p1, structs.MsgpackHandle)
+	require.NoError(t, encoder.Encode(req))
+
+	return streamMsg, errCh, func() {
+		p1.Close()
+		p2.Close()
+	}
+}
+
+func TestEvent_Stream(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	rpcCodec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	streamMsg, errCh, cleanup := testEventStream(t, s1, &structs.EventStreamRequest{
+		Topics: map[structs.Topic][]string{structs.TopicJob: {"*"}},
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	})
+	defer cleanup()
+
+	// Register a job
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(rpcCodec, "Job.Register", req, &resp))
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case <-timeout:
+			t.Fatal("timeout waiting for job event")
+		case err := <-errCh:
+			t.Fatal(err)
+		case msg := <-streamMsg:
+			require.Nil(msg.Error)
+
+			var events structs.Events
+			require.NoError(json.Unmarshal(msg.Payload, &events))
+			if len(events.Events) == 0 {
+				continue
+			}
+			require.Equal(resp.JobModifyIndex, events.Index)
+			require.Equal(structs.TopicJob, events.Events[0].Topic)
+			require.Equal(structs.TypeJobRegistered, events.Events[0].Type)
+			require.Equal(job.ID, events.Events[0].Key)
+			return
+		}
+	}
+}
+
+func TestEvent_Stream_ACL(t *testing.T) {
+	t.Parallel()
+
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	policy := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	token := mock.CreatePolicyAndToken(t, s1.State(), 1001, "job-read", policy)
+
+	cases := []struct {
+		Name          string
+		Token         string
+		Topic         structs.Topic
+		ExpectedError string
+	}{
+		{
+			Name:          "no token",
+			Topic:         structs.TopicJob,
+			ExpectedError: structs.ErrPermissionDenied.Error(),
+		},
+		{
+			Name:          "job token node topic",
+			Token:         token.SecretID,
+			Topic:         structs.TopicNode,
+			ExpectedError: structs.ErrPermissionDenied.Error(),
+		},
+		{
+			Name:          "job token all topics",
+			Token:         token.SecretID,
+			Topic:         structs.TopicAll,
+			ExpectedError: structs.ErrPermissionDenied.Error(),
+		},
+		{
+			Name:          "unknown topic",
+			Token:         root.SecretID,
+			Topic:         "Foo",
+			ExpectedError: "unknown topic",
+		},
+		{
+			Name:  "job token job topic",
+			Token: token.SecretID,
+			Topic: structs.TopicJob,
+		},
+		{
+			Name:  "root token all topics",
+			Token: root.SecretID,
+			Topic: structs.TopicAll,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			streamMsg, errCh, cleanup := testEventStream(t, s1, &structs.EventStreamRequest{
+				Topics: map[structs.Topic][]string{c.Topic: {"*"}},
+				QueryOptions: structs.QueryOptions{
+					Region:    "global",
+					Namespace: structs.DefaultNamespace,
+					AuthToken: c.Token,
+				},
+			})
+			defer cleanup()
+
+			select {
+			case err := <-errCh:
+				t.Fatal(err)
+			case msg := <-streamMsg:
+				if c.ExpectedError == "" {
+					t.Fatalf("unexpected message: %#v", msg)
+				}
+				require.NotNil(t, msg.Error)
+				require.Contains(t, msg.Error.Error(), c.ExpectedError)
+			case <-time.After(100 * time.Millisecond):
+				if c.ExpectedError != "" {
+					t.Fatalf("expected error %q", c.ExpectedError)
+				}
+			}
+		})
+	}
+}
+
+func TestEvent_AllowedEvents(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	policy := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	aclObj, err := acl.NewACL(false, []*acl.Policy{mustParsePolicy(t, policy)})
+	require.NoError(err)
+
+	events := &structs.Events{
+		Index: 10,
+		Events: []structs.Event{
+			{Topic: structs.TopicJob, Key: "a", Namespace: structs.DefaultNamespace},
+			{Topic: structs.TopicJob, Key: "b", Namespace: "other"},
+			{Topic: structs.TopicNode, Key: "c"},
+		},
+	}
+
+	// Events of other namespaces and of nodes are removed
+	allowed := allowedEvents(aclObj, events)
+	require.Len(allowed.Events, 1)
+	require.Equal("a", allowed.Events[0].Key)
+	require.Equal(uint64(10), allowed.Index)
+
+	// Nil is returned when no events are allowed
+	require.Nil(allowedEvents(aclObj, &structs.Events{Index: 11, Events: events.Events[1:]}))
+
+	// All events are allowed when ACLs are disabled
+	require.Equal(events, allowedEvents(nil, events))
+}
+
+// mustParsePolicy parses the ACL policy rules.
+func mustParsePolicy(t *testing.T, rules string) *acl.Policy {
+	policy, err := acl.Parse(rules)
+	require.NoError(t, err)
+	return policy
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	memdb "github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/stream"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/scheduler"
 	"github.com/hashicorp/raft"
@@ -45,6 +47,15 @@ const (
 	ACLPolicySnapshot
 	ACLTokenSnapshot
 	SchedulerConfigSnapshot
+	ServiceRegistrationSnapshot
+	VariablesSnapshot
+	ACLRoleSnapshot
+	ACLAuthMethodSnapshot
+	ACLBindingRuleSnapshot
+	CSIVolumeSnapshot
+	CSIPluginSnapshot
+	GCConfigSnapshot
+	ScalingEventsSnapshot
 )
 
 // LogApplier is the definition of a function that can apply a Raft log
@@ -113,6 +124,10 @@ type FSMConfig struct {
 	// be added to.
 	Blocked *BlockedEvals
 
+	// EventBroker is the broker the events of applied logs are published to.
+	// Events are not published when it is nil.
+	EventBroker *stream.EventBroker
+
 	// Logger is the logger used by the FSM
 	Logger log.Logger
 
@@ -215,6 +230,8 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyDeregisterVaultAccessor(buf[1:], log.Index)
 	case structs.ApplyPlanResultsRequestType:
 		return n.applyPlanResults(buf[1:], log.Index)
+	case structs.ApplyPlanResultsBatchRequestType:
+		return n.applyPlanResultsBatch(buf[1:], log.Index)
 	case structs.DeploymentStatusUpdateRequestType:
 		return n.applyDeploymentStatusUpdate(buf[1:], log.Index)
 	case structs.DeploymentPromoteRequestType:
@@ -249,6 +266,46 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyBatchDrainUpdate(buf[1:], log.Index)
 	case structs.SchedulerConfigRequestType:
 		return n.applySchedulerConfigUpdate(buf[1:], log.Index)
+	case structs.NodeHostVolumeRegisterRequestType:
+		return n.applyNodeHostVolumeRegister(buf[1:], log.Index)
+	case structs.NodeScaleInRequestType:
+		return n.applyNodeScaleIn(buf[1:], log.Index)
+	case structs.NodeHostVolumeDeregisterRequestType:
+		return n.applyNodeHostVolumeDeregister(buf[1:], log.Index)
+	case structs.ServiceRegistrationUpsertRequestType:
+		return n.applyUpsertServiceRegistrations(buf[1:], log.Index)
+	case structs.ServiceRegistrationDeleteRequestType:
+		return n.applyDeleteServiceRegistrations(buf[1:], log.Index)
+	case structs.VariablesUpsertRequestType:
+		return n.applyVariablesUpsert(buf[1:], log.Index)
+	case structs.VariablesDeleteRequestType:
+		return n.applyVariablesDelete(buf[1:], log.Index)
+	case structs.ACLRoleUpsertRequestType:
+		return n.applyACLRoleUpsert(buf[1:], log.Index)
+	case structs.ACLRoleDeleteRequestType:
+		return n.applyACLRoleDelete(buf[1:], log.Index)
+	case structs.ACLAuthMethodUpsertRequestType:
+		return n.applyACLAuthMethodUpsert(buf[1:], log.Index)
+	case structs.ACLAuthMethodDeleteRequestType:
+		return n.applyACLAuthMethodDelete(buf[1:], log.Index)
+	case structs.ACLBindingRuleUpsertRequestType:
+		return n.applyACLBindingRuleUpsert(buf[1:], log.Index)
+	case structs.ACLBindingRuleDeleteRequestType:
+		return n.applyACLBindingRuleDelete(buf[1:], log.Index)
+	case structs.JobVersionTagRequestType:
+		return n.applyJobVersionTag(buf[1:], log.Index)
+	case structs.CSIVolumeRegisterRequestType:
+		return n.applyCSIVolumeRegister(buf[1:], log.Index)
+	case structs.CSIVolumeDeregisterRequestType:
+		return n.applyCSIVolumeDeregister(buf[1:], log.Index)
+	case structs.CSIVolumeClaimRequestType:
+		return n.applyCSIVolumeClaim(buf[1:], log.Index)
+	case structs.GCConfigRequestType:
+		return n.applyGCConfigUpdate(buf[1:], log.Index)
+	case structs.JobBatchRegisterRequestType:
+		return n.applyBatchRegisterJob(buf[1:], log.Index)
+	case structs.ScalingEventRegisterRequestType:
+		return n.applyUpsertScalingEvent(buf[1:], log.Index)
 	}
 
 	// Check enterprise only message types.
@@ -279,6 +336,7 @@ func (n *nomadFSM) applyUpsertNode(buf []byte, index uint64) interface{} {
 		n.logger.Error("UpsertNode failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.nodeEvents(structs.TypeNodeRegistration, req.Node.ID))
 
 	// Unblock evals for the nodes computed node class if it is in a ready
 	// state.
@@ -296,10 +354,19 @@ func (n *nomadFSM) applyDeregisterNode(buf []byte, index uint64) interface{} {
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
+	// Look up the node for the event before it is deleted
+	var events []structs.Event
+	if n.eventsEnabled() {
+		if node, _ := n.state.NodeByID(nil, req.NodeID); node != nil {
+			events = append(events, nodeEvent(structs.TypeNodeDeregistration, node))
+		}
+	}
+
 	if err := n.state.DeleteNode(index, req.NodeID); err != nil {
 		n.logger.Error("DeleteNode failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, events)
 	return nil
 }
 
@@ -314,6 +381,7 @@ func (n *nomadFSM) applyStatusUpdate(buf []byte, index uint64) interface{} {
 		n.logger.Error("UpdateNodeStatus failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.nodeEvents(structs.TypeNodeStatusUpdate, req.NodeID))
 
 	// Unblock evals for the nodes computed node class if it is in a ready
 	// state.
@@ -356,6 +424,7 @@ func (n *nomadFSM) applyDrainUpdate(buf []byte, index uint64) interface{} {
 		n.logger.Error("UpdateNodeDrain failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.nodeEvents(structs.TypeNodeDrain, req.NodeID))
 	return nil
 }
 
@@ -370,6 +439,13 @@ func (n *nomadFSM) applyBatchDrainUpdate(buf []byte, index uint64) interface{} {
 		n.logger.Error("BatchUpdateNodeDrain failed", "error", err)
 		return err
 	}
+
+	nodeIDs := make([]string, 0, len(req.Updates))
+	for nodeID := range req.Updates {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	n.publishEvents(index, n.nodeEvents(structs.TypeNodeDrain, nodeIDs...))
 	return nil
 }
 
@@ -391,6 +467,7 @@ func (n *nomadFSM) applyNodeEligibilityUpdate(buf []byte, index uint64) interfac
 		n.logger.Error("UpdateNodeEligibility failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.nodeEvents(structs.TypeNodeEligibility, req.NodeID))
 
 	// Unblock evals for the nodes computed node class if it is in a ready
 	// state.
@@ -402,6 +479,112 @@ func (n *nomadFSM) applyNodeEligibilityUpdate(buf []byte, index uint64) interfac
 	return nil
 }
 
+func (n *nomadFSM) applyNodeHostVolumeRegister(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "node_host_volume_register"}, time.Now())
+	var req structs.NodeHostVolumeRegisterRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertNodeHostVolume(index, req.NodeID, req.Volume, req.NodeEvent); err != nil {
+		n.logger.Error("UpsertNodeHostVolume failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyNodeHostVolumeDeregister(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "node_host_volume_deregister"}, time.Now())
+	var req structs.NodeHostVolumeDeregisterRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteNodeHostVolume(index, req.NodeID, req.Name, req.NodeEvent); err != nil {
+		n.logger.Error("DeleteNodeHostVolume failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyNodeScaleIn(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "node_scale_in"}, time.Now())
+	var req structs.NodeScaleInRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpdateNodeScaleIn(index, req.NodeID, req.ScaleIn, req.DrainStrategy, req.NodeEvent); err != nil {
+		n.logger.Error("UpdateNodeScaleIn failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyUpsertServiceRegistrations(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_service_registrations"}, time.Now())
+	var req structs.ServiceRegistrationUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertServiceRegistrations(index, req.Services); err != nil {
+		n.logger.Error("UpsertServiceRegistrations failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyDeleteServiceRegistrations(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "delete_service_registrations"}, time.Now())
+	var req structs.ServiceRegistrationDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteServiceRegistrations(index, req.NodeID, req.IDs); err != nil {
+		n.logger.Error("DeleteServiceRegistrations failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyVariablesUpsert(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "variables_upsert"}, time.Now())
+	var req structs.VariablesEncryptedUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	var applied bool
+	var err error
+	if req.SetLock {
+		applied, err = n.state.UpsertVariableLock(index, req.Var)
+	} else {
+		applied, err = n.state.UpsertVariable(index, req.Var, req.CAS)
+	}
+	if err != nil {
+		n.logger.Error("UpsertVariable failed", "error", err)
+		return err
+	}
+	return applied
+}
+
+func (n *nomadFSM) applyVariablesDelete(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "variables_delete"}, time.Now())
+	var req structs.VariablesDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	applied, err := n.state.DeleteVariable(index, req.RequestNamespace(), req.Path, req.CAS, req.ModifyIndex)
+	if err != nil {
+		n.logger.Error("DeleteVariable failed", "error", err)
+		return err
+	}
+	return applied
+}
+
 func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "register_job"}, time.Now())
 	var req structs.JobRegisterRequest
@@ -422,11 +605,18 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 		n.logger.Error("UpsertJob failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.jobEvents(structs.TypeJobRegistered, req.Job))
+
+	return n.handleUpsertedJob(index, req.Namespace, req.Job)
+}
 
+// handleUpsertedJob tracks the periodic launches of a job after it was
+// upserted.
+func (n *nomadFSM) handleUpsertedJob(index uint64, namespace string, job *structs.Job) error {
 	// We always add the job to the periodic dispatcher because there is the
 	// possibility that the periodic spec was removed and then we should stop
 	// tracking it.
-	if err := n.periodicDispatcher.Add(req.Job); err != nil {
+	if err := n.periodicDispatcher.Add(job); err != nil {
 		n.logger.Error("periodicDispatcher.Add failed", "error", err)
 		return fmt.Errorf("failed adding job to periodic dispatcher: %v", err)
 	}
@@ -439,8 +629,8 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 	// the time it is added to when it was suppose to launch, leader election
 	// occurs and the job was not launched. In this case, we use the insertion
 	// time to determine if a launch was missed.
-	if req.Job.IsPeriodicActive() {
-		prevLaunch, err := n.state.PeriodicLaunchByID(ws, req.Namespace, req.Job.ID)
+	if job.IsPeriodicActive() {
+		prevLaunch, err := n.state.PeriodicLaunchByID(ws, namespace, job.ID)
 		if err != nil {
 			n.logger.Error("PeriodicLaunchByID failed", "error", err)
 			return err
@@ -450,8 +640,8 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 		// such that the first entry is the insertion time.
 		if prevLaunch == nil {
 			launch := &structs.PeriodicLaunch{
-				ID:        req.Job.ID,
-				Namespace: req.Namespace,
+				ID:        job.ID,
+				Namespace: namespace,
 				Launch:    time.Now(),
 			}
 			if err := n.state.UpsertPeriodicLaunch(index, launch); err != nil {
@@ -462,11 +652,11 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 	}
 
 	// Check if the parent job is periodic and mark the launch time.
-	parentID := req.Job.ParentID
+	parentID := job.ParentID
 	if parentID != "" {
-		parent, err := n.state.JobByID(ws, req.Namespace, parentID)
+		parent, err := n.state.JobByID(ws, namespace, parentID)
 		if err != nil {
-			n.logger.Error("JobByID lookup for parent failed", "parent_id", parentID, "namespace", req.Namespace, "error", err)
+			n.logger.Error("JobByID lookup for parent failed", "parent_id", parentID, "namespace", namespace, "error", err)
 			return err
 		} else if parent == nil {
 			// The parent has been deregistered.
@@ -474,15 +664,15 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 		}
 
 		if parent.IsPeriodic() && !parent.IsParameterized() {
-			t, err := n.periodicDispatcher.LaunchTime(req.Job.ID)
+			t, err := n.periodicDispatcher.LaunchTime(job.ID)
 			if err != nil {
-				n.logger.Error("LaunchTime failed", "job", req.Job.NamespacedID(), "error", err)
+				n.logger.Error("LaunchTime failed", "job", job.NamespacedID(), "error", err)
 				return err
 			}
 
 			launch := &structs.PeriodicLaunch{
 				ID:        parentID,
-				Namespace: req.Namespace,
+				Namespace: namespace,
 				Launch:    t,
 			}
 			if err := n.state.UpsertPeriodicLaunch(index, launch); err != nil {
@@ -495,6 +685,74 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+func (n *nomadFSM) applyBatchRegisterJob(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "batch_register_job"}, time.Now())
+	var req structs.JobBatchRegisterRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	// Register the jobs and create their evaluations in a single transaction
+	// so that either the whole batch is applied or none of it is. Enforced
+	// job modify indexes are checked again, as the jobs may have changed
+	// since the batch was validated by the endpoint.
+	var upserted []*structs.Job
+	err := n.state.WithWriteTransaction(func(tx state.Txn) error {
+		jobIndexes := make(map[structs.NamespacedID]uint64, len(req.Jobs))
+		for _, entry := range req.Jobs {
+			job := entry.Job
+			job.Canonicalize()
+
+			existing, err := n.state.JobByIDTxn(nil, job.Namespace, job.ID, tx)
+			if err != nil {
+				n.logger.Error("JobByID lookup failed", "job", job.NamespacedID(), "error", err)
+				return err
+			}
+			if entry.EnforceIndex {
+				if err := checkEnforceIndex(existing, entry.JobModifyIndex); err != nil {
+					return fmt.Errorf("job %q: %v", job.ID, err)
+				}
+			}
+
+			// Unchanged jobs are left as is, only their evaluation is created
+			if existing != nil && !existing.SpecChanged(job) {
+				jobIndexes[*job.NamespacedID()] = existing.JobModifyIndex
+				continue
+			}
+
+			if err := n.state.UpsertJobTxn(index, job, tx); err != nil {
+				n.logger.Error("UpsertJob failed", "job", job.NamespacedID(), "error", err)
+				return err
+			}
+			jobIndexes[*job.NamespacedID()] = index
+			upserted = append(upserted, job)
+		}
+
+		for _, eval := range req.Evals {
+			eval.JobModifyIndex = jobIndexes[structs.NamespacedID{ID: eval.JobID, Namespace: eval.Namespace}]
+		}
+		if err := n.state.UpsertEvalsTxn(index, req.Evals, tx); err != nil {
+			n.logger.Error("UpsertEvals failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// perform the side effects outside the transactions
+	n.handleUpsertedEvals(req.Evals)
+	n.publishEvents(index, n.jobEvents(structs.TypeJobRegistered, upserted...))
+	for _, job := range upserted {
+		if err := n.handleUpsertedJob(index, job.Namespace, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (n *nomadFSM) applyDeregisterJob(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "deregister_job"}, time.Now())
 	var req structs.JobDeregisterRequest
@@ -502,7 +760,13 @@ func (n *nomadFSM) applyDeregisterJob(buf []byte, index uint64) interface{} {
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
-	return n.state.WithWriteTransaction(func(tx state.Txn) error {
+	// Look up the job for the event before it may be purged
+	var job *structs.Job
+	if n.eventsEnabled() {
+		job, _ = n.state.JobByID(nil, req.Namespace, req.JobID)
+	}
+
+	err := n.state.WithWriteTransaction(func(tx state.Txn) error {
 		if err := n.handleJobDeregister(index, req.JobID, req.Namespace, req.Purge, tx); err != nil {
 			n.logger.Error("deregistering job failed", "error", err)
 			return err
@@ -510,6 +774,12 @@ func (n *nomadFSM) applyDeregisterJob(buf []byte, index uint64) interface{} {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	n.publishEvents(index, n.jobEvents(structs.TypeJobDeregistered, job))
+	return nil
 }
 
 func (n *nomadFSM) applyBatchDeregisterJob(buf []byte, index uint64) interface{} {
@@ -519,6 +789,19 @@ func (n *nomadFSM) applyBatchDeregisterJob(buf []byte, index uint64) interface{}
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
+	// Look up the jobs for the events before they may be purged
+	var jobs []*structs.Job
+	if n.eventsEnabled() {
+		for jobNS := range req.Jobs {
+			if job, _ := n.state.JobByID(nil, jobNS.Namespace, jobNS.ID); job != nil {
+				jobs = append(jobs, job)
+			}
+		}
+		sort.Slice(jobs, func(i, j int) bool {
+			return jobs[i].NamespacedID().String() < jobs[j].NamespacedID().String()
+		})
+	}
+
 	// Perform all store updates atomically to ensure a consistent view for store readers.
 	// A partial update may increment the snapshot index, allowing eval brokers to process
 	// evals for jobs whose deregistering didn't get committed yet.
@@ -544,6 +827,7 @@ func (n *nomadFSM) applyBatchDeregisterJob(buf []byte, index uint64) interface{}
 
 	// perform the side effects outside the transactions
 	n.handleUpsertedEvals(req.Evals)
+	n.publishEvents(index, n.jobEvents(structs.TypeJobDeregistered, jobs...))
 	return nil
 }
 
@@ -683,6 +967,7 @@ func (n *nomadFSM) applyAllocUpdate(buf []byte, index uint64) interface{} {
 		n.logger.Error("UpsertAllocs failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.allocEvents(structs.TypeAllocationUpdated, allocIDs(req.Alloc)...))
 	return nil
 }
 
@@ -712,6 +997,7 @@ func (n *nomadFSM) applyAllocClientUpdate(buf []byte, index uint64) interface{}
 		n.logger.Error("UpdateAllocFromClient failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.allocEvents(structs.TypeAllocationUpdated, allocIDs(req.Alloc)...))
 
 	// Update any evals
 	if len(req.Evals) > 0 {
@@ -762,16 +1048,37 @@ func (n *nomadFSM) applyAllocUpdateDesiredTransition(buf []byte, index uint64) i
 		return err
 	}
 
+	ids := make([]string, 0, len(req.Allocs))
+	for allocID := range req.Allocs {
+		ids = append(ids, allocID)
+	}
+	sort.Strings(ids)
+	n.publishEvents(index, n.allocEvents(structs.TypeAllocationUpdateDesired, ids...))
+
 	n.handleUpsertedEvals(req.Evals)
 	return nil
 }
 
-// applyReconcileSummaries reconciles summaries for all the jobs
+// applyReconcileSummaries reconciles summaries for the jobs in the request, or
+// for all the jobs if the request does not list any
 func (n *nomadFSM) applyReconcileSummaries(buf []byte, index uint64) interface{} {
-	if err := n.state.ReconcileJobSummaries(index); err != nil {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "reconcile_job_summaries"}, time.Now())
+	var req structs.JobSummaryReconcileRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if len(req.Jobs) == 0 {
+		if err := n.state.ReconcileJobSummaries(index); err != nil {
+			return err
+		}
+		return n.reconcileQueuedAllocations(index, nil)
+	}
+
+	if err := n.state.ReconcileJobSummariesByID(index, req.Jobs); err != nil {
 		return err
 	}
-	return n.reconcileQueuedAllocations(index)
+	return n.reconcileQueuedAllocations(index, req.Jobs)
 }
 
 // applyUpsertNodeEvent tracks the given node events.
@@ -835,12 +1142,40 @@ func (n *nomadFSM) applyPlanResults(buf []byte, index uint64) interface{} {
 		n.logger.Error("ApplyPlan failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.planResultEvents(&req))
 
 	// Add evals for jobs that were preempted
 	n.handleUpsertedEvals(req.PreemptionEvals)
 	return nil
 }
 
+// applyPlanResultsBatch applies the results of multiple plans that the
+// planner committed together.
+func (n *nomadFSM) applyPlanResultsBatch(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_plan_results_batch"}, time.Now())
+	var req structs.ApplyPlanResultsBatchRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertPlanResultsBatch(index, req.Results); err != nil {
+		n.logger.Error("ApplyPlanBatch failed", "error", err)
+		return err
+	}
+
+	var events []structs.Event
+	for _, result := range req.Results {
+		events = append(events, n.planResultEvents(result)...)
+	}
+	n.publishEvents(index, events)
+
+	// Add evals for jobs that were preempted
+	for _, result := range req.Results {
+		n.handleUpsertedEvals(result.PreemptionEvals)
+	}
+	return nil
+}
+
 // applyDeploymentStatusUpdate is used to update the status of an existing
 // deployment
 func (n *nomadFSM) applyDeploymentStatusUpdate(buf []byte, index uint64) interface{} {
@@ -854,6 +1189,7 @@ func (n *nomadFSM) applyDeploymentStatusUpdate(buf []byte, index uint64) interfa
 		n.logger.Error("UpsertDeploymentStatusUpdate failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.deploymentEvents(structs.TypeDeploymentUpdate, req.DeploymentUpdate.DeploymentID))
 
 	n.handleUpsertedEval(req.Eval)
 	return nil
@@ -871,6 +1207,7 @@ func (n *nomadFSM) applyDeploymentPromotion(buf []byte, index uint64) interface{
 		n.logger.Error("UpsertDeploymentPromotion failed", "error", err)
 		return err
 	}
+	n.publishEvents(index, n.deploymentEvents(structs.TypeDeploymentPromotion, req.DeploymentID))
 
 	n.handleUpsertedEval(req.Eval)
 	return nil
@@ -890,6 +1227,11 @@ func (n *nomadFSM) applyDeploymentAllocHealth(buf []byte, index uint64) interfac
 		return err
 	}
 
+	events := n.deploymentEvents(structs.TypeDeploymentAllocHealth, req.DeploymentID)
+	events = append(events, n.allocEvents(structs.TypeDeploymentAllocHealth, req.HealthyAllocationIDs...)...)
+	events = append(events, n.allocEvents(structs.TypeDeploymentAllocHealth, req.UnhealthyAllocationIDs...)...)
+	n.publishEvents(index, events)
+
 	n.handleUpsertedEval(req.Eval)
 	return nil
 }
@@ -926,6 +1268,86 @@ func (n *nomadFSM) applyJobStability(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+// applyJobVersionTag is used to tag a job version or remove a tag
+func (n *nomadFSM) applyJobVersionTag(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_job_version_tag"}, time.Now())
+	var req structs.JobVersionTagRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpdateJobVersionTag(index, req.Namespace, req.JobID, req.JobVersion, req.Tag, req.Unset); err != nil {
+		n.logger.Error("UpdateJobVersionTag failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// applyUpsertScalingEvent is used to record a scaling event of a task group
+func (n *nomadFSM) applyUpsertScalingEvent(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_scaling_event"}, time.Now())
+	var req structs.ScalingEventRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertScalingEvent(index, &req); err != nil {
+		n.logger.Error("UpsertScalingEvent failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// applyCSIVolumeRegister is used to register CSI volumes
+func (n *nomadFSM) applyCSIVolumeRegister(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_csi_volume_register"}, time.Now())
+	var req structs.CSIVolumeRegisterRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.CSIVolumeRegister(index, req.Volumes); err != nil {
+		n.logger.Error("CSIVolumeRegister failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// applyCSIVolumeDeregister is used to deregister CSI volumes
+func (n *nomadFSM) applyCSIVolumeDeregister(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_csi_volume_deregister"}, time.Now())
+	var req structs.CSIVolumeDeregisterRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.CSIVolumeDeregister(index, req.RequestNamespace(), req.VolumeIDs, req.Force); err != nil {
+		n.logger.Error("CSIVolumeDeregister failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// applyCSIVolumeClaim is used to claim a CSI volume or release a claim
+func (n *nomadFSM) applyCSIVolumeClaim(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_csi_volume_claim"}, time.Now())
+	var req structs.CSIVolumeClaimRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.CSIVolumeClaim(index, req.RequestNamespace(), req.VolumeID, req.ToClaim()); err != nil {
+		n.logger.Error("CSIVolumeClaim failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
 // applyACLPolicyUpsert is used to upsert a set of policies
 func (n *nomadFSM) applyACLPolicyUpsert(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_policy_upsert"}, time.Now())
@@ -956,6 +1378,96 @@ func (n *nomadFSM) applyACLPolicyDelete(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+// applyACLRoleUpsert is used to upsert a set of roles
+func (n *nomadFSM) applyACLRoleUpsert(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_role_upsert"}, time.Now())
+	var req structs.ACLRoleUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertACLRoles(index, req.Roles); err != nil {
+		n.logger.Error("UpsertACLRoles failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// applyACLRoleDelete is used to delete a set of roles
+func (n *nomadFSM) applyACLRoleDelete(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_role_delete"}, time.Now())
+	var req structs.ACLRoleDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteACLRoles(index, req.Names); err != nil {
+		n.logger.Error("DeleteACLRoles failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// applyACLAuthMethodUpsert is used to upsert a set of auth methods
+func (n *nomadFSM) applyACLAuthMethodUpsert(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_auth_method_upsert"}, time.Now())
+	var req structs.ACLAuthMethodUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertACLAuthMethods(index, req.AuthMethods); err != nil {
+		n.logger.Error("UpsertACLAuthMethods failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// applyACLAuthMethodDelete is used to delete a set of auth methods
+func (n *nomadFSM) applyACLAuthMethodDelete(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_auth_method_delete"}, time.Now())
+	var req structs.ACLAuthMethodDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteACLAuthMethods(index, req.Names); err != nil {
+		n.logger.Error("DeleteACLAuthMethods failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// applyACLBindingRuleUpsert is used to upsert a set of binding rules
+func (n *nomadFSM) applyACLBindingRuleUpsert(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_binding_rule_upsert"}, time.Now())
+	var req structs.ACLBindingRuleUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertACLBindingRules(index, req.Rules); err != nil {
+		n.logger.Error("UpsertACLBindingRules failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// applyACLBindingRuleDelete is used to delete a set of binding rules
+func (n *nomadFSM) applyACLBindingRuleDelete(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_binding_rule_delete"}, time.Now())
+	var req structs.ACLBindingRuleDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteACLBindingRules(index, req.RuleIDs); err != nil {
+		n.logger.Error("DeleteACLBindingRules failed", "error", err)
+		return err
+	}
+	return nil
+}
+
 // applyACLTokenUpsert is used to upsert a set of policies
 func (n *nomadFSM) applyACLTokenUpsert(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_token_upsert"}, time.Now())
@@ -1035,9 +1547,26 @@ func (n *nomadFSM) applySchedulerConfigUpdate(buf []byte, index uint64) interfac
 	return n.state.SchedulerSetConfig(index, &req.Config)
 }
 
-func (n *nomadFSM) Snapshot() (raft.FSMSnapshot, error) {
-	// Create a new snapshot
-	snap, err := n.state.Snapshot()
+func (n *nomadFSM) applyGCConfigUpdate(buf []byte, index uint64) interface{} {
+	var req structs.GCSetConfigRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_gc_config"}, time.Now())
+
+	if req.CAS {
+		applied, err := n.state.GCCASConfig(index, req.Config.ModifyIndex, &req.Config)
+		if err != nil {
+			return err
+		}
+		return applied
+	}
+	return n.state.GCSetConfig(index, &req.Config)
+}
+
+func (n *nomadFSM) Snapshot() (raft.FSMSnapshot, error) {
+	// Create a new snapshot
+	snap, err := n.state.Snapshot()
 	if err != nil {
 		return nil, err
 	}
@@ -1264,6 +1793,87 @@ func (n *nomadFSM) Restore(old io.ReadCloser) error {
 				return err
 			}
 
+		case ServiceRegistrationSnapshot:
+			service := new(structs.ServiceRegistration)
+			if err := dec.Decode(service); err != nil {
+				return err
+			}
+			if err := restore.ServiceRegistrationRestore(service); err != nil {
+				return err
+			}
+
+		case VariablesSnapshot:
+			v := new(structs.VariableEncrypted)
+			if err := dec.Decode(v); err != nil {
+				return err
+			}
+			if err := restore.VariableRestore(v); err != nil {
+				return err
+			}
+
+		case ACLRoleSnapshot:
+			role := new(structs.ACLRole)
+			if err := dec.Decode(role); err != nil {
+				return err
+			}
+			if err := restore.ACLRoleRestore(role); err != nil {
+				return err
+			}
+
+		case ACLAuthMethodSnapshot:
+			method := new(structs.ACLAuthMethod)
+			if err := dec.Decode(method); err != nil {
+				return err
+			}
+			if err := restore.ACLAuthMethodRestore(method); err != nil {
+				return err
+			}
+
+		case ACLBindingRuleSnapshot:
+			rule := new(structs.ACLBindingRule)
+			if err := dec.Decode(rule); err != nil {
+				return err
+			}
+			if err := restore.ACLBindingRuleRestore(rule); err != nil {
+				return err
+			}
+
+		case CSIVolumeSnapshot:
+			volume := new(structs.CSIVolume)
+			if err := dec.Decode(volume); err != nil {
+				return err
+			}
+			if err := restore.CSIVolumeRestore(volume); err != nil {
+				return err
+			}
+
+		case CSIPluginSnapshot:
+			plugin := new(structs.CSIPlugin)
+			if err := dec.Decode(plugin); err != nil {
+				return err
+			}
+			if err := restore.CSIPluginRestore(plugin); err != nil {
+				return err
+			}
+
+		case GCConfigSnapshot:
+			gcConfig := new(structs.GCConfiguration)
+			if err := dec.Decode(gcConfig); err != nil {
+				return err
+			}
+			if err := restore.GCConfigRestore(gcConfig); err != nil {
+				return err
+			}
+
+		case ScalingEventsSnapshot:
+			jobEvents := new(structs.JobScalingEvents)
+			if err := dec.Decode(jobEvents); err != nil {
+				return err
+			}
+			if err := restore.ScalingEventsRestore(jobEvents); err != nil {
+				return err
+			}
+
 		default:
 			// Check if this is an enterprise only object being restored
 			restorer, ok := n.enterpriseRestorers[snapType]
@@ -1379,13 +1989,34 @@ func (n *nomadFSM) failLeakedDeployments(state *state.StateStore) error {
 }
 
 // reconcileQueuedAllocations re-calculates the queued allocations for every job that we
-// created a Job Summary during the snap shot restore
-func (n *nomadFSM) reconcileQueuedAllocations(index uint64) error {
-	// Get all the jobs
+// created a Job Summary during the snap shot restore, or only the given jobs if
+// jobIDs is non-nil
+func (n *nomadFSM) reconcileQueuedAllocations(index uint64, jobIDs []structs.NamespacedID) error {
+	// Get the jobs
 	ws := memdb.NewWatchSet()
-	iter, err := n.state.Jobs(ws)
-	if err != nil {
-		return err
+	var jobs []*structs.Job
+	if jobIDs == nil {
+		iter, err := n.state.Jobs(ws)
+		if err != nil {
+			return err
+		}
+		for {
+			rawJob := iter.Next()
+			if rawJob == nil {
+				break
+			}
+			jobs = append(jobs, rawJob.(*structs.Job))
+		}
+	} else {
+		for _, id := range jobIDs {
+			job, err := n.state.JobByID(ws, id.Namespace, id.ID)
+			if err != nil {
+				return err
+			}
+			if job != nil {
+				jobs = append(jobs, job)
+			}
+		}
 	}
 
 	snap, err := n.state.Snapshot()
@@ -1395,12 +2026,7 @@ func (n *nomadFSM) reconcileQueuedAllocations(index uint64) error {
 
 	// Invoking the scheduler for every job so that we can populate the number
 	// of queued allocations for every job
-	for {
-		rawJob := iter.Next()
-		if rawJob == nil {
-			break
-		}
-		job := rawJob.(*structs.Job)
+	for _, job := range jobs {
 
 		// Nothing to do for queued allocations if the job is a parent periodic/parameterized job
 		if job.IsParameterized() || job.IsPeriodic() {
@@ -1560,6 +2186,42 @@ func (s *nomadSnapshot) Persist(sink raft.SnapshotSink) error {
 		sink.Cancel()
 		return err
 	}
+	if err := s.persistServiceRegistrations(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistVariables(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistACLRoles(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistACLAuthMethods(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistACLBindingRules(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistCSIVolumes(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistCSIPlugins(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistGCConfig(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistScalingEvents(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
 	return nil
 }
 
@@ -1755,6 +2417,31 @@ func (s *nomadSnapshot) persistJobSummaries(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistScalingEvents(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+
+	ws := memdb.NewWatchSet()
+	events, err := s.snap.ScalingEvents(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := events.Next()
+		if raw == nil {
+			break
+		}
+
+		jobEvents := raw.(*structs.JobScalingEvents)
+
+		sink.Write([]byte{byte(ScalingEventsSnapshot)})
+		if err := encoder.Encode(jobEvents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *nomadSnapshot) persistVaultAccessors(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 
@@ -1864,6 +2551,140 @@ func (s *nomadSnapshot) persistACLPolicies(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistACLRoles(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the roles
+	ws := memdb.NewWatchSet()
+	roles, err := s.snap.ACLRoles(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := roles.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		role := raw.(*structs.ACLRole)
+
+		// Write out a role registration
+		sink.Write([]byte{byte(ACLRoleSnapshot)})
+		if err := encoder.Encode(role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistACLAuthMethods(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the auth methods
+	ws := memdb.NewWatchSet()
+	methods, err := s.snap.ACLAuthMethods(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := methods.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		method := raw.(*structs.ACLAuthMethod)
+
+		// Write out an auth method registration
+		sink.Write([]byte{byte(ACLAuthMethodSnapshot)})
+		if err := encoder.Encode(method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistACLBindingRules(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the binding rules
+	ws := memdb.NewWatchSet()
+	rules, err := s.snap.ACLBindingRules(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := rules.Next()
+		if raw == nil {
+			break
+		}
+
+		// Prepare the request struct
+		rule := raw.(*structs.ACLBindingRule)
+
+		// Write out a binding rule registration
+		sink.Write([]byte{byte(ACLBindingRuleSnapshot)})
+		if err := encoder.Encode(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistCSIVolumes(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+
+	ws := memdb.NewWatchSet()
+	volumes, err := s.snap.CSIVolumes(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := volumes.Next()
+		if raw == nil {
+			break
+		}
+
+		volume := raw.(*structs.CSIVolume)
+
+		sink.Write([]byte{byte(CSIVolumeSnapshot)})
+		if err := encoder.Encode(volume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistCSIPlugins(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+
+	ws := memdb.NewWatchSet()
+	plugins, err := s.snap.CSIPlugins(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := plugins.Next()
+		if raw == nil {
+			break
+		}
+
+		plugin := raw.(*structs.CSIPlugin)
+
+		sink.Write([]byte{byte(CSIPluginSnapshot)})
+		if err := encoder.Encode(plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *nomadSnapshot) persistACLTokens(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 	// Get all the policies
@@ -1907,6 +2728,74 @@ func (s *nomadSnapshot) persistSchedulerConfig(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistGCConfig(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	_, gcConfig, err := s.snap.GCConfig()
+	if err != nil {
+		return err
+	}
+
+	// Only persist a config set by operators
+	if gcConfig == nil {
+		return nil
+	}
+	sink.Write([]byte{byte(GCConfigSnapshot)})
+	if err := encoder.Encode(gcConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistServiceRegistrations(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+
+	ws := memdb.NewWatchSet()
+	services, err := s.snap.ServiceRegistrations(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := services.Next()
+		if raw == nil {
+			break
+		}
+
+		service := raw.(*structs.ServiceRegistration)
+
+		sink.Write([]byte{byte(ServiceRegistrationSnapshot)})
+		if err := encoder.Encode(service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistVariables(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+
+	ws := memdb.NewWatchSet()
+	variables, err := s.snap.Variables(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := variables.Next()
+		if raw == nil {
+			break
+		}
+
+		v := raw.(*structs.VariableEncrypted)
+
+		sink.Write([]byte{byte(VariablesSnapshot)})
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Release is a no-op, as we just need to GC the pointer
 // to the state store snapshot. There is nothing to explicitly
 // cleanup.
@@ -0,0 +1,178 @@
+package nomad
+
+import (
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// publishEvents publishes the events created by applying the log at index to
+// the event broker, if it is enabled.
+func (n *nomadFSM) publishEvents(index uint64, events []structs.Event) {
+	if n.config.EventBroker == nil || len(events) == 0 {
+		return
+	}
+	n.config.EventBroker.Publish(index, events)
+}
+
+// eventsEnabled returns whether events are published, so that callers can
+// skip looking up the objects of events.
+func (n *nomadFSM) eventsEnabled() bool {
+	return n.config.EventBroker != nil
+}
+
+// jobEvents returns the events of the given jobs as they are in the state
+// store. Jobs that are no longer in the state store, because they were
+// purged, are included as passed.
+func (n *nomadFSM) jobEvents(eventType string, jobs ...*structs.Job) []structs.Event {
+	if !n.eventsEnabled() {
+		return nil
+	}
+
+	events := make([]structs.Event, 0, len(jobs))
+	for _, job := range jobs {
+		if job == nil {
+			continue
+		}
+		current, err := n.state.JobByID(nil, job.Namespace, job.ID)
+		if err != nil {
+			n.logger.Error("failed to look up job for event", "job_id", job.ID, "namespace", job.Namespace, "error", err)
+			continue
+		}
+		if current != nil {
+			job = current
+		}
+		events = append(events, structs.Event{
+			Topic:     structs.TopicJob,
+			Type:      eventType,
+			Key:       job.ID,
+			Namespace: job.Namespace,
+			Payload:   &structs.JobEvent{Job: job},
+		})
+	}
+	return events
+}
+
+// allocEvents returns the events of the allocations with the given IDs. The
+// job of the allocations is not included in the events.
+func (n *nomadFSM) allocEvents(eventType string, allocIDs ...string) []structs.Event {
+	if !n.eventsEnabled() {
+		return nil
+	}
+
+	events := make([]structs.Event, 0, len(allocIDs))
+	for _, id := range allocIDs {
+		alloc, err := n.state.AllocByID(nil, id)
+		if err != nil {
+			n.logger.Error("failed to look up allocation for event", "alloc_id", id, "error", err)
+			continue
+		} else if alloc == nil {
+			continue
+		}
+
+		// The job is omitted since it is large and available from the job
+		// events
+		alloc = alloc.CopySkipJob()
+		alloc.Job = nil
+
+		filterKeys := []string{alloc.JobID}
+		if alloc.DeploymentID != "" {
+			filterKeys = append(filterKeys, alloc.DeploymentID)
+		}
+		events = append(events, structs.Event{
+			Topic:      structs.TopicAllocation,
+			Type:       eventType,
+			Key:        alloc.ID,
+			FilterKeys: filterKeys,
+			Namespace:  alloc.Namespace,
+			Payload:    &structs.AllocationEvent{Allocation: alloc},
+		})
+	}
+	return events
+}
+
+// nodeEvents returns the events of the nodes with the given IDs. The nodes
+// are sanitized so that their secrets are not included.
+func (n *nomadFSM) nodeEvents(eventType string, nodeIDs ...string) []structs.Event {
+	if !n.eventsEnabled() {
+		return nil
+	}
+
+	events := make([]structs.Event, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		node, err := n.state.NodeByID(nil, id)
+		if err != nil {
+			n.logger.Error("failed to look up node for event", "node_id", id, "error", err)
+			continue
+		} else if node == nil {
+			continue
+		}
+		events = append(events, nodeEvent(eventType, node))
+	}
+	return events
+}
+
+// nodeEvent returns the event of the node, sanitizing it so that its secret
+// is not included.
+func nodeEvent(eventType string, node *structs.Node) structs.Event {
+	return structs.Event{
+		Topic:   structs.TopicNode,
+		Type:    eventType,
+		Key:     node.ID,
+		Payload: &structs.NodeStreamEvent{Node: node.Sanitize()},
+	}
+}
+
+// deploymentEvents returns the events of the deployments with the given IDs.
+func (n *nomadFSM) deploymentEvents(eventType string, deploymentIDs ...string) []structs.Event {
+	if !n.eventsEnabled() {
+		return nil
+	}
+
+	events := make([]structs.Event, 0, len(deploymentIDs))
+	for _, id := range deploymentIDs {
+		d, err := n.state.DeploymentByID(nil, id)
+		if err != nil {
+			n.logger.Error("failed to look up deployment for event", "deployment_id", id, "error", err)
+			continue
+		} else if d == nil {
+			continue
+		}
+		events = append(events, structs.Event{
+			Topic:      structs.TopicDeployment,
+			Type:       eventType,
+			Key:        d.ID,
+			FilterKeys: []string{d.JobID},
+			Namespace:  d.Namespace,
+			Payload:    &structs.DeploymentEvent{Deployment: d},
+		})
+	}
+	return events
+}
+
+// planResultEvents returns the events of the allocations and deployments
+// changed by applying a plan.
+func (n *nomadFSM) planResultEvents(req *structs.ApplyPlanResultsRequest) []structs.Event {
+	if !n.eventsEnabled() {
+		return nil
+	}
+
+	ids := append(allocIDs(req.Alloc), allocIDs(req.NodePreemptions)...)
+	events := n.allocEvents(structs.TypePlanResult, ids...)
+
+	var deploymentIDs []string
+	if req.Deployment != nil {
+		deploymentIDs = append(deploymentIDs, req.Deployment.ID)
+	}
+	for _, u := range req.DeploymentUpdates {
+		deploymentIDs = append(deploymentIDs, u.DeploymentID)
+	}
+	return append(events, n.deploymentEvents(structs.TypePlanResult, deploymentIDs...)...)
+}
+
+// allocIDs returns the IDs of the allocations.
+func allocIDs(allocs []*structs.Allocation) []string {
+	ids := make([]string, len(allocs))
+	for i, alloc := range allocs {
+		ids[i] = alloc.ID
+	}
+	return ids
+}
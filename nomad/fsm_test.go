@@ -2,6 +2,7 @@ package nomad
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/stream"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/hashicorp/raft"
@@ -50,11 +52,12 @@ func testFSM(t *testing.T) *nomadFSM {
 	dispatcher, _ := testPeriodicDispatcher(t)
 	logger := testlog.HCLogger(t)
 	fsmConfig := &FSMConfig{
-		EvalBroker: broker,
-		Periodic:   dispatcher,
-		Blocked:    NewBlockedEvals(broker, logger),
-		Logger:     logger,
-		Region:     "global",
+		EvalBroker:  broker,
+		Periodic:    dispatcher,
+		Blocked:     NewBlockedEvals(broker, logger),
+		EventBroker: stream.NewEventBroker(0),
+		Logger:      logger,
+		Region:      "global",
 	}
 	fsm, err := NewFSM(fsmConfig)
 	if err != nil {
@@ -504,6 +507,64 @@ func TestFSM_UpdateNodeEligibility(t *testing.T) {
 	require.Contains(err.Error(), "draining")
 }
 
+func TestFSM_NodeHostVolumes(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	node := mock.Node()
+	req := structs.NodeRegisterRequest{
+		Node: node,
+	}
+	buf, err := structs.Encode(structs.NodeRegisterRequestType, req)
+	require.Nil(err)
+
+	resp := fsm.Apply(makeLog(buf))
+	require.Nil(resp)
+
+	// Register a volume
+	req2 := structs.NodeHostVolumeRegisterRequest{
+		NodeID: node.ID,
+		Volume: &structs.HostVolume{
+			Name:       "data",
+			Path:       "/srv/data",
+			AccessMode: structs.HostVolumeAccessModeReadOnly,
+		},
+	}
+	buf, err = structs.Encode(structs.NodeHostVolumeRegisterRequestType, req2)
+	require.Nil(err)
+
+	resp = fsm.Apply(makeLog(buf))
+	require.Nil(resp)
+
+	// Lookup the node and check
+	node, err = fsm.State().NodeByID(nil, req.Node.ID)
+	require.Nil(err)
+	require.Len(node.HostVolumes, 1)
+	require.Equal("/srv/data", node.HostVolumes["data"].Path)
+	require.Equal(structs.HostVolumeAccessModeReadOnly, node.HostVolumes["data"].AccessMode)
+
+	// Deregister the volume
+	req3 := structs.NodeHostVolumeDeregisterRequest{
+		NodeID: node.ID,
+		Name:   "data",
+	}
+	buf, err = structs.Encode(structs.NodeHostVolumeDeregisterRequestType, req3)
+	require.Nil(err)
+
+	resp = fsm.Apply(makeLog(buf))
+	require.Nil(resp)
+
+	node, err = fsm.State().NodeByID(nil, req.Node.ID)
+	require.Nil(err)
+	require.Empty(node.HostVolumes)
+
+	// Deregistering it again fails
+	resp = fsm.Apply(makeLog(buf))
+	require.NotNil(resp)
+	require.Contains(resp.(error).Error(), "not found")
+}
+
 func TestFSM_UpdateNodeEligibility_Unblock(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -850,6 +911,78 @@ func TestFSM_DeregisterJob_NoPurge(t *testing.T) {
 	}
 }
 
+func TestFSM_BatchRegisterJob(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	existing := mock.Job()
+	req := structs.JobRegisterRequest{
+		Job: existing,
+		WriteRequest: structs.WriteRequest{
+			Namespace: existing.Namespace,
+		},
+	}
+	buf, err := structs.Encode(structs.JobRegisterRequestType, req)
+	require.Nil(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	// A batch enforcing a stale index is rejected as a whole
+	updated := existing.Copy()
+	updated.Priority = 60
+	periodic := mock.PeriodicJob()
+	eval := mock.Eval()
+	eval.JobID = updated.ID
+	req2 := structs.JobBatchRegisterRequest{
+		Jobs: []*structs.JobBatchRegisterEntry{
+			{Job: periodic},
+			{Job: updated, EnforceIndex: true, JobModifyIndex: 100},
+		},
+		Evals: []*structs.Evaluation{eval},
+		WriteRequest: structs.WriteRequest{
+			Namespace: existing.Namespace,
+		},
+	}
+	buf, err = structs.Encode(structs.JobBatchRegisterRequestType, req2)
+	require.Nil(err)
+	resp := fsm.Apply(&raft.Log{Index: 2, Term: 1, Type: raft.LogCommand, Data: buf})
+	err, ok := resp.(error)
+	require.True(ok)
+	require.Contains(err.Error(), "Enforcing job modify index")
+
+	ws := memdb.NewWatchSet()
+	out, err := fsm.State().JobByID(ws, periodic.Namespace, periodic.ID)
+	require.Nil(err)
+	require.Nil(out)
+	evalOut, err := fsm.State().EvalByID(ws, eval.ID)
+	require.Nil(err)
+	require.Nil(evalOut)
+
+	// The batch is applied with the current index
+	req2.Jobs[1].JobModifyIndex = 1
+	buf, err = structs.Encode(structs.JobBatchRegisterRequestType, req2)
+	require.Nil(err)
+	require.Nil(fsm.Apply(&raft.Log{Index: 3, Term: 1, Type: raft.LogCommand, Data: buf}))
+
+	out, err = fsm.State().JobByID(ws, updated.Namespace, updated.ID)
+	require.Nil(err)
+	require.Equal(60, out.Priority)
+	require.EqualValues(3, out.JobModifyIndex)
+
+	evalOut, err = fsm.State().EvalByID(ws, eval.ID)
+	require.Nil(err)
+	require.NotNil(evalOut)
+	require.EqualValues(3, evalOut.JobModifyIndex)
+
+	// The periodic job is tracked by the dispatcher
+	tuple := structs.NamespacedID{
+		ID:        periodic.ID,
+		Namespace: periodic.Namespace,
+	}
+	_, ok = fsm.periodicDispatcher.tracked[tuple]
+	require.True(ok)
+}
+
 func TestFSM_BatchDeregisterJob(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -1169,6 +1302,81 @@ func TestFSM_DeleteEval(t *testing.T) {
 	}
 }
 
+func TestFSM_PublishEvents(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	sub := fsm.config.EventBroker.Subscribe(&stream.SubscribeRequest{
+		Topics:    map[structs.Topic][]string{structs.TopicAll: {"*"}},
+		Namespace: "*",
+	})
+	next := func() *structs.Events {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		events, err := sub.Next(ctx)
+		require.NoError(err)
+		return events
+	}
+	apply := func(index uint64, msgType structs.MessageType, req interface{}) {
+		buf, err := structs.Encode(msgType, req)
+		require.NoError(err)
+		require.Nil(fsm.Apply(&raft.Log{Index: index, Term: 1, Type: raft.LogCommand, Data: buf}))
+	}
+
+	// Node events do not include the secret ID of the node
+	node := mock.Node()
+	apply(10, structs.NodeRegisterRequestType, &structs.NodeRegisterRequest{Node: node})
+	events := next()
+	require.Equal(uint64(10), events.Index)
+	require.Len(events.Events, 1)
+	require.Equal(structs.TopicNode, events.Events[0].Topic)
+	require.Equal(structs.TypeNodeRegistration, events.Events[0].Type)
+	require.Equal(node.ID, events.Events[0].Key)
+	require.Empty(events.Events[0].Payload.(*structs.NodeStreamEvent).Node.SecretID)
+	require.NotEmpty(node.SecretID)
+
+	job := mock.Job()
+	apply(11, structs.JobRegisterRequestType, &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Namespace: job.Namespace},
+	})
+	events = next()
+	require.Len(events.Events, 1)
+	require.Equal(structs.TopicJob, events.Events[0].Topic)
+	require.Equal(structs.TypeJobRegistered, events.Events[0].Type)
+	require.Equal(job.ID, events.Events[0].Key)
+	require.Equal(job.Namespace, events.Events[0].Namespace)
+	require.Equal(uint64(11), events.Events[0].Payload.(*structs.JobEvent).Job.ModifyIndex)
+
+	// Allocation events are filterable by job and do not include the job
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	alloc.NodeID = node.ID
+	apply(12, structs.AllocUpdateRequestType, &structs.AllocUpdateRequest{
+		Job:   job,
+		Alloc: []*structs.Allocation{alloc},
+	})
+	events = next()
+	require.Len(events.Events, 1)
+	require.Equal(structs.TopicAllocation, events.Events[0].Topic)
+	require.Equal(alloc.ID, events.Events[0].Key)
+	require.Contains(events.Events[0].FilterKeys, job.ID)
+	require.Nil(events.Events[0].Payload.(*structs.AllocationEvent).Allocation.Job)
+
+	// Purged jobs are included in deregistration events
+	apply(13, structs.JobDeregisterRequestType, &structs.JobDeregisterRequest{
+		JobID:        job.ID,
+		Purge:        true,
+		WriteRequest: structs.WriteRequest{Namespace: job.Namespace},
+	})
+	events = next()
+	require.Len(events.Events, 1)
+	require.Equal(structs.TypeJobDeregistered, events.Events[0].Type)
+	require.Equal(job.ID, events.Events[0].Payload.(*structs.JobEvent).Job.ID)
+}
+
 func TestFSM_UpsertAllocs(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -1805,6 +2013,79 @@ func TestFSM_ApplyPlanResults(t *testing.T) {
 
 }
 
+func TestFSM_ApplyPlanResultsBatch(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+	fsm.evalBroker.SetEnabled(true)
+
+	// Create two plan results for different jobs
+	var reqs []*structs.ApplyPlanResultsRequest
+	var allocs []*structs.Allocation
+	for i := 0; i < 2; i++ {
+		alloc := mock.Alloc()
+		alloc.Resources = &structs.Resources{} // COMPAT(0.11): Remove in 0.11, used to bypass resource creation in state store
+		job := alloc.Job
+		alloc.Job = nil
+
+		eval := mock.Eval()
+		eval.JobID = job.ID
+		require.NoError(fsm.State().UpsertEvals(1, []*structs.Evaluation{eval}))
+		require.NoError(fsm.State().UpsertJobSummary(1, mock.JobSummary(alloc.JobID)))
+
+		// Preempt an alloc of another job
+		preemptedJob := mock.Job()
+		preempted := mock.Alloc()
+		preempted.Job = preemptedJob
+		preempted.JobID = preemptedJob.ID
+		preempted.PreemptedByAllocation = alloc.ID
+		require.NoError(fsm.State().UpsertAllocs(1, []*structs.Allocation{preempted}))
+
+		preemptionEval := mock.Eval()
+		preemptionEval.JobID = preemptedJob.ID
+
+		reqs = append(reqs, &structs.ApplyPlanResultsRequest{
+			AllocUpdateRequest: structs.AllocUpdateRequest{
+				Job:   job,
+				Alloc: []*structs.Allocation{alloc},
+			},
+			EvalID:          eval.ID,
+			NodePreemptions: []*structs.Allocation{preempted},
+			PreemptionEvals: []*structs.Evaluation{preemptionEval},
+		})
+		allocs = append(allocs, alloc)
+	}
+
+	buf, err := structs.Encode(structs.ApplyPlanResultsBatchRequestType, &structs.ApplyPlanResultsBatchRequest{Results: reqs})
+	require.NoError(err)
+
+	log := makeLog(buf)
+	log.Index = 10
+	require.Nil(fsm.Apply(log))
+
+	// Verify the results of both plans were applied at the same index
+	ws := memdb.NewWatchSet()
+	for i, alloc := range allocs {
+		out, err := fsm.State().AllocByID(ws, alloc.ID)
+		require.NoError(err)
+		require.NotNil(out)
+		require.EqualValues(10, out.CreateIndex)
+		require.NotNil(out.Job)
+
+		evalOut, err := fsm.State().EvalByID(ws, reqs[i].EvalID)
+		require.NoError(err)
+		require.EqualValues(10, evalOut.ModifyIndex)
+
+		// Verify the follow up eval was created and enqueued
+		preemptionEval := reqs[i].PreemptionEvals[0]
+		evalOut, err = fsm.State().EvalByID(ws, preemptionEval.ID)
+		require.NoError(err)
+		require.NotNil(evalOut)
+		_, ok := fsm.evalBroker.evals[preemptionEval.ID]
+		require.True(ok)
+	}
+}
+
 func TestFSM_DeploymentStatusUpdate(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -1915,6 +2196,81 @@ func TestFSM_JobStabilityUpdate(t *testing.T) {
 	}
 }
 
+func TestFSM_JobVersionTag(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+	state := fsm.State()
+
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1, job))
+
+	// Tag the job version
+	req := &structs.JobVersionTagRequest{
+		JobID:      job.ID,
+		JobVersion: job.Version,
+		Tag:        &structs.JobVersionTag{Name: "golden", TaggedTime: 10},
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	buf, err := structs.Encode(structs.JobVersionTagRequestType, req)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByIDAndVersion(ws, job.Namespace, job.ID, job.Version)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal(req.Tag, out.VersionTag)
+
+	// Remove the tag
+	req.Unset = true
+	buf, err = structs.Encode(structs.JobVersionTagRequestType, req)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err = state.JobByIDAndVersion(ws, job.Namespace, job.ID, job.Version)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Nil(out.VersionTag)
+}
+
+func TestFSM_UpsertScalingEvent(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+	state := fsm.State()
+
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1, job))
+
+	req := &structs.ScalingEventRequest{
+		JobID:     job.ID,
+		TaskGroup: "web",
+		ScalingEvent: &structs.ScalingEvent{
+			Time:          10,
+			Count:         helper.Int64ToPtr(5),
+			PreviousCount: 10,
+			Message:       "scaled down",
+		},
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	buf, err := structs.Encode(structs.ScalingEventRegisterRequestType, req)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.ScalingEventsByJob(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Len(out.ScalingEvents["web"], 1)
+	require.Equal("scaled down", out.ScalingEvents["web"][0].Message)
+	require.EqualValues(5, *out.ScalingEvents["web"][0].Count)
+}
+
 func TestFSM_DeploymentPromotion(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -2187,6 +2543,87 @@ func TestFSM_UpsertACLPolicies(t *testing.T) {
 	assert.NotNil(t, out)
 }
 
+func TestFSM_UpsertDeleteACLRoles(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	role := mock.ACLRole()
+	buf, err := structs.Encode(structs.ACLRoleUpsertRequestType, structs.ACLRoleUpsertRequest{
+		Roles: []*structs.ACLRole{role},
+	})
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err := fsm.State().ACLRoleByName(nil, role.Name)
+	require.NoError(err)
+	require.NotNil(out)
+
+	buf, err = structs.Encode(structs.ACLRoleDeleteRequestType, structs.ACLRoleDeleteRequest{
+		Names: []string{role.Name},
+	})
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err = fsm.State().ACLRoleByName(nil, role.Name)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestFSM_UpsertDeleteACLAuthMethods(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	method := mock.ACLAuthMethod()
+	buf, err := structs.Encode(structs.ACLAuthMethodUpsertRequestType, structs.ACLAuthMethodUpsertRequest{
+		AuthMethods: []*structs.ACLAuthMethod{method},
+	})
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err := fsm.State().ACLAuthMethodByName(nil, method.Name)
+	require.NoError(err)
+	require.NotNil(out)
+
+	buf, err = structs.Encode(structs.ACLAuthMethodDeleteRequestType, structs.ACLAuthMethodDeleteRequest{
+		Names: []string{method.Name},
+	})
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err = fsm.State().ACLAuthMethodByName(nil, method.Name)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestFSM_UpsertDeleteACLBindingRules(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	rule := mock.ACLBindingRule()
+	buf, err := structs.Encode(structs.ACLBindingRuleUpsertRequestType, structs.ACLBindingRuleUpsertRequest{
+		Rules: []*structs.ACLBindingRule{rule},
+	})
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err := fsm.State().ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(err)
+	require.NotNil(out)
+
+	buf, err = structs.Encode(structs.ACLBindingRuleDeleteRequestType, structs.ACLBindingRuleDeleteRequest{
+		RuleIDs: []string{rule.ID},
+	})
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err = fsm.State().ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
 func TestFSM_DeleteACLPolicies(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -2659,6 +3096,44 @@ func TestFSM_SnapshotRestore_ACLPolicy(t *testing.T) {
 	assert.Equal(t, p2, out2)
 }
 
+func TestFSM_SnapshotRestore_ACLRoles(t *testing.T) {
+	t.Parallel()
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	r1 := mock.ACLRole()
+	r2 := mock.ACLRole()
+	state.UpsertACLRoles(1000, []*structs.ACLRole{r1, r2})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out1, _ := state2.ACLRoleByName(nil, r1.Name)
+	out2, _ := state2.ACLRoleByName(nil, r2.Name)
+	assert.Equal(t, r1, out1)
+	assert.Equal(t, r2, out2)
+}
+
+func TestFSM_SnapshotRestore_ACLAuthMethods(t *testing.T) {
+	t.Parallel()
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	m1 := mock.ACLAuthMethod()
+	r1 := mock.ACLBindingRule()
+	r1.AuthMethod = m1.Name
+	state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{m1})
+	state.UpsertACLBindingRules(1001, []*structs.ACLBindingRule{r1})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out1, _ := state2.ACLAuthMethodByName(nil, m1.Name)
+	out2, _ := state2.ACLBindingRuleByID(nil, r1.ID)
+	assert.Equal(t, m1, out1)
+	assert.Equal(t, r1, out2)
+}
+
 func TestFSM_SnapshotRestore_ACLTokens(t *testing.T) {
 	t.Parallel()
 	// Add some state
@@ -2701,6 +3176,60 @@ func TestFSM_SnapshotRestore_SchedulerConfiguration(t *testing.T) {
 
 }
 
+func TestFSM_SnapshotRestore_GCConfiguration(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+	state := fsm.State()
+
+	// Snapshots without a GC config restore without one
+	fsm2 := testSnapshotRestore(t, fsm)
+	_, out, err := fsm2.State().GCConfig()
+	require.NoError(err)
+	require.Nil(out)
+
+	gcConfig := &structs.GCConfiguration{
+		EvalGCThreshold: 10 * time.Minute,
+	}
+	require.NoError(state.GCSetConfig(1000, gcConfig))
+
+	fsm2 = testSnapshotRestore(t, fsm)
+	index, out, err := fsm2.State().GCConfig()
+	require.NoError(err)
+	require.EqualValues(1000, index)
+	require.Equal(gcConfig, out)
+}
+
+func TestFSM_SnapshotRestore_ScalingEvents(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+	state := fsm.State()
+
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1000, job))
+	require.NoError(state.UpsertScalingEvent(1001, &structs.ScalingEventRequest{
+		JobID:     job.ID,
+		TaskGroup: "web",
+		ScalingEvent: &structs.ScalingEvent{
+			Time:    10,
+			Message: "scaling failed",
+			Error:   true,
+		},
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}))
+
+	fsm2 := testSnapshotRestore(t, fsm)
+	ws := memdb.NewWatchSet()
+	expected, err := state.ScalingEventsByJob(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	out, err := fsm2.State().ScalingEventsByJob(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Equal(expected, out)
+}
+
 func TestFSM_SnapshotRestore_AddMissingSummary(t *testing.T) {
 	t.Parallel()
 	// Add some state
@@ -3010,3 +3539,230 @@ func TestFSM_SchedulerConfig(t *testing.T) {
 	// Verify that preemption is still enabled
 	require.True(config.PreemptionConfig.SystemSchedulerEnabled)
 }
+
+func TestFSM_GCConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	req := structs.GCSetConfigRequest{
+		Config: structs.GCConfiguration{
+			JobGCThreshold: time.Hour,
+		},
+	}
+	buf, err := structs.Encode(structs.GCConfigRequestType, req)
+	require.NoError(err)
+	resp := fsm.Apply(makeLog(buf))
+	require.Nil(resp)
+
+	_, config, err := fsm.state.GCConfig()
+	require.NoError(err)
+	require.Equal(time.Hour, config.JobGCThreshold)
+
+	// A CAS with an old index is not applied
+	req.CAS = true
+	req.Config.JobGCThreshold = time.Minute
+	req.Config.ModifyIndex = config.ModifyIndex - 1
+	buf, err = structs.Encode(structs.GCConfigRequestType, req)
+	require.NoError(err)
+	resp = fsm.Apply(makeLog(buf))
+	require.Equal(false, resp)
+
+	_, config, err = fsm.state.GCConfig()
+	require.NoError(err)
+	require.Equal(time.Hour, config.JobGCThreshold)
+}
+
+func TestFSM_UpsertDeleteServiceRegistrations(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	alloc := mock.Alloc()
+	require.NoError(fsm.State().UpsertAllocs(1, []*structs.Allocation{alloc}))
+	service := mock.ServiceRegistration(alloc)
+
+	req := structs.ServiceRegistrationUpsertRequest{
+		Services: []*structs.ServiceRegistration{service},
+	}
+	buf, err := structs.Encode(structs.ServiceRegistrationUpsertRequestType, req)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err := fsm.State().ServiceRegistrationByID(nil, service.ID)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal(uint64(1), out.CreateIndex)
+
+	dreq := structs.ServiceRegistrationDeleteRequest{
+		NodeID: alloc.NodeID,
+		IDs:    []string{service.ID},
+	}
+	buf, err = structs.Encode(structs.ServiceRegistrationDeleteRequestType, dreq)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err = fsm.State().ServiceRegistrationByID(nil, service.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestFSM_SnapshotRestore_ServiceRegistrations(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	alloc := mock.Alloc()
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+	s1 := mock.ServiceRegistration(alloc)
+	s2 := mock.ServiceRegistration(alloc)
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{s1, s2}))
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out1, _ := state2.ServiceRegistrationByID(nil, s1.ID)
+	out2, _ := state2.ServiceRegistrationByID(nil, s2.ID)
+	require.Equal(s1, out1)
+	require.Equal(s2, out2)
+}
+
+func TestFSM_UpsertDeleteVariable(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	v := mock.VariableEncrypted()
+	req := structs.VariablesEncryptedUpsertRequest{
+		Var: v,
+	}
+	buf, err := structs.Encode(structs.VariablesUpsertRequestType, req)
+	require.NoError(err)
+	require.Equal(true, fsm.Apply(makeLog(buf)))
+
+	out, err := fsm.State().VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal(uint64(1), out.CreateIndex)
+
+	// A check-and-set write with a stale index is not applied
+	req.CAS = true
+	buf, err = structs.Encode(structs.VariablesUpsertRequestType, req)
+	require.NoError(err)
+	require.Equal(false, fsm.Apply(makeLog(buf)))
+
+	dreq := structs.VariablesDeleteRequest{
+		Path: v.Path,
+		WriteRequest: structs.WriteRequest{
+			Namespace: v.Namespace,
+		},
+	}
+	buf, err = structs.Encode(structs.VariablesDeleteRequestType, dreq)
+	require.NoError(err)
+	require.Equal(true, fsm.Apply(makeLog(buf)))
+
+	out, err = fsm.State().VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestFSM_SnapshotRestore_Variables(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	v1 := mock.VariableEncrypted()
+	v2 := mock.VariableEncrypted()
+	_, err := state.UpsertVariable(1000, v1, false)
+	require.NoError(err)
+	_, err = state.UpsertVariable(1001, v2, false)
+	require.NoError(err)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out1, _ := state2.VariableByPath(nil, v1.Namespace, v1.Path)
+	out2, _ := state2.VariableByPath(nil, v2.Namespace, v2.Path)
+	require.Equal(v1, out1)
+	require.Equal(v2, out2)
+}
+
+func TestFSM_CSIVolume_RegisterClaimDeregister(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	fsm := testFSM(t)
+
+	node := mock.CSINode("ebs")
+	require.NoError(fsm.State().UpsertNode(1, node))
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	require.NoError(fsm.State().UpsertAllocs(1, []*structs.Allocation{alloc}))
+	vol := mock.CSIVolume("ebs")
+
+	req := structs.CSIVolumeRegisterRequest{
+		Volumes: []*structs.CSIVolume{vol},
+	}
+	buf, err := structs.Encode(structs.CSIVolumeRegisterRequestType, req)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	creq := structs.CSIVolumeClaimRequest{
+		VolumeID:     vol.ID,
+		AllocationID: alloc.ID,
+		NodeID:       node.ID,
+		Claim:        structs.CSIVolumeClaimRead,
+		WriteRequest: structs.WriteRequest{Namespace: vol.Namespace},
+	}
+	buf, err = structs.Encode(structs.CSIVolumeClaimRequestType, creq)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err := fsm.State().CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Len(out.ReadClaims, 1)
+
+	// Volumes in use are only deregistered when forced
+	dreq := structs.CSIVolumeDeregisterRequest{
+		VolumeIDs:    []string{vol.ID},
+		WriteRequest: structs.WriteRequest{Namespace: vol.Namespace},
+	}
+	buf, err = structs.Encode(structs.CSIVolumeDeregisterRequestType, dreq)
+	require.NoError(err)
+	require.NotNil(fsm.Apply(makeLog(buf)))
+
+	dreq.Force = true
+	buf, err = structs.Encode(structs.CSIVolumeDeregisterRequestType, dreq)
+	require.NoError(err)
+	require.Nil(fsm.Apply(makeLog(buf)))
+
+	out, err = fsm.State().CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestFSM_SnapshotRestore_CSIVolumes(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	node := mock.CSINode("ebs")
+	require.NoError(state.UpsertNode(1000, node))
+	vol := mock.CSIVolume("ebs")
+	require.NoError(state.CSIVolumeRegister(1001, []*structs.CSIVolume{vol}))
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	plugin, _ := state.CSIPluginByID(nil, "ebs")
+	plugin2, _ := state2.CSIPluginByID(nil, "ebs")
+	require.Equal(plugin, plugin2)
+	out, _ := state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	out2, _ := state2.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.Equal(out, out2)
+}
@@ -21,6 +21,12 @@ const (
 	// NodeHeartbeatEventMissed is the event used when the Nodes heartbeat is
 	// missed.
 	NodeHeartbeatEventMissed = "Node heartbeat missed"
+
+	// heartbeatStormMinNodes and heartbeatStormFraction are the number and
+	// fraction of nodes that must be overdue on their heartbeats for an
+	// expiring heartbeat to be considered part of a correlated miss.
+	heartbeatStormMinNodes = 3
+	heartbeatStormFraction = 0.05
 )
 
 var (
@@ -39,6 +45,25 @@ type nodeHeartbeater struct {
 	// a TTL. On expiration, the node status is updated to be 'down'.
 	heartbeatTimers     map[string]*time.Timer
 	heartbeatTimersLock sync.Mutex
+
+	// heartbeatStates tracks when each node is next expected to heartbeat
+	// and how late its heartbeats arrive. It is guarded by
+	// heartbeatTimersLock.
+	heartbeatStates map[string]*heartbeatState
+}
+
+// heartbeatState tracks the timeliness of a node's heartbeats.
+type heartbeatState struct {
+	// deadline is when the node's next heartbeat is expected.
+	deadline time.Time
+
+	// lateness is a moving average of how late the node's heartbeats arrive
+	// after their deadline.
+	lateness time.Duration
+
+	// deferred is set when the expiration of the node's heartbeat has been
+	// deferred by the missed heartbeat grace.
+	deferred bool
 }
 
 // newNodeHeartbeater returns a new node heartbeater used to detect and act on
@@ -69,6 +94,7 @@ func (h *nodeHeartbeater) initializeHeartbeatTimers() error {
 
 	h.heartbeatTimersLock.Lock()
 	defer h.heartbeatTimersLock.Unlock()
+	h.heartbeatStates = nil
 
 	// Handle each node
 	for {
@@ -104,11 +130,78 @@ func (h *nodeHeartbeater) resetHeartbeatTimer(id string) (time.Duration, error)
 	ttl := lib.RateScaledInterval(h.config.MaxHeartbeatsPerSecond, h.config.MinHeartbeatTTL, n)
 	ttl += lib.RandomStagger(ttl)
 
-	// Reset the TTL
-	h.resetHeartbeatTimerLocked(id, ttl+h.config.HeartbeatGrace)
+	// Reset the TTL, extending the grace for nodes whose heartbeats are
+	// usually late
+	grace := h.config.HeartbeatGrace + h.observeHeartbeatLocked(id, ttl)
+	h.resetHeartbeatTimerLocked(id, ttl+grace)
 	return ttl, nil
 }
 
+// observeHeartbeatLocked records the arrival of a heartbeat from the node and
+// when its next one is expected. It returns the additional grace to give the
+// node based on how late its heartbeats arrive, which is at most the
+// configured heartbeat grace. It assumes the heartbeatTimersLock is held.
+func (h *nodeHeartbeater) observeHeartbeatLocked(id string, ttl time.Duration) time.Duration {
+	if h.heartbeatStates == nil {
+		h.heartbeatStates = make(map[string]*heartbeatState)
+	}
+
+	now := time.Now()
+	state, ok := h.heartbeatStates[id]
+	if !ok {
+		state = &heartbeatState{}
+		h.heartbeatStates[id] = state
+	} else {
+		var late time.Duration
+		if now.After(state.deadline) {
+			late = now.Sub(state.deadline)
+		}
+		state.lateness = (3*state.lateness + late) / 4
+	}
+	state.deadline = now.Add(ttl)
+	state.deferred = false
+
+	extra := 2 * state.lateness
+	if extra > h.config.HeartbeatGrace {
+		extra = h.config.HeartbeatGrace
+	}
+	return extra
+}
+
+// deferExpirationLocked defers the expiration of the node's heartbeat by the
+// missed heartbeat grace if many nodes are overdue on their heartbeats, since
+// correlated misses are more likely caused by the servers being unavailable
+// than by the nodes failing. The expiration of a node is only deferred once
+// between heartbeats. It returns whether the expiration was deferred and
+// assumes the heartbeatTimersLock is held.
+func (h *nodeHeartbeater) deferExpirationLocked(id string) bool {
+	grace := h.config.MissedHeartbeatGrace
+	if grace <= 0 {
+		return false
+	}
+
+	state, ok := h.heartbeatStates[id]
+	if !ok || state.deferred {
+		return false
+	}
+
+	now := time.Now()
+	overdue := 0
+	for _, s := range h.heartbeatStates {
+		if now.After(s.deadline) {
+			overdue++
+		}
+	}
+	if overdue < heartbeatStormMinNodes ||
+		float64(overdue) < heartbeatStormFraction*float64(len(h.heartbeatStates)) {
+		return false
+	}
+
+	state.deferred = true
+	h.resetHeartbeatTimerLocked(id, grace)
+	return true
+}
+
 // resetHeartbeatTimerLocked is used to reset a heartbeat timer
 // assuming the heartbeatTimerLock is already held
 func (h *nodeHeartbeater) resetHeartbeatTimerLocked(id string, ttl time.Duration) {
@@ -150,6 +243,17 @@ func (h *nodeHeartbeater) invalidateHeartbeat(id string) {
 		return
 	}
 
+	h.heartbeatTimersLock.Lock()
+	if h.deferExpirationLocked(id) {
+		h.heartbeatTimersLock.Unlock()
+		h.logger.Warn("node TTL expired while many nodes are missing heartbeats; deferring marking node down",
+			"node_id", id, "grace", h.config.MissedHeartbeatGrace)
+		metrics.IncrCounter([]string{"nomad", "heartbeat", "deferred"}, 1)
+		return
+	}
+	delete(h.heartbeatStates, id)
+	h.heartbeatTimersLock.Unlock()
+
 	h.logger.Warn("node TTL expired", "node_id", id)
 
 	// Make a request to update the node status
@@ -178,6 +282,7 @@ func (h *nodeHeartbeater) clearHeartbeatTimer(id string) error {
 		timer.Stop()
 		delete(h.heartbeatTimers, id)
 	}
+	delete(h.heartbeatStates, id)
 	return nil
 }
 
@@ -191,9 +296,29 @@ func (h *nodeHeartbeater) clearAllHeartbeatTimers() error {
 		t.Stop()
 	}
 	h.heartbeatTimers = nil
+	h.heartbeatStates = nil
 	return nil
 }
 
+// heartbeatSummary returns the number of nodes whose heartbeats are tracked,
+// how many of them are overdue on their heartbeat and how many have had the
+// expiration of their heartbeat deferred.
+func (h *nodeHeartbeater) heartbeatSummary() (tracked, overdue, deferred int) {
+	h.heartbeatTimersLock.Lock()
+	defer h.heartbeatTimersLock.Unlock()
+
+	now := time.Now()
+	for _, state := range h.heartbeatStates {
+		if now.After(state.deadline) {
+			overdue++
+		}
+		if state.deferred {
+			deferred++
+		}
+	}
+	return len(h.heartbeatTimers), overdue, deferred
+}
+
 // heartbeatStats is a long running routine used to capture
 // the number of active heartbeats being tracked
 func (h *nodeHeartbeater) heartbeatStats() {
@@ -162,6 +162,79 @@ func TestHeartbeat_InvalidateHeartbeat(t *testing.T) {
 	require.Equal(NodeHeartbeatEventMissed, out.Events[1].Message)
 }
 
+func TestHeartbeat_InvalidateHeartbeat_Deferred(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.MissedHeartbeatGrace = time.Minute
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create nodes that are all overdue on their heartbeats
+	state := s1.fsm.State()
+	nodes := make([]*structs.Node, heartbeatStormMinNodes)
+	for i := range nodes {
+		nodes[i] = mock.Node()
+		require.NoError(state.UpsertNode(uint64(i+1), nodes[i]))
+		_, err := s1.resetHeartbeatTimer(nodes[i].ID)
+		require.NoError(err)
+	}
+	s1.heartbeatTimersLock.Lock()
+	for _, hs := range s1.heartbeatStates {
+		hs.deadline = time.Now().Add(-time.Second)
+	}
+	s1.heartbeatTimersLock.Unlock()
+
+	// The first expiration should be deferred
+	node := nodes[0]
+	s1.invalidateHeartbeat(node.ID)
+
+	ws := memdb.NewWatchSet()
+	out, err := state.NodeByID(ws, node.ID)
+	require.NoError(err)
+	require.False(out.TerminalStatus())
+
+	s1.heartbeatTimersLock.Lock()
+	_, ok := s1.heartbeatTimers[node.ID]
+	deferred := s1.heartbeatStates[node.ID].deferred
+	s1.heartbeatTimersLock.Unlock()
+	require.True(ok)
+	require.True(deferred)
+
+	// The second expiration should mark the node down
+	s1.invalidateHeartbeat(node.ID)
+
+	out, err = state.NodeByID(ws, node.ID)
+	require.NoError(err)
+	require.True(out.TerminalStatus())
+	require.Equal(NodeHeartbeatEventMissed, out.Events[len(out.Events)-1].Message)
+}
+
+func TestHeartbeat_ObserveHeartbeat(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.HeartbeatGrace = 5 * time.Second
+	})
+	defer s1.Shutdown()
+
+	s1.heartbeatTimersLock.Lock()
+	defer s1.heartbeatTimersLock.Unlock()
+
+	// The first heartbeat gets no additional grace
+	require.Zero(s1.observeHeartbeatLocked("test", 10*time.Second))
+
+	// A heartbeat that is 4s late extends the grace
+	s1.heartbeatStates["test"].deadline = time.Now().Add(-4 * time.Second)
+	extra := s1.observeHeartbeatLocked("test", 10*time.Second)
+	require.True(extra >= 2*time.Second && extra < 3*time.Second, "extra %v", extra)
+
+	// The additional grace is capped by the heartbeat grace
+	s1.heartbeatStates["test"].deadline = time.Now().Add(-time.Minute)
+	require.Equal(5*time.Second, s1.observeHeartbeatLocked("test", 10*time.Second))
+}
+
 func TestHeartbeat_ClearHeartbeatTimer(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
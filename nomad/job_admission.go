@@ -0,0 +1,522 @@
+package nomad
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/nomad/nomad/deploymentwatcher"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+const (
+	// JobAdmissionEnforcementMandatory rules reject the jobs they deny, and
+	// JobAdmissionEnforcementAdvisory rules only add a warning.
+	JobAdmissionEnforcementMandatory = "mandatory"
+	JobAdmissionEnforcementAdvisory  = "advisory"
+
+	// defaultJobAdmissionWebhookTimeout is the timeout of an admission
+	// webhook request if none is configured.
+	defaultJobAdmissionWebhookTimeout = 10 * time.Second
+)
+
+// JobAdmissionWebhook is an HTTP endpoint that validates, and optionally
+// mutates, the jobs being registered.
+type JobAdmissionWebhook struct {
+	// Name identifies the webhook in errors and warnings.
+	Name string
+
+	// URL is the endpoint the jobs are posted to.
+	URL string
+
+	// HMACKey signs the body of the requests with HMAC-SHA256 in the
+	// X-Nomad-Signature header. Requests aren't signed if it is empty.
+	HMACKey string
+
+	// Timeout bounds each request to the endpoint.
+	Timeout time.Duration
+
+	// Mutate allows the webhook to replace the job with the one it returns.
+	Mutate bool
+
+	// IgnoreFailure admits jobs with a warning when the webhook can't be
+	// reached or fails, instead of rejecting them.
+	IgnoreFailure bool
+}
+
+// JobAdmissionRule is a policy the jobs being registered must satisfy,
+// written as an HCL expression evaluated against the job.
+type JobAdmissionRule struct {
+	// Name identifies the rule in errors and warnings.
+	Name string
+
+	// Condition is an HCL expression that must evaluate to true for the job
+	// to be admitted. The job is available as the "job" variable, in the
+	// same form as the JSON of the jobs API.
+	Condition string
+
+	// Message explains why jobs denied by the rule are denied.
+	Message string
+
+	// EnforcementLevel is either JobAdmissionEnforcementMandatory, the
+	// default, or JobAdmissionEnforcementAdvisory.
+	EnforcementLevel string
+}
+
+// JobAdmissionWebhookRequest is the JSON body posted to admission webhooks.
+type JobAdmissionWebhookRequest struct {
+	// Operation is the operation the job is submitted for: "register",
+	// "plan" or "validate".
+	Operation string
+
+	Job *structs.Job
+}
+
+// JobAdmissionWebhookResponse is the JSON body admission webhooks respond
+// with.
+type JobAdmissionWebhookResponse struct {
+	// Allowed must be true for the job to be admitted.
+	Allowed bool
+
+	// Errors explain why the job isn't allowed, and Warnings are returned to
+	// the submitter of an allowed job.
+	Errors   []string
+	Warnings []string
+
+	// Job replaces the submitted job if set and the webhook may mutate jobs.
+	Job *structs.Job
+}
+
+// jobMutator is an admission controller that may change the jobs being
+// registered.
+type jobMutator interface {
+	Name() string
+	Mutate(op string, job *structs.Job) (out *structs.Job, warnings []error, err error)
+}
+
+// jobValidator is an admission controller that accepts or rejects the jobs
+// being registered.
+type jobValidator interface {
+	Name() string
+	Validate(op string, job *structs.Job) (warnings []error, err error)
+}
+
+// jobAdmission runs the admission controllers configured on the server. The
+// mutators run first, in order, followed by all the validators.
+type jobAdmission struct {
+	mutators   []jobMutator
+	validators []jobValidator
+}
+
+// newJobAdmission returns the admission controllers of the server
+// configuration, or an error if a rule is invalid.
+func newJobAdmission(logger log.Logger, c *Config) (*jobAdmission, error) {
+	a := &jobAdmission{}
+	for _, w := range c.JobAdmissionWebhooks {
+		webhook := newJobAdmissionWebhook(logger, w)
+		if w.Mutate {
+			a.mutators = append(a.mutators, webhook)
+		} else {
+			a.validators = append(a.validators, webhook)
+		}
+	}
+
+	var mErr multierror.Error
+	for _, r := range c.JobAdmissionRules {
+		rule, err := newJobAdmissionRule(r)
+		if err != nil {
+			multierror.Append(&mErr, err)
+			continue
+		}
+		a.validators = append(a.validators, rule)
+	}
+	if err := mErr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// admit runs the admission controllers on the job. It returns the admitted
+// job, which is a copy if it was mutated, and the warnings of the
+// controllers.
+func (a *jobAdmission) admit(op string, job *structs.Job) (*structs.Job, []error, error) {
+	var warnings []error
+	for _, m := range a.mutators {
+		out, w, err := m.Mutate(op, job)
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		if out.ID != job.ID || out.Namespace != job.Namespace {
+			return nil, warnings, fmt.Errorf("admission controller %q can't change the ID or namespace of the job", m.Name())
+		}
+		job = out
+	}
+
+	var mErr multierror.Error
+	for _, v := range a.validators {
+		w, err := v.Validate(op, job)
+		warnings = append(warnings, w...)
+		if err != nil {
+			multierror.Append(&mErr, err)
+		}
+	}
+	return job, warnings, mErr.ErrorOrNil()
+}
+
+// admitJob runs the admission controllers of the server on the job. It
+// returns the admitted job, the reason the job was denied if it was, and the
+// warnings of the controllers. Mutated jobs are canonicalized and validated
+// again.
+func (j *Job) admitJob(op string, job *structs.Job) (out *structs.Job, denied, warnings error) {
+	a := j.srv.jobAdmission
+	if a == nil || len(a.mutators)+len(a.validators) == 0 {
+		return job, nil, nil
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "admission"}, time.Now())
+
+	out, warns, err := a.admit(op, job)
+	var mWarn multierror.Error
+	multierror.Append(&mWarn, warns...)
+	if err != nil {
+		return nil, err, mWarn.ErrorOrNil()
+	}
+
+	if out != job {
+		if w := out.Canonicalize(); w != nil {
+			multierror.Append(&mWarn, w)
+		}
+		setImplicitConstraints(out)
+		j.setVaultClusters(out)
+		if err, _ := validateJob(out); err != nil {
+			return nil, fmt.Errorf("admitted job is invalid: %v", err), mWarn.ErrorOrNil()
+		}
+	}
+	return out, nil, mWarn.ErrorOrNil()
+}
+
+// jobAdmissionWebhook is an admission controller posting the jobs to an HTTP
+// endpoint.
+type jobAdmissionWebhook struct {
+	config *JobAdmissionWebhook
+	client *http.Client
+	logger log.Logger
+}
+
+func newJobAdmissionWebhook(logger log.Logger, c *JobAdmissionWebhook) *jobAdmissionWebhook {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultJobAdmissionWebhookTimeout
+	}
+
+	return &jobAdmissionWebhook{
+		config: c,
+		client: &http.Client{Timeout: timeout},
+		logger: logger.Named("job_admission").With("webhook", c.Name),
+	}
+}
+
+func (w *jobAdmissionWebhook) Name() string {
+	return w.config.Name
+}
+
+func (w *jobAdmissionWebhook) Mutate(op string, job *structs.Job) (*structs.Job, []error, error) {
+	resp, warnings, err := w.call(op, job)
+	if err != nil || resp == nil || resp.Job == nil {
+		return job, warnings, err
+	}
+
+	// The Vault token isn't sent to the webhook
+	resp.Job.VaultToken = job.VaultToken
+	return resp.Job, warnings, nil
+}
+
+func (w *jobAdmissionWebhook) Validate(op string, job *structs.Job) ([]error, error) {
+	_, warnings, err := w.call(op, job)
+	return warnings, err
+}
+
+// call posts the job to the webhook and returns its response, or nil if the
+// webhook failed and failures are ignored.
+func (w *jobAdmissionWebhook) call(op string, job *structs.Job) (*JobAdmissionWebhookResponse, []error, error) {
+	resp, err := w.send(op, job)
+	if err != nil {
+		if w.config.IgnoreFailure {
+			w.logger.Warn("admission webhook failed, admitting job", "job_id", job.ID, "error", err)
+			return nil, []error{fmt.Errorf("admission webhook %q failed: %v", w.config.Name, err)}, nil
+		}
+		return nil, nil, fmt.Errorf("admission webhook %q failed: %v", w.config.Name, err)
+	}
+
+	warnings := make([]error, 0, len(resp.Warnings))
+	for _, warn := range resp.Warnings {
+		warnings = append(warnings, fmt.Errorf("admission webhook %q: %s", w.config.Name, warn))
+	}
+	if !resp.Allowed {
+		var mErr multierror.Error
+		for _, e := range resp.Errors {
+			multierror.Append(&mErr, fmt.Errorf("admission webhook %q denied job: %s", w.config.Name, e))
+		}
+		if len(mErr.Errors) == 0 {
+			multierror.Append(&mErr, fmt.Errorf("admission webhook %q denied job", w.config.Name))
+		}
+		return nil, warnings, mErr.ErrorOrNil()
+	}
+	return resp, warnings, nil
+}
+
+// send posts the job, without its Vault token, to the webhook and decodes its
+// response.
+func (w *jobAdmissionWebhook) send(op string, job *structs.Job) (*JobAdmissionWebhookResponse, error) {
+	if job.VaultToken != "" {
+		job = job.Copy()
+		job.VaultToken = ""
+	}
+
+	body, err := json.Marshal(&JobAdmissionWebhookRequest{Operation: op, Job: job})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.config.HMACKey != "" {
+		req.Header.Set(deploymentwatcher.WebhookSignatureHeader,
+			"sha256="+deploymentwatcher.WebhookSignature([]byte(w.config.HMACKey), body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var out JobAdmissionWebhookResponse
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return &out, nil
+}
+
+// jobAdmissionRule is an admission controller evaluating an HCL expression
+// against the jobs.
+type jobAdmissionRule struct {
+	config    *JobAdmissionRule
+	condition hcl.Expression
+}
+
+func newJobAdmissionRule(c *JobAdmissionRule) (*jobAdmissionRule, error) {
+	switch c.EnforcementLevel {
+	case "", JobAdmissionEnforcementMandatory, JobAdmissionEnforcementAdvisory:
+	default:
+		return nil, fmt.Errorf("admission rule %q has invalid enforcement level %q", c.Name, c.EnforcementLevel)
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(c.Condition), c.Name, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("admission rule %q has invalid condition: %v", c.Name, diags)
+	}
+	return &jobAdmissionRule{config: c, condition: expr}, nil
+}
+
+func (r *jobAdmissionRule) Name() string {
+	return r.config.Name
+}
+
+func (r *jobAdmissionRule) Validate(op string, job *structs.Job) ([]error, error) {
+	allowed, err := r.eval(job)
+	if err != nil {
+		err = fmt.Errorf("admission rule %q failed: %v", r.config.Name, err)
+	} else if !allowed {
+		msg := r.config.Message
+		if msg == "" {
+			msg = "condition not satisfied"
+		}
+		err = fmt.Errorf("admission rule %q denied job: %s", r.config.Name, msg)
+	} else {
+		return nil, nil
+	}
+
+	if r.config.EnforcementLevel == JobAdmissionEnforcementAdvisory {
+		return []error{err}, nil
+	}
+	return nil, err
+}
+
+// eval evaluates the condition of the rule against the job.
+func (r *jobAdmissionRule) eval(job *structs.Job) (bool, error) {
+	val, err := jobCtyValue(job)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"job": val},
+		Functions: jobAdmissionFunctions,
+	}
+	out, diags := r.condition.Value(ctx)
+	if diags.HasErrors() {
+		return false, diags
+	}
+	if out.IsNull() || !out.IsKnown() || !out.Type().Equals(cty.Bool) {
+		return false, fmt.Errorf("condition must evaluate to a bool")
+	}
+	return out.True(), nil
+}
+
+// jobCtyValue returns the job as a cty value with the same structure as its
+// JSON encoding.
+func jobCtyValue(job *structs.Job) (cty.Value, error) {
+	buf, err := json.Marshal(job)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return cty.NilVal, err
+	}
+	return jsonCtyValue(raw)
+}
+
+// jsonCtyValue converts a decoded JSON value to a cty value. Objects become
+// cty objects and arrays become tuples.
+func jsonCtyValue(raw interface{}) (cty.Value, error) {
+	switch v := raw.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case string:
+		return cty.StringVal(v), nil
+	case json.Number:
+		return convert.Convert(cty.StringVal(v.String()), cty.Number)
+	case []interface{}:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		vals := make([]cty.Value, len(v))
+		for i, e := range v {
+			val, err := jsonCtyValue(e)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[i] = val
+		}
+		return cty.TupleVal(vals), nil
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+		attrs := make(map[string]cty.Value, len(v))
+		for k, e := range v {
+			val, err := jsonCtyValue(e)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = val
+		}
+		return cty.ObjectVal(attrs), nil
+	default:
+		return cty.NilVal, fmt.Errorf("unexpected JSON value %T", raw)
+	}
+}
+
+// jobAdmissionFunctions are the functions available to the conditions of
+// admission rules.
+var jobAdmissionFunctions = map[string]function.Function{
+	"coalesce": stdlib.CoalesceFunc,
+	"concat":   stdlib.ConcatFunc,
+	"contains": containsFunc,
+	"format":   stdlib.FormatFunc,
+	"keys":     keysFunc,
+	"length":   stdlib.LengthFunc,
+	"lower":    stdlib.LowerFunc,
+	"max":      stdlib.MaxFunc,
+	"min":      stdlib.MinFunc,
+	"upper":    stdlib.UpperFunc,
+}
+
+// containsFunc returns whether a list, set or tuple contains a value.
+var containsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.DynamicPseudoType, AllowNull: true},
+		{Name: "value", Type: cty.DynamicPseudoType, AllowNull: true},
+	},
+	Type: function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		list := args[0]
+		if list.IsNull() {
+			return cty.False, nil
+		}
+		if !list.CanIterateElements() {
+			return cty.NilVal, fmt.Errorf("argument must be a list, set or tuple")
+		}
+		for it := list.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if eq := v.Equals(args[1]); eq.IsKnown() && eq.True() {
+				return cty.True, nil
+			}
+		}
+		return cty.False, nil
+	},
+})
+
+// keysFunc returns the sorted keys of a map or object.
+var keysFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "map", Type: cty.DynamicPseudoType, AllowNull: true},
+	},
+	Type: function.StaticReturnType(cty.List(cty.String)),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		m := args[0]
+		if m.IsNull() {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		if !m.Type().IsMapType() && !m.Type().IsObjectType() {
+			return cty.NilVal, fmt.Errorf("argument must be a map or object")
+		}
+
+		var keys []string
+		for it := m.ElementIterator(); it.Next(); {
+			k, _ := it.Element()
+			keys = append(keys, k.AsString())
+		}
+		if len(keys) == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		sort.Strings(keys)
+
+		vals := make([]cty.Value, len(keys))
+		for i, k := range keys {
+			vals[i] = cty.StringVal(k)
+		}
+		return cty.ListVal(vals), nil
+	},
+})
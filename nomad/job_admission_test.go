@@ -0,0 +1,232 @@
+package nomad
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/deploymentwatcher"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobAdmissionRule(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		Name      string
+		Condition string
+		Level     string
+		Allowed   bool
+		Failed    bool
+		Warning   bool
+	}{
+		{
+			Name:      "resource ceiling",
+			Condition: `max([for tg in job.TaskGroups : tg.Tasks[0].Resources.MemoryMB]...) <= 1024`,
+			Allowed:   true,
+		},
+		{
+			Name:      "resource ceiling exceeded",
+			Condition: `max([for tg in job.TaskGroups : tg.Tasks[0].Resources.MemoryMB]...) <= 128`,
+		},
+		{
+			Name:      "required meta key",
+			Condition: `contains(keys(job.Meta), "owner")`,
+			Allowed:   true,
+		},
+		{
+			Name:      "missing meta key",
+			Condition: `contains(keys(job.Meta), "team")`,
+		},
+		{
+			Name:      "banned driver",
+			Condition: `!contains([for tg in job.TaskGroups : tg.Tasks[0].Driver], "exec")`,
+		},
+		{
+			Name:      "advisory",
+			Condition: `!contains([for tg in job.TaskGroups : tg.Tasks[0].Driver], "exec")`,
+			Level:     JobAdmissionEnforcementAdvisory,
+			Allowed:   true,
+			Warning:   true,
+		},
+		{
+			Name:      "not a bool",
+			Condition: `job.ID`,
+			Failed:    true,
+		},
+	}
+
+	job := mock.Job()
+	job.Meta = map[string]string{"owner": "armon"}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rule, err := newJobAdmissionRule(&JobAdmissionRule{
+				Name:             tc.Name,
+				Condition:        tc.Condition,
+				Message:          "denied",
+				EnforcementLevel: tc.Level,
+			})
+			require.NoError(t, err)
+
+			warnings, err := rule.Validate("register", job)
+			switch {
+			case tc.Allowed:
+				require.NoError(t, err)
+			case tc.Failed:
+				require.Contains(t, err.Error(), "failed")
+			default:
+				require.Contains(t, err.Error(), "denied job: denied")
+			}
+			if tc.Warning {
+				require.Len(t, warnings, 1)
+			} else {
+				require.Empty(t, warnings)
+			}
+		})
+	}
+}
+
+func TestJobAdmissionRule_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := newJobAdmissionRule(&JobAdmissionRule{Name: "bad", Condition: "job.ID =="})
+	require.Error(t, err)
+
+	_, err = newJobAdmissionRule(&JobAdmissionRule{Name: "bad", Condition: "true", EnforcementLevel: "soft"})
+	require.Error(t, err)
+}
+
+func TestJobAdmissionWebhook(t *testing.T) {
+	t.Parallel()
+
+	var received JobAdmissionWebhookRequest
+	var signature string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(deploymentwatcher.WebhookSignatureHeader)
+		require.NoError(t, json.Unmarshal(body, &received))
+		require.Equal(t, "sha256="+deploymentwatcher.WebhookSignature([]byte("key"), body), signature)
+
+		job := received.Job
+		if job.Meta == nil {
+			job.Meta = make(map[string]string)
+		}
+		job.Meta["admitted"] = "true"
+		json.NewEncoder(w).Encode(&JobAdmissionWebhookResponse{
+			Allowed:  true,
+			Warnings: []string{"mutated"},
+			Job:      job,
+		})
+	}))
+	defer ts.Close()
+
+	webhook := newJobAdmissionWebhook(testlog.HCLogger(t), &JobAdmissionWebhook{
+		Name:    "test",
+		URL:     ts.URL,
+		HMACKey: "key",
+		Mutate:  true,
+	})
+
+	job := mock.Job()
+	job.VaultToken = "secret"
+	out, warnings, err := webhook.Mutate("register", job)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, "register", received.Operation)
+	require.Empty(t, received.Job.VaultToken)
+	require.Equal(t, "true", out.Meta["admitted"])
+	require.Equal(t, "secret", out.VaultToken)
+}
+
+func TestJobAdmissionWebhook_Failure(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := &JobAdmissionWebhook{Name: "test", URL: ts.URL}
+	_, err := newJobAdmissionWebhook(testlog.HCLogger(t), c).Validate("register", mock.Job())
+	require.Error(t, err)
+
+	c.IgnoreFailure = true
+	warnings, err := newJobAdmissionWebhook(testlog.HCLogger(t), c).Validate("register", mock.Job())
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+}
+
+func TestJobEndpoint_Register_Admission(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req JobAdmissionWebhookRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		resp := &JobAdmissionWebhookResponse{Allowed: true}
+		if req.Job.Meta["owner"] == "" {
+			resp.Allowed = false
+			resp.Errors = []string{"owner meta key required"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.JobAdmissionWebhooks = []*JobAdmissionWebhook{{Name: "meta", URL: ts.URL}}
+		c.JobAdmissionRules = []*JobAdmissionRule{{
+			Name:             "count",
+			Condition:        `job.TaskGroups[0].Count <= 5`,
+			Message:          "too many instances",
+			EnforcementLevel: JobAdmissionEnforcementAdvisory,
+		}}
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Jobs denied by the webhook are rejected
+	job := mock.Job()
+	job.Meta = nil
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "owner meta key required")
+
+	// Allowed jobs are registered with the warnings of advisory rules
+	job.Meta = map[string]string{"owner": "armon"}
+	job.TaskGroups[0].Count = 10
+	resp = structs.JobRegisterResponse{}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	require.NotZero(t, resp.Index)
+	require.Contains(t, resp.Warnings, "too many instances")
+
+	// Validate reports the denials as validation errors
+	job.Meta = nil
+	vreq := &structs.JobValidateRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var vresp structs.JobValidateResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Validate", vreq, &vresp))
+	require.Len(t, vresp.ValidationErrors, 1)
+	require.Contains(t, vresp.Error, "owner meta key required")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/scheduler"
 )
 
@@ -30,6 +32,10 @@ const (
 	// DispatchPayloadSizeLimit is the maximum size of the uncompressed input
 	// data payload.
 	DispatchPayloadSizeLimit = 16 * 1024
+
+	// DispatchArraySizeLimit is the maximum number of child jobs that may be
+	// dispatched as a single array.
+	DispatchArraySizeLimit = 1000
 )
 
 var (
@@ -67,12 +73,20 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		return fmt.Errorf("missing job for registration")
 	}
 
+	// Multiregion jobs are registered in each of their regions
+	if args.Job.IsMultiregion() && !args.Regional {
+		return j.multiregionRegister(args, reply)
+	}
+
 	// Initialize the job fields (sets defaults and any necessary init work).
 	canonicalizeWarnings := args.Job.Canonicalize()
 
 	// Add implicit constraints
 	setImplicitConstraints(args.Job)
 
+	// Select the Vault clusters of tasks that don't select one
+	j.setVaultClusters(args.Job)
+
 	// Validate the job and capture any warnings
 	err, warnings := validateJob(args.Job)
 	if err != nil {
@@ -86,7 +100,7 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
 	} else if aclObj != nil {
-		if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		if !aclObj.AllowJobOp(args.RequestNamespace(), args.Job.ID, acl.NamespaceCapabilitySubmitJob) {
 			return structs.ErrPermissionDenied
 		}
 		// Check if override is set and we do not have permissions
@@ -99,6 +113,19 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		}
 	}
 
+	// Enforce the submission rate limits
+	if err := j.srv.jobRateLimiter.allow("register", args.RequestNamespace(), args.AuthToken, 1); err != nil {
+		return err
+	}
+
+	// Run the admission controllers, which may replace the job
+	job, err, admissionWarnings := j.admitJob("register", args.Job)
+	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings, admissionWarnings)
+	if err != nil {
+		return err
+	}
+	args.Job = job
+
 	// Lookup the job
 	snap, err := j.srv.State().Snapshot()
 	if err != nil {
@@ -112,16 +139,8 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 
 	// If EnforceIndex set, check it before trying to apply
 	if args.EnforceIndex {
-		jmi := args.JobModifyIndex
-		if existingJob != nil {
-			if jmi == 0 {
-				return fmt.Errorf("%s 0: job already exists", RegisterEnforceIndexErrPrefix)
-			} else if jmi != existingJob.JobModifyIndex {
-				return fmt.Errorf("%s %d: job exists with conflicting job modify index: %d",
-					RegisterEnforceIndexErrPrefix, jmi, existingJob.JobModifyIndex)
-			}
-		} else if jmi != 0 {
-			return fmt.Errorf("%s %d: job does not exist", RegisterEnforceIndexErrPrefix, jmi)
+		if err := checkEnforceIndex(existingJob, args.JobModifyIndex); err != nil {
+			return err
 		}
 	}
 
@@ -131,40 +150,8 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	}
 
 	// Ensure that the job has permissions for the requested Vault tokens
-	policies := args.Job.VaultPolicies()
-	if len(policies) != 0 {
-		vconf := j.srv.config.VaultConfig
-		if !vconf.IsEnabled() {
-			return fmt.Errorf("Vault not enabled and Vault policies requested")
-		}
-
-		// Have to check if the user has permissions
-		if !vconf.AllowsUnauthenticated() {
-			if args.Job.VaultToken == "" {
-				return fmt.Errorf("Vault policies requested but missing Vault Token")
-			}
-
-			vault := j.srv.vault
-			s, err := vault.LookupToken(context.Background(), args.Job.VaultToken)
-			if err != nil {
-				return err
-			}
-
-			allowedPolicies, err := PoliciesFrom(s)
-			if err != nil {
-				return err
-			}
-
-			// If we are given a root token it can access all policies
-			if !lib.StrContains(allowedPolicies, "root") {
-				flatPolicies := structs.VaultPoliciesSet(policies)
-				subset, offending := helper.SliceStringIsSubset(allowedPolicies, flatPolicies)
-				if !subset {
-					return fmt.Errorf("Passed Vault Token doesn't allow access to the following policies: %s",
-						strings.Join(offending, ", "))
-				}
-			}
-		}
+	if err := j.checkVaultPolicies(args.Job); err != nil {
+		return err
 	}
 
 	// Enforce Sentinel policies
@@ -174,14 +161,16 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	}
 	if policyWarnings != nil {
 		reply.Warnings = structs.MergeMultierrorWarnings(warnings,
-			canonicalizeWarnings, policyWarnings)
+			canonicalizeWarnings, admissionWarnings, policyWarnings)
 	}
 
 	// Clear the Vault token
 	args.Job.VaultToken = ""
 
-	// Check if the job has changed at all
-	if existingJob == nil || existingJob.SpecChanged(args.Job) {
+	// Check if the job has changed at all. The regional copy of a multiregion
+	// job is registered with a newer version when any of the regions changed.
+	if existingJob == nil || existingJob.SpecChanged(args.Job) ||
+		(args.Regional && args.Job.Version > existingJob.Version) {
 		// Set the submit time
 		args.Job.SetSubmitTime()
 
@@ -239,6 +228,218 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	return nil
 }
 
+// checkEnforceIndex returns an error if the job modify index of the existing
+// job doesn't match the one enforced by a register. An index of zero requires
+// the job to be new.
+func checkEnforceIndex(existing *structs.Job, jmi uint64) error {
+	if existing != nil {
+		if jmi == 0 {
+			return fmt.Errorf("%s 0: job already exists", RegisterEnforceIndexErrPrefix)
+		} else if jmi != existing.JobModifyIndex {
+			return fmt.Errorf("%s %d: job exists with conflicting job modify index: %d",
+				RegisterEnforceIndexErrPrefix, jmi, existing.JobModifyIndex)
+		}
+	} else if jmi != 0 {
+		return fmt.Errorf("%s %d: job does not exist", RegisterEnforceIndexErrPrefix, jmi)
+	}
+	return nil
+}
+
+// setVaultClusters selects the Vault cluster mapped to the namespace of the
+// job for the tasks that don't select one. Tasks of namespaces that aren't
+// mapped keep using the default cluster.
+func (j *Job) setVaultClusters(job *structs.Job) {
+	cluster := config.VaultClusterFor(j.srv.config.VaultClusters, job.Namespace)
+	if cluster == config.DefaultVaultClusterName {
+		return
+	}
+
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			if task.Vault != nil && task.Vault.Cluster == "" {
+				task.Vault.Cluster = cluster
+			}
+		}
+	}
+}
+
+// checkVaultPolicies ensures that the Vault token of the job allows access to
+// the Vault policies it requests from each Vault cluster.
+func (j *Job) checkVaultPolicies(job *structs.Job) error {
+	policies := job.VaultPolicies()
+	if len(policies) == 0 {
+		return nil
+	}
+
+	// Group the policies by the Vault cluster granting them
+	clusters := make(map[string]map[string]map[string]*structs.Vault)
+	for tg, tasks := range policies {
+		for task, v := range tasks {
+			cp, ok := clusters[v.Cluster]
+			if !ok {
+				cp = make(map[string]map[string]*structs.Vault)
+				clusters[v.Cluster] = cp
+			}
+			if cp[tg] == nil {
+				cp[tg] = make(map[string]*structs.Vault)
+			}
+			cp[tg][task] = v
+		}
+	}
+
+	for cluster, policies := range clusters {
+		if err := j.checkVaultClusterPolicies(job, cluster, policies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVaultClusterPolicies ensures that the job may use the Vault cluster and
+// that its Vault token allows access to the policies requested from it.
+func (j *Job) checkVaultClusterPolicies(job *structs.Job, cluster string, policies map[string]map[string]*structs.Vault) error {
+	vconf := j.srv.config.VaultConfigFor(cluster)
+	if vconf == nil {
+		return fmt.Errorf("Vault cluster %q not configured", cluster)
+	}
+	if !vconf.IsEnabled() {
+		if cluster != "" {
+			return fmt.Errorf("Vault cluster %q not enabled and Vault policies requested", cluster)
+		}
+		return fmt.Errorf("Vault not enabled and Vault policies requested")
+	}
+	if !vconf.AllowsNomadNamespace(job.Namespace) {
+		return fmt.Errorf("Vault cluster %q can't be used by jobs in namespace %q", vconf.GetName(), job.Namespace)
+	}
+
+	// Have to check if the user has permissions. With JWT auth the policies
+	// of tasks are bounded by the Vault role clients log in with instead.
+	if vconf.AllowsUnauthenticated() || vconf.UsesJWTAuth() {
+		return nil
+	}
+	if job.VaultToken == "" {
+		return fmt.Errorf("Vault policies requested but missing Vault Token")
+	}
+
+	s, err := j.srv.vaultClientFor(cluster).LookupToken(context.Background(), job.VaultToken)
+	if err != nil {
+		return err
+	}
+
+	allowedPolicies, err := PoliciesFrom(s)
+	if err != nil {
+		return err
+	}
+
+	// If we are given a root token it can access all policies
+	if !lib.StrContains(allowedPolicies, "root") {
+		flatPolicies := structs.VaultPoliciesSet(policies)
+		subset, offending := helper.SliceStringIsSubset(allowedPolicies, flatPolicies)
+		if !subset {
+			return fmt.Errorf("Passed Vault Token doesn't allow access to the following policies: %s",
+				strings.Join(offending, ", "))
+		}
+	}
+	return nil
+}
+
+// multiregionRegister registers the regional copies of a multiregion job in
+// all its regions. The copies share the same version so that the deployments
+// of the regions can be matched. The reply is the one of the local region.
+func (j *Job) multiregionRegister(args *structs.JobRegisterRequest, reply *structs.JobRegisterResponse) error {
+	job := args.Job
+	job.Canonicalize()
+	if err := job.Multiregion.Validate(); err != nil {
+		return fmt.Errorf("Multiregion validation failed: %v", err)
+	}
+
+	local := j.srv.Region()
+	if job.Multiregion.Index(local) < 0 {
+		return fmt.Errorf("multiregion job must be registered in one of its regions, not in %q", local)
+	}
+
+	// Lookup the job in every region to pick the next version. If none of
+	// the regions changed, the current version is kept.
+	var version uint64
+	found, changed := false, false
+	for _, region := range job.Multiregion.Regions {
+		req := &structs.JobSpecificRequest{
+			JobID: job.ID,
+			QueryOptions: structs.QueryOptions{
+				Region:    region.Name,
+				Namespace: args.RequestNamespace(),
+				AuthToken: args.AuthToken,
+			},
+		}
+		var resp structs.SingleJobResponse
+		if err := j.srv.RPC("Job.GetJob", req, &resp); err != nil {
+			return fmt.Errorf("failed to lookup job in region %q: %v", region.Name, err)
+		}
+
+		existing := resp.Job
+		if existing == nil {
+			changed = true
+			continue
+		}
+		if found && existing.Version != version {
+			changed = true
+		}
+		if existing.Version > version {
+			version = existing.Version
+		}
+		found = true
+
+		regional := job.Multiregion.RegionalJob(job, region.Name)
+		setImplicitConstraints(regional)
+		j.setVaultClusters(regional)
+		if existing.SpecChanged(regional) {
+			changed = true
+		}
+	}
+	if changed && found {
+		version++
+	}
+
+	// Register the local region first so that a job rejected by the local
+	// region isn't registered in any other region
+	regions := []string{local}
+	for _, region := range job.Multiregion.Regions {
+		if region.Name != local {
+			regions = append(regions, region.Name)
+		}
+	}
+
+	for _, region := range regions {
+		req := &structs.JobRegisterRequest{
+			Job:            job.Multiregion.RegionalJob(job, region),
+			PolicyOverride: args.PolicyOverride,
+			Regional:       true,
+			WriteRequest: structs.WriteRequest{
+				Region:    region,
+				Namespace: args.RequestNamespace(),
+				AuthToken: args.AuthToken,
+			},
+		}
+		req.Job.Version = version
+
+		if region == local {
+			req.EnforceIndex = args.EnforceIndex
+			req.JobModifyIndex = args.JobModifyIndex
+			if err := j.Register(req, reply); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var resp structs.JobRegisterResponse
+		if err := j.srv.RPC("Job.Register", req, &resp); err != nil {
+			return fmt.Errorf("failed to register job in region %q: %v", region, err)
+		}
+	}
+
+	return nil
+}
+
 // setImplicitConstraints adds implicit constraints to the job based on the
 // features it is requesting.
 func setImplicitConstraints(j *structs.Job) {
@@ -323,7 +524,7 @@ func (j *Job) Summary(args *structs.JobSummaryRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -365,7 +566,7 @@ func (j *Job) Validate(args *structs.JobValidateRequest, reply *structs.JobValid
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.Job.ID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -375,6 +576,9 @@ func (j *Job) Validate(args *structs.JobValidateRequest, reply *structs.JobValid
 	// Add implicit constraints
 	setImplicitConstraints(args.Job)
 
+	// Select the Vault clusters of tasks that don't select one
+	j.setVaultClusters(args.Job)
+
 	// Validate the job and capture any warnings
 	err, warnings := validateJob(args.Job)
 	if err != nil {
@@ -389,8 +593,25 @@ func (j *Job) Validate(args *structs.JobValidateRequest, reply *structs.JobValid
 		}
 	}
 
+	// Run the admission controllers on valid jobs
+	var admissionWarnings error
+	if err == nil {
+		var denied error
+		_, denied, admissionWarnings = j.admitJob("validate", args.Job)
+		if denied != nil {
+			if merr, ok := denied.(*multierror.Error); ok {
+				for _, err := range merr.Errors {
+					reply.ValidationErrors = append(reply.ValidationErrors, err.Error())
+				}
+			} else {
+				reply.ValidationErrors = append(reply.ValidationErrors, denied.Error())
+			}
+			reply.Error = denied.Error()
+		}
+	}
+
 	// Set the warning message
-	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings)
+	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings, admissionWarnings)
 	reply.DriverConfigValidated = true
 	return nil
 }
@@ -405,7 +626,7 @@ func (j *Job) Revert(args *structs.JobRevertRequest, reply *structs.JobRegisterR
 	// Check for submit-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -431,6 +652,11 @@ func (j *Job) Revert(args *structs.JobRevertRequest, reply *structs.JobRegisterR
 	if args.JobVersion == cur.Version {
 		return fmt.Errorf("can't revert to current version")
 	}
+	if cur.IsMultiregion() {
+		// The versions of the regions must stay in step, so the job has to
+		// be registered again instead
+		return fmt.Errorf("can't revert multiregion job %q, register the wanted version of the job instead", args.JobID)
+	}
 
 	jobV, err := snap.JobByIDAndVersion(ws, args.RequestNamespace(), args.JobID, args.JobVersion)
 	if err != nil {
@@ -470,7 +696,7 @@ func (j *Job) Stable(args *structs.JobStabilityRequest, reply *structs.JobStabil
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -506,6 +732,280 @@ func (j *Job) Stable(args *structs.JobStabilityRequest, reply *structs.JobStabil
 	return nil
 }
 
+// TagVersion is used to tag a job version, or to remove a tag
+func (j *Job) TagVersion(args *structs.JobVersionTagRequest, reply *structs.JobVersionTagResponse) error {
+	if done, err := j.srv.forward("Job.TagVersion", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "tag_version"}, time.Now())
+
+	// Check for submit-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID for tagging job version")
+	}
+	if args.Tag == nil {
+		return fmt.Errorf("missing tag for tagging job version")
+	}
+	if args.Unset {
+		if args.Tag.Name == "" {
+			return fmt.Errorf("missing tag name for removing tag")
+		}
+	} else if err := args.Tag.Validate(); err != nil {
+		return err
+	}
+
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	ws := memdb.NewWatchSet()
+	tagged, err := snap.JobVersionByTag(ws, args.RequestNamespace(), args.JobID, args.Tag.Name)
+	if err != nil {
+		return err
+	}
+	if args.Unset {
+		if tagged == nil {
+			return fmt.Errorf("tag %q not found for job %q in namespace %q", args.Tag.Name, args.JobID, args.RequestNamespace())
+		}
+	} else {
+		if tagged != nil && tagged.Version != args.JobVersion {
+			return fmt.Errorf("tag %q is already applied to version %d", args.Tag.Name, tagged.Version)
+		}
+
+		jobV, err := snap.JobByIDAndVersion(ws, args.RequestNamespace(), args.JobID, args.JobVersion)
+		if err != nil {
+			return err
+		}
+		if jobV == nil {
+			return fmt.Errorf("job %q in namespace %q at version %d not found", args.JobID, args.RequestNamespace(), args.JobVersion)
+		}
+
+		args.Tag.TaggedTime = time.Now().UTC().UnixNano()
+	}
+
+	// Commit this tag request via Raft
+	_, modifyIndex, err := j.srv.raftApply(structs.JobVersionTagRequestType, args)
+	if err != nil {
+		j.logger.Error("submitting job version tag request failed", "error", err)
+		return err
+	}
+
+	// Setup the reply
+	reply.Index = modifyIndex
+	return nil
+}
+
+// Scale is used to change the count of a task group of a job, or to record a
+// scaling event for it without changing the count
+func (j *Job) Scale(args *structs.JobScaleRequest, reply *structs.JobRegisterResponse) error {
+	if done, err := j.srv.forward("Job.Scale", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "scale"}, time.Now())
+
+	// Check for submit-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if err := args.Validate(); err != nil {
+		return err
+	}
+
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	ws := memdb.NewWatchSet()
+	job, err := snap.JobByID(ws, args.RequestNamespace(), args.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job %q not found", args.JobID)
+	}
+	group := job.LookupTaskGroup(args.Group)
+	if group == nil {
+		return fmt.Errorf("task group %q not found in job %q", args.Group, args.JobID)
+	}
+
+	event := &structs.ScalingEvent{
+		Time:          time.Now().UTC().UnixNano(),
+		Count:         args.Count,
+		PreviousCount: int64(group.Count),
+		Message:       args.Message,
+		Error:         args.Error,
+		Meta:          args.Meta,
+	}
+	if j.srv.config.ACLEnabled && args.AuthToken != "" {
+		token, err := snap.ACLTokenBySecretID(nil, args.AuthToken)
+		if err != nil {
+			return err
+		}
+		if token != nil {
+			event.AccessorID = token.AccessorID
+		}
+	}
+
+	if args.Count != nil {
+		switch {
+		case job.Stop:
+			return fmt.Errorf("can't scale stopped job %q", args.JobID)
+		case job.IsPeriodic() || job.IsParameterized():
+			return fmt.Errorf("can't scale periodic or parameterized job %q", args.JobID)
+		case job.IsMultiregion():
+			// The count of each region is set in the multiregion stanza, so
+			// the job has to be registered again instead
+			return fmt.Errorf("can't scale multiregion job %q, register the job with the wanted count instead", args.JobID)
+		}
+
+		// Register the job with the new count, failing if it was modified in
+		// the meantime
+		scaled := job.Copy()
+		scaled.LookupTaskGroup(args.Group).Count = int(*args.Count)
+		reg := &structs.JobRegisterRequest{
+			Job:            scaled,
+			EnforceIndex:   true,
+			JobModifyIndex: job.JobModifyIndex,
+			PolicyOverride: args.PolicyOverride,
+			WriteRequest:   args.WriteRequest,
+		}
+		if err := j.Register(reg, reply); err != nil {
+			return err
+		}
+		event.EvalID = reply.EvalID
+	}
+
+	// Record the scaling event
+	req := &structs.ScalingEventRequest{
+		JobID:        args.JobID,
+		TaskGroup:    args.Group,
+		ScalingEvent: event,
+		WriteRequest: args.WriteRequest,
+	}
+	_, index, err := j.srv.raftApply(structs.ScalingEventRegisterRequestType, req)
+	if err != nil {
+		j.logger.Error("recording scaling event failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// ScaleStatus retrieves the counts and scaling events of the task groups of a
+// job
+func (j *Job) ScaleStatus(args *structs.JobScaleStatusRequest,
+	reply *structs.JobScaleStatusResponse) error {
+
+	if done, err := j.srv.forward("Job.ScaleStatus", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "scale_status"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			job, err := state.JobByID(ws, args.RequestNamespace(), args.JobID)
+			if err != nil {
+				return err
+			}
+			if job == nil {
+				reply.JobScaleStatus = nil
+				index, err := state.Index("jobs")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+				j.srv.setQueryMeta(&reply.QueryMeta)
+				return nil
+			}
+
+			allocs, err := state.AllocsByJob(ws, args.RequestNamespace(), args.JobID, false)
+			if err != nil {
+				return err
+			}
+			events, err := state.ScalingEventsByJob(ws, args.RequestNamespace(), args.JobID)
+			if err != nil {
+				return err
+			}
+
+			status := &structs.JobScaleStatus{
+				JobID:          job.ID,
+				Namespace:      job.Namespace,
+				JobCreateIndex: job.CreateIndex,
+				JobModifyIndex: job.ModifyIndex,
+				JobStopped:     job.Stop,
+				TaskGroups:     make(map[string]*structs.TaskGroupScaleStatus, len(job.TaskGroups)),
+			}
+			for _, tg := range job.TaskGroups {
+				tgStatus := &structs.TaskGroupScaleStatus{
+					Desired: tg.Count,
+				}
+				if events != nil {
+					tgStatus.Events = events.ScalingEvents[tg.Name]
+				}
+				status.TaskGroups[tg.Name] = tgStatus
+			}
+			for _, alloc := range allocs {
+				tgStatus, ok := status.TaskGroups[alloc.TaskGroup]
+				if !ok || alloc.TerminalStatus() {
+					continue
+				}
+				tgStatus.Placed++
+				if alloc.ClientStatus == structs.AllocClientStatusRunning {
+					tgStatus.Running++
+				}
+				if alloc.DeploymentStatus.IsHealthy() {
+					tgStatus.Healthy++
+				} else if alloc.DeploymentStatus.IsUnhealthy() {
+					tgStatus.Unhealthy++
+				}
+			}
+			reply.JobScaleStatus = status
+
+			// Use the last index that affected the status
+			index, err := state.Index("jobs")
+			if err != nil {
+				return err
+			}
+			for _, table := range []string{"allocs", "scaling_event"} {
+				tableIndex, err := state.Index(table)
+				if err != nil {
+					return err
+				}
+				index = helper.Uint64Max(index, tableIndex)
+			}
+			reply.Index = index
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
 // Evaluate is used to force a job for re-evaluation
 func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegisterResponse) error {
 	if done, err := j.srv.forward("Job.Evaluate", args, args, reply); done {
@@ -516,7 +1016,7 @@ func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegis
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -579,101 +1079,291 @@ func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegis
 		Status:         structs.EvalStatusPending,
 	}
 
-	// Create a AllocUpdateDesiredTransitionRequest request with the eval and any forced rescheduled allocs
-	updateTransitionReq := &structs.AllocUpdateDesiredTransitionRequest{
-		Allocs: forceRescheduleAllocs,
-		Evals:  []*structs.Evaluation{eval},
+	// Create a AllocUpdateDesiredTransitionRequest request with the eval and any forced rescheduled allocs
+	updateTransitionReq := &structs.AllocUpdateDesiredTransitionRequest{
+		Allocs: forceRescheduleAllocs,
+		Evals:  []*structs.Evaluation{eval},
+	}
+	_, evalIndex, err := j.srv.raftApply(structs.AllocUpdateDesiredTransitionRequestType, updateTransitionReq)
+
+	if err != nil {
+		j.logger.Error("eval create failed", "error", err, "method", "evaluate")
+		return err
+	}
+
+	// Setup the reply
+	reply.EvalID = eval.ID
+	reply.EvalCreateIndex = evalIndex
+	reply.JobModifyIndex = job.ModifyIndex
+	reply.Index = evalIndex
+	return nil
+}
+
+// Deregister is used to remove a job the cluster.
+func (j *Job) Deregister(args *structs.JobDeregisterRequest, reply *structs.JobDeregisterResponse) error {
+	if done, err := j.srv.forward("Job.Deregister", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "deregister"}, time.Now())
+
+	// Check for submit-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID for deregistering")
+	}
+
+	// Lookup the job
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	ws := memdb.NewWatchSet()
+	job, err := snap.JobByID(ws, args.RequestNamespace(), args.JobID)
+	if err != nil {
+		return err
+	}
+
+	// Commit this update via Raft
+	_, index, err := j.srv.raftApply(structs.JobDeregisterRequestType, args)
+	if err != nil {
+		j.logger.Error("deregister failed", "error", err)
+		return err
+	}
+
+	// Populate the reply with job information
+	reply.JobModifyIndex = index
+
+	// If the job is periodic or parameterized, we don't create an eval.
+	if job != nil && (job.IsPeriodic() || job.IsParameterized()) {
+		return nil
+	}
+
+	// Create a new evaluation
+	// XXX: The job priority / type is strange for this, since it's not a high
+	// priority even if the job was.
+	eval := &structs.Evaluation{
+		ID:             uuid.Generate(),
+		Namespace:      args.RequestNamespace(),
+		Priority:       structs.JobDefaultPriority,
+		Type:           structs.JobTypeService,
+		TriggeredBy:    structs.EvalTriggerJobDeregister,
+		JobID:          args.JobID,
+		JobModifyIndex: index,
+		Status:         structs.EvalStatusPending,
+	}
+	update := &structs.EvalUpdateRequest{
+		Evals:        []*structs.Evaluation{eval},
+		WriteRequest: structs.WriteRequest{Region: args.Region},
+	}
+
+	// Commit this evaluation via Raft
+	_, evalIndex, err := j.srv.raftApply(structs.EvalUpdateRequestType, update)
+	if err != nil {
+		j.logger.Error("eval create failed", "error", err, "method", "deregister")
+		return err
+	}
+
+	// Populate the reply with eval information
+	reply.EvalID = eval.ID
+	reply.EvalCreateIndex = evalIndex
+	reply.Index = evalIndex
+	return nil
+}
+
+// BatchRegister is used to register a set of jobs atomically, along with
+// their evaluations. Either all of the jobs are registered or none are, so
+// that coupled jobs are never partially updated.
+func (j *Job) BatchRegister(args *structs.JobBatchRegisterRequest, reply *structs.JobBatchRegisterResponse) error {
+	if done, err := j.srv.forward("Job.BatchRegister", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "batch_register"}, time.Now())
+
+	// Validate the arguments
+	if len(args.Jobs) == 0 {
+		return fmt.Errorf("given no jobs to register")
+	}
+	if len(args.Evals) != 0 {
+		return fmt.Errorf("evaluations should not be populated")
+	}
+	namespace := args.RequestNamespace()
+	seen := make(map[string]struct{}, len(args.Jobs))
+	for i, entry := range args.Jobs {
+		if entry == nil || entry.Job == nil {
+			return fmt.Errorf("missing job %d for registration", i)
+		}
+		job := entry.Job
+		if _, ok := seen[job.ID]; ok {
+			return fmt.Errorf("job %q is registered more than once", job.ID)
+		}
+		seen[job.ID] = struct{}{}
+		if job.IsMultiregion() {
+			return fmt.Errorf("multiregion job %q can't be registered in a batch", job.ID)
+		}
+	}
+
+	// Check job submission permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil {
+		for _, entry := range args.Jobs {
+			if !aclObj.AllowJobOp(namespace, entry.Job.ID, acl.NamespaceCapabilitySubmitJob) {
+				return structs.ErrPermissionDenied
+			}
+		}
+		// Check if override is set and we do not have permissions
+		if args.PolicyOverride {
+			if !aclObj.AllowNsOp(namespace, acl.NamespaceCapabilitySentinelOverride) {
+				j.logger.Warn("policy override attempted without permissions for job batch")
+				return structs.ErrPermissionDenied
+			}
+			j.logger.Warn("policy override set for job batch")
+		}
+	}
+
+	// Enforce the submission rate limits
+	if err := j.srv.jobRateLimiter.allow("register", namespace, args.AuthToken, len(args.Jobs)); err != nil {
+		return err
+	}
+
+	snap, err := j.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	// Validate each job the same way as a single registration. The FSM
+	// decides which of the jobs changed, and checks the enforced indexes
+	// again, when the batch is applied.
+	reply.Jobs = make([]*structs.JobBatchRegisterResult, len(args.Jobs))
+	for i, entry := range args.Jobs {
+		result, err := j.prepareBatchRegister(snap, namespace, args.PolicyOverride, entry)
+		if err != nil {
+			return fmt.Errorf("job %q: %v", entry.Job.ID, err)
+		}
+		reply.Jobs[i] = result
+
+		// If the job is periodic or parameterized, we don't create an eval.
+		job := entry.Job
+		if job.IsPeriodic() || job.IsParameterized() {
+			continue
+		}
+
+		// The job modify index is set when the batch is applied
+		eval := &structs.Evaluation{
+			ID:          uuid.Generate(),
+			Namespace:   namespace,
+			Priority:    job.Priority,
+			Type:        job.Type,
+			TriggeredBy: structs.EvalTriggerJobRegister,
+			JobID:       job.ID,
+			Status:      structs.EvalStatusPending,
+		}
+		args.Evals = append(args.Evals, eval)
+		result.EvalID = eval.ID
 	}
-	_, evalIndex, err := j.srv.raftApply(structs.AllocUpdateDesiredTransitionRequestType, updateTransitionReq)
 
+	// Commit the jobs and evaluations via Raft
+	fsmErr, index, err := j.srv.raftApply(structs.JobBatchRegisterRequestType, args)
+	if err, ok := fsmErr.(error); ok && err != nil {
+		j.logger.Error("batch register failed", "error", err, "fsm", true)
+		return err
+	}
 	if err != nil {
-		j.logger.Error("eval create failed", "error", err, "method", "evaluate")
+		j.logger.Error("batch register failed", "error", err, "raft", true)
 		return err
 	}
 
-	// Setup the reply
-	reply.EvalID = eval.ID
-	reply.EvalCreateIndex = evalIndex
-	reply.JobModifyIndex = job.ModifyIndex
-	reply.Index = evalIndex
+	// Populate the reply with the resulting job modify indexes
+	current := j.srv.State()
+	for _, result := range reply.Jobs {
+		job, err := current.JobByID(nil, namespace, result.JobID)
+		if err != nil {
+			return err
+		}
+		if job != nil {
+			result.JobModifyIndex = job.JobModifyIndex
+		}
+	}
+
+	reply.EvalCreateIndex = index
+	reply.Index = index
 	return nil
 }
 
-// Deregister is used to remove a job the cluster.
-func (j *Job) Deregister(args *structs.JobDeregisterRequest, reply *structs.JobDeregisterResponse) error {
-	if done, err := j.srv.forward("Job.Deregister", args, args, reply); done {
-		return err
-	}
-	defer metrics.MeasureSince([]string{"nomad", "job", "deregister"}, time.Now())
+// prepareBatchRegister validates a job of a batch registration and returns
+// its result, without the evaluation and job modify index.
+func (j *Job) prepareBatchRegister(snap *state.StateSnapshot, namespace string,
+	policyOverride bool, entry *structs.JobBatchRegisterEntry) (*structs.JobBatchRegisterResult, error) {
 
-	// Check for submit-job permissions
-	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
-		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
-		return structs.ErrPermissionDenied
-	}
+	job := entry.Job
 
-	// Validate the arguments
-	if args.JobID == "" {
-		return fmt.Errorf("missing job ID for deregistering")
+	// Initialize the job fields (sets defaults and any necessary init work).
+	canonicalizeWarnings := job.Canonicalize()
+	if job.Namespace != namespace {
+		return nil, fmt.Errorf("job namespace %q doesn't match the namespace %q of the batch", job.Namespace, namespace)
 	}
 
-	// Lookup the job
-	snap, err := j.srv.fsm.State().Snapshot()
+	// Add implicit constraints
+	setImplicitConstraints(job)
+
+	// Select the Vault clusters of tasks that don't select one
+	j.setVaultClusters(job)
+
+	// Validate the job and capture any warnings
+	err, warnings := validateJob(job)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	ws := memdb.NewWatchSet()
-	job, err := snap.JobByID(ws, args.RequestNamespace(), args.JobID)
+
+	// Run the admission controllers, which may replace the job
+	job, err, admissionWarnings := j.admitJob("register", job)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	entry.Job = job
 
-	// Commit this update via Raft
-	_, index, err := j.srv.raftApply(structs.JobDeregisterRequestType, args)
+	existingJob, err := snap.JobByID(nil, namespace, job.ID)
 	if err != nil {
-		j.logger.Error("deregister failed", "error", err)
-		return err
+		return nil, err
 	}
 
-	// Populate the reply with job information
-	reply.JobModifyIndex = index
-
-	// If the job is periodic or parameterized, we don't create an eval.
-	if job != nil && (job.IsPeriodic() || job.IsParameterized()) {
-		return nil
+	// If EnforceIndex set, check it before trying to apply
+	if entry.EnforceIndex {
+		if err := checkEnforceIndex(existingJob, entry.JobModifyIndex); err != nil {
+			return nil, err
+		}
 	}
 
-	// Create a new evaluation
-	// XXX: The job priority / type is strange for this, since it's not a high
-	// priority even if the job was.
-	eval := &structs.Evaluation{
-		ID:             uuid.Generate(),
-		Namespace:      args.RequestNamespace(),
-		Priority:       structs.JobDefaultPriority,
-		Type:           structs.JobTypeService,
-		TriggeredBy:    structs.EvalTriggerJobDeregister,
-		JobID:          args.JobID,
-		JobModifyIndex: index,
-		Status:         structs.EvalStatusPending,
+	// Validate job transitions if its an update
+	if err := validateJobUpdate(existingJob, job); err != nil {
+		return nil, err
 	}
-	update := &structs.EvalUpdateRequest{
-		Evals:        []*structs.Evaluation{eval},
-		WriteRequest: structs.WriteRequest{Region: args.Region},
+
+	// Ensure that the job has permissions for the requested Vault tokens
+	if err := j.checkVaultPolicies(job); err != nil {
+		return nil, err
 	}
 
-	// Commit this evaluation via Raft
-	_, evalIndex, err := j.srv.raftApply(structs.EvalUpdateRequestType, update)
+	// Enforce Sentinel policies
+	policyWarnings, err := j.enforceSubmitJob(policyOverride, job)
 	if err != nil {
-		j.logger.Error("eval create failed", "error", err, "method", "deregister")
-		return err
+		return nil, err
 	}
 
-	// Populate the reply with eval information
-	reply.EvalID = eval.ID
-	reply.EvalCreateIndex = evalIndex
-	reply.Index = evalIndex
-	return nil
+	// Clear the Vault token
+	job.VaultToken = ""
+	job.SetSubmitTime()
+
+	return &structs.JobBatchRegisterResult{
+		JobID:    job.ID,
+		Warnings: structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings, admissionWarnings, policyWarnings),
+	}, nil
 }
 
 // BatchDeregister is used to remove a set of jobs from the cluster.
@@ -700,7 +1390,7 @@ func (j *Job) BatchDeregister(args *structs.JobBatchDeregisterRequest, reply *st
 	// Loop through checking for permissions
 	for jobNS := range args.Jobs {
 		// Check for submit-job permissions
-		if aclObj != nil && !aclObj.AllowNsOp(jobNS.Namespace, acl.NamespaceCapabilitySubmitJob) {
+		if aclObj != nil && !aclObj.AllowJobOp(jobNS.Namespace, jobNS.ID, acl.NamespaceCapabilitySubmitJob) {
 			return structs.ErrPermissionDenied
 		}
 	}
@@ -769,7 +1459,7 @@ func (j *Job) GetJob(args *structs.JobSpecificRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -815,7 +1505,7 @@ func (j *Job) GetJobVersions(args *structs.JobVersionsRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -862,6 +1552,91 @@ func (j *Job) GetJobVersions(args *structs.JobVersionsRequest,
 	return j.srv.blockingRPC(&opts)
 }
 
+// GetJobVersionDiff is used to diff two versions of a job, selected by their
+// number or tag name.
+func (j *Job) GetJobVersionDiff(args *structs.JobVersionDiffRequest,
+	reply *structs.JobVersionDiffResponse) error {
+	if done, err := j.srv.forward("Job.GetJobVersionDiff", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "get_job_version_diff"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID for diffing job versions")
+	}
+	if args.From == "" {
+		return fmt.Errorf("missing version to diff from")
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			from, err := jobVersionBySelector(ws, state, args.RequestNamespace(), args.JobID, args.From)
+			if err != nil {
+				return err
+			}
+			to, err := jobVersionBySelector(ws, state, args.RequestNamespace(), args.JobID, args.To)
+			if err != nil {
+				return err
+			}
+
+			d, err := from.Diff(to, true)
+			if err != nil {
+				return fmt.Errorf("failed to create job diff: %v", err)
+			}
+			reply.FromVersion = from.Version
+			reply.ToVersion = to.Version
+			reply.Diff = d
+
+			// Use the last index that affected the job versions
+			index, err := state.Index("job_version")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
+// jobVersionBySelector returns the version of the job selected by its number
+// or tag name. The current version is returned if the selector is empty.
+func jobVersionBySelector(ws memdb.WatchSet, state *state.StateStore, namespace, jobID, selector string) (*structs.Job, error) {
+	var job *structs.Job
+	var err error
+	if selector == "" {
+		job, err = state.JobByID(ws, namespace, jobID)
+	} else if version, perr := strconv.ParseUint(selector, 10, 64); perr == nil {
+		job, err = state.JobByIDAndVersion(ws, namespace, jobID, version)
+	} else {
+		job, err = state.JobVersionByTag(ws, namespace, jobID, selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		if selector == "" {
+			return nil, fmt.Errorf("job %q in namespace %q not found", jobID, namespace)
+		}
+		return nil, fmt.Errorf("job %q in namespace %q at version %q not found", jobID, namespace, selector)
+	}
+	return job, nil
+}
+
 // List is used to list the jobs registered in the system
 func (j *Job) List(args *structs.JobListRequest,
 	reply *structs.JobListResponse) error {
@@ -871,9 +1646,10 @@ func (j *Job) List(args *structs.JobListRequest,
 	defer metrics.MeasureSince([]string{"nomad", "job", "list"}, time.Now())
 
 	// Check for list-job permissions
-	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+	aclObj, err := j.srv.ResolveToken(args.AuthToken)
+	if err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityListJobs) {
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityListJobs) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -901,6 +1677,11 @@ func (j *Job) List(args *structs.JobListRequest,
 					break
 				}
 				job := raw.(*structs.Job)
+
+				// Only list the jobs the token may list
+				if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), job.ID, acl.NamespaceCapabilityListJobs) {
+					continue
+				}
 				summary, err := state.JobSummaryByID(ws, args.RequestNamespace(), job.ID)
 				if err != nil {
 					return fmt.Errorf("unable to look up summary for job: %v", job.ID)
@@ -938,7 +1719,7 @@ func (j *Job) Allocations(args *structs.JobSpecificRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -948,7 +1729,13 @@ func (j *Job) Allocations(args *structs.JobSpecificRequest,
 		queryMeta: &reply.QueryMeta,
 		run: func(ws memdb.WatchSet, state *state.StateStore) error {
 			// Capture the allocations
-			allocs, err := state.AllocsByJob(ws, args.RequestNamespace(), args.JobID, args.AllAllocs)
+			var allocs []*structs.Allocation
+			var err error
+			if args.DeploymentID != "" {
+				allocs, err = state.AllocsByJobDeployment(ws, args.RequestNamespace(), args.JobID, args.DeploymentID)
+			} else {
+				allocs, err = state.AllocsByJob(ws, args.RequestNamespace(), args.JobID, args.AllAllocs)
+			}
 			if err != nil {
 				return err
 			}
@@ -957,7 +1744,7 @@ func (j *Job) Allocations(args *structs.JobSpecificRequest,
 			if len(allocs) > 0 {
 				reply.Allocations = make([]*structs.AllocListStub, 0, len(allocs))
 				for _, alloc := range allocs {
-					reply.Allocations = append(reply.Allocations, alloc.Stub())
+					reply.Allocations = append(reply.Allocations, alloc.Stub(nil))
 				}
 			}
 
@@ -987,7 +1774,7 @@ func (j *Job) Evaluations(args *structs.JobSpecificRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -1029,7 +1816,7 @@ func (j *Job) Deployments(args *structs.JobSpecificRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -1071,7 +1858,7 @@ func (j *Job) LatestDeployment(args *structs.JobSpecificRequest,
 	// Check for read-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -1123,9 +1910,19 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	// Initialize the job fields (sets defaults and any necessary init work).
 	canonicalizeWarnings := args.Job.Canonicalize()
 
+	// Plan the regional copy of a multiregion job
+	if args.Job.IsMultiregion() {
+		if regional := args.Job.Multiregion.RegionalJob(args.Job, j.srv.Region()); regional != nil {
+			args.Job = regional
+		}
+	}
+
 	// Add implicit constraints
 	setImplicitConstraints(args.Job)
 
+	// Select the Vault clusters of tasks that don't select one
+	j.setVaultClusters(args.Job)
+
 	// Validate the job and capture any warnings
 	err, warnings := validateJob(args.Job)
 	if err != nil {
@@ -1139,7 +1936,7 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
 	} else if aclObj != nil {
-		if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		if !aclObj.AllowJobOp(args.RequestNamespace(), args.Job.ID, acl.NamespaceCapabilitySubmitJob) {
 			return structs.ErrPermissionDenied
 		}
 		// Check if override is set and we do not have permissions
@@ -1150,6 +1947,14 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 		}
 	}
 
+	// Run the admission controllers, which may replace the job
+	job, err, admissionWarnings := j.admitJob("plan", args.Job)
+	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings, admissionWarnings)
+	if err != nil {
+		return err
+	}
+	args.Job = job
+
 	// Enforce Sentinel policies
 	policyWarnings, err := j.enforceSubmitJob(args.PolicyOverride, args.Job)
 	if err != nil {
@@ -1157,7 +1962,7 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	}
 	if policyWarnings != nil {
 		reply.Warnings = structs.MergeMultierrorWarnings(warnings,
-			canonicalizeWarnings, policyWarnings)
+			canonicalizeWarnings, admissionWarnings, policyWarnings)
 	}
 
 	// Acquire a snapshot of the state
@@ -1335,7 +2140,7 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 	// Check for submit-job permissions
 	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityDispatchJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityDispatchJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -1370,24 +2175,59 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 		return err
 	}
 
-	// Derive the child job and commit it via Raft
-	dispatchJob := parameterizedJob.Copy()
-	dispatchJob.ID = structs.DispatchedID(parameterizedJob.ID, time.Now())
-	dispatchJob.ParentID = parameterizedJob.ID
-	dispatchJob.Name = dispatchJob.ID
-	dispatchJob.SetSubmitTime()
-	dispatchJob.Dispatched = true
+	// Dispatching an array creates count indexed child jobs that share the
+	// array ID
+	count := 1
+	arrayID := ""
+	if args.Count > 1 {
+		count = args.Count
+		arrayID = uuid.Generate()
+	}
+
+	// Enforce the submission rate limits, each child job counting as one
+	// submission
+	if err := j.srv.jobRateLimiter.allow("dispatch", args.RequestNamespace(), args.AuthToken, count); err != nil {
+		return err
+	}
+
+	// Derive the child jobs
+	jobs := make([]*structs.Job, 0, count)
+	for i := 0; i < count; i++ {
+		dispatchJob := parameterizedJob.Copy()
+		dispatchJob.ID = structs.DispatchedID(parameterizedJob.ID, time.Now())
+		dispatchJob.ParentID = parameterizedJob.ID
+		dispatchJob.Name = dispatchJob.ID
+		dispatchJob.SetSubmitTime()
+		dispatchJob.Dispatched = true
 
-	// Merge in the meta data
-	for k, v := range args.Meta {
-		if dispatchJob.Meta == nil {
-			dispatchJob.Meta = make(map[string]string, len(args.Meta))
+		// Merge in the meta data
+		for k, v := range args.Meta {
+			if dispatchJob.Meta == nil {
+				dispatchJob.Meta = make(map[string]string, len(args.Meta))
+			}
+			dispatchJob.Meta[k] = v
+		}
+
+		// Tag the child with its position in the array
+		if arrayID != "" {
+			if dispatchJob.Meta == nil {
+				dispatchJob.Meta = make(map[string]string, 2)
+			}
+			dispatchJob.Meta[structs.DispatchArrayIDMetaKey] = arrayID
+			dispatchJob.Meta[structs.DispatchArrayIndexMetaKey] = strconv.Itoa(i)
 		}
-		dispatchJob.Meta[k] = v
+
+		// Compress the payload
+		dispatchJob.Payload = snappy.Encode(nil, args.Payload)
+		jobs = append(jobs, dispatchJob)
 	}
 
-	// Compress the payload
-	dispatchJob.Payload = snappy.Encode(nil, args.Payload)
+	// The children of an array are committed together so that either all of
+	// them are dispatched or none are
+	if arrayID != "" {
+		return j.dispatchArray(args, arrayID, jobs, reply)
+	}
+	dispatchJob := jobs[0]
 
 	regReq := &structs.JobRegisterRequest{
 		Job:          dispatchJob,
@@ -1443,6 +2283,160 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 	return nil
 }
 
+// dispatchArray commits the child jobs of a dispatched array and their
+// evaluations in a single Raft transaction.
+func (j *Job) dispatchArray(args *structs.JobDispatchRequest, arrayID string,
+	jobs []*structs.Job, reply *structs.JobDispatchResponse) error {
+
+	batch := &structs.JobBatchRegisterRequest{
+		Jobs:         make([]*structs.JobBatchRegisterEntry, 0, len(jobs)),
+		WriteRequest: args.WriteRequest,
+	}
+	jobIDs := make([]string, 0, len(jobs))
+	var evalIDs []string
+	for _, job := range jobs {
+		batch.Jobs = append(batch.Jobs, &structs.JobBatchRegisterEntry{Job: job})
+		jobIDs = append(jobIDs, job.ID)
+
+		// If the job is periodic, we don't create an eval. The job modify
+		// index is set when the batch is applied.
+		if job.IsPeriodic() {
+			continue
+		}
+		eval := &structs.Evaluation{
+			ID:          uuid.Generate(),
+			Namespace:   args.RequestNamespace(),
+			Priority:    job.Priority,
+			Type:        job.Type,
+			TriggeredBy: structs.EvalTriggerJobRegister,
+			JobID:       job.ID,
+			Status:      structs.EvalStatusPending,
+		}
+		batch.Evals = append(batch.Evals, eval)
+		evalIDs = append(evalIDs, eval.ID)
+	}
+
+	// Commit the jobs and evaluations via Raft
+	fsmErr, index, err := j.srv.raftApply(structs.JobBatchRegisterRequestType, batch)
+	if err, ok := fsmErr.(error); ok && err != nil {
+		j.logger.Error("dispatched array register failed", "error", err, "fsm", true)
+		return err
+	}
+	if err != nil {
+		j.logger.Error("dispatched array register failed", "error", err, "raft", true)
+		return err
+	}
+
+	// Setup the reply
+	reply.ArrayID = arrayID
+	reply.DispatchedJobIDs = jobIDs
+	reply.DispatchedJobID = jobIDs[len(jobIDs)-1]
+	reply.JobCreateIndex = index
+	reply.Index = index
+	if len(evalIDs) != 0 {
+		reply.EvalIDs = evalIDs
+		reply.EvalID = evalIDs[len(evalIDs)-1]
+		reply.EvalCreateIndex = index
+	}
+	return nil
+}
+
+// DispatchArray is used to retrieve the aggregate status of the child jobs
+// dispatched as an array.
+func (j *Job) DispatchArray(args *structs.JobDispatchArrayRequest,
+	reply *structs.JobDispatchArrayResponse) error {
+	if done, err := j.srv.forward("Job.DispatchArray", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "dispatch_array"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.JobID == "" {
+		return fmt.Errorf("missing parameterized job ID")
+	}
+	if args.ArrayID == "" {
+		return fmt.Errorf("missing dispatch array ID")
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			iter, err := state.JobsByIDPrefix(ws, args.RequestNamespace(), args.JobID+structs.DispatchLaunchSuffix)
+			if err != nil {
+				return err
+			}
+
+			var children []*structs.Job
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				job := raw.(*structs.Job)
+				if job.ParentID != args.JobID || job.Meta[structs.DispatchArrayIDMetaKey] != args.ArrayID {
+					continue
+				}
+				children = append(children, job)
+			}
+
+			if len(children) != 0 {
+				reply.Summary = dispatchArraySummary(args.JobID, args.ArrayID, children)
+			} else {
+				reply.Summary = nil
+			}
+
+			// Use the last index that affected the jobs table
+			index, err := state.Index("jobs")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
+// dispatchArraySummary aggregates the status of the child jobs of a
+// dispatched array.
+func dispatchArraySummary(jobID, arrayID string, children []*structs.Job) *structs.DispatchArraySummary {
+	// Order the children by their array index
+	sort.Slice(children, func(i, j int) bool {
+		a, _ := strconv.Atoi(children[i].Meta[structs.DispatchArrayIndexMetaKey])
+		b, _ := strconv.Atoi(children[j].Meta[structs.DispatchArrayIndexMetaKey])
+		return a < b
+	})
+
+	summary := &structs.DispatchArraySummary{
+		JobID:   jobID,
+		ArrayID: arrayID,
+		Count:   len(children),
+		JobIDs:  make([]string, 0, len(children)),
+	}
+	for _, child := range children {
+		summary.JobIDs = append(summary.JobIDs, child.ID)
+		switch child.Status {
+		case structs.JobStatusRunning:
+			summary.Running++
+		case structs.JobStatusDead:
+			summary.Dead++
+		default:
+			summary.Pending++
+		}
+	}
+	return summary
+}
+
 // validateDispatchRequest returns whether the request is valid given the
 // parameterized job.
 func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job) error {
@@ -1459,6 +2453,13 @@ func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job)
 		return fmt.Errorf("Payload exceeds maximum size; %d > %d", l, DispatchPayloadSizeLimit)
 	}
 
+	// Check the array size is within bounds
+	if req.Count < 0 {
+		return fmt.Errorf("Dispatch count can not be less than zero: %d < 0", req.Count)
+	} else if req.Count > DispatchArraySizeLimit {
+		return fmt.Errorf("Dispatch count exceeds maximum array size; %d > %d", req.Count, DispatchArraySizeLimit)
+	}
+
 	// Check if the metadata is a set
 	keys := make(map[string]struct{}, len(req.Meta))
 	for k := range keys {
@@ -1506,5 +2507,15 @@ func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job)
 		return fmt.Errorf("Dispatch did not provide required meta keys: %v", flat)
 	}
 
+	// Check the meta values and payload match their declared types and schema
+	if err := job.ParameterizedJob.ValidateDispatchMeta(req.Meta); err != nil {
+		return fmt.Errorf("Dispatch meta does not match the parameterized job: %v", err)
+	}
+	if hasInputData {
+		if err := job.ParameterizedJob.ValidateDispatchPayload(req.Payload); err != nil {
+			return fmt.Errorf("Payload does not match the schema of the parameterized job: %v", err)
+		}
+	}
+
 	return nil
 }
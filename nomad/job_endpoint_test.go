@@ -14,6 +14,7 @@ import (
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/require"
@@ -141,6 +142,44 @@ func TestJobEndpoint_Register_ACL(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_RateLimited(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.JobNamespaceRateLimit = 0.001
+		c.JobNamespaceRateBurst = 2
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// The burst of registrations is allowed
+	for i := 0; i < 2; i++ {
+		req := &structs.JobRegisterRequest{
+			Job:          mock.Job(),
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.JobRegisterResponse
+		require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	}
+
+	// The next one is rejected without being applied
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	require.Error(err)
+	require.True(structs.IsErrJobRateLimited(err))
+
+	out, err := s1.fsm.State().JobByID(memdb.NewWatchSet(), job.Namespace, job.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
 func TestJobEndpoint_Register_InvalidNamespace(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, func(c *Config) {
@@ -848,6 +887,76 @@ func TestJobEndpoint_Register_Vault_Policies(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_Vault_Clusters(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Map the default namespace to the eu Vault cluster
+	tr := true
+	s1.config.VaultClusters = map[string]*config.VaultConfig{
+		"eu": {
+			Name:                 "eu",
+			Enabled:              &tr,
+			AllowUnauthenticated: &tr,
+			NomadNamespaces:      []string{structs.DefaultNamespace},
+		},
+		"apac": {
+			Name:                 "apac",
+			Enabled:              &tr,
+			AllowUnauthenticated: &tr,
+			NomadNamespaces:      []string{"apac"},
+		},
+	}
+
+	register := func(job *structs.Job) error {
+		req := &structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: job.Namespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		return msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	}
+
+	// Tasks that don't select a cluster use the one of their namespace
+	job := mock.Job()
+	job.TaskGroups[0].Tasks[0].Vault = &structs.Vault{
+		Policies:   []string{"foo"},
+		ChangeMode: structs.VaultChangeModeRestart,
+	}
+	require.NoError(register(job))
+
+	out, err := s1.fsm.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal("eu", out.TaskGroups[0].Tasks[0].Vault.Cluster)
+
+	// Clusters must be configured
+	job = mock.Job()
+	job.TaskGroups[0].Tasks[0].Vault = &structs.Vault{
+		Policies:   []string{"foo"},
+		ChangeMode: structs.VaultChangeModeRestart,
+		Cluster:    "us",
+	}
+	err = register(job)
+	require.Error(err)
+	require.Contains(err.Error(), "not configured")
+
+	// Clusters may be restricted to other namespaces
+	job.TaskGroups[0].Tasks[0].Vault.Cluster = "apac"
+	err = register(job)
+	require.Error(err)
+	require.Contains(err.Error(), "can't be used by jobs in namespace")
+}
+
 func TestJobEndpoint_Revert(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, func(c *Config) {
@@ -1130,19 +1239,551 @@ func TestJobEndpoint_Stable(t *testing.T) {
 		t.Fatalf("bad index: %d", resp.Index)
 	}
 
-	// Check that the job is marked stable
-	state := s1.fsm.State()
-	ws := memdb.NewWatchSet()
-	out, err := state.JobByID(ws, job.Namespace, job.ID)
-	if err != nil {
-		t.Fatalf("err: %v", err)
-	}
-	if out == nil {
-		t.Fatalf("expected job")
-	}
-	if !out.Stable {
-		t.Fatalf("Job is not marked stable")
-	}
+	// Check that the job is marked stable
+	state := s1.fsm.State()
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected job")
+	}
+	if !out.Stable {
+		t.Fatalf("Job is not marked stable")
+	}
+}
+
+func TestJobEndpoint_Register_Multiregion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s2.Shutdown()
+	TestJoin(t, s1, s2)
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForLeader(t, s2.RPC)
+	codec := rpcClient(t, s1)
+
+	job := mock.Job()
+	job.Multiregion = &structs.Multiregion{
+		Strategy: &structs.MultiregionStrategy{MaxParallel: 1},
+		Regions: []*structs.MultiregionRegion{
+			{Name: "region1", Count: 2},
+			{Name: "region2", Count: 3, Datacenters: []string{"dc2"}, Meta: map[string]string{"team": "two"}},
+		},
+	}
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "region1",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	require.NotEmpty(resp.EvalID)
+
+	// Each region has its regional copy of the job
+	out1, err := s1.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(out1)
+	require.Equal("region1", out1.Region)
+	require.Equal(2, out1.TaskGroups[0].Count)
+	require.Equal(job.Datacenters, out1.Datacenters)
+	require.EqualValues(0, out1.Version)
+
+	out2, err := s2.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(out2)
+	require.Equal("region2", out2.Region)
+	require.Equal(3, out2.TaskGroups[0].Count)
+	require.Equal([]string{"dc2"}, out2.Datacenters)
+	require.Equal("two", out2.Meta["team"])
+	require.EqualValues(0, out2.Version)
+
+	// Changing a single region bumps the version of all the regions
+	job = job.Copy()
+	job.Multiregion.Regions[1].Count = 4
+	req.Job = job
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+
+	out1, err = s1.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(1, out1.Version)
+	out2, err = s2.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(1, out2.Version)
+	require.Equal(4, out2.TaskGroups[0].Count)
+
+	// Registering the same job again keeps the versions
+	req.Job = job.Copy()
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	out1, err = s1.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(1, out1.Version)
+	out2, err = s2.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(1, out2.Version)
+
+	// Multiregion jobs can't be reverted
+	revert := &structs.JobRevertRequest{
+		JobID:      job.ID,
+		JobVersion: 0,
+		WriteRequest: structs.WriteRequest{
+			Region:    "region1",
+			Namespace: job.Namespace,
+		},
+	}
+	err = msgpackrpc.CallWithCodec(codec, "Job.Revert", revert, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "can't revert multiregion job")
+
+	// The job must be registered in one of its regions
+	other := mock.Job()
+	other.Multiregion = &structs.Multiregion{
+		Regions: []*structs.MultiregionRegion{{Name: "region2"}},
+	}
+	req.Job = other
+	err = msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "must be registered in one of its regions")
+}
+
+func TestJobEndpoint_TagVersion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register the job twice to get two versions
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	req.Job = job.Copy()
+	req.Job.Meta = map[string]string{"version": "1"}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+
+	// Tag the first version
+	tagReq := &structs.JobVersionTagRequest{
+		JobID:      job.ID,
+		JobVersion: 0,
+		Tag:        &structs.JobVersionTag{Name: "golden", Description: "known good"},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var tagResp structs.JobVersionTagResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp))
+	require.NotZero(tagResp.Index)
+
+	state := s1.fsm.State()
+	ws := memdb.NewWatchSet()
+	out, err := state.JobVersionByTag(ws, job.Namespace, job.ID, "golden")
+	require.NoError(err)
+	require.NotNil(out)
+	require.EqualValues(0, out.Version)
+	require.Equal("known good", out.VersionTag.Description)
+	require.NotZero(out.VersionTag.TaggedTime)
+
+	// The tag can't be applied to another version
+	tagReq.JobVersion = 1
+	err = msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp)
+	require.Error(err)
+	require.Contains(err.Error(), "already applied")
+
+	// Tag names can't be version numbers
+	tagReq.Tag = &structs.JobVersionTag{Name: "1"}
+	err = msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp)
+	require.Error(err)
+	require.Contains(err.Error(), "can't be a version number")
+
+	// Diff the tagged version with the current version
+	diffReq := &structs.JobVersionDiffRequest{
+		JobID: job.ID,
+		From:  "golden",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var diffResp structs.JobVersionDiffResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.GetJobVersionDiff", diffReq, &diffResp))
+	require.EqualValues(0, diffResp.FromVersion)
+	require.EqualValues(1, diffResp.ToVersion)
+	require.NotNil(diffResp.Diff)
+	require.Equal(structs.DiffTypeEdited, diffResp.Diff.Type)
+
+	// Diff by version number
+	diffReq.From, diffReq.To = "1", "0"
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.GetJobVersionDiff", diffReq, &diffResp))
+	require.EqualValues(1, diffResp.FromVersion)
+	require.EqualValues(0, diffResp.ToVersion)
+
+	// Unknown versions fail
+	diffReq.From = "unknown"
+	require.Error(msgpackrpc.CallWithCodec(codec, "Job.GetJobVersionDiff", diffReq, &diffResp))
+
+	// Remove the tag
+	untagReq := &structs.JobVersionTagRequest{
+		JobID: job.ID,
+		Tag:   &structs.JobVersionTag{Name: "golden"},
+		Unset: true,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.TagVersion", untagReq, &tagResp))
+	out, err = state.JobVersionByTag(ws, job.Namespace, job.ID, "golden")
+	require.NoError(err)
+	require.Nil(out)
+
+	// Removing an unknown tag fails
+	require.Error(msgpackrpc.CallWithCodec(codec, "Job.TagVersion", untagReq, &tagResp))
+}
+
+func TestJobEndpoint_TagVersion_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, root := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1000, job))
+
+	tagReq := &structs.JobVersionTagRequest{
+		JobID: job.ID,
+		Tag:   &structs.JobVersionTag{Name: "golden"},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Tagging without a token fails
+	var tagResp structs.JobVersionTagResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "Permission denied")
+
+	// Tagging with a read-job token fails
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+	tagReq.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "Permission denied")
+
+	// But diffing is allowed
+	diffReq := &structs.JobVersionDiffRequest{
+		JobID: job.ID,
+		From:  "0",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+			AuthToken: invalidToken.SecretID,
+		},
+	}
+	var diffResp structs.JobVersionDiffResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.GetJobVersionDiff", diffReq, &diffResp))
+
+	// Tagging with a submit-job token succeeds
+	validToken := mock.CreatePolicyAndToken(t, state, 1005, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilitySubmitJob}))
+	tagReq.AuthToken = validToken.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp))
+
+	// Tagging with a management token succeeds
+	tagReq.AuthToken = root.SecretID
+	tagReq.Tag = &structs.JobVersionTag{Name: "other"}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.TagVersion", tagReq, &tagResp))
+}
+
+func TestJobEndpoint_Scale(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register the job
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+
+	// Scale the group
+	scaleReq := &structs.JobScaleRequest{
+		JobID:   job.ID,
+		Group:   "web",
+		Count:   helper.Int64ToPtr(3),
+		Message: "scaling down for the night",
+		Meta:    map[string]string{"source": "test"},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var scaleResp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Scale", scaleReq, &scaleResp))
+	require.NotEmpty(scaleResp.EvalID)
+	require.NotZero(scaleResp.Index)
+
+	state := s1.fsm.State()
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Equal(3, out.LookupTaskGroup("web").Count)
+	require.EqualValues(1, out.Version)
+
+	eval, err := state.EvalByID(ws, scaleResp.EvalID)
+	require.NoError(err)
+	require.NotNil(eval)
+	require.Equal(out.JobModifyIndex, eval.JobModifyIndex)
+
+	events, err := state.ScalingEventsByJob(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Len(events.ScalingEvents["web"], 1)
+	event := events.ScalingEvents["web"][0]
+	require.EqualValues(3, *event.Count)
+	require.EqualValues(10, event.PreviousCount)
+	require.Equal("scaling down for the night", event.Message)
+	require.Equal(scaleResp.EvalID, event.EvalID)
+	require.Equal(map[string]string{"source": "test"}, event.Meta)
+	require.NotZero(event.Time)
+
+	// Events can be recorded without changing the count
+	errReq := &structs.JobScaleRequest{
+		JobID:        job.ID,
+		Group:        "web",
+		Message:      "autoscaler failed to query metrics",
+		Error:        true,
+		WriteRequest: scaleReq.WriteRequest,
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Scale", errReq, &scaleResp))
+	events, err = state.ScalingEventsByJob(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Len(events.ScalingEvents["web"], 2)
+	require.True(events.ScalingEvents["web"][0].Error)
+	require.Nil(events.ScalingEvents["web"][0].Count)
+	require.Empty(events.ScalingEvents["web"][0].EvalID)
+
+	out, err = state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(1, out.Version)
+
+	// Invalid requests fail
+	for _, tc := range []struct {
+		req *structs.JobScaleRequest
+		err string
+	}{
+		{&structs.JobScaleRequest{JobID: job.ID, Group: "web", Count: helper.Int64ToPtr(-1)}, "non-negative"},
+		{&structs.JobScaleRequest{JobID: job.ID, Group: "web"}, "require a message"},
+		{&structs.JobScaleRequest{JobID: job.ID, Group: "api", Count: helper.Int64ToPtr(1)}, "task group \"api\" not found"},
+		{&structs.JobScaleRequest{JobID: "unknown", Group: "web", Count: helper.Int64ToPtr(1)}, "not found"},
+	} {
+		tc.req.WriteRequest = scaleReq.WriteRequest
+		err := msgpackrpc.CallWithCodec(codec, "Job.Scale", tc.req, &scaleResp)
+		require.Error(err)
+		require.Contains(err.Error(), tc.err)
+	}
+
+	// Stopped jobs can't be scaled
+	stopped := out.Copy()
+	stopped.Stop = true
+	require.NoError(state.UpsertJob(1000, stopped))
+	err = msgpackrpc.CallWithCodec(codec, "Job.Scale", scaleReq, &scaleResp)
+	require.Error(err)
+	require.Contains(err.Error(), "can't scale stopped job")
+}
+
+func TestJobEndpoint_Scale_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, root := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1000, job))
+
+	scaleReq := &structs.JobScaleRequest{
+		JobID: job.ID,
+		Group: "web",
+		Count: helper.Int64ToPtr(2),
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Scaling without a token fails
+	var scaleResp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Scale", scaleReq, &scaleResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "Permission denied")
+
+	// Scaling with a read-job token fails, but reading the status succeeds
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+	scaleReq.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Job.Scale", scaleReq, &scaleResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "Permission denied")
+
+	statusReq := &structs.JobScaleStatusRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+			AuthToken: invalidToken.SecretID,
+		},
+	}
+	var statusResp structs.JobScaleStatusResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.ScaleStatus", statusReq, &statusResp))
+
+	// Scaling with a submit-job token succeeds and records who scaled
+	validToken := mock.CreatePolicyAndToken(t, state, 1005, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilitySubmitJob}))
+	scaleReq.AuthToken = validToken.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Scale", scaleReq, &scaleResp))
+
+	events, err := state.ScalingEventsByJob(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Equal(validToken.AccessorID, events.ScalingEvents["web"][0].AccessorID)
+
+	// Scaling with a management token succeeds
+	scaleReq.AuthToken = root.SecretID
+	scaleReq.Count = helper.Int64ToPtr(4)
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Scale", scaleReq, &scaleResp))
+
+	// Reading the status without a token fails
+	statusReq.AuthToken = ""
+	err = msgpackrpc.CallWithCodec(codec, "Job.ScaleStatus", statusReq, &statusResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "Permission denied")
+}
+
+func TestJobEndpoint_ScaleStatus(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	job := mock.Job()
+	statusReq := &structs.JobScaleStatusRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Unknown jobs have no status
+	var statusResp structs.JobScaleStatusResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.ScaleStatus", statusReq, &statusResp))
+	require.Nil(statusResp.JobScaleStatus)
+
+	require.NoError(state.UpsertJob(1000, job))
+
+	// Place allocations in different states
+	running := mock.Alloc()
+	running.Job = job
+	running.JobID = job.ID
+	running.ClientStatus = structs.AllocClientStatusRunning
+	running.DeploymentStatus = &structs.AllocDeploymentStatus{Healthy: helper.BoolToPtr(true)}
+	pending := mock.Alloc()
+	pending.Job = job
+	pending.JobID = job.ID
+	pending.DeploymentStatus = &structs.AllocDeploymentStatus{Healthy: helper.BoolToPtr(false)}
+	stopped := mock.Alloc()
+	stopped.Job = job
+	stopped.JobID = job.ID
+	stopped.DesiredStatus = structs.AllocDesiredStatusStop
+	require.NoError(state.UpsertAllocs(1001, []*structs.Allocation{running, pending, stopped}))
+
+	require.NoError(state.UpsertScalingEvent(1002, &structs.ScalingEventRequest{
+		JobID:        job.ID,
+		TaskGroup:    "web",
+		ScalingEvent: &structs.ScalingEvent{Message: "manual", Count: helper.Int64ToPtr(10)},
+		WriteRequest: structs.WriteRequest{Namespace: job.Namespace},
+	}))
+
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.ScaleStatus", statusReq, &statusResp))
+	status := statusResp.JobScaleStatus
+	require.NotNil(status)
+	require.EqualValues(1002, statusResp.Index)
+	require.Equal(job.ID, status.JobID)
+	require.False(status.JobStopped)
+	require.Contains(status.TaskGroups, "web")
+	tg := status.TaskGroups["web"]
+	require.Equal(10, tg.Desired)
+	require.Equal(2, tg.Placed)
+	require.Equal(1, tg.Running)
+	require.Equal(1, tg.Healthy)
+	require.Equal(1, tg.Unhealthy)
+	require.Len(tg.Events, 1)
+	require.Equal("manual", tg.Events[0].Message)
+
+	// Blocking queries are unblocked by new events
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		state.UpsertScalingEvent(1003, &structs.ScalingEventRequest{
+			JobID:        job.ID,
+			TaskGroup:    "web",
+			ScalingEvent: &structs.ScalingEvent{Message: "again", Error: true},
+			WriteRequest: structs.WriteRequest{Namespace: job.Namespace},
+		})
+	}()
+	statusReq.MinQueryIndex = 1002
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.ScaleStatus", statusReq, &statusResp))
+	require.EqualValues(1003, statusResp.Index)
+	require.Len(statusResp.JobScaleStatus.TaskGroups["web"].Events, 2)
 }
 
 func TestJobEndpoint_Stable_ACL(t *testing.T) {
@@ -1867,6 +2508,198 @@ func TestJobEndpoint_Deregister_ParameterizedJob(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_BatchRegister(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a service and a periodic job together
+	job := mock.Job()
+	periodic := mock.PeriodicJob()
+	req := &structs.JobBatchRegisterRequest{
+		Jobs: []*structs.JobBatchRegisterEntry{
+			{Job: job},
+			{Job: periodic},
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobBatchRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp))
+	require.NotZero(resp.Index)
+	require.Equal(resp.Index, resp.EvalCreateIndex)
+	require.Len(resp.Jobs, 2)
+
+	// Both jobs are registered by the same Raft entry
+	state := s1.fsm.State()
+	for i, j := range []*structs.Job{job, periodic} {
+		out, err := state.JobByID(nil, j.Namespace, j.ID)
+		require.NoError(err)
+		require.NotNil(out)
+		require.Equal(resp.Index, out.JobModifyIndex)
+		require.Equal(j.ID, resp.Jobs[i].JobID)
+		require.Equal(resp.Index, resp.Jobs[i].JobModifyIndex)
+	}
+
+	// Only the service job gets an evaluation
+	require.Empty(resp.Jobs[1].EvalID)
+	eval, err := state.EvalByID(nil, resp.Jobs[0].EvalID)
+	require.NoError(err)
+	require.NotNil(eval)
+	require.Equal(job.ID, eval.JobID)
+	require.Equal(structs.EvalTriggerJobRegister, eval.TriggeredBy)
+	require.Equal(resp.Index, eval.JobModifyIndex)
+	require.Equal(resp.Index, eval.CreateIndex)
+
+	// Registering an unchanged job keeps its modify index
+	job2 := mock.Job()
+	req = &structs.JobBatchRegisterRequest{
+		Jobs: []*structs.JobBatchRegisterEntry{
+			{Job: job.Copy()},
+			{Job: job2},
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp2 structs.JobBatchRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp2))
+	require.Equal(resp.Index, resp2.Jobs[0].JobModifyIndex)
+	require.Equal(resp2.Index, resp2.Jobs[1].JobModifyIndex)
+	out, err := state.JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Zero(out.Version)
+	eval, err = state.EvalByID(nil, resp2.Jobs[0].EvalID)
+	require.NoError(err)
+	require.Equal(resp.Index, eval.JobModifyIndex)
+
+	// Jobs can't be registered twice in a batch
+	req = &structs.JobBatchRegisterRequest{
+		Jobs: []*structs.JobBatchRegisterEntry{
+			{Job: job2.Copy()},
+			{Job: job2.Copy()},
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	err = msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "registered more than once")
+}
+
+func TestJobEndpoint_BatchRegister_Atomic(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	existing := mock.Job()
+	reg := &structs.JobRegisterRequest{
+		Job: existing,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: existing.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", reg, &regResp))
+
+	// A stale enforced index fails the whole batch
+	updated := existing.Copy()
+	updated.Priority = 60
+	newJob := mock.Job()
+	req := &structs.JobBatchRegisterRequest{
+		Jobs: []*structs.JobBatchRegisterEntry{
+			{Job: newJob, EnforceIndex: true},
+			{Job: updated, EnforceIndex: true, JobModifyIndex: regResp.JobModifyIndex - 1},
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: existing.Namespace,
+		},
+	}
+	var resp structs.JobBatchRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), RegisterEnforceIndexErrPrefix)
+
+	state := s1.fsm.State()
+	out, err := state.JobByID(nil, newJob.Namespace, newJob.ID)
+	require.NoError(err)
+	require.Nil(out)
+
+	// An invalid job fails the whole batch
+	invalid := mock.Job()
+	invalid.Type = "invalid"
+	req.Jobs = []*structs.JobBatchRegisterEntry{
+		{Job: newJob},
+		{Job: invalid},
+	}
+	err = msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), invalid.ID)
+
+	out, err = state.JobByID(nil, newJob.Namespace, newJob.ID)
+	require.NoError(err)
+	require.Nil(out)
+
+	// The batch is registered with the current index
+	req.Jobs = []*structs.JobBatchRegisterEntry{
+		{Job: newJob, EnforceIndex: true},
+		{Job: updated, EnforceIndex: true, JobModifyIndex: regResp.JobModifyIndex},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp))
+	out, err = state.JobByID(nil, updated.Namespace, updated.ID)
+	require.NoError(err)
+	require.Equal(60, out.Priority)
+	require.Equal(resp.Index, out.JobModifyIndex)
+}
+
+func TestJobEndpoint_BatchRegister_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	job := mock.Job()
+	req := &structs.JobBatchRegisterRequest{
+		Jobs: []*structs.JobBatchRegisterEntry{{Job: job}},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Try without a token, expect failure
+	var resp structs.JobBatchRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), structs.ErrPermissionDenied.Error())
+
+	// Try with a management token
+	req.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.BatchRegister", req, &resp))
+	require.NotZero(resp.Index)
+}
+
 func TestJobEndpoint_BatchDeregister(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -2919,6 +3752,79 @@ func TestJobEndpoint_ListJobs_WithACL(t *testing.T) {
 	require.Equal(job.ID, validResp.Jobs[0].ID)
 }
 
+func TestJobEndpoint_JobRules_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, _ := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer srv.Shutdown()
+	codec := rpcClient(t, srv)
+	testutil.WaitForLeader(t, srv.RPC)
+	state := srv.fsm.State()
+
+	teamA := mock.Job()
+	teamA.ID = "team-a/web"
+	teamB := mock.Job()
+	teamB.ID = "team-b/web"
+	require.NoError(state.UpsertJob(1000, teamA))
+	require.NoError(state.UpsertJob(1001, teamB))
+
+	token := mock.CreatePolicyAndToken(t, state, 1002, "team-a", `
+namespace "default" {
+	job "team-a/*" {
+		capabilities = ["list-jobs", "read-job", "submit-job"]
+	}
+}`)
+
+	// Only the jobs matching the job rule are listed
+	listReq := &structs.JobListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: token.SecretID,
+		},
+	}
+	var listResp structs.JobListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.List", listReq, &listResp))
+	require.Len(listResp.Jobs, 1)
+	require.Equal(teamA.ID, listResp.Jobs[0].ID)
+
+	// Jobs matching the job rule can be read and registered
+	getReq := &structs.JobSpecificRequest{
+		JobID: teamA.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: token.SecretID,
+		},
+	}
+	var getResp structs.SingleJobResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.GetJob", getReq, &getResp))
+	require.NotNil(getResp.Job)
+
+	regReq := &structs.JobRegisterRequest{
+		Job: teamA.Copy(),
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: token.SecretID,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp))
+
+	// Other jobs of the namespace can't
+	getReq.JobID = teamB.ID
+	err := msgpackrpc.CallWithCodec(codec, "Job.GetJob", getReq, &getResp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	regReq.Job = teamB.Copy()
+	err = msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+}
+
 func TestJobEndpoint_ListJobs_Blocking(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -3026,6 +3932,42 @@ func TestJobEndpoint_Allocations(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Allocations_Deployment(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	// Create two allocations of a job where only one is part of a deployment
+	alloc1 := mock.Alloc()
+	alloc2 := mock.Alloc()
+	alloc2.JobID = alloc1.JobID
+	d := mock.Deployment()
+	d.JobID = alloc1.JobID
+	alloc1.DeploymentID = d.ID
+	state := s1.fsm.State()
+	require.NoError(state.UpsertJobSummary(998, mock.JobSummary(alloc1.JobID)))
+	require.NoError(state.UpsertDeployment(999, d))
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc1, alloc2}))
+
+	// Lookup the allocations of the deployment
+	get := &structs.JobSpecificRequest{
+		JobID:        alloc1.JobID,
+		DeploymentID: d.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: alloc1.Job.Namespace,
+		},
+	}
+	var resp structs.JobAllocationsResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Allocations", get, &resp))
+	require.EqualValues(1000, resp.Index)
+	require.Len(resp.Allocations, 1)
+	require.Equal(alloc1.ID, resp.Allocations[0].ID)
+}
+
 func TestJobEndpoint_Allocations_ACL(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -4080,6 +5022,14 @@ func TestJobEndpoint_Dispatch(t *testing.T) {
 	d7.ParameterizedJob = &structs.ParameterizedJobConfig{}
 	d7.Stop = true
 
+	// Typed meta and payload schema
+	d8 := mock.BatchJob()
+	d8.ParameterizedJob = &structs.ParameterizedJobConfig{
+		MetaRequired:  []string{"count"},
+		MetaTypes:     map[string]string{"count": structs.DispatchMetaTypeInt},
+		PayloadSchema: `{"type": "object", "required": ["name"]}`,
+	}
+
 	reqNoInputNoMeta := &structs.JobDispatchRequest{}
 	reqInputDataNoMeta := &structs.JobDispatchRequest{
 		Payload: []byte("hello world"),
@@ -4105,9 +5055,24 @@ func TestJobEndpoint_Dispatch(t *testing.T) {
 			"baz": "f3",
 		},
 	}
+	reqSchemaMatch := &structs.JobDispatchRequest{
+		Payload: []byte(`{"name": "foo"}`),
+		Meta:    map[string]string{"count": "3"},
+	}
+	reqBadMetaType := &structs.JobDispatchRequest{
+		Payload: []byte(`{"name": "foo"}`),
+		Meta:    map[string]string{"count": "three"},
+	}
+	reqSchemaMismatch := &structs.JobDispatchRequest{
+		Payload: []byte(`{"other": "foo"}`),
+		Meta:    map[string]string{"count": "3"},
+	}
 	reqInputDataTooLarge := &structs.JobDispatchRequest{
 		Payload: make([]byte, DispatchPayloadSizeLimit+100),
 	}
+	reqArrayTooLarge := &structs.JobDispatchRequest{
+		Count: DispatchArraySizeLimit + 1,
+	}
 
 	type testCase struct {
 		name             string
@@ -4195,6 +5160,13 @@ func TestJobEndpoint_Dispatch(t *testing.T) {
 			err:              true,
 			errStr:           "Payload exceeds maximum size",
 		},
+		{
+			name:             "array larger than limit",
+			parameterizedJob: d1,
+			dispatchReq:      reqArrayTooLarge,
+			err:              true,
+			errStr:           "exceeds maximum array size",
+		},
 		{
 			name:             "periodic job dispatched, ensure no eval",
 			parameterizedJob: d6,
@@ -4208,6 +5180,26 @@ func TestJobEndpoint_Dispatch(t *testing.T) {
 			err:              true,
 			errStr:           "stopped",
 		},
+		{
+			name:             "typed meta and payload matching schema",
+			parameterizedJob: d8,
+			dispatchReq:      reqSchemaMatch,
+			err:              false,
+		},
+		{
+			name:             "meta w/ wrong type",
+			parameterizedJob: d8,
+			dispatchReq:      reqBadMetaType,
+			err:              true,
+			errStr:           `Meta key "count" must be of type int`,
+		},
+		{
+			name:             "payload not matching schema",
+			parameterizedJob: d8,
+			dispatchReq:      reqSchemaMismatch,
+			err:              true,
+			errStr:           "does not match the schema",
+		},
 	}
 
 	for _, tc := range cases {
@@ -4316,3 +5308,145 @@ func TestJobEndpoint_Dispatch(t *testing.T) {
 		})
 	}
 }
+
+func TestJobEndpoint_Dispatch_Array(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a parameterized job
+	job := mock.BatchJob()
+	job.ParameterizedJob = &structs.ParameterizedJobConfig{
+		MetaOptional: []string{"foo"},
+	}
+	regReq := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp))
+
+	// Dispatch an array of three children
+	dispatchReq := &structs.JobDispatchRequest{
+		JobID: job.ID,
+		Count: 3,
+		Meta:  map[string]string{"foo": "bar"},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	var dispatchResp structs.JobDispatchResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", dispatchReq, &dispatchResp))
+	require.NotEmpty(dispatchResp.ArrayID)
+	require.Len(dispatchResp.DispatchedJobIDs, 3)
+	require.Len(dispatchResp.EvalIDs, 3)
+
+	// Check the children are tagged with their index and were committed in
+	// a single transaction
+	state := s1.fsm.State()
+	ws := memdb.NewWatchSet()
+	for i, id := range dispatchResp.DispatchedJobIDs {
+		out, err := state.JobByID(ws, job.Namespace, id)
+		require.NoError(err)
+		require.NotNil(out)
+		require.Equal(job.ID, out.ParentID)
+		require.Equal("bar", out.Meta["foo"])
+		require.Equal(dispatchResp.ArrayID, out.Meta[structs.DispatchArrayIDMetaKey])
+		require.Equal(fmt.Sprintf("%d", i), out.Meta[structs.DispatchArrayIndexMetaKey])
+		require.Equal(dispatchResp.JobCreateIndex, out.CreateIndex)
+
+		eval, err := state.EvalByID(ws, dispatchResp.EvalIDs[i])
+		require.NoError(err)
+		require.NotNil(eval)
+		require.Equal(id, eval.JobID)
+		require.Equal(dispatchResp.EvalCreateIndex, eval.CreateIndex)
+		require.Equal(out.JobModifyIndex, eval.JobModifyIndex)
+	}
+	require.Equal(dispatchResp.JobCreateIndex, dispatchResp.EvalCreateIndex)
+
+	// Lookup the aggregate status of the array
+	arrayReq := &structs.JobDispatchArrayRequest{
+		JobID:   job.ID,
+		ArrayID: dispatchResp.ArrayID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var arrayResp structs.JobDispatchArrayResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.DispatchArray", arrayReq, &arrayResp))
+	require.NotNil(arrayResp.Summary)
+	require.Equal(3, arrayResp.Summary.Count)
+	require.Equal(3, arrayResp.Summary.Pending)
+	require.Equal(dispatchResp.DispatchedJobIDs, arrayResp.Summary.JobIDs)
+	require.False(arrayResp.Summary.Complete())
+
+	// An unknown array returns no summary
+	arrayReq.ArrayID = uuid.Generate()
+	var missingResp structs.JobDispatchArrayResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.DispatchArray", arrayReq, &missingResp))
+	require.Nil(missingResp.Summary)
+}
+
+func TestJobEndpoint_Dispatch_RateLimited(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, root := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.JobTokenRateLimit = 0.001
+		c.JobTokenRateBurst = 3
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// Create a parameterized job
+	job := mock.BatchJob()
+	job.ParameterizedJob = &structs.ParameterizedJobConfig{}
+	require.NoError(state.UpsertJob(400, job))
+
+	otherToken := mock.CreatePolicyAndToken(t, state, 1001, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityDispatchJob}))
+
+	req := &structs.JobDispatchRequest{
+		JobID: job.ID,
+		Count: 2,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+			AuthToken: root.SecretID,
+		},
+	}
+
+	// Each child counts against the limit of the token
+	var resp structs.JobDispatchResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp))
+	require.Len(resp.DispatchedJobIDs, 2)
+
+	err := msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp)
+	require.Error(err)
+	require.True(structs.IsErrJobRateLimited(err))
+
+	// A single dispatch still fits
+	req.Count = 0
+	var resp2 structs.JobDispatchResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp2))
+
+	// Other tokens have their own limit
+	req.AuthToken = otherToken.SecretID
+	var resp3 structs.JobDispatchResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp3))
+}
@@ -0,0 +1,151 @@
+package nomad
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// jobRateLimitIdleTTL is the minimum time the limiter of a namespace or
+	// token is kept after its last use.
+	jobRateLimitIdleTTL = 10 * time.Minute
+
+	// jobRateLimitGCInterval is the minimum interval between collections of
+	// the idle limiters.
+	jobRateLimitGCInterval = time.Minute
+)
+
+// jobRateLimiter limits the rate of job registrations and dispatches per
+// namespace and per ACL token, protecting the Raft log from clients
+// submitting jobs in a loop.
+type jobRateLimiter struct {
+	namespaces *keyedRateLimiter
+	tokens     *keyedRateLimiter
+}
+
+// newJobRateLimiter returns a job rate limiter for the server configuration.
+// Limits whose rate is zero are disabled.
+func newJobRateLimiter(c *Config) *jobRateLimiter {
+	return &jobRateLimiter{
+		namespaces: newKeyedRateLimiter(c.JobNamespaceRateLimit, c.JobNamespaceRateBurst),
+		tokens:     newKeyedRateLimiter(c.JobTokenRateLimit, c.JobTokenRateBurst),
+	}
+}
+
+// allow consumes n submissions from the limits of the namespace and token and
+// returns structs.ErrJobRateLimited if either is exceeded, in which case
+// nothing is consumed. The op is only used to label the emitted metrics.
+func (j *jobRateLimiter) allow(op, namespace, token string, n int) error {
+	now := time.Now()
+	limit := ""
+	if ns, ok := j.namespaces.reserve(namespace, now, n); !ok {
+		limit = "namespace"
+	} else if _, ok := j.tokens.reserve(token, now, n); !ok {
+		limit = "token"
+		if ns != nil {
+			ns.CancelAt(now)
+		}
+	}
+	if limit == "" {
+		return nil
+	}
+
+	metrics.IncrCounterWithLabels([]string{"nomad", "job", "rate_limited"}, 1, []metrics.Label{
+		{Name: "op", Value: op},
+		{Name: "namespace", Value: namespace},
+		{Name: "limit", Value: limit},
+	})
+	return structs.ErrJobRateLimited
+}
+
+// keyedRateLimiter is a token bucket rate limiter per key.
+type keyedRateLimiter struct {
+	limit   rate.Limit
+	burst   int
+	idleTTL time.Duration
+
+	limiters map[string]*keyedLimiterEntry
+	lastGC   time.Time
+	l        sync.Mutex
+}
+
+// keyedLimiterEntry is the limiter of a key and when it was last used.
+type keyedLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// newKeyedRateLimiter returns a limiter allowing the given number of events
+// per second for each key. The burst defaults to the rate rounded up. A nil
+// limiter, which allows everything, is returned if the rate is zero.
+func newKeyedRateLimiter(perSecond float64, burst int) *keyedRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(perSecond))
+	}
+
+	// Limiters are only dropped once they have refilled, so that dropping
+	// them doesn't loosen the limit.
+	idleTTL := time.Duration(float64(burst) / perSecond * float64(time.Second))
+	if idleTTL < jobRateLimitIdleTTL {
+		idleTTL = jobRateLimitIdleTTL
+	}
+
+	return &keyedRateLimiter{
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		idleTTL:  idleTTL,
+		limiters: make(map[string]*keyedLimiterEntry),
+	}
+}
+
+// reserve returns whether n events are allowed for the key at the given time.
+// If so they are consumed and the returned reservation can be cancelled to
+// give them back. The reservation is nil if the limiter is disabled.
+func (k *keyedRateLimiter) reserve(key string, now time.Time, n int) (*rate.Reservation, bool) {
+	if k == nil {
+		return nil, true
+	}
+
+	k.l.Lock()
+	defer k.l.Unlock()
+
+	if now.Sub(k.lastGC) > jobRateLimitGCInterval {
+		k.gc(now)
+	}
+
+	entry, ok := k.limiters[key]
+	if !ok {
+		entry = &keyedLimiterEntry{limiter: rate.NewLimiter(k.limit, k.burst)}
+		k.limiters[key] = entry
+	}
+	entry.lastUsed = now
+
+	r := entry.limiter.ReserveN(now, n)
+	if !r.OK() {
+		return nil, false
+	}
+	if r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		return nil, false
+	}
+	return r, true
+}
+
+// gc drops the limiters that haven't been used recently. It must be called
+// with the lock held.
+func (k *keyedRateLimiter) gc(now time.Time) {
+	for key, entry := range k.limiters {
+		if now.Sub(entry.lastUsed) > k.idleTTL {
+			delete(k.limiters, key)
+		}
+	}
+	k.lastGC = now
+}
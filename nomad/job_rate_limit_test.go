@@ -0,0 +1,71 @@
+package nomad
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedRateLimiter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// A zero rate disables the limiter
+	require.Nil(newKeyedRateLimiter(0, 10))
+	var disabled *keyedRateLimiter
+	_, ok := disabled.reserve("foo", time.Now(), 100)
+	require.True(ok)
+
+	// The burst defaults to the rate rounded up
+	k := newKeyedRateLimiter(1.5, 0)
+	require.Equal(2, k.burst)
+
+	now := time.Now()
+	_, ok = k.reserve("foo", now, 2)
+	require.True(ok)
+	_, ok = k.reserve("foo", now, 1)
+	require.False(ok)
+
+	// Keys are limited independently
+	_, ok = k.reserve("bar", now, 1)
+	require.True(ok)
+
+	// More than the burst is never allowed
+	_, ok = k.reserve("baz", now, 3)
+	require.False(ok)
+
+	// Events are allowed again once the limiter refills
+	_, ok = k.reserve("foo", now.Add(time.Second), 1)
+	require.True(ok)
+
+	// Idle limiters are collected
+	k.reserve("bar", now.Add(2*jobRateLimitIdleTTL), 1)
+	require.Len(k.limiters, 1)
+	require.Contains(k.limiters, "bar")
+}
+
+func TestJobRateLimiter_Allow(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	j := newJobRateLimiter(&Config{
+		JobNamespaceRateLimit: 0.001,
+		JobNamespaceRateBurst: 3,
+		JobTokenRateLimit:     0.001,
+		JobTokenRateBurst:     1,
+	})
+
+	require.NoError(j.allow("register", "default", "a", 1))
+
+	// Exceeding the token limit doesn't consume from the namespace
+	err := j.allow("register", "default", "a", 1)
+	require.Equal(structs.ErrJobRateLimited, err)
+	require.NoError(j.allow("register", "default", "b", 1))
+	require.NoError(j.allow("register", "default", "c", 1))
+
+	// The namespace limit is exceeded
+	require.Equal(structs.ErrJobRateLimited, j.allow("register", "default", "d", 1))
+	require.NoError(j.allow("register", "other", "d", 1))
+}
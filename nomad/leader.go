@@ -44,6 +44,10 @@ var minAutopilotVersion = version.Must(version.NewVersion("0.8.0"))
 
 var minSchedulerConfigVersion = version.Must(version.NewVersion("0.9.0"))
 
+// minPlanBatchVersion is the Nomad version all servers must be on before
+// plan results are committed in batches.
+var minPlanBatchVersion = version.Must(version.NewVersion("0.9.0"))
+
 // Default configuration for scheduler with preemption enabled for system jobs
 var defaultSchedulerConfig = &structs.SchedulerConfiguration{
 	PreemptionConfig: structs.PreemptionConfig{
@@ -100,6 +104,19 @@ func (s *Server) leaderLoop(stopCh chan struct{}) {
 	var reconcileCh chan serf.Member
 	establishedLeader := false
 
+	// leaderStopCh is passed to the leadership actions instead of stopCh so
+	// that they can be restarted when leadership is reasserted.
+	var leaderStopCh chan struct{}
+	cancelLeader := func() {}
+	defer func() {
+		cancelLeader()
+		if establishedLeader {
+			if err := s.revokeLeadership(); err != nil {
+				s.logger.Error("failed to revoke leadership", "error", err)
+			}
+		}
+	}()
+
 RECONCILE:
 	// Setup a reconciliation timer
 	reconcileCh = nil
@@ -116,11 +133,13 @@ RECONCILE:
 
 	// Check if we need to handle initial leadership actions
 	if !establishedLeader {
-		if err := s.establishLeadership(stopCh); err != nil {
+		leaderStopCh, cancelLeader = newLeaderStopCh(stopCh)
+		if err := s.establishLeadership(leaderStopCh); err != nil {
 			s.logger.Error("failed to establish leadership", "error", err)
 
 			// Immediately revoke leadership since we didn't successfully
 			// establish leadership.
+			cancelLeader()
 			if err := s.revokeLeadership(); err != nil {
 				s.logger.Error("failed to revoke leadership", "error", err)
 			}
@@ -129,11 +148,6 @@ RECONCILE:
 		}
 
 		establishedLeader = true
-		defer func() {
-			if err := s.revokeLeadership(); err != nil {
-				s.logger.Error("failed to revoke leadership", "error", err)
-			}
-		}()
 	}
 
 	// Reconcile any missing data
@@ -167,7 +181,54 @@ WAIT:
 			goto RECONCILE
 		case member := <-reconcileCh:
 			s.reconcileMember(member)
+		case errCh := <-s.reassertLeaderCh:
+			// Leadership can't be reasserted if it was never established,
+			// in which case it is retried on the next reconcile.
+			if !establishedLeader {
+				errCh <- fmt.Errorf("leadership has not been established")
+				continue
+			}
+
+			// Restart the leadership actions so that they use the current
+			// contents of the state store, which may have been replaced by
+			// restoring a snapshot.
+			cancelLeader()
+			establishedLeader = false
+			if err := s.revokeLeadership(); err != nil {
+				errCh <- err
+				continue
+			}
+			leaderStopCh, cancelLeader = newLeaderStopCh(stopCh)
+			if err := s.establishLeadership(leaderStopCh); err != nil {
+				cancelLeader()
+				if rerr := s.revokeLeadership(); rerr != nil {
+					s.logger.Error("failed to revoke leadership", "error", rerr)
+				}
+				errCh <- err
+				continue
+			}
+			establishedLeader = true
+			errCh <- nil
+		}
+	}
+}
+
+// newLeaderStopCh returns a channel that is closed when either stopCh is
+// closed or the returned cancel function is called.
+func newLeaderStopCh(stopCh chan struct{}) (chan struct{}, func()) {
+	leaderStopCh := make(chan struct{})
+	cancelCh := make(chan struct{})
+	go func() {
+		select {
+		case <-stopCh:
+		case <-cancelCh:
 		}
+		close(leaderStopCh)
+	}()
+
+	var once sync.Once
+	return leaderStopCh, func() {
+		once.Do(func() { close(cancelCh) })
 	}
 }
 
@@ -224,7 +285,9 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 	}
 
 	// Activate the vault client
-	s.vault.SetActive(true)
+	for _, v := range s.vaultClients() {
+		v.SetActive(true)
+	}
 	if err := s.restoreRevokingAccessors(); err != nil {
 		return err
 	}
@@ -252,6 +315,9 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 	// Periodically publish job summary metrics
 	go s.publishJobSummaryMetrics(stopCh)
 
+	// Periodically publish the placement failures of blocked evaluations
+	go s.publishPlacementFailureMetrics(stopCh)
+
 	// Setup the heartbeat timers. This is done both when starting up or when
 	// a leader fail over happens. Since the timers are maintained by the leader
 	// node, effectively this means all the timers are renewed at the time of failover.
@@ -279,6 +345,9 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 	// and we are not the authoritative region.
 	if s.config.ACLEnabled && s.config.Region != s.config.AuthoritativeRegion {
 		go s.replicateACLPolicies(stopCh)
+		go s.replicateACLRoles(stopCh)
+		go s.replicateACLAuthMethods(stopCh)
+		go s.replicateACLBindingRules(stopCh)
 		go s.replicateACLTokens(stopCh)
 	}
 
@@ -362,7 +431,7 @@ func (s *Server) restoreRevokingAccessors() error {
 	}
 
 	if len(revoke) != 0 {
-		if err := s.vault.RevokeTokens(context.Background(), revoke, true); err != nil {
+		if err := s.revokeVaultTokens(context.Background(), revoke, true); err != nil {
 			return fmt.Errorf("failed to revoke tokens: %v", err)
 		}
 	}
@@ -449,6 +518,10 @@ func (s *Server) schedulePeriodic(stopCh chan struct{}) {
 	defer jobGC.Stop()
 	deploymentGC := time.NewTicker(s.config.DeploymentGCInterval)
 	defer deploymentGC.Stop()
+	aclTokenGC := time.NewTicker(s.config.ACLTokenExpirationGCInterval)
+	defer aclTokenGC.Stop()
+	csiVolumeClaimGC := time.NewTicker(s.config.CSIVolumeClaimGCInterval)
+	defer csiVolumeClaimGC.Stop()
 
 	// getLatest grabs the latest index from the state store. It returns true if
 	// the index was retrieved successfully.
@@ -481,6 +554,17 @@ func (s *Server) schedulePeriodic(stopCh chan struct{}) {
 			if index, ok := getLatest(); ok {
 				s.evalBroker.Enqueue(s.coreJobEval(structs.CoreJobDeploymentGC, index))
 			}
+		case <-aclTokenGC.C:
+			if !s.config.ACLEnabled {
+				continue
+			}
+			if index, ok := getLatest(); ok {
+				s.evalBroker.Enqueue(s.coreJobEval(structs.CoreJobExpiredACLTokenGC, index))
+			}
+		case <-csiVolumeClaimGC.C:
+			if index, ok := getLatest(); ok {
+				s.evalBroker.Enqueue(s.coreJobEval(structs.CoreJobCSIVolumeClaimGC, index))
+			}
 		case <-stopCh:
 			return
 		}
@@ -726,7 +810,9 @@ func (s *Server) revokeLeadership() error {
 	s.periodicDispatcher.SetEnabled(false)
 
 	// Disable the Vault client as it is only useful as a leader.
-	s.vault.SetActive(false)
+	for _, v := range s.vaultClients() {
+		v.SetActive(false)
+	}
 
 	// Disable the deployment watcher as it is only useful as a leader.
 	s.deploymentWatcher.SetEnabled(false, nil)
@@ -791,17 +877,26 @@ func (s *Server) reconcileMember(member serf.Member) error {
 	return nil
 }
 
-// reconcileJobSummaries reconciles the summaries of all the jobs registered in
-// the system
+// reconcileJobSummaries reconciles the summaries of the jobs registered in the
+// system. Job summaries are updated incrementally as allocations change, so
+// only the summaries that have drifted from their allocations are found
+// outside of Raft and reconciled.
 // COMPAT 0.4 -> 0.4.1
 func (s *Server) reconcileJobSummaries() error {
-	index, err := s.fsm.state.LatestIndex()
+	snap, err := s.fsm.State().Snapshot()
+	if err != nil {
+		return fmt.Errorf("unable to snapshot state: %v", err)
+	}
+	stale, err := snap.StaleJobSummaries()
 	if err != nil {
-		return fmt.Errorf("unable to read latest index: %v", err)
+		return fmt.Errorf("unable to find stale job summaries: %v", err)
+	}
+	if len(stale) == 0 {
+		return nil
 	}
-	s.logger.Debug("leader reconciling job summaries", "index", index)
+	s.logger.Debug("leader reconciling job summaries", "num_jobs", len(stale))
 
-	args := &structs.GenericResponse{}
+	args := &structs.JobSummaryReconcileRequest{Jobs: stale}
 	msg := structs.ReconcileJobSummariesRequestType | structs.IgnoreUnknownTypeFlag
 	if _, _, err = s.raftApply(msg, args); err != nil {
 		return fmt.Errorf("reconciliation of job summaries failed: %v", err)
@@ -1088,6 +1183,429 @@ func diffACLPolicies(state *state.StateStore, minIndex uint64, remoteList []*str
 	return
 }
 
+// replicateACLRoles is used to replicate ACL roles from
+// the authoritative region to this region.
+func (s *Server) replicateACLRoles(stopCh chan struct{}) {
+	req := structs.ACLRoleListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:     s.config.AuthoritativeRegion,
+			AllowStale: true,
+		},
+	}
+	limiter := rate.NewLimiter(replicationRateLimit, int(replicationRateLimit))
+	s.logger.Debug("starting ACL role replication from authoritative region", "authoritative_region", req.Region)
+
+START:
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+			// Rate limit how often we attempt replication
+			limiter.Wait(context.Background())
+
+			// Fetch the list of roles
+			var resp structs.ACLRoleListResponse
+			req.AuthToken = s.ReplicationToken()
+			err := s.forwardRegion(s.config.AuthoritativeRegion,
+				"ACL.ListRoles", &req, &resp)
+			if err != nil {
+				s.logger.Error("failed to fetch roles from authoritative region", "error", err)
+				goto ERR_WAIT
+			}
+
+			// Perform a two-way diff
+			delete, update := diffACLRoles(s.State(), req.MinQueryIndex, resp.Roles)
+
+			// Delete roles that should not exist
+			if len(delete) > 0 {
+				args := &structs.ACLRoleDeleteRequest{
+					Names: delete,
+				}
+				_, _, err := s.raftApply(structs.ACLRoleDeleteRequestType, args)
+				if err != nil {
+					s.logger.Error("failed to delete roles", "error", err)
+					goto ERR_WAIT
+				}
+			}
+
+			// Fetch any outdated roles
+			var fetched []*structs.ACLRole
+			if len(update) > 0 {
+				req := structs.ACLRoleSetRequest{
+					Names: update,
+					QueryOptions: structs.QueryOptions{
+						Region:        s.config.AuthoritativeRegion,
+						AuthToken:     s.ReplicationToken(),
+						AllowStale:    true,
+						MinQueryIndex: resp.Index - 1,
+					},
+				}
+				var reply structs.ACLRoleSetResponse
+				if err := s.forwardRegion(s.config.AuthoritativeRegion,
+					"ACL.GetRoles", &req, &reply); err != nil {
+					s.logger.Error("failed to fetch roles from authoritative region", "error", err)
+					goto ERR_WAIT
+				}
+				for _, role := range reply.Roles {
+					fetched = append(fetched, role)
+				}
+			}
+
+			// Update local roles
+			if len(fetched) > 0 {
+				args := &structs.ACLRoleUpsertRequest{
+					Roles: fetched,
+				}
+				_, _, err := s.raftApply(structs.ACLRoleUpsertRequestType, args)
+				if err != nil {
+					s.logger.Error("failed to update roles", "error", err)
+					goto ERR_WAIT
+				}
+			}
+
+			// Update the minimum query index, blocks until there
+			// is a change.
+			req.MinQueryIndex = resp.Index
+		}
+	}
+
+ERR_WAIT:
+	select {
+	case <-time.After(s.config.ReplicationBackoff):
+		goto START
+	case <-stopCh:
+		return
+	}
+}
+
+// diffACLRoles is used to perform a two-way diff between the local
+// roles and the remote roles to determine which roles need to
+// be deleted or updated.
+func diffACLRoles(state *state.StateStore, minIndex uint64, remoteList []*structs.ACLRoleListStub) (delete []string, update []string) {
+	// Construct a set of the local and remote roles
+	local := make(map[string][]byte)
+	remote := make(map[string]struct{})
+
+	// Add all the local roles
+	iter, err := state.ACLRoles(nil)
+	if err != nil {
+		panic("failed to iterate local roles")
+	}
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		role := raw.(*structs.ACLRole)
+		local[role.Name] = role.Hash
+	}
+
+	// Iterate over the remote roles
+	for _, rr := range remoteList {
+		remote[rr.Name] = struct{}{}
+
+		// Check if the role is missing locally
+		if localHash, ok := local[rr.Name]; !ok {
+			update = append(update, rr.Name)
+
+			// Check if role is newer remotely and there is a hash mis-match.
+		} else if rr.ModifyIndex > minIndex && !bytes.Equal(localHash, rr.Hash) {
+			update = append(update, rr.Name)
+		}
+	}
+
+	// Check if role should be deleted
+	for lr := range local {
+		if _, ok := remote[lr]; !ok {
+			delete = append(delete, lr)
+		}
+	}
+	return
+}
+
+// replicateACLAuthMethods is used to replicate ACL auth methods from
+// the authoritative region to this region.
+func (s *Server) replicateACLAuthMethods(stopCh chan struct{}) {
+	req := structs.ACLAuthMethodListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:     s.config.AuthoritativeRegion,
+			AllowStale: true,
+		},
+	}
+	limiter := rate.NewLimiter(replicationRateLimit, int(replicationRateLimit))
+	s.logger.Debug("starting ACL auth method replication from authoritative region", "authoritative_region", req.Region)
+
+START:
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+			// Rate limit how often we attempt replication
+			limiter.Wait(context.Background())
+
+			// Fetch the list of auth methods
+			var resp structs.ACLAuthMethodListResponse
+			req.AuthToken = s.ReplicationToken()
+			err := s.forwardRegion(s.config.AuthoritativeRegion,
+				"ACL.ListAuthMethods", &req, &resp)
+			if err != nil {
+				s.logger.Error("failed to fetch auth methods from authoritative region", "error", err)
+				goto ERR_WAIT
+			}
+
+			// Perform a two-way diff
+			delete, update := diffACLAuthMethods(s.State(), req.MinQueryIndex, resp.AuthMethods)
+
+			// Delete auth methods that should not exist
+			if len(delete) > 0 {
+				args := &structs.ACLAuthMethodDeleteRequest{
+					Names: delete,
+				}
+				_, _, err := s.raftApply(structs.ACLAuthMethodDeleteRequestType, args)
+				if err != nil {
+					s.logger.Error("failed to delete auth methods", "error", err)
+					goto ERR_WAIT
+				}
+			}
+
+			// Fetch any outdated auth methods
+			var fetched []*structs.ACLAuthMethod
+			if len(update) > 0 {
+				req := structs.ACLAuthMethodSetRequest{
+					Names: update,
+					QueryOptions: structs.QueryOptions{
+						Region:        s.config.AuthoritativeRegion,
+						AuthToken:     s.ReplicationToken(),
+						AllowStale:    true,
+						MinQueryIndex: resp.Index - 1,
+					},
+				}
+				var reply structs.ACLAuthMethodSetResponse
+				if err := s.forwardRegion(s.config.AuthoritativeRegion,
+					"ACL.GetAuthMethods", &req, &reply); err != nil {
+					s.logger.Error("failed to fetch auth methods from authoritative region", "error", err)
+					goto ERR_WAIT
+				}
+				for _, method := range reply.AuthMethods {
+					fetched = append(fetched, method)
+				}
+			}
+
+			// Update local auth methods
+			if len(fetched) > 0 {
+				args := &structs.ACLAuthMethodUpsertRequest{
+					AuthMethods: fetched,
+				}
+				_, _, err := s.raftApply(structs.ACLAuthMethodUpsertRequestType, args)
+				if err != nil {
+					s.logger.Error("failed to update auth methods", "error", err)
+					goto ERR_WAIT
+				}
+			}
+
+			// Update the minimum query index, blocks until there
+			// is a change.
+			req.MinQueryIndex = resp.Index
+		}
+	}
+
+ERR_WAIT:
+	select {
+	case <-time.After(s.config.ReplicationBackoff):
+		goto START
+	case <-stopCh:
+		return
+	}
+}
+
+// diffACLAuthMethods is used to perform a two-way diff between the local
+// auth methods and the remote auth methods to determine which auth
+// methods need to be deleted or updated.
+func diffACLAuthMethods(state *state.StateStore, minIndex uint64, remoteList []*structs.ACLAuthMethodListStub) (delete []string, update []string) {
+	// Construct a set of the local and remote auth methods
+	local := make(map[string][]byte)
+	remote := make(map[string]struct{})
+
+	// Add all the local auth methods
+	iter, err := state.ACLAuthMethods(nil)
+	if err != nil {
+		panic("failed to iterate local auth methods")
+	}
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		method := raw.(*structs.ACLAuthMethod)
+		local[method.Name] = method.Hash
+	}
+
+	// Iterate over the remote auth methods
+	for _, rr := range remoteList {
+		remote[rr.Name] = struct{}{}
+
+		// Check if the auth method is missing locally
+		if localHash, ok := local[rr.Name]; !ok {
+			update = append(update, rr.Name)
+
+			// Check if auth method is newer remotely and there is a hash mis-match.
+		} else if rr.ModifyIndex > minIndex && !bytes.Equal(localHash, rr.Hash) {
+			update = append(update, rr.Name)
+		}
+	}
+
+	// Check if auth method should be deleted
+	for lr := range local {
+		if _, ok := remote[lr]; !ok {
+			delete = append(delete, lr)
+		}
+	}
+	return
+}
+
+// replicateACLBindingRules is used to replicate ACL binding rules from
+// the authoritative region to this region.
+func (s *Server) replicateACLBindingRules(stopCh chan struct{}) {
+	req := structs.ACLBindingRuleListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:     s.config.AuthoritativeRegion,
+			AllowStale: true,
+		},
+	}
+	limiter := rate.NewLimiter(replicationRateLimit, int(replicationRateLimit))
+	s.logger.Debug("starting ACL binding rule replication from authoritative region", "authoritative_region", req.Region)
+
+START:
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+			// Rate limit how often we attempt replication
+			limiter.Wait(context.Background())
+
+			// Fetch the list of binding rules
+			var resp structs.ACLBindingRuleListResponse
+			req.AuthToken = s.ReplicationToken()
+			err := s.forwardRegion(s.config.AuthoritativeRegion,
+				"ACL.ListBindingRules", &req, &resp)
+			if err != nil {
+				s.logger.Error("failed to fetch binding rules from authoritative region", "error", err)
+				goto ERR_WAIT
+			}
+
+			// Perform a two-way diff
+			delete, update := diffACLBindingRules(s.State(), req.MinQueryIndex, resp.Rules)
+
+			// Delete binding rules that should not exist
+			if len(delete) > 0 {
+				args := &structs.ACLBindingRuleDeleteRequest{
+					RuleIDs: delete,
+				}
+				_, _, err := s.raftApply(structs.ACLBindingRuleDeleteRequestType, args)
+				if err != nil {
+					s.logger.Error("failed to delete binding rules", "error", err)
+					goto ERR_WAIT
+				}
+			}
+
+			// Fetch any outdated binding rules
+			var fetched []*structs.ACLBindingRule
+			if len(update) > 0 {
+				req := structs.ACLBindingRuleSetRequest{
+					RuleIDs: update,
+					QueryOptions: structs.QueryOptions{
+						Region:        s.config.AuthoritativeRegion,
+						AuthToken:     s.ReplicationToken(),
+						AllowStale:    true,
+						MinQueryIndex: resp.Index - 1,
+					},
+				}
+				var reply structs.ACLBindingRuleSetResponse
+				if err := s.forwardRegion(s.config.AuthoritativeRegion,
+					"ACL.GetBindingRules", &req, &reply); err != nil {
+					s.logger.Error("failed to fetch binding rules from authoritative region", "error", err)
+					goto ERR_WAIT
+				}
+				for _, rule := range reply.Rules {
+					fetched = append(fetched, rule)
+				}
+			}
+
+			// Update local binding rules
+			if len(fetched) > 0 {
+				args := &structs.ACLBindingRuleUpsertRequest{
+					Rules: fetched,
+				}
+				_, _, err := s.raftApply(structs.ACLBindingRuleUpsertRequestType, args)
+				if err != nil {
+					s.logger.Error("failed to update binding rules", "error", err)
+					goto ERR_WAIT
+				}
+			}
+
+			// Update the minimum query index, blocks until there
+			// is a change.
+			req.MinQueryIndex = resp.Index
+		}
+	}
+
+ERR_WAIT:
+	select {
+	case <-time.After(s.config.ReplicationBackoff):
+		goto START
+	case <-stopCh:
+		return
+	}
+}
+
+// diffACLBindingRules is used to perform a two-way diff between the local
+// binding rules and the remote binding rules to determine which binding
+// rules need to be deleted or updated.
+func diffACLBindingRules(state *state.StateStore, minIndex uint64, remoteList []*structs.ACLBindingRuleListStub) (delete []string, update []string) {
+	// Construct a set of the local and remote binding rules
+	local := make(map[string][]byte)
+	remote := make(map[string]struct{})
+
+	// Add all the local binding rules
+	iter, err := state.ACLBindingRules(nil)
+	if err != nil {
+		panic("failed to iterate local binding rules")
+	}
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		rule := raw.(*structs.ACLBindingRule)
+		local[rule.ID] = rule.Hash
+	}
+
+	// Iterate over the remote binding rules
+	for _, rr := range remoteList {
+		remote[rr.ID] = struct{}{}
+
+		// Check if the binding rule is missing locally
+		if localHash, ok := local[rr.ID]; !ok {
+			update = append(update, rr.ID)
+
+			// Check if binding rule is newer remotely and there is a hash mis-match.
+		} else if rr.ModifyIndex > minIndex && !bytes.Equal(localHash, rr.Hash) {
+			update = append(update, rr.ID)
+		}
+	}
+
+	// Check if binding rule should be deleted
+	for lr := range local {
+		if _, ok := remote[lr]; !ok {
+			delete = append(delete, lr)
+		}
+	}
+	return
+}
+
 // replicateACLTokens is used to replicate global ACL tokens from
 // the authoritative region to this region.
 func (s *Server) replicateACLTokens(stopCh chan struct{}) {
@@ -746,6 +746,102 @@ func TestLeader_DiffACLPolicies(t *testing.T) {
 	assert.Equal(t, []string{p3.Name, p4.Name}, update)
 }
 
+func TestLeader_DiffACLRoles(t *testing.T) {
+	t.Parallel()
+
+	state := state.TestStateStore(t)
+
+	// Populate the local state
+	r1 := mock.ACLRole()
+	r2 := mock.ACLRole()
+	r3 := mock.ACLRole()
+	assert.Nil(t, state.UpsertACLRoles(100, []*structs.ACLRole{r1, r2, r3}))
+
+	// Simulate a remote list
+	r2Stub := r2.Stub()
+	r2Stub.ModifyIndex = 50 // Ignored, same index
+	r3Stub := r3.Stub()
+	r3Stub.ModifyIndex = 100 // Updated, higher index
+	r3Stub.Hash = []byte{0, 1, 2, 3}
+	r4 := mock.ACLRole()
+	remoteList := []*structs.ACLRoleListStub{
+		r2Stub,
+		r3Stub,
+		r4.Stub(),
+	}
+	delete, update := diffACLRoles(state, 50, remoteList)
+
+	// R1 does not exist on the remote side, should delete
+	assert.Equal(t, []string{r1.Name}, delete)
+
+	// R2 is un-modified - ignore. R3 modified, R4 new.
+	assert.Equal(t, []string{r3.Name, r4.Name}, update)
+}
+
+func TestLeader_DiffACLAuthMethods(t *testing.T) {
+	t.Parallel()
+
+	state := state.TestStateStore(t)
+
+	// Populate the local state
+	m1 := mock.ACLAuthMethod()
+	m2 := mock.ACLAuthMethod()
+	m3 := mock.ACLAuthMethod()
+	assert.Nil(t, state.UpsertACLAuthMethods(100, []*structs.ACLAuthMethod{m1, m2, m3}))
+
+	// Simulate a remote list
+	m2Stub := m2.Stub()
+	m2Stub.ModifyIndex = 50 // Ignored, same index
+	m3Stub := m3.Stub()
+	m3Stub.ModifyIndex = 100 // Updated, higher index
+	m3Stub.Hash = []byte{0, 1, 2, 3}
+	m4 := mock.ACLAuthMethod()
+	remoteList := []*structs.ACLAuthMethodListStub{
+		m2Stub,
+		m3Stub,
+		m4.Stub(),
+	}
+	delete, update := diffACLAuthMethods(state, 50, remoteList)
+
+	// M1 does not exist on the remote side, should delete
+	assert.Equal(t, []string{m1.Name}, delete)
+
+	// M2 is un-modified - ignore. M3 modified, M4 new.
+	assert.Equal(t, []string{m3.Name, m4.Name}, update)
+}
+
+func TestLeader_DiffACLBindingRules(t *testing.T) {
+	t.Parallel()
+
+	state := state.TestStateStore(t)
+
+	// Populate the local state
+	r1 := mock.ACLBindingRule()
+	r2 := mock.ACLBindingRule()
+	r3 := mock.ACLBindingRule()
+	assert.Nil(t, state.UpsertACLBindingRules(100, []*structs.ACLBindingRule{r1, r2, r3}))
+
+	// Simulate a remote list
+	r2Stub := r2.Stub()
+	r2Stub.ModifyIndex = 50 // Ignored, same index
+	r3Stub := r3.Stub()
+	r3Stub.ModifyIndex = 100 // Updated, higher index
+	r3Stub.Hash = []byte{0, 1, 2, 3}
+	r4 := mock.ACLBindingRule()
+	remoteList := []*structs.ACLBindingRuleListStub{
+		r2Stub,
+		r3Stub,
+		r4.Stub(),
+	}
+	delete, update := diffACLBindingRules(state, 50, remoteList)
+
+	// R1 does not exist on the remote side, should delete
+	assert.Equal(t, []string{r1.ID}, delete)
+
+	// R2 is un-modified - ignore. R3 modified, R4 new.
+	assert.Equal(t, []string{r3.ID, r4.ID}, update)
+}
+
 func TestLeader_ReplicateACLTokens(t *testing.T) {
 	t.Parallel()
 	s1, root := TestACLServer(t, func(c *Config) {
@@ -910,6 +1006,42 @@ func TestLeader_UpgradeRaftVersion(t *testing.T) {
 	}
 }
 
+func TestLeader_ReconcileJobSummaries(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create a job with an alloc
+	state := s1.fsm.State()
+	alloc := mock.Alloc()
+	require.NoError(state.UpsertJob(1000, alloc.Job))
+	require.NoError(state.UpsertAllocs(1001, []*structs.Allocation{alloc}))
+
+	// Nothing is applied if the summaries are correct
+	index, err := state.Index("job_summary")
+	require.NoError(err)
+	require.NoError(s1.reconcileJobSummaries())
+	out, err := state.Index("job_summary")
+	require.NoError(err)
+	require.Equal(index, out)
+
+	// Make the summary incorrect
+	summary, err := state.JobSummaryByID(nil, alloc.Namespace, alloc.JobID)
+	require.NoError(err)
+	summary = summary.Copy()
+	summary.Summary["web"] = structs.TaskGroupSummary{Failed: 4}
+	require.NoError(state.UpsertJobSummary(1002, summary))
+
+	// The stale summary is reconciled
+	require.NoError(s1.reconcileJobSummaries())
+	summary, err = state.JobSummaryByID(nil, alloc.Namespace, alloc.JobID)
+	require.NoError(err)
+	require.Zero(summary.Summary["web"].Failed)
+	require.Equal(1, summary.Summary["web"].Starting)
+}
+
 func TestLeader_Reelection(t *testing.T) {
 	raftProtocols := []int{1, 2, 3}
 	for _, p := range raftProtocols {
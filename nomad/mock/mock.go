@@ -659,6 +659,66 @@ func VaultAccessor() *structs.VaultAccessor {
 	}
 }
 
+// ServiceRegistration returns a registration of the web service for the
+// allocation.
+func ServiceRegistration(alloc *structs.Allocation) *structs.ServiceRegistration {
+	return &structs.ServiceRegistration{
+		ID:          "_nomad-task-" + uuid.Generate(),
+		ServiceName: "web",
+		Namespace:   alloc.Namespace,
+		NodeID:      alloc.NodeID,
+		Datacenter:  "dc1",
+		JobID:       alloc.JobID,
+		AllocID:     alloc.ID,
+		Task:        "web",
+		Tags:        []string{"http"},
+		Address:     "192.168.0.100",
+		Port:        5000,
+	}
+}
+
+// CSIVolume returns an unclaimed volume provided by the plugin
+func CSIVolume(pluginID string) *structs.CSIVolume {
+	return &structs.CSIVolume{
+		ID:             uuid.Generate(),
+		Name:           "data",
+		Namespace:      structs.DefaultNamespace,
+		ExternalID:     "vol-" + uuid.Generate()[:8],
+		PluginID:       pluginID,
+		AccessMode:     structs.CSIVolumeAccessModeSingleNodeWriter,
+		AttachmentMode: structs.CSIVolumeAttachmentModeFilesystem,
+	}
+}
+
+// CSINode returns a node running a healthy node plugin for the plugin
+func CSINode(pluginID string) *structs.Node {
+	node := Node()
+	node.CSINodePlugins = map[string]*structs.CSIInfo{
+		pluginID: {
+			PluginID:        pluginID,
+			Healthy:         true,
+			Provider:        "com.hashicorp:mock",
+			ProviderVersion: "0.1.0",
+			NodeInfo: &structs.CSINodeInfo{
+				ID: "i-" + node.ID[:8],
+			},
+		},
+	}
+	return node
+}
+
+func VariableEncrypted() *structs.VariableEncrypted {
+	return &structs.VariableEncrypted{
+		VariableMetadata: structs.VariableMetadata{
+			Namespace:  structs.DefaultNamespace,
+			Path:       "nomad/jobs/" + uuid.Generate(),
+			ModifyTime: time.Now().UnixNano(),
+		},
+		KeyID: "0123456789abcdef",
+		Data:  []byte(uuid.Generate()),
+	}
+}
+
 func Deployment() *structs.Deployment {
 	return &structs.Deployment{
 		ID:             uuid.Generate(),
@@ -711,6 +771,51 @@ func ACLPolicy() *structs.ACLPolicy {
 	return ap
 }
 
+func ACLRole() *structs.ACLRole {
+	r := &structs.ACLRole{
+		Name:        fmt.Sprintf("role-%s", uuid.Generate()),
+		Description: "Super cool role!",
+		Policies:    []string{"foo", "bar"},
+		CreateIndex: 10,
+		ModifyIndex: 20,
+	}
+	r.SetHash()
+	return r
+}
+
+func ACLAuthMethod() *structs.ACLAuthMethod {
+	m := &structs.ACLAuthMethod{
+		Name:          fmt.Sprintf("method-%s", uuid.Generate()[:8]),
+		Type:          structs.ACLAuthMethodTypeJWT,
+		TokenLocality: structs.ACLAuthMethodTokenLocalityLocal,
+		MaxTokenTTL:   time.Hour,
+		Config: &structs.ACLAuthMethodConfig{
+			JWKSURL:        "https://example.com/.well-known/jwks.json",
+			BoundAudiences: []string{"nomad"},
+			ClaimMappings:  map[string]string{"project": "project"},
+		},
+		CreateIndex: 10,
+		ModifyIndex: 20,
+	}
+	m.SetHash()
+	return m
+}
+
+func ACLBindingRule() *structs.ACLBindingRule {
+	r := &structs.ACLBindingRule{
+		ID:          uuid.Generate(),
+		Description: "Super cool binding rule!",
+		AuthMethod:  "method",
+		Selector:    `value.project == "web"`,
+		BindType:    structs.ACLBindingRuleBindTypePolicy,
+		BindName:    "web-${value.project}",
+		CreateIndex: 10,
+		ModifyIndex: 20,
+	}
+	r.SetHash()
+	return r
+}
+
 func ACLToken() *structs.ACLToken {
 	tk := &structs.ACLToken{
 		AccessorID:  uuid.Generate(),
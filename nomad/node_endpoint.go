@@ -3,6 +3,7 @@ package nomad
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +17,7 @@ import (
 	vapi "github.com/hashicorp/vault/api"
 
 	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -43,9 +45,20 @@ const (
 	// ineligible
 	NodeEligibilityEventIneligible = "Node marked as ineligible for scheduling"
 
+	// NodeHostVolumeEvents are the messages used when host volumes are
+	// registered and deregistered
+	NodeHostVolumeEventRegistered   = "Host volume %q registered"
+	NodeHostVolumeEventUpdated      = "Host volume %q updated"
+	NodeHostVolumeEventDeregistered = "Host volume %q deregistered"
+
 	// NodeHeartbeatEventReregistered is the message used when the node becomes
 	// reregistered by the heartbeat.
 	NodeHeartbeatEventReregistered = "Node reregistered by heartbeat"
+
+	// NodeScaleInEvents are the messages used when the scale-in of a node is
+	// started and cancelled
+	NodeScaleInEventStarted   = "Node scale-in started"
+	NodeScaleInEventCancelled = "Node scale-in cancelled"
 )
 
 // Node endpoint is used for client interactions
@@ -216,13 +229,19 @@ func (n *Node) constructNodeServerInfoResponse(snap *state.StateSnapshot, reply
 	// Reply with config information required for future RPC requests
 	reply.Servers = make([]*structs.NodeServerInfo, 0, len(n.srv.localPeers))
 	for _, v := range n.srv.localPeers {
-		reply.Servers = append(reply.Servers,
-			&structs.NodeServerInfo{
-				RPCAdvertiseAddr: v.RPCAddr.String(),
-				RPCMajorVersion:  int32(v.MajorVersion),
-				RPCMinorVersion:  int32(v.MinorVersion),
-				Datacenter:       v.Datacenter,
-			})
+		info := &structs.NodeServerInfo{
+			RPCAdvertiseAddr: v.RPCAddr.String(),
+			RPCMajorVersion:  int32(v.MajorVersion),
+			RPCMinorVersion:  int32(v.MinorVersion),
+			Datacenter:       v.Datacenter,
+		}
+		if len(v.RPCSegmentAddrs) > 0 {
+			info.RPCSegmentAddrs = make(map[string]string, len(v.RPCSegmentAddrs))
+			for segment, addr := range v.RPCSegmentAddrs {
+				info.RPCSegmentAddrs[segment] = addr.String()
+			}
+		}
+		reply.Servers = append(reply.Servers, info)
 	}
 
 	// TODO(sean@): Use an indexed node count instead
@@ -264,6 +283,12 @@ func (n *Node) Deregister(args *structs.NodeDeregisterRequest, reply *structs.No
 	if args.NodeID == "" {
 		return fmt.Errorf("missing node ID for client deregistration")
 	}
+	return n.deregister(args, reply)
+}
+
+// deregister removes a client from the cluster, creates the evaluations of
+// its allocations and revokes the Vault tokens of its tasks.
+func (n *Node) deregister(args *structs.NodeDeregisterRequest, reply *structs.NodeUpdateResponse) error {
 	// Look for the node
 	snap, err := n.srv.fsm.State().Snapshot()
 	if err != nil {
@@ -305,7 +330,7 @@ func (n *Node) Deregister(args *structs.NodeDeregisterRequest, reply *structs.No
 
 	if l := len(accessors); l != 0 {
 		n.logger.Debug("revoking accessors on node due to deregister", "num_accessors", l, "node_id", args.NodeID)
-		if err := n.srv.vault.RevokeTokens(context.Background(), accessors, true); err != nil {
+		if err := n.srv.revokeVaultTokens(context.Background(), accessors, true); err != nil {
 			n.logger.Error("revoking accessors for node failed", "node_id", args.NodeID, "error", err)
 			return err
 		}
@@ -414,7 +439,7 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 
 		if l := len(accessors); l != 0 {
 			n.logger.Debug("revoking accessors on node due to down state", "num_accessors", l, "node_id", args.NodeID)
-			if err := n.srv.vault.RevokeTokens(context.Background(), accessors, true); err != nil {
+			if err := n.srv.revokeVaultTokens(context.Background(), accessors, true); err != nil {
 				n.logger.Error("revoking accessors for node failed", "node_id", args.NodeID, "error", err)
 				return err
 			}
@@ -458,7 +483,31 @@ func (n *Node) UpdateDrain(args *structs.NodeUpdateDrainRequest,
 
 	// Check node write permissions
 	if aclObj, err := n.srv.ResolveToken(args.AuthToken); err != nil {
-		return err
+		// If ResolveToken had an unexpected error return that
+		if err != structs.ErrTokenNotFound {
+			return err
+		}
+
+		// Attempt to lookup AuthToken as a Node.SecretID since nodes
+		// drain themselves when shutting down and don't have an ACL
+		// token.
+		node, stateErr := n.srv.fsm.State().NodeBySecretID(nil, args.AuthToken)
+		if stateErr != nil {
+			// Return the original ResolveToken error with this err
+			var merr multierror.Error
+			merr.Errors = append(merr.Errors, err, stateErr)
+			return merr.ErrorOrNil()
+		}
+
+		// Not a node or a valid ACL token
+		if node == nil {
+			return structs.ErrTokenNotFound
+		}
+
+		// Nodes may only drain themselves
+		if node.ID != args.NodeID {
+			return structs.ErrPermissionDenied
+		}
 	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
 		return structs.ErrPermissionDenied
 	}
@@ -484,6 +533,12 @@ func (n *Node) UpdateDrain(args *structs.NodeUpdateDrainRequest,
 		return fmt.Errorf("node not found")
 	}
 
+	// Nodes being scaled in must stay ineligible until the scale-in is
+	// cancelled
+	if node.ScaleIn != nil && args.MarkEligible && args.DrainStrategy == nil && !args.Drain {
+		return fmt.Errorf("can not mark node eligible for scheduling while it is being scaled in")
+	}
+
 	// COMPAT: Remove in 0.9. Attempt to upgrade the request if it is of the old
 	// format.
 	if args.Drain && args.DrainStrategy == nil {
@@ -582,6 +637,9 @@ func (n *Node) UpdateEligibility(args *structs.NodeUpdateEligibilityRequest,
 	if node.DrainStrategy != nil && args.Eligibility == structs.NodeSchedulingEligible {
 		return fmt.Errorf("can not set node's scheduling eligibility to eligible while it is draining")
 	}
+	if node.ScaleIn != nil && args.Eligibility == structs.NodeSchedulingEligible {
+		return fmt.Errorf("can not set node's scheduling eligibility to eligible while it is being scaled in")
+	}
 
 	switch args.Eligibility {
 	case structs.NodeSchedulingEligible, structs.NodeSchedulingIneligible:
@@ -629,6 +687,140 @@ func (n *Node) UpdateEligibility(args *structs.NodeUpdateEligibilityRequest,
 	return nil
 }
 
+// RegisterHostVolume is used to register a host volume on a node or update an
+// already registered volume of the same name
+func (n *Node) RegisterHostVolume(args *structs.NodeHostVolumeRegisterRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := n.srv.forward("Node.RegisterHostVolume", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "register_host_volume"}, time.Now())
+
+	// Check node write permissions
+	if aclObj, err := n.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Verify the arguments
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID for registering host volume")
+	}
+	if args.Volume == nil {
+		return fmt.Errorf("missing host volume")
+	}
+	if args.NodeEvent != nil {
+		return fmt.Errorf("node event must not be set")
+	}
+	args.Volume.Canonicalize()
+	if err := args.Volume.Validate(); err != nil {
+		return err
+	}
+
+	// Look for the node
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	node, err := snap.NodeByID(nil, args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	// Construct the node event
+	msg := NodeHostVolumeEventRegistered
+	if _, ok := node.HostVolumes[args.Volume.Name]; ok {
+		msg = NodeHostVolumeEventUpdated
+	}
+	args.NodeEvent = structs.NewNodeEvent().SetSubsystem(structs.NodeEventSubsystemCluster).
+		SetMessage(fmt.Sprintf(msg, args.Volume.Name))
+
+	// Commit this update via Raft
+	outErr, index, err := n.srv.raftApply(structs.NodeHostVolumeRegisterRequestType, args)
+	if err != nil {
+		n.logger.Error("host volume registration failed", "error", err)
+		return err
+	}
+	if outErr != nil {
+		if err, ok := outErr.(error); ok && err != nil {
+			n.logger.Error("host volume registration failed", "error", err)
+			return err
+		}
+	}
+
+	// Set the reply index
+	reply.Index = index
+	return nil
+}
+
+// DeregisterHostVolume is used to deregister a host volume from a node
+func (n *Node) DeregisterHostVolume(args *structs.NodeHostVolumeDeregisterRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := n.srv.forward("Node.DeregisterHostVolume", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "deregister_host_volume"}, time.Now())
+
+	// Check node write permissions
+	if aclObj, err := n.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Verify the arguments
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID for deregistering host volume")
+	}
+	if args.Name == "" {
+		return fmt.Errorf("missing host volume name")
+	}
+	if args.NodeEvent != nil {
+		return fmt.Errorf("node event must not be set")
+	}
+
+	// Look for the node and volume
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	node, err := snap.NodeByID(nil, args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+	if _, ok := node.HostVolumes[args.Name]; !ok {
+		return fmt.Errorf("host volume %q not found", args.Name)
+	}
+
+	// Construct the node event
+	args.NodeEvent = structs.NewNodeEvent().SetSubsystem(structs.NodeEventSubsystemCluster).
+		SetMessage(fmt.Sprintf(NodeHostVolumeEventDeregistered, args.Name))
+
+	// Commit this update via Raft
+	outErr, index, err := n.srv.raftApply(structs.NodeHostVolumeDeregisterRequestType, args)
+	if err != nil {
+		n.logger.Error("host volume deregistration failed", "error", err)
+		return err
+	}
+	if outErr != nil {
+		if err, ok := outErr.(error); ok && err != nil {
+			n.logger.Error("host volume deregistration failed", "error", err)
+			return err
+		}
+	}
+
+	// Set the reply index
+	reply.Index = index
+	return nil
+}
+
 // Evaluate is used to force a re-evaluation of the node
 func (n *Node) Evaluate(args *structs.NodeEvaluateRequest, reply *structs.NodeUpdateResponse) error {
 	if done, err := n.srv.forward("Node.Evaluate", args, args, reply); done {
@@ -772,24 +964,25 @@ func (n *Node) GetAllocs(args *structs.NodeSpecificRequest,
 		return structs.ErrPermissionDenied
 	}
 
-	// cache namespace perms
-	readableNamespaces := map[string]bool{}
+	// cache job perms
+	readableJobs := map[structs.NamespacedID]bool{}
 
-	// readNS is a caching namespace read-job helper
-	readNS := func(ns string) bool {
+	// readJob is a caching job read-job helper
+	readJob := func(ns, jobID string) bool {
 		if aclObj == nil {
 			// ACLs are disabled; everything is readable
 			return true
 		}
 
-		if readable, ok := readableNamespaces[ns]; ok {
+		key := structs.NamespacedID{ID: jobID, Namespace: ns}
+		if readable, ok := readableJobs[key]; ok {
 			// cache hit
 			return readable
 		}
 
 		// cache miss
-		readable := aclObj.AllowNsOp(ns, acl.NamespaceCapabilityReadJob)
-		readableNamespaces[ns] = readable
+		readable := aclObj.AllowJobOp(ns, jobID, acl.NamespaceCapabilityReadJob)
+		readableJobs[key] = readable
 		return readable
 	}
 
@@ -813,7 +1006,7 @@ func (n *Node) GetAllocs(args *structs.NodeSpecificRequest,
 			if n := len(allocs); n != 0 {
 				reply.Allocs = make([]*structs.Allocation, 0, n)
 				for _, alloc := range allocs {
-					if readNS(alloc.Namespace) {
+					if readJob(alloc.Namespace, alloc.JobID) {
 						reply.Allocs = append(reply.Allocs, alloc)
 					}
 
@@ -1134,7 +1327,7 @@ func (n *Node) batchUpdate(future *structs.BatchFuture, updates []*structs.Alloc
 
 	if l := len(revoke); l != 0 {
 		n.logger.Debug("revoking accessors due to terminal allocations", "num_accessors", l)
-		if err := n.srv.vault.RevokeTokens(context.Background(), revoke, true); err != nil {
+		if err := n.srv.revokeVaultTokens(context.Background(), revoke, true); err != nil {
 			n.logger.Error("batched Vault accessor revocation failed", "error", err)
 			mErr.Errors = append(mErr.Errors, err)
 		}
@@ -1183,7 +1376,7 @@ func (n *Node) List(args *structs.NodeListRequest,
 					break
 				}
 				node := raw.(*structs.Node)
-				nodes = append(nodes, node.Stub())
+				nodes = append(nodes, node.Stub(args.Fields))
 			}
 			reply.Nodes = nodes
 
@@ -1416,6 +1609,36 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 		return nil
 	}
 
+	// Split the tasks by whether their Vault cluster uses JWT auth, in which
+	// case the client exchanges a workload identity for the Vault token itself
+	identities := make(map[string]string)
+	var tokenTasks []string
+	for _, task := range args.Tasks {
+		cluster := tg[task].Cluster
+		if signer := n.srv.vaultIdentitySigner(cluster); signer != nil {
+			signed, err := signVaultIdentities(signer, n.srv.Region(), alloc, []string{task}, time.Now())
+			if err != nil {
+				setErr(err, false)
+				return nil
+			}
+			identities[task] = signed[task]
+			continue
+		}
+		if n.srv.vaultClientFor(cluster) == nil {
+			setErr(fmt.Errorf("Task %q requests unknown Vault cluster %q", task, cluster), false)
+			return nil
+		}
+		tokenTasks = append(tokenTasks, task)
+	}
+
+	if len(identities) != 0 {
+		reply.Identities = identities
+	}
+	if len(tokenTasks) == 0 {
+		n.srv.setQueryMeta(&reply.QueryMeta)
+		return nil
+	}
+
 	// At this point the request is valid and we should contact Vault for
 	// tokens.
 
@@ -1425,14 +1648,14 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 	g, ctx := errgroup.WithContext(context.Background())
 
 	// Cap the handlers
-	handlers := len(args.Tasks)
+	handlers := len(tokenTasks)
 	if handlers > maxParallelRequestsPerDerive {
 		handlers = maxParallelRequestsPerDerive
 	}
 
 	// Create the Vault Tokens
 	input := make(chan string, handlers)
-	results := make(map[string]*vapi.Secret, len(args.Tasks))
+	results := make(map[string]*vapi.Secret, len(tokenTasks))
 	for i := 0; i < handlers; i++ {
 		g.Go(func() error {
 			for {
@@ -1442,7 +1665,8 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 						return nil
 					}
 
-					secret, err := n.srv.vault.CreateToken(ctx, alloc, task)
+					cluster := tg[task].Cluster
+					secret, err := n.srv.vaultClientFor(cluster).CreateToken(ctx, alloc, task)
 					if err != nil {
 						return err
 					}
@@ -1458,7 +1682,7 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 	// Send the input
 	go func() {
 		defer close(input)
-		for _, task := range args.Tasks {
+		for _, task := range tokenTasks {
 			select {
 			case <-ctx.Done():
 				return
@@ -1483,6 +1707,7 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 			NodeID:      alloc.NodeID,
 			AllocID:     alloc.ID,
 			CreationTTL: w.TTL,
+			Cluster:     tg[task].Cluster,
 		}
 
 		accessors = append(accessors, accessor)
@@ -1492,7 +1717,7 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 	if createErr != nil {
 		n.logger.Error("Vault token creation for alloc failed", "alloc_id", alloc.ID, "error", createErr)
 
-		if revokeErr := n.srv.vault.RevokeTokens(context.Background(), accessors, false); revokeErr != nil {
+		if revokeErr := n.srv.revokeVaultTokens(context.Background(), accessors, false); revokeErr != nil {
 			n.logger.Error("Vault token revocation for alloc failed", "alloc_id", alloc.ID, "error", revokeErr)
 		}
 
@@ -1552,3 +1777,376 @@ func (n *Node) EmitEvents(args *structs.EmitNodeEventsRequest, reply *structs.Em
 	reply.Index = index
 	return nil
 }
+
+// ScaleIn is used by autoscalers to start or cancel the scale-in of a node.
+// Starting it marks the node ineligible and drains it in a single update, so
+// no allocations are placed on the node while its instance is terminated.
+func (n *Node) ScaleIn(args *structs.NodeScaleInRequest,
+	reply *structs.NodeDrainUpdateResponse) error {
+	if done, err := n.srv.forward("Node.ScaleIn", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "scale_in"}, time.Now())
+
+	// Check node write permissions
+	aclObj, err := n.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Verify the arguments
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID for scale-in")
+	}
+	if args.ScaleIn != nil || args.DrainStrategy != nil || args.NodeEvent != nil {
+		return fmt.Errorf("scale-in, drain strategy and node event must not be set")
+	}
+
+	// Look for the node
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	node, err := snap.NodeByID(nil, args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	args.NodeEvent = structs.NewNodeEvent().SetSubsystem(structs.NodeEventSubsystemCluster)
+	if args.Cancel {
+		if node.ScaleIn == nil {
+			return fmt.Errorf("node %q is not being scaled in", args.NodeID)
+		}
+		args.NodeEvent.SetMessage(NodeScaleInEventCancelled)
+	} else {
+		if node.ScaleIn != nil {
+			return fmt.Errorf("node %q is already being scaled in", args.NodeID)
+		}
+
+		spec := args.DrainSpec
+		if spec == nil {
+			spec = &structs.DrainSpec{IgnoreSystemJobs: true}
+		}
+		args.DrainStrategy = &structs.DrainStrategy{DrainSpec: *spec}
+		if spec.Deadline > 0 {
+			args.DrainStrategy.ForceDeadline = time.Now().Add(spec.Deadline)
+		}
+
+		args.ScaleIn = &structs.NodeScaleIn{
+			RequestedAt: time.Now().UTC().UnixNano(),
+			Message:     args.Message,
+		}
+		if n.srv.config.ACLEnabled && args.AuthToken != "" {
+			token, err := snap.ACLTokenBySecretID(nil, args.AuthToken)
+			if err != nil {
+				return err
+			}
+			if token != nil {
+				args.ScaleIn.AccessorID = token.AccessorID
+			}
+		}
+
+		msg := NodeScaleInEventStarted
+		if args.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, args.Message)
+		}
+		args.NodeEvent.SetMessage(msg)
+	}
+
+	// Commit this update via Raft
+	outErr, index, err := n.srv.raftApply(structs.NodeScaleInRequestType, args)
+	if err != nil {
+		n.logger.Error("node scale-in update failed", "error", err)
+		return err
+	}
+	if outErr != nil {
+		if err, ok := outErr.(error); ok && err != nil {
+			n.logger.Error("node scale-in update failed", "error", err)
+			return err
+		}
+	}
+	reply.NodeModifyIndex = index
+
+	// Cancelling the scale-in makes the node eligible again, so system jobs
+	// may have to be placed on it
+	if args.Cancel {
+		evalIDs, evalIndex, err := n.createNodeEvals(args.NodeID, index)
+		if err != nil {
+			n.logger.Error("eval creation failed", "error", err)
+			return err
+		}
+		reply.EvalIDs = evalIDs
+		reply.EvalCreateIndex = evalIndex
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// ScaleInStatus returns the progress of the scale-in of a node. Autoscalers
+// wait for the node to be ready before terminating its instance.
+func (n *Node) ScaleInStatus(args *structs.NodeSpecificRequest,
+	reply *structs.NodeScaleInStatusResponse) error {
+	if done, err := n.srv.forward("Node.ScaleInStatus", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "scale_in_status"}, time.Now())
+
+	// Check node read permissions
+	if aclObj, err := n.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Verify the arguments
+			if args.NodeID == "" {
+				return fmt.Errorf("missing node ID")
+			}
+
+			node, err := state.NodeByID(ws, args.NodeID)
+			if err != nil {
+				return err
+			}
+			if node == nil {
+				return fmt.Errorf("node not found")
+			}
+
+			reply.Status = nil
+			if node.ScaleIn != nil {
+				allocs, err := state.AllocsByNode(ws, args.NodeID)
+				if err != nil {
+					return err
+				}
+				reply.Status = nodeScaleInStatus(node, allocs)
+			}
+
+			// Use the last index that affected the nodes or allocs tables
+			index, err := state.Index("nodes")
+			if err != nil {
+				return err
+			}
+			allocIndex, err := state.Index("allocs")
+			if err != nil {
+				return err
+			}
+			reply.Index = helper.Uint64Max(index, allocIndex)
+			n.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
+
+// nodeScaleInStatus returns the scale-in status of a node being scaled in.
+// Allocations of system jobs aren't counted as remaining, since they run on
+// every node and are stopped along with the instance of the node.
+func nodeScaleInStatus(node *structs.Node, allocs []*structs.Allocation) *structs.NodeScaleInStatus {
+	status := &structs.NodeScaleInStatus{
+		NodeID:          node.ID,
+		ScaleIn:         node.ScaleIn.Copy(),
+		NodeStatus:      node.Status,
+		NodeModifyIndex: node.ModifyIndex,
+	}
+	for _, alloc := range allocs {
+		if alloc.TerminalStatus() {
+			continue
+		}
+		if alloc.Job != nil && alloc.Job.Type == structs.JobTypeSystem {
+			continue
+		}
+		status.RemainingAllocs++
+	}
+
+	status.Status = structs.NodeScaleInStatusDraining
+	if node.DrainStrategy == nil && status.RemainingAllocs == 0 {
+		status.Status = structs.NodeScaleInStatusReady
+	}
+	return status
+}
+
+// CompleteScaleIn purges a node that has been scaled in, once the autoscaler
+// has terminated its instance. Unless forced, the node must be ready, so
+// allocations are never lost because an instance was terminated too early.
+func (n *Node) CompleteScaleIn(args *structs.NodeScaleInCompleteRequest,
+	reply *structs.NodeUpdateResponse) error {
+	if done, err := n.srv.forward("Node.CompleteScaleIn", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "complete_scale_in"}, time.Now())
+
+	// Check node write permissions
+	if aclObj, err := n.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Verify the arguments
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID for scale-in")
+	}
+
+	// Look for the node
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	node, err := snap.NodeByID(nil, args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+	if node.ScaleIn == nil {
+		return fmt.Errorf("node %q is not being scaled in", args.NodeID)
+	}
+
+	if !args.Force {
+		allocs, err := snap.AllocsByNode(nil, args.NodeID)
+		if err != nil {
+			return err
+		}
+		status := nodeScaleInStatus(node, allocs)
+		if status.Status != structs.NodeScaleInStatusReady {
+			return fmt.Errorf("node %q has not finished draining: %d allocations remaining",
+				args.NodeID, status.RemainingAllocs)
+		}
+	}
+
+	return n.deregister(&structs.NodeDeregisterRequest{
+		NodeID:       args.NodeID,
+		WriteRequest: args.WriteRequest,
+	}, reply)
+}
+
+// ScalingStatus returns the capacity of the node pools of the cluster, where a
+// pool is the nodes of a datacenter and node class. Autoscalers use it to
+// evaluate cluster scaling policies without listing every node and
+// allocation.
+func (n *Node) ScalingStatus(args *structs.NodeScalingStatusRequest,
+	reply *structs.NodeScalingStatusResponse) error {
+	if done, err := n.srv.forward("Node.ScalingStatus", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "scaling_status"}, time.Now())
+
+	// Check node read permissions
+	if aclObj, err := n.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			iter, err := state.Nodes(ws)
+			if err != nil {
+				return err
+			}
+
+			pools := make(map[[2]string]*structs.NodePoolScalingStatus)
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				node := raw.(*structs.Node)
+				if args.Datacenter != "" && node.Datacenter != args.Datacenter {
+					continue
+				}
+				if args.NodeClass != "" && node.NodeClass != args.NodeClass {
+					continue
+				}
+
+				key := [2]string{node.Datacenter, node.NodeClass}
+				pool, ok := pools[key]
+				if !ok {
+					pool = &structs.NodePoolScalingStatus{
+						Datacenter: node.Datacenter,
+						NodeClass:  node.NodeClass,
+					}
+					pools[key] = pool
+				}
+
+				allocs, err := state.AllocsByNode(ws, node.ID)
+				if err != nil {
+					return err
+				}
+				addNodeScalingStatus(pool, node, allocs)
+			}
+
+			reply.Pools = make([]*structs.NodePoolScalingStatus, 0, len(pools))
+			for _, pool := range pools {
+				reply.Pools = append(reply.Pools, pool)
+			}
+			sort.Slice(reply.Pools, func(i, j int) bool {
+				a, b := reply.Pools[i], reply.Pools[j]
+				if a.Datacenter != b.Datacenter {
+					return a.Datacenter < b.Datacenter
+				}
+				return a.NodeClass < b.NodeClass
+			})
+
+			// Use the last index that affected the nodes or allocs tables
+			index, err := state.Index("nodes")
+			if err != nil {
+				return err
+			}
+			allocIndex, err := state.Index("allocs")
+			if err != nil {
+				return err
+			}
+			reply.Index = helper.Uint64Max(index, allocIndex)
+			n.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
+
+// addNodeScalingStatus adds a node and its allocations to the scaling status
+// of its pool. Only the resources of ready nodes count towards the capacity
+// of the pool.
+func addNodeScalingStatus(pool *structs.NodePoolScalingStatus, node *structs.Node, allocs []*structs.Allocation) {
+	pool.Nodes++
+	if node.DrainStrategy != nil {
+		pool.Draining++
+	}
+	if node.ScaleIn != nil {
+		pool.ScalingIn++
+	}
+
+	var allocated structs.ComparableResources
+	for _, alloc := range allocs {
+		if alloc.TerminalStatus() {
+			continue
+		}
+		pool.Allocations++
+		allocated.Add(alloc.ComparableResources())
+	}
+
+	if !node.Ready() {
+		return
+	}
+	pool.Ready++
+
+	total := node.ComparableResources()
+	total.Subtract(node.ComparableReservedResources())
+	pool.TotalCPU += total.Flattened.Cpu.CpuShares
+	pool.TotalMemoryMB += total.Flattened.Memory.MemoryMB
+	pool.AllocatedCPU += allocated.Flattened.Cpu.CpuShares
+	pool.AllocatedMemoryMB += allocated.Flattened.Memory.MemoryMB
+}
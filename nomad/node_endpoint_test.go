@@ -1,8 +1,16 @@
 package nomad
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -12,6 +20,7 @@ import (
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/auth"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -819,8 +828,13 @@ func TestClientEndpoint_UpdateStatus_HeartbeatOnly_Advertise(t *testing.T) {
 	adv, err := net.ResolveTCPAddr("tcp", advAddr)
 	require.Nil(err)
 
+	segAddr := "127.0.2.1:2345"
+	seg, err := net.ResolveTCPAddr("tcp", segAddr)
+	require.Nil(err)
+
 	s1 := TestServer(t, func(c *Config) {
 		c.ClientRPCAdvertise = adv
+		c.ClientRPCAdvertiseSegments = map[string]*net.TCPAddr{"dmz": seg}
 	})
 	defer s1.Shutdown()
 	codec := rpcClient(t, s1)
@@ -848,6 +862,7 @@ func TestClientEndpoint_UpdateStatus_HeartbeatOnly_Advertise(t *testing.T) {
 	// Check for heartbeat servers
 	require.Len(resp.Servers, 1)
 	require.Equal(resp.Servers[0].RPCAdvertiseAddr, advAddr)
+	require.Equal(map[string]string{"dmz": segAddr}, resp.Servers[0].RPCSegmentAddrs)
 }
 
 func TestClientEndpoint_UpdateDrain(t *testing.T) {
@@ -991,6 +1006,24 @@ func TestClientEndpoint_UpdateDrain_ACL(t *testing.T) {
 		var resp structs.NodeDrainUpdateResponse
 		require.Nil(msgpackrpc.CallWithCodec(codec, "Node.UpdateDrain", dereg, &resp), "RPC")
 	}
+
+	// Try with the node's secret ID
+	dereg.AuthToken = node.SecretID
+	{
+		var resp structs.NodeDrainUpdateResponse
+		require.Nil(msgpackrpc.CallWithCodec(codec, "Node.UpdateDrain", dereg, &resp), "RPC")
+	}
+
+	// Try with another node's secret ID
+	other := mock.Node()
+	require.Nil(state.UpsertNode(1004, other), "UpsertNode")
+	dereg.AuthToken = other.SecretID
+	{
+		var resp structs.NodeDrainUpdateResponse
+		err := msgpackrpc.CallWithCodec(codec, "Node.UpdateDrain", dereg, &resp)
+		require.NotNil(err, "RPC")
+		require.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
 }
 
 // This test ensures that Nomad marks client state of allocations which are in
@@ -1237,6 +1270,350 @@ func TestClientEndpoint_UpdateEligibility_ACL(t *testing.T) {
 	}
 }
 
+func TestClientEndpoint_HostVolumes(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Fetch the response
+	var resp structs.NodeUpdateResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp))
+
+	// Registering an invalid volume fails
+	volReg := &structs.NodeHostVolumeRegisterRequest{
+		NodeID: node.ID,
+		Volume: &structs.HostVolume{
+			Name: "data",
+			Path: "srv/data",
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp2 structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "Node.RegisterHostVolume", volReg, &resp2)
+	require.NotNil(err)
+	require.Contains(err.Error(), "must be absolute")
+
+	// Register the volume
+	volReg.Volume.Path = "/srv/data"
+	volReg.Volume.CapacityMB = 1024
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.RegisterHostVolume", volReg, &resp2))
+	require.NotZero(resp2.Index)
+
+	// Check for the volume in the FSM
+	state := s1.fsm.State()
+	out, err := state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Len(out.HostVolumes, 1)
+	vol := out.HostVolumes["data"]
+	require.Equal("/srv/data", vol.Path)
+	require.EqualValues(1024, vol.CapacityMB)
+	require.Equal(structs.HostVolumeAccessModeSingleWriter, vol.AccessMode)
+	require.Len(out.Events, 2)
+	require.Equal(`Host volume "data" registered`, out.Events[1].Message)
+
+	// Update the volume
+	volReg.Volume.AccessMode = structs.HostVolumeAccessModeReadOnly
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.RegisterHostVolume", volReg, &resp2))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Equal(structs.HostVolumeAccessModeReadOnly, out.HostVolumes["data"].AccessMode)
+	require.Equal(`Host volume "data" updated`, out.Events[2].Message)
+
+	// Deregister the volume
+	volDereg := &structs.NodeHostVolumeDeregisterRequest{
+		NodeID:       node.ID,
+		Name:         "data",
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp3 structs.GenericResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.DeregisterHostVolume", volDereg, &resp3))
+	require.NotZero(resp3.Index)
+
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Empty(out.HostVolumes)
+	require.Equal(`Host volume "data" deregistered`, out.Events[3].Message)
+
+	// Deregistering an unknown volume fails
+	err = msgpackrpc.CallWithCodec(codec, "Node.DeregisterHostVolume", volDereg, &resp3)
+	require.NotNil(err)
+	require.Contains(err.Error(), "not found")
+}
+
+func TestClientEndpoint_HostVolumes_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	// Create the node
+	node := mock.Node()
+	state := s1.fsm.State()
+
+	require.Nil(state.UpsertNode(1, node), "UpsertNode")
+
+	// Create the policy and tokens
+	validToken := mock.CreatePolicyAndToken(t, state, 1001, "test-valid", mock.NodePolicy(acl.PolicyWrite))
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid", mock.NodePolicy(acl.PolicyRead))
+
+	// Register the volume without a token and expect failure
+	reg := &structs.NodeHostVolumeRegisterRequest{
+		NodeID: node.ID,
+		Volume: &structs.HostVolume{
+			Name: "data",
+			Path: "/srv/data",
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	{
+		var resp structs.GenericResponse
+		err := msgpackrpc.CallWithCodec(codec, "Node.RegisterHostVolume", reg, &resp)
+		require.NotNil(err, "RPC")
+		require.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with a valid token
+	reg.AuthToken = validToken.SecretID
+	{
+		var resp structs.GenericResponse
+		require.Nil(msgpackrpc.CallWithCodec(codec, "Node.RegisterHostVolume", reg, &resp), "RPC")
+	}
+
+	// Try with a invalid token
+	dereg := &structs.NodeHostVolumeDeregisterRequest{
+		NodeID:       node.ID,
+		Name:         "data",
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	dereg.AuthToken = invalidToken.SecretID
+	{
+		var resp structs.GenericResponse
+		err := msgpackrpc.CallWithCodec(codec, "Node.DeregisterHostVolume", dereg, &resp)
+		require.NotNil(err, "RPC")
+		require.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with a root token
+	dereg.AuthToken = root.SecretID
+	{
+		var resp structs.GenericResponse
+		require.Nil(msgpackrpc.CallWithCodec(codec, "Node.DeregisterHostVolume", dereg, &resp), "RPC")
+	}
+}
+
+func TestClientEndpoint_ScaleIn(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	// Create a node running an allocation
+	node := mock.Node()
+	state := s1.fsm.State()
+	require.Nil(state.UpsertNode(1, node))
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.ClientStatus = structs.AllocClientStatusRunning
+	require.Nil(state.UpsertJob(2, alloc.Job))
+	require.Nil(state.UpsertAllocs(3, []*structs.Allocation{alloc}))
+
+	// Start the scale-in
+	req := &structs.NodeScaleInRequest{
+		NodeID:       node.ID,
+		Message:      "scaling down",
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeDrainUpdateResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.ScaleIn", req, &resp))
+	require.NotZero(resp.Index)
+
+	out, err := state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.NotNil(out.ScaleIn)
+	require.Equal("scaling down", out.ScaleIn.Message)
+	require.NotNil(out.DrainStrategy)
+	require.True(out.DrainStrategy.IgnoreSystemJobs)
+	require.Equal(structs.NodeSchedulingIneligible, out.SchedulingEligibility)
+
+	// Starting it again fails
+	err = msgpackrpc.CallWithCodec(codec, "Node.ScaleIn", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "already being scaled in")
+
+	// The node can't be marked eligible
+	elig := &structs.NodeUpdateEligibilityRequest{
+		NodeID:       node.ID,
+		Eligibility:  structs.NodeSchedulingEligible,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var eligResp structs.NodeEligibilityUpdateResponse
+	require.NotNil(msgpackrpc.CallWithCodec(codec, "Node.UpdateEligibility", elig, &eligResp))
+
+	// The node is draining while the allocation is running
+	statusReq := &structs.NodeSpecificRequest{
+		NodeID:       node.ID,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var statusResp structs.NodeScaleInStatusResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.ScaleInStatus", statusReq, &statusResp))
+	require.NotNil(statusResp.Status)
+	require.Equal(structs.NodeScaleInStatusDraining, statusResp.Status.Status)
+	require.Equal(1, statusResp.Status.RemainingAllocs)
+
+	// Completing the scale-in fails until the node is drained
+	complete := &structs.NodeScaleInCompleteRequest{
+		NodeID:       node.ID,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var completeResp structs.NodeUpdateResponse
+	err = msgpackrpc.CallWithCodec(codec, "Node.CompleteScaleIn", complete, &completeResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "1 allocations remaining")
+
+	// Stop the allocation and wait for the drain to complete
+	stopped := alloc.Copy()
+	stopped.ClientStatus = structs.AllocClientStatusComplete
+	require.Nil(state.UpdateAllocsFromClient(4, []*structs.Allocation{stopped}))
+	testutil.WaitForResult(func() (bool, error) {
+		var statusResp structs.NodeScaleInStatusResponse
+		if err := msgpackrpc.CallWithCodec(codec, "Node.ScaleInStatus", statusReq, &statusResp); err != nil {
+			return false, err
+		}
+		status := statusResp.Status.Status
+		return status == structs.NodeScaleInStatusReady, fmt.Errorf("scale-in status is %q", status)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// Complete the scale-in, purging the node
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.CompleteScaleIn", complete, &completeResp))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Nil(out)
+}
+
+func TestClientEndpoint_ScaleIn_Cancel(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	node := mock.Node()
+	state := s1.fsm.State()
+	require.Nil(state.UpsertNode(1, node))
+
+	// Cancelling without a scale-in fails
+	req := &structs.NodeScaleInRequest{
+		NodeID:       node.ID,
+		Cancel:       true,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeDrainUpdateResponse
+	err := msgpackrpc.CallWithCodec(codec, "Node.ScaleIn", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "not being scaled in")
+
+	// Completing without a scale-in fails
+	complete := &structs.NodeScaleInCompleteRequest{
+		NodeID:       node.ID,
+		Force:        true,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var completeResp structs.NodeUpdateResponse
+	err = msgpackrpc.CallWithCodec(codec, "Node.CompleteScaleIn", complete, &completeResp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "not being scaled in")
+
+	// Start and cancel the scale-in
+	req.Cancel = false
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.ScaleIn", req, &resp))
+	req.Cancel = true
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.ScaleIn", req, &resp))
+
+	out, err := state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Nil(out.ScaleIn)
+	require.Nil(out.DrainStrategy)
+	require.Equal(structs.NodeSchedulingEligible, out.SchedulingEligibility)
+	require.Equal(NodeScaleInEventCancelled, out.Events[len(out.Events)-1].Message)
+}
+
+func TestClientEndpoint_ScalingStatus(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+
+	// Create two ready nodes of a class, one of which runs an allocation,
+	// and an ineligible node of another class
+	state := s1.fsm.State()
+	node1, node2, node3 := mock.Node(), mock.Node(), mock.Node()
+	node3.NodeClass = "windows-large"
+	node3.SchedulingEligibility = structs.NodeSchedulingIneligible
+	require.Nil(state.UpsertNode(1, node1))
+	require.Nil(state.UpsertNode(2, node2))
+	require.Nil(state.UpsertNode(3, node3))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node1.ID
+	require.Nil(state.UpsertJobSummary(4, mock.JobSummary(alloc.JobID)))
+	require.Nil(state.UpsertAllocs(5, []*structs.Allocation{alloc}))
+
+	req := &structs.NodeScalingStatusRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.NodeScalingStatusResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.ScalingStatus", req, &resp))
+	require.EqualValues(5, resp.Index)
+	require.Len(resp.Pools, 2)
+
+	pool := resp.Pools[0]
+	require.Equal(node1.NodeClass, pool.NodeClass)
+	require.Equal(2, pool.Nodes)
+	require.Equal(2, pool.Ready)
+	require.Equal(1, pool.Allocations)
+
+	total := node1.ComparableResources()
+	total.Subtract(node1.ComparableReservedResources())
+	require.Equal(2*total.Flattened.Cpu.CpuShares, pool.TotalCPU)
+	require.Equal(2*total.Flattened.Memory.MemoryMB, pool.TotalMemoryMB)
+	allocated := alloc.ComparableResources()
+	require.Equal(allocated.Flattened.Cpu.CpuShares, pool.AllocatedCPU)
+	require.Equal(allocated.Flattened.Memory.MemoryMB, pool.AllocatedMemoryMB)
+
+	require.Equal("windows-large", resp.Pools[1].NodeClass)
+	require.Equal(1, resp.Pools[1].Nodes)
+	require.Zero(resp.Pools[1].Ready)
+	require.Zero(resp.Pools[1].TotalCPU)
+
+	// Filter by node class
+	req.NodeClass = "windows-large"
+	var resp2 structs.NodeScalingStatusResponse
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Node.ScalingStatus", req, &resp2))
+	require.Len(resp2.Pools, 1)
+	require.Equal("windows-large", resp2.Pools[0].NodeClass)
+}
+
 func TestClientEndpoint_GetNode(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -2912,6 +3289,163 @@ func TestClientEndpoint_DeriveVaultToken(t *testing.T) {
 	}
 }
 
+func TestClientEndpoint_DeriveVaultToken_Clusters(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	state := s1.fsm.State()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Replace the Vault Clients on the server
+	tvc, eu := &TestVaultClient{}, &TestVaultClient{}
+	s1.vault = tvc
+	s1.vaultClusters = map[string]VaultClient{"eu": eu}
+
+	// Create the node
+	node := mock.Node()
+	require.NoError(state.UpsertNode(2, node))
+
+	// Create an allocation whose task derives its token from the eu cluster
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Vault = &structs.Vault{Policies: []string{"a", "b"}, Cluster: "eu"}
+	require.NoError(state.UpsertAllocs(3, []*structs.Allocation{alloc}))
+
+	accessor := uuid.Generate()
+	eu.SetCreateTokenSecret(alloc.ID, task.Name, &vapi.Secret{
+		WrapInfo: &vapi.SecretWrapInfo{
+			Token:           uuid.Generate(),
+			WrappedAccessor: accessor,
+			TTL:             10,
+		},
+	})
+
+	req := &structs.DeriveVaultTokenRequest{
+		NodeID:   node.ID,
+		SecretID: node.SecretID,
+		AllocID:  alloc.ID,
+		Tasks:    []string{task.Name},
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+
+	var resp structs.DeriveVaultTokenResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Node.DeriveVaultToken", req, &resp))
+	require.Nil(resp.Error)
+	require.Len(resp.Tasks, 1)
+
+	// The accessor records the cluster that created the token
+	va, err := state.VaultAccessor(nil, accessor)
+	require.NoError(err)
+	require.NotNil(va)
+	require.Equal("eu", va.Cluster)
+
+	// Revoking the token uses the client of the cluster
+	require.NoError(s1.revokeVaultTokens(context.Background(), []*structs.VaultAccessor{va}, true))
+	require.Len(eu.RevokedTokens, 1)
+	require.Empty(tvc.RevokedTokens)
+
+	// Tasks can't derive tokens from unknown clusters
+	task.Vault.Cluster = "us"
+	require.NoError(state.UpsertAllocs(4, []*structs.Allocation{alloc}))
+
+	resp = structs.DeriveVaultTokenResponse{}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Node.DeriveVaultToken", req, &resp))
+	require.NotNil(resp.Error)
+	require.Contains(resp.Error.Error(), "unknown Vault cluster")
+}
+
+func TestClientEndpoint_DeriveVaultToken_JWTAuth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	state := s1.fsm.State()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Enable vault with JWT auth
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	dir, err := ioutil.TempDir("", "nomad")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "identity.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(ioutil.WriteFile(keyFile, keyPEM, 0600))
+
+	tr, f := true, false
+	s1.config.VaultConfig.Enabled = &tr
+	s1.config.VaultConfig.AllowUnauthenticated = &f
+	s1.config.VaultConfig.JWTSigningKeyFile = keyFile
+	require.NoError(s1.setupVaultIdentity(s1.config.VaultConfig))
+
+	// Create the node
+	node := mock.Node()
+	require.NoError(state.UpsertNode(2, node))
+
+	// Create an allocation that has vault policies required
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Vault = &structs.Vault{Policies: []string{"a", "b"}}
+	require.NoError(state.UpsertAllocs(3, []*structs.Allocation{alloc}))
+
+	req := &structs.DeriveVaultTokenRequest{
+		NodeID:   node.ID,
+		SecretID: node.SecretID,
+		AllocID:  alloc.ID,
+		Tasks:    []string{task.Name},
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+
+	var resp structs.DeriveVaultTokenResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Node.DeriveVaultToken", req, &resp))
+	require.Nil(resp.Error)
+	require.Nil(resp.Tasks)
+	require.Len(resp.Identities, 1)
+
+	// The workload identity is signed by the key and identifies the task
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(err)
+	method := &structs.ACLAuthMethod{
+		Name: "vault",
+		Type: structs.ACLAuthMethodTypeJWT,
+		Config: &structs.ACLAuthMethodConfig{
+			JWTValidationPubKeys: []string{string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+			BoundIssuer:          []string{vaultIdentityIssuer},
+			BoundAudiences:       []string{vaultIdentityAudience},
+			ClaimMappings: map[string]string{
+				"sub":                 "sub",
+				"nomad_job_id":        "job",
+				"nomad_task":          "task",
+				"nomad_allocation_id": "alloc",
+			},
+		},
+	}
+	v, err := auth.NewValidator(method)
+	require.NoError(err)
+	id, err := v.Validate(context.Background(), resp.Identities[task.Name])
+	require.NoError(err)
+	require.Equal(map[string]string{
+		"sub":   fmt.Sprintf("global:%s:%s:%s:%s", alloc.Namespace, alloc.JobID, alloc.TaskGroup, task.Name),
+		"job":   alloc.JobID,
+		"task":  task.Name,
+		"alloc": alloc.ID,
+	}, id.Values)
+
+	// No Vault accessors are tracked
+	iter, err := state.VaultAccessors(memdb.NewWatchSet())
+	require.NoError(err)
+	require.Nil(iter.Next())
+}
+
 func TestClientEndpoint_DeriveVaultToken_VaultError(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -1,15 +1,23 @@
 package nomad
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net"
+	"sort"
+	"time"
 
 	log "github.com/hashicorp/go-hclog"
 
 	"github.com/hashicorp/consul/agent/consul/autopilot"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/snapshot"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/raft"
 	"github.com/hashicorp/serf/serf"
+	"github.com/ugorji/go/codec"
 )
 
 // Operator endpoint is used to perform low-level operator tasks for Nomad.
@@ -18,6 +26,11 @@ type Operator struct {
 	logger log.Logger
 }
 
+func (op *Operator) register() {
+	op.srv.streamingRpcs.Register("Operator.SnapshotSave", op.snapshotSave)
+	op.srv.streamingRpcs.Register("Operator.SnapshotRestore", op.snapshotRestore)
+}
+
 // RaftGetConfiguration is used to retrieve the current Raft configuration.
 func (op *Operator) RaftGetConfiguration(args *structs.GenericRequest, reply *structs.RaftConfigurationResponse) error {
 	if done, err := op.srv.forward("Operator.RaftGetConfiguration", args, args, reply); done {
@@ -290,6 +303,133 @@ func (op *Operator) ServerHealth(args *structs.GenericRequest, reply *autopilot.
 	return nil
 }
 
+// ClusterHealth is used to get the aggregated health of the servers and of the
+// node heartbeats of the region.
+func (op *Operator) ClusterHealth(args *structs.GenericRequest, reply *structs.ClusterHealthResponse) error {
+	// This must be sent to the leader, which tracks the node heartbeats and
+	// the Autopilot health of the servers.
+	args.AllowStale = false
+	if done, err := op.srv.forward("Operator.ClusterHealth", args, args, reply); done {
+		return err
+	}
+
+	// This action requires operator read access.
+	rule, err := op.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	}
+	if rule != nil && !rule.AllowOperatorRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	future := op.srv.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+
+	// Index the Nomad information about the servers.
+	serverMap := make(map[raft.ServerAddress]serf.Member)
+	serverParts := make(map[raft.ServerAddress]*serverParts)
+	for _, member := range op.srv.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if !valid || parts.Region != op.srv.config.Region {
+			continue
+		}
+
+		addr := raft.ServerAddress((&net.TCPAddr{IP: member.Addr, Port: parts.Port}).String())
+		serverMap[addr] = member
+		serverParts[addr] = parts
+	}
+
+	// Autopilot health is only available with Raft protocol 3
+	minRaftProtocol, err := op.srv.autopilot.MinRaftProtocol()
+	if err != nil {
+		return fmt.Errorf("error getting server raft protocol versions: %s", err)
+	}
+	var health autopilot.OperatorHealthReply
+	if minRaftProtocol >= 3 {
+		health = op.srv.autopilot.GetClusterHealth()
+		reply.Autopilot = true
+		reply.Healthy = health.Healthy
+		reply.FailureTolerance = health.FailureTolerance
+	}
+
+	leader := op.srv.raft.Leader()
+	versions := make(map[string]struct{})
+	voters, aliveVoters := 0, 0
+	for _, server := range future.Configuration().Servers {
+		entry := &structs.ClusterServerHealth{
+			ID:           string(server.ID),
+			Name:         "(unknown)",
+			Address:      string(server.Address),
+			Version:      "unknown",
+			RaftProtocol: "unknown",
+			SerfStatus:   serf.StatusNone.String(),
+			Leader:       server.Address == leader,
+			Voter:        server.Suffrage == raft.Voter,
+		}
+		if member, ok := serverMap[server.Address]; ok {
+			entry.Name = member.Name
+			entry.SerfStatus = member.Status.String()
+			entry.Version = serverParts[server.Address].Build.String()
+			if raftVsn, ok := member.Tags["raft_vsn"]; ok {
+				entry.RaftProtocol = raftVsn
+			}
+			versions[entry.Version] = struct{}{}
+		}
+
+		if reply.Autopilot {
+			if h := health.ServerHealth(entry.ID); h != nil {
+				entry.Healthy = h.Healthy
+				entry.LastContact = h.LastContact
+				entry.LastTerm = h.LastTerm
+				entry.LastIndex = h.LastIndex
+				entry.StableSince = h.StableSince
+			}
+		} else {
+			entry.Healthy = entry.SerfStatus == serf.StatusAlive.String()
+		}
+
+		if entry.Voter {
+			voters++
+			if entry.Healthy {
+				aliveVoters++
+			}
+		}
+		reply.Servers = append(reply.Servers, entry)
+	}
+
+	// Without Autopilot, the servers are healthy if all of them are alive
+	if !reply.Autopilot {
+		reply.Healthy = aliveVoters == voters
+		if tolerance := aliveVoters - (voters/2 + 1); tolerance > 0 {
+			reply.FailureTolerance = tolerance
+		}
+	}
+
+	for v := range versions {
+		reply.Versions = append(reply.Versions, v)
+	}
+	sort.Strings(reply.Versions)
+	reply.VersionSkew = len(reply.Versions) > 1
+
+	reply.HeartbeatsTracked, reply.HeartbeatsOverdue, reply.HeartbeatsDeferred = op.srv.heartbeatSummary()
+
+	iter, err := op.srv.fsm.State().Nodes(nil)
+	if err != nil {
+		return err
+	}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		if raw.(*structs.Node).Status == structs.NodeStatusDown {
+			reply.DownNodes++
+		}
+	}
+
+	reply.Index = future.Index()
+	op.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
 // SchedulerSetConfiguration is used to set the current Scheduler configuration.
 func (op *Operator) SchedulerSetConfiguration(args *structs.SchedulerSetConfigRequest, reply *structs.SchedulerSetConfigurationResponse) error {
 	if done, err := op.srv.forward("Operator.SchedulerSetConfiguration", args, args, reply); done {
@@ -355,3 +495,291 @@ func (op *Operator) SchedulerGetConfiguration(args *structs.GenericRequest, repl
 
 	return nil
 }
+
+// snapshotSave streams a snapshot of the state of the servers. The
+// SnapshotSaveResponse is sent first, followed by the archive if no error
+// occurred.
+func (op *Operator) snapshotSave(conn io.ReadWriteCloser) {
+	defer conn.Close()
+
+	var args structs.SnapshotSaveRequest
+	decoder := codec.NewDecoder(conn, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(conn, structs.MsgpackHandle)
+
+	handleFailure := func(code int, err error) {
+		encoder.Encode(&structs.SnapshotSaveResponse{
+			ErrorCode: code,
+			ErrorMsg:  err.Error(),
+		})
+	}
+
+	if err := decoder.Decode(&args); err != nil {
+		handleFailure(500, err)
+		return
+	}
+
+	// Forward to the region or leader if needed
+	if server, err := op.snapshotServer(&args); err != nil {
+		handleFailure(500, err)
+		return
+	} else if server != nil {
+		if err := op.forwardStreamingRpc(server, "Operator.SnapshotSave", &args, conn); err != nil {
+			handleFailure(500, err)
+		}
+		return
+	}
+
+	// Check management permissions
+	if aclObj, err := op.srv.ResolveToken(args.AuthToken); err != nil {
+		handleFailure(403, err)
+		return
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		handleFailure(403, structs.ErrPermissionDenied)
+		return
+	}
+
+	snap, err := snapshot.New(op.logger, op.srv.raft)
+	if err != nil {
+		handleFailure(500, err)
+		return
+	}
+	defer snap.Close()
+
+	reply := structs.SnapshotSaveResponse{
+		SnapshotChecksum: snap.Checksum(),
+	}
+	op.srv.setQueryMeta(&reply.QueryMeta)
+	reply.Index = snap.Index()
+	if err := encoder.Encode(&reply); err != nil {
+		op.logger.Error("failed to send snapshot response", "error", err)
+		return
+	}
+	if _, err := io.Copy(conn, snap); err != nil {
+		op.logger.Error("failed to stream snapshot", "error", err)
+	}
+}
+
+// snapshotRestore restores the state of the servers from a snapshot streamed
+// after the request, and sends a SnapshotRestoreResponse once done.
+func (op *Operator) snapshotRestore(conn io.ReadWriteCloser) {
+	defer conn.Close()
+
+	var args structs.SnapshotRestoreRequest
+	decoder := codec.NewDecoder(conn, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(conn, structs.MsgpackHandle)
+
+	handleFailure := func(code int, err error) {
+		encoder.Encode(&structs.SnapshotRestoreResponse{
+			ErrorCode: code,
+			ErrorMsg:  err.Error(),
+		})
+	}
+
+	if err := decoder.Decode(&args); err != nil {
+		handleFailure(500, err)
+		return
+	}
+
+	// Forward to the region or leader if needed
+	if server, err := op.snapshotServer(&args); err != nil {
+		handleFailure(500, err)
+		return
+	} else if server != nil {
+		if err := op.forwardStreamingRpc(server, "Operator.SnapshotRestore", &args, conn); err != nil {
+			handleFailure(500, err)
+		}
+		return
+	}
+
+	// Check management permissions
+	if aclObj, err := op.srv.ResolveToken(args.AuthToken); err != nil {
+		handleFailure(403, err)
+		return
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		handleFailure(403, structs.ErrPermissionDenied)
+		return
+	}
+
+	// Copy the streamed archive into a pipe read by the restore
+	snapReader, snapWriter := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			var wrapper cstructs.StreamErrWrapper
+			if err := decoder.Decode(&wrapper); err != nil {
+				snapWriter.CloseWithError(err)
+				errCh <- err
+				return
+			}
+			if wrapper.Error != nil {
+				err := errors.New(wrapper.Error.Error())
+				snapWriter.CloseWithError(err)
+				errCh <- err
+				return
+			}
+
+			// A message without a payload ends the archive
+			if len(wrapper.Payload) == 0 {
+				snapWriter.Close()
+				errCh <- nil
+				return
+			}
+			if _, err := snapWriter.Write(wrapper.Payload); err != nil {
+				errCh <- err
+				return
+			}
+		}
+	}()
+
+	err := snapshot.Restore(op.logger, snapReader, op.srv.raft)
+	snapReader.Close()
+	if rerr := <-errCh; err == nil && rerr != nil {
+		err = rerr
+	}
+	if err != nil {
+		handleFailure(500, err)
+		return
+	}
+
+	// Reassert leadership so that the leader systems use the restored state
+	lerrCh := make(chan error, 1)
+	timeoutCh := time.After(time.Minute)
+	select {
+	case op.srv.reassertLeaderCh <- lerrCh:
+	case <-timeoutCh:
+		handleFailure(500, fmt.Errorf("timed out waiting to reassert leadership"))
+		return
+	case <-op.srv.shutdownCh:
+		handleFailure(500, fmt.Errorf("server is shutting down"))
+		return
+	}
+	select {
+	case err := <-lerrCh:
+		if err != nil {
+			handleFailure(500, fmt.Errorf("failed to reassert leadership: %v", err))
+			return
+		}
+	case <-timeoutCh:
+		handleFailure(500, fmt.Errorf("timed out waiting to reassert leadership"))
+		return
+	}
+
+	var reply structs.SnapshotRestoreResponse
+	reply.Index, _ = op.srv.State().LatestIndex()
+	op.srv.setQueryMeta(&reply.QueryMeta)
+	if err := encoder.Encode(&reply); err != nil {
+		op.logger.Error("failed to send snapshot restore response", "error", err)
+	}
+}
+
+// snapshotServer returns the server a snapshot request must be forwarded to,
+// or nil if it is handled by this server. Requests are handled by the leader
+// of their region, unless they allow stale reads.
+func (op *Operator) snapshotServer(info structs.RPCInfo) (*serverParts, error) {
+	if region := info.RequestRegion(); region != op.srv.Region() {
+		op.srv.peerLock.RLock()
+		defer op.srv.peerLock.RUnlock()
+		servers := op.srv.peers[region]
+		if len(servers) == 0 {
+			return nil, structs.ErrNoRegionPath
+		}
+		return servers[rand.Intn(len(servers))], nil
+	}
+
+	if info.IsRead() && info.AllowStaleRead() {
+		return nil, nil
+	}
+
+	isLeader, server := op.srv.getLeader()
+	if isLeader {
+		return nil, nil
+	} else if server == nil {
+		return nil, structs.ErrNoLeader
+	}
+	return server, nil
+}
+
+// forwardStreamingRpc forwards the streaming RPC to the server, bridging the
+// connection until either side closes it.
+func (op *Operator) forwardStreamingRpc(server *serverParts, method string, args interface{}, conn io.ReadWriteCloser) error {
+	srvConn, err := op.srv.streamingRpc(server, method)
+	if err != nil {
+		return err
+	}
+	defer srvConn.Close()
+
+	// Send the request
+	outEncoder := codec.NewEncoder(srvConn, structs.MsgpackHandle)
+	if err := outEncoder.Encode(args); err != nil {
+		return err
+	}
+
+	structs.Bridge(conn, srvConn)
+	return nil
+}
+
+// GCSetConfiguration is used to set the GC thresholds at runtime.
+func (op *Operator) GCSetConfiguration(args *structs.GCSetConfigRequest, reply *structs.GCSetConfigurationResponse) error {
+	if done, err := op.srv.forward("Operator.GCSetConfiguration", args, args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	rule, err := op.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if rule != nil && !rule.AllowOperatorWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	if err := args.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid GC configuration: %v", err)
+	}
+
+	// Apply the update
+	resp, index, err := op.srv.raftApply(structs.GCConfigRequestType, args)
+	if err != nil {
+		op.logger.Error("failed applying GC configuration", "error", err)
+		return err
+	} else if respErr, ok := resp.(error); ok {
+		return respErr
+	}
+
+	// Check if the return type is a bool
+	// Only applies to CAS requests
+	if respBool, ok := resp.(bool); ok {
+		reply.Updated = respBool
+	}
+	reply.Index = index
+	return nil
+}
+
+// GCGetConfiguration is used to retrieve the GC thresholds set at runtime.
+// Thresholds that were not set are returned as zero.
+func (op *Operator) GCGetConfiguration(args *structs.GenericRequest, reply *structs.GCConfigurationResponse) error {
+	if done, err := op.srv.forward("Operator.GCGetConfiguration", args, args, reply); done {
+		return err
+	}
+
+	// This action requires operator read access.
+	rule, err := op.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if rule != nil && !rule.AllowOperatorRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	state := op.srv.fsm.State()
+	index, config, err := state.GCConfig()
+	if err != nil {
+		return err
+	} else if config == nil {
+		config = &structs.GCConfiguration{}
+	}
+
+	reply.GCConfig = config
+	reply.QueryMeta.Index = index
+	op.srv.setQueryMeta(&reply.QueryMeta)
+
+	return nil
+}
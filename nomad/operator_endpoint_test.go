@@ -1,20 +1,27 @@
 package nomad
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/lib/freeport"
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/acl"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/snapshot"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/hashicorp/raft"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
 )
 
 func TestOperator_RaftGetConfiguration(t *testing.T) {
@@ -494,3 +501,292 @@ func TestOperator_SchedulerSetConfiguration_ACL(t *testing.T) {
 	}
 
 }
+
+// testOperatorSnapshotSave takes a snapshot using the streaming RPC of the server and
+// returns the response and archive.
+func testOperatorSnapshotSave(t *testing.T, s *Server, args *structs.SnapshotSaveRequest) (*structs.SnapshotSaveResponse, []byte) {
+	handler, err := s.StreamingRpcHandler("Operator.SnapshotSave")
+	require.NoError(t, err)
+
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	go handler(p2)
+
+	require.NoError(t, codec.NewEncoder(p1, structs.MsgpackHandle).Encode(args))
+
+	var resp structs.SnapshotSaveResponse
+	require.NoError(t, codec.NewDecoder(p1, structs.MsgpackHandle).Decode(&resp))
+
+	var archive bytes.Buffer
+	if resp.ErrorCode == 0 {
+		_, err = io.Copy(&archive, p1)
+		require.NoError(t, err)
+	}
+	return &resp, archive.Bytes()
+}
+
+// testOperatorSnapshotRestore restores the archive using the streaming RPC of the
+// server and returns the response.
+func testOperatorSnapshotRestore(t *testing.T, s *Server, args *structs.SnapshotRestoreRequest, archive []byte) *structs.SnapshotRestoreResponse {
+	handler, err := s.StreamingRpcHandler("Operator.SnapshotRestore")
+	require.NoError(t, err)
+
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	go handler(p2)
+
+	// Send the request and archive asynchronously, since the server may
+	// stop reading on errors
+	go func() {
+		encoder := codec.NewEncoder(p1, structs.MsgpackHandle)
+		if err := encoder.Encode(args); err != nil {
+			return
+		}
+		for len(archive) > 0 {
+			n := 1024
+			if len(archive) < n {
+				n = len(archive)
+			}
+			if err := encoder.Encode(&cstructs.StreamErrWrapper{Payload: archive[:n]}); err != nil {
+				return
+			}
+			archive = archive[n:]
+		}
+		encoder.Encode(&cstructs.StreamErrWrapper{})
+	}()
+
+	var resp structs.SnapshotRestoreResponse
+	require.NoError(t, codec.NewDecoder(p1, structs.MsgpackHandle).Decode(&resp))
+	return &resp
+}
+
+func TestOperator_SnapshotSaveRestore(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	rpcCodec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a job
+	job := mock.Job()
+	regReq := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(rpcCodec, "Job.Register", regReq, &regResp))
+
+	// Take a snapshot
+	resp, archive := testOperatorSnapshotSave(t, s1, &structs.SnapshotSaveRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	})
+	require.Zero(resp.ErrorCode, resp.ErrorMsg)
+	require.Contains(resp.SnapshotChecksum, "sha-256=")
+	require.True(resp.Index >= regResp.JobModifyIndex)
+
+	meta, err := snapshot.Verify(bytes.NewReader(archive))
+	require.NoError(err)
+	require.Equal(resp.Index, meta.Index)
+
+	// Purge the job
+	deregReq := &structs.JobDeregisterRequest{
+		JobID: job.ID,
+		Purge: true,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var deregResp structs.JobDeregisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(rpcCodec, "Job.Deregister", deregReq, &deregResp))
+	out, err := s1.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Nil(out)
+
+	// Restoring the snapshot brings the job back
+	restoreResp := testOperatorSnapshotRestore(t, s1, &structs.SnapshotRestoreRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}, archive)
+	require.Zero(restoreResp.ErrorCode, restoreResp.ErrorMsg)
+
+	out, err = s1.State().JobByID(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(out)
+
+	// The leader still works after reasserting leadership
+	regReq.Job = mock.Job()
+	require.NoError(msgpackrpc.CallWithCodec(rpcCodec, "Job.Register", regReq, &regResp))
+
+	// Corrupt archives are rejected
+	restoreResp = testOperatorSnapshotRestore(t, s1, &structs.SnapshotRestoreRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}, archive[:len(archive)/2])
+	require.Equal(500, restoreResp.ErrorCode)
+}
+
+func TestOperator_SnapshotSave_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	policy := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	token := mock.CreatePolicyAndToken(t, s1.State(), 1001, "job-read", policy)
+
+	// Non-management tokens are rejected
+	resp, _ := testOperatorSnapshotSave(t, s1, &structs.SnapshotSaveRequest{
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: token.SecretID},
+	})
+	require.Equal(403, resp.ErrorCode)
+	require.Contains(resp.ErrorMsg, structs.ErrPermissionDenied.Error())
+
+	restoreResp := testOperatorSnapshotRestore(t, s1, &structs.SnapshotRestoreRequest{
+		WriteRequest: structs.WriteRequest{Region: "global", AuthToken: token.SecretID},
+	}, nil)
+	require.Equal(403, restoreResp.ErrorCode)
+
+	// Management tokens are allowed
+	resp, archive := testOperatorSnapshotSave(t, s1, &structs.SnapshotSaveRequest{
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: root.SecretID},
+	})
+	require.Zero(resp.ErrorCode, resp.ErrorMsg)
+	require.NotEmpty(archive)
+}
+
+func TestOperator_GCConfiguration(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// Unset thresholds are returned as zero
+	get := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    s1.config.Region,
+			AuthToken: root.SecretID,
+		},
+	}
+	var reply structs.GCConfigurationResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.GCGetConfiguration", &get, &reply))
+	require.NotNil(reply.GCConfig)
+	require.Zero(reply.GCConfig.EvalGCThreshold)
+
+	// Setting the config requires operator write
+	token := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+	set := structs.GCSetConfigRequest{
+		Config: structs.GCConfiguration{
+			EvalGCThreshold: 10 * time.Minute,
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    s1.config.Region,
+			AuthToken: token.SecretID,
+		},
+	}
+	var setReply structs.GCSetConfigurationResponse
+	err := msgpackrpc.CallWithCodec(codec, "Operator.GCSetConfiguration", &set, &setReply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Negative thresholds are rejected
+	set.AuthToken = root.SecretID
+	set.Config.JobGCThreshold = -time.Minute
+	err = msgpackrpc.CallWithCodec(codec, "Operator.GCSetConfiguration", &set, &setReply)
+	require.Error(err)
+	require.Contains(err.Error(), "job GC threshold must not be negative")
+
+	set.Config.JobGCThreshold = 0
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.GCSetConfiguration", &set, &setReply))
+	require.NotZero(setReply.Index)
+
+	reply = structs.GCConfigurationResponse{}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.GCGetConfiguration", &get, &reply))
+	require.Equal(setReply.Index, reply.Index)
+	require.Equal(10*time.Minute, reply.GCConfig.EvalGCThreshold)
+}
+
+func TestOperator_ClusterHealth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// Register a node that has been marked down
+	node := mock.Node()
+	node.Status = structs.NodeStatusDown
+	require.NoError(state.UpsertNode(1000, node))
+
+	arg := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region: s1.config.Region,
+		},
+	}
+
+	// Reading the health requires operator read
+	token := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+	arg.AuthToken = token.SecretID
+	var reply structs.ClusterHealthResponse
+	err := msgpackrpc.CallWithCodec(codec, "Operator.ClusterHealth", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	arg.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.ClusterHealth", &arg, &reply))
+
+	// Without Raft protocol 3 the health is based on the Serf status
+	require.False(reply.Autopilot)
+	require.True(reply.Healthy)
+	require.Zero(reply.FailureTolerance)
+	require.Len(reply.Servers, 1)
+	server := reply.Servers[0]
+	require.Equal(fmt.Sprintf("%v.%v", s1.config.NodeName, s1.config.Region), server.Name)
+	require.Equal("alive", server.SerfStatus)
+	require.True(server.Leader)
+	require.True(server.Voter)
+	require.True(server.Healthy)
+	require.Equal([]string{server.Version}, reply.Versions)
+	require.False(reply.VersionSkew)
+	require.Equal(1, reply.DownNodes)
+	require.NotZero(reply.Index)
+}
+
+func TestOperator_ClusterHealth_Autopilot(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, func(c *Config) {
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	arg := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region: s1.config.Region,
+		},
+	}
+	testutil.WaitForResult(func() (bool, error) {
+		var reply structs.ClusterHealthResponse
+		if err := msgpackrpc.CallWithCodec(codec, "Operator.ClusterHealth", &arg, &reply); err != nil {
+			return false, err
+		}
+		if !reply.Autopilot || !reply.Healthy || len(reply.Servers) != 1 {
+			return false, fmt.Errorf("bad: %#v", reply)
+		}
+		if server := reply.Servers[0]; !server.Healthy || server.StableSince.IsZero() {
+			return false, fmt.Errorf("bad server: %#v", server)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
@@ -26,12 +26,31 @@ type PeriodicDispatch struct {
 	tracked map[structs.NamespacedID]*structs.Job
 	heap    *periodicHeap
 
+	// queued is the launch time of the launch deferred for each job whose
+	// overlap policy is enqueue, and enqueueInterval how often the running
+	// children of those jobs are checked.
+	queued          map[structs.NamespacedID]time.Time
+	enqueueInterval time.Duration
+
+	// history is the recent launches of each tracked job.
+	history map[structs.NamespacedID][]*structs.PeriodicLaunchEvent
+
 	updateCh chan struct{}
+	ctx      context.Context
 	stopFn   context.CancelFunc
 	logger   log.Logger
 	l        sync.RWMutex
 }
 
+const (
+	// periodicHistoryLimit is the number of launch events kept per job.
+	periodicHistoryLimit = 50
+
+	// periodicEnqueueInterval is how often the running children of a job with
+	// a queued launch are checked.
+	periodicEnqueueInterval = 5 * time.Second
+)
+
 // JobEvalDispatcher is an interface to submit jobs and have evaluations created
 // for them.
 type JobEvalDispatcher interface {
@@ -41,6 +60,10 @@ type JobEvalDispatcher interface {
 
 	// RunningChildren returns whether the passed job has any running children.
 	RunningChildren(job *structs.Job) (bool, error)
+
+	// StopRunningChildren stops the running children of the passed job and
+	// returns their IDs.
+	StopRunningChildren(job *structs.Job) ([]string, error)
 }
 
 // DispatchJob creates an evaluation for the passed job and commits both the
@@ -93,19 +116,73 @@ func (s *Server) DispatchJob(job *structs.Job) (*structs.Evaluation, error) {
 
 // RunningChildren checks whether the passed job has any running children.
 func (s *Server) RunningChildren(job *structs.Job) (bool, error) {
-	state, err := s.fsm.State().Snapshot()
+	children, err := s.runningChildren(job)
 	if err != nil {
 		return false, err
 	}
+	return len(children) != 0, nil
+}
+
+// StopRunningChildren stops the running children of the passed job, creating
+// an evaluation for each, and returns their IDs.
+func (s *Server) StopRunningChildren(job *structs.Job) ([]string, error) {
+	children, err := s.runningChildren(job)
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := make([]string, 0, len(children))
+	for _, child := range children {
+		req := structs.JobDeregisterRequest{
+			JobID: child.ID,
+			WriteRequest: structs.WriteRequest{
+				Namespace: child.Namespace,
+			},
+		}
+		_, index, err := s.raftApply(structs.JobDeregisterRequestType, req)
+		if err != nil {
+			return stopped, err
+		}
+
+		eval := &structs.Evaluation{
+			ID:             uuid.Generate(),
+			Namespace:      child.Namespace,
+			Priority:       child.Priority,
+			Type:           child.Type,
+			TriggeredBy:    structs.EvalTriggerJobDeregister,
+			JobID:          child.ID,
+			JobModifyIndex: index,
+			Status:         structs.EvalStatusPending,
+		}
+		update := &structs.EvalUpdateRequest{
+			Evals: []*structs.Evaluation{eval},
+		}
+		if _, _, err := s.raftApply(structs.EvalUpdateRequestType, update); err != nil {
+			return stopped, err
+		}
+		stopped = append(stopped, child.ID)
+	}
+	return stopped, nil
+}
+
+// runningChildren returns the children of the passed job that have active
+// evaluations or running allocations.
+func (s *Server) runningChildren(job *structs.Job) ([]*structs.Job, error) {
+	state, err := s.fsm.State().Snapshot()
+	if err != nil {
+		return nil, err
+	}
 
 	ws := memdb.NewWatchSet()
 	prefix := fmt.Sprintf("%s%s", job.ID, structs.PeriodicLaunchSuffix)
 	iter, err := state.JobsByIDPrefix(ws, job.Namespace, prefix)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
+	var running []*structs.Job
 	var child *structs.Job
+CHILDREN:
 	for i := iter.Next(); i != nil; i = iter.Next() {
 		child = i.(*structs.Job)
 
@@ -117,41 +194,45 @@ func (s *Server) RunningChildren(job *structs.Job) (bool, error) {
 		// Get the childs evaluations.
 		evals, err := state.EvalsByJob(ws, child.Namespace, child.ID)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		// Check if any of the evals are active or have running allocations.
 		for _, eval := range evals {
 			if !eval.TerminalStatus() {
-				return true, nil
+				running = append(running, child)
+				continue CHILDREN
 			}
 
 			allocs, err := state.AllocsByEval(ws, eval.ID)
 			if err != nil {
-				return false, err
+				return nil, err
 			}
 
 			for _, alloc := range allocs {
 				if !alloc.TerminalStatus() {
-					return true, nil
+					running = append(running, child)
+					continue CHILDREN
 				}
 			}
 		}
 	}
 
-	// There are no evals or allocations that aren't terminal.
-	return false, nil
+	return running, nil
 }
 
 // NewPeriodicDispatch returns a periodic dispatcher that is used to track and
 // launch periodic jobs.
 func NewPeriodicDispatch(logger log.Logger, dispatcher JobEvalDispatcher) *PeriodicDispatch {
 	return &PeriodicDispatch{
-		dispatcher: dispatcher,
-		tracked:    make(map[structs.NamespacedID]*structs.Job),
-		heap:       NewPeriodicHeap(),
-		updateCh:   make(chan struct{}, 1),
-		logger:     logger.Named("periodic"),
+		dispatcher:      dispatcher,
+		tracked:         make(map[structs.NamespacedID]*structs.Job),
+		heap:            NewPeriodicHeap(),
+		queued:          make(map[structs.NamespacedID]time.Time),
+		enqueueInterval: periodicEnqueueInterval,
+		history:         make(map[structs.NamespacedID][]*structs.PeriodicLaunchEvent),
+		updateCh:        make(chan struct{}, 1),
+		logger:          logger.Named("periodic"),
 	}
 }
 
@@ -172,6 +253,7 @@ func (p *PeriodicDispatch) SetEnabled(enabled bool) {
 	} else if enabled && !wasRunning {
 		// If we are transitioning from disabled to enabled, run the daemon.
 		ctx, cancel := context.WithCancel(context.Background())
+		p.ctx = ctx
 		p.stopFn = cancel
 		go p.run(ctx, p.updateCh)
 	}
@@ -272,6 +354,8 @@ func (p *PeriodicDispatch) removeLocked(jobID structs.NamespacedID) error {
 	}
 
 	delete(p.tracked, jobID)
+	delete(p.queued, jobID)
+	delete(p.history, jobID)
 	if err := p.heap.Remove(job); err != nil {
 		return fmt.Errorf("failed to remove tracked job %q (%s): %v", jobID.ID, jobID.Namespace, err)
 	}
@@ -308,7 +392,19 @@ func (p *PeriodicDispatch) ForceRun(namespace, jobID string) (*structs.Evaluatio
 	}
 
 	p.l.Unlock()
-	return p.createEval(job, time.Now().In(job.Periodic.GetLocation()))
+	return p.launch(job, time.Now().In(job.Periodic.GetLocation()), structs.PeriodicLaunchForced, nil)
+}
+
+// History returns the recent launches of the job, from oldest to newest. The
+// history is only kept for tracked jobs, by the leader.
+func (p *PeriodicDispatch) History(namespace, jobID string) []*structs.PeriodicLaunchEvent {
+	p.l.RLock()
+	defer p.l.RUnlock()
+
+	history := p.history[structs.NamespacedID{ID: jobID, Namespace: namespace}]
+	out := make([]*structs.PeriodicLaunchEvent, len(history))
+	copy(out, history)
+	return out
 }
 
 // shouldRun returns whether the long lived run function should run.
@@ -356,8 +452,9 @@ func (p *PeriodicDispatch) dispatch(job *structs.Job, launchTime time.Time) {
 	}
 
 	// If the job prohibits overlapping and there are running children, we skip
-	// the launch.
-	if job.Periodic.ProhibitOverlap {
+	// or queue the launch.
+	policy := job.Periodic.GetOverlapPolicy()
+	if policy == structs.PeriodicOverlapSkip || policy == structs.PeriodicOverlapEnqueue {
 		running, err := p.dispatcher.RunningChildren(job)
 		if err != nil {
 			p.logger.Error("failed to determine if periodic job has running children", "job", job.NamespacedID(), "error", err)
@@ -365,8 +462,23 @@ func (p *PeriodicDispatch) dispatch(job *structs.Job, launchTime time.Time) {
 			return
 		}
 
-		if running {
+		// A queued launch is launched once the children finish, so launching
+		// now would overlap with it.
+		if _, queued := p.queued[*job.NamespacedID()]; queued {
+			running = true
+		}
+
+		if running && policy == structs.PeriodicOverlapSkip {
 			p.logger.Debug("skipping launch of periodic job because job prohibits overlap", "job", job.NamespacedID())
+			p.recordLocked(job, &structs.PeriodicLaunchEvent{
+				Type:       structs.PeriodicLaunchSkipped,
+				LaunchTime: launchTime,
+				Message:    "previous launch is still running",
+			})
+			p.l.Unlock()
+			return
+		} else if running {
+			p.enqueueLocked(job, launchTime)
 			p.l.Unlock()
 			return
 		}
@@ -374,7 +486,138 @@ func (p *PeriodicDispatch) dispatch(job *structs.Job, launchTime time.Time) {
 
 	p.logger.Debug(" launching job", "job", job.NamespacedID(), "launch_time", launchTime)
 	p.l.Unlock()
-	p.createEval(job, launchTime)
+
+	// Stopping the children must happen without the lock held as it goes
+	// through Raft, which tracks and untracks jobs.
+	var stopped []string
+	if policy == structs.PeriodicOverlapCancelRunning {
+		var err error
+		stopped, err = p.dispatcher.StopRunningChildren(job)
+		if err != nil {
+			p.logger.Error("failed to stop running children of periodic job", "job", job.NamespacedID(), "error", err)
+			p.record(job, &structs.PeriodicLaunchEvent{
+				Type:          structs.PeriodicLaunchSkipped,
+				LaunchTime:    launchTime,
+				StoppedJobIDs: stopped,
+				Message:       fmt.Sprintf("failed to stop running children: %v", err),
+			})
+			return
+		}
+	}
+
+	p.launch(job, launchTime, structs.PeriodicLaunchFired, stopped)
+}
+
+// enqueueLocked defers the launch of the job until its running children
+// finish. If a launch is already queued, the launch is skipped instead. It
+// assumes this is called while a lock is held.
+func (p *PeriodicDispatch) enqueueLocked(job *structs.Job, launchTime time.Time) {
+	tuple := *job.NamespacedID()
+	if _, queued := p.queued[tuple]; queued {
+		p.logger.Debug("skipping launch of periodic job because a launch is already queued", "job", tuple)
+		p.recordLocked(job, &structs.PeriodicLaunchEvent{
+			Type:       structs.PeriodicLaunchSkipped,
+			LaunchTime: launchTime,
+			Message:    "a launch is already queued",
+		})
+		return
+	}
+
+	p.logger.Debug("queueing launch of periodic job until running children finish", "job", tuple, "launch_time", launchTime)
+	p.queued[tuple] = launchTime
+	p.recordLocked(job, &structs.PeriodicLaunchEvent{
+		Type:       structs.PeriodicLaunchQueued,
+		LaunchTime: launchTime,
+		Message:    "waiting for the previous launch to finish",
+	})
+	go p.runQueued(p.ctx, tuple, launchTime)
+}
+
+// runQueued waits for the running children of the job to finish and launches
+// the queued launch. It stops waiting if the job is no longer tracked.
+func (p *PeriodicDispatch) runQueued(ctx context.Context, tuple structs.NamespacedID, launchTime time.Time) {
+	ticker := time.NewTicker(p.enqueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		p.l.Lock()
+		job, tracked := p.tracked[tuple]
+		queuedTime, queued := p.queued[tuple]
+		if !tracked || !queued || !queuedTime.Equal(launchTime) {
+			p.l.Unlock()
+			return
+		}
+
+		running, err := p.dispatcher.RunningChildren(job)
+		if err != nil {
+			p.logger.Error("failed to determine if periodic job has running children", "job", tuple, "error", err)
+		}
+		if err != nil || running {
+			p.l.Unlock()
+			continue
+		}
+
+		delete(p.queued, tuple)
+		p.logger.Debug(" launching queued job", "job", tuple, "launch_time", launchTime)
+		p.l.Unlock()
+
+		p.launch(job, launchTime, structs.PeriodicLaunchFired, nil)
+		return
+	}
+}
+
+// launch creates an evaluation for the job and records the launch in the
+// history of the job. This should not be called with the lock held.
+func (p *PeriodicDispatch) launch(job *structs.Job, launchTime time.Time, eventType string, stopped []string) (*structs.Evaluation, error) {
+	event := &structs.PeriodicLaunchEvent{
+		Type:          eventType,
+		LaunchTime:    launchTime,
+		StoppedJobIDs: stopped,
+	}
+
+	eval, err := p.createEval(job, launchTime)
+	if err != nil {
+		event.Type = structs.PeriodicLaunchSkipped
+		event.Message = fmt.Sprintf("failed to launch: %v", err)
+	} else {
+		event.ChildJobID = p.derivedJobID(job, launchTime)
+		if eval != nil {
+			event.EvalID = eval.ID
+		}
+	}
+
+	p.record(job, event)
+	return eval, err
+}
+
+// record adds the event to the history of the job.
+func (p *PeriodicDispatch) record(job *structs.Job, event *structs.PeriodicLaunchEvent) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.recordLocked(job, event)
+}
+
+// recordLocked adds the event to the history of the job, dropping the oldest
+// events past the limit. It assumes this is called while a lock is held.
+func (p *PeriodicDispatch) recordLocked(job *structs.Job, event *structs.PeriodicLaunchEvent) {
+	tuple := *job.NamespacedID()
+
+	// Don't keep history for jobs that have been untracked.
+	if _, tracked := p.tracked[tuple]; !tracked {
+		return
+	}
+
+	history := append(p.history[tuple], event)
+	if len(history) > periodicHistoryLimit {
+		history = history[len(history)-periodicHistoryLimit:]
+	}
+	p.history[tuple] = history
 }
 
 // nextLaunch returns the next job to launch and when it should be launched. If
@@ -468,6 +711,9 @@ func (p *PeriodicDispatch) flush() {
 	p.updateCh = make(chan struct{}, 1)
 	p.tracked = make(map[structs.NamespacedID]*structs.Job)
 	p.heap = NewPeriodicHeap()
+	p.queued = make(map[structs.NamespacedID]time.Time)
+	p.history = make(map[structs.NamespacedID][]*structs.PeriodicLaunchEvent)
+	p.ctx = nil
 	p.stopFn = nil
 }
 
@@ -28,7 +28,7 @@ func (p *Periodic) Force(args *structs.PeriodicForceRequest, reply *structs.Peri
 	// Check for write-job permissions
 	if aclObj, err := p.srv.ResolveToken(args.AuthToken); err != nil {
 		return err
-	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilitySubmitJob) {
 		return structs.ErrPermissionDenied
 	}
 
@@ -67,3 +67,56 @@ func (p *Periodic) Force(args *structs.PeriodicForceRequest, reply *structs.Peri
 	reply.Index = eval.CreateIndex
 	return nil
 }
+
+// History is used to read the recent launches of a periodic job, including
+// the launches skipped or queued because of its overlap policy. The history
+// is kept in memory by the leader, so it is reset when leadership changes.
+func (p *Periodic) History(args *structs.PeriodicHistoryRequest, reply *structs.PeriodicHistoryResponse) error {
+	// Only the leader knows the history
+	args.AllowStale = false
+	if done, err := p.srv.forward("Periodic.History", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "periodic", "history"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := p.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobOp(args.RequestNamespace(), args.JobID, acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID for periodic history")
+	}
+
+	// Lookup the job
+	snap, err := p.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	ws := memdb.NewWatchSet()
+	job, err := snap.JobByID(ws, args.RequestNamespace(), args.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job not found")
+	}
+
+	if !job.IsPeriodic() {
+		return fmt.Errorf("can't read history of non-periodic job")
+	}
+
+	index, err := snap.Index("periodic_launch")
+	if err != nil {
+		return err
+	}
+
+	reply.Launches = p.srv.periodicDispatcher.History(args.RequestNamespace(), job.ID)
+	reply.Index = index
+	p.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
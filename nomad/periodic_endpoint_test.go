@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPeriodicEndpoint_Force(t *testing.T) {
@@ -173,3 +174,55 @@ func TestPeriodicEndpoint_Force_NonPeriodic(t *testing.T) {
 		t.Fatalf("Force on non-periodic job should err")
 	}
 }
+
+func TestPeriodicEndpoint_History(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	state := s1.fsm.State()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create, insert and force launch a periodic job.
+	job := mock.PeriodicJob()
+	require.Nil(state.UpsertJob(100, job))
+	require.Nil(s1.periodicDispatcher.Add(job))
+	eval, err := s1.periodicDispatcher.ForceRun(job.Namespace, job.ID)
+	require.Nil(err)
+
+	req := &structs.PeriodicHistoryRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Try with no token and expect permission denied
+	var resp structs.PeriodicHistoryResponse
+	err = msgpackrpc.CallWithCodec(codec, "Periodic.History", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), structs.ErrPermissionDenied.Error())
+
+	// Fetch the history with a read-job token
+	policy := mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob})
+	token := mock.CreatePolicyAndToken(t, state, 1003, "valid", policy)
+	req.AuthToken = token.SecretID
+	require.Nil(msgpackrpc.CallWithCodec(codec, "Periodic.History", req, &resp))
+	require.Len(resp.Launches, 1)
+	require.Equal(structs.PeriodicLaunchForced, resp.Launches[0].Type)
+	require.Equal(eval.ID, resp.Launches[0].EvalID)
+	require.Equal(eval.JobID, resp.Launches[0].ChildJobID)
+
+	// Non-periodic jobs have no history
+	other := mock.Job()
+	require.Nil(state.UpsertJob(1004, other))
+	req.AuthToken = root.SecretID
+	req.JobID = other.ID
+	err = msgpackrpc.CallWithCodec(codec, "Periodic.History", req, &resp)
+	require.NotNil(err)
+	require.Contains(err.Error(), "non-periodic")
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MockJobEvalDispatcher struct {
@@ -49,6 +50,30 @@ func (m *MockJobEvalDispatcher) RunningChildren(parent *structs.Job) (bool, erro
 	return false, nil
 }
 
+func (m *MockJobEvalDispatcher) StopRunningChildren(parent *structs.Job) ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var stopped []string
+	for tuple, job := range m.Jobs {
+		if job.ParentID == parent.ID && job.Namespace == parent.Namespace {
+			delete(m.Jobs, tuple)
+			stopped = append(stopped, job.ID)
+		}
+	}
+	return stopped, nil
+}
+
+// FinishChildren removes the children of the parent, as if they had finished.
+func (m *MockJobEvalDispatcher) FinishChildren(parent *structs.Job) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for tuple, job := range m.Jobs {
+		if job.ParentID == parent.ID && job.Namespace == parent.Namespace {
+			delete(m.Jobs, tuple)
+		}
+	}
+}
+
 // LaunchTimes returns the launch times of child jobs in sorted order.
 func (m *MockJobEvalDispatcher) LaunchTimes(p *PeriodicDispatch, namespace, parentID string) ([]time.Time, error) {
 	m.lock.Lock()
@@ -414,6 +439,118 @@ func TestPeriodicDispatch_Run_DisallowOverlaps(t *testing.T) {
 	}
 }
 
+func TestPeriodicDispatch_Run_CancelRunning(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	p, m := testPeriodicDispatcher(t)
+
+	// Create a job that will trigger two launches and cancels running
+	// children.
+	launch1 := time.Now().Round(1 * time.Second).Add(1 * time.Second)
+	launch2 := time.Now().Round(1 * time.Second).Add(2 * time.Second)
+	job := testPeriodicJob(launch1, launch2)
+	job.Periodic.OverlapPolicy = structs.PeriodicOverlapCancelRunning
+	require.NoError(p.Add(job))
+
+	time.Sleep(3 * time.Second)
+
+	// Only the second launch is still running.
+	times, err := m.LaunchTimes(p, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Equal([]time.Time{launch2}, times)
+
+	// Both launches fired and the second stopped the first.
+	history := p.History(job.Namespace, job.ID)
+	require.Len(history, 2)
+	require.Equal(structs.PeriodicLaunchFired, history[0].Type)
+	require.Equal(p.derivedJobID(job, launch1), history[0].ChildJobID)
+	require.Empty(history[0].StoppedJobIDs)
+	require.Equal(structs.PeriodicLaunchFired, history[1].Type)
+	require.Equal([]string{p.derivedJobID(job, launch1)}, history[1].StoppedJobIDs)
+}
+
+func TestPeriodicDispatch_Run_Enqueue(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	p, m := testPeriodicDispatcher(t)
+	p.enqueueInterval = 50 * time.Millisecond
+
+	// Create a job that will trigger three launches while the first is
+	// running.
+	launch1 := time.Now().Round(1 * time.Second).Add(1 * time.Second)
+	launch2 := time.Now().Round(1 * time.Second).Add(2 * time.Second)
+	launch3 := time.Now().Round(1 * time.Second).Add(3 * time.Second)
+	job := testPeriodicJob(launch1, launch2, launch3)
+	job.Periodic.OverlapPolicy = structs.PeriodicOverlapEnqueue
+	require.NoError(p.Add(job))
+
+	time.Sleep(4 * time.Second)
+
+	// The second launch is queued and the third skipped.
+	times, err := m.LaunchTimes(p, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Equal([]time.Time{launch1}, times)
+
+	history := p.History(job.Namespace, job.ID)
+	require.Len(history, 3)
+	require.Equal(structs.PeriodicLaunchFired, history[0].Type)
+	require.Equal(structs.PeriodicLaunchQueued, history[1].Type)
+	require.Equal(launch2, history[1].LaunchTime)
+	require.Equal(structs.PeriodicLaunchSkipped, history[2].Type)
+	require.Equal(launch3, history[2].LaunchTime)
+
+	// Once the first launch finishes, the queued launch is launched.
+	m.FinishChildren(job)
+	testutil.WaitForResult(func() (bool, error) {
+		times, err := m.LaunchTimes(p, job.Namespace, job.ID)
+		if err != nil {
+			return false, err
+		}
+		if len(times) != 1 || times[0] != launch2 {
+			return false, fmt.Errorf("got launches %v; want %v", times, launch2)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	testutil.WaitForResult(func() (bool, error) {
+		history := p.History(job.Namespace, job.ID)
+		if len(history) != 4 {
+			return false, fmt.Errorf("got %d events; want 4", len(history))
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	history = p.History(job.Namespace, job.ID)
+	require.Equal(structs.PeriodicLaunchFired, history[3].Type)
+	require.Equal(p.derivedJobID(job, launch2), history[3].ChildJobID)
+}
+
+func TestPeriodicDispatch_History(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	p, _ := testPeriodicDispatcher(t)
+
+	job := mock.PeriodicJob()
+	require.NoError(p.Add(job))
+
+	// Forced launches are recorded, up to the limit.
+	for i := 0; i < periodicHistoryLimit+1; i++ {
+		_, err := p.ForceRun(job.Namespace, job.ID)
+		require.NoError(err)
+	}
+	history := p.History(job.Namespace, job.ID)
+	require.Len(history, periodicHistoryLimit)
+	require.Equal(structs.PeriodicLaunchForced, history[0].Type)
+	require.NotEmpty(history[0].ChildJobID)
+
+	// The history is dropped with the job.
+	require.NoError(p.Remove(job.Namespace, job.ID))
+	require.Empty(p.History(job.Namespace, job.ID))
+}
+
 func TestPeriodicDispatch_Run_Multiple(t *testing.T) {
 	t.Parallel()
 	p, m := testPeriodicDispatcher(t)
@@ -0,0 +1,149 @@
+package nomad
+
+import (
+	"sort"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// placementFailures returns the placement failures of the blocked evaluations
+// returned by the iterator, sorted by job and task group.
+func placementFailures(ws memdb.WatchSet, state *state.StateStore, iter memdb.ResultIterator) ([]*structs.PlacementFailure, error) {
+	var failures []*structs.PlacementFailure
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		eval := raw.(*structs.Evaluation)
+		if eval.Status != structs.EvalStatusBlocked {
+			continue
+		}
+
+		// Blocked evaluations record their failures when they are reblocked,
+		// otherwise they are recorded by the evaluation that created them.
+		failed := eval.FailedTGAllocs
+		if len(failed) == 0 && eval.PreviousEval != "" {
+			prev, err := state.EvalByID(ws, eval.PreviousEval)
+			if err != nil {
+				return nil, err
+			}
+			if prev != nil {
+				failed = prev.FailedTGAllocs
+			}
+		}
+
+		for tg, metric := range failed {
+			failures = append(failures, &structs.PlacementFailure{
+				Namespace: eval.Namespace,
+				JobID:     eval.JobID,
+				TaskGroup: tg,
+				EvalID:    eval.ID,
+				Unplaced:  metric.CoalescedFailures + 1,
+				Metrics:   metric,
+			})
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		a, b := failures[i], failures[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.JobID != b.JobID {
+			return a.JobID < b.JobID
+		}
+		return a.TaskGroup < b.TaskGroup
+	})
+	return failures, nil
+}
+
+// placementFailureGauge is a gauge of the placement failure metrics.
+type placementFailureGauge struct {
+	name  string
+	label string
+	value string
+}
+
+// placementFailureGauges sums the placement failures into gauges by reason.
+func placementFailureGauges(failures []*structs.PlacementFailure) map[placementFailureGauge]float32 {
+	gauges := make(map[placementFailureGauge]float32)
+	add := func(name, label string, counts map[string]int) {
+		for value, count := range counts {
+			gauges[placementFailureGauge{name, label, value}] += float32(count)
+		}
+	}
+
+	for _, f := range failures {
+		gauges[placementFailureGauge{name: "unplaced"}] += float32(f.Unplaced)
+
+		m := f.Metrics
+		add("dimension_exhausted", "dimension", m.DimensionExhausted)
+		add("class_exhausted", "node_class", m.ClassExhausted)
+		add("class_filtered", "node_class", m.ClassFiltered)
+		add("constraint_filtered", "constraint", m.ConstraintFiltered)
+		for _, dimension := range m.QuotaExhausted {
+			gauges[placementFailureGauge{"quota_exhausted", "dimension", dimension}]++
+		}
+	}
+	return gauges
+}
+
+// publishPlacementFailureMetrics publishes the placement failures of the
+// blocked evaluations as metrics
+func (s *Server) publishPlacementFailureMetrics(stopCh chan struct{}) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	// Gauges that are no longer reported are zeroed so that they don't keep
+	// their last value
+	var prev map[placementFailureGauge]float32
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C:
+			timer.Reset(s.config.StatsCollectionInterval)
+			state := s.State()
+			ws := memdb.NewWatchSet()
+			iter, err := state.Evals(ws)
+			if err != nil {
+				s.logger.Error("failed to get evaluations", "error", err)
+				continue
+			}
+			failures, err := placementFailures(ws, state, iter)
+			if err != nil {
+				s.logger.Error("failed to get placement failures", "error", err)
+				continue
+			}
+
+			gauges := placementFailureGauges(failures)
+			for g := range prev {
+				if _, ok := gauges[g]; !ok {
+					gauges[g] = 0
+				}
+			}
+			for g, v := range gauges {
+				key := []string{"nomad", "placement_failures", g.name}
+				if g.label == "" {
+					metrics.SetGauge(key, v)
+					continue
+				}
+				metrics.SetGaugeWithLabels(key, v, []metrics.Label{{Name: g.label, Value: g.value}})
+			}
+
+			// Only keep the gauges that are still reported
+			prev = make(map[placementFailureGauge]float32, len(gauges))
+			for g, v := range gauges {
+				if v != 0 {
+					prev[g] = v
+				}
+			}
+		}
+	}
+}
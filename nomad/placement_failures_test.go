@@ -0,0 +1,42 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementFailureGauges(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	failures := []*structs.PlacementFailure{
+		{
+			Unplaced: 3,
+			Metrics: &structs.AllocMetric{
+				DimensionExhausted: map[string]int{"memory": 2, "cpu": 1},
+				ClassExhausted:     map[string]int{"large": 2},
+				ClassFiltered:      map[string]int{"gpu": 4},
+			},
+		},
+		{
+			Unplaced: 1,
+			Metrics: &structs.AllocMetric{
+				DimensionExhausted: map[string]int{"memory": 1},
+				ConstraintFiltered: map[string]int{"${attr.kernel.name} = linux": 5},
+				QuotaExhausted:     []string{"memory exhausted (1024 > 512)"},
+			},
+		},
+	}
+
+	require.Equal(map[placementFailureGauge]float32{
+		{name: "unplaced"}: 4,
+		{"dimension_exhausted", "dimension", "memory"}:                       3,
+		{"dimension_exhausted", "dimension", "cpu"}:                          1,
+		{"class_exhausted", "node_class", "large"}:                           2,
+		{"class_filtered", "node_class", "gpu"}:                              4,
+		{"constraint_filtered", "constraint", "${attr.kernel.name} = linux"}: 5,
+		{"quota_exhausted", "dimension", "memory exhausted (1024 > 512)"}:    1,
+	}, placementFailureGauges(failures))
+}
@@ -9,6 +9,7 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -41,6 +42,10 @@ func newPlanner(s *Server) (*planner, error) {
 	}, nil
 }
 
+// maxPlanBatchSize is the maximum number of plans that are evaluated together
+// and committed in a single Raft transaction.
+const maxPlanBatchSize = 64
+
 // planApply is a long lived goroutine that reads plan allocations from
 // the plan queue, determines if they can be applied safely and applies
 // them via Raft.
@@ -51,8 +56,8 @@ func newPlanner(s *Server) (*planner, error) {
 // not evaluating, but simply waiting for a transaction to apply.
 //
 // To avoid this, we overlap verification with apply. This means once
-// we've verified plan N we attempt to apply it. However, while waiting
-// for apply, we begin to verify plan N+1 under the assumption that plan
+// we've verified batch N we attempt to apply it. However, while waiting
+// for apply, we begin to verify batch N+1 under the assumption that batch
 // N has succeeded.
 //
 // In this sense, we track two parallel versions of the world. One is
@@ -67,6 +72,15 @@ func newPlanner(s *Server) (*planner, error) {
 // the Raft log is updated. This means our schedulers will stall,
 // but there are many of those and only a single plan verifier.
 //
+// When many schedulers submit plans at once, such as during a burst of
+// batch job registrations, the cost of a Raft apply per plan dominates.
+// To amortize it, every plan waiting in the queue is dequeued together
+// and evaluated in order against the optimistic state, with the results
+// of each plan staged so that later plans in the batch observe them. The
+// results of the whole batch are then committed in a single Raft
+// transaction, or in one transaction per plan while any server is older
+// than minPlanBatchVersion.
+//
 func (p *planner) planApply() {
 	// waitCh is used to track an outstanding application while snap
 	// holds an optimistic state which includes that plan application.
@@ -82,8 +96,8 @@ func (p *planner) planApply() {
 	defer pool.Shutdown()
 
 	for {
-		// Pull the next pending plan, exit if we are no longer leader
-		pending, err := p.planQueue.Dequeue(0)
+		// Pull the next pending plans, exit if we are no longer leader
+		batch, err := p.planQueue.DequeueBatch(0, maxPlanBatchSize)
 		if err != nil {
 			return
 		}
@@ -102,60 +116,179 @@ func (p *planner) planApply() {
 			snap, err = p.fsm.State().Snapshot()
 			if err != nil {
 				p.logger.Error("failed to snapshot state", "error", err)
-				pending.respond(nil, err)
+				for _, pending := range batch {
+					pending.respond(nil, err)
+				}
 				continue
 			}
 		}
 
-		// Evaluate the plan
-		result, err := evaluatePlan(pool, snap, pending.plan, p.logger)
-		if err != nil {
-			p.logger.Error("failed to evaluate plan", "error", err)
-			pending.respond(nil, err)
-			continue
-		}
-
-		// Fast-path the response if there is nothing to do
-		if result.IsNoOp() {
-			pending.respond(result, nil)
+		// Evaluate the plans, staging the results of each so that the
+		// following plans are evaluated against them
+		applied := p.evaluateBatch(pool, snap, batch)
+		if len(applied) == 0 {
 			continue
 		}
 
 		// Ensure any parallel apply is complete before starting the next one.
-		// This also limits how out of date our snapshot can be.
+		// This also limits how out of date our snapshot can be. The
+		// optimistic results are applied to the new snapshot once the batch
+		// is submitted.
+		var applySnap *state.StateSnapshot
 		if waitCh != nil {
 			<-waitCh
 			snap, err = p.fsm.State().Snapshot()
 			if err != nil {
 				p.logger.Error("failed to snapshot state", "error", err)
-				pending.respond(nil, err)
+				for _, a := range applied {
+					a.span.SetError(err)
+					a.span.End()
+					a.pending.respond(nil, err)
+				}
 				continue
 			}
+			applySnap = snap
 		}
 
-		// Dispatch the Raft transaction for the plan
-		future, err := p.applyPlan(pending.plan, result, snap)
+		// Dispatch the Raft transaction for the batch
+		reqs := make([]*structs.ApplyPlanResultsRequest, 0, len(applied))
+		for _, a := range applied {
+			reqs = append(reqs, a.req)
+		}
+		metrics.AddSample([]string{"nomad", "plan", "batch_size"}, float32(len(reqs)))
+		future, err := p.applyPlanResults(reqs, applySnap)
 		if err != nil {
 			p.logger.Error("failed to submit plan", "error", err)
-			pending.respond(nil, err)
+			for _, a := range applied {
+				a.span.SetError(err)
+				a.span.End()
+				a.pending.respond(nil, err)
+			}
+
+			// The snapshot may hold the staged results of the failed batch
+			snap = nil
 			continue
 		}
 
-		// Respond to the plan in async
+		// Respond to the plans in async
 		waitCh = make(chan struct{})
-		go p.asyncPlanWait(waitCh, future, result, pending)
+		go p.asyncPlanWait(waitCh, future, applied)
+	}
+}
+
+// appliedPlan is a plan whose results are part of a pending Raft
+// transaction.
+type appliedPlan struct {
+	pending *pendingPlan
+	result  *structs.PlanResult
+	req     *structs.ApplyPlanResultsRequest
+
+	// span tracks the application of the plan and is ended once the
+	// transaction is applied.
+	span *tracing.Span
+}
+
+// evaluateBatch evaluates the plans of a batch in order against the snapshot.
+// Plans that fail to evaluate or have nothing to apply are responded to
+// directly, while the results of the others are staged in the snapshot and
+// returned so that they can be committed.
+func (p *planner) evaluateBatch(pool *EvaluatePool, snap *state.StateSnapshot, batch []*pendingPlan) []*appliedPlan {
+	applied := make([]*appliedPlan, 0, len(batch))
+	for _, pending := range batch {
+		// Evaluate the plan
+		span := p.tracer.Start("plan.evaluate", pending.plan.EvalID, tracing.SpanKindInternal)
+		span.SetAttribute("eval_id", pending.plan.EvalID)
+		result, err := evaluatePlan(pool, snap, pending.plan, p.logger)
+		span.SetError(err)
+		span.End()
+		if err != nil {
+			p.logger.Error("failed to evaluate plan", "error", err)
+			pending.respond(nil, err)
+			continue
+		}
+
+		// Fast-path the response if there is nothing to do
+		if result.IsNoOp() {
+			pending.respond(result, nil)
+			continue
+		}
+
+		// Stage a copy of the results so that the request is not modified
+		// before it is encoded
+		req := p.planResultsRequest(pending.plan, result)
+		nextIdx := p.raft.AppliedIndex() + 1
+		if err := snap.UpsertPlanResults(nextIdx, stagedPlanResults(req)); err != nil {
+			p.logger.Error("failed to stage plan", "error", err)
+			pending.respond(nil, err)
+			continue
+		}
+
+		span = p.tracer.Start("plan.apply", pending.plan.EvalID, tracing.SpanKindInternal)
+		span.SetAttribute("eval_id", pending.plan.EvalID)
+		applied = append(applied, &appliedPlan{
+			pending: pending,
+			result:  result,
+			req:     req,
+			span:    span,
+		})
 	}
+	return applied
 }
 
 // applyPlan is used to apply the plan result and to return the alloc index
 func (p *planner) applyPlan(plan *structs.Plan, result *structs.PlanResult, snap *state.StateSnapshot) (raft.ApplyFuture, error) {
+	req := p.planResultsRequest(plan, result)
+	return p.applyPlanResults([]*structs.ApplyPlanResultsRequest{req}, snap)
+}
+
+// applyPlanResults dispatches a Raft transaction committing the given plan
+// results and, if a snapshot is given, optimistically applies them to it.
+func (p *planner) applyPlanResults(reqs []*structs.ApplyPlanResultsRequest, snap *state.StateSnapshot) (raft.ApplyFuture, error) {
+	// Dispatch the Raft transaction. A single plan is committed using the
+	// original message type so that the common case does not depend on
+	// the batch request.
+	var future raft.ApplyFuture
+	var err error
+	switch {
+	case len(reqs) == 1:
+		future, err = p.raftApplyFuture(structs.ApplyPlanResultsRequestType, reqs[0])
+	case !ServersMeetMinimumVersion(p.Members(), minPlanBatchVersion):
+		// Servers that predate batching can't apply the batch request, so
+		// commit each plan in its own transaction. Raft applies them in
+		// order, so waiting on the last one covers the whole batch.
+		for _, req := range reqs {
+			future, err = p.raftApplyFuture(structs.ApplyPlanResultsRequestType, req)
+			if err != nil {
+				break
+			}
+		}
+	default:
+		batch := structs.ApplyPlanResultsBatchRequest{Results: reqs}
+		future, err = p.raftApplyFuture(structs.ApplyPlanResultsBatchRequestType, &batch)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Optimistically apply to our state view
+	if snap != nil {
+		nextIdx := p.raft.AppliedIndex() + 1
+		if err := snap.UpsertPlanResultsBatch(nextIdx, reqs); err != nil {
+			return future, err
+		}
+	}
+	return future, nil
+}
+
+// planResultsRequest builds the request committing the result of a plan.
+func (p *planner) planResultsRequest(plan *structs.Plan, result *structs.PlanResult) *structs.ApplyPlanResultsRequest {
 	// Determine the minimum number of updates, could be more if there
 	// are multiple updates per node
 	minUpdates := len(result.NodeUpdate)
 	minUpdates += len(result.NodeAllocation)
 
 	// Setup the update request
-	req := structs.ApplyPlanResultsRequest{
+	req := &structs.ApplyPlanResultsRequest{
 		AllocUpdateRequest: structs.AllocUpdateRequest{
 			Job:   plan.Job,
 			Alloc: make([]*structs.Allocation, 0, minUpdates),
@@ -215,47 +348,61 @@ func (p *planner) applyPlan(plan *structs.Plan, result *structs.PlanResult, snap
 		}
 	}
 	req.PreemptionEvals = evals
+	return req
+}
 
-	// Dispatch the Raft transaction
-	future, err := p.raftApplyFuture(structs.ApplyPlanResultsRequestType, &req)
-	if err != nil {
-		return nil, err
-	}
-
-	// Optimistically apply to our state view
-	if snap != nil {
-		nextIdx := p.raft.AppliedIndex() + 1
-		if err := snap.UpsertPlanResults(nextIdx, &req); err != nil {
-			return future, err
-		}
-	}
-	return future, nil
+// stagedPlanResults returns a copy of the request that can be applied to an
+// optimistic snapshot. Applying the request sets the indexes of the objects
+// it references, which must not leak into the request sent to Raft.
+func stagedPlanResults(req *structs.ApplyPlanResultsRequest) *structs.ApplyPlanResultsRequest {
+	staged := *req
+	staged.Alloc = make([]*structs.Allocation, len(req.Alloc))
+	for i, alloc := range req.Alloc {
+		staged.Alloc[i] = alloc.CopySkipJob()
+	}
+	staged.Deployment = req.Deployment.Copy()
+	staged.PreemptionEvals = make([]*structs.Evaluation, len(req.PreemptionEvals))
+	for i, eval := range req.PreemptionEvals {
+		staged.PreemptionEvals[i] = eval.Copy()
+	}
+	return &staged
 }
 
-// asyncPlanWait is used to apply and respond to a plan async
-func (p *planner) asyncPlanWait(waitCh chan struct{}, future raft.ApplyFuture,
-	result *structs.PlanResult, pending *pendingPlan) {
+// asyncPlanWait is used to apply and respond to a batch of plans async. The
+// spans of the plan applications are ended once the Raft transaction is
+// applied.
+func (p *planner) asyncPlanWait(waitCh chan struct{}, future raft.ApplyFuture, applied []*appliedPlan) {
 	defer metrics.MeasureSince([]string{"nomad", "plan", "apply"}, time.Now())
 	defer close(waitCh)
 
-	// Wait for the plan to apply
-	if err := future.Error(); err != nil {
+	// Wait for the plans to apply
+	err := future.Error()
+	for _, a := range applied {
+		a.span.SetError(err)
+		a.span.End()
+	}
+	if err != nil {
 		p.logger.Error("failed to apply plan", "error", err)
-		pending.respond(nil, err)
+		for _, a := range applied {
+			a.pending.respond(nil, err)
+		}
 		return
 	}
 
-	// Respond to the plan
-	result.AllocIndex = future.Index()
+	for _, a := range applied {
+		// Respond to the plan
+		result := a.result
+		result.AllocIndex = future.Index()
 
-	// If this is a partial plan application, we need to ensure the scheduler
-	// at least has visibility into any placements it made to avoid double placement.
-	// The RefreshIndex computed by evaluatePlan may be stale due to evaluation
-	// against an optimistic copy of the state.
-	if result.RefreshIndex != 0 {
-		result.RefreshIndex = maxUint64(result.RefreshIndex, result.AllocIndex)
+		// If this is a partial plan application, we need to ensure the scheduler
+		// at least has visibility into any placements it made to avoid double placement.
+		// The RefreshIndex computed by evaluatePlan may be stale due to evaluation
+		// against an optimistic copy of the state.
+		if result.RefreshIndex != 0 {
+			result.RefreshIndex = maxUint64(result.RefreshIndex, result.AllocIndex)
+		}
+		a.pending.respond(result, nil)
 	}
-	pending.respond(result, nil)
 }
 
 // evaluatePlan is used to determine what portions of a plan
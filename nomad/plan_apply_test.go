@@ -228,6 +228,172 @@ func TestPlanApply_applyPlan(t *testing.T) {
 	assert.Equal(index, evalOut.ModifyIndex)
 }
 
+// testPendingPlan returns a pending plan placing the alloc on its node.
+func testPendingPlan(t *testing.T, s *Server, alloc *structs.Allocation) *pendingPlan {
+	eval := mock.Eval()
+	eval.JobID = alloc.JobID
+	require.NoError(t, s.State().UpsertEvals(1, []*structs.Evaluation{eval}))
+	require.NoError(t, s.State().UpsertJobSummary(1, mock.JobSummary(alloc.JobID)))
+
+	return &pendingPlan{
+		plan: &structs.Plan{
+			EvalID: eval.ID,
+			Job:    alloc.Job,
+			NodeAllocation: map[string][]*structs.Allocation{
+				alloc.NodeID: {alloc},
+			},
+		},
+		errCh: make(chan error, 1),
+	}
+}
+
+func TestPlanApply_applyPlanResults_Batch(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.Build = "0.9.0+unittest"
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register two nodes and a plan for each
+	var batch []*pendingPlan
+	for i := 0; i < 2; i++ {
+		node := mock.Node()
+		testRegisterNode(t, s1, node)
+
+		alloc := mock.Alloc()
+		alloc.NodeID = node.ID
+		batch = append(batch, testPendingPlan(t, s1, alloc))
+	}
+
+	snap, err := s1.State().Snapshot()
+	require.NoError(err)
+
+	pool := NewEvaluatePool(workerPoolSize, workerPoolBufferSize)
+	defer pool.Shutdown()
+
+	// Both plans should be staged in the snapshot
+	applied := s1.evaluateBatch(pool, snap, batch)
+	require.Len(applied, 2)
+	ws := memdb.NewWatchSet()
+	for _, a := range applied {
+		for _, alloc := range a.req.Alloc {
+			out, err := snap.AllocByID(ws, alloc.ID)
+			require.NoError(err)
+			require.NotNil(out)
+
+			// Staging should not modify the request
+			require.Zero(alloc.CreateIndex)
+		}
+	}
+
+	// Commit the batch in a single transaction
+	reqs := []*structs.ApplyPlanResultsRequest{applied[0].req, applied[1].req}
+	future, err := s1.applyPlanResults(reqs, nil)
+	require.NoError(err)
+	index, err := planWaitFuture(future)
+	require.NoError(err)
+
+	for _, req := range reqs {
+		out, err := s1.fsm.State().AllocByID(ws, req.Alloc[0].ID)
+		require.NoError(err)
+		require.NotNil(out)
+		require.Equal(index, out.CreateIndex)
+
+		evalOut, err := s1.fsm.State().EvalByID(ws, req.EvalID)
+		require.NoError(err)
+		require.Equal(index, evalOut.ModifyIndex)
+	}
+}
+
+func TestPlanApply_applyPlanResults_Batch_MixedVersion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.Build = "0.8.0+unittest"
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register two nodes and a plan for each
+	var batch []*pendingPlan
+	for i := 0; i < 2; i++ {
+		node := mock.Node()
+		testRegisterNode(t, s1, node)
+
+		alloc := mock.Alloc()
+		alloc.NodeID = node.ID
+		batch = append(batch, testPendingPlan(t, s1, alloc))
+	}
+
+	snap, err := s1.State().Snapshot()
+	require.NoError(err)
+
+	pool := NewEvaluatePool(workerPoolSize, workerPoolBufferSize)
+	defer pool.Shutdown()
+
+	applied := s1.evaluateBatch(pool, snap, batch)
+	require.Len(applied, 2)
+
+	// Servers that can't apply the batch request get one transaction per
+	// plan, and the returned future is the last of them
+	reqs := []*structs.ApplyPlanResultsRequest{applied[0].req, applied[1].req}
+	future, err := s1.applyPlanResults(reqs, nil)
+	require.NoError(err)
+	index, err := planWaitFuture(future)
+	require.NoError(err)
+
+	ws := memdb.NewWatchSet()
+	first, err := s1.fsm.State().AllocByID(ws, reqs[0].Alloc[0].ID)
+	require.NoError(err)
+	require.NotNil(first)
+	second, err := s1.fsm.State().AllocByID(ws, reqs[1].Alloc[0].ID)
+	require.NoError(err)
+	require.NotNil(second)
+	require.True(first.CreateIndex < second.CreateIndex)
+	require.Equal(index, second.CreateIndex)
+}
+
+func TestPlanApply_evaluateBatch_Conflict(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	node := mock.Node()
+	testRegisterNode(t, s1, node)
+
+	// Create two plans that reserve the same port on the node
+	alloc1 := mock.Alloc()
+	alloc1.NodeID = node.ID
+	alloc2 := mock.Alloc()
+	alloc2.NodeID = node.ID
+	batch := []*pendingPlan{testPendingPlan(t, s1, alloc1), testPendingPlan(t, s1, alloc2)}
+
+	snap, err := s1.State().Snapshot()
+	require.NoError(err)
+
+	pool := NewEvaluatePool(workerPoolSize, workerPoolBufferSize)
+	defer pool.Shutdown()
+
+	// Only the first plan should be applied since the second is evaluated
+	// against the staged results of the first
+	applied := s1.evaluateBatch(pool, snap, batch)
+	require.Len(applied, 1)
+	require.Equal(batch[0], applied[0].pending)
+
+	result, err := batch[1].Wait()
+	require.NoError(err)
+	require.Empty(result.NodeAllocation)
+	require.NotZero(result.RefreshIndex)
+
+	for _, a := range applied {
+		a.span.End()
+	}
+}
+
 func TestPlanApply_EvalPlan_Simple(t *testing.T) {
 	t.Parallel()
 	state := testStateStore(t)
@@ -124,6 +124,17 @@ func (q *PlanQueue) Enqueue(plan *structs.Plan) (PlanFuture, error) {
 
 // Dequeue is used to perform a blocking dequeue
 func (q *PlanQueue) Dequeue(timeout time.Duration) (*pendingPlan, error) {
+	batch, err := q.DequeueBatch(timeout, 1)
+	if err != nil || len(batch) == 0 {
+		return nil, err
+	}
+	return batch[0], nil
+}
+
+// DequeueBatch is used to perform a blocking dequeue of up to max plans. It
+// blocks until at least one plan is ready and then returns any other plans
+// that are already waiting, without blocking for more to arrive.
+func (q *PlanQueue) DequeueBatch(timeout time.Duration, max int) ([]*pendingPlan, error) {
 SCAN:
 	q.l.Lock()
 
@@ -135,11 +146,22 @@ SCAN:
 
 	// Look for available work
 	if len(q.ready) > 0 {
-		raw := heap.Pop(&q.ready)
-		pending := raw.(*pendingPlan)
-		q.stats.Depth -= 1
+		metrics.AddSample([]string{"nomad", "plan", "dequeue_depth"}, float32(len(q.ready)))
+
+		n := len(q.ready)
+		if max > 0 && n > max {
+			n = max
+		}
+		batch := make([]*pendingPlan, 0, n)
+		for i := 0; i < n; i++ {
+			raw := heap.Pop(&q.ready)
+			pending := raw.(*pendingPlan)
+			metrics.MeasureSince([]string{"nomad", "plan", "queue_wait"}, pending.enqueueTime)
+			batch = append(batch, pending)
+		}
+		q.stats.Depth -= n
 		q.l.Unlock()
-		return pending, nil
+		return batch, nil
 	}
 	q.l.Unlock()
 
@@ -74,6 +74,52 @@ func TestPlanQueue_Enqueue_Dequeue(t *testing.T) {
 	}
 }
 
+func TestPlanQueue_DequeueBatch(t *testing.T) {
+	t.Parallel()
+	pq := testPlanQueue(t)
+	pq.SetEnabled(true)
+
+	plans := []*structs.Plan{mock.Plan(), mock.Plan(), mock.Plan()}
+	for _, plan := range plans {
+		if _, err := pq.Enqueue(plan); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	// Dequeue up to the max
+	batch, err := pq.DequeueBatch(time.Second, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(batch) != 2 || batch[0].plan != plans[0] || batch[1].plan != plans[1] {
+		t.Fatalf("bad: %#v", batch)
+	}
+	if stats := pq.Stats(); stats.Depth != 1 {
+		t.Fatalf("bad: %#v", stats)
+	}
+
+	// Dequeue the remaining plan without waiting for more
+	batch, err = pq.DequeueBatch(time.Second, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(batch) != 1 || batch[0].plan != plans[2] {
+		t.Fatalf("bad: %#v", batch)
+	}
+	if stats := pq.Stats(); stats.Depth != 0 {
+		t.Fatalf("bad: %#v", stats)
+	}
+
+	// Timeout when there is nothing to dequeue
+	batch, err = pq.DequeueBatch(5*time.Millisecond, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(batch) != 0 {
+		t.Fatalf("bad: %#v", batch)
+	}
+}
+
 func TestPlanQueue_Enqueue_Disable(t *testing.T) {
 	t.Parallel()
 	pq := testPlanQueue(t)
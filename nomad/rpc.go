@@ -21,6 +21,7 @@ import (
 
 	"github.com/hashicorp/consul/lib"
 	"github.com/hashicorp/nomad/helper/pool"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/raft"
@@ -276,7 +277,7 @@ func (r *rpcHandler) handleMultiplex(ctx context.Context, conn net.Conn, rpcCtx
 // handleNomadConn is used to service a single Nomad RPC connection
 func (r *rpcHandler) handleNomadConn(ctx context.Context, conn net.Conn, server *rpc.Server) {
 	defer conn.Close()
-	rpcCodec := pool.NewServerCodec(conn)
+	rpcCodec := r.traceCodec(pool.NewServerCodec(conn))
 	for {
 		select {
 		case <-ctx.Done():
@@ -724,3 +725,52 @@ RUN_QUERY:
 	}
 	return err
 }
+
+// traceCodec wraps the codec of an RPC server to record a span for each
+// request it serves. The codec is returned as is if tracing is disabled.
+func (s *Server) traceCodec(c rpc.ServerCodec) rpc.ServerCodec {
+	if s.tracer == nil {
+		return c
+	}
+	return &tracingCodec{ServerCodec: c, tracer: s.tracer}
+}
+
+// tracingCodec records a span from reading the header of a request to
+// writing its response. The RPC server serves the requests of a codec one at
+// a time so a single span is outstanding.
+type tracingCodec struct {
+	rpc.ServerCodec
+	tracer *tracing.Tracer
+	span   *tracing.Span
+}
+
+func (c *tracingCodec) ReadRequestHeader(r *rpc.Request) error {
+	if err := c.ServerCodec.ReadRequestHeader(r); err != nil {
+		return err
+	}
+	c.span = c.tracer.Start(r.ServiceMethod, "", tracing.SpanKindServer)
+	c.span.SetAttribute("rpc.system", "nomad")
+	c.span.SetAttribute("rpc.method", r.ServiceMethod)
+	return nil
+}
+
+func (c *tracingCodec) ReadRequestBody(body interface{}) error {
+	if err := c.ServerCodec.ReadRequestBody(body); err != nil {
+		return err
+	}
+	if info, ok := body.(structs.RPCInfo); ok {
+		c.span.SetAttribute("nomad.region", info.RequestRegion())
+		c.span.SetAttribute("nomad.rpc.forwarded", fmt.Sprintf("%v", info.IsForwarded()))
+	}
+	return nil
+}
+
+func (c *tracingCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	err := c.ServerCodec.WriteResponse(r, body)
+	if r.Error != "" {
+		c.span.SetError(errors.New(r.Error))
+	}
+	c.span.End()
+	c.span = nil
+	return err
+}
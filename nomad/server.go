@@ -26,9 +26,12 @@ import (
 	"github.com/hashicorp/nomad/helper/pool"
 	"github.com/hashicorp/nomad/helper/stats"
 	"github.com/hashicorp/nomad/helper/tlsutil"
+	"github.com/hashicorp/nomad/helper/tracing"
+	"github.com/hashicorp/nomad/nomad/auth"
 	"github.com/hashicorp/nomad/nomad/deploymentwatcher"
 	"github.com/hashicorp/nomad/nomad/drainer"
 	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/stream"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/scheduler"
@@ -166,6 +169,11 @@ type Server struct {
 	// join/leave from the region.
 	reconcileCh chan serf.Member
 
+	// reassertLeaderCh is used to ask the leader loop to restart the
+	// leadership actions, such as after restoring a snapshot. The error of
+	// doing so is sent on the passed channel.
+	reassertLeaderCh chan chan error
+
 	// eventCh is used to receive events from the serf cluster
 	eventCh chan serf.Event
 
@@ -184,6 +192,10 @@ type Server struct {
 	// that are waiting to be brokered to a sub-scheduler
 	evalBroker *EvalBroker
 
+	// tracer records the spans of RPCs and evaluations. It is nil if
+	// tracing is disabled.
+	tracer *tracing.Tracer
+
 	// periodicDispatcher is used to track and create evaluations for periodic jobs.
 	periodicDispatcher *PeriodicDispatch
 
@@ -198,20 +210,56 @@ type Server struct {
 	// consulCatalog is used for discovering other Nomad Servers via Consul
 	consulCatalog consul.CatalogAPI
 
-	// vault is the client for communicating with Vault.
+	// vault is the client for communicating with the default Vault cluster.
 	vault VaultClient
 
+	// vaultClusters are the clients of the named Vault clusters, which tasks
+	// select with the cluster of their vault block.
+	vaultClusters     map[string]VaultClient
+	vaultClustersLock sync.RWMutex
+
+	// vaultIdentity signs the workload identities clients exchange for Vault
+	// tokens, indexed by the Vault cluster. Only clusters with JWT auth
+	// enabled have a signer.
+	vaultIdentity     map[string]*auth.Signer
+	vaultIdentityLock sync.RWMutex
+
+	// encrypter encrypts and decrypts variables. It is nil when no
+	// variables encryption key is configured.
+	encrypter *Encrypter
+
 	// Worker used for processing
 	workers []*Worker
 
 	// aclCache is used to maintain the parsed ACL objects
 	aclCache *lru.TwoQueueCache
 
+	// aclAuthValidators caches the login token validators of the ACL auth
+	// methods
+	aclAuthValidators *auth.ValidatorCache
+
+	// eventBroker buffers the events of state changes for the event stream.
+	// It is nil when the event broker is disabled.
+	eventBroker *stream.EventBroker
+
+	// jobRateLimiter limits the rate of job registrations and dispatches.
+	jobRateLimiter *jobRateLimiter
+
+	// jobAdmission runs the admission controllers on the submitted jobs.
+	jobAdmission *jobAdmission
+
+	// decisionLog records the placement decisions of the schedulers.
+	decisionLog *decisionLog
+
 	// leaderAcl is the management ACL token that is valid when resolved by the
 	// current leader.
 	leaderAcl     string
 	leaderAclLock sync.Mutex
 
+	// aclConfigLock guards the ACL settings of the config that are updated
+	// when the configuration is reloaded.
+	aclConfigLock sync.RWMutex
+
 	// statsFetcher is used by autopilot to check the status of the other
 	// Nomad router.
 	statsFetcher *StatsFetcher
@@ -242,10 +290,17 @@ type endpoints struct {
 	ACL        *ACL
 	Enterprise *EnterpriseEndpoints
 
+	ServiceRegistration *ServiceRegistration
+	Variables           *Variables
+	CSIVolume           *CSIVolume
+	CSIPlugin           *CSIPlugin
+	Event               *Event
+
 	// Client endpoints
 	ClientStats       *ClientStats
 	FileSystem        *FileSystem
 	ClientAllocations *ClientAllocations
+	ClientCSI         *ClientCSI
 }
 
 // NewServer is used to construct a new Nomad server from the
@@ -287,24 +342,50 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error)
 
 	// Create the server
 	s := &Server{
-		config:        config,
-		consulCatalog: consulCatalog,
-		connPool:      pool.NewPool(logger, serverRPCCache, serverMaxStreams, tlsWrap),
-		logger:        logger,
-		tlsWrap:       tlsWrap,
-		rpcServer:     rpc.NewServer(),
-		streamingRpcs: structs.NewStreamingRpcRegistry(),
-		nodeConns:     make(map[string][]*nodeConnState),
-		peers:         make(map[string][]*serverParts),
-		localPeers:    make(map[raft.ServerAddress]*serverParts),
-		reconcileCh:   make(chan serf.Member, 32),
-		eventCh:       make(chan serf.Event, 256),
-		evalBroker:    evalBroker,
-		blockedEvals:  NewBlockedEvals(evalBroker, logger),
-		rpcTLS:        incomingTLS,
-		aclCache:      aclCache,
-		shutdownCh:    make(chan struct{}),
+		config:           config,
+		consulCatalog:    consulCatalog,
+		connPool:         pool.NewPool(logger, serverRPCCache, serverMaxStreams, tlsWrap),
+		logger:           logger,
+		tlsWrap:          tlsWrap,
+		rpcServer:        rpc.NewServer(),
+		streamingRpcs:    structs.NewStreamingRpcRegistry(),
+		nodeConns:        make(map[string][]*nodeConnState),
+		peers:            make(map[string][]*serverParts),
+		localPeers:       make(map[raft.ServerAddress]*serverParts),
+		reconcileCh:      make(chan serf.Member, 32),
+		reassertLeaderCh: make(chan chan error),
+		eventCh:          make(chan serf.Event, 256),
+		evalBroker:       evalBroker,
+		blockedEvals:     NewBlockedEvals(evalBroker, logger),
+		rpcTLS:           incomingTLS,
+		aclCache:         aclCache,
+		shutdownCh:       make(chan struct{}),
+	}
+
+	// Create the tracer recording the spans of RPCs and evaluations
+	s.tracer = tracing.NewTracer(logger, config.TracingConfig)
+	evalBroker.tracer = s.tracer
+
+	// Create the ACL auth method validator cache
+	s.aclAuthValidators = auth.NewValidatorCache()
+
+	// Create the event broker the FSM publishes events to
+	if config.EnableEventBroker {
+		s.eventBroker = stream.NewEventBroker(config.EventBufferSize)
+	}
+
+	// Create the job submission rate limiter
+	s.jobRateLimiter = newJobRateLimiter(config)
+
+	// Create the job admission controllers
+	jobAdmission, err := newJobAdmission(logger, config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to setup job admission: %v", err)
 	}
+	s.jobAdmission = jobAdmission
+
+	// Record the placement decisions of the schedulers if enabled
+	s.decisionLog = newDecisionLog(logger, config.DecisionLogOutput)
 
 	// Create the RPC handler
 	s.rpcHandler = newRpcHandler(s)
@@ -325,6 +406,15 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error)
 	// Initialize the stats fetcher that autopilot will use.
 	s.statsFetcher = NewStatsFetcher(s.logger, s.connPool, s.config.Region)
 
+	// Setup the variables encrypter
+	if len(config.VariablesEncryptionKey) != 0 {
+		encrypter, err := NewEncrypter(config.VariablesEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to setup variables encryption: %v", err)
+		}
+		s.encrypter = encrypter
+	}
+
 	// Setup Vault
 	if err := s.setupVaultClient(); err != nil {
 		s.Shutdown()
@@ -561,11 +651,19 @@ func (s *Server) Shutdown() error {
 		s.fsm.Close()
 	}
 
+	// Close the event stream subscriptions
+	if s.eventBroker != nil {
+		s.eventBroker.Close()
+	}
+
 	// Stop Vault token renewal
-	if s.vault != nil {
-		s.vault.Stop()
+	for _, v := range s.vaultClients() {
+		v.Stop()
 	}
 
+	// Export the remaining spans
+	s.tracer.Shutdown()
+
 	return nil
 }
 
@@ -688,6 +786,12 @@ func (s *Server) Reload(newConfig *Config) error {
 			multierror.Append(&mErr, err)
 		}
 	}
+	if err := s.setupVaultIdentity(newConfig.VaultConfig); err != nil {
+		multierror.Append(&mErr, err)
+	}
+	if err := s.reloadVaultClusters(newConfig.VaultClusters); err != nil {
+		multierror.Append(&mErr, err)
+	}
 
 	shouldReloadTLS, err := tlsutil.ShouldReloadRPCConnections(s.config.TLSConfig, newConfig.TLSConfig)
 	if err != nil {
@@ -701,9 +805,25 @@ func (s *Server) Reload(newConfig *Config) error {
 		}
 	}
 
+	s.reloadACLConfig(newConfig)
+
 	return mErr.ErrorOrNil()
 }
 
+// reloadACLConfig updates the ACL settings that can be changed without
+// restarting the server.
+func (s *Server) reloadACLConfig(newConfig *Config) {
+	s.aclConfigLock.Lock()
+	defer s.aclConfigLock.Unlock()
+
+	if s.config.ReplicationToken != newConfig.ReplicationToken {
+		s.logger.Info("reloading ACL replication token")
+		s.config.ReplicationToken = newConfig.ReplicationToken
+	}
+	s.config.ACLTokenMinExpirationTTL = newConfig.ACLTokenMinExpirationTTL
+	s.config.ACLTokenMaxExpirationTTL = newConfig.ACLTokenMaxExpirationTTL
+}
+
 // setupBootstrapHandler() creates the closure necessary to support a Consul
 // fallback handler.
 func (s *Server) setupBootstrapHandler() error {
@@ -907,12 +1027,25 @@ func (s *Server) setupDeploymentWatcher() error {
 		apply: s.raftApply,
 	}
 
+	// Create the region shim used to follow the deployments of the peer
+	// regions of multiregion jobs
+	regionShim := &deploymentWatcherRegionShim{
+		srv: s,
+	}
+
 	// Create the deployment watcher
 	s.deploymentWatcher = deploymentwatcher.NewDeploymentsWatcher(
-		s.logger, raftShim,
+		s.logger, raftShim, regionShim,
 		deploymentwatcher.LimitStateQueriesPerSecond,
 		deploymentwatcher.CrossDeploymentUpdateBatchDuration)
 
+	// Notify external tooling of the deployments failing their progress
+	// deadline
+	if url := s.config.DeploymentWebhookURL; url != "" {
+		s.deploymentWatcher.SetWebhook(deploymentwatcher.NewWebhook(s.logger, url,
+			s.config.DeploymentWebhookHMACKey, s.config.DeploymentWebhookTimeout))
+	}
+
 	return nil
 }
 
@@ -940,7 +1073,10 @@ func (s *Server) setupVaultClient() error {
 		return err
 	}
 	s.vault = v
-	return nil
+	if err := s.setupVaultIdentity(s.config.VaultConfig); err != nil {
+		return err
+	}
+	return s.reloadVaultClusters(s.config.VaultClusters)
 }
 
 // setupRPC is used to setup the RPC listener
@@ -1022,15 +1158,23 @@ func (s *Server) setupRpcServer(server *rpc.Server, ctx *RPCContext) {
 		s.staticEndpoints.Status = &Status{srv: s, logger: s.logger.Named("status")}
 		s.staticEndpoints.System = &System{srv: s, logger: s.logger.Named("system")}
 		s.staticEndpoints.Search = &Search{srv: s, logger: s.logger.Named("search")}
+		s.staticEndpoints.ServiceRegistration = &ServiceRegistration{srv: s, logger: s.logger.Named("service_registration")}
+		s.staticEndpoints.Variables = &Variables{srv: s, logger: s.logger.Named("variables")}
+		s.staticEndpoints.CSIVolume = &CSIVolume{srv: s, logger: s.logger.Named("csi_volume")}
+		s.staticEndpoints.CSIPlugin = &CSIPlugin{srv: s, logger: s.logger.Named("csi_plugin")}
 		s.staticEndpoints.Enterprise = NewEnterpriseEndpoints(s)
 
 		// Client endpoints
 		s.staticEndpoints.ClientStats = &ClientStats{srv: s, logger: s.logger.Named("client_stats")}
 		s.staticEndpoints.ClientAllocations = &ClientAllocations{srv: s, logger: s.logger.Named("client_allocs")}
+		s.staticEndpoints.ClientCSI = &ClientCSI{srv: s, logger: s.logger.Named("client_csi")}
 
 		// Streaming endpoints
 		s.staticEndpoints.FileSystem = &FileSystem{srv: s, logger: s.logger.Named("client_fs")}
 		s.staticEndpoints.FileSystem.register()
+		s.staticEndpoints.Event = &Event{srv: s, logger: s.logger.Named("event")}
+		s.staticEndpoints.Event.register()
+		s.staticEndpoints.Operator.register()
 	}
 
 	// Register the static handlers
@@ -1046,9 +1190,14 @@ func (s *Server) setupRpcServer(server *rpc.Server, ctx *RPCContext) {
 	server.Register(s.staticEndpoints.Status)
 	server.Register(s.staticEndpoints.System)
 	server.Register(s.staticEndpoints.Search)
+	server.Register(s.staticEndpoints.ServiceRegistration)
+	server.Register(s.staticEndpoints.Variables)
+	server.Register(s.staticEndpoints.CSIVolume)
+	server.Register(s.staticEndpoints.CSIPlugin)
 	s.staticEndpoints.Enterprise.Register(server)
 	server.Register(s.staticEndpoints.ClientStats)
 	server.Register(s.staticEndpoints.ClientAllocations)
+	server.Register(s.staticEndpoints.ClientCSI)
 	server.Register(s.staticEndpoints.FileSystem)
 
 	// Create new dynamic endpoints and add them to the RPC server.
@@ -1071,11 +1220,12 @@ func (s *Server) setupRaft() error {
 
 	// Create the FSM
 	fsmConfig := &FSMConfig{
-		EvalBroker: s.evalBroker,
-		Periodic:   s.periodicDispatcher,
-		Blocked:    s.blockedEvals,
-		Logger:     s.logger,
-		Region:     s.Region(),
+		EvalBroker:  s.evalBroker,
+		Periodic:    s.periodicDispatcher,
+		Blocked:     s.blockedEvals,
+		EventBroker: s.eventBroker,
+		Logger:      s.logger,
+		Region:      s.Region(),
 	}
 	var err error
 	s.fsm, err = NewFSM(fsmConfig)
@@ -1110,7 +1260,9 @@ func (s *Server) setupRaft() error {
 		s.raftInmem = store
 		stable = store
 		log = store
-		snap = raft.NewDiscardSnapshotStore()
+
+		// Snapshots are kept in memory so that they can be saved
+		snap = raft.NewInmemSnapshotStore()
 
 	} else {
 		// Create the base raft path
@@ -1173,7 +1325,10 @@ func (s *Server) setupRaft() error {
 			if err != nil {
 				return fmt.Errorf("recovery failed to parse peers.json: %v", err)
 			}
-			tmpFsm, err := NewFSM(fsmConfig)
+			// Events of the recovered logs are not published
+			tmpConfig := *fsmConfig
+			tmpConfig.EventBroker = nil
+			tmpFsm, err := NewFSM(&tmpConfig)
 			if err != nil {
 				return fmt.Errorf("recovery failed to make temp FSM: %v", err)
 			}
@@ -1238,6 +1393,9 @@ func (s *Server) setupSerf(conf *serf.Config, ch chan serf.Event, path string) (
 	conf.Tags["id"] = s.config.NodeID
 	conf.Tags["rpc_addr"] = s.clientRpcAdvertise.(*net.TCPAddr).IP.String()         // Address that clients will use to RPC to servers
 	conf.Tags["port"] = fmt.Sprintf("%d", s.serverRpcAdvertise.(*net.TCPAddr).Port) // Port servers use to RPC to one and another
+	for segment, addr := range s.config.ClientRPCAdvertiseSegments {
+		conf.Tags[rpcSegmentTagPrefix+segment] = addr.String() // Address that clients of the segment will use to RPC to servers
+	}
 	if s.config.Bootstrap || (s.config.DevMode && !s.config.DevDisableBootstrap) {
 		conf.Tags["bootstrap"] = "1"
 	}
@@ -1405,7 +1563,7 @@ func (s *Server) RPC(method string, args interface{}, reply interface{}) error {
 		Args:   args,
 		Reply:  reply,
 	}
-	if err := s.rpcServer.ServeRequest(codec); err != nil {
+	if err := s.rpcServer.ServeRequest(s.traceCodec(codec)); err != nil {
 		return err
 	}
 	return codec.Err
@@ -1455,11 +1613,21 @@ func (s *Server) GetConfig() *Config {
 }
 
 // ReplicationToken returns the token used for replication. We use a method to support
-// dynamic reloading of this value later.
+// dynamic reloading of this value.
 func (s *Server) ReplicationToken() string {
+	s.aclConfigLock.RLock()
+	defer s.aclConfigLock.RUnlock()
 	return s.config.ReplicationToken
 }
 
+// aclTokenExpirationTTLs returns the bounds of the expiration TTL of ACL
+// tokens.
+func (s *Server) aclTokenExpirationTTLs() (min, max time.Duration) {
+	s.aclConfigLock.RLock()
+	defer s.aclConfigLock.RUnlock()
+	return s.config.ACLTokenMinExpirationTTL, s.config.ACLTokenMaxExpirationTTL
+}
+
 // peersInfoContent is used to help operators understand what happened to the
 // peers.json file. This is written to a file called peers.info in the same
 // location.
@@ -1,16 +1,21 @@
 package nomad
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -40,6 +45,92 @@ func TestServer_RPC(t *testing.T) {
 	}
 }
 
+func TestServer_Tracing(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Collect the names and trace IDs of the exported spans
+	var l sync.Mutex
+	spans := make(map[string]string)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						Name    string `json:"name"`
+						TraceID string `json:"traceId"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		l.Lock()
+		defer l.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					spans[span.Name] = span.TraceID
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+
+	s1 := TestServer(t, func(c *Config) {
+		c.TracingConfig = &tracing.Config{
+			Endpoint:    collector.URL,
+			SampleRate:  1,
+			ServiceName: "nomad",
+		}
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	state := s1.fsm.State()
+	require.NoError(state.UpsertNode(1000, mock.Node()))
+
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+
+	testutil.WaitForResult(func() (bool, error) {
+		eval, err := state.EvalByID(nil, resp.EvalID)
+		if err != nil {
+			return false, err
+		}
+		if eval == nil || eval.Status != structs.EvalStatusComplete {
+			return false, fmt.Errorf("eval not complete: %v", eval)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// Shutting down exports the spans
+	s1.Shutdown()
+
+	l.Lock()
+	defer l.Unlock()
+	require.Contains(spans, "Job.Register")
+
+	// The spans of the evaluation share a trace
+	traceID := strings.Replace(resp.EvalID, "-", "", -1)
+	for _, name := range []string{"eval_broker.ready", "worker.invoke_scheduler", "plan.evaluate", "plan.apply"} {
+		require.Equal(traceID, spans[name], name)
+	}
+}
+
 func TestServer_RPC_TLS(t *testing.T) {
 	t.Parallel()
 	const (
@@ -232,6 +323,27 @@ func TestServer_Reload_Vault(t *testing.T) {
 	}
 }
 
+func TestServer_Reload_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1, _ := TestACLServer(t, func(c *Config) {
+		c.ReplicationToken = "foo"
+	})
+	defer s1.Shutdown()
+
+	config := DefaultConfig()
+	config.ReplicationToken = "bar"
+	config.ACLTokenMinExpirationTTL = 5 * time.Minute
+	config.ACLTokenMaxExpirationTTL = 48 * time.Hour
+	require.NoError(s1.Reload(config))
+
+	require.Equal("bar", s1.ReplicationToken())
+	min, max := s1.aclTokenExpirationTTLs()
+	require.Equal(5*time.Minute, min)
+	require.Equal(48*time.Hour, max)
+}
+
 func connectionReset(msg string) bool {
 	return strings.Contains(msg, "EOF") || strings.Contains(msg, "connection reset by peer")
 }
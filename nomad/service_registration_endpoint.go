@@ -0,0 +1,307 @@
+package nomad
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	memdb "github.com/hashicorp/go-memdb"
+	multierror "github.com/hashicorp/go-multierror"
+
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// ServiceRegistration endpoint is used to register and look up services in
+// Nomad's built-in service catalog
+type ServiceRegistration struct {
+	srv    *Server
+	logger log.Logger
+}
+
+// Upsert is used by clients to register the services of the allocations
+// they are running
+func (s *ServiceRegistration) Upsert(args *structs.ServiceRegistrationUpsertRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.Upsert", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "upsert"}, time.Now())
+
+	if len(args.Services) == 0 {
+		return fmt.Errorf("must specify at least one service")
+	}
+
+	node, err := s.authenticateNode(args.AuthToken)
+	if err != nil {
+		return err
+	}
+
+	var mErr multierror.Error
+	for _, service := range args.Services {
+		if err := service.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("service %q: %v", service.ServiceName, err))
+			continue
+		}
+
+		// Nodes may only register services for themselves
+		if service.NodeID != node.ID {
+			return structs.ErrPermissionDenied
+		}
+	}
+	if err := mErr.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	_, index, err := s.srv.raftApply(structs.ServiceRegistrationUpsertRequestType, args)
+	if err != nil {
+		s.logger.Error("upsert failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// Delete is used by clients to deregister the services of the allocations
+// they are running
+func (s *ServiceRegistration) Delete(args *structs.ServiceRegistrationDeleteRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.Delete", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "delete"}, time.Now())
+
+	if len(args.IDs) == 0 {
+		return fmt.Errorf("must specify at least one service ID")
+	}
+
+	node, err := s.authenticateNode(args.AuthToken)
+	if err != nil {
+		return err
+	}
+
+	// Nodes may only deregister services for themselves
+	if args.NodeID != node.ID {
+		return structs.ErrPermissionDenied
+	}
+
+	_, index, err := s.srv.raftApply(structs.ServiceRegistrationDeleteRequestType, args)
+	if err != nil {
+		s.logger.Error("delete failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// DeleteByID is used by operators to deregister a service instance
+func (s *ServiceRegistration) DeleteByID(args *structs.ServiceRegistrationDeleteByIDRequest,
+	reply *structs.GenericResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.DeleteByID", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "delete_id"}, time.Now())
+
+	// Check namespace submit-job permissions
+	aclObj, err := s.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.ServiceName == "" || args.ID == "" {
+		return fmt.Errorf("missing service name or ID")
+	}
+
+	service, err := s.srv.fsm.State().ServiceRegistrationByID(nil, args.ID)
+	if err != nil {
+		return err
+	}
+	if service == nil || service.Namespace != args.RequestNamespace() || service.ServiceName != args.ServiceName {
+		return fmt.Errorf("service registration %q not found", args.ID)
+	}
+	if aclObj != nil && !aclObj.AllowJobOp(service.Namespace, service.JobID, acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Deregister the service on behalf of the node that registered it
+	req := &structs.ServiceRegistrationDeleteRequest{
+		NodeID:       service.NodeID,
+		IDs:          []string{service.ID},
+		WriteRequest: args.WriteRequest,
+	}
+	_, index, err := s.srv.raftApply(structs.ServiceRegistrationDeleteRequestType, req)
+	if err != nil {
+		s.logger.Error("delete failed", "error", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// List is used to list the services registered in a namespace
+func (s *ServiceRegistration) List(args *structs.ServiceRegistrationListRequest,
+	reply *structs.ServiceRegistrationListResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "list"}, time.Now())
+
+	// Check namespace read-job permissions
+	aclObj, err := s.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			iter, err := state.ServiceRegistrations(ws)
+			if err != nil {
+				return err
+			}
+
+			// Merge the instances of each service
+			stubs := make(map[string]*structs.ServiceRegistrationStub)
+			tags := make(map[string]map[string]struct{})
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				service := raw.(*structs.ServiceRegistration)
+				if service.Namespace != args.RequestNamespace() {
+					continue
+				}
+				if aclObj != nil && !aclObj.AllowJobOp(service.Namespace, service.JobID, acl.NamespaceCapabilityReadJob) {
+					continue
+				}
+
+				stub, ok := stubs[service.ServiceName]
+				if !ok {
+					stub = &structs.ServiceRegistrationStub{
+						ServiceName: service.ServiceName,
+						Tags:        []string{},
+					}
+					stubs[service.ServiceName] = stub
+					tags[service.ServiceName] = make(map[string]struct{})
+				}
+				for _, tag := range service.Tags {
+					if _, ok := tags[service.ServiceName][tag]; ok {
+						continue
+					}
+					tags[service.ServiceName][tag] = struct{}{}
+					stub.Tags = append(stub.Tags, tag)
+				}
+			}
+
+			services := make([]*structs.ServiceRegistrationStub, 0, len(stubs))
+			for _, stub := range stubs {
+				sort.Strings(stub.Tags)
+				services = append(services, stub)
+			}
+			sort.Slice(services, func(i, j int) bool {
+				return services[i].ServiceName < services[j].ServiceName
+			})
+			reply.Services = services
+
+			// Use the last index that affected the service registrations
+			// table
+			index, err := state.Index("service_registrations")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			s.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return s.srv.blockingRPC(&opts)
+}
+
+// GetService is used to get the registered instances of a service
+func (s *ServiceRegistration) GetService(args *structs.ServiceRegistrationByNameRequest,
+	reply *structs.ServiceRegistrationByNameResponse) error {
+	if done, err := s.srv.forward("ServiceRegistration.GetService", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "get_service"}, time.Now())
+
+	// Check namespace read-job permissions
+	aclObj, err := s.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNamespaceJobsOperation(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.ServiceName == "" {
+		return fmt.Errorf("missing service name")
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			services, err := state.ServiceRegistrationsByName(ws, args.RequestNamespace(), args.ServiceName)
+			if err != nil {
+				return err
+			}
+			if aclObj != nil {
+				allowed := make([]*structs.ServiceRegistration, 0, len(services))
+				for _, service := range services {
+					if aclObj.AllowJobOp(service.Namespace, service.JobID, acl.NamespaceCapabilityReadJob) {
+						allowed = append(allowed, service)
+					}
+				}
+				services = allowed
+			}
+			if services == nil {
+				services = []*structs.ServiceRegistration{}
+			}
+			reply.Services = services
+
+			// Use the last index that affected the service registrations
+			// table
+			index, err := state.Index("service_registrations")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			s.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return s.srv.blockingRPC(&opts)
+}
+
+// authenticateNode returns the node whose SecretID is the auth token. Only
+// clients register services so ACL tokens are not accepted.
+func (s *ServiceRegistration) authenticateNode(authToken string) (*structs.Node, error) {
+	if authToken == "" {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	node, err := s.srv.fsm.State().NodeBySecretID(nil, authToken)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, structs.ErrPermissionDenied
+	}
+	return node, nil
+}
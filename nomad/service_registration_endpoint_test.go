@@ -0,0 +1,248 @@
+package nomad
+
+import (
+	"testing"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceRegistrationEndpoint_UpsertDelete(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	node := mock.Node()
+	other := mock.Node()
+	require.NoError(state.UpsertNode(1000, node))
+	require.NoError(state.UpsertNode(1001, other))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	require.NoError(state.UpsertAllocs(1002, []*structs.Allocation{alloc}))
+	service := mock.ServiceRegistration(alloc)
+
+	req := &structs.ServiceRegistrationUpsertRequest{
+		Services:     []*structs.ServiceRegistration{service},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Only nodes may register services
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Nodes may not register services for other nodes
+	req.AuthToken = other.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = node.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp))
+	require.NotZero(resp.Index)
+
+	out, err := state.ServiceRegistrationByID(nil, service.ID)
+	require.NoError(err)
+	require.NotNil(out)
+
+	// Nodes may not deregister services for other nodes
+	dreq := &structs.ServiceRegistrationDeleteRequest{
+		NodeID: node.ID,
+		IDs:    []string{service.ID},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: other.SecretID,
+		},
+	}
+	err = msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Delete", dreq, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	dreq.AuthToken = node.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Delete", dreq, &resp))
+
+	out, err = state.ServiceRegistrationByID(nil, service.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
+
+func TestServiceRegistrationEndpoint_Upsert_Invalid(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	node := mock.Node()
+	require.NoError(s1.fsm.State().UpsertNode(1000, node))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	service := mock.ServiceRegistration(alloc)
+	service.ServiceName = ""
+
+	req := &structs.ServiceRegistrationUpsertRequest{
+		Services: []*structs.ServiceRegistration{service},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: node.SecretID,
+		},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.Upsert", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "missing service name")
+}
+
+func TestServiceRegistrationEndpoint_ListGetService(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	a1 := mock.Alloc()
+	a2 := mock.Alloc()
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{a1, a2}))
+
+	s1a := mock.ServiceRegistration(a1)
+	s1b := mock.ServiceRegistration(a2)
+	s1b.Tags = []string{"http", "v2"}
+	s2 := mock.ServiceRegistration(a1)
+	s2.ServiceName = "api"
+	s2.Tags = nil
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{s1a, s1b, s2}))
+
+	get := &structs.ServiceRegistrationListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+	var resp structs.ServiceRegistrationListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.List", get, &resp))
+	require.EqualValues(1001, resp.Index)
+	require.Equal([]*structs.ServiceRegistrationStub{
+		{ServiceName: "api", Tags: []string{}},
+		{ServiceName: "web", Tags: []string{"http", "v2"}},
+	}, resp.Services)
+
+	byName := &structs.ServiceRegistrationByNameRequest{
+		ServiceName: "web",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+	var resp2 structs.ServiceRegistrationByNameResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.GetService", byName, &resp2))
+	require.EqualValues(1001, resp2.Index)
+	require.Len(resp2.Services, 2)
+
+	// Services in other namespaces are not returned
+	byName.Namespace = "other"
+	var resp3 structs.ServiceRegistrationByNameResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.GetService", byName, &resp3))
+	require.Empty(resp3.Services)
+}
+
+func TestServiceRegistrationEndpoint_List_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	alloc := mock.Alloc()
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{mock.ServiceRegistration(alloc)}))
+
+	validToken := mock.CreatePolicyAndToken(t, state, 1002, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityListJobs}))
+
+	get := &structs.ServiceRegistrationListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+		},
+	}
+
+	// Try with no token and expect permission denied
+	var resp structs.ServiceRegistrationListResponse
+	err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.List", get, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with an invalid token
+	get.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "ServiceRegistration.List", get, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	for _, token := range []string{validToken.SecretID, root.SecretID} {
+		get.AuthToken = token
+		var resp structs.ServiceRegistrationListResponse
+		require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.List", get, &resp))
+		require.Len(resp.Services, 1)
+	}
+}
+
+func TestServiceRegistrationEndpoint_DeleteByID(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	alloc := mock.Alloc()
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+	service := mock.ServiceRegistration(alloc)
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{service}))
+
+	readToken := mock.CreatePolicyAndToken(t, state, 1002, "test-read",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+
+	req := &structs.ServiceRegistrationDeleteByIDRequest{
+		ServiceName: service.ServiceName,
+		ID:          service.ID,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: structs.DefaultNamespace,
+			AuthToken: readToken.SecretID,
+		},
+	}
+
+	// Deleting requires submit-job
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ServiceRegistration.DeleteByID", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// The ID must belong to the named service
+	req.AuthToken = root.SecretID
+	req.ServiceName = "other"
+	err = msgpackrpc.CallWithCodec(codec, "ServiceRegistration.DeleteByID", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "not found")
+
+	req.ServiceName = service.ServiceName
+	require.NoError(msgpackrpc.CallWithCodec(codec, "ServiceRegistration.DeleteByID", req, &resp))
+	require.NotZero(resp.Index)
+
+	out, err := state.ServiceRegistrationByID(nil, service.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
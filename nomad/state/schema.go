@@ -43,8 +43,17 @@ func init() {
 		vaultAccessorTableSchema,
 		aclPolicyTableSchema,
 		aclTokenTableSchema,
+		aclRoleTableSchema,
+		aclAuthMethodTableSchema,
+		aclBindingRuleTableSchema,
 		autopilotConfigTableSchema,
 		schedulerConfigTableSchema,
+		gcConfigTableSchema,
+		serviceRegistrationTableSchema,
+		variablesTableSchema,
+		csiVolumeTableSchema,
+		csiPluginTableSchema,
+		scalingEventTableSchema,
 	}...)
 }
 
@@ -325,6 +334,25 @@ func deploymentSchema() *memdb.TableSchema {
 					},
 				},
 			},
+
+			// Namespace status index is used to list deployments in a namespace
+			// by status without scanning the namespace
+			"namespace_status": {
+				Name:         "namespace_status",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "Status",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -410,6 +438,25 @@ func evalTableSchema() *memdb.TableSchema {
 					},
 				},
 			},
+
+			// Namespace status index is used to list evaluations in a namespace
+			// by status without scanning the namespace
+			"namespace_status": {
+				Name:         "namespace_status",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "Status",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -507,6 +554,49 @@ func allocTableSchema() *memdb.TableSchema {
 					Field: "DeploymentID",
 				},
 			},
+
+			// Job deployment index is used to lookup the allocations of a job
+			// that are part of a deployment. Allocations without a deployment
+			// are not indexed.
+			"job_deployment": {
+				Name:         "job_deployment",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "JobID",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "DeploymentID",
+						},
+					},
+				},
+			},
+
+			// Namespace status index is used to list allocations in a namespace
+			// by status without scanning the namespace
+			"namespace_status": {
+				Name:         "namespace_status",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "ClientStatus",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -549,6 +639,79 @@ func vaultAccessorTableSchema() *memdb.TableSchema {
 	}
 }
 
+// serviceRegistrationTableSchema returns the MemDB schema for the service
+// registrations table. This table stores the services registered in Nomad's
+// built-in service catalog.
+func serviceRegistrationTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "service_registrations",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+
+			// The service_name index is used to look up the instances of a
+			// service in a namespace
+			"service_name": {
+				Name:         "service_name",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "ServiceName",
+						},
+					},
+				},
+			},
+
+			"alloc_id": {
+				Name:         "alloc_id",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "AllocID",
+				},
+			},
+		},
+	}
+}
+
+// variablesTableSchema returns the MemDB schema for the variables table. This
+// table stores encrypted variables by namespace and path.
+func variablesTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "variables",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "Path",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 // aclPolicyTableSchema returns the MemDB schema for the policy table.
 // This table is used to store the policies which are referenced by tokens
 func aclPolicyTableSchema() *memdb.TableSchema {
@@ -601,6 +764,25 @@ func aclTokenTableSchema() *memdb.TableSchema {
 	}
 }
 
+// aclRoleTableSchema returns the MemDB schema for the role table.
+// This table is used to store the roles which are bundles of policies
+// that tokens can be associated with.
+func aclRoleTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "acl_role",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Name",
+				},
+			},
+		},
+	}
+}
+
 // schedulerConfigTableSchema returns the MemDB schema for the scheduler config table.
 // This table is used to store configuration options for the scheduler
 func schedulerConfigTableSchema() *memdb.TableSchema {
@@ -619,3 +801,152 @@ func schedulerConfigTableSchema() *memdb.TableSchema {
 		},
 	}
 }
+
+// gcConfigTableSchema returns the MemDB schema for the GC config table.
+// This table is used to store the GC thresholds set at runtime.
+func gcConfigTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "gc_config",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: true,
+				Unique:       true,
+				// This indexer ensures that this table is a singleton
+				Indexer: &memdb.ConditionalIndex{
+					Conditional: func(obj interface{}) (bool, error) { return true, nil },
+				},
+			},
+		},
+	}
+}
+
+// aclAuthMethodTableSchema returns the MemDB schema for the auth method
+// table. This table is used to store the auth methods used to exchange
+// external identities for ACL tokens.
+func aclAuthMethodTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "acl_auth_method",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Name",
+				},
+			},
+		},
+	}
+}
+
+// aclBindingRuleTableSchema returns the MemDB schema for the binding rule
+// table. This table is used to store the rules that grant policies and roles
+// to the tokens created by auth methods.
+func aclBindingRuleTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "acl_binding_rule",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.UUIDFieldIndex{
+					Field: "ID",
+				},
+			},
+			"auth_method": {
+				Name:         "auth_method",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "AuthMethod",
+				},
+			},
+		},
+	}
+}
+
+// csiVolumeTableSchema returns the MemDB schema for the CSI volumes table.
+// This table stores the CSI volumes registered by namespace and ID.
+func csiVolumeTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "csi_volumes",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "ID",
+						},
+					},
+				},
+			},
+
+			"plugin_id": {
+				Name:         "plugin_id",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "PluginID",
+				},
+			},
+		},
+	}
+}
+
+// csiPluginTableSchema returns the MemDB schema for the CSI plugins table.
+// This table stores the CSI plugins running on the nodes, aggregated by
+// plugin ID.
+func csiPluginTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "csi_plugins",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+		},
+	}
+}
+
+// scalingEventTableSchema returns the MemDB schema for the scaling events
+// table. This table stores the recent scaling events of the task groups of
+// each job.
+func scalingEventTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "scaling_event",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+
+				// Use a compound index so the tuple of (Namespace, JobID) is
+				// uniquely identifying
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "JobID",
+						},
+					},
+				},
+			},
+		},
+	}
+}
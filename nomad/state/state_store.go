@@ -4,10 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
-	"reflect"
-
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
 	multierror "github.com/hashicorp/go-multierror"
@@ -173,6 +172,34 @@ func (s *StateStore) UpsertPlanResults(index uint64, results *structs.ApplyPlanR
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
+	if err := s.upsertPlanResultsImpl(index, results, txn); err != nil {
+		return err
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// UpsertPlanResultsBatch is used to upsert the results of multiple plans
+// within a single transaction. The results are applied in order, so later
+// plans observe the changes made by earlier ones.
+func (s *StateStore) UpsertPlanResultsBatch(index uint64, results []*structs.ApplyPlanResultsRequest) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, result := range results {
+		if err := s.upsertPlanResultsImpl(index, result, txn); err != nil {
+			return err
+		}
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// upsertPlanResultsImpl is used to upsert the results of a plan using the
+// passed transaction.
+func (s *StateStore) upsertPlanResultsImpl(index uint64, results *structs.ApplyPlanResultsRequest, txn *memdb.Txn) error {
 	// Upsert the newly created or updated deployment
 	if results.Deployment != nil {
 		if err := s.upsertDeploymentImpl(index, results.Deployment, txn); err != nil {
@@ -262,7 +289,6 @@ func (s *StateStore) UpsertPlanResults(index uint64, results *structs.ApplyPlanR
 		}
 	}
 
-	txn.Commit()
 	return nil
 }
 
@@ -418,6 +444,20 @@ func (s *StateStore) DeploymentsByNamespace(ws memdb.WatchSet, namespace string)
 	return iter, nil
 }
 
+// DeploymentsByNamespaceStatus returns an iterator over the deployments in
+// the namespace with the given status
+func (s *StateStore) DeploymentsByNamespaceStatus(ws memdb.WatchSet, namespace, status string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("deployment", "namespace_status", namespace, status)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+	return iter, nil
+}
+
 func (s *StateStore) DeploymentsByIDPrefix(ws memdb.WatchSet, namespace, deploymentID string) (memdb.ResultIterator, error) {
 	txn := s.db.Txn(false)
 
@@ -596,6 +636,8 @@ func (s *StateStore) UpsertNode(index uint64, node *structs.Node) error {
 		node.Drain = exist.Drain                                 // Retain the drain mode
 		node.SchedulingEligibility = exist.SchedulingEligibility // Retain the eligibility
 		node.DrainStrategy = exist.DrainStrategy                 // Retain the drain strategy
+		node.ScaleIn = exist.ScaleIn                             // Retain the scale-in
+		node.HostVolumes = exist.HostVolumes                     // Retain the host volumes
 	} else {
 		// Because this is the first time the node is being registered, we should
 		// also create a node registration event
@@ -614,6 +656,9 @@ func (s *StateStore) UpsertNode(index uint64, node *structs.Node) error {
 	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
+	if err := upsertNodeCSIPlugins(txn, node, index); err != nil {
+		return err
+	}
 
 	txn.Commit()
 	return nil
@@ -640,6 +685,9 @@ func (s *StateStore) DeleteNode(index uint64, nodeID string) error {
 	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
+	if err := deleteNodeCSIPlugins(txn, existing.(*structs.Node), index); err != nil {
+		return err
+	}
 
 	txn.Commit()
 	return nil
@@ -679,6 +727,9 @@ func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string, event
 	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
+	if err := upsertNodeCSIPlugins(txn, copyNode, index); err != nil {
+		return err
+	}
 
 	txn.Commit()
 	return nil
@@ -731,6 +782,12 @@ func (s *StateStore) updateNodeDrainImpl(txn *memdb.Txn, index uint64, nodeID st
 		appendNodeEvents(index, copyNode, []*structs.NodeEvent{event})
 	}
 
+	// Nodes being scaled in must stay ineligible until the scale-in is
+	// cancelled
+	if drain == nil && markEligible && copyNode.ScaleIn != nil {
+		return fmt.Errorf("can not mark node eligible for scheduling while it is being scaled in")
+	}
+
 	// Update the drain in the copy
 	copyNode.Drain = drain != nil // COMPAT: Remove in Nomad 0.9
 	copyNode.DrainStrategy = drain
@@ -781,6 +838,9 @@ func (s *StateStore) UpdateNodeEligibility(index uint64, nodeID string, eligibil
 	if copyNode.DrainStrategy != nil && eligibility == structs.NodeSchedulingEligible {
 		return fmt.Errorf("can not set node's scheduling eligibility to eligible while it is draining")
 	}
+	if copyNode.ScaleIn != nil && eligibility == structs.NodeSchedulingEligible {
+		return fmt.Errorf("can not set node's scheduling eligibility to eligible while it is being scaled in")
+	}
 
 	// Update the eligibility in the copy
 	copyNode.SchedulingEligibility = eligibility
@@ -798,6 +858,150 @@ func (s *StateStore) UpdateNodeEligibility(index uint64, nodeID string, eligibil
 	return nil
 }
 
+// UpdateNodeScaleIn is used to start or cancel the scale-in of a node. Starting
+// it drains the node with the given drain strategy, while cancelling it
+// removes the drain and marks the node eligible for scheduling.
+func (s *StateStore) UpdateNodeScaleIn(index uint64, nodeID string, scaleIn *structs.NodeScaleIn,
+	drain *structs.DrainStrategy, event *structs.NodeEvent) error {
+
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Lookup the node
+	existing, err := txn.First("nodes", "id", nodeID)
+	if err != nil {
+		return fmt.Errorf("node lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	// Copy the existing node
+	copyNode := existing.(*structs.Node).Copy()
+
+	// Add the event if given
+	if event != nil {
+		appendNodeEvents(index, copyNode, []*structs.NodeEvent{event})
+	}
+
+	// Update the scale-in and drain in the copy
+	copyNode.ScaleIn = scaleIn
+	copyNode.Drain = drain != nil // COMPAT: Remove in Nomad 0.9
+	copyNode.DrainStrategy = drain
+	if scaleIn != nil {
+		copyNode.SchedulingEligibility = structs.NodeSchedulingIneligible
+	} else {
+		copyNode.SchedulingEligibility = structs.NodeSchedulingEligible
+	}
+	copyNode.ModifyIndex = index
+
+	// Insert the node
+	if err := txn.Insert("nodes", copyNode); err != nil {
+		return fmt.Errorf("node update failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// UpsertNodeHostVolume is used to register a host volume on a node or update
+// an already registered volume of the same name.
+func (s *StateStore) UpsertNodeHostVolume(index uint64, nodeID string, volume *structs.HostVolume, event *structs.NodeEvent) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Lookup the node
+	existing, err := txn.First("nodes", "id", nodeID)
+	if err != nil {
+		return fmt.Errorf("node lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	// Copy the existing node
+	copyNode := existing.(*structs.Node).Copy()
+
+	// Add the event if given
+	if event != nil {
+		appendNodeEvents(index, copyNode, []*structs.NodeEvent{event})
+	}
+
+	// Setup the indexes correctly
+	volume = volume.Copy()
+	if exist, ok := copyNode.HostVolumes[volume.Name]; ok {
+		volume.CreateIndex = exist.CreateIndex
+	} else {
+		volume.CreateIndex = index
+	}
+	volume.ModifyIndex = index
+
+	if copyNode.HostVolumes == nil {
+		copyNode.HostVolumes = make(map[string]*structs.HostVolume)
+	}
+	copyNode.HostVolumes[volume.Name] = volume
+	copyNode.ModifyIndex = index
+
+	// Insert the node
+	if err := txn.Insert("nodes", copyNode); err != nil {
+		return fmt.Errorf("node update failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// DeleteNodeHostVolume is used to deregister a host volume from a node
+func (s *StateStore) DeleteNodeHostVolume(index uint64, nodeID, name string, event *structs.NodeEvent) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Lookup the node
+	existing, err := txn.First("nodes", "id", nodeID)
+	if err != nil {
+		return fmt.Errorf("node lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	existingNode := existing.(*structs.Node)
+	if _, ok := existingNode.HostVolumes[name]; !ok {
+		return fmt.Errorf("host volume %q not found", name)
+	}
+
+	// Copy the existing node
+	copyNode := existingNode.Copy()
+
+	// Add the event if given
+	if event != nil {
+		appendNodeEvents(index, copyNode, []*structs.NodeEvent{event})
+	}
+
+	delete(copyNode.HostVolumes, name)
+	if len(copyNode.HostVolumes) == 0 {
+		copyNode.HostVolumes = nil
+	}
+	copyNode.ModifyIndex = index
+
+	// Insert the node
+	if err := txn.Insert("nodes", copyNode); err != nil {
+		return fmt.Errorf("node update failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"nodes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
 // UpsertNodeEvents adds the node events to the nodes, rotating events as
 // necessary.
 func (s *StateStore) UpsertNodeEvents(index uint64, nodeEvents map[string][]*structs.NodeEvent) error {
@@ -963,8 +1167,15 @@ func (s *StateStore) upsertJobImpl(index uint64, job *structs.Job, keepVersion b
 		// when changing an internal field such as Stable. A spec change should
 		// always come with a version bump
 		if !keepVersion {
+			// The versions of a multiregion job are chosen across all its
+			// regions so that they match, so a higher version is kept
+			requested := job.Version
 			job.JobModifyIndex = index
 			job.Version = existing.(*structs.Job).Version + 1
+			if job.IsMultiregion() && requested > job.Version {
+				job.Version = requested
+			}
+			job.VersionTag = nil
 		}
 
 		// Compute the job status
@@ -977,7 +1188,10 @@ func (s *StateStore) upsertJobImpl(index uint64, job *structs.Job, keepVersion b
 		job.CreateIndex = index
 		job.ModifyIndex = index
 		job.JobModifyIndex = index
-		job.Version = 0
+		if !job.IsMultiregion() {
+			job.Version = 0
+		}
+		job.VersionTag = nil
 
 		if err := s.setJobStatus(index, txn, job, false, ""); err != nil {
 			return fmt.Errorf("setting job status for %q failed: %v", job.ID, err)
@@ -1113,6 +1327,15 @@ func (s *StateStore) DeleteJobTxn(index uint64, namespace, jobID string, txn Txn
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Delete the scaling events
+	if num, err := txn.DeleteAll("scaling_event", "id", namespace, jobID); err != nil {
+		return fmt.Errorf("deleting job scaling events failed: %v", err)
+	} else if num > 0 {
+		if err := txn.Insert("index", &IndexEntry{"scaling_event", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -1183,6 +1406,15 @@ func (s *StateStore) upsertJobVersion(index uint64, job *structs.Job, txn *memdb
 		return fmt.Errorf("failed to look up job versions for %q: %v", job.ID, err)
 	}
 
+	// Tagged versions are kept regardless of the limit
+	untagged := all[:0:0]
+	for _, j := range all {
+		if j.VersionTag == nil {
+			untagged = append(untagged, j)
+		}
+	}
+	all = untagged
+
 	// If we are below the limit there is no GCing to be done
 	if len(all) <= structs.JobTrackedVersions {
 		return nil
@@ -1352,6 +1584,33 @@ func (s *StateStore) jobByIDAndVersionImpl(ws memdb.WatchSet, namespace, id stri
 	return nil, nil
 }
 
+// JobVersionByTag returns the version of the job with the given tag name, or
+// nil if no version has it.
+func (s *StateStore) JobVersionByTag(ws memdb.WatchSet, namespace, id, name string) (*structs.Job, error) {
+	txn := s.db.Txn(false)
+	return s.jobVersionByTagImpl(ws, namespace, id, name, txn)
+}
+
+// jobVersionByTagImpl returns the version of the job with the given tag name,
+// using the given transaction.
+func (s *StateStore) jobVersionByTagImpl(ws memdb.WatchSet, namespace, id, name string, txn *memdb.Txn) (*structs.Job, error) {
+	var watch *memdb.WatchSet
+	if ws != nil {
+		watch = &ws
+	}
+	all, err := s.jobVersionByID(txn, watch, namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, j := range all {
+		if j.VersionTag != nil && j.VersionTag.Name == name {
+			return j, nil
+		}
+	}
+	return nil, nil
+}
+
 func (s *StateStore) JobVersions(ws memdb.WatchSet) (memdb.ResultIterator, error) {
 	txn := s.db.Txn(false)
 
@@ -1822,6 +2081,9 @@ func (s *StateStore) DeleteEval(index uint64, evals []string, allocs []string) e
 		if err := txn.Delete("allocs", raw); err != nil {
 			return fmt.Errorf("alloc delete failed: %v", err)
 		}
+		if err := s.deleteServiceRegistrationsByAllocImpl(index, alloc, txn); err != nil {
+			return err
+		}
 	}
 
 	// Update the indexes
@@ -1961,6 +2223,21 @@ func (s *StateStore) EvalsByNamespace(ws memdb.WatchSet, namespace string) (memd
 	return iter, nil
 }
 
+// EvalsByNamespaceStatus returns an iterator over the evaluations in the
+// namespace with the given status
+func (s *StateStore) EvalsByNamespaceStatus(ws memdb.WatchSet, namespace, status string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("evals", "namespace_status", namespace, status)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
 // UpdateAllocsFromClient is used to update an allocation based on input
 // from a client. While the schedulers are the authority on the allocation for
 // most things, some updates are authoritative from the client. Specifically,
@@ -2056,6 +2333,14 @@ func (s *StateStore) nestedUpdateAllocFromClient(txn *memdb.Txn, index uint64, a
 		return fmt.Errorf("alloc insert failed: %v", err)
 	}
 
+	// Deregister the services of stopped allocations in case the client
+	// failed to
+	if copyAlloc.ClientTerminalStatus() {
+		if err := s.deleteServiceRegistrationsByAllocImpl(index, copyAlloc.ID, txn); err != nil {
+			return err
+		}
+	}
+
 	// Set the job's status
 	forceStatus := ""
 	if !copyAlloc.TerminalStatus() {
@@ -2166,6 +2451,13 @@ func (s *StateStore) upsertAllocsImpl(index uint64, allocs []*structs.Allocation
 			return fmt.Errorf("alloc insert failed: %v", err)
 		}
 
+		// Lost allocations can not be deregistered by their client
+		if alloc.ClientTerminalStatus() {
+			if err := s.deleteServiceRegistrationsByAllocImpl(index, alloc.ID, txn); err != nil {
+				return err
+			}
+		}
+
 		if alloc.PreviousAllocation != "" {
 			prevAlloc, err := txn.First("allocs", "id", alloc.PreviousAllocation)
 			if err != nil {
@@ -2458,6 +2750,30 @@ func (s *StateStore) AllocsByDeployment(ws memdb.WatchSet, deploymentID string)
 	return out, nil
 }
 
+// AllocsByJobDeployment returns the allocations of the job that are part of
+// the given deployment
+func (s *StateStore) AllocsByJobDeployment(ws memdb.WatchSet, namespace, jobID, deploymentID string) ([]*structs.Allocation, error) {
+	txn := s.db.Txn(false)
+
+	// Get an iterator over the job's deployment allocations
+	iter, err := txn.Get("allocs", "job_deployment", namespace, jobID, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	var out []*structs.Allocation
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		out = append(out, raw.(*structs.Allocation))
+	}
+	return out, nil
+}
+
 // Allocs returns an iterator over all the evaluations
 func (s *StateStore) Allocs(ws memdb.WatchSet) (memdb.ResultIterator, error) {
 	txn := s.db.Txn(false)
@@ -2494,6 +2810,21 @@ func (s *StateStore) allocsByNamespaceImpl(ws memdb.WatchSet, txn *memdb.Txn, na
 	return iter, nil
 }
 
+// AllocsByNamespaceStatus returns an iterator over the allocations in the
+// namespace with the given client status
+func (s *StateStore) AllocsByNamespaceStatus(ws memdb.WatchSet, namespace, status string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("allocs", "namespace_status", namespace, status)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
 // UpsertVaultAccessors is used to register a set of Vault Accessors
 func (s *StateStore) UpsertVaultAccessor(index uint64, accessors []*structs.VaultAccessor) error {
 	txn := s.db.Txn(true)
@@ -2616,31 +2947,694 @@ func (s *StateStore) VaultAccessorsByNode(ws memdb.WatchSet, nodeID string) ([]*
 	return out, nil
 }
 
-// UpdateDeploymentStatus is used to make deployment status updates and
-// potentially make a evaluation
-func (s *StateStore) UpdateDeploymentStatus(index uint64, req *structs.DeploymentStatusUpdateRequest) error {
+// UpsertServiceRegistrations is used to register a set of services. The
+// registrations of allocations that no longer exist or are client terminal
+// are skipped.
+func (s *StateStore) UpsertServiceRegistrations(index uint64, services []*structs.ServiceRegistration) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
-	if err := s.updateDeploymentStatusImpl(index, req.DeploymentUpdate, txn); err != nil {
-		return err
-	}
+	updated := false
+	for _, service := range services {
+		raw, err := txn.First("allocs", "id", service.AllocID)
+		if err != nil {
+			return fmt.Errorf("alloc lookup failed: %v", err)
+		}
+		if alloc, ok := raw.(*structs.Allocation); !ok || alloc.ClientTerminalStatus() {
+			continue
+		}
 
-	// Upsert the job if necessary
-	if req.Job != nil {
-		if err := s.upsertJobImpl(index, req.Job, false, txn); err != nil {
-			return err
+		existing, err := txn.First("service_registrations", "id", service.ID)
+		if err != nil {
+			return fmt.Errorf("service registration lookup failed: %v", err)
 		}
-	}
 
-	// Upsert the optional eval
-	if req.Eval != nil {
-		if err := s.nestedUpsertEval(txn, index, req.Eval); err != nil {
-			return err
+		if exist, ok := existing.(*structs.ServiceRegistration); ok {
+			if exist.Equals(service) {
+				continue
+			}
+			service.CreateIndex = exist.CreateIndex
+		} else {
+			service.CreateIndex = index
+		}
+		service.ModifyIndex = index
+
+		if err := txn.Insert("service_registrations", service); err != nil {
+			return fmt.Errorf("service registration insert failed: %v", err)
 		}
+		updated = true
 	}
 
-	txn.Commit()
+	if updated {
+		if err := txn.Insert("index", &IndexEntry{"service_registrations", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// DeleteServiceRegistrations is used to deregister the services with the given
+// IDs that were registered by the node.
+func (s *StateStore) DeleteServiceRegistrations(index uint64, nodeID string, ids []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	deleted := false
+	for _, id := range ids {
+		existing, err := txn.First("service_registrations", "id", id)
+		if err != nil {
+			return fmt.Errorf("service registration lookup failed: %v", err)
+		}
+		if existing == nil || existing.(*structs.ServiceRegistration).NodeID != nodeID {
+			continue
+		}
+
+		if err := txn.Delete("service_registrations", existing); err != nil {
+			return fmt.Errorf("service registration delete failed: %v", err)
+		}
+		deleted = true
+	}
+
+	if deleted {
+		if err := txn.Insert("index", &IndexEntry{"service_registrations", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// deleteServiceRegistrationsByAllocImpl deregisters all of an allocation's
+// services within an existing transaction.
+func (s *StateStore) deleteServiceRegistrationsByAllocImpl(index uint64, allocID string, txn *memdb.Txn) error {
+	num, err := txn.DeleteAll("service_registrations", "alloc_id", allocID)
+	if err != nil {
+		return fmt.Errorf("service registration delete failed: %v", err)
+	}
+	if num == 0 {
+		return nil
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"service_registrations", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// ServiceRegistrationByID returns the service registration with the given ID
+func (s *StateStore) ServiceRegistrationByID(ws memdb.WatchSet, id string) (*structs.ServiceRegistration, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("service_registrations", "id", id)
+	if err != nil {
+		return nil, fmt.Errorf("service registration lookup failed: %v", err)
+	}
+
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.ServiceRegistration), nil
+	}
+
+	return nil, nil
+}
+
+// ServiceRegistrations returns an iterator over all service registrations
+func (s *StateStore) ServiceRegistrations(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("service_registrations", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// ServiceRegistrationsByName returns all the instances of a service in a
+// namespace
+func (s *StateStore) ServiceRegistrationsByName(ws memdb.WatchSet, namespace, name string) ([]*structs.ServiceRegistration, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("service_registrations", "service_name", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	var out []*structs.ServiceRegistration
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		out = append(out, raw.(*structs.ServiceRegistration))
+	}
+	return out, nil
+}
+
+// ServiceRegistrationsByAlloc returns all the services registered by an
+// allocation
+func (s *StateStore) ServiceRegistrationsByAlloc(ws memdb.WatchSet, allocID string) ([]*structs.ServiceRegistration, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("service_registrations", "alloc_id", allocID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	var out []*structs.ServiceRegistration
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		out = append(out, raw.(*structs.ServiceRegistration))
+	}
+	return out, nil
+}
+
+// UpsertVariable is used to create or update an encrypted variable. If cas
+// is set the variable is only written if its ModifyIndex matches the one of
+// the given variable, and whether it was written is returned. The lock of the
+// variable is kept.
+func (s *StateStore) UpsertVariable(index uint64, v *structs.VariableEncrypted, cas bool) (bool, error) {
+	return s.upsertVariableImpl(index, v, cas, false)
+}
+
+// UpsertVariableLock is used to write an encrypted variable along with its
+// lock, which may be nil to release it. The variable is only written if its
+// ModifyIndex matches the one of the given variable, and whether it was
+// written is returned.
+func (s *StateStore) UpsertVariableLock(index uint64, v *structs.VariableEncrypted) (bool, error) {
+	return s.upsertVariableImpl(index, v, true, true)
+}
+
+// upsertVariableImpl is the implementation for writing a variable
+func (s *StateStore) upsertVariableImpl(index uint64, v *structs.VariableEncrypted, cas, setLock bool) (bool, error) {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	existing, err := txn.First("variables", "id", v.Namespace, v.Path)
+	if err != nil {
+		return false, fmt.Errorf("variable lookup failed: %v", err)
+	}
+
+	if exist, ok := existing.(*structs.VariableEncrypted); ok {
+		if cas && exist.ModifyIndex != v.ModifyIndex {
+			return false, nil
+		}
+		v.CreateIndex = exist.CreateIndex
+		v.CreateTime = exist.CreateTime
+		if !setLock {
+			v.Lock = exist.Lock
+		}
+	} else {
+		if cas && v.ModifyIndex != 0 {
+			return false, nil
+		}
+		v.CreateIndex = index
+		v.CreateTime = v.ModifyTime
+		if !setLock {
+			v.Lock = nil
+		}
+	}
+	v.ModifyIndex = index
+
+	if err := txn.Insert("variables", v); err != nil {
+		return false, fmt.Errorf("variable insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"variables", index}); err != nil {
+		return false, fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return true, nil
+}
+
+// DeleteVariable is used to delete a variable. If cas is set the variable is
+// only deleted if its ModifyIndex matches, and whether it was deleted is
+// returned.
+func (s *StateStore) DeleteVariable(index uint64, namespace, path string, cas bool, modifyIndex uint64) (bool, error) {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	existing, err := txn.First("variables", "id", namespace, path)
+	if err != nil {
+		return false, fmt.Errorf("variable lookup failed: %v", err)
+	}
+	if existing == nil {
+		return !cas || modifyIndex == 0, nil
+	}
+	if cas && existing.(*structs.VariableEncrypted).ModifyIndex != modifyIndex {
+		return false, nil
+	}
+
+	if err := txn.Delete("variables", existing); err != nil {
+		return false, fmt.Errorf("variable delete failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"variables", index}); err != nil {
+		return false, fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return true, nil
+}
+
+// VariableByPath returns the encrypted variable at the path in the namespace
+func (s *StateStore) VariableByPath(ws memdb.WatchSet, namespace, path string) (*structs.VariableEncrypted, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("variables", "id", namespace, path)
+	if err != nil {
+		return nil, fmt.Errorf("variable lookup failed: %v", err)
+	}
+
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.VariableEncrypted), nil
+	}
+
+	return nil, nil
+}
+
+// VariablesByNamespacePrefix returns an iterator over the variables in the
+// namespace whose path has the prefix
+func (s *StateStore) VariablesByNamespacePrefix(ws memdb.WatchSet, namespace, prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("variables", "id_prefix", namespace, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("variables lookup failed: %v", err)
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// Variables returns an iterator over all variables
+func (s *StateStore) Variables(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("variables", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// upsertNodeCSIPlugins updates the CSI plugins table with the plugins running
+// on the node, removing the node from plugins it no longer runs.
+func upsertNodeCSIPlugins(txn *memdb.Txn, node *structs.Node, index uint64) error {
+	loadOrCreate := func(id string) (*structs.CSIPlugin, error) {
+		raw, err := txn.First("csi_plugins", "id", id)
+		if err != nil {
+			return nil, fmt.Errorf("csi plugin lookup failed: %v", err)
+		}
+		if raw == nil {
+			return structs.NewCSIPlugin(id, index), nil
+		}
+		return raw.(*structs.CSIPlugin).Copy(), nil
+	}
+
+	// Plugins on down nodes can not serve requests
+	health := func(info *structs.CSIInfo) *structs.CSIInfo {
+		info = info.Copy()
+		if node.Status == structs.NodeStatusDown {
+			info.Healthy = false
+			info.HealthDescription = "node is down"
+		}
+		return info
+	}
+
+	updated := make(map[string]*structs.CSIPlugin)
+	for id, info := range node.CSIControllerPlugins {
+		plugin, err := loadOrCreate(id)
+		if err != nil {
+			return err
+		}
+		plugin.AddController(node.ID, health(info))
+		updated[id] = plugin
+	}
+	for id, info := range node.CSINodePlugins {
+		plugin, ok := updated[id]
+		if !ok {
+			var err error
+			if plugin, err = loadOrCreate(id); err != nil {
+				return err
+			}
+		}
+		plugin.AddNode(node.ID, health(info))
+		updated[id] = plugin
+	}
+
+	// Remove the node from the plugins it no longer runs
+	iter, err := txn.Get("csi_plugins", "id")
+	if err != nil {
+		return fmt.Errorf("csi plugins lookup failed: %v", err)
+	}
+	var stale []*structs.CSIPlugin
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		plugin := raw.(*structs.CSIPlugin)
+		if _, ok := updated[plugin.ID]; ok {
+			continue
+		}
+		_, controller := plugin.Controllers[node.ID]
+		_, nodePlugin := plugin.Nodes[node.ID]
+		if controller || nodePlugin {
+			stale = append(stale, plugin)
+		}
+	}
+	for _, plugin := range stale {
+		plugin = plugin.Copy()
+		plugin.DeleteController(node.ID)
+		plugin.DeleteNode(node.ID)
+		updated[plugin.ID] = plugin
+	}
+
+	for id, plugin := range updated {
+		if _, ok := node.CSIControllerPlugins[id]; !ok {
+			plugin.DeleteController(node.ID)
+		}
+		if _, ok := node.CSINodePlugins[id]; !ok {
+			plugin.DeleteNode(node.ID)
+		}
+		if err := updateOrGCCSIPlugin(txn, plugin, index); err != nil {
+			return err
+		}
+	}
+
+	if len(updated) != 0 {
+		if err := txn.Insert("index", &IndexEntry{"csi_plugins", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// deleteNodeCSIPlugins removes the node from the CSI plugins it runs
+func deleteNodeCSIPlugins(txn *memdb.Txn, node *structs.Node, index uint64) error {
+	ids := make(map[string]struct{})
+	for id := range node.CSIControllerPlugins {
+		ids[id] = struct{}{}
+	}
+	for id := range node.CSINodePlugins {
+		ids[id] = struct{}{}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for id := range ids {
+		raw, err := txn.First("csi_plugins", "id", id)
+		if err != nil {
+			return fmt.Errorf("csi plugin lookup failed: %v", err)
+		}
+		if raw == nil {
+			continue
+		}
+		plugin := raw.(*structs.CSIPlugin).Copy()
+		plugin.DeleteController(node.ID)
+		plugin.DeleteNode(node.ID)
+		if err := updateOrGCCSIPlugin(txn, plugin, index); err != nil {
+			return err
+		}
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"csi_plugins", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// updateOrGCCSIPlugin inserts the plugin or deletes it once no instances of
+// the plugin remain
+func updateOrGCCSIPlugin(txn *memdb.Txn, plugin *structs.CSIPlugin, index uint64) error {
+	if plugin.IsEmpty() {
+		existing, err := txn.First("csi_plugins", "id", plugin.ID)
+		if err != nil {
+			return fmt.Errorf("csi plugin lookup failed: %v", err)
+		}
+		if existing == nil {
+			return nil
+		}
+		if err := txn.Delete("csi_plugins", existing); err != nil {
+			return fmt.Errorf("csi plugin delete failed: %v", err)
+		}
+		return nil
+	}
+
+	plugin.ModifyIndex = index
+	if err := txn.Insert("csi_plugins", plugin); err != nil {
+		return fmt.Errorf("csi plugin insert failed: %v", err)
+	}
+	return nil
+}
+
+// CSIPlugins returns an iterator over all CSI plugins
+func (s *StateStore) CSIPlugins(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("csi_plugins", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// CSIPluginByID returns the CSI plugin with the given ID
+func (s *StateStore) CSIPluginByID(ws memdb.WatchSet, id string) (*structs.CSIPlugin, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("csi_plugins", "id", id)
+	if err != nil {
+		return nil, fmt.Errorf("csi plugin lookup failed: %v", err)
+	}
+
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.CSIPlugin), nil
+	}
+
+	return nil, nil
+}
+
+// CSIVolumeRegister is used to register a set of CSI volumes. Volumes that
+// are claimed can not be updated.
+func (s *StateStore) CSIVolumeRegister(index uint64, volumes []*structs.CSIVolume) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, volume := range volumes {
+		existing, err := txn.First("csi_volumes", "id", volume.Namespace, volume.ID)
+		if err != nil {
+			return fmt.Errorf("csi volume lookup failed: %v", err)
+		}
+
+		if existing != nil {
+			exist := existing.(*structs.CSIVolume)
+			if exist.InUse() {
+				return fmt.Errorf("csi volume %q is in use", volume.ID)
+			}
+			volume.CreateIndex = exist.CreateIndex
+		} else {
+			volume.CreateIndex = index
+		}
+		volume.ModifyIndex = index
+		volume.Canonicalize()
+
+		if err := txn.Insert("csi_volumes", volume); err != nil {
+			return fmt.Errorf("csi volume insert failed: %v", err)
+		}
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"csi_volumes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// CSIVolumeDeregister is used to deregister CSI volumes. Volumes that are
+// claimed are only deregistered if force is set.
+func (s *StateStore) CSIVolumeDeregister(index uint64, namespace string, ids []string, force bool) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, id := range ids {
+		existing, err := txn.First("csi_volumes", "id", namespace, id)
+		if err != nil {
+			return fmt.Errorf("csi volume lookup failed: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("csi volume %q not found", id)
+		}
+		if existing.(*structs.CSIVolume).InUse() && !force {
+			return fmt.Errorf("csi volume %q is in use", id)
+		}
+
+		if err := txn.Delete("csi_volumes", existing); err != nil {
+			return fmt.Errorf("csi volume delete failed: %v", err)
+		}
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"csi_volumes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// CSIVolumeClaim is used to claim a CSI volume for an allocation or record
+// the progress of releasing a claim.
+func (s *StateStore) CSIVolumeClaim(index uint64, namespace, id string, claim *structs.CSIVolumeClaim) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	existing, err := txn.First("csi_volumes", "id", namespace, id)
+	if err != nil {
+		return fmt.Errorf("csi volume lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("csi volume %q not found", id)
+	}
+
+	if claim.Mode != structs.CSIVolumeClaimRelease {
+		raw, err := txn.First("allocs", "id", claim.AllocationID)
+		if err != nil {
+			return fmt.Errorf("alloc lookup failed: %v", err)
+		}
+		if alloc, ok := raw.(*structs.Allocation); !ok || alloc.TerminalStatus() {
+			return fmt.Errorf("alloc %q is not running", claim.AllocationID)
+		}
+	}
+
+	volume := existing.(*structs.CSIVolume).Copy()
+	if err := volume.Claim(claim); err != nil {
+		return err
+	}
+	volume.ModifyIndex = index
+
+	if err := txn.Insert("csi_volumes", volume); err != nil {
+		return fmt.Errorf("csi volume insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"csi_volumes", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// CSIVolumeByID returns the CSI volume with the given ID with the health of
+// its plugin
+func (s *StateStore) CSIVolumeByID(ws memdb.WatchSet, namespace, id string) (*structs.CSIVolume, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("csi_volumes", "id", namespace, id)
+	if err != nil {
+		return nil, fmt.Errorf("csi volume lookup failed: %v", err)
+	}
+
+	ws.Add(watchCh)
+
+	if existing == nil {
+		return nil, nil
+	}
+
+	return s.CSIVolumeDenormalizePlugins(ws, existing.(*structs.CSIVolume))
+}
+
+// CSIVolumeDenormalizePlugins returns a copy of the volume with the health of
+// its plugin
+func (s *StateStore) CSIVolumeDenormalizePlugins(ws memdb.WatchSet, volume *structs.CSIVolume) (*structs.CSIVolume, error) {
+	plugin, err := s.CSIPluginByID(ws, volume.PluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	volume = volume.Copy()
+	volume.SetPluginHealth(plugin)
+	return volume, nil
+}
+
+// CSIVolumes returns an iterator over all CSI volumes
+func (s *StateStore) CSIVolumes(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("csi_volumes", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// CSIVolumesByPluginID returns an iterator over the CSI volumes provided by
+// the plugin
+func (s *StateStore) CSIVolumesByPluginID(ws memdb.WatchSet, pluginID string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("csi_volumes", "plugin_id", pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// UpdateDeploymentStatus is used to make deployment status updates and
+// potentially make a evaluation
+func (s *StateStore) UpdateDeploymentStatus(index uint64, req *structs.DeploymentStatusUpdateRequest) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	if err := s.updateDeploymentStatusImpl(index, req.DeploymentUpdate, txn); err != nil {
+		return err
+	}
+
+	// Upsert the job if necessary
+	if req.Job != nil {
+		if err := s.upsertJobImpl(index, req.Job, false, txn); err != nil {
+			return err
+		}
+	}
+
+	// Upsert the optional eval
+	if req.Eval != nil {
+		if err := s.nestedUpsertEval(txn, index, req.Eval); err != nil {
+			return err
+		}
+	}
+
+	txn.Commit()
 	return nil
 }
 
@@ -2668,71 +3662,228 @@ func (s *StateStore) updateDeploymentStatusImpl(index uint64, u *structs.Deploym
 		copy.Namespace = structs.DefaultNamespace
 	}
 
-	// Insert the deployment
-	if err := txn.Insert("deployment", copy); err != nil {
+	// Insert the deployment
+	if err := txn.Insert("deployment", copy); err != nil {
+		return err
+	}
+
+	// Update the index
+	if err := txn.Insert("index", &IndexEntry{"deployment", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	// If the deployment is being marked as complete, set the job to stable.
+	if copy.Status == structs.DeploymentStatusSuccessful {
+		if err := s.updateJobStabilityImpl(index, copy.Namespace, copy.JobID, copy.JobVersion, true, txn); err != nil {
+			return fmt.Errorf("failed to update job stability: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateJobStability updates the stability of the given job and version to the
+// desired status.
+func (s *StateStore) UpdateJobStability(index uint64, namespace, jobID string, jobVersion uint64, stable bool) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// COMPAT 0.7: Upgrade old objects that do not have namespaces
+	if namespace == "" {
+		namespace = structs.DefaultNamespace
+	}
+
+	if err := s.updateJobStabilityImpl(index, namespace, jobID, jobVersion, stable, txn); err != nil {
+		return err
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// updateJobStabilityImpl updates the stability of the given job and version
+func (s *StateStore) updateJobStabilityImpl(index uint64, namespace, jobID string, jobVersion uint64, stable bool, txn *memdb.Txn) error {
+	// COMPAT 0.7: Upgrade old objects that do not have namespaces
+	if namespace == "" {
+		namespace = structs.DefaultNamespace
+	}
+
+	// Get the job that is referenced
+	job, err := s.jobByIDAndVersionImpl(nil, namespace, jobID, jobVersion, txn)
+	if err != nil {
+		return err
+	}
+
+	// Has already been cleared, nothing to do
+	if job == nil {
+		return nil
+	}
+
+	// If the job already has the desired stability, nothing to do
+	if job.Stable == stable {
+		return nil
+	}
+
+	copy := job.Copy()
+	copy.Stable = stable
+	return s.upsertJobImpl(index, copy, true, txn)
+}
+
+// UpdateJobVersionTag applies the tag to the given job version, or removes the
+// tag with the given name if unset is set. A tag name may only be applied to
+// one version of a job.
+func (s *StateStore) UpdateJobVersionTag(index uint64, namespace, jobID string, jobVersion uint64, tag *structs.JobVersionTag, unset bool) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// COMPAT 0.7: Upgrade old objects that do not have namespaces
+	if namespace == "" {
+		namespace = structs.DefaultNamespace
+	}
+
+	tagged, err := s.jobVersionByTagImpl(nil, namespace, jobID, tag.Name, txn)
+	if err != nil {
 		return err
 	}
 
-	// Update the index
-	if err := txn.Insert("index", &IndexEntry{"deployment", index}); err != nil {
+	var job *structs.Job
+	if unset {
+		// Nothing to do if no version has the tag
+		if tagged == nil {
+			return nil
+		}
+
+		job = tagged.Copy()
+		job.VersionTag = nil
+	} else {
+		if tagged != nil && tagged.Version != jobVersion {
+			return fmt.Errorf("tag %q is already applied to version %d of job %q", tag.Name, tagged.Version, jobID)
+		}
+
+		existing, err := s.jobByIDAndVersionImpl(nil, namespace, jobID, jobVersion, txn)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return fmt.Errorf("job %q in namespace %q at version %d not found", jobID, namespace, jobVersion)
+		}
+
+		job = existing.Copy()
+		job.VersionTag = tag.Copy()
+	}
+	job.ModifyIndex = index
+
+	if err := txn.Insert("job_version", job); err != nil {
+		return fmt.Errorf("failed to insert job into job_version table: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"job_version", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
-	// If the deployment is being marked as complete, set the job to stable.
-	if copy.Status == structs.DeploymentStatusSuccessful {
-		if err := s.updateJobStabilityImpl(index, copy.Namespace, copy.JobID, copy.JobVersion, true, txn); err != nil {
-			return fmt.Errorf("failed to update job stability: %v", err)
+	// Update the job if the current version was tagged
+	current, err := txn.First("jobs", "id", namespace, jobID)
+	if err != nil {
+		return fmt.Errorf("job lookup failed: %v", err)
+	}
+	if current != nil && current.(*structs.Job).Version == job.Version {
+		if err := txn.Insert("jobs", job); err != nil {
+			return fmt.Errorf("job insert failed: %v", err)
+		}
+		if err := txn.Insert("index", &IndexEntry{"jobs", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
 		}
 	}
 
+	txn.Commit()
 	return nil
 }
 
-// UpdateJobStability updates the stability of the given job and version to the
-// desired status.
-func (s *StateStore) UpdateJobStability(index uint64, namespace, jobID string, jobVersion uint64, stable bool) error {
+// UpsertScalingEvent records a scaling event for a task group of a job,
+// dropping the oldest events of the task group beyond
+// structs.JobTrackedScalingEvents.
+func (s *StateStore) UpsertScalingEvent(index uint64, req *structs.ScalingEventRequest) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
 	// COMPAT 0.7: Upgrade old objects that do not have namespaces
+	namespace := req.Namespace
 	if namespace == "" {
 		namespace = structs.DefaultNamespace
 	}
 
-	if err := s.updateJobStabilityImpl(index, namespace, jobID, jobVersion, stable, txn); err != nil {
-		return err
+	existing, err := txn.First("scaling_event", "id", namespace, req.JobID)
+	if err != nil {
+		return fmt.Errorf("scaling event lookup failed: %v", err)
+	}
+
+	var jobEvents *structs.JobScalingEvents
+	if existing != nil {
+		jobEvents = existing.(*structs.JobScalingEvents).Copy()
+	} else {
+		jobEvents = &structs.JobScalingEvents{
+			Namespace:     namespace,
+			JobID:         req.JobID,
+			ScalingEvents: make(map[string][]*structs.ScalingEvent),
+		}
+	}
+
+	event := req.ScalingEvent.Copy()
+	event.CreateIndex = index
+
+	// Keep the newest events first
+	events := append([]*structs.ScalingEvent{event}, jobEvents.ScalingEvents[req.TaskGroup]...)
+	if len(events) > structs.JobTrackedScalingEvents {
+		events = events[:structs.JobTrackedScalingEvents]
+	}
+	jobEvents.ScalingEvents[req.TaskGroup] = events
+	jobEvents.ModifyIndex = index
+
+	if err := txn.Insert("scaling_event", jobEvents); err != nil {
+		return fmt.Errorf("scaling event insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"scaling_event", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
 	}
 
 	txn.Commit()
 	return nil
 }
 
-// updateJobStabilityImpl updates the stability of the given job and version
-func (s *StateStore) updateJobStabilityImpl(index uint64, namespace, jobID string, jobVersion uint64, stable bool, txn *memdb.Txn) error {
+// ScalingEventsByJob returns the scaling events of the task groups of a job,
+// or nil if there are none.
+func (s *StateStore) ScalingEventsByJob(ws memdb.WatchSet, namespace, jobID string) (*structs.JobScalingEvents, error) {
+	txn := s.db.Txn(false)
+
 	// COMPAT 0.7: Upgrade old objects that do not have namespaces
 	if namespace == "" {
 		namespace = structs.DefaultNamespace
 	}
 
-	// Get the job that is referenced
-	job, err := s.jobByIDAndVersionImpl(nil, namespace, jobID, jobVersion, txn)
+	watchCh, existing, err := txn.FirstWatch("scaling_event", "id", namespace, jobID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Has already been cleared, nothing to do
-	if job == nil {
-		return nil
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.JobScalingEvents), nil
 	}
+	return nil, nil
+}
 
-	// If the job already has the desired stability, nothing to do
-	if job.Stable == stable {
-		return nil
+// ScalingEvents returns an iterator over the scaling events of all jobs.
+func (s *StateStore) ScalingEvents(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("scaling_event", "id")
+	if err != nil {
+		return nil, err
 	}
 
-	copy := job.Copy()
-	copy.Stable = stable
-	return s.upsertJobImpl(index, copy, true, txn)
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
 }
 
 // UpdateDeploymentPromotion is used to promote canaries in a deployment and
@@ -2758,11 +3909,33 @@ func (s *StateStore) UpdateDeploymentPromotion(index uint64, req *structs.ApplyD
 		return err
 	}
 
-	// groupIndex is a map of groups being promoted
+	// groupIndex is a map of groups being promoted. Promoting all the groups
+	// skips the groups that must be promoted by name.
 	groupIndex := make(map[string]struct{}, len(req.Groups))
 	for _, g := range req.Groups {
 		groupIndex[g] = struct{}{}
 	}
+	if req.All {
+		var gated []string
+		pending := false
+		for tg, state := range deployment.TaskGroups {
+			needsPromotion := state.DesiredCanaries > 0 && !state.Promoted
+			if state.ManualPromotionPerGroup {
+				if needsPromotion {
+					gated = append(gated, tg)
+				}
+				continue
+			}
+			groupIndex[tg] = struct{}{}
+			pending = pending || needsPromotion
+		}
+
+		// Refuse a promotion that would only skip groups
+		if len(gated) != 0 && !pending {
+			sort.Strings(gated)
+			return fmt.Errorf("Task groups %s must be promoted by name", strings.Join(gated, ", "))
+		}
+	}
 
 	// canaryIndex is the set of placed canaries in the deployment
 	canaryIndex := make(map[string]struct{}, len(deployment.TaskGroups))
@@ -2793,7 +3966,7 @@ func (s *StateStore) UpdateDeploymentPromotion(index uint64, req *structs.ApplyD
 		}
 
 		// Check that the canary is part of a group being promoted
-		if _, ok := groupIndex[alloc.TaskGroup]; !req.All && !ok {
+		if _, ok := groupIndex[alloc.TaskGroup]; !ok {
 			continue
 		}
 
@@ -2809,7 +3982,7 @@ func (s *StateStore) UpdateDeploymentPromotion(index uint64, req *structs.ApplyD
 	// Determine if we have enough healthy allocations
 	var unhealthyErr multierror.Error
 	for tg, state := range deployment.TaskGroups {
-		if _, ok := groupIndex[tg]; !req.All && !ok {
+		if _, ok := groupIndex[tg]; !ok {
 			continue
 		}
 
@@ -2831,8 +4004,7 @@ func (s *StateStore) UpdateDeploymentPromotion(index uint64, req *structs.ApplyD
 	copy := deployment.Copy()
 	copy.ModifyIndex = index
 	for tg, status := range copy.TaskGroups {
-		_, ok := groupIndex[tg]
-		if !req.All && !ok {
+		if _, ok := groupIndex[tg]; !ok {
 			continue
 		}
 
@@ -2949,6 +4121,30 @@ func (s *StateStore) UpdateDeploymentAllocHealth(index uint64, req *structs.Appl
 		}
 	}
 
+	// Record any health overrides on the deployment
+	if len(req.HealthOverrides) != 0 {
+		existing, err := s.deploymentByIDImpl(ws, req.DeploymentID, txn)
+		if err != nil {
+			return err
+		}
+
+		deploymentCopy := existing.Copy()
+		deploymentCopy.ModifyIndex = index
+		for group, override := range req.HealthOverrides {
+			state, ok := deploymentCopy.TaskGroups[group]
+			if !ok {
+				return fmt.Errorf("deployment %q has no task group %q", req.DeploymentID, group)
+			}
+			o := override.Copy()
+			o.Timestamp = req.Timestamp
+			state.HealthOverrides = append(state.HealthOverrides, o)
+		}
+
+		if err := s.upsertDeploymentImpl(index, deploymentCopy, txn); err != nil {
+			return err
+		}
+	}
+
 	// Update the deployment status as needed.
 	if req.DeploymentUpdate != nil {
 		if err := s.updateDeploymentStatusImpl(index, req.DeploymentUpdate, txn); err != nil {
@@ -3037,22 +4233,297 @@ func (s *StateStore) Indexes() (memdb.ResultIterator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return iter, nil
+	return iter, nil
+}
+
+// ReconcileJobSummaries re-creates summaries for all jobs present in the state
+// store
+func (s *StateStore) ReconcileJobSummaries(index uint64) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// COMPAT: Remove after 0.11
+	// Build a list of parent jobs and their children
+	parentMap, err := jobChildrenByParent(txn)
+	if err != nil {
+		return err
+	}
+
+	// Get all the jobs
+	iter, err := txn.Get("jobs", "id")
+	if err != nil {
+		return err
+	}
+
+	for {
+		rawJob := iter.Next()
+		if rawJob == nil {
+			break
+		}
+		job := rawJob.(*structs.Job)
+
+		if err := s.reconcileJobSummaryImpl(txn, index, job, parentMap[job.ID]); err != nil {
+			return err
+		}
+	}
+
+	// Update the indexes table for job summary
+	if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	txn.Commit()
+	return nil
+}
+
+// ReconcileJobSummariesByID re-creates the summaries of the given jobs. Jobs
+// that are no longer registered are skipped.
+func (s *StateStore) ReconcileJobSummariesByID(index uint64, jobs []structs.NamespacedID) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, id := range jobs {
+		existing, err := txn.First("jobs", "id", id.Namespace, id.ID)
+		if err != nil {
+			return fmt.Errorf("job lookup failed: %v", err)
+		}
+		if existing == nil {
+			continue
+		}
+		job := existing.(*structs.Job)
+
+		var children []*structs.Job
+		if job.IsParameterized() || job.IsPeriodic() {
+			children, err = jobChildren(txn, job)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.reconcileJobSummaryImpl(txn, index, job, children); err != nil {
+			return err
+		}
+	}
+
+	// Update the indexes table for job summary
+	if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	txn.Commit()
+	return nil
+}
+
+// StaleJobSummaries returns the jobs whose summary does not match the summary
+// computed from their allocations or, for parent jobs, their children. Job
+// summaries are kept up to date as allocations change, so this is used to
+// find the summaries that need to be reconciled without re-creating all of
+// them.
+func (s *StateStore) StaleJobSummaries() ([]structs.NamespacedID, error) {
+	txn := s.db.Txn(false)
+
+	parentMap, err := jobChildrenByParent(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := txn.Get("jobs", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []structs.NamespacedID
+	for {
+		rawJob := iter.Next()
+		if rawJob == nil {
+			break
+		}
+		job := rawJob.(*structs.Job)
+
+		rawSummary, err := txn.First("job_summary", "id", job.Namespace, job.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Parent job summaries are only fixed up if they exist
+		isParent := job.IsParameterized() || job.IsPeriodic()
+		if isParent && rawSummary == nil {
+			continue
+		}
+
+		summary, err := s.computeJobSummary(txn, job, parentMap[job.ID])
+		if err != nil {
+			return nil, err
+		}
+
+		if rawSummary == nil || !jobSummaryCountsEqual(rawSummary.(*structs.JobSummary), summary) {
+			stale = append(stale, structs.NamespacedID{Namespace: job.Namespace, ID: job.ID})
+		}
+	}
+	return stale, nil
+}
+
+// reconcileJobSummaryImpl re-creates the summary of the job using the passed
+// transaction. The children are only used for periodic and parameterized
+// jobs.
+func (s *StateStore) reconcileJobSummaryImpl(txn *memdb.Txn, index uint64, job *structs.Job, children []*structs.Job) error {
+	summary, err := s.computeJobSummary(txn, job, children)
+	if err != nil {
+		return err
+	}
+
+	if job.IsParameterized() || job.IsPeriodic() {
+		// COMPAT: Remove after 0.11
+
+		// The following block of code fixes incorrect child summaries due to a bug
+		// See https://github.com/hashicorp/nomad/issues/3886 for details
+		rawSummary, err := txn.First("job_summary", "id", job.Namespace, job.ID)
+		if err != nil {
+			return err
+		}
+		if rawSummary == nil {
+			return nil
+		}
+
+		// Nothing to do if the summary is correct
+		if jobSummaryCountsEqual(rawSummary.(*structs.JobSummary), summary) {
+			return nil
+		}
+	}
+
+	// Set the create index of the summary same as the job's create index
+	// and the modify index to the current index
+	summary.CreateIndex = job.CreateIndex
+	summary.ModifyIndex = index
+
+	// Insert the job summary
+	if err := txn.Insert("job_summary", summary); err != nil {
+		return fmt.Errorf("error inserting job summary: %v", err)
+	}
+	return nil
+}
+
+// computeJobSummary returns the summary of the job as computed from its
+// allocations or, for periodic and parameterized jobs, from the passed
+// children. The indexes of the returned summary are not set.
+func (s *StateStore) computeJobSummary(txn *memdb.Txn, job *structs.Job, children []*structs.Job) (*structs.JobSummary, error) {
+	if job.IsParameterized() || job.IsPeriodic() {
+		// Create an empty summary
+		summary := &structs.JobSummary{
+			JobID:     job.ID,
+			Namespace: job.Namespace,
+			Summary:   make(map[string]structs.TaskGroupSummary),
+			Children:  &structs.JobChildrenSummary{},
+		}
+
+		// Iterate over children of this job if any to fix summary counts
+		for _, childJob := range children {
+			switch childJob.Status {
+			case structs.JobStatusPending:
+				summary.Children.Pending++
+			case structs.JobStatusDead:
+				summary.Children.Dead++
+			case structs.JobStatusRunning:
+				summary.Children.Running++
+			}
+		}
+		return summary, nil
+	}
+
+	// Create a job summary for the job
+	summary := &structs.JobSummary{
+		JobID:     job.ID,
+		Namespace: job.Namespace,
+		Summary:   make(map[string]structs.TaskGroupSummary),
+	}
+	for _, tg := range job.TaskGroups {
+		summary.Summary[tg.Name] = structs.TaskGroupSummary{}
+	}
+
+	// COMPAT 0.7: Upgrade old objects that do not have namespaces. The job
+	// may be shared with readers, so it isn't modified.
+	namespace := job.Namespace
+	if namespace == "" {
+		namespace = structs.DefaultNamespace
+	}
+
+	// Find all the allocations for the jobs
+	iterAllocs, err := txn.Get("allocs", "job", namespace, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate the summary for the job
+	for {
+		rawAlloc := iterAllocs.Next()
+		if rawAlloc == nil {
+			break
+		}
+		alloc := rawAlloc.(*structs.Allocation)
+
+		// Ignore the allocation if it doesn't belong to the currently
+		// registered job. The allocation is checked because of issue #2304
+		if alloc.Job == nil || alloc.Job.CreateIndex != job.CreateIndex {
+			continue
+		}
+
+		tg := summary.Summary[alloc.TaskGroup]
+		switch alloc.ClientStatus {
+		case structs.AllocClientStatusFailed:
+			tg.Failed += 1
+		case structs.AllocClientStatusLost:
+			tg.Lost += 1
+		case structs.AllocClientStatusComplete:
+			tg.Complete += 1
+		case structs.AllocClientStatusRunning:
+			tg.Running += 1
+		case structs.AllocClientStatusPending:
+			tg.Starting += 1
+		default:
+			s.logger.Error("invalid client status set on allocation", "client_status", alloc.ClientStatus, "alloc_id", alloc.ID)
+		}
+		summary.Summary[alloc.TaskGroup] = tg
+	}
+	return summary, nil
 }
 
-// ReconcileJobSummaries re-creates summaries for all jobs present in the state
-// store
-func (s *StateStore) ReconcileJobSummaries(index uint64) error {
-	txn := s.db.Txn(true)
-	defer txn.Abort()
+// jobSummaryCountsEqual returns whether the allocation and children counts of
+// the summaries are equal. Queued counts are ignored since they are not
+// derived from allocations. A missing children summary is equal to an empty
+// one.
+func jobSummaryCountsEqual(a, b *structs.JobSummary) bool {
+	var childrenA, childrenB structs.JobChildrenSummary
+	if a.Children != nil {
+		childrenA = *a.Children
+	}
+	if b.Children != nil {
+		childrenB = *b.Children
+	}
+	if childrenA != childrenB {
+		return false
+	}
+	if len(a.Summary) != len(b.Summary) {
+		return false
+	}
+	for name, tgA := range a.Summary {
+		tgB, ok := b.Summary[name]
+		if !ok {
+			return false
+		}
+		tgA.Queued, tgB.Queued = 0, 0
+		if tgA != tgB {
+			return false
+		}
+	}
+	return true
+}
 
-	// Get all the jobs
+// jobChildrenByParent returns the child jobs of all parent jobs, keyed by the
+// parent's ID.
+func jobChildrenByParent(txn *memdb.Txn) (map[string][]*structs.Job, error) {
 	iter, err := txn.Get("jobs", "id")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// COMPAT: Remove after 0.11
-	// Iterate over jobs to build a list of parent jobs and their children
+
 	parentMap := make(map[string][]*structs.Job)
 	for {
 		rawJob := iter.Next()
@@ -3061,147 +4532,32 @@ func (s *StateStore) ReconcileJobSummaries(index uint64) error {
 		}
 		job := rawJob.(*structs.Job)
 		if job.ParentID != "" {
-			children := parentMap[job.ParentID]
-			children = append(children, job)
-			parentMap[job.ParentID] = children
+			parentMap[job.ParentID] = append(parentMap[job.ParentID], job)
 		}
 	}
+	return parentMap, nil
+}
 
-	// Get all the jobs again
-	iter, err = txn.Get("jobs", "id")
+// jobChildren returns the child jobs of the parent job. Child job IDs are
+// prefixed with the ID of their parent.
+func jobChildren(txn *memdb.Txn, parent *structs.Job) ([]*structs.Job, error) {
+	iter, err := txn.Get("jobs", "id_prefix", parent.Namespace, parent.ID+"/")
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("job lookup failed: %v", err)
 	}
 
+	var children []*structs.Job
 	for {
 		rawJob := iter.Next()
 		if rawJob == nil {
 			break
 		}
 		job := rawJob.(*structs.Job)
-
-		if job.IsParameterized() || job.IsPeriodic() {
-			// COMPAT: Remove after 0.11
-
-			// The following block of code fixes incorrect child summaries due to a bug
-			// See https://github.com/hashicorp/nomad/issues/3886 for details
-			rawSummary, err := txn.First("job_summary", "id", job.Namespace, job.ID)
-			if err != nil {
-				return err
-			}
-			if rawSummary == nil {
-				continue
-			}
-
-			oldSummary := rawSummary.(*structs.JobSummary)
-
-			// Create an empty summary
-			summary := &structs.JobSummary{
-				JobID:     job.ID,
-				Namespace: job.Namespace,
-				Summary:   make(map[string]structs.TaskGroupSummary),
-				Children:  &structs.JobChildrenSummary{},
-			}
-
-			// Iterate over children of this job if any to fix summary counts
-			children := parentMap[job.ID]
-			for _, childJob := range children {
-				switch childJob.Status {
-				case structs.JobStatusPending:
-					summary.Children.Pending++
-				case structs.JobStatusDead:
-					summary.Children.Dead++
-				case structs.JobStatusRunning:
-					summary.Children.Running++
-				}
-			}
-
-			// Insert the job summary if its different
-			if !reflect.DeepEqual(summary, oldSummary) {
-				// Set the create index of the summary same as the job's create index
-				// and the modify index to the current index
-				summary.CreateIndex = job.CreateIndex
-				summary.ModifyIndex = index
-
-				if err := txn.Insert("job_summary", summary); err != nil {
-					return fmt.Errorf("error inserting job summary: %v", err)
-				}
-			}
-
-			// Done with handling a parent job, continue to next
-			continue
-		}
-
-		// Create a job summary for the job
-		summary := &structs.JobSummary{
-			JobID:     job.ID,
-			Namespace: job.Namespace,
-			Summary:   make(map[string]structs.TaskGroupSummary),
-		}
-		for _, tg := range job.TaskGroups {
-			summary.Summary[tg.Name] = structs.TaskGroupSummary{}
-		}
-
-		// COMPAT 0.7: Upgrade old objects that do not have namespaces
-		if job.Namespace == "" {
-			job.Namespace = structs.DefaultNamespace
-		}
-
-		// Find all the allocations for the jobs
-		iterAllocs, err := txn.Get("allocs", "job", job.Namespace, job.ID)
-		if err != nil {
-			return err
-		}
-
-		// Calculate the summary for the job
-		for {
-			rawAlloc := iterAllocs.Next()
-			if rawAlloc == nil {
-				break
-			}
-			alloc := rawAlloc.(*structs.Allocation)
-
-			// Ignore the allocation if it doesn't belong to the currently
-			// registered job. The allocation is checked because of issue #2304
-			if alloc.Job == nil || alloc.Job.CreateIndex != job.CreateIndex {
-				continue
-			}
-
-			tg := summary.Summary[alloc.TaskGroup]
-			switch alloc.ClientStatus {
-			case structs.AllocClientStatusFailed:
-				tg.Failed += 1
-			case structs.AllocClientStatusLost:
-				tg.Lost += 1
-			case structs.AllocClientStatusComplete:
-				tg.Complete += 1
-			case structs.AllocClientStatusRunning:
-				tg.Running += 1
-			case structs.AllocClientStatusPending:
-				tg.Starting += 1
-			default:
-				s.logger.Error("invalid client status set on allocation", "client_status", alloc.ClientStatus, "alloc_id", alloc.ID)
-			}
-			summary.Summary[alloc.TaskGroup] = tg
-		}
-
-		// Set the create index of the summary same as the job's create index
-		// and the modify index to the current index
-		summary.CreateIndex = job.CreateIndex
-		summary.ModifyIndex = index
-
-		// Insert the job summary
-		if err := txn.Insert("job_summary", summary); err != nil {
-			return fmt.Errorf("error inserting job summary: %v", err)
+		if job.ParentID == parent.ID {
+			children = append(children, job)
 		}
 	}
-
-	// Update the indexes table for job summary
-	if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
-		return fmt.Errorf("index update failed: %v", err)
-	}
-	txn.Commit()
-	return nil
+	return children, nil
 }
 
 // setJobStatuses is a helper for calling setJobStatus on multiple jobs by ID.
@@ -3665,85 +5021,411 @@ func (s *StateStore) updateSummaryWithAlloc(index uint64, alloc *structs.Allocat
 			s.logger.Error("invalid old client status for allocatio",
 				"alloc_id", existingAlloc.ID, "client_status", existingAlloc.ClientStatus)
 		}
-		summaryChanged = true
+		summaryChanged = true
+	}
+	jobSummary.Summary[alloc.TaskGroup] = tgSummary
+
+	if summaryChanged {
+		jobSummary.ModifyIndex = index
+
+		// COMPAT 0.7: Upgrade old objects that do not have namespaces
+		if jobSummary.Namespace == "" {
+			jobSummary.Namespace = structs.DefaultNamespace
+		}
+
+		// Update the indexes table for job summary
+		if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+
+		if err := txn.Insert("job_summary", jobSummary); err != nil {
+			return fmt.Errorf("updating job summary failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// GCConfigRestore is used to restore the GC configuration
+func (r *StateRestore) GCConfigRestore(config *structs.GCConfiguration) error {
+	if err := r.txn.Insert("gc_config", config); err != nil {
+		return fmt.Errorf("inserting GC config failed: %s", err)
+	}
+	return nil
+}
+
+// addEphemeralDiskToTaskGroups adds missing EphemeralDisk objects to TaskGroups
+func (s *StateStore) addEphemeralDiskToTaskGroups(job *structs.Job) {
+	for _, tg := range job.TaskGroups {
+		var diskMB int
+		for _, task := range tg.Tasks {
+			if task.Resources != nil {
+				diskMB += task.Resources.DiskMB
+				task.Resources.DiskMB = 0
+			}
+		}
+		if tg.EphemeralDisk != nil {
+			continue
+		}
+		tg.EphemeralDisk = &structs.EphemeralDisk{
+			SizeMB: diskMB,
+		}
+	}
+}
+
+// UpsertACLPolicies is used to create or update a set of ACL policies
+func (s *StateStore) UpsertACLPolicies(index uint64, policies []*structs.ACLPolicy) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, policy := range policies {
+		// Ensure the policy hash is non-nil. This should be done outside the state store
+		// for performance reasons, but we check here for defense in depth.
+		if len(policy.Hash) == 0 {
+			policy.SetHash()
+		}
+
+		// Check if the policy already exists
+		existing, err := txn.First("acl_policy", "id", policy.Name)
+		if err != nil {
+			return fmt.Errorf("policy lookup failed: %v", err)
+		}
+
+		// Update all the indexes
+		if existing != nil {
+			policy.CreateIndex = existing.(*structs.ACLPolicy).CreateIndex
+			policy.ModifyIndex = index
+		} else {
+			policy.CreateIndex = index
+			policy.ModifyIndex = index
+		}
+
+		// Update the policy
+		if err := txn.Insert("acl_policy", policy); err != nil {
+			return fmt.Errorf("upserting policy failed: %v", err)
+		}
+	}
+
+	// Update the indexes tabl
+	if err := txn.Insert("index", &IndexEntry{"acl_policy", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// DeleteACLPolicies deletes the policies with the given names
+func (s *StateStore) DeleteACLPolicies(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Delete the policy
+	for _, name := range names {
+		if _, err := txn.DeleteAll("acl_policy", "id", name); err != nil {
+			return fmt.Errorf("deleting acl policy failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_policy", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	txn.Commit()
+	return nil
+}
+
+// ACLPolicyByName is used to lookup a policy by name
+func (s *StateStore) ACLPolicyByName(ws memdb.WatchSet, name string) (*structs.ACLPolicy, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("acl_policy", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("acl policy lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.ACLPolicy), nil
+	}
+	return nil, nil
+}
+
+// ACLPolicyByNamePrefix is used to lookup policies by prefix
+func (s *StateStore) ACLPolicyByNamePrefix(ws memdb.WatchSet, prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("acl_policy", "id_prefix", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("acl policy lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// ACLPolicies returns an iterator over all the acl policies
+func (s *StateStore) ACLPolicies(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("acl_policy", "id")
+	if err != nil {
+		return nil, err
+	}
+	ws.Add(iter.WatchCh())
+	return iter, nil
+}
+
+// UpsertACLRoles is used to create or update a set of ACL roles
+func (s *StateStore) UpsertACLRoles(index uint64, roles []*structs.ACLRole) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, role := range roles {
+		// Ensure the role hash is non-nil. This should be done outside the state store
+		// for performance reasons, but we check here for defense in depth.
+		if len(role.Hash) == 0 {
+			role.SetHash()
+		}
+
+		// Check if the role already exists
+		existing, err := txn.First("acl_role", "id", role.Name)
+		if err != nil {
+			return fmt.Errorf("role lookup failed: %v", err)
+		}
+
+		// Update all the indexes
+		if existing != nil {
+			role.CreateIndex = existing.(*structs.ACLRole).CreateIndex
+			role.ModifyIndex = index
+		} else {
+			role.CreateIndex = index
+			role.ModifyIndex = index
+		}
+
+		// Update the role
+		if err := txn.Insert("acl_role", role); err != nil {
+			return fmt.Errorf("upserting role failed: %v", err)
+		}
 	}
-	jobSummary.Summary[alloc.TaskGroup] = tgSummary
 
-	if summaryChanged {
-		jobSummary.ModifyIndex = index
+	// Update the indexes table
+	if err := txn.Insert("index", &IndexEntry{"acl_role", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
 
-		// COMPAT 0.7: Upgrade old objects that do not have namespaces
-		if jobSummary.Namespace == "" {
-			jobSummary.Namespace = structs.DefaultNamespace
+	txn.Commit()
+	return nil
+}
+
+// DeleteACLRoles deletes the roles with the given names
+func (s *StateStore) DeleteACLRoles(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Delete the role
+	for _, name := range names {
+		if _, err := txn.DeleteAll("acl_role", "id", name); err != nil {
+			return fmt.Errorf("deleting acl role failed: %v", err)
 		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_role", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	txn.Commit()
+	return nil
+}
 
-		// Update the indexes table for job summary
-		if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
-			return fmt.Errorf("index update failed: %v", err)
+// ACLRoleByName is used to lookup a role by name
+func (s *StateStore) ACLRoleByName(ws memdb.WatchSet, name string) (*structs.ACLRole, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("acl_role", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("acl role lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.ACLRole), nil
+	}
+	return nil, nil
+}
+
+// ACLRoleByNamePrefix is used to lookup roles by prefix
+func (s *StateStore) ACLRoleByNamePrefix(ws memdb.WatchSet, prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("acl_role", "id_prefix", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("acl role lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// ACLRoles returns an iterator over all the acl roles
+func (s *StateStore) ACLRoles(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("acl_role", "id")
+	if err != nil {
+		return nil, err
+	}
+	ws.Add(iter.WatchCh())
+	return iter, nil
+}
+
+// UpsertACLAuthMethods is used to create or update a set of ACL auth methods
+func (s *StateStore) UpsertACLAuthMethods(index uint64, methods []*structs.ACLAuthMethod) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, method := range methods {
+		// Ensure the auth method hash is non-nil. This should be done outside the state store
+		// for performance reasons, but we check here for defense in depth.
+		if len(method.Hash) == 0 {
+			method.SetHash()
 		}
 
-		if err := txn.Insert("job_summary", jobSummary); err != nil {
-			return fmt.Errorf("updating job summary failed: %v", err)
+		// Check if the auth method already exists
+		existing, err := txn.First("acl_auth_method", "id", method.Name)
+		if err != nil {
+			return fmt.Errorf("auth method lookup failed: %v", err)
+		}
+
+		// Update all the indexes
+		if existing != nil {
+			method.CreateIndex = existing.(*structs.ACLAuthMethod).CreateIndex
+			method.ModifyIndex = index
+		} else {
+			method.CreateIndex = index
+			method.ModifyIndex = index
+		}
+
+		// Update the auth method
+		if err := txn.Insert("acl_auth_method", method); err != nil {
+			return fmt.Errorf("upserting auth method failed: %v", err)
 		}
 	}
 
+	// Update the indexes table
+	if err := txn.Insert("index", &IndexEntry{"acl_auth_method", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
 	return nil
 }
 
-// addEphemeralDiskToTaskGroups adds missing EphemeralDisk objects to TaskGroups
-func (s *StateStore) addEphemeralDiskToTaskGroups(job *structs.Job) {
-	for _, tg := range job.TaskGroups {
-		var diskMB int
-		for _, task := range tg.Tasks {
-			if task.Resources != nil {
-				diskMB += task.Resources.DiskMB
-				task.Resources.DiskMB = 0
-			}
+// DeleteACLAuthMethods deletes the auth methods with the given names, along
+// with their binding rules
+func (s *StateStore) DeleteACLAuthMethods(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Delete the auth methods and their binding rules
+	deletedRules := false
+	for _, name := range names {
+		if _, err := txn.DeleteAll("acl_auth_method", "id", name); err != nil {
+			return fmt.Errorf("deleting acl auth method failed: %v", err)
 		}
-		if tg.EphemeralDisk != nil {
-			continue
+		n, err := txn.DeleteAll("acl_binding_rule", "auth_method", name)
+		if err != nil {
+			return fmt.Errorf("deleting acl binding rules failed: %v", err)
 		}
-		tg.EphemeralDisk = &structs.EphemeralDisk{
-			SizeMB: diskMB,
+		deletedRules = deletedRules || n > 0
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_auth_method", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	if deletedRules {
+		if err := txn.Insert("index", &IndexEntry{"acl_binding_rule", index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
 		}
 	}
+	txn.Commit()
+	return nil
 }
 
-// UpsertACLPolicies is used to create or update a set of ACL policies
-func (s *StateStore) UpsertACLPolicies(index uint64, policies []*structs.ACLPolicy) error {
+// ACLAuthMethodByName is used to lookup an auth method by name
+func (s *StateStore) ACLAuthMethodByName(ws memdb.WatchSet, name string) (*structs.ACLAuthMethod, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("acl_auth_method", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("acl auth method lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.ACLAuthMethod), nil
+	}
+	return nil, nil
+}
+
+// ACLAuthMethodByNamePrefix is used to lookup auth methods by prefix
+func (s *StateStore) ACLAuthMethodByNamePrefix(ws memdb.WatchSet, prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("acl_auth_method", "id_prefix", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("acl auth method lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// ACLAuthMethods returns an iterator over all the acl auth methods
+func (s *StateStore) ACLAuthMethods(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("acl_auth_method", "id")
+	if err != nil {
+		return nil, err
+	}
+	ws.Add(iter.WatchCh())
+	return iter, nil
+}
+
+// UpsertACLBindingRules is used to create or update a set of ACL binding rules
+func (s *StateStore) UpsertACLBindingRules(index uint64, rules []*structs.ACLBindingRule) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
-	for _, policy := range policies {
-		// Ensure the policy hash is non-nil. This should be done outside the state store
+	for _, rule := range rules {
+		// Ensure the binding rule hash is non-nil. This should be done outside the state store
 		// for performance reasons, but we check here for defense in depth.
-		if len(policy.Hash) == 0 {
-			policy.SetHash()
+		if len(rule.Hash) == 0 {
+			rule.SetHash()
 		}
 
-		// Check if the policy already exists
-		existing, err := txn.First("acl_policy", "id", policy.Name)
+		// Check if the binding rule already exists
+		existing, err := txn.First("acl_binding_rule", "id", rule.ID)
 		if err != nil {
-			return fmt.Errorf("policy lookup failed: %v", err)
+			return fmt.Errorf("binding rule lookup failed: %v", err)
 		}
 
 		// Update all the indexes
 		if existing != nil {
-			policy.CreateIndex = existing.(*structs.ACLPolicy).CreateIndex
-			policy.ModifyIndex = index
+			rule.CreateIndex = existing.(*structs.ACLBindingRule).CreateIndex
+			rule.ModifyIndex = index
 		} else {
-			policy.CreateIndex = index
-			policy.ModifyIndex = index
+			rule.CreateIndex = index
+			rule.ModifyIndex = index
 		}
 
-		// Update the policy
-		if err := txn.Insert("acl_policy", policy); err != nil {
-			return fmt.Errorf("upserting policy failed: %v", err)
+		// Update the binding rule
+		if err := txn.Insert("acl_binding_rule", rule); err != nil {
+			return fmt.Errorf("upserting binding rule failed: %v", err)
 		}
 	}
 
-	// Update the indexes tabl
-	if err := txn.Insert("index", &IndexEntry{"acl_policy", index}); err != nil {
+	// Update the indexes table
+	if err := txn.Insert("index", &IndexEntry{"acl_binding_rule", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
@@ -3751,59 +5433,60 @@ func (s *StateStore) UpsertACLPolicies(index uint64, policies []*structs.ACLPoli
 	return nil
 }
 
-// DeleteACLPolicies deletes the policies with the given names
-func (s *StateStore) DeleteACLPolicies(index uint64, names []string) error {
+// DeleteACLBindingRules deletes the binding rules with the given IDs
+func (s *StateStore) DeleteACLBindingRules(index uint64, ids []string) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
-	// Delete the policy
-	for _, name := range names {
-		if _, err := txn.DeleteAll("acl_policy", "id", name); err != nil {
-			return fmt.Errorf("deleting acl policy failed: %v", err)
+	// Delete the binding rules
+	for _, id := range ids {
+		if _, err := txn.DeleteAll("acl_binding_rule", "id", id); err != nil {
+			return fmt.Errorf("deleting acl binding rule failed: %v", err)
 		}
 	}
-	if err := txn.Insert("index", &IndexEntry{"acl_policy", index}); err != nil {
+	if err := txn.Insert("index", &IndexEntry{"acl_binding_rule", index}); err != nil {
 		return fmt.Errorf("index update failed: %v", err)
 	}
 	txn.Commit()
 	return nil
 }
 
-// ACLPolicyByName is used to lookup a policy by name
-func (s *StateStore) ACLPolicyByName(ws memdb.WatchSet, name string) (*structs.ACLPolicy, error) {
+// ACLBindingRuleByID is used to lookup a binding rule by ID
+func (s *StateStore) ACLBindingRuleByID(ws memdb.WatchSet, id string) (*structs.ACLBindingRule, error) {
 	txn := s.db.Txn(false)
 
-	watchCh, existing, err := txn.FirstWatch("acl_policy", "id", name)
+	watchCh, existing, err := txn.FirstWatch("acl_binding_rule", "id", id)
 	if err != nil {
-		return nil, fmt.Errorf("acl policy lookup failed: %v", err)
+		return nil, fmt.Errorf("acl binding rule lookup failed: %v", err)
 	}
 	ws.Add(watchCh)
 
 	if existing != nil {
-		return existing.(*structs.ACLPolicy), nil
+		return existing.(*structs.ACLBindingRule), nil
 	}
 	return nil, nil
 }
 
-// ACLPolicyByNamePrefix is used to lookup policies by prefix
-func (s *StateStore) ACLPolicyByNamePrefix(ws memdb.WatchSet, prefix string) (memdb.ResultIterator, error) {
+// ACLBindingRulesByAuthMethod returns an iterator over the binding rules of
+// an auth method
+func (s *StateStore) ACLBindingRulesByAuthMethod(ws memdb.WatchSet, method string) (memdb.ResultIterator, error) {
 	txn := s.db.Txn(false)
 
-	iter, err := txn.Get("acl_policy", "id_prefix", prefix)
+	iter, err := txn.Get("acl_binding_rule", "auth_method", method)
 	if err != nil {
-		return nil, fmt.Errorf("acl policy lookup failed: %v", err)
+		return nil, fmt.Errorf("acl binding rule lookup failed: %v", err)
 	}
 	ws.Add(iter.WatchCh())
 
 	return iter, nil
 }
 
-// ACLPolicies returns an iterator over all the acl policies
-func (s *StateStore) ACLPolicies(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+// ACLBindingRules returns an iterator over all the acl binding rules
+func (s *StateStore) ACLBindingRules(ws memdb.WatchSet) (memdb.ResultIterator, error) {
 	txn := s.db.Txn(false)
 
 	// Walk the entire table
-	iter, err := txn.Get("acl_policy", "id")
+	iter, err := txn.Get("acl_binding_rule", "id")
 	if err != nil {
 		return nil, err
 	}
@@ -4095,6 +5778,87 @@ func (s *StateStore) schedulerSetConfigTxn(idx uint64, tx *memdb.Txn, config *st
 	return nil
 }
 
+// GCConfig is used to get the GC configuration set at runtime. A nil config
+// is returned if operators never set one.
+func (s *StateStore) GCConfig() (uint64, *structs.GCConfiguration, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	c, err := tx.First("gc_config", "id")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed GC config lookup: %s", err)
+	}
+
+	config, ok := c.(*structs.GCConfiguration)
+	if !ok {
+		return 0, nil, nil
+	}
+
+	return config.ModifyIndex, config, nil
+}
+
+// GCSetConfig is used to set the GC configuration.
+func (s *StateStore) GCSetConfig(idx uint64, config *structs.GCConfiguration) error {
+	tx := s.db.Txn(true)
+	defer tx.Abort()
+
+	if err := s.gcSetConfigTxn(idx, tx, config); err != nil {
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// GCCASConfig is used to update the GC configuration with a given Raft
+// index. If the CAS index specified is not equal to the last observed index
+// for the config, then the call is a noop. A CAS index of zero only succeeds
+// if no config has been set yet.
+func (s *StateStore) GCCASConfig(idx, cidx uint64, config *structs.GCConfiguration) (bool, error) {
+	tx := s.db.Txn(true)
+	defer tx.Abort()
+
+	existing, err := tx.First("gc_config", "id")
+	if err != nil {
+		return false, fmt.Errorf("failed GC config lookup: %s", err)
+	}
+
+	var modifyIndex uint64
+	if existing != nil {
+		modifyIndex = existing.(*structs.GCConfiguration).ModifyIndex
+	}
+	if modifyIndex != cidx {
+		return false, nil
+	}
+
+	if err := s.gcSetConfigTxn(idx, tx, config); err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+	return true, nil
+}
+
+func (s *StateStore) gcSetConfigTxn(idx uint64, tx *memdb.Txn, config *structs.GCConfiguration) error {
+	existing, err := tx.First("gc_config", "id")
+	if err != nil {
+		return fmt.Errorf("failed GC config lookup: %s", err)
+	}
+
+	// Set the indexes.
+	if existing != nil {
+		config.CreateIndex = existing.(*structs.GCConfiguration).CreateIndex
+	} else {
+		config.CreateIndex = idx
+	}
+	config.ModifyIndex = idx
+
+	if err := tx.Insert("gc_config", config); err != nil {
+		return fmt.Errorf("failed updating GC config: %s", err)
+	}
+	return nil
+}
+
 // StateSnapshot is used to provide a point-in-time snapshot
 type StateSnapshot struct {
 	StateStore
@@ -4173,6 +5937,14 @@ func (r *StateRestore) JobSummaryRestore(jobSummary *structs.JobSummary) error {
 	return nil
 }
 
+// ScalingEventsRestore is used to restore the scaling events of a job
+func (r *StateRestore) ScalingEventsRestore(jobEvents *structs.JobScalingEvents) error {
+	if err := r.txn.Insert("scaling_event", jobEvents); err != nil {
+		return fmt.Errorf("scaling event insert failed: %v", err)
+	}
+	return nil
+}
+
 // JobVersionRestore is used to restore a job version
 func (r *StateRestore) JobVersionRestore(version *structs.Job) error {
 	if err := r.txn.Insert("job_version", version); err != nil {
@@ -4189,6 +5961,38 @@ func (r *StateRestore) DeploymentRestore(deployment *structs.Deployment) error {
 	return nil
 }
 
+// ServiceRegistrationRestore is used to restore a service registration
+func (r *StateRestore) ServiceRegistrationRestore(service *structs.ServiceRegistration) error {
+	if err := r.txn.Insert("service_registrations", service); err != nil {
+		return fmt.Errorf("service registration insert failed: %v", err)
+	}
+	return nil
+}
+
+// VariableRestore is used to restore an encrypted variable
+func (r *StateRestore) VariableRestore(v *structs.VariableEncrypted) error {
+	if err := r.txn.Insert("variables", v); err != nil {
+		return fmt.Errorf("variable insert failed: %v", err)
+	}
+	return nil
+}
+
+// CSIVolumeRestore is used to restore a CSI volume
+func (r *StateRestore) CSIVolumeRestore(volume *structs.CSIVolume) error {
+	if err := r.txn.Insert("csi_volumes", volume); err != nil {
+		return fmt.Errorf("csi volume insert failed: %v", err)
+	}
+	return nil
+}
+
+// CSIPluginRestore is used to restore a CSI plugin
+func (r *StateRestore) CSIPluginRestore(plugin *structs.CSIPlugin) error {
+	if err := r.txn.Insert("csi_plugins", plugin); err != nil {
+		return fmt.Errorf("csi plugin insert failed: %v", err)
+	}
+	return nil
+}
+
 // VaultAccessorRestore is used to restore a vault accessor
 func (r *StateRestore) VaultAccessorRestore(accessor *structs.VaultAccessor) error {
 	if err := r.txn.Insert("vault_accessors", accessor); err != nil {
@@ -4205,6 +6009,30 @@ func (r *StateRestore) ACLPolicyRestore(policy *structs.ACLPolicy) error {
 	return nil
 }
 
+// ACLRoleRestore is used to restore an ACL role
+func (r *StateRestore) ACLRoleRestore(role *structs.ACLRole) error {
+	if err := r.txn.Insert("acl_role", role); err != nil {
+		return fmt.Errorf("inserting acl role failed: %v", err)
+	}
+	return nil
+}
+
+// ACLAuthMethodRestore is used to restore an ACL auth method
+func (r *StateRestore) ACLAuthMethodRestore(method *structs.ACLAuthMethod) error {
+	if err := r.txn.Insert("acl_auth_method", method); err != nil {
+		return fmt.Errorf("inserting acl auth method failed: %v", err)
+	}
+	return nil
+}
+
+// ACLBindingRuleRestore is used to restore an ACL binding rule
+func (r *StateRestore) ACLBindingRuleRestore(rule *structs.ACLBindingRule) error {
+	if err := r.txn.Insert("acl_binding_rule", rule); err != nil {
+		return fmt.Errorf("inserting acl binding rule failed: %v", err)
+	}
+	return nil
+}
+
 // ACLTokenRestore is used to restore an ACL token
 func (r *StateRestore) ACLTokenRestore(token *structs.ACLToken) error {
 	if err := r.txn.Insert("acl_token", token); err != nil {
@@ -140,6 +140,57 @@ func TestStateStore_UpsertPlanResults_AllocationsCreated_Denormalized(t *testing
 	assert.EqualValues(1000, evalOut.ModifyIndex)
 }
 
+func TestStateStore_UpsertPlanResultsBatch(t *testing.T) {
+	state := testStateStore(t)
+	require := require.New(t)
+
+	// Create the results of two plans, the second evicting the alloc placed
+	// by the first
+	alloc := mock.Alloc()
+	job := alloc.Job
+	alloc.Job = nil
+	require.NoError(state.UpsertJob(999, job))
+
+	eval := mock.Eval()
+	eval.JobID = job.ID
+	require.NoError(state.UpsertEvals(1, []*structs.Evaluation{eval}))
+
+	evict := alloc.Copy()
+	evict.DesiredStatus = structs.AllocDesiredStatusEvict
+	results := []*structs.ApplyPlanResultsRequest{
+		{
+			AllocUpdateRequest: structs.AllocUpdateRequest{
+				Alloc: []*structs.Allocation{alloc},
+				Job:   job,
+			},
+			EvalID: eval.ID,
+		},
+		{
+			AllocUpdateRequest: structs.AllocUpdateRequest{
+				Alloc: []*structs.Allocation{evict},
+				Job:   job,
+			},
+			EvalID: eval.ID,
+		},
+	}
+	require.NoError(state.UpsertPlanResultsBatch(1000, results))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.AllocByID(ws, alloc.ID)
+	require.NoError(err)
+	require.Equal(structs.AllocDesiredStatusEvict, out.DesiredStatus)
+	require.EqualValues(1000, out.CreateIndex)
+	require.NotNil(out.Job)
+
+	index, err := state.Index("allocs")
+	require.NoError(err)
+	require.EqualValues(1000, index)
+
+	evalOut, err := state.EvalByID(ws, eval.ID)
+	require.NoError(err)
+	require.EqualValues(1000, evalOut.ModifyIndex)
+}
+
 // This test checks that the deployment is created and allocations count towards
 // the deployment
 func TestStateStore_UpsertPlanResults_Deployment(t *testing.T) {
@@ -1045,6 +1096,133 @@ func TestStateStore_UpdateNodeEligibility(t *testing.T) {
 	require.Contains(err.Error(), "while it is draining")
 }
 
+func TestStateStore_UpdateNodeScaleIn(t *testing.T) {
+	require := require.New(t)
+	state := testStateStore(t)
+	node := mock.Node()
+	require.Nil(state.UpsertNode(1000, node))
+
+	// Start the scale-in
+	scaleIn := &structs.NodeScaleIn{RequestedAt: time.Now().UnixNano()}
+	drain := &structs.DrainStrategy{
+		DrainSpec: structs.DrainSpec{IgnoreSystemJobs: true},
+	}
+	ws := memdb.NewWatchSet()
+	_, err := state.NodeByID(ws, node.ID)
+	require.Nil(err)
+	require.Nil(state.UpdateNodeScaleIn(1001, node.ID, scaleIn, drain, nil))
+	require.True(watchFired(ws))
+
+	out, err := state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Equal(scaleIn, out.ScaleIn)
+	require.Equal(drain, out.DrainStrategy)
+	require.True(out.Drain)
+	require.Equal(structs.NodeSchedulingIneligible, out.SchedulingEligibility)
+	require.EqualValues(1001, out.ModifyIndex)
+
+	// The node can't be marked eligible once the drain is done
+	require.Nil(state.UpdateNodeDrain(1002, node.ID, nil, false, nil))
+	err = state.UpdateNodeEligibility(1003, node.ID, structs.NodeSchedulingEligible, nil)
+	require.NotNil(err)
+	require.Contains(err.Error(), "while it is being scaled in")
+	err = state.UpdateNodeDrain(1003, node.ID, nil, true, nil)
+	require.NotNil(err)
+	require.Contains(err.Error(), "while it is being scaled in")
+
+	// The scale-in is retained when the node registers again
+	require.Nil(state.UpsertNode(1004, node.Copy()))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Equal(scaleIn, out.ScaleIn)
+
+	// Cancel the scale-in
+	require.Nil(state.UpdateNodeScaleIn(1005, node.ID, nil, nil, nil))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Nil(out.ScaleIn)
+	require.Nil(out.DrainStrategy)
+	require.Equal(structs.NodeSchedulingEligible, out.SchedulingEligibility)
+
+	index, err := state.Index("nodes")
+	require.Nil(err)
+	require.EqualValues(1005, index)
+}
+
+func TestStateStore_NodeHostVolumes(t *testing.T) {
+	require := require.New(t)
+	state := testStateStore(t)
+	node := mock.Node()
+
+	require.Nil(state.UpsertNode(1000, node))
+
+	// Create a watchset so we can test that registering a volume fires the
+	// watch
+	ws := memdb.NewWatchSet()
+	_, err := state.NodeByID(ws, node.ID)
+	require.Nil(err)
+
+	volume := &structs.HostVolume{
+		Name:       "data",
+		Path:       "/srv/data",
+		CapacityMB: 1024,
+		AccessMode: structs.HostVolumeAccessModeSingleWriter,
+	}
+	event := &structs.NodeEvent{
+		Message:   "Host volume registered",
+		Subsystem: structs.NodeEventSubsystemCluster,
+		Timestamp: time.Now(),
+	}
+	require.Nil(state.UpsertNodeHostVolume(1001, node.ID, volume, event))
+	require.True(watchFired(ws))
+
+	out, err := state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Len(out.HostVolumes, 1)
+	require.Equal("/srv/data", out.HostVolumes["data"].Path)
+	require.EqualValues(1001, out.HostVolumes["data"].CreateIndex)
+	require.EqualValues(1001, out.HostVolumes["data"].ModifyIndex)
+	require.EqualValues(1001, out.ModifyIndex)
+	require.Len(out.Events, 2)
+	require.Equal(event, out.Events[1])
+
+	// Updating the volume retains its create index
+	volume.CapacityMB = 2048
+	require.Nil(state.UpsertNodeHostVolume(1002, node.ID, volume, nil))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.EqualValues(2048, out.HostVolumes["data"].CapacityMB)
+	require.EqualValues(1001, out.HostVolumes["data"].CreateIndex)
+	require.EqualValues(1002, out.HostVolumes["data"].ModifyIndex)
+
+	// Re-registering the node retains its volumes
+	require.Nil(state.UpsertNode(1003, node.Copy()))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Len(out.HostVolumes, 1)
+
+	// Deregister the volume
+	require.Nil(state.DeleteNodeHostVolume(1004, node.ID, "data", nil))
+	out, err = state.NodeByID(nil, node.ID)
+	require.Nil(err)
+	require.Empty(out.HostVolumes)
+	require.EqualValues(1004, out.ModifyIndex)
+
+	index, err := state.Index("nodes")
+	require.Nil(err)
+	require.EqualValues(1004, index)
+
+	// Deregistering an unknown volume fails
+	err = state.DeleteNodeHostVolume(1005, node.ID, "data", nil)
+	require.NotNil(err)
+	require.Contains(err.Error(), "not found")
+
+	// Registering on an unknown node fails
+	err = state.UpsertNodeHostVolume(1005, uuid.Generate(), volume, nil)
+	require.NotNil(err)
+	require.Contains(err.Error(), "node not found")
+}
+
 func TestStateStore_Nodes(t *testing.T) {
 	state := testStateStore(t)
 	var nodes []*structs.Node
@@ -4265,6 +4443,57 @@ func TestStateStore_JobSummary(t *testing.T) {
 	}
 }
 
+func TestStateStore_StaleJobSummaries(t *testing.T) {
+	state := testStateStore(t)
+	require := require.New(t)
+
+	// Create two jobs with an alloc each
+	alloc1 := mock.Alloc()
+	alloc2 := mock.Alloc()
+	require.NoError(state.UpsertJob(100, alloc1.Job))
+	require.NoError(state.UpsertJob(101, alloc2.Job))
+	require.NoError(state.UpsertAllocs(110, []*structs.Allocation{alloc1, alloc2}))
+
+	// The summaries are kept up to date as the allocs are upserted
+	stale, err := state.StaleJobSummaries()
+	require.NoError(err)
+	require.Empty(stale)
+
+	// Make the summary of the first job incorrect. The queued count is not
+	// derived from allocations, so it is ignored.
+	summary, err := state.JobSummaryByID(nil, alloc1.Namespace, alloc1.JobID)
+	require.NoError(err)
+	summary = summary.Copy()
+	summary.Summary["web"] = structs.TaskGroupSummary{Running: 3, Queued: 2}
+	require.NoError(state.UpsertJobSummary(120, summary))
+
+	summary, err = state.JobSummaryByID(nil, alloc2.Namespace, alloc2.JobID)
+	require.NoError(err)
+	summary = summary.Copy()
+	summary.Summary["web"] = structs.TaskGroupSummary{Starting: 1, Queued: 2}
+	require.NoError(state.UpsertJobSummary(121, summary))
+
+	stale, err = state.StaleJobSummaries()
+	require.NoError(err)
+	require.Equal([]structs.NamespacedID{{Namespace: alloc1.Namespace, ID: alloc1.JobID}}, stale)
+
+	// Reconcile only the stale summary
+	require.NoError(state.ReconcileJobSummariesByID(130, stale))
+
+	out, err := state.JobSummaryByID(nil, alloc1.Namespace, alloc1.JobID)
+	require.NoError(err)
+	require.Equal(structs.TaskGroupSummary{Starting: 1}, out.Summary["web"])
+	require.EqualValues(130, out.ModifyIndex)
+
+	out, err = state.JobSummaryByID(nil, alloc2.Namespace, alloc2.JobID)
+	require.NoError(err)
+	require.EqualValues(121, out.ModifyIndex)
+
+	stale, err = state.StaleJobSummaries()
+	require.NoError(err)
+	require.Empty(stale)
+}
+
 func TestStateStore_ReconcileJobSummary(t *testing.T) {
 	state := testStateStore(t)
 
@@ -4660,6 +4889,104 @@ func TestStateStore_AllocsByJob(t *testing.T) {
 	}
 }
 
+func TestStateStore_AllocsByJobDeployment(t *testing.T) {
+	state := testStateStore(t)
+	require := require.New(t)
+
+	// Create allocations of a job where only some are part of the deployment
+	job := mock.Job()
+	d := mock.Deployment()
+	d.JobID = job.ID
+
+	var allocs []*structs.Allocation
+	for i := 0; i < 4; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		if i%2 == 0 {
+			alloc.DeploymentID = d.ID
+		}
+		allocs = append(allocs, alloc)
+	}
+
+	// An allocation of another job in the deployment should be ignored
+	other := mock.Alloc()
+	other.DeploymentID = d.ID
+	allocs = append(allocs, other)
+
+	require.NoError(state.UpsertJobSummary(998, mock.JobSummary(job.ID)))
+	require.NoError(state.UpsertJobSummary(999, mock.JobSummary(other.JobID)))
+	require.NoError(state.UpsertAllocs(1000, allocs))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.AllocsByJobDeployment(ws, job.Namespace, job.ID, d.ID)
+	require.NoError(err)
+
+	expected := []*structs.Allocation{allocs[0], allocs[2]}
+	sort.Sort(AllocIDSort(expected))
+	sort.Sort(AllocIDSort(out))
+	require.Equal(expected, out)
+
+	// Updating an allocation of the deployment should fire the watch
+	update := allocs[0].Copy()
+	update.ClientStatus = structs.AllocClientStatusRunning
+	require.NoError(state.UpsertAllocs(1001, []*structs.Allocation{update}))
+	require.True(watchFired(ws))
+}
+
+func TestStateStore_ListByNamespaceStatus(t *testing.T) {
+	state := testStateStore(t)
+	require := require.New(t)
+
+	alloc1 := mock.Alloc()
+	alloc2 := mock.Alloc()
+	alloc2.ClientStatus = structs.AllocClientStatusRunning
+	require.NoError(state.UpsertJobSummary(998, mock.JobSummary(alloc1.JobID)))
+	require.NoError(state.UpsertJobSummary(999, mock.JobSummary(alloc2.JobID)))
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc1, alloc2}))
+
+	eval1 := mock.Eval()
+	eval2 := mock.Eval()
+	eval2.Status = structs.EvalStatusBlocked
+	require.NoError(state.UpsertEvals(1001, []*structs.Evaluation{eval1, eval2}))
+
+	d1 := mock.Deployment()
+	d2 := mock.Deployment()
+	d2.Status = structs.DeploymentStatusSuccessful
+	require.NoError(state.UpsertDeployment(1002, d1))
+	require.NoError(state.UpsertDeployment(1003, d2))
+
+	ws := memdb.NewWatchSet()
+	iter, err := state.AllocsByNamespaceStatus(ws, structs.DefaultNamespace, structs.AllocClientStatusRunning)
+	require.NoError(err)
+	require.Equal(alloc2.ID, iter.Next().(*structs.Allocation).ID)
+	require.Nil(iter.Next())
+
+	iter, err = state.EvalsByNamespaceStatus(ws, structs.DefaultNamespace, structs.EvalStatusBlocked)
+	require.NoError(err)
+	require.Equal(eval2.ID, iter.Next().(*structs.Evaluation).ID)
+	require.Nil(iter.Next())
+
+	iter, err = state.DeploymentsByNamespaceStatus(ws, structs.DefaultNamespace, structs.DeploymentStatusSuccessful)
+	require.NoError(err)
+	require.Equal(d2.ID, iter.Next().(*structs.Deployment).ID)
+	require.Nil(iter.Next())
+
+	iter, err = state.AllocsByNamespaceStatus(ws, "other", structs.AllocClientStatusRunning)
+	require.NoError(err)
+	require.Nil(iter.Next())
+
+	// Objects without a status can still be inserted
+	alloc3 := mock.Alloc()
+	alloc3.ClientStatus = ""
+	require.NoError(state.UpsertJobSummary(1004, mock.JobSummary(alloc3.JobID)))
+	require.NoError(state.UpsertAllocs(1005, []*structs.Allocation{alloc3}))
+
+	d3 := mock.Deployment()
+	d3.Status = ""
+	require.NoError(state.UpsertDeployment(1006, d3))
+}
+
 func TestStateStore_AllocsForRegisteredJob(t *testing.T) {
 	state := testStateStore(t)
 	var allocs []*structs.Allocation
@@ -5643,6 +5970,177 @@ func TestStateStore_UpdateJobStability(t *testing.T) {
 	}
 }
 
+func TestStateStore_UpdateJobVersionTag(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	// Insert a job twice to get two versions
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1, job))
+	require.NoError(state.UpsertJob(2, job.Copy()))
+
+	// Tag the old version
+	tag := &structs.JobVersionTag{Name: "golden", Description: "known good"}
+	require.NoError(state.UpdateJobVersionTag(3, job.Namespace, job.ID, 0, tag, false))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.JobVersionByTag(ws, job.Namespace, job.ID, "golden")
+	require.NoError(err)
+	require.NotNil(out)
+	require.EqualValues(0, out.Version)
+	require.Equal(tag, out.VersionTag)
+
+	// The current version is untouched
+	cur, err := state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(1, cur.Version)
+	require.Nil(cur.VersionTag)
+
+	// The tag can't be applied to another version
+	require.Error(state.UpdateJobVersionTag(4, job.Namespace, job.ID, 1, tag, false))
+
+	// Tagging the current version updates the job
+	require.NoError(state.UpdateJobVersionTag(5, job.Namespace, job.ID, 1, &structs.JobVersionTag{Name: "latest"}, false))
+	cur, err = state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(cur.VersionTag)
+	require.Equal("latest", cur.VersionTag.Name)
+	require.EqualValues(5, cur.ModifyIndex)
+
+	// New versions aren't tagged
+	require.NoError(state.UpsertJob(6, cur.Copy()))
+	cur, err = state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(2, cur.Version)
+	require.Nil(cur.VersionTag)
+
+	// Remove the tag
+	require.NoError(state.UpdateJobVersionTag(7, job.Namespace, job.ID, 0, tag, true))
+	out, err = state.JobVersionByTag(ws, job.Namespace, job.ID, "golden")
+	require.NoError(err)
+	require.Nil(out)
+	out, err = state.JobByIDAndVersion(ws, job.Namespace, job.ID, 0)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Nil(out.VersionTag)
+}
+
+func TestStateStore_UpsertScalingEvent(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1, job))
+
+	// Record more events than are tracked
+	ws := memdb.NewWatchSet()
+	_, err := state.ScalingEventsByJob(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	total := structs.JobTrackedScalingEvents + 5
+	for i := 0; i < total; i++ {
+		require.NoError(state.UpsertScalingEvent(uint64(2+i), &structs.ScalingEventRequest{
+			JobID:     job.ID,
+			TaskGroup: "web",
+			ScalingEvent: &structs.ScalingEvent{
+				Time:  int64(i),
+				Count: helper.Int64ToPtr(int64(i)),
+			},
+			WriteRequest: structs.WriteRequest{
+				Namespace: job.Namespace,
+			},
+		}))
+	}
+	require.True(watchFired(ws))
+
+	// The newest events are kept, newest first
+	out, err := state.ScalingEventsByJob(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.NotNil(out)
+	events := out.ScalingEvents["web"]
+	require.Len(events, structs.JobTrackedScalingEvents)
+	require.EqualValues(total-1, events[0].Time)
+	require.EqualValues(total+1, events[0].CreateIndex)
+	require.EqualValues(total-structs.JobTrackedScalingEvents, events[len(events)-1].Time)
+	require.EqualValues(total+1, out.ModifyIndex)
+
+	index, err := state.Index("scaling_event")
+	require.NoError(err)
+	require.EqualValues(total+1, index)
+
+	// Deleting the job deletes its events
+	require.NoError(state.DeleteJob(100, job.Namespace, job.ID))
+	out, err = state.ScalingEventsByJob(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Nil(out)
+	index, err = state.Index("scaling_event")
+	require.NoError(err)
+	require.EqualValues(100, index)
+}
+
+func TestStateStore_UpsertJob_TaggedVersionsNotGCed(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	// Tag the first version
+	job := mock.Job()
+	require.NoError(state.UpsertJob(1, job))
+	require.NoError(state.UpdateJobVersionTag(2, job.Namespace, job.ID, 0, &structs.JobVersionTag{Name: "golden"}, false))
+
+	// Create more versions than are tracked
+	for i := 0; i < structs.JobTrackedVersions+5; i++ {
+		require.NoError(state.UpsertJob(uint64(3+i), job.Copy()))
+	}
+
+	// The tagged version is kept on top of the tracked versions
+	ws := memdb.NewWatchSet()
+	all, err := state.JobVersionsByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Len(all, structs.JobTrackedVersions+1)
+
+	out, err := state.JobByIDAndVersion(ws, job.Namespace, job.ID, 0)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal("golden", out.VersionTag.Name)
+}
+
+func TestStateStore_UpsertJob_MultiregionVersion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	// The version picked across the regions is kept when creating the job
+	job := mock.Job()
+	job.Multiregion = &structs.Multiregion{
+		Regions: []*structs.MultiregionRegion{{Name: "global"}, {Name: "east"}},
+	}
+	job.Version = 3
+	require.NoError(state.UpsertJob(1, job))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(3, out.Version)
+
+	// A higher version is kept on update
+	job2 := job.Copy()
+	job2.Version = 6
+	require.NoError(state.UpsertJob(2, job2))
+	out, err = state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(6, out.Version)
+
+	// Otherwise the version is bumped as usual
+	job3 := job.Copy()
+	job3.Version = 0
+	require.NoError(state.UpsertJob(3, job3))
+	out, err = state.JobByID(ws, job.Namespace, job.ID)
+	require.NoError(err)
+	require.EqualValues(7, out.Version)
+}
+
 // Test that nonexistent deployment can't be promoted
 func TestStateStore_UpsertDeploymentPromotion_Nonexistent(t *testing.T) {
 	state := testStateStore(t)
@@ -5957,18 +6455,116 @@ func TestStateStore_UpsertDeploymentPromotion_Subset(t *testing.T) {
 	require.True(aout3.DeploymentStatus.Canary)
 }
 
-// Test that allocation health can't be set against a nonexistent deployment
-func TestStateStore_UpsertDeploymentAllocHealth_Nonexistent(t *testing.T) {
+// Test promoting all the groups skips the groups promoted by name
+func TestStateStore_UpsertDeploymentPromotion_ManualPerGroup(t *testing.T) {
 	state := testStateStore(t)
+	require := require.New(t)
 
-	// Set health against the nonexistent deployment
-	req := &structs.ApplyDeploymentAllocHealthRequest{
-		DeploymentAllocHealthRequest: structs.DeploymentAllocHealthRequest{
-			DeploymentID:         uuid.Generate(),
-			HealthyAllocationIDs: []string{uuid.Generate()},
-		},
-	}
-	err := state.UpdateDeploymentAllocHealth(2, req)
+	// Create a job with two task groups, one of which is promoted by name
+	j := mock.Job()
+	tg1 := j.TaskGroups[0]
+	tg2 := tg1.Copy()
+	tg2.Name = "foo"
+	j.TaskGroups = append(j.TaskGroups, tg2)
+	require.Nil(state.UpsertJob(1, j))
+
+	// Create a deployment
+	d := mock.Deployment()
+	d.JobID = j.ID
+	d.TaskGroups = map[string]*structs.DeploymentState{
+		"web": {
+			DesiredTotal:    10,
+			DesiredCanaries: 1,
+		},
+		"foo": {
+			DesiredTotal:            10,
+			DesiredCanaries:         1,
+			ManualPromotionPerGroup: true,
+		},
+	}
+	require.Nil(state.UpsertDeployment(2, d))
+
+	// Create a healthy canary for each group
+	c1 := mock.Alloc()
+	c1.JobID = j.ID
+	c1.DeploymentID = d.ID
+	d.TaskGroups[c1.TaskGroup].PlacedCanaries = append(d.TaskGroups[c1.TaskGroup].PlacedCanaries, c1.ID)
+	c1.DeploymentStatus = &structs.AllocDeploymentStatus{
+		Healthy: helper.BoolToPtr(true),
+		Canary:  true,
+	}
+
+	c2 := mock.Alloc()
+	c2.JobID = j.ID
+	c2.DeploymentID = d.ID
+	c2.TaskGroup = tg2.Name
+	d.TaskGroups[c2.TaskGroup].PlacedCanaries = append(d.TaskGroups[c2.TaskGroup].PlacedCanaries, c2.ID)
+	c2.DeploymentStatus = &structs.AllocDeploymentStatus{
+		Healthy: helper.BoolToPtr(true),
+		Canary:  true,
+	}
+
+	require.Nil(state.UpsertAllocs(3, []*structs.Allocation{c1, c2}))
+
+	// Promoting all the groups only promotes the ungated group
+	req := &structs.ApplyDeploymentPromoteRequest{
+		DeploymentPromoteRequest: structs.DeploymentPromoteRequest{
+			DeploymentID: d.ID,
+			All:          true,
+		},
+		Eval: mock.Eval(),
+	}
+	require.Nil(state.UpdateDeploymentPromotion(4, req))
+
+	ws := memdb.NewWatchSet()
+	dout, err := state.DeploymentByID(ws, d.ID)
+	require.Nil(err)
+	require.True(dout.TaskGroups["web"].Promoted)
+	require.False(dout.TaskGroups["foo"].Promoted)
+	require.True(dout.RequiresPromotion())
+
+	aout2, err := state.AllocByID(ws, c2.ID)
+	require.Nil(err)
+	require.True(aout2.DeploymentStatus.Canary)
+
+	// Promoting all the groups again has nothing to promote
+	req.Eval = mock.Eval()
+	err = state.UpdateDeploymentPromotion(5, req)
+	require.Error(err)
+	require.Contains(err.Error(), "Task groups foo must be promoted by name")
+
+	// Promoting the gated group by name promotes it
+	req = &structs.ApplyDeploymentPromoteRequest{
+		DeploymentPromoteRequest: structs.DeploymentPromoteRequest{
+			DeploymentID: d.ID,
+			Groups:       []string{"foo"},
+		},
+		Eval: mock.Eval(),
+	}
+	require.Nil(state.UpdateDeploymentPromotion(6, req))
+
+	dout, err = state.DeploymentByID(ws, d.ID)
+	require.Nil(err)
+	require.True(dout.TaskGroups["foo"].Promoted)
+	require.False(dout.RequiresPromotion())
+
+	aout2, err = state.AllocByID(ws, c2.ID)
+	require.Nil(err)
+	require.False(aout2.DeploymentStatus.Canary)
+}
+
+// Test that allocation health can't be set against a nonexistent deployment
+func TestStateStore_UpsertDeploymentAllocHealth_Nonexistent(t *testing.T) {
+	state := testStateStore(t)
+
+	// Set health against the nonexistent deployment
+	req := &structs.ApplyDeploymentAllocHealthRequest{
+		DeploymentAllocHealthRequest: structs.DeploymentAllocHealthRequest{
+			DeploymentID:         uuid.Generate(),
+			HealthyAllocationIDs: []string{uuid.Generate()},
+		},
+	}
+	err := state.UpdateDeploymentAllocHealth(2, req)
 	if err == nil || !strings.Contains(err.Error(), "does not exist") {
 		t.Fatalf("expected error because the deployment doesn't exist: %v", err)
 	}
@@ -6882,6 +7478,253 @@ func TestStateStore_RestoreACLPolicy(t *testing.T) {
 	assert.Equal(t, policy, out)
 }
 
+func TestStateStore_UpsertDeleteACLRoles(t *testing.T) {
+	require := require.New(t)
+	state := testStateStore(t)
+	role := mock.ACLRole()
+	role2 := mock.ACLRole()
+
+	// Create a watcher
+	ws := memdb.NewWatchSet()
+	_, err := state.ACLRoleByName(ws, role.Name)
+	require.NoError(err)
+
+	require.NoError(state.UpsertACLRoles(1000, []*structs.ACLRole{role, role2}))
+	require.True(watchFired(ws))
+
+	ws = memdb.NewWatchSet()
+	out, err := state.ACLRoleByName(ws, role.Name)
+	require.NoError(err)
+	require.Equal(role, out)
+	require.EqualValues(1000, out.CreateIndex)
+
+	// Update the role and ensure the create index is preserved
+	role3 := &structs.ACLRole{
+		Name:     role.Name,
+		Policies: []string{"baz"},
+	}
+	role3.SetHash()
+	require.NoError(state.UpsertACLRoles(1001, []*structs.ACLRole{role3}))
+	require.True(watchFired(ws))
+
+	out, err = state.ACLRoleByName(nil, role.Name)
+	require.NoError(err)
+	require.EqualValues(1000, out.CreateIndex)
+	require.EqualValues(1001, out.ModifyIndex)
+	require.Equal([]string{"baz"}, out.Policies)
+
+	iter, err := state.ACLRoles(nil)
+	require.NoError(err)
+	count := 0
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	require.Equal(2, count)
+
+	// Delete the roles
+	require.NoError(state.DeleteACLRoles(1002, []string{role.Name, role2.Name}))
+	out, err = state.ACLRoleByName(nil, role.Name)
+	require.NoError(err)
+	require.Nil(out)
+
+	index, err := state.Index("acl_role")
+	require.NoError(err)
+	require.EqualValues(1002, index)
+}
+
+func TestStateStore_ACLRoleByNamePrefix(t *testing.T) {
+	state := testStateStore(t)
+	names := []string{"foo", "bar", "foobar", "zip"}
+
+	for i, name := range names {
+		r := mock.ACLRole()
+		r.Name = name
+		require.NoError(t, state.UpsertACLRoles(uint64(1000+i), []*structs.ACLRole{r}))
+	}
+
+	iter, err := state.ACLRoleByNamePrefix(nil, "foo")
+	require.NoError(t, err)
+
+	out := []string{}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		out = append(out, raw.(*structs.ACLRole).Name)
+	}
+	sort.Strings(out)
+	require.Equal(t, []string{"foo", "foobar"}, out)
+}
+
+func TestStateStore_RestoreACLRole(t *testing.T) {
+	state := testStateStore(t)
+	role := mock.ACLRole()
+
+	restore, err := state.Restore()
+	require.NoError(t, err)
+	require.NoError(t, restore.ACLRoleRestore(role))
+	restore.Commit()
+
+	out, err := state.ACLRoleByName(nil, role.Name)
+	require.NoError(t, err)
+	require.Equal(t, role, out)
+}
+
+func TestStateStore_UpsertDeleteACLAuthMethods(t *testing.T) {
+	require := require.New(t)
+	state := testStateStore(t)
+	method := mock.ACLAuthMethod()
+	method2 := mock.ACLAuthMethod()
+
+	// Create a watcher
+	ws := memdb.NewWatchSet()
+	_, err := state.ACLAuthMethodByName(ws, method.Name)
+	require.NoError(err)
+
+	require.NoError(state.UpsertACLAuthMethods(1000, []*structs.ACLAuthMethod{method, method2}))
+	require.True(watchFired(ws))
+
+	ws = memdb.NewWatchSet()
+	out, err := state.ACLAuthMethodByName(ws, method.Name)
+	require.NoError(err)
+	require.Equal(method, out)
+	require.EqualValues(1000, out.CreateIndex)
+
+	// Update the auth method and ensure the create index is preserved
+	method3 := *method
+	method3.MaxTokenTTL = 2 * time.Hour
+	method3.SetHash()
+	require.NoError(state.UpsertACLAuthMethods(1001, []*structs.ACLAuthMethod{&method3}))
+	require.True(watchFired(ws))
+
+	out, err = state.ACLAuthMethodByName(nil, method.Name)
+	require.NoError(err)
+	require.EqualValues(1000, out.CreateIndex)
+	require.EqualValues(1001, out.ModifyIndex)
+	require.Equal(2*time.Hour, out.MaxTokenTTL)
+
+	iter, err := state.ACLAuthMethods(nil)
+	require.NoError(err)
+	count := 0
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	require.Equal(2, count)
+
+	// Add a binding rule for each auth method
+	rule := mock.ACLBindingRule()
+	rule.AuthMethod = method.Name
+	rule2 := mock.ACLBindingRule()
+	rule2.AuthMethod = method2.Name
+	require.NoError(state.UpsertACLBindingRules(1002, []*structs.ACLBindingRule{rule, rule2}))
+
+	// Delete the first auth method and ensure its binding rules are deleted
+	require.NoError(state.DeleteACLAuthMethods(1003, []string{method.Name}))
+	out, err = state.ACLAuthMethodByName(nil, method.Name)
+	require.NoError(err)
+	require.Nil(out)
+
+	outRule, err := state.ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(err)
+	require.Nil(outRule)
+	outRule, err = state.ACLBindingRuleByID(nil, rule2.ID)
+	require.NoError(err)
+	require.NotNil(outRule)
+
+	index, err := state.Index("acl_auth_method")
+	require.NoError(err)
+	require.EqualValues(1003, index)
+	index, err = state.Index("acl_binding_rule")
+	require.NoError(err)
+	require.EqualValues(1003, index)
+}
+
+func TestStateStore_ACLAuthMethodByNamePrefix(t *testing.T) {
+	state := testStateStore(t)
+	names := []string{"foo", "bar", "foobar", "zip"}
+
+	for i, name := range names {
+		m := mock.ACLAuthMethod()
+		m.Name = name
+		require.NoError(t, state.UpsertACLAuthMethods(uint64(1000+i), []*structs.ACLAuthMethod{m}))
+	}
+
+	iter, err := state.ACLAuthMethodByNamePrefix(nil, "foo")
+	require.NoError(t, err)
+
+	out := []string{}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		out = append(out, raw.(*structs.ACLAuthMethod).Name)
+	}
+	sort.Strings(out)
+	require.Equal(t, []string{"foo", "foobar"}, out)
+}
+
+func TestStateStore_UpsertDeleteACLBindingRules(t *testing.T) {
+	require := require.New(t)
+	state := testStateStore(t)
+	rule := mock.ACLBindingRule()
+	rule2 := mock.ACLBindingRule()
+	rule2.AuthMethod = "other"
+
+	// Create a watcher
+	ws := memdb.NewWatchSet()
+	_, err := state.ACLBindingRuleByID(ws, rule.ID)
+	require.NoError(err)
+
+	require.NoError(state.UpsertACLBindingRules(1000, []*structs.ACLBindingRule{rule, rule2}))
+	require.True(watchFired(ws))
+
+	out, err := state.ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(err)
+	require.Equal(rule, out)
+
+	// Lookup by auth method
+	iter, err := state.ACLBindingRulesByAuthMethod(nil, rule.AuthMethod)
+	require.NoError(err)
+	var ids []string
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		ids = append(ids, raw.(*structs.ACLBindingRule).ID)
+	}
+	require.Equal([]string{rule.ID}, ids)
+
+	iter, err = state.ACLBindingRules(nil)
+	require.NoError(err)
+	count := 0
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	require.Equal(2, count)
+
+	// Delete the binding rules
+	require.NoError(state.DeleteACLBindingRules(1001, []string{rule.ID, rule2.ID}))
+	out, err = state.ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(err)
+	require.Nil(out)
+
+	index, err := state.Index("acl_binding_rule")
+	require.NoError(err)
+	require.EqualValues(1001, index)
+}
+
+func TestStateStore_RestoreACLAuthMethodsAndBindingRules(t *testing.T) {
+	state := testStateStore(t)
+	method := mock.ACLAuthMethod()
+	rule := mock.ACLBindingRule()
+	rule.AuthMethod = method.Name
+
+	restore, err := state.Restore()
+	require.NoError(t, err)
+	require.NoError(t, restore.ACLAuthMethodRestore(method))
+	require.NoError(t, restore.ACLBindingRuleRestore(rule))
+	restore.Commit()
+
+	outMethod, err := state.ACLAuthMethodByName(nil, method.Name)
+	require.NoError(t, err)
+	require.Equal(t, method, outMethod)
+
+	outRule, err := state.ACLBindingRuleByID(nil, rule.ID)
+	require.NoError(t, err)
+	require.Equal(t, rule, outRule)
+}
+
 func TestStateStore_ACLTokensByGlobal(t *testing.T) {
 	state := testStateStore(t)
 	tk1 := mock.ACLToken()
@@ -6964,6 +7807,35 @@ func TestStateStore_SchedulerConfig(t *testing.T) {
 	require.Equal(schedConfig, out)
 }
 
+func TestStateStore_GCConfig(t *testing.T) {
+	require := require.New(t)
+	state := testStateStore(t)
+
+	// Nothing is returned until operators set a config
+	index, out, err := state.GCConfig()
+	require.NoError(err)
+	require.Zero(index)
+	require.Nil(out)
+
+	// A CAS of zero only applies while no config is set
+	config := &structs.GCConfiguration{EvalGCThreshold: time.Minute}
+	ok, err := state.GCCASConfig(100, 0, config)
+	require.NoError(err)
+	require.True(ok)
+
+	ok, err = state.GCCASConfig(101, 0, &structs.GCConfiguration{JobGCThreshold: time.Hour})
+	require.NoError(err)
+	require.False(ok)
+
+	require.NoError(state.GCSetConfig(102, &structs.GCConfiguration{JobGCThreshold: time.Hour}))
+	index, out, err = state.GCConfig()
+	require.NoError(err)
+	require.EqualValues(102, index)
+	require.EqualValues(100, out.CreateIndex)
+	require.Zero(out.EvalGCThreshold)
+	require.Equal(time.Hour, out.JobGCThreshold)
+}
+
 func TestStateStore_Abandon(t *testing.T) {
 	s := testStateStore(t)
 	abandonCh := s.AbandonCh()
@@ -7044,3 +7916,457 @@ func (n AllocIDSort) Less(i, j int) bool {
 func (n AllocIDSort) Swap(i, j int) {
 	n[i], n[j] = n[j], n[i]
 }
+
+func TestStateStore_UpsertServiceRegistrations(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	alloc := mock.Alloc()
+	stopped := mock.Alloc()
+	stopped.ClientStatus = structs.AllocClientStatusComplete
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc, stopped}))
+
+	s1 := mock.ServiceRegistration(alloc)
+	s2 := mock.ServiceRegistration(alloc)
+	s2.ServiceName = "api"
+	s3 := mock.ServiceRegistration(stopped)
+
+	ws := memdb.NewWatchSet()
+	_, err := state.ServiceRegistrationsByName(ws, alloc.Namespace, "web")
+	require.NoError(err)
+
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{s1, s2, s3}))
+	require.True(watchFired(ws))
+
+	// Services of stopped allocations are not registered
+	out, err := state.ServiceRegistrationByID(nil, s3.ID)
+	require.NoError(err)
+	require.Nil(out)
+
+	out, err = state.ServiceRegistrationByID(nil, s1.ID)
+	require.NoError(err)
+	require.Equal(uint64(1001), out.CreateIndex)
+	require.Equal(uint64(1001), out.ModifyIndex)
+
+	services, err := state.ServiceRegistrationsByName(nil, alloc.Namespace, "api")
+	require.NoError(err)
+	require.Len(services, 1)
+	require.Equal(s2.ID, services[0].ID)
+
+	// Unchanged services are not updated
+	require.NoError(state.UpsertServiceRegistrations(1002, []*structs.ServiceRegistration{s1.Copy()}))
+	index, err := state.Index("service_registrations")
+	require.NoError(err)
+	require.Equal(uint64(1001), index)
+
+	update := s1.Copy()
+	update.Port = 6000
+	require.NoError(state.UpsertServiceRegistrations(1003, []*structs.ServiceRegistration{update}))
+	out, err = state.ServiceRegistrationByID(nil, s1.ID)
+	require.NoError(err)
+	require.Equal(6000, out.Port)
+	require.Equal(uint64(1001), out.CreateIndex)
+	require.Equal(uint64(1003), out.ModifyIndex)
+}
+
+func TestStateStore_DeleteServiceRegistrations(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	alloc := mock.Alloc()
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc}))
+
+	s1 := mock.ServiceRegistration(alloc)
+	s2 := mock.ServiceRegistration(alloc)
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{s1, s2}))
+
+	// Services registered by other nodes are not deleted
+	require.NoError(state.DeleteServiceRegistrations(1002, "other-node", []string{s1.ID}))
+	out, err := state.ServiceRegistrationByID(nil, s1.ID)
+	require.NoError(err)
+	require.NotNil(out)
+
+	ws := memdb.NewWatchSet()
+	_, err = state.ServiceRegistrationByID(ws, s1.ID)
+	require.NoError(err)
+
+	require.NoError(state.DeleteServiceRegistrations(1003, alloc.NodeID, []string{s1.ID}))
+	require.True(watchFired(ws))
+
+	services, err := state.ServiceRegistrationsByAlloc(nil, alloc.ID)
+	require.NoError(err)
+	require.Len(services, 1)
+	require.Equal(s2.ID, services[0].ID)
+
+	index, err := state.Index("service_registrations")
+	require.NoError(err)
+	require.Equal(uint64(1003), index)
+}
+
+func TestStateStore_ServiceRegistrations_StoppedAlloc(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	alloc := mock.Alloc()
+	lost := mock.Alloc()
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{alloc, lost}))
+	require.NoError(state.UpsertServiceRegistrations(1001, []*structs.ServiceRegistration{
+		mock.ServiceRegistration(alloc),
+		mock.ServiceRegistration(lost),
+	}))
+
+	// Services are deregistered when the client stops the allocation
+	update := alloc.Copy()
+	update.ClientStatus = structs.AllocClientStatusFailed
+	require.NoError(state.UpdateAllocsFromClient(1002, []*structs.Allocation{update}))
+
+	services, err := state.ServiceRegistrationsByAlloc(nil, alloc.ID)
+	require.NoError(err)
+	require.Empty(services)
+
+	// Services are deregistered when the allocation is lost
+	update = lost.Copy()
+	update.ClientStatus = structs.AllocClientStatusLost
+	require.NoError(state.UpsertAllocs(1003, []*structs.Allocation{update}))
+
+	services, err = state.ServiceRegistrationsByAlloc(nil, lost.ID)
+	require.NoError(err)
+	require.Empty(services)
+
+	index, err := state.Index("service_registrations")
+	require.NoError(err)
+	require.Equal(uint64(1003), index)
+}
+
+func TestStateStore_RestoreServiceRegistration(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+	service := mock.ServiceRegistration(mock.Alloc())
+
+	restore, err := state.Restore()
+	require.NoError(err)
+	require.NoError(restore.ServiceRegistrationRestore(service))
+	restore.Commit()
+
+	out, err := state.ServiceRegistrationByID(nil, service.ID)
+	require.NoError(err)
+	require.Equal(service, out)
+}
+
+func TestStateStore_UpsertVariable(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	v := mock.VariableEncrypted()
+	ws := memdb.NewWatchSet()
+	_, err := state.VariableByPath(ws, v.Namespace, v.Path)
+	require.NoError(err)
+
+	applied, err := state.UpsertVariable(1000, v.Copy(), false)
+	require.NoError(err)
+	require.True(applied)
+	require.True(watchFired(ws))
+
+	out, err := state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal(uint64(1000), out.CreateIndex)
+	require.Equal(uint64(1000), out.ModifyIndex)
+	require.Equal(v.ModifyTime, out.CreateTime)
+	require.Equal(v.Data, out.Data)
+
+	// Updates preserve the create index and time
+	update := v.Copy()
+	update.ModifyTime = v.ModifyTime + 1
+	update.Data = []byte("updated")
+	applied, err = state.UpsertVariable(1001, update, false)
+	require.NoError(err)
+	require.True(applied)
+
+	out, err = state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Equal(uint64(1000), out.CreateIndex)
+	require.Equal(uint64(1001), out.ModifyIndex)
+	require.Equal(v.ModifyTime, out.CreateTime)
+	require.Equal([]byte("updated"), out.Data)
+
+	index, err := state.Index("variables")
+	require.NoError(err)
+	require.Equal(uint64(1001), index)
+}
+
+func TestStateStore_UpsertVariable_CAS(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	// Creating with a non-zero index fails
+	v := mock.VariableEncrypted()
+	v.ModifyIndex = 10
+	applied, err := state.UpsertVariable(1000, v.Copy(), true)
+	require.NoError(err)
+	require.False(applied)
+
+	v.ModifyIndex = 0
+	applied, err = state.UpsertVariable(1000, v.Copy(), true)
+	require.NoError(err)
+	require.True(applied)
+
+	// A stale index fails
+	applied, err = state.UpsertVariable(1001, v.Copy(), true)
+	require.NoError(err)
+	require.False(applied)
+
+	v.ModifyIndex = 1000
+	applied, err = state.UpsertVariable(1001, v.Copy(), true)
+	require.NoError(err)
+	require.True(applied)
+
+	index, err := state.Index("variables")
+	require.NoError(err)
+	require.Equal(uint64(1001), index)
+}
+
+func TestStateStore_UpsertVariableLock(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	v := mock.VariableEncrypted()
+	v.Lock = &structs.VariableLock{ID: "foo", TTL: 15 * time.Second}
+	applied, err := state.UpsertVariableLock(1000, v.Copy())
+	require.NoError(err)
+	require.True(applied)
+
+	out, err := state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Equal("foo", out.Lock.ID)
+
+	// Plain writes keep the lock
+	update := v.Copy()
+	update.Lock = nil
+	applied, err = state.UpsertVariable(1001, update, false)
+	require.NoError(err)
+	require.True(applied)
+
+	out, err = state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Equal("foo", out.Lock.ID)
+
+	// Lock writes are check-and-set
+	release := out.Copy()
+	release.Lock = nil
+	release.ModifyIndex = 1000
+	applied, err = state.UpsertVariableLock(1002, release.Copy())
+	require.NoError(err)
+	require.False(applied)
+
+	release.ModifyIndex = 1001
+	applied, err = state.UpsertVariableLock(1002, release.Copy())
+	require.NoError(err)
+	require.True(applied)
+
+	out, err = state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Nil(out.Lock)
+	require.Equal(uint64(1002), out.ModifyIndex)
+}
+
+func TestStateStore_DeleteVariable(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	v := mock.VariableEncrypted()
+	_, err := state.UpsertVariable(1000, v.Copy(), false)
+	require.NoError(err)
+
+	// A stale index fails
+	applied, err := state.DeleteVariable(1001, v.Namespace, v.Path, true, 999)
+	require.NoError(err)
+	require.False(applied)
+
+	ws := memdb.NewWatchSet()
+	_, err = state.VariableByPath(ws, v.Namespace, v.Path)
+	require.NoError(err)
+
+	applied, err = state.DeleteVariable(1001, v.Namespace, v.Path, true, 1000)
+	require.NoError(err)
+	require.True(applied)
+	require.True(watchFired(ws))
+
+	out, err := state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Nil(out)
+
+	index, err := state.Index("variables")
+	require.NoError(err)
+	require.Equal(uint64(1001), index)
+
+	// Deleting a missing variable is a no-op
+	applied, err = state.DeleteVariable(1002, v.Namespace, v.Path, false, 0)
+	require.NoError(err)
+	require.True(applied)
+}
+
+func TestStateStore_VariablesByNamespacePrefix(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	v1 := mock.VariableEncrypted()
+	v1.Path = "nomad/jobs/web"
+	v2 := mock.VariableEncrypted()
+	v2.Path = "nomad/jobs/web/task"
+	v3 := mock.VariableEncrypted()
+	v3.Path = "other"
+	v4 := mock.VariableEncrypted()
+	v4.Namespace = "other"
+	v4.Path = "nomad/jobs/web"
+	for i, v := range []*structs.VariableEncrypted{v1, v2, v3, v4} {
+		_, err := state.UpsertVariable(uint64(1000+i), v, false)
+		require.NoError(err)
+	}
+
+	iter, err := state.VariablesByNamespacePrefix(nil, structs.DefaultNamespace, "nomad/jobs")
+	require.NoError(err)
+
+	var paths []string
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		paths = append(paths, raw.(*structs.VariableEncrypted).Path)
+	}
+	require.Equal([]string{"nomad/jobs/web", "nomad/jobs/web/task"}, paths)
+}
+
+func TestStateStore_RestoreVariable(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+	v := mock.VariableEncrypted()
+
+	restore, err := state.Restore()
+	require.NoError(err)
+	require.NoError(restore.VariableRestore(v))
+	restore.Commit()
+
+	out, err := state.VariableByPath(nil, v.Namespace, v.Path)
+	require.NoError(err)
+	require.Equal(v, out)
+}
+
+func TestStateStore_CSIPlugins_NodeLifecycle(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	n1 := mock.CSINode("ebs")
+	n1.CSIControllerPlugins = map[string]*structs.CSIInfo{
+		"ebs": {PluginID: "ebs", Healthy: true, ControllerInfo: &structs.CSIControllerInfo{}},
+	}
+	n2 := mock.CSINode("ebs")
+
+	ws := memdb.NewWatchSet()
+	_, err := state.CSIPluginByID(ws, "ebs")
+	require.NoError(err)
+
+	require.NoError(state.UpsertNode(1000, n1))
+	require.NoError(state.UpsertNode(1001, n2))
+	require.True(watchFired(ws))
+
+	plugin, err := state.CSIPluginByID(nil, "ebs")
+	require.NoError(err)
+	require.NotNil(plugin)
+	require.Equal(1, plugin.ControllersHealthy)
+	require.Equal(2, plugin.NodesHealthy)
+	require.Equal("com.hashicorp:mock", plugin.Provider)
+	require.Equal(uint64(1000), plugin.CreateIndex)
+	require.Equal(uint64(1001), plugin.ModifyIndex)
+
+	// Plugins on down nodes are unhealthy
+	require.NoError(state.UpdateNodeStatus(1002, n2.ID, structs.NodeStatusDown, nil))
+	plugin, err = state.CSIPluginByID(nil, "ebs")
+	require.NoError(err)
+	require.Equal(1, plugin.NodesHealthy)
+	require.Len(plugin.Nodes, 2)
+
+	// Nodes that stop running the plugin are removed from it
+	n1 = n1.Copy()
+	n1.CSIControllerPlugins = nil
+	require.NoError(state.UpsertNode(1003, n1))
+	plugin, err = state.CSIPluginByID(nil, "ebs")
+	require.NoError(err)
+	require.Empty(plugin.Controllers)
+
+	// The plugin is removed with its last instance
+	require.NoError(state.DeleteNode(1004, n1.ID))
+	require.NoError(state.DeleteNode(1005, n2.ID))
+	plugin, err = state.CSIPluginByID(nil, "ebs")
+	require.NoError(err)
+	require.Nil(plugin)
+
+	index, err := state.Index("csi_plugins")
+	require.NoError(err)
+	require.Equal(uint64(1005), index)
+}
+
+func TestStateStore_CSIVolume_RegisterClaim(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	state := testStateStore(t)
+
+	node := mock.CSINode("ebs")
+	require.NoError(state.UpsertNode(1000, node))
+
+	vol := mock.CSIVolume("ebs")
+	ws := memdb.NewWatchSet()
+	_, err := state.CSIVolumeByID(ws, vol.Namespace, vol.ID)
+	require.NoError(err)
+
+	require.NoError(state.CSIVolumeRegister(1001, []*structs.CSIVolume{vol}))
+	require.True(watchFired(ws))
+
+	// The health of the volume comes from its plugin
+	out, err := state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.True(out.Schedulable)
+	require.Equal(1, out.NodesHealthy)
+	require.Equal(uint64(1001), out.CreateIndex)
+
+	// Claims require a running allocation
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	claim := &structs.CSIVolumeClaim{AllocationID: alloc.ID, NodeID: node.ID, Mode: structs.CSIVolumeClaimWrite}
+	require.Error(state.CSIVolumeClaim(1002, vol.Namespace, vol.ID, claim))
+
+	require.NoError(state.UpsertAllocs(1003, []*structs.Allocation{alloc}))
+	require.NoError(state.CSIVolumeClaim(1004, vol.Namespace, vol.ID, claim))
+	out, err = state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Len(out.WriteClaims, 1)
+	require.Equal(uint64(1004), out.ModifyIndex)
+
+	// Claimed volumes can not be updated or deregistered without force
+	require.Error(state.CSIVolumeRegister(1005, []*structs.CSIVolume{vol.Copy()}))
+	require.Error(state.CSIVolumeDeregister(1005, vol.Namespace, []string{vol.ID}, false))
+
+	release := &structs.CSIVolumeClaim{AllocationID: alloc.ID, Mode: structs.CSIVolumeClaimRelease,
+		State: structs.CSIVolumeClaimStateReadyToFree}
+	require.NoError(state.CSIVolumeClaim(1006, vol.Namespace, vol.ID, release))
+	out, err = state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.False(out.InUse())
+
+	require.NoError(state.CSIVolumeDeregister(1007, vol.Namespace, []string{vol.ID}, false))
+	out, err = state.CSIVolumeByID(nil, vol.Namespace, vol.ID)
+	require.NoError(err)
+	require.Nil(out)
+}
@@ -0,0 +1,200 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// DefaultEventBufferSize is the default number of raft logs whose events
+	// are buffered for replay.
+	DefaultEventBufferSize = 100
+)
+
+var (
+	// ErrSubscriptionClosed is returned by subscriptions of a closed broker.
+	ErrSubscriptionClosed = errors.New("subscription closed by server")
+)
+
+// EventBroker buffers the events published by the FSM and delivers them to
+// subscribers. Subscribers may resume from a raft index, in which case the
+// buffered events after the index are replayed before new events are
+// delivered.
+type EventBroker struct {
+	l      sync.Mutex
+	buffer *eventBuffer
+
+	// notifyCh is closed and replaced every time events are published to
+	// wake subscriptions waiting for new events.
+	notifyCh chan struct{}
+
+	closed bool
+}
+
+// NewEventBroker returns a broker buffering the events of up to size raft
+// logs.
+func NewEventBroker(size int) *EventBroker {
+	if size <= 0 {
+		size = DefaultEventBufferSize
+	}
+	return &EventBroker{
+		buffer:   newEventBuffer(size),
+		notifyCh: make(chan struct{}),
+	}
+}
+
+// Publish adds the events created by the raft log at index to the buffer and
+// notifies subscribers.
+func (e *EventBroker) Publish(index uint64, events []structs.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	for i := range events {
+		events[i].Index = index
+	}
+
+	e.l.Lock()
+	defer e.l.Unlock()
+	if e.closed {
+		return
+	}
+	e.buffer.append(&structs.Events{Index: index, Events: events})
+	close(e.notifyCh)
+	e.notifyCh = make(chan struct{})
+}
+
+// Len returns the number of buffered raft logs.
+func (e *EventBroker) Len() int {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.buffer.len()
+}
+
+// Close closes the broker, causing all subscriptions to return
+// ErrSubscriptionClosed.
+func (e *EventBroker) Close() {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if e.closed {
+		return
+	}
+	e.closed = true
+	close(e.notifyCh)
+}
+
+// SubscribeRequest describes the events a subscription receives.
+type SubscribeRequest struct {
+	// Topics maps topics to the keys of the events to receive. The topic or
+	// key "*" matches every topic or key.
+	Topics map[structs.Topic][]string
+
+	// Namespace restricts namespaced events to the namespace. The namespace
+	// "*" matches every namespace.
+	Namespace string
+
+	// Index is the raft index to resume after. When zero, only events
+	// published after subscribing are received.
+	Index uint64
+}
+
+// Subscribe returns a subscription to the events matching the request.
+func (e *EventBroker) Subscribe(req *SubscribeRequest) *Subscription {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	next := e.buffer.tail
+	if req.Index != 0 {
+		next = e.buffer.seqAfter(req.Index)
+	}
+	return &Subscription{
+		broker: e,
+		req:    req,
+		next:   next,
+	}
+}
+
+// Subscription is a position in the event stream of a broker.
+type Subscription struct {
+	broker *EventBroker
+	req    *SubscribeRequest
+
+	// next is the sequence number of the next buffered item to read.
+	next uint64
+}
+
+// Next blocks until events matching the subscription are published and
+// returns them. If the subscriber falls so far behind that events are dropped
+// from the buffer, it continues from the oldest buffered events.
+func (s *Subscription) Next(ctx context.Context) (*structs.Events, error) {
+	for {
+		e := s.broker
+		e.l.Lock()
+		if e.closed {
+			e.l.Unlock()
+			return nil, ErrSubscriptionClosed
+		}
+		if s.next < e.buffer.head {
+			s.next = e.buffer.head
+		}
+		events, ok := e.buffer.get(s.next)
+		notifyCh := e.notifyCh
+		e.l.Unlock()
+
+		if ok {
+			s.next++
+			if filtered := s.filter(events); filtered != nil {
+				return filtered, nil
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notifyCh:
+		}
+	}
+}
+
+// filter returns the events matching the subscription, or nil if none do.
+func (s *Subscription) filter(events *structs.Events) *structs.Events {
+	var matched []structs.Event
+	for _, event := range events.Events {
+		if s.matches(&event) {
+			matched = append(matched, event)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	return &structs.Events{Index: events.Index, Events: matched}
+}
+
+// matches returns whether the event matches the subscription.
+func (s *Subscription) matches(event *structs.Event) bool {
+	if event.Namespace != "" && s.req.Namespace != "*" && event.Namespace != s.req.Namespace {
+		return false
+	}
+
+	keys, ok := s.req.Topics[event.Topic]
+	if !ok {
+		if keys, ok = s.req.Topics[structs.TopicAll]; !ok {
+			return false
+		}
+	}
+	for _, key := range keys {
+		if key == "*" || key == event.Key {
+			return true
+		}
+		for _, fk := range event.FilterKeys {
+			if key == fk {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func jobEvent(id, namespace string) structs.Event {
+	return structs.Event{
+		Topic:     structs.TopicJob,
+		Type:      structs.TypeJobRegistered,
+		Key:       id,
+		Namespace: namespace,
+	}
+}
+
+func TestEventBroker_Subscribe_New(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	broker := NewEventBroker(10)
+	broker.Publish(1, []structs.Event{jobEvent("old", "default")})
+
+	sub := broker.Subscribe(&SubscribeRequest{
+		Topics:    map[structs.Topic][]string{structs.TopicJob: {"*"}},
+		Namespace: "default",
+	})
+
+	// Publish asynchronously to exercise waiting for new events
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		broker.Publish(2, []structs.Event{jobEvent("new", "default")})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := sub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(2), events.Index)
+	require.Len(events.Events, 1)
+	require.Equal("new", events.Events[0].Key)
+	require.Equal(uint64(2), events.Events[0].Index)
+}
+
+func TestEventBroker_Subscribe_Resume(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	broker := NewEventBroker(2)
+	broker.Publish(10, []structs.Event{jobEvent("a", "default")})
+	broker.Publish(20, []structs.Event{jobEvent("b", "default")})
+	broker.Publish(30, []structs.Event{jobEvent("c", "default")})
+	require.Equal(2, broker.Len())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Resuming after a buffered index replays the newer events
+	sub := broker.Subscribe(&SubscribeRequest{
+		Topics:    map[structs.Topic][]string{structs.TopicAll: {"*"}},
+		Namespace: "default",
+		Index:     20,
+	})
+	events, err := sub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(30), events.Index)
+
+	// Resuming after a dropped index replays from the oldest buffered events
+	sub = broker.Subscribe(&SubscribeRequest{
+		Topics:    map[structs.Topic][]string{structs.TopicAll: {"*"}},
+		Namespace: "default",
+		Index:     5,
+	})
+	events, err = sub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(20), events.Index)
+	events, err = sub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(30), events.Index)
+}
+
+func TestEventBroker_Subscribe_Filter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	broker := NewEventBroker(10)
+	alloc := structs.Event{
+		Topic:      structs.TopicAllocation,
+		Type:       structs.TypeAllocationUpdated,
+		Key:        "alloc",
+		FilterKeys: []string{"web"},
+		Namespace:  "default",
+	}
+	node := structs.Event{
+		Topic: structs.TopicNode,
+		Type:  structs.TypeNodeRegistration,
+		Key:   "node",
+	}
+	sub := broker.Subscribe(&SubscribeRequest{
+		Topics: map[structs.Topic][]string{
+			structs.TopicJob:        {"web"},
+			structs.TopicAllocation: {"web"},
+		},
+		Namespace: "default",
+	})
+	wildcardSub := broker.Subscribe(&SubscribeRequest{
+		Topics:    map[structs.Topic][]string{structs.TopicAll: {"web", "node"}},
+		Namespace: "*",
+	})
+
+	broker.Publish(1, []structs.Event{jobEvent("web", "other"), jobEvent("api", "default")})
+	broker.Publish(2, []structs.Event{node})
+	broker.Publish(3, []structs.Event{jobEvent("web", "default"), alloc})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The logs without matching events are skipped, and allocations match
+	// on their job
+	events, err := sub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(3), events.Index)
+	require.Len(events.Events, 2)
+	require.Equal("web", events.Events[0].Key)
+	require.Equal("alloc", events.Events[1].Key)
+
+	// Namespace wildcards match namespaced events of every namespace, and
+	// events without a namespace
+	events, err = wildcardSub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(1), events.Index)
+	require.Len(events.Events, 1)
+	require.Equal("other", events.Events[0].Namespace)
+	events, err = wildcardSub.Next(ctx)
+	require.NoError(err)
+	require.Equal(uint64(2), events.Index)
+}
+
+func TestEventBroker_Close(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	broker := NewEventBroker(10)
+	sub := broker.Subscribe(&SubscribeRequest{
+		Topics: map[structs.Topic][]string{structs.TopicAll: {"*"}},
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := sub.Next(context.Background())
+		errCh <- err
+	}()
+
+	broker.Close()
+	select {
+	case err := <-errCh:
+		require.Equal(ErrSubscriptionClosed, err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("subscription not closed")
+	}
+}
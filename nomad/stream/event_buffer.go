@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"sort"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// eventBuffer is a fixed size ring buffer of published events. Each item is
+// assigned an increasing sequence number when it is appended, which
+// subscriptions use to track their position. When the buffer is full the
+// oldest item is overwritten. eventBuffer is not safe for concurrent use.
+type eventBuffer struct {
+	items []*structs.Events
+
+	// head is the sequence number of the oldest item and tail is the
+	// sequence number the next item is appended with.
+	head uint64
+	tail uint64
+}
+
+// newEventBuffer returns an empty buffer holding up to size items.
+func newEventBuffer(size int) *eventBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &eventBuffer{
+		items: make([]*structs.Events, size),
+	}
+}
+
+// append adds the events to the buffer, dropping the oldest item if the
+// buffer is full.
+func (b *eventBuffer) append(events *structs.Events) {
+	b.items[b.tail%uint64(len(b.items))] = events
+	b.tail++
+	if b.tail-b.head > uint64(len(b.items)) {
+		b.head++
+	}
+}
+
+// get returns the item with the given sequence number, or false if it has
+// been dropped or not yet appended.
+func (b *eventBuffer) get(seq uint64) (*structs.Events, bool) {
+	if seq < b.head || seq >= b.tail {
+		return nil, false
+	}
+	return b.items[seq%uint64(len(b.items))], true
+}
+
+// seqAfter returns the sequence number of the oldest buffered item with a
+// raft index greater than index, or the tail if there is none.
+func (b *eventBuffer) seqAfter(index uint64) uint64 {
+	n := int(b.tail - b.head)
+	i := sort.Search(n, func(i int) bool {
+		events, _ := b.get(b.head + uint64(i))
+		return events.Index > index
+	})
+	return b.head + uint64(i)
+}
+
+// len returns the number of buffered items.
+func (b *eventBuffer) len() int {
+	return int(b.tail - b.head)
+}
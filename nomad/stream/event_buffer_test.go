@@ -0,0 +1,48 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBuffer_Append(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	b := newEventBuffer(3)
+	for i := uint64(1); i <= 5; i++ {
+		b.append(&structs.Events{Index: i * 10})
+	}
+
+	// The two oldest items were dropped
+	require.Equal(3, b.len())
+	_, ok := b.get(1)
+	require.False(ok)
+	_, ok = b.get(5)
+	require.False(ok)
+	for seq := uint64(2); seq < 5; seq++ {
+		events, ok := b.get(seq)
+		require.True(ok)
+		require.Equal((seq+1)*10, events.Index)
+	}
+}
+
+func TestEventBuffer_SeqAfter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	b := newEventBuffer(3)
+	for i := uint64(1); i <= 5; i++ {
+		b.append(&structs.Events{Index: i * 10})
+	}
+
+	// Indexes older than the buffer resume from the oldest item
+	require.Equal(uint64(2), b.seqAfter(5))
+	require.Equal(uint64(2), b.seqAfter(20))
+	require.Equal(uint64(3), b.seqAfter(30))
+	require.Equal(uint64(3), b.seqAfter(35))
+	require.Equal(uint64(5), b.seqAfter(50))
+	require.Equal(uint64(5), b.seqAfter(100))
+}
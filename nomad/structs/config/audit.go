@@ -0,0 +1,172 @@
+package config
+
+import (
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
+// AuditConfig is the configuration of the audit logging of the HTTP API
+// requests handled by the agent.
+type AuditConfig struct {
+	// Enabled controls whether the agent audits requests.
+	Enabled *bool `hcl:"enabled"`
+
+	// Sinks are where the audit events are written.
+	Sinks []*AuditSink `hcl:"sink,expand"`
+
+	// Filters exclude matching events from the audit log.
+	Filters []*AuditFilter `hcl:"filter,expand"`
+}
+
+// AuditSink is the configuration of a destination of audit events.
+type AuditSink struct {
+	// Name is the unique name of the sink.
+	Name string `hcl:",key"`
+
+	// Type is the type of the sink, either "file" or "socket".
+	Type string `hcl:"type"`
+
+	// DeliveryGuarantee is either "enforced", in which case requests fail
+	// when their events can't be written, or "best-effort".
+	DeliveryGuarantee string `hcl:"delivery_guarantee"`
+
+	// Format is the format of the events. Only "json" is supported.
+	Format string `hcl:"format"`
+
+	// Path is the file events are written to by file sinks.
+	Path string `hcl:"path"`
+
+	// RotateBytes is the size after which the file of a file sink is
+	// rotated. Zero disables rotating by size.
+	RotateBytes int `hcl:"rotate_bytes"`
+
+	// RotateDuration is the time after which the file of a file sink is
+	// rotated. Zero disables rotating by time.
+	RotateDuration    time.Duration `hcl:"-"`
+	RotateDurationHCL string        `hcl:"rotate_duration" json:"-"`
+
+	// RotateMaxFiles is the number of rotated files kept by a file sink.
+	// Zero keeps all of them.
+	RotateMaxFiles int `hcl:"rotate_max_files"`
+
+	// Address is the address events are sent to by socket sinks.
+	Address string `hcl:"address"`
+
+	// SocketType is the network of socket sinks: "tcp", "udp" or "unix".
+	SocketType string `hcl:"socket_type"`
+}
+
+// AuditFilter excludes the events matching all of its fields from the audit
+// log.
+type AuditFilter struct {
+	// Name is the unique name of the filter.
+	Name string `hcl:",key"`
+
+	// Type is the type of events filtered. Only "HTTPEvent" is supported.
+	Type string `hcl:"type"`
+
+	// Endpoints are the glob patterns of the request paths to filter.
+	Endpoints []string `hcl:"endpoints"`
+
+	// Stages are the stages of the events to filter, or "*" for all.
+	Stages []string `hcl:"stages"`
+
+	// Operations are the HTTP methods of the requests to filter, or "*" for
+	// all.
+	Operations []string `hcl:"operations"`
+}
+
+// DefaultAuditConfig returns the canonical defaults for the Nomad `audit`
+// configuration. Auditing is disabled by default.
+func DefaultAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		Enabled: helper.BoolToPtr(false),
+	}
+}
+
+// Merge is used to merge two audit configs together. The settings from the
+// input always take precedence. Sinks and filters are merged by name.
+func (a *AuditConfig) Merge(b *AuditConfig) *AuditConfig {
+	result := a.Copy()
+
+	if b.Enabled != nil {
+		result.Enabled = helper.BoolToPtr(*b.Enabled)
+	}
+
+SINKS:
+	for _, sink := range b.Sinks {
+		for i, existing := range result.Sinks {
+			if existing.Name == sink.Name {
+				result.Sinks[i] = sink.Copy()
+				continue SINKS
+			}
+		}
+		result.Sinks = append(result.Sinks, sink.Copy())
+	}
+
+FILTERS:
+	for _, filter := range b.Filters {
+		for i, existing := range result.Filters {
+			if existing.Name == filter.Name {
+				result.Filters[i] = filter.Copy()
+				continue FILTERS
+			}
+		}
+		result.Filters = append(result.Filters, filter.Copy())
+	}
+
+	return result
+}
+
+// Copy returns a copy of this audit config.
+func (a *AuditConfig) Copy() *AuditConfig {
+	if a == nil {
+		return nil
+	}
+
+	nc := new(AuditConfig)
+	*nc = *a
+
+	if a.Enabled != nil {
+		nc.Enabled = helper.BoolToPtr(*a.Enabled)
+	}
+	if a.Sinks != nil {
+		nc.Sinks = make([]*AuditSink, len(a.Sinks))
+		for i, sink := range a.Sinks {
+			nc.Sinks[i] = sink.Copy()
+		}
+	}
+	if a.Filters != nil {
+		nc.Filters = make([]*AuditFilter, len(a.Filters))
+		for i, filter := range a.Filters {
+			nc.Filters[i] = filter.Copy()
+		}
+	}
+	return nc
+}
+
+// Copy returns a copy of this audit sink.
+func (s *AuditSink) Copy() *AuditSink {
+	if s == nil {
+		return nil
+	}
+
+	ns := new(AuditSink)
+	*ns = *s
+	return ns
+}
+
+// Copy returns a copy of this audit filter.
+func (f *AuditFilter) Copy() *AuditFilter {
+	if f == nil {
+		return nil
+	}
+
+	nf := new(AuditFilter)
+	*nf = *f
+	nf.Endpoints = helper.CopySliceString(f.Endpoints)
+	nf.Stages = helper.CopySliceString(f.Stages)
+	nf.Operations = helper.CopySliceString(f.Operations)
+	return nf
+}
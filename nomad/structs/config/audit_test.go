@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditConfig_Merge(t *testing.T) {
+	c1 := &AuditConfig{
+		Enabled: helper.BoolToPtr(false),
+		Sinks: []*AuditSink{
+			{
+				Name: "file",
+				Type: "file",
+				Path: "/tmp/audit.log",
+			},
+		},
+		Filters: []*AuditFilter{
+			{
+				Name:      "metrics",
+				Type:      "HTTPEvent",
+				Endpoints: []string{"/v1/metrics"},
+			},
+		},
+	}
+
+	c2 := &AuditConfig{
+		Enabled: helper.BoolToPtr(true),
+		Sinks: []*AuditSink{
+			{
+				Name:           "file",
+				Type:           "file",
+				Path:           "/var/log/audit.log",
+				RotateDuration: time.Hour,
+			},
+			{
+				Name:    "socket",
+				Type:    "socket",
+				Address: "127.0.0.1:9090",
+			},
+		},
+	}
+
+	expected := &AuditConfig{
+		Enabled: helper.BoolToPtr(true),
+		Sinks: []*AuditSink{
+			{
+				Name:           "file",
+				Type:           "file",
+				Path:           "/var/log/audit.log",
+				RotateDuration: time.Hour,
+			},
+			{
+				Name:    "socket",
+				Type:    "socket",
+				Address: "127.0.0.1:9090",
+			},
+		},
+		Filters: []*AuditFilter{
+			{
+				Name:      "metrics",
+				Type:      "HTTPEvent",
+				Endpoints: []string{"/v1/metrics"},
+			},
+		},
+	}
+
+	result := c1.Merge(c2)
+	require.Equal(t, expected, result)
+
+	// The inputs are not modified
+	require.Equal(t, "/tmp/audit.log", c1.Sinks[0].Path)
+	require.Len(t, c1.Sinks, 1)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	vault "github.com/hashicorp/vault/api"
@@ -10,6 +11,18 @@ const (
 	// DefaultVaultConnectRetryIntv is the retry interval between trying to
 	// connect to Vault
 	DefaultVaultConnectRetryIntv = 30 * time.Second
+
+	// DefaultVaultJWTAuthPath is the default mount path of the Vault JWT auth
+	// method clients exchange workload identities against
+	DefaultVaultJWTAuthPath = "jwt"
+
+	// DefaultVaultJWTAuthRole is the default Vault role clients exchange
+	// workload identities for
+	DefaultVaultJWTAuthRole = "nomad-workloads"
+
+	// DefaultVaultClusterName is the name of the Vault cluster configured by
+	// the unnamed vault block
+	DefaultVaultClusterName = "default"
 )
 
 // VaultConfig contains the configuration information necessary to
@@ -22,6 +35,10 @@ const (
 // - Create child tokens with policy subsets of the Server's token.
 type VaultConfig struct {
 
+	// Name is the name of the Vault cluster jobs select it by. The unnamed
+	// cluster is the default cluster.
+	Name string `mapstructure:"name"`
+
 	// Enabled enables or disables Vault support.
 	Enabled *bool `mapstructure:"enabled"`
 
@@ -74,6 +91,28 @@ type VaultConfig struct {
 
 	// TLSServerName, if set, is used to set the SNI host when connecting via TLS.
 	TLSServerName string `mapstructure:"tls_server_name"`
+
+	// JWTSigningKeyFile is the path to the PEM encoded private key Nomad
+	// Servers sign workload identities with. When set, Servers don't hold a
+	// Vault token: clients exchange the workload identity of each task for a
+	// Vault token by logging in to Vault's JWT auth method.
+	JWTSigningKeyFile string `mapstructure:"jwt_signing_key_file"`
+
+	// JWTAuthPath is the mount path of the Vault JWT auth method clients log
+	// in to with workload identities.
+	JWTAuthPath string `mapstructure:"jwt_auth_path"`
+
+	// JWTAuthRole is the Vault role clients log in with workload identities.
+	JWTAuthRole string `mapstructure:"jwt_auth_role"`
+
+	// Namespace is the Vault Enterprise namespace requests to the cluster are
+	// made in.
+	Namespace string `mapstructure:"namespace"`
+
+	// NomadNamespaces are the Nomad namespaces whose jobs use the cluster
+	// unless they select another one. When set, jobs from other namespaces
+	// can't use the cluster.
+	NomadNamespaces []string `mapstructure:"nomad_namespaces"`
 }
 
 // DefaultVaultConfig() returns the canonical defaults for the Nomad
@@ -93,6 +132,85 @@ func (a *VaultConfig) IsEnabled() bool {
 	return a.Enabled != nil && *a.Enabled
 }
 
+// GetName returns the name of the Vault cluster.
+func (a *VaultConfig) GetName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return DefaultVaultClusterName
+}
+
+// AllowsNomadNamespace returns whether jobs in the Nomad namespace can use the
+// cluster.
+func (a *VaultConfig) AllowsNomadNamespace(namespace string) bool {
+	if len(a.NomadNamespaces) == 0 {
+		return true
+	}
+	for _, ns := range a.NomadNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// VaultClusterFor returns the name of the Vault cluster used by jobs in the
+// Nomad namespace that don't select one: the named cluster listing the
+// namespace, or else the default cluster.
+func VaultClusterFor(clusters map[string]*VaultConfig, namespace string) string {
+	for name, c := range clusters {
+		for _, ns := range c.NomadNamespaces {
+			if ns == namespace {
+				return name
+			}
+		}
+	}
+	return DefaultVaultClusterName
+}
+
+// ValidateVaultClusters checks that the named Vault clusters are keyed by
+// their names and that each Nomad namespace is mapped to a single cluster.
+func ValidateVaultClusters(clusters map[string]*VaultConfig) error {
+	namespaces := make(map[string]string)
+	for name, c := range clusters {
+		if name == DefaultVaultClusterName {
+			return fmt.Errorf("Vault cluster name %q is reserved for the unnamed vault block", name)
+		}
+		if c.GetName() != name {
+			return fmt.Errorf("Vault cluster %q has mismatched name %q", name, c.GetName())
+		}
+		for _, ns := range c.NomadNamespaces {
+			if other, ok := namespaces[ns]; ok {
+				return fmt.Errorf("Nomad namespace %q is mapped to Vault clusters %q and %q", ns, other, name)
+			}
+			namespaces[ns] = name
+		}
+	}
+	return nil
+}
+
+// UsesJWTAuth returns whether Servers sign workload identities that clients
+// exchange for Vault tokens rather than creating the tokens themselves.
+func (a *VaultConfig) UsesJWTAuth() bool {
+	return a.JWTSigningKeyFile != ""
+}
+
+// GetJWTAuthPath returns the mount path of the Vault JWT auth method.
+func (a *VaultConfig) GetJWTAuthPath() string {
+	if a.JWTAuthPath != "" {
+		return a.JWTAuthPath
+	}
+	return DefaultVaultJWTAuthPath
+}
+
+// GetJWTAuthRole returns the Vault role clients log in with.
+func (a *VaultConfig) GetJWTAuthRole() string {
+	if a.JWTAuthRole != "" {
+		return a.JWTAuthRole
+	}
+	return DefaultVaultJWTAuthRole
+}
+
 // AllowsUnauthenticated returns whether the config allows unauthenticated
 // access to Vault
 func (a *VaultConfig) AllowsUnauthenticated() bool {
@@ -103,6 +221,9 @@ func (a *VaultConfig) AllowsUnauthenticated() bool {
 func (a *VaultConfig) Merge(b *VaultConfig) *VaultConfig {
 	result := *a
 
+	if b.Name != "" {
+		result.Name = b.Name
+	}
 	if b.Token != "" {
 		result.Token = b.Token
 	}
@@ -133,6 +254,21 @@ func (a *VaultConfig) Merge(b *VaultConfig) *VaultConfig {
 	if b.TLSServerName != "" {
 		result.TLSServerName = b.TLSServerName
 	}
+	if b.JWTSigningKeyFile != "" {
+		result.JWTSigningKeyFile = b.JWTSigningKeyFile
+	}
+	if b.JWTAuthPath != "" {
+		result.JWTAuthPath = b.JWTAuthPath
+	}
+	if b.JWTAuthRole != "" {
+		result.JWTAuthRole = b.JWTAuthRole
+	}
+	if b.Namespace != "" {
+		result.Namespace = b.Namespace
+	}
+	if len(b.NomadNamespaces) != 0 {
+		result.NomadNamespaces = append([]string(nil), b.NomadNamespaces...)
+	}
 	if b.AllowUnauthenticated != nil {
 		result.AllowUnauthenticated = b.AllowUnauthenticated
 	}
@@ -179,6 +315,9 @@ func (c *VaultConfig) Copy() *VaultConfig {
 
 	nc := new(VaultConfig)
 	*nc = *c
+	if c.NomadNamespaces != nil {
+		nc.NomadNamespaces = append([]string(nil), c.NomadNamespaces...)
+	}
 	return nc
 }
 
@@ -192,6 +331,9 @@ func (a *VaultConfig) IsEqual(b *VaultConfig) bool {
 		return false
 	}
 
+	if a.Name != b.Name {
+		return false
+	}
 	if a.Token != b.Token {
 		return false
 	}
@@ -222,6 +364,26 @@ func (a *VaultConfig) IsEqual(b *VaultConfig) bool {
 	if a.TLSServerName != b.TLSServerName {
 		return false
 	}
+	if a.JWTSigningKeyFile != b.JWTSigningKeyFile {
+		return false
+	}
+	if a.JWTAuthPath != b.JWTAuthPath {
+		return false
+	}
+	if a.JWTAuthRole != b.JWTAuthRole {
+		return false
+	}
+	if a.Namespace != b.Namespace {
+		return false
+	}
+	if len(a.NomadNamespaces) != len(b.NomadNamespaces) {
+		return false
+	}
+	for i, ns := range a.NomadNamespaces {
+		if ns != b.NomadNamespaces[i] {
+			return false
+		}
+	}
 	if a.AllowUnauthenticated != b.AllowUnauthenticated {
 		return false
 	}
@@ -22,6 +22,9 @@ func TestVaultConfig_Merge(t *testing.T) {
 		TLSKeyFile:           "1",
 		TLSSkipVerify:        &trueValue,
 		TLSServerName:        "1",
+		JWTSigningKeyFile:    "1",
+		JWTAuthPath:          "1",
+		JWTAuthRole:          "1",
 	}
 
 	c2 := &VaultConfig{
@@ -37,6 +40,9 @@ func TestVaultConfig_Merge(t *testing.T) {
 		TLSKeyFile:           "2",
 		TLSSkipVerify:        nil,
 		TLSServerName:        "2",
+		JWTSigningKeyFile:    "2",
+		JWTAuthPath:          "2",
+		JWTAuthRole:          "2",
 	}
 
 	e := &VaultConfig{
@@ -52,6 +58,9 @@ func TestVaultConfig_Merge(t *testing.T) {
 		TLSKeyFile:           "2",
 		TLSSkipVerify:        &trueValue,
 		TLSServerName:        "2",
+		JWTSigningKeyFile:    "2",
+		JWTAuthPath:          "2",
+		JWTAuthRole:          "2",
 	}
 
 	result := c1.Merge(c2)
@@ -127,3 +136,46 @@ func TestVaultConfig_IsEqual(t *testing.T) {
 	}
 	require.False(c3.IsEqual(c4))
 }
+
+func TestVaultConfig_JWTAuth(t *testing.T) {
+	require := require.New(t)
+
+	c := &VaultConfig{}
+	require.False(c.UsesJWTAuth())
+	require.Equal(DefaultVaultJWTAuthPath, c.GetJWTAuthPath())
+	require.Equal(DefaultVaultJWTAuthRole, c.GetJWTAuthRole())
+
+	c = &VaultConfig{
+		JWTSigningKeyFile: "/etc/nomad.d/identity.pem",
+		JWTAuthPath:       "nomad",
+		JWTAuthRole:       "web",
+	}
+	require.True(c.UsesJWTAuth())
+	require.Equal("nomad", c.GetJWTAuthPath())
+	require.Equal("web", c.GetJWTAuthRole())
+}
+
+func TestVaultConfig_Clusters(t *testing.T) {
+	require := require.New(t)
+
+	eu := &VaultConfig{Name: "eu", NomadNamespaces: []string{"web"}}
+	clusters := map[string]*VaultConfig{"eu": eu}
+	require.NoError(ValidateVaultClusters(clusters))
+
+	// Namespaces select the cluster mapped to them
+	require.Equal("eu", VaultClusterFor(clusters, "web"))
+	require.Equal(DefaultVaultClusterName, VaultClusterFor(clusters, "db"))
+	require.True(eu.AllowsNomadNamespace("web"))
+	require.False(eu.AllowsNomadNamespace("db"))
+	require.True((&VaultConfig{}).AllowsNomadNamespace("db"))
+
+	// Namespaces may be mapped to a single cluster
+	clusters["us"] = &VaultConfig{Name: "us", NomadNamespaces: []string{"web"}}
+	require.Error(ValidateVaultClusters(clusters))
+
+	// The default cluster is the unnamed one
+	require.Error(ValidateVaultClusters(map[string]*VaultConfig{
+		DefaultVaultClusterName: {Name: DefaultVaultClusterName},
+	}))
+	require.Error(ValidateVaultClusters(map[string]*VaultConfig{"eu": {Name: "us"}}))
+}
@@ -0,0 +1,794 @@
+package structs
+
+import (
+	"fmt"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+// CSIVolumeAccessMode is how allocations may access a CSI volume. The modes
+// mirror the access modes of the CSI specification.
+type CSIVolumeAccessMode string
+
+const (
+	CSIVolumeAccessModeUnknown CSIVolumeAccessMode = ""
+
+	// CSIVolumeAccessModeSingleNodeReader allows the volume to be mounted
+	// read-only on a single node.
+	CSIVolumeAccessModeSingleNodeReader CSIVolumeAccessMode = "single-node-reader-only"
+
+	// CSIVolumeAccessModeSingleNodeWriter allows the volume to be mounted
+	// for writing on a single node.
+	CSIVolumeAccessModeSingleNodeWriter CSIVolumeAccessMode = "single-node-writer"
+
+	// CSIVolumeAccessModeMultiNodeReader allows the volume to be mounted
+	// read-only on any number of nodes.
+	CSIVolumeAccessModeMultiNodeReader CSIVolumeAccessMode = "multi-node-reader-only"
+
+	// CSIVolumeAccessModeMultiNodeSingleWriter allows the volume to be
+	// mounted on any number of nodes but only written by one allocation.
+	CSIVolumeAccessModeMultiNodeSingleWriter CSIVolumeAccessMode = "multi-node-single-writer"
+
+	// CSIVolumeAccessModeMultiNodeMultiWriter allows the volume to be
+	// mounted for writing on any number of nodes.
+	CSIVolumeAccessModeMultiNodeMultiWriter CSIVolumeAccessMode = "multi-node-multi-writer"
+)
+
+// Valid returns whether the access mode is known
+func (m CSIVolumeAccessMode) Valid() bool {
+	switch m {
+	case CSIVolumeAccessModeSingleNodeReader,
+		CSIVolumeAccessModeSingleNodeWriter,
+		CSIVolumeAccessModeMultiNodeReader,
+		CSIVolumeAccessModeMultiNodeSingleWriter,
+		CSIVolumeAccessModeMultiNodeMultiWriter:
+		return true
+	default:
+		return false
+	}
+}
+
+// singleNode returns whether the volume may only be mounted on one node at a
+// time
+func (m CSIVolumeAccessMode) singleNode() bool {
+	return m == CSIVolumeAccessModeSingleNodeReader || m == CSIVolumeAccessModeSingleNodeWriter
+}
+
+// readOnly returns whether the volume may only be mounted read-only
+func (m CSIVolumeAccessMode) readOnly() bool {
+	return m == CSIVolumeAccessModeSingleNodeReader || m == CSIVolumeAccessModeMultiNodeReader
+}
+
+// CSIVolumeAttachmentMode is how a CSI volume is presented to allocations
+type CSIVolumeAttachmentMode string
+
+const (
+	CSIVolumeAttachmentModeUnknown CSIVolumeAttachmentMode = ""
+
+	// CSIVolumeAttachmentModeBlockDevice presents the volume as a raw block
+	// device.
+	CSIVolumeAttachmentModeBlockDevice CSIVolumeAttachmentMode = "block-device"
+
+	// CSIVolumeAttachmentModeFilesystem presents the volume as a mounted
+	// filesystem.
+	CSIVolumeAttachmentModeFilesystem CSIVolumeAttachmentMode = "file-system"
+)
+
+// Valid returns whether the attachment mode is known
+func (m CSIVolumeAttachmentMode) Valid() bool {
+	switch m {
+	case CSIVolumeAttachmentModeBlockDevice, CSIVolumeAttachmentModeFilesystem:
+		return true
+	default:
+		return false
+	}
+}
+
+// CSIVolumeClaimMode is the kind of claim an allocation holds on a volume
+type CSIVolumeClaimMode int
+
+const (
+	CSIVolumeClaimRead CSIVolumeClaimMode = iota
+	CSIVolumeClaimWrite
+
+	// CSIVolumeClaimRelease is used to release a claim once the allocation
+	// holding it is terminal.
+	CSIVolumeClaimRelease
+)
+
+func (m CSIVolumeClaimMode) String() string {
+	switch m {
+	case CSIVolumeClaimRead:
+		return "read"
+	case CSIVolumeClaimWrite:
+		return "write"
+	case CSIVolumeClaimRelease:
+		return "release"
+	default:
+		return "unknown"
+	}
+}
+
+// CSIVolumeClaimState tracks the progress of releasing a claim. Each step of
+// detaching a volume is recorded so that a failed release can be resumed.
+type CSIVolumeClaimState int
+
+const (
+	CSIVolumeClaimStateTaken CSIVolumeClaimState = iota
+	CSIVolumeClaimStateNodeDetached
+	CSIVolumeClaimStateReadyToFree
+)
+
+func (s CSIVolumeClaimState) String() string {
+	switch s {
+	case CSIVolumeClaimStateTaken:
+		return "taken"
+	case CSIVolumeClaimStateNodeDetached:
+		return "node-detached"
+	case CSIVolumeClaimStateReadyToFree:
+		return "ready-to-free"
+	default:
+		return "unknown"
+	}
+}
+
+// CSIVolumeClaim is an allocation's claim on a CSI volume
+type CSIVolumeClaim struct {
+	AllocationID string
+	NodeID       string
+
+	// ExternalNodeID is the ID of the node as known to the storage provider,
+	// as reported by the node plugin.
+	ExternalNodeID string
+
+	Mode  CSIVolumeClaimMode
+	State CSIVolumeClaimState
+}
+
+// Copy returns a copy of the claim
+func (c *CSIVolumeClaim) Copy() *CSIVolumeClaim {
+	if c == nil {
+		return nil
+	}
+	nc := new(CSIVolumeClaim)
+	*nc = *c
+	return nc
+}
+
+// CSIVolume is a volume provided by a CSI plugin and registered with Nomad so
+// it can be claimed by allocations.
+type CSIVolume struct {
+	// ID is the name of the volume in Nomad, unique within a namespace
+	ID        string
+	Name      string
+	Namespace string
+
+	// ExternalID is the ID of the volume known to the storage provider
+	ExternalID string
+
+	PluginID        string
+	Provider        string
+	ProviderVersion string
+
+	AccessMode     CSIVolumeAccessMode
+	AttachmentMode CSIVolumeAttachmentMode
+
+	// CapacityMin and CapacityMax bound the size in bytes of volumes created
+	// by Nomad. Capacity is the size reported by the provider.
+	CapacityMin int64
+	CapacityMax int64
+	Capacity    int64
+
+	// Secrets are passed to the plugin with every request for the volume.
+	// They are never returned by the API.
+	Secrets map[string]string
+
+	// Parameters are passed to the plugin when creating the volume and
+	// Context is the opaque volume context returned by the provider.
+	Parameters map[string]string
+	Context    map[string]string
+
+	// ReadClaims and WriteClaims are the current claims by allocation ID.
+	// PastClaims are claims of terminal allocations that are still being
+	// released.
+	ReadClaims  map[string]*CSIVolumeClaim
+	WriteClaims map[string]*CSIVolumeClaim
+	PastClaims  map[string]*CSIVolumeClaim
+
+	// The health of the volume is denormalized from its plugin when the
+	// volume is read.
+	Schedulable         bool
+	ControllerRequired  bool
+	ControllersHealthy  int
+	ControllersExpected int
+	NodesHealthy        int
+	NodesExpected       int
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Copy returns a deep copy of the volume
+func (v *CSIVolume) Copy() *CSIVolume {
+	if v == nil {
+		return nil
+	}
+	nv := new(CSIVolume)
+	*nv = *v
+	nv.Secrets = helper.CopyMapStringString(v.Secrets)
+	nv.Parameters = helper.CopyMapStringString(v.Parameters)
+	nv.Context = helper.CopyMapStringString(v.Context)
+	nv.ReadClaims = copyCSIVolumeClaims(v.ReadClaims)
+	nv.WriteClaims = copyCSIVolumeClaims(v.WriteClaims)
+	nv.PastClaims = copyCSIVolumeClaims(v.PastClaims)
+	return nv
+}
+
+// copyCSIVolumeClaims is a helper to copy a map of CSIVolumeClaim
+func copyCSIVolumeClaims(claims map[string]*CSIVolumeClaim) map[string]*CSIVolumeClaim {
+	if claims == nil {
+		return nil
+	}
+	c := make(map[string]*CSIVolumeClaim, len(claims))
+	for id, claim := range claims {
+		c[id] = claim.Copy()
+	}
+	return c
+}
+
+// Sanitize returns a copy of the volume without its secrets
+func (v *CSIVolume) Sanitize() *CSIVolume {
+	if v == nil {
+		return nil
+	}
+	clean := v.Copy()
+	clean.Secrets = nil
+	return clean
+}
+
+// Canonicalize initializes the claim maps
+func (v *CSIVolume) Canonicalize() {
+	if v.ReadClaims == nil {
+		v.ReadClaims = make(map[string]*CSIVolumeClaim)
+	}
+	if v.WriteClaims == nil {
+		v.WriteClaims = make(map[string]*CSIVolumeClaim)
+	}
+	if v.PastClaims == nil {
+		v.PastClaims = make(map[string]*CSIVolumeClaim)
+	}
+}
+
+// Validate checks the fields set by operators registering the volume
+func (v *CSIVolume) Validate() error {
+	var mErr multierror.Error
+	if v.ID == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing volume ID"))
+	}
+	if v.Namespace == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing namespace"))
+	}
+	if v.PluginID == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing plugin ID"))
+	}
+	if !v.AccessMode.Valid() {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid access mode %q", v.AccessMode))
+	}
+	if !v.AttachmentMode.Valid() {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid attachment mode %q", v.AttachmentMode))
+	}
+	if v.CapacityMin < 0 || v.CapacityMax < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("capacity can not be negative"))
+	}
+	if v.CapacityMax != 0 && v.CapacityMin > v.CapacityMax {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("capacity_min is greater than capacity_max"))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// InUse returns whether any allocation holds or is releasing a claim on the
+// volume
+func (v *CSIVolume) InUse() bool {
+	return len(v.ReadClaims) != 0 || len(v.WriteClaims) != 0 || len(v.PastClaims) != 0
+}
+
+// Claim adds or releases the claim on the volume. Claims that would exceed
+// what the access mode of the volume allows are rejected.
+func (v *CSIVolume) Claim(claim *CSIVolumeClaim) error {
+	v.Canonicalize()
+
+	switch claim.Mode {
+	case CSIVolumeClaimRead, CSIVolumeClaimWrite:
+		return v.claim(claim)
+	case CSIVolumeClaimRelease:
+		return v.release(claim)
+	default:
+		return fmt.Errorf("unknown claim mode %d", claim.Mode)
+	}
+}
+
+func (v *CSIVolume) claim(claim *CSIVolumeClaim) error {
+	// Claims are only made once per allocation
+	if _, ok := v.ReadClaims[claim.AllocationID]; ok {
+		return nil
+	}
+	if _, ok := v.WriteClaims[claim.AllocationID]; ok {
+		return nil
+	}
+
+	if claim.Mode == CSIVolumeClaimWrite && v.AccessMode.readOnly() {
+		return fmt.Errorf("volume %q is read-only", v.ID)
+	}
+
+	if v.AccessMode.singleNode() {
+		for _, claims := range []map[string]*CSIVolumeClaim{v.ReadClaims, v.WriteClaims, v.PastClaims} {
+			for _, c := range claims {
+				if c.NodeID != claim.NodeID {
+					return fmt.Errorf("volume %q is claimed on node %q", v.ID, c.NodeID)
+				}
+			}
+		}
+	}
+
+	if claim.Mode == CSIVolumeClaimWrite {
+		single := v.AccessMode == CSIVolumeAccessModeSingleNodeWriter ||
+			v.AccessMode == CSIVolumeAccessModeMultiNodeSingleWriter
+		if single && len(v.WriteClaims) != 0 {
+			return fmt.Errorf("volume %q has reached its maximum number of writers", v.ID)
+		}
+	}
+
+	c := claim.Copy()
+	c.State = CSIVolumeClaimStateTaken
+	if c.Mode == CSIVolumeClaimWrite {
+		v.WriteClaims[c.AllocationID] = c
+	} else {
+		v.ReadClaims[c.AllocationID] = c
+	}
+	return nil
+}
+
+// release moves the claim to the past claims, recording the progress of the
+// release, and removes it once the volume is ready to be freed.
+func (v *CSIVolume) release(claim *CSIVolumeClaim) error {
+	existing, ok := v.ReadClaims[claim.AllocationID]
+	if !ok {
+		existing, ok = v.WriteClaims[claim.AllocationID]
+	}
+	if !ok {
+		existing, ok = v.PastClaims[claim.AllocationID]
+	}
+	if !ok {
+		return nil
+	}
+
+	delete(v.ReadClaims, claim.AllocationID)
+	delete(v.WriteClaims, claim.AllocationID)
+	if claim.State == CSIVolumeClaimStateReadyToFree {
+		delete(v.PastClaims, claim.AllocationID)
+		return nil
+	}
+
+	past := existing.Copy()
+	past.Mode = CSIVolumeClaimRelease
+	past.State = claim.State
+	v.PastClaims[claim.AllocationID] = past
+	return nil
+}
+
+// ClaimsOnNode returns the number of claims, other than the given
+// allocation's, that are held or being released on the node
+func (v *CSIVolume) ClaimsOnNode(nodeID, allocID string) int {
+	count := 0
+	for _, claims := range []map[string]*CSIVolumeClaim{v.ReadClaims, v.WriteClaims, v.PastClaims} {
+		for id, c := range claims {
+			if c.NodeID == nodeID && id != allocID {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// SetPluginHealth sets the health of the volume from its plugin
+func (v *CSIVolume) SetPluginHealth(plugin *CSIPlugin) {
+	if plugin == nil {
+		v.Schedulable = false
+		v.ControllersHealthy, v.ControllersExpected = 0, 0
+		v.NodesHealthy, v.NodesExpected = 0, 0
+		return
+	}
+
+	v.Provider = plugin.Provider
+	v.ProviderVersion = plugin.Version
+	v.ControllerRequired = plugin.ControllerRequired
+	v.ControllersHealthy = plugin.ControllersHealthy
+	v.ControllersExpected = len(plugin.Controllers)
+	v.NodesHealthy = plugin.NodesHealthy
+	v.NodesExpected = len(plugin.Nodes)
+	v.Schedulable = v.NodesHealthy > 0 && (!v.ControllerRequired || v.ControllersHealthy > 0)
+}
+
+// Stub returns a summary of the volume
+func (v *CSIVolume) Stub() *CSIVolumeListStub {
+	return &CSIVolumeListStub{
+		ID:                  v.ID,
+		Name:                v.Name,
+		Namespace:           v.Namespace,
+		ExternalID:          v.ExternalID,
+		PluginID:            v.PluginID,
+		Provider:            v.Provider,
+		AccessMode:          v.AccessMode,
+		AttachmentMode:      v.AttachmentMode,
+		CurrentReaders:      len(v.ReadClaims),
+		CurrentWriters:      len(v.WriteClaims),
+		Schedulable:         v.Schedulable,
+		ControllerRequired:  v.ControllerRequired,
+		ControllersHealthy:  v.ControllersHealthy,
+		ControllersExpected: v.ControllersExpected,
+		NodesHealthy:        v.NodesHealthy,
+		NodesExpected:       v.NodesExpected,
+		CreateIndex:         v.CreateIndex,
+		ModifyIndex:         v.ModifyIndex,
+	}
+}
+
+// CSIVolumeListStub is a summary of a CSI volume
+type CSIVolumeListStub struct {
+	ID                  string
+	Name                string
+	Namespace           string
+	ExternalID          string
+	PluginID            string
+	Provider            string
+	AccessMode          CSIVolumeAccessMode
+	AttachmentMode      CSIVolumeAttachmentMode
+	CurrentReaders      int
+	CurrentWriters      int
+	Schedulable         bool
+	ControllerRequired  bool
+	ControllersHealthy  int
+	ControllersExpected int
+	NodesHealthy        int
+	NodesExpected       int
+	CreateIndex         uint64
+	ModifyIndex         uint64
+}
+
+// CSIInfo is the state of a CSI plugin running on a node, as fingerprinted
+// by the client.
+type CSIInfo struct {
+	PluginID          string
+	Healthy           bool
+	HealthDescription string
+	UpdateTime        time.Time
+
+	Provider        string
+	ProviderVersion string
+
+	// RequiresControllerPlugin is set by node plugins whose volumes must be
+	// attached by a controller plugin before they can be mounted.
+	RequiresControllerPlugin bool
+
+	// ControllerInfo is set for controller plugins and NodeInfo for node
+	// plugins
+	ControllerInfo *CSIControllerInfo
+	NodeInfo       *CSINodeInfo
+}
+
+// Copy returns a deep copy of the plugin info
+func (c *CSIInfo) Copy() *CSIInfo {
+	if c == nil {
+		return nil
+	}
+	nc := new(CSIInfo)
+	*nc = *c
+	if c.ControllerInfo != nil {
+		ci := *c.ControllerInfo
+		nc.ControllerInfo = &ci
+	}
+	if c.NodeInfo != nil {
+		ni := *c.NodeInfo
+		nc.NodeInfo = &ni
+	}
+	return nc
+}
+
+// CSIControllerInfo is the capabilities of a controller plugin
+type CSIControllerInfo struct {
+	SupportsAttachDetach bool
+	SupportsCreateDelete bool
+}
+
+// CSINodeInfo is the identity of a node as reported by a node plugin
+type CSINodeInfo struct {
+	// ID is the ID of the node known to the storage provider
+	ID string
+
+	// MaxVolumes is the maximum number of volumes the node can mount or
+	// zero if unlimited
+	MaxVolumes int64
+}
+
+// copyNodeCSIInfo is a helper to copy a map of CSIInfo
+func copyNodeCSIInfo(plugins map[string]*CSIInfo) map[string]*CSIInfo {
+	l := len(plugins)
+	if l == 0 {
+		return nil
+	}
+
+	c := make(map[string]*CSIInfo, l)
+	for id, info := range plugins {
+		c[id] = info.Copy()
+	}
+	return c
+}
+
+// CSIPlugin aggregates the instances of a CSI plugin running on the nodes of
+// the cluster.
+type CSIPlugin struct {
+	ID                 string
+	Provider           string
+	Version            string
+	ControllerRequired bool
+
+	// Controllers and Nodes are the plugin instances by node ID
+	Controllers map[string]*CSIInfo
+	Nodes       map[string]*CSIInfo
+
+	ControllersHealthy int
+	NodesHealthy       int
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// NewCSIPlugin returns a plugin without any instances
+func NewCSIPlugin(id string, index uint64) *CSIPlugin {
+	return &CSIPlugin{
+		ID:          id,
+		Controllers: make(map[string]*CSIInfo),
+		Nodes:       make(map[string]*CSIInfo),
+		CreateIndex: index,
+		ModifyIndex: index,
+	}
+}
+
+// Copy returns a deep copy of the plugin
+func (p *CSIPlugin) Copy() *CSIPlugin {
+	if p == nil {
+		return nil
+	}
+	np := new(CSIPlugin)
+	*np = *p
+	np.Controllers = make(map[string]*CSIInfo, len(p.Controllers))
+	for id, info := range p.Controllers {
+		np.Controllers[id] = info.Copy()
+	}
+	np.Nodes = make(map[string]*CSIInfo, len(p.Nodes))
+	for id, info := range p.Nodes {
+		np.Nodes[id] = info.Copy()
+	}
+	return np
+}
+
+// AddController sets the controller plugin instance running on the node
+func (p *CSIPlugin) AddController(nodeID string, info *CSIInfo) {
+	p.Controllers[nodeID] = info
+	p.setProvider(info)
+	p.updateHealth()
+}
+
+// AddNode sets the node plugin instance running on the node
+func (p *CSIPlugin) AddNode(nodeID string, info *CSIInfo) {
+	p.Nodes[nodeID] = info
+	p.setProvider(info)
+	if info.RequiresControllerPlugin {
+		p.ControllerRequired = true
+	}
+	p.updateHealth()
+}
+
+// DeleteController removes the controller plugin instance of the node
+func (p *CSIPlugin) DeleteController(nodeID string) {
+	delete(p.Controllers, nodeID)
+	p.updateHealth()
+}
+
+// DeleteNode removes the node plugin instance of the node
+func (p *CSIPlugin) DeleteNode(nodeID string) {
+	delete(p.Nodes, nodeID)
+	p.updateHealth()
+}
+
+// IsEmpty returns whether no instances of the plugin remain
+func (p *CSIPlugin) IsEmpty() bool {
+	return len(p.Controllers) == 0 && len(p.Nodes) == 0
+}
+
+func (p *CSIPlugin) setProvider(info *CSIInfo) {
+	if info.Provider != "" {
+		p.Provider = info.Provider
+		p.Version = info.ProviderVersion
+	}
+}
+
+func (p *CSIPlugin) updateHealth() {
+	p.ControllersHealthy = 0
+	for _, info := range p.Controllers {
+		if info.Healthy {
+			p.ControllersHealthy++
+		}
+	}
+	p.NodesHealthy = 0
+	for _, info := range p.Nodes {
+		if info.Healthy {
+			p.NodesHealthy++
+		}
+	}
+}
+
+// HealthyControllers returns the IDs of the nodes running a healthy
+// controller plugin instance
+func (p *CSIPlugin) HealthyControllers() []string {
+	var nodes []string
+	for id, info := range p.Controllers {
+		if info.Healthy {
+			nodes = append(nodes, id)
+		}
+	}
+	return nodes
+}
+
+// Stub returns a summary of the plugin
+func (p *CSIPlugin) Stub() *CSIPluginListStub {
+	return &CSIPluginListStub{
+		ID:                  p.ID,
+		Provider:            p.Provider,
+		ControllerRequired:  p.ControllerRequired,
+		ControllersHealthy:  p.ControllersHealthy,
+		ControllersExpected: len(p.Controllers),
+		NodesHealthy:        p.NodesHealthy,
+		NodesExpected:       len(p.Nodes),
+		CreateIndex:         p.CreateIndex,
+		ModifyIndex:         p.ModifyIndex,
+	}
+}
+
+// CSIPluginListStub is a summary of a CSI plugin
+type CSIPluginListStub struct {
+	ID                  string
+	Provider            string
+	ControllerRequired  bool
+	ControllersHealthy  int
+	ControllersExpected int
+	NodesHealthy        int
+	NodesExpected       int
+	CreateIndex         uint64
+	ModifyIndex         uint64
+}
+
+// CSIVolumeRegisterRequest is used to register CSI volumes that already exist
+// in the storage provider.
+type CSIVolumeRegisterRequest struct {
+	Volumes []*CSIVolume
+	WriteRequest
+}
+
+// CSIVolumeDeregisterRequest is used to deregister CSI volumes. Volumes with
+// claims are only deregistered if Force is set.
+type CSIVolumeDeregisterRequest struct {
+	VolumeIDs []string
+	Force     bool
+	WriteRequest
+}
+
+// CSIVolumeCreateRequest is used to create volumes in the storage provider
+// and register them.
+type CSIVolumeCreateRequest struct {
+	Volumes []*CSIVolume
+	WriteRequest
+}
+
+// CSIVolumeCreateResponse returns the created volumes
+type CSIVolumeCreateResponse struct {
+	Volumes []*CSIVolume
+	WriteMeta
+}
+
+// CSIVolumeDeleteRequest is used to delete volumes from the storage provider
+// and deregister them.
+type CSIVolumeDeleteRequest struct {
+	VolumeIDs []string
+	WriteRequest
+}
+
+// CSIVolumeClaimRequest is used to claim a volume for an allocation. It is
+// also the raft request used to record claims and their release.
+type CSIVolumeClaimRequest struct {
+	VolumeID       string
+	AllocationID   string
+	NodeID         string
+	ExternalNodeID string
+	Claim          CSIVolumeClaimMode
+	State          CSIVolumeClaimState
+	WriteRequest
+}
+
+// ToClaim returns the claim described by the request
+func (r *CSIVolumeClaimRequest) ToClaim() *CSIVolumeClaim {
+	return &CSIVolumeClaim{
+		AllocationID:   r.AllocationID,
+		NodeID:         r.NodeID,
+		ExternalNodeID: r.ExternalNodeID,
+		Mode:           r.Claim,
+		State:          r.State,
+	}
+}
+
+// CSIVolumeClaimResponse returns the claimed volume and the publish context
+// returned by its controller plugin
+type CSIVolumeClaimResponse struct {
+	PublishContext map[string]string
+	Volume         *CSIVolume
+	QueryMeta
+}
+
+// CSIVolumeUnpublishRequest is used to release an allocation's claim on a
+// volume, detaching the volume from the node if it is no longer used there.
+type CSIVolumeUnpublishRequest struct {
+	VolumeID     string
+	AllocationID string
+	WriteRequest
+}
+
+// CSIVolumeListRequest is used to list the volumes in a namespace,
+// optionally filtered by plugin or claiming node.
+type CSIVolumeListRequest struct {
+	PluginID string
+	NodeID   string
+	QueryOptions
+}
+
+// CSIVolumeListResponse is used to return a list of volumes
+type CSIVolumeListResponse struct {
+	Volumes []*CSIVolumeListStub
+	QueryMeta
+}
+
+// CSIVolumeGetRequest is used to get a single volume
+type CSIVolumeGetRequest struct {
+	ID string
+	QueryOptions
+}
+
+// CSIVolumeGetResponse is used to return a single volume
+type CSIVolumeGetResponse struct {
+	Volume *CSIVolume
+	QueryMeta
+}
+
+// CSIPluginListRequest is used to list the CSI plugins
+type CSIPluginListRequest struct {
+	QueryOptions
+}
+
+// CSIPluginListResponse is used to return a list of plugins
+type CSIPluginListResponse struct {
+	Plugins []*CSIPluginListStub
+	QueryMeta
+}
+
+// CSIPluginGetRequest is used to get a single plugin
+type CSIPluginGetRequest struct {
+	ID string
+	QueryOptions
+}
+
+// CSIPluginGetResponse is used to return a single plugin
+type CSIPluginGetResponse struct {
+	Plugin *CSIPlugin
+	QueryMeta
+}
@@ -0,0 +1,149 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSIVolume_Validate(t *testing.T) {
+	require := require.New(t)
+
+	vol := &CSIVolume{
+		ID:             "data",
+		Namespace:      DefaultNamespace,
+		PluginID:       "ebs",
+		AccessMode:     CSIVolumeAccessModeSingleNodeWriter,
+		AttachmentMode: CSIVolumeAttachmentModeFilesystem,
+	}
+	require.NoError(vol.Validate())
+
+	vol.AccessMode = "foo"
+	vol.CapacityMin = 10
+	vol.CapacityMax = 5
+	err := vol.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "invalid access mode")
+	require.Contains(err.Error(), "capacity_min is greater than capacity_max")
+}
+
+func TestCSIVolume_Claim(t *testing.T) {
+	claim := func(alloc, node string, mode CSIVolumeClaimMode) *CSIVolumeClaim {
+		return &CSIVolumeClaim{AllocationID: alloc, NodeID: node, Mode: mode}
+	}
+
+	cases := []struct {
+		Name     string
+		Mode     CSIVolumeAccessMode
+		Existing []*CSIVolumeClaim
+		Claim    *CSIVolumeClaim
+		Err      string
+	}{
+		{
+			Name:  "read-only write",
+			Mode:  CSIVolumeAccessModeMultiNodeReader,
+			Claim: claim("a1", "n1", CSIVolumeClaimWrite),
+			Err:   "read-only",
+		},
+		{
+			Name:     "single node other node",
+			Mode:     CSIVolumeAccessModeSingleNodeReader,
+			Existing: []*CSIVolumeClaim{claim("a1", "n1", CSIVolumeClaimRead)},
+			Claim:    claim("a2", "n2", CSIVolumeClaimRead),
+			Err:      "claimed on node",
+		},
+		{
+			Name:     "single node same node",
+			Mode:     CSIVolumeAccessModeSingleNodeReader,
+			Existing: []*CSIVolumeClaim{claim("a1", "n1", CSIVolumeClaimRead)},
+			Claim:    claim("a2", "n1", CSIVolumeClaimRead),
+		},
+		{
+			Name:     "single writer",
+			Mode:     CSIVolumeAccessModeMultiNodeSingleWriter,
+			Existing: []*CSIVolumeClaim{claim("a1", "n1", CSIVolumeClaimWrite)},
+			Claim:    claim("a2", "n2", CSIVolumeClaimWrite),
+			Err:      "maximum number of writers",
+		},
+		{
+			Name:     "single writer readers",
+			Mode:     CSIVolumeAccessModeMultiNodeSingleWriter,
+			Existing: []*CSIVolumeClaim{claim("a1", "n1", CSIVolumeClaimWrite)},
+			Claim:    claim("a2", "n2", CSIVolumeClaimRead),
+		},
+		{
+			Name:     "multi writer",
+			Mode:     CSIVolumeAccessModeMultiNodeMultiWriter,
+			Existing: []*CSIVolumeClaim{claim("a1", "n1", CSIVolumeClaimWrite)},
+			Claim:    claim("a2", "n2", CSIVolumeClaimWrite),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			vol := &CSIVolume{ID: "data", AccessMode: c.Mode}
+			for _, e := range c.Existing {
+				require.NoError(t, vol.Claim(e))
+			}
+
+			err := vol.Claim(c.Claim)
+			if c.Err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.Err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCSIVolume_Claim_Release(t *testing.T) {
+	require := require.New(t)
+
+	vol := &CSIVolume{ID: "data", AccessMode: CSIVolumeAccessModeSingleNodeWriter}
+	require.NoError(vol.Claim(&CSIVolumeClaim{AllocationID: "a1", NodeID: "n1", Mode: CSIVolumeClaimWrite}))
+	require.Len(vol.WriteClaims, 1)
+
+	// Releasing moves the claim to the past claims until it is freed
+	release := &CSIVolumeClaim{AllocationID: "a1", Mode: CSIVolumeClaimRelease, State: CSIVolumeClaimStateNodeDetached}
+	require.NoError(vol.Claim(release))
+	require.Empty(vol.WriteClaims)
+	require.Len(vol.PastClaims, 1)
+	require.Equal(CSIVolumeClaimStateNodeDetached, vol.PastClaims["a1"].State)
+	require.Equal("n1", vol.PastClaims["a1"].NodeID)
+	require.True(vol.InUse())
+
+	// The node is still attached so other nodes can not claim it yet
+	require.Error(vol.Claim(&CSIVolumeClaim{AllocationID: "a2", NodeID: "n2", Mode: CSIVolumeClaimWrite}))
+
+	release.State = CSIVolumeClaimStateReadyToFree
+	require.NoError(vol.Claim(release))
+	require.False(vol.InUse())
+	require.NoError(vol.Claim(&CSIVolumeClaim{AllocationID: "a2", NodeID: "n2", Mode: CSIVolumeClaimWrite}))
+}
+
+func TestCSIPlugin_Health(t *testing.T) {
+	require := require.New(t)
+
+	plugin := NewCSIPlugin("ebs", 1)
+	plugin.AddController("n1", &CSIInfo{PluginID: "ebs", Healthy: true})
+	plugin.AddNode("n1", &CSIInfo{PluginID: "ebs", Healthy: true, RequiresControllerPlugin: true})
+	plugin.AddNode("n2", &CSIInfo{PluginID: "ebs", Healthy: false})
+	require.True(plugin.ControllerRequired)
+	require.Equal(1, plugin.ControllersHealthy)
+	require.Equal(1, plugin.NodesHealthy)
+	require.Equal([]string{"n1"}, plugin.HealthyControllers())
+
+	vol := &CSIVolume{PluginID: "ebs"}
+	vol.SetPluginHealth(plugin)
+	require.True(vol.Schedulable)
+	require.Equal(2, vol.NodesExpected)
+
+	plugin.DeleteController("n1")
+	vol.SetPluginHealth(plugin)
+	require.False(vol.Schedulable)
+
+	plugin.DeleteNode("n1")
+	plugin.DeleteNode("n2")
+	require.True(plugin.IsEmpty())
+}
@@ -133,6 +133,11 @@ func (j *Job) Diff(other *Job, contextual bool) (*JobDiff, error) {
 		diff.Objects = append(diff.Objects, cDiff)
 	}
 
+	// Multiregion diff
+	if mDiff := multiregionDiff(j.Multiregion, other.Multiregion, contextual); mDiff != nil {
+		diff.Objects = append(diff.Objects, mDiff)
+	}
+
 	// Check to see if there is a diff. We don't use reflect because we are
 	// filtering quite a few fields that will change on each diff.
 	if diff.Type == DiffTypeNone {
@@ -810,6 +815,92 @@ func parameterizedJobDiff(old, new *ParameterizedJobConfig, contextual bool) *Ob
 	return diff
 }
 
+// multiregionDiff returns the diff of two multiregion objects. If contextual
+// diff is enabled, all fields will be returned, even if no diff occurred.
+func multiregionDiff(old, new *Multiregion, contextual bool) *ObjectDiff {
+	diff := &ObjectDiff{Type: DiffTypeNone, Name: "Multiregion"}
+
+	if reflect.DeepEqual(old, new) {
+		return nil
+	} else if old == nil {
+		old = &Multiregion{}
+		diff.Type = DiffTypeAdded
+	} else if new == nil {
+		new = &Multiregion{}
+		diff.Type = DiffTypeDeleted
+	} else {
+		diff.Type = DiffTypeEdited
+	}
+
+	// Strategy diff
+	if sDiff := primitiveObjectDiff(old.Strategy, new.Strategy, nil, "Strategy", contextual); sDiff != nil {
+		diff.Objects = append(diff.Objects, sDiff)
+	}
+
+	// Region diffs, keyed by region name
+	oldMap := make(map[string]*MultiregionRegion, len(old.Regions))
+	newMap := make(map[string]*MultiregionRegion, len(new.Regions))
+	for _, r := range old.Regions {
+		oldMap[r.Name] = r
+	}
+	for _, r := range new.Regions {
+		newMap[r.Name] = r
+	}
+
+	var regionDiffs []*ObjectDiff
+	for name, oldRegion := range oldMap {
+		if rDiff := multiregionRegionDiff(oldRegion, newMap[name], contextual); rDiff != nil {
+			regionDiffs = append(regionDiffs, rDiff)
+		}
+	}
+	for name, newRegion := range newMap {
+		if _, ok := oldMap[name]; ok {
+			continue
+		}
+		if rDiff := multiregionRegionDiff(nil, newRegion, contextual); rDiff != nil {
+			regionDiffs = append(regionDiffs, rDiff)
+		}
+	}
+	sort.Sort(ObjectDiffs(regionDiffs))
+	diff.Objects = append(diff.Objects, regionDiffs...)
+
+	return diff
+}
+
+// multiregionRegionDiff returns the diff of two multiregion regions. If
+// contextual diff is enabled, all fields will be returned, even if no diff
+// occurred.
+func multiregionRegionDiff(old, new *MultiregionRegion, contextual bool) *ObjectDiff {
+	diff := &ObjectDiff{Type: DiffTypeNone, Name: "Region"}
+	var oldPrimitiveFlat, newPrimitiveFlat map[string]string
+
+	if reflect.DeepEqual(old, new) {
+		return nil
+	} else if old == nil {
+		old = &MultiregionRegion{}
+		diff.Type = DiffTypeAdded
+		newPrimitiveFlat = flatmap.Flatten(new, nil, true)
+	} else if new == nil {
+		new = &MultiregionRegion{}
+		diff.Type = DiffTypeDeleted
+		oldPrimitiveFlat = flatmap.Flatten(old, nil, true)
+	} else {
+		diff.Type = DiffTypeEdited
+		oldPrimitiveFlat = flatmap.Flatten(old, nil, true)
+		newPrimitiveFlat = flatmap.Flatten(new, nil, true)
+	}
+
+	// Diff the primitive fields.
+	diff.Fields = fieldDiffs(oldPrimitiveFlat, newPrimitiveFlat, contextual)
+
+	// Datacenters diff
+	if dcDiff := stringSetDiff(old.Datacenters, new.Datacenters, "Datacenters", contextual); dcDiff != nil {
+		diff.Objects = append(diff.Objects, dcDiff)
+	}
+
+	return diff
+}
+
 // Diff returns a diff of two resource objects. If contextual diff is enabled,
 // non-changed fields will still be returned.
 func (r *Resources) Diff(other *Resources, contextual bool) *ObjectDiff {
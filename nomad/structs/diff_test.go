@@ -636,6 +636,12 @@ func TestJobDiff(t *testing.T) {
 								Old:  "false",
 								New:  "true",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "OverlapPolicy",
+								Old:  "",
+								New:  "",
+							},
 							{
 								Type: DiffTypeNone,
 								Name: "ProhibitOverlap",
@@ -1045,6 +1051,106 @@ func TestJobDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			// Multiregion edited
+			Old: &Job{
+				Multiregion: &Multiregion{
+					Strategy: &MultiregionStrategy{
+						MaxParallel: 1,
+					},
+					Regions: []*MultiregionRegion{
+						{
+							Name:  "east",
+							Count: 1,
+						},
+						{
+							Name:        "west",
+							Datacenters: []string{"west-1"},
+						},
+					},
+				},
+			},
+			New: &Job{
+				Multiregion: &Multiregion{
+					Strategy: &MultiregionStrategy{
+						MaxParallel: 2,
+					},
+					Regions: []*MultiregionRegion{
+						{
+							Name:  "east",
+							Count: 2,
+						},
+					},
+				},
+			},
+			Expected: &JobDiff{
+				Type: DiffTypeEdited,
+				Objects: []*ObjectDiff{
+					{
+						Type: DiffTypeEdited,
+						Name: "Multiregion",
+						Objects: []*ObjectDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "Strategy",
+								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeEdited,
+										Name: "MaxParallel",
+										Old:  "1",
+										New:  "2",
+									},
+								},
+							},
+							{
+								Type: DiffTypeEdited,
+								Name: "Region",
+								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeEdited,
+										Name: "Count",
+										Old:  "1",
+										New:  "2",
+									},
+								},
+							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "Region",
+								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeDeleted,
+										Name: "Count",
+										Old:  "0",
+										New:  "",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "Name",
+										Old:  "west",
+										New:  "",
+									},
+								},
+								Objects: []*ObjectDiff{
+									{
+										Type: DiffTypeDeleted,
+										Name: "Datacenters",
+										Fields: []*FieldDiff{
+											{
+												Type: DiffTypeDeleted,
+												Name: "Datacenters",
+												Old:  "west-1",
+												New:  "",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			// Parameterized Job edited
 			Old: &Job{
@@ -1147,6 +1253,12 @@ func TestJobDiff(t *testing.T) {
 								Old:  DispatchPayloadRequired,
 								New:  DispatchPayloadOptional,
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "PayloadSchema",
+								Old:  "",
+								New:  "",
+							},
 						},
 						Objects: []*ObjectDiff{
 							{
@@ -1977,6 +2089,12 @@ func TestTaskGroupDiff(t *testing.T) {
 						Type: DiffTypeDeleted,
 						Name: "Update",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeDeleted,
+								Name: "AutoPromote",
+								Old:  "false",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "AutoRevert",
@@ -1995,6 +2113,12 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "0",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "ManualPromotionPerGroup",
+								Old:  "false",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "MaxParallel",
@@ -2033,6 +2157,12 @@ func TestTaskGroupDiff(t *testing.T) {
 						Type: DiffTypeAdded,
 						Name: "Update",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "AutoPromote",
+								Old:  "",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "AutoRevert",
@@ -2051,6 +2181,12 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "",
 								New:  "0",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "ManualPromotionPerGroup",
+								Old:  "",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "MaxParallel",
@@ -2084,18 +2220,21 @@ func TestTaskGroupDiff(t *testing.T) {
 					HealthyDeadline:  30 * time.Second,
 					ProgressDeadline: 29 * time.Second,
 					AutoRevert:       true,
+					AutoPromote:      true,
 					Canary:           2,
 				},
 			},
 			New: &TaskGroup{
 				Update: &UpdateStrategy{
-					MaxParallel:      7,
-					HealthCheck:      "bar",
-					MinHealthyTime:   2 * time.Second,
-					HealthyDeadline:  31 * time.Second,
-					ProgressDeadline: 32 * time.Second,
-					AutoRevert:       false,
-					Canary:           1,
+					MaxParallel:             7,
+					HealthCheck:             "bar",
+					MinHealthyTime:          2 * time.Second,
+					HealthyDeadline:         31 * time.Second,
+					ProgressDeadline:        32 * time.Second,
+					AutoRevert:              false,
+					AutoPromote:             false,
+					ManualPromotionPerGroup: true,
+					Canary:                  1,
 				},
 			},
 			Expected: &TaskGroupDiff{
@@ -2105,6 +2244,12 @@ func TestTaskGroupDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "Update",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "AutoPromote",
+								Old:  "true",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "AutoRevert",
@@ -2129,6 +2274,12 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "30000000000",
 								New:  "31000000000",
 							},
+							{
+								Type: DiffTypeEdited,
+								Name: "ManualPromotionPerGroup",
+								Old:  "false",
+								New:  "true",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "MaxParallel",
@@ -2184,6 +2335,12 @@ func TestTaskGroupDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "Update",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeNone,
+								Name: "AutoPromote",
+								Old:  "false",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeNone,
 								Name: "AutoRevert",
@@ -2208,6 +2365,12 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "30000000000",
 								New:  "30000000000",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "ManualPromotionPerGroup",
+								Old:  "false",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "MaxParallel",
@@ -2938,6 +3101,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeAdded,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "DisableFiles",
+								Old:  "",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "MaxFileSizeMB",
@@ -2971,6 +3140,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeDeleted,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeDeleted,
+								Name: "DisableFiles",
+								Old:  "false",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "MaxFileSizeMB",
@@ -3048,6 +3223,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeNone,
+								Name: "DisableFiles",
+								Old:  "false",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "MaxFileSizeMB",
@@ -3060,6 +3241,67 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "1",
 								New:  "1",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Sink",
+								Old:  "",
+								New:  "",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "LogConfig sink edited",
+			Old: &Task{
+				LogConfig: &LogConfig{
+					MaxFiles:      1,
+					MaxFileSizeMB: 10,
+					Sink:          "syslog",
+					SinkOptions: map[string]string{
+						"tag":      "web",
+						"facility": "local0",
+					},
+				},
+			},
+			New: &Task{
+				LogConfig: &LogConfig{
+					MaxFiles:      1,
+					MaxFileSizeMB: 10,
+					Sink:          "syslog",
+					SinkOptions: map[string]string{
+						"tag":     "web",
+						"address": "udp://127.0.0.1:514",
+					},
+					DisableFiles: true,
+				},
+			},
+			Expected: &TaskDiff{
+				Type: DiffTypeEdited,
+				Objects: []*ObjectDiff{
+					{
+						Type: DiffTypeEdited,
+						Name: "LogConfig",
+						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "DisableFiles",
+								Old:  "false",
+								New:  "true",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "SinkOptions[address]",
+								Old:  "",
+								New:  "udp://127.0.0.1:514",
+							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "SinkOptions[facility]",
+								Old:  "local0",
+								New:  "",
+							},
 						},
 					},
 				},
@@ -4000,6 +4242,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "foo",
 								New:  "bar",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Provider",
+								Old:  "",
+								New:  "",
+							},
 						},
 					},
 				},
@@ -4093,6 +4341,10 @@ func TestTaskDiff(t *testing.T) {
 								Type: DiffTypeNone,
 								Name: "PortLabel",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Provider",
+							},
 						},
 					},
 				},
@@ -4431,6 +4683,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Provider",
+								Old:  "",
+								New:  "",
+							},
 						},
 						Objects: []*ObjectDiff{
 							{
@@ -4968,6 +5226,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "SIGUSR1",
 								New:  "SIGUSR1",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Cluster",
+								Old:  "",
+								New:  "",
+							},
 							{
 								Type: DiffTypeNone,
 								Name: "Env",
@@ -5099,6 +5363,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "0776",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Sandbox",
+								Old:  "",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "SourcePath",
@@ -5159,6 +5429,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "0666",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "Sandbox",
+								Old:  "false",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "SourcePath",
@@ -10,11 +10,13 @@ const (
 	errNoLeader            = "No cluster leader"
 	errNoRegionPath        = "No path to region"
 	errTokenNotFound       = "ACL token not found"
+	errTokenExpired        = "ACL token expired"
 	errPermissionDenied    = "Permission denied"
 	errNoNodeConn          = "No path to node"
 	errUnknownMethod       = "Unknown rpc method"
 	errUnknownNomadVersion = "Unable to determine Nomad version"
 	errNodeLacksRpc        = "Node does not support RPC; requires 0.8 or later"
+	errJobRateLimited      = "Job submission rate limit exceeded"
 
 	// Prefix based errors that are used to check if the error is of a given
 	// type. These errors should be created with the associated constructor.
@@ -29,11 +31,13 @@ var (
 	ErrNoLeader            = errors.New(errNoLeader)
 	ErrNoRegionPath        = errors.New(errNoRegionPath)
 	ErrTokenNotFound       = errors.New(errTokenNotFound)
+	ErrTokenExpired        = errors.New(errTokenExpired)
 	ErrPermissionDenied    = errors.New(errPermissionDenied)
 	ErrNoNodeConn          = errors.New(errNoNodeConn)
 	ErrUnknownMethod       = errors.New(errUnknownMethod)
 	ErrUnknownNomadVersion = errors.New(errUnknownNomadVersion)
 	ErrNodeLacksRpc        = errors.New(errNodeLacksRpc)
+	ErrJobRateLimited      = errors.New(errJobRateLimited)
 )
 
 // IsErrNoLeader returns whether the error is due to there being no leader.
@@ -53,6 +57,12 @@ func IsErrTokenNotFound(err error) bool {
 	return err != nil && strings.Contains(err.Error(), errTokenNotFound)
 }
 
+// IsErrTokenExpired returns whether the error is due to the passed token
+// having expired.
+func IsErrTokenExpired(err error) bool {
+	return err != nil && strings.Contains(err.Error(), errTokenExpired)
+}
+
 // IsErrPermissionDenied returns whether the error is due to the operation not
 // being allowed due to lack of permissions.
 func IsErrPermissionDenied(err error) bool {
@@ -140,3 +150,9 @@ func IsErrUnknownNomadVersion(err error) bool {
 func IsErrNodeLacksRpc(err error) bool {
 	return err != nil && strings.Contains(err.Error(), errNodeLacksRpc)
 }
+
+// IsErrJobRateLimited returns whether the error is due to the job submission
+// rate limits being exceeded.
+func IsErrJobRateLimited(err error) bool {
+	return err != nil && strings.Contains(err.Error(), errJobRateLimited)
+}
@@ -0,0 +1,97 @@
+package structs
+
+// Topic is the category of the objects an event is about.
+type Topic string
+
+const (
+	// TopicAll subscribes to the events of every topic.
+	TopicAll Topic = "*"
+
+	TopicJob        Topic = "Job"
+	TopicAllocation Topic = "Allocation"
+	TopicNode       Topic = "Node"
+	TopicDeployment Topic = "Deployment"
+)
+
+const (
+	TypeJobRegistered           = "JobRegistered"
+	TypeJobDeregistered         = "JobDeregistered"
+	TypeAllocationUpdated       = "AllocationUpdated"
+	TypeAllocationUpdateDesired = "AllocationUpdateDesiredStatus"
+	TypeNodeRegistration        = "NodeRegistration"
+	TypeNodeDeregistration      = "NodeDeregistration"
+	TypeNodeStatusUpdate        = "NodeStatusUpdate"
+	TypeNodeDrain               = "NodeDrain"
+	TypeNodeEligibility         = "NodeEligibility"
+	TypeDeploymentUpdate        = "DeploymentStatusUpdate"
+	TypeDeploymentPromotion     = "DeploymentPromotion"
+	TypeDeploymentAllocHealth   = "DeploymentAllocHealth"
+	TypePlanResult              = "PlanResult"
+)
+
+// Event is a change to an object in the state store.
+type Event struct {
+	// Topic is the category of the object that changed.
+	Topic Topic
+
+	// Type describes the change.
+	Type string
+
+	// Key is the ID of the object that changed, and FilterKeys are other
+	// keys the event can be selected with, such as the job ID of an
+	// allocation.
+	Key        string
+	FilterKeys []string
+
+	// Namespace is the namespace of the object, if it is namespaced.
+	Namespace string
+
+	// Index is the raft index the change was applied at.
+	Index uint64
+
+	// Payload holds the changed object, which is one of JobEvent,
+	// AllocationEvent, NodeStreamEvent or DeploymentEvent.
+	Payload interface{}
+}
+
+// Events is the set of events created by a single raft log.
+type Events struct {
+	Index  uint64
+	Events []Event
+}
+
+// JobEvent is the payload of Job events.
+type JobEvent struct {
+	Job *Job
+}
+
+// AllocationEvent is the payload of Allocation events. The job of the
+// allocation is not included.
+type AllocationEvent struct {
+	Allocation *Allocation
+}
+
+// NodeStreamEvent is the payload of Node events. The node is sanitized so
+// that its secret is not included.
+type NodeStreamEvent struct {
+	Node *Node
+}
+
+// DeploymentEvent is the payload of Deployment events.
+type DeploymentEvent struct {
+	Deployment *Deployment
+}
+
+// EventStreamRequest is used to stream the events of the cluster.
+type EventStreamRequest struct {
+	// Topics maps the topics to subscribe to to the keys of the events to
+	// receive. The key "*" matches every event of the topic.
+	Topics map[Topic][]string
+
+	// Index is the raft index to resume streaming after. Buffered events
+	// with a higher index are replayed before new events are streamed. When
+	// zero, only new events are streamed.
+	Index uint64
+
+	QueryOptions
+}
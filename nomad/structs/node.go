@@ -1,8 +1,11 @@
 package structs
 
 import (
+	"fmt"
+	"path/filepath"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/helper"
 )
 
@@ -60,3 +63,87 @@ func (di *DriverInfo) HealthCheckEquals(other *DriverInfo) bool {
 
 	return true
 }
+
+const (
+	// HostVolumeAccessModeSingleWriter allows a single allocation to mount
+	// the volume for writing while others may mount it read-only.
+	HostVolumeAccessModeSingleWriter = "single-writer"
+
+	// HostVolumeAccessModeMultiWriter allows any number of allocations to
+	// mount the volume for writing.
+	HostVolumeAccessModeMultiWriter = "multi-writer"
+
+	// HostVolumeAccessModeReadOnly only allows the volume to be mounted
+	// read-only.
+	HostVolumeAccessModeReadOnly = "read-only"
+)
+
+// HostVolume is a directory on a node, registered by an operator, that can be
+// made available to tasks running on the node.
+type HostVolume struct {
+	// Name is the unique name of the volume on the node
+	Name string
+
+	// Path is the path of the volume on the node
+	Path string
+
+	// CapacityMB is the capacity of the volume in megabytes or zero if
+	// unknown
+	CapacityMB int64
+
+	// AccessMode is how allocations may access the volume
+	AccessMode string
+
+	// Meta is operator supplied metadata about the volume
+	Meta map[string]string
+
+	// Raft Indexes of when the volume was attached to the node and last
+	// updated
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Copy returns a deep copy of the host volume
+func (v *HostVolume) Copy() *HostVolume {
+	if v == nil {
+		return nil
+	}
+
+	nv := new(HostVolume)
+	*nv = *v
+	nv.Meta = helper.CopyMapStringString(v.Meta)
+	return nv
+}
+
+// Canonicalize sets the default access mode of the volume
+func (v *HostVolume) Canonicalize() {
+	if v.AccessMode == "" {
+		v.AccessMode = HostVolumeAccessModeSingleWriter
+	}
+}
+
+// Validate returns an error if the host volume is invalid
+func (v *HostVolume) Validate() error {
+	var mErr multierror.Error
+	if v.Name == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing volume name"))
+	} else if !validHostVolumeName.MatchString(v.Name) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("volume name %q must match regex %s", v.Name, validHostVolumeName))
+	}
+	if v.Path == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing volume path"))
+	} else if !filepath.IsAbs(v.Path) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("volume path %q must be absolute", v.Path))
+	}
+	if v.CapacityMB < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("volume capacity must not be negative"))
+	}
+
+	switch v.AccessMode {
+	case HostVolumeAccessModeSingleWriter, HostVolumeAccessModeMultiWriter, HostVolumeAccessModeReadOnly:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid volume access mode %q", v.AccessMode))
+	}
+
+	return mErr.ErrorOrNil()
+}
@@ -3,6 +3,7 @@ package structs
 import (
 	"testing"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/stretchr/testify/require"
 )
 
@@ -60,3 +61,32 @@ func TestDriverInfoEquals(t *testing.T) {
 		require.Equal(testCase.expected, first.HealthCheckEquals(second), testCase.errorMsg)
 	}
 }
+
+func TestHostVolume_Validate(t *testing.T) {
+	require := require.New(t)
+
+	volume := &HostVolume{
+		Name: "data",
+		Path: "/srv/data",
+	}
+	volume.Canonicalize()
+	require.Equal(HostVolumeAccessModeSingleWriter, volume.AccessMode)
+	require.NoError(volume.Validate())
+
+	volume = &HostVolume{
+		Name:       "data volume",
+		Path:       "srv/data",
+		CapacityMB: -1,
+		AccessMode: "foo",
+	}
+	err := volume.Validate()
+	require.Error(err)
+	mErr := err.(*multierror.Error)
+	require.Len(mErr.Errors, 4)
+	require.Contains(mErr.Errors[0].Error(), "volume name")
+	require.Contains(mErr.Errors[1].Error(), "must be absolute")
+	require.Contains(mErr.Errors[2].Error(), "capacity")
+	require.Contains(mErr.Errors[3].Error(), "access mode")
+
+	require.Error((&HostVolume{}).Validate())
+}
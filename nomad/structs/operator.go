@@ -1,8 +1,10 @@
 package structs
 
 import (
+	"fmt"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/raft"
 )
 
@@ -167,3 +169,184 @@ type SchedulerSetConfigRequest struct {
 	// WriteRequest holds the ACL token to go along with this request.
 	WriteRequest
 }
+
+// GCConfiguration is the config for tuning garbage collection at runtime. A
+// zero threshold falls back to the value set in the server's agent config.
+type GCConfiguration struct {
+	// EvalGCThreshold is how old an evaluation and its allocations must be
+	// to be eligible for GC.
+	EvalGCThreshold time.Duration
+
+	// JobGCThreshold is how old a terminal job must be to be eligible for GC.
+	JobGCThreshold time.Duration
+
+	// DeploymentGCThreshold is how old a terminal deployment must be to be
+	// eligible for GC.
+	DeploymentGCThreshold time.Duration
+
+	// CreateIndex/ModifyIndex store the create/modify indexes of this configuration.
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Validate returns an error if any of the thresholds is negative.
+func (c *GCConfiguration) Validate() error {
+	var mErr multierror.Error
+	if c.EvalGCThreshold < 0 {
+		multierror.Append(&mErr, fmt.Errorf("eval GC threshold must not be negative"))
+	}
+	if c.JobGCThreshold < 0 {
+		multierror.Append(&mErr, fmt.Errorf("job GC threshold must not be negative"))
+	}
+	if c.DeploymentGCThreshold < 0 {
+		multierror.Append(&mErr, fmt.Errorf("deployment GC threshold must not be negative"))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// GCConfigurationResponse is the response object that wraps GCConfiguration
+type GCConfigurationResponse struct {
+	// GCConfig contains the GC config options
+	GCConfig *GCConfiguration
+
+	QueryMeta
+}
+
+// GCSetConfigurationResponse is the response object used when updating the
+// GC configuration
+type GCSetConfigurationResponse struct {
+	// Updated returns whether the config was actually updated
+	// Only set when the request uses CAS
+	Updated bool
+
+	WriteMeta
+}
+
+// GCSetConfigRequest is used by the Operator endpoint to update the current
+// GC configuration of the cluster.
+type GCSetConfigRequest struct {
+	// Config is the new GC configuration to use.
+	Config GCConfiguration
+
+	// CAS controls whether to use check-and-set semantics for this request.
+	CAS bool
+
+	// WriteRequest holds the ACL token to go along with this request.
+	WriteRequest
+}
+
+// SnapshotSaveRequest is used by the Operator endpoint to take a snapshot of
+// the state of the servers.
+type SnapshotSaveRequest struct {
+	QueryOptions
+}
+
+// SnapshotSaveResponse is sent by the Operator endpoint before the snapshot
+// archive. The archive is only sent if the error code is zero.
+type SnapshotSaveResponse struct {
+	// SnapshotChecksum is the checksum of the archive in the format of the
+	// HTTP Digest header.
+	SnapshotChecksum string
+
+	// ErrorCode and ErrorMsg describe the error taking the snapshot, using
+	// HTTP status codes.
+	ErrorCode int    `codec:",omitempty"`
+	ErrorMsg  string `codec:",omitempty"`
+
+	QueryMeta
+}
+
+// SnapshotRestoreRequest is used by the Operator endpoint to restore the
+// state of the servers from a snapshot. The archive is sent after the request
+// as the payloads of StreamErrWrapper messages, ending with a message without
+// a payload.
+type SnapshotRestoreRequest struct {
+	WriteRequest
+}
+
+// SnapshotRestoreResponse is sent by the Operator endpoint once the snapshot
+// has been restored.
+type SnapshotRestoreResponse struct {
+	// ErrorCode and ErrorMsg describe the error restoring the snapshot,
+	// using HTTP status codes.
+	ErrorCode int    `codec:",omitempty"`
+	ErrorMsg  string `codec:",omitempty"`
+
+	QueryMeta
+}
+
+// ClusterServerHealth is the health of a server in the Raft configuration,
+// combining its Raft, Serf and Autopilot state.
+type ClusterServerHealth struct {
+	// ID is the Raft ID of the server.
+	ID string
+
+	// Name is the node name of the server, or "(unknown)" if the server is
+	// not known to Serf.
+	Name string
+
+	// Address is the IP:port of the server, used for Raft communications.
+	Address string
+
+	// Version is the Nomad version of the server.
+	Version string
+
+	// RaftProtocol is the version of the Raft protocol spoken by this server.
+	RaftProtocol string
+
+	// SerfStatus is the status of the server in the gossip pool.
+	SerfStatus string
+
+	// Leader is true if this server is the current cluster leader.
+	Leader bool
+
+	// Voter is true if this server has a vote in the cluster.
+	Voter bool
+
+	// Healthy is whether the server is healthy. It is the Autopilot verdict
+	// if Autopilot is available and whether the server is alive otherwise.
+	Healthy bool
+
+	// LastContact, LastTerm, LastIndex and StableSince are reported by
+	// Autopilot and are only set if it is available.
+	LastContact time.Duration
+	LastTerm    uint64
+	LastIndex   uint64
+	StableSince time.Time
+}
+
+// ClusterHealthResponse is the aggregated health of the servers of a region
+// and of the heartbeats of its nodes.
+type ClusterHealthResponse struct {
+	// Healthy is true if all the servers are healthy.
+	Healthy bool
+
+	// FailureTolerance is the number of healthy servers that could be lost
+	// without an outage occurring.
+	FailureTolerance int
+
+	// Autopilot is true if the server health was determined by Autopilot,
+	// which requires all servers to use Raft protocol 3 or higher.
+	Autopilot bool
+
+	// Servers holds the health of each server in the Raft configuration.
+	Servers []*ClusterServerHealth
+
+	// Versions is the sorted set of Nomad versions run by the servers.
+	// VersionSkew is true if there is more than one.
+	Versions    []string
+	VersionSkew bool
+
+	// HeartbeatsTracked is the number of nodes whose heartbeats are tracked
+	// by the leader, HeartbeatsOverdue the number of those whose heartbeat
+	// is late and HeartbeatsDeferred the number of those that would have
+	// been marked down if the missed heartbeat grace hadn't deferred it.
+	HeartbeatsTracked  int
+	HeartbeatsOverdue  int
+	HeartbeatsDeferred int
+
+	// DownNodes is the number of nodes marked down.
+	DownNodes int
+
+	QueryMeta
+}
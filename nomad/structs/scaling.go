@@ -0,0 +1,332 @@
+package structs
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+const (
+	// JobTrackedScalingEvents is the number of scaling events kept for each
+	// task group of a job. Older events are dropped.
+	JobTrackedScalingEvents = 20
+)
+
+// ScalingEvent records a change of the count of a task group, or an event
+// about scaling it, such as an external autoscaler failing to scale it.
+type ScalingEvent struct {
+	// Time is the time of the event, in Unix nanoseconds.
+	Time int64
+
+	// Count is the new count of the task group. It is nil for events that
+	// didn't change the count.
+	Count *int64
+
+	// PreviousCount is the count of the task group before the event.
+	PreviousCount int64
+
+	// Message is the reason for the event.
+	Message string
+
+	// Error is set for events of failures to scale the task group.
+	Error bool
+
+	// Meta is arbitrary metadata about the event, such as the source of the
+	// decision to scale.
+	Meta map[string]string
+
+	// EvalID is the ID of the evaluation created by the event, if any.
+	EvalID string
+
+	// AccessorID is the accessor ID of the ACL token used to scale the task
+	// group, if ACLs are enabled.
+	AccessorID string
+
+	CreateIndex uint64
+}
+
+// Copy returns a copy of the scaling event.
+func (e *ScalingEvent) Copy() *ScalingEvent {
+	if e == nil {
+		return nil
+	}
+	c := new(ScalingEvent)
+	*c = *e
+	if e.Count != nil {
+		c.Count = helper.Int64ToPtr(*e.Count)
+	}
+	c.Meta = helper.CopyMapStringString(e.Meta)
+	return c
+}
+
+// JobScalingEvents is the scaling events of the task groups of a job, from
+// newest to oldest.
+type JobScalingEvents struct {
+	Namespace string
+	JobID     string
+
+	// ScalingEvents is the events of each task group, keyed by name.
+	ScalingEvents map[string][]*ScalingEvent
+
+	ModifyIndex uint64
+}
+
+// Copy returns a copy of the job scaling events.
+func (j *JobScalingEvents) Copy() *JobScalingEvents {
+	if j == nil {
+		return nil
+	}
+	c := new(JobScalingEvents)
+	*c = *j
+	c.ScalingEvents = make(map[string][]*ScalingEvent, len(j.ScalingEvents))
+	for group, events := range j.ScalingEvents {
+		copied := make([]*ScalingEvent, len(events))
+		for i, e := range events {
+			copied[i] = e.Copy()
+		}
+		c.ScalingEvents[group] = copied
+	}
+	return c
+}
+
+// JobScaleRequest is used to scale a task group of a job, or to record a
+// scaling event for it.
+type JobScaleRequest struct {
+	JobID string
+
+	// Group is the name of the task group to scale.
+	Group string
+
+	// Count is the new count of the task group. If nil, only the event is
+	// recorded.
+	Count *int64
+
+	// Message is the reason for scaling the task group.
+	Message string
+
+	// Error marks the event as a failure to scale the task group. Error
+	// events can't change the count.
+	Error bool
+
+	// Meta is arbitrary metadata recorded with the event.
+	Meta map[string]string
+
+	// PolicyOverride is set when the user is attempting to override any
+	// policies.
+	PolicyOverride bool
+
+	WriteRequest
+}
+
+// Validate returns an error if the scale request is invalid.
+func (r *JobScaleRequest) Validate() error {
+	var mErr multierror.Error
+	if r.JobID == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing job ID"))
+	}
+	if r.Group == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing task group name"))
+	}
+	if r.Count != nil {
+		if *r.Count < 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("scaling count must be non-negative; got %d", *r.Count))
+		}
+		if r.Error {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("error events can't change the count"))
+		}
+	} else if r.Message == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("events that don't change the count require a message"))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ScalingEventRequest is used to record a scaling event for a task group.
+type ScalingEventRequest struct {
+	JobID        string
+	TaskGroup    string
+	ScalingEvent *ScalingEvent
+
+	WriteRequest
+}
+
+// JobScaleStatusRequest is used to get the scale status of a job.
+type JobScaleStatusRequest struct {
+	JobID string
+	QueryOptions
+}
+
+// JobScaleStatusResponse is the response to a scale status request.
+type JobScaleStatusResponse struct {
+	// JobScaleStatus is nil if the job doesn't exist.
+	JobScaleStatus *JobScaleStatus
+	QueryMeta
+}
+
+// JobScaleStatus is the counts and scaling events of the task groups of a
+// job.
+type JobScaleStatus struct {
+	JobID          string
+	Namespace      string
+	JobCreateIndex uint64
+	JobModifyIndex uint64
+	JobStopped     bool
+
+	// TaskGroups is the status of each task group, keyed by name.
+	TaskGroups map[string]*TaskGroupScaleStatus
+}
+
+// TaskGroupScaleStatus is the counts and scaling events of a task group.
+type TaskGroupScaleStatus struct {
+	// Desired is the count of the task group.
+	Desired int
+
+	// Placed is the number of allocations that are placed and not
+	// terminal.
+	Placed int
+
+	// Running is the number of allocations that are running.
+	Running int
+
+	// Healthy and Unhealthy are the number of placed allocations whose
+	// deployment health is known.
+	Healthy   int
+	Unhealthy int
+
+	// Events is the scaling events of the task group, from newest to oldest.
+	Events []*ScalingEvent
+}
+
+const (
+	// NodeScaleInStatusDraining is the scale-in status of nodes whose
+	// allocations are still being drained.
+	NodeScaleInStatusDraining = "draining"
+
+	// NodeScaleInStatusReady is the scale-in status of nodes that have been
+	// drained and can be terminated and purged.
+	NodeScaleInStatusReady = "ready"
+)
+
+// NodeScaleIn marks a node that is being removed from the cluster by an
+// autoscaler. Nodes being scaled in are drained and kept ineligible for
+// scheduling until the scale-in is cancelled or the node is purged.
+type NodeScaleIn struct {
+	// RequestedAt is the time scale-in started, in Unix nanoseconds.
+	RequestedAt int64
+
+	// Message is the reason for scaling in the node.
+	Message string
+
+	// AccessorID is the accessor ID of the ACL token used to start the
+	// scale-in, if ACLs are enabled.
+	AccessorID string
+}
+
+// Copy returns a copy of the node scale-in.
+func (s *NodeScaleIn) Copy() *NodeScaleIn {
+	if s == nil {
+		return nil
+	}
+	c := new(NodeScaleIn)
+	*c = *s
+	return c
+}
+
+// NodeScaleInRequest is used to start or cancel the scale-in of a node.
+type NodeScaleInRequest struct {
+	NodeID string
+
+	// DrainSpec is the drain used to stop the allocations of the node. It
+	// defaults to a drain without deadline that ignores system jobs.
+	DrainSpec *DrainSpec
+
+	// Message is the reason for scaling in the node.
+	Message string
+
+	// Cancel stops the scale-in and the drain of the node and marks it
+	// eligible for scheduling again.
+	Cancel bool
+
+	// ScaleIn, DrainStrategy and NodeEvent are set by the server.
+	ScaleIn       *NodeScaleIn
+	DrainStrategy *DrainStrategy
+	NodeEvent     *NodeEvent
+
+	WriteRequest
+}
+
+// NodeScaleInCompleteRequest is used to purge a node that has been scaled
+// in, once its instance has been terminated.
+type NodeScaleInCompleteRequest struct {
+	NodeID string
+
+	// Force purges the node even if it still has allocations or its client
+	// is still connected.
+	Force bool
+
+	WriteRequest
+}
+
+// NodeScaleInStatus is the progress of the scale-in of a node, used by
+// autoscalers to know when the instance of the node can be terminated.
+type NodeScaleInStatus struct {
+	NodeID  string
+	ScaleIn *NodeScaleIn
+
+	// Status is the scale-in status of the node, draining or ready.
+	Status string
+
+	// NodeStatus is the status of the client of the node.
+	NodeStatus string
+
+	// RemainingAllocs is the number of allocations on the node that are not
+	// terminal, excluding system allocations when the drain ignores them.
+	RemainingAllocs int
+
+	NodeModifyIndex uint64
+}
+
+// NodeScaleInStatusResponse is the response to a scale-in status request.
+type NodeScaleInStatusResponse struct {
+	// Status is nil if the node isn't being scaled in.
+	Status *NodeScaleInStatus
+	QueryMeta
+}
+
+// NodeScalingStatusRequest is used to get the capacity of the node pools of
+// the cluster, optionally filtered by datacenter and node class.
+type NodeScalingStatusRequest struct {
+	Datacenter string
+	NodeClass  string
+	QueryOptions
+}
+
+// NodeScalingStatusResponse is the response to a node scaling status request.
+type NodeScalingStatusResponse struct {
+	Pools []*NodePoolScalingStatus
+	QueryMeta
+}
+
+// NodePoolScalingStatus is the metadata autoscalers use to evaluate cluster
+// scaling policies for the nodes of a datacenter and node class.
+type NodePoolScalingStatus struct {
+	Datacenter string
+	NodeClass  string
+
+	// Nodes is the number of nodes in the pool, Ready the number of ready
+	// and eligible nodes, Draining the number of draining nodes and
+	// ScalingIn the number of nodes being scaled in.
+	Nodes     int
+	Ready     int
+	Draining  int
+	ScalingIn int
+
+	// The total and allocated resources of the ready and eligible nodes.
+	TotalCPU          int64
+	AllocatedCPU      int64
+	TotalMemoryMB     int64
+	AllocatedMemoryMB int64
+
+	// Allocations is the number of non-terminal allocations in the pool.
+	Allocations int
+}
@@ -0,0 +1,160 @@
+package structs
+
+import (
+	"fmt"
+	"reflect"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+const (
+	// ServiceProviderConsul is the default service provider and registers
+	// services with Consul.
+	ServiceProviderConsul = "consul"
+
+	// ServiceProviderNomad registers services in Nomad's built-in service
+	// catalog.
+	ServiceProviderNomad = "nomad"
+)
+
+// ServiceRegistration is an instance of a service registered in Nomad's
+// built-in service catalog by the client running the allocation providing
+// it.
+type ServiceRegistration struct {
+	// ID is unique to the allocation, task and service definition the
+	// registration is for.
+	ID string
+
+	// ServiceName is the interpolated name of the service.
+	ServiceName string
+
+	Namespace  string
+	NodeID     string
+	Datacenter string
+	JobID      string
+	AllocID    string
+	Task       string
+	Tags       []string
+
+	// Address and Port are where the service can be reached.
+	Address string
+	Port    int
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Copy returns a deep copy of the registration.
+func (s *ServiceRegistration) Copy() *ServiceRegistration {
+	if s == nil {
+		return nil
+	}
+	ns := new(ServiceRegistration)
+	*ns = *s
+	ns.Tags = helper.CopySliceString(s.Tags)
+	return ns
+}
+
+// Equals returns whether the registrations are the same, ignoring their raft
+// indexes.
+func (s *ServiceRegistration) Equals(o *ServiceRegistration) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return s.ID == o.ID &&
+		s.ServiceName == o.ServiceName &&
+		s.Namespace == o.Namespace &&
+		s.NodeID == o.NodeID &&
+		s.Datacenter == o.Datacenter &&
+		s.JobID == o.JobID &&
+		s.AllocID == o.AllocID &&
+		s.Task == o.Task &&
+		reflect.DeepEqual(s.Tags, o.Tags) &&
+		s.Address == o.Address &&
+		s.Port == o.Port
+}
+
+// Validate checks the fields set by clients registering the service.
+func (s *ServiceRegistration) Validate() error {
+	var mErr multierror.Error
+	if s.ID == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing ID"))
+	}
+	if s.ServiceName == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing service name"))
+	}
+	if s.Namespace == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing namespace"))
+	}
+	if s.NodeID == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing node ID"))
+	}
+	if s.AllocID == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing alloc ID"))
+	}
+	if s.Port < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid port %d", s.Port))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ServiceRegistrationStub summarizes the instances of a service.
+type ServiceRegistrationStub struct {
+	ServiceName string
+
+	// Tags is the union of the tags of all instances
+	Tags []string
+}
+
+// ServiceRegistrationUpsertRequest is used by clients to register services.
+type ServiceRegistrationUpsertRequest struct {
+	Services []*ServiceRegistration
+	WriteRequest
+}
+
+// ServiceRegistrationDeleteRequest is used by clients to deregister services.
+type ServiceRegistrationDeleteRequest struct {
+	// NodeID is the node deregistering the services. Only services
+	// registered by the node are deleted.
+	NodeID string
+	IDs    []string
+	WriteRequest
+}
+
+// ServiceRegistrationDeleteByIDRequest is used by operators to deregister a
+// service instance, for example one left behind by a lost node.
+type ServiceRegistrationDeleteByIDRequest struct {
+	// ServiceName must be the name of the service with the ID, so that a
+	// mistyped ID doesn't deregister an instance of another service.
+	ServiceName string
+	ID          string
+	WriteRequest
+}
+
+// ServiceRegistrationListRequest is used to list the services registered in
+// a namespace.
+type ServiceRegistrationListRequest struct {
+	QueryOptions
+}
+
+// ServiceRegistrationListResponse is used to return the services registered
+// in a namespace.
+type ServiceRegistrationListResponse struct {
+	Services []*ServiceRegistrationStub
+	QueryMeta
+}
+
+// ServiceRegistrationByNameRequest is used to get the instances of a
+// service.
+type ServiceRegistrationByNameRequest struct {
+	ServiceName string
+	QueryOptions
+}
+
+// ServiceRegistrationByNameResponse is used to return the instances of a
+// service.
+type ServiceRegistrationByNameResponse struct {
+	Services []*ServiceRegistration
+	QueryMeta
+}
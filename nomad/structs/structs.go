@@ -24,6 +24,7 @@ import (
 	"strings"
 	"time"
 
+	units "github.com/docker/go-units"
 	"github.com/gorhill/cronexpr"
 	"github.com/hashicorp/consul/api"
 	hcodec "github.com/hashicorp/go-msgpack/codec"
@@ -32,6 +33,7 @@ import (
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/args"
+	"github.com/hashicorp/nomad/helper/jsonschema"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/lib/kheap"
 	psstructs "github.com/hashicorp/nomad/plugins/shared/structs"
@@ -44,6 +46,13 @@ var (
 	// validPolicyName is used to validate a policy name
 	validPolicyName = regexp.MustCompile("^[a-zA-Z0-9-]{1,128}$")
 
+	// validClaimMappingName is used to validate the names claims are
+	// mapped to by auth methods
+	validClaimMappingName = regexp.MustCompile("^[a-zA-Z0-9_]{1,128}$")
+
+	// validHostVolumeName is used to validate a host volume name
+	validHostVolumeName = regexp.MustCompile("^[a-zA-Z0-9_-]{1,128}$")
+
 	// b32 is a lowercase base32 encoding for use in URL friendly service hashes
 	b32 = base32.NewEncoding(strings.ToLower("abcdefghijklmnopqrstuvwxyz234567"))
 )
@@ -82,6 +91,27 @@ const (
 	NodeUpdateEligibilityRequestType
 	BatchNodeUpdateDrainRequestType
 	SchedulerConfigRequestType
+	NodeHostVolumeRegisterRequestType
+	NodeHostVolumeDeregisterRequestType
+	ServiceRegistrationUpsertRequestType
+	ServiceRegistrationDeleteRequestType
+	VariablesUpsertRequestType
+	VariablesDeleteRequestType
+	ACLRoleUpsertRequestType
+	ACLRoleDeleteRequestType
+	ACLAuthMethodUpsertRequestType
+	ACLAuthMethodDeleteRequestType
+	ACLBindingRuleUpsertRequestType
+	ACLBindingRuleDeleteRequestType
+	JobVersionTagRequestType
+	CSIVolumeRegisterRequestType
+	CSIVolumeDeregisterRequestType
+	CSIVolumeClaimRequestType
+	GCConfigRequestType
+	JobBatchRegisterRequestType
+	ScalingEventRegisterRequestType
+	NodeScaleInRequestType
+	ApplyPlanResultsBatchRequestType
 )
 
 const (
@@ -121,6 +151,21 @@ const (
 	ACLClientToken     = "client"
 	ACLManagementToken = "management"
 
+	// ACLAuthMethodTypeJWT and ACLAuthMethodTypeOIDC are the supported
+	// types of auth methods
+	ACLAuthMethodTypeJWT  = "JWT"
+	ACLAuthMethodTypeOIDC = "OIDC"
+
+	// ACLAuthMethodTokenLocalityLocal and ACLAuthMethodTokenLocalityGlobal
+	// control whether tokens created by an auth method are replicated
+	ACLAuthMethodTokenLocalityLocal  = "local"
+	ACLAuthMethodTokenLocalityGlobal = "global"
+
+	// ACLBindingRuleBindTypePolicy and ACLBindingRuleBindTypeRole are the
+	// kinds of ACL objects a binding rule can grant
+	ACLBindingRuleBindTypePolicy = "policy"
+	ACLBindingRuleBindTypeRole   = "role"
+
 	// DefaultNamespace is the default namespace.
 	DefaultNamespace            = "default"
 	DefaultNamespaceDescription = "Default shared namespace"
@@ -338,6 +383,10 @@ type NodeServerInfo struct {
 
 	// Datacenter is the datacenter that a Nomad server belongs to
 	Datacenter string
+
+	// RPCSegmentAddrs maps network segments to the IP endpoint the Nomad
+	// Server wishes to be contacted at by the clients of that segment.
+	RPCSegmentAddrs map[string]string
 }
 
 // NodeUpdateStatusRequest is used for Node.UpdateStatus endpoint
@@ -401,6 +450,29 @@ type NodeUpdateEligibilityRequest struct {
 	WriteRequest
 }
 
+// NodeHostVolumeRegisterRequest is used to register a host volume on a node
+type NodeHostVolumeRegisterRequest struct {
+	NodeID string
+	Volume *HostVolume
+
+	// NodeEvent is the event added to the node
+	NodeEvent *NodeEvent
+
+	WriteRequest
+}
+
+// NodeHostVolumeDeregisterRequest is used to deregister a host volume from a
+// node
+type NodeHostVolumeDeregisterRequest struct {
+	NodeID string
+	Name   string
+
+	// NodeEvent is the event added to the node
+	NodeEvent *NodeEvent
+
+	WriteRequest
+}
+
 // NodeEvaluateRequest is used to re-evaluate the node
 type NodeEvaluateRequest struct {
 	NodeID string
@@ -457,6 +529,10 @@ type JobRegisterRequest struct {
 	// PolicyOverride is set when the user is attempting to override any policies
 	PolicyOverride bool
 
+	// Regional is set when registering the regional copy of a multiregion
+	// job. Such a registration is not forwarded to the other regions.
+	Regional bool
+
 	WriteRequest
 }
 
@@ -485,6 +561,34 @@ type JobBatchDeregisterRequest struct {
 	WriteRequest
 }
 
+// JobBatchRegisterRequest is used to register a set of jobs atomically. Either
+// all of the jobs are registered or none are.
+type JobBatchRegisterRequest struct {
+	// Jobs is the set of jobs to register
+	Jobs []*JobBatchRegisterEntry
+
+	// PolicyOverride is set when the user is attempting to override any
+	// policies
+	PolicyOverride bool
+
+	// Evals is the set of evaluations to create. It is populated by the
+	// server.
+	Evals []*Evaluation
+
+	WriteRequest
+}
+
+// JobBatchRegisterEntry is a job registered as part of a batch.
+type JobBatchRegisterEntry struct {
+	Job *Job
+
+	// If EnforceIndex is set then the batch will only be registered if the
+	// passed JobModifyIndex matches the current Jobs index. If the index is
+	// zero, the batch is only registered if the job is new.
+	EnforceIndex   bool
+	JobModifyIndex uint64
+}
+
 // JobDeregisterOptions configures how a job is deregistered.
 type JobDeregisterOptions struct {
 	// Purge controls whether the deregister purges the job from the system or
@@ -509,6 +613,11 @@ type EvalOptions struct {
 type JobSpecificRequest struct {
 	JobID     string
 	AllAllocs bool
+
+	// DeploymentID limits the allocations of the job to those that are
+	// part of the deployment.
+	DeploymentID string
+
 	QueryOptions
 }
 
@@ -533,14 +642,37 @@ type JobSummaryRequest struct {
 	QueryOptions
 }
 
+// JobSummaryReconcileRequest is used to reconcile job summaries with the
+// allocations and children of their jobs.
+type JobSummaryReconcileRequest struct {
+	// Jobs are the jobs whose summaries are reconciled. If empty, the
+	// summaries of all jobs are reconciled.
+	Jobs []NamespacedID
+
+	WriteRequest
+}
+
 // JobDispatchRequest is used to dispatch a job based on a parameterized job
 type JobDispatchRequest struct {
 	JobID   string
 	Payload []byte
 	Meta    map[string]string
+
+	// Count is the number of indexed child jobs to dispatch as an array. If
+	// zero or one, a single child job is dispatched.
+	Count int
+
 	WriteRequest
 }
 
+// JobDispatchArrayRequest is used to retrieve the status of the child jobs
+// dispatched as an array.
+type JobDispatchArrayRequest struct {
+	JobID   string
+	ArrayID string
+	QueryOptions
+}
+
 // JobValidateRequest is used to validate a job
 type JobValidateRequest struct {
 	Job *Job
@@ -578,9 +710,36 @@ type JobStabilityResponse struct {
 	WriteMeta
 }
 
+// JobVersionTagRequest is used to tag a job version, or to remove a tag.
+type JobVersionTagRequest struct {
+	// JobID is the ID of the job whose version is tagged
+	JobID string
+
+	// JobVersion is the version to tag. It is ignored when removing a tag.
+	JobVersion uint64
+
+	// Tag is the tag to apply to the version. TaggedTime is set by the
+	// server.
+	Tag *JobVersionTag
+
+	// Unset removes the tag with the name of Tag from the version that has
+	// it.
+	Unset bool
+
+	WriteRequest
+}
+
+// JobVersionTagResponse is the response when tagging a job version.
+type JobVersionTagResponse struct {
+	WriteMeta
+}
+
 // NodeListRequest is used to parameterize a list request
 type NodeListRequest struct {
 	QueryOptions
+
+	// Fields selects the optional fields of the returned stubs.
+	Fields *NodeStubFields
 }
 
 // EvalUpdateRequest is used for upserting evaluations.
@@ -620,6 +779,15 @@ type EvalDequeueRequest struct {
 
 // EvalListRequest is used to list the evaluations
 type EvalListRequest struct {
+	// Status limits the evaluations to those with the given status.
+	Status string
+
+	QueryOptions
+}
+
+// EvalPlacementFailuresRequest is used to list the placement failures of the
+// blocked evaluations
+type EvalPlacementFailuresRequest struct {
 	QueryOptions
 }
 
@@ -662,6 +830,14 @@ type ApplyPlanResultsRequest struct {
 	PreemptionEvals []*Evaluation
 }
 
+// ApplyPlanResultsBatchRequest is used by the planner to commit the results
+// of multiple plans in a single Raft transaction.
+type ApplyPlanResultsBatchRequest struct {
+	// Results are the plan results to apply, in the order they were
+	// evaluated.
+	Results []*ApplyPlanResultsRequest
+}
+
 // AllocUpdateRequest is used to submit changes to allocations, either
 // to cause evictions or to assign new allocations. Both can be done
 // within a single transaction
@@ -696,6 +872,72 @@ type AllocUpdateDesiredTransitionRequest struct {
 // AllocListRequest is used to request a list of allocations
 type AllocListRequest struct {
 	QueryOptions
+
+	// Fields selects the optional fields of the returned stubs.
+	Fields *AllocStubFields
+
+	// ClientStatus limits the allocations to those with the given client
+	// status.
+	ClientStatus string
+}
+
+// AllocStopRequest is used to stop and reschedule a running allocation.
+type AllocStopRequest struct {
+	AllocID string
+
+	WriteRequest
+}
+
+// AllocStopResponse is the response to an AllocStopRequest.
+type AllocStopResponse struct {
+	// EvalID is the id of the evaluation created to reschedule the
+	// allocation.
+	EvalID string
+
+	WriteMeta
+}
+
+// AllocRescheduleRequest is used to reschedule a failed allocation without
+// waiting for its reschedule delay.
+type AllocRescheduleRequest struct {
+	AllocID string
+
+	WriteRequest
+}
+
+// AllocRescheduleResponse is the response to an AllocRescheduleRequest.
+type AllocRescheduleResponse struct {
+	// EvalID is the id of the evaluation created to place the replacement
+	// allocation.
+	EvalID string
+
+	WriteMeta
+}
+
+// AllocRestartRequest is used to restart the tasks of an allocation.
+type AllocRestartRequest struct {
+	AllocID string
+
+	// TaskName is the task to restart. All the tasks of the allocation are
+	// restarted if it is empty.
+	TaskName string
+
+	QueryOptions
+}
+
+// AllocSignalRequest is used to send a signal to the tasks of an
+// allocation.
+type AllocSignalRequest struct {
+	AllocID string
+
+	// Task is the task to signal. All the tasks of the allocation are
+	// signalled if it is empty.
+	Task string
+
+	// Signal is the name of the signal to send, such as "SIGHUP".
+	Signal string
+
+	QueryOptions
 }
 
 // AllocSpecificRequest is used to query a specific allocation
@@ -716,6 +958,13 @@ type PeriodicForceRequest struct {
 	WriteRequest
 }
 
+// PeriodicHistoryRequest is used to read the launch history of a periodic
+// job.
+type PeriodicHistoryRequest struct {
+	JobID string
+	QueryOptions
+}
+
 // ServerMembersResponse has the list of servers in a cluster
 type ServerMembersResponse struct {
 	ServerName   string
@@ -763,6 +1012,9 @@ type VaultAccessor struct {
 	Accessor    string
 	CreationTTL int
 
+	// Cluster is the name of the Vault cluster that created the token
+	Cluster string
+
 	// Raft Indexes
 	CreateIndex uint64
 }
@@ -772,6 +1024,11 @@ type DeriveVaultTokenResponse struct {
 	// Tasks is a mapping between the task name and the wrapped token
 	Tasks map[string]string
 
+	// Identities is a mapping between the task name and its workload
+	// identity. It is set instead of Tasks when the Servers use Vault JWT
+	// auth, in which case clients exchange the identities for Vault tokens.
+	Identities map[string]string
+
 	// Error stores any error that occurred. Errors are stored here so we can
 	// communicate whether it is retriable
 	Error *RecoverableError
@@ -787,6 +1044,9 @@ type GenericRequest struct {
 
 // DeploymentListRequest is used to list the deployments
 type DeploymentListRequest struct {
+	// Status limits the deployments to those with the given status.
+	Status string
+
 	QueryOptions
 }
 
@@ -845,6 +1105,26 @@ type ApplyDeploymentAllocHealthRequest struct {
 
 	// An optional evaluation to create after promoting the canaries
 	Eval *Evaluation
+
+	// HealthOverrides records, per task group, that the healthy allocations
+	// were set by an operator rather than by Nomad's own health checking.
+	HealthOverrides map[string]*DeploymentHealthOverride
+}
+
+// DeploymentGroupHealthRequest is used to mark every pending allocation of the
+// given task groups in a deployment as healthy. It is used when the health of
+// the allocations has been validated by a system external to Nomad.
+type DeploymentGroupHealthRequest struct {
+	DeploymentID string
+
+	// Groups is the set of task groups to mark healthy
+	Groups []string
+
+	// Reason is a required description of why the groups are being marked
+	// healthy. It is recorded on the deployment for auditing.
+	Reason string
+
+	WriteRequest
 }
 
 // DeploymentPromoteRequest is used to promote task groups in a deployment
@@ -938,6 +1218,28 @@ type JobBatchDeregisterResponse struct {
 	QueryMeta
 }
 
+// JobBatchRegisterResponse is used to respond to a batch job registration
+type JobBatchRegisterResponse struct {
+	// Jobs is the result of the registration of each job, in the order of
+	// the request
+	Jobs []*JobBatchRegisterResult
+
+	EvalCreateIndex uint64
+	QueryMeta
+}
+
+// JobBatchRegisterResult is the result of registering a job as part of a
+// batch.
+type JobBatchRegisterResult struct {
+	JobID          string
+	EvalID         string
+	JobModifyIndex uint64
+
+	// Warnings contains any warnings about the given job. These may include
+	// deprecation warnings.
+	Warnings string
+}
+
 // JobValidateResponse is the response from validate request
 type JobValidateResponse struct {
 	// DriverConfigValidated indicates whether the agent validated the driver
@@ -1040,9 +1342,23 @@ type JobDispatchResponse struct {
 	EvalID          string
 	EvalCreateIndex uint64
 	JobCreateIndex  uint64
+
+	// ArrayID, DispatchedJobIDs and EvalIDs are set when the dispatch
+	// created an array of child jobs. The IDs are ordered by array index.
+	ArrayID          string
+	DispatchedJobIDs []string
+	EvalIDs          []string
+
 	WriteMeta
 }
 
+// JobDispatchArrayResponse is used to return the status of the child jobs
+// dispatched as an array.
+type JobDispatchArrayResponse struct {
+	Summary *DispatchArraySummary
+	QueryMeta
+}
+
 // JobListResponse is used for a list request
 type JobListResponse struct {
 	Jobs []*JobListStub
@@ -1063,6 +1379,28 @@ type JobVersionsResponse struct {
 	QueryMeta
 }
 
+// JobVersionDiffRequest is used to diff two versions of a job. The versions
+// are selected either by their number or by the name of their tag.
+type JobVersionDiffRequest struct {
+	JobID string
+
+	// From selects the version to diff from.
+	From string
+
+	// To selects the version to diff to. It defaults to the current version.
+	To string
+
+	QueryOptions
+}
+
+// JobVersionDiffResponse is used for a job version diff request
+type JobVersionDiffResponse struct {
+	FromVersion uint64
+	ToVersion   uint64
+	Diff        *JobDiff
+	QueryMeta
+}
+
 // JobPlanResponse is used to respond to a job plan request
 type JobPlanResponse struct {
 	// Annotations stores annotations explaining decisions the scheduler made.
@@ -1176,12 +1514,46 @@ type EvalListResponse struct {
 	QueryMeta
 }
 
+// EvalPlacementFailuresResponse is used to return the placement failures of
+// the blocked evaluations
+type EvalPlacementFailuresResponse struct {
+	Failures []*PlacementFailure
+	QueryMeta
+}
+
+// PlacementFailure describes why the allocations of a task group of a job
+// could not be placed, as recorded by its blocked evaluation.
+type PlacementFailure struct {
+	Namespace string
+	JobID     string
+	TaskGroup string
+
+	// EvalID is the ID of the blocked evaluation.
+	EvalID string
+
+	// Unplaced is the number of allocations that could not be placed.
+	Unplaced int
+
+	// Metrics are the metrics of the last attempt to place the allocations.
+	Metrics *AllocMetric
+}
+
 // EvalAllocationsResponse is used to return the allocations for an evaluation
 type EvalAllocationsResponse struct {
 	Allocations []*AllocListStub
 	QueryMeta
 }
 
+// EvalRelatedResponse is used to return the evaluations related to an
+// evaluation
+type EvalRelatedResponse struct {
+	// Evaluations are the evaluations linked to the evaluation through their
+	// previous, next and blocked evaluations, including itself, sorted by
+	// creation.
+	Evaluations []*Evaluation
+	QueryMeta
+}
+
 // PeriodicForceResponse is used to respond to a periodic job force launch
 type PeriodicForceResponse struct {
 	EvalID          string
@@ -1189,6 +1561,13 @@ type PeriodicForceResponse struct {
 	WriteMeta
 }
 
+// PeriodicHistoryResponse is used to respond to a periodic history request.
+// The events are ordered from oldest to newest.
+type PeriodicHistoryResponse struct {
+	Launches []*PeriodicLaunchEvent
+	QueryMeta
+}
+
 // DeploymentUpdateResponse is used to respond to a deployment change. The
 // response will include the modify index of the deployment as well as details
 // of any triggered evaluation.
@@ -1491,6 +1870,10 @@ type Node struct {
 	// placements.
 	SchedulingEligibility string
 
+	// ScaleIn is set while the node is being removed from the cluster by an
+	// autoscaler.
+	ScaleIn *NodeScaleIn
+
 	// Status of this node
 	Status string
 
@@ -1508,6 +1891,15 @@ type Node struct {
 	// Drivers is a map of driver names to current driver information
 	Drivers map[string]*DriverInfo
 
+	// HostVolumes is a map of volume names to the host volumes registered
+	// on the node by operators
+	HostVolumes map[string]*HostVolume
+
+	// CSIControllerPlugins and CSINodePlugins are maps of plugin IDs to the
+	// CSI plugins running on the node
+	CSIControllerPlugins map[string]*CSIInfo
+	CSINodePlugins       map[string]*CSIInfo
+
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -1551,10 +1943,24 @@ func (n *Node) Copy() *Node {
 	nn.Meta = helper.CopyMapStringString(nn.Meta)
 	nn.Events = copyNodeEvents(n.Events)
 	nn.DrainStrategy = nn.DrainStrategy.Copy()
+	nn.ScaleIn = nn.ScaleIn.Copy()
 	nn.Drivers = copyNodeDrivers(n.Drivers)
+	nn.HostVolumes = copyNodeHostVolumes(n.HostVolumes)
+	nn.CSIControllerPlugins = copyNodeCSIInfo(n.CSIControllerPlugins)
+	nn.CSINodePlugins = copyNodeCSIInfo(n.CSINodePlugins)
 	return nn
 }
 
+// Sanitize returns a copy of the node without its secret ID.
+func (n *Node) Sanitize() *Node {
+	if n == nil {
+		return nil
+	}
+	clean := n.Copy()
+	clean.SecretID = ""
+	return clean
+}
+
 // copyNodeEvents is a helper to copy a list of NodeEvent's
 func copyNodeEvents(events []*NodeEvent) []*NodeEvent {
 	l := len(events)
@@ -1583,6 +1989,20 @@ func copyNodeDrivers(drivers map[string]*DriverInfo) map[string]*DriverInfo {
 	return c
 }
 
+// copyNodeHostVolumes is a helper to copy a map of HostVolume
+func copyNodeHostVolumes(volumes map[string]*HostVolume) map[string]*HostVolume {
+	l := len(volumes)
+	if l == 0 {
+		return nil
+	}
+
+	c := make(map[string]*HostVolume, l)
+	for name, volume := range volumes {
+		c[name] = volume.Copy()
+	}
+	return c
+}
+
 // TerminalStatus returns if the current status is terminal and
 // will no longer transition.
 func (n *Node) TerminalStatus() bool {
@@ -1653,11 +2073,11 @@ func (n *Node) ComparableResources() *ComparableResources {
 }
 
 // Stub returns a summarized version of the node
-func (n *Node) Stub() *NodeListStub {
+func (n *Node) Stub(fields *NodeStubFields) *NodeListStub {
 
 	addr, _, _ := net.SplitHostPort(n.HTTPAddr)
 
-	return &NodeListStub{
+	s := &NodeListStub{
 		Address:               addr,
 		ID:                    n.ID,
 		Datacenter:            n.Datacenter,
@@ -1672,6 +2092,13 @@ func (n *Node) Stub() *NodeListStub {
 		CreateIndex:           n.CreateIndex,
 		ModifyIndex:           n.ModifyIndex,
 	}
+
+	if fields != nil && fields.Resources {
+		s.NodeResources = n.NodeResources
+		s.ReservedResources = n.ReservedResources
+	}
+
+	return s
 }
 
 // NodeListStub is used to return a subset of job information
@@ -1688,10 +2115,18 @@ type NodeListStub struct {
 	Status                string
 	StatusDescription     string
 	Drivers               map[string]*DriverInfo
+	NodeResources         *NodeResources         `json:",omitempty"`
+	ReservedResources     *NodeReservedResources `json:",omitempty"`
 	CreateIndex           uint64
 	ModifyIndex           uint64
 }
 
+// NodeStubFields selects the optional fields of node stubs.
+type NodeStubFields struct {
+	// Resources includes the resources and reserved resources of the nodes.
+	Resources bool
+}
+
 // Resources is used to define the resources available
 // on a client
 type Resources struct {
@@ -3100,6 +3535,10 @@ type Job struct {
 	// COMPAT: Remove in 0.7.0. Stagger is deprecated in 0.6.0.
 	Update UpdateStrategy
 
+	// Multiregion is used to register the job in multiple regions and to
+	// stage its deployments across them.
+	Multiregion *Multiregion
+
 	// Periodic is used to define the interval the job is run at.
 	Periodic *PeriodicConfig
 
@@ -3138,6 +3577,10 @@ type Job struct {
 	// on each job register.
 	Version uint64
 
+	// VersionTag is the tag of the version, if any. Tagged versions are not
+	// garbage collected.
+	VersionTag *JobVersionTag
+
 	// SubmitTime is the time at which the job was submitted as a UnixNano in
 	// UTC
 	SubmitTime int64
@@ -3188,6 +3631,10 @@ func (j *Job) Canonicalize() (warnings error) {
 		j.Periodic.Canonicalize()
 	}
 
+	if j.Multiregion != nil {
+		j.Multiregion.Canonicalize()
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -3214,6 +3661,8 @@ func (j *Job) Copy() *Job {
 	nj.Periodic = nj.Periodic.Copy()
 	nj.Meta = helper.CopyMapStringString(nj.Meta)
 	nj.ParameterizedJob = nj.ParameterizedJob.Copy()
+	nj.VersionTag = nj.VersionTag.Copy()
+	nj.Multiregion = nj.Multiregion.Copy()
 	return nj
 }
 
@@ -3332,6 +3781,18 @@ func (j *Job) Validate() error {
 		}
 	}
 
+	if j.IsMultiregion() {
+		if j.Type != JobTypeService {
+			mErr.Errors = append(mErr.Errors,
+				fmt.Errorf("Multiregion can only be used with %q scheduler", JobTypeService))
+		}
+
+		if err := j.Multiregion.Validate(); err != nil {
+			outer := fmt.Errorf("Multiregion validation failed: %v", err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -3451,6 +3912,11 @@ func (j *Job) IsParameterized() bool {
 	return j.ParameterizedJob != nil && !j.Dispatched
 }
 
+// IsMultiregion returns whether a job is registered in multiple regions.
+func (j *Job) IsMultiregion() bool {
+	return j.Multiregion != nil && len(j.Multiregion.Regions) != 0
+}
+
 // VaultPolicies returns the set of Vault policies per task group, per task
 func (j *Job) VaultPolicies() map[string]map[string]*Vault {
 	policies := make(map[string]map[string]*Vault, len(j.TaskGroups))
@@ -3543,6 +4009,7 @@ func (j *Job) SpecChanged(new *Job) bool {
 	c.StatusDescription = j.StatusDescription
 	c.Stable = j.Stable
 	c.Version = j.Version
+	c.VersionTag = j.VersionTag
 	c.CreateIndex = j.CreateIndex
 	c.ModifyIndex = j.ModifyIndex
 	c.JobModifyIndex = j.JobModifyIndex
@@ -3556,6 +4023,56 @@ func (j *Job) SetSubmitTime() {
 	j.SubmitTime = time.Now().UTC().UnixNano()
 }
 
+const (
+	// maxJobVersionTagNameLength limits the length of a job version tag name
+	maxJobVersionTagNameLength = 128
+
+	// maxJobVersionTagDescriptionLength limits the length of a job version
+	// tag description
+	maxJobVersionTagDescriptionLength = 256
+)
+
+// JobVersionTag names a version of a job, such as the version known to be
+// good, so that it can be referred to and is kept.
+type JobVersionTag struct {
+	// Name of the tag, unique among the versions of the job
+	Name string
+
+	// Description is a free form description of the tag
+	Description string
+
+	// TaggedTime is the time at which the version was tagged as a UnixNano
+	// in UTC
+	TaggedTime int64
+}
+
+// Copy returns a copy of the tag
+func (t *JobVersionTag) Copy() *JobVersionTag {
+	if t == nil {
+		return nil
+	}
+	nt := new(JobVersionTag)
+	*nt = *t
+	return nt
+}
+
+// Validate is used to sanity check a job version tag
+func (t *JobVersionTag) Validate() error {
+	var mErr multierror.Error
+	if t.Name == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Missing tag name"))
+	} else if len(t.Name) > maxJobVersionTagNameLength {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Tag name longer than %d characters", maxJobVersionTagNameLength))
+	} else if _, err := strconv.ParseUint(t.Name, 10, 64); err == nil {
+		// Versions are selected either by number or tag name
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Tag name %q can't be a version number", t.Name))
+	}
+	if len(t.Description) > maxJobVersionTagDescriptionLength {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Tag description longer than %d characters", maxJobVersionTagDescriptionLength))
+	}
+	return mErr.ErrorOrNil()
+}
+
 // JobListStub is used to return a subset of job information
 // for the job list
 type JobListStub struct {
@@ -3702,6 +4219,15 @@ type UpdateStrategy struct {
 	// stable version.
 	AutoRevert bool
 
+	// AutoPromote declares that the deployment should be promoted when all
+	// canaries are healthy.
+	AutoPromote bool
+
+	// ManualPromotionPerGroup declares that the canaries of the task group
+	// are only promoted when the task group is promoted by name, so that the
+	// groups of a job can be promoted one at a time.
+	ManualPromotionPerGroup bool
+
 	// Canary is the number of canaries to deploy when a change to the task
 	// group is detected.
 	Canary int
@@ -3753,6 +4279,9 @@ func (u *UpdateStrategy) Validate() error {
 	if u.Stagger <= 0 {
 		multierror.Append(&mErr, fmt.Errorf("Stagger must be greater than zero: %v", u.Stagger))
 	}
+	if u.AutoPromote && u.ManualPromotionPerGroup {
+		multierror.Append(&mErr, fmt.Errorf("Auto promote can not be used with manual promotion per group"))
+	}
 
 	return mErr.ErrorOrNil()
 }
@@ -3772,6 +4301,20 @@ const (
 	PeriodicSpecTest = "_internal_test"
 )
 
+const (
+	// PeriodicOverlapSkip skips a launch while children of the job are
+	// running. It is the policy used when ProhibitOverlap is set.
+	PeriodicOverlapSkip = "skip"
+
+	// PeriodicOverlapCancelRunning stops the running children of the job
+	// before launching a new one.
+	PeriodicOverlapCancelRunning = "cancel_running"
+
+	// PeriodicOverlapEnqueue defers a launch until the running children of
+	// the job have finished. At most one launch is queued at a time.
+	PeriodicOverlapEnqueue = "enqueue"
+)
+
 // Periodic defines the interval a job should be run at.
 type PeriodicConfig struct {
 	// Enabled determines if the job should be run periodically.
@@ -3787,6 +4330,11 @@ type PeriodicConfig struct {
 	// ProhibitOverlap enforces that spawned jobs do not run in parallel.
 	ProhibitOverlap bool
 
+	// OverlapPolicy controls what happens to a launch while spawned jobs are
+	// running. If empty, ProhibitOverlap determines whether launches are
+	// skipped.
+	OverlapPolicy string
+
 	// TimeZone is the user specified string that determines the time zone to
 	// launch against. The time zones must be specified from IANA Time Zone
 	// database, such as "America/New_York".
@@ -3824,6 +4372,16 @@ func (p *PeriodicConfig) Validate() error {
 		}
 	}
 
+	switch p.OverlapPolicy {
+	case "", PeriodicOverlapSkip:
+	case PeriodicOverlapCancelRunning, PeriodicOverlapEnqueue:
+		if p.ProhibitOverlap {
+			multierror.Append(&mErr, fmt.Errorf("Prohibit overlap can not be used with overlap policy %q", p.OverlapPolicy))
+		}
+	default:
+		multierror.Append(&mErr, fmt.Errorf("Unknown overlap policy %q", p.OverlapPolicy))
+	}
+
 	switch p.SpecType {
 	case PeriodicSpecCron:
 		// Validate the cron spec
@@ -3911,17 +4469,67 @@ func (p *PeriodicConfig) GetLocation() *time.Location {
 	return time.UTC
 }
 
+// GetOverlapPolicy returns the policy applied to launches while spawned jobs
+// are running, or an empty string if overlapping is allowed.
+func (p *PeriodicConfig) GetOverlapPolicy() string {
+	if p.OverlapPolicy != "" {
+		return p.OverlapPolicy
+	}
+	if p.ProhibitOverlap {
+		return PeriodicOverlapSkip
+	}
+	return ""
+}
+
 const (
 	// PeriodicLaunchSuffix is the string appended to the periodic jobs ID
 	// when launching derived instances of it.
 	PeriodicLaunchSuffix = "/periodic-"
 )
 
-// PeriodicLaunch tracks the last launch time of a periodic job.
-type PeriodicLaunch struct {
-	ID        string    // ID of the periodic job.
-	Namespace string    // Namespace of the periodic job
-	Launch    time.Time // The last launch time.
+const (
+	// PeriodicLaunchFired is recorded when a periodic job launches on its
+	// schedule.
+	PeriodicLaunchFired = "fired"
+
+	// PeriodicLaunchForced is recorded when a periodic job is force launched.
+	PeriodicLaunchForced = "forced"
+
+	// PeriodicLaunchSkipped is recorded when a launch is skipped, because of
+	// the overlap policy of the job or a failure to launch it.
+	PeriodicLaunchSkipped = "skipped"
+
+	// PeriodicLaunchQueued is recorded when a launch is deferred until the
+	// running children of the job finish.
+	PeriodicLaunchQueued = "queued"
+)
+
+// PeriodicLaunchEvent records a launch, or a skipped launch, of a periodic
+// job.
+type PeriodicLaunchEvent struct {
+	// Type is the type of the event, such as fired or skipped.
+	Type string
+
+	// LaunchTime is the launch time in the time zone of the job.
+	LaunchTime time.Time
+
+	// ChildJobID and EvalID are the job launched and its evaluation.
+	ChildJobID string
+	EvalID     string
+
+	// StoppedJobIDs are the running children stopped by the launch when the
+	// overlap policy is cancel_running.
+	StoppedJobIDs []string
+
+	// Message describes why a launch was skipped or queued.
+	Message string
+}
+
+// PeriodicLaunch tracks the last launch time of a periodic job.
+type PeriodicLaunch struct {
+	ID        string    // ID of the periodic job.
+	Namespace string    // Namespace of the periodic job
+	Launch    time.Time // The last launch time.
 
 	// Raft Indexes
 	CreateIndex uint64
@@ -3936,8 +4544,50 @@ const (
 	// DispatchLaunchSuffix is the string appended to the parameterized job's ID
 	// when dispatching instances of it.
 	DispatchLaunchSuffix = "/dispatch-"
+
+	// DispatchArrayIDMetaKey and DispatchArrayIndexMetaKey are the meta keys
+	// set on child jobs dispatched as an array. They identify the array and
+	// the index of the child job within it.
+	DispatchArrayIDMetaKey    = "dispatch_array_id"
+	DispatchArrayIndexMetaKey = "dispatch_array_index"
+)
+
+const (
+	// DispatchMetaTypeString, DispatchMetaTypeInt, DispatchMetaTypeFloat and
+	// DispatchMetaTypeBool are the types dispatch meta values can be
+	// required to have.
+	DispatchMetaTypeString = "string"
+	DispatchMetaTypeInt    = "int"
+	DispatchMetaTypeFloat  = "float"
+	DispatchMetaTypeBool   = "bool"
 )
 
+// DispatchArraySummary is the aggregate status of the child jobs dispatched
+// as a single array.
+type DispatchArraySummary struct {
+	// JobID is the ID of the parameterized job
+	JobID string
+
+	// ArrayID is the ID of the dispatched array
+	ArrayID string
+
+	// Count is the number of child jobs in the array
+	Count int
+
+	// Pending, Running and Dead is the number of child jobs in each status
+	Pending int
+	Running int
+	Dead    int
+
+	// JobIDs are the IDs of the child jobs ordered by array index
+	JobIDs []string
+}
+
+// Complete returns whether all the child jobs of the array are dead.
+func (d *DispatchArraySummary) Complete() bool {
+	return d.Count != 0 && d.Dead == d.Count
+}
+
 // ParameterizedJobConfig is used to configure the parameterized job
 type ParameterizedJobConfig struct {
 	// Payload configure the payload requirements
@@ -3948,6 +4598,13 @@ type ParameterizedJobConfig struct {
 
 	// MetaOptional is metadata keys that may be specified by the dispatcher
 	MetaOptional []string
+
+	// MetaTypes is the type the values of meta keys must have, such as int
+	// or bool.
+	MetaTypes map[string]string
+
+	// PayloadSchema is a JSON schema the payload must match, if set.
+	PayloadSchema string
 }
 
 func (d *ParameterizedJobConfig) Validate() error {
@@ -3964,9 +4621,73 @@ func (d *ParameterizedJobConfig) Validate() error {
 		multierror.Append(&mErr, fmt.Errorf("Required and optional meta keys should be disjoint. Following keys exist in both: %v", offending))
 	}
 
+	// Check that typed meta keys are allowed and have a known type
+	allowed := helper.SliceStringToSet(append(helper.CopySliceString(d.MetaRequired), d.MetaOptional...))
+	for k, t := range d.MetaTypes {
+		if _, ok := allowed[k]; !ok {
+			multierror.Append(&mErr, fmt.Errorf("Meta key %q has a type but is neither required nor optional", k))
+		}
+		switch t {
+		case DispatchMetaTypeString, DispatchMetaTypeInt, DispatchMetaTypeFloat, DispatchMetaTypeBool:
+		default:
+			multierror.Append(&mErr, fmt.Errorf("Unknown type %q for meta key %q", t, k))
+		}
+	}
+
+	if d.PayloadSchema != "" {
+		if d.Payload == DispatchPayloadForbidden {
+			multierror.Append(&mErr, fmt.Errorf("Payload schema can not be set when the payload is forbidden"))
+		}
+		if _, err := jsonschema.Parse([]byte(d.PayloadSchema)); err != nil {
+			multierror.Append(&mErr, fmt.Errorf("Invalid payload schema: %v", err))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// ValidateDispatchMeta checks that the dispatch meta values have the types
+// declared for their keys.
+func (d *ParameterizedJobConfig) ValidateDispatchMeta(meta map[string]string) error {
+	var mErr multierror.Error
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := meta[k]
+		var err error
+		switch d.MetaTypes[k] {
+		case DispatchMetaTypeInt:
+			_, err = strconv.ParseInt(v, 10, 64)
+		case DispatchMetaTypeFloat:
+			_, err = strconv.ParseFloat(v, 64)
+		case DispatchMetaTypeBool:
+			_, err = strconv.ParseBool(v)
+		}
+		if err != nil {
+			multierror.Append(&mErr, fmt.Errorf("Meta key %q must be of type %s; got %q", k, d.MetaTypes[k], v))
+		}
+	}
 	return mErr.ErrorOrNil()
 }
 
+// ValidateDispatchPayload checks that the dispatch payload matches the payload
+// schema, if one is set.
+func (d *ParameterizedJobConfig) ValidateDispatchPayload(payload []byte) error {
+	if d.PayloadSchema == "" {
+		return nil
+	}
+
+	schema, err := jsonschema.Parse([]byte(d.PayloadSchema))
+	if err != nil {
+		return fmt.Errorf("Invalid payload schema: %v", err)
+	}
+	return schema.Validate(payload)
+}
+
 func (d *ParameterizedJobConfig) Canonicalize() {
 	if d.Payload == "" {
 		d.Payload = DispatchPayloadOptional
@@ -3981,9 +4702,164 @@ func (d *ParameterizedJobConfig) Copy() *ParameterizedJobConfig {
 	*nd = *d
 	nd.MetaOptional = helper.CopySliceString(nd.MetaOptional)
 	nd.MetaRequired = helper.CopySliceString(nd.MetaRequired)
+	nd.MetaTypes = helper.CopyMapStringString(nd.MetaTypes)
 	return nd
 }
 
+const (
+	// MultiregionOnFailureFailAll fails the deployments of all the regions
+	// when the deployment of one region fails
+	MultiregionOnFailureFailAll = "fail_all"
+
+	// MultiregionOnFailureFailLocal only fails the deployment of the region
+	// that failed and lets the other regions complete their deployments
+	MultiregionOnFailureFailLocal = "fail_local"
+)
+
+// Multiregion is used to register a job in multiple regions. The job is
+// registered in every region, with the regional overrides applied, and its
+// deployments are staged across the regions in the order they are listed.
+type Multiregion struct {
+	Strategy *MultiregionStrategy
+	Regions  []*MultiregionRegion
+}
+
+// MultiregionStrategy configures how deployments are staged across regions
+type MultiregionStrategy struct {
+	// MaxParallel is the number of regions deploying at the same time. Zero
+	// means all the regions deploy at once.
+	MaxParallel int
+
+	// OnFailure is the behavior when the deployment of a region fails. By
+	// default the deployments of the regions that have not completed yet are
+	// failed.
+	OnFailure string
+}
+
+// MultiregionRegion is a region the job is registered in and the overrides of
+// the job for that region
+type MultiregionRegion struct {
+	// Name of the region
+	Name string
+
+	// Count overrides the count of all the task groups when set
+	Count int
+
+	// Datacenters overrides the datacenters of the job when set
+	Datacenters []string
+
+	// Meta is merged into the meta of the job
+	Meta map[string]string
+}
+
+func (m *Multiregion) Copy() *Multiregion {
+	if m == nil {
+		return nil
+	}
+	nm := new(Multiregion)
+	if m.Strategy != nil {
+		nm.Strategy = new(MultiregionStrategy)
+		*nm.Strategy = *m.Strategy
+	}
+	if m.Regions != nil {
+		nm.Regions = make([]*MultiregionRegion, len(m.Regions))
+		for i, r := range m.Regions {
+			nr := new(MultiregionRegion)
+			*nr = *r
+			nr.Datacenters = helper.CopySliceString(r.Datacenters)
+			nr.Meta = helper.CopyMapStringString(r.Meta)
+			nm.Regions[i] = nr
+		}
+	}
+	return nm
+}
+
+func (m *Multiregion) Canonicalize() {
+	if m.Strategy == nil {
+		m.Strategy = &MultiregionStrategy{}
+	}
+	for _, r := range m.Regions {
+		if len(r.Meta) == 0 {
+			r.Meta = nil
+		}
+	}
+}
+
+func (m *Multiregion) Validate() error {
+	var mErr multierror.Error
+	if m.Strategy != nil {
+		if m.Strategy.MaxParallel < 0 {
+			multierror.Append(&mErr, fmt.Errorf("Max parallel must be >= 0"))
+		}
+		switch m.Strategy.OnFailure {
+		case "", MultiregionOnFailureFailAll, MultiregionOnFailureFailLocal:
+		default:
+			multierror.Append(&mErr, fmt.Errorf("Unknown on_failure behavior: %q", m.Strategy.OnFailure))
+		}
+	}
+
+	seen := make(map[string]struct{}, len(m.Regions))
+	for idx, r := range m.Regions {
+		if r.Name == "" {
+			multierror.Append(&mErr, fmt.Errorf("Region %d missing name", idx+1))
+			continue
+		}
+		if _, ok := seen[r.Name]; ok {
+			multierror.Append(&mErr, fmt.Errorf("Region %q is defined more than once", r.Name))
+		}
+		seen[r.Name] = struct{}{}
+		if r.Count < 0 {
+			multierror.Append(&mErr, fmt.Errorf("Region %q count must be >= 0", r.Name))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// Index returns the position of the region in the deployment order or -1 if
+// the region is not part of the job.
+func (m *Multiregion) Index(region string) int {
+	if m == nil {
+		return -1
+	}
+	for i, r := range m.Regions {
+		if r.Name == region {
+			return i
+		}
+	}
+	return -1
+}
+
+// RegionalJob returns a copy of the job for the given region with the
+// regional overrides applied.
+func (m *Multiregion) RegionalJob(job *Job, region string) *Job {
+	i := m.Index(region)
+	if i < 0 {
+		return nil
+	}
+	r := m.Regions[i]
+
+	nj := job.Copy()
+	nj.Region = region
+	if len(r.Datacenters) != 0 {
+		nj.Datacenters = helper.CopySliceString(r.Datacenters)
+	}
+	if r.Count > 0 {
+		for _, tg := range nj.TaskGroups {
+			tg.Count = r.Count
+		}
+	}
+	if len(r.Meta) != 0 {
+		if nj.Meta == nil {
+			nj.Meta = make(map[string]string, len(r.Meta))
+		}
+		for k, v := range r.Meta {
+			nj.Meta[k] = v
+		}
+	}
+	return nj
+}
+
 // DispatchedID returns an ID appropriate for a job dispatched against a
 // particular parameterized job
 func DispatchedID(templateID string, t time.Time) string {
@@ -4586,7 +5462,13 @@ func (tg *TaskGroup) Validate(j *Job) error {
 	// Validate the update strategy
 	if u := tg.Update; u != nil {
 		switch j.Type {
-		case JobTypeService, JobTypeSystem:
+		case JobTypeService:
+		case JobTypeSystem:
+			// System jobs have no deployment on which health can be set
+			// manually, so a rolling update would never make progress
+			if u.HealthCheck == UpdateStrategyHealthCheck_Manual {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("System jobs do not support %q health checks", UpdateStrategyHealthCheck_Manual))
+			}
 		default:
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("Job type %q does not allow update block", j.Type))
 		}
@@ -4664,8 +5546,9 @@ func (tg *TaskGroup) Warnings(j *Job) error {
 
 	// Validate the update strategy
 	if u := tg.Update; u != nil {
-		// Check the counts are appropriate
-		if u.MaxParallel > tg.Count {
+		// Check the counts are appropriate. System jobs place one
+		// allocation per node regardless of the count, so it is ignored.
+		if j.Type != JobTypeSystem && u.MaxParallel > tg.Count {
 			mErr.Errors = append(mErr.Errors,
 				fmt.Errorf("Update max parallel count is greater than task group count (%d > %d). "+
 					"A destructive change would result in the simultaneous replacement of all allocations.", u.MaxParallel, tg.Count))
@@ -4954,6 +5837,10 @@ type Service struct {
 	// this service.
 	AddressMode string
 
+	// Provider is where the service is registered, either Consul or
+	// Nomad's built-in service catalog. Defaults to Consul if empty.
+	Provider string
+
 	Tags       []string        // List of tags for the service
 	CanaryTags []string        // List of tags for the service when it is a canary
 	Checks     []*ServiceCheck // List of checks associated with the service
@@ -4979,6 +5866,12 @@ func (s *Service) Copy() *Service {
 	return ns
 }
 
+// UsesNomadProvider returns whether the service is registered in Nomad's
+// built-in service catalog rather than Consul.
+func (s *Service) UsesNomadProvider() bool {
+	return s.Provider == ServiceProviderNomad
+}
+
 // Canonicalize interpolates values of Job, Task Group and Task in the Service
 // Name. This also generates check names, service id and check ids.
 func (s *Service) Canonicalize(job string, taskGroup string, task string) {
@@ -5027,6 +5920,17 @@ func (s *Service) Validate() error {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("service address_mode must be %q, %q, or %q; not %q", AddressModeAuto, AddressModeHost, AddressModeDriver, s.AddressMode))
 	}
 
+	switch s.Provider {
+	case "", ServiceProviderConsul:
+		// OK
+	case ServiceProviderNomad:
+		if len(s.Checks) != 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("service %q uses the %q provider which does not support checks", s.Name, ServiceProviderNomad))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("service provider must be %q or %q; not %q", ServiceProviderConsul, ServiceProviderNomad, s.Provider))
+	}
+
 	for _, c := range s.Checks {
 		if s.PortLabel == "" && c.PortLabel == "" && c.RequiresPort() {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("check %s invalid: check requires a port but neither check nor service %+q have a port", c.Name, s.Name))
@@ -5071,6 +5975,12 @@ func (s *Service) Hash(allocID, taskName string, canary bool) string {
 		io.WriteString(h, tag)
 	}
 
+	// Only include the Nomad provider so the IDs of Consul services do not
+	// change
+	if s.Provider == ServiceProviderNomad {
+		io.WriteString(h, s.Provider)
+	}
+
 	// Vary ID on whether or not CanaryTags will be used
 	if canary {
 		h.Write([]byte("Canary"))
@@ -5093,6 +6003,17 @@ const (
 type LogConfig struct {
 	MaxFiles      int
 	MaxFileSizeMB int
+
+	// Sink is the name of the log sink the task's output is streamed to in
+	// addition to its log files.
+	Sink string
+
+	// SinkOptions are the options of the log sink.
+	SinkOptions map[string]string
+
+	// DisableFiles disables writing the task's output to log files when
+	// streaming it to a sink.
+	DisableFiles bool
 }
 
 // DefaultLogConfig returns the default LogConfig values.
@@ -5103,6 +6024,16 @@ func DefaultLogConfig() *LogConfig {
 	}
 }
 
+func (l *LogConfig) Copy() *LogConfig {
+	if l == nil {
+		return nil
+	}
+	nl := new(LogConfig)
+	*nl = *l
+	nl.SinkOptions = helper.CopyMapStringString(l.SinkOptions)
+	return nl
+}
+
 // Validate returns an error if the log config specified are less than
 // the minimum allowed.
 func (l *LogConfig) Validate() error {
@@ -5113,6 +6044,12 @@ func (l *LogConfig) Validate() error {
 	if l.MaxFileSizeMB < 1 {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum file size is 1MB; got %d", l.MaxFileSizeMB))
 	}
+	if l.DisableFiles && l.Sink == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("log files may only be disabled when a sink is set"))
+	}
+	if l.Sink == "" && len(l.SinkOptions) != 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("sink options require a sink"))
+	}
 	return mErr.ErrorOrNil()
 }
 
@@ -5211,6 +6148,7 @@ func (t *Task) Copy() *Task {
 	nt.Resources = nt.Resources.Copy()
 	nt.Meta = helper.CopyMapStringString(nt.Meta)
 	nt.DispatchPayload = nt.DispatchPayload.Copy()
+	nt.LogConfig = nt.LogConfig.Copy()
 
 	if t.Artifacts != nil {
 		artifacts := make([]*TaskArtifact, 0, len(t.Artifacts))
@@ -5346,7 +6284,7 @@ func (t *Task) Validate(ephemeralDisk *EphemeralDisk, jobType string) error {
 		mErr.Errors = append(mErr.Errors, err)
 	}
 
-	if t.LogConfig != nil && ephemeralDisk != nil {
+	if t.LogConfig != nil && !t.LogConfig.DisableFiles && ephemeralDisk != nil {
 		logUsage := (t.LogConfig.MaxFiles * t.LogConfig.MaxFileSizeMB)
 		if ephemeralDisk.SizeMB <= logUsage {
 			mErr.Errors = append(mErr.Errors,
@@ -5601,6 +6539,10 @@ type Template struct {
 	// secret. If the lease of a secret is less than the grace, a new secret is
 	// acquired.
 	VaultGrace time.Duration
+
+	// Sandbox disallows template functions that access the host, such as
+	// reading files or the environment of the client.
+	Sandbox bool
 }
 
 // DefaultTemplate returns a default template.
@@ -5708,6 +6650,10 @@ type TaskState struct {
 	// task starts
 	StartedAt time.Time
 
+	// StartLatency is how long the task took to start the last time it was
+	// started, measured from when its prestart hooks began to run.
+	StartLatency time.Duration
+
 	// FinishedAt is the time at which the task transitioned to dead and will
 	// not be started again.
 	FinishedAt time.Time
@@ -5988,13 +6934,19 @@ func (event *TaskEvent) PopulateEventDisplayMessage() {
 		}
 	case TaskTerminated:
 		var parts []string
+		oom := event.oomKilledMessage()
+		if oom != "" {
+			parts = append(parts, oom)
+		}
+
 		parts = append(parts, fmt.Sprintf("Exit Code: %d", event.ExitCode))
 
 		if event.Signal != 0 {
 			parts = append(parts, fmt.Sprintf("Signal: %d", event.Signal))
 		}
 
-		if event.Message != "" {
+		// Drivers report OOM kills as the exit message as well
+		if event.Message != "" && !(oom != "" && event.Message == "OOM Killed") {
 			parts = append(parts, fmt.Sprintf("Exit Message: %q", event.Message))
 		}
 		desc = strings.Join(parts, ", ")
@@ -6038,6 +6990,9 @@ func (event *TaskEvent) PopulateEventDisplayMessage() {
 		}
 	case TaskDriverMessage:
 		desc = event.DriverMessage
+		if oom := event.oomKilledMessage(); oom != "" {
+			desc = fmt.Sprintf("%s - %s", oom, desc)
+		}
 	case TaskLeaderDead:
 		desc = "Leader Task in Group dead"
 	default:
@@ -6163,6 +7118,14 @@ func (e *TaskEvent) SetTaskSignal(s os.Signal) *TaskEvent {
 	return e
 }
 
+// SetSignalText sets the signal of the event from its name, such as "SIGHUP",
+// for signals requested by name rather than sent by the client itself.
+func (e *TaskEvent) SetSignalText(sig string) *TaskEvent {
+	e.TaskSignal = sig
+	e.Details["task_signal"] = sig
+	return e
+}
+
 func (e *TaskEvent) SetDownloadError(err error) *TaskEvent {
 	if err != nil {
 		e.DownloadError = err.Error()
@@ -6216,6 +7179,39 @@ func (e *TaskEvent) SetOOMKilled(oom bool) *TaskEvent {
 	return e
 }
 
+// SetExitDetails adds the driver specific details of the task's exit to the
+// event.
+func (e *TaskEvent) SetExitDetails(details map[string]string) *TaskEvent {
+	for k, v := range details {
+		e.Details[k] = v
+	}
+	return e
+}
+
+// oomKilledMessage returns a description of the OOM kill of the task, along
+// with its memory usage and limit if the driver reported them, or an empty
+// string if the task wasn't OOM killed.
+func (e *TaskEvent) oomKilledMessage() string {
+	if e.Details["oom_killed"] != "true" {
+		return ""
+	}
+
+	var usage []string
+	for _, d := range []struct{ key, name string }{
+		{"memory_rss", "rss"},
+		{"memory_limit", "limit"},
+	} {
+		if size, err := strconv.ParseFloat(e.Details[d.key], 64); err == nil && size > 0 {
+			usage = append(usage, fmt.Sprintf("%s=%s", d.name, strings.Replace(units.BytesSize(size), " ", "", 1)))
+		}
+	}
+
+	if len(usage) == 0 {
+		return "OOM Killed"
+	}
+	return fmt.Sprintf("OOM Killed, %s", strings.Join(usage, " "))
+}
+
 // TaskArtifact is an artifact to download before running the task.
 type TaskArtifact struct {
 	// GetterSource is the source to download an artifact using go-getter
@@ -6695,6 +7691,11 @@ type Vault struct {
 	// ChangeSignal is the signal sent to the task when a new token is
 	// retrieved. This is only valid when using the signal change mode.
 	ChangeSignal string
+
+	// Cluster is the name of the Vault cluster the task derives its token
+	// from. If unset, the server picks the cluster mapped to the job's
+	// namespace.
+	Cluster string
 }
 
 func DefaultVaultBlock() *Vault {
@@ -6759,10 +7760,19 @@ const (
 	DeploymentStatusSuccessful = "successful"
 	DeploymentStatusCancelled  = "cancelled"
 
+	// DeploymentStatusPending is the status of the deployment of a
+	// multiregion job waiting for the deployments of earlier regions
+	DeploymentStatusPending = "pending"
+
+	// DeploymentStatusBlocked is the status of the completed deployment of a
+	// multiregion job waiting for the deployments of the other regions
+	DeploymentStatusBlocked = "blocked"
+
 	// DeploymentStatusDescriptions are the various descriptions of the states a
 	// deployment can be in.
 	DeploymentStatusDescriptionRunning               = "Deployment is running"
 	DeploymentStatusDescriptionRunningNeedsPromotion = "Deployment is running but requires promotion"
+	DeploymentStatusDescriptionRunningAutoPromotion  = "Deployment is running pending automatic promotion"
 	DeploymentStatusDescriptionPaused                = "Deployment is paused"
 	DeploymentStatusDescriptionSuccessful            = "Deployment completed successfully"
 	DeploymentStatusDescriptionStoppedJob            = "Cancelled because job is stopped"
@@ -6770,6 +7780,9 @@ const (
 	DeploymentStatusDescriptionFailedAllocations     = "Failed due to unhealthy allocations"
 	DeploymentStatusDescriptionProgressDeadline      = "Failed due to progress deadline"
 	DeploymentStatusDescriptionFailedByUser          = "Deployment marked as failed"
+	DeploymentStatusDescriptionPendingForPeer        = "Deployment is pending, waiting for peer region"
+	DeploymentStatusDescriptionBlocked               = "Deployment is complete but waiting for peer region"
+	DeploymentStatusDescriptionFailedByPeer          = "Failed because of an error in peer region"
 )
 
 // DeploymentStatusDescriptionRollback is used to get the status description of
@@ -6871,7 +7884,8 @@ func (d *Deployment) Copy() *Deployment {
 // Active returns whether the deployment is active or terminal.
 func (d *Deployment) Active() bool {
 	switch d.Status {
-	case DeploymentStatusRunning, DeploymentStatusPaused:
+	case DeploymentStatusRunning, DeploymentStatusPaused,
+		DeploymentStatusPending, DeploymentStatusBlocked:
 		return true
 	default:
 		return false
@@ -6913,6 +7927,20 @@ func (d *Deployment) RequiresPromotion() bool {
 	return false
 }
 
+// HasAutoPromote returns whether all the task groups of the deployment that
+// have canaries are promoted automatically
+func (d *Deployment) HasAutoPromote() bool {
+	if d == nil || len(d.TaskGroups) == 0 || d.Status != DeploymentStatusRunning {
+		return false
+	}
+	for _, group := range d.TaskGroups {
+		if group.DesiredCanaries > 0 && !group.AutoPromote {
+			return false
+		}
+	}
+	return true
+}
+
 func (d *Deployment) GoString() string {
 	base := fmt.Sprintf("Deployment ID %q for job %q has status %q (%v):", d.ID, d.JobID, d.Status, d.StatusDescription)
 	for group, state := range d.TaskGroups {
@@ -6927,6 +7955,14 @@ type DeploymentState struct {
 	// reverted on failure
 	AutoRevert bool
 
+	// AutoPromote marks whether the task group has indicated the deployment
+	// should be promoted once its canaries are healthy
+	AutoPromote bool
+
+	// ManualPromotionPerGroup marks whether the canaries of the task group
+	// are only promoted when the group is promoted by name
+	ManualPromotionPerGroup bool
+
 	// ProgressDeadline is the deadline by which an allocation must transition
 	// to healthy before the deployment is considered failed.
 	ProgressDeadline time.Duration
@@ -6956,6 +7992,10 @@ type DeploymentState struct {
 
 	// UnhealthyAllocs are allocations that have been marked as unhealthy.
 	UnhealthyAllocs int
+
+	// HealthOverrides is the audit trail of operators marking the task
+	// group's allocations healthy.
+	HealthOverrides []*DeploymentHealthOverride
 }
 
 func (d *DeploymentState) GoString() string {
@@ -6967,6 +8007,8 @@ func (d *DeploymentState) GoString() string {
 	base += fmt.Sprintf("\n\tHealthy: %d", d.HealthyAllocs)
 	base += fmt.Sprintf("\n\tUnhealthy: %d", d.UnhealthyAllocs)
 	base += fmt.Sprintf("\n\tAutoRevert: %v", d.AutoRevert)
+	base += fmt.Sprintf("\n\tAutoPromote: %v", d.AutoPromote)
+	base += fmt.Sprintf("\n\tManualPromotionPerGroup: %v", d.ManualPromotionPerGroup)
 	return base
 }
 
@@ -6974,6 +8016,39 @@ func (d *DeploymentState) Copy() *DeploymentState {
 	c := &DeploymentState{}
 	*c = *d
 	c.PlacedCanaries = helper.CopySliceString(d.PlacedCanaries)
+	if d.HealthOverrides != nil {
+		c.HealthOverrides = make([]*DeploymentHealthOverride, len(d.HealthOverrides))
+		for i, o := range d.HealthOverrides {
+			c.HealthOverrides[i] = o.Copy()
+		}
+	}
+	return c
+}
+
+// DeploymentHealthOverride records that the allocations of a task group were
+// manually marked healthy.
+type DeploymentHealthOverride struct {
+	// Reason is the operator supplied reason for the override
+	Reason string
+
+	// AccessorID is the accessor ID of the ACL token used to make the
+	// request. It is empty when ACLs are disabled.
+	AccessorID string
+
+	// AllocationIDs are the allocations that were marked healthy
+	AllocationIDs []string
+
+	// Timestamp is when the override was applied
+	Timestamp time.Time
+}
+
+func (o *DeploymentHealthOverride) Copy() *DeploymentHealthOverride {
+	if o == nil {
+		return nil
+	}
+	c := new(DeploymentHealthOverride)
+	*c = *o
+	c.AllocationIDs = helper.CopySliceString(o.AllocationIDs)
 	return c
 }
 
@@ -7461,6 +8536,75 @@ func (a *Allocation) NextDelay() time.Duration {
 	return delayDur
 }
 
+// AllocRescheduleStatus describes when a failed allocation is rescheduled
+// according to the reschedule policy of its task group.
+type AllocRescheduleStatus struct {
+	AllocID string
+
+	// NextAllocation is the ID of the replacement allocation, if the
+	// allocation has already been rescheduled.
+	NextAllocation string
+
+	// Eligible is whether the reschedule policy allows the allocation to be
+	// rescheduled.
+	Eligible bool
+
+	// NextRescheduleTime is the time on or after which the allocation is
+	// rescheduled, and Delay the delay since its failure that it is computed
+	// from. Both are zero if the allocation isn't waiting to be rescheduled.
+	NextRescheduleTime time.Time
+	Delay              time.Duration
+
+	// Attempted is the number of reschedule attempts made within the
+	// interval of the policy and Attempts the number of attempts it allows.
+	Attempted int
+	Attempts  int
+	Unlimited bool
+
+	// FollowupEvalID is the evaluation that will reschedule the allocation.
+	FollowupEvalID string
+
+	// ForceReschedule is whether the allocation has been marked to be
+	// rescheduled immediately.
+	ForceReschedule bool
+}
+
+// RescheduleStatus returns when the allocation is rescheduled.
+func (a *Allocation) RescheduleStatus() *AllocRescheduleStatus {
+	status := &AllocRescheduleStatus{
+		AllocID:         a.ID,
+		NextAllocation:  a.NextAllocation,
+		FollowupEvalID:  a.FollowupEvalID,
+		ForceReschedule: a.DesiredTransition.ShouldForceReschedule(),
+	}
+	if a.Job == nil {
+		return status
+	}
+	policy := a.ReschedulePolicy()
+	if policy == nil {
+		return status
+	}
+	status.Attempts = policy.Attempts
+	status.Unlimited = policy.Unlimited
+
+	failTime := a.LastEventTime()
+	if a.RescheduleTracker != nil && policy.Interval > 0 {
+		for _, event := range a.RescheduleTracker.Events {
+			if failTime.UTC().UnixNano()-event.RescheduleTime < policy.Interval.Nanoseconds() {
+				status.Attempted++
+			}
+		}
+	}
+
+	if a.NextAllocation == "" {
+		status.NextRescheduleTime, status.Eligible = a.NextRescheduleTime()
+		if !status.NextRescheduleTime.IsZero() {
+			status.Delay = status.NextRescheduleTime.Sub(failTime)
+		}
+	}
+	return status
+}
+
 // Terminated returns if the allocation is in a terminal state on a client.
 func (a *Allocation) Terminated() bool {
 	if a.ClientStatus == AllocClientStatusFailed ||
@@ -7581,8 +8725,8 @@ func (a *Allocation) LookupTask(name string) *Task {
 }
 
 // Stub returns a list stub for the allocation
-func (a *Allocation) Stub() *AllocListStub {
-	return &AllocListStub{
+func (a *Allocation) Stub(fields *AllocStubFields) *AllocListStub {
+	s := &AllocListStub{
 		ID:                 a.ID,
 		EvalID:             a.EvalID,
 		Name:               a.Name,
@@ -7606,6 +8750,18 @@ func (a *Allocation) Stub() *AllocListStub {
 		CreateTime:         a.CreateTime,
 		ModifyTime:         a.ModifyTime,
 	}
+
+	if fields != nil && fields.Resources {
+		s.AllocatedResources = a.AllocatedResources
+	}
+
+	return s
+}
+
+// AllocStubFields selects the optional fields of allocation stubs.
+type AllocStubFields struct {
+	// Resources includes the resources allocated to the allocations.
+	Resources bool
 }
 
 // AllocListStub is used to return a subset of alloc information
@@ -7628,6 +8784,7 @@ type AllocListStub struct {
 	DeploymentStatus   *AllocDeploymentStatus
 	FollowupEvalID     string
 	RescheduleTracker  *RescheduleTracker
+	AllocatedResources *AllocatedResources `json:",omitempty"`
 	CreateIndex        uint64
 	ModifyIndex        uint64
 	CreateTime         int64
@@ -7936,6 +9093,8 @@ const (
 	EvalTriggerRetryFailedAlloc  = "alloc-failure"
 	EvalTriggerQueuedAllocs      = "queued-allocs"
 	EvalTriggerPreemption        = "preemption"
+	EvalTriggerAllocStop         = "alloc-stop"
+	EvalTriggerAllocReschedule   = "alloc-reschedule"
 )
 
 const (
@@ -7961,6 +9120,16 @@ const (
 	// check if they are terminal. If so, we delete these out of the system.
 	CoreJobDeploymentGC = "deployment-gc"
 
+	// CoreJobExpiredACLTokenGC is used for the garbage collection of expired
+	// ACL tokens. We periodically scan the tokens and delete those whose
+	// expiration time has passed.
+	CoreJobExpiredACLTokenGC = "expired-acl-token-gc"
+
+	// CoreJobCSIVolumeClaimGC is used for the garbage collection of CSI
+	// volume claims. We periodically scan the volumes for claims held by
+	// terminal allocations and release them, detaching the volumes.
+	CoreJobCSIVolumeClaimGC = "csi-volume-claim-gc"
+
 	// CoreJobForceGC is used to force garbage collection of all GCable objects.
 	CoreJobForceGC = "force-gc"
 )
@@ -8726,44 +9895,175 @@ type ACLPolicyUpsertRequest struct {
 	WriteRequest
 }
 
-// ACLToken represents a client token which is used to Authenticate
-type ACLToken struct {
-	AccessorID  string   // Public Accessor ID (UUID)
-	SecretID    string   // Secret ID, private (UUID)
-	Name        string   // Human friendly name
-	Type        string   // Client or Management
-	Policies    []string // Policies this token ties to
-	Global      bool     // Global or Region local
+// ACLRole is a named set of policies that tokens can be associated with
+type ACLRole struct {
+	Name        string   // Unique name
+	Description string   // Human readable
+	Policies    []string // Policies this role grants
 	Hash        []byte
-	CreateTime  time.Time // Time of creation
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
-var (
-	// AnonymousACLToken is used no SecretID is provided, and the
-	// request is made anonymously.
-	AnonymousACLToken = &ACLToken{
-		AccessorID: "anonymous",
-		Name:       "Anonymous Token",
-		Type:       ACLClientToken,
-		Policies:   []string{"anonymous"},
-		Global:     false,
+// SetHash is used to compute and set the hash of the ACL role
+func (r *ACLRole) SetHash() []byte {
+	// Initialize a 256bit Blake2 hash (32 bytes)
+	hash, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
 	}
-)
 
-type ACLTokenListStub struct {
-	AccessorID  string
-	Name        string
-	Type        string
-	Policies    []string
-	Global      bool
-	Hash        []byte
-	CreateTime  time.Time
+	// Write all the user set fields
+	hash.Write([]byte(r.Name))
+	hash.Write([]byte(r.Description))
+	for _, policyName := range r.Policies {
+		hash.Write([]byte(policyName))
+	}
+
+	// Finalize the hash
+	hashVal := hash.Sum(nil)
+
+	// Set and return the hash
+	r.Hash = hashVal
+	return hashVal
+}
+
+func (r *ACLRole) Stub() *ACLRoleListStub {
+	return &ACLRoleListStub{
+		Name:        r.Name,
+		Description: r.Description,
+		Policies:    r.Policies,
+		Hash:        r.Hash,
+		CreateIndex: r.CreateIndex,
+		ModifyIndex: r.ModifyIndex,
+	}
+}
+
+func (r *ACLRole) Validate() error {
+	var mErr multierror.Error
+	if !validPolicyName.MatchString(r.Name) {
+		err := fmt.Errorf("invalid name '%s'", r.Name)
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	if len(r.Policies) == 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("role missing policies"))
+	}
+	if len(r.Description) > maxPolicyDescriptionLength {
+		err := fmt.Errorf("description longer than %d", maxPolicyDescriptionLength)
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ACLRoleListStub is used to for listing ACL roles
+type ACLRoleListStub struct {
+	Name        string
+	Description string
+	Policies    []string
+	Hash        []byte
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
+// ACLRoleListRequest is used to request a list of roles
+type ACLRoleListRequest struct {
+	QueryOptions
+}
+
+// ACLRoleSpecificRequest is used to query a specific role
+type ACLRoleSpecificRequest struct {
+	Name string
+	QueryOptions
+}
+
+// ACLRoleSetRequest is used to query a set of roles
+type ACLRoleSetRequest struct {
+	Names []string
+	QueryOptions
+}
+
+// ACLRoleListResponse is used for a list request
+type ACLRoleListResponse struct {
+	Roles []*ACLRoleListStub
+	QueryMeta
+}
+
+// SingleACLRoleResponse is used to return a single role
+type SingleACLRoleResponse struct {
+	Role *ACLRole
+	QueryMeta
+}
+
+// ACLRoleSetResponse is used to return a set of roles
+type ACLRoleSetResponse struct {
+	Roles map[string]*ACLRole
+	QueryMeta
+}
+
+// ACLRoleDeleteRequest is used to delete a set of roles
+type ACLRoleDeleteRequest struct {
+	Names []string
+	WriteRequest
+}
+
+// ACLRoleUpsertRequest is used to upsert a set of roles
+type ACLRoleUpsertRequest struct {
+	Roles []*ACLRole
+	WriteRequest
+}
+
+// ACLToken represents a client token which is used to Authenticate
+type ACLToken struct {
+	AccessorID string   // Public Accessor ID (UUID)
+	SecretID   string   // Secret ID, private (UUID)
+	Name       string   // Human friendly name
+	Type       string   // Client or Management
+	Policies   []string // Policies this token ties to
+	Roles      []string // Roles this token ties to
+	Global     bool     // Global or Region local
+	AuthMethod string   // Auth method that created the token, if any
+	Hash       []byte
+	CreateTime time.Time // Time of creation
+
+	// ExpirationTime is the time after which the token is no longer valid
+	// and is garbage collected. It is nil for tokens that do not expire.
+	ExpirationTime *time.Time
+
+	// ExpirationTTL is used when creating a token to set its expiration
+	// time relative to its creation time.
+	ExpirationTTL time.Duration
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+var (
+	// AnonymousACLToken is used no SecretID is provided, and the
+	// request is made anonymously.
+	AnonymousACLToken = &ACLToken{
+		AccessorID: "anonymous",
+		Name:       "Anonymous Token",
+		Type:       ACLClientToken,
+		Policies:   []string{"anonymous"},
+		Global:     false,
+	}
+)
+
+type ACLTokenListStub struct {
+	AccessorID     string
+	Name           string
+	Type           string
+	Policies       []string
+	Roles          []string
+	Global         bool
+	AuthMethod     string
+	Hash           []byte
+	CreateTime     time.Time
+	ExpirationTime *time.Time
+	CreateIndex    uint64
+	ModifyIndex    uint64
+}
+
 // SetHash is used to compute and set the hash of the ACL token
 func (a *ACLToken) SetHash() []byte {
 	// Initialize a 256bit Blake2 hash (32 bytes)
@@ -8778,6 +10078,15 @@ func (a *ACLToken) SetHash() []byte {
 	for _, policyName := range a.Policies {
 		hash.Write([]byte(policyName))
 	}
+	for _, roleName := range a.Roles {
+		hash.Write([]byte("role:" + roleName))
+	}
+	if a.ExpirationTime != nil {
+		hash.Write([]byte(a.ExpirationTime.String()))
+	}
+	if a.AuthMethod != "" {
+		hash.Write([]byte("auth-method:" + a.AuthMethod))
+	}
 	if a.Global {
 		hash.Write([]byte("global"))
 	} else {
@@ -8794,15 +10103,18 @@ func (a *ACLToken) SetHash() []byte {
 
 func (a *ACLToken) Stub() *ACLTokenListStub {
 	return &ACLTokenListStub{
-		AccessorID:  a.AccessorID,
-		Name:        a.Name,
-		Type:        a.Type,
-		Policies:    a.Policies,
-		Global:      a.Global,
-		Hash:        a.Hash,
-		CreateTime:  a.CreateTime,
-		CreateIndex: a.CreateIndex,
-		ModifyIndex: a.ModifyIndex,
+		AccessorID:     a.AccessorID,
+		Name:           a.Name,
+		Type:           a.Type,
+		Policies:       a.Policies,
+		Roles:          a.Roles,
+		Global:         a.Global,
+		AuthMethod:     a.AuthMethod,
+		Hash:           a.Hash,
+		CreateTime:     a.CreateTime,
+		ExpirationTime: a.ExpirationTime,
+		CreateIndex:    a.CreateIndex,
+		ModifyIndex:    a.ModifyIndex,
 	}
 }
 
@@ -8814,19 +10126,27 @@ func (a *ACLToken) Validate() error {
 	}
 	switch a.Type {
 	case ACLClientToken:
-		if len(a.Policies) == 0 {
-			mErr.Errors = append(mErr.Errors, fmt.Errorf("client token missing policies"))
+		if len(a.Policies) == 0 && len(a.Roles) == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("client token missing policies or roles"))
 		}
 	case ACLManagementToken:
-		if len(a.Policies) != 0 {
-			mErr.Errors = append(mErr.Errors, fmt.Errorf("management token cannot be associated with policies"))
+		if len(a.Policies) != 0 || len(a.Roles) != 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("management token cannot be associated with policies or roles"))
 		}
 	default:
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("token type must be client or management"))
 	}
+	if a.ExpirationTTL < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("token expiration TTL must not be negative"))
+	}
 	return mErr.ErrorOrNil()
 }
 
+// IsExpired returns whether the token has expired at the given time
+func (a *ACLToken) IsExpired(t time.Time) bool {
+	return a.ExpirationTime != nil && !t.Before(*a.ExpirationTime)
+}
+
 // PolicySubset checks if a given set of policies is a subset of the token
 func (a *ACLToken) PolicySubset(policies []string) bool {
 	// Hot-path the management tokens, superset of all policies.
@@ -8845,6 +10165,24 @@ func (a *ACLToken) PolicySubset(policies []string) bool {
 	return true
 }
 
+// RoleSubset checks if a given set of roles is a subset of the token
+func (a *ACLToken) RoleSubset(roles []string) bool {
+	// Hot-path the management tokens, superset of all roles.
+	if a.Type == ACLManagementToken {
+		return true
+	}
+	associatedRoles := make(map[string]struct{}, len(a.Roles))
+	for _, role := range a.Roles {
+		associatedRoles[role] = struct{}{}
+	}
+	for _, role := range roles {
+		if _, ok := associatedRoles[role]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // ACLTokenListRequest is used to request a list of tokens
 type ACLTokenListRequest struct {
 	GlobalOnly bool
@@ -8893,6 +10231,19 @@ type ResolveACLTokenResponse struct {
 	QueryMeta
 }
 
+// ACLTokenCapabilitiesRequest is used to get the capabilities granted to the
+// token of the request
+type ACLTokenCapabilitiesRequest struct {
+	QueryOptions
+}
+
+// ACLTokenCapabilitiesResponse is used to return the capabilities granted to
+// a token
+type ACLTokenCapabilitiesResponse struct {
+	Capabilities *acl.Capabilities
+	QueryMeta
+}
+
 // ACLTokenDeleteRequest is used to delete a set of tokens
 type ACLTokenDeleteRequest struct {
 	AccessorIDs []string
@@ -8917,3 +10268,435 @@ type ACLTokenUpsertResponse struct {
 	Tokens []*ACLToken
 	WriteMeta
 }
+
+// ACLAuthMethod is used to exchange a JWT issued by a trusted identity
+// provider for a Nomad ACL token. The policies and roles granted to the
+// token are determined by the binding rules of the auth method.
+type ACLAuthMethod struct {
+	Name          string               // Unique name
+	Type          string               // JWT or OIDC
+	TokenLocality string               // local or global
+	MaxTokenTTL   time.Duration        // Lifetime of created tokens
+	Config        *ACLAuthMethodConfig // How login tokens are validated
+	Hash          []byte
+	CreateIndex   uint64
+	ModifyIndex   uint64
+}
+
+// ACLAuthMethodConfig describes how the JWTs presented to an auth method are
+// validated and which of their claims are made available to binding rules.
+type ACLAuthMethodConfig struct {
+	// JWTValidationPubKeys is a list of PEM encoded public keys used to
+	// verify JWT signatures.
+	JWTValidationPubKeys []string
+
+	// JWKSURL is the URL of a JSON Web Key Set used to verify JWT
+	// signatures, along with an optional PEM encoded CA certificate.
+	JWKSURL    string
+	JWKSCACert string
+
+	// OIDCDiscoveryURL is the issuer URL of an OpenID Connect provider. The
+	// provider's discovery document is used to locate its signing keys,
+	// along with an optional PEM encoded CA certificate.
+	OIDCDiscoveryURL    string
+	OIDCDiscoveryCACert string
+
+	// BoundAudiences and BoundIssuer restrict the "aud" and "iss" claims
+	// that are accepted.
+	BoundAudiences []string
+	BoundIssuer    []string
+
+	// SigningAlgs is the list of accepted signing algorithms. It defaults
+	// to RS256.
+	SigningAlgs []string
+
+	// ClaimMappings and ListClaimMappings map claims to the names they are
+	// available under in binding rule selectors, as "value.<name>" and
+	// "list.<name>" respectively.
+	ClaimMappings     map[string]string
+	ListClaimMappings map[string]string
+
+	// ClockSkewLeeway is the allowed clock skew when validating the time
+	// based claims of a JWT.
+	ClockSkewLeeway time.Duration
+}
+
+// SetHash is used to compute and set the hash of the ACL auth method
+func (a *ACLAuthMethod) SetHash() []byte {
+	// Initialize a 256bit Blake2 hash (32 bytes)
+	hash, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Write all the user set fields
+	hash.Write([]byte(a.Name))
+	hash.Write([]byte(a.Type))
+	hash.Write([]byte(a.TokenLocality))
+	hash.Write([]byte(a.MaxTokenTTL.String()))
+	if c := a.Config; c != nil {
+		for _, key := range c.JWTValidationPubKeys {
+			hash.Write([]byte(key))
+		}
+		hash.Write([]byte(c.JWKSURL))
+		hash.Write([]byte(c.JWKSCACert))
+		hash.Write([]byte(c.OIDCDiscoveryURL))
+		hash.Write([]byte(c.OIDCDiscoveryCACert))
+		for _, aud := range c.BoundAudiences {
+			hash.Write([]byte("aud:" + aud))
+		}
+		for _, iss := range c.BoundIssuer {
+			hash.Write([]byte("iss:" + iss))
+		}
+		for _, alg := range c.SigningAlgs {
+			hash.Write([]byte("alg:" + alg))
+		}
+		for _, k := range sortedKeys(c.ClaimMappings) {
+			hash.Write([]byte("claim:" + k + "=" + c.ClaimMappings[k]))
+		}
+		for _, k := range sortedKeys(c.ListClaimMappings) {
+			hash.Write([]byte("list:" + k + "=" + c.ListClaimMappings[k]))
+		}
+		hash.Write([]byte(c.ClockSkewLeeway.String()))
+	}
+
+	// Finalize the hash
+	hashVal := hash.Sum(nil)
+
+	// Set and return the hash
+	a.Hash = hashVal
+	return hashVal
+}
+
+// sortedKeys returns the keys of the map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (a *ACLAuthMethod) Stub() *ACLAuthMethodListStub {
+	return &ACLAuthMethodListStub{
+		Name:          a.Name,
+		Type:          a.Type,
+		TokenLocality: a.TokenLocality,
+		MaxTokenTTL:   a.MaxTokenTTL,
+		Hash:          a.Hash,
+		CreateIndex:   a.CreateIndex,
+		ModifyIndex:   a.ModifyIndex,
+	}
+}
+
+// TokenGlobal returns whether tokens created by the auth method are global
+func (a *ACLAuthMethod) TokenGlobal() bool {
+	return a.TokenLocality == ACLAuthMethodTokenLocalityGlobal
+}
+
+// Validate is used to sanity check an auth method. The bounds of MaxTokenTTL
+// depend on server configuration and are checked by the ACL endpoint.
+func (a *ACLAuthMethod) Validate() error {
+	var mErr multierror.Error
+	if !validPolicyName.MatchString(a.Name) {
+		err := fmt.Errorf("invalid name '%s'", a.Name)
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	switch a.Type {
+	case ACLAuthMethodTypeJWT, ACLAuthMethodTypeOIDC:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("auth method type must be %s or %s",
+			ACLAuthMethodTypeJWT, ACLAuthMethodTypeOIDC))
+	}
+	switch a.TokenLocality {
+	case ACLAuthMethodTokenLocalityLocal, ACLAuthMethodTokenLocalityGlobal:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("token locality must be %s or %s",
+			ACLAuthMethodTokenLocalityLocal, ACLAuthMethodTokenLocalityGlobal))
+	}
+	if a.MaxTokenTTL <= 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("max token TTL must be positive"))
+	}
+
+	c := a.Config
+	if c == nil {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing config"))
+		return mErr.ErrorOrNil()
+	}
+
+	keySources := 0
+	if len(c.JWTValidationPubKeys) != 0 {
+		keySources++
+	}
+	if c.JWKSURL != "" {
+		keySources++
+	}
+	if c.OIDCDiscoveryURL != "" {
+		keySources++
+	}
+	switch {
+	case a.Type == ACLAuthMethodTypeOIDC && (c.OIDCDiscoveryURL == "" || keySources != 1):
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("OIDC auth method must only set OIDCDiscoveryURL"))
+	case keySources != 1:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf(
+			"exactly one of JWTValidationPubKeys, JWKSURL or OIDCDiscoveryURL must be set"))
+	}
+	if a.Type == ACLAuthMethodTypeOIDC && len(c.BoundAudiences) == 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("OIDC auth method must set BoundAudiences"))
+	}
+	for _, u := range []string{c.JWKSURL, c.OIDCDiscoveryURL} {
+		if u == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid URL %q", u))
+		}
+	}
+	if c.JWKSCACert != "" && c.JWKSURL == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("JWKSCACert requires JWKSURL"))
+	}
+	if c.OIDCDiscoveryCACert != "" && c.OIDCDiscoveryURL == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("OIDCDiscoveryCACert requires OIDCDiscoveryURL"))
+	}
+	if c.ClockSkewLeeway < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("clock skew leeway must not be negative"))
+	}
+
+	// Mapped names must be unique across both kinds of mapping since they
+	// are used to build selector and bind name variables.
+	mapped := make(map[string]struct{})
+	for _, mappings := range []map[string]string{c.ClaimMappings, c.ListClaimMappings} {
+		for claim, name := range mappings {
+			if claim == "" || !validClaimMappingName.MatchString(name) {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid claim mapping %q => %q", claim, name))
+				continue
+			}
+			if _, ok := mapped[name]; ok {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("claim mapping name %q used more than once", name))
+			}
+			mapped[name] = struct{}{}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ACLAuthMethodListStub is used to for listing ACL auth methods
+type ACLAuthMethodListStub struct {
+	Name          string
+	Type          string
+	TokenLocality string
+	MaxTokenTTL   time.Duration
+	Hash          []byte
+	CreateIndex   uint64
+	ModifyIndex   uint64
+}
+
+// ACLAuthMethodListRequest is used to request a list of auth methods
+type ACLAuthMethodListRequest struct {
+	QueryOptions
+}
+
+// ACLAuthMethodSpecificRequest is used to query a specific auth method
+type ACLAuthMethodSpecificRequest struct {
+	Name string
+	QueryOptions
+}
+
+// ACLAuthMethodSetRequest is used to query a set of auth methods
+type ACLAuthMethodSetRequest struct {
+	Names []string
+	QueryOptions
+}
+
+// ACLAuthMethodListResponse is used for a list request
+type ACLAuthMethodListResponse struct {
+	AuthMethods []*ACLAuthMethodListStub
+	QueryMeta
+}
+
+// SingleACLAuthMethodResponse is used to return a single auth method
+type SingleACLAuthMethodResponse struct {
+	AuthMethod *ACLAuthMethod
+	QueryMeta
+}
+
+// ACLAuthMethodSetResponse is used to return a set of auth methods
+type ACLAuthMethodSetResponse struct {
+	AuthMethods map[string]*ACLAuthMethod
+	QueryMeta
+}
+
+// ACLAuthMethodDeleteRequest is used to delete a set of auth methods
+type ACLAuthMethodDeleteRequest struct {
+	Names []string
+	WriteRequest
+}
+
+// ACLAuthMethodUpsertRequest is used to upsert a set of auth methods
+type ACLAuthMethodUpsertRequest struct {
+	AuthMethods []*ACLAuthMethod
+	WriteRequest
+}
+
+// ACLBindingRule grants a policy or role to the tokens created by an auth
+// method when the claims of the login token match its selector.
+type ACLBindingRule struct {
+	ID          string // Unique ID (UUID)
+	Description string // Human readable
+	AuthMethod  string // Auth method the rule applies to
+	Selector    string // Expression matched against the mapped claims
+	BindType    string // policy or role
+	BindName    string // Name of the policy or role, may reference claims
+	Hash        []byte
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// SetHash is used to compute and set the hash of the ACL binding rule
+func (r *ACLBindingRule) SetHash() []byte {
+	// Initialize a 256bit Blake2 hash (32 bytes)
+	hash, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Write all the user set fields
+	hash.Write([]byte(r.ID))
+	hash.Write([]byte(r.Description))
+	hash.Write([]byte(r.AuthMethod))
+	hash.Write([]byte(r.Selector))
+	hash.Write([]byte(r.BindType))
+	hash.Write([]byte(r.BindName))
+
+	// Finalize the hash
+	hashVal := hash.Sum(nil)
+
+	// Set and return the hash
+	r.Hash = hashVal
+	return hashVal
+}
+
+func (r *ACLBindingRule) Stub() *ACLBindingRuleListStub {
+	return &ACLBindingRuleListStub{
+		ID:          r.ID,
+		Description: r.Description,
+		AuthMethod:  r.AuthMethod,
+		BindType:    r.BindType,
+		BindName:    r.BindName,
+		Hash:        r.Hash,
+		CreateIndex: r.CreateIndex,
+		ModifyIndex: r.ModifyIndex,
+	}
+}
+
+// Validate is used to sanity check a binding rule. The selector is compiled
+// by the ACL endpoint since its syntax is defined by the auth package.
+func (r *ACLBindingRule) Validate() error {
+	var mErr multierror.Error
+	if r.AuthMethod == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing auth method"))
+	}
+	switch r.BindType {
+	case ACLBindingRuleBindTypePolicy, ACLBindingRuleBindTypeRole:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("bind type must be %s or %s",
+			ACLBindingRuleBindTypePolicy, ACLBindingRuleBindTypeRole))
+	}
+	if r.BindName == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing bind name"))
+	}
+	if len(r.Description) > maxPolicyDescriptionLength {
+		err := fmt.Errorf("description longer than %d", maxPolicyDescriptionLength)
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ValidACLBindName returns whether the interpolated bind name of a binding
+// rule is a valid policy or role name
+func ValidACLBindName(name string) bool {
+	return validPolicyName.MatchString(name)
+}
+
+// ACLBindingRuleListStub is used to for listing ACL binding rules
+type ACLBindingRuleListStub struct {
+	ID          string
+	Description string
+	AuthMethod  string
+	BindType    string
+	BindName    string
+	Hash        []byte
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLBindingRuleListRequest is used to request a list of binding rules,
+// optionally limited to a single auth method
+type ACLBindingRuleListRequest struct {
+	AuthMethod string
+	QueryOptions
+}
+
+// ACLBindingRuleSpecificRequest is used to query a specific binding rule
+type ACLBindingRuleSpecificRequest struct {
+	RuleID string
+	QueryOptions
+}
+
+// ACLBindingRuleSetRequest is used to query a set of binding rules
+type ACLBindingRuleSetRequest struct {
+	RuleIDs []string
+	QueryOptions
+}
+
+// ACLBindingRuleListResponse is used for a list request
+type ACLBindingRuleListResponse struct {
+	Rules []*ACLBindingRuleListStub
+	QueryMeta
+}
+
+// SingleACLBindingRuleResponse is used to return a single binding rule
+type SingleACLBindingRuleResponse struct {
+	Rule *ACLBindingRule
+	QueryMeta
+}
+
+// ACLBindingRuleSetResponse is used to return a set of binding rules
+type ACLBindingRuleSetResponse struct {
+	Rules map[string]*ACLBindingRule
+	QueryMeta
+}
+
+// ACLBindingRuleDeleteRequest is used to delete a set of binding rules
+type ACLBindingRuleDeleteRequest struct {
+	RuleIDs []string
+	WriteRequest
+}
+
+// ACLBindingRuleUpsertRequest is used to upsert a set of binding rules
+type ACLBindingRuleUpsertRequest struct {
+	Rules []*ACLBindingRule
+	WriteRequest
+}
+
+// ACLBindingRuleUpsertResponse is used to return from an
+// ACLBindingRuleUpsertRequest
+type ACLBindingRuleUpsertResponse struct {
+	Rules []*ACLBindingRule
+	WriteMeta
+}
+
+// ACLLoginRequest is used to exchange a JWT for an ACL token using an auth
+// method
+type ACLLoginRequest struct {
+	AuthMethod string
+	LoginToken string
+	WriteRequest
+}
+
+// ACLLoginResponse is used to return from an ACLLoginRequest
+type ACLLoginResponse struct {
+	Token *ACLToken
+	WriteMeta
+}
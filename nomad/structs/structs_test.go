@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -133,6 +134,22 @@ func TestJob_Warnings(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:     "System job ignores the count for update stanza",
+			Expected: []string{},
+			Job: &Job{
+				Type: JobTypeSystem,
+				TaskGroups: []*TaskGroup{
+					{
+						Name:  "foo",
+						Count: 1,
+						Update: &UpdateStrategy{
+							MaxParallel: 10,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -147,6 +164,9 @@ func TestJob_Warnings(t *testing.T) {
 			}
 
 			a := warnings.Error()
+			if len(c.Expected) == 0 {
+				t.Fatalf("Got unexpected warnings %q", a)
+			}
 			for _, e := range c.Expected {
 				if !strings.Contains(a, e) {
 					t.Fatalf("Got warnings %q; didn't contain %q", a, e)
@@ -164,6 +184,7 @@ func TestJob_SpecChanged(t *testing.T) {
 	mutatedBase := base.Copy()
 	mutatedBase.Status = "foo"
 	mutatedBase.ModifyIndex = base.ModifyIndex + 100
+	mutatedBase.VersionTag = &JobVersionTag{Name: "golden"}
 
 	// changed contains a spec change that should be detected
 	change := base.Copy()
@@ -293,6 +314,28 @@ func TestJob_Copy(t *testing.T) {
 	}
 }
 
+func TestJobVersionTag_Validate(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError((&JobVersionTag{Name: "golden", Description: "known good"}).Validate())
+
+	err := (&JobVersionTag{}).Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "Missing tag name")
+
+	err = (&JobVersionTag{Name: "12"}).Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "can't be a version number")
+
+	err = (&JobVersionTag{Name: strings.Repeat("a", maxJobVersionTagNameLength+1)}).Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "Tag name longer")
+
+	err = (&JobVersionTag{Name: "golden", Description: strings.Repeat("a", maxJobVersionTagDescriptionLength+1)}).Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "Tag description longer")
+}
+
 func TestJob_IsPeriodic(t *testing.T) {
 	j := &Job{
 		Type: JobTypeService,
@@ -751,6 +794,13 @@ func TestTaskGroup_Validate(t *testing.T) {
 		t.Fatalf("err: %s", err)
 	}
 
+	tg.Update.HealthCheck = UpdateStrategyHealthCheck_Manual
+	j.Type = JobTypeSystem
+	err = tg.Validate(j)
+	if !strings.Contains(err.Error(), "System jobs do not support \"manual\" health checks") {
+		t.Fatalf("err: %s", err)
+	}
+
 	tg = &TaskGroup{
 		Count: -1,
 		RestartPolicy: &RestartPolicy{
@@ -1361,6 +1411,34 @@ func TestTask_Validate_LogConfig(t *testing.T) {
 	}
 }
 
+func TestTask_Validate_LogConfig_Sink(t *testing.T) {
+	require := require.New(t)
+
+	l := DefaultLogConfig()
+	l.DisableFiles = true
+	err := l.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "only be disabled when a sink is set")
+
+	l = DefaultLogConfig()
+	l.SinkOptions = map[string]string{"tag": "web"}
+	err = l.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "sink options require a sink")
+
+	// Log files do not count against the disk when disabled
+	l = DefaultLogConfig()
+	l.Sink = "syslog"
+	l.DisableFiles = true
+	require.NoError(l.Validate())
+
+	task := &Task{
+		LogConfig: l,
+	}
+	err = task.Validate(&EphemeralDisk{SizeMB: 1}, JobTypeService)
+	require.NotContains(err.Error(), "log storage")
+}
+
 func TestTask_Validate_Template(t *testing.T) {
 
 	bad := &Template{}
@@ -1726,6 +1804,17 @@ func TestUpdateStrategy_Validate(t *testing.T) {
 	}
 }
 
+func TestUpdateStrategy_Validate_ManualPromotionPerGroup(t *testing.T) {
+	u := DefaultUpdateStrategy.Copy()
+	u.ManualPromotionPerGroup = true
+	require.NoError(t, u.Validate())
+
+	u.AutoPromote = true
+	err := u.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Auto promote can not be used with manual promotion per group")
+}
+
 func TestResource_NetIndex(t *testing.T) {
 	r := &Resources{
 		Networks: []*NetworkResource{
@@ -2137,6 +2226,75 @@ func TestService_Canonicalize(t *testing.T) {
 
 }
 
+func TestService_Validate_Provider(t *testing.T) {
+	s := &Service{
+		Name:      "web",
+		PortLabel: "http",
+		Provider:  ServiceProviderNomad,
+	}
+	require.NoError(t, s.Validate())
+	require.True(t, s.UsesNomadProvider())
+
+	// Checks are only supported by Consul
+	s.Checks = []*ServiceCheck{{
+		Name:     "check",
+		Type:     ServiceCheckTCP,
+		Interval: time.Second,
+		Timeout:  time.Second,
+	}}
+	err := s.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not support checks")
+
+	s.Provider = ServiceProviderConsul
+	require.NoError(t, s.Validate())
+	require.False(t, s.UsesNomadProvider())
+
+	s.Provider = "etcd"
+	err = s.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "service provider must be")
+}
+
+func TestService_Hash_Provider(t *testing.T) {
+	s := &Service{
+		Name:      "web",
+		PortLabel: "http",
+	}
+	consul := s.Hash("alloc", "task", false)
+
+	// Setting the default provider keeps existing IDs stable
+	s.Provider = ServiceProviderConsul
+	require.Equal(t, consul, s.Hash("alloc", "task", false))
+
+	s.Provider = ServiceProviderNomad
+	require.NotEqual(t, consul, s.Hash("alloc", "task", false))
+}
+
+func TestServiceRegistration_Validate(t *testing.T) {
+	s := &ServiceRegistration{
+		ID:          "_nomad-task-abc",
+		ServiceName: "web",
+		Namespace:   DefaultNamespace,
+		NodeID:      "node",
+		AllocID:     "alloc",
+		Port:        8080,
+	}
+	require.NoError(t, s.Validate())
+
+	s.ServiceName = ""
+	s.Port = -1
+	err := s.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing service name")
+	require.Contains(t, err.Error(), "invalid port")
+
+	c := s.Copy()
+	require.True(t, c.Equals(s))
+	c.Tags = []string{"http"}
+	require.False(t, c.Equals(s))
+}
+
 func TestJob_ExpandServiceNames(t *testing.T) {
 	j := &Job{
 		Name: "my-job",
@@ -2284,6 +2442,32 @@ func TestPeriodicConfig_ValidTimeZone(t *testing.T) {
 	}
 }
 
+func TestPeriodicConfig_OverlapPolicy(t *testing.T) {
+	require := require.New(t)
+
+	p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "* * * * *"}
+	require.NoError(p.Validate())
+	require.Empty(p.GetOverlapPolicy())
+
+	// Prohibiting overlap skips launches
+	p.ProhibitOverlap = true
+	require.NoError(p.Validate())
+	require.Equal(PeriodicOverlapSkip, p.GetOverlapPolicy())
+
+	// Other policies can't be combined with prohibiting overlap
+	p.OverlapPolicy = PeriodicOverlapEnqueue
+	require.Error(p.Validate())
+
+	p.ProhibitOverlap = false
+	require.NoError(p.Validate())
+	require.Equal(PeriodicOverlapEnqueue, p.GetOverlapPolicy())
+
+	p.OverlapPolicy = "foo"
+	err := p.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "Unknown overlap policy")
+}
+
 func TestPeriodicConfig_DST(t *testing.T) {
 	require := require.New(t)
 
@@ -3477,6 +3661,59 @@ func TestAllocation_NextDelay(t *testing.T) {
 
 }
 
+func TestAllocation_RescheduleStatus(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	now := time.Now()
+	alloc := &Allocation{
+		ID:           "a",
+		TaskGroup:    "web",
+		ClientStatus: AllocClientStatusFailed,
+		TaskStates: map[string]*TaskState{
+			"web": {State: TaskStateDead, FinishedAt: now},
+		},
+		Job: &Job{
+			TaskGroups: []*TaskGroup{
+				{
+					Name: "web",
+					ReschedulePolicy: &ReschedulePolicy{
+						Attempts:      3,
+						Interval:      time.Hour,
+						Delay:         5 * time.Second,
+						DelayFunction: "exponential",
+						MaxDelay:      time.Hour,
+					},
+				},
+			},
+		},
+		RescheduleTracker: &RescheduleTracker{
+			Events: []*RescheduleEvent{
+				{RescheduleTime: now.Add(-2 * time.Hour).UTC().UnixNano(), Delay: 5 * time.Second},
+				{RescheduleTime: now.Add(-time.Minute).UTC().UnixNano(), Delay: 10 * time.Second},
+			},
+		},
+	}
+
+	// Only the attempt within the interval is counted and the delay doubles
+	status := alloc.RescheduleStatus()
+	require.True(status.Eligible)
+	require.Equal(1, status.Attempted)
+	require.Equal(3, status.Attempts)
+	require.Equal(20*time.Second, status.Delay)
+	require.True(now.Add(20 * time.Second).Equal(status.NextRescheduleTime))
+
+	// Replaced allocations aren't waiting to be rescheduled
+	alloc.NextAllocation = "b"
+	alloc.DesiredTransition.ForceReschedule = helper.BoolToPtr(true)
+	status = alloc.RescheduleStatus()
+	require.False(status.Eligible)
+	require.True(status.NextRescheduleTime.IsZero())
+	require.Zero(status.Delay)
+	require.Equal("b", status.NextAllocation)
+	require.True(status.ForceReschedule)
+}
+
 func TestRescheduleTracker_Copy(t *testing.T) {
 	type testCase struct {
 		original *RescheduleTracker
@@ -3549,6 +3786,73 @@ func TestParameterizedJobConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestParameterizedJobConfig_Validate_Types(t *testing.T) {
+	require := require.New(t)
+
+	d := &ParameterizedJobConfig{
+		Payload:       DispatchPayloadForbidden,
+		MetaRequired:  []string{"count"},
+		MetaTypes:     map[string]string{"count": "duration", "other": DispatchMetaTypeInt},
+		PayloadSchema: `{"type": "text"}`,
+	}
+
+	err := d.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), `Unknown type "duration"`)
+	require.Contains(err.Error(), `"other" has a type but is neither required nor optional`)
+	require.Contains(err.Error(), "Invalid payload schema")
+	require.Contains(err.Error(), "payload is forbidden")
+
+	d.Payload = DispatchPayloadRequired
+	d.MetaTypes = map[string]string{"count": DispatchMetaTypeInt}
+	d.PayloadSchema = `{"type": "object"}`
+	require.NoError(d.Validate())
+}
+
+func TestParameterizedJobConfig_ValidateDispatchMeta(t *testing.T) {
+	require := require.New(t)
+
+	d := &ParameterizedJobConfig{
+		MetaOptional: []string{"count", "ratio", "verbose", "name"},
+		MetaTypes: map[string]string{
+			"count":   DispatchMetaTypeInt,
+			"ratio":   DispatchMetaTypeFloat,
+			"verbose": DispatchMetaTypeBool,
+			"name":    DispatchMetaTypeString,
+		},
+	}
+
+	require.NoError(d.ValidateDispatchMeta(map[string]string{
+		"count":   "3",
+		"ratio":   "0.5",
+		"verbose": "true",
+		"name":    "anything",
+	}))
+
+	err := d.ValidateDispatchMeta(map[string]string{
+		"count":   "three",
+		"ratio":   "half",
+		"verbose": "maybe",
+	})
+	require.Error(err)
+	require.Contains(err.Error(), `Meta key "count" must be of type int`)
+	require.Contains(err.Error(), `Meta key "ratio" must be of type float`)
+	require.Contains(err.Error(), `Meta key "verbose" must be of type bool`)
+}
+
+func TestParameterizedJobConfig_ValidateDispatchPayload(t *testing.T) {
+	require := require.New(t)
+
+	d := &ParameterizedJobConfig{}
+	require.NoError(d.ValidateDispatchPayload([]byte("not json")))
+
+	d.PayloadSchema = `{"type": "object", "required": ["name"]}`
+	require.NoError(d.ValidateDispatchPayload([]byte(`{"name": "foo"}`)))
+	err := d.ValidateDispatchPayload([]byte(`{}`))
+	require.Error(err)
+	require.Contains(err.Error(), `missing required property "name"`)
+}
+
 func TestParameterizedJobConfig_Validate_NonBatch(t *testing.T) {
 	job := testJob()
 	job.ParameterizedJob = &ParameterizedJobConfig{
@@ -3569,6 +3873,75 @@ func TestParameterizedJobConfig_Canonicalize(t *testing.T) {
 	}
 }
 
+func TestMultiregion_Validate(t *testing.T) {
+	require := require.New(t)
+
+	m := &Multiregion{
+		Strategy: &MultiregionStrategy{
+			MaxParallel: 1,
+			OnFailure:   MultiregionOnFailureFailLocal,
+		},
+		Regions: []*MultiregionRegion{
+			{Name: "east", Count: 2},
+			{Name: "west", Datacenters: []string{"west-1"}},
+		},
+	}
+	require.NoError(m.Validate())
+
+	m.Strategy.MaxParallel = -1
+	m.Strategy.OnFailure = "bad"
+	m.Regions = append(m.Regions, &MultiregionRegion{Name: "east"}, &MultiregionRegion{})
+	err := m.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "Max parallel")
+	require.Contains(err.Error(), "on_failure")
+	require.Contains(err.Error(), "defined more than once")
+	require.Contains(err.Error(), "missing name")
+}
+
+func TestMultiregion_Validate_NonService(t *testing.T) {
+	job := testJob()
+	job.Type = JobTypeBatch
+	job.Multiregion = &Multiregion{
+		Regions: []*MultiregionRegion{{Name: "east"}},
+	}
+
+	err := job.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Multiregion can only be used with")
+}
+
+func TestMultiregion_RegionalJob(t *testing.T) {
+	require := require.New(t)
+
+	job := testJob()
+	job.Multiregion = &Multiregion{
+		Regions: []*MultiregionRegion{
+			{Name: "east", Count: 3, Datacenters: []string{"east-1"}, Meta: map[string]string{"team": "east"}},
+			{Name: "west"},
+		},
+	}
+
+	east := job.Multiregion.RegionalJob(job, "east")
+	require.Equal("east", east.Region)
+	require.Equal([]string{"east-1"}, east.Datacenters)
+	require.Equal(3, east.TaskGroups[0].Count)
+	require.Equal("east", east.Meta["team"])
+	require.Equal("armon", east.Meta["owner"])
+
+	// Without overrides the job is kept as is
+	west := job.Multiregion.RegionalJob(job, "west")
+	require.Equal("west", west.Region)
+	require.Equal(job.Datacenters, west.Datacenters)
+	require.Equal(job.TaskGroups[0].Count, west.TaskGroups[0].Count)
+
+	// The original job is not modified
+	require.Equal(10, job.TaskGroups[0].Count)
+	require.NotContains(job.Meta, "team")
+
+	require.Nil(job.Multiregion.RegionalJob(job, "north"))
+}
+
 func TestDispatchPayloadConfig_Validate(t *testing.T) {
 	d := &DispatchPayloadConfig{
 		File: "foo",
@@ -3680,6 +4053,55 @@ func TestACLTokenPolicySubset(t *testing.T) {
 	assert.Equal(t, true, tk.PolicySubset([]string{"new"}))
 }
 
+func TestACLTokenValidate_Roles(t *testing.T) {
+	require := require.New(t)
+
+	// A client token may be associated with only roles
+	tk := &ACLToken{
+		Type:  ACLClientToken,
+		Roles: []string{"ops"},
+	}
+	require.NoError(tk.Validate())
+
+	// A management token may not be associated with roles
+	tk.Type = ACLManagementToken
+	err := tk.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "associated with policies or roles")
+
+	// The TTL must not be negative
+	tk.Roles = nil
+	tk.ExpirationTTL = -time.Minute
+	err = tk.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "must not be negative")
+}
+
+func TestACLTokenIsExpired(t *testing.T) {
+	now := time.Now()
+	tk := &ACLToken{}
+	require.False(t, tk.IsExpired(now))
+
+	expiry := now.Add(time.Minute)
+	tk.ExpirationTime = &expiry
+	require.False(t, tk.IsExpired(now))
+	require.True(t, tk.IsExpired(expiry))
+	require.True(t, tk.IsExpired(now.Add(time.Hour)))
+}
+
+func TestACLTokenRoleSubset(t *testing.T) {
+	tk := &ACLToken{
+		Type:  ACLClientToken,
+		Roles: []string{"foo", "bar"},
+	}
+	require.True(t, tk.RoleSubset([]string{"foo"}))
+	require.True(t, tk.RoleSubset([]string{}))
+	require.False(t, tk.RoleSubset([]string{"foo", "new"}))
+
+	tk = &ACLToken{Type: ACLManagementToken}
+	require.True(t, tk.RoleSubset([]string{"new"}))
+}
+
 func TestACLTokenSetHash(t *testing.T) {
 	tk := &ACLToken{
 		Name:     "foo",
@@ -3700,6 +4122,33 @@ func TestACLTokenSetHash(t *testing.T) {
 	assert.NotEqual(t, out1, out2)
 }
 
+func TestACLRoleValidate(t *testing.T) {
+	require := require.New(t)
+	r := &ACLRole{Name: "bad name"}
+	err := r.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "invalid name")
+	require.Contains(err.Error(), "missing policies")
+
+	r.Name = "ops"
+	r.Policies = []string{"readonly"}
+	require.NoError(r.Validate())
+}
+
+func TestACLRoleSetHash(t *testing.T) {
+	r := &ACLRole{
+		Name:     "ops",
+		Policies: []string{"foo", "bar"},
+	}
+	out1 := r.SetHash()
+	require.NotNil(t, out1)
+	require.Equal(t, out1, r.Hash)
+
+	r.Policies = []string{"foo"}
+	out2 := r.SetHash()
+	require.NotEqual(t, out1, out2)
+}
+
 func TestACLPolicySetHash(t *testing.T) {
 	ap := &ACLPolicy{
 		Name:        "foo",
@@ -3746,6 +4195,9 @@ func TestTaskEventPopulate(t *testing.T) {
 		{NewTaskEvent(TaskKilling).SetKillTimeout(1 * time.Second), "Sent interrupt. Waiting 1s before force killing"},
 		{NewTaskEvent(TaskTerminated).SetExitCode(-1).SetSignal(3), "Exit Code: -1, Signal: 3"},
 		{NewTaskEvent(TaskTerminated).SetMessage("Goodbye"), "Exit Code: 0, Exit Message: \"Goodbye\""},
+		{NewTaskEvent(TaskTerminated).SetExitCode(137).SetOOMKilled(true).SetExitMessage(fmt.Errorf("OOM Killed")), "OOM Killed, Exit Code: 137"},
+		{NewTaskEvent(TaskTerminated).SetExitCode(137).SetOOMKilled(true).SetExitDetails(map[string]string{"memory_rss": "2254857830", "memory_limit": "2147483648"}).SetExitMessage(fmt.Errorf("OOM Killed")), "OOM Killed, rss=2.1GiB limit=2GiB, Exit Code: 137"},
+		{NewTaskEvent(TaskTerminated).SetExitCode(1).SetOOMKilled(false).SetExitDetails(map[string]string{"memory_limit": "2147483648"}), "Exit Code: 1"},
 		{NewTaskEvent(TaskKilled), "Task successfully killed"},
 		{NewTaskEvent(TaskKilled).SetKillError(fmt.Errorf("undead creatures can't be killed")), "undead creatures can't be killed"},
 		{NewTaskEvent(TaskNotRestarting).SetRestartReason("Chaos Monkey did it"), "Chaos Monkey did it"},
@@ -3759,6 +4211,7 @@ func TestTaskEventPopulate(t *testing.T) {
 		{NewTaskEvent(TaskRestartSignal), "Task signaled to restart"},
 		{NewTaskEvent(TaskRestartSignal).SetRestartReason("Chaos Monkey restarted it"), "Chaos Monkey restarted it"},
 		{NewTaskEvent(TaskDriverMessage).SetDriverMessage("YOLO"), "YOLO"},
+		{NewTaskEvent(TaskDriverMessage).SetDriverMessage("container exited").SetOOMKilled(true).SetExitDetails(map[string]string{"memory_limit": "2147483648"}), "OOM Killed, limit=2GiB - container exited"},
 		{NewTaskEvent("Unknown Type, No message"), ""},
 		{NewTaskEvent("Unknown Type").SetMessage("Hello world"), "Hello world"},
 	}
@@ -0,0 +1,258 @@
+package structs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+const (
+	// MaxVariableSize is the maximum size of a variable's items.
+	MaxVariableSize = 16 * 1024
+
+	// VariableLockOpAcquire, VariableLockOpRenew and VariableLockOpRelease
+	// are the operations on the lock of a variable.
+	VariableLockOpAcquire = "lock-acquire"
+	VariableLockOpRenew   = "lock-renew"
+	VariableLockOpRelease = "lock-release"
+
+	// DefaultVariableLockTTL is the TTL of variable locks acquired without
+	// one. MinVariableLockTTL and MaxVariableLockTTL bound the TTL.
+	DefaultVariableLockTTL = 15 * time.Second
+	MinVariableLockTTL     = 10 * time.Second
+	MaxVariableLockTTL     = 24 * time.Hour
+)
+
+var (
+	// ErrCASConflict is returned when a check-and-set write does not apply
+	// because the variable was modified since it was read.
+	ErrCASConflict = errors.New("check-and-set conflict")
+
+	// ErrVariableLocked is returned when acquiring the lock of a variable
+	// that is held by someone else.
+	ErrVariableLocked = errors.New("variable is locked")
+
+	// ErrVariableLockNotHeld is returned when renewing or releasing a lock
+	// that isn't held with the given lock ID.
+	ErrVariableLockNotHeld = errors.New("variable lock is not held")
+
+	// validVariablePath is the set of characters allowed in variable paths.
+	validVariablePath = regexp.MustCompile("^[a-zA-Z0-9-_~/]{1,128}$")
+)
+
+// VariableMetadata is the part of a variable that is stored unencrypted.
+type VariableMetadata struct {
+	Namespace string
+	Path      string
+
+	CreateIndex uint64
+	ModifyIndex uint64
+	CreateTime  int64
+	ModifyTime  int64
+
+	// Lock is the lock held on the variable, if any.
+	Lock *VariableLock
+}
+
+// VariableLock is a lease-style lock held on a variable. It expires unless
+// it is renewed within its TTL, so that it can be used for leader election
+// among processes that may fail without releasing it.
+type VariableLock struct {
+	// ID identifies the holder of the lock. It is generated when the lock
+	// is acquired and is required to renew or release it.
+	ID string
+
+	// TTL is how long the lock is held without being renewed.
+	TTL time.Duration
+
+	// ExpireTime is when the lock expires unless it is renewed, in unix
+	// nanoseconds.
+	ExpireTime int64
+}
+
+// Copy returns a copy of the lock.
+func (l *VariableLock) Copy() *VariableLock {
+	if l == nil {
+		return nil
+	}
+	nl := new(VariableLock)
+	*nl = *l
+	return nl
+}
+
+// Expired returns whether the lock has expired at the given time.
+func (l *VariableLock) Expired(now time.Time) bool {
+	return l == nil || now.UnixNano() >= l.ExpireTime
+}
+
+// VariableEncrypted is a variable as it is stored in the state store. Its
+// items are only ever stored encrypted.
+type VariableEncrypted struct {
+	VariableMetadata
+
+	// KeyID identifies the key the items were encrypted with.
+	KeyID string
+
+	// Data is the nonce followed by the encrypted items.
+	Data []byte
+}
+
+// Copy returns a deep copy of the variable.
+func (v *VariableEncrypted) Copy() *VariableEncrypted {
+	if v == nil {
+		return nil
+	}
+	nv := new(VariableEncrypted)
+	*nv = *v
+	nv.Lock = v.Lock.Copy()
+	nv.Data = make([]byte, len(v.Data))
+	copy(nv.Data, v.Data)
+	return nv
+}
+
+// VariableDecrypted is a variable along with its decrypted items.
+type VariableDecrypted struct {
+	VariableMetadata
+	Items map[string]string
+}
+
+// Copy returns a deep copy of the variable.
+func (v *VariableDecrypted) Copy() *VariableDecrypted {
+	if v == nil {
+		return nil
+	}
+	nv := new(VariableDecrypted)
+	*nv = *v
+	nv.Lock = v.Lock.Copy()
+	nv.Items = helper.CopyMapStringString(v.Items)
+	return nv
+}
+
+// Validate checks the variable's path and items.
+func (v *VariableDecrypted) Validate() error {
+	var mErr multierror.Error
+	if err := ValidateVariablePath(v.Path); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	if v.Namespace == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("missing namespace"))
+	}
+	if len(v.Items) == 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("variable must have at least one item"))
+	}
+
+	size := 0
+	for k, val := range v.Items {
+		if k == "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("item keys must not be empty"))
+		}
+		size += len(k) + len(val)
+	}
+	if size > MaxVariableSize {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("variable items are %d bytes; must be at most %d bytes", size, MaxVariableSize))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ValidateVariablePath returns an error if the path is not a valid variable
+// path. Paths are made of segments separated by slashes.
+func ValidateVariablePath(path string) error {
+	if !validVariablePath.MatchString(path) {
+		return fmt.Errorf("invalid variable path %q: must be 1-128 characters of letters, digits, '-', '_', '~' and '/'", path)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			return fmt.Errorf("invalid variable path %q: must not contain empty segments", path)
+		}
+	}
+	return nil
+}
+
+// VariablesUpsertRequest is used to create or update a variable.
+type VariablesUpsertRequest struct {
+	Var *VariableDecrypted
+
+	// CAS controls whether to use check-and-set semantics. The variable is
+	// only written if its ModifyIndex matches Var.ModifyIndex, which must
+	// be zero to create a variable.
+	CAS bool
+
+	WriteRequest
+}
+
+// VariablesEncryptedUpsertRequest is the raft request used to write an
+// encrypted variable.
+type VariablesEncryptedUpsertRequest struct {
+	Var *VariableEncrypted
+	CAS bool
+
+	// SetLock replaces the lock of the variable with the one of Var. Other
+	// writes keep the lock of the variable.
+	SetLock bool
+
+	WriteRequest
+}
+
+// VariablesLockRequest is used to acquire, renew or release the lock of a
+// variable.
+type VariablesLockRequest struct {
+	// Op is one of VariableLockOpAcquire, VariableLockOpRenew and
+	// VariableLockOpRelease.
+	Op string
+
+	// Var is the variable to lock. When acquiring, its items are written if
+	// set, and are required if the variable doesn't exist yet. Lock.TTL is
+	// the TTL of an acquired lock, and Lock.ID identifies the lock to renew
+	// or release.
+	Var *VariableDecrypted
+
+	WriteRequest
+}
+
+// VariablesDeleteRequest is used to delete a variable.
+type VariablesDeleteRequest struct {
+	Path string
+
+	// CAS controls whether to use check-and-set semantics. The variable is
+	// only deleted if its ModifyIndex matches ModifyIndex.
+	CAS         bool
+	ModifyIndex uint64
+
+	WriteRequest
+}
+
+// VariablesWriteResponse is returned when writing or deleting a variable.
+type VariablesWriteResponse struct {
+	// Var is the metadata of the written variable. It is nil for deletes.
+	Var *VariableMetadata
+	WriteMeta
+}
+
+// VariablesReadRequest is used to read a variable.
+type VariablesReadRequest struct {
+	Path string
+	QueryOptions
+}
+
+// VariablesReadResponse is used to return a decrypted variable.
+type VariablesReadResponse struct {
+	Data *VariableDecrypted
+	QueryMeta
+}
+
+// VariablesListRequest is used to list the variables in a namespace. The
+// Prefix query option filters by path.
+type VariablesListRequest struct {
+	QueryOptions
+}
+
+// VariablesListResponse is used to return the metadata of variables.
+type VariablesListResponse struct {
+	Data []*VariableMetadata
+	QueryMeta
+}
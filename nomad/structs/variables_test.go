@@ -0,0 +1,59 @@
+package structs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateVariablePath(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Path  string
+		Valid bool
+	}{
+		{"app", true},
+		{"nomad/jobs/web", true},
+		{"app/db-1_~", true},
+		{"", false},
+		{"/app", false},
+		{"app/", false},
+		{"app//db", false},
+		{"app/db.json", false},
+		{strings.Repeat("a", 129), false},
+	}
+	for _, c := range cases {
+		err := ValidateVariablePath(c.Path)
+		if c.Valid {
+			require.NoError(t, err, c.Path)
+		} else {
+			require.Error(t, err, c.Path)
+		}
+	}
+}
+
+func TestVariableDecrypted_Validate(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	v := &VariableDecrypted{
+		VariableMetadata: VariableMetadata{
+			Namespace: DefaultNamespace,
+			Path:      "app/db",
+		},
+		Items: map[string]string{"password": "hunter2"},
+	}
+	require.NoError(v.Validate())
+
+	v.Items = nil
+	require.Error(v.Validate())
+
+	v.Items = map[string]string{"": "value"}
+	require.Error(v.Validate())
+
+	v.Items = map[string]string{"large": strings.Repeat("a", MaxVariableSize)}
+	err := v.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "must be at most")
+}
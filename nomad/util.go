@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/nomad/nomad/state"
@@ -22,6 +23,10 @@ func ensurePath(path string, dir bool) error {
 	return os.MkdirAll(path, 0755)
 }
 
+// rpcSegmentTagPrefix prefixes the serf tags holding the RPC addresses a
+// server advertises to the clients of each network segment.
+const rpcSegmentTagPrefix = "rpc_seg_"
+
 // serverParts is used to return the parts of a server role
 type serverParts struct {
 	Name         string
@@ -39,6 +44,10 @@ type serverParts struct {
 	RPCAddr      net.Addr
 	Status       serf.MemberStatus
 	NonVoter     bool
+
+	// RPCSegmentAddrs are the RPC addresses advertised to the clients of
+	// each network segment.
+	RPCSegmentAddrs map[string]net.Addr
 }
 
 func (s *serverParts) String() string {
@@ -121,6 +130,24 @@ func isNomadServer(m serf.Member) (bool, *serverParts) {
 	// Check if the server is a non voter
 	_, nonVoter := m.Tags["nonvoter"]
 
+	// Collect the addresses advertised to the clients of network segments,
+	// ignoring the ones that can't be parsed
+	var segmentAddrs map[string]net.Addr
+	for tag, value := range m.Tags {
+		segment := strings.TrimPrefix(tag, rpcSegmentTagPrefix)
+		if segment == tag || segment == "" {
+			continue
+		}
+		addr, err := net.ResolveTCPAddr("tcp", value)
+		if err != nil {
+			continue
+		}
+		if segmentAddrs == nil {
+			segmentAddrs = make(map[string]net.Addr)
+		}
+		segmentAddrs[segment] = addr
+	}
+
 	addr := &net.TCPAddr{IP: m.Addr, Port: port}
 	rpcAddr := &net.TCPAddr{IP: rpcIP, Port: port}
 	parts := &serverParts{
@@ -139,6 +166,8 @@ func isNomadServer(m serf.Member) (bool, *serverParts) {
 		RaftVersion:  raftVsn,
 		Status:       m.Status,
 		NonVoter:     nonVoter,
+
+		RPCSegmentAddrs: segmentAddrs,
 	}
 	return true, parts
 }
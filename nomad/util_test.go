@@ -26,6 +26,9 @@ func TestIsNomadServer(t *testing.T) {
 			"raft_vsn": "2",
 			"build":    "0.7.0+ent",
 			"nonvoter": "1",
+
+			"rpc_seg_dmz":  "2.2.2.2:10001",
+			"rpc_seg_edge": "invalid",
 		},
 	}
 	valid, parts := isNomadServer(m)
@@ -59,6 +62,9 @@ func TestIsNomadServer(t *testing.T) {
 	if !parts.NonVoter {
 		t.Fatalf("should be nonvoter")
 	}
+	if len(parts.RPCSegmentAddrs) != 1 || parts.RPCSegmentAddrs["dmz"].String() != "2.2.2.2:10001" {
+		t.Fatalf("bad: %v", parts.RPCSegmentAddrs)
+	}
 
 	m.Tags["bootstrap"] = "1"
 	valid, parts = isNomadServer(m)
@@ -0,0 +1,375 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	memdb "github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+var (
+	// errVariablesDisabled is returned when the server has no variables
+	// encryption key.
+	errVariablesDisabled = fmt.Errorf("variables are disabled: no variables encryption key is configured")
+)
+
+// Variables endpoint is used to manage encrypted variables
+type Variables struct {
+	srv    *Server
+	logger log.Logger
+}
+
+// Upsert is used to create or update a variable
+func (v *Variables) Upsert(args *structs.VariablesUpsertRequest, reply *structs.VariablesWriteResponse) error {
+	if done, err := v.srv.forward("Variables.Upsert", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "upsert"}, time.Now())
+
+	if v.srv.encrypter == nil {
+		return errVariablesDisabled
+	}
+	if args.Var == nil {
+		return fmt.Errorf("missing variable")
+	}
+	args.Var.Namespace = args.RequestNamespace()
+
+	// Check variable write permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowVariableOperation(args.Var.Namespace, args.Var.Path, acl.VariablesCapabilityWrite) {
+		return structs.ErrPermissionDenied
+	}
+
+	if err := args.Var.Validate(); err != nil {
+		return err
+	}
+
+	args.Var.ModifyTime = time.Now().UnixNano()
+	encrypted, err := v.srv.encrypter.Encrypt(args.Var)
+	if err != nil {
+		return err
+	}
+
+	req := &structs.VariablesEncryptedUpsertRequest{
+		Var:          encrypted,
+		CAS:          args.CAS,
+		WriteRequest: args.WriteRequest,
+	}
+	resp, index, err := v.srv.raftApply(structs.VariablesUpsertRequestType, req)
+	if err != nil {
+		v.logger.Error("upsert failed", "error", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+	if applied, ok := resp.(bool); ok && !applied {
+		return structs.ErrCASConflict
+	}
+
+	out, err := v.srv.fsm.State().VariableByPath(nil, args.Var.Namespace, args.Var.Path)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		meta := out.VariableMetadata
+		redactVariableLock(&meta)
+		reply.Var = &meta
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// Lock is used to acquire, renew or release the lock of a variable. Locks are
+// advisory and don't prevent writes to the variable. The lock ID is only
+// returned when the lock is acquired or renewed.
+func (v *Variables) Lock(args *structs.VariablesLockRequest, reply *structs.VariablesWriteResponse) error {
+	if done, err := v.srv.forward("Variables.Lock", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "lock"}, time.Now())
+
+	if v.srv.encrypter == nil {
+		return errVariablesDisabled
+	}
+	if args.Var == nil {
+		return fmt.Errorf("missing variable")
+	}
+	args.Var.Namespace = args.RequestNamespace()
+
+	// Check variable write permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowVariableOperation(args.Var.Namespace, args.Var.Path, acl.VariablesCapabilityWrite) {
+		return structs.ErrPermissionDenied
+	}
+
+	if err := structs.ValidateVariablePath(args.Var.Path); err != nil {
+		return err
+	}
+
+	existing, err := v.srv.fsm.State().VariableByPath(nil, args.Var.Namespace, args.Var.Path)
+	if err != nil {
+		return err
+	}
+
+	// Expiry is evaluated with the clock of the leader, and the write below
+	// is a check-and-set against the variable that was looked at, so that
+	// concurrent lock operations can't both succeed.
+	now := time.Now()
+	var lock *structs.VariableLock
+	switch args.Op {
+	case structs.VariableLockOpAcquire:
+		if existing != nil && !existing.Lock.Expired(now) {
+			return structs.ErrVariableLocked
+		}
+
+		ttl := structs.DefaultVariableLockTTL
+		if args.Var.Lock != nil && args.Var.Lock.TTL != 0 {
+			ttl = args.Var.Lock.TTL
+		}
+		if ttl < structs.MinVariableLockTTL || ttl > structs.MaxVariableLockTTL {
+			return fmt.Errorf("lock TTL must be between %v and %v", structs.MinVariableLockTTL, structs.MaxVariableLockTTL)
+		}
+		lock = &structs.VariableLock{
+			ID:         uuid.Generate(),
+			TTL:        ttl,
+			ExpireTime: now.Add(ttl).UnixNano(),
+		}
+	case structs.VariableLockOpRenew, structs.VariableLockOpRelease:
+		if existing == nil || existing.Lock == nil || args.Var.Lock == nil || existing.Lock.ID != args.Var.Lock.ID {
+			return structs.ErrVariableLockNotHeld
+		}
+		if args.Op == structs.VariableLockOpRenew {
+			// An expired lock may have been acquired by someone else in
+			// the meantime, so it must be acquired again.
+			if existing.Lock.Expired(now) {
+				return structs.ErrVariableLockNotHeld
+			}
+			lock = existing.Lock.Copy()
+			lock.ExpireTime = now.Add(lock.TTL).UnixNano()
+		}
+	default:
+		return fmt.Errorf("invalid lock operation %q", args.Op)
+	}
+
+	// Write the given items, otherwise keep the encrypted items of the
+	// variable
+	var encrypted *structs.VariableEncrypted
+	if len(args.Var.Items) != 0 || existing == nil {
+		if err := args.Var.Validate(); err != nil {
+			return err
+		}
+		args.Var.ModifyTime = now.UnixNano()
+		encrypted, err = v.srv.encrypter.Encrypt(args.Var)
+		if err != nil {
+			return err
+		}
+	} else {
+		encrypted = existing.Copy()
+		encrypted.ModifyTime = now.UnixNano()
+	}
+	encrypted.Lock = lock
+	encrypted.ModifyIndex = 0
+	if existing != nil {
+		encrypted.ModifyIndex = existing.ModifyIndex
+	}
+
+	req := &structs.VariablesEncryptedUpsertRequest{
+		Var:          encrypted,
+		CAS:          true,
+		SetLock:      true,
+		WriteRequest: args.WriteRequest,
+	}
+	resp, index, err := v.srv.raftApply(structs.VariablesUpsertRequestType, req)
+	if err != nil {
+		v.logger.Error("lock failed", "op", args.Op, "error", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+	if applied, ok := resp.(bool); ok && !applied {
+		return structs.ErrCASConflict
+	}
+
+	out, err := v.srv.fsm.State().VariableByPath(nil, args.Var.Namespace, args.Var.Path)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		meta := out.VariableMetadata
+		meta.Lock = meta.Lock.Copy()
+		reply.Var = &meta
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// redactVariableLock removes the ID of the lock of a variable, so that only
+// the holder of the lock may renew or release it.
+func redactVariableLock(meta *structs.VariableMetadata) {
+	if meta.Lock != nil {
+		lock := meta.Lock.Copy()
+		lock.ID = ""
+		meta.Lock = lock
+	}
+}
+
+// Delete is used to delete a variable
+func (v *Variables) Delete(args *structs.VariablesDeleteRequest, reply *structs.VariablesWriteResponse) error {
+	if done, err := v.srv.forward("Variables.Delete", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "delete"}, time.Now())
+
+	if v.srv.encrypter == nil {
+		return errVariablesDisabled
+	}
+
+	// Check variable destroy permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowVariableOperation(args.RequestNamespace(), args.Path, acl.VariablesCapabilityDestroy) {
+		return structs.ErrPermissionDenied
+	}
+
+	if err := structs.ValidateVariablePath(args.Path); err != nil {
+		return err
+	}
+
+	resp, index, err := v.srv.raftApply(structs.VariablesDeleteRequestType, args)
+	if err != nil {
+		v.logger.Error("delete failed", "error", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+	if applied, ok := resp.(bool); ok && !applied {
+		return structs.ErrCASConflict
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// Read is used to read and decrypt a variable
+func (v *Variables) Read(args *structs.VariablesReadRequest, reply *structs.VariablesReadResponse) error {
+	if done, err := v.srv.forward("Variables.Read", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "read"}, time.Now())
+
+	if v.srv.encrypter == nil {
+		return errVariablesDisabled
+	}
+
+	// Check variable read permissions
+	if aclObj, err := v.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowVariableOperation(args.RequestNamespace(), args.Path, acl.VariablesCapabilityRead) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			out, err := state.VariableByPath(ws, args.RequestNamespace(), args.Path)
+			if err != nil {
+				return err
+			}
+
+			reply.Data = nil
+			if out != nil {
+				decrypted, err := v.srv.encrypter.Decrypt(out)
+				if err != nil {
+					return err
+				}
+				redactVariableLock(&decrypted.VariableMetadata)
+				reply.Data = decrypted
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the variables table
+				index, err := state.Index("variables")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			v.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return v.srv.blockingRPC(&opts)
+}
+
+// List is used to list the metadata of the variables in a namespace. Only
+// variables the token may list are returned.
+func (v *Variables) List(args *structs.VariablesListRequest, reply *structs.VariablesListResponse) error {
+	if done, err := v.srv.forward("Variables.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "variables", "list"}, time.Now())
+
+	if v.srv.encrypter == nil {
+		return errVariablesDisabled
+	}
+
+	aclObj, err := v.srv.ResolveToken(args.AuthToken)
+	if err != nil {
+		return err
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			iter, err := state.VariablesByNamespacePrefix(ws, args.RequestNamespace(), args.Prefix)
+			if err != nil {
+				return err
+			}
+
+			var vars []*structs.VariableMetadata
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				variable := raw.(*structs.VariableEncrypted)
+				if aclObj != nil && !aclObj.AllowVariableOperation(variable.Namespace, variable.Path, acl.VariablesCapabilityList) {
+					continue
+				}
+				meta := variable.VariableMetadata
+				redactVariableLock(&meta)
+				vars = append(vars, &meta)
+			}
+			reply.Data = vars
+
+			// Use the last index that affected the variables table
+			index, err := state.Index("variables")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			v.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return v.srv.blockingRPC(&opts)
+}
@@ -0,0 +1,368 @@
+package nomad
+
+import (
+	"testing"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariablesEndpoint_UpsertReadDelete(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.VariablesEncryptionKey = testVariablesKey(1)
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	req := &structs.VariablesUpsertRequest{
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Path: "app/db"},
+			Items:            map[string]string{"password": "hunter2"},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.VariablesWriteResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp))
+	require.NotZero(resp.Index)
+	require.Equal(structs.DefaultNamespace, resp.Var.Namespace)
+	require.Equal(resp.Index, resp.Var.ModifyIndex)
+
+	// The items are stored encrypted
+	stored, err := s1.fsm.State().VariableByPath(nil, structs.DefaultNamespace, "app/db")
+	require.NoError(err)
+	require.NotContains(string(stored.Data), "hunter2")
+
+	get := &structs.VariablesReadRequest{
+		Path: "app/db",
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+	var getResp structs.VariablesReadResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp))
+	require.Equal(resp.Index, getResp.Index)
+	require.Equal(map[string]string{"password": "hunter2"}, getResp.Data.Items)
+
+	// A check-and-set write with a stale index conflicts
+	req.CAS = true
+	req.Var.ModifyIndex = resp.Index - 1
+	err = msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp)
+	require.EqualError(err, structs.ErrCASConflict.Error())
+
+	del := &structs.VariablesDeleteRequest{
+		Path:         "app/db",
+		CAS:          true,
+		ModifyIndex:  resp.Index,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var delResp structs.VariablesWriteResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Delete", del, &delResp))
+
+	getResp = structs.VariablesReadResponse{}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp))
+	require.Nil(getResp.Data)
+	require.Equal(delResp.Index, getResp.Index)
+}
+
+func TestVariablesEndpoint_Lock(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.VariablesEncryptionKey = testVariablesKey(1)
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Acquiring the lock of a new variable requires items
+	req := &structs.VariablesLockRequest{
+		Op: structs.VariableLockOpAcquire,
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Path: "jobs/leader"},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.VariablesWriteResponse
+	err := msgpackrpc.CallWithCodec(codec, "Variables.Lock", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "at least one item")
+
+	req.Var.Items = map[string]string{"leader": "alloc-1"}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Lock", req, &resp))
+	require.NotNil(resp.Var.Lock)
+	lock := *resp.Var.Lock
+	require.NotEmpty(lock.ID)
+	require.Equal(structs.DefaultVariableLockTTL, lock.TTL)
+
+	// The lock can't be acquired while it is held
+	req.Var = &structs.VariableDecrypted{
+		VariableMetadata: structs.VariableMetadata{Path: "jobs/leader"},
+		Items:            map[string]string{"leader": "alloc-2"},
+	}
+	err = msgpackrpc.CallWithCodec(codec, "Variables.Lock", req, &resp)
+	require.EqualError(err, structs.ErrVariableLocked.Error())
+
+	// Readers don't see the lock ID, and the items are kept
+	get := &structs.VariablesReadRequest{
+		Path:         "jobs/leader",
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var getResp structs.VariablesReadResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp))
+	require.Equal("alloc-1", getResp.Data.Items["leader"])
+	require.NotNil(getResp.Data.Lock)
+	require.Empty(getResp.Data.Lock.ID)
+
+	// Plain writes keep the lock
+	upsert := &structs.VariablesUpsertRequest{
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Path: "jobs/leader"},
+			Items:            map[string]string{"leader": "alloc-1", "epoch": "1"},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Upsert", upsert, &resp))
+	require.NotNil(resp.Var.Lock)
+	require.Empty(resp.Var.Lock.ID)
+
+	// Renewing requires the lock ID
+	renew := &structs.VariablesLockRequest{
+		Op: structs.VariableLockOpRenew,
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{
+				Path: "jobs/leader",
+				Lock: &structs.VariableLock{ID: "bad"},
+			},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	err = msgpackrpc.CallWithCodec(codec, "Variables.Lock", renew, &resp)
+	require.EqualError(err, structs.ErrVariableLockNotHeld.Error())
+
+	renew.Var.Lock.ID = lock.ID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Lock", renew, &resp))
+	require.Equal(lock.ID, resp.Var.Lock.ID)
+	require.True(resp.Var.Lock.ExpireTime >= lock.ExpireTime)
+
+	// Releasing the lock allows it to be acquired again
+	release := &structs.VariablesLockRequest{
+		Op: structs.VariableLockOpRelease,
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{
+				Path: "jobs/leader",
+				Lock: &structs.VariableLock{ID: lock.ID},
+			},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Lock", release, &resp))
+	require.Nil(resp.Var.Lock)
+
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Lock", req, &resp))
+	require.NotEqual(lock.ID, resp.Var.Lock.ID)
+
+	getResp = structs.VariablesReadResponse{}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp))
+	require.Equal("alloc-2", getResp.Data.Items["leader"])
+}
+
+func TestVariablesEndpoint_Lock_Expired(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.VariablesEncryptionKey = testVariablesKey(1)
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Store a variable whose lock has expired
+	variable := &structs.VariableDecrypted{
+		VariableMetadata: structs.VariableMetadata{
+			Namespace: structs.DefaultNamespace,
+			Path:      "jobs/leader",
+		},
+		Items: map[string]string{"leader": "alloc-1"},
+	}
+	encrypted, err := s1.encrypter.Encrypt(variable)
+	require.NoError(err)
+	encrypted.Lock = &structs.VariableLock{
+		ID:         "expired",
+		TTL:        structs.DefaultVariableLockTTL,
+		ExpireTime: time.Now().Add(-time.Second).UnixNano(),
+	}
+	_, err = s1.fsm.State().UpsertVariableLock(1000, encrypted)
+	require.NoError(err)
+
+	// The expired lock can't be renewed
+	renew := &structs.VariablesLockRequest{
+		Op: structs.VariableLockOpRenew,
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{
+				Path: "jobs/leader",
+				Lock: &structs.VariableLock{ID: "expired"},
+			},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.VariablesWriteResponse
+	err = msgpackrpc.CallWithCodec(codec, "Variables.Lock", renew, &resp)
+	require.EqualError(err, structs.ErrVariableLockNotHeld.Error())
+
+	// but it can be acquired by someone else
+	acquire := &structs.VariablesLockRequest{
+		Op: structs.VariableLockOpAcquire,
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{
+				Path: "jobs/leader",
+				Lock: &structs.VariableLock{TTL: time.Minute},
+			},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Lock", acquire, &resp))
+	require.NotEqual("expired", resp.Var.Lock.ID)
+	require.Equal(time.Minute, resp.Var.Lock.TTL)
+
+	// TTLs are bounded
+	acquire.Var.Path = "jobs/other"
+	acquire.Var.Items = map[string]string{"leader": "alloc-1"}
+	acquire.Var.Lock.TTL = time.Second
+	err = msgpackrpc.CallWithCodec(codec, "Variables.Lock", acquire, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "lock TTL must be between")
+}
+
+func TestVariablesEndpoint_Disabled(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	req := &structs.VariablesUpsertRequest{
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Path: "app/db"},
+			Items:            map[string]string{"password": "hunter2"},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.VariablesWriteResponse
+	err := msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp)
+	require.EqualError(err, errVariablesDisabled.Error())
+}
+
+func TestVariablesEndpoint_Upsert_Invalid(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1 := TestServer(t, func(c *Config) {
+		c.VariablesEncryptionKey = testVariablesKey(1)
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	req := &structs.VariablesUpsertRequest{
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Path: "app//db"},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.VariablesWriteResponse
+	err := msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "empty segments")
+	require.Contains(err.Error(), "at least one item")
+}
+
+func TestVariablesEndpoint_ACL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	s1, root := TestACLServer(t, func(c *Config) {
+		c.VariablesEncryptionKey = testVariablesKey(1)
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	policy := `
+namespace "default" {
+  variables {
+    path "app/*" {
+      capabilities = ["list", "read"]
+    }
+  }
+}`
+	token := mock.CreatePolicyAndToken(t, state, 1000, "test-valid", policy)
+
+	for _, path := range []string{"app/db", "other/db"} {
+		req := &structs.VariablesUpsertRequest{
+			Var: &structs.VariableDecrypted{
+				VariableMetadata: structs.VariableMetadata{Path: path},
+				Items:            map[string]string{"password": "hunter2"},
+			},
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				AuthToken: root.SecretID,
+			},
+		}
+		var resp structs.VariablesWriteResponse
+		require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp))
+	}
+
+	// The token may not write
+	req := &structs.VariablesUpsertRequest{
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Path: "app/db"},
+			Items:            map[string]string{"password": "changed"},
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp structs.VariablesWriteResponse
+	err := msgpackrpc.CallWithCodec(codec, "Variables.Upsert", req, &resp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// The token may only read paths it is granted
+	get := &structs.VariablesReadRequest{
+		Path: "other/db",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var getResp structs.VariablesReadResponse
+	err = msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	get.Path = "app/db"
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.Read", get, &getResp))
+	require.Equal("hunter2", getResp.Data.Items["password"])
+
+	// Listing only returns the granted paths
+	list := &structs.VariablesListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var listResp structs.VariablesListResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.List", list, &listResp))
+	require.Len(listResp.Data, 1)
+	require.Equal("app/db", listResp.Data[0].Path)
+
+	list.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Variables.List", list, &listResp))
+	require.Len(listResp.Data, 2)
+}
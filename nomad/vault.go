@@ -247,7 +247,7 @@ func NewVaultClient(c *config.VaultConfig, logger log.Logger, purgeFn PurgeVault
 		tomb:     &tomb.Tomb{},
 	}
 
-	if v.config.IsEnabled() {
+	if managesTokens(v.config) {
 		if err := v.buildClient(); err != nil {
 			return nil, err
 		}
@@ -346,7 +346,7 @@ func (v *vaultClient) SetConfig(config *config.VaultConfig) error {
 	v.config = config
 
 	// Check if we should relaunch
-	if v.config.IsEnabled() {
+	if managesTokens(v.config) {
 		// Rebuild the client
 		if err := v.buildClient(); err != nil {
 			return err
@@ -403,6 +403,9 @@ func (v *vaultClient) buildClient() error {
 	// Set the token and store the client
 	v.token = v.config.Token
 	client.SetToken(v.token)
+	if v.config.Namespace != "" {
+		client.SetNamespace(v.config.Namespace)
+	}
 	v.client = client
 	v.auth = client.Auth().Token()
 	return nil
@@ -883,7 +886,14 @@ func (v *vaultClient) ConnectionEstablished() (bool, error) {
 func (v *vaultClient) Enabled() bool {
 	v.l.Lock()
 	defer v.l.Unlock()
-	return v.config.IsEnabled()
+	return managesTokens(v.config)
+}
+
+// managesTokens returns whether the Vault config requires the Servers to hold
+// a Vault token to create the tokens of tasks. Servers signing workload
+// identities leave the tokens to the clients instead.
+func managesTokens(c *config.VaultConfig) bool {
+	return c.IsEnabled() && !c.UsesJWTAuth()
 }
 
 // Active returns whether the client is active
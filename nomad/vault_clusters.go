@@ -0,0 +1,97 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+// vaultClientFor returns the client of the named Vault cluster, or nil if the
+// cluster isn't configured. An empty name selects the default cluster.
+func (s *Server) vaultClientFor(cluster string) VaultClient {
+	if cluster == "" || cluster == config.DefaultVaultClusterName {
+		return s.vault
+	}
+
+	s.vaultClustersLock.RLock()
+	defer s.vaultClustersLock.RUnlock()
+	return s.vaultClusters[cluster]
+}
+
+// vaultClients returns the clients of the default and all named Vault
+// clusters.
+func (s *Server) vaultClients() []VaultClient {
+	s.vaultClustersLock.RLock()
+	defer s.vaultClustersLock.RUnlock()
+
+	clients := make([]VaultClient, 0, len(s.vaultClusters)+1)
+	if s.vault != nil {
+		clients = append(clients, s.vault)
+	}
+	for _, v := range s.vaultClusters {
+		clients = append(clients, v)
+	}
+	return clients
+}
+
+// reloadVaultClusters updates the clients of the named Vault clusters with
+// their new configs and creates clients for newly added clusters. Clients of
+// removed clusters are kept so that the tokens they created can still be
+// revoked.
+func (s *Server) reloadVaultClusters(clusters map[string]*config.VaultConfig) error {
+	s.vaultClustersLock.Lock()
+	defer s.vaultClustersLock.Unlock()
+
+	if s.vaultClusters == nil {
+		s.vaultClusters = make(map[string]VaultClient, len(clusters))
+	}
+
+	var mErr multierror.Error
+	for name, c := range clusters {
+		if v, ok := s.vaultClusters[name]; ok {
+			if err := v.SetConfig(c); err != nil {
+				multierror.Append(&mErr, fmt.Errorf("Vault cluster %q: %v", name, err))
+			}
+		} else {
+			v, err := NewVaultClient(c, s.logger.With("vault_cluster", name), s.purgeVaultAccessors)
+			if err != nil {
+				multierror.Append(&mErr, fmt.Errorf("Vault cluster %q: %v", name, err))
+				continue
+			}
+			if s.IsLeader() {
+				v.SetActive(true)
+			}
+			s.vaultClusters[name] = v
+		}
+
+		if err := s.setupVaultIdentity(c); err != nil {
+			multierror.Append(&mErr, fmt.Errorf("Vault cluster %q: %v", name, err))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// revokeVaultTokens revokes the Vault tokens of the accessors with the clients
+// of the Vault clusters that created them.
+func (s *Server) revokeVaultTokens(ctx context.Context, accessors []*structs.VaultAccessor, committed bool) error {
+	byCluster := make(map[string][]*structs.VaultAccessor)
+	for _, a := range accessors {
+		byCluster[a.Cluster] = append(byCluster[a.Cluster], a)
+	}
+
+	var mErr multierror.Error
+	for cluster, accessors := range byCluster {
+		v := s.vaultClientFor(cluster)
+		if v == nil {
+			multierror.Append(&mErr, fmt.Errorf("unknown Vault cluster %q", cluster))
+			continue
+		}
+		if err := v.RevokeTokens(ctx, accessors, committed); err != nil {
+			multierror.Append(&mErr, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
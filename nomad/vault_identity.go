@@ -0,0 +1,96 @@
+package nomad
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/auth"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+const (
+	// vaultIdentityIssuer is the issuer of workload identities
+	vaultIdentityIssuer = "nomad"
+
+	// vaultIdentityAudience is the audience of workload identities, which the
+	// Vault JWT auth role must bind to
+	vaultIdentityAudience = "vault.io"
+
+	// vaultIdentityTTL is the lifetime of workload identities. Clients
+	// exchange them for Vault tokens as soon as they receive them.
+	vaultIdentityTTL = 5 * time.Minute
+)
+
+// setupVaultIdentity loads the key workload identities for the Vault cluster
+// are signed with when its config enables JWT auth, and clears it otherwise.
+func (s *Server) setupVaultIdentity(c *config.VaultConfig) error {
+	var signer *auth.Signer
+	if c.IsEnabled() && c.UsesJWTAuth() {
+		keyPEM, err := ioutil.ReadFile(c.JWTSigningKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read Vault JWT signing key: %v", err)
+		}
+		signer, err = auth.NewSigner(keyPEM)
+		if err != nil {
+			return fmt.Errorf("invalid Vault JWT signing key: %v", err)
+		}
+	}
+
+	s.vaultIdentityLock.Lock()
+	defer s.vaultIdentityLock.Unlock()
+	if signer == nil {
+		delete(s.vaultIdentity, c.GetName())
+		return nil
+	}
+	if s.vaultIdentity == nil {
+		s.vaultIdentity = make(map[string]*auth.Signer)
+	}
+	s.vaultIdentity[c.GetName()] = signer
+	return nil
+}
+
+// vaultIdentitySigner returns the signer of workload identities for the Vault
+// cluster, or nil if the Servers create its Vault tokens themselves.
+func (s *Server) vaultIdentitySigner(cluster string) *auth.Signer {
+	if cluster == "" {
+		cluster = config.DefaultVaultClusterName
+	}
+
+	s.vaultIdentityLock.RLock()
+	defer s.vaultIdentityLock.RUnlock()
+	return s.vaultIdentity[cluster]
+}
+
+// signVaultIdentities returns the workload identities of the tasks of the
+// allocation, indexed by task name. The claims identify the task so that
+// Vault roles and policy templates can bind to them.
+func signVaultIdentities(signer *auth.Signer, region string, alloc *structs.Allocation, tasks []string, now time.Time) (map[string]string, error) {
+	identities := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		claims := map[string]interface{}{
+			"iss": vaultIdentityIssuer,
+			"aud": vaultIdentityAudience,
+			"sub": strings.Join([]string{region, alloc.Namespace, alloc.JobID, alloc.TaskGroup, task}, ":"),
+			"iat": now.Unix(),
+			"nbf": now.Unix(),
+			"exp": now.Add(vaultIdentityTTL).Unix(),
+
+			"nomad_namespace":     alloc.Namespace,
+			"nomad_job_id":        alloc.JobID,
+			"nomad_task_group":    alloc.TaskGroup,
+			"nomad_task":          task,
+			"nomad_allocation_id": alloc.ID,
+			"nomad_node_id":       alloc.NodeID,
+		}
+
+		token, err := signer.Sign(claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign workload identity of task %q: %v", task, err)
+		}
+		identities[task] = token
+	}
+	return identities, nil
+}
@@ -9,6 +9,7 @@ import (
 	metrics "github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/helper/tracing"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/scheduler"
 )
@@ -262,8 +263,18 @@ CHECK:
 }
 
 // invokeScheduler is used to invoke the business logic of the scheduler
-func (w *Worker) invokeScheduler(eval *structs.Evaluation, token string) error {
+func (w *Worker) invokeScheduler(eval *structs.Evaluation, token string) (err error) {
 	defer metrics.MeasureSince([]string{"nomad", "worker", "invoke_scheduler", eval.Type}, time.Now())
+
+	span := w.srv.tracer.Start("worker.invoke_scheduler", eval.ID, tracing.SpanKindInternal)
+	span.SetAttribute("eval_id", eval.ID)
+	span.SetAttribute("job_id", eval.JobID)
+	span.SetAttribute("scheduler", eval.Type)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Store the evaluation token
 	w.evalToken = token
 
@@ -310,6 +321,9 @@ func (w *Worker) SubmitPlan(plan *structs.Plan) (*structs.PlanResult, scheduler.
 	// Add the evaluation token to the plan
 	plan.EvalToken = w.evalToken
 
+	// Capture the placements of the plan before it is applied
+	decisions := w.srv.decisionLog.planPlacements(plan)
+
 	// Setup the request
 	req := structs.PlanRequest{
 		Plan: plan,
@@ -337,6 +351,7 @@ SUBMIT:
 	if result == nil {
 		return nil, nil, fmt.Errorf("missing result")
 	}
+	w.srv.decisionLog.placements(decisions, result)
 
 	// Check if a state update is required. This could be required if we
 	// planning based on stale data, which is causing issues. For example, a
@@ -396,6 +411,7 @@ SUBMIT:
 		w.logger.Debug("updated evaluation", "eval", log.Fmt("%#v", eval))
 		w.backoffReset()
 	}
+	w.srv.decisionLog.failures(eval)
 	return nil
 }
 
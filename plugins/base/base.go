@@ -1,6 +1,8 @@
 package base
 
 import (
+	"context"
+
 	"github.com/hashicorp/nomad/plugins/base/proto"
 	"github.com/hashicorp/nomad/plugins/shared/hclspec"
 )
@@ -18,6 +20,14 @@ type BasePlugin interface {
 	SetConfig(c *Config) error
 }
 
+// Pinger is implemented by the clients of external plugins to check that the
+// plugin process is alive and responsive.
+type Pinger interface {
+	// Ping returns an error if the plugin doesn't respond before the context
+	// is done.
+	Ping(ctx context.Context) error
+}
+
 // PluginInfoResponse returns basic information about the plugin such that Nomad
 // can decide whether to load the plugin or not.
 type PluginInfoResponse struct {
@@ -7,6 +7,8 @@ import (
 	"github.com/hashicorp/nomad/helper/pluginutils/grpcutils"
 	"github.com/hashicorp/nomad/plugins/base/proto"
 	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // BasePluginClient implements the client side of a remote base plugin, using
@@ -63,3 +65,14 @@ func (b *BasePluginClient) SetConfig(c *Config) error {
 
 	return grpcutils.HandleGrpcErr(err, b.DoneCtx)
 }
+
+// Ping checks that the plugin responds before ctx is done. Plugins built
+// before the Ping RPC was added are considered alive if they answer at all.
+func (b *BasePluginClient) Ping(ctx context.Context) error {
+	_, err := b.Client.Ping(ctx, &proto.PingRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+
+	return grpcutils.HandleReqCtxGrpcErr(err, ctx, b.DoneCtx)
+}
@@ -1,7 +1,9 @@
 package base
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	pb "github.com/golang/protobuf/proto"
 	plugin "github.com/hashicorp/go-plugin"
@@ -160,3 +162,29 @@ func TestBasePlugin_SetConfig(t *testing.T) {
 	require.EqualValues(1337, actual.Bar)
 	require.True(actual.Baz)
 }
+
+func TestBasePlugin_Ping(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	client, server := plugin.TestPluginGRPCConn(t, map[string]plugin.Plugin{
+		PluginTypeBase: &PluginBase{Impl: &MockPlugin{}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(PluginTypeBase)
+	require.NoError(err)
+
+	impl, ok := raw.(Pinger)
+	require.True(ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(impl.Ping(ctx))
+
+	// A plugin that stopped serving fails to respond
+	server.Stop()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Error(impl.Ping(ctx))
+}
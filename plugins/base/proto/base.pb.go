@@ -404,6 +404,68 @@ func (m *SetConfigResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_SetConfigResponse proto.InternalMessageInfo
 
+// PingRequest is used to check that the plugin is alive and responsive.
+type PingRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PingRequest) Reset()         { *m = PingRequest{} }
+func (m *PingRequest) String() string { return proto.CompactTextString(m) }
+func (*PingRequest) ProtoMessage()    {}
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_base_f2480776612a8fbd, []int{8}
+}
+func (m *PingRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PingRequest.Unmarshal(m, b)
+}
+func (m *PingRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PingRequest.Marshal(b, m, deterministic)
+}
+func (dst *PingRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingRequest.Merge(dst, src)
+}
+func (m *PingRequest) XXX_Size() int {
+	return xxx_messageInfo_PingRequest.Size(m)
+}
+func (m *PingRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PingRequest proto.InternalMessageInfo
+
+// PingResponse is returned by a plugin that is alive and responsive.
+type PingResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PingResponse) Reset()         { *m = PingResponse{} }
+func (m *PingResponse) String() string { return proto.CompactTextString(m) }
+func (*PingResponse) ProtoMessage()    {}
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_base_f2480776612a8fbd, []int{9}
+}
+func (m *PingResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PingResponse.Unmarshal(m, b)
+}
+func (m *PingResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PingResponse.Marshal(b, m, deterministic)
+}
+func (dst *PingResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingResponse.Merge(dst, src)
+}
+func (m *PingResponse) XXX_Size() int {
+	return xxx_messageInfo_PingResponse.Size(m)
+}
+func (m *PingResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PingResponse proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*PluginInfoRequest)(nil), "hashicorp.nomad.plugins.base.proto.PluginInfoRequest")
 	proto.RegisterType((*PluginInfoResponse)(nil), "hashicorp.nomad.plugins.base.proto.PluginInfoResponse")
@@ -413,6 +475,8 @@ func init() {
 	proto.RegisterType((*NomadConfig)(nil), "hashicorp.nomad.plugins.base.proto.NomadConfig")
 	proto.RegisterType((*NomadDriverConfig)(nil), "hashicorp.nomad.plugins.base.proto.NomadDriverConfig")
 	proto.RegisterType((*SetConfigResponse)(nil), "hashicorp.nomad.plugins.base.proto.SetConfigResponse")
+	proto.RegisterType((*PingRequest)(nil), "hashicorp.nomad.plugins.base.proto.PingRequest")
+	proto.RegisterType((*PingResponse)(nil), "hashicorp.nomad.plugins.base.proto.PingResponse")
 	proto.RegisterEnum("hashicorp.nomad.plugins.base.proto.PluginType", PluginType_name, PluginType_value)
 }
 
@@ -434,6 +498,8 @@ type BasePluginClient interface {
 	ConfigSchema(ctx context.Context, in *ConfigSchemaRequest, opts ...grpc.CallOption) (*ConfigSchemaResponse, error)
 	// SetConfig is used to set the configuration.
 	SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error)
+	// Ping is used to check that the plugin is alive and responsive.
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 }
 
 type basePluginClient struct {
@@ -471,6 +537,15 @@ func (c *basePluginClient) SetConfig(ctx context.Context, in *SetConfigRequest,
 	return out, nil
 }
 
+func (c *basePluginClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, "/hashicorp.nomad.plugins.base.proto.BasePlugin/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BasePluginServer is the server API for BasePlugin service.
 type BasePluginServer interface {
 	// PluginInfo describes the type and version of a plugin.
@@ -479,6 +554,8 @@ type BasePluginServer interface {
 	ConfigSchema(context.Context, *ConfigSchemaRequest) (*ConfigSchemaResponse, error)
 	// SetConfig is used to set the configuration.
 	SetConfig(context.Context, *SetConfigRequest) (*SetConfigResponse, error)
+	// Ping is used to check that the plugin is alive and responsive.
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
 }
 
 func RegisterBasePluginServer(s *grpc.Server, srv BasePluginServer) {
@@ -539,6 +616,24 @@ func _BasePlugin_SetConfig_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BasePlugin_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BasePluginServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hashicorp.nomad.plugins.base.proto.BasePlugin/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BasePluginServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BasePlugin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hashicorp.nomad.plugins.base.proto.BasePlugin",
 	HandlerType: (*BasePluginServer)(nil),
@@ -555,47 +650,54 @@ var _BasePlugin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetConfig",
 			Handler:    _BasePlugin_SetConfig_Handler,
 		},
+		{
+			MethodName: "Ping",
+			Handler:    _BasePlugin_Ping_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "plugins/base/proto/base.proto",
 }
 
-func init() { proto.RegisterFile("plugins/base/proto/base.proto", fileDescriptor_base_f2480776612a8fbd) }
+func init() {
+	proto.RegisterFile("plugins/base/proto/base.proto", fileDescriptor_base_f2480776612a8fbd)
+}
 
 var fileDescriptor_base_f2480776612a8fbd = []byte{
-	// 535 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x41, 0x8f, 0x12, 0x4d,
-	0x10, 0xdd, 0x01, 0x3e, 0x36, 0x14, 0xb0, 0x81, 0xe6, 0x33, 0x21, 0x24, 0x26, 0x64, 0xa2, 0x09,
-	0x31, 0x9b, 0x9e, 0x88, 0xa2, 0x1e, 0x57, 0x58, 0x0e, 0xc4, 0x2c, 0x6e, 0x06, 0x45, 0x63, 0x4c,
-	0x48, 0x33, 0xf4, 0x32, 0x1d, 0xa1, 0xbb, 0x9d, 0x1e, 0x36, 0xae, 0x89, 0x27, 0xcf, 0xfe, 0x22,
-	0x8f, 0xfe, 0x31, 0x33, 0xdd, 0x0d, 0x0c, 0xbb, 0x1a, 0xe1, 0x34, 0x45, 0xd5, 0x7b, 0xaf, 0xaa,
-	0x1e, 0xd5, 0x70, 0x5f, 0x2e, 0x56, 0x73, 0xc6, 0x95, 0x37, 0x25, 0x8a, 0x7a, 0x32, 0x12, 0xb1,
-	0xd0, 0x21, 0xd6, 0x21, 0x72, 0x43, 0xa2, 0x42, 0x16, 0x88, 0x48, 0x62, 0x2e, 0x96, 0x64, 0x86,
-	0x2d, 0x1c, 0x6f, 0x31, 0x8d, 0xb3, 0x39, 0x8b, 0xc3, 0xd5, 0x14, 0x07, 0x62, 0xe9, 0x6d, 0xe0,
-	0x9e, 0x86, 0x7b, 0x6b, 0x75, 0x15, 0x92, 0x88, 0xce, 0xbc, 0x30, 0x58, 0x28, 0x49, 0x83, 0xe4,
-	0x3b, 0x49, 0x02, 0xa3, 0xe0, 0xd6, 0xa0, 0x7a, 0xa9, 0x81, 0x03, 0x7e, 0x25, 0x7c, 0xfa, 0x79,
-	0x45, 0x55, 0xec, 0xfe, 0x72, 0x00, 0xa5, 0xb3, 0x4a, 0x0a, 0xae, 0x28, 0xea, 0x42, 0x2e, 0xbe,
-	0x91, 0xb4, 0xee, 0x34, 0x9d, 0xd6, 0x49, 0x1b, 0xe3, 0x7f, 0x0f, 0x88, 0x8d, 0xca, 0x9b, 0x1b,
-	0x49, 0x7d, 0xcd, 0x45, 0x18, 0x6a, 0x06, 0x36, 0x21, 0x92, 0x4d, 0xae, 0x69, 0xa4, 0x98, 0xe0,
-	0xaa, 0x9e, 0x69, 0x66, 0x5b, 0x05, 0xbf, 0x6a, 0x4a, 0x2f, 0x25, 0x1b, 0xdb, 0x02, 0x7a, 0x08,
-	0x27, 0x16, 0x6f, 0xb1, 0xf5, 0x6c, 0xd3, 0x69, 0x15, 0xfc, 0xb2, 0xc9, 0x5a, 0x1c, 0x42, 0x90,
-	0xe3, 0x64, 0x49, 0xeb, 0x39, 0x5d, 0xd4, 0xb1, 0x7b, 0x0f, 0x6a, 0x3d, 0xc1, 0xaf, 0xd8, 0x7c,
-	0x14, 0x84, 0x74, 0x49, 0xd6, 0xcb, 0xbd, 0x87, 0xff, 0x77, 0xd3, 0x76, 0xbb, 0x33, 0xc8, 0x25,
-	0xbe, 0xe8, 0xed, 0x8a, 0xed, 0xd3, 0xbf, 0x6e, 0x67, 0xfc, 0xc4, 0xd6, 0x4f, 0x3c, 0x92, 0x34,
-	0xf0, 0x35, 0xd3, 0xfd, 0xe9, 0x40, 0x65, 0x44, 0x63, 0xa3, 0x6e, 0xdb, 0x25, 0x0b, 0x2c, 0xd5,
-	0x5c, 0x92, 0xe0, 0xd3, 0x24, 0xd0, 0x05, 0xdd, 0xa0, 0xe4, 0x97, 0x6d, 0xd6, 0xa0, 0x91, 0x0f,
-	0x25, 0xdd, 0x66, 0x0d, 0xca, 0xe8, 0x29, 0xbc, 0x7d, 0x3c, 0x1e, 0x26, 0x05, 0xdb, 0xb4, 0xc8,
-	0xb7, 0x3f, 0xd0, 0x29, 0xa0, 0xbb, 0x5e, 0x5b, 0xff, 0x2a, 0xb7, 0xad, 0x76, 0x3f, 0x42, 0x31,
-	0xa5, 0x84, 0x2e, 0x20, 0x3f, 0x8b, 0xd8, 0x35, 0x8d, 0xac, 0x21, 0x9d, 0xbd, 0x47, 0x39, 0xd7,
-	0x34, 0x3b, 0x90, 0x15, 0x71, 0x27, 0x50, 0xbd, 0x53, 0x44, 0x0f, 0xa0, 0xdc, 0x5b, 0x30, 0xca,
-	0xe3, 0x0b, 0xf2, 0xe5, 0x52, 0x44, 0xb1, 0x6e, 0x55, 0xf6, 0x77, 0x93, 0x29, 0x14, 0xe3, 0x1a,
-	0x95, 0xd9, 0x41, 0x99, 0x64, 0x72, 0xc8, 0x29, 0xef, 0xcd, 0x7f, 0xfa, 0xe8, 0x31, 0xc0, 0xf6,
-	0x02, 0x51, 0x11, 0x8e, 0xdf, 0x0e, 0x5f, 0x0d, 0x5f, 0xbf, 0x1b, 0x56, 0x8e, 0x10, 0x40, 0xfe,
-	0xdc, 0x1f, 0x8c, 0xfb, 0x7e, 0x25, 0xa3, 0xe3, 0xfe, 0x78, 0xd0, 0xeb, 0x57, 0xb2, 0xed, 0x1f,
-	0x59, 0x80, 0x2e, 0x51, 0xd4, 0xf0, 0xd0, 0xb7, 0xb5, 0x42, 0xf2, 0x12, 0x50, 0x67, 0xff, 0x9b,
-	0x4f, 0xbd, 0xa7, 0xc6, 0xb3, 0x43, 0x69, 0x66, 0x7c, 0xf7, 0x08, 0x7d, 0x77, 0xa0, 0x94, 0xbe,
-	0x56, 0xf4, 0x7c, 0x1f, 0xa9, 0x3f, 0x9c, 0x7d, 0xe3, 0xc5, 0xe1, 0xc4, 0xcd, 0x14, 0x5f, 0xa1,
-	0xb0, 0xf1, 0x16, 0x3d, 0xdd, 0x47, 0xe8, 0xf6, 0x33, 0x68, 0x74, 0x0e, 0x64, 0xad, 0x7b, 0x77,
-	0x8f, 0x3f, 0xfc, 0xa7, 0x8b, 0xd3, 0xbc, 0xfe, 0x3c, 0xf9, 0x1d, 0x00, 0x00, 0xff, 0xff, 0xcc,
-	0x26, 0x80, 0xcf, 0x37, 0x05, 0x00, 0x00,
+	// 559 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x53, 0x5d, 0x8f, 0xd2, 0x40,
+	0x14, 0xdd, 0x42, 0x65, 0xc3, 0x2d, 0x25, 0x30, 0x68, 0x42, 0x9a, 0x98, 0x6c, 0x1a, 0x4d, 0x36,
+	0x66, 0x33, 0x5d, 0x71, 0x51, 0x1f, 0x57, 0x58, 0x1e, 0x88, 0x59, 0x24, 0x45, 0xd1, 0x18, 0x13,
+	0x52, 0xca, 0x2c, 0x9d, 0x2c, 0xfd, 0xd8, 0xb6, 0x18, 0x35, 0xf1, 0xc9, 0x9f, 0xe5, 0xa3, 0xff,
+	0xca, 0x27, 0xdb, 0x99, 0x29, 0x94, 0x5d, 0x8d, 0xe5, 0xa5, 0xbd, 0x73, 0xef, 0x39, 0xe7, 0x7e,
+	0xcc, 0x5c, 0x78, 0x18, 0xac, 0xd6, 0x4b, 0xea, 0x45, 0xc6, 0xdc, 0x8a, 0x88, 0x11, 0x84, 0x7e,
+	0xec, 0x33, 0x13, 0x33, 0x13, 0xe9, 0x8e, 0x15, 0x39, 0xd4, 0xf6, 0xc3, 0x00, 0x7b, 0xbe, 0x6b,
+	0x2d, 0xb0, 0x80, 0xe3, 0x2d, 0x46, 0x3b, 0x5f, 0xd2, 0xd8, 0x59, 0xcf, 0xb1, 0xed, 0xbb, 0xc6,
+	0x06, 0x6e, 0x30, 0xb8, 0x91, 0xa9, 0x47, 0x8e, 0x15, 0x92, 0x85, 0xe1, 0xd8, 0xab, 0x28, 0x20,
+	0x76, 0xfa, 0x9f, 0xa5, 0x06, 0x57, 0xd0, 0x5b, 0xd0, 0x1c, 0x33, 0xe0, 0xd0, 0xbb, 0xf2, 0x4d,
+	0x72, 0xb3, 0x26, 0x51, 0xac, 0xff, 0x92, 0x00, 0xe5, 0xbd, 0x51, 0xe0, 0x7b, 0x11, 0x41, 0x3d,
+	0x90, 0xe3, 0xaf, 0x01, 0x69, 0x4b, 0x47, 0xd2, 0x71, 0xbd, 0x83, 0xf1, 0xff, 0x0b, 0xc4, 0x5c,
+	0xe5, 0x6d, 0xc2, 0x32, 0x19, 0x17, 0x61, 0x68, 0x71, 0xd8, 0xcc, 0x0a, 0xe8, 0xec, 0x33, 0x09,
+	0x23, 0x9a, 0x68, 0xb7, 0x4b, 0x47, 0xe5, 0xe3, 0xaa, 0xd9, 0xe4, 0xa1, 0x57, 0x01, 0x9d, 0x8a,
+	0x00, 0x7a, 0x0c, 0x75, 0x81, 0x17, 0xd8, 0x76, 0x39, 0xc9, 0x5e, 0x35, 0x55, 0xee, 0x15, 0x38,
+	0x84, 0x40, 0xf6, 0x2c, 0x97, 0xb4, 0x65, 0x16, 0x64, 0xb6, 0xfe, 0x00, 0x5a, 0x7d, 0xdf, 0xbb,
+	0xa2, 0xcb, 0x89, 0xed, 0x10, 0xd7, 0xca, 0x9a, 0xfb, 0x00, 0xf7, 0x77, 0xdd, 0xa2, 0xbb, 0x73,
+	0x90, 0xd3, 0xb9, 0xb0, 0xee, 0x94, 0xce, 0xc9, 0x3f, 0xbb, 0xe3, 0xf3, 0xc4, 0x62, 0x9e, 0x78,
+	0x92, 0x7c, 0x4c, 0xc6, 0xd4, 0x7f, 0x4a, 0xd0, 0x98, 0x90, 0x98, 0xab, 0x8b, 0x74, 0x69, 0x03,
+	0x6e, 0xb4, 0x0c, 0x2c, 0xfb, 0x7a, 0x66, 0xb3, 0x00, 0x4b, 0x50, 0x33, 0x55, 0xe1, 0xe5, 0x68,
+	0x64, 0x42, 0x8d, 0xa5, 0xc9, 0x40, 0x25, 0x56, 0x85, 0x51, 0x64, 0xc6, 0xa3, 0x34, 0x20, 0x92,
+	0x2a, 0xde, 0xf6, 0x80, 0x4e, 0x00, 0xdd, 0x9d, 0xb5, 0x98, 0x5f, 0xe3, 0xf6, 0xa8, 0xf5, 0x4f,
+	0xa0, 0xe4, 0x94, 0xd0, 0x25, 0x54, 0x16, 0x21, 0x4d, 0x48, 0x62, 0x20, 0xdd, 0xc2, 0xa5, 0x5c,
+	0x30, 0x9a, 0x28, 0x48, 0x88, 0xe8, 0x33, 0x68, 0xde, 0x09, 0xa2, 0x47, 0xa0, 0xf6, 0x57, 0x94,
+	0x78, 0xf1, 0xa5, 0xf5, 0x65, 0xec, 0x87, 0x31, 0x4b, 0xa5, 0x9a, 0xbb, 0xce, 0x1c, 0x8a, 0x7a,
+	0x0c, 0x55, 0xda, 0x41, 0x71, 0x67, 0xfa, 0x90, 0x73, 0xb3, 0xe7, 0x77, 0xaa, 0xab, 0xa0, 0x8c,
+	0xa9, 0x97, 0xdd, 0x85, 0x5e, 0x87, 0x1a, 0x3f, 0xf2, 0xf0, 0x93, 0xa7, 0x00, 0xdb, 0x07, 0x8a,
+	0x14, 0x38, 0x7c, 0x37, 0x7a, 0x3d, 0x7a, 0xf3, 0x7e, 0xd4, 0x38, 0x40, 0x00, 0x95, 0x0b, 0x73,
+	0x38, 0x1d, 0x98, 0x8d, 0x12, 0xb3, 0x07, 0xd3, 0x61, 0x7f, 0xd0, 0x28, 0x77, 0x7e, 0x97, 0x01,
+	0x7a, 0x49, 0xc3, 0x9c, 0x87, 0xbe, 0x67, 0x0a, 0xe9, 0xa2, 0xa0, 0x6e, 0xf1, 0x95, 0xc8, 0xad,
+	0x9b, 0xf6, 0x7c, 0x5f, 0x9a, 0xe8, 0xee, 0x00, 0xfd, 0x90, 0xa0, 0x96, 0x7f, 0xcc, 0xe8, 0x45,
+	0x11, 0xa9, 0xbf, 0x6c, 0x85, 0xf6, 0x72, 0x7f, 0xe2, 0xa6, 0x8a, 0x6f, 0x50, 0xdd, 0x8c, 0x1e,
+	0x9d, 0x15, 0x11, 0xba, 0xbd, 0x25, 0x5a, 0x77, 0x4f, 0xd6, 0x26, 0xf7, 0x35, 0xc8, 0xe9, 0x95,
+	0xa2, 0x42, 0x9b, 0x92, 0x7b, 0x0b, 0xda, 0x69, 0x71, 0x42, 0x96, 0xac, 0x77, 0xf8, 0xf1, 0x1e,
+	0xf3, 0xcf, 0x2b, 0xec, 0xf7, 0xec, 0x0f, 0xef, 0xb3, 0x0c, 0xcb, 0xc3, 0x05, 0x00, 0x00,
 }
@@ -84,3 +84,7 @@ func (b *basePluginServer) SetConfig(ctx context.Context, req *proto.SetConfigRe
 
 	return &proto.SetConfigResponse{}, nil
 }
+
+func (b *basePluginServer) Ping(context.Context, *proto.PingRequest) (*proto.PingResponse, error) {
+	return &proto.PingResponse{}, nil
+}
@@ -13,6 +13,9 @@ import (
 const (
 	// DeviceTypeGPU is a canonical device type for a GPU.
 	DeviceTypeGPU = "gpu"
+
+	// DeviceTypeNIC is a canonical device type for a network interface.
+	DeviceTypeNIC = "nic"
 )
 
 // DevicePlugin is the interface for a plugin that can expose detected devices
@@ -188,6 +188,7 @@ func (d *driverPluginClient) handleWaitTask(ctx context.Context, id string, ch c
 		result.ExitCode = int(resp.Result.ExitCode)
 		result.Signal = int(resp.Result.Signal)
 		result.OOMKilled = resp.Result.OomKilled
+		result.Details = resp.Result.Details
 		if len(resp.Err) > 0 {
 			result.Err = errors.New(resp.Err)
 		}
@@ -346,6 +347,9 @@ func (d *driverPluginClient) handleTaskEvents(reqCtx context.Context, ch chan *T
 			Message:     ev.Message,
 			Timestamp:   timestamp,
 		}
+		if ev.ExitResult != nil {
+			event.ExitResult = exitResultFromProto(ev.ExitResult)
+		}
 		select {
 		case <-reqCtx.Done():
 			return
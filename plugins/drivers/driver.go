@@ -304,11 +304,25 @@ const (
 
 type TaskState string
 
+const (
+	// ExitDetailMemoryRSS is the ExitResult detail holding the resident set
+	// size of the task when it was last measured, in bytes
+	ExitDetailMemoryRSS = "memory_rss"
+
+	// ExitDetailMemoryLimit is the ExitResult detail holding the memory
+	// limit the task was subject to, in bytes
+	ExitDetailMemoryLimit = "memory_limit"
+)
+
 type ExitResult struct {
 	ExitCode  int
 	Signal    int
 	OOMKilled bool
 	Err       error
+
+	// Details contains driver specific details about the exit. Drivers
+	// should use the ExitDetail keys where they apply.
+	Details map[string]string
 }
 
 func (r *ExitResult) Successful() bool {
@@ -321,6 +335,7 @@ func (r *ExitResult) Copy() *ExitResult {
 	}
 	res := new(ExitResult)
 	*res = *r
+	res.Details = helper.CopyMapStringString(r.Details)
 	return res
 }
 
@@ -343,6 +358,10 @@ type TaskEvent struct {
 	Message     string
 	Annotations map[string]string
 
+	// ExitResult is set by drivers reporting the exit of the task with the
+	// event
+	ExitResult *ExitResult
+
 	// Err is only used if an error occurred while consuming the RPC stream
 	Err error
 }
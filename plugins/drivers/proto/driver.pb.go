@@ -2176,10 +2176,13 @@ type ExitResult struct {
 	// Signal is set if a signal was sent to the task
 	Signal int32 `protobuf:"varint,2,opt,name=signal,proto3" json:"signal,omitempty"`
 	// OomKilled is true if the task exited as a result of the OOM Killer
-	OomKilled            bool     `protobuf:"varint,3,opt,name=oom_killed,json=oomKilled,proto3" json:"oom_killed,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	OomKilled bool `protobuf:"varint,3,opt,name=oom_killed,json=oomKilled,proto3" json:"oom_killed,omitempty"`
+	// Details contains driver specific details about the exit, such as the
+	// memory usage and limit of an OOM killed task
+	Details              map[string]string `protobuf:"bytes,4,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ExitResult) Reset()         { *m = ExitResult{} }
@@ -2227,6 +2230,13 @@ func (m *ExitResult) GetOomKilled() bool {
 	return false
 }
 
+func (m *ExitResult) GetDetails() map[string]string {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
+
 // TaskStatus includes information of a specific task
 type TaskStatus struct {
 	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -2651,10 +2661,12 @@ type DriverTaskEvent struct {
 	// Message is the body of the event
 	Message string `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
 	// Annotations allows for additional key/value data to be sent along with the event
-	Annotations          map[string]string `protobuf:"bytes,6,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Annotations map[string]string `protobuf:"bytes,6,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// ExitResult is set if the event reports the exit of the task
+	ExitResult           *ExitResult `protobuf:"bytes,7,opt,name=exit_result,json=exitResult,proto3" json:"exit_result,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
 func (m *DriverTaskEvent) Reset()         { *m = DriverTaskEvent{} }
@@ -2723,6 +2735,13 @@ func (m *DriverTaskEvent) GetAnnotations() map[string]string {
 	return nil
 }
 
+func (m *DriverTaskEvent) GetExitResult() *ExitResult {
+	if m != nil {
+		return m.ExitResult
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*TaskConfigSchemaRequest)(nil), "hashicorp.nomad.plugins.drivers.proto.TaskConfigSchemaRequest")
 	proto.RegisterType((*TaskConfigSchemaResponse)(nil), "hashicorp.nomad.plugins.drivers.proto.TaskConfigSchemaResponse")
@@ -2767,6 +2786,7 @@ func init() {
 	proto.RegisterType((*NetworkOverride)(nil), "hashicorp.nomad.plugins.drivers.proto.NetworkOverride")
 	proto.RegisterMapType((map[string]int32)(nil), "hashicorp.nomad.plugins.drivers.proto.NetworkOverride.PortMapEntry")
 	proto.RegisterType((*ExitResult)(nil), "hashicorp.nomad.plugins.drivers.proto.ExitResult")
+	proto.RegisterMapType((map[string]string)(nil), "hashicorp.nomad.plugins.drivers.proto.ExitResult.DetailsEntry")
 	proto.RegisterType((*TaskStatus)(nil), "hashicorp.nomad.plugins.drivers.proto.TaskStatus")
 	proto.RegisterType((*TaskDriverStatus)(nil), "hashicorp.nomad.plugins.drivers.proto.TaskDriverStatus")
 	proto.RegisterMapType((map[string]string)(nil), "hashicorp.nomad.plugins.drivers.proto.TaskDriverStatus.AttributesEntry")
@@ -3400,192 +3420,194 @@ func init() {
 }
 
 var fileDescriptor_driver_50fc54a49fb06bcb = []byte{
-	// 2990 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0xcb, 0x6f, 0x23, 0xc7,
-	0xd1, 0x17, 0x9f, 0x22, 0x8b, 0x12, 0x35, 0xdb, 0xbb, 0x6b, 0xd3, 0x34, 0xbe, 0xcf, 0xeb, 0x01,
-	0xfc, 0x41, 0xb0, 0xbd, 0x94, 0x2d, 0xe3, 0xdb, 0x57, 0xfc, 0xa2, 0xc9, 0x59, 0x49, 0x5e, 0x89,
-	0x52, 0x9a, 0x14, 0xd6, 0x9b, 0xc4, 0x3b, 0x19, 0xcd, 0xf4, 0x92, 0xb3, 0x9a, 0x97, 0x67, 0x7a,
-	0x64, 0x09, 0x41, 0x90, 0xc0, 0x01, 0x82, 0xe4, 0x10, 0x20, 0x17, 0x23, 0xf7, 0xe4, 0x98, 0xbf,
-	0x20, 0x09, 0xfc, 0x97, 0x24, 0x97, 0x04, 0x08, 0x90, 0x6b, 0x8e, 0xb9, 0x05, 0xfd, 0x98, 0xe1,
-	0x50, 0xd2, 0x7a, 0x87, 0x5c, 0x9f, 0x66, 0xba, 0xba, 0xeb, 0xd7, 0xd5, 0x5d, 0xd5, 0x55, 0xd5,
-	0x5d, 0xa0, 0x06, 0x4e, 0x3c, 0xb6, 0xbd, 0x68, 0xc3, 0x0a, 0xed, 0x13, 0x12, 0x46, 0x1b, 0x41,
-	0xe8, 0x53, 0x5f, 0xb6, 0x3a, 0xbc, 0x81, 0xde, 0x98, 0x18, 0xd1, 0xc4, 0x36, 0xfd, 0x30, 0xe8,
-	0x78, 0xbe, 0x6b, 0x58, 0x1d, 0xc9, 0xd3, 0x91, 0x3c, 0x62, 0x58, 0xfb, 0x7f, 0xc7, 0xbe, 0x3f,
-	0x76, 0x88, 0x40, 0x38, 0x8a, 0x9f, 0x6c, 0x58, 0x71, 0x68, 0x50, 0xdb, 0xf7, 0x64, 0xff, 0x6b,
-	0xe7, 0xfb, 0xa9, 0xed, 0x92, 0x88, 0x1a, 0x6e, 0x20, 0x07, 0x7c, 0x3c, 0xb6, 0xe9, 0x24, 0x3e,
-	0xea, 0x98, 0xbe, 0xbb, 0x91, 0x4e, 0xb9, 0xc1, 0xa7, 0xdc, 0x48, 0xc4, 0x8c, 0x26, 0x46, 0x48,
-	0xac, 0x8d, 0x89, 0xe9, 0x44, 0x01, 0x31, 0xd9, 0x57, 0x67, 0x3f, 0x12, 0x61, 0x2b, 0x3f, 0x42,
-	0x44, 0xc3, 0xd8, 0xa4, 0xc9, 0x7a, 0x0d, 0x4a, 0x43, 0xfb, 0x28, 0xa6, 0x44, 0x00, 0xa9, 0xaf,
-	0xc0, 0xcb, 0x23, 0x23, 0x3a, 0xee, 0xf9, 0xde, 0x13, 0x7b, 0x3c, 0x34, 0x27, 0xc4, 0x35, 0x30,
-	0xf9, 0x22, 0x26, 0x11, 0x55, 0x7f, 0x04, 0xad, 0x8b, 0x5d, 0x51, 0xe0, 0x7b, 0x11, 0x41, 0x1f,
-	0x43, 0x99, 0x49, 0xd3, 0x2a, 0xdc, 0x28, 0xac, 0x37, 0x36, 0xdf, 0xee, 0x3c, 0x6b, 0xe3, 0x84,
-	0x0c, 0x1d, 0xb9, 0x8a, 0xce, 0x30, 0x20, 0x26, 0xe6, 0x9c, 0xea, 0x75, 0xb8, 0xda, 0x33, 0x02,
-	0xe3, 0xc8, 0x76, 0x6c, 0x6a, 0x93, 0x28, 0x99, 0x34, 0x86, 0x6b, 0xb3, 0x64, 0x39, 0xe1, 0xe7,
-	0xb0, 0x62, 0x66, 0xe8, 0x72, 0xe2, 0xbb, 0x9d, 0x5c, 0x1a, 0xeb, 0xf4, 0x79, 0x6b, 0x06, 0x78,
-	0x06, 0x4e, 0xbd, 0x06, 0xe8, 0xbe, 0xed, 0x8d, 0x49, 0x18, 0x84, 0xb6, 0x47, 0x13, 0x61, 0xbe,
-	0x29, 0xc1, 0xd5, 0x19, 0xb2, 0x14, 0xe6, 0x29, 0x40, 0xba, 0x8f, 0x4c, 0x94, 0xd2, 0x7a, 0x63,
-	0xf3, 0xd3, 0x9c, 0xa2, 0x5c, 0x82, 0xd7, 0xe9, 0xa6, 0x60, 0x9a, 0x47, 0xc3, 0x33, 0x9c, 0x41,
-	0x47, 0x8f, 0xa1, 0x3a, 0x21, 0x86, 0x43, 0x27, 0xad, 0xe2, 0x8d, 0xc2, 0x7a, 0x73, 0xf3, 0xfe,
-	0x0b, 0xcc, 0xb3, 0xcd, 0x81, 0x86, 0xd4, 0xa0, 0x04, 0x4b, 0x54, 0x74, 0x13, 0x90, 0xf8, 0xd3,
-	0x2d, 0x12, 0x99, 0xa1, 0x1d, 0x30, 0x43, 0x6e, 0x95, 0x6e, 0x14, 0xd6, 0xeb, 0xf8, 0x8a, 0xe8,
-	0xe9, 0x4f, 0x3b, 0xda, 0x01, 0xac, 0x9d, 0x93, 0x16, 0x29, 0x50, 0x3a, 0x26, 0x67, 0x5c, 0x23,
-	0x75, 0xcc, 0x7e, 0xd1, 0x16, 0x54, 0x4e, 0x0c, 0x27, 0x26, 0x5c, 0xe4, 0xc6, 0xe6, 0xbb, 0xcf,
-	0x33, 0x0f, 0x69, 0xa2, 0xd3, 0x7d, 0xc0, 0x82, 0xff, 0x5e, 0xf1, 0x4e, 0x41, 0xbd, 0x0b, 0x8d,
-	0x8c, 0xdc, 0xa8, 0x09, 0x70, 0x38, 0xe8, 0x6b, 0x23, 0xad, 0x37, 0xd2, 0xfa, 0xca, 0x12, 0x5a,
-	0x85, 0xfa, 0xe1, 0x60, 0x5b, 0xeb, 0xee, 0x8e, 0xb6, 0x1f, 0x29, 0x05, 0xd4, 0x80, 0xe5, 0xa4,
-	0x51, 0x54, 0x4f, 0x01, 0x61, 0x62, 0xfa, 0x27, 0x24, 0x64, 0x86, 0x2c, 0xb5, 0x8a, 0x5e, 0x86,
-	0x65, 0x6a, 0x44, 0xc7, 0xba, 0x6d, 0x49, 0x99, 0xab, 0xac, 0xb9, 0x63, 0xa1, 0x1d, 0xa8, 0x4e,
-	0x0c, 0xcf, 0x72, 0x9e, 0x2f, 0xf7, 0xec, 0x56, 0x33, 0xf0, 0x6d, 0xce, 0x88, 0x25, 0x00, 0xb3,
-	0xee, 0x99, 0x99, 0x85, 0x02, 0xd4, 0x47, 0xa0, 0x0c, 0xa9, 0x11, 0xd2, 0xac, 0x38, 0x1a, 0x94,
-	0xd9, 0xfc, 0xd2, 0xa2, 0xe7, 0x99, 0x53, 0x9c, 0x4c, 0xcc, 0xd9, 0xd5, 0x7f, 0x17, 0xe1, 0x4a,
-	0x06, 0x5b, 0x5a, 0xea, 0x43, 0xa8, 0x86, 0x24, 0x8a, 0x1d, 0xca, 0xe1, 0x9b, 0x9b, 0x1f, 0xe5,
-	0x84, 0xbf, 0x80, 0xd4, 0xc1, 0x1c, 0x06, 0x4b, 0x38, 0xb4, 0x0e, 0x8a, 0xe0, 0xd0, 0x49, 0x18,
-	0xfa, 0xa1, 0xee, 0x46, 0x63, 0xbe, 0x6b, 0x75, 0xdc, 0x14, 0x74, 0x8d, 0x91, 0xf7, 0xa2, 0x71,
-	0x66, 0x57, 0x4b, 0x2f, 0xb8, 0xab, 0xc8, 0x00, 0xc5, 0x23, 0xf4, 0x4b, 0x3f, 0x3c, 0xd6, 0xd9,
-	0xd6, 0x86, 0xb6, 0x45, 0x5a, 0x65, 0x0e, 0x7a, 0x2b, 0x27, 0xe8, 0x40, 0xb0, 0xef, 0x4b, 0x6e,
-	0xbc, 0xe6, 0xcd, 0x12, 0xd4, 0xb7, 0xa0, 0x2a, 0x56, 0xca, 0x2c, 0x69, 0x78, 0xd8, 0xeb, 0x69,
-	0xc3, 0xa1, 0xb2, 0x84, 0xea, 0x50, 0xc1, 0xda, 0x08, 0x33, 0x0b, 0xab, 0x43, 0xe5, 0x7e, 0x77,
-	0xd4, 0xdd, 0x55, 0x8a, 0xea, 0x9b, 0xb0, 0xf6, 0xd0, 0xb0, 0x69, 0x1e, 0xe3, 0x52, 0x7d, 0x50,
-	0xa6, 0x63, 0xa5, 0x76, 0x76, 0x66, 0xb4, 0x93, 0x7f, 0x6b, 0xb4, 0x53, 0x9b, 0x9e, 0xd3, 0x87,
-	0x02, 0x25, 0x12, 0x86, 0x52, 0x05, 0xec, 0x57, 0xfd, 0x12, 0xd6, 0x86, 0xd4, 0x0f, 0x72, 0x59,
-	0xfe, 0x7b, 0xb0, 0xcc, 0x62, 0x94, 0x1f, 0x53, 0x69, 0xfa, 0xaf, 0x74, 0x44, 0x0c, 0xeb, 0x24,
-	0x31, 0xac, 0xd3, 0x97, 0x31, 0x0e, 0x27, 0x23, 0xd1, 0x4b, 0x50, 0x8d, 0xec, 0xb1, 0x67, 0x38,
-	0xd2, 0x5b, 0xc8, 0x96, 0x8a, 0x98, 0x91, 0x27, 0x13, 0x4b, 0xc3, 0xef, 0x01, 0xea, 0x93, 0x88,
-	0x86, 0xfe, 0x59, 0x2e, 0x79, 0xae, 0x41, 0xe5, 0x89, 0x1f, 0x9a, 0xe2, 0x20, 0xd6, 0xb0, 0x68,
-	0xb0, 0x43, 0x35, 0x03, 0x22, 0xb1, 0x6f, 0x02, 0xda, 0xf1, 0x58, 0x4c, 0xc9, 0xa7, 0x88, 0xdf,
-	0x16, 0xe1, 0xea, 0xcc, 0x78, 0xa9, 0x8c, 0xc5, 0xcf, 0x21, 0x73, 0x4c, 0x71, 0x24, 0xce, 0x21,
-	0xda, 0x87, 0xaa, 0x18, 0x21, 0x77, 0xf2, 0xf6, 0x1c, 0x40, 0x22, 0x4c, 0x49, 0x38, 0x09, 0x73,
-	0xa9, 0xd1, 0x97, 0xbe, 0x5b, 0xa3, 0xff, 0x12, 0x94, 0x64, 0x1d, 0xd1, 0x73, 0x75, 0xf3, 0x29,
-	0x5c, 0x35, 0x7d, 0xc7, 0x21, 0x26, 0xb3, 0x06, 0xdd, 0xf6, 0x28, 0x09, 0x4f, 0x0c, 0xe7, 0xf9,
-	0x76, 0x83, 0xa6, 0x5c, 0x3b, 0x92, 0x49, 0xfd, 0x21, 0x5c, 0xc9, 0x4c, 0x2c, 0x15, 0x71, 0x1f,
-	0x2a, 0x11, 0x23, 0x48, 0x4d, 0xbc, 0x33, 0xa7, 0x26, 0x22, 0x2c, 0xd8, 0xd5, 0xab, 0x02, 0x5c,
-	0x3b, 0x21, 0x5e, 0xba, 0x2c, 0xb5, 0x0f, 0x57, 0x86, 0xdc, 0x4c, 0x73, 0xd9, 0xe1, 0xd4, 0xc4,
-	0x8b, 0x33, 0x26, 0x7e, 0x0d, 0x50, 0x16, 0x45, 0x1a, 0xe2, 0x19, 0xac, 0x69, 0xa7, 0xc4, 0xcc,
-	0x85, 0xdc, 0x82, 0x65, 0xd3, 0x77, 0x5d, 0xc3, 0xb3, 0x5a, 0xc5, 0x1b, 0xa5, 0xf5, 0x3a, 0x4e,
-	0x9a, 0xd9, 0xb3, 0x58, 0xca, 0x7b, 0x16, 0xd5, 0xdf, 0x14, 0x40, 0x99, 0xce, 0x2d, 0x37, 0x92,
-	0x49, 0x4f, 0x2d, 0x06, 0xc4, 0xe6, 0x5e, 0xc1, 0xb2, 0x25, 0xe9, 0x89, 0xbb, 0x10, 0x74, 0x12,
-	0x86, 0x19, 0x77, 0x54, 0x7a, 0x41, 0x77, 0xa4, 0xfe, 0xb3, 0x00, 0xe8, 0x62, 0xd2, 0x85, 0x5e,
-	0x87, 0x95, 0x88, 0x78, 0x96, 0x2e, 0xb6, 0x51, 0x68, 0xb8, 0x86, 0x1b, 0x8c, 0x26, 0xf6, 0x33,
-	0x42, 0x08, 0xca, 0xe4, 0x94, 0x98, 0xf2, 0xe4, 0xf3, 0x7f, 0x34, 0x81, 0x95, 0x27, 0x91, 0x6e,
-	0x47, 0xbe, 0x63, 0xa4, 0xd9, 0x49, 0x73, 0x53, 0x5b, 0x38, 0xf9, 0xeb, 0xdc, 0x1f, 0xee, 0x24,
-	0x60, 0xb8, 0xf1, 0x24, 0x4a, 0x1b, 0x6a, 0x07, 0x1a, 0x99, 0x3e, 0x54, 0x83, 0xf2, 0x60, 0x7f,
-	0xa0, 0x29, 0x4b, 0x08, 0xa0, 0xda, 0xdb, 0xc6, 0xfb, 0xfb, 0x23, 0x11, 0x01, 0x76, 0xf6, 0xba,
-	0x5b, 0x9a, 0x52, 0x54, 0xff, 0x54, 0x05, 0x98, 0x86, 0x62, 0xd4, 0x84, 0x62, 0xaa, 0xe9, 0xa2,
-	0x6d, 0xb1, 0xc5, 0x78, 0x86, 0x4b, 0xa4, 0xf5, 0xf0, 0x7f, 0xb4, 0x09, 0xd7, 0xdd, 0x68, 0x1c,
-	0x18, 0xe6, 0xb1, 0x2e, 0x23, 0xa8, 0xc9, 0x99, 0xf9, 0xaa, 0x56, 0xf0, 0x55, 0xd9, 0x29, 0xa5,
-	0x16, 0xb8, 0xbb, 0x50, 0x22, 0xde, 0x49, 0xab, 0xcc, 0x33, 0xcd, 0x7b, 0x73, 0xa7, 0x08, 0x1d,
-	0xcd, 0x3b, 0x11, 0x99, 0x25, 0x83, 0x41, 0x3a, 0x80, 0x45, 0x4e, 0x6c, 0x93, 0xe8, 0x0c, 0xb4,
-	0xc2, 0x41, 0x3f, 0x9e, 0x1f, 0xb4, 0xcf, 0x31, 0x52, 0xe8, 0xba, 0x95, 0xb4, 0xd1, 0x00, 0xea,
-	0x21, 0x89, 0xfc, 0x38, 0x34, 0x49, 0xd4, 0xaa, 0xce, 0x75, 0x8a, 0x71, 0xc2, 0x87, 0xa7, 0x10,
-	0xa8, 0x0f, 0x55, 0xd7, 0x8f, 0x3d, 0x1a, 0xb5, 0x96, 0xb9, 0xb0, 0x6f, 0xe7, 0x04, 0xdb, 0x63,
-	0x4c, 0x58, 0xf2, 0xa2, 0x2d, 0x58, 0x16, 0x22, 0x46, 0xad, 0x1a, 0x87, 0xb9, 0x99, 0xd7, 0x80,
-	0x38, 0x17, 0x4e, 0xb8, 0x99, 0x56, 0xe3, 0x88, 0x84, 0xad, 0xba, 0xd0, 0x2a, 0xfb, 0x47, 0xaf,
-	0x42, 0xdd, 0x70, 0x1c, 0xdf, 0xd4, 0x2d, 0x3b, 0x6c, 0x01, 0xef, 0xa8, 0x71, 0x42, 0xdf, 0x0e,
-	0xd1, 0x6b, 0xd0, 0x10, 0x47, 0x4f, 0x0f, 0x0c, 0x3a, 0x69, 0x35, 0x78, 0x37, 0x08, 0xd2, 0x81,
-	0x41, 0x27, 0x72, 0x00, 0x09, 0x43, 0x31, 0x60, 0x25, 0x1d, 0x40, 0xc2, 0x90, 0x0f, 0xf8, 0x3f,
-	0x58, 0xe3, 0x7e, 0x64, 0x1c, 0xfa, 0x71, 0xa0, 0x73, 0x9b, 0x5a, 0xe5, 0x83, 0x56, 0x19, 0x79,
-	0x8b, 0x51, 0x07, 0xcc, 0xb8, 0x5e, 0x81, 0xda, 0x53, 0xff, 0x48, 0x0c, 0x68, 0xf2, 0x01, 0xcb,
-	0x4f, 0xfd, 0xa3, 0xa4, 0x4b, 0x48, 0x68, 0x5b, 0xad, 0x35, 0xd1, 0xc5, 0xdb, 0x3b, 0x56, 0xfb,
-	0x16, 0xd4, 0x12, 0x35, 0x5e, 0x92, 0xcd, 0x5f, 0xcb, 0x66, 0xf3, 0xf5, 0x4c, 0x6a, 0xde, 0x7e,
-	0x1f, 0x9a, 0xb3, 0x46, 0x30, 0x0f, 0xb7, 0xfa, 0xd7, 0x02, 0xd4, 0x53, 0x75, 0x23, 0x0f, 0xae,
-	0x72, 0x71, 0x0c, 0x4a, 0x2c, 0x7d, 0x6a, 0x3d, 0x22, 0x06, 0x7c, 0x90, 0x53, 0x53, 0xdd, 0x04,
-	0x41, 0xfa, 0x41, 0x69, 0x4a, 0x28, 0x45, 0x9e, 0xce, 0xf7, 0x18, 0xd6, 0x1c, 0xdb, 0x8b, 0x4f,
-	0x33, 0x73, 0x89, 0x10, 0xf6, 0xff, 0x39, 0xe7, 0xda, 0x65, 0xdc, 0xd3, 0x39, 0x9a, 0xce, 0x4c,
-	0x5b, 0xfd, 0xba, 0x08, 0x2f, 0x5d, 0x2e, 0x0e, 0x1a, 0x40, 0xc9, 0x0c, 0x62, 0xb9, 0xb4, 0xf7,
-	0xe7, 0x5d, 0x5a, 0x2f, 0x88, 0xa7, 0xb3, 0x32, 0x20, 0x96, 0xe4, 0xbb, 0xc4, 0xf5, 0xc3, 0x33,
-	0xb9, 0x82, 0x8f, 0xe6, 0x85, 0xdc, 0xe3, 0xdc, 0x53, 0x54, 0x09, 0x87, 0x30, 0xd4, 0x64, 0xaa,
-	0x10, 0x49, 0x37, 0x31, 0x67, 0xca, 0x91, 0x40, 0xe2, 0x14, 0x47, 0xbd, 0x05, 0xd7, 0x2f, 0x5d,
-	0x0a, 0xfa, 0x1f, 0x00, 0x33, 0x88, 0x75, 0x7e, 0x25, 0x14, 0x7a, 0x2f, 0xe1, 0xba, 0x19, 0xc4,
-	0x43, 0x4e, 0x50, 0x6f, 0x43, 0xeb, 0x59, 0xf2, 0xb2, 0xc3, 0x27, 0x24, 0xd6, 0xdd, 0x23, 0xbe,
-	0x07, 0x25, 0x5c, 0x13, 0x84, 0xbd, 0x23, 0xf5, 0x77, 0x45, 0x58, 0x3b, 0x27, 0x0e, 0x8b, 0x80,
-	0xe2, 0x30, 0x27, 0x51, 0x59, 0xb4, 0xd8, 0xc9, 0x36, 0x6d, 0x2b, 0x49, 0xa3, 0xf9, 0x3f, 0xf7,
-	0xe9, 0x81, 0x4c, 0x71, 0x8b, 0x76, 0xc0, 0x0c, 0xda, 0x3d, 0xb2, 0x69, 0xc4, 0x6f, 0x1e, 0x15,
-	0x2c, 0x1a, 0xe8, 0x11, 0x34, 0x43, 0x12, 0x91, 0xf0, 0x84, 0x58, 0x7a, 0xe0, 0x87, 0x34, 0xd9,
-	0xb0, 0xcd, 0xf9, 0x36, 0xec, 0xc0, 0x0f, 0x29, 0x5e, 0x4d, 0x90, 0x58, 0x2b, 0x42, 0x0f, 0x61,
-	0xd5, 0x3a, 0xf3, 0x0c, 0xd7, 0x36, 0x25, 0x72, 0x75, 0x61, 0xe4, 0x15, 0x09, 0xc4, 0x81, 0xd9,
-	0xcd, 0x3a, 0xd3, 0xc9, 0x16, 0xe6, 0x18, 0x47, 0xc4, 0x91, 0x7b, 0x22, 0x1a, 0xb3, 0xe7, 0xb7,
-	0x22, 0xcf, 0xaf, 0xfa, 0x87, 0x22, 0x34, 0x67, 0x0f, 0x40, 0xa2, 0xbf, 0x80, 0x84, 0xb6, 0x6f,
-	0x65, 0xf4, 0x77, 0xc0, 0x09, 0x4c, 0x47, 0xac, 0xfb, 0x8b, 0xd8, 0xa7, 0x46, 0xa2, 0x23, 0x33,
-	0x88, 0xbf, 0xcf, 0xda, 0xe7, 0x74, 0x5f, 0x3a, 0xa7, 0x7b, 0xf4, 0x36, 0x20, 0xa9, 0x5f, 0xc7,
-	0x76, 0x6d, 0xaa, 0x1f, 0x9d, 0x51, 0x22, 0xf6, 0xbf, 0x84, 0x15, 0xd1, 0xb3, 0xcb, 0x3a, 0x3e,
-	0x61, 0x74, 0xa4, 0xc2, 0xaa, 0xef, 0xbb, 0x7a, 0x64, 0xfa, 0x21, 0xd1, 0x0d, 0xeb, 0x69, 0xab,
-	0xc2, 0x07, 0x36, 0x7c, 0xdf, 0x1d, 0x32, 0x5a, 0xd7, 0x7a, 0xca, 0x1c, 0xae, 0x19, 0xc4, 0x11,
-	0xa1, 0x3a, 0xfb, 0xf0, 0x18, 0x55, 0xc7, 0x20, 0x48, 0xbd, 0x20, 0x8e, 0x32, 0x03, 0x5c, 0xe2,
-	0xb2, 0xb8, 0x93, 0x19, 0xb0, 0x47, 0x5c, 0x36, 0xcb, 0xca, 0x01, 0x09, 0x4d, 0xe2, 0xd1, 0x91,
-	0x6d, 0x1e, 0xb3, 0x90, 0x52, 0x58, 0x2f, 0xe0, 0x19, 0x9a, 0xfa, 0x39, 0x54, 0x78, 0x08, 0x62,
-	0x8b, 0xe7, 0xee, 0x9b, 0x7b, 0x77, 0xb1, 0xbd, 0x35, 0x46, 0xe0, 0xbe, 0xfd, 0x55, 0xa8, 0x4f,
-	0xfc, 0x48, 0xc6, 0x06, 0x61, 0x79, 0x35, 0x46, 0xe0, 0x9d, 0x6d, 0xa8, 0x85, 0xc4, 0xb0, 0x7c,
-	0xcf, 0x39, 0xe3, 0xfb, 0x52, 0xc3, 0x69, 0x5b, 0xfd, 0x02, 0xaa, 0xc2, 0xfd, 0xbe, 0x00, 0xfe,
-	0x4d, 0x40, 0xa6, 0x08, 0x2a, 0x01, 0x09, 0x5d, 0x3b, 0x8a, 0x6c, 0xdf, 0x8b, 0x92, 0xe7, 0x1f,
-	0xd1, 0x73, 0x30, 0xed, 0x50, 0xff, 0x56, 0x10, 0xf9, 0x8e, 0xb8, 0x98, 0xb3, 0x2c, 0x96, 0x59,
-	0x1a, 0xcb, 0xc9, 0x0a, 0xdc, 0x3c, 0x92, 0x26, 0xcb, 0x25, 0x65, 0x5a, 0x53, 0x5c, 0xf4, 0x5d,
-	0x43, 0x02, 0x24, 0xf7, 0x01, 0x22, 0xd3, 0xbe, 0x79, 0xef, 0x03, 0x44, 0xdc, 0x07, 0x08, 0x4b,
-	0x3e, 0x65, 0xc2, 0x25, 0xe0, 0xca, 0x3c, 0xdf, 0x6a, 0x58, 0xe9, 0xa5, 0x8b, 0xa8, 0xff, 0x2a,
-	0xa4, 0xbe, 0x22, 0xb9, 0x1c, 0xa1, 0xc7, 0x50, 0x63, 0xc7, 0x4e, 0x77, 0x8d, 0x40, 0x3e, 0xf5,
-	0xf5, 0x16, 0xbb, 0x77, 0x75, 0xd8, 0x29, 0xdb, 0x33, 0x02, 0x91, 0x2e, 0x2d, 0x07, 0xa2, 0xc5,
-	0x7c, 0x8e, 0x61, 0x4d, 0x7d, 0x0e, 0xfb, 0x47, 0x6f, 0x40, 0xd3, 0x88, 0xa9, 0xaf, 0x1b, 0xd6,
-	0x09, 0x09, 0xa9, 0x1d, 0x11, 0xa9, 0xfb, 0x55, 0x46, 0xed, 0x26, 0xc4, 0xf6, 0x3d, 0x58, 0xc9,
-	0x62, 0x3e, 0x2f, 0xfa, 0x56, 0xb2, 0xd1, 0xf7, 0xc7, 0x00, 0xd3, 0xbc, 0x9d, 0xd9, 0x08, 0x39,
-	0xb5, 0xa9, 0x6e, 0xfa, 0x16, 0x91, 0xaa, 0xac, 0x31, 0x42, 0xcf, 0xb7, 0xc8, 0xb9, 0x5b, 0x50,
-	0x25, 0xb9, 0x05, 0xb1, 0x53, 0xcb, 0x0e, 0xda, 0xb1, 0xed, 0x38, 0xc4, 0x92, 0x12, 0xd6, 0x7d,
-	0xdf, 0x7d, 0xc0, 0x09, 0xea, 0x37, 0x45, 0x61, 0x2b, 0xe2, 0x3e, 0x9b, 0x2b, 0x37, 0xfe, 0xae,
-	0x54, 0x7d, 0x17, 0x20, 0xa2, 0x46, 0xc8, 0x52, 0x09, 0x83, 0xca, 0x27, 0xa2, 0xf6, 0x85, 0x6b,
-	0xd4, 0x28, 0x79, 0x96, 0xc7, 0x75, 0x39, 0xba, 0x4b, 0xd1, 0x07, 0xb0, 0x62, 0xfa, 0x6e, 0xe0,
-	0x10, 0xc9, 0x5c, 0x79, 0x2e, 0x73, 0x23, 0x1d, 0xdf, 0xa5, 0x99, 0x3b, 0x54, 0xf5, 0x45, 0xef,
-	0x50, 0x7f, 0x2e, 0x88, 0x6b, 0x79, 0xf6, 0x55, 0x00, 0x8d, 0x2f, 0x79, 0x7a, 0xde, 0x5a, 0xf0,
-	0x89, 0xe1, 0xdb, 0xde, 0x9d, 0xdb, 0x1f, 0xe4, 0x79, 0xe8, 0x7d, 0x76, 0x72, 0xf7, 0x97, 0x12,
-	0xd4, 0xd3, 0x1b, 0xf9, 0x05, 0xdd, 0xdf, 0x81, 0x7a, 0x5a, 0x13, 0x91, 0x0e, 0xe2, 0x5b, 0xd5,
-	0x93, 0x0e, 0x46, 0x4f, 0x00, 0x19, 0xe3, 0x71, 0x9a, 0xb4, 0xe9, 0x71, 0x64, 0x8c, 0x93, 0xf7,
-	0x90, 0x3b, 0x73, 0xec, 0x43, 0x12, 0xb7, 0x0e, 0x19, 0x3f, 0x56, 0x8c, 0xf1, 0x78, 0x86, 0x82,
-	0x7e, 0x02, 0xd7, 0x67, 0xe7, 0xd0, 0x8f, 0xce, 0xf4, 0xc0, 0xb6, 0xe4, 0x1d, 0x6c, 0x7b, 0xde,
-	0x47, 0x89, 0xce, 0x0c, 0xfc, 0x27, 0x67, 0x07, 0xb6, 0x25, 0xf6, 0x1c, 0x85, 0x17, 0x3a, 0xda,
-	0x3f, 0x83, 0x97, 0x9f, 0x31, 0xfc, 0x12, 0x1d, 0x0c, 0x66, 0x1f, 0xdb, 0x17, 0xdf, 0x84, 0x8c,
-	0xf6, 0x7e, 0x5f, 0x10, 0x6f, 0x27, 0xb3, 0x7b, 0xd2, 0xcd, 0xe6, 0xad, 0x1b, 0x39, 0xe7, 0xe9,
-	0x1d, 0x1c, 0x0a, 0x78, 0x9e, 0xaa, 0x7e, 0x7a, 0x2e, 0x55, 0xcd, 0x9b, 0xc4, 0x88, 0x8c, 0x4f,
-	0x00, 0x49, 0x04, 0xf5, 0x8f, 0x25, 0xa8, 0x25, 0xe8, 0xfc, 0x06, 0x75, 0x16, 0x51, 0xe2, 0xea,
-	0x6e, 0xe2, 0xc2, 0x0a, 0x18, 0x04, 0x69, 0x8f, 0x39, 0xb1, 0x57, 0xa1, 0xce, 0x2e, 0x6a, 0xa2,
-	0xbb, 0xc8, 0xbb, 0x6b, 0x8c, 0xc0, 0x3b, 0x5f, 0x83, 0x06, 0xf5, 0xa9, 0xe1, 0xe8, 0x94, 0xc7,
-	0xf2, 0x92, 0xe0, 0xe6, 0x24, 0x1e, 0xc9, 0xd1, 0x5b, 0x70, 0x85, 0x4e, 0x42, 0x9f, 0x52, 0x87,
-	0xe5, 0x77, 0x3c, 0xa3, 0x11, 0x09, 0x48, 0x19, 0x2b, 0x69, 0x87, 0xc8, 0x74, 0x22, 0xe6, 0xbd,
-	0xa7, 0x83, 0x99, 0xe9, 0x72, 0x27, 0x52, 0xc6, 0xab, 0x29, 0x95, 0x99, 0x36, 0x0b, 0x9e, 0x81,
-	0xc8, 0x16, 0xb8, 0xaf, 0x28, 0xe0, 0xa4, 0x89, 0x74, 0x58, 0x73, 0x89, 0x11, 0xc5, 0x21, 0xb1,
-	0xf4, 0x27, 0x36, 0x71, 0x2c, 0x71, 0xf1, 0x6d, 0xe6, 0x4e, 0xbf, 0x93, 0x6d, 0xe9, 0xdc, 0xe7,
-	0xdc, 0xb8, 0x99, 0xc0, 0x89, 0x36, 0xcb, 0x1c, 0xc4, 0x1f, 0x5a, 0x83, 0xc6, 0xf0, 0xd1, 0x70,
-	0xa4, 0xed, 0xe9, 0x7b, 0xfb, 0x7d, 0x4d, 0xd6, 0x53, 0x86, 0x1a, 0x16, 0xcd, 0x02, 0xeb, 0x1f,
-	0xed, 0x8f, 0xba, 0xbb, 0xfa, 0x68, 0xa7, 0xf7, 0x60, 0xa8, 0x14, 0xd1, 0x75, 0xb8, 0x32, 0xda,
-	0xc6, 0xfb, 0xa3, 0xd1, 0xae, 0xd6, 0xd7, 0x0f, 0x34, 0xbc, 0xb3, 0xdf, 0x1f, 0x2a, 0x25, 0x84,
-	0xa0, 0x39, 0x25, 0x8f, 0x76, 0xf6, 0x34, 0xa5, 0x8c, 0x1a, 0xb0, 0x7c, 0xa0, 0xe1, 0x9e, 0x36,
-	0x18, 0x29, 0x15, 0xf5, 0x3f, 0x45, 0x68, 0x64, 0xb4, 0xc8, 0x0c, 0x39, 0x8c, 0x44, 0x9e, 0x5f,
-	0xc6, 0xec, 0x97, 0x39, 0x13, 0xd3, 0x30, 0x27, 0x42, 0x3b, 0x65, 0x2c, 0x1a, 0x3c, 0xb7, 0x37,
-	0x4e, 0x33, 0xe7, 0xbc, 0x8c, 0x6b, 0xae, 0x71, 0x2a, 0x40, 0x5e, 0x87, 0x95, 0x63, 0x12, 0x7a,
-	0xc4, 0x91, 0xfd, 0x42, 0x23, 0x0d, 0x41, 0x13, 0x43, 0xd6, 0x41, 0x91, 0x43, 0xa6, 0x30, 0x42,
-	0x1d, 0x4d, 0x41, 0xdf, 0x4b, 0xc0, 0xae, 0x41, 0x45, 0x74, 0x2f, 0x8b, 0xf9, 0x79, 0x03, 0x1d,
-	0x5d, 0xd4, 0x45, 0x95, 0xeb, 0xe2, 0xee, 0xfc, 0xa6, 0xfb, 0x2c, 0x75, 0x3c, 0x4e, 0xd5, 0xb1,
-	0x0c, 0x25, 0x9c, 0x14, 0x1c, 0x7a, 0xdd, 0xde, 0x36, 0x53, 0xc1, 0x2a, 0xd4, 0xf7, 0xba, 0x9f,
-	0xe9, 0x87, 0x43, 0xfe, 0xe4, 0x84, 0x14, 0x58, 0x79, 0xa0, 0xe1, 0x81, 0xb6, 0x2b, 0x29, 0x25,
-	0x74, 0x0d, 0x14, 0x49, 0x99, 0x8e, 0x2b, 0x33, 0x04, 0xf1, 0x5b, 0x51, 0xff, 0x5e, 0x84, 0x35,
-	0xe1, 0xf8, 0xd3, 0x07, 0xd1, 0x67, 0xbf, 0x4c, 0x66, 0xdf, 0x09, 0x8a, 0x33, 0xef, 0x04, 0x69,
-	0x9a, 0xc9, 0xe3, 0x76, 0x69, 0x9a, 0x66, 0xf2, 0xf7, 0x85, 0x19, 0x9f, 0x5e, 0x9e, 0xc7, 0xa7,
-	0xb7, 0x60, 0xd9, 0x25, 0x51, 0xaa, 0x99, 0x3a, 0x4e, 0x9a, 0xc8, 0x86, 0x86, 0xe1, 0x79, 0x3e,
-	0xe5, 0xaf, 0x71, 0xc9, 0xc5, 0x67, 0x6b, 0xae, 0x77, 0xbf, 0x74, 0xc5, 0x9d, 0xee, 0x14, 0x49,
-	0xb8, 0xde, 0x2c, 0x76, 0xfb, 0x43, 0x50, 0xce, 0x0f, 0x98, 0x27, 0xe0, 0xbd, 0xf9, 0xee, 0x34,
-	0xde, 0x11, 0x66, 0xf9, 0x87, 0x83, 0x07, 0x83, 0xfd, 0x87, 0x03, 0x65, 0x89, 0x35, 0xf0, 0xe1,
-	0x60, 0xb0, 0x33, 0xd8, 0x52, 0x0a, 0x08, 0xa0, 0xaa, 0x7d, 0xb6, 0x33, 0xd2, 0xfa, 0x4a, 0x71,
-	0xf3, 0x1f, 0xab, 0x50, 0x15, 0x42, 0xa2, 0xaf, 0x65, 0xac, 0xcf, 0x16, 0xdb, 0xd1, 0x87, 0x73,
-	0xe7, 0xcc, 0x33, 0x05, 0xfc, 0xf6, 0x47, 0x0b, 0xf3, 0xcb, 0x07, 0xed, 0x25, 0xf4, 0xeb, 0x02,
-	0xac, 0xcc, 0xbc, 0xe0, 0xe6, 0x7d, 0x7c, 0xbc, 0xa4, 0xb6, 0xdf, 0xfe, 0xde, 0x42, 0xbc, 0xa9,
-	0x2c, 0xbf, 0x2a, 0x40, 0x23, 0x53, 0xd5, 0x46, 0x77, 0x17, 0xa9, 0x84, 0x0b, 0x49, 0xee, 0x2d,
-	0x5e, 0x44, 0x57, 0x97, 0xde, 0x29, 0xa0, 0x5f, 0x16, 0xa0, 0x91, 0xa9, 0xef, 0xe6, 0x16, 0xe5,
-	0x62, 0x35, 0x3a, 0xb7, 0x28, 0x97, 0x95, 0x93, 0x97, 0xd0, 0xcf, 0x0b, 0x50, 0x4f, 0x6b, 0xb5,
-	0xe8, 0xf6, 0xfc, 0xd5, 0x5d, 0x21, 0xc4, 0x9d, 0x45, 0xcb, 0xc2, 0xea, 0x12, 0xfa, 0x29, 0xd4,
-	0x92, 0xc2, 0x26, 0xca, 0x1b, 0x9f, 0xce, 0x55, 0x4d, 0xdb, 0xb7, 0xe7, 0xe6, 0xcb, 0x4e, 0x9f,
-	0x54, 0x1b, 0x73, 0x4f, 0x7f, 0xae, 0x2e, 0xda, 0xbe, 0x3d, 0x37, 0x5f, 0x3a, 0x3d, 0xb3, 0x84,
-	0x4c, 0x51, 0x32, 0xb7, 0x25, 0x5c, 0xac, 0x86, 0xe6, 0xb6, 0x84, 0xcb, 0x6a, 0xa0, 0x42, 0x90,
-	0x4c, 0x59, 0x33, 0xb7, 0x20, 0x17, 0x4b, 0xa7, 0xb9, 0x05, 0xb9, 0xa4, 0x8a, 0xaa, 0x2e, 0xa1,
-	0xaf, 0x0a, 0xd9, 0xcc, 0xff, 0xf6, 0xdc, 0xd5, 0xbb, 0x39, 0x4d, 0xf2, 0x42, 0xfd, 0x90, 0x1f,
-	0xd0, 0xaf, 0xe4, 0x3b, 0x85, 0x28, 0xfe, 0xa1, 0x79, 0xc0, 0x66, 0xea, 0x85, 0xed, 0x5b, 0x8b,
-	0x05, 0x1b, 0x2e, 0xc4, 0x2f, 0x0a, 0x00, 0xd3, 0x32, 0x61, 0x6e, 0x21, 0x2e, 0xd4, 0x27, 0xdb,
-	0x77, 0x17, 0xe0, 0xcc, 0x1e, 0x90, 0xa4, 0x32, 0x98, 0xfb, 0x80, 0x9c, 0x2b, 0x63, 0xe6, 0x3e,
-	0x20, 0xe7, 0x4b, 0x90, 0xea, 0xd2, 0x27, 0xcb, 0x3f, 0xa8, 0x88, 0xe8, 0x5f, 0xe5, 0x9f, 0xf7,
-	0xfe, 0x1b, 0x00, 0x00, 0xff, 0xff, 0xc9, 0x5b, 0xdc, 0xf6, 0x89, 0x27, 0x00, 0x00,
+	// 3015 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xad, 0x5a, 0xcb, 0x6f, 0x1b, 0xc7,
+	0x19, 0x37, 0x9f, 0x22, 0x3f, 0x4a, 0x14, 0x3d, 0xb2, 0x63, 0x85, 0x45, 0x9b, 0x76, 0x81, 0x14,
+	0x46, 0xe2, 0x50, 0x89, 0x82, 0xfa, 0xd5, 0xbc, 0x18, 0x6a, 0x6d, 0xd1, 0x96, 0x28, 0x75, 0x49,
+	0xc1, 0x76, 0xdb, 0x78, 0xb1, 0xe2, 0x8e, 0xa8, 0xb5, 0x96, 0x5c, 0x66, 0x77, 0xe9, 0x58, 0x28,
+	0x8a, 0x16, 0x29, 0x50, 0xb4, 0x87, 0x02, 0xbd, 0x04, 0xbd, 0xa7, 0xc7, 0x9e, 0x7b, 0x68, 0x8b,
+	0xfc, 0x25, 0xed, 0xa9, 0x40, 0x81, 0x5e, 0x7b, 0x29, 0xd0, 0x5b, 0xbf, 0x79, 0xec, 0x8b, 0x92,
+	0xe3, 0x5d, 0x3a, 0x17, 0x69, 0xe7, 0x9b, 0xf9, 0x7e, 0xf3, 0xcd, 0x7c, 0xcf, 0x99, 0x21, 0x28,
+	0x53, 0x7b, 0x36, 0xb2, 0x26, 0xde, 0x86, 0xe9, 0x5a, 0x4f, 0xa9, 0xeb, 0x6d, 0x4c, 0x5d, 0xc7,
+	0x77, 0x64, 0xab, 0xc5, 0x1b, 0xe4, 0xf5, 0x63, 0xc3, 0x3b, 0xb6, 0x86, 0x8e, 0x3b, 0x6d, 0x4d,
+	0x9c, 0xb1, 0x61, 0xb6, 0x24, 0x4f, 0x4b, 0xf2, 0x88, 0x61, 0xcd, 0xef, 0x8c, 0x1c, 0x67, 0x64,
+	0x53, 0x81, 0x70, 0x38, 0x3b, 0xda, 0x30, 0x67, 0xae, 0xe1, 0x5b, 0xce, 0x44, 0xf6, 0xbf, 0x36,
+	0xdf, 0xef, 0x5b, 0x63, 0xea, 0xf9, 0xc6, 0x78, 0x2a, 0x07, 0x7c, 0x34, 0xb2, 0xfc, 0xe3, 0xd9,
+	0x61, 0x6b, 0xe8, 0x8c, 0x37, 0xc2, 0x29, 0x37, 0xf8, 0x94, 0x1b, 0x81, 0x98, 0xde, 0xb1, 0xe1,
+	0x52, 0x73, 0xe3, 0x78, 0x68, 0x7b, 0x53, 0x3a, 0x64, 0xff, 0x75, 0xf6, 0x21, 0x11, 0xee, 0xa6,
+	0x47, 0xf0, 0x7c, 0x77, 0x36, 0xf4, 0x83, 0xf5, 0x1a, 0xbe, 0xef, 0x5a, 0x87, 0x33, 0x9f, 0x0a,
+	0x20, 0xe5, 0x55, 0xb8, 0x32, 0x30, 0xbc, 0x93, 0x8e, 0x33, 0x39, 0xb2, 0x46, 0xfd, 0xe1, 0x31,
+	0x1d, 0x1b, 0x1a, 0xfd, 0x74, 0x86, 0xe2, 0x2a, 0x3f, 0x85, 0xf5, 0xb3, 0x5d, 0xde, 0xd4, 0x99,
+	0x78, 0x94, 0x7c, 0x04, 0x45, 0x26, 0xcd, 0x7a, 0xee, 0xbb, 0xb9, 0xab, 0xb5, 0xcd, 0x6b, 0xad,
+	0xe7, 0x6d, 0x9c, 0x90, 0xa1, 0x25, 0x57, 0xd1, 0xea, 0xe3, 0x1f, 0x8d, 0x73, 0x2a, 0x97, 0x61,
+	0xad, 0x63, 0x4c, 0x8d, 0x43, 0xcb, 0xb6, 0x7c, 0x8b, 0x7a, 0xc1, 0xa4, 0x33, 0xb8, 0x94, 0x24,
+	0xcb, 0x09, 0x3f, 0x81, 0xe5, 0x61, 0x8c, 0x2e, 0x27, 0xbe, 0xd5, 0x4a, 0xa5, 0xb1, 0xd6, 0x16,
+	0x6f, 0x25, 0x80, 0x13, 0x70, 0xca, 0x25, 0x20, 0x77, 0xac, 0xc9, 0x88, 0xba, 0x53, 0xd7, 0x9a,
+	0xf8, 0x81, 0x30, 0x5f, 0x15, 0x60, 0x2d, 0x41, 0x96, 0xc2, 0x3c, 0x01, 0x08, 0xf7, 0x91, 0x89,
+	0x52, 0x40, 0x51, 0xee, 0xa5, 0x14, 0xe5, 0x1c, 0xbc, 0x56, 0x3b, 0x04, 0x53, 0x27, 0xbe, 0x7b,
+	0xaa, 0xc5, 0xd0, 0xc9, 0x63, 0x28, 0x1f, 0x53, 0xc3, 0xf6, 0x8f, 0xd7, 0xf3, 0xb8, 0xe4, 0xfa,
+	0xe6, 0x9d, 0x97, 0x98, 0x67, 0x9b, 0x03, 0xf5, 0x7d, 0xc3, 0xa7, 0x9a, 0x44, 0x25, 0x6f, 0x01,
+	0x11, 0x5f, 0xba, 0x49, 0xbd, 0xa1, 0x6b, 0x4d, 0x99, 0x21, 0xaf, 0x17, 0x70, 0xae, 0xaa, 0x76,
+	0x51, 0xf4, 0x6c, 0x45, 0x1d, 0xcd, 0x29, 0xac, 0xce, 0x49, 0x4b, 0x1a, 0x50, 0x38, 0xa1, 0xa7,
+	0x5c, 0x23, 0x55, 0x8d, 0x7d, 0x92, 0xbb, 0x50, 0x7a, 0x6a, 0xd8, 0x33, 0xca, 0x45, 0xae, 0x6d,
+	0xbe, 0xf3, 0x22, 0xf3, 0x90, 0x26, 0x1a, 0xed, 0x83, 0x26, 0xf8, 0x6f, 0xe7, 0x6f, 0xe6, 0x94,
+	0x5b, 0x50, 0x8b, 0xc9, 0x4d, 0xea, 0x00, 0x07, 0xbd, 0x2d, 0x75, 0xa0, 0x76, 0x06, 0xea, 0x56,
+	0xe3, 0x02, 0x59, 0x81, 0xea, 0x41, 0x6f, 0x5b, 0x6d, 0xef, 0x0c, 0xb6, 0x1f, 0x35, 0x72, 0xa4,
+	0x06, 0x4b, 0x41, 0x23, 0xaf, 0x3c, 0x03, 0xa2, 0xd1, 0xa1, 0x83, 0xbb, 0xc2, 0x0c, 0x59, 0x6a,
+	0x95, 0x5c, 0x81, 0x25, 0x1f, 0x9b, 0xba, 0x65, 0x4a, 0x99, 0xcb, 0xac, 0xd9, 0x35, 0x49, 0x17,
+	0xb7, 0xda, 0x98, 0x98, 0xf6, 0x8b, 0xe5, 0x4e, 0x6e, 0x35, 0x03, 0xdf, 0xe6, 0x8c, 0x9a, 0x04,
+	0x60, 0xd6, 0x9d, 0x98, 0x59, 0x28, 0x40, 0x79, 0x04, 0x0d, 0x5c, 0x85, 0xeb, 0xc7, 0xc5, 0x51,
+	0xa1, 0xc8, 0xe6, 0x97, 0x16, 0x9d, 0x65, 0x4e, 0xe1, 0x99, 0x1a, 0x67, 0x57, 0xfe, 0x93, 0x87,
+	0x8b, 0x31, 0x6c, 0x69, 0xa9, 0x0f, 0xa0, 0xec, 0x52, 0x6f, 0x66, 0xfb, 0x1c, 0xbe, 0xbe, 0xf9,
+	0x61, 0x4a, 0xf8, 0x33, 0x48, 0x2d, 0x8d, 0xc3, 0x68, 0x12, 0x8e, 0x5c, 0x85, 0x86, 0xe0, 0xd0,
+	0xa9, 0xeb, 0x3a, 0xae, 0x3e, 0xf6, 0x46, 0x7c, 0xd7, 0xaa, 0x5a, 0x5d, 0xd0, 0x55, 0x46, 0xde,
+	0xf5, 0x46, 0xb1, 0x5d, 0x2d, 0xbc, 0xe4, 0xae, 0x12, 0x03, 0x1a, 0x13, 0xea, 0x7f, 0xe6, 0xb8,
+	0x27, 0x3a, 0xdb, 0x5a, 0xd7, 0x32, 0xe9, 0x7a, 0x91, 0x83, 0x5e, 0x4f, 0x09, 0xda, 0x13, 0xec,
+	0x7b, 0x92, 0x5b, 0x5b, 0x9d, 0x24, 0x09, 0xca, 0x9b, 0x50, 0x16, 0x2b, 0x65, 0x96, 0xd4, 0x3f,
+	0xe8, 0x74, 0xd4, 0x7e, 0x1f, 0xad, 0xac, 0x0a, 0x25, 0x4d, 0x1d, 0x68, 0xcc, 0xc2, 0xf0, 0xf3,
+	0x4e, 0x7b, 0xd0, 0xde, 0x41, 0xfb, 0x7a, 0x03, 0x56, 0x1f, 0x18, 0x96, 0x9f, 0xc6, 0xb8, 0x14,
+	0x07, 0x1a, 0xd1, 0x58, 0xa9, 0x9d, 0x6e, 0x42, 0x3b, 0xe9, 0xb7, 0x46, 0x7d, 0x66, 0xf9, 0x73,
+	0xfa, 0x40, 0x27, 0xc4, 0x15, 0x48, 0x15, 0xb0, 0x4f, 0xe5, 0x33, 0x58, 0xed, 0xfb, 0xce, 0x34,
+	0x95, 0xe5, 0xbf, 0x8b, 0x1d, 0x98, 0xa3, 0x9c, 0x99, 0x2f, 0x4d, 0xff, 0xd5, 0x96, 0xc8, 0x61,
+	0xad, 0x20, 0x87, 0xb5, 0xb6, 0x64, 0x8e, 0xd3, 0x82, 0x91, 0xe4, 0x15, 0x28, 0x7b, 0xd6, 0x68,
+	0x62, 0xd8, 0x32, 0x5a, 0xc8, 0x96, 0x42, 0x98, 0x91, 0x07, 0x13, 0x4b, 0xc3, 0xef, 0x00, 0xc1,
+	0x28, 0xe2, 0xbb, 0xce, 0x69, 0x2a, 0x79, 0x2e, 0x41, 0xe9, 0xc8, 0x71, 0x87, 0xc2, 0x11, 0x2b,
+	0x9a, 0x68, 0x30, 0xa7, 0x4a, 0x80, 0x48, 0x6c, 0x8c, 0x60, 0xdd, 0x09, 0xcb, 0x29, 0xe9, 0x14,
+	0xf1, 0xfb, 0x3c, 0xac, 0x25, 0xc6, 0x4b, 0x65, 0x2c, 0xee, 0x87, 0x2c, 0x30, 0xcd, 0x3c, 0xe1,
+	0x87, 0x64, 0x0f, 0xca, 0x62, 0x84, 0xdc, 0xc9, 0x1b, 0x19, 0x80, 0x44, 0x9a, 0x92, 0x70, 0x12,
+	0xe6, 0x5c, 0xa3, 0x2f, 0x7c, 0xb3, 0x46, 0xff, 0x19, 0x34, 0x82, 0x75, 0x78, 0x2f, 0xd4, 0xcd,
+	0x3d, 0x58, 0x1b, 0x3a, 0xb6, 0x8d, 0xdb, 0x87, 0xd6, 0xa0, 0x63, 0x7a, 0xa1, 0x2e, 0x06, 0xeb,
+	0x17, 0xdb, 0x0d, 0x89, 0xb8, 0xba, 0x92, 0x49, 0xf9, 0x09, 0x5c, 0x8c, 0x4d, 0x2c, 0x15, 0x71,
+	0x07, 0x4a, 0x1e, 0x23, 0x48, 0x4d, 0xbc, 0x9d, 0x51, 0x13, 0x9e, 0x26, 0xd8, 0x95, 0x35, 0x01,
+	0xae, 0x3e, 0xa5, 0x93, 0x70, 0x59, 0xca, 0x16, 0x46, 0x49, 0x6e, 0xa6, 0xa9, 0xec, 0x30, 0x32,
+	0xf1, 0x7c, 0xc2, 0xc4, 0xb1, 0x5c, 0x88, 0xa3, 0x48, 0x43, 0x3c, 0x85, 0x55, 0xf5, 0x19, 0x1d,
+	0xa6, 0x42, 0x5e, 0x87, 0x25, 0xac, 0xdd, 0xc6, 0x18, 0xd7, 0x10, 0xba, 0x80, 0x1d, 0x41, 0x33,
+	0xee, 0x8b, 0x85, 0xb4, 0xbe, 0xa8, 0xfc, 0x2e, 0x07, 0x8d, 0x68, 0x6e, 0xb9, 0x91, 0x4c, 0x7a,
+	0xdf, 0x64, 0x40, 0x6c, 0xee, 0x65, 0x4d, 0xb6, 0x24, 0x3d, 0x08, 0x17, 0x82, 0x8e, 0xad, 0x58,
+	0x38, 0x2a, 0xbc, 0x64, 0x38, 0x52, 0xfe, 0x95, 0x43, 0x87, 0x3f, 0x53, 0x74, 0x91, 0xef, 0xc1,
+	0xb2, 0x47, 0x27, 0xa6, 0x2e, 0xb6, 0x51, 0x68, 0xb8, 0xa2, 0xd5, 0x18, 0x4d, 0xec, 0xa7, 0x47,
+	0x08, 0x14, 0x29, 0x2e, 0x44, 0x7a, 0x3e, 0xff, 0x26, 0xc7, 0xb0, 0x7c, 0xe4, 0xe9, 0x96, 0xe7,
+	0xd8, 0x46, 0x58, 0x9d, 0xd4, 0x37, 0xd5, 0x85, 0x8b, 0xbf, 0xd6, 0x9d, 0x7e, 0x37, 0x00, 0xd3,
+	0x6a, 0x47, 0x5e, 0xd8, 0x50, 0x5a, 0x50, 0x8b, 0xf5, 0x91, 0x0a, 0x14, 0x7b, 0x7b, 0x3d, 0x15,
+	0x13, 0x00, 0x40, 0xb9, 0xb3, 0xad, 0xed, 0xed, 0x0d, 0x44, 0x06, 0xe8, 0xee, 0xb6, 0xef, 0xaa,
+	0x98, 0x01, 0xfe, 0x52, 0x06, 0x88, 0x52, 0x31, 0x16, 0x27, 0xf9, 0x50, 0xd3, 0xf8, 0xc5, 0x16,
+	0x33, 0x31, 0xc6, 0x54, 0x5a, 0x0f, 0xff, 0x26, 0x9b, 0x70, 0x19, 0x93, 0xe5, 0xd4, 0x18, 0x9e,
+	0xe8, 0x32, 0x83, 0x0e, 0x39, 0x33, 0x5f, 0xd5, 0xb2, 0xb6, 0x26, 0x3b, 0xa5, 0xd4, 0x02, 0x77,
+	0x07, 0xa3, 0xfb, 0xe4, 0x29, 0xe6, 0x3a, 0x56, 0x69, 0xde, 0xce, 0x5c, 0x22, 0xb4, 0xd4, 0xc9,
+	0x53, 0x51, 0x59, 0x32, 0x18, 0xa2, 0x03, 0x98, 0xf4, 0xa9, 0x35, 0xa4, 0x3a, 0x03, 0x2d, 0x71,
+	0xd0, 0x8f, 0xb2, 0x83, 0x6e, 0x71, 0x8c, 0x10, 0xba, 0x6a, 0x06, 0x6d, 0xd2, 0x83, 0x2a, 0xda,
+	0x81, 0x33, 0xc3, 0xa0, 0xed, 0xad, 0x97, 0x33, 0x79, 0xb1, 0x16, 0xf0, 0x69, 0x11, 0x04, 0xd9,
+	0x82, 0xf2, 0xd8, 0x99, 0xa1, 0x17, 0xaf, 0x2f, 0x71, 0x61, 0xaf, 0xa5, 0x04, 0xdb, 0x65, 0x4c,
+	0x9a, 0xe4, 0xc5, 0xaa, 0x74, 0x49, 0x88, 0xe8, 0xad, 0x57, 0x38, 0xcc, 0x5b, 0x69, 0x0d, 0x88,
+	0x73, 0x69, 0x01, 0x37, 0xd3, 0xea, 0xcc, 0xc3, 0x00, 0x5f, 0x15, 0x5a, 0x65, 0xdf, 0xe4, 0x5b,
+	0x50, 0x35, 0x6c, 0xdb, 0x19, 0xea, 0xa6, 0xe5, 0xae, 0x03, 0xef, 0xa8, 0x70, 0xc2, 0x96, 0xe5,
+	0x92, 0xd7, 0xa0, 0x26, 0x5c, 0x4f, 0x9f, 0x1a, 0x58, 0xc8, 0xd7, 0x78, 0x37, 0x08, 0xd2, 0x3e,
+	0x52, 0xe4, 0x00, 0xf4, 0x41, 0x31, 0x60, 0x39, 0x1c, 0x80, 0x24, 0x3e, 0xe0, 0xfb, 0xb0, 0xca,
+	0xe3, 0xc8, 0xc8, 0x75, 0x66, 0x53, 0x9d, 0xdb, 0xd4, 0x0a, 0x1f, 0xb4, 0xc2, 0xc8, 0x77, 0x19,
+	0xb5, 0xc7, 0x8c, 0xeb, 0x55, 0xa8, 0x3c, 0x71, 0x0e, 0xc5, 0x80, 0x3a, 0x1f, 0xb0, 0x84, 0xed,
+	0xa0, 0x4b, 0x48, 0x88, 0x16, 0xba, 0x2a, 0xba, 0x78, 0xbb, 0x6b, 0x36, 0xaf, 0x43, 0x25, 0x50,
+	0xe3, 0x39, 0xd5, 0xfc, 0xa5, 0x78, 0x35, 0x5f, 0x8d, 0x95, 0xe6, 0xcd, 0xf7, 0xa0, 0x9e, 0x34,
+	0x82, 0x2c, 0xdc, 0xca, 0xdf, 0x73, 0x50, 0x0d, 0xd5, 0x4d, 0x26, 0xb0, 0xc6, 0xc5, 0xc1, 0x1a,
+	0xdf, 0xd4, 0x23, 0xeb, 0x11, 0x39, 0xe0, 0xfd, 0x94, 0x9a, 0x6a, 0x07, 0x08, 0x32, 0x0e, 0x4a,
+	0x53, 0x22, 0x21, 0x72, 0x34, 0xdf, 0x63, 0x58, 0xb5, 0xad, 0xc9, 0xec, 0x59, 0x6c, 0x2e, 0x91,
+	0xc2, 0x7e, 0x90, 0x72, 0xae, 0x1d, 0xc6, 0x1d, 0xcd, 0x51, 0xb7, 0x13, 0x6d, 0xe5, 0x8b, 0x3c,
+	0xbc, 0x72, 0xbe, 0x38, 0xe8, 0x1e, 0x85, 0xe1, 0x74, 0x26, 0x97, 0xf6, 0x5e, 0xd6, 0xa5, 0x75,
+	0xa6, 0xb3, 0x68, 0x56, 0x06, 0xc4, 0x8a, 0xfc, 0x31, 0x1d, 0x3b, 0xee, 0xa9, 0x5c, 0xc1, 0x87,
+	0x59, 0x21, 0x77, 0x39, 0x77, 0x84, 0x2a, 0xe1, 0x88, 0x06, 0x15, 0x59, 0x2a, 0x78, 0x32, 0x4c,
+	0x64, 0x2c, 0x39, 0x02, 0x48, 0x2d, 0xc4, 0x51, 0xae, 0xc3, 0xe5, 0x73, 0x97, 0x42, 0xbe, 0x0d,
+	0x80, 0x8b, 0xd1, 0xf9, 0x91, 0x50, 0xe8, 0xbd, 0xa0, 0x55, 0x91, 0xd2, 0xe7, 0x04, 0xe5, 0x06,
+	0xac, 0x3f, 0x4f, 0x5e, 0xe6, 0x7c, 0x42, 0x62, 0x7d, 0x7c, 0xc8, 0xf7, 0xa0, 0xa0, 0x55, 0x04,
+	0x61, 0xf7, 0x50, 0xf9, 0x43, 0x1e, 0x56, 0xe7, 0xc4, 0x61, 0x19, 0x50, 0x38, 0x73, 0x90, 0x95,
+	0x45, 0x8b, 0x79, 0xf6, 0xd0, 0x32, 0x83, 0x32, 0x9a, 0x7f, 0xf3, 0x98, 0x3e, 0x95, 0x25, 0x2e,
+	0x7e, 0x31, 0x83, 0x1e, 0x1f, 0x5a, 0x18, 0x8b, 0xd8, 0xc9, 0xa3, 0xa4, 0x89, 0x06, 0x79, 0x04,
+	0x75, 0x94, 0x12, 0xab, 0x1a, 0xb4, 0xde, 0xa9, 0xe3, 0xfa, 0xc1, 0x86, 0x6d, 0x66, 0xdb, 0xb0,
+	0x7d, 0x64, 0xd5, 0x56, 0x02, 0x24, 0xd6, 0xf2, 0x50, 0xbd, 0x2b, 0xe6, 0x29, 0x7a, 0xb4, 0x35,
+	0x94, 0xc8, 0xe5, 0x85, 0x91, 0x97, 0x25, 0x10, 0x07, 0x66, 0x27, 0xeb, 0x58, 0x27, 0x5b, 0x98,
+	0x6d, 0x1c, 0x52, 0x5b, 0xee, 0x89, 0x68, 0x24, 0xfd, 0xb7, 0x24, 0xfd, 0x57, 0xf9, 0x63, 0x1e,
+	0xea, 0x49, 0x07, 0x08, 0xf4, 0x37, 0xa5, 0xae, 0xe5, 0x98, 0x31, 0xfd, 0xed, 0x73, 0x02, 0xd3,
+	0x11, 0xeb, 0xfe, 0x74, 0xe6, 0xf8, 0x46, 0xa0, 0x23, 0x24, 0xfc, 0x88, 0xb5, 0xe7, 0x74, 0x5f,
+	0x98, 0xd3, 0x3d, 0xb9, 0x06, 0x44, 0xea, 0xd7, 0xb6, 0xc6, 0x96, 0xaf, 0x1f, 0x9e, 0xb2, 0x7b,
+	0x97, 0x22, 0x1f, 0xd6, 0x10, 0x3d, 0x3b, 0xac, 0xe3, 0x63, 0x46, 0x27, 0x0a, 0xac, 0x38, 0xce,
+	0x58, 0xf7, 0x70, 0x67, 0xa8, 0x6e, 0x98, 0x4f, 0x50, 0x13, 0x6c, 0x60, 0x0d, 0x89, 0x7d, 0x46,
+	0x6b, 0x9b, 0x4f, 0x58, 0xc0, 0x45, 0x78, 0x8f, 0xfa, 0x3a, 0xfb, 0xc7, 0x73, 0x14, 0x06, 0x5c,
+	0x41, 0x42, 0xab, 0xf4, 0x62, 0x03, 0x10, 0x9f, 0xe5, 0x9d, 0xd8, 0x00, 0x34, 0x3f, 0x36, 0xcb,
+	0x32, 0xae, 0x6c, 0x88, 0xb5, 0xe5, 0xc0, 0x1a, 0x9e, 0xb0, 0x94, 0x92, 0xbb, 0x9a, 0xd3, 0x12,
+	0x34, 0xe5, 0x13, 0x28, 0xf1, 0x14, 0xc4, 0x16, 0xcf, 0xc3, 0x37, 0x8f, 0xee, 0x62, 0x7b, 0x2b,
+	0x8c, 0xc0, 0x63, 0x3b, 0x76, 0x1e, 0x3b, 0x9e, 0xcc, 0x0d, 0xc2, 0xf2, 0x2a, 0x8c, 0xc0, 0x3b,
+	0x9b, 0x50, 0x71, 0xa9, 0x61, 0x3a, 0x13, 0xfb, 0x94, 0xef, 0x4b, 0x45, 0x0b, 0xdb, 0xca, 0xa7,
+	0x50, 0x16, 0xe1, 0xf7, 0x25, 0xf0, 0xf1, 0xf0, 0x34, 0x14, 0x49, 0x05, 0x15, 0x37, 0xb6, 0x3c,
+	0x0f, 0xcb, 0x1e, 0x2f, 0xb8, 0xfe, 0x11, 0x3d, 0xfb, 0x51, 0x87, 0xf2, 0x8f, 0x9c, 0xa8, 0x77,
+	0xc4, 0xc1, 0x9c, 0x55, 0xb1, 0xcc, 0xd2, 0x58, 0x4d, 0x96, 0xe3, 0xe6, 0x11, 0x34, 0x59, 0x2d,
+	0x29, 0xcb, 0x9a, 0xfc, 0xa2, 0xf7, 0x1a, 0x12, 0x20, 0x38, 0x0f, 0x50, 0x59, 0xf6, 0x65, 0x3d,
+	0x0f, 0x50, 0x71, 0x1e, 0xa0, 0xac, 0xf8, 0x94, 0x05, 0x97, 0x80, 0x2b, 0xf2, 0x7a, 0xab, 0x66,
+	0x86, 0x87, 0x2e, 0xaa, 0xfc, 0x3b, 0x17, 0xc6, 0x8a, 0xe0, 0x70, 0x84, 0x89, 0xa2, 0xc2, 0xdc,
+	0x4e, 0x1f, 0x1b, 0x53, 0x79, 0xd5, 0xd7, 0x59, 0xec, 0xdc, 0xd5, 0x62, 0x5e, 0xb6, 0x6b, 0x4c,
+	0x45, 0xb9, 0xb4, 0x34, 0x15, 0x2d, 0x16, 0x73, 0x0c, 0x33, 0x8a, 0x39, 0xec, 0x9b, 0xbc, 0x0e,
+	0x75, 0x63, 0xe6, 0x3b, 0x68, 0xbd, 0xc8, 0xeb, 0x5b, 0x1e, 0x95, 0xba, 0x5f, 0x61, 0xd4, 0x76,
+	0x40, 0x6c, 0xde, 0x46, 0x1b, 0x8c, 0x61, 0xbe, 0x28, 0xfb, 0x96, 0xe2, 0xd9, 0xf7, 0xbf, 0xa8,
+	0xc9, 0xa8, 0x70, 0x67, 0x46, 0x42, 0xb1, 0x85, 0xb5, 0xa8, 0x49, 0xa5, 0x2e, 0x2b, 0x8c, 0xd0,
+	0xc1, 0xf6, 0xdc, 0x31, 0xa8, 0x14, 0x1c, 0x83, 0x98, 0xdb, 0x32, 0x4f, 0x3b, 0xb1, 0xf0, 0x60,
+	0x67, 0x4a, 0x11, 0xab, 0x48, 0xb9, 0xcf, 0x09, 0xe4, 0x21, 0x2b, 0xb8, 0x7c, 0xc3, 0xb2, 0x3d,
+	0x59, 0xb9, 0x7e, 0x90, 0xf9, 0x40, 0x81, 0xb5, 0x17, 0x07, 0x90, 0x7b, 0x26, 0xe1, 0xd8, 0xc2,
+	0xe3, 0x1d, 0x99, 0xca, 0x8e, 0xaf, 0xf2, 0xc2, 0x84, 0xc5, 0x31, 0x3b, 0x55, 0xc9, 0xfe, 0x4d,
+	0x59, 0xe0, 0x2d, 0xc0, 0x9a, 0xce, 0x70, 0x59, 0x85, 0x63, 0xf8, 0xf2, 0xe6, 0xaa, 0x79, 0xe6,
+	0x74, 0x37, 0x08, 0x5e, 0x0b, 0xb4, 0xaa, 0x1c, 0xdd, 0xf6, 0xc9, 0xfb, 0xb0, 0x8c, 0x07, 0xc4,
+	0xa9, 0x4d, 0x25, 0x73, 0xe9, 0x85, 0xcc, 0xb5, 0x70, 0x3c, 0xb2, 0x47, 0x47, 0xbb, 0xf2, 0xcb,
+	0x1e, 0xed, 0xfe, 0x9a, 0x13, 0xb7, 0x05, 0xf1, 0xcb, 0x0a, 0x32, 0x3a, 0xe7, 0x46, 0xfc, 0xee,
+	0x82, 0x37, 0x1f, 0x5f, 0x77, 0x1d, 0xde, 0x7c, 0x3f, 0xcd, 0xfd, 0xf3, 0xf3, 0x95, 0xff, 0xb7,
+	0x02, 0x54, 0xc3, 0x8b, 0x82, 0x33, 0xba, 0xbf, 0x89, 0x61, 0x34, 0xd8, 0x3f, 0x19, 0xb7, 0xbe,
+	0x56, 0x3d, 0xe1, 0x60, 0x72, 0x04, 0xc4, 0x18, 0x8d, 0xc2, 0x5a, 0x52, 0x9f, 0x79, 0xc6, 0x28,
+	0xb8, 0xa6, 0xb9, 0x99, 0x61, 0x1f, 0x82, 0x74, 0x7a, 0xc0, 0xf8, 0xb5, 0x06, 0x62, 0x26, 0x28,
+	0xe4, 0x67, 0x70, 0x39, 0x39, 0x07, 0xe6, 0x42, 0x7d, 0x8a, 0x8b, 0x10, 0x0e, 0xb6, 0x9d, 0xf5,
+	0xae, 0xa4, 0x95, 0x80, 0xff, 0xf8, 0x74, 0xdf, 0x32, 0xc5, 0x9e, 0x13, 0xf7, 0x4c, 0x47, 0xf3,
+	0x17, 0x70, 0xe5, 0x39, 0xc3, 0xcf, 0xd1, 0x41, 0x2f, 0xf9, 0x06, 0xb0, 0xf8, 0x26, 0xc4, 0xb4,
+	0xf7, 0x65, 0x4e, 0x5c, 0xe9, 0x24, 0xf7, 0xa4, 0x1d, 0x2f, 0xa7, 0x37, 0x52, 0xce, 0xd3, 0xd9,
+	0x3f, 0x10, 0xf0, 0xbc, 0x82, 0xbe, 0x37, 0x57, 0x41, 0xa7, 0xad, 0xad, 0x44, 0x21, 0x2a, 0x80,
+	0x24, 0x82, 0xf2, 0xa7, 0x02, 0x54, 0x02, 0x74, 0x7e, 0xb0, 0x3b, 0xf5, 0x7c, 0x3a, 0xd6, 0xc7,
+	0x41, 0x60, 0xcd, 0xe1, 0xc1, 0x8e, 0x93, 0x76, 0x59, 0x68, 0xc5, 0xb8, 0xcb, 0xce, 0x8f, 0xa2,
+	0x3b, 0xcf, 0xbb, 0x2b, 0x8c, 0xc0, 0x3b, 0x91, 0xdb, 0xc7, 0xf2, 0xc8, 0xd6, 0x7d, 0x5e, 0x62,
+	0x14, 0x04, 0x37, 0x27, 0xf1, 0x02, 0x83, 0xbc, 0x09, 0x17, 0xfd, 0x63, 0x94, 0xc4, 0xb7, 0x59,
+	0xd9, 0xc9, 0x0b, 0x2d, 0x51, 0x17, 0x15, 0xb5, 0x46, 0xd8, 0x21, 0x0a, 0x30, 0x8f, 0x25, 0x95,
+	0x68, 0x30, 0x33, 0x5d, 0x1e, 0x44, 0x8a, 0x78, 0x84, 0x0c, 0xa8, 0xcc, 0xb4, 0x59, 0x4e, 0x9f,
+	0x8a, 0x22, 0x86, 0xc7, 0x8a, 0x9c, 0x16, 0x34, 0x89, 0x0e, 0xab, 0x63, 0x6a, 0x78, 0x33, 0x17,
+	0xf9, 0x8f, 0x2c, 0x6a, 0x9b, 0xe2, 0x3c, 0x5e, 0x4f, 0x7d, 0x2a, 0x08, 0xb6, 0xa5, 0x75, 0x87,
+	0x73, 0x6b, 0xf5, 0x00, 0x4e, 0xb4, 0x59, 0x41, 0x23, 0xbe, 0xc8, 0x2a, 0xd4, 0xfa, 0x8f, 0xfa,
+	0x03, 0x75, 0x57, 0xdf, 0xdd, 0xdb, 0x52, 0xe5, 0x33, 0x4f, 0x5f, 0xd5, 0x44, 0x33, 0xc7, 0xfa,
+	0x07, 0x7b, 0x83, 0xf6, 0x8e, 0x3e, 0xe8, 0x76, 0xee, 0xf7, 0x1b, 0x79, 0x72, 0x19, 0x2d, 0x63,
+	0x5b, 0xdb, 0x1b, 0x0c, 0x76, 0xd4, 0x2d, 0x7d, 0x5f, 0xd5, 0xba, 0x7b, 0x5b, 0xfd, 0x46, 0x01,
+	0xa3, 0x79, 0x3d, 0x22, 0x0f, 0xba, 0xbb, 0x6a, 0xa3, 0xc8, 0x2e, 0xf6, 0x71, 0x40, 0x47, 0xed,
+	0x0d, 0x1a, 0x25, 0xe5, 0x7f, 0x79, 0xa8, 0xc5, 0xb4, 0xc8, 0x0c, 0xd9, 0xf5, 0xc4, 0xf1, 0xa3,
+	0xa8, 0xb1, 0x4f, 0x16, 0x4c, 0x86, 0xc6, 0xf0, 0x58, 0x68, 0xa7, 0xa8, 0x89, 0x06, 0x3f, 0x72,
+	0x18, 0xcf, 0x62, 0x7e, 0x5e, 0xc4, 0x23, 0x87, 0xf1, 0x4c, 0x80, 0x60, 0xa5, 0x71, 0x42, 0xdd,
+	0x09, 0xb5, 0x65, 0xbf, 0xd0, 0x48, 0x4d, 0xd0, 0xc4, 0x90, 0xab, 0xd0, 0x90, 0x43, 0x22, 0x18,
+	0xa1, 0x8e, 0xba, 0xa0, 0xef, 0x06, 0x60, 0x38, 0xbf, 0xe8, 0x5e, 0x12, 0xf3, 0xf3, 0x06, 0x39,
+	0x3c, 0xab, 0x8b, 0x32, 0xd7, 0xc5, 0xad, 0xec, 0xa6, 0xfb, 0x3c, 0x75, 0x3c, 0x0e, 0xd5, 0xb1,
+	0x04, 0x05, 0x2d, 0x78, 0x07, 0xe9, 0xb4, 0x3b, 0xdb, 0x4c, 0x05, 0xa8, 0x91, 0xdd, 0xf6, 0x43,
+	0xfd, 0xa0, 0xcf, 0x6f, 0xc2, 0x70, 0xe3, 0x96, 0xef, 0xab, 0x5a, 0x4f, 0xdd, 0x91, 0x94, 0x02,
+	0x0a, 0xde, 0x90, 0x94, 0x68, 0x5c, 0x91, 0x21, 0x88, 0xcf, 0x92, 0xf2, 0xe7, 0x02, 0xac, 0x8a,
+	0xc0, 0x1f, 0xde, 0xd3, 0x3e, 0xff, 0xc2, 0x34, 0x7e, 0x7d, 0x91, 0x4f, 0x5c, 0x5f, 0x84, 0xd5,
+	0x2f, 0xcf, 0xdb, 0x85, 0xa8, 0xfa, 0xe5, 0xd7, 0x1e, 0x89, 0x98, 0x5e, 0xcc, 0x12, 0xd3, 0xd1,
+	0x11, 0xf0, 0x33, 0xd4, 0x0c, 0x4e, 0x28, 0x9b, 0xc4, 0x82, 0x9a, 0x31, 0x99, 0xa0, 0x1b, 0xfa,
+	0xbc, 0x5a, 0x2e, 0x67, 0x4a, 0x77, 0x73, 0x2b, 0x6e, 0xb5, 0x23, 0x24, 0x11, 0x7a, 0xe3, 0xd8,
+	0x78, 0x04, 0xaf, 0xf1, 0xba, 0x4c, 0x66, 0xef, 0xa5, 0x45, 0xb3, 0x37, 0xd0, 0xf0, 0xbb, 0xf9,
+	0x01, 0x34, 0xe6, 0x27, 0xcd, 0x92, 0x44, 0xdf, 0x78, 0x27, 0xca, 0xa1, 0x94, 0x79, 0xd3, 0x41,
+	0xef, 0x7e, 0x6f, 0xef, 0x41, 0x0f, 0xcd, 0x03, 0x1b, 0xda, 0x41, 0xaf, 0xd7, 0xed, 0xdd, 0x45,
+	0x03, 0x01, 0x28, 0xab, 0x0f, 0xbb, 0xec, 0x95, 0x36, 0xbf, 0xf9, 0xcf, 0x15, 0x3c, 0xab, 0x88,
+	0xf7, 0x8c, 0x2f, 0x64, 0xfd, 0x10, 0xff, 0x5d, 0x01, 0xf9, 0x20, 0xf3, 0xf1, 0x20, 0xf1, 0x5b,
+	0x85, 0xe6, 0x87, 0x0b, 0xf3, 0xcb, 0xbb, 0xfb, 0x0b, 0xe4, 0xb7, 0x39, 0x58, 0x4e, 0x5c, 0x56,
+	0xa7, 0xbd, 0x67, 0x3d, 0xe7, 0x67, 0x0c, 0xcd, 0x1f, 0x2e, 0xc4, 0x1b, 0xca, 0xf2, 0x9b, 0x1c,
+	0xd4, 0x62, 0x0f, 0xf8, 0xe4, 0xd6, 0x22, 0x8f, 0xfe, 0x42, 0x92, 0xdb, 0x8b, 0xff, 0x5e, 0x40,
+	0xb9, 0xf0, 0x76, 0x8e, 0xfc, 0x1a, 0x45, 0x89, 0x3d, 0x65, 0xa7, 0x16, 0xe5, 0xec, 0xc3, 0x7b,
+	0x6a, 0x51, 0xce, 0x7b, 0x39, 0xbf, 0x40, 0x7e, 0x99, 0x83, 0x6a, 0xf8, 0x2c, 0x4d, 0x6e, 0x64,
+	0x7f, 0xc8, 0x16, 0x42, 0xdc, 0x5c, 0xf4, 0x05, 0x1c, 0x45, 0xf8, 0x39, 0x54, 0x82, 0x37, 0x5c,
+	0x92, 0x36, 0xe7, 0xcd, 0x3d, 0x10, 0x37, 0x6f, 0x64, 0xe6, 0x8b, 0x4f, 0x1f, 0x3c, 0xac, 0xa6,
+	0x9e, 0x7e, 0xee, 0x09, 0xb8, 0x79, 0x23, 0x33, 0x5f, 0x38, 0x3d, 0xb3, 0x84, 0xd8, 0xfb, 0x6b,
+	0x6a, 0x4b, 0x38, 0xfb, 0xf0, 0x9b, 0xda, 0x12, 0xce, 0x7b, 0xee, 0x15, 0x82, 0xc4, 0x5e, 0x70,
+	0x53, 0x0b, 0x72, 0xf6, 0x95, 0x38, 0xb5, 0x20, 0xe7, 0x3c, 0x18, 0xa3, 0x20, 0x9f, 0xe7, 0xe2,
+	0xa7, 0x89, 0x1b, 0x99, 0x1f, 0x2a, 0x33, 0x9a, 0xe4, 0x99, 0xa7, 0x52, 0xee, 0xa0, 0x9f, 0xcb,
+	0x2b, 0x19, 0xf1, 0xce, 0x49, 0xb2, 0x80, 0x25, 0x9e, 0x46, 0x9b, 0xd7, 0x17, 0x4b, 0x60, 0x5c,
+	0x88, 0x5f, 0xa1, 0x10, 0xd1, 0x8b, 0x68, 0x6a, 0x21, 0xce, 0x3c, 0xc5, 0x36, 0x6f, 0x2d, 0xc0,
+	0x19, 0x77, 0x90, 0xe0, 0x11, 0x34, 0xb5, 0x83, 0xcc, 0xbd, 0xd8, 0xa6, 0x76, 0x90, 0xf9, 0xd7,
+	0x56, 0xe5, 0xc2, 0xc7, 0x4b, 0x3f, 0x2e, 0x89, 0x8a, 0xa2, 0xcc, 0xff, 0xbd, 0xfb, 0x7f, 0x6f,
+	0xf8, 0x46, 0x73, 0x74, 0x28, 0x00, 0x00,
 }
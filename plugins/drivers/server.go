@@ -160,6 +160,7 @@ func (b *driverPluginServer) WaitTask(ctx context.Context, req *proto.WaitTaskRe
 			ExitCode:  int32(result.ExitCode),
 			Signal:    int32(result.Signal),
 			OomKilled: result.OOMKilled,
+			Details:   result.Details,
 		},
 	}
 
@@ -311,6 +312,9 @@ func (b *driverPluginServer) TaskEvents(req *proto.TaskEventsRequest, srv proto.
 			Message:     event.Message,
 			Annotations: event.Annotations,
 		}
+		if event.ExitResult != nil {
+			pbEvent.ExitResult = exitResultToProto(event.ExitResult)
+		}
 
 		if err = srv.Send(pbEvent); err == io.EOF {
 			break
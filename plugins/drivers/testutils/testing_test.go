@@ -163,7 +163,14 @@ func TestBaseDriver_WaitTask(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
 
-	result := &drivers.ExitResult{ExitCode: 1, Signal: 9}
+	result := &drivers.ExitResult{
+		ExitCode:  137,
+		OOMKilled: true,
+		Details: map[string]string{
+			drivers.ExitDetailMemoryRSS:   "2254857830",
+			drivers.ExitDetailMemoryLimit: "2147483648",
+		},
+	}
 
 	signalTask := make(chan struct{})
 
@@ -227,6 +234,18 @@ func TestBaseDriver_TaskEvents(t *testing.T) {
 			Annotations: map[string]string{"foo": "bar"},
 			Message:     "running",
 		},
+		{
+			TaskID:      "xyz",
+			Timestamp:   now.Add(5 * time.Second),
+			Annotations: map[string]string{"foo": "bar"},
+			Message:     "exited",
+			ExitResult: &drivers.ExitResult{
+				ExitCode:  137,
+				Signal:    9,
+				OOMKilled: true,
+				Details:   map[string]string{drivers.ExitDetailMemoryLimit: "2147483648"},
+			},
+		},
 	}
 
 	impl := &MockDriver{
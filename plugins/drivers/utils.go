@@ -331,6 +331,7 @@ func exitResultToProto(result *ExitResult) *proto.ExitResult {
 		ExitCode:  int32(result.ExitCode),
 		Signal:    int32(result.Signal),
 		OomKilled: result.OOMKilled,
+		Details:   result.Details,
 	}
 }
 
@@ -339,6 +340,7 @@ func exitResultFromProto(pb *proto.ExitResult) *ExitResult {
 		ExitCode:  int(pb.ExitCode),
 		Signal:    int(pb.Signal),
 		OOMKilled: pb.OomKilled,
+		Details:   pb.Details,
 	}
 }
 
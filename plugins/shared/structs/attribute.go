@@ -26,6 +26,7 @@ const (
 	UnitByteRate
 	UnitHertz
 	UnitWatt
+	UnitBitRate
 )
 
 // Unit describes a unit and its multiplier over the base unit type
@@ -379,6 +379,17 @@ func TestAttribute_Compare_Int_With_Units(t *testing.T) {
 			},
 			Expected: 0,
 		},
+		{
+			A: &Attribute{
+				Int:  helper.Int64ToPtr(25000),
+				Unit: "Mb/s",
+			},
+			B: &Attribute{
+				Int:  helper.Int64ToPtr(10),
+				Unit: "Gb/s",
+			},
+			Expected: 1,
+		},
 	}
 	testComparison(t, cases)
 }
@@ -626,6 +637,13 @@ func TestAttribute_ParseAndValidate(t *testing.T) {
 				Unit: "MHz",
 			},
 		},
+		{
+			Input: "10Gb/s",
+			Expected: &Attribute{
+				Int:  helper.Int64ToPtr(10),
+				Unit: "Gb/s",
+			},
+		},
 		{
 			Input: "-1.0MB/s",
 			Expected: &Attribute{
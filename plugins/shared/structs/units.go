@@ -37,6 +37,12 @@ const (
 	UnitPBPerS = "PB/s"
 	UnitEBPerS = "EB/s"
 
+	// Decimal SI Bit Rates
+	UnitkbPerS = "kb/s"
+	UnitMbPerS = "Mb/s"
+	UnitGbPerS = "Gb/s"
+	UnitTbPerS = "Tb/s"
+
 	// Hertz units
 	UnitMHz = "MHz"
 	UnitGHz = "GHz"
@@ -51,7 +57,7 @@ const (
 
 var (
 	// numUnits is the number of known units
-	numUnits = len(binarySIBytes) + len(decimalSIBytes) + len(binarySIByteRates) + len(decimalSIByteRates) + len(watts) + len(hertz)
+	numUnits = len(binarySIBytes) + len(decimalSIBytes) + len(binarySIByteRates) + len(decimalSIByteRates) + len(decimalSIBitRates) + len(watts) + len(hertz)
 
 	// UnitIndex is a map of unit name to unit
 	UnitIndex = make(map[string]*Unit, numUnits)
@@ -202,6 +208,29 @@ var (
 		},
 	}
 
+	decimalSIBitRates = []*Unit{
+		{
+			Name:       UnitkbPerS,
+			Base:       UnitBitRate,
+			Multiplier: 1000,
+		},
+		{
+			Name:       UnitMbPerS,
+			Base:       UnitBitRate,
+			Multiplier: Pow(1000, 2),
+		},
+		{
+			Name:       UnitGbPerS,
+			Base:       UnitBitRate,
+			Multiplier: Pow(1000, 3),
+		},
+		{
+			Name:       UnitTbPerS,
+			Base:       UnitBitRate,
+			Multiplier: Pow(1000, 4),
+		},
+	}
+
 	hertz = []*Unit{
 		{
 			Name:       UnitMHz,
@@ -247,7 +276,7 @@ var (
 
 func init() {
 	// Build the index
-	for _, units := range [][]*Unit{binarySIBytes, decimalSIBytes, binarySIByteRates, decimalSIByteRates, watts, hertz} {
+	for _, units := range [][]*Unit{binarySIBytes, decimalSIBytes, binarySIByteRates, decimalSIByteRates, decimalSIBitRates, watts, hertz} {
 		for _, unit := range units {
 			UnitIndex[unit.Name] = unit
 			lengthSortedUnits = append(lengthSortedUnits, unit.Name)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 
+	memdb "github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -510,6 +511,145 @@ func (iter *NodeReschedulingPenaltyIterator) Reset() {
 	iter.source.Reset()
 }
 
+// CanaryAntiAffinityIterator is used to apply a penalty to nodes that would
+// place a canary in the same fault domain as the existing allocations of the
+// task group. A fault domain is either the node itself or a value of any of
+// the spread attributes of the job and task group. This ensures canaries
+// exercise new placements rather than landing next to the allocations they
+// are meant to replace.
+type CanaryAntiAffinityIterator struct {
+	ctx       Context
+	source    RankIterator
+	job       *structs.Job
+	taskGroup *structs.TaskGroup
+	canary    bool
+
+	// attributes is the set of spread attributes that define fault domains
+	attributes []string
+
+	// existingNodes and existingValues are the nodes and attribute values
+	// used by existing non-canary allocations of the task group
+	existingNodes  map[string]struct{}
+	existingValues map[string]map[string]struct{}
+}
+
+// NewCanaryAntiAffinityIterator is used to create a CanaryAntiAffinityIterator
+// that penalizes placing canaries into fault domains already in use.
+func NewCanaryAntiAffinityIterator(ctx Context, source RankIterator) *CanaryAntiAffinityIterator {
+	iter := &CanaryAntiAffinityIterator{
+		ctx:    ctx,
+		source: source,
+	}
+	return iter
+}
+
+func (iter *CanaryAntiAffinityIterator) SetJob(job *structs.Job) {
+	iter.job = job
+}
+
+func (iter *CanaryAntiAffinityIterator) SetTaskGroup(tg *structs.TaskGroup) {
+	iter.taskGroup = tg
+	iter.attributes = nil
+	iter.existingNodes = nil
+	iter.existingValues = nil
+}
+
+// SetCanary sets whether the current selection is for a canary placement.
+// Nodes are only penalized when placing canaries.
+func (iter *CanaryAntiAffinityIterator) SetCanary(canary bool) {
+	iter.canary = canary
+	if canary && iter.existingNodes == nil {
+		iter.computeExisting()
+	}
+}
+
+// computeExisting builds the set of nodes and spread attribute values used by
+// the existing non-canary allocations of the task group.
+func (iter *CanaryAntiAffinityIterator) computeExisting() {
+	iter.existingNodes = make(map[string]struct{})
+	iter.existingValues = make(map[string]map[string]struct{})
+
+	for _, spread := range iter.job.Spreads {
+		iter.attributes = append(iter.attributes, spread.Attribute)
+	}
+	for _, spread := range iter.taskGroup.Spreads {
+		iter.attributes = append(iter.attributes, spread.Attribute)
+	}
+	for _, attr := range iter.attributes {
+		iter.existingValues[attr] = make(map[string]struct{})
+	}
+
+	ws := memdb.NewWatchSet()
+	allocs, err := iter.ctx.State().AllocsByJob(ws, iter.job.Namespace, iter.job.ID, false)
+	if err != nil {
+		iter.ctx.Logger().Named("canary_anti_affinity").Error("failed retrieving existing allocations", "error", err)
+		return
+	}
+
+	for _, alloc := range allocs {
+		if alloc.TaskGroup != iter.taskGroup.Name || alloc.TerminalStatus() {
+			continue
+		}
+		if alloc.DeploymentStatus.IsCanary() {
+			continue
+		}
+		iter.existingNodes[alloc.NodeID] = struct{}{}
+
+		if len(iter.attributes) == 0 {
+			continue
+		}
+		node, err := iter.ctx.State().NodeByID(ws, alloc.NodeID)
+		if err != nil {
+			iter.ctx.Logger().Named("canary_anti_affinity").Error("failed to lookup node", "node_id", alloc.NodeID, "error", err)
+			continue
+		}
+		for _, attr := range iter.attributes {
+			if value, ok := getProperty(node, attr); ok {
+				iter.existingValues[attr][value] = struct{}{}
+			}
+		}
+	}
+}
+
+func (iter *CanaryAntiAffinityIterator) Next() *RankedNode {
+	for {
+		option := iter.source.Next()
+		if option == nil || !iter.canary {
+			return option
+		}
+
+		// Sharing a node with an existing allocation is the maximum penalty.
+		// Otherwise the penalty is proportional to the number of spread
+		// attributes whose value is already used by an existing allocation.
+		scorePenalty := 0.0
+		if _, ok := iter.existingNodes[option.Node.ID]; ok {
+			scorePenalty = -1.0
+		} else if len(iter.attributes) != 0 {
+			collisions := 0
+			for _, attr := range iter.attributes {
+				value, ok := getProperty(option.Node, attr)
+				if !ok {
+					continue
+				}
+				if _, ok := iter.existingValues[attr][value]; ok {
+					collisions++
+				}
+			}
+			scorePenalty = -1 * float64(collisions) / float64(len(iter.attributes))
+		}
+
+		if scorePenalty != 0.0 {
+			option.Scores = append(option.Scores, scorePenalty)
+		}
+		iter.ctx.Metrics().ScoreNode(option.Node, "canary-anti-affinity", scorePenalty)
+		return option
+	}
+}
+
+func (iter *CanaryAntiAffinityIterator) Reset() {
+	iter.source.Reset()
+}
+
 // NodeAffinityIterator is used to resolve any affinity rules in the job or task group,
 // and apply a weighted score to nodes if they match.
 type NodeAffinityIterator struct {
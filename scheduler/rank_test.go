@@ -906,6 +906,70 @@ func TestNodeAntiAffinity_PenaltyNodes(t *testing.T) {
 
 }
 
+func TestCanaryAntiAffinity_FaultDomains(t *testing.T) {
+	require := require.New(t)
+	state, ctx := testContext(t)
+
+	// Create three nodes, two in dc1 and one in dc2
+	var nodes []*RankedNode
+	for i, dc := range []string{"dc1", "dc1", "dc2"} {
+		node := mock.Node()
+		node.Datacenter = dc
+		require.NoError(state.UpsertNode(uint64(100+i), node))
+		nodes = append(nodes, &RankedNode{Node: node})
+	}
+
+	job := mock.Job()
+	job.Spreads = []*structs.Spread{{Attribute: "${node.datacenter}", Weight: 100}}
+	tg := job.TaskGroups[0]
+
+	// Place an existing non-canary alloc on the first node and a canary on
+	// the third which should be ignored
+	existing := mock.Alloc()
+	existing.Job = job
+	existing.JobID = job.ID
+	existing.NodeID = nodes[0].Node.ID
+
+	canary := mock.Alloc()
+	canary.Job = job
+	canary.JobID = job.ID
+	canary.NodeID = nodes[2].Node.ID
+	canary.DeploymentStatus = &structs.AllocDeploymentStatus{Canary: true}
+	require.NoError(state.UpsertAllocs(1000, []*structs.Allocation{existing, canary}))
+
+	static := NewStaticRankIterator(ctx, nodes)
+	canaryIter := NewCanaryAntiAffinityIterator(ctx, static)
+	canaryIter.SetJob(job)
+	canaryIter.SetTaskGroup(tg)
+	canaryIter.SetCanary(true)
+
+	scoreNorm := NewScoreNormalizationIterator(ctx, canaryIter)
+	out := collectRanked(scoreNorm)
+
+	require.Len(out, 3)
+	require.Equal(-1.0, out[0].FinalScore)
+	require.Equal(-1.0, out[1].FinalScore)
+	require.Equal(0.0, out[2].FinalScore)
+
+	// Non-canary placements should not be penalized
+	var fresh []*RankedNode
+	for _, option := range nodes {
+		fresh = append(fresh, &RankedNode{Node: option.Node})
+	}
+	static = NewStaticRankIterator(ctx, fresh)
+	canaryIter = NewCanaryAntiAffinityIterator(ctx, static)
+	canaryIter.SetJob(job)
+	canaryIter.SetTaskGroup(tg)
+	canaryIter.SetCanary(false)
+
+	out = collectRanked(NewScoreNormalizationIterator(ctx, canaryIter))
+
+	require.Len(out, 3)
+	for _, option := range out {
+		require.Equal(0.0, option.FinalScore)
+	}
+}
+
 func TestScoreNormalizationIterator(t *testing.T) {
 	// Test normalized scores when there is more than one scorer
 	_, ctx := testContext(t)
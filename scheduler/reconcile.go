@@ -67,6 +67,10 @@ type allocReconciler struct {
 	// deploymentFailed marks whether the deployment is failed
 	deploymentFailed bool
 
+	// deploymentPending marks whether a created deployment has to wait for
+	// the deployments of the earlier regions of a multiregion job
+	deploymentPending bool
+
 	// taintedNodes contains a map of nodes that are tainted
 	taintedNodes map[string]*structs.Node
 
@@ -195,8 +199,12 @@ func (a *allocReconciler) Compute() *reconcileResults {
 
 	// Detect if the deployment is paused
 	if a.deployment != nil {
-		a.deploymentPaused = a.deployment.Status == structs.DeploymentStatusPaused
+		a.deploymentPaused = a.deployment.Status == structs.DeploymentStatusPaused ||
+			a.deployment.Status == structs.DeploymentStatusPending
 		a.deploymentFailed = a.deployment.Status == structs.DeploymentStatusFailed
+	} else if a.multiregionPending() {
+		a.deploymentPending = true
+		a.deploymentPaused = true
 	}
 
 	// Reconcile each group
@@ -208,23 +216,65 @@ func (a *allocReconciler) Compute() *reconcileResults {
 
 	// Mark the deployment as complete if possible
 	if a.deployment != nil && complete {
-		a.result.deploymentUpdates = append(a.result.deploymentUpdates, &structs.DeploymentStatusUpdate{
-			DeploymentID:      a.deployment.ID,
-			Status:            structs.DeploymentStatusSuccessful,
-			StatusDescription: structs.DeploymentStatusDescriptionSuccessful,
-		})
+		if !a.job.IsMultiregion() {
+			a.result.deploymentUpdates = append(a.result.deploymentUpdates, &structs.DeploymentStatusUpdate{
+				DeploymentID:      a.deployment.ID,
+				Status:            structs.DeploymentStatusSuccessful,
+				StatusDescription: structs.DeploymentStatusDescriptionSuccessful,
+			})
+		} else if a.deployment.Status != structs.DeploymentStatusBlocked {
+			// The deployments of a multiregion job only succeed once all the
+			// regions are complete
+			a.result.deploymentUpdates = append(a.result.deploymentUpdates, &structs.DeploymentStatusUpdate{
+				DeploymentID:      a.deployment.ID,
+				Status:            structs.DeploymentStatusBlocked,
+				StatusDescription: structs.DeploymentStatusDescriptionBlocked,
+			})
+		}
 	}
 
 	// Set the description of a created deployment
 	if d := a.result.deployment; d != nil {
 		if d.RequiresPromotion() {
-			d.StatusDescription = structs.DeploymentStatusDescriptionRunningNeedsPromotion
+			if d.HasAutoPromote() {
+				d.StatusDescription = structs.DeploymentStatusDescriptionRunningAutoPromotion
+			} else {
+				d.StatusDescription = structs.DeploymentStatusDescriptionRunningNeedsPromotion
+			}
 		}
 	}
 
 	return a.result
 }
 
+// multiregionPending returns whether the deployment of a new version of a
+// multiregion job has to wait for the deployments of the earlier regions.
+func (a *allocReconciler) multiregionPending() bool {
+	if !a.job.IsMultiregion() {
+		return false
+	}
+	strategy := a.job.Multiregion.Strategy
+	if strategy == nil || strategy.MaxParallel == 0 ||
+		a.job.Multiregion.Index(a.job.Region) < strategy.MaxParallel {
+		return false
+	}
+
+	// Only the first deployment of a version waits
+	for _, d := range []*structs.Deployment{a.deployment, a.oldDeployment} {
+		if d != nil && d.JobCreateIndex == a.job.CreateIndex && d.JobVersion == a.job.Version {
+			return false
+		}
+	}
+
+	// Without an update strategy no deployment is created to wait on
+	for _, tg := range a.job.TaskGroups {
+		if tg.Update != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // cancelDeployments cancels any deployment that is not needed
 func (a *allocReconciler) cancelDeployments() {
 	// If the job is stopped and there is a non-terminal deployment, cancel it
@@ -327,6 +377,8 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 		dstate = &structs.DeploymentState{}
 		if tg.Update != nil {
 			dstate.AutoRevert = tg.Update.AutoRevert
+			dstate.AutoPromote = tg.Update.AutoPromote
+			dstate.ManualPromotionPerGroup = tg.Update.ManualPromotionPerGroup
 			dstate.ProgressDeadline = tg.Update.ProgressDeadline
 		}
 	}
@@ -498,6 +550,10 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 		// A previous group may have made the deployment already
 		if a.deployment == nil {
 			a.deployment = structs.NewDeployment(a.job)
+			if a.deploymentPending {
+				a.deployment.Status = structs.DeploymentStatusPending
+				a.deployment.StatusDescription = structs.DeploymentStatusDescriptionPendingForPeer
+			}
 			a.result.deployment = a.deployment
 		}
 
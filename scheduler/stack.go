@@ -34,6 +34,7 @@ type Stack interface {
 type SelectOptions struct {
 	PenaltyNodeIDs map[string]struct{}
 	PreferredNodes []*structs.Node
+	Canary         bool
 }
 
 // GenericStack is the Stack used for the Generic scheduler. It is
@@ -55,6 +56,7 @@ type GenericStack struct {
 	binPack                    *BinPackIterator
 	jobAntiAff                 *JobAntiAffinityIterator
 	nodeReschedulingPenalty    *NodeReschedulingPenaltyIterator
+	canaryAntiAff              *CanaryAntiAffinityIterator
 	limit                      *LimitIterator
 	maxScore                   *MaxScoreIterator
 	nodeAffinity               *NodeAffinityIterator
@@ -119,7 +121,11 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 
 	s.nodeReschedulingPenalty = NewNodeReschedulingPenaltyIterator(ctx, s.jobAntiAff)
 
-	s.nodeAffinity = NewNodeAffinityIterator(ctx, s.nodeReschedulingPenalty)
+	// Apply the canary anti-affinity iterator. This is to place canaries in
+	// fault domains not used by the existing allocations of the task group.
+	s.canaryAntiAff = NewCanaryAntiAffinityIterator(ctx, s.nodeReschedulingPenalty)
+
+	s.nodeAffinity = NewNodeAffinityIterator(ctx, s.canaryAntiAff)
 
 	s.spread = NewSpreadIterator(ctx, s.nodeAffinity)
 
@@ -161,6 +167,7 @@ func (s *GenericStack) SetJob(job *structs.Job) {
 	s.distinctPropertyConstraint.SetJob(job)
 	s.binPack.SetPriority(job.Priority)
 	s.jobAntiAff.SetJob(job)
+	s.canaryAntiAff.SetJob(job)
 	s.nodeAffinity.SetJob(job)
 	s.spread.SetJob(job)
 	s.ctx.Eligibility().SetJob(job)
@@ -204,13 +211,17 @@ func (s *GenericStack) Select(tg *structs.TaskGroup, options *SelectOptions) *Ra
 	s.wrappedChecks.SetTaskGroup(tg.Name)
 	s.binPack.SetTaskGroup(tg)
 	s.jobAntiAff.SetTaskGroup(tg)
+	s.canaryAntiAff.SetTaskGroup(tg)
+	canary := false
 	if options != nil {
 		s.nodeReschedulingPenalty.SetPenaltyNodes(options.PenaltyNodeIDs)
+		canary = options.Canary
 	}
+	s.canaryAntiAff.SetCanary(canary)
 	s.nodeAffinity.SetTaskGroup(tg)
 	s.spread.SetTaskGroup(tg)
 
-	if s.nodeAffinity.hasAffinities() || s.spread.hasSpreads() {
+	if s.nodeAffinity.hasAffinities() || s.spread.hasSpreads() || canary {
 		s.limit.SetLimit(math.MaxInt32)
 	}
 
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"fmt"
+	"time"
 
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
@@ -35,6 +36,10 @@ type SystemScheduler struct {
 	limitReached bool
 	nextEval     *structs.Evaluation
 
+	// rollingStagger is the period to wait before re-evaluating a rolling
+	// update that was limited by the task groups' update strategies
+	rollingStagger time.Duration
+
 	failedTGAllocs map[string]*structs.AllocMetric
 	queuedAllocs   map[string]int
 }
@@ -61,7 +66,7 @@ func (s *SystemScheduler) Process(eval *structs.Evaluation) error {
 	switch eval.TriggeredBy {
 	case structs.EvalTriggerJobRegister, structs.EvalTriggerNodeUpdate, structs.EvalTriggerFailedFollowUp,
 		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate, structs.EvalTriggerPreemption,
-		structs.EvalTriggerDeploymentWatcher, structs.EvalTriggerNodeDrain:
+		structs.EvalTriggerDeploymentWatcher, structs.EvalTriggerNodeDrain, structs.EvalTriggerAllocStop:
 	default:
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason",
 			eval.TriggeredBy)
@@ -130,16 +135,16 @@ func (s *SystemScheduler) process() (bool, error) {
 		return false, err
 	}
 
-	// If the plan is a no-op, we can bail. If AnnotatePlan is set submit the plan
-	// anyways to get the annotations.
-	if s.plan.IsNoOp() && !s.eval.AnnotatePlan {
-		return true, nil
-	}
-
 	// If the limit of placements was reached we need to create an evaluation
-	// to pickup from here after the stagger period.
+	// to pickup from here after the stagger period. This is done even if the
+	// plan is a no-op as a rolling update may be waiting on the health of
+	// previously updated allocations.
 	if s.limitReached && s.nextEval == nil {
-		s.nextEval = s.eval.NextRollingEval(s.job.Update.Stagger)
+		stagger := s.job.Update.Stagger
+		if s.rollingStagger != 0 {
+			stagger = s.rollingStagger
+		}
+		s.nextEval = s.eval.NextRollingEval(stagger)
 		if err := s.planner.CreateEval(s.nextEval); err != nil {
 			s.logger.Error("failed to make next eval for rolling update", "error", err)
 			return false, err
@@ -147,6 +152,12 @@ func (s *SystemScheduler) process() (bool, error) {
 		s.logger.Debug("rolling update limit reached, next eval created", "next_eval_id", s.nextEval.ID)
 	}
 
+	// If the plan is a no-op, we can bail. If AnnotatePlan is set submit the plan
+	// anyways to get the annotations.
+	if s.plan.IsNoOp() && !s.eval.AnnotatePlan {
+		return true, nil
+	}
+
 	// Submit the plan
 	result, newState, err := s.planner.SubmitPlan(s.plan)
 	s.planResult = result
@@ -234,15 +245,20 @@ func (s *SystemScheduler) computeJobAllocs() error {
 		}
 	}
 
-	// Check if a rolling upgrade strategy is being used
-	limit := len(diff.update)
-	if !s.job.Stopped() && s.job.Update.Rolling() {
-		limit = s.job.Update.MaxParallel
+	// Treat non in-place updates as an eviction and new placement. If the
+	// task groups define an update strategy the updates are rolled per task
+	// group based on the health of the already updated allocations.
+	// Otherwise fall back to the job's legacy rolling upgrade strategy.
+	if !s.job.Stopped() && s.job.HasUpdateStrategy() {
+		s.limitReached = s.evictAndPlaceRolling(diff, allocs)
+	} else {
+		limit := len(diff.update)
+		if !s.job.Stopped() && s.job.Update.Rolling() {
+			limit = s.job.Update.MaxParallel
+		}
+		s.limitReached = evictAndPlace(s.ctx, diff, diff.update, allocUpdating, &limit)
 	}
 
-	// Treat non in-place updates as an eviction and new placement.
-	s.limitReached = evictAndPlace(s.ctx, diff, diff.update, allocUpdating, &limit)
-
 	// Nothing remaining to do if placement is not required
 	if len(diff.place) == 0 {
 		if !s.job.Stopped() {
@@ -262,6 +278,71 @@ func (s *SystemScheduler) computeJobAllocs() error {
 	return s.computePlacements(diff.place)
 }
 
+// evictAndPlaceRolling is used to evict and replace the allocations requiring
+// a destructive update while respecting the update strategy of each task
+// group. At most MaxParallel allocations of a task group may be updated and
+// not yet healthy at any point in time. If an updated allocation is
+// unhealthy the rollout of its task group is halted. It returns whether the
+// updates were limited and a follow up evaluation is required.
+func (s *SystemScheduler) evictAndPlaceRolling(diff *diffResult, allocs []*structs.Allocation) bool {
+	// Group the updates by task group
+	updates := make(map[string][]allocTuple)
+	for _, tuple := range diff.update {
+		name := tuple.TaskGroup.Name
+		updates[name] = append(updates[name], tuple)
+	}
+
+	// Determine the allocations of the current job version that have not yet
+	// been marked healthy
+	pending := make(map[string]int)
+	unhealthy := make(map[string]int)
+	for _, alloc := range allocs {
+		if alloc.Job == nil || alloc.Job.JobModifyIndex != s.job.JobModifyIndex {
+			continue
+		}
+		if alloc.DeploymentStatus.IsUnhealthy() {
+			unhealthy[alloc.TaskGroup]++
+		} else if !alloc.DeploymentStatus.IsHealthy() {
+			pending[alloc.TaskGroup]++
+		}
+	}
+
+	limitReached := false
+	for _, tg := range s.job.TaskGroups {
+		tuples := updates[tg.Name]
+		if len(tuples) == 0 {
+			continue
+		}
+
+		// Without an update strategy all allocations are updated at once
+		if tg.Update == nil {
+			limit := len(tuples)
+			evictAndPlace(s.ctx, diff, tuples, allocUpdating, &limit)
+			continue
+		}
+
+		// Do not continue the rollout if updated allocations are unhealthy
+		if unhealthy[tg.Name] > 0 {
+			s.logger.Debug("halting rolling update of task group with unhealthy allocations",
+				"task_group", tg.Name, "unhealthy", unhealthy[tg.Name])
+			continue
+		}
+
+		limit := tg.Update.MaxParallel - pending[tg.Name]
+		if limit < 0 {
+			limit = 0
+		}
+		if evictAndPlace(s.ctx, diff, tuples, allocUpdating, &limit) {
+			limitReached = true
+			if s.rollingStagger == 0 || tg.Update.Stagger < s.rollingStagger {
+				s.rollingStagger = tg.Update.Stagger
+			}
+		}
+	}
+
+	return limitReached
+}
+
 // computePlacements computes placements for allocations
 func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 	nodeByID := make(map[string]*structs.Node, len(s.nodes))
@@ -355,7 +436,7 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 
 					preemptedAllocIDs = append(preemptedAllocIDs, stop.ID)
 					if s.eval.AnnotatePlan && s.plan.Annotations != nil {
-						s.plan.Annotations.PreemptedAllocs = append(s.plan.Annotations.PreemptedAllocs, stop.Stub())
+						s.plan.Annotations.PreemptedAllocs = append(s.plan.Annotations.PreemptedAllocs, stop.Stub(nil))
 						if s.plan.Annotations.DesiredTGUpdates != nil {
 							desired := s.plan.Annotations.DesiredTGUpdates[missing.TaskGroup.Name]
 							desired.Preemptions += 1
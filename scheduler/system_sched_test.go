@@ -735,6 +735,129 @@ func TestSystemSched_JobModify_Rolling(t *testing.T) {
 	}
 }
 
+func TestSystemSched_JobModify_RollingHealth(t *testing.T) {
+	h := NewHarness(t)
+	require := require.New(t)
+
+	// Create some nodes
+	var nodes []*structs.Node
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		nodes = append(nodes, node)
+		require.NoError(h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Generate a fake job with allocations
+	job := mock.SystemJob()
+	require.NoError(h.State.UpsertJob(h.NextIndex(), job))
+
+	var allocs []*structs.Allocation
+	for _, node := range nodes {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = node.ID
+		alloc.Name = "my-job.web[0]"
+		allocs = append(allocs, alloc)
+	}
+	require.NoError(h.State.UpsertAllocs(h.NextIndex(), allocs))
+
+	// Update the job with a task group update strategy
+	job2 := job.Copy()
+	job2.TaskGroups[0].Update = &structs.UpdateStrategy{
+		Stagger:         10 * time.Second,
+		MaxParallel:     3,
+		HealthCheck:     structs.UpdateStrategyHealthCheck_TaskStates,
+		MinHealthyTime:  10 * time.Second,
+		HealthyDeadline: 5 * time.Minute,
+	}
+
+	// Update the task, such that it cannot be done in-place
+	job2.TaskGroups[0].Tasks[0].Config["command"] = "/bin/other"
+	require.NoError(h.State.UpsertJob(h.NextIndex(), job2))
+
+	process := func() *structs.Plan {
+		eval := &structs.Evaluation{
+			Namespace:   structs.DefaultNamespace,
+			ID:          uuid.Generate(),
+			Priority:    50,
+			TriggeredBy: structs.EvalTriggerRollingUpdate,
+			JobID:       job.ID,
+			Status:      structs.EvalStatusPending,
+		}
+		require.NoError(h.State.UpsertEvals(h.NextIndex(), []*structs.Evaluation{eval}))
+		numPlans := len(h.Plans)
+		require.NoError(h.Process(NewSystemScheduler, eval))
+		if len(h.Plans) == numPlans {
+			return nil
+		}
+		return h.Plans[len(h.Plans)-1]
+	}
+
+	countUpdates := func(plan *structs.Plan) int {
+		if plan == nil {
+			return 0
+		}
+		var update []*structs.Allocation
+		for _, updateList := range plan.NodeUpdate {
+			update = append(update, updateList...)
+		}
+		return len(update)
+	}
+
+	// The first evaluation should only update MaxParallel allocations
+	plan := process()
+	require.Equal(3, countUpdates(plan))
+	require.Len(h.CreateEvals, 1)
+	require.Equal(10*time.Second, h.CreateEvals[0].Wait)
+
+	// The updated allocations have not been marked healthy so no further
+	// updates should be made but a follow up evaluation should be created
+	plan = process()
+	require.Zero(countUpdates(plan))
+	require.Len(h.CreateEvals, 2)
+
+	// Mark the updated allocations as healthy
+	ws := memdb.NewWatchSet()
+	out, err := h.State.AllocsByJob(ws, job.Namespace, job.ID, false)
+	require.NoError(err)
+	var healthy []*structs.Allocation
+	for _, alloc := range out {
+		if alloc.Job.JobModifyIndex != job2.JobModifyIndex || alloc.TerminalStatus() {
+			continue
+		}
+		alloc = alloc.Copy()
+		alloc.DeploymentStatus = &structs.AllocDeploymentStatus{Healthy: helper.BoolToPtr(true)}
+		healthy = append(healthy, alloc)
+	}
+	require.Len(healthy, 3)
+	require.NoError(h.State.UpsertAllocs(h.NextIndex(), healthy))
+
+	// The rollout should continue
+	plan = process()
+	require.Equal(3, countUpdates(plan))
+	require.Len(h.CreateEvals, 3)
+
+	// Mark one of the newly updated allocations as unhealthy
+	out, err = h.State.AllocsByJob(ws, job.Namespace, job.ID, false)
+	require.NoError(err)
+	for _, alloc := range out {
+		if alloc.Job.JobModifyIndex != job2.JobModifyIndex || alloc.TerminalStatus() ||
+			alloc.DeploymentStatus.IsHealthy() {
+			continue
+		}
+		alloc = alloc.Copy()
+		alloc.DeploymentStatus = &structs.AllocDeploymentStatus{Healthy: helper.BoolToPtr(false)}
+		require.NoError(h.State.UpsertAllocs(h.NextIndex(), []*structs.Allocation{alloc}))
+		break
+	}
+
+	// The rollout should be halted without a follow up evaluation
+	plan = process()
+	require.Zero(countUpdates(plan))
+	require.Len(h.CreateEvals, 3)
+}
+
 func TestSystemSched_JobModify_InPlace(t *testing.T) {
 	h := NewHarness(t)
 
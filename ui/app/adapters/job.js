@@ -76,6 +76,16 @@ export default Watchable.extend({
     });
   },
 
+  // The schema is static for the agent, so it is only requested once. Agents
+  // that don't export a schema resolve to null so validation is skipped.
+  schema() {
+    if (!this._schema) {
+      const url = addToPath(this.urlForFindAll('job'), '/schema');
+      this._schema = this.ajax(url, 'GET').catch(() => null);
+    }
+    return this._schema;
+  },
+
   plan(job) {
     const jobId = job.get('id');
     const store = this.get('store');
@@ -38,6 +38,12 @@ export default Component.extend({
   showPlanMessage: localStorageProperty('nomadMessageJobPlan', true),
   showEditorMessage: localStorageProperty('nomadMessageJobEditor', true),
 
+  // JSON definitions are highlighted as javascript and anything else as HCL
+  editorMode: computed('job._newDefinition', 'jobSpec', function() {
+    const definition = this.get('job._newDefinition') || this.get('jobSpec') || '';
+    return definition.trim().startsWith('{') ? 'javascript' : 'ruby';
+  }),
+
   stage: computed('planOutput', function() {
     return this.get('planOutput') ? 'plan' : 'editor';
   }),
@@ -48,6 +48,25 @@ export default Component.extend(WindowResizable, {
 
   mode: 'stdout',
 
+  // When set, only the lines of the log containing the term are shown
+  searchTerm: '',
+
+  output: computed('logger.output', 'searchTerm', function() {
+    const output = this.get('logger.output') || '';
+    const term = (this.get('searchTerm') || '').toLowerCase();
+    if (!term) return output;
+
+    return output
+      .split('\n')
+      .filter(line => line.toLowerCase().includes(term))
+      .join('\n');
+  }),
+
+  matchCount: computed('output', 'searchTerm', function() {
+    if (!this.get('searchTerm')) return 0;
+    return this.get('output').split('\n').filter(line => line).length;
+  }),
+
   logUrl: computed('allocation.id', 'allocation.node.httpAddr', 'useServer', function() {
     const address = this.get('allocation.node.httpAddr');
     const allocation = this.get('allocation.id');
@@ -0,0 +1,48 @@
+import Component from '@ember/component';
+import { computed } from '@ember/object';
+
+export default Component.extend({
+  classNames: ['topology-node'],
+  classNameBindings: ['isIneligible:is-ineligible'],
+
+  node: null,
+  allocations: null,
+
+  isIneligible: computed('node.{isEligible,status}', function() {
+    return !this.get('node.isEligible') || this.get('node.status') !== 'ready';
+  }),
+
+  cpu: resourceUtilization('cpu'),
+  memory: resourceUtilization('memory'),
+});
+
+// Computes the capacity of the node for a resource, the amount reserved by
+// each allocation and the portion of the bar each allocation takes.
+function resourceUtilization(resource) {
+  return computed(
+    `node.{resources,reserved}.${resource}`,
+    `allocations.@each.resources`,
+    function() {
+      const capacity =
+        (this.get(`node.resources.${resource}`) || 0) -
+        (this.get(`node.reserved.${resource}`) || 0);
+
+      const allocations = (this.get('allocations') || []).map(allocation => {
+        const value = allocation.get(`resources.${resource}`) || 0;
+        const percent = capacity ? Math.min(value / capacity, 1) : 0;
+        return {
+          allocation,
+          value,
+          style: `width:${(percent * 100).toFixed(2)}%`.htmlSafe(),
+        };
+      });
+
+      const used = allocations.mapBy('value').reduce((total, value) => total + value, 0);
+      return {
+        capacity,
+        used,
+        allocations,
+      };
+    }
+  );
+}
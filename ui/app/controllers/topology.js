@@ -0,0 +1,77 @@
+import { alias } from '@ember/object/computed';
+import Controller from '@ember/controller';
+import { computed } from '@ember/object';
+
+const sum = (total, value) => total + (value || 0);
+
+export default Controller.extend({
+  nodes: alias('model.nodes'),
+  allocations: alias('model.allocations'),
+
+  isForbidden: false,
+
+  // Only allocations that are placed and not yet stopped use the resources
+  // of their client
+  activeAllocations: computed('allocations.@each.clientStatus', function() {
+    return (this.get('allocations') || []).filter(alloc =>
+      ['pending', 'running'].includes(alloc.get('clientStatus'))
+    );
+  }),
+
+  allocationsByNode: computed('activeAllocations.[]', function() {
+    return this.get('activeAllocations').reduce((groups, alloc) => {
+      const nodeId = alloc.belongsTo('node').id();
+      (groups[nodeId] || (groups[nodeId] = [])).push(alloc);
+      return groups;
+    }, {});
+  }),
+
+  // Nodes grouped by datacenter, then by node class, each sorted by name
+  datacenters: computed('nodes.@each.{datacenter,nodeClass,name}', 'allocationsByNode', function() {
+    const allocationsByNode = this.get('allocationsByNode');
+    const datacenters = {};
+
+    (this.get('nodes') || []).sortBy('name').forEach(node => {
+      const dc = node.get('datacenter');
+      const nodeClass = node.get('nodeClass') || '';
+      const classes = datacenters[dc] || (datacenters[dc] = {});
+      (classes[nodeClass] || (classes[nodeClass] = [])).push({
+        node,
+        allocations: allocationsByNode[node.get('id')] || [],
+      });
+    });
+
+    return Object.keys(datacenters)
+      .sort()
+      .map(name => ({
+        name,
+        classes: Object.keys(datacenters[name])
+          .sort()
+          .map(nodeClass => ({ name: nodeClass, nodes: datacenters[name][nodeClass] })),
+      }));
+  }),
+
+  totalCPU: computed('nodes.@each.resources', function() {
+    return (this.get('nodes') || [])
+      .map(node => node.get('resources.cpu') - (node.get('reserved.cpu') || 0))
+      .reduce(sum, 0);
+  }),
+
+  totalMemory: computed('nodes.@each.resources', function() {
+    return (this.get('nodes') || [])
+      .map(node => node.get('resources.memory') - (node.get('reserved.memory') || 0))
+      .reduce(sum, 0);
+  }),
+
+  reservedCPU: computed('activeAllocations.@each.resources', function() {
+    return this.get('activeAllocations')
+      .map(alloc => alloc.get('resources.cpu'))
+      .reduce(sum, 0);
+  }),
+
+  reservedMemory: computed('activeAllocations.@each.resources', function() {
+    return this.get('activeAllocations')
+      .map(alloc => alloc.get('resources.memory'))
+      .reduce(sum, 0);
+  }),
+});
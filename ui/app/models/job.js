@@ -6,6 +6,7 @@ import { belongsTo, hasMany } from 'ember-data/relationships';
 import { fragmentArray } from 'ember-data-model-fragments/attributes';
 import RSVP from 'rsvp';
 import { assert } from '@ember/debug';
+import validateJobDefinition from 'nomad-ui/utils/validate-job-definition';
 
 const JOB_TYPES = ['service', 'batch', 'system'];
 
@@ -225,7 +226,18 @@ export default Model.extend({
         this.setIdByPayload(json);
       }
 
-      promise = RSVP.resolve(definition);
+      // JSON jobs are validated against the schema of the agent instead, so
+      // misspelled fields aren't silently dropped by the API.
+      promise = this.store
+        .adapterFor('job')
+        .schema()
+        .then(schema => {
+          const errors = schema ? validateJobDefinition(json, schema) : [];
+          if (errors.length) {
+            return RSVP.reject({ errors: errors.map(detail => ({ detail })) });
+          }
+          return definition;
+        });
     } catch (err) {
       // If the definition is invalid JSON, assume it is HCL. If it is invalid
       // in anyway, the parse endpoint will throw an error.
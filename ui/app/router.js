@@ -24,6 +24,8 @@ Router.map(function() {
     this.route('client', { path: '/:node_id' });
   });
 
+  this.route('topology');
+
   this.route('servers', function() {
     this.route('server', { path: '/:agent_id' });
   });
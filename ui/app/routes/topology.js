@@ -0,0 +1,28 @@
+import { inject as service } from '@ember/service';
+import Route from '@ember/routing/route';
+import RSVP from 'rsvp';
+import WithForbiddenState from 'nomad-ui/mixins/with-forbidden-state';
+import notifyForbidden from 'nomad-ui/utils/notify-forbidden';
+
+export default Route.extend(WithForbiddenState, {
+  store: service(),
+  system: service(),
+
+  breadcrumbs: [
+    {
+      label: 'Topology',
+      args: ['topology'],
+    },
+  ],
+
+  beforeModel() {
+    return this.get('system.leader');
+  },
+
+  model() {
+    return RSVP.hash({
+      nodes: this.get('store').query('node', { resources: true }),
+      allocations: this.get('store').query('allocation', { resources: true }),
+    }).catch(notifyForbidden(this));
+  },
+});
@@ -38,6 +38,17 @@ export default ApplicationSerializer.extend({
     hash.CreateTimeNanos = hash.CreateTime % 1000000;
     hash.CreateTime = Math.floor(hash.CreateTime / 1000000);
 
+    // Allocation lists requested with resources include the allocated
+    // resources of each task instead of the total Resources object
+    if (!hash.Resources && hash.AllocatedResources) {
+      const tasks = Object.values(hash.AllocatedResources.Tasks || {});
+      hash.Resources = {
+        CPU: tasks.reduce((sum, task) => sum + (get(task, 'Cpu.CpuShares') || 0), 0),
+        MemoryMB: tasks.reduce((sum, task) => sum + (get(task, 'Memory.MemoryMB') || 0), 0),
+        DiskMB: get(hash, 'AllocatedResources.Shared.DiskMB') || 0,
+      };
+    }
+
     hash.RescheduleEvents = (hash.RescheduleTracker || {}).Events;
 
     // API returns empty strings instead of null
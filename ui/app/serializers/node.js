@@ -17,6 +17,15 @@ export default ApplicationSerializer.extend({
       return assign({}, drivers[key], { Name: key });
     });
 
+    // Node lists requested with resources include the structured resources
+    // instead of the flat Resources and Reserved objects
+    if (hash.NodeResources) {
+      hash.Resources = flattenResources(hash.NodeResources);
+    }
+    if (hash.ReservedResources) {
+      hash.Reserved = flattenResources(hash.ReservedResources);
+    }
+
     return this._super(modelClass, hash);
   },
 
@@ -35,3 +44,11 @@ export default ApplicationSerializer.extend({
     };
   },
 });
+
+function flattenResources(resources) {
+  return {
+    CPU: (resources.Cpu || {}).CpuShares,
+    MemoryMB: (resources.Memory || {}).MemoryMB,
+    DiskMB: (resources.Disk || {}).DiskMB,
+  };
+}
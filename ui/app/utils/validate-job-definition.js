@@ -0,0 +1,69 @@
+// Validates a JSON job against the schema returned by /v1/jobs/schema and
+// returns a list of error messages, which is empty when the job is valid.
+//
+// Only the subset of JSON Schema used by the jobs schema is supported: type,
+// properties, additionalProperties and items. Like the API, properties are
+// matched case-insensitively.
+export default function validateJobDefinition(json, schema) {
+  const errors = [];
+  validate(json, schema || {}, 'Job', errors);
+  return errors;
+}
+
+function validate(value, schema, path, errors) {
+  const types = schema.type ? [].concat(schema.type) : [];
+
+  if (value === null) {
+    if (types.length && !types.includes('null')) {
+      errors.push(`${path} must not be null`);
+    }
+    return;
+  }
+
+  if (types.length) {
+    const type = typeOf(value);
+    const allowed = types.includes(type) || (type === 'integer' && types.includes('number'));
+    if (!allowed) {
+      errors.push(`${path} must be ${article(types.filter(t => t !== 'null')[0])}`);
+      return;
+    }
+  }
+
+  if (Array.isArray(value)) {
+    if (schema.items) {
+      value.forEach((item, index) => validate(item, schema.items, `${path}[${index}]`, errors));
+    }
+    return;
+  }
+
+  if (typeof value === 'object') {
+    const properties = schema.properties || {};
+    const names = Object.keys(properties).reduce((names, name) => {
+      names[name.toLowerCase()] = name;
+      return names;
+    }, {});
+
+    Object.keys(value).forEach(key => {
+      const name = names[key.toLowerCase()];
+      const keyPath = `${path}.${key}`;
+
+      if (name) {
+        validate(value[key], properties[name], keyPath, errors);
+      } else if (schema.additionalProperties === false) {
+        errors.push(`${keyPath} is not a valid field`);
+      } else if (schema.additionalProperties) {
+        validate(value[key], schema.additionalProperties, keyPath, errors);
+      }
+    });
+  }
+}
+
+function typeOf(value) {
+  if (Array.isArray(value)) return 'array';
+  if (typeof value === 'number') return Number.isInteger(value) ? 'integer' : 'number';
+  return typeof value;
+}
+
+function article(type) {
+  return /^[aeiou]/.test(type) ? `an ${type}` : `a ${type}`;
+}
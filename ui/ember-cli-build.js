@@ -14,7 +14,9 @@ module.exports = function(defaults) {
       paths: ['public/images/icons'],
     },
     codemirror: {
-      modes: ['javascript'],
+      // HCL has no mode of its own, and ruby highlights the same strings,
+      // numbers, comments and heredocs
+      modes: ['javascript', 'ruby'],
     },
     funnel: {
       enabled: isProd,
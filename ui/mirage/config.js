@@ -85,6 +85,11 @@ export default function() {
     return new Response(200, {}, this.serialize(job));
   });
 
+  // Accept any job, tests that validate jobs override this with a real schema
+  this.get('/jobs/schema', function() {
+    return { type: ['object', 'null'] };
+  });
+
   this.post('/job/:id/plan', function(schema, req) {
     const body = JSON.parse(req.requestBody);
 
@@ -187,8 +192,14 @@ export default function() {
     return this.serialize(allocations.slice(0, 3));
   });
 
-  this.get('/nodes', function({ nodes }) {
+  this.get('/nodes', function({ nodes }, { queryParams }) {
     const json = this.serialize(nodes.all());
+    if (queryParams.resources === 'true') {
+      json.forEach(node => {
+        node.NodeResources = structuredResources(node.Resources);
+        node.ReservedResources = structuredResources(node.Reserved);
+      });
+    }
     return json;
   });
 
@@ -198,7 +209,22 @@ export default function() {
     return this.serialize(allocations.where({ nodeId: params.id }));
   });
 
-  this.get('/allocations');
+  this.get('/allocations', function({ allocations }, { queryParams }) {
+    const json = this.serialize(allocations.all());
+    if (queryParams.resources === 'true') {
+      json.forEach(alloc => {
+        const tasks = Object.keys(alloc.TaskResources || {});
+        alloc.AllocatedResources = {
+          Tasks: tasks.reduce((resources, name) => {
+            resources[name] = structuredResources(alloc.TaskResources[name]);
+            return resources;
+          }, {}),
+          Shared: { DiskMB: 0 },
+        };
+      });
+    }
+    return json;
+  });
 
   this.get('/allocation/:id');
 
@@ -360,3 +386,14 @@ function generateFailedTGAllocs(job, taskGroups) {
     return hash;
   }, {});
 }
+
+// Converts flat resources into the structured resources of nodes and
+// allocations listed with their resources
+function structuredResources(resources) {
+  resources = resources || {};
+  return {
+    Cpu: { CpuShares: resources.CPU || 0 },
+    Memory: { MemoryMB: resources.MemoryMB || 0 },
+    Disk: { DiskMB: resources.DiskMB || 0 },
+  };
+}
@@ -274,6 +274,49 @@ test('when parse fails, the parse error message is shown', function(assert) {
     });
 });
 
+test('when a json job does not match the schema, the validation errors are shown', function(assert) {
+  const spec = jsonJob({ Datacenter: 'dc1', Priority: 'high' });
+
+  let job;
+  run(() => {
+    job = this.store.createRecord('job');
+  });
+
+  this.server.pretender.get('/v1/jobs/schema', () => [
+    200,
+    {},
+    JSON.stringify({
+      type: ['object', 'null'],
+      additionalProperties: false,
+      properties: {
+        Name: { type: ['string', 'null'] },
+        Namespace: { type: ['string', 'null'] },
+        Datacenters: { type: ['array', 'null'], items: { type: ['string', 'null'] } },
+        Priority: { type: ['integer', 'null'] },
+        TaskGroups: { type: ['array', 'null'], items: {} },
+      },
+    }),
+  ]);
+
+  return wait()
+    .then(renderNewJob(this, job))
+    .then(planJob(spec))
+    .then(() => {
+      const requests = this.server.pretender.handledRequests.mapBy('url');
+      assert.notOk(requests.includes(`/v1/job/${newJobName}/plan`), 'Invalid jobs are not planned');
+
+      assert.ok(Editor.parseError.isPresent, 'Parse error is shown');
+      assert.ok(
+        Editor.parseError.message.includes('Job.Priority must be an integer'),
+        'Type errors are shown in the UI'
+      );
+      assert.ok(
+        Editor.parseError.message.includes('Job.Datacenter is not a valid field'),
+        'Unknown fields are shown in the UI'
+      );
+    });
+});
+
 test('when plan fails, the plan error message is shown', function(assert) {
   const spec = hclJob();
   const errorMessage = 'Plan Failed!! :o';
@@ -182,6 +182,38 @@ test('Clicking stderr switches the log to standard error', function(assert) {
   });
 });
 
+test('Searching shows only the lines of the log containing the search term', function(assert) {
+  run.later(run, run.cancelTimers, commonProps.interval);
+
+  this.setProperties(commonProps);
+  this.set('searchTerm', 'ONE');
+  this.render(hbs`{{task-log allocation=allocation task=task searchTerm=searchTerm}}`);
+
+  return wait().then(() => {
+    assert.equal(
+      find('[data-test-log-cli]').textContent,
+      'one',
+      'Matching lines are shown regardless of case'
+    );
+    assert.equal(
+      find('[data-test-log-match-count]').textContent.trim(),
+      '1 match',
+      'The number of matching lines is shown'
+    );
+
+    this.set('searchTerm', 'two');
+    assert.equal(find('[data-test-log-cli]').textContent, '', 'Other lines are hidden');
+
+    this.set('searchTerm', '');
+    assert.equal(
+      find('[data-test-log-cli]').textContent,
+      streamFrames[0],
+      'The whole log is shown without a search term'
+    );
+    assert.notOk(find('[data-test-log-match-count]'), 'No match count without a search term');
+  });
+});
+
 test('When the client is inaccessible, task-log falls back to requesting logs through the server', function(assert) {
   run.later(run, run.cancelTimers, allowedConnectionTime * 2);
 
@@ -0,0 +1,83 @@
+import { getOwner } from '@ember/application';
+import { test, moduleForComponent } from 'ember-qunit';
+import wait from 'ember-test-helpers/wait';
+import hbs from 'htmlbars-inline-precompile';
+import { find, findAll } from 'ember-native-dom-helpers';
+import { startMirage } from 'nomad-ui/initializers/ember-cli-mirage';
+import { initialize as fragmentSerializerInitializer } from 'nomad-ui/initializers/fragment-serializer';
+
+moduleForComponent('topology-node', 'Integration | Component | topology node', {
+  integration: true,
+  beforeEach() {
+    fragmentSerializerInitializer(getOwner(this));
+    this.store = getOwner(this).lookup('service:store');
+    this.server = startMirage();
+    this.server.create('namespace');
+    this.server.create('node', {
+      resources: { CPU: 1000, MemoryMB: 2048, DiskMB: 10000, IOPS: 0, Networks: [] },
+    });
+    this.server.create('job', { createAllocations: false });
+  },
+  afterEach() {
+    this.server.shutdown();
+  },
+});
+
+test('each allocation is a segment of the resource bars sized by its share of the node', function(assert) {
+  this.server.createList('allocation', 2, { clientStatus: 'running' });
+
+  this.store.query('node', { resources: true });
+  this.store.query('allocation', { resources: true });
+
+  return wait()
+    .then(() => {
+      this.setProperties({
+        node: this.store.peekAll('node').get('firstObject'),
+        allocations: this.store.peekAll('allocation').toArray(),
+      });
+
+      this.render(hbs`
+        {{topology-node node=node allocations=allocations}}
+      `);
+      return wait();
+    })
+    .then(() => {
+      const allocations = this.get('allocations');
+      const cpu = allocations.reduce((sum, alloc) => sum + alloc.get('resources.cpu'), 0);
+      const memory = allocations.reduce((sum, alloc) => sum + alloc.get('resources.memory'), 0);
+
+      assert.equal(
+        find('[data-test-topology-node-name]').textContent.trim(),
+        this.get('node.name'),
+        'Node name is shown'
+      );
+      assert.equal(
+        findAll('[data-test-topology-node-cpu] [data-test-topology-node-segment]').length,
+        allocations.length,
+        'One CPU segment per allocation'
+      );
+      assert.equal(
+        find('[data-test-topology-node-cpu] [data-test-topology-node-amount]').textContent.trim(),
+        `${cpu} / 1000 MHz`,
+        'CPU used and capacity are shown'
+      );
+      assert.equal(
+        find(
+          '[data-test-topology-node-memory] [data-test-topology-node-amount]'
+        ).textContent.trim(),
+        `${memory} / 2048 MiB`,
+        'Memory used and capacity are shown'
+      );
+
+      const alloc = allocations[0];
+      const segment = find(
+        `[data-test-topology-node-cpu] [data-test-topology-node-segment="${alloc.get('id')}"]`
+      );
+      const width = (Math.min(alloc.get('resources.cpu') / 1000, 1) * 100).toFixed(2);
+      assert.equal(
+        segment.getAttribute('style'),
+        `width:${width}%`,
+        'Segment width is the share of the node'
+      );
+    });
+});
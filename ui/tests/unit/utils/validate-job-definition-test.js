@@ -0,0 +1,68 @@
+import { module, test } from 'ember-qunit';
+import validateJobDefinition from 'nomad-ui/utils/validate-job-definition';
+
+module('Unit | Util | validateJobDefinition');
+
+const schema = {
+  type: ['object', 'null'],
+  additionalProperties: false,
+  properties: {
+    Name: { type: ['string', 'null'] },
+    Priority: { type: ['integer', 'null'] },
+    Meta: { type: ['object', 'null'], additionalProperties: { type: ['string', 'null'] } },
+    TaskGroups: {
+      type: ['array', 'null'],
+      items: {
+        type: ['object', 'null'],
+        additionalProperties: false,
+        properties: {
+          Name: { type: ['string', 'null'] },
+          Tasks: {
+            type: ['array', 'null'],
+            items: {
+              type: ['object', 'null'],
+              additionalProperties: false,
+              properties: {
+                Driver: { type: ['string', 'null'] },
+                Config: { type: ['object', 'null'], additionalProperties: {} },
+              },
+            },
+          },
+        },
+      },
+    },
+  },
+};
+
+test('Valid jobs have no errors', function(assert) {
+  const job = {
+    Name: 'example',
+    Priority: 50,
+    Meta: { owner: 'ops' },
+    TaskGroups: [{ Name: 'cache', Tasks: [{ Driver: 'docker', Config: { image: 'redis' } }] }],
+  };
+  assert.deepEqual(validateJobDefinition(job, schema), []);
+});
+
+test('Null values and case-insensitive field names are allowed like the API', function(assert) {
+  const job = { name: 'example', Priority: null, TaskGroups: null };
+  assert.deepEqual(validateJobDefinition(job, schema), []);
+});
+
+test('Unknown fields and values of the wrong type are errors', function(assert) {
+  const job = {
+    Name: 'example',
+    Priority: 'high',
+    Meta: { owner: 1 },
+    TaskGroups: [{ Name: 'cache', Tasks: [{ Drivr: 'docker' }] }],
+  };
+  assert.deepEqual(validateJobDefinition(job, schema), [
+    'Job.Priority must be an integer',
+    'Job.Meta.owner must be a string',
+    'Job.TaskGroups[0].Tasks[0].Drivr is not a valid field',
+  ]);
+});
+
+test('Without a schema any job is valid', function(assert) {
+  assert.deepEqual(validateJobDefinition({ Anything: true }), []);
+});